@@ -257,6 +257,57 @@ await foreach (var entry in profileService.StreamBuildToolsLogAsync(runId, cance
             }
         });
 
+        host.MapGet("/profiles/custom", async (IProfileService profileService, CancellationToken cancellationToken) =>
+            Results.Ok(await profileService.ListCustomProfilesAsync(cancellationToken)));
+
+        // The jar is the raw request body; the metadata comes in the query string.
+        host.MapPost("/profiles/custom", async (
+            HttpRequest request,
+            string name,
+            string software,
+            string version,
+            int? java,
+            string? description,
+            bool? replace,
+            IProfileService profileService,
+            CancellationToken cancellationToken) =>
+        {
+            try
+            {
+                var upload = new CustomProfileUpload(name, software, version, java, description, replace ?? false);
+                var profile = await profileService.SaveCustomProfileAsync(upload, request.Body, cancellationToken);
+                return Results.Ok(profile);
+            }
+            catch (ArgumentException ex)
+            {
+                return Results.BadRequest(new { error = ex.Message });
+            }
+            catch (InvalidOperationException ex)
+            {
+                return Results.Conflict(new { error = ex.Message });
+            }
+        });
+
+        host.MapDelete("/profiles/custom/{id}", async (
+            string id,
+            IProfileService profileService,
+            CancellationToken cancellationToken) =>
+        {
+            try
+            {
+                await profileService.DeleteCustomProfileAsync(id, cancellationToken);
+                return Results.Ok(new { message = $"Profile '{id}' deleted" });
+            }
+            catch (ArgumentException ex)
+            {
+                return Results.BadRequest(new { error = ex.Message });
+            }
+            catch (FileNotFoundException ex)
+            {
+                return Results.NotFound(new { error = ex.Message });
+            }
+        });
+
         host.MapPost("/profiles/{id}/copy-to-server", async (
             string id,
             [FromBody] CopyProfileRequest request,
@@ -282,6 +333,57 @@ await foreach (var entry in profileService.StreamBuildToolsLogAsync(runId, cance
             }
         });
 
+        host.MapGet("/quarantine", async (IJarScanService jarScanService, CancellationToken cancellationToken) =>
+            Results.Ok(await jarScanService.ListQuarantineAsync(cancellationToken)));
+
+        host.MapPost("/quarantine/{id}/release", async (
+            string id,
+            IJarScanService jarScanService,
+            CancellationToken cancellationToken) =>
+        {
+            try
+            {
+                var entry = await jarScanService.ReleaseAsync(id, cancellationToken);
+                return Results.Ok(new { message = $"Released '{entry.FileName}' to server '{entry.ServerName}'" });
+            }
+            catch (ArgumentException ex)
+            {
+                return Results.BadRequest(new { error = ex.Message });
+            }
+            catch (FileNotFoundException ex)
+            {
+                return Results.NotFound(new { error = ex.Message });
+            }
+            catch (DirectoryNotFoundException ex)
+            {
+                return Results.NotFound(new { error = ex.Message });
+            }
+            catch (InvalidOperationException ex)
+            {
+                return Results.Conflict(new { error = ex.Message });
+            }
+        });
+
+        host.MapDelete("/quarantine/{id}", async (
+            string id,
+            IJarScanService jarScanService,
+            CancellationToken cancellationToken) =>
+        {
+            try
+            {
+                await jarScanService.DeleteAsync(id, cancellationToken);
+                return Results.Ok(new { message = $"Quarantine entry '{id}' deleted" });
+            }
+            catch (ArgumentException ex)
+            {
+                return Results.BadRequest(new { error = ex.Message });
+            }
+            catch (FileNotFoundException ex)
+            {
+                return Results.NotFound(new { error = ex.Message });
+            }
+        });
+
         host.MapGet("/imports", async (IHostService hostService, CancellationToken cancellationToken) =>
             Results.Ok(await hostService.GetImportsAsync(cancellationToken)));
 
@@ -335,7 +437,7 @@ await foreach (var entry in profileService.StreamBuildToolsLogAsync(runId, cance
                         var importService = services.GetRequiredService<IImportService>();
                         var serverService = services.GetRequiredService<IServerService>();
                         progress.Report(new JobProgressDto(resolvedJobId, "import", request.ServerName, "running", 10, "Unpacking archive", DateTimeOffset.UtcNow));
-                        await importService.CreateServerFromImportAsync(filename, request.ServerName, token);
+                        await importService.CreateServerFromImportAsync(filename, request.ServerName, request.AllowFlagged, token);
                         progress.Report(new JobProgressDto(resolvedJobId, "import", request.ServerName, "running", 90, "Finalizing", DateTimeOffset.UtcNow));
                         await serverService.GetServerAsync(request.ServerName, token);
                     });
@@ -450,4 +552,4 @@ private static bool TryGetUserId(ClaimsPrincipal user, out int userId)
 }
 
 public record BuildToolsRequest(string Group, string Version);
-public record ImportServerRequest(string ServerName);
+public record ImportServerRequest(string ServerName, bool AllowFlagged = false);
@@ -73,6 +73,10 @@ public static RouteGroupBuilder MapModEndpoints(this RouteGroupBuilder servers)
             {
                 return Results.BadRequest(new { error = ex.Message });
             }
+            catch (InvalidOperationException ex)
+            {
+                return Results.Conflict(new { error = ex.Message });
+            }
         });
 
         servers.MapDelete("/{name}/mods/{filename}", async (
@@ -541,9 +545,16 @@ await foreach (var progress in jobService.StreamJobProgressAsync(jobId, cancella
                 return Results.BadRequest(new { error = "No files available for this version" });
             }
 
-            await using var stream = await modrinthService.OpenDownloadStreamAsync(file.Url, cancellationToken);
-            await modService.SaveModAsync(name, file.FileName, stream, cancellationToken);
-            return Results.Ok(new { message = $"Installed mod '{file.FileName}'" });
+            try
+            {
+                await using var stream = await modrinthService.OpenDownloadStreamAsync(file.Url, cancellationToken);
+                await modService.SaveModAsync(name, file.FileName, stream, cancellationToken);
+                return Results.Ok(new { message = $"Installed mod '{file.FileName}'" });
+            }
+            catch (InvalidOperationException ex)
+            {
+                return Results.Conflict(new { error = ex.Message });
+            }
         });
 
         var modrinthModpacks = servers.MapGroup("/{name}/mods/modrinth/modpacks");
@@ -56,6 +56,10 @@ public static RouteGroupBuilder MapPluginEndpoints(this RouteGroupBuilder server
             {
                 return Results.BadRequest(new { error = ex.Message });
             }
+            catch (InvalidOperationException ex)
+            {
+                return Results.Conflict(new { error = ex.Message });
+            }
         });
 
         servers.MapDelete("/{name}/plugins/{filename}", async (
@@ -262,9 +266,16 @@ public static RouteGroupBuilder MapPluginEndpoints(this RouteGroupBuilder server
                 return Results.BadRequest(new { error = "No files available for this version" });
             }
 
-            await using var stream = await modrinthService.OpenDownloadStreamAsync(file.Url, cancellationToken);
-            await pluginService.SavePluginAsync(name, file.FileName, stream, cancellationToken);
-            return Results.Ok(new { message = $"Installed plugin '{file.FileName}'" });
+            try
+            {
+                await using var stream = await modrinthService.OpenDownloadStreamAsync(file.Url, cancellationToken);
+                await pluginService.SavePluginAsync(name, file.FileName, stream, cancellationToken);
+                return Results.Ok(new { message = $"Installed plugin '{file.FileName}'" });
+            }
+            catch (InvalidOperationException ex)
+            {
+                return Results.Conflict(new { error = ex.Message });
+            }
         });
 
         return servers;
@@ -219,6 +219,7 @@ public static RouteGroupBuilder MapServerEndpoints(this RouteGroupBuilder api)
             string action,
             IServerService serverService,
             ISettingsService settingsService,
+            [FromQuery] int? timeoutSeconds,
             CancellationToken cancellationToken) =>
         {
             try
@@ -230,12 +231,12 @@ public static RouteGroupBuilder MapServerEndpoints(this RouteGroupBuilder api)
                         return Results.Ok(new { message = $"Server '{name}' started" });
 
                     case "stop":
-                        var timeout = await ResolveShutdownTimeoutAsync(settingsService, null, cancellationToken);
+                        var timeout = await ResolveShutdownTimeoutAsync(settingsService, timeoutSeconds, cancellationToken);
                         await serverService.StopServerAsync(name, timeout, cancellationToken);
                         return Results.Ok(new { message = $"Server '{name}' stopped" });
 
                     case "restart":
-                        var restartTimeout = await ResolveShutdownTimeoutAsync(settingsService, null, cancellationToken);
+                        var restartTimeout = await ResolveShutdownTimeoutAsync(settingsService, timeoutSeconds, cancellationToken);
                         await serverService.StopServerAsync(name, restartTimeout, cancellationToken);
                         await Task.Delay(1000, cancellationToken);
                         await serverService.StartServerAsync(name, cancellationToken);
@@ -310,6 +311,61 @@ public static RouteGroupBuilder MapServerEndpoints(this RouteGroupBuilder api)
             return Results.Ok(new { message = "Config updated" });
         });
 
+        // Environment variables and Java system properties, applied at the next start
+        servers.MapGet("/{name}/environment", async (
+            string name,
+            IServerService serverService,
+            CancellationToken cancellationToken) =>
+        {
+            try
+            {
+                var environment = await serverService.GetServerEnvironmentAsync(name, cancellationToken);
+                return Results.Ok(environment);
+            }
+            catch (DirectoryNotFoundException ex)
+            {
+                return Results.NotFound(new { error = ex.Message });
+            }
+        });
+
+        servers.MapPut("/{name}/environment", async (
+            string name,
+            [FromBody] ServerEnvironmentDto environment,
+            IServerService serverService,
+            CancellationToken cancellationToken) =>
+        {
+            try
+            {
+                await serverService.UpdateServerEnvironmentAsync(name, environment, cancellationToken);
+                return Results.Ok(new { message = "Environment updated" });
+            }
+            catch (InvalidOperationException ex)
+            {
+                return Results.Conflict(new { error = ex.Message });
+            }
+            catch (DirectoryNotFoundException ex)
+            {
+                return Results.NotFound(new { error = ex.Message });
+            }
+        });
+
+        // Hashes of the server jar, mods and plugins, for checking against upstream
+        servers.MapGet("/{name}/integrity", async (
+            string name,
+            IServerService serverService,
+            CancellationToken cancellationToken) =>
+        {
+            try
+            {
+                var integrity = await serverService.GetServerFileHashesAsync(name, cancellationToken);
+                return Results.Ok(integrity);
+            }
+            catch (DirectoryNotFoundException ex)
+            {
+                return Results.NotFound(new { error = ex.Message });
+            }
+        });
+
         servers.MapPost("/{name}/eula", async (
             string name,
             IServerService serverService,
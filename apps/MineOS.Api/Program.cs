@@ -18,6 +18,7 @@
 using MineOS.Infrastructure.Services;
 using MineOS.Infrastructure.External;
 using MineOS.Infrastructure.Background;
+using MineOS.Infrastructure.Utilities;
 using Serilog;
 
 Log.Logger = new LoggerConfiguration()
@@ -156,6 +157,7 @@
 builder.Services.AddScoped<IFileService, FileService>();
 builder.Services.AddScoped<IImportService, ImportService>();
 builder.Services.AddScoped<IPluginService, PluginService>();
+builder.Services.AddScoped<IJarScanService, JarScanService>();
 builder.Services.AddScoped<ICurseForgeService, CurseForgeService>();
 builder.Services.AddScoped<IWorldService, WorldService>();
 builder.Services.AddScoped<IPlayerService, PlayerService>();
@@ -185,16 +187,20 @@
 builder.Services.AddSingleton<WatchdogService>();
 builder.Services.AddSingleton<IWatchdogService>(sp => sp.GetRequiredService<WatchdogService>());
 builder.Services.AddHostedService(sp => sp.GetRequiredService<WatchdogService>());
-builder.Services.AddHttpClient<IProfileService, ProfileService>();
+// One bucket for every profile, mod and modpack download (MINEOS_RATE_LIMIT).
+builder.Services.AddSingleton<TransferRateLimiter>();
+builder.Services.AddTransient<TransferRateLimitHandler>();
+builder.Services.AddHttpClient<IProfileService, ProfileService>()
+    .AddHttpMessageHandler<TransferRateLimitHandler>();
 builder.Services.AddHttpClient<IModService, ModService>(client =>
 {
     client.Timeout = Timeout.InfiniteTimeSpan;
-});
+}).AddHttpMessageHandler<TransferRateLimitHandler>();
 builder.Services.AddHttpClient<IModrinthService, ModrinthService>(client =>
 {
     client.BaseAddress = new Uri("https://api.modrinth.com/v2/");
     client.DefaultRequestHeaders.UserAgent.ParseAdd("MineOS/1.0");
-});
+}).AddHttpMessageHandler<TransferRateLimitHandler>();
 builder.Services.AddHttpClient<CurseForgeClient>();
 builder.Services.AddScoped<ApiKeySeeder>();
 builder.Services.AddScoped<UserSeeder>();
@@ -25,6 +25,26 @@ public record ProfileDto(
     bool Downloaded,
     object? Progress);
 
+public record CustomProfileDto(
+    string Id,
+    string Name,
+    string Software,
+    string Version,
+    int? JavaVersion,
+    string? Description,
+    string Filename,
+    long SizeBytes,
+    string Sha256,
+    DateTimeOffset UploadedAt);
+
+public record CustomProfileUpload(
+    string Name,
+    string Software,
+    string Version,
+    int? JavaVersion,
+    string? Description,
+    bool Replace);
+
 public record BuildToolsRunDto(
     string RunId,
     string ProfileId,
@@ -0,0 +1,21 @@
+namespace MineOS.Application.Dtos;
+
+public record JarScanFindingDto(
+    string Severity,   // "high" or "medium"
+    string Rule,
+    string Detail,
+    string? Entry);    // Class or nested jar the rule matched in
+
+public record JarScanReportDto(
+    string FileName,
+    string Sha256,
+    bool Flagged,
+    IReadOnlyList<JarScanFindingDto> Findings);
+
+public record QuarantinedJarDto(
+    string Id,
+    string ServerName,
+    string FileName,
+    string OriginalPath,   // Relative to the server directory
+    DateTimeOffset QuarantinedAt,
+    JarScanReportDto Report);
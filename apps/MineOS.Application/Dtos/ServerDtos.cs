@@ -77,6 +77,22 @@ public record AutoRestartConfigDto(
     bool NotifyOnCrash,        // Send notification when crash detected
     bool NotifyOnRestart);     // Send notification when auto-restart triggered
 
+public record ServerEnvironmentDto(
+    Dictionary<string, string> Environment,       // Exported before the server process starts
+    Dictionary<string, string> SystemProperties); // Passed to Java as -Dkey=value
+
+public record ServerFileHashDto(
+    string Path,     // Relative to the server directory
+    string Kind,     // server, mod or plugin
+    long SizeBytes,
+    string Sha1,
+    string Sha256,
+    string Sha512);
+
+public record ServerIntegrityDto(
+    string? JarFile,
+    IReadOnlyList<ServerFileHashDto> Files);
+
 public record MonitoringConfigDto(
     bool TpsEnabled,
     string? TpsCommand);
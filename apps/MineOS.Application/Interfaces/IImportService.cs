@@ -2,7 +2,7 @@ namespace MineOS.Application.Interfaces;
 
 public interface IImportService
 {
-    Task<string> CreateServerFromImportAsync(string filename, string serverName, CancellationToken cancellationToken);
+    Task<string> CreateServerFromImportAsync(string filename, string serverName, bool allowFlagged, CancellationToken cancellationToken);
     Task<string> SaveImportAsync(string filename, Stream content, CancellationToken cancellationToken);
     Task DeleteImportAsync(string filename, CancellationToken cancellationToken);
 }
@@ -0,0 +1,12 @@
+using MineOS.Application.Dtos;
+
+namespace MineOS.Application.Interfaces;
+
+public interface IJarScanService
+{
+    Task<JarScanReportDto> ScanAsync(string jarPath, CancellationToken cancellationToken);
+    Task<QuarantinedJarDto?> QuarantineIfFlaggedAsync(string serverName, string jarPath, CancellationToken cancellationToken);
+    Task<IReadOnlyList<QuarantinedJarDto>> ListQuarantineAsync(CancellationToken cancellationToken);
+    Task<QuarantinedJarDto> ReleaseAsync(string id, CancellationToken cancellationToken);
+    Task DeleteAsync(string id, CancellationToken cancellationToken);
+}
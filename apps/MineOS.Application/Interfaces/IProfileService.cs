@@ -14,4 +14,7 @@ public interface IProfileService
     Task<BuildToolsRunDto?> GetBuildToolsRunAsync(string runId, CancellationToken cancellationToken);
     IAsyncEnumerable<BuildToolsLogEntryDto> StreamBuildToolsLogAsync(string runId, CancellationToken cancellationToken);
     Task DeleteBuildToolsAsync(string id, CancellationToken cancellationToken);
+    Task<IReadOnlyList<CustomProfileDto>> ListCustomProfilesAsync(CancellationToken cancellationToken);
+    Task<CustomProfileDto> SaveCustomProfileAsync(CustomProfileUpload upload, Stream jar, CancellationToken cancellationToken);
+    Task DeleteCustomProfileAsync(string id, CancellationToken cancellationToken);
 }
@@ -23,6 +23,9 @@ public interface IServerService
     Task UpdateServerPropertiesAsync(string name, Dictionary<string, string> properties, CancellationToken cancellationToken);
     Task<ServerConfigDto> GetServerConfigAsync(string name, CancellationToken cancellationToken);
     Task UpdateServerConfigAsync(string name, ServerConfigDto config, CancellationToken cancellationToken);
+    Task<ServerEnvironmentDto> GetServerEnvironmentAsync(string name, CancellationToken cancellationToken);
+    Task UpdateServerEnvironmentAsync(string name, ServerEnvironmentDto environment, CancellationToken cancellationToken);
+    Task<ServerIntegrityDto> GetServerFileHashesAsync(string name, CancellationToken cancellationToken);
 
     Task AcceptEulaAsync(string name, CancellationToken cancellationToken);
     Task RunFtbInstallerAsync(string name, CancellationToken cancellationToken);
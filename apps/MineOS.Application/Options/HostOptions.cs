@@ -8,6 +8,7 @@ public sealed class HostOptions
     public string ProfilesPathSegment { get; set; } = "profiles";
     public string ImportPathSegment { get; set; } = "import";
     public string BackupsPathSegment { get; set; } = "backups";
+    public string QuarantinePathSegment { get; set; } = "quarantine";
     public int RunAsUid { get; set; } = 1000;
     public int RunAsGid { get; set; } = 1000;
 }
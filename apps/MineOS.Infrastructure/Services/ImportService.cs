@@ -12,11 +12,16 @@ public sealed class ImportService : IImportService
 {
     private readonly HostOptions _options;
     private readonly ILogger<ImportService> _logger;
+    private readonly IJarScanService _jarScanService;
 
-    public ImportService(IOptions<HostOptions> options, ILogger<ImportService> logger)
+    public ImportService(
+        IOptions<HostOptions> options,
+        ILogger<ImportService> logger,
+        IJarScanService jarScanService)
     {
         _options = options.Value;
         _logger = logger;
+        _jarScanService = jarScanService;
     }
 
     private string GetImportPath() =>
@@ -56,7 +61,11 @@ public async Task<string> SaveImportAsync(string filename, Stream content, Cance
         return targetPath;
     }
 
-    public async Task<string> CreateServerFromImportAsync(string filename, string serverName, CancellationToken cancellationToken)
+    public async Task<string> CreateServerFromImportAsync(
+        string filename,
+        string serverName,
+        bool allowFlagged,
+        CancellationToken cancellationToken)
     {
         if (string.IsNullOrWhiteSpace(serverName))
         {
@@ -151,6 +160,15 @@ public async Task<string> CreateServerFromImportAsync(string filename, string se
                 _logger,
                 recursive: true);
 
+            if (allowFlagged)
+            {
+                _logger.LogWarning("Skipping jar scan for imported server {ServerName}", serverName);
+            }
+            else
+            {
+                await QuarantineFlaggedJarsAsync(serverName, serverPath, cancellationToken);
+            }
+
             _logger.LogInformation("Imported server {ServerName} from {Filename}", serverName, filename);
             return serverPath;
         }
@@ -185,6 +203,26 @@ public Task DeleteImportAsync(string filename, CancellationToken cancellationTok
         return Task.CompletedTask;
     }
 
+    // Imported archives come from outside MineOS, so every mod and plugin jar is
+    // scanned before the server can be started. Flagged jars are moved to the
+    // quarantine folder and the import still succeeds.
+    private async Task QuarantineFlaggedJarsAsync(string serverName, string serverPath, CancellationToken cancellationToken)
+    {
+        foreach (var folder in new[] { "mods", "plugins" })
+        {
+            var path = Path.Combine(serverPath, folder);
+            if (!Directory.Exists(path))
+            {
+                continue;
+            }
+
+            foreach (var jar in Directory.GetFiles(path, "*.jar", SearchOption.AllDirectories))
+            {
+                await _jarScanService.QuarantineIfFlaggedAsync(serverName, jar, cancellationToken);
+            }
+        }
+    }
+
     private static string ResolveExtractedRoot(string tempDir)
     {
         var directories = Directory.GetDirectories(tempDir);
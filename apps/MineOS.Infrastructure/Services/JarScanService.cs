@@ -0,0 +1,171 @@
+using System.Text.Json;
+using Microsoft.Extensions.Logging;
+using Microsoft.Extensions.Options;
+using MineOS.Application.Dtos;
+using MineOS.Application.Interfaces;
+using MineOS.Application.Options;
+using MineOS.Infrastructure.Utilities;
+
+namespace MineOS.Infrastructure.Services;
+
+public sealed class JarScanService : IJarScanService
+{
+    private const string RecordFileName = "quarantine.json";
+    private static readonly JsonSerializerOptions JsonOptions = new(JsonSerializerDefaults.Web) { WriteIndented = true };
+
+    private readonly HostOptions _options;
+    private readonly ILogger<JarScanService> _logger;
+
+    public JarScanService(IOptions<HostOptions> options, ILogger<JarScanService> logger)
+    {
+        _options = options.Value;
+        _logger = logger;
+    }
+
+    private string GetQuarantinePath() =>
+        Path.Combine(_options.BaseDirectory, _options.QuarantinePathSegment);
+
+    private string GetServerPath(string serverName) =>
+        Path.Combine(_options.BaseDirectory, _options.ServersPathSegment, serverName);
+
+    public Task<JarScanReportDto> ScanAsync(string jarPath, CancellationToken cancellationToken) =>
+        Task.Run(() => JarScanner.Scan(jarPath), cancellationToken);
+
+    public async Task<QuarantinedJarDto?> QuarantineIfFlaggedAsync(
+        string serverName,
+        string jarPath,
+        CancellationToken cancellationToken)
+    {
+        var report = await ScanAsync(jarPath, cancellationToken);
+        if (!report.Flagged)
+        {
+            return null;
+        }
+
+        var id = $"{DateTimeOffset.UtcNow:yyyyMMddHHmmss}-{Guid.NewGuid().ToString("N")[..8]}";
+        var folder = Path.Combine(GetQuarantinePath(), id);
+        Directory.CreateDirectory(folder);
+
+        var fileName = Path.GetFileName(jarPath);
+        var originalPath = Path.GetRelativePath(GetServerPath(serverName), jarPath);
+        File.Move(jarPath, Path.Combine(folder, fileName));
+
+        var record = new QuarantinedJarDto(id, serverName, fileName, originalPath, DateTimeOffset.UtcNow, report);
+        await File.WriteAllTextAsync(
+            Path.Combine(folder, RecordFileName),
+            JsonSerializer.Serialize(record, JsonOptions),
+            cancellationToken);
+
+        _logger.LogWarning(
+            "Quarantined {FileName} from server {ServerName} as {Id}: {Rules}",
+            fileName,
+            serverName,
+            id,
+            string.Join(", ", report.Findings.Select(f => f.Rule)));
+        return record;
+    }
+
+    public async Task<IReadOnlyList<QuarantinedJarDto>> ListQuarantineAsync(CancellationToken cancellationToken)
+    {
+        var root = GetQuarantinePath();
+        if (!Directory.Exists(root))
+        {
+            return Array.Empty<QuarantinedJarDto>();
+        }
+
+        var records = new List<QuarantinedJarDto>();
+        foreach (var folder in Directory.GetDirectories(root))
+        {
+            var record = await ReadRecordAsync(folder, cancellationToken);
+            if (record != null)
+            {
+                records.Add(record);
+            }
+        }
+
+        return records.OrderByDescending(r => r.QuarantinedAt).ToList();
+    }
+
+    public async Task<QuarantinedJarDto> ReleaseAsync(string id, CancellationToken cancellationToken)
+    {
+        var folder = GetEntryPath(id);
+        var record = await ReadRecordAsync(folder, cancellationToken)
+                     ?? throw new FileNotFoundException($"Quarantine entry '{id}' not found");
+
+        var serverPath = GetServerPath(record.ServerName);
+        if (!Directory.Exists(serverPath))
+        {
+            throw new DirectoryNotFoundException($"Server '{record.ServerName}' not found");
+        }
+
+        var targetPath = Path.GetFullPath(Path.Combine(serverPath, record.OriginalPath));
+        if (!targetPath.StartsWith(Path.GetFullPath(serverPath) + Path.DirectorySeparatorChar, StringComparison.Ordinal))
+        {
+            throw new ArgumentException("Invalid original path");
+        }
+
+        if (File.Exists(targetPath))
+        {
+            throw new InvalidOperationException($"{record.OriginalPath} already exists on server '{record.ServerName}'");
+        }
+
+        Directory.CreateDirectory(Path.GetDirectoryName(targetPath)!);
+        File.Move(Path.Combine(folder, record.FileName), targetPath);
+        await OwnershipHelper.ChangeOwnershipAsync(
+            targetPath,
+            _options.RunAsUid,
+            _options.RunAsGid,
+            _logger,
+            cancellationToken);
+        Directory.Delete(folder, recursive: true);
+
+        _logger.LogWarning(
+            "Released quarantined {FileName} back to server {ServerName}",
+            record.FileName,
+            record.ServerName);
+        return record;
+    }
+
+    public Task DeleteAsync(string id, CancellationToken cancellationToken)
+    {
+        var folder = GetEntryPath(id);
+        if (!Directory.Exists(folder))
+        {
+            throw new FileNotFoundException($"Quarantine entry '{id}' not found");
+        }
+
+        Directory.Delete(folder, recursive: true);
+        _logger.LogInformation("Deleted quarantine entry {Id}", id);
+        return Task.CompletedTask;
+    }
+
+    private string GetEntryPath(string id)
+    {
+        if (string.IsNullOrWhiteSpace(id) || Path.GetFileName(id) != id)
+        {
+            throw new ArgumentException("Invalid quarantine id");
+        }
+
+        return Path.Combine(GetQuarantinePath(), id);
+    }
+
+    private async Task<QuarantinedJarDto?> ReadRecordAsync(string folder, CancellationToken cancellationToken)
+    {
+        var path = Path.Combine(folder, RecordFileName);
+        if (!File.Exists(path))
+        {
+            return null;
+        }
+
+        try
+        {
+            var json = await File.ReadAllTextAsync(path, cancellationToken);
+            return JsonSerializer.Deserialize<QuarantinedJarDto>(json, JsonOptions);
+        }
+        catch (JsonException ex)
+        {
+            _logger.LogWarning(ex, "Skipping unreadable quarantine record {Path}", path);
+            return null;
+        }
+    }
+}
@@ -52,6 +52,7 @@ public sealed class ModService : IModService
     private readonly IProfileService _profileService;
     private readonly HttpClient _httpClient;
     private readonly IModpackRepository _modpackRepo;
+    private readonly IJarScanService _jarScanService;
 
     public ModService(
         ILogger<ModService> logger,
@@ -62,7 +63,8 @@ public sealed class ModService : IModService
         IServerService serverService,
         IProfileService profileService,
         HttpClient httpClient,
-        IModpackRepository modpackRepo)
+        IModpackRepository modpackRepo,
+        IJarScanService jarScanService)
     {
         _logger = logger;
         _hostOptions = hostOptions.Value;
@@ -73,6 +75,7 @@ public sealed class ModService : IModService
         _profileService = profileService;
         _httpClient = httpClient;
         _modpackRepo = modpackRepo;
+        _jarScanService = jarScanService;
     }
 
     public Task<IReadOnlyList<InstalledModDto>> ListModsAsync(string serverName, CancellationToken cancellationToken)
@@ -134,16 +137,12 @@ await using (var tempFile = new FileStream(tempPath, FileMode.Create, FileAccess
                 }
 
                 // Extract archive contents
-                if (isZip)
-                {
-                    await ExtractZipToModsAsync(tempPath, modsPath, cancellationToken);
-                }
-                else if (isTar)
-                {
-                    await ExtractTarToModsAsync(tempPath, modsPath, cancellationToken);
-                }
+                var extracted = isZip
+                    ? await ExtractZipToModsAsync(tempPath, modsPath, cancellationToken)
+                    : await ExtractTarToModsAsync(tempPath, modsPath, cancellationToken);
 
                 _logger.LogInformation("Extracted archive {FileName} for server {ServerName}", safeName, serverName);
+                await QuarantineFlaggedJarsAsync(serverName, extracted, cancellationToken);
             }
             finally
             {
@@ -158,8 +157,11 @@ await using (var tempFile = new FileStream(tempPath, FileMode.Create, FileAccess
         {
             // Regular file (JAR) - save directly
             var targetPath = Path.Combine(modsPath, safeName);
-            await using var target = new FileStream(targetPath, FileMode.Create, FileAccess.Write, FileShare.None);
-            await content.CopyToAsync(target, cancellationToken);
+            await using (var target = new FileStream(targetPath, FileMode.Create, FileAccess.Write, FileShare.None))
+            {
+                await content.CopyToAsync(target, cancellationToken);
+            }
+            await QuarantineFlaggedJarsAsync(serverName, new[] { targetPath }, cancellationToken);
             await OwnershipHelper.ChangeOwnershipAsync(
                 targetPath,
                 _hostOptions.RunAsUid,
@@ -354,6 +356,7 @@ public Task<string> GetClientModPathAsync(string serverName, string fileName, Ca
         var targetPath = Path.Combine(modsPath, ValidateFileName(modFile.FileName));
 
         await DownloadFileAsync(downloadUrl, targetPath, progress, serverName, "mod-install", cancellationToken);
+        await QuarantineFlaggedJarsAsync(serverName, new[] { targetPath }, cancellationToken);
         MarkRestartRequired(GetServerPath(serverName));
         _logger.LogInformation("Installed mod {ModId} ({FileName}) for server {ServerName}", modId, modFile.FileName, serverName);
     }
@@ -775,6 +778,9 @@ await using (var manifestStream = manifestEntry.Open())
             }
         }
 
+        state.AppendOutput("Scanning mod and plugin jars...");
+        await QuarantineFlaggedModpackJarsAsync(serverName, state.GetInstalledFilePaths(), cancellationToken);
+
         state.UpdateProgress(95, "Saving modpack records");
         state.AppendOutput("Saving installation records to database...");
 
@@ -850,6 +856,9 @@ await using (var manifestStream = manifestEntry.Open())
 
         state.AppendOutput($"Extracted {extractedCount} file(s).");
 
+        state.AppendOutput("Scanning mod and plugin jars...");
+        await QuarantineFlaggedModpackJarsAsync(serverName, state.GetInstalledFilePaths(), cancellationToken);
+
         AddOverrideModRecords(installedModRecords, modFiles, serverName);
 
         state.UpdateProgress(95, "Saving modpack records");
@@ -1061,7 +1070,7 @@ await using (var manifestStream = manifestEntry.Open())
         var completed = 0;
 
         progress.Report(new JobProgressDto(string.Empty, "modpack-install", serverName, "running", 0, "Extracting overrides", DateTimeOffset.UtcNow));
-        ExtractOverrides(archive, serverName, "overrides/");
+        var writtenPaths = ExtractOverrides(archive, serverName, "overrides/");
         completed++;
         ReportProgress(progress, serverName, "modpack-install", completed, total, "Overrides extracted");
 
@@ -1086,6 +1095,7 @@ await using (var manifestStream = manifestEntry.Open())
                 cancellationToken,
                 null,
                 message => ReportProgress(progress, serverName, "modpack-install", completed, total, message));
+            writtenPaths.Add(targetPath);
 
             await OwnershipHelper.ChangeOwnershipAsync(
                 targetPath,
@@ -1096,6 +1106,8 @@ await using (var manifestStream = manifestEntry.Open())
             completed++;
             ReportProgress(progress, serverName, "modpack-install", completed, total, $"Downloaded {download.FileName}");
         }
+
+        await QuarantineFlaggedModpackJarsAsync(serverName, writtenPaths, cancellationToken);
     }
 
     private async Task ApplyCurseForgeServerPackAsync(
@@ -1111,6 +1123,7 @@ await using (var manifestStream = manifestEntry.Open())
             .ToList();
         var total = fileEntries.Count;
         var completed = 0;
+        var writtenPaths = new List<string>();
 
         progress.Report(new JobProgressDto(
             string.Empty,
@@ -1142,6 +1155,7 @@ await using (var manifestStream = manifestEntry.Open())
             await using var fileStream = new FileStream(destination, FileMode.Create, FileAccess.Write, FileShare.None);
             await entryStream.CopyToAsync(fileStream, cancellationToken);
             OwnershipHelper.TrySetOwnership(destination, _hostOptions.RunAsUid, _hostOptions.RunAsGid, _logger);
+            writtenPaths.Add(destination);
 
             completed++;
             if (completed % 25 == 0 || completed == total)
@@ -1155,6 +1169,8 @@ await using (var manifestStream = manifestEntry.Open())
                     $"Extracted {completed}/{total} files");
             }
         }
+
+        await QuarantineFlaggedModpackJarsAsync(serverName, writtenPaths, cancellationToken);
     }
 
     private async Task ApplyModrinthModpackAsync(
@@ -1210,7 +1226,7 @@ await using (var manifestStream = manifestEntry.Open())
         var completed = 0;
 
         progress.Report(new JobProgressDto(string.Empty, "modrinth-modpack-install", serverName, "running", 0, "Extracting overrides", DateTimeOffset.UtcNow));
-        ExtractOverrides(archive, serverName, "overrides/", "server-overrides/", "server_overrides/");
+        var writtenPaths = ExtractOverrides(archive, serverName, "overrides/", "server-overrides/", "server_overrides/");
         completed++;
         ReportProgress(progress, serverName, "modrinth-modpack-install", completed, total, "Overrides extracted");
         var overrideModFiles = GetOverrideModFileNames(archive, "overrides/", "server-overrides/", "server_overrides/");
@@ -1323,6 +1339,7 @@ await using (var manifestStream = manifestEntry.Open())
                 cancellationToken,
                 null,
                 message => ReportProgress(progress, serverName, "modrinth-modpack-install", completed, total, message));
+            writtenPaths.Add(targetPath);
 
             await OwnershipHelper.ChangeOwnershipAsync(
                 targetPath,
@@ -1417,6 +1434,7 @@ await using (var manifestStream = manifestEntry.Open())
                     cancellationToken,
                     null,
                     message => ReportProgress(progress, serverName, "modrinth-modpack-install", completed, total, message));
+                writtenPaths.Add(targetPath);
 
                 await OwnershipHelper.ChangeOwnershipAsync(
                     targetPath,
@@ -1500,6 +1518,8 @@ await using (var manifestStream = manifestEntry.Open())
             await UpdateClientModSourceMapAsync(serverName, clientModFileNames, modpackName, cancellationToken);
         }
 
+        await QuarantineFlaggedModpackJarsAsync(serverName, writtenPaths, cancellationToken);
+
         AddOverrideModRecords(installedRecords, overrideModFiles, serverName);
 
         await _modpackRepo.UpsertModpackAsync(
@@ -1527,9 +1547,11 @@ await using (var manifestStream = manifestEntry.Open())
             DateTimeOffset.UtcNow));
     }
 
-    private void ExtractOverrides(ZipArchive archive, string serverName, params string[] roots)
+    // Returns the paths of the files written, for the jar scan.
+    private List<string> ExtractOverrides(ZipArchive archive, string serverName, params string[] roots)
     {
         var serverPath = GetServerPath(serverName);
+        var written = new List<string>();
         var normalizedRoots = roots
             .Where(root => !string.IsNullOrWhiteSpace(root))
             .Select(root => root.EndsWith("/", StringComparison.Ordinal) ? root : $"{root}/")
@@ -1578,7 +1600,10 @@ private void ExtractOverrides(ZipArchive archive, string serverName, params stri
             using var fileStream = new FileStream(destination, FileMode.Create, FileAccess.Write, FileShare.None);
             entryStream.CopyTo(fileStream);
             OwnershipHelper.TrySetOwnership(destination, _hostOptions.RunAsUid, _hostOptions.RunAsGid, _logger);
+            written.Add(destination);
         }
+
+        return written;
     }
 
     private static IReadOnlyList<string> GetOverrideModFileNames(ZipArchive archive, params string[] roots)
@@ -1943,50 +1968,28 @@ private sealed record ModpackDownload(int ProjectId, int FileId, string FileName
         {
             try
             {
-                using var request = new HttpRequestMessage(HttpMethod.Get, url);
-
-                // Add CurseForge API key for downloads from api.curseforge.com
-                if (!string.IsNullOrWhiteSpace(curseForgeApiKey))
-                {
-                    request.Headers.Add("x-api-key", curseForgeApiKey);
-                }
-
-                using var response = await _httpClient.SendAsync(
-                    request,
-                    HttpCompletionOption.ResponseHeadersRead,
+                // A retry, or a later install of the same file, resumes the
+                // part the failed attempt left.
+                await ResumableDownload.DownloadAsync(
+                    _httpClient,
+                    url,
+                    targetPath,
+                    request =>
+                    {
+                        // Add CurseForge API key for downloads from api.curseforge.com
+                        if (!string.IsNullOrWhiteSpace(curseForgeApiKey))
+                        {
+                            request.Headers.Add("x-api-key", curseForgeApiKey);
+                        }
+                    },
+                    progressCallback,
+                    _logger,
                     cancellationToken);
 
-                if (!response.IsSuccessStatusCode)
-                {
-                    var body = await response.Content.ReadAsStringAsync(cancellationToken);
-                    _logger.LogError("Download failed for URL: {Url} - Status: {StatusCode} - Body: {Body}",
-                        url, (int)response.StatusCode, body);
-                    throw new HttpRequestException(
-                        $"Download failed ({(int)response.StatusCode}) for URL {url}: {body}",
-                        null,
-                        response.StatusCode);
-                }
-
-                var totalBytes = response.Content.Headers.ContentLength;
-
-                await using var source = await response.Content.ReadAsStreamAsync(cancellationToken);
-                await using var target = new FileStream(targetPath, FileMode.Create, FileAccess.Write, FileShare.None);
-
-                var buffer = new byte[8192];
-                long totalRead = 0;
-                int read;
-                while ((read = await source.ReadAsync(buffer, cancellationToken)) > 0)
-                {
-                    await target.WriteAsync(buffer.AsMemory(0, read), cancellationToken);
-                    totalRead += read;
-                    progressCallback?.Invoke(totalRead, totalBytes);
-                }
-
                 return;
             }
             catch (Exception ex) when (IsRetryableDownloadException(ex) && attempt < DownloadRetryDelays.Length)
             {
-                TryDeleteFile(targetPath);
                 var delay = DownloadRetryDelays[attempt];
                 onRetry?.Invoke($"Download failed ({FormatDownloadError(ex)}). Retrying in {delay.TotalSeconds:0}s...");
                 await Task.Delay(delay, cancellationToken);
@@ -2027,21 +2030,6 @@ private static string FormatDownloadError(Exception ex)
         return ex.GetType().Name;
     }
 
-    private static void TryDeleteFile(string path)
-    {
-        try
-        {
-            if (File.Exists(path))
-            {
-                File.Delete(path);
-            }
-        }
-        catch
-        {
-            // Ignore cleanup failures.
-        }
-    }
-
     private async Task<int> ResolveFileIdAsync(int modId, int? fileId, CancellationToken cancellationToken)
     {
         if (fileId.HasValue)
@@ -2741,8 +2729,9 @@ private static bool IsKnownClientOnlyCategory(IReadOnlyList<string> categories)
                ?? version.Files.FirstOrDefault();
     }
 
-    private async Task ExtractZipToModsAsync(string zipPath, string modsPath, CancellationToken cancellationToken)
+    private async Task<IReadOnlyList<string>> ExtractZipToModsAsync(string zipPath, string modsPath, CancellationToken cancellationToken)
     {
+        var extracted = new List<string>();
         using var archive = ZipFile.OpenRead(zipPath);
         foreach (var entry in archive.Entries)
         {
@@ -2766,12 +2755,16 @@ private async Task ExtractZipToModsAsync(string zipPath, string modsPath, Cancel
                 _logger,
                 cancellationToken);
 
+            extracted.Add(targetPath);
             _logger.LogInformation("Extracted JAR: {FileName}", entry.Name);
         }
+
+        return extracted;
     }
 
-    private async Task ExtractTarToModsAsync(string tarPath, string modsPath, CancellationToken cancellationToken)
+    private async Task<IReadOnlyList<string>> ExtractTarToModsAsync(string tarPath, string modsPath, CancellationToken cancellationToken)
     {
+        var extracted = new List<string>();
         // For tar/tar.gz extraction, we'll shell out to tar command (more reliable on Linux)
         var isGzipped = tarPath.EndsWith(".gz", StringComparison.OrdinalIgnoreCase) ||
                         tarPath.EndsWith(".tgz", StringComparison.OrdinalIgnoreCase);
@@ -2825,6 +2818,7 @@ private async Task ExtractTarToModsAsync(string tarPath, string modsPath, Cancel
                     _logger,
                     cancellationToken);
 
+                extracted.Add(targetPath);
                 _logger.LogInformation("Extracted JAR: {FileName}", fileName);
             }
         }
@@ -2836,6 +2830,53 @@ private async Task ExtractTarToModsAsync(string tarPath, string modsPath, Cancel
                 Directory.Delete(tempExtractPath, recursive: true);
             }
         }
+
+        return extracted;
+    }
+
+    // Moves any jar the scanner flags into quarantine. The caller gets an
+    // InvalidOperationException naming the quarantine entries so the upload is
+    // reported as refused; clean jars from the same archive stay installed.
+    private async Task QuarantineFlaggedJarsAsync(
+        string serverName,
+        IEnumerable<string> jarPaths,
+        CancellationToken cancellationToken)
+    {
+        var quarantined = new List<QuarantinedJarDto>();
+        foreach (var jarPath in jarPaths)
+        {
+            var entry = await _jarScanService.QuarantineIfFlaggedAsync(serverName, jarPath, cancellationToken);
+            if (entry != null)
+            {
+                quarantined.Add(entry);
+            }
+        }
+
+        if (quarantined.Count > 0)
+        {
+            throw new InvalidOperationException(JarScanner.DescribeQuarantine(quarantined));
+        }
+    }
+
+    // A modpack writes configs, libraries and the loader too; only the jars the
+    // server loads from mods/ and plugins/ are scanned, as for an import.
+    private Task QuarantineFlaggedModpackJarsAsync(
+        string serverName,
+        IEnumerable<string> writtenPaths,
+        CancellationToken cancellationToken)
+    {
+        var serverPath = GetServerPath(serverName);
+        var jars = writtenPaths
+            .Where(path => IsJarFile(path) && File.Exists(path))
+            .Where(path =>
+            {
+                var relativePath = Path.GetRelativePath(serverPath, path).Replace('\\', '/');
+                return IsModPath(relativePath) ||
+                       relativePath.StartsWith("plugins/", StringComparison.OrdinalIgnoreCase);
+            })
+            .Distinct()
+            .ToList();
+        return QuarantineFlaggedJarsAsync(serverName, jars, cancellationToken);
     }
 
     private async Task<string> WriteModrinthInstallReportAsync(
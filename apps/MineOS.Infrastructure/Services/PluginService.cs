@@ -12,11 +12,16 @@ public sealed class PluginService : IPluginService
     private const string RestartFlagFile = ".mineos-restart-required";
     private readonly ILogger<PluginService> _logger;
     private readonly HostOptions _hostOptions;
+    private readonly IJarScanService _jarScanService;
 
-    public PluginService(ILogger<PluginService> logger, IOptions<HostOptions> hostOptions)
+    public PluginService(
+        ILogger<PluginService> logger,
+        IOptions<HostOptions> hostOptions,
+        IJarScanService jarScanService)
     {
         _logger = logger;
         _hostOptions = hostOptions.Value;
+        _jarScanService = jarScanService;
     }
 
     public Task<IReadOnlyList<InstalledPluginDto>> ListPluginsAsync(string serverName, CancellationToken cancellationToken)
@@ -67,8 +72,17 @@ public async Task SavePluginAsync(string serverName, string fileName, Stream con
 
         var pluginsPath = EnsurePluginsPath(serverName);
         var targetPath = Path.Combine(pluginsPath, safeName);
-        await using var target = new FileStream(targetPath, FileMode.Create, FileAccess.Write, FileShare.None);
-        await content.CopyToAsync(target, cancellationToken);
+        await using (var target = new FileStream(targetPath, FileMode.Create, FileAccess.Write, FileShare.None))
+        {
+            await content.CopyToAsync(target, cancellationToken);
+        }
+
+        var quarantined = await _jarScanService.QuarantineIfFlaggedAsync(serverName, targetPath, cancellationToken);
+        if (quarantined != null)
+        {
+            throw new InvalidOperationException(JarScanner.DescribeQuarantine(new[] { quarantined }));
+        }
+
         await OwnershipHelper.ChangeOwnershipAsync(
             targetPath,
             _hostOptions.RunAsUid,
@@ -2,7 +2,10 @@
 using System.Diagnostics;
 using System.IO.Compression;
 using System.Runtime.CompilerServices;
+using System.Security.Cryptography;
 using System.Text.Json;
+using System.Text.RegularExpressions;
+using System.Threading.Channels;
 using Microsoft.Extensions.Logging;
 using Microsoft.Extensions.Options;
 using MineOS.Application.Dtos;
@@ -19,6 +22,11 @@ public sealed class ProfileService : IProfileService
     private const string BuildToolsUrl =
         "https://hub.spigotmc.org/jenkins/job/BuildTools/lastSuccessfulBuild/artifact/target/BuildTools.jar";
     private const string RestartFlagFile = ".mineos-restart-required";
+    private const string CustomProfilePrefix = "custom-";
+    private const string CustomProfileMetadataFile = "custom-profile.json";
+    private static readonly Regex CustomProfileNamePattern = new("^[a-z0-9][a-z0-9._-]{0,47}$", RegexOptions.Compiled);
+    private static readonly Regex CustomProfileSoftwarePattern = new("^[a-z][a-z0-9-]{0,31}$", RegexOptions.Compiled);
+    private static readonly Regex CustomProfileVersionPattern = new("^[A-Za-z0-9][A-Za-z0-9._+-]{0,31}$", RegexOptions.Compiled);
     private const string PaperProjectUrl = "https://api.papermc.io/v2/projects/paper";
     private const string MojangVersionManifestUrl = "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json";
     private const int PaperVersionLimit = 20;
@@ -95,6 +103,7 @@ public async Task<IReadOnlyList<ProfileDto>> ListProfilesAsync(CancellationToken
         var paperProfiles = await GetPaperProfilesAsync(cancellationToken);
         var buildToolsProfiles = await DiscoverBuildToolsProfilesAsync(cancellationToken);
         var bedrockProfiles = await GetBedrockProfilesAsync(cancellationToken);
+        var customProfiles = await ListCustomProfilesAsync(cancellationToken);
         var combined = new Dictionary<string, ProfileDto>(StringComparer.OrdinalIgnoreCase);
 
         foreach (var profile in profiles)
@@ -133,6 +142,11 @@ public async Task<IReadOnlyList<ProfileDto>> ListProfilesAsync(CancellationToken
             combined[profile.Id] = profile;
         }
 
+        foreach (var custom in customProfiles)
+        {
+            combined[custom.Id] = ToProfileDto(custom);
+        }
+
         var ordered = combined.Values
             .OrderBy(p => p.Group)
             .ThenByDescending(p => TryParseVersion(p.Version) ?? new Version(0, 0))
@@ -178,11 +192,7 @@ public async Task<string> DownloadProfileAsync(string id, CancellationToken canc
 
         var jarPath = GetProfileJarPath(profile);
 
-        using var response = await _httpClient.GetAsync(profile.Url, HttpCompletionOption.ResponseHeadersRead, cancellationToken);
-        response.EnsureSuccessStatusCode();
-
-        await using var fileStream = new FileStream(jarPath, FileMode.Create, FileAccess.Write, FileShare.None);
-        await response.Content.CopyToAsync(fileStream, cancellationToken);
+        await ResumableDownload.DownloadAsync(_httpClient, profile.Url, jarPath, null, null, _logger, cancellationToken);
 
         _logger.LogInformation("Downloaded profile {ProfileId} to {JarPath}", id, jarPath);
 
@@ -255,7 +265,7 @@ public async Task CopyProfileToServerAsync(string profileId, string serverName,
             _hostOptions.RunAsGid,
             _logger,
             cancellationToken);
-        await UpdateServerConfigJarAsync(serverPath, jarFilename, cancellationToken);
+        await UpdateServerConfigJarAsync(serverPath, jarFilename, await GetCustomJavaBinaryAsync(profile, cancellationToken), cancellationToken);
         await MarkRestartRequiredAsync(serverPath, cancellationToken);
 
         _logger.LogInformation("Copied profile {ProfileId} to server {ServerName}", profileId, serverName);
@@ -278,32 +288,43 @@ public async Task CopyProfileToServerAsync(string profileId, string serverName,
 
         yield return new ProfileDownloadProgressDto(0, null, 0, "Starting download");
 
-        using var response = await _httpClient.GetAsync(profile.Url, HttpCompletionOption.ResponseHeadersRead, cancellationToken);
-        response.EnsureSuccessStatusCode();
-
-        var totalBytes = response.Content.Headers.ContentLength;
-
-        await using var contentStream = await response.Content.ReadAsStreamAsync(cancellationToken);
-        await using var fileStream = new FileStream(jarPath, FileMode.Create, FileAccess.Write, FileShare.None);
-
-        var buffer = new byte[8192];
+        // The download reports through a channel so its progress can be
+        // yielded; an interrupted one resumes from the part it left.
+        var url = profile.Url;
+        var updates = Channel.CreateUnbounded<ProfileDownloadProgressDto>();
         long bytesDownloaded = 0;
-
-        int bytesRead;
-        while ((bytesRead = await contentStream.ReadAsync(buffer, cancellationToken)) > 0)
+        long? totalBytes = null;
+        var download = Task.Run(async () =>
         {
-            await fileStream.WriteAsync(buffer.AsMemory(0, bytesRead), cancellationToken);
-            bytesDownloaded += bytesRead;
-
-            var percentage = totalBytes.HasValue ? (int)((bytesDownloaded * 100) / totalBytes.Value) : 0;
+            try
+            {
+                await ResumableDownload.DownloadAsync(
+                    _httpClient,
+                    url,
+                    jarPath,
+                    null,
+                    (read, total) =>
+                    {
+                        bytesDownloaded = read;
+                        totalBytes = total;
+                        var percentage = total.HasValue ? (int)((read * 100) / total.Value) : 0;
+                        updates.Writer.TryWrite(new ProfileDownloadProgressDto(read, total, percentage, "Downloading"));
+                    },
+                    _logger,
+                    cancellationToken);
+                updates.Writer.Complete();
+            }
+            catch (Exception ex)
+            {
+                updates.Writer.Complete(ex);
+            }
+        }, cancellationToken);
 
-            yield return new ProfileDownloadProgressDto(
-                bytesDownloaded,
-                totalBytes,
-                percentage,
-                "Downloading"
-            );
+        await foreach (var update in updates.Reader.ReadAllAsync(cancellationToken))
+        {
+            yield return update;
         }
+        await download;
 
         yield return new ProfileDownloadProgressDto(bytesDownloaded, totalBytes, 100, "Complete");
 
@@ -712,7 +733,215 @@ public async Task DeleteBuildToolsAsync(string id, CancellationToken cancellatio
         _logger.LogInformation("Deleted BuildTools profile {ProfileId}", id);
     }
 
-    private async Task UpdateServerConfigJarAsync(string serverPath, string jarFilename, CancellationToken cancellationToken)
+    public async Task<IReadOnlyList<CustomProfileDto>> ListCustomProfilesAsync(CancellationToken cancellationToken)
+    {
+        var results = new List<CustomProfileDto>();
+        var profilesPath = GetProfilesPath();
+        if (!Directory.Exists(profilesPath))
+        {
+            return results;
+        }
+
+        foreach (var dir in Directory.EnumerateDirectories(profilesPath, CustomProfilePrefix + "*"))
+        {
+            var metadataPath = Path.Combine(dir, CustomProfileMetadataFile);
+            if (!File.Exists(metadataPath))
+            {
+                continue;
+            }
+
+            try
+            {
+                var json = await File.ReadAllTextAsync(metadataPath, cancellationToken);
+                var profile = JsonSerializer.Deserialize<CustomProfileDto>(json, JsonOptions);
+                if (profile != null && File.Exists(Path.Combine(dir, profile.Filename)))
+                {
+                    results.Add(profile);
+                }
+            }
+            catch (JsonException ex)
+            {
+                _logger.LogWarning(ex, "Ignoring unreadable custom profile metadata {Path}", metadataPath);
+            }
+        }
+
+        return results.OrderBy(p => p.Id, StringComparer.Ordinal).ToList();
+    }
+
+    public async Task<CustomProfileDto> SaveCustomProfileAsync(
+        CustomProfileUpload upload,
+        Stream jar,
+        CancellationToken cancellationToken)
+    {
+        var name = upload.Name?.Trim().ToLowerInvariant() ?? "";
+        if (!CustomProfileNamePattern.IsMatch(name))
+        {
+            throw new ArgumentException("Profile name must be 1-48 lowercase letters, digits, '.', '-' or '_'");
+        }
+
+        var software = upload.Software?.Trim().ToLowerInvariant() ?? "";
+        if (!CustomProfileSoftwarePattern.IsMatch(software))
+        {
+            throw new ArgumentException("Software must be a lowercase name such as paper, spigot, fabric or forge");
+        }
+
+        var version = upload.Version?.Trim() ?? "";
+        if (!CustomProfileVersionPattern.IsMatch(version))
+        {
+            throw new ArgumentException("Minecraft version is required, e.g. 1.20.4");
+        }
+
+        if (upload.JavaVersion is < 8 or > 99)
+        {
+            throw new ArgumentException("Java version must be a feature release such as 8, 17 or 21");
+        }
+
+        var id = CustomProfilePrefix + name;
+        var profilePath = GetProfilePath(id);
+        var metadataPath = Path.Combine(profilePath, CustomProfileMetadataFile);
+        if (File.Exists(metadataPath) && !upload.Replace)
+        {
+            throw new InvalidOperationException($"Profile '{id}' already exists");
+        }
+
+        Directory.CreateDirectory(profilePath);
+        var filename = $"{id}.jar";
+        var jarPath = Path.Combine(profilePath, filename);
+        var tempPath = jarPath + ".upload";
+
+        long size;
+        string sha256;
+        try
+        {
+            await using (var file = new FileStream(tempPath, FileMode.Create, FileAccess.Write, FileShare.None))
+            {
+                using var hash = IncrementalHash.CreateHash(HashAlgorithmName.SHA256);
+                var buffer = new byte[81920];
+                int read;
+                while ((read = await jar.ReadAsync(buffer, cancellationToken)) > 0)
+                {
+                    hash.AppendData(buffer, 0, read);
+                    await file.WriteAsync(buffer.AsMemory(0, read), cancellationToken);
+                }
+
+                size = file.Length;
+                sha256 = Convert.ToHexString(hash.GetHashAndReset()).ToLowerInvariant();
+            }
+
+            if (!IsJar(tempPath))
+            {
+                throw new ArgumentException("Upload is not a jar file");
+            }
+
+            File.Move(tempPath, jarPath, overwrite: true);
+        }
+        finally
+        {
+            if (File.Exists(tempPath))
+            {
+                File.Delete(tempPath);
+            }
+        }
+
+        var profile = new CustomProfileDto(
+            id,
+            name,
+            software,
+            version,
+            upload.JavaVersion,
+            string.IsNullOrWhiteSpace(upload.Description) ? null : upload.Description.Trim(),
+            filename,
+            size,
+            sha256,
+            DateTimeOffset.UtcNow);
+        await File.WriteAllTextAsync(metadataPath, JsonSerializer.Serialize(profile, JsonOptions), cancellationToken);
+        OwnershipHelper.TrySetOwnership(profilePath, _hostOptions.RunAsUid, _hostOptions.RunAsGid, _logger, recursive: true);
+
+        _logger.LogInformation("Saved custom profile {ProfileId} ({Size} bytes)", id, size);
+        return profile;
+    }
+
+    public Task DeleteCustomProfileAsync(string id, CancellationToken cancellationToken)
+    {
+        if (string.IsNullOrWhiteSpace(id) ||
+            !id.StartsWith(CustomProfilePrefix, StringComparison.Ordinal) ||
+            !CustomProfileNamePattern.IsMatch(id[CustomProfilePrefix.Length..]))
+        {
+            throw new ArgumentException($"'{id}' is not a custom profile");
+        }
+
+        var profilePath = GetProfilePath(id);
+        if (!File.Exists(Path.Combine(profilePath, CustomProfileMetadataFile)))
+        {
+            throw new FileNotFoundException($"Profile '{id}' not found");
+        }
+
+        Directory.Delete(profilePath, recursive: true);
+        _logger.LogInformation("Deleted custom profile {ProfileId}", id);
+        return Task.CompletedTask;
+    }
+
+    private static ProfileDto ToProfileDto(CustomProfileDto custom) =>
+        new(
+            custom.Id,
+            custom.Software,
+            "custom",
+            custom.Version,
+            custom.UploadedAt.ToString("O"),
+            string.Empty,
+            custom.Filename,
+            true,
+            null);
+
+    // A custom jar's file name says nothing about its Minecraft version, so
+    // the Java runtime is chosen when it is copied rather than at start.
+    private async Task<string?> GetCustomJavaBinaryAsync(ProfileDto profile, CancellationToken cancellationToken)
+    {
+        if (!string.Equals(profile.Type, "custom", StringComparison.OrdinalIgnoreCase))
+        {
+            return null;
+        }
+
+        var custom = (await ListCustomProfilesAsync(cancellationToken))
+            .FirstOrDefault(p => p.Id.Equals(profile.Id, StringComparison.OrdinalIgnoreCase));
+        if (custom == null)
+        {
+            return null;
+        }
+
+        var javaBinary = custom.JavaVersion is int javaVersion
+            ? ServerService.FindJavaBinary(javaVersion)
+            : ServerService.ResolveJavaBinary(custom.Version);
+        if (javaBinary == "java")
+        {
+            _logger.LogWarning(
+                "No Java {JavaVersion} runtime found for custom profile {ProfileId}; using the default java",
+                custom.JavaVersion?.ToString() ?? "for MC " + custom.Version,
+                custom.Id);
+            return null;
+        }
+
+        return javaBinary;
+    }
+
+    private static bool IsJar(string path)
+    {
+        try
+        {
+            using var archive = ZipFile.OpenRead(path);
+            return archive.Entries.Count > 0;
+        }
+        catch (InvalidDataException)
+        {
+            return false;
+        }
+    }
+
+    private async Task UpdateServerConfigJarAsync(
+        string serverPath,
+        string jarFilename,
+        string? javaBinary,
+        CancellationToken cancellationToken)
     {
         var configPath = Path.Combine(serverPath, "server.config");
         if (!File.Exists(configPath))
@@ -730,6 +959,10 @@ private async Task UpdateServerConfigJarAsync(string serverPath, string jarFilen
         }
 
         javaSection["jarfile"] = jarFilename;
+        if (!string.IsNullOrEmpty(javaBinary))
+        {
+            javaSection["java_binary"] = javaBinary;
+        }
 
         var updated = IniParser.WriteWithSections(sections);
         await File.WriteAllTextAsync(configPath, updated, cancellationToken);
@@ -1,5 +1,8 @@
 using System.Diagnostics;
 using System.Security;
+using System.Security.Cryptography;
+using System.Text.Json;
+using System.Text.RegularExpressions;
 using Microsoft.Extensions.Logging;
 using Microsoft.Extensions.Options;
 using MineOS.Application.Dtos;
@@ -15,6 +18,17 @@ public class ServerService : IServerService
 {
     private const string RestartFlagFile = ".mineos-restart-required";
     private const string StopRequestedFlagFile = ".mineos-stop-requested";
+    private const string EnvironmentFile = ".mineos-environment.json";
+    private static readonly Regex EnvironmentNamePattern = new(@"^[A-Za-z_][A-Za-z0-9_]*$", RegexOptions.Compiled);
+    private static readonly Regex SystemPropertyNamePattern = new(@"^[A-Za-z0-9_][A-Za-z0-9_.\-]*$", RegexOptions.Compiled);
+    // Set by MineOS itself at start; an override would break process tracking
+    private static readonly HashSet<string> ReservedEnvironment = new(StringComparer.Ordinal) { "MINEOS_SERVER", "LD_LIBRARY_PATH" };
+    private static readonly HashSet<string> ReservedSystemProperties = new(StringComparer.Ordinal) { "mineos.server" };
+    private static readonly JsonSerializerOptions EnvironmentJsonOptions = new()
+    {
+        PropertyNamingPolicy = JsonNamingPolicy.CamelCase,
+        WriteIndented = true
+    };
     private readonly IProcessManager _processManager;
     private readonly HostOptions _options;
     private readonly ILogger<ServerService> _logger;
@@ -76,7 +90,7 @@ public static string ResolveJavaBinary(string? minecraftVersion)
         return "java";
     }
 
-    private static string FindJavaBinary(params int[] preferredVersions)
+    internal static string FindJavaBinary(params int[] preferredVersions)
     {
         foreach (var ver in preferredVersions)
         {
@@ -88,6 +102,8 @@ private static string FindJavaBinary(params int[] preferredVersions)
                 $"/usr/lib/jvm/temurin-{ver}-jdk/bin/java",
                 $"/usr/lib/jvm/java-{ver}-openjdk-amd64/bin/java",
                 $"/usr/lib/jvm/java-{ver}-openjdk/bin/java",
+                // Installed with 'mineos java install'
+                $"/var/games/minecraft/.runtimes/java/temurin-{ver}/bin/java",
             };
 
             foreach (var path in paths)
@@ -106,6 +122,9 @@ private static string FindJavaBinary(params int[] preferredVersions)
     private string GetConfigPath(string name) =>
         Path.Combine(GetServerPath(name), "server.config");
 
+    private string GetEnvironmentPath(string name) =>
+        Path.Combine(GetServerPath(name), EnvironmentFile);
+
     private string GetServerTypePath(string name) =>
         Path.Combine(GetServerPath(name), ServerTypeFile);
 
@@ -577,6 +596,10 @@ public async Task StartServerAsync(string name, CancellationToken cancellationTo
         var startupStamp = $"[{startTime:O}] Launching {name}";
         var startupLogArg = EscapeBashArgument(startupLogPath);
         var escapedServerPath = EscapeBashArgument(serverPath);
+        var environment = await GetServerEnvironmentAsync(name, cancellationToken);
+        var exports = string.Concat(environment.Environment
+            .OrderBy(pair => pair.Key, StringComparer.Ordinal)
+            .Select(pair => $"export {EscapeBashArgument($"{pair.Key}={pair.Value}")}; "));
 
         if (serverType == "bedrock")
         {
@@ -609,7 +632,7 @@ public async Task StartServerAsync(string name, CancellationToken cancellationTo
             // Bedrock needs LD_LIBRARY_PATH set to its own directory
             var ldPath = $"LD_LIBRARY_PATH={escapedServerPath}";
             var envVar = $"MINEOS_SERVER={EscapeBashArgument(name)}";
-            shellCommand = $"cd {escapedServerPath} && echo {EscapeBashArgument(startupStamp)} >> {startupLogArg}; export {ldPath}; export {envVar}; exec ./bedrock_server >> {startupLogArg} 2>&1";
+            shellCommand = $"cd {escapedServerPath} && echo {EscapeBashArgument(startupStamp)} >> {startupLogArg}; {exports}export {ldPath}; export {envVar}; exec ./bedrock_server >> {startupLogArg} 2>&1";
         }
         else
         {
@@ -691,6 +714,11 @@ public async Task StartServerAsync(string name, CancellationToken cancellationTo
                 $"-Dmineos.server={name}"
             };
 
+            foreach (var (key, value) in environment.SystemProperties.OrderBy(pair => pair.Key, StringComparer.Ordinal))
+            {
+                javaArgs.Add($"-D{key}={value}");
+            }
+
             if (config.Java.JavaXmx > 0)
                 javaArgs.Add($"-Xmx{config.Java.JavaXmx}M");
             if (config.Java.JavaXms > 0)
@@ -722,7 +750,7 @@ public async Task StartServerAsync(string name, CancellationToken cancellationTo
 
             var javaCommand = string.Join(" ", javaArgs.Select(EscapeBashArgument));
             _logger.LogInformation("Java command: {JavaCommand}", javaCommand);
-            shellCommand = $"cd {escapedServerPath} && echo {EscapeBashArgument(startupStamp)} >> {startupLogArg}; exec {javaCommand} >> {startupLogArg} 2>&1";
+            shellCommand = $"cd {escapedServerPath} && echo {EscapeBashArgument(startupStamp)} >> {startupLogArg}; {exports}exec {javaCommand} >> {startupLogArg} 2>&1";
         }
 
         var args = new List<string>
@@ -977,6 +1005,149 @@ public async Task UpdateServerConfigAsync(string name, ServerConfigDto config, C
         _logger.LogInformation("Updated server.config for {ServerName}", name);
     }
 
+    public async Task<ServerEnvironmentDto> GetServerEnvironmentAsync(string name, CancellationToken cancellationToken)
+    {
+        var serverPath = GetServerPath(name);
+        if (!Directory.Exists(serverPath))
+        {
+            throw new DirectoryNotFoundException($"Server '{name}' not found");
+        }
+
+        var path = GetEnvironmentPath(name);
+        if (!File.Exists(path))
+        {
+            return new ServerEnvironmentDto(new Dictionary<string, string>(), new Dictionary<string, string>());
+        }
+
+        await using var stream = File.OpenRead(path);
+        var stored = await JsonSerializer.DeserializeAsync<ServerEnvironmentDto>(stream, EnvironmentJsonOptions, cancellationToken);
+        return new ServerEnvironmentDto(
+            stored?.Environment ?? new Dictionary<string, string>(),
+            stored?.SystemProperties ?? new Dictionary<string, string>());
+    }
+
+    public async Task UpdateServerEnvironmentAsync(string name, ServerEnvironmentDto environment, CancellationToken cancellationToken)
+    {
+        var existing = await GetServerEnvironmentAsync(name, cancellationToken);
+        var variables = environment.Environment ?? new Dictionary<string, string>();
+        var properties = environment.SystemProperties ?? new Dictionary<string, string>();
+
+        foreach (var key in variables.Keys)
+        {
+            if (!EnvironmentNamePattern.IsMatch(key))
+                throw new InvalidOperationException($"'{key}' is not a valid environment variable name.");
+            if (ReservedEnvironment.Contains(key))
+                throw new InvalidOperationException($"{key} is set by MineOS and cannot be overridden.");
+        }
+        foreach (var key in properties.Keys)
+        {
+            if (!SystemPropertyNamePattern.IsMatch(key))
+                throw new InvalidOperationException($"'{key}' is not a valid system property name.");
+            if (ReservedSystemProperties.Contains(key))
+                throw new InvalidOperationException($"{key} is set by MineOS and cannot be overridden.");
+        }
+        if (variables.Values.Concat(properties.Values).Any(value => value is null || value.Contains('\n') || value.Contains('\0')))
+        {
+            throw new InvalidOperationException("Values must be set and fit on one line.");
+        }
+
+        var path = GetEnvironmentPath(name);
+        if (variables.Count == 0 && properties.Count == 0)
+        {
+            if (File.Exists(path))
+                File.Delete(path);
+        }
+        else
+        {
+            var content = JsonSerializer.Serialize(new ServerEnvironmentDto(variables, properties), EnvironmentJsonOptions);
+            await File.WriteAllTextAsync(path, content, cancellationToken);
+            await OwnershipHelper.ChangeOwnershipAsync(path, _options.RunAsUid, _options.RunAsGid, _logger, cancellationToken);
+        }
+
+        var changed = !SameEntries(existing.Environment, variables) || !SameEntries(existing.SystemProperties, properties);
+        if (changed && await _processManager.IsServerRunningAsync(name, cancellationToken))
+        {
+            await MarkRestartRequiredAsync(name);
+        }
+
+        _logger.LogInformation("Updated environment for {ServerName}: {VariableCount} variables, {PropertyCount} system properties",
+            name, variables.Count, properties.Count);
+    }
+
+    private static bool SameEntries(Dictionary<string, string> left, Dictionary<string, string> right) =>
+        left.Count == right.Count && left.All(pair => right.TryGetValue(pair.Key, out var value) && value == pair.Value);
+
+    public async Task<ServerIntegrityDto> GetServerFileHashesAsync(string name, CancellationToken cancellationToken)
+    {
+        var serverPath = GetServerPath(name);
+        if (!Directory.Exists(serverPath))
+        {
+            throw new DirectoryNotFoundException($"Server '{name}' not found");
+        }
+
+        var files = new List<ServerFileHashDto>();
+        string? jarFile = null;
+        if (File.Exists(GetConfigPath(name)))
+        {
+            var config = await GetServerConfigAsync(name, cancellationToken);
+            jarFile = string.IsNullOrWhiteSpace(config.Java.JarFile) ? null : config.Java.JarFile;
+        }
+
+        if (jarFile != null)
+        {
+            var jarPath = Path.GetFullPath(Path.Combine(serverPath, jarFile));
+            if (jarPath.StartsWith(Path.GetFullPath(serverPath) + Path.DirectorySeparatorChar, StringComparison.Ordinal) &&
+                File.Exists(jarPath))
+            {
+                files.Add(await HashServerFileAsync(serverPath, jarPath, "server", cancellationToken));
+            }
+        }
+
+        foreach (var (directory, kind) in new[] { ("mods", "mod"), ("plugins", "plugin") })
+        {
+            var path = Path.Combine(serverPath, directory);
+            if (!Directory.Exists(path))
+            {
+                continue;
+            }
+
+            foreach (var file in Directory.EnumerateFiles(path, "*.jar").OrderBy(f => f, StringComparer.Ordinal))
+            {
+                files.Add(await HashServerFileAsync(serverPath, file, kind, cancellationToken));
+            }
+        }
+
+        return new ServerIntegrityDto(jarFile, files);
+    }
+
+    private static async Task<ServerFileHashDto> HashServerFileAsync(
+        string serverPath,
+        string path,
+        string kind,
+        CancellationToken cancellationToken)
+    {
+        using var sha1 = IncrementalHash.CreateHash(HashAlgorithmName.SHA1);
+        using var sha256 = IncrementalHash.CreateHash(HashAlgorithmName.SHA256);
+        using var sha512 = IncrementalHash.CreateHash(HashAlgorithmName.SHA512);
+        await using var stream = new FileStream(path, FileMode.Open, FileAccess.Read, FileShare.ReadWrite);
+        var buffer = new byte[81920];
+        int read;
+        while ((read = await stream.ReadAsync(buffer, cancellationToken)) > 0)
+        {
+            sha1.AppendData(buffer, 0, read);
+            sha256.AppendData(buffer, 0, read);
+            sha512.AppendData(buffer, 0, read);
+        }
+
+        return new ServerFileHashDto(
+            Path.GetRelativePath(serverPath, path).Replace(Path.DirectorySeparatorChar, '/'),
+            kind,
+            stream.Length,
+            Convert.ToHexString(sha1.GetHashAndReset()).ToLowerInvariant(),
+            Convert.ToHexString(sha256.GetHashAndReset()).ToLowerInvariant(),
+            Convert.ToHexString(sha512.GetHashAndReset()).ToLowerInvariant());
+    }
+
     public async Task AcceptEulaAsync(string name, CancellationToken cancellationToken)
     {
         var serverPath = GetServerPath(name);
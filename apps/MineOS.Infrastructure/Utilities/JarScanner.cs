@@ -0,0 +1,340 @@
+using System.IO.Compression;
+using System.Security.Cryptography;
+using System.Text;
+using MineOS.Application.Dtos;
+
+namespace MineOS.Infrastructure.Utilities;
+
+/// <summary>
+/// Static scanner for mod and plugin jars. Reads the constant pool of every class
+/// and matches it against known malware signatures and a few heuristics that rarely
+/// show up in legitimate mods. A jar is flagged on any high severity finding or on
+/// two or more distinct medium severity findings within one archive: the jar's own
+/// classes, or one of its nested jars. Libraries bundled as nested jars are judged
+/// on their own, so unrelated capabilities of a large modpack jar do not add up.
+/// </summary>
+public static class JarScanner
+{
+    public const string SeverityHigh = "high";
+    public const string SeverityMedium = "medium";
+
+    private const int MaxClassBytes = 4 * 1024 * 1024;
+    private const int MaxNestedJarBytes = 64 * 1024 * 1024;
+    private const int MaxNestingDepth = 2;
+    private const int ObfuscationMinClasses = 20;
+    private const int MediumFindingsToFlag = 2;
+
+    // Package prefixes of known malware families (fractureiser, etc.).
+    private static readonly string[] MaliciousPackages =
+    {
+        "dev/neko/nekoclient",
+        "dev/neko/nekoinjector",
+    };
+
+    // Command-and-control hosts seen in malicious Minecraft jars.
+    private static readonly string[] MaliciousHosts =
+    {
+        "files-8ie.pages.dev",
+        "85.217.144.130",
+        "107.189.3.101",
+        "skyrage.de",
+    };
+
+    private static readonly string[] PersistencePaths =
+    {
+        "CurrentVersion\\Run",
+        "Start Menu\\Programs\\Startup",
+    };
+
+    private static readonly string[] DiscordWebhooks =
+    {
+        "discord.com/api/webhooks",
+        "discordapp.com/api/webhooks",
+    };
+
+    public static JarScanReportDto Scan(string jarPath)
+    {
+        var findings = new List<JarScanFindingDto>();
+        var flagged = false;
+        string sha256;
+
+        using (var stream = new FileStream(jarPath, FileMode.Open, FileAccess.Read, FileShare.Read))
+        {
+            sha256 = Convert.ToHexString(SHA256.HashData(stream)).ToLowerInvariant();
+            stream.Position = 0;
+
+            try
+            {
+                using var archive = new ZipArchive(stream, ZipArchiveMode.Read);
+                flagged = ScanArchive(archive, string.Empty, 0, findings);
+            }
+            catch (InvalidDataException)
+            {
+                // Not a zip at all; nothing to inspect, and the server will refuse to load it.
+            }
+        }
+
+        var unique = findings
+            .GroupBy(f => f.Rule)
+            .Select(g => g.First())
+            .OrderBy(f => f.Severity == SeverityHigh ? 0 : 1)
+            .ThenBy(f => f.Rule, StringComparer.Ordinal)
+            .ToList();
+
+        return new JarScanReportDto(Path.GetFileName(jarPath), sha256, flagged, unique);
+    }
+
+    /// <summary>
+    /// Builds the error returned to the user when an install was refused because
+    /// one or more jars were quarantined.
+    /// </summary>
+    public static string DescribeQuarantine(IReadOnlyList<QuarantinedJarDto> entries)
+    {
+        var parts = entries.Select(e =>
+            $"{e.FileName} ({string.Join(", ", e.Report.Findings.Select(f => f.Rule))}) as {e.Id}");
+        return $"Quarantined {string.Join("; ", parts)}. " +
+               "Review the findings and release trusted jars with 'mineos quarantine release <id>'.";
+    }
+
+    /// <summary>
+    /// Scans an archive and the jars nested in it, adding every finding to
+    /// <paramref name="findings"/>. Returns whether this archive or a nested one
+    /// is flagged on its own findings.
+    /// </summary>
+    private static bool ScanArchive(ZipArchive archive, string prefix, int depth, List<JarScanFindingDto> findings)
+    {
+        var classCount = 0;
+        var obfuscatedCount = 0;
+        var own = new List<JarScanFindingDto>();
+        var flagged = false;
+
+        foreach (var entry in archive.Entries)
+        {
+            var entryName = prefix + entry.FullName;
+
+            if (entry.FullName.EndsWith(".class", StringComparison.OrdinalIgnoreCase))
+            {
+                classCount++;
+                if (LooksObfuscated(entry.FullName))
+                {
+                    obfuscatedCount++;
+                }
+
+                foreach (var package in MaliciousPackages)
+                {
+                    if (entry.FullName.StartsWith(package, StringComparison.Ordinal))
+                    {
+                        own.Add(new JarScanFindingDto(SeverityHigh, "known-malware",
+                            $"Contains classes from the {package.Replace('/', '.')} malware family", entryName));
+                    }
+                }
+
+                if (entry.Length is > 0 and <= MaxClassBytes)
+                {
+                    var strings = ReadConstantPool(entry);
+                    if (strings != null)
+                    {
+                        ScanClass(strings, entryName, own);
+                    }
+                }
+            }
+            else if (depth < MaxNestingDepth &&
+                     entry.FullName.EndsWith(".jar", StringComparison.OrdinalIgnoreCase) &&
+                     entry.Length is > 0 and <= MaxNestedJarBytes)
+            {
+                try
+                {
+                    using var buffer = new MemoryStream();
+                    using (var nested = entry.Open())
+                    {
+                        nested.CopyTo(buffer);
+                    }
+                    buffer.Position = 0;
+                    using var nestedArchive = new ZipArchive(buffer, ZipArchiveMode.Read);
+                    flagged |= ScanArchive(nestedArchive, entryName + "!/", depth + 1, findings);
+                }
+                catch (InvalidDataException)
+                {
+                }
+            }
+        }
+
+        if (classCount >= ObfuscationMinClasses && obfuscatedCount * 2 > classCount)
+        {
+            own.Add(new JarScanFindingDto(SeverityMedium, "obfuscation",
+                $"{obfuscatedCount} of {classCount} classes have obfuscated names",
+                string.IsNullOrEmpty(prefix) ? null : prefix.TrimEnd('/', '!')));
+        }
+
+        findings.AddRange(own);
+        return flagged ||
+               own.Any(f => f.Severity == SeverityHigh) ||
+               own.Where(f => f.Severity == SeverityMedium).Select(f => f.Rule).Distinct().Count() >= MediumFindingsToFlag;
+    }
+
+    private static void ScanClass(IReadOnlyList<string> strings, string entry, List<JarScanFindingDto> findings)
+    {
+        bool Has(string value) => strings.Any(s => s.Contains(value, StringComparison.Ordinal));
+        bool HasIgnoreCase(string value) => strings.Any(s => s.Contains(value, StringComparison.OrdinalIgnoreCase));
+
+        foreach (var package in MaliciousPackages)
+        {
+            if (Has(package))
+            {
+                findings.Add(new JarScanFindingDto(SeverityHigh, "known-malware",
+                    $"References the {package.Replace('/', '.')} malware family", entry));
+            }
+        }
+
+        foreach (var host in MaliciousHosts)
+        {
+            if (Has(host))
+            {
+                findings.Add(new JarScanFindingDto(SeverityHigh, "malicious-host",
+                    $"Contacts known malware host {host}", entry));
+            }
+        }
+
+        if (Has("Local Storage") && HasIgnoreCase("leveldb") && HasIgnoreCase("discord"))
+        {
+            findings.Add(new JarScanFindingDto(SeverityHigh, "token-grabber",
+                "Reads Discord's local storage database", entry));
+        }
+
+        foreach (var path in PersistencePaths)
+        {
+            if (HasIgnoreCase(path))
+            {
+                findings.Add(new JarScanFindingDto(SeverityHigh, "persistence",
+                    $"Writes to the Windows autostart location '{path}'", entry));
+            }
+        }
+
+        if (Has("java/lang/ProcessBuilder") || (Has("java/lang/Runtime") && strings.Contains("exec")))
+        {
+            findings.Add(new JarScanFindingDto(SeverityMedium, "process-exec",
+                "Spawns external processes", entry));
+        }
+
+        if (Has("java/net/URLClassLoader") && (Has("http://") || Has("https://")))
+        {
+            findings.Add(new JarScanFindingDto(SeverityMedium, "remote-classloading",
+                "Loads classes from a remote URL", entry));
+        }
+
+        if (strings.Contains("defineClass") && Has("java/util/Base64"))
+        {
+            findings.Add(new JarScanFindingDto(SeverityMedium, "encoded-classes",
+                "Defines classes from Base64-encoded data", entry));
+        }
+
+        foreach (var webhook in DiscordWebhooks)
+        {
+            if (Has(webhook))
+            {
+                findings.Add(new JarScanFindingDto(SeverityMedium, "discord-webhook",
+                    "Posts data to a Discord webhook", entry));
+            }
+        }
+    }
+
+    private static bool LooksObfuscated(string classPath)
+    {
+        var name = Path.GetFileNameWithoutExtension(classPath);
+        var dollar = name.IndexOf('$');
+        if (dollar > 0)
+        {
+            name = name[..dollar];
+        }
+
+        if (name is "package-info" or "module-info")
+        {
+            return false;
+        }
+
+        if (name.Length <= 2)
+        {
+            return true;
+        }
+
+        // Names built only from look-alike characters (IlIlIl, O0O0) or non-ASCII.
+        return name.All(c => c is 'I' or 'l' or '1' or 'O' or '0' or '_') || name.Any(c => c > 127);
+    }
+
+    /// <summary>
+    /// Returns the UTF-8 constants of a class file, or null when the entry is not a
+    /// class file that can be parsed.
+    /// </summary>
+    private static IReadOnlyList<string>? ReadConstantPool(ZipArchiveEntry entry)
+    {
+        byte[] data;
+        try
+        {
+            using var buffer = new MemoryStream((int)entry.Length);
+            using (var stream = entry.Open())
+            {
+                stream.CopyTo(buffer);
+            }
+            data = buffer.ToArray();
+        }
+        catch (InvalidDataException)
+        {
+            return null;
+        }
+
+        if (data.Length < 10 || data[0] != 0xCA || data[1] != 0xFE || data[2] != 0xBA || data[3] != 0xBE)
+        {
+            return null;
+        }
+
+        var strings = new List<string>();
+        var count = ReadU2(data, 8);
+        var offset = 10;
+
+        for (var i = 1; i < count; i++)
+        {
+            if (offset >= data.Length)
+            {
+                return strings;
+            }
+
+            var tag = data[offset++];
+            switch (tag)
+            {
+                case 1: // Utf8
+                    if (offset + 2 > data.Length)
+                    {
+                        return strings;
+                    }
+                    var length = ReadU2(data, offset);
+                    offset += 2;
+                    if (offset + length > data.Length)
+                    {
+                        return strings;
+                    }
+                    strings.Add(Encoding.UTF8.GetString(data, offset, length));
+                    offset += length;
+                    break;
+                case 7: case 8: case 16: case 19: case 20: // Class, String, MethodType, Module, Package
+                    offset += 2;
+                    break;
+                case 15: // MethodHandle
+                    offset += 3;
+                    break;
+                case 3: case 4: case 9: case 10: case 11: case 12: case 17: case 18:
+                    offset += 4;
+                    break;
+                case 5: case 6: // Long and Double take two slots
+                    offset += 8;
+                    i++;
+                    break;
+                default:
+                    return strings;
+            }
+        }
+
+        return strings;
+    }
+
+    private static int ReadU2(byte[] data, int offset) => (data[offset] << 8) | data[offset + 1];
+}
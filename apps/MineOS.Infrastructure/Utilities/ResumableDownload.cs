@@ -0,0 +1,245 @@
+using System.Globalization;
+using System.Net;
+using System.Net.Http.Headers;
+using System.Security.Cryptography;
+using System.Text.Json;
+using Microsoft.Extensions.Logging;
+
+namespace MineOS.Infrastructure.Utilities;
+
+/// <summary>
+/// Downloads large files with HTTP range requests. The transfer goes to
+/// target.part, with a target.part.json checkpoint of how many bytes it holds
+/// and their SHA-256, so a dropped connection or a later attempt continues
+/// from the last checkpoint instead of starting over. The part is renamed to
+/// the target once it is complete.
+/// </summary>
+public static class ResumableDownload
+{
+    // How often the checkpoint is written while bytes arrive.
+    private const long CheckpointEvery = 4L << 20;
+
+    private static readonly JsonSerializerOptions JsonOptions = new(JsonSerializerDefaults.Web);
+
+    private sealed record Checkpoint(
+        string Url,
+        string? ETag,
+        string? LastModified,
+        long Size,
+        long Bytes,
+        string Sha256);
+
+    public static async Task DownloadAsync(
+        HttpClient httpClient,
+        string url,
+        string targetPath,
+        Action<HttpRequestMessage>? configureRequest,
+        Action<long, long?>? progressCallback,
+        ILogger logger,
+        CancellationToken cancellationToken)
+    {
+        var partPath = targetPath + ".part";
+        var checkpointPath = partPath + ".json";
+        var (state, hash) = await LoadCheckpointAsync(url, partPath, checkpointPath, cancellationToken);
+        try
+        {
+            using var request = new HttpRequestMessage(HttpMethod.Get, url);
+            configureRequest?.Invoke(request);
+            if (state.Bytes > 0)
+            {
+                request.Headers.Range = new RangeHeaderValue(state.Bytes, null);
+                if (!string.IsNullOrEmpty(state.ETag) && EntityTagHeaderValue.TryParse(state.ETag, out var etag))
+                {
+                    request.Headers.IfRange = new RangeConditionHeaderValue(etag);
+                }
+                else if (DateTimeOffset.TryParse(state.LastModified, CultureInfo.InvariantCulture, DateTimeStyles.AssumeUniversal, out var modified))
+                {
+                    request.Headers.IfRange = new RangeConditionHeaderValue(modified);
+                }
+            }
+
+            using var response = await httpClient.SendAsync(
+                request,
+                HttpCompletionOption.ResponseHeadersRead,
+                cancellationToken);
+
+            if (response.StatusCode == HttpStatusCode.PartialContent && state.Bytes > 0)
+            {
+                logger.LogInformation("Resuming download of {Url} at byte {Offset}", url, state.Bytes);
+            }
+            else if (response.StatusCode == HttpStatusCode.RequestedRangeNotSatisfiable && state.Bytes > 0 && state.Bytes == state.Size)
+            {
+                // The part was already complete.
+                Finish(partPath, checkpointPath, targetPath);
+                return;
+            }
+            else if (response.IsSuccessStatusCode && response.StatusCode != HttpStatusCode.PartialContent)
+            {
+                // A fresh download, or the server ignored the range or the file changed.
+                hash.Dispose();
+                hash = IncrementalHash.CreateHash(HashAlgorithmName.SHA256);
+                state = new Checkpoint(
+                    url,
+                    response.Headers.ETag?.ToString(),
+                    response.Content.Headers.LastModified?.ToString("R", CultureInfo.InvariantCulture),
+                    response.Content.Headers.ContentLength ?? 0,
+                    0,
+                    string.Empty);
+            }
+            else
+            {
+                if (response.StatusCode == HttpStatusCode.RequestedRangeNotSatisfiable)
+                {
+                    // The checkpoint no longer fits the remote file; start over next time.
+                    TryDelete(partPath);
+                    TryDelete(checkpointPath);
+                }
+                var body = await response.Content.ReadAsStringAsync(cancellationToken);
+                logger.LogError("Download failed for URL: {Url} - Status: {StatusCode} - Body: {Body}",
+                    url, (int)response.StatusCode, body);
+                throw new HttpRequestException(
+                    $"Download failed ({(int)response.StatusCode}) for URL {url}: {body}",
+                    null,
+                    response.StatusCode);
+            }
+
+            await using (var target = new FileStream(partPath, state.Bytes == 0 ? FileMode.Create : FileMode.OpenOrCreate, FileAccess.Write, FileShare.None))
+            {
+                target.SetLength(state.Bytes);
+                target.Seek(state.Bytes, SeekOrigin.Begin);
+                await using var source = await response.Content.ReadAsStreamAsync(cancellationToken);
+
+                var buffer = new byte[81920];
+                long sinceCheckpoint = 0;
+                progressCallback?.Invoke(state.Bytes, Total(state));
+                try
+                {
+                    int read;
+                    while ((read = await source.ReadAsync(buffer, cancellationToken)) > 0)
+                    {
+                        await target.WriteAsync(buffer.AsMemory(0, read), cancellationToken);
+                        hash.AppendData(buffer, 0, read);
+                        state = state with { Bytes = state.Bytes + read };
+                        progressCallback?.Invoke(state.Bytes, Total(state));
+
+                        sinceCheckpoint += read;
+                        if (sinceCheckpoint >= CheckpointEvery)
+                        {
+                            sinceCheckpoint = 0;
+                            await target.FlushAsync(cancellationToken);
+                            await SaveCheckpointAsync(checkpointPath, state, hash);
+                        }
+                    }
+                }
+                catch
+                {
+                    await target.FlushAsync(CancellationToken.None);
+                    await SaveCheckpointAsync(checkpointPath, state, hash);
+                    throw;
+                }
+            }
+
+            if (state.Size > 0 && state.Bytes != state.Size)
+            {
+                await SaveCheckpointAsync(checkpointPath, state, hash);
+                throw new IOException($"Download ended early: got {state.Bytes} of {state.Size} bytes");
+            }
+            Finish(partPath, checkpointPath, targetPath);
+        }
+        finally
+        {
+            hash.Dispose();
+        }
+    }
+
+    private static long? Total(Checkpoint state) => state.Size > 0 ? state.Size : null;
+
+    // Loads the checkpoint for url and re-hashes the recorded prefix of the
+    // part file. Anything that does not line up starts over.
+    private static async Task<(Checkpoint State, IncrementalHash Hash)> LoadCheckpointAsync(
+        string url,
+        string partPath,
+        string checkpointPath,
+        CancellationToken cancellationToken)
+    {
+        var fresh = new Checkpoint(url, null, null, 0, 0, string.Empty);
+        var hash = IncrementalHash.CreateHash(HashAlgorithmName.SHA256);
+        if (!File.Exists(checkpointPath) || !File.Exists(partPath))
+        {
+            return (fresh, hash);
+        }
+
+        try
+        {
+            var state = JsonSerializer.Deserialize<Checkpoint>(
+                await File.ReadAllTextAsync(checkpointPath, cancellationToken), JsonOptions);
+            if (state == null || state.Url != url || state.Bytes <= 0)
+            {
+                return (fresh, hash);
+            }
+
+            await using var part = new FileStream(partPath, FileMode.Open, FileAccess.Read, FileShare.Read);
+            if (part.Length < state.Bytes)
+            {
+                return (fresh, hash);
+            }
+            var buffer = new byte[81920];
+            var remaining = state.Bytes;
+            while (remaining > 0)
+            {
+                var read = await part.ReadAsync(buffer.AsMemory(0, (int)Math.Min(buffer.Length, remaining)), cancellationToken);
+                if (read == 0)
+                {
+                    break;
+                }
+                hash.AppendData(buffer, 0, read);
+                remaining -= read;
+            }
+            if (remaining == 0 && Hex(hash).Equals(state.Sha256, StringComparison.OrdinalIgnoreCase))
+            {
+                return (state, hash);
+            }
+        }
+        catch (JsonException)
+        {
+        }
+        catch (IOException)
+        {
+        }
+
+        hash.Dispose();
+        return (fresh, IncrementalHash.CreateHash(HashAlgorithmName.SHA256));
+    }
+
+    private static async Task SaveCheckpointAsync(string path, Checkpoint state, IncrementalHash hash)
+    {
+        try
+        {
+            await File.WriteAllTextAsync(path, JsonSerializer.Serialize(state with { Sha256 = Hex(hash) }, JsonOptions));
+        }
+        catch (IOException)
+        {
+            // Without a checkpoint the next attempt starts over.
+        }
+    }
+
+    private static string Hex(IncrementalHash hash) => Convert.ToHexString(hash.GetCurrentHash()).ToLowerInvariant();
+
+    private static void Finish(string partPath, string checkpointPath, string targetPath)
+    {
+        File.Move(partPath, targetPath, overwrite: true);
+        TryDelete(checkpointPath);
+    }
+
+    private static void TryDelete(string path)
+    {
+        try
+        {
+            File.Delete(path);
+        }
+        catch (IOException)
+        {
+            // Ignore cleanup failures.
+        }
+    }
+}
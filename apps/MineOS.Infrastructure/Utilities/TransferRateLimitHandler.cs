@@ -0,0 +1,51 @@
+using System.Net.Http.Headers;
+
+namespace MineOS.Infrastructure.Utilities;
+
+/// <summary>
+/// Passes the request and response bodies of an HttpClient through the shared
+/// <see cref="TransferRateLimiter"/>.
+/// </summary>
+public sealed class TransferRateLimitHandler : DelegatingHandler
+{
+    private readonly TransferRateLimiter _limiter;
+
+    public TransferRateLimitHandler(TransferRateLimiter limiter)
+    {
+        _limiter = limiter;
+    }
+
+    protected override async Task<HttpResponseMessage> SendAsync(
+        HttpRequestMessage request,
+        CancellationToken cancellationToken)
+    {
+        if (!_limiter.Enabled)
+        {
+            return await base.SendAsync(request, cancellationToken);
+        }
+
+        if (request.Content != null)
+        {
+            request.Content = await LimitAsync(request.Content, cancellationToken);
+        }
+        var response = await base.SendAsync(request, cancellationToken);
+        response.Content = await LimitAsync(response.Content, cancellationToken);
+        return response;
+    }
+
+    private async Task<HttpContent> LimitAsync(HttpContent content, CancellationToken cancellationToken)
+    {
+        var stream = await content.ReadAsStreamAsync(cancellationToken);
+        var limited = new StreamContent(_limiter.Wrap(stream), _limiter.ChunkSize);
+        CopyHeaders(content.Headers, limited.Headers);
+        return limited;
+    }
+
+    private static void CopyHeaders(HttpContentHeaders from, HttpContentHeaders to)
+    {
+        foreach (var header in from)
+        {
+            to.TryAddWithoutValidation(header.Key, header.Value);
+        }
+    }
+}
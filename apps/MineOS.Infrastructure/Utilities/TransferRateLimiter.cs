@@ -0,0 +1,163 @@
+using System.Diagnostics;
+using System.Globalization;
+using Microsoft.Extensions.Configuration;
+
+namespace MineOS.Infrastructure.Utilities;
+
+/// <summary>
+/// A token bucket shared by every download the API makes (profiles, mods and
+/// modpacks), so transfers running side by side stay under MINEOS_RATE_LIMIT
+/// together instead of each getting the full rate.
+/// </summary>
+public sealed class TransferRateLimiter
+{
+    public const string ConfigKey = "MINEOS_RATE_LIMIT";
+
+    private readonly object _lock = new();
+    private long _nextTimestamp;
+
+    public TransferRateLimiter(IConfiguration configuration)
+        : this(Parse(configuration[ConfigKey]))
+    {
+    }
+
+    public TransferRateLimiter(long bytesPerSecond)
+    {
+        BytesPerSecond = Math.Max(bytesPerSecond, 0);
+    }
+
+    public long BytesPerSecond { get; }
+
+    public bool Enabled => BytesPerSecond > 0;
+
+    // Small chunks keep the rate smooth and let parallel transfers take turns.
+    internal int ChunkSize => (int)Math.Clamp(BytesPerSecond / 10, 1024, 1024 * 1024);
+
+    /// <summary>
+    /// Reads rates like curl's --limit-rate: bytes per second with an optional
+    /// K, M or G suffix (powers of 1024), e.g. "500K" or "5M". Empty or "0"
+    /// means unlimited.
+    /// </summary>
+    public static long Parse(string? raw)
+    {
+        var value = (raw ?? string.Empty).Trim().ToUpperInvariant();
+        if (value.EndsWith("/S", StringComparison.Ordinal))
+        {
+            value = value[..^2];
+        }
+        if (value.EndsWith('B'))
+        {
+            value = value[..^1];
+        }
+        if (value.Length == 0)
+        {
+            return 0;
+        }
+
+        var multiplier = value[^1] switch
+        {
+            'K' => 1L << 10,
+            'M' => 1L << 20,
+            'G' => 1L << 30,
+            _ => 1L
+        };
+        if (multiplier > 1)
+        {
+            value = value[..^1];
+        }
+        if (!double.TryParse(value, NumberStyles.Float, CultureInfo.InvariantCulture, out var number) || number < 0)
+        {
+            throw new FormatException($"Invalid {ConfigKey} \"{raw}\" (use e.g. 500K or 5M)");
+        }
+        return (long)(number * multiplier);
+    }
+
+    /// <summary>
+    /// Reserves bytes from the bucket and waits until it has paid for them.
+    /// Idle time is not saved up, so there is no burst after a pause.
+    /// </summary>
+    public Task WaitAsync(int bytes, CancellationToken cancellationToken)
+    {
+        if (!Enabled || bytes <= 0)
+        {
+            return Task.CompletedTask;
+        }
+
+        TimeSpan delay;
+        lock (_lock)
+        {
+            var now = Stopwatch.GetTimestamp();
+            if (_nextTimestamp < now)
+            {
+                _nextTimestamp = now;
+            }
+            _nextTimestamp += (long)((double)bytes / BytesPerSecond * Stopwatch.Frequency);
+            delay = Stopwatch.GetElapsedTime(now, _nextTimestamp);
+        }
+        return delay > TimeSpan.Zero ? Task.Delay(delay, cancellationToken) : Task.CompletedTask;
+    }
+
+    /// <summary>
+    /// Wraps a stream so reads from it draw from this bucket.
+    /// </summary>
+    public Stream Wrap(Stream stream) => Enabled ? new ThrottledStream(stream, this) : stream;
+
+    private sealed class ThrottledStream : Stream
+    {
+        private readonly Stream _inner;
+        private readonly TransferRateLimiter _limiter;
+
+        public ThrottledStream(Stream inner, TransferRateLimiter limiter)
+        {
+            _inner = inner;
+            _limiter = limiter;
+        }
+
+        public override bool CanRead => _inner.CanRead;
+        public override bool CanSeek => false;
+        public override bool CanWrite => false;
+        public override long Length => _inner.Length;
+
+        public override long Position
+        {
+            get => _inner.Position;
+            set => throw new NotSupportedException();
+        }
+
+        public override int Read(byte[] buffer, int offset, int count)
+        {
+            var read = _inner.Read(buffer, offset, Math.Min(count, _limiter.ChunkSize));
+            _limiter.WaitAsync(read, CancellationToken.None).GetAwaiter().GetResult();
+            return read;
+        }
+
+        public override async ValueTask<int> ReadAsync(Memory<byte> buffer, CancellationToken cancellationToken = default)
+        {
+            var read = await _inner.ReadAsync(buffer[..Math.Min(buffer.Length, _limiter.ChunkSize)], cancellationToken);
+            await _limiter.WaitAsync(read, cancellationToken);
+            return read;
+        }
+
+        public override Task<int> ReadAsync(byte[] buffer, int offset, int count, CancellationToken cancellationToken) =>
+            ReadAsync(buffer.AsMemory(offset, count), cancellationToken).AsTask();
+
+        public override void Flush()
+        {
+        }
+
+        public override long Seek(long offset, SeekOrigin origin) => throw new NotSupportedException();
+        public override void SetLength(long value) => throw new NotSupportedException();
+        public override void Write(byte[] buffer, int offset, int count) => throw new NotSupportedException();
+
+        protected override void Dispose(bool disposing)
+        {
+            if (disposing)
+            {
+                _inner.Dispose();
+            }
+            base.Dispose(disposing);
+        }
+
+        public override ValueTask DisposeAsync() => _inner.DisposeAsync();
+    }
+}
@@ -0,0 +1,142 @@
+using System.IO.Compression;
+using System.Text;
+using MineOS.Application.Dtos;
+using MineOS.Infrastructure.Utilities;
+
+namespace MineOS.Tests.Unit;
+
+public class JarScannerTests : IDisposable
+{
+    // Constant pool strings that trip one medium rule each.
+    private static readonly string[] ProcessExec = { "java/lang/ProcessBuilder" };
+    private static readonly string[] DiscordWebhook = { "https://discord.com/api/webhooks/1/x" };
+    private static readonly string[] MalwareHost = { "https://files-8ie.pages.dev/x" };
+
+    private readonly string _dir = Path.Combine(Path.GetTempPath(), "mineos-jarscan-" + Guid.NewGuid().ToString("N"));
+
+    public JarScannerTests() => Directory.CreateDirectory(_dir);
+
+    public void Dispose() => Directory.Delete(_dir, recursive: true);
+
+    [Fact]
+    public void Clean_Jar_Is_Not_Flagged()
+    {
+        var report = Scan(Jar(("com/example/Mod.class", ClassFile("java/lang/Object"))));
+
+        Assert.False(report.Flagged);
+        Assert.Empty(report.Findings);
+    }
+
+    [Fact]
+    public void Two_Medium_Rules_In_One_Jar_Are_Flagged()
+    {
+        var report = Scan(Jar(
+            ("com/example/Updater.class", ClassFile(ProcessExec)),
+            ("com/example/Reporter.class", ClassFile(DiscordWebhook))));
+
+        Assert.True(report.Flagged);
+        Assert.Equal(new[] { "discord-webhook", "process-exec" }, report.Findings.Select(f => f.Rule));
+    }
+
+    [Fact]
+    public void One_Medium_Rule_Is_Not_Flagged()
+    {
+        var report = Scan(Jar(
+            ("com/example/A.class", ClassFile(ProcessExec)),
+            ("com/example/B.class", ClassFile(ProcessExec))));
+
+        Assert.False(report.Flagged);
+        Assert.Single(report.Findings);
+    }
+
+    [Fact]
+    public void Medium_Rules_In_Separate_Nested_Jars_Do_Not_Add_Up()
+    {
+        var report = Scan(Jar(
+            ("META-INF/jars/launcher.jar", Jar(("org/launcher/Exec.class", ClassFile(ProcessExec)))),
+            ("META-INF/jars/reporter.jar", Jar(("org/reporter/Hook.class", ClassFile(DiscordWebhook))))));
+
+        Assert.False(report.Flagged);
+        Assert.Equal(2, report.Findings.Count);
+    }
+
+    [Fact]
+    public void Medium_Rules_In_The_Jar_And_A_Nested_Jar_Do_Not_Add_Up()
+    {
+        var report = Scan(Jar(
+            ("com/example/Updater.class", ClassFile(ProcessExec)),
+            ("META-INF/jars/reporter.jar", Jar(("org/reporter/Hook.class", ClassFile(DiscordWebhook))))));
+
+        Assert.False(report.Flagged);
+    }
+
+    [Fact]
+    public void Two_Medium_Rules_In_One_Nested_Jar_Are_Flagged()
+    {
+        var report = Scan(Jar(
+            ("com/example/Mod.class", ClassFile("java/lang/Object")),
+            ("META-INF/jars/payload.jar", Jar(
+                ("org/payload/Exec.class", ClassFile(ProcessExec)),
+                ("org/payload/Hook.class", ClassFile(DiscordWebhook))))));
+
+        Assert.True(report.Flagged);
+    }
+
+    [Fact]
+    public void High_Rule_In_A_Nested_Jar_Is_Flagged()
+    {
+        var report = Scan(Jar(
+            ("META-INF/jars/lib.jar", Jar(("org/lib/Loader.class", ClassFile(MalwareHost))))));
+
+        Assert.True(report.Flagged);
+        var finding = Assert.Single(report.Findings);
+        Assert.Equal("malicious-host", finding.Rule);
+        Assert.Equal("META-INF/jars/lib.jar!/org/lib/Loader.class", finding.Entry);
+    }
+
+    private JarScanReportDto Scan(byte[] jar)
+    {
+        var path = Path.Combine(_dir, "mod.jar");
+        File.WriteAllBytes(path, jar);
+        return JarScanner.Scan(path);
+    }
+
+    private static byte[] Jar(params (string Name, byte[] Data)[] entries)
+    {
+        using var buffer = new MemoryStream();
+        using (var archive = new ZipArchive(buffer, ZipArchiveMode.Create, leaveOpen: true))
+        {
+            foreach (var (name, data) in entries)
+            {
+                using var stream = archive.CreateEntry(name).Open();
+                stream.Write(data);
+            }
+        }
+        return buffer.ToArray();
+    }
+
+    /// <summary>
+    /// A class file header followed by a constant pool of Utf8 entries, which is all
+    /// the scanner reads.
+    /// </summary>
+    private static byte[] ClassFile(params string[] constants)
+    {
+        using var buffer = new MemoryStream();
+        buffer.Write(new byte[] { 0xCA, 0xFE, 0xBA, 0xBE, 0, 0, 0, 52 });
+        WriteU2(buffer, constants.Length + 1);
+        foreach (var constant in constants)
+        {
+            var bytes = Encoding.UTF8.GetBytes(constant);
+            buffer.WriteByte(1);
+            WriteU2(buffer, bytes.Length);
+            buffer.Write(bytes);
+        }
+        return buffer.ToArray();
+    }
+
+    private static void WriteU2(Stream stream, int value)
+    {
+        stream.WriteByte((byte)(value >> 8));
+        stream.WriteByte((byte)value);
+    }
+}
@@ -0,0 +1,51 @@
+using System.Diagnostics;
+using MineOS.Infrastructure.Utilities;
+
+namespace MineOS.Tests.Unit;
+
+public class TransferRateLimiterTests
+{
+    [Theory]
+    [InlineData("", 0)]
+    [InlineData("0", 0)]
+    [InlineData("2048", 2048)]
+    [InlineData("500K", 500 * 1024)]
+    [InlineData("5m", 5 * 1024 * 1024)]
+    [InlineData("1.5MB/s", 3 * 512 * 1024)]
+    [InlineData("1G", 1024L * 1024 * 1024)]
+    public void Parse_Reads_Curl_Style_Rates(string raw, long expected)
+    {
+        Assert.Equal(expected, TransferRateLimiter.Parse(raw));
+    }
+
+    [Theory]
+    [InlineData("fast")]
+    [InlineData("-5M")]
+    public void Parse_Rejects_Invalid_Rates(string raw)
+    {
+        Assert.Throws<FormatException>(() => TransferRateLimiter.Parse(raw));
+    }
+
+    [Fact]
+    public async Task Parallel_Transfers_Share_One_Bucket()
+    {
+        var limiter = new TransferRateLimiter(100 * 1024);
+        var watch = Stopwatch.StartNew();
+
+        // Two readers of 20K each at 100K/s together take at least 0.4s; with
+        // a bucket each they would finish in half that.
+        await Task.WhenAll(
+            Drain(limiter.Wrap(new MemoryStream(new byte[20 * 1024]))),
+            Drain(limiter.Wrap(new MemoryStream(new byte[20 * 1024]))));
+
+        Assert.True(watch.Elapsed >= TimeSpan.FromMilliseconds(350), $"took {watch.Elapsed}");
+    }
+
+    private static async Task Drain(Stream stream)
+    {
+        await using (stream)
+        {
+            await stream.CopyToAsync(Stream.Null);
+        }
+    }
+}
@@ -123,3 +123,21 @@ export function formatPercent(value: number | null | undefined, decimals = 0): s
 	if (value == null) return '0%';
 	return `${value.toFixed(decimals)}%`;
 }
+
+/**
+ * Join a host and port for display, bracketing IPv6 literals.
+ * A host that already carries a port is returned unchanged.
+ * @param host - Hostname, IPv4 or IPv6 address (bracketed or not)
+ * @param port - Port number
+ * @returns Address like "play.example.com:25565" or "[2001:db8::1]:25565"
+ */
+export function formatHostPort(host: string, port: number | string): string {
+	const value = host.trim();
+	if (value.startsWith('[')) {
+		return value.includes(']:') ? value : `${value}:${port}`;
+	}
+	const colons = value.split(':').length - 1;
+	if (colons > 1) return `[${value}]:${port}`;
+	if (colons === 1) return value;
+	return `${value}:${port}`;
+}
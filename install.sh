@@ -215,6 +215,11 @@ install_cli() {
         if [ -f "$tmp_dir/cli/$bin_name" ]; then
             mv "$tmp_dir/cli/$bin_name" "./mineos"
             chmod +x "./mineos"
+            if [ "$os" = "darwin" ]; then
+                # A bundle downloaded with a browser carries the quarantine
+                # flag, and Gatekeeper refuses to run the unsigned binary.
+                xattr -d com.apple.quarantine "./mineos" 2>/dev/null || true
+            fi
             echo "[INFO] Installed mineos-cli to ${INSTALL_DIR}/mineos"
         else
             echo "[WARN] mineos-cli binary not found in archive."
@@ -236,8 +241,13 @@ run_cli_installer() {
     fi
 
     # Redirect stdin from /dev/tty so CLI can read interactive input
-    # even when this script is piped (curl | bash)
-    "$cli" install "$@" < /dev/tty
+    # even when this script is piped (curl | bash). Unattended runs
+    # (cloud-init, CI) have no terminal and pass --quiet.
+    if (: < /dev/tty) 2>/dev/null; then
+        "$cli" install "$@" < /dev/tty
+    else
+        "$cli" install "$@"
+    fi
 }
 
 while [ $# -gt 0 ]; do
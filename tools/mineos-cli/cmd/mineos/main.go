@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
@@ -18,6 +19,15 @@ func main() {
 	}
 
 	if err := application.Run(); err != nil {
+		// Some commands report a result through the exit status, e.g. diff
+		// exits with 8 when the installation drifted from its manifest.
+		var coded interface{ ExitCode() int }
+		if errors.As(err, &coded) {
+			if msg := err.Error(); msg != "" {
+				fmt.Fprintln(os.Stderr, msg)
+			}
+			os.Exit(coded.ExitCode())
+		}
 		exitWithError(err)
 	}
 }
@@ -0,0 +1,90 @@
+package apitest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/spf13/cobra"
+)
+
+// UpdateGoldenEnv rewrites golden files instead of comparing against them
+// when set to 1, e.g. MINEOS_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "MINEOS_UPDATE_GOLDEN"
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// StripANSI removes terminal escape sequences so snapshots do not depend on
+// the color profile of the machine running the tests.
+func StripANSI(value string) string {
+	return ansiPattern.ReplaceAllString(value, "")
+}
+
+// AssertGolden compares got with testdata/<name>.golden in the test's
+// package directory.
+func AssertGolden(t testing.TB, name, got string) {
+	t.Helper()
+	got = StripANSI(got)
+	path := filepath.Join("testdata", name+".golden")
+
+	if os.Getenv(UpdateGoldenEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create testdata: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden %s: %v (run with %s=1 to create it)", path, err, UpdateGoldenEnv)
+	}
+	if !bytes.Equal(want, []byte(got)) {
+		t.Errorf("output does not match %s (run with %s=1 to update)\n--- want\n%s\n--- got\n%s", path, UpdateGoldenEnv, want, got)
+	}
+}
+
+// RunCommand executes a cobra command with args and returns everything it
+// wrote to stdout and stderr.
+func RunCommand(t testing.TB, cmd *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	cmd.SilenceUsage = true
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+// ViewWidth and ViewHeight are the terminal size RenderView gives a model.
+const ViewWidth, ViewHeight = 80, 24
+
+// RenderView runs model in a teatest program with a ViewWidth x ViewHeight
+// terminal and returns its final view. It waits until the output shows
+// until, so the commands Init started have landed, then sends msgs and
+// quits; commands those messages return are not waited for.
+func RenderView(t testing.TB, model tea.Model, until string, msgs ...tea.Msg) string {
+	t.Helper()
+	tm := teatest.NewTestModel(t, model, teatest.WithInitialTermSize(ViewWidth, ViewHeight))
+	if until != "" {
+		teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+			return strings.Contains(StripANSI(string(out)), until)
+		}, teatest.WithDuration(5*time.Second))
+	}
+	for _, msg := range msgs {
+		tm.Send(msg)
+	}
+	if err := tm.Quit(); err != nil {
+		t.Fatalf("quit program: %v", err)
+	}
+	return tm.FinalModel(t, teatest.WithFinalTimeout(5*time.Second)).View()
+}
@@ -0,0 +1,723 @@
+// Package apitest provides an in-memory fake of the MineOS API and helpers
+// for snapshot-testing command output and TUI views without a Docker stack.
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+const DefaultApiKey = "test-api-key"
+
+// ServerState is the fake's view of one Minecraft server.
+type ServerState struct {
+	Detail      ports.ServerDetail
+	Properties  map[string]string
+	Config      ports.ServerConfig
+	Files       map[string]string
+	Performance ports.PerformanceSample
+	History     []ports.PerformanceSample
+	Memory      ports.MemoryInfo
+	Worlds      []ports.World
+	Players     []ports.PlayerSummary
+	Sessions    []ports.PlayerSession
+	Console     []string
+	Logs        []ports.LogEntry
+}
+
+// Request records a call made against the fake.
+type Request struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+type Server struct {
+	*httptest.Server
+
+	ApiKey string
+
+	mu        sync.Mutex
+	servers   map[string]*ServerState
+	profiles  []ports.Profile
+	modrinth  []modrinthProject
+	jobs      map[string]ports.JobStatus
+	settings  map[string]string
+	requests  []Request
+	overrides map[string]http.HandlerFunc
+	nextJob   int
+}
+
+// NewServer starts a fake API that is shut down when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		ApiKey:    DefaultApiKey,
+		servers:   map[string]*ServerState{},
+		jobs:      map[string]ports.JobStatus{},
+		settings:  map[string]string{},
+		overrides: map[string]http.HandlerFunc{},
+	}
+	s.Server = httptest.NewServer(s.routes())
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Client returns an API client pointed at the fake.
+func (s *Server) Client() *api.Client {
+	return api.NewClient(s.URL, s.ApiKey)
+}
+
+// Config returns a CLI config whose API port targets the fake.
+func (s *Server) Config() config.Config {
+	parsed, _ := url.Parse(s.URL)
+	return config.Config{
+		ApiPort:          parsed.Port(),
+		ManagementApiKey: s.ApiKey,
+		NetworkMode:      "bridge",
+	}
+}
+
+// LoadConfig returns a use case that always yields Config, for wiring
+// commands against the fake.
+func (s *Server) LoadConfig() *usecases.LoadConfigUseCase {
+	return usecases.NewLoadConfigUseCase(&configRepository{cfg: s.Config()})
+}
+
+// AddServer registers a server. Empty fields get sensible defaults.
+func (s *Server) AddServer(state ServerState) *ServerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state.Detail.Status == "" {
+		state.Detail.Status = "stopped"
+	}
+	if state.Detail.ServerType == "" {
+		state.Detail.ServerType = "java"
+	}
+	if state.Properties == nil {
+		state.Properties = map[string]string{}
+	}
+	if state.Files == nil {
+		state.Files = map[string]string{}
+	}
+	state.Performance.ServerName = state.Detail.Name
+	copied := state
+	s.servers[state.Detail.Name] = &copied
+	return &copied
+}
+
+// State returns the live state of a server so tests can assert on it.
+func (s *Server) State(name string) (*ServerState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.servers[name]
+	return state, ok
+}
+
+func (s *Server) AddProfile(profile ports.Profile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profiles = append(s.profiles, profile)
+}
+
+type modrinthProject struct {
+	project  ports.ModrinthProject
+	versions []ports.ModrinthVersion
+}
+
+// AddModrinthProject makes a project and its versions available to the
+// mods and plugins Modrinth search routes.
+func (s *Server) AddModrinthProject(project ports.ModrinthProject, versions ...ports.ModrinthVersion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.modrinth = append(s.modrinth, modrinthProject{project: project, versions: versions})
+}
+
+// Handle overrides a route, e.g. Handle("POST /servers/lobby/actions/start", h).
+// Paths are relative to /api/v1.
+func (s *Server) Handle(pattern string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[pattern] = handler
+}
+
+// Fail makes a route respond with the given status and error message.
+func (s *Server) Fail(pattern string, status int, message string) {
+	s.Handle(pattern, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, status, map[string]string{"error": message})
+	})
+}
+
+// Requests returns the calls received so far, excluding health checks.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Setting returns a value stored through the settings endpoint.
+func (s *Server) Setting(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.settings[key]
+	return value, ok
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/health", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "healthy"})
+	})
+
+	mux.HandleFunc("GET /api/v1/servers/list", s.listServers)
+	mux.HandleFunc("POST /api/v1/servers", s.createServer)
+	mux.HandleFunc("POST /api/v1/servers/actions/stop-all", s.stopAll)
+	mux.HandleFunc("GET /api/v1/servers/{name}", s.withServer(func(w http.ResponseWriter, _ *http.Request, state *ServerState) {
+		writeJSON(w, http.StatusOK, state.Detail)
+	}))
+	mux.HandleFunc("POST /api/v1/servers/{name}/eula", s.withServer(func(w http.ResponseWriter, _ *http.Request, state *ServerState) {
+		state.Detail.EulaAccepted = true
+		state.Files["eula.txt"] = "eula=true\n"
+		writeJSON(w, http.StatusOK, map[string]string{"message": "EULA accepted"})
+	}))
+	mux.HandleFunc("POST /api/v1/servers/{name}/actions/{action}", s.withServer(s.serverAction))
+	mux.HandleFunc("POST /api/v1/servers/{name}/console", s.withServer(func(w http.ResponseWriter, r *http.Request, state *ServerState) {
+		var body struct {
+			Command string `json:"command"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		state.Console = append(state.Console, body.Command)
+		writeJSON(w, http.StatusOK, map[string]string{"message": "sent"})
+	}))
+	mux.HandleFunc("GET /api/v1/servers/{name}/console/stream", s.withServer(s.streamConsole))
+	mux.HandleFunc("GET /api/v1/servers/{name}/performance/realtime", s.withServer(func(w http.ResponseWriter, _ *http.Request, state *ServerState) {
+		sample := state.Performance
+		sample.IsRunning = state.Detail.IsRunning()
+		writeJSON(w, http.StatusOK, sample)
+	}))
+	mux.HandleFunc("GET /api/v1/servers/{name}/ping", s.withServer(func(w http.ResponseWriter, _ *http.Request, state *ServerState) {
+		if !state.Detail.IsRunning() {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "server is not responding"})
+			return
+		}
+		writeJSON(w, http.StatusOK, ports.PingInfo{
+			ServerVersion: "1.21",
+			Motd:          "A Minecraft Server",
+			PlayersOnline: state.Performance.PlayerCount,
+			PlayersMax:    20,
+		})
+	}))
+	mux.HandleFunc("GET /api/v1/servers/{name}/performance/history", s.withServer(func(w http.ResponseWriter, _ *http.Request, state *ServerState) {
+		writeJSON(w, http.StatusOK, nonNil(state.History))
+	}))
+	mux.HandleFunc("GET /api/v1/servers/{name}/memory", s.withServer(func(w http.ResponseWriter, _ *http.Request, state *ServerState) {
+		writeJSON(w, http.StatusOK, state.Memory)
+	}))
+	mux.HandleFunc("GET /api/v1/servers/{name}/worlds", s.withServer(func(w http.ResponseWriter, _ *http.Request, state *ServerState) {
+		writeJSON(w, http.StatusOK, nonNil(state.Worlds))
+	}))
+	mux.HandleFunc("GET /api/v1/servers/{name}/files/{path...}", s.withServer(s.readFile))
+	mux.HandleFunc("PUT /api/v1/servers/{name}/files/{path...}", s.withServer(s.writeFile))
+	mux.HandleFunc("POST /api/v1/servers/{name}/files/{path...}", s.withServer(s.writeFile))
+	mux.HandleFunc("GET /api/v1/servers/{name}/plugins", s.withServer(s.listJars("plugins")))
+	mux.HandleFunc("GET /api/v1/servers/{name}/mods", s.withServer(s.listJars("mods")))
+	for _, kind := range []string{"mods", "plugins"} {
+		mux.HandleFunc("GET /api/v1/servers/{name}/"+kind+"/modrinth/search", s.withServer(s.searchModrinth))
+		mux.HandleFunc("GET /api/v1/servers/{name}/"+kind+"/modrinth/project/{id}/versions", s.withServer(s.modrinthVersions))
+		mux.HandleFunc("POST /api/v1/servers/{name}/"+kind+"/modrinth/install", s.withServer(s.installModrinth(kind)))
+		mux.HandleFunc("DELETE /api/v1/servers/{name}/"+kind+"/{filename}", s.withServer(func(w http.ResponseWriter, r *http.Request, state *ServerState) {
+			filePath := kind + "/" + r.PathValue("filename")
+			if _, ok := state.Files[filePath]; !ok {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "File not found"})
+				return
+			}
+			delete(state.Files, filePath)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+	}
+	mux.HandleFunc("POST /api/v1/servers/{name}/plugins/upload", s.withServer(s.uploadPlugin))
+	mux.HandleFunc("POST /api/v1/servers/{name}/icon", s.withServer(func(w http.ResponseWriter, r *http.Request, state *ServerState) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil || len(data) == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "File must be a valid image (PNG, JPG, etc.)"})
+			return
+		}
+		state.Files["server-icon.png"] = string(data)
+		writeJSON(w, http.StatusOK, map[string]string{"message": "Server icon uploaded successfully"})
+	}))
+	mux.HandleFunc("GET /api/v1/servers/{name}/players", s.withServer(func(w http.ResponseWriter, _ *http.Request, state *ServerState) {
+		writeJSON(w, http.StatusOK, map[string]any{"data": state.Players})
+	}))
+	mux.HandleFunc("GET /api/v1/servers/{name}/players/sessions", s.withServer(s.listSessions))
+	mux.HandleFunc("GET /api/v1/servers/{name}/players/{uuid}/sessions", s.withServer(s.listSessions))
+	mux.HandleFunc("POST /api/v1/servers/{name}/players/activity/process", s.withServer(func(w http.ResponseWriter, _ *http.Request, _ *ServerState) {
+		writeJSON(w, http.StatusOK, map[string]string{"message": "Log processing completed"})
+	}))
+	mux.HandleFunc("GET /api/v1/servers/{name}/server-properties", s.withServer(func(w http.ResponseWriter, _ *http.Request, state *ServerState) {
+		writeJSON(w, http.StatusOK, state.Properties)
+	}))
+	mux.HandleFunc("PUT /api/v1/servers/{name}/server-properties", s.withServer(func(w http.ResponseWriter, r *http.Request, state *ServerState) {
+		props := map[string]string{}
+		if err := json.NewDecoder(r.Body).Decode(&props); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		state.Properties = props
+		writeJSON(w, http.StatusOK, map[string]string{"message": "updated"})
+	}))
+	mux.HandleFunc("GET /api/v1/servers/{name}/server-config", s.withServer(func(w http.ResponseWriter, _ *http.Request, state *ServerState) {
+		writeJSON(w, http.StatusOK, state.Config)
+	}))
+	mux.HandleFunc("PUT /api/v1/servers/{name}/server-config", s.withServer(func(w http.ResponseWriter, r *http.Request, state *ServerState) {
+		var cfg ports.ServerConfig
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		state.Config = cfg
+		writeJSON(w, http.StatusOK, map[string]string{"message": "updated"})
+	}))
+
+	mux.HandleFunc("GET /api/v1/host/profiles", func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		writeJSON(w, http.StatusOK, nonNil(s.profiles))
+	})
+	mux.HandleFunc("POST /api/v1/host/profiles/{id}/download", s.downloadProfile)
+	mux.HandleFunc("POST /api/v1/host/profiles/{id}/copy-to-server", s.copyProfile)
+	mux.HandleFunc("POST /api/v1/host/imports/{filename}/create-server", s.importServer)
+	mux.HandleFunc("PUT /api/v1/settings/{key}", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Value string `json:"value"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.settings[r.PathValue("key")] = body.Value
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]string{"message": "Setting '" + r.PathValue("key") + "' updated"})
+	})
+	mux.HandleFunc("GET /api/v1/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		job, ok := s.jobs[r.PathValue("id")]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, job)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1")
+		if path != "/health" {
+			if r.Header.Get("X-Api-Key") != s.ApiKey {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid api key"})
+				return
+			}
+			s.record(r, path)
+		}
+
+		s.mu.Lock()
+		override := s.overrides[r.Method+" "+path]
+		s.mu.Unlock()
+		if override != nil {
+			override(w, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) record(r *http.Request, path string) {
+	var body []byte
+	if r.Body != nil {
+		body, _ = io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: path, Body: string(body)})
+	s.mu.Unlock()
+}
+
+// withServer resolves {name} and holds the lock while the handler runs.
+func (s *Server) withServer(handler func(http.ResponseWriter, *http.Request, *ServerState)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		state, ok := s.servers[r.PathValue("name")]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("Server '%s' not found", r.PathValue("name"))})
+			return
+		}
+		handler(w, r, state)
+	}
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request, state *ServerState) {
+	limit := 50
+	if value, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && value > 0 {
+		limit = value
+	}
+	uuid := r.PathValue("uuid")
+	sessions := []ports.PlayerSession{}
+	for _, session := range state.Sessions {
+		if uuid == "" || session.PlayerUUID == uuid {
+			sessions = append(sessions, session)
+		}
+	}
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": sessions})
+}
+
+func (s *Server) listServers(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	servers := make([]ports.Server, 0, len(s.servers))
+	for _, state := range s.servers {
+		servers = append(servers, ports.Server{Name: state.Detail.Name, Status: state.Detail.Status})
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+	writeJSON(w, http.StatusOK, servers)
+}
+
+func (s *Server) createServer(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name       string `json:"name"`
+		ServerType string `json:"serverType"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name is required"})
+		return
+	}
+	if _, exists := s.State(body.Name); exists {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": fmt.Sprintf("Server '%s' already exists", body.Name)})
+		return
+	}
+	state := s.AddServer(ServerState{Detail: ports.ServerDetail{Name: body.Name, ServerType: body.ServerType}})
+	s.mu.Lock()
+	state.Properties["server-port"] = fmt.Sprint(25565 + len(s.servers) - 1)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusCreated, state.Detail)
+}
+
+func (s *Server) serverAction(w http.ResponseWriter, r *http.Request, state *ServerState) {
+	switch action := r.PathValue("action"); action {
+	case "start", "restart":
+		if !state.Detail.IsBedrock() && !state.Detail.EulaAccepted {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "EULA not accepted"})
+			return
+		}
+		state.Detail.Status = "running"
+	case "stop", "kill":
+		state.Detail.Status = "stopped"
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown action " + action})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "ok"})
+}
+
+func (s *Server) stopAll(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := ports.StopAllResult{Total: len(s.servers)}
+	names := make([]string, 0, len(s.servers))
+	for name := range s.servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		state := s.servers[name]
+		item := ports.StopAllItem{Name: name, Status: "skipped"}
+		if state.Detail.IsRunning() {
+			result.Running++
+			result.Stopped++
+			state.Detail.Status = "stopped"
+			item.Status = "stopped"
+		} else {
+			result.Skipped++
+		}
+		result.Results = append(result.Results, item)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) streamConsole(w http.ResponseWriter, _ *http.Request, state *ServerState) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	for _, entry := range state.Logs {
+		payload, _ := json.Marshal(entry)
+		fmt.Fprintf(w, "data: %s\n\n", payload)
+	}
+}
+
+func (s *Server) readFile(w http.ResponseWriter, r *http.Request, state *ServerState) {
+	filePath := r.PathValue("path")
+	content, ok := state.Files[filePath]
+	if !ok {
+		if entries := directoryEntries(state, filePath); len(entries) > 0 {
+			writeJSON(w, http.StatusOK, map[string]any{"path": filePath, "kind": "directory", "entries": entries})
+			return
+		}
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "File not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"path": filePath,
+		"kind": "file",
+		"file": map[string]any{"path": filePath, "content": content, "size": len(content), "modified": time.Now().UTC()},
+	})
+}
+
+// directoryEntries lists the files and subdirectories directly inside dir.
+func directoryEntries(state *ServerState, dir string) []ports.FileEntry {
+	prefix := strings.Trim(dir, "/") + "/"
+	seen := map[string]bool{}
+	entries := []ports.FileEntry{}
+	for filePath, content := range state.Files {
+		rest, ok := strings.CutPrefix(filePath, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		name, _, nested := strings.Cut(rest, "/")
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		entry := ports.FileEntry{Name: name, IsDirectory: nested, Modified: time.Now().UTC()}
+		if !nested {
+			entry.Size = int64(len(content))
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+func (s *Server) writeFile(w http.ResponseWriter, r *http.Request, state *ServerState) {
+	var body struct {
+		Content       string `json:"content"`
+		ContentBase64 string `json:"contentBase64"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if body.ContentBase64 != "" {
+		data, err := base64.StdEncoding.DecodeString(body.ContentBase64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid base64 content: " + err.Error()})
+			return
+		}
+		body.Content = string(data)
+	}
+	state.Files[r.PathValue("path")] = body.Content
+	writeJSON(w, http.StatusOK, map[string]string{"message": "saved"})
+}
+
+// listPlugins reports the jars stored under plugins/ in the server's files.
+// listJars serves the plugins or mods listing, derived from jar files in
+// that directory.
+func (s *Server) listJars(dir string) func(http.ResponseWriter, *http.Request, *ServerState) {
+	return func(w http.ResponseWriter, _ *http.Request, state *ServerState) {
+		jars := []ports.InstalledPlugin{}
+		for filePath, content := range state.Files {
+			parent, file := path.Split(filePath)
+			if parent == dir+"/" && (strings.HasSuffix(file, ".jar") || strings.HasSuffix(file, ".jar.disabled")) {
+				jars = append(jars, ports.InstalledPlugin{
+					FileName:   file,
+					SizeBytes:  int64(len(content)),
+					ModifiedAt: time.Now().UTC(),
+					IsDisabled: strings.HasSuffix(file, ".disabled"),
+				})
+			}
+		}
+		sort.Slice(jars, func(i, j int) bool { return jars[i].FileName < jars[j].FileName })
+		writeJSON(w, http.StatusOK, jars)
+	}
+}
+
+func (s *Server) searchModrinth(w http.ResponseWriter, r *http.Request, _ *ServerState) {
+	query := strings.ToLower(r.URL.Query().Get("query"))
+	hits := []ports.ModrinthProject{}
+	for _, entry := range s.modrinth {
+		if strings.Contains(strings.ToLower(entry.project.Slug), query) || strings.Contains(strings.ToLower(entry.project.Title), query) {
+			hits = append(hits, entry.project)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"index": 0, "pageSize": len(hits), "totalHits": len(hits), "results": hits})
+}
+
+// modrinthVersions accepts a project id or slug, like Modrinth.
+func (s *Server) modrinthVersions(w http.ResponseWriter, r *http.Request, _ *ServerState) {
+	for _, entry := range s.modrinth {
+		if entry.project.ProjectID == r.PathValue("id") || entry.project.Slug == r.PathValue("id") {
+			writeJSON(w, http.StatusOK, entry.versions)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, []ports.ModrinthVersion{})
+}
+
+func (s *Server) installModrinth(kind string) func(http.ResponseWriter, *http.Request, *ServerState) {
+	return func(w http.ResponseWriter, r *http.Request, state *ServerState) {
+		var body struct {
+			VersionID string `json:"versionId"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for _, entry := range s.modrinth {
+			for _, version := range entry.versions {
+				if version.ID != body.VersionID || len(version.Files) == 0 {
+					continue
+				}
+				file := version.Files[0]
+				for _, candidate := range version.Files {
+					if candidate.Primary {
+						file = candidate
+					}
+				}
+				state.Files[kind+"/"+file.FileName] = "jar"
+				writeJSON(w, http.StatusOK, map[string]string{"message": "Installed '" + file.FileName + "'"})
+				return
+			}
+		}
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Version not found"})
+	}
+}
+
+func (s *Server) uploadPlugin(w http.ResponseWriter, r *http.Request, state *ServerState) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Plugin file is required"})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	state.Files["plugins/"+header.Filename] = string(data)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Uploaded plugin '" + header.Filename + "'"})
+}
+
+func (s *Server) downloadProfile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.profiles {
+		if s.profiles[i].Id == r.PathValue("id") {
+			s.profiles[i].Downloaded = true
+			writeJSON(w, http.StatusOK, map[string]string{"message": "downloaded"})
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"error": "Profile not found"})
+}
+
+func (s *Server) copyProfile(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ServerName string `json:"serverName"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.servers[body.ServerName]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Server not found"})
+		return
+	}
+	for _, profile := range s.profiles {
+		if profile.Id != r.PathValue("id") {
+			continue
+		}
+		if !profile.Downloaded {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Profile JAR not downloaded"})
+			return
+		}
+		jar := profile.Filename
+		state.Config.Java.JarFile = &jar
+		state.Detail.NeedsRestart = true
+		writeJSON(w, http.StatusOK, map[string]string{"message": "copied"})
+		return
+	}
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Profile not found"})
+}
+
+// importServer creates the server immediately and reports a completed job.
+func (s *Server) importServer(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ServerName string `json:"serverName"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	s.AddServer(ServerState{Detail: ports.ServerDetail{Name: body.ServerName}})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextJob++
+	id := fmt.Sprintf("job-%d", s.nextJob)
+	now := time.Now().UTC()
+	s.jobs[id] = ports.JobStatus{
+		JobId:       id,
+		Type:        "import",
+		ServerName:  body.ServerName,
+		Status:      "completed",
+		Percentage:  100,
+		Message:     "Imported " + r.PathValue("filename"),
+		StartedAt:   now,
+		CompletedAt: &now,
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"jobId": id})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+	return values
+}
+
+type configRepository struct {
+	cfg config.Config
+}
+
+func (r *configRepository) Load(context.Context) (config.Config, error) { return r.cfg, nil }
+func (r *configRepository) SetPath(path string)                         { r.cfg.EnvPath = path }
+func (r *configRepository) SetOverlays(paths []string)                  { r.cfg.EnvOverlays = paths }
+func (r *configRepository) Path() string                                { return r.cfg.EnvPath }
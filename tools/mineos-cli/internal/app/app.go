@@ -55,7 +55,7 @@ func (a *App) Run() error {
 		// Print update notice if available (after command completes)
 		a.printUpdateNotice()
 	}()
-	return a.rootCmd.Execute()
+	return commands.Execute(a.rootCmd)
 }
 
 func (a *App) checkForUpdates() {
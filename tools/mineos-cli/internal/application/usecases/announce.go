@@ -0,0 +1,85 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/motd"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/schedule"
+)
+
+type AnnounceUseCase struct {
+	client ports.ApiClient
+}
+
+func NewAnnounceUseCase(client ports.ApiClient) *AnnounceUseCase {
+	return &AnnounceUseCase{client: client}
+}
+
+// Execute broadcasts message to the running servers the announcement
+// matches. Servers without players online are skipped, as nobody would see
+// it. It returns the servers reached and joins the errors of the others.
+func (uc *AnnounceUseCase) Execute(ctx context.Context, announcement schedule.Announcement, message string) ([]string, error) {
+	servers, err := uc.client.ListServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var sent []string
+	var errs []error
+	for _, server := range servers {
+		if server.Status != "running" || !announcement.MatchesServer(server.Name) {
+			continue
+		}
+		if sample, err := uc.client.GetPerformance(ctx, server.Name); err == nil && sample.PlayerCount == 0 {
+			continue
+		}
+		detail, err := uc.client.GetServer(ctx, server.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", server.Name, err))
+			continue
+		}
+		if err := uc.send(ctx, server.Name, AnnounceCommands(message, announcement.Mode, detail.IsBedrock())); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", server.Name, err))
+			continue
+		}
+		sent = append(sent, server.Name)
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (uc *AnnounceUseCase) send(ctx context.Context, name string, commands []string) error {
+	for _, command := range commands {
+		if err := uc.client.SendConsoleCommand(ctx, name, command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AnnounceCommands turns a MiniMessage or '&'-coded message into console
+// commands. tellraw keeps the formatting: Java takes a JSON text component
+// and Bedrock a rawtext object with '§' codes. say sends plain text, one
+// command per line since the console reads one line per command.
+func AnnounceCommands(message, mode string, bedrock bool) []string {
+	segments := motd.Parse(message)
+	if mode == schedule.AnnounceSay {
+		var commands []string
+		for _, line := range strings.Split(motd.PlainText(segments), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				commands = append(commands, "say "+line)
+			}
+		}
+		return commands
+	}
+	if bedrock {
+		rawtext, _ := json.Marshal(map[string]any{
+			"rawtext": []map[string]string{{"text": motd.Legacy(segments, false)}},
+		})
+		return []string{"tellraw @a " + string(rawtext)}
+	}
+	return []string{"tellraw @a " + string(motd.MarshalComponent(segments))}
+}
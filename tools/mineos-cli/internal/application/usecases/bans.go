@@ -0,0 +1,277 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/bans"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// PlayerLookup resolves a Java player name to its UUID and canonical name.
+type PlayerLookup func(ctx context.Context, name string) (uuid, canonical string, err error)
+
+// Ways a ban change reaches a server.
+const (
+	BanViaConsole = "console"
+	BanViaFile    = "file"
+	BanSkipped    = "skipped"
+)
+
+// BanResult reports how a ban change was applied to one server.
+type BanResult struct {
+	Server string
+	Via    string
+	Note   string
+	Err    error
+}
+
+// BanSyncResult reports what sync changed on one server.
+type BanSyncResult struct {
+	Server  string
+	Via     string
+	Banned  []string
+	Pardons []string
+	Note    string
+	Err     error
+}
+
+type BanSyncOptions struct {
+	// From makes one server's list authoritative. Empty merges every
+	// selected server's list.
+	From string
+	// Prune pardons players missing from the wanted list.
+	Prune bool
+	// DryRun reports changes without applying them.
+	DryRun bool
+}
+
+// BanSource is recorded in banned-players.json for bans written by the CLI.
+const BanSource = "MineOS CLI"
+
+// BansUseCase bans and pardons players. Running servers are changed through
+// console commands so the server's in-memory list stays authoritative;
+// stopped servers have banned-players.json edited directly.
+type BansUseCase struct {
+	client ports.ApiClient
+	lookup PlayerLookup
+	now    func() time.Time
+}
+
+func NewBansUseCase(client ports.ApiClient, lookup PlayerLookup) *BansUseCase {
+	return &BansUseCase{client: client, lookup: lookup, now: time.Now}
+}
+
+// List returns a server's ban list; a missing file is an empty list.
+func (uc *BansUseCase) List(ctx context.Context, name string) (bans.List, error) {
+	content, err := uc.client.ReadServerFile(ctx, name, bans.File)
+	if err != nil {
+		if errors.Is(err, ports.ErrNotFound) {
+			return bans.List{}, nil
+		}
+		return nil, err
+	}
+	return bans.Parse(content)
+}
+
+func (uc *BansUseCase) Ban(ctx context.Context, name, player, reason string) BanResult {
+	result := BanResult{Server: name}
+	detail, skip, err := uc.target(ctx, name)
+	if err != nil || skip != "" {
+		result.Via, result.Note, result.Err = BanSkipped, skip, err
+		return result
+	}
+
+	if detail.IsRunning() {
+		result.Via = BanViaConsole
+		result.Err = uc.client.SendConsoleCommand(ctx, name, strings.TrimSpace("ban "+player+" "+reason))
+		return result
+	}
+
+	result.Via = BanViaFile
+	list, err := uc.List(ctx, name)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if _, ok := list.Find(player); ok {
+		result.Note = "already banned"
+		return result
+	}
+	uuid, canonical, err := uc.resolve(ctx, name, player)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Err = uc.write(ctx, name, list.With(bans.NewEntry(uuid, canonical, BanSource, reason, uc.now())))
+	return result
+}
+
+func (uc *BansUseCase) Pardon(ctx context.Context, name, player string) BanResult {
+	result := BanResult{Server: name}
+	detail, skip, err := uc.target(ctx, name)
+	if err != nil || skip != "" {
+		result.Via, result.Note, result.Err = BanSkipped, skip, err
+		return result
+	}
+
+	if detail.IsRunning() {
+		result.Via = BanViaConsole
+		result.Err = uc.client.SendConsoleCommand(ctx, name, "pardon "+player)
+		return result
+	}
+
+	result.Via = BanViaFile
+	list, err := uc.List(ctx, name)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if _, ok := list.Find(player); !ok {
+		result.Note = "not banned"
+		return result
+	}
+	result.Err = uc.write(ctx, name, list.Without(player))
+	return result
+}
+
+// Sync makes the ban lists of the given servers consistent. By default every
+// ban found on any server is applied to all of them; with From set only that
+// server's bans are propagated.
+func (uc *BansUseCase) Sync(ctx context.Context, names []string, opts BanSyncOptions) (bans.List, []BanSyncResult, error) {
+	lists := map[string]bans.List{}
+	details := map[string]ports.ServerDetail{}
+	skipped := map[string]string{}
+	for _, name := range names {
+		detail, skip, err := uc.target(ctx, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if skip != "" {
+			skipped[name] = skip
+			continue
+		}
+		list, err := uc.List(ctx, name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		lists[name] = list
+		details[name] = detail
+	}
+
+	var want bans.List
+	if opts.From != "" {
+		source, err := uc.List(ctx, opts.From)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", opts.From, err)
+		}
+		want = bans.Merge(source)
+	} else {
+		all := make([]bans.List, 0, len(lists))
+		for _, name := range names {
+			if list, ok := lists[name]; ok {
+				all = append(all, list)
+			}
+		}
+		want = bans.Merge(all...)
+	}
+
+	results := make([]BanSyncResult, 0, len(names))
+	for _, name := range names {
+		if note, ok := skipped[name]; ok {
+			results = append(results, BanSyncResult{Server: name, Via: BanSkipped, Note: note})
+			continue
+		}
+		if name == opts.From {
+			continue
+		}
+		results = append(results, uc.syncOne(ctx, name, details[name], lists[name], want, opts))
+	}
+	return want, results, nil
+}
+
+func (uc *BansUseCase) syncOne(ctx context.Context, name string, detail ports.ServerDetail, current, want bans.List, opts BanSyncOptions) BanSyncResult {
+	result := BanSyncResult{Server: name, Via: BanViaFile}
+	if detail.IsRunning() {
+		result.Via = BanViaConsole
+	}
+	add, remove := bans.Diff(current, want)
+	if !opts.Prune {
+		remove = nil
+	}
+	for _, entry := range add {
+		result.Banned = append(result.Banned, entry.Name)
+	}
+	for _, entry := range remove {
+		result.Pardons = append(result.Pardons, entry.Name)
+	}
+	if opts.DryRun || (len(add) == 0 && len(remove) == 0) {
+		return result
+	}
+
+	if detail.IsRunning() {
+		for _, entry := range add {
+			if err := uc.client.SendConsoleCommand(ctx, name, strings.TrimSpace("ban "+entry.Name+" "+entry.Reason)); err != nil {
+				result.Err = err
+				return result
+			}
+		}
+		for _, entry := range remove {
+			if err := uc.client.SendConsoleCommand(ctx, name, "pardon "+entry.Name); err != nil {
+				result.Err = err
+				return result
+			}
+		}
+		return result
+	}
+
+	updated := current
+	for _, entry := range remove {
+		updated = updated.Without(entry.Name)
+	}
+	for _, entry := range add {
+		updated = updated.With(entry)
+	}
+	result.Err = uc.write(ctx, name, updated)
+	return result
+}
+
+// target loads a server and reports why it cannot carry a Java ban list.
+func (uc *BansUseCase) target(ctx context.Context, name string) (ports.ServerDetail, string, error) {
+	detail, err := uc.client.GetServer(ctx, name)
+	if err != nil {
+		return ports.ServerDetail{}, "", err
+	}
+	if detail.IsBedrock() {
+		return detail, "Bedrock servers manage bans through their own allowlist and permissions", nil
+	}
+	return detail, "", nil
+}
+
+// resolve finds the UUID a stopped server will match a ban against. Offline
+// mode servers derive it from the name; online servers need the Mojang UUID.
+func (uc *BansUseCase) resolve(ctx context.Context, name, player string) (string, string, error) {
+	props, err := uc.client.GetServerProperties(ctx, name)
+	if err == nil && strings.EqualFold(strings.TrimSpace(props["online-mode"]), "false") {
+		return bans.OfflineUUID(player), player, nil
+	}
+	if uc.lookup == nil {
+		return "", "", fmt.Errorf("cannot look up %s's UUID; start the server and ban through the console instead", player)
+	}
+	uuid, canonical, err := uc.lookup(ctx, player)
+	if err != nil {
+		return "", "", fmt.Errorf("look up %s: %w", player, err)
+	}
+	return uuid, canonical, nil
+}
+
+func (uc *BansUseCase) write(ctx context.Context, name string, list bans.List) error {
+	content, err := list.Encode()
+	if err != nil {
+		return err
+	}
+	return uc.client.WriteServerFile(ctx, name, bans.File, content)
+}
@@ -0,0 +1,90 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+type BootstrapServerUseCase struct {
+	client       ports.ApiClient
+	pollInterval time.Duration
+}
+
+func NewBootstrapServerUseCase(client ports.ApiClient) *BootstrapServerUseCase {
+	return &BootstrapServerUseCase{client: client, pollInterval: 2 * time.Second}
+}
+
+// Execute performs a first start/stop cycle so the server generates its
+// configuration files. A server that exits during startup is inspected so an
+// unaccepted EULA is reported explicitly instead of as a silent stop.
+func (uc *BootstrapServerUseCase) Execute(ctx context.Context, name string, timeout time.Duration, progress func(string)) error {
+	report := func(msg string) {
+		if progress != nil {
+			progress(msg)
+		}
+	}
+
+	detail, err := uc.client.GetServer(ctx, name)
+	if err != nil {
+		return err
+	}
+	if detail.IsRunning() {
+		return fmt.Errorf("%s is already running; stop it before bootstrapping", name)
+	}
+	if !detail.IsBedrock() && !detail.EulaAccepted {
+		return fmt.Errorf("%s: %w", name, ErrEulaNotAccepted)
+	}
+
+	report("Starting server for first run...")
+	if err := uc.client.ServerAction(ctx, name, "start"); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	stableChecks := 0
+	for {
+		if time.Now().After(deadline) {
+			report("Timed out waiting for first start; stopping server...")
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(uc.pollInterval):
+		}
+
+		detail, err = uc.client.GetServer(ctx, name)
+		if err != nil {
+			return err
+		}
+		if !detail.IsRunning() {
+			return uc.diagnoseEarlyExit(ctx, name)
+		}
+		if _, err := uc.client.ReadServerFile(ctx, name, "server.properties"); err == nil {
+			stableChecks++
+		}
+		if stableChecks >= 3 {
+			report("Configuration generated.")
+			break
+		}
+	}
+
+	report("Stopping server...")
+	return uc.client.ServerAction(ctx, name, "stop")
+}
+
+func (uc *BootstrapServerUseCase) diagnoseEarlyExit(ctx context.Context, name string) error {
+	content, err := uc.client.ReadServerFile(ctx, name, "eula.txt")
+	if err == nil && strings.Contains(strings.ReplaceAll(strings.ToLower(content), " ", ""), "eula=false") {
+		return fmt.Errorf("%s stopped immediately: %w", name, ErrEulaNotAccepted)
+	}
+	if err != nil && !errors.Is(err, ports.ErrNotFound) {
+		return err
+	}
+	return fmt.Errorf("%s stopped during first start; check 'mineos servers logs %s --source java'", name, name)
+}
@@ -0,0 +1,164 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// ServerSelector describes which servers a bulk operation targets. Patterns
+// may be literal names or shell globs such as "lobby-*".
+type ServerSelector struct {
+	All      bool
+	Tag      string
+	Patterns []string
+}
+
+func (s ServerSelector) IsEmpty() bool {
+	return !s.All && strings.TrimSpace(s.Tag) == "" && len(s.Patterns) == 0
+}
+
+type SelectServersUseCase struct {
+	client ports.ApiClient
+}
+
+func NewSelectServersUseCase(client ports.ApiClient) *SelectServersUseCase {
+	return &SelectServersUseCase{client: client}
+}
+
+// Execute resolves the selector against the live server list and returns the
+// matching names sorted alphabetically.
+func (uc *SelectServersUseCase) Execute(ctx context.Context, selector ServerSelector) ([]string, error) {
+	if selector.IsEmpty() {
+		return nil, errors.New("no servers selected; pass names, glob patterns, --all or --tag")
+	}
+
+	servers, err := uc.client.ListServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]string, 0, len(servers))
+	for _, server := range servers {
+		candidates = append(candidates, server.Name)
+	}
+	sort.Strings(candidates)
+
+	selected := candidates
+	if len(selector.Patterns) > 0 {
+		selected = nil
+		seen := map[string]bool{}
+		for _, pattern := range selector.Patterns {
+			matched := false
+			for _, name := range candidates {
+				ok, err := path.Match(pattern, name)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+				}
+				if ok {
+					matched = true
+					if !seen[name] {
+						seen[name] = true
+						selected = append(selected, name)
+					}
+				}
+			}
+			if !matched {
+				return nil, ports.WithKind(fmt.Errorf("no servers match %q", pattern), ports.ErrNotFound)
+			}
+		}
+		sort.Strings(selected)
+	}
+
+	if tag := strings.ToLower(strings.TrimSpace(selector.Tag)); tag != "" {
+		tagsUC := NewServerTagsUseCase(uc.client)
+		filtered := make([]string, 0, len(selected))
+		for _, name := range selected {
+			tags, err := tagsUC.Get(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("read tags for %s: %w", name, err)
+			}
+			for _, t := range tags {
+				if t == tag {
+					filtered = append(filtered, name)
+					break
+				}
+			}
+		}
+		selected = filtered
+	}
+
+	return selected, nil
+}
+
+type BulkServerActionUseCase struct {
+	client ports.ApiClient
+}
+
+func NewBulkServerActionUseCase(client ports.ApiClient) *BulkServerActionUseCase {
+	return &BulkServerActionUseCase{client: client}
+}
+
+// Execute runs action against every server with at most parallel requests in
+// flight. Results are returned in the same order as names.
+func (uc *BulkServerActionUseCase) Execute(ctx context.Context, names []string, action string, parallel int, onDone func(ports.BulkActionResult)) []ports.BulkActionResult {
+	return uc.run(ctx, names, action, parallel, false, onDone)
+}
+
+// Ensure is Execute with ServerActionUseCase.Ensure semantics: servers
+// already in the requested state are reported as unchanged.
+func (uc *BulkServerActionUseCase) Ensure(ctx context.Context, names []string, action string, parallel int, onDone func(ports.BulkActionResult)) []ports.BulkActionResult {
+	return uc.run(ctx, names, action, parallel, true, onDone)
+}
+
+func (uc *BulkServerActionUseCase) run(ctx context.Context, names []string, action string, parallel int, ensure bool, onDone func(ports.BulkActionResult)) []ports.BulkActionResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	actionUC := NewServerActionUseCase(uc.client)
+	results := make([]ports.BulkActionResult, len(names))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = ports.BulkActionResult{Name: name, Action: action, Err: ctx.Err()}
+				return
+			}
+			defer func() { <-sem }()
+
+			started := time.Now()
+			changed := true
+			var err error
+			if ensure {
+				changed, err = actionUC.Ensure(ctx, name, action)
+			} else {
+				err = actionUC.Execute(ctx, name, action)
+			}
+			result := ports.BulkActionResult{Name: name, Action: action, Duration: time.Since(started), Err: err, Unchanged: err == nil && !changed}
+			results[i] = result
+			if onDone != nil {
+				mu.Lock()
+				onDone(result)
+				mu.Unlock()
+			}
+		}(i, name)
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,190 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// DeferredRestartOptions controls how long a restart may wait for players.
+type DeferredRestartOptions struct {
+	// MaxDelay is the longest the restart is deferred while players are online.
+	MaxDelay time.Duration
+	// Recheck is how often the player count is polled and the pending restart
+	// is announced.
+	Recheck time.Duration
+	// Warnings are countdown broadcasts before a forced restart, e.g. 10m, 5m, 1m.
+	Warnings []time.Duration
+	// Message is an optional reason appended to broadcasts.
+	Message string
+}
+
+func DefaultDeferredRestartOptions() DeferredRestartOptions {
+	return DeferredRestartOptions{
+		MaxDelay: 2 * time.Hour,
+		Recheck:  5 * time.Minute,
+		Warnings: []time.Duration{10 * time.Minute, 5 * time.Minute, time.Minute},
+	}
+}
+
+type DeferredRestartUseCase struct {
+	client ports.ApiClient
+	now    func() time.Time
+}
+
+func NewDeferredRestartUseCase(client ports.ApiClient) *DeferredRestartUseCase {
+	return &DeferredRestartUseCase{client: client, now: time.Now}
+}
+
+// Execute restarts a server as soon as it is empty. While players are online
+// the restart is announced every Recheck; once MaxDelay is nearly used up a
+// countdown is broadcast and the server restarts regardless. It reports
+// false without error when the server is not running.
+func (uc *DeferredRestartUseCase) Execute(ctx context.Context, name string, opts DeferredRestartOptions, progress func(string)) (bool, error) {
+	report := func(format string, args ...any) {
+		if progress != nil {
+			progress(fmt.Sprintf(format, args...))
+		}
+	}
+	if opts.Recheck <= 0 {
+		opts.Recheck = time.Minute
+	}
+	warnings := make([]time.Duration, 0, len(opts.Warnings))
+	for _, warning := range opts.Warnings {
+		if warning > 0 && warning <= opts.MaxDelay {
+			warnings = append(warnings, warning)
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i] > warnings[j] })
+	lead := time.Duration(0)
+	if len(warnings) > 0 {
+		lead = warnings[0]
+	}
+
+	deadline := uc.now().Add(opts.MaxDelay)
+	for {
+		players, running, err := uc.onlinePlayers(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		if !running {
+			report("%s is not running; skipping restart", name)
+			return false, nil
+		}
+		if players == 0 {
+			report("No players online; restarting %s", name)
+			break
+		}
+
+		remaining := deadline.Sub(uc.now())
+		if remaining <= lead {
+			if err := uc.countdown(ctx, name, deadline, warnings, opts.Message, report); err != nil {
+				return false, err
+			}
+			break
+		}
+
+		report("%d player(s) online on %s; deferring restart (forced in %s)", players, name, formatWait(remaining))
+		uc.say(ctx, name, fmt.Sprintf("A server restart is pending%s. It will happen once everyone has left, or in %s at the latest.", reason(opts.Message), formatWait(remaining)))
+		if err := sleepContext(ctx, min(opts.Recheck, remaining-lead)); err != nil {
+			return false, err
+		}
+	}
+
+	if err := uc.client.ServerAction(ctx, name, "restart"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// countdown broadcasts each warning until the deadline, restarting early if
+// the server empties in the meantime.
+func (uc *DeferredRestartUseCase) countdown(ctx context.Context, name string, deadline time.Time, warnings []time.Duration, message string, report func(string, ...any)) error {
+	remaining := deadline.Sub(uc.now())
+	report("Maximum delay reached; restarting %s in %s", name, formatWait(remaining))
+	uc.say(ctx, name, fmt.Sprintf("Server restarting in %s%s.", formatWait(remaining), reason(message)))
+
+	for _, warning := range warnings {
+		if warning >= remaining {
+			continue
+		}
+		if err := sleepContext(ctx, deadline.Sub(uc.now())-warning); err != nil {
+			return err
+		}
+		if players, running, err := uc.onlinePlayers(ctx, name); err == nil && running && players == 0 {
+			report("Server emptied; restarting %s now", name)
+			return nil
+		}
+		uc.say(ctx, name, fmt.Sprintf("Server restarting in %s%s.", formatWait(warning), reason(message)))
+		remaining = warning
+	}
+	return sleepContext(ctx, deadline.Sub(uc.now()))
+}
+
+// onlinePlayers prefers a live status ping and falls back to the last
+// performance sample while the server is not answering pings yet.
+func (uc *DeferredRestartUseCase) onlinePlayers(ctx context.Context, name string) (int, bool, error) {
+	detail, err := uc.client.GetServer(ctx, name)
+	if err != nil {
+		return 0, false, err
+	}
+	if !detail.IsRunning() {
+		return 0, false, nil
+	}
+	if ping, err := uc.client.Ping(ctx, name); err == nil {
+		return ping.PlayersOnline, true, nil
+	}
+	sample, err := uc.client.GetPerformance(ctx, name)
+	if err != nil {
+		return 0, true, err
+	}
+	return sample.PlayerCount, true, nil
+}
+
+// say broadcasts to players. Failures are ignored: a missed warning must not
+// block the restart.
+func (uc *DeferredRestartUseCase) say(ctx context.Context, name, message string) {
+	_ = uc.client.SendConsoleCommand(ctx, name, "say "+message)
+}
+
+func reason(message string) string {
+	if message == "" {
+		return ""
+	}
+	return " (" + message + ")"
+}
+
+func formatWait(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	case d >= 2*time.Minute:
+		return fmt.Sprintf("%d minutes", int(d.Round(time.Minute).Minutes()))
+	case d >= time.Minute:
+		return "1 minute"
+	case d >= 2*time.Second:
+		return fmt.Sprintf("%d seconds", int(d.Seconds()))
+	case d >= time.Second:
+		return "1 second"
+	default:
+		return "a moment"
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,227 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// MaintenanceFile records the properties maintenance mode replaced, so
+// turning it off restores them. It lives in the server directory.
+const MaintenanceFile = ".mineos-maintenance.json"
+
+// maintenanceProperties are saved when maintenance starts and restored when
+// it ends, with the vanilla default recorded for properties that are unset.
+var maintenanceProperties = []struct{ key, fallback string }{
+	{"white-list", "false"},
+	{"enforce-whitelist", "false"},
+	{"motd", "A Minecraft Server"},
+}
+
+// MaintenanceState is the content of MaintenanceFile. Turning maintenance
+// off keeps the file with Active false.
+type MaintenanceState struct {
+	Active   bool              `json:"active"`
+	Since    time.Time         `json:"since"`
+	Message  string            `json:"message,omitempty"`
+	Previous map[string]string `json:"previous"`
+}
+
+type MaintenanceOptions struct {
+	// Message is the kick reason shown to players.
+	Message string
+	// Motd is the encoded server.properties value; empty keeps the MOTD.
+	Motd string
+}
+
+// MaintenanceResult reports what a maintenance change did.
+type MaintenanceResult struct {
+	Running bool
+	// Kicked lists players removed for not being whitelisted or op.
+	Kicked []string
+	// Unchanged is set when the server was already in the requested mode.
+	Unchanged bool
+	// Notes are steps that could not be completed; the mode still changed.
+	Notes []string
+}
+
+// MaintenanceUseCase closes a server to everyone but whitelisted players and
+// operators, and reopens it with the settings it had before.
+type MaintenanceUseCase struct {
+	client ports.ApiClient
+	now    func() time.Time
+}
+
+func NewMaintenanceUseCase(client ports.ApiClient) *MaintenanceUseCase {
+	return &MaintenanceUseCase{client: client, now: time.Now}
+}
+
+// State returns the recorded maintenance state; a server that never had
+// maintenance mode reports Active false.
+func (uc *MaintenanceUseCase) State(ctx context.Context, name string) (MaintenanceState, error) {
+	content, err := uc.client.ReadServerFile(ctx, name, MaintenanceFile)
+	if err != nil {
+		if errors.Is(err, ports.ErrNotFound) {
+			return MaintenanceState{}, nil
+		}
+		return MaintenanceState{}, err
+	}
+	var state MaintenanceState
+	if strings.TrimSpace(content) == "" {
+		return state, nil
+	}
+	if err := json.Unmarshal([]byte(content), &state); err != nil {
+		return MaintenanceState{}, fmt.Errorf("%s: %w", MaintenanceFile, err)
+	}
+	return state, nil
+}
+
+// On enables the whitelist, kicks everyone who is neither whitelisted nor
+// an operator and sets the maintenance MOTD. The previous values are saved
+// before anything changes.
+func (uc *MaintenanceUseCase) On(ctx context.Context, name string, opts MaintenanceOptions) (MaintenanceResult, error) {
+	var result MaintenanceResult
+	detail, err := uc.client.GetServer(ctx, name)
+	if err != nil {
+		return result, err
+	}
+	if detail.IsBedrock() {
+		return result, fmt.Errorf("%s is a Bedrock server; maintenance mode needs the Java whitelist", name)
+	}
+	result.Running = detail.IsRunning()
+
+	state, err := uc.State(ctx, name)
+	if err != nil {
+		return result, err
+	}
+	if state.Active {
+		result.Unchanged = true
+		return result, nil
+	}
+	props, err := uc.client.GetServerProperties(ctx, name)
+	if err != nil {
+		return result, err
+	}
+	state = MaintenanceState{Active: true, Since: uc.now().UTC(), Message: opts.Message, Previous: map[string]string{}}
+	for _, property := range maintenanceProperties {
+		value, ok := props[property.key]
+		if !ok {
+			value = property.fallback
+		}
+		state.Previous[property.key] = value
+	}
+	if err := uc.writeState(ctx, name, state); err != nil {
+		return result, err
+	}
+
+	if result.Running {
+		// "whitelist on" saves server.properties from the server's memory,
+		// so it runs before the file edit below rather than after it.
+		if err := uc.client.SendConsoleCommand(ctx, name, "whitelist on"); err != nil {
+			return result, err
+		}
+		kicked, err := uc.kickOutsiders(ctx, name, opts.Message)
+		result.Kicked = kicked
+		if err != nil {
+			result.Notes = append(result.Notes, "could not check online players: "+err.Error())
+		}
+	}
+
+	props["white-list"] = "true"
+	props["enforce-whitelist"] = "true"
+	if opts.Motd != "" {
+		props["motd"] = opts.Motd
+	}
+	if err := uc.client.UpdateServerProperties(ctx, name, props); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// Off restores the properties saved by On and turns the whitelist off again
+// if it was off before.
+func (uc *MaintenanceUseCase) Off(ctx context.Context, name string) (MaintenanceResult, error) {
+	var result MaintenanceResult
+	state, err := uc.State(ctx, name)
+	if err != nil {
+		return result, err
+	}
+	if !state.Active {
+		result.Unchanged = true
+		return result, nil
+	}
+	detail, err := uc.client.GetServer(ctx, name)
+	if err != nil {
+		return result, err
+	}
+	result.Running = detail.IsRunning()
+
+	if result.Running && !strings.EqualFold(strings.TrimSpace(state.Previous["white-list"]), "true") {
+		if err := uc.client.SendConsoleCommand(ctx, name, "whitelist off"); err != nil {
+			return result, err
+		}
+	}
+	props, err := uc.client.GetServerProperties(ctx, name)
+	if err != nil {
+		return result, err
+	}
+	for _, property := range maintenanceProperties {
+		if previous, ok := state.Previous[property.key]; ok {
+			props[property.key] = previous
+		}
+	}
+	if err := uc.client.UpdateServerProperties(ctx, name, props); err != nil {
+		return result, err
+	}
+	state.Active = false
+	return result, uc.writeState(ctx, name, state)
+}
+
+// kickOutsiders kicks online players that are neither whitelisted nor op;
+// operators may join a whitelisted server, so they stay.
+func (uc *MaintenanceUseCase) kickOutsiders(ctx context.Context, name, message string) ([]string, error) {
+	_ = uc.client.ProcessPlayerActivity(ctx, name)
+	sessions, err := uc.client.ListPlayerSessions(ctx, name, "", 500)
+	if err != nil {
+		return nil, err
+	}
+	players, err := uc.client.ListPlayers(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	allowed := map[string]bool{}
+	for _, player := range players {
+		if player.Whitelisted || player.IsOp {
+			allowed[strings.ToLower(player.Name)] = true
+		}
+	}
+
+	var kicked []string
+	seen := map[string]bool{}
+	for _, session := range sessions {
+		player := session.PlayerName
+		key := strings.ToLower(player)
+		if session.LeftAt != nil || player == "" || seen[key] || allowed[key] {
+			continue
+		}
+		seen[key] = true
+		if err := uc.client.SendConsoleCommand(ctx, name, strings.TrimSpace("kick "+player+" "+message)); err != nil {
+			return kicked, err
+		}
+		kicked = append(kicked, player)
+	}
+	return kicked, nil
+}
+
+func (uc *MaintenanceUseCase) writeState(ctx context.Context, name string, state MaintenanceState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return uc.client.WriteServerFile(ctx, name, MaintenanceFile, string(data)+"\n")
+}
@@ -0,0 +1,482 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/manifest"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// Manifest change actions. Drift is reported but never applied.
+const (
+	ManifestCreate = "create"
+	ManifestUpdate = "update"
+	ManifestDrift  = "drift"
+)
+
+// ManifestChange is one difference between a manifest and the installation.
+// Field is server, type, eula, version, memory, property, mod, plugin,
+// tags or world; Key names the property, project or world.
+type ManifestChange struct {
+	Server string `json:"server"`
+	Action string `json:"action"`
+	Field  string `json:"field"`
+	Key    string `json:"key,omitempty"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+	Note   string `json:"note,omitempty"`
+
+	project   manifest.Project
+	versionID string
+}
+
+type ManifestPlan struct {
+	Changes []ManifestChange `json:"changes"`
+	// Unmanaged lists servers that exist but are not in the manifest. They
+	// are never touched.
+	Unmanaged []string `json:"unmanaged,omitempty"`
+}
+
+// Pending returns the changes apply would make.
+func (p ManifestPlan) Pending() []ManifestChange {
+	var pending []ManifestChange
+	for _, change := range p.Changes {
+		if change.Action != ManifestDrift {
+			pending = append(pending, change)
+		}
+	}
+	return pending
+}
+
+// Drift returns the differences apply leaves alone.
+func (p ManifestPlan) Drift() []ManifestChange {
+	var drift []ManifestChange
+	for _, change := range p.Changes {
+		if change.Action == ManifestDrift {
+			drift = append(drift, change)
+		}
+	}
+	return drift
+}
+
+type ManifestOptions struct {
+	// AllowVersionChange switches existing servers to the manifest's version
+	// instead of reporting the difference as drift.
+	AllowVersionChange bool
+}
+
+type ManifestHooks struct {
+	// AfterCreate runs once a server has been created, e.g. to assign ports.
+	AfterCreate func(ctx context.Context, name string) error
+	Progress    func(change ManifestChange, err error)
+}
+
+type ManifestUseCase struct {
+	client   ports.ApiClient
+	profiles []ports.Profile
+}
+
+func NewManifestUseCase(client ports.ApiClient) *ManifestUseCase {
+	return &ManifestUseCase{client: client}
+}
+
+// Plan compares the manifest with the installation without changing it.
+func (uc *ManifestUseCase) Plan(ctx context.Context, m manifest.Manifest, opts ManifestOptions) (ManifestPlan, error) {
+	servers, err := uc.client.ListServers(ctx)
+	if err != nil {
+		return ManifestPlan{}, err
+	}
+	existing := map[string]bool{}
+	for _, server := range servers {
+		existing[server.Name] = true
+	}
+
+	var plan ManifestPlan
+	declared := map[string]bool{}
+	for _, desired := range m.Servers {
+		declared[desired.Name] = true
+		var changes []ManifestChange
+		if existing[desired.Name] {
+			changes, err = uc.planExisting(ctx, desired, opts)
+		} else {
+			changes, err = uc.planNew(ctx, desired)
+		}
+		if err != nil {
+			return ManifestPlan{}, fmt.Errorf("%s: %w", desired.Name, err)
+		}
+		plan.Changes = append(plan.Changes, changes...)
+	}
+	for _, server := range servers {
+		if !declared[server.Name] {
+			plan.Unmanaged = append(plan.Unmanaged, server.Name)
+		}
+	}
+	sort.Strings(plan.Unmanaged)
+	return plan, nil
+}
+
+func (uc *ManifestUseCase) planNew(ctx context.Context, desired manifest.Server) ([]ManifestChange, error) {
+	name := desired.Name
+	changes := []ManifestChange{{Server: name, Action: ManifestCreate, Field: "server", To: desired.Type}}
+	if desired.Eula {
+		changes = append(changes, ManifestChange{Server: name, Action: ManifestUpdate, Field: "eula", To: "accepted"})
+	}
+	if id := desired.ProfileID(); id != "" {
+		if _, err := uc.profile(ctx, id); err != nil {
+			return nil, err
+		}
+		changes = append(changes, ManifestChange{Server: name, Action: ManifestUpdate, Field: "version", To: id})
+	}
+	if mb := desired.MemoryMB(); mb > 0 {
+		changes = append(changes, ManifestChange{Server: name, Action: ManifestUpdate, Field: "memory", To: manifest.FormatMemory(mb)})
+	}
+	for _, key := range sortedKeys(desired.Properties) {
+		changes = append(changes, ManifestChange{Server: name, Action: ManifestUpdate, Field: "property", Key: key, To: desired.Properties[key]})
+	}
+	// Projects are resolved against the server's loader and version once it
+	// exists.
+	for _, project := range desired.Mods {
+		changes = append(changes, ManifestChange{Server: name, Action: ManifestUpdate, Field: "mod", Key: project.String(), project: project})
+	}
+	for _, project := range desired.Plugins {
+		changes = append(changes, ManifestChange{Server: name, Action: ManifestUpdate, Field: "plugin", Key: project.String(), project: project})
+	}
+	if len(desired.Tags) > 0 {
+		changes = append(changes, ManifestChange{Server: name, Action: ManifestUpdate, Field: "tags", To: strings.Join(normalizeTags(desired.Tags), ", ")})
+	}
+	return changes, nil
+}
+
+func (uc *ManifestUseCase) planExisting(ctx context.Context, desired manifest.Server, opts ManifestOptions) ([]ManifestChange, error) {
+	name := desired.Name
+	detail, err := uc.client.GetServer(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	actualType := fallbackString(detail.ServerType, "java")
+	if actualType != desired.Type {
+		return []ManifestChange{{Server: name, Action: ManifestDrift, Field: "type", From: actualType, To: desired.Type, Note: "the server type cannot be changed"}}, nil
+	}
+
+	var changes []ManifestChange
+	if desired.Eula && !detail.EulaAccepted {
+		changes = append(changes, ManifestChange{Server: name, Action: ManifestUpdate, Field: "eula", From: "not accepted", To: "accepted"})
+	}
+
+	if desired.ProfileID() != "" || desired.MemoryMB() > 0 {
+		cfg, err := uc.client.GetServerConfig(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if id := desired.ProfileID(); id != "" {
+			profile, err := uc.profile(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			current := ""
+			if cfg.Java.JarFile != nil {
+				current = *cfg.Java.JarFile
+			}
+			if current != profile.Filename {
+				change := ManifestChange{Server: name, Action: ManifestUpdate, Field: "version", From: fallbackString(current, "none"), To: id}
+				if !opts.AllowVersionChange && current != "" {
+					change.Action = ManifestDrift
+					change.Note = "pass --allow-version-change to switch; back up the world first"
+				}
+				changes = append(changes, change)
+			}
+		}
+		if mb := desired.MemoryMB(); mb > 0 && cfg.Java.JavaXmx != mb {
+			from := "unset"
+			if cfg.Java.JavaXmx > 0 {
+				from = manifest.FormatMemory(cfg.Java.JavaXmx)
+			}
+			changes = append(changes, ManifestChange{Server: name, Action: ManifestUpdate, Field: "memory", From: from, To: manifest.FormatMemory(mb)})
+		}
+	}
+
+	if len(desired.Properties) > 0 {
+		current, err := uc.client.GetServerProperties(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range sortedKeys(desired.Properties) {
+			if value, ok := current[key]; !ok || value != desired.Properties[key] {
+				changes = append(changes, ManifestChange{Server: name, Action: ManifestUpdate, Field: "property", Key: key, From: current[key], To: desired.Properties[key]})
+			}
+		}
+	}
+
+	if desired.Mods != nil {
+		installed, err := uc.client.ListMods(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		jarChanges, err := uc.planJars(ctx, name, "mods", desired.Mods, installed)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, jarChanges...)
+	}
+	if desired.Plugins != nil {
+		installed, err := uc.client.ListPlugins(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		jarChanges, err := uc.planJars(ctx, name, "plugins", desired.Plugins, installed)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, jarChanges...)
+	}
+
+	if desired.Worlds != nil {
+		worlds, err := uc.client.ListWorlds(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		have := map[string]bool{}
+		for _, world := range worlds {
+			have[world.Name] = true
+		}
+		want := map[string]bool{}
+		for _, world := range desired.Worlds {
+			want[world] = true
+			if !have[world] {
+				changes = append(changes, ManifestChange{Server: name, Action: ManifestDrift, Field: "world", Key: world, Note: "missing"})
+			}
+		}
+		for _, world := range worlds {
+			if !want[world.Name] {
+				changes = append(changes, ManifestChange{Server: name, Action: ManifestDrift, Field: "world", Key: world.Name, Note: "not in manifest"})
+			}
+		}
+	}
+
+	if desired.Tags != nil {
+		current, err := NewServerTagsUseCase(uc.client).Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		have, want := strings.Join(current, ", "), strings.Join(normalizeTags(desired.Tags), ", ")
+		if have != want {
+			changes = append(changes, ManifestChange{Server: name, Action: ManifestUpdate, Field: "tags", From: have, To: want})
+		}
+	}
+	return changes, nil
+}
+
+// planJars matches installed jars to the manifest's projects. A project is
+// up to date when its resolved file is installed; otherwise a jar whose name
+// starts with the project slug is taken as the version to replace. Other
+// jars are reported as drift.
+func (uc *ManifestUseCase) planJars(ctx context.Context, name, kind string, projects []manifest.Project, installed []ports.InstalledPlugin) ([]ManifestChange, error) {
+	field := strings.TrimSuffix(kind, "s")
+	files := map[string]bool{}
+	for _, jar := range installed {
+		files[jar.FileName] = true
+	}
+	claimed := map[string]bool{}
+
+	var changes []ManifestChange
+	for _, project := range projects {
+		version, file, err := uc.resolveProject(ctx, name, kind, project)
+		if err != nil {
+			return nil, err
+		}
+		if files[file] {
+			claimed[file] = true
+			continue
+		}
+		old := ""
+		for _, jar := range installed {
+			if !claimed[jar.FileName] && jarMatchesSlug(jar.FileName, project.Slug) {
+				old = jar.FileName
+				claimed[old] = true
+				break
+			}
+		}
+		changes = append(changes, ManifestChange{Server: name, Action: ManifestUpdate, Field: field, Key: project.String(), From: old, To: file, project: project, versionID: version.ID})
+	}
+	for _, jar := range installed {
+		if !claimed[jar.FileName] {
+			changes = append(changes, ManifestChange{Server: name, Action: ManifestDrift, Field: field, Key: jar.FileName, Note: "not in manifest"})
+		}
+	}
+	return changes, nil
+}
+
+func jarMatchesSlug(fileName, slug string) bool {
+	lower, slug := strings.ToLower(fileName), strings.ToLower(slug)
+	if !strings.HasPrefix(lower, slug) {
+		return false
+	}
+	rest := []rune(lower[len(slug):])
+	return len(rest) == 0 || !unicode.IsLetter(rest[0])
+}
+
+// resolveProject picks the pinned version of a project, or the newest one
+// compatible with the server, and its primary file.
+func (uc *ManifestUseCase) resolveProject(ctx context.Context, name, kind string, project manifest.Project) (ports.ModrinthVersion, string, error) {
+	versions, err := uc.client.ModrinthVersions(ctx, name, kind, project.Slug)
+	if err != nil {
+		return ports.ModrinthVersion{}, "", fmt.Errorf("%s: %w", project.Slug, err)
+	}
+	var chosen *ports.ModrinthVersion
+	for i := range versions {
+		version := &versions[i]
+		if project.Version != "" {
+			if version.VersionNumber == project.Version {
+				chosen = version
+				break
+			}
+			continue
+		}
+		if chosen == nil || version.DatePublished.After(chosen.DatePublished) {
+			chosen = version
+		}
+	}
+	if chosen == nil {
+		return ports.ModrinthVersion{}, "", fmt.Errorf("no Modrinth version of %s matches this server", project)
+	}
+	for _, file := range chosen.Files {
+		if file.Primary {
+			return *chosen, file.FileName, nil
+		}
+	}
+	if len(chosen.Files) == 0 {
+		return ports.ModrinthVersion{}, "", fmt.Errorf("%s %s has no files", project.Slug, chosen.VersionNumber)
+	}
+	return *chosen, chosen.Files[0].FileName, nil
+}
+
+func (uc *ManifestUseCase) profile(ctx context.Context, id string) (ports.Profile, error) {
+	if uc.profiles == nil {
+		profiles, err := uc.client.ListProfiles(ctx)
+		if err != nil {
+			return ports.Profile{}, err
+		}
+		uc.profiles = profiles
+	}
+	for _, profile := range uc.profiles {
+		if profile.Id == id {
+			return profile, nil
+		}
+	}
+	return ports.Profile{}, ports.WithKind(fmt.Errorf("profile %s not found; list versions with 'mineos versions'", id), ports.ErrNotFound)
+}
+
+// Apply makes the plan's pending changes in order. A server that fails to be
+// created is skipped; other failures are reported and applying continues.
+func (uc *ManifestUseCase) Apply(ctx context.Context, plan ManifestPlan, hooks ManifestHooks) error {
+	failed := 0
+	skipped := map[string]bool{}
+	for _, change := range plan.Pending() {
+		if skipped[change.Server] {
+			continue
+		}
+		err := uc.apply(ctx, change, hooks)
+		if hooks.Progress != nil {
+			hooks.Progress(change, err)
+		}
+		if err != nil {
+			failed++
+			if change.Field == "server" {
+				skipped[change.Server] = true
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d change(s) failed", failed)
+	}
+	return nil
+}
+
+func (uc *ManifestUseCase) apply(ctx context.Context, change ManifestChange, hooks ManifestHooks) error {
+	name := change.Server
+	switch change.Field {
+	case "server":
+		if err := uc.client.CreateServer(ctx, name, change.To); err != nil {
+			return err
+		}
+		if hooks.AfterCreate != nil {
+			return hooks.AfterCreate(ctx, name)
+		}
+		return nil
+	case "eula":
+		return uc.client.AcceptEula(ctx, name)
+	case "version":
+		profile, err := uc.profile(ctx, change.To)
+		if err != nil {
+			return err
+		}
+		if !profile.Downloaded {
+			if err := uc.client.DownloadProfile(ctx, profile.Id); err != nil {
+				return err
+			}
+		}
+		return uc.client.CopyProfileToServer(ctx, profile.Id, name)
+	case "memory":
+		mb, err := manifest.ParseMemory(change.To)
+		if err != nil {
+			return err
+		}
+		cfg, err := uc.client.GetServerConfig(ctx, name)
+		if err != nil {
+			return err
+		}
+		cfg.Java.JavaXmx = mb
+		if cfg.Java.JavaXms > mb {
+			cfg.Java.JavaXms = mb
+		}
+		return uc.client.UpdateServerConfig(ctx, name, cfg)
+	case "property":
+		properties, err := uc.client.GetServerProperties(ctx, name)
+		if err != nil {
+			return err
+		}
+		properties[change.Key] = change.To
+		return uc.client.UpdateServerProperties(ctx, name, properties)
+	case "mod", "plugin":
+		kind := change.Field + "s"
+		versionID, file := change.versionID, change.To
+		if versionID == "" {
+			version, resolved, err := uc.resolveProject(ctx, name, kind, change.project)
+			if err != nil {
+				return err
+			}
+			versionID, file = version.ID, resolved
+		}
+		if err := uc.client.InstallModrinth(ctx, name, kind, versionID); err != nil {
+			return err
+		}
+		if change.From != "" && change.From != file {
+			return uc.client.DeleteJar(ctx, name, kind, change.From)
+		}
+		return nil
+	case "tags":
+		return NewServerTagsUseCase(uc.client).Set(ctx, name, strings.Split(change.To, ","))
+	}
+	return errors.New("unsupported change " + change.Field)
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func fallbackString(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
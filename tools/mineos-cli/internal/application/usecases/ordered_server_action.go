@@ -0,0 +1,62 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+var ErrTierSkipped = errors.New("skipped: an earlier tier failed")
+
+type OrderedServerActionUseCase struct {
+	client ports.ApiClient
+}
+
+func NewOrderedServerActionUseCase(client ports.ApiClient) *OrderedServerActionUseCase {
+	return &OrderedServerActionUseCase{client: client}
+}
+
+type OrderedActionOptions struct {
+	Parallel       int
+	Stagger        time.Duration
+	AbortOnFailure bool
+	OnTier         func(index int, tier []string)
+	OnDone         func(ports.BulkActionResult)
+}
+
+// Execute runs action tier by tier, waiting for each tier to finish before the
+// next begins. With AbortOnFailure set, servers in later tiers are reported as
+// skipped once any server fails.
+func (uc *OrderedServerActionUseCase) Execute(ctx context.Context, tiers [][]string, action string, opts OrderedActionOptions) []ports.BulkActionResult {
+	bulk := NewBulkServerActionUseCase(uc.client)
+	var results []ports.BulkActionResult
+	failed := false
+
+	for i, tier := range tiers {
+		if failed && opts.AbortOnFailure {
+			for _, name := range tier {
+				results = append(results, ports.BulkActionResult{Name: name, Action: action, Err: ErrTierSkipped})
+			}
+			continue
+		}
+		if i > 0 && opts.Stagger > 0 {
+			select {
+			case <-time.After(opts.Stagger):
+			case <-ctx.Done():
+			}
+		}
+		if opts.OnTier != nil {
+			opts.OnTier(i, tier)
+		}
+		for _, result := range bulk.Execute(ctx, tier, action, opts.Parallel, opts.OnDone) {
+			if result.Err != nil {
+				failed = true
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,135 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+type PlayerHistoryQuery struct {
+	// Player limits the history to one player name.
+	Player string
+	// Limit caps how many sessions are fetched.
+	Limit int
+	// Refresh scans latest.log for new joins and leaves before reading.
+	Refresh bool
+}
+
+// PlayerActivity totals one player's sessions.
+type PlayerActivity struct {
+	UUID      string
+	Name      string
+	Sessions  int
+	Playtime  time.Duration
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Online    bool
+}
+
+type PlayerHistory struct {
+	Sessions []ports.PlayerSession
+	Players  []PlayerActivity
+}
+
+type PlayerHistoryUseCase struct {
+	client ports.ApiClient
+	now    func() time.Time
+}
+
+func NewPlayerHistoryUseCase(client ports.ApiClient) *PlayerHistoryUseCase {
+	return &PlayerHistoryUseCase{client: client, now: time.Now}
+}
+
+// Execute returns recorded sessions, newest first, and per-player totals
+// ordered by playtime. Sessions still open count up to now.
+func (uc *PlayerHistoryUseCase) Execute(ctx context.Context, server string, query PlayerHistoryQuery) (PlayerHistory, error) {
+	if query.Refresh {
+		if err := uc.client.ProcessPlayerActivity(ctx, server); err != nil {
+			return PlayerHistory{}, err
+		}
+	}
+
+	uuid := ""
+	if query.Player != "" {
+		players, err := uc.client.ListPlayers(ctx, server)
+		if err != nil {
+			return PlayerHistory{}, err
+		}
+		for _, player := range players {
+			if strings.EqualFold(player.Name, query.Player) {
+				uuid = player.UUID
+				break
+			}
+		}
+		if uuid == "" {
+			return PlayerHistory{}, fmt.Errorf("%s has never joined %s", query.Player, server)
+		}
+	}
+
+	sessions, err := uc.client.ListPlayerSessions(ctx, server, uuid, query.Limit)
+	if err != nil {
+		return PlayerHistory{}, err
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].JoinedAt.After(sessions[j].JoinedAt) })
+	return PlayerHistory{Sessions: sessions, Players: summarizeSessions(sessions, uc.now())}, nil
+}
+
+// SessionDuration is how long a session lasted, or has lasted so far.
+func SessionDuration(session ports.PlayerSession, now time.Time) time.Duration {
+	if session.DurationSeconds != nil {
+		return time.Duration(*session.DurationSeconds) * time.Second
+	}
+	end := now
+	if session.LeftAt != nil {
+		end = *session.LeftAt
+	}
+	if end.Before(session.JoinedAt) {
+		return 0
+	}
+	return end.Sub(session.JoinedAt)
+}
+
+func summarizeSessions(sessions []ports.PlayerSession, now time.Time) []PlayerActivity {
+	byUUID := map[string]*PlayerActivity{}
+	for _, session := range sessions {
+		key := strings.ToLower(session.PlayerUUID)
+		if key == "" {
+			key = "name:" + strings.ToLower(session.PlayerName)
+		}
+		activity, ok := byUUID[key]
+		if !ok {
+			activity = &PlayerActivity{UUID: session.PlayerUUID, Name: session.PlayerName, FirstSeen: session.JoinedAt}
+			byUUID[key] = activity
+		}
+		activity.Sessions++
+		activity.Playtime += SessionDuration(session, now)
+		if session.JoinedAt.Before(activity.FirstSeen) {
+			activity.FirstSeen = session.JoinedAt
+		}
+		seen := now
+		if session.LeftAt != nil {
+			seen = *session.LeftAt
+		} else {
+			activity.Online = true
+		}
+		if seen.After(activity.LastSeen) {
+			activity.LastSeen = seen
+		}
+	}
+
+	players := make([]PlayerActivity, 0, len(byUUID))
+	for _, activity := range byUUID {
+		players = append(players, *activity)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].Playtime != players[j].Playtime {
+			return players[i].Playtime > players[j].Playtime
+		}
+		return strings.ToLower(players[i].Name) < strings.ToLower(players[j].Name)
+	})
+	return players
+}
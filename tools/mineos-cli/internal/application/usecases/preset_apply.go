@@ -0,0 +1,45 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/preset"
+)
+
+type ApplyPresetUseCase struct {
+	client ports.ApiClient
+}
+
+func NewApplyPresetUseCase(client ports.ApiClient) *ApplyPresetUseCase {
+	return &ApplyPresetUseCase{client: client}
+}
+
+// Execute sends the preset's console commands to a running Java server,
+// calling progress with each one sent. It stops at the first command the API
+// refuses and returns the commands sent before it.
+func (uc *ApplyPresetUseCase) Execute(ctx context.Context, server string, p preset.Preset, progress func(command string)) ([]string, error) {
+	detail, err := uc.client.GetServer(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	if !detail.IsRunning() {
+		return nil, fmt.Errorf("%s is not running; presets are applied through its console", server)
+	}
+	if detail.IsBedrock() {
+		return nil, fmt.Errorf("%s is a Bedrock server; presets use Java Edition gamerules and world border commands", server)
+	}
+
+	var sent []string
+	for _, command := range p.ConsoleCommands() {
+		if err := uc.client.SendConsoleCommand(ctx, server, command); err != nil {
+			return sent, fmt.Errorf("%s: %w", command, err)
+		}
+		sent = append(sent, command)
+		if progress != nil {
+			progress(command)
+		}
+	}
+	return sent, nil
+}
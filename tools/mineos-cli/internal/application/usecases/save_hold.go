@@ -0,0 +1,210 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/console"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// Save hold phases reported while pausing a server's saves.
+const (
+	SaveHolding  = "holding"
+	SaveFlushing = "flushing"
+	SaveFlushed  = "flushed"
+	SaveReleased = "released"
+	// SaveSkipped is reported for a stopped server, whose files are
+	// already consistent.
+	SaveSkipped = "skipped"
+)
+
+// DefaultSaveTimeout bounds the wait for a server to confirm its flush.
+const DefaultSaveTimeout = 2 * time.Minute
+
+// ErrSaveUnconfirmed is returned when no flush confirmation shows up in the
+// server log within the timeout.
+var ErrSaveUnconfirmed = errors.New("the server did not confirm its save")
+
+// ConsoleFollower calls onLine with each line a server logs, stamped with
+// the time it was logged, until ctx ends. Lines logged shortly before the
+// call may be passed first.
+type ConsoleFollower func(ctx context.Context, server string, onLine func(time.Time, string)) error
+
+type SaveHoldOptions struct {
+	// Timeout bounds the wait for the flush; zero uses DefaultSaveTimeout.
+	Timeout time.Duration
+	// OnPhase is called as the hold moves through its phases.
+	OnPhase func(server, phase string)
+}
+
+func (opts SaveHoldOptions) phase(server, phase string) {
+	if opts.OnPhase != nil {
+		opts.OnPhase(server, phase)
+	}
+}
+
+// SaveHoldUseCase keeps a running server from writing its world while a
+// backup reads it: saving is turned off, everything is flushed, and the
+// backup starts only once the log confirms the flush. Any backup, be it a
+// snapshot, an archive or an upload, can run inside Around.
+type SaveHoldUseCase struct {
+	client ports.ApiClient
+	follow ConsoleFollower
+	now    func() time.Time
+	// settle is how long the console stream gets to replay recent lines
+	// before the flush is asked for.
+	settle time.Duration
+}
+
+func NewSaveHoldUseCase(client ports.ApiClient, follow ConsoleFollower) *SaveHoldUseCase {
+	return &SaveHoldUseCase{client: client, follow: follow, now: time.Now, settle: 2 * time.Second}
+}
+
+// SaveHold is a server whose saving is paused until Release.
+type SaveHold struct {
+	Server string
+	// Held is false for a stopped server, where nothing was paused.
+	Held     bool
+	commands console.SaveCommands
+	client   ports.ApiClient
+	opts     SaveHoldOptions
+}
+
+// Release turns saving back on. It is safe to call more than once.
+func (h *SaveHold) Release(ctx context.Context) error {
+	if h == nil || !h.Held {
+		return nil
+	}
+	h.Held = false
+	if err := h.client.SendConsoleCommand(ctx, h.Server, h.commands.Release); err != nil {
+		return fmt.Errorf("resume saving on %s: %w", h.Server, err)
+	}
+	h.opts.phase(h.Server, SaveReleased)
+	return nil
+}
+
+// Around runs backup while server's saves are held and releases them
+// afterwards, whether or not the backup succeeded.
+func (uc *SaveHoldUseCase) Around(ctx context.Context, server string, opts SaveHoldOptions, backup func(context.Context) error) error {
+	hold, err := uc.Hold(ctx, server, opts)
+	if err != nil {
+		return err
+	}
+	err = backup(ctx)
+	// A cancelled backup still has to turn saving back on.
+	return errors.Join(err, hold.Release(context.WithoutCancel(ctx)))
+}
+
+// Hold pauses saving on server and waits for its flush to be confirmed. A
+// stopped server is returned unheld. On error saving is already resumed.
+func (uc *SaveHoldUseCase) Hold(ctx context.Context, server string, opts SaveHoldOptions) (*SaveHold, error) {
+	detail, err := uc.client.GetServer(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	hold := &SaveHold{Server: server, commands: console.SaveCommandsFor(detail.IsBedrock()), client: uc.client, opts: opts}
+	if !detail.IsRunning() {
+		opts.phase(server, SaveSkipped)
+		return hold, nil
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultSaveTimeout
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	lines := make(chan struct{}, 1)
+	confirmed := make(chan struct{}, 1)
+	followErr := make(chan error, 1)
+	var since time.Time
+	listening := make(chan struct{})
+	go func() {
+		followErr <- uc.follow(waitCtx, server, func(at time.Time, line string) {
+			select {
+			case <-listening:
+			default:
+				select {
+				case lines <- struct{}{}:
+				default:
+				}
+				return
+			}
+			if !at.Before(since) && console.SaveConfirmed(line) {
+				select {
+				case confirmed <- struct{}{}:
+				default:
+				}
+			}
+		})
+	}()
+	uc.awaitReplay(waitCtx, lines)
+	since = uc.now().Truncate(time.Second)
+	close(listening)
+
+	opts.phase(server, SaveHolding)
+	if err := uc.client.SendConsoleCommand(ctx, server, hold.commands.Hold); err != nil {
+		return nil, fmt.Errorf("pause saving on %s: %w", server, err)
+	}
+	hold.Held = true
+	fail := func(err error) (*SaveHold, error) {
+		return nil, errors.Join(err, hold.Release(context.WithoutCancel(ctx)))
+	}
+
+	opts.phase(server, SaveFlushing)
+	if err := uc.client.SendConsoleCommand(ctx, server, hold.commands.Flush); err != nil {
+		return fail(fmt.Errorf("flush %s: %w", server, err))
+	}
+	retry := time.NewTicker(hold.commands.Retry)
+	defer retry.Stop()
+	for {
+		select {
+		case <-confirmed:
+			opts.phase(server, SaveFlushed)
+			return hold, nil
+		case err := <-followErr:
+			if waitCtx.Err() == nil {
+				if err == nil {
+					err = errors.New("the stream ended")
+				}
+				return fail(fmt.Errorf("follow the console of %s: %w", server, err))
+			}
+		case <-retry.C:
+			// Bedrock only confirms a query once the files are ready, and a
+			// flush sent before the stream caught up goes unseen.
+			if err := uc.client.SendConsoleCommand(ctx, server, hold.commands.Flush); err != nil {
+				return fail(fmt.Errorf("flush %s: %w", server, err))
+			}
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return fail(ctx.Err())
+			}
+			return fail(fmt.Errorf("%s: %w within %s", server, ErrSaveUnconfirmed, timeout))
+		}
+	}
+}
+
+// awaitReplay waits for the recent lines a console stream starts with to
+// pass, as they may hold an older confirmation: until the stream has been
+// quiet for a moment, or settle is up.
+func (uc *SaveHoldUseCase) awaitReplay(ctx context.Context, lines <-chan struct{}) {
+	deadline := time.After(uc.settle)
+	quiet := time.NewTimer(uc.settle)
+	defer quiet.Stop()
+	for {
+		select {
+		case <-lines:
+			quiet.Reset(250 * time.Millisecond)
+		case <-quiet.C:
+			return
+		case <-deadline:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/integrity"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/java"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/security"
+)
+
+// SecurityScanUseCase checks servers against known CVEs and, for Paper
+// servers, looks for newer builds whose changes mention security fixes.
+// PaperMC being unreachable leaves the build check unknown rather than
+// failing the scan.
+type SecurityScanUseCase struct {
+	client ports.ApiClient
+	paper  ports.PaperReleases
+}
+
+func NewSecurityScanUseCase(client ports.ApiClient, paper ports.PaperReleases) *SecurityScanUseCase {
+	return &SecurityScanUseCase{client: client, paper: paper}
+}
+
+// Execute scans the named servers, or every server when none are given.
+func (uc *SecurityScanUseCase) Execute(ctx context.Context, servers []string) ([]security.Report, error) {
+	if len(servers) == 0 {
+		list, err := uc.client.ListServers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, server := range list {
+			servers = append(servers, server.Name)
+		}
+		sort.Strings(servers)
+	}
+
+	reports := make([]security.Report, 0, len(servers))
+	for _, name := range servers {
+		report, err := uc.scanServer(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func (uc *SecurityScanUseCase) scanServer(ctx context.Context, name string) (security.Report, error) {
+	report := security.Report{Server: name, Findings: []security.Finding{}}
+	detail, err := uc.client.GetServer(ctx, name)
+	if err != nil {
+		return report, err
+	}
+	if detail.IsBedrock() {
+		report.Software = "bedrock"
+		report.Findings = append(report.Findings, security.Finding{
+			Check:  security.Log4ShellCheck,
+			Status: security.OK,
+			Detail: "Bedrock servers do not use log4j",
+		})
+		return report, nil
+	}
+
+	var jarFile, profile, tweaks string
+	if cfg := detail.Config; cfg != nil {
+		if cfg.Java.JarFile != nil {
+			jarFile = *cfg.Java.JarFile
+		}
+		if cfg.Minecraft.Profile != nil {
+			profile = *cfg.Minecraft.Profile
+		}
+		if cfg.Java.JavaTweaks != nil {
+			tweaks = *cfg.Java.JavaTweaks
+		}
+	}
+
+	jar, known := integrity.ParseServerJar(jarFile)
+	switch {
+	case known && jar.Software == "custom":
+		report.Software = "custom"
+		if profiles, err := uc.client.ListCustomProfiles(ctx); err == nil {
+			for _, p := range profiles {
+				if p.Id == jar.Profile {
+					report.Software = p.Software
+					report.Version = p.Version
+				}
+			}
+		}
+	case known:
+		report.Software = jar.Software
+		report.Version = jar.Version
+	default:
+		if prefix, _, ok := strings.Cut(jarFile, "-"); ok {
+			report.Software = strings.ToLower(prefix)
+		}
+		report.Version = java.ExtractMinecraftVersion(profile, jarFile)
+	}
+
+	var buildTime time.Time
+	if report.Software == "paper" && report.Version != "" {
+		var finding security.Finding
+		finding, buildTime = uc.checkPaperBuild(ctx, report.Version, jar.Build)
+		report.Findings = append(report.Findings, finding)
+	}
+
+	properties := security.ParseJavaProperties(tweaks)
+	env, err := uc.client.GetServerEnvironment(ctx, name)
+	if err != nil {
+		return report, err
+	}
+	for key, value := range env.SystemProperties {
+		properties[key] = value
+	}
+
+	input := security.Log4ShellInput{
+		Version:     report.Version,
+		Properties:  properties,
+		Environment: env.Environment,
+		BuildTime:   buildTime,
+	}
+	if mitigation, affected := security.Log4ShellFor(report.Version); affected {
+		report.Log4j = mitigation.Log4j
+		if mitigation.ConfigFile != "" {
+			input.ConfigFileExists = uc.fileExists(ctx, name, mitigation.ConfigFile)
+		}
+	}
+	// The Log4Shell finding leads the report; it is the one that matters.
+	report.Findings = append([]security.Finding{security.CheckLog4Shell(input)}, report.Findings...)
+	return report, nil
+}
+
+func (uc *SecurityScanUseCase) checkPaperBuild(ctx context.Context, version string, installed int) (security.Finding, time.Time) {
+	finding := security.Finding{Check: security.PaperBuildCheck, Status: security.Unknown}
+	if installed == 0 {
+		finding.Detail = "the jar name has no build number"
+		return finding, time.Time{}
+	}
+	history, err := uc.paper.PaperBuildHistory(ctx, version)
+	if err != nil {
+		finding.Detail = "could not reach PaperMC: " + err.Error()
+		return finding, time.Time{}
+	}
+
+	index := -1
+	for i, build := range history {
+		if build.Build == installed {
+			index = i
+		}
+	}
+	if index < 0 {
+		finding.Detail = fmt.Sprintf("build %d is not a published Paper %s build", installed, version)
+		return finding, time.Time{}
+	}
+
+	current := history[index]
+	newer := history[index+1:]
+	if len(newer) == 0 {
+		finding.Status = security.OK
+		finding.Detail = fmt.Sprintf("build %d is the latest", installed)
+		return finding, current.Time
+	}
+
+	latest := newer[len(newer)-1]
+	var fixes []string
+	for _, build := range newer {
+		for _, change := range build.Changes {
+			if security.MentionsSecurityFix(change) {
+				fixes = append(fixes, fmt.Sprintf("#%d %s", build.Build, firstLine(change)))
+			}
+		}
+	}
+	if len(fixes) == 0 {
+		finding.Status = security.OK
+		finding.Detail = fmt.Sprintf("build %d; %d newer build(s) up to %d, none mention security fixes", installed, len(newer), latest.Build)
+		return finding, current.Time
+	}
+	finding.Status = security.Outdated
+	finding.Detail = fmt.Sprintf("build %d; newer builds fix: %s", installed, strings.Join(fixes, "; "))
+	finding.Fix = fmt.Sprintf("update to Paper %s build %d", version, latest.Build)
+	return finding, current.Time
+}
+
+// fileExists reports whether a file is in the server directory, or nil when
+// the API could not tell.
+func (uc *SecurityScanUseCase) fileExists(ctx context.Context, server, file string) *bool {
+	_, err := uc.client.ReadServerFile(ctx, server, file)
+	exists := err == nil
+	if err != nil && !errors.Is(err, ports.ErrNotFound) {
+		return nil
+	}
+	return &exists
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
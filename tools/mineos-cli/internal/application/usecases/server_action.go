@@ -2,10 +2,16 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
 )
 
+// ErrEulaNotAccepted is returned when a Java server would stop immediately
+// because eula.txt has not been accepted.
+var ErrEulaNotAccepted = errors.New("EULA not accepted")
+
 type ServerActionUseCase struct {
 	client ports.ApiClient
 }
@@ -15,5 +21,36 @@ func NewServerActionUseCase(client ports.ApiClient) *ServerActionUseCase {
 }
 
 func (uc *ServerActionUseCase) Execute(ctx context.Context, name, action string) error {
+	if action == "start" {
+		if detail, err := uc.client.GetServer(ctx, name); err == nil && !detail.IsBedrock() && !detail.EulaAccepted {
+			return fmt.Errorf("%s: %w", name, ErrEulaNotAccepted)
+		}
+	}
 	return uc.client.ServerAction(ctx, name, action)
 }
+
+// CanEnsure reports whether Ensure supports action. Restarting always
+// changes the server, so it has no idempotent form.
+func CanEnsure(action string) bool {
+	return action == "start" || action == "stop" || action == "kill"
+}
+
+// Ensure runs action only when the server is not already in the state it
+// leads to: start when stopped, stop or kill when running. It reports whether
+// the action ran.
+func (uc *ServerActionUseCase) Ensure(ctx context.Context, name, action string) (bool, error) {
+	if !CanEnsure(action) {
+		return false, fmt.Errorf("%s has no idempotent form", action)
+	}
+	detail, err := uc.client.GetServer(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	if detail.IsRunning() == (action == "start") {
+		return false, nil
+	}
+	if err := uc.Execute(ctx, name, action); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+var (
+	envNamePattern      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	propertyNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.\-]*$`)
+)
+
+// EnvironmentChange is one edit of a server's environment. Properties are
+// Java system properties; Unset removes the keys instead of setting them.
+type EnvironmentChange struct {
+	Environment map[string]string
+	Properties  map[string]string
+	Unset       bool
+}
+
+type ServerEnvironmentUseCase struct {
+	client ports.ApiClient
+}
+
+func NewServerEnvironmentUseCase(client ports.ApiClient) *ServerEnvironmentUseCase {
+	return &ServerEnvironmentUseCase{client: client}
+}
+
+func (uc *ServerEnvironmentUseCase) Get(ctx context.Context, server string) (ports.ServerEnvironment, error) {
+	env, err := uc.client.GetServerEnvironment(ctx, server)
+	if err != nil {
+		return ports.ServerEnvironment{}, err
+	}
+	if env.Environment == nil {
+		env.Environment = map[string]string{}
+	}
+	if env.SystemProperties == nil {
+		env.SystemProperties = map[string]string{}
+	}
+	return env, nil
+}
+
+// Apply makes the change and returns the resulting environment, and whether
+// anything was different. Names are checked here so a typo is reported
+// before the API is asked.
+func (uc *ServerEnvironmentUseCase) Apply(ctx context.Context, server string, change EnvironmentChange) (ports.ServerEnvironment, bool, error) {
+	for key := range change.Environment {
+		if !envNamePattern.MatchString(key) {
+			return ports.ServerEnvironment{}, false, fmt.Errorf("%q is not a valid environment variable name", key)
+		}
+	}
+	for key := range change.Properties {
+		if !propertyNamePattern.MatchString(key) {
+			return ports.ServerEnvironment{}, false, fmt.Errorf("%q is not a valid system property name", key)
+		}
+	}
+
+	env, err := uc.Get(ctx, server)
+	if err != nil {
+		return ports.ServerEnvironment{}, false, err
+	}
+	changed := applyEntries(env.Environment, change.Environment, change.Unset)
+	changed = applyEntries(env.SystemProperties, change.Properties, change.Unset) || changed
+	if !changed {
+		return env, false, nil
+	}
+	if err := uc.client.UpdateServerEnvironment(ctx, server, env); err != nil {
+		return ports.ServerEnvironment{}, false, err
+	}
+	return env, true, nil
+}
+
+func applyEntries(current, change map[string]string, unset bool) bool {
+	changed := false
+	for key, value := range change {
+		existing, ok := current[key]
+		switch {
+		case unset && ok:
+			delete(current, key)
+			changed = true
+		case !unset && (!ok || existing != value):
+			current[key] = value
+			changed = true
+		}
+	}
+	return changed
+}
+
+// LooksSecret reports names whose values are masked when listed, such as
+// VELOCITY_SECRET or rcon.password.
+func LooksSecret(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, word := range []string{"SECRET", "TOKEN", "PASSWORD", "PASSWD", "KEY"} {
+		if strings.Contains(upper, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/portmap"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+type ServerPortsUseCase struct {
+	client ports.ApiClient
+}
+
+func NewServerPortsUseCase(client ports.ApiClient) *ServerPortsUseCase {
+	return &ServerPortsUseCase{client: client}
+}
+
+// Execute collects the port allocations of every server. The running map
+// reports which servers are currently up so callers can tell their own
+// listeners apart from foreign host processes.
+func (uc *ServerPortsUseCase) Execute(ctx context.Context) ([]portmap.Allocation, map[string]bool, error) {
+	servers, err := uc.client.ListServers(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var allocs []portmap.Allocation
+	running := map[string]bool{}
+	for _, server := range servers {
+		running[server.Name] = server.Status == "running"
+		serverAllocs, err := uc.ForServer(ctx, server.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		allocs = append(allocs, serverAllocs...)
+	}
+	portmap.Sort(allocs)
+	return allocs, running, nil
+}
+
+func (uc *ServerPortsUseCase) ForServer(ctx context.Context, name string) ([]portmap.Allocation, error) {
+	serverType := "java"
+	if detail, err := uc.client.GetServer(ctx, name); err == nil && detail.ServerType != "" {
+		serverType = detail.ServerType
+	}
+	props, err := uc.client.GetServerProperties(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return portmap.FromProperties(name, serverType, props), nil
+}
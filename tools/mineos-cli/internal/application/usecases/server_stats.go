@@ -0,0 +1,64 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+type ServerStatsUseCase struct {
+	client ports.ApiClient
+}
+
+func NewServerStatsUseCase(client ports.ApiClient) *ServerStatsUseCase {
+	return &ServerStatsUseCase{client: client}
+}
+
+// Execute gathers a snapshot of server metrics. The realtime sample is
+// required; memory, world sizes and history are best-effort since they are
+// unavailable while a server is stopped.
+func (uc *ServerStatsUseCase) Execute(ctx context.Context, name string, historyMinutes int) (ports.ServerStats, error) {
+	sample, err := uc.client.GetPerformance(ctx, name)
+	if err != nil {
+		return ports.ServerStats{}, err
+	}
+	stats := ports.ServerStats{Performance: sample}
+
+	if memory, err := uc.client.GetMemory(ctx, name); err == nil {
+		stats.Memory = memory
+	}
+	if worlds, err := uc.client.ListWorlds(ctx, name); err == nil {
+		stats.Worlds = worlds
+	}
+	if historyMinutes > 0 {
+		if history, err := uc.client.GetPerformanceHistory(ctx, name, historyMinutes); err == nil {
+			stats.TpsHistory = history
+		}
+	}
+
+	return stats, nil
+}
+
+// SampleAll returns the current sample of every server. Stopped servers get
+// a sample with IsRunning false so the history shows when they were down.
+func (uc *ServerStatsUseCase) SampleAll(ctx context.Context) ([]ports.PerformanceSample, error) {
+	servers, err := uc.client.ListServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	samples := make([]ports.PerformanceSample, 0, len(servers))
+	for _, server := range servers {
+		sample := ports.PerformanceSample{ServerName: server.Name, Timestamp: now}
+		if server.Status == "running" {
+			if current, err := uc.client.GetPerformance(ctx, server.Name); err == nil {
+				sample = current
+				sample.ServerName = server.Name
+				sample.Timestamp = now
+			}
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// ServerTagsFile is stored in each server directory through the files API so
+// tags follow the server rather than the machine running the CLI.
+const ServerTagsFile = ".mineos-tags"
+
+type ServerTagsUseCase struct {
+	client ports.ApiClient
+}
+
+func NewServerTagsUseCase(client ports.ApiClient) *ServerTagsUseCase {
+	return &ServerTagsUseCase{client: client}
+}
+
+func (uc *ServerTagsUseCase) Get(ctx context.Context, name string) ([]string, error) {
+	content, err := uc.client.ReadServerFile(ctx, name, ServerTagsFile)
+	if err != nil {
+		if errors.Is(err, ports.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return normalizeTags(strings.Split(content, "\n")), nil
+}
+
+func (uc *ServerTagsUseCase) Set(ctx context.Context, name string, tags []string) error {
+	tags = normalizeTags(tags)
+	content := strings.Join(tags, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	return uc.client.WriteServerFile(ctx, name, ServerTagsFile, content)
+}
+
+// Add adds tags and reports whether the server's tags changed. Nothing is
+// written when they did not.
+func (uc *ServerTagsUseCase) Add(ctx context.Context, name string, tags ...string) ([]string, bool, error) {
+	current, err := uc.Get(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	return uc.update(ctx, name, current, normalizeTags(append(current, tags...)))
+}
+
+// Remove drops tags and reports whether the server's tags changed.
+func (uc *ServerTagsUseCase) Remove(ctx context.Context, name string, tags ...string) ([]string, bool, error) {
+	current, err := uc.Get(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	drop := map[string]bool{}
+	for _, tag := range normalizeTags(tags) {
+		drop[tag] = true
+	}
+	updated := make([]string, 0, len(current))
+	for _, tag := range current {
+		if !drop[tag] {
+			updated = append(updated, tag)
+		}
+	}
+	return uc.update(ctx, name, current, updated)
+}
+
+func (uc *ServerTagsUseCase) update(ctx context.Context, name string, current, updated []string) ([]string, bool, error) {
+	if slices.Equal(current, updated) {
+		return updated, false, nil
+	}
+	if err := uc.Set(ctx, name, updated); err != nil {
+		return nil, false, err
+	}
+	return updated, true, nil
+}
+
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || strings.HasPrefix(tag, "#") || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -2,10 +2,26 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"path"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
 )
 
+// Stop phases reported while stopping servers one by one.
+const (
+	StopSaving   = "saving"
+	StopStopping = "stopping"
+	StopStopped  = "stopped"
+	StopTimeout  = "timeout"
+	StopFailed   = "failed"
+	StopSkipped  = "skipped"
+	StopExcluded = "excluded"
+)
+
 type StopAllServersUseCase struct {
 	client ports.ApiClient
 }
@@ -17,3 +33,122 @@ func NewStopAllServersUseCase(client ports.ApiClient) *StopAllServersUseCase {
 func (uc *StopAllServersUseCase) Execute(ctx context.Context, timeoutSeconds int) (ports.StopAllResult, error) {
 	return uc.client.StopAll(ctx, timeoutSeconds)
 }
+
+type StopAllOptions struct {
+	TimeoutSeconds int
+	// Timeouts overrides TimeoutSeconds for individual servers.
+	Timeouts map[string]int
+	// Exclude lists names or glob patterns of servers to leave running.
+	Exclude []string
+	// Parallel caps how many servers stop at once; zero stops all at once
+	// like the API's stop-all.
+	Parallel int
+	// OnPhase is called, one call at a time, as each running server moves
+	// through saving, stopping and stopped (or timeout, failed).
+	OnPhase func(name, phase string, err error)
+}
+
+// Excluded reports whether opts keeps the server running.
+func (opts StopAllOptions) Excluded(name string) bool {
+	for _, pattern := range opts.Exclude {
+		if ok, _ := path.Match(pattern, name); ok || pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Running returns the running servers Stream would stop, sorted by name.
+func (opts StopAllOptions) Running(servers []ports.Server) []string {
+	var names []string
+	for _, server := range servers {
+		if strings.EqualFold(server.Status, "running") && !opts.Excluded(server.Name) {
+			names = append(names, server.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stream stops the running servers itself instead of in one API call, so
+// progress can be shown per server: a Java server is told to save-all
+// first, then stopped with its own timeout. The result has the same shape
+// as the API's stop-all; excluded servers are listed as "excluded".
+func (uc *StopAllServersUseCase) Stream(ctx context.Context, servers []ports.Server, opts StopAllOptions) ports.StopAllResult {
+	result := ports.StopAllResult{Total: len(servers)}
+	running := opts.Running(servers)
+	items := map[string]*ports.StopAllItem{}
+	for _, server := range servers {
+		item := ports.StopAllItem{Name: server.Name, Status: StopSkipped}
+		if strings.EqualFold(server.Status, "running") && opts.Excluded(server.Name) {
+			item.Status = StopExcluded
+		}
+		result.Results = append(result.Results, item)
+	}
+	sort.Slice(result.Results, func(i, j int) bool { return result.Results[i].Name < result.Results[j].Name })
+	for i := range result.Results {
+		items[result.Results[i].Name] = &result.Results[i]
+	}
+	result.Running = len(running)
+	result.Skipped = len(servers) - len(running)
+
+	parallel := opts.Parallel
+	if parallel <= 0 {
+		parallel = max(len(running), 1)
+	}
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	report := func(name, phase string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		item := items[name]
+		item.Status = phase
+		if err != nil {
+			item.Error = err.Error()
+		}
+		if phase == StopStopped {
+			result.Stopped++
+		}
+		if opts.OnPhase != nil {
+			opts.OnPhase(name, phase, err)
+		}
+	}
+
+	for _, name := range running {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				report(name, StopFailed, ctx.Err())
+				return
+			}
+			defer func() { <-sem }()
+
+			if detail, err := uc.client.GetServer(ctx, name); err == nil && !detail.IsBedrock() {
+				report(name, StopSaving, nil)
+				// The console runs commands in order, so the stop below waits
+				// for the save; a failure here does not hold the stop up.
+				_ = uc.client.SendConsoleCommand(ctx, name, "save-all flush")
+			}
+			report(name, StopStopping, nil)
+			timeout := opts.TimeoutSeconds
+			if override, ok := opts.Timeouts[name]; ok && override > 0 {
+				timeout = override
+			}
+			err := uc.client.ServerActionWithTimeout(ctx, name, "stop", timeout)
+			switch {
+			case err == nil:
+				report(name, StopStopped, nil)
+			case errors.Is(err, ports.ErrStopTimeout):
+				report(name, StopTimeout, err)
+			default:
+				report(name, StopFailed, err)
+			}
+		}(name)
+	}
+	wg.Wait()
+	return result
+}
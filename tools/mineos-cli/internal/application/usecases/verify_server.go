@@ -0,0 +1,130 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/integrity"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// VerifyServerUseCase compares the hashes of a server's jar, mods and plugins
+// with the ones Mojang, PaperMC, Modrinth or the custom profile published.
+// An upstream that cannot be reached leaves its files unknown rather than
+// failing the whole check.
+type VerifyServerUseCase struct {
+	client   ports.ApiClient
+	upstream ports.UpstreamHashes
+}
+
+func NewVerifyServerUseCase(client ports.ApiClient, upstream ports.UpstreamHashes) *VerifyServerUseCase {
+	return &VerifyServerUseCase{client: client, upstream: upstream}
+}
+
+func (uc *VerifyServerUseCase) Execute(ctx context.Context, server string) (integrity.Report, error) {
+	hashes, err := uc.client.GetServerFileHashes(ctx, server)
+	if err != nil {
+		return integrity.Report{}, err
+	}
+	report := integrity.Report{Server: server, Checks: []integrity.Check{}}
+
+	var jars []ports.ServerFileHash
+	var sha512s []string
+	for _, file := range hashes.Files {
+		if file.Kind == "server" {
+			report.Checks = append(report.Checks, uc.checkServerJar(ctx, file))
+			continue
+		}
+		jars = append(jars, file)
+		sha512s = append(sha512s, strings.ToLower(file.Sha512))
+	}
+
+	known, lookupErr := uc.upstream.ModrinthFiles(ctx, sha512s)
+	for _, file := range jars {
+		check := newCheck(file)
+		switch modrinth, ok := known[strings.ToLower(file.Sha512)]; {
+		case lookupErr != nil:
+			check.Detail = "could not reach Modrinth: " + lookupErr.Error()
+		case ok:
+			check.Status = integrity.Verified
+			check.Source = "modrinth"
+			check.Detail = strings.TrimSpace(fallbackString(modrinth.Project, modrinth.ProjectID) + " " + modrinth.Version)
+			if modrinth.FileName != "" && modrinth.FileName != path.Base(file.Path) {
+				check.Detail += " (published as " + modrinth.FileName + ")"
+			}
+		default:
+			check.Detail = "not published on Modrinth: installed by hand, from CurseForge, or modified"
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report, nil
+}
+
+func (uc *VerifyServerUseCase) checkServerJar(ctx context.Context, file ports.ServerFileHash) integrity.Check {
+	check := newCheck(file)
+	jar, ok := integrity.ParseServerJar(path.Base(file.Path))
+	if !ok {
+		check.Detail = "not installed from a MineOS profile; nothing to compare with"
+		return check
+	}
+
+	switch jar.Software {
+	case "vanilla":
+		check.Source = "mojang"
+		expected, err := uc.upstream.MojangServerSha1(ctx, jar.Version)
+		switch {
+		case err != nil:
+			check.Detail = "could not look up Mojang's hash: " + err.Error()
+		case strings.EqualFold(expected, file.Sha1):
+			check.Status = integrity.Verified
+			check.Detail = "vanilla " + jar.Version
+		default:
+			check.Status = integrity.Mismatch
+			check.Detail = fmt.Sprintf("SHA-1 differs from Mojang's vanilla %s jar (%s)", jar.Version, expected)
+		}
+	case "paper":
+		check.Source = "paper"
+		builds, err := uc.upstream.PaperBuilds(ctx, jar.Version)
+		build, found := builds[strings.ToLower(file.Sha256)]
+		switch {
+		case err != nil:
+			check.Detail = "could not look up Paper's builds: " + err.Error()
+		case found:
+			check.Status = integrity.Verified
+			check.Detail = fmt.Sprintf("paper %s build %d", jar.Version, build)
+		case len(builds) == 0:
+			check.Detail = "Paper lists no builds for " + jar.Version
+		default:
+			check.Status = integrity.Mismatch
+			check.Detail = fmt.Sprintf("SHA-256 matches none of the %d Paper %s builds", len(builds), jar.Version)
+		}
+	case "custom":
+		check.Source = "profile"
+		profiles, err := uc.client.ListCustomProfiles(ctx)
+		if err != nil {
+			check.Detail = "could not read custom profiles: " + err.Error()
+			return check
+		}
+		for _, profile := range profiles {
+			if profile.Id != jar.Profile {
+				continue
+			}
+			if strings.EqualFold(profile.Sha256, file.Sha256) {
+				check.Status = integrity.Verified
+				check.Detail = "matches the uploaded " + profile.Id
+			} else {
+				check.Status = integrity.Mismatch
+				check.Detail = "differs from the uploaded " + profile.Id + " (changed since, or the profile was replaced)"
+			}
+			return check
+		}
+		check.Detail = "profile " + jar.Profile + " no longer exists"
+	}
+	return check
+}
+
+func newCheck(file ports.ServerFileHash) integrity.Check {
+	return integrity.Check{Path: file.Path, Kind: file.Kind, Status: integrity.Unknown, Sha256: file.Sha256}
+}
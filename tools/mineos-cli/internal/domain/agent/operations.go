@@ -0,0 +1,114 @@
+// Package agent defines the operations the mineos agent may run on behalf of
+// remote callers.
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Operation is a whitelisted CLI invocation. Callers only choose the
+// operation and its parameters; the argument list is built here.
+type Operation struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Params      []string `json:"params,omitempty"`
+	// OptIn operations are left out of the default allow list and must be
+	// named with --allow.
+	OptIn bool `json:"optIn,omitempty"`
+	build func(params map[string]string) []string
+}
+
+var paramPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+var operations = []Operation{
+	{Name: "servers.start", Description: "Start a server", Params: []string{"server"}, build: serverArgs("start")},
+	{Name: "servers.stop", Description: "Stop a server", Params: []string{"server"}, build: serverArgs("stop")},
+	{Name: "servers.restart", Description: "Restart a server", Params: []string{"server"}, build: serverArgs("restart")},
+	{Name: "servers.backup", Description: "Create an incremental backup of a server", Params: []string{"server"}, build: serverArgs("backup")},
+	{Name: "servers.stop-all", Description: "Stop all running servers", build: fixedArgs("servers", "stop-all")},
+	{Name: "stack.restart", Description: "Restart the MineOS containers", build: fixedArgs("stack", "restart")},
+	{Name: "stack.update", Description: "Pull new images and recreate the containers", build: fixedArgs("stack", "update")},
+	// Relays for 'network test --relay': probe a Minecraft port from this host.
+	{Name: "network.test", Description: "Test the connection to a Java server from this host", Params: []string{"host", "port"}, OptIn: true, build: networkTestArgs(false)},
+	{Name: "network.test-bedrock", Description: "Test the connection to a Bedrock server from this host", Params: []string{"host", "port"}, OptIn: true, build: networkTestArgs(true)},
+}
+
+func serverArgs(action string) func(map[string]string) []string {
+	return func(params map[string]string) []string {
+		return []string{"servers", action, params["server"]}
+	}
+}
+
+func networkTestArgs(bedrock bool) func(map[string]string) []string {
+	return func(params map[string]string) []string {
+		args := []string{"network", "test", params["host"] + ":" + params["port"], "--json"}
+		if bedrock {
+			args = append(args, "--bedrock")
+		}
+		return args
+	}
+}
+
+func fixedArgs(args ...string) func(map[string]string) []string {
+	return func(map[string]string) []string {
+		return append([]string(nil), args...)
+	}
+}
+
+// Operations returns every operation the agent knows about.
+func Operations() []Operation {
+	return append([]Operation(nil), operations...)
+}
+
+func Find(name string) (Operation, bool) {
+	for _, op := range operations {
+		if op.Name == name {
+			return op, true
+		}
+	}
+	return Operation{}, false
+}
+
+// Names returns the sorted operation names.
+func Names() []string {
+	names := make([]string, 0, len(operations))
+	for _, op := range operations {
+		names = append(names, op.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Args validates params and returns the CLI arguments for the operation.
+// Values are restricted to name-like strings so they can never be read as
+// flags or shell syntax.
+func (o Operation) Args(params map[string]string) ([]string, error) {
+	for _, name := range o.Params {
+		value := strings.TrimSpace(params[name])
+		if value == "" {
+			return nil, fmt.Errorf("%s requires parameter %q", o.Name, name)
+		}
+		if !paramPattern.MatchString(value) {
+			return nil, fmt.Errorf("invalid value for %q", name)
+		}
+		params[name] = value
+	}
+	for name := range params {
+		if !contains(o.Params, name) {
+			return nil, fmt.Errorf("%s does not accept parameter %q", o.Name, name)
+		}
+	}
+	return o.build(params), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+// Package alerts evaluates user-defined rules such as "tps < 15 for 5m"
+// against server samples and decides when to notify.
+package alerts
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// DefaultCooldown is how long a rule stays quiet after notifying, unless it
+// sets its own cooldown.
+const DefaultCooldown = 30 * time.Minute
+
+// Definition is the alerts file.
+type Definition struct {
+	Rules  []Rule `yaml:"rules"`
+	Notify Notify `yaml:"notify"`
+}
+
+// Notify lists where notifications go. Discord defaults to the
+// Discord__WebhookUrl the API uses.
+type Notify struct {
+	Discord  string   `yaml:"discord"`
+	Webhooks []string `yaml:"webhooks"`
+}
+
+// Rule notifies when When holds for at least For.
+type Rule struct {
+	Name string `yaml:"name"`
+	// Servers are names or glob patterns; empty means every server. Disk
+	// rules are host-wide and ignore it.
+	Servers []string `yaml:"servers"`
+	// When is "<metric> <op> <value>" with metric tps, cpu, memory, players
+	// or disk, op one of < <= > >=, and value a number, a percentage for
+	// memory ("90%") or a size for disk ("5GB"); or just "down".
+	When     string        `yaml:"when"`
+	For      time.Duration `yaml:"for"`
+	Cooldown time.Duration `yaml:"cooldown"`
+	Message  string        `yaml:"message"`
+}
+
+// Condition is a parsed Rule.When.
+type Condition struct {
+	Metric  string
+	Op      string
+	Value   float64
+	Percent bool
+}
+
+const (
+	MetricTps     = "tps"
+	MetricCPU     = "cpu"
+	MetricMemory  = "memory"
+	MetricPlayers = "players"
+	MetricDisk    = "disk"
+	MetricDown    = "down"
+)
+
+func (d Definition) Validate() error {
+	seen := map[string]bool{}
+	for i, rule := range d.Rules {
+		if _, err := ParseCondition(rule.When); err != nil {
+			return fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		if rule.For < 0 || rule.Cooldown < 0 {
+			return fmt.Errorf("rules[%d] (%s): durations must not be negative", i, rule.Label())
+		}
+		if seen[rule.Label()] {
+			return fmt.Errorf("rules[%d]: duplicate rule %q; give it a name", i, rule.Label())
+		}
+		seen[rule.Label()] = true
+	}
+	return nil
+}
+
+// Label is the rule's name, or its condition when it has none.
+func (r Rule) Label() string {
+	if name := strings.TrimSpace(r.Name); name != "" {
+		return name
+	}
+	return strings.TrimSpace(r.When)
+}
+
+func (r Rule) cooldown() time.Duration {
+	if r.Cooldown > 0 {
+		return r.Cooldown
+	}
+	return DefaultCooldown
+}
+
+func (r Rule) matchesServer(name string) bool {
+	if len(r.Servers) == 0 {
+		return true
+	}
+	for _, pattern := range r.Servers {
+		if ok, _ := path.Match(pattern, name); ok || pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCondition reads a rule's when expression.
+func ParseCondition(expression string) (Condition, error) {
+	fields := strings.Fields(strings.ToLower(expression))
+	if len(fields) == 1 && fields[0] == MetricDown {
+		return Condition{Metric: MetricDown}, nil
+	}
+	if len(fields) != 3 {
+		return Condition{}, fmt.Errorf("when %q must be \"<metric> <op> <value>\" or \"down\"", expression)
+	}
+	cond := Condition{Metric: fields[0], Op: fields[1]}
+	switch cond.Metric {
+	case MetricTps, MetricCPU, MetricMemory, MetricPlayers, MetricDisk:
+	default:
+		return Condition{}, fmt.Errorf("when %q: unknown metric %q (use tps, cpu, memory, players, disk or down)", expression, cond.Metric)
+	}
+	switch cond.Op {
+	case "<", "<=", ">", ">=":
+	default:
+		return Condition{}, fmt.Errorf("when %q: unknown operator %q", expression, cond.Op)
+	}
+
+	raw := fields[2]
+	var err error
+	switch {
+	case cond.Metric == MetricDisk:
+		cond.Value, err = parseSize(raw)
+	case strings.HasSuffix(raw, "%"):
+		if cond.Metric != MetricMemory && cond.Metric != MetricCPU {
+			return Condition{}, fmt.Errorf("when %q: percentages only apply to memory and cpu", expression)
+		}
+		cond.Percent = true
+		cond.Value, err = strconv.ParseFloat(strings.TrimSuffix(raw, "%"), 64)
+	case cond.Metric == MetricMemory:
+		// A plain memory value is megabytes, like the API reports it.
+		if number, plainErr := strconv.ParseFloat(raw, 64); plainErr == nil {
+			cond.Value = number
+		} else {
+			cond.Value, err = parseSize(raw)
+			cond.Value /= 1 << 20
+		}
+	default:
+		cond.Value, err = strconv.ParseFloat(raw, 64)
+	}
+	if err != nil {
+		return Condition{}, fmt.Errorf("when %q: invalid value %q", expression, raw)
+	}
+	return cond, nil
+}
+
+// parseSize reads "5GB", "512MB" or "2TiB" as bytes; a plain number is
+// bytes. Decimal and binary suffixes are both taken as powers of 1024, the
+// way free space is usually shown.
+func parseSize(raw string) (float64, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	multiplier := 1.0
+	for _, unit := range []struct {
+		suffix string
+		factor float64
+	}{
+		{"tib", 1 << 40}, {"gib", 1 << 30}, {"mib", 1 << 20}, {"kib", 1 << 10},
+		{"tb", 1 << 40}, {"gb", 1 << 30}, {"mb", 1 << 20}, {"kb", 1 << 10},
+		{"t", 1 << 40}, {"g", 1 << 30}, {"m", 1 << 20}, {"k", 1 << 10}, {"b", 1},
+	} {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, multiplier = number, unit.factor
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q", raw)
+	}
+	return number * multiplier, nil
+}
+
+// Snapshot is what one evaluation looks at.
+type Snapshot struct {
+	Time    time.Time
+	Samples []ports.PerformanceSample
+	// DiskFree is the free space in bytes where server data lives; nil when
+	// it could not be read.
+	DiskFree *int64
+	DiskPath string
+}
+
+// holds reports whether c holds for the sample and the observed value. ok
+// is false when the sample has no value for the metric, such as TPS of a
+// stopped server; the rule then neither fires nor resolves.
+func (c Condition) holds(sample ports.PerformanceSample) (holds bool, value float64, ok bool) {
+	switch c.Metric {
+	case MetricDown:
+		return !sample.IsRunning, 0, true
+	case MetricTps:
+		if !sample.IsRunning || sample.Tps == nil {
+			return false, 0, false
+		}
+		value = *sample.Tps
+	case MetricCPU:
+		if !sample.IsRunning {
+			return false, 0, false
+		}
+		value = sample.CpuPercent
+	case MetricPlayers:
+		if !sample.IsRunning {
+			return false, 0, false
+		}
+		value = float64(sample.PlayerCount)
+	case MetricMemory:
+		if !sample.IsRunning {
+			return false, 0, false
+		}
+		value = float64(sample.RamUsedMb)
+		if c.Percent {
+			if sample.RamTotalMb <= 0 {
+				return false, 0, false
+			}
+			value = value / float64(sample.RamTotalMb) * 100
+		}
+	default:
+		return false, 0, false
+	}
+	return c.compare(value), value, true
+}
+
+func (c Condition) compare(value float64) bool {
+	switch c.Op {
+	case "<":
+		return value < c.Value
+	case "<=":
+		return value <= c.Value
+	case ">":
+		return value > c.Value
+	case ">=":
+		return value >= c.Value
+	}
+	return false
+}
+
+// FormatValue shows an observed value in the metric's unit.
+func (c Condition) FormatValue(value float64) string {
+	switch c.Metric {
+	case MetricTps:
+		return fmt.Sprintf("%.1f", value)
+	case MetricCPU:
+		return fmt.Sprintf("%.0f%%", value)
+	case MetricMemory:
+		if c.Percent {
+			return fmt.Sprintf("%.0f%%", value)
+		}
+		return fmt.Sprintf("%.0f MB", value)
+	case MetricDisk:
+		return fmt.Sprintf("%.1f GB", value/(1<<30))
+	case MetricPlayers:
+		return fmt.Sprintf("%.0f", value)
+	}
+	return ""
+}
@@ -0,0 +1,163 @@
+package alerts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type EventKind string
+
+const (
+	Firing   EventKind = "firing"
+	Resolved EventKind = "resolved"
+)
+
+// Event is a notification the engine wants sent.
+type Event struct {
+	Kind      EventKind
+	Rule      Rule
+	Condition Condition
+	// Subject is the server name, or "host" for disk rules.
+	Subject string
+	Value   string
+	// Since is when the condition started to hold.
+	Since time.Time
+	Time  time.Time
+	// Reminder is set when the rule is still firing after its cooldown.
+	Reminder bool
+}
+
+// Title is a one-line summary such as "Alert: low-tps on survival".
+func (e Event) Title() string {
+	prefix := "Alert"
+	switch {
+	case e.Kind == Resolved:
+		prefix = "Resolved"
+	case e.Reminder:
+		prefix = "Still firing"
+	}
+	return fmt.Sprintf("%s: %s on %s", prefix, e.Rule.Label(), e.Subject)
+}
+
+// Text describes the event for the notification body.
+func (e Event) Text() string {
+	var b strings.Builder
+	duration := e.Time.Sub(e.Since).Round(time.Second)
+	switch {
+	case e.Condition.Metric == MetricDown && e.Kind == Firing:
+		fmt.Fprintf(&b, "%s is down%s.", e.Subject, forDuration(duration))
+	case e.Condition.Metric == MetricDown:
+		fmt.Fprintf(&b, "%s is running again.", e.Subject)
+	case e.Kind == Firing:
+		fmt.Fprintf(&b, "%s is %s (rule: %s)%s.", e.Condition.Metric, e.Value, strings.TrimSpace(e.Rule.When), forDuration(duration))
+	default:
+		fmt.Fprintf(&b, "%s is back to %s.", e.Condition.Metric, e.Value)
+	}
+	if message := strings.TrimSpace(e.Rule.Message); message != "" && e.Kind == Firing {
+		b.WriteString("\n" + message)
+	}
+	return b.String()
+}
+
+func forDuration(d time.Duration) string {
+	if d < time.Second {
+		return ""
+	}
+	return " for " + d.String()
+}
+
+type ruleState struct {
+	since        time.Time
+	firing       bool
+	notified     bool
+	lastNotified time.Time
+}
+
+// Engine remembers, per rule and server, since when a condition holds and
+// when it last notified. The zero value is not usable; use NewEngine.
+type Engine struct {
+	states map[string]*ruleState
+}
+
+func NewEngine() *Engine {
+	return &Engine{states: map[string]*ruleState{}}
+}
+
+// Evaluate checks every rule against the snapshot. A rule fires once its
+// condition has held for For, and notifies again only after its cooldown,
+// both while it keeps firing and when it clears and fires again. A resolved
+// event follows every firing event that was sent.
+func (e *Engine) Evaluate(def Definition, snapshot Snapshot) []Event {
+	now := snapshot.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	var events []Event
+	for _, rule := range def.Rules {
+		cond, err := ParseCondition(rule.When)
+		if err != nil {
+			continue
+		}
+		if cond.Metric == MetricDisk {
+			if snapshot.DiskFree == nil {
+				continue
+			}
+			value := float64(*snapshot.DiskFree)
+			if event, ok := e.step(rule, cond, "host", cond.compare(value), cond.FormatValue(value), now); ok {
+				events = append(events, event)
+			}
+			continue
+		}
+		for _, sample := range snapshot.Samples {
+			if !rule.matchesServer(sample.ServerName) {
+				continue
+			}
+			holds, value, ok := cond.holds(sample)
+			if !ok {
+				continue
+			}
+			if event, ok := e.step(rule, cond, sample.ServerName, holds, cond.FormatValue(value), now); ok {
+				events = append(events, event)
+			}
+		}
+	}
+	return events
+}
+
+func (e *Engine) step(rule Rule, cond Condition, subject string, holds bool, value string, now time.Time) (Event, bool) {
+	key := rule.Label() + "\x00" + subject
+	state := e.states[key]
+	if state == nil {
+		state = &ruleState{}
+		e.states[key] = state
+	}
+	event := Event{Rule: rule, Condition: cond, Subject: subject, Value: value, Since: state.since, Time: now}
+
+	if !holds {
+		wasNotified := state.firing && state.notified
+		state.since, state.firing, state.notified = time.Time{}, false, false
+		if wasNotified {
+			event.Kind = Resolved
+			return event, true
+		}
+		return Event{}, false
+	}
+
+	if state.since.IsZero() {
+		state.since = now
+		event.Since = now
+	}
+	if now.Sub(state.since) < rule.For {
+		return Event{}, false
+	}
+	state.firing = true
+	if !state.lastNotified.IsZero() && now.Sub(state.lastNotified) < rule.cooldown() {
+		return Event{}, false
+	}
+	event.Kind = Firing
+	event.Reminder = state.notified
+	state.notified = true
+	state.lastNotified = now
+	return event, true
+}
@@ -0,0 +1,338 @@
+package anvil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// SectorSize is the allocation unit of a region file.
+const SectorSize = 4096
+
+// HeaderSize covers the location and timestamp tables.
+const HeaderSize = 2 * SectorSize
+
+// ChunksPerRegion is the 32x32 grid of chunks stored in one region file.
+const ChunksPerRegion = 1024
+
+// Compression schemes stored in the chunk header.
+const (
+	CompressionGzip = 1
+	CompressionZlib = 2
+	CompressionNone = 3
+	CompressionLZ4  = 4
+	// External marks chunks too large for the region file, stored in a
+	// c.<x>.<z>.mcc file beside it.
+	External = 128
+)
+
+var regionName = regexp.MustCompile(`^r\.(-?\d+)\.(-?\d+)\.mca$`)
+
+// RegionCoords parses "r.-1.2.mca" into its region coordinates.
+func RegionCoords(fileName string) (int, int, bool) {
+	m := regionName.FindStringSubmatch(fileName)
+	if m == nil {
+		return 0, 0, false
+	}
+	x, _ := strconv.Atoi(m[1])
+	z, _ := strconv.Atoi(m[2])
+	return x, z, true
+}
+
+// Chunk identifies a slot in a region file.
+type Chunk struct {
+	Index int
+	// X and Z are world chunk coordinates; multiply by 16 for blocks.
+	X int
+	Z int
+}
+
+// ChunkAt returns the chunk in slot index of region (rx, rz).
+func ChunkAt(rx, rz, index int) Chunk {
+	return Chunk{Index: index, X: rx*32 + index%32, Z: rz*32 + index/32}
+}
+
+// ExternalFile is the file beside the region that holds the data of an
+// external chunk.
+func ExternalFile(chunk Chunk) string {
+	return fmt.Sprintf("c.%d.%d.mcc", chunk.X, chunk.Z)
+}
+
+// Problem is one defect found in a region file. Chunk is nil for defects of
+// the file as a whole.
+type Problem struct {
+	Chunk  *Chunk
+	Reason string
+}
+
+func (p Problem) String() string {
+	if p.Chunk == nil {
+		return p.Reason
+	}
+	return fmt.Sprintf("chunk %d,%d (blocks %d,%d): %s", p.Chunk.X, p.Chunk.Z, p.Chunk.X*16, p.Chunk.Z*16, p.Reason)
+}
+
+// Region is a parsed region file.
+type Region struct {
+	X, Z int
+	Data []byte
+}
+
+// location returns the sector offset and count of a chunk slot.
+func (r Region) location(index int) (int, int) {
+	entry := binary.BigEndian.Uint32(r.Data[index*4:])
+	return int(entry >> 8), int(entry & 0xff)
+}
+
+// Present reports whether a slot holds a chunk.
+func (r Region) Present(index int) bool {
+	offset, count := r.location(index)
+	return offset != 0 || count != 0
+}
+
+// External reports whether a chunk's data is in its ExternalFile, with only
+// a stub in the region.
+func (r Region) External(index int) bool {
+	offset, _ := r.location(index)
+	start := offset * SectorSize
+	return offset >= 2 && start+5 <= len(r.Data) && r.Data[start+4]&External != 0
+}
+
+// Timestamp is when a chunk was last saved.
+func (r Region) Timestamp(index int) time.Time {
+	seconds := binary.BigEndian.Uint32(r.Data[SectorSize+index*4:])
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(seconds), 0)
+}
+
+// Check validates the header and every chunk: sector bounds, overlapping
+// allocations, length fields, compression and the start of the NBT payload.
+// externalExists reports whether a c.x.z.mcc file exists for a chunk stored
+// outside the region; nil skips that check.
+func Check(region Region, externalExists func(Chunk) bool) []Problem {
+	return check(region, externalExists, true)
+}
+
+// CheckHeader validates the header only, without decompressing the chunks:
+// every location must fall inside the file without overlapping another,
+// and every chunk's length field must fit its sectors. It is quick enough
+// for every region file of a backup.
+func CheckHeader(region Region, externalExists func(Chunk) bool) []Problem {
+	return check(region, externalExists, false)
+}
+
+func check(region Region, externalExists func(Chunk) bool, payloads bool) []Problem {
+	size := len(region.Data)
+	if size == 0 {
+		return nil
+	}
+	if size < HeaderSize {
+		return []Problem{{Reason: fmt.Sprintf("truncated header (%d of %d bytes)", size, HeaderSize)}}
+	}
+
+	var problems []Problem
+	if size%SectorSize != 0 {
+		problems = append(problems, Problem{Reason: fmt.Sprintf("size %d is not a multiple of %d; the last sector is truncated", size, SectorSize)})
+	}
+	sectors := (size + SectorSize - 1) / SectorSize
+	owner := make([]int, sectors)
+	for i := range owner {
+		owner[i] = -1
+	}
+
+	// Each chunk is reported once; reported maps a slot to its problem.
+	reported := map[int]int{}
+	for index := 0; index < ChunksPerRegion; index++ {
+		if !region.Present(index) {
+			continue
+		}
+		chunk := ChunkAt(region.X, region.Z, index)
+		reason, sector, other := region.checkChunk(index, sectors, owner, externalExists, payloads)
+		if reason == "" {
+			continue
+		}
+		reported[index] = len(problems)
+		problems = append(problems, Problem{Chunk: &chunk, Reason: reason})
+		// Either chunk of an overlap may hold the damaged data, so the one
+		// that claimed the sector first is reported too.
+		if _, ok := reported[other]; other >= 0 && !ok {
+			first := ChunkAt(region.X, region.Z, other)
+			reported[other] = len(problems)
+			problems = append(problems, Problem{Chunk: &first, Reason: fmt.Sprintf("shares sector %d with chunk %d,%d", sector, chunk.X, chunk.Z)})
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := problems[i].Chunk, problems[j].Chunk
+		return a == nil && b != nil || a != nil && b != nil && a.Index < b.Index
+	})
+	return problems
+}
+
+// checkChunk returns why a chunk is damaged, or "". When its sectors overlap
+// an earlier chunk's, it also returns the first shared sector and the slot
+// of that chunk; other is -1 otherwise.
+func (r Region) checkChunk(index, sectors int, owner []int, externalExists func(Chunk) bool, payload bool) (reason string, sector, other int) {
+	offset, count := r.location(index)
+	switch {
+	case offset < 2:
+		return "location points into the header", 0, -1
+	case count == 0:
+		return "allocated zero sectors", 0, -1
+	case offset+count > sectors:
+		return fmt.Sprintf("data runs past the end of the file (sectors %d-%d of %d)", offset, offset+count-1, sectors), 0, -1
+	}
+	for sector := offset; sector < offset+count; sector++ {
+		if owner[sector] >= 0 {
+			other := ChunkAt(r.X, r.Z, owner[sector])
+			return fmt.Sprintf("shares sector %d with chunk %d,%d", sector, other.X, other.Z), sector, owner[sector]
+		}
+		owner[sector] = index
+	}
+	return r.checkData(index, offset, count, externalExists, payload), 0, -1
+}
+
+// checkData validates the length, compression and payload of a chunk whose
+// sectors are in bounds.
+func (r Region) checkData(index, offset, count int, externalExists func(Chunk) bool, payload bool) string {
+
+	start := offset * SectorSize
+	if start+5 > len(r.Data) {
+		return "chunk header is truncated"
+	}
+	length := int(binary.BigEndian.Uint32(r.Data[start:]))
+	compression := r.Data[start+4]
+	if length == 0 {
+		return "length is zero"
+	}
+	if length-1 > count*SectorSize-5 || start+4+length > len(r.Data) {
+		return fmt.Sprintf("length %d exceeds its %d allocated sector(s)", length, count)
+	}
+	if compression&External != 0 {
+		if externalExists != nil && !externalExists(ChunkAt(r.X, r.Z, index)) {
+			return "stored externally but the .mcc file is missing"
+		}
+		return ""
+	}
+	if !payload {
+		return ""
+	}
+	data, err := decompress(compression, r.Data[start+5:start+4+length])
+	if err != nil {
+		return err.Error()
+	}
+	if len(data) < 3 || data[0] != 10 {
+		return "payload is not an NBT compound"
+	}
+	return ""
+}
+
+// ErrLZ4 is returned for LZ4 chunks, which are not decoded here.
+var ErrLZ4 = errors.New("lz4 compression is not supported")
+
+func decompress(compression byte, payload []byte) ([]byte, error) {
+	var reader io.Reader
+	switch compression {
+	case CompressionGzip:
+		gz, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("gzip data is corrupt: %w", err)
+		}
+		reader = gz
+	case CompressionZlib:
+		zr, err := zlib.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("zlib data is corrupt: %w", err)
+		}
+		reader = zr
+	case CompressionNone:
+		return payload, nil
+	case CompressionLZ4:
+		// Accept LZ4 chunks (1.20.5+ region-file-compression=lz4) untested
+		// rather than flagging every one of them.
+		return []byte{10, 0, 0}, nil
+	default:
+		return nil, fmt.Errorf("unknown compression type %d", compression)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("compressed data is corrupt: %w", err)
+	}
+	return data, nil
+}
+
+// ChunkData returns the decompressed NBT of a chunk.
+func (r Region) ChunkData(index int) ([]byte, error) {
+	if len(r.Data) < HeaderSize || !r.Present(index) {
+		return nil, errors.New("chunk is not present")
+	}
+	offset, count := r.location(index)
+	start := offset * SectorSize
+	if offset < 2 || start+5 > len(r.Data) {
+		return nil, errors.New("chunk location is invalid")
+	}
+	length := int(binary.BigEndian.Uint32(r.Data[start:]))
+	if length == 0 || length-1 > count*SectorSize-5 || start+4+length > len(r.Data) {
+		return nil, errors.New("chunk length is invalid")
+	}
+	compression := r.Data[start+4]
+	if compression == CompressionLZ4 {
+		return nil, ErrLZ4
+	}
+	if compression&External != 0 {
+		return nil, errors.New("chunk is stored in an external .mcc file")
+	}
+	return decompress(compression, r.Data[start+5:start+4+length])
+}
+
+// DeleteChunk clears a chunk's header entries so the game regenerates it.
+// The data sectors are left in place and reused by the game later.
+func (r *Region) DeleteChunk(index int) {
+	binary.BigEndian.PutUint32(r.Data[index*4:], 0)
+	binary.BigEndian.PutUint32(r.Data[SectorSize+index*4:], 0)
+}
+
+// CopyChunk replaces a chunk with the same slot from another region, e.g. a
+// backup. The copied sectors are appended to the file. For an external
+// chunk they are only the stub: the caller copies its ExternalFile too.
+func (r *Region) CopyChunk(index int, from Region) error {
+	if len(from.Data) < HeaderSize || !from.Present(index) {
+		return errors.New("chunk is not present in the source region")
+	}
+	offset, count := from.location(index)
+	start := offset * SectorSize
+	if offset < 2 || start+5 > len(from.Data) {
+		return errors.New("chunk location in the source region is invalid")
+	}
+	length := int(binary.BigEndian.Uint32(from.Data[start:]))
+	if length == 0 || start+4+length > len(from.Data) {
+		return errors.New("chunk in the source region is truncated")
+	}
+	if count > 255 {
+		return errors.New("chunk is too large to copy")
+	}
+
+	// Pad the file to a sector boundary before appending.
+	if rem := len(r.Data) % SectorSize; rem != 0 {
+		r.Data = append(r.Data, make([]byte, SectorSize-rem)...)
+	}
+	newOffset := len(r.Data) / SectorSize
+	if newOffset >= 1<<24 {
+		return errors.New("region file is too large")
+	}
+	chunk := make([]byte, count*SectorSize)
+	copy(chunk, from.Data[start:min(start+count*SectorSize, len(from.Data))])
+	r.Data = append(r.Data, chunk...)
+	binary.BigEndian.PutUint32(r.Data[index*4:], uint32(newOffset)<<8|uint32(count))
+	copy(r.Data[SectorSize+index*4:SectorSize+index*4+4], from.Data[SectorSize+index*4:SectorSize+index*4+4])
+	return nil
+}
@@ -0,0 +1,237 @@
+package anvil
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// nbtStub is the smallest valid chunk payload: an empty root compound.
+var nbtStub = []byte{10, 0, 0, 0}
+
+type testChunk struct {
+	index, offset, count int
+	// length overrides the chunk header's length field; zeroLength writes 0.
+	length      int
+	zeroLength  bool
+	compression byte
+	payload     []byte
+}
+
+// buildRegion lays out a region file of the given number of sectors.
+func buildRegion(t *testing.T, sectors int, chunks ...testChunk) Region {
+	t.Helper()
+	data := make([]byte, sectors*SectorSize)
+	for _, c := range chunks {
+		binary.BigEndian.PutUint32(data[c.index*4:], uint32(c.offset)<<8|uint32(c.count))
+		binary.BigEndian.PutUint32(data[SectorSize+c.index*4:], 1700000000)
+		start := c.offset * SectorSize
+		if start+5 > len(data) {
+			continue
+		}
+		length := c.length
+		if length == 0 && !c.zeroLength {
+			length = len(c.payload) + 1
+		}
+		binary.BigEndian.PutUint32(data[start:], uint32(length))
+		data[start+4] = c.compression
+		copy(data[start+5:], c.payload)
+	}
+	return Region{Data: data}
+}
+
+func zlibStub(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(nbtStub)
+	w.Close()
+	return buf.Bytes()
+}
+
+type found struct {
+	index  int // -1 for the file as a whole
+	reason string
+}
+
+func summarize(problems []Problem) []found {
+	var out []found
+	for _, p := range problems {
+		index := -1
+		if p.Chunk != nil {
+			index = p.Chunk.Index
+		}
+		out = append(out, found{index, p.Reason})
+	}
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	ok := func(index, offset int) testChunk {
+		return testChunk{index: index, offset: offset, count: 1, compression: CompressionNone, payload: nbtStub}
+	}
+	tests := []struct {
+		name     string
+		region   Region
+		external func(Chunk) bool
+		want     []found
+	}{
+		{
+			name:   "empty file",
+			region: Region{},
+		},
+		{
+			name:   "healthy chunks",
+			region: buildRegion(t, 5, ok(0, 2), ok(1, 3), testChunk{index: 2, offset: 4, count: 1, compression: CompressionZlib, payload: zlibStub(t)}),
+		},
+		{
+			name:   "truncated header",
+			region: Region{Data: make([]byte, SectorSize)},
+			want:   []found{{-1, "truncated header (4096 of 8192 bytes)"}},
+		},
+		{
+			name: "truncated last sector",
+			region: func() Region {
+				r := buildRegion(t, 3, ok(0, 2))
+				r.Data = r.Data[:len(r.Data)-10]
+				return r
+			}(),
+			want: []found{{-1, "size 12278 is not a multiple of 4096; the last sector is truncated"}},
+		},
+		{
+			name:   "location in the header",
+			region: buildRegion(t, 3, ok(0, 1)),
+			want:   []found{{0, "location points into the header"}},
+		},
+		{
+			name:   "zero sectors",
+			region: buildRegion(t, 3, testChunk{index: 0, offset: 2}),
+			want:   []found{{0, "allocated zero sectors"}},
+		},
+		{
+			name:   "past the end",
+			region: buildRegion(t, 3, testChunk{index: 5, offset: 2, count: 2}),
+			want:   []found{{5, "data runs past the end of the file (sectors 2-3 of 3)"}},
+		},
+		{
+			name:   "overlap reports both chunks",
+			region: buildRegion(t, 4, testChunk{index: 0, offset: 2, count: 2, compression: CompressionNone, payload: nbtStub}, ok(1, 3)),
+			want: []found{
+				{0, "shares sector 3 with chunk 1,0"},
+				{1, "shares sector 3 with chunk 0,0"},
+			},
+		},
+		{
+			name: "overlap with an already damaged chunk",
+			region: buildRegion(t, 4,
+				testChunk{index: 0, offset: 2, count: 2, compression: 9, payload: nbtStub},
+				ok(1, 3)),
+			want: []found{
+				{0, "unknown compression type 9"},
+				{1, "shares sector 3 with chunk 0,0"},
+			},
+		},
+		{
+			name:   "zero length",
+			region: buildRegion(t, 3, testChunk{index: 0, offset: 2, count: 1, zeroLength: true}),
+			want:   []found{{0, "length is zero"}},
+		},
+		{
+			name:   "length past its sectors",
+			region: buildRegion(t, 4, testChunk{index: 0, offset: 2, count: 1, length: SectorSize + 10, compression: CompressionNone}),
+			want:   []found{{0, "length 4106 exceeds its 1 allocated sector(s)"}},
+		},
+		{
+			name:   "not a compound",
+			region: buildRegion(t, 3, testChunk{index: 0, offset: 2, count: 1, compression: CompressionNone, payload: []byte{8, 0, 0}}),
+			want:   []found{{0, "payload is not an NBT compound"}},
+		},
+		{
+			name:     "external chunk present",
+			region:   buildRegion(t, 3, testChunk{index: 0, offset: 2, count: 1, compression: External | CompressionZlib}),
+			external: func(Chunk) bool { return true },
+		},
+		{
+			name:     "external chunk missing",
+			region:   buildRegion(t, 3, testChunk{index: 0, offset: 2, count: 1, compression: External | CompressionZlib}),
+			external: func(Chunk) bool { return false },
+			want:     []found{{0, "stored externally but the .mcc file is missing"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarize(Check(tt.region, tt.external))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Check = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckHeaderSkipsPayloads(t *testing.T) {
+	region := buildRegion(t, 3, testChunk{index: 0, offset: 2, count: 1, compression: CompressionZlib, payload: []byte("not zlib")})
+	if problems := CheckHeader(region, nil); len(problems) != 0 {
+		t.Fatalf("CheckHeader = %v, want none", problems)
+	}
+	if problems := Check(region, nil); len(problems) != 1 {
+		t.Fatalf("Check = %v, want the corrupt payload", problems)
+	}
+}
+
+func TestChunkAt(t *testing.T) {
+	tests := []struct {
+		rx, rz, index int
+		want          Chunk
+	}{
+		{0, 0, 0, Chunk{Index: 0, X: 0, Z: 0}},
+		{0, 0, 33, Chunk{Index: 33, X: 1, Z: 1}},
+		{-1, 2, 1023, Chunk{Index: 1023, X: -1, Z: 95}},
+	}
+	for _, tt := range tests {
+		if got := ChunkAt(tt.rx, tt.rz, tt.index); got != tt.want {
+			t.Errorf("ChunkAt(%d, %d, %d) = %+v, want %+v", tt.rx, tt.rz, tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestRegionCoords(t *testing.T) {
+	tests := []struct {
+		name string
+		x, z int
+		ok   bool
+	}{
+		{"r.0.0.mca", 0, 0, true},
+		{"r.-1.2.mca", -1, 2, true},
+		{"r.1.mcr", 0, 0, false},
+		{"c.1.2.mcc", 0, 0, false},
+	}
+	for _, tt := range tests {
+		x, z, ok := RegionCoords(tt.name)
+		if x != tt.x || z != tt.z || ok != tt.ok {
+			t.Errorf("RegionCoords(%q) = %d, %d, %v", tt.name, x, z, ok)
+		}
+	}
+}
+
+func TestCopyAndDeleteChunk(t *testing.T) {
+	backup := buildRegion(t, 3, testChunk{index: 7, offset: 2, count: 1, compression: CompressionZlib, payload: zlibStub(t)})
+	region := buildRegion(t, 3, testChunk{index: 7, offset: 2, count: 1, compression: 9, payload: nbtStub})
+
+	if err := region.CopyChunk(7, backup); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if problems := Check(region, nil); len(problems) != 0 {
+		t.Fatalf("after copy: %v", problems)
+	}
+	data, err := region.ChunkData(7)
+	if err != nil || !bytes.Equal(data, nbtStub) {
+		t.Fatalf("ChunkData = %v, %v", data, err)
+	}
+
+	region.DeleteChunk(7)
+	if region.Present(7) || !region.Timestamp(7).IsZero() {
+		t.Fatal("chunk still present after delete")
+	}
+}
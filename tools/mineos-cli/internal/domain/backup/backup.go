@@ -0,0 +1,210 @@
+// Package backup describes host-side server backups: tar archives of a server
+// directory with a manifest listing every file, so a backup can store only
+// what changed since the one before it.
+package backup
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatZstd Format = "tar.zst"
+	FormatGzip Format = "tar.gz"
+	// FormatZip is single threaded, kept for tools that only read zip.
+	FormatZip Format = "zip"
+)
+
+// ParseFormat accepts a format or its short name (zstd, gzip).
+func ParseFormat(value string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "zstd", "zst", "tar.zst":
+		return FormatZstd, nil
+	case "gzip", "gz", "tar.gz", "tgz":
+		return FormatGzip, nil
+	case "zip":
+		return FormatZip, nil
+	default:
+		return "", fmt.Errorf("unknown backup format %q (use zstd, gzip or zip)", value)
+	}
+}
+
+// DefaultLevel is the compression level used when none is given.
+func (f Format) DefaultLevel() int {
+	if f == FormatZstd {
+		return 3
+	}
+	return 6
+}
+
+// ValidLevel reports whether level suits the format: 1-22 for zstd, 1-9 for
+// gzip and zip.
+func (f Format) ValidLevel(level int) error {
+	maxLevel := 9
+	if f == FormatZstd {
+		maxLevel = 22
+	}
+	if level < 1 || level > maxLevel {
+		return fmt.Errorf("%s compression level must be between 1 and %d", f, maxLevel)
+	}
+	return nil
+}
+
+// ChangeDetection decides which files an incremental backup stores.
+type ChangeDetection string
+
+const (
+	// ByMtime stores files whose size or modification time changed.
+	ByMtime ChangeDetection = "mtime"
+	// ByHash hashes every file and stores those whose content changed,
+	// slower but immune to tools that restore old modification times.
+	ByHash ChangeDetection = "hash"
+)
+
+type EntryType string
+
+const (
+	TypeFile    EntryType = "file"
+	TypeDir     EntryType = "dir"
+	TypeSymlink EntryType = "symlink"
+)
+
+// Entry is one path of the backed up tree.
+type Entry struct {
+	Path    string      `json:"path"`
+	Type    EntryType   `json:"type"`
+	Mode    fs.FileMode `json:"mode"`
+	Size    int64       `json:"size,omitempty"`
+	ModTime time.Time   `json:"modTime"`
+	Link    string      `json:"link,omitempty"`
+	Sha256  string      `json:"sha256,omitempty"`
+	// Archive is the ID of the backup whose archive holds the file's
+	// content: this one, or an earlier one in the chain.
+	Archive string `json:"archive,omitempty"`
+}
+
+// Manifest describes a backup. Its Entries list the whole tree as it was, so
+// restoring it needs this archive and the ones Entries point at.
+type Manifest struct {
+	ID      string    `json:"id"`
+	Server  string    `json:"server"`
+	Created time.Time `json:"created"`
+	Format  Format    `json:"format"`
+	Level   int       `json:"level"`
+	// Parent is the backup this one is incremental to; empty for a full one.
+	Parent   string          `json:"parent,omitempty"`
+	Detect   ChangeDetection `json:"detect,omitempty"`
+	Entries  []Entry         `json:"entries,omitempty"`
+	Stored   int             `json:"stored"`
+	Bytes    int64           `json:"bytes"`
+	Archive  string          `json:"archive"`
+	Size     int64           `json:"size"`
+	Duration time.Duration   `json:"duration"`
+}
+
+// NewID names a backup after its server and time.
+func NewID(server string, at time.Time) string {
+	return server + "-" + at.UTC().Format("20060102-150405")
+}
+
+// Full reports whether the backup stores every file itself.
+func (m Manifest) Full() bool {
+	return m.Parent == ""
+}
+
+// TotalBytes is the size of the tree the backup restores.
+func (m Manifest) TotalBytes() int64 {
+	var total int64
+	for _, entry := range m.Entries {
+		total += entry.Size
+	}
+	return total
+}
+
+// Unchanged reports whether a file can be taken from its previous backup
+// instead of being stored again. Under ByHash both entries need a hash.
+func Unchanged(current, previous Entry, detect ChangeDetection) bool {
+	if current.Type != TypeFile || previous.Type != TypeFile || previous.Archive == "" {
+		return false
+	}
+	if detect == ByHash {
+		return current.Sha256 != "" && current.Sha256 == previous.Sha256
+	}
+	return current.Size == previous.Size && current.ModTime.Equal(previous.ModTime)
+}
+
+// Plan marks which entries this backup stores. Unchanged files point at the
+// archive that already holds them; the rest point at id.
+func Plan(id string, entries []Entry, parent *Manifest, detect ChangeDetection) []Entry {
+	previous := map[string]Entry{}
+	if parent != nil {
+		for _, entry := range parent.Entries {
+			previous[entry.Path] = entry
+		}
+	}
+	planned := make([]Entry, len(entries))
+	for i, entry := range entries {
+		if entry.Type != TypeFile {
+			planned[i] = entry
+			continue
+		}
+		if prev, ok := previous[entry.Path]; ok && Unchanged(entry, prev, detect) {
+			entry.Archive = prev.Archive
+			if entry.Sha256 == "" {
+				entry.Sha256 = prev.Sha256
+			}
+		} else {
+			entry.Archive = id
+		}
+		planned[i] = entry
+	}
+	return planned
+}
+
+// Chain returns the backups a restore of m reads, m first. It fails when one
+// of them is missing from known.
+func Chain(m Manifest, known map[string]Manifest) ([]Manifest, error) {
+	needed := map[string]bool{}
+	for _, entry := range m.Entries {
+		if entry.Archive != "" {
+			needed[entry.Archive] = true
+		}
+	}
+	chain := []Manifest{m}
+	delete(needed, m.ID)
+	ids := make([]string, 0, len(needed))
+	for id := range needed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		parent, ok := known[id]
+		if !ok {
+			return nil, fmt.Errorf("backup %s needs %s, which is missing", m.ID, id)
+		}
+		chain = append(chain, parent)
+	}
+	return chain, nil
+}
+
+// Dependents returns the backups that take files from id.
+func Dependents(id string, all []Manifest) []string {
+	var dependents []string
+	for _, m := range all {
+		if m.ID == id {
+			continue
+		}
+		for _, entry := range m.Entries {
+			if entry.Archive == id {
+				dependents = append(dependents, m.ID)
+				break
+			}
+		}
+	}
+	return dependents
+}
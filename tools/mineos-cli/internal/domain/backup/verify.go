@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report is the outcome of verifying a backup.
+type Report struct {
+	ID     string `json:"id"`
+	Server string `json:"server"`
+	// Archives are the backups whose archives were read, ID first.
+	Archives []string `json:"archives"`
+	Files    int      `json:"files"`
+	Bytes    int64    `json:"bytes"`
+	Regions  int      `json:"regions"`
+	Worlds   []string `json:"worlds"`
+	// Extracted is set when the backup was also restored into a scratch
+	// directory.
+	Extracted bool          `json:"extracted"`
+	Duration  time.Duration `json:"duration"`
+	// Problems make the backup fail verification.
+	Problems []string `json:"problems,omitempty"`
+	// Notes are worth knowing but do not fail it.
+	Notes []string `json:"notes,omitempty"`
+}
+
+func (r Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *Report) Problem(format string, args ...any) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// LevelFile marks the root of a world, Java or Bedrock.
+const LevelFile = "level.dat"
+
+// Worlds finds the worlds of a backed up tree: folders holding level.dat
+// or a region folder. Region folders of dimensions (DIM-1, DIM1 and those
+// under dimensions/) belong to the world above them. missing lists the
+// worlds that have regions but no level.dat.
+func Worlds(entries []Entry) (worlds, missing []string) {
+	levels := map[string]bool{}
+	candidates := map[string]bool{}
+	for _, entry := range entries {
+		dir, name := path.Split(entry.Path)
+		dir = strings.TrimSuffix(dir, "/")
+		switch {
+		case entry.Type == TypeFile && name == LevelFile:
+			levels[dir] = true
+			candidates[dir] = true
+		case entry.Type == TypeDir && name == "region" && !dimensionDir(dir):
+			candidates[dir] = true
+		}
+	}
+	for dir := range candidates {
+		label := dir
+		if label == "" {
+			label = "."
+		}
+		worlds = append(worlds, label)
+		if !levels[dir] {
+			missing = append(missing, label)
+		}
+	}
+	sort.Strings(worlds)
+	sort.Strings(missing)
+	return worlds, missing
+}
+
+func dimensionDir(dir string) bool {
+	base := path.Base(dir)
+	if base == "DIM-1" || base == "DIM1" {
+		return true
+	}
+	for _, segment := range strings.Split(dir, "/") {
+		if segment == "dimensions" {
+			return true
+		}
+	}
+	return false
+}
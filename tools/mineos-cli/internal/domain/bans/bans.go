@@ -0,0 +1,167 @@
+package bans
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// File is the Java server's ban list, relative to the server directory.
+const File = "banned-players.json"
+
+// TimeLayout is the timestamp format Minecraft uses in ban lists.
+const TimeLayout = "2006-01-02 15:04:05 -0700"
+
+const Forever = "forever"
+
+// Entry is one record of banned-players.json.
+type Entry struct {
+	UUID    string `json:"uuid"`
+	Name    string `json:"name"`
+	Created string `json:"created"`
+	Source  string `json:"source"`
+	Expires string `json:"expires"`
+	Reason  string `json:"reason"`
+}
+
+// NewEntry returns a permanent ban issued now.
+func NewEntry(uuid, name, source, reason string, now time.Time) Entry {
+	if reason == "" {
+		reason = "Banned by an operator."
+	}
+	return Entry{
+		UUID:    uuid,
+		Name:    name,
+		Created: now.Format(TimeLayout),
+		Source:  source,
+		Expires: Forever,
+		Reason:  reason,
+	}
+}
+
+// key identifies a player; the name is the fallback for entries without a
+// UUID.
+func (e Entry) key() string {
+	if e.UUID != "" {
+		return strings.ToLower(e.UUID)
+	}
+	return "name:" + strings.ToLower(e.Name)
+}
+
+// Matches reports whether the entry bans the given player name.
+func (e Entry) Matches(name string) bool {
+	return strings.EqualFold(e.Name, name)
+}
+
+type List []Entry
+
+func Parse(content string) (List, error) {
+	if strings.TrimSpace(content) == "" {
+		return List{}, nil
+	}
+	var list List
+	if err := json.Unmarshal([]byte(content), &list); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", File, err)
+	}
+	return list, nil
+}
+
+// Encode writes the list the way Minecraft does: an indented JSON array.
+func (l List) Encode() (string, error) {
+	if l == nil {
+		l = List{}
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+func (l List) Find(name string) (Entry, bool) {
+	for _, entry := range l {
+		if entry.Matches(name) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// With adds or replaces the entry for a player.
+func (l List) With(entry Entry) List {
+	out := l.Without(entry.Name)
+	return append(out, entry)
+}
+
+// Without removes every entry for a player name.
+func (l List) Without(name string) List {
+	out := make(List, 0, len(l))
+	for _, entry := range l {
+		if !entry.Matches(name) {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// Merge returns the union of ban lists. When a player is banned on several
+// servers the earliest entry wins so the original reason is kept.
+func Merge(lists ...List) List {
+	byKey := map[string]Entry{}
+	for _, list := range lists {
+		for _, entry := range list {
+			key := entry.key()
+			current, ok := byKey[key]
+			if !ok || entryTime(entry).Before(entryTime(current)) {
+				byKey[key] = entry
+			}
+		}
+	}
+	merged := make(List, 0, len(byKey))
+	for _, entry := range byKey {
+		merged = append(merged, entry)
+	}
+	sort.Slice(merged, func(i, j int) bool { return strings.ToLower(merged[i].Name) < strings.ToLower(merged[j].Name) })
+	return merged
+}
+
+// Diff reports the players target must ban and pardon to match want.
+func Diff(target, want List) (ban List, pardon List) {
+	have := map[string]bool{}
+	for _, entry := range target {
+		have[entry.key()] = true
+	}
+	wanted := map[string]bool{}
+	for _, entry := range want {
+		wanted[entry.key()] = true
+		if !have[entry.key()] {
+			ban = append(ban, entry)
+		}
+	}
+	for _, entry := range target {
+		if !wanted[entry.key()] {
+			pardon = append(pardon, entry)
+		}
+	}
+	return ban, pardon
+}
+
+func entryTime(entry Entry) time.Time {
+	t, err := time.Parse(TimeLayout, entry.Created)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// OfflineUUID derives the UUID an offline-mode server assigns a player
+// (a version 3 UUID of "OfflinePlayer:<name>").
+func OfflineUUID(name string) string {
+	sum := md5.Sum([]byte("OfflinePlayer:" + name))
+	sum[6] = sum[6]&0x0f | 0x30
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
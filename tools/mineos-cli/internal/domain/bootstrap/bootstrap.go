@@ -0,0 +1,273 @@
+// Package bootstrap renders a script that re-creates a MineOS install on a
+// new machine: it runs the official installer non-interactively with the
+// options of the original install, then writes the .env settings the
+// installer does not ask about.
+package bootstrap
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Formats a plan can be rendered in.
+const (
+	FormatShell      = "sh"
+	FormatPowerShell = "ps1"
+	FormatCloudInit  = "cloud-init"
+)
+
+// Installer URLs, as in the README.
+const (
+	InstallShURL  = "https://mineos.net/install.sh"
+	InstallPs1URL = "https://mineos.net/install.ps1"
+)
+
+// AdminPasswordVar holds the admin password when the script runs, unless it
+// was exported with the secrets.
+const AdminPasswordVar = "MINEOS_ADMIN_PASSWORD"
+
+// Value is an installer flag or .env setting. A secret value is read from
+// the environment variable Var when the script runs, falling back to Value,
+// which is empty unless the secrets were exported.
+type Value struct {
+	Name   string
+	Value  string
+	Secret bool
+	Var    string
+}
+
+// Plan is what the script re-creates.
+type Plan struct {
+	// Header lines, e.g. where and when the plan was exported.
+	Header []string
+	// Version is the release to install; empty installs the latest.
+	Version string
+	// Dir is the install directory, relative to where the script runs.
+	Dir      string
+	Build    bool
+	Flags    []Value
+	Settings []Value
+}
+
+// Render renders the plan in one of the formats.
+func Render(plan Plan, format string) (string, error) {
+	switch format {
+	case FormatShell:
+		return Shell(plan), nil
+	case FormatPowerShell:
+		return PowerShell(plan), nil
+	case FormatCloudInit:
+		return CloudInit(plan), nil
+	}
+	return "", fmt.Errorf("unknown format %q (sh, ps1 or cloud-init)", format)
+}
+
+// Shell renders a bash script for Linux and macOS. Docker is installed with
+// get.docker.com on Linux when it is missing.
+func Shell(plan Plan) string {
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	writeHeader(&b, plan, "#", "bash mineos-bootstrap.sh")
+	b.WriteString("set -euo pipefail\n\n")
+
+	if secrets := plan.secrets(); len(secrets) > 0 {
+		b.WriteString("# Secrets: set these in the environment or fill them in here.\n")
+		for _, value := range secrets {
+			fmt.Fprintf(&b, "%s=${%s:-%s}\n", value.Var, value.Var, shQuote(value.Value))
+		}
+		for _, value := range secrets {
+			fmt.Fprintf(&b, "[ -n \"$%s\" ] || { echo \"[ERR] set %s (%s)\" >&2; exit 1; }\n", value.Var, value.Var, value.Name)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(`if ! command -v docker >/dev/null 2>&1; then
+    if [ "$(uname -s)" = Linux ]; then
+        echo "[INFO] Installing Docker..."
+        curl -fsSL https://get.docker.com | sh
+    else
+        echo "[ERR] Docker is required; install Docker Desktop first." >&2
+        exit 1
+    fi
+fi
+
+`)
+	args := []string{"--dir", shQuote(plan.Dir)}
+	if plan.Version != "" {
+		args = append(args, "--version", shQuote(plan.Version))
+	}
+	args = append(args, "--quiet")
+	if plan.Build {
+		args = append(args, "--build")
+	}
+	for _, flag := range plan.Flags {
+		args = append(args, flag.Name, shValue(flag))
+	}
+	fmt.Fprintf(&b, "curl -fsSL %s | bash -s -- \\\n    %s\n\n", InstallShURL, strings.Join(pairs(args, " "), " \\\n    "))
+
+	fmt.Fprintf(&b, "cd %s\n", shQuote(plan.Dir))
+	if len(plan.Settings) > 0 {
+		b.WriteString(`set_env() {
+    grep -v "^$1=" .env > .env.bootstrap || true
+    printf '%s=%s\n' "$1" "$2" >> .env.bootstrap
+    mv .env.bootstrap .env
+}
+`)
+		for _, setting := range plan.Settings {
+			fmt.Fprintf(&b, "set_env %s %s\n", shQuote(setting.Name), shValue(setting))
+		}
+		b.WriteString("./mineos stack up\n")
+	}
+	b.WriteString("echo \"[INFO] MineOS is set up in $(pwd).\"\n")
+	return b.String()
+}
+
+// PowerShell renders a script for Windows with Docker Desktop.
+func PowerShell(plan Plan) string {
+	var b strings.Builder
+	writeHeader(&b, plan, "#", "powershell -ExecutionPolicy Bypass -File mineos-bootstrap.ps1")
+	b.WriteString("$ErrorActionPreference = \"Stop\"\n\n")
+
+	if secrets := plan.secrets(); len(secrets) > 0 {
+		b.WriteString("# Secrets: set these in the environment or fill them in here.\n")
+		for _, value := range secrets {
+			fmt.Fprintf(&b, "$%s = if ($env:%s) { $env:%s } else { %s }\n", psVar(value.Var), value.Var, value.Var, psQuote(value.Value))
+		}
+		for _, value := range secrets {
+			fmt.Fprintf(&b, "if (-not $%s) { throw \"Set %s (%s).\" }\n", psVar(value.Var), value.Var, value.Name)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(`if (-not (Get-Command docker -ErrorAction SilentlyContinue)) {
+    throw "Docker Desktop is required; install it first."
+}
+
+`)
+	forward := []string{"'--quiet'"}
+	if plan.Build {
+		forward = append(forward, "'--build'")
+	}
+	for _, flag := range plan.Flags {
+		forward = append(forward, psQuote(flag.Name), psValue(flag))
+	}
+	fmt.Fprintf(&b, "$forward = @(\n    %s\n)\n", strings.Join(pairs(forward, ", "), ",\n    "))
+	fmt.Fprintf(&b, "$installer = [scriptblock]::Create((Invoke-WebRequest -Uri %s -UseBasicParsing).Content)\n", psQuote(InstallPs1URL))
+	call := "& $installer -InstallDir " + psQuote(plan.Dir)
+	if plan.Version != "" {
+		call += " -Version " + psQuote(plan.Version)
+	}
+	b.WriteString(call + " -ForwardArgs $forward\n\n")
+
+	fmt.Fprintf(&b, "Set-Location %s\n", psQuote(plan.Dir))
+	if len(plan.Settings) > 0 {
+		b.WriteString(`function Set-EnvValue([string]$Key, [string]$Value) {
+    $lines = @(Get-Content .env | Where-Object { -not $_.StartsWith("$Key=") })
+    $lines += "$Key=$Value"
+    [IO.File]::WriteAllLines((Resolve-Path .env), $lines)
+}
+`)
+		for _, setting := range plan.Settings {
+			fmt.Fprintf(&b, "Set-EnvValue %s %s\n", psQuote(setting.Name), psValue(setting))
+		}
+		b.WriteString(".\\mineos.exe stack up\n")
+	}
+	b.WriteString("Write-Host \"[INFO] MineOS is set up in $(Get-Location).\"\n")
+	return b.String()
+}
+
+// CloudInit renders user-data that installs curl, writes the shell script
+// to /root and runs it on first boot, logging to /var/log.
+func CloudInit(plan Plan) string {
+	var b strings.Builder
+	b.WriteString("#cloud-config\n")
+	writeHeader(&b, plan, "#", "")
+	b.WriteString("package_update: true\npackages:\n  - curl\n  - ca-certificates\nwrite_files:\n")
+	b.WriteString("  - path: /root/mineos-bootstrap.sh\n    permissions: '0700'\n    content: |\n")
+	for _, line := range strings.Split(strings.TrimRight(Shell(plan), "\n"), "\n") {
+		if line == "" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString("      " + line + "\n")
+	}
+	b.WriteString("runcmd:\n  - [bash, -c, \"cd /root && bash /root/mineos-bootstrap.sh > /var/log/mineos-bootstrap.log 2>&1\"]\n")
+	return b.String()
+}
+
+func writeHeader(b *strings.Builder, plan Plan, comment, usage string) {
+	for _, line := range plan.Header {
+		fmt.Fprintf(b, "%s %s\n", comment, line)
+	}
+	if usage != "" {
+		fmt.Fprintf(b, "%s Run on the new machine: %s\n", comment, usage)
+	}
+	fmt.Fprintf(b, "%s Servers and worlds are not included; bring them over with backups or 'mineos migrate'.\n\n", comment)
+}
+
+func (p Plan) secrets() []Value {
+	var secrets []Value
+	for _, value := range append(append([]Value{}, p.Flags...), p.Settings...) {
+		if value.Secret {
+			secrets = append(secrets, value)
+		}
+	}
+	return secrets
+}
+
+var (
+	identifier    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// SecretVar is the variable a secret .env setting is read from: the key
+// itself where it is a valid variable name.
+func SecretVar(key string) string {
+	if identifier.MatchString(key) {
+		return key
+	}
+	return "MINEOS_SECRET_" + nonIdentifier.ReplaceAllString(key, "_")
+}
+
+// pairs keeps a flag and its value on one line, joined by sep.
+func pairs(args []string, sep string) []string {
+	var lines []string
+	for i := 0; i < len(args); i++ {
+		if i+1 < len(args) && strings.HasPrefix(strings.Trim(args[i], "'"), "--") && !strings.HasPrefix(strings.Trim(args[i+1], "'"), "--") {
+			lines = append(lines, args[i]+sep+args[i+1])
+			i++
+			continue
+		}
+		lines = append(lines, args[i])
+	}
+	return lines
+}
+
+func shValue(value Value) string {
+	if value.Secret {
+		return `"$` + value.Var + `"`
+	}
+	return shQuote(value.Value)
+}
+
+func psValue(value Value) string {
+	if value.Secret {
+		return "$" + psVar(value.Var)
+	}
+	return psQuote(value.Value)
+}
+
+// psVar is the script variable holding a secret, apart from $env:.
+func psVar(name string) string {
+	return "secret_" + name
+}
+
+// shQuote makes a value one shell word.
+func shQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func psQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -1,7 +1,21 @@
 package config
 
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Address families the stack's ports can be published on.
+const (
+	BindDual = "dual"
+	BindIPv4 = "ipv4"
+	BindIPv6 = "ipv6"
+)
+
 type Config struct {
 	EnvPath            string
+	EnvOverlays        []string // env files layered over EnvPath, lowest precedence first
 	ApiPort            string
 	WebOrigin          string
 	NetworkMode        string
@@ -16,11 +30,21 @@ type Config struct {
 	DatabaseConnection string
 	DataDirectory      string
 	ShutdownTimeout    string
+	DockerWait         string // seconds compose commands wait for the Docker daemon; "0" fails at once
+	PersistServiceLogs string // "true" for the agent to capture container logs under logs/
 	PreReleaseUpdates  string // "true" to enable pre-release updates, "false" for stable only
 	TelemetryEnabled   string // "true" to enable telemetry, "false" to disable
 	TelemetryEndpoint  string // URL for telemetry endpoint
 	InstallationID     string // UUID for this installation
 	TelemetryKey       string // Bearer token for telemetry API
+	TuiReadOnly        string // "true" to start the TUI without mutating actions
+	ConfirmLevel       string // how destructive per-server actions are confirmed: "name" (default) or "yes"
+	BindAddress        string // compose host-IP prefix for published ports: "", "0.0.0.0:" or "[::]:"
+	ApiMemoryLimit     string // compose memory limit of the api container, e.g. "2g"; empty for none
+	ApiCpuLimit        string // compose CPU limit of the api container, e.g. "1.5"
+	WebMemoryLimit     string
+	WebCpuLimit        string
+	ComposeOverrides   string // comma-separated files in compose-overrides/ added to every compose command
 }
 
 func (c Config) EffectiveApiKey() string {
@@ -37,6 +61,16 @@ func (c Config) IsPreReleaseEnabled() bool {
 	return c.PreReleaseUpdates == "true"
 }
 
+func (c Config) IsTuiReadOnly() bool {
+	return c.TuiReadOnly == "true"
+}
+
+// ConfirmsByName reports whether deleting or restoring a server asks for its
+// name to be typed rather than a y/n.
+func (c Config) ConfirmsByName() bool {
+	return !strings.EqualFold(strings.TrimSpace(c.ConfirmLevel), "yes")
+}
+
 func (c Config) IsTelemetryEnabled() bool {
 	// Default to true if not explicitly set to false
 	return c.TelemetryEnabled != "false"
@@ -49,3 +83,61 @@ func (c Config) EffectiveTelemetryEndpoint() string {
 	// Default to production endpoint
 	return "https://mineos.net"
 }
+
+// BindFamily reports which address family the published ports listen on.
+func (c Config) BindFamily() string {
+	switch strings.TrimSuffix(strings.TrimSpace(c.BindAddress), ":") {
+	case "0.0.0.0", "127.0.0.1":
+		return BindIPv4
+	case "[::]", "[::1]", "::":
+		return BindIPv6
+	}
+	return BindDual
+}
+
+// LoopbackHost is the local address that reaches the published ports.
+// "localhost" lets the dialer try both families on dual-stack hosts.
+func (c Config) LoopbackHost() string {
+	switch c.BindFamily() {
+	case BindIPv4:
+		return "127.0.0.1"
+	case BindIPv6:
+		return "::1"
+	}
+	return "localhost"
+}
+
+// ApiURL is the base URL of the API on this machine.
+func (c Config) ApiURL() string {
+	port := strings.TrimSpace(c.ApiPort)
+	if port == "" {
+		port = "5078"
+	}
+	return "http://" + net.JoinHostPort(c.LoopbackHost(), port)
+}
+
+// MinecraftAddress joins PUBLIC_MINECRAFT_HOST (localhost when unset) with
+// port. The host may be written as a URL, with a port or with IPv6
+// brackets; only the host part is kept.
+func (c Config) MinecraftAddress(port string) string {
+	return net.JoinHostPort(NormalizeHost(c.MinecraftHost, "localhost"), port)
+}
+
+// NormalizeHost reduces a hostname, IP literal, host:port or URL to the bare
+// host, without IPv6 brackets; fallback is used when nothing is left.
+func NormalizeHost(value, fallback string) string {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "://") {
+		if parsed, err := url.Parse(value); err == nil {
+			value = parsed.Host
+		}
+	}
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	if value == "" {
+		return fallback
+	}
+	return value
+}
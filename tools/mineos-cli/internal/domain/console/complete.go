@@ -0,0 +1,139 @@
+// Package console completes Minecraft server console commands as they are
+// typed, with the names of online players for player arguments.
+package console
+
+import "strings"
+
+// syntaxes are the commands offered, one line per form. A word is a literal,
+// alternatives such as <on|off>, <player> for an online player, or any
+// other <placeholder>, which matches a word but is not completed.
+var syntaxes = []string{
+	"list",
+	"say <message>",
+	"msg <player> <message>",
+	"gamemode <survival|creative|adventure|spectator> <player>",
+	"tp <player> <player>",
+	"give <player> <item> <count>",
+	"kill <player>",
+	"kick <player> <reason>",
+	"ban <player> <reason>",
+	"pardon <player>",
+	"op <player>",
+	"deop <player>",
+	"whitelist add <player>",
+	"whitelist remove <player>",
+	"whitelist list",
+	"whitelist on",
+	"whitelist off",
+	"whitelist reload",
+	"time set <day|noon|night|midnight>",
+	"weather <clear|rain|thunder>",
+	"difficulty <peaceful|easy|normal|hard>",
+	"gamerule <rule> <value>",
+	"save-all",
+	"stop",
+}
+
+// Complete returns the lines line can be completed to by finishing its last
+// word, or by adding the next word when line ends in a space. A leading '/'
+// is kept. Candidates come in syntax order, each once.
+func Complete(line string, players []string) []string {
+	prefix, typed := splitLast(line)
+	words := strings.Fields(prefix)
+	slash := ""
+	if len(words) > 0 && strings.HasPrefix(words[0], "/") {
+		words[0] = words[0][1:]
+	} else if len(words) == 0 && strings.HasPrefix(typed, "/") {
+		slash, typed = "/", typed[1:]
+	}
+
+	var candidates []string
+	seen := map[string]bool{}
+	for _, syntax := range syntaxes {
+		parts := strings.Fields(syntax)
+		if len(parts) <= len(words) || !matches(parts[:len(words)], words) {
+			continue
+		}
+		for _, option := range options(parts[len(words)], players) {
+			if !strings.HasPrefix(strings.ToLower(option), strings.ToLower(typed)) || seen[option] {
+				continue
+			}
+			seen[option] = true
+			candidates = append(candidates, prefix+slash+option)
+		}
+	}
+	return candidates
+}
+
+// Usage returns the syntax of the command being typed, or "" while it is
+// not known which one: before the command word is complete, for commands
+// not listed, and for whitelist and the like until their subcommand is.
+func Usage(line string) string {
+	prefix, _ := splitLast(strings.TrimLeft(line, " \t/"))
+	words := strings.Fields(prefix)
+	if len(words) == 0 {
+		return ""
+	}
+	var found string
+	for _, syntax := range syntaxes {
+		parts := strings.Fields(syntax)
+		n := min(len(parts), len(words))
+		if !matches(parts[:n], words[:n]) {
+			continue
+		}
+		if found != "" {
+			return ""
+		}
+		found = syntax
+	}
+	return found
+}
+
+func splitLast(line string) (string, string) {
+	i := strings.LastIndexAny(line, " \t")
+	return line[:i+1], line[i+1:]
+}
+
+func matches(parts, words []string) bool {
+	for i, part := range parts {
+		if !isPlaceholder(part) && !strings.EqualFold(part, words[i]) {
+			return false
+		}
+		if choices := alternatives(part); choices != nil && !containsFold(choices, words[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func options(part string, players []string) []string {
+	switch {
+	case part == "<player>":
+		return players
+	case alternatives(part) != nil:
+		return alternatives(part)
+	case isPlaceholder(part):
+		return nil
+	}
+	return []string{part}
+}
+
+func isPlaceholder(part string) bool {
+	return strings.HasPrefix(part, "<") && strings.HasSuffix(part, ">")
+}
+
+func alternatives(part string) []string {
+	if !isPlaceholder(part) || !strings.Contains(part, "|") {
+		return nil
+	}
+	return strings.Split(part[1:len(part)-1], "|")
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
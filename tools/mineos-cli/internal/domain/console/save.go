@@ -0,0 +1,42 @@
+package console
+
+import (
+	"regexp"
+	"time"
+)
+
+// SaveCommands pause and resume world saving so the files can be copied
+// while the server runs.
+type SaveCommands struct {
+	// Hold stops the server from writing its world.
+	Hold string
+	// Flush asks for everything to be written now.
+	Flush   string
+	Release string
+	// Retry is how often Flush is sent again until it is confirmed:
+	// Bedrock answers a query with "not ready" until its files are.
+	Retry time.Duration
+}
+
+var (
+	javaSave    = SaveCommands{Hold: "save-off", Flush: "save-all flush", Release: "save-on", Retry: 20 * time.Second}
+	bedrockSave = SaveCommands{Hold: "save hold", Flush: "save query", Release: "save resume", Retry: 2 * time.Second}
+)
+
+// SaveCommandsFor returns the save commands of a Java or Bedrock server.
+func SaveCommandsFor(bedrock bool) SaveCommands {
+	if bedrock {
+		return bedrockSave
+	}
+	return javaSave
+}
+
+// savedPattern matches the log lines that confirm a flush: "Saved the game"
+// on Java (older versions and some forks say "world"), "Data saved" on
+// Bedrock once the files can be copied.
+var savedPattern = regexp.MustCompile(`(?i)\bSaved the (game|world)\b|Data saved\. Files are now ready to be copied`)
+
+// SaveConfirmed reports whether a console line confirms a flush.
+func SaveConfirmed(line string) bool {
+	return savedPattern.MatchString(line)
+}
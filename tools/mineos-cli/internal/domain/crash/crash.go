@@ -0,0 +1,295 @@
+package crash
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Dir is where the server writes crash reports, relative to its directory.
+const Dir = "crash-reports"
+
+// Report is the triage-relevant part of a crash-reports/*.txt file.
+type Report struct {
+	Time             time.Time
+	Description      string
+	Exception        string
+	CausedBy         []string
+	Frames           []string
+	SuspectedMods    []string
+	MinecraftVersion string
+	JavaVersion      string
+}
+
+// Root returns the innermost cause, which usually names the real failure.
+func (r Report) Root() string {
+	if len(r.CausedBy) > 0 {
+		return r.CausedBy[len(r.CausedBy)-1]
+	}
+	return r.Exception
+}
+
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.000",
+	"1/2/06, 3:04 PM",
+	"1/2/06 3:04 PM",
+	"02.01.06 15:04",
+}
+
+// Parse extracts the header, first stack trace and system details of a crash
+// report. Unknown sections are ignored.
+func Parse(content string) Report {
+	var report Report
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	inTrace := false
+	inSuspects := false
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		switch {
+		case strings.HasPrefix(line, "Time: ") && report.Time.IsZero():
+			value := strings.TrimPrefix(line, "Time: ")
+			for _, layout := range timeLayouts {
+				if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+					report.Time = t
+					break
+				}
+			}
+			continue
+		case strings.HasPrefix(line, "Description: ") && report.Description == "":
+			report.Description = strings.TrimPrefix(line, "Description: ")
+			// The exception follows the description after a blank line.
+			for j := i + 1; j < len(lines); j++ {
+				if next := strings.TrimSpace(lines[j]); next != "" {
+					report.Exception = next
+					inTrace = true
+					break
+				}
+			}
+			continue
+		case strings.HasPrefix(line, "Minecraft Version: ") && report.MinecraftVersion == "":
+			report.MinecraftVersion = strings.TrimPrefix(line, "Minecraft Version: ")
+		case strings.HasPrefix(line, "Java Version: ") && report.JavaVersion == "":
+			report.JavaVersion = strings.TrimPrefix(line, "Java Version: ")
+		case strings.HasPrefix(line, "Suspected Mod:"), strings.HasPrefix(line, "Suspected Mods:"):
+			value := strings.TrimSpace(line[strings.Index(line, ":")+1:])
+			inSuspects = value == ""
+			if value != "" && !strings.EqualFold(value, "NONE") {
+				report.SuspectedMods = append(report.SuspectedMods, value)
+			}
+			continue
+		}
+
+		if inSuspects {
+			if line == "" || !strings.HasPrefix(raw, "\t") && !strings.HasPrefix(raw, " ") {
+				inSuspects = false
+			} else if !strings.EqualFold(line, "NONE") && !strings.HasPrefix(line, "Issue tracker") && !strings.HasPrefix(line, "at ") {
+				report.SuspectedMods = append(report.SuspectedMods, line)
+			}
+		}
+		if !inTrace || line == report.Exception || line == "" && len(report.Frames) == 0 {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "at "):
+			report.Frames = append(report.Frames, strings.TrimPrefix(line, "at "))
+		case strings.HasPrefix(line, "Caused by: "):
+			report.CausedBy = append(report.CausedBy, strings.TrimPrefix(line, "Caused by: "))
+		case strings.HasPrefix(line, "..."):
+		default:
+			inTrace = false
+		}
+	}
+	return report
+}
+
+// Platform packages that show up in nearly every trace and never point at
+// the culprit.
+var platformPackages = []string{
+	"java.", "javax.", "jdk.", "sun.", "com.sun.",
+	"net.minecraft.", "com.mojang.", "net.minecraftforge.", "net.neoforged.", "cpw.mods.", "net.fabricmc.", "org.quiltmc.",
+	"org.bukkit.", "org.spigotmc.", "io.papermc.", "com.destroystokyo.", "ca.spottedleaf.", "co.aikar.",
+	"org.spongepowered.asm.", "org.objectweb.", "org.apache.", "com.google.", "io.netty.", "it.unimi.", "org.slf4j.",
+	"kotlin.", "scala.", "org.jetbrains.", "com.llamalad7.mixinextras.",
+}
+
+// Mixin handlers embed the owning mod id: handler$zzc000$sodium$onRender.
+var mixinHandler = regexp.MustCompile(`\$[a-z]{3}\d{3}\$([a-z0-9_]+)\$`)
+
+var genericSegments = map[string]bool{
+	"com": true, "org": true, "net": true, "io": true, "me": true, "dev": true, "de": true, "fr": true,
+	"github": true, "gitlab": true, "mod": true, "mods": true, "plugin": true, "plugins": true,
+	"common": true, "core": true, "api": true, "impl": true, "mixin": true, "mixins": true, "forge": true, "fabric": true,
+	"bukkit": true, "spigot": true, "paper": true, "minecraft": true, "server": true, "util": true, "utils": true,
+}
+
+// Suspect is the mod or plugin most likely responsible for a crash.
+type Suspect struct {
+	// Name is the mod id, plugin name or installed jar the report points at.
+	Name string
+	// Jar is the installed file matched to the suspect, if any.
+	Jar string
+	// Reason explains how the suspect was chosen.
+	Reason string
+}
+
+// FindSuspect picks the likely culprit: the loader's own "Suspected Mods"
+// line first, then mixin handlers, then the first non-platform stack frame
+// matched against the installed jars.
+func (r Report) FindSuspect(jars []string) (Suspect, bool) {
+	if len(r.SuspectedMods) > 0 {
+		name := r.SuspectedMods[0]
+		// "Create (create), Version: 0.5.1" -> "Create"
+		if idx := strings.IndexAny(name, "(,"); idx > 0 {
+			name = strings.TrimSpace(name[:idx])
+		}
+		return Suspect{Name: name, Jar: matchJar(tokens(name), jars), Reason: "named by the mod loader: " + r.SuspectedMods[0]}, true
+	}
+
+	for _, frame := range r.Frames {
+		if m := mixinHandler.FindStringSubmatch(frame); m != nil {
+			return Suspect{Name: m[1], Jar: matchJar([]string{m[1]}, jars), Reason: "mixin handler in " + frame}, true
+		}
+	}
+
+	for _, frame := range r.Frames {
+		frame = stripModule(frame)
+		if isPlatform(frame) {
+			continue
+		}
+		segments := packageSegments(frame)
+		if jar := matchJar(segments, jars); jar != "" {
+			return Suspect{Name: jarName(jar), Jar: jar, Reason: "first non-platform frame: " + frame}, true
+		}
+		if name := bestSegment(segments); name != "" {
+			return Suspect{Name: name, Reason: "first non-platform frame: " + frame}, true
+		}
+	}
+	return Suspect{}, false
+}
+
+// stripModule removes module and class loader prefixes such as
+// "TRANSFORMER/create@0.5.1/".
+func stripModule(frame string) string {
+	head := frame
+	if idx := strings.Index(frame, "("); idx >= 0 {
+		head = frame[:idx]
+	}
+	if idx := strings.LastIndex(head, "/"); idx >= 0 {
+		return frame[idx+1:]
+	}
+	return frame
+}
+
+func isPlatform(frame string) bool {
+	for _, prefix := range platformPackages {
+		if strings.HasPrefix(frame, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// packageSegments returns the package parts of a frame, excluding the class
+// and method names.
+func packageSegments(frame string) []string {
+	if idx := strings.Index(frame, "("); idx >= 0 {
+		frame = frame[:idx]
+	}
+	parts := strings.Split(frame, ".")
+	var segments []string
+	for _, part := range parts {
+		if part == "" || part[0] >= 'A' && part[0] <= 'Z' {
+			break
+		}
+		segments = append(segments, strings.ToLower(part))
+	}
+	return segments
+}
+
+func bestSegment(segments []string) string {
+	// Reverse-domain packages put the project after the owner:
+	// com.simibubi.create -> create.
+	for i := len(segments) - 1; i >= 0; i-- {
+		if i <= 2 && !genericSegments[segments[i]] && len(segments[i]) > 2 {
+			return segments[i]
+		}
+	}
+	return ""
+}
+
+func tokens(name string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	return fields
+}
+
+// matchJar finds an installed jar whose name contains one of the candidate
+// tokens, preferring later (more specific) package segments.
+func matchJar(candidates []string, jars []string) string {
+	for i := len(candidates) - 1; i >= 0; i-- {
+		candidate := candidates[i]
+		if genericSegments[candidate] || len(candidate) < 3 {
+			continue
+		}
+		for _, jar := range jars {
+			for _, token := range tokens(jar) {
+				if token == candidate {
+					return jar
+				}
+			}
+		}
+	}
+	return ""
+}
+
+var loaderTokens = map[string]bool{
+	"fabric": true, "forge": true, "neoforge": true, "quilt": true,
+	"bukkit": true, "spigot": true, "paper": true, "velocity": true, "bungee": true, "bungeecord": true,
+}
+
+// jarParts splits "lithium-fabric-mc1.20.1-0.11.2.jar" into its name
+// ("lithium") and version ("mc1.20.1-0.11.2") by peeling version and loader
+// tokens off the end.
+func jarParts(jar string) (string, string) {
+	base := strings.TrimSuffix(strings.TrimSuffix(jar, ".disabled"), ".jar")
+	parts := strings.FieldsFunc(base, func(r rune) bool { return r == '-' || r == '_' || r == ' ' || r == '+' })
+	end := len(parts)
+	var version []string
+	for end > 1 {
+		token := strings.ToLower(parts[end-1])
+		if loaderTokens[token] {
+			end--
+			continue
+		}
+		if isVersionToken(token) {
+			version = append([]string{parts[end-1]}, version...)
+			end--
+			continue
+		}
+		break
+	}
+	if end == 0 {
+		return base, ""
+	}
+	return strings.Join(parts[:end], "-"), strings.Join(version, "-")
+}
+
+func isVersionToken(token string) bool {
+	token = strings.TrimPrefix(strings.TrimPrefix(token, "mc"), "v")
+	return token != "" && token[0] >= '0' && token[0] <= '9'
+}
+
+// jarName turns "create-1.20.1-0.5.1.f.jar" into "create".
+func jarName(jar string) string {
+	name, _ := jarParts(jar)
+	return name
+}
+
+// JarVersion returns the version part of a jar file name, or "" if none
+// can be found.
+func JarVersion(jar string) string {
+	_, version := jarParts(jar)
+	return version
+}
@@ -0,0 +1,141 @@
+// Package fleet describes the MineOS installations one machine manages:
+// named contexts, each a local .env or an install on an ssh host, and groups
+// of them that fleet commands go through in order.
+package fleet
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Context is one installation. Exactly one of SSH and Env is set.
+type Context struct {
+	// SSH is the host as --ssh takes it: user@host[:port] or an ssh alias.
+	SSH string `yaml:"ssh"`
+	// Dir is the install directory on the SSH host (default ~/mineos).
+	Dir string `yaml:"dir"`
+	// Env is the .env of an installation on this machine.
+	Env string `yaml:"env"`
+}
+
+// Target describes where the context's installation is.
+func (c Context) Target() string {
+	if c.SSH != "" && c.Dir != "" {
+		return c.SSH + ":" + c.Dir
+	}
+	if c.SSH != "" {
+		return c.SSH
+	}
+	return c.Env
+}
+
+// Definition is the contexts file.
+type Definition struct {
+	Contexts map[string]Context `yaml:"contexts"`
+	// Groups list contexts in the order rolling commands take them.
+	Groups map[string][]string `yaml:"groups"`
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Validate checks names, that every context says where it is and that
+// groups only list known contexts.
+func (d Definition) Validate() error {
+	for _, name := range d.Names() {
+		context := d.Contexts[name]
+		if !namePattern.MatchString(name) {
+			return fmt.Errorf("context %q: names use letters, digits, '.', '_' and '-'", name)
+		}
+		switch {
+		case context.SSH == "" && context.Env == "":
+			return fmt.Errorf("context %s: set ssh or env", name)
+		case context.SSH != "" && context.Env != "":
+			return fmt.Errorf("context %s: set ssh or env, not both", name)
+		case context.Env != "" && context.Dir != "":
+			return fmt.Errorf("context %s: dir only applies to ssh contexts", name)
+		}
+	}
+	for _, group := range d.GroupNames() {
+		seen := map[string]bool{}
+		for _, member := range d.Groups[group] {
+			if _, ok := d.Contexts[member]; !ok {
+				return fmt.Errorf("group %s: unknown context %q", group, member)
+			}
+			if seen[member] {
+				return fmt.Errorf("group %s: %s is listed twice", group, member)
+			}
+			seen[member] = true
+		}
+	}
+	return nil
+}
+
+// Names are the context names, sorted.
+func (d Definition) Names() []string {
+	names := make([]string, 0, len(d.Contexts))
+	for name := range d.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GroupNames are the group names, sorted.
+func (d Definition) GroupNames() []string {
+	names := make([]string, 0, len(d.Groups))
+	for name := range d.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GroupsOf lists the groups a context belongs to.
+func (d Definition) GroupsOf(name string) []string {
+	var groups []string
+	for _, group := range d.GroupNames() {
+		for _, member := range d.Groups[group] {
+			if member == name {
+				groups = append(groups, group)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// Select returns the contexts of a group in the group's order, followed by
+// the named contexts that are not already in it.
+func (d Definition) Select(group string, names []string) ([]string, error) {
+	var selected []string
+	seen := map[string]bool{}
+	if group != "" {
+		members, ok := d.Groups[group]
+		if !ok {
+			return nil, fmt.Errorf("no context group %q (groups: %s)", group, listOrNone(d.GroupNames()))
+		}
+		for _, member := range members {
+			seen[member] = true
+			selected = append(selected, member)
+		}
+	}
+	for _, name := range names {
+		if _, ok := d.Contexts[name]; !ok {
+			return nil, fmt.Errorf("no context %q (contexts: %s)", name, listOrNone(d.Names()))
+		}
+		if !seen[name] {
+			seen[name] = true
+			selected = append(selected, name)
+		}
+	}
+	return selected, nil
+}
+
+func listOrNone(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,111 @@
+// Package fuzzy matches partially typed or misspelt names, such as a server
+// name given as "surv" for "survival-smp".
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+)
+
+// Resolve returns the name that input refers to. Matching tries, in order:
+// the exact name, the name ignoring case, a prefix, a prefix of one of the
+// name's words (split on '-', '_' and '.'), a substring, the letters in
+// order ("svsmp"), and finally a name within a small edit distance. The
+// first kind with any match decides: one match is the answer, several are
+// returned as candidates, sorted. Both results are empty when nothing
+// matches.
+func Resolve(input string, names []string) (string, []string) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", nil
+	}
+	for _, name := range names {
+		if name == input {
+			return name, nil
+		}
+	}
+
+	needle := strings.ToLower(input)
+	tiers := []func(name string) bool{
+		func(name string) bool { return name == needle },
+		func(name string) bool { return strings.HasPrefix(name, needle) },
+		func(name string) bool {
+			for _, word := range strings.FieldsFunc(name, isSeparator) {
+				if strings.HasPrefix(word, needle) {
+					return true
+				}
+			}
+			return false
+		},
+		func(name string) bool { return strings.Contains(name, needle) },
+		func(name string) bool { return isSubsequence(needle, name) },
+		func(name string) bool { return Distance(needle, name) <= maxTypos(needle) },
+	}
+	for _, matches := range tiers {
+		var found []string
+		for _, name := range names {
+			if matches(strings.ToLower(name)) {
+				found = append(found, name)
+			}
+		}
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return found[0], nil
+		}
+		sort.Strings(found)
+		return "", found
+	}
+	return "", nil
+}
+
+// Distance is the Levenshtein distance between a and b.
+func Distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// maxTypos allows one typo in short names and two in longer ones, so "lobyy"
+// finds "lobby" without "a" matching every three-letter name.
+func maxTypos(input string) int {
+	switch {
+	case len(input) < 4:
+		return 0
+	case len(input) < 8:
+		return 1
+	}
+	return 2
+}
+
+func isSubsequence(needle, name string) bool {
+	if len(needle) < 2 {
+		return false
+	}
+	i := 0
+	for j := 0; j < len(name) && i < len(needle); j++ {
+		if name[j] == needle[i] {
+			i++
+		}
+	}
+	return i == len(needle)
+}
+
+func isSeparator(r rune) bool {
+	return r == '-' || r == '_' || r == '.'
+}
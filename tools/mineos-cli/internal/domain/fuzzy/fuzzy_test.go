@@ -0,0 +1,56 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	names := []string{"survival-smp", "survival-test", "Lobby", "creative", "skyblock_v2", "lobby-old"}
+	tests := []struct {
+		name       string
+		input      string
+		want       string
+		candidates []string
+	}{
+		{name: "exact", input: "creative", want: "creative"},
+		{name: "exact beats case-insensitive", input: "Lobby", want: "Lobby"},
+		{name: "ignoring case", input: "LOBBY", want: "Lobby"},
+		{name: "unique prefix", input: "cre", want: "creative"},
+		{name: "ambiguous prefix", input: "surv", candidates: []string{"survival-smp", "survival-test"}},
+		{name: "word prefix", input: "smp", want: "survival-smp"},
+		{name: "word prefix after underscore", input: "v2", want: "skyblock_v2"},
+		{name: "substring", input: "ival-t", want: "survival-test"},
+		{name: "letters in order", input: "svsmp", want: "survival-smp"},
+		{name: "one typo", input: "creatvie", want: "creative"},
+		{name: "short input allows no typos", input: "xyz"},
+		{name: "blank", input: "  "},
+		{name: "no match", input: "hardcore"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, candidates := Resolve(tt.input, names)
+			if got != tt.want || !reflect.DeepEqual(candidates, tt.candidates) {
+				t.Fatalf("Resolve(%q) = %q, %v; want %q, %v", tt.input, got, candidates, tt.want, tt.candidates)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"lobby", "lobby", 0},
+		{"lobyy", "lobby", 1},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Package guard spots connection abuse in Minecraft server logs, such as
+// repeated failed logins, join floods and exploit attempts, and decides when
+// an address has earned a temporary firewall ban.
+package guard
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultThreshold = 10
+	DefaultWindow    = 5 * time.Minute
+	DefaultBan       = time.Hour
+)
+
+// Firewall backends.
+const (
+	FirewallAuto     = "auto"
+	FirewallNftables = "nftables"
+	FirewallUfw      = "ufw"
+	// FirewallNone only reports and audits offenders.
+	FirewallNone = "none"
+)
+
+// Rules an offence can match, with the weight each adds to an address's
+// score by default.
+const (
+	RuleFailedLogin    = "failed-login"
+	RuleNotWhitelisted = "not-whitelisted"
+	RuleBanned         = "banned"
+	RuleJoin           = "join"
+	RuleBadPacket      = "bad-packet"
+	RuleJndi           = "jndi"
+)
+
+var defaultWeights = map[string]int{
+	RuleFailedLogin:    2,
+	RuleNotWhitelisted: 2,
+	RuleBanned:         2,
+	RuleJoin:           1,
+	RuleBadPacket:      5,
+	RuleJndi:           DefaultThreshold,
+}
+
+// Rules returns the rule names in a stable order.
+func Rules() []string {
+	names := make([]string, 0, len(defaultWeights))
+	for name := range defaultWeights {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Definition is the guard file.
+type Definition struct {
+	// Threshold is the score within Window at which an address is banned.
+	Threshold int           `yaml:"threshold"`
+	Window    time.Duration `yaml:"window"`
+	Ban       time.Duration `yaml:"ban"`
+	// Whitelist holds addresses and CIDR ranges that are never banned.
+	// Loopback addresses are always exempt.
+	Whitelist []string `yaml:"whitelist"`
+	Firewall  string   `yaml:"firewall"`
+	// Servers are names or glob patterns; empty means every server.
+	Servers []string `yaml:"servers"`
+	// Weights overrides the score of a rule; 0 disables it.
+	Weights map[string]int `yaml:"weights"`
+}
+
+// WithDefaults fills unset fields.
+func (d Definition) WithDefaults() Definition {
+	if d.Threshold == 0 {
+		d.Threshold = DefaultThreshold
+	}
+	if d.Window == 0 {
+		d.Window = DefaultWindow
+	}
+	if d.Ban == 0 {
+		d.Ban = DefaultBan
+	}
+	if d.Firewall == "" {
+		d.Firewall = FirewallAuto
+	}
+	return d
+}
+
+func (d Definition) Validate() error {
+	if d.Threshold < 0 || d.Window < 0 || d.Ban < 0 {
+		return fmt.Errorf("threshold, window and ban must not be negative")
+	}
+	switch d.Firewall {
+	case "", FirewallAuto, FirewallNftables, FirewallUfw, FirewallNone:
+	default:
+		return fmt.Errorf("firewall must be auto, nftables, ufw or none, not %q", d.Firewall)
+	}
+	for i, entry := range d.Whitelist {
+		if _, err := parseNetwork(entry); err != nil {
+			return fmt.Errorf("whitelist[%d]: %w", i, err)
+		}
+	}
+	for rule, weight := range d.Weights {
+		if _, ok := defaultWeights[rule]; !ok {
+			return fmt.Errorf("weights: unknown rule %q (known: %s)", rule, strings.Join(Rules(), ", "))
+		}
+		if weight < 0 {
+			return fmt.Errorf("weights.%s must not be negative", rule)
+		}
+	}
+	for _, pattern := range d.Servers {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("servers: invalid pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// Weight returns the score a rule adds.
+func (d Definition) Weight(rule string) int {
+	if weight, ok := d.Weights[rule]; ok {
+		return weight
+	}
+	if rule == RuleJndi {
+		// An exploit attempt is banned outright whatever the threshold.
+		return max(d.WithDefaults().Threshold, 1)
+	}
+	return defaultWeights[rule]
+}
+
+// Watches reports whether the guard follows a server's log.
+func (d Definition) Watches(server string) bool {
+	if len(d.Servers) == 0 {
+		return true
+	}
+	for _, pattern := range d.Servers {
+		if ok, _ := path.Match(pattern, server); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Whitelisted reports whether ip must never be banned.
+func (d Definition) Whitelisted(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() {
+		return true
+	}
+	for _, entry := range d.Whitelist {
+		if network, err := parseNetwork(entry); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseNetwork(entry string) (*net.IPNet, error) {
+	entry = strings.TrimSpace(entry)
+	if strings.Contains(entry, "/") {
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range %q", entry)
+		}
+		return network, nil
+	}
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid address %q", entry)
+	}
+	bits := 128
+	if ip.To4() != nil {
+		ip, bits = ip.To4(), 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// Offence is one log line that counts against an address.
+type Offence struct {
+	Server string
+	IP     string
+	Rule   string
+	Player string
+	Time   time.Time
+}
+
+// Ban is the decision to block an address until Until.
+type Ban struct {
+	IP     string    `json:"ip"`
+	Server string    `json:"server"`
+	Rules  []string  `json:"rules"`
+	Score  int       `json:"score"`
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until"`
+	// Firewall is the backend that holds the block, so it can be lifted
+	// with the same one.
+	Firewall string `json:"firewall"`
+}
+
+// Engine keeps the recent offences of every address. It is not safe for
+// concurrent use.
+type Engine struct {
+	hits   map[string][]Offence
+	banned map[string]time.Time
+}
+
+func NewEngine() *Engine {
+	return &Engine{hits: map[string][]Offence{}, banned: map[string]time.Time{}}
+}
+
+// MarkBanned tells the engine an address is already banned until until, e.g.
+// from a ban restored after a restart.
+func (e *Engine) MarkBanned(ip string, until time.Time) {
+	e.banned[ip] = until
+}
+
+// Release forgets a ban so the address is judged afresh.
+func (e *Engine) Release(ip string) {
+	delete(e.banned, ip)
+	delete(e.hits, ip)
+}
+
+// Record adds an offence and returns a ban when the address's score within
+// the window reaches the threshold.
+func (e *Engine) Record(def Definition, offence Offence) (Ban, bool) {
+	def = def.WithDefaults()
+	ip := net.ParseIP(offence.IP)
+	if ip == nil || def.Whitelisted(ip) || def.Weight(offence.Rule) == 0 {
+		return Ban{}, false
+	}
+	if until, ok := e.banned[offence.IP]; ok && offence.Time.Before(until) {
+		return Ban{}, false
+	}
+
+	cutoff := offence.Time.Add(-def.Window)
+	hits := append(e.hits[offence.IP], offence)
+	kept := hits[:0]
+	score := 0
+	rules := map[string]bool{}
+	for _, hit := range hits {
+		if hit.Time.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, hit)
+		score += def.Weight(hit.Rule)
+		rules[hit.Rule] = true
+	}
+	e.hits[offence.IP] = kept
+	if score < def.Threshold {
+		return Ban{}, false
+	}
+
+	ban := Ban{
+		IP:     offence.IP,
+		Server: offence.Server,
+		Score:  score,
+		Since:  offence.Time,
+		Until:  offence.Time.Add(def.Ban),
+	}
+	for rule := range rules {
+		ban.Rules = append(ban.Rules, rule)
+	}
+	sort.Strings(ban.Rules)
+	e.banned[offence.IP] = ban.Until
+	delete(e.hits, offence.IP)
+	return ban, true
+}
+
+// Prune drops offences and bans that no longer matter at now.
+func (e *Engine) Prune(def Definition, now time.Time) {
+	cutoff := now.Add(-def.WithDefaults().Window)
+	for ip, hits := range e.hits {
+		if len(hits) == 0 || hits[len(hits)-1].Time.Before(cutoff) {
+			delete(e.hits, ip)
+		}
+	}
+	for ip, until := range e.banned {
+		if !now.Before(until) {
+			delete(e.banned, ip)
+		}
+	}
+}
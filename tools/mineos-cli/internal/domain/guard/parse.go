@@ -0,0 +1,96 @@
+package guard
+
+import (
+	"net"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// loginRe matches "Steve[/203.0.113.7:51234] logged in with entity id".
+	loginRe = regexp.MustCompile(`(\w{1,16})\[/([0-9A-Fa-f:.]+):\d+\] logged in`)
+	// addressRe matches the "(/203.0.113.7:51234)" or "/203.0.113.7:51234:"
+	// a disconnect line names the client by.
+	addressRe = regexp.MustCompile(`/([0-9A-Fa-f:.]+):\d+\)?:?\s`)
+	leftRe    = regexp.MustCompile(`(\w{1,16}) left the game`)
+	chatRe    = regexp.MustCompile(`<(\w{1,16})>`)
+	// jndiRe matches log4j lookups, including the nested ${lower:j} style.
+	jndiRe = regexp.MustCompile(`(?i)\$\{[^}]*(jndi|lower:|upper:|env:|::-)`)
+)
+
+// disconnectReasons maps what Minecraft says when it drops a client to a rule.
+var disconnectReasons = []struct {
+	text string
+	rule string
+}{
+	{"failed to verify username", RuleFailedLogin},
+	{"invalid session", RuleFailedLogin},
+	{"not white-listed", RuleNotWhitelisted},
+	{"not whitelisted", RuleNotWhitelisted},
+	{"you are banned", RuleBanned},
+	{"banned from this server", RuleBanned},
+	{"decoderexception", RuleBadPacket},
+	{"badly compressed packet", RuleBadPacket},
+	{"packet too big", RuleBadPacket},
+	{"payload may not be larger", RuleBadPacket},
+	{"indexoutofboundsexception", RuleBadPacket},
+}
+
+// Parser turns console lines into offences. It remembers which address each
+// online player joined from so chat lines can be traced to an address. It is
+// not safe for concurrent use; use one per server.
+type Parser struct {
+	server  string
+	players map[string]string
+}
+
+func NewParser(server string) *Parser {
+	return &Parser{server: server, players: map[string]string{}}
+}
+
+// Parse returns the offence a console line records, if any.
+func (p *Parser) Parse(line string, at time.Time) (Offence, bool) {
+	offence := Offence{Server: p.server, Time: at}
+
+	if m := loginRe.FindStringSubmatch(line); m != nil {
+		if net.ParseIP(m[2]) == nil {
+			return Offence{}, false
+		}
+		p.players[strings.ToLower(m[1])] = m[2]
+		offence.Player, offence.IP, offence.Rule = m[1], m[2], RuleJoin
+		return offence, true
+	}
+
+	if jndiRe.MatchString(line) {
+		offence.Rule = RuleJndi
+		if m := addressRe.FindStringSubmatch(line); m != nil && net.ParseIP(m[1]) != nil {
+			offence.IP = m[1]
+		} else if m := chatRe.FindStringSubmatch(line); m != nil {
+			offence.Player = m[1]
+			offence.IP = p.players[strings.ToLower(m[1])]
+		}
+		return offence, offence.IP != ""
+	}
+
+	if m := leftRe.FindStringSubmatch(line); m != nil {
+		delete(p.players, strings.ToLower(m[1]))
+		return Offence{}, false
+	}
+
+	lower := strings.ToLower(line)
+	if !strings.Contains(lower, "lost connection") && !strings.Contains(lower, "disconnecting") {
+		return Offence{}, false
+	}
+	m := addressRe.FindStringSubmatch(line)
+	if m == nil || net.ParseIP(m[1]) == nil {
+		return Offence{}, false
+	}
+	for _, reason := range disconnectReasons {
+		if strings.Contains(lower, reason.text) {
+			offence.IP, offence.Rule = m[1], reason.rule
+			return offence, true
+		}
+	}
+	return Offence{}, false
+}
@@ -0,0 +1,103 @@
+package icon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Size is the width and height Minecraft requires for server-icon.png.
+const Size = 64
+
+// MaxInputBytes matches the API's upload limit.
+const MaxInputBytes = 5 * 1024 * 1024
+
+type Info struct {
+	Format  string
+	Width   int
+	Height  int
+	Cropped bool
+	Resized bool
+}
+
+// Prepare decodes a PNG, JPEG or GIF, center-crops it to a square and scales
+// it to Size x Size, returning the PNG to upload.
+func Prepare(r io.Reader) ([]byte, Info, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxInputBytes+1))
+	if err != nil {
+		return nil, Info{}, err
+	}
+	if len(data) > MaxInputBytes {
+		return nil, Info{}, fmt.Errorf("image is larger than %d MB", MaxInputBytes/1024/1024)
+	}
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, Info{}, errors.New("file must be a PNG, JPEG or GIF image")
+		}
+		return nil, Info{}, err
+	}
+
+	bounds := src.Bounds()
+	info := Info{Format: format, Width: bounds.Dx(), Height: bounds.Dy()}
+	if info.Width == 0 || info.Height == 0 {
+		return nil, info, errors.New("image is empty")
+	}
+
+	side := min(info.Width, info.Height)
+	square := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(info.Width-side)/2,
+		bounds.Min.Y+(info.Height-side)/2,
+	))
+	info.Cropped = square != bounds
+	info.Resized = side != Size
+
+	if format == "png" && !info.Cropped && !info.Resized {
+		return data, info, nil
+	}
+	var out bytes.Buffer
+	if err := png.Encode(&out, scale(src, square, Size)); err != nil {
+		return nil, info, err
+	}
+	return out.Bytes(), info, nil
+}
+
+// scale resamples the square region of src to size x size by averaging the
+// source pixels that fall into each target pixel (nearest neighbour when
+// enlarging).
+func scale(src image.Image, region image.Rectangle, size int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	side := region.Dx()
+	for y := 0; y < size; y++ {
+		y0 := region.Min.Y + y*side/size
+		y1 := max(region.Min.Y+(y+1)*side/size, y0+1)
+		for x := 0; x < size; x++ {
+			x0 := region.Min.X + x*side/size
+			x1 := max(region.Min.X+(x+1)*side/size, x0+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBAModel.Convert(src.At(sx, sy)).(color.NRGBA)
+					// Weight by alpha so transparent pixels do not darken edges.
+					r += uint64(c.R) * uint64(c.A)
+					g += uint64(c.G) * uint64(c.A)
+					b += uint64(c.B) * uint64(c.A)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			pixel := color.NRGBA{A: uint8(a / n)}
+			if a > 0 {
+				pixel.R, pixel.G, pixel.B = uint8(r/a), uint8(g/a), uint8(b/a)
+			}
+			dst.SetNRGBA(x, y, pixel)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,81 @@
+// Package integrity classifies a server's jars by whether their hashes match
+// what the upstream vendor published.
+package integrity
+
+import (
+	"regexp"
+	"strconv"
+)
+
+type Status string
+
+const (
+	// Verified files match a published hash.
+	Verified Status = "verified"
+	// Mismatch files claim to be a published file but differ from it:
+	// tampered with or corrupted.
+	Mismatch Status = "mismatch"
+	// Unknown files could not be checked, e.g. mods from outside Modrinth.
+	Unknown Status = "unknown"
+)
+
+// Check is the outcome for one file.
+type Check struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+	Status Status `json:"status"`
+	// Source is where the expected hash came from: mojang, paper, modrinth
+	// or profile.
+	Source string `json:"source,omitempty"`
+	Detail string `json:"detail"`
+	Sha256 string `json:"sha256"`
+}
+
+type Report struct {
+	Server string  `json:"server"`
+	Checks []Check `json:"checks"`
+}
+
+func (r Report) Count(status Status) int {
+	n := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// ServerJar is a server jar installed from a MineOS profile, recognised by
+// the file name the profile gave it.
+type ServerJar struct {
+	Software string
+	Version  string
+	// Build is the Paper build number, zero when the name has none.
+	Build int
+	// Profile is the id of a custom profile.
+	Profile string
+}
+
+var (
+	vanillaJarPattern = regexp.MustCompile(`^vanilla-(.+)\.jar$`)
+	paperJarPattern   = regexp.MustCompile(`^paper-(.+?)(?:-(\d+))?\.jar$`)
+	customJarPattern  = regexp.MustCompile(`^(custom-[a-z0-9][a-z0-9._-]*)\.jar$`)
+)
+
+// ParseServerJar recognises vanilla-<version>.jar, paper-<version>[-<build>].jar
+// and custom-<name>.jar.
+func ParseServerJar(fileName string) (ServerJar, bool) {
+	if match := vanillaJarPattern.FindStringSubmatch(fileName); match != nil {
+		return ServerJar{Software: "vanilla", Version: match[1]}, true
+	}
+	if match := paperJarPattern.FindStringSubmatch(fileName); match != nil {
+		// Older profiles left the build out.
+		build, _ := strconv.Atoi(match[2])
+		return ServerJar{Software: "paper", Version: match[1], Build: build}, true
+	}
+	if match := customJarPattern.FindStringSubmatch(fileName); match != nil {
+		return ServerJar{Software: "custom", Profile: match[1]}, true
+	}
+	return ServerJar{}, false
+}
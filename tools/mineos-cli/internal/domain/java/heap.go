@@ -0,0 +1,197 @@
+package java
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// MinHeapMB is the smallest heap worth giving a Java server.
+	MinHeapMB = 1024
+	// heapStepMB rounds recommendations to whole half gigabytes.
+	heapStepMB = 512
+	// hostReserveMB is kept back for the OS, Docker and the MineOS stack.
+	hostReserveMB = 1536
+	// largeHeapMB is where Aikar's flags switch to their large-heap variant.
+	largeHeapMB = 12 * 1024
+)
+
+// jvmOverhead is how much memory a JVM uses beyond its heap (metaspace,
+// thread stacks, native buffers), as a factor of the heap.
+const jvmOverhead = 1.25
+
+// HeapInput is what a heap recommendation is based on. Zero values are
+// unknown and ignored.
+type HeapInput struct {
+	HostMemoryMB int64
+	// OtherServersMB is the heap the host's other servers are set to.
+	OtherServersMB int64
+	OtherServers   int
+	Mods           int
+	Plugins        int
+	// PeakUsedMB is the most memory the server was seen using.
+	PeakUsedMB int64
+	// HistorySpan describes how much history PeakUsedMB covers, e.g. "7d".
+	HistorySpan string
+	CurrentXmx  int
+}
+
+// HeapRecommendation is the suggested -Xmx, -Xms and GC flags.
+type HeapRecommendation struct {
+	XmxMB int    `json:"xmxMb"`
+	XmsMB int    `json:"xmsMb"`
+	Flags string `json:"flags"`
+	// Reasons explain how the numbers were reached, in order.
+	Reasons  []string `json:"reasons"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// RecommendHeap sizes a heap from the workload (mods and plugins), raises it
+// to fit what the server was seen using, and caps it to what the host has
+// left once its other servers are accounted for.
+func RecommendHeap(in HeapInput) HeapRecommendation {
+	var rec HeapRecommendation
+
+	want := int64(2048)
+	switch {
+	case in.Mods > 0:
+		// Modpacks load every mod's classes and registries up front; large
+		// packs of 200+ mods commonly need 8-12 GB.
+		want = 3072 + int64(in.Mods)*40
+		rec.Reasons = append(rec.Reasons, fmt.Sprintf("%d mods: %s for the workload", in.Mods, FormatMB(want)))
+	case in.Plugins > 0:
+		want += int64(in.Plugins) * 24
+		rec.Reasons = append(rec.Reasons, fmt.Sprintf("%d plugins: %s for the workload", in.Plugins, FormatMB(want)))
+	default:
+		rec.Reasons = append(rec.Reasons, fmt.Sprintf("No mods or plugins: %s for the workload", FormatMB(want)))
+	}
+
+	if in.PeakUsedMB > 0 {
+		// The memory a server is seen using includes off-heap memory, so
+		// the heap it needs is smaller; keep a fifth free for GC to work in.
+		seen := int64(float64(in.PeakUsedMB) / jvmOverhead * 1.2)
+		if seen > want {
+			rec.Reasons = append(rec.Reasons, fmt.Sprintf("Peak use of %s over %s needs a %s heap", FormatMB(in.PeakUsedMB), in.HistorySpan, FormatMB(seen)))
+			want = seen
+		} else {
+			rec.Reasons = append(rec.Reasons, fmt.Sprintf("Peak use of %s over %s fits", FormatMB(in.PeakUsedMB), in.HistorySpan))
+		}
+	} else {
+		rec.Reasons = append(rec.Reasons, "No usage history; record it with 'mineos agent --record-metrics' for a better estimate")
+	}
+
+	capped := false
+	if in.HostMemoryMB > 0 {
+		left := in.HostMemoryMB - hostReserveMB - int64(float64(in.OtherServersMB)*jvmOverhead)
+		limit := int64(float64(left) / jvmOverhead)
+		switch {
+		case limit < MinHeapMB:
+			rec.Warnings = append(rec.Warnings, fmt.Sprintf("The host has %s and its %d other server(s) are set to %s; there is no room for another %s heap. Lower their heaps or run fewer servers at once.",
+				FormatMB(in.HostMemoryMB), in.OtherServers, FormatMB(in.OtherServersMB), FormatMB(MinHeapMB)))
+			want = MinHeapMB
+		case want > limit:
+			rec.Reasons = append(rec.Reasons, fmt.Sprintf("Host has %s, %s left after the OS and %d other server(s): capped to %s",
+				FormatMB(in.HostMemoryMB), FormatMB(left), in.OtherServers, FormatMB(limit)))
+			rec.Warnings = append(rec.Warnings, fmt.Sprintf("The workload wants %s but the host only has room for %s; expect longer GC pauses.", FormatMB(want), FormatMB(limit)))
+			want, capped = limit, true
+		}
+	}
+
+	rec.XmxMB = roundHeap(want)
+	if capped && int64(rec.XmxMB) > want && rec.XmxMB > MinHeapMB {
+		rec.XmxMB -= heapStepMB
+	}
+	// Reserving the whole heap at start avoids resizing pauses, and the
+	// memory had to be there anyway.
+	rec.XmsMB = rec.XmxMB
+	rec.Flags = GCFlags(rec.XmxMB)
+
+	if in.CurrentXmx > 0 && in.CurrentXmx > rec.XmxMB*2 {
+		rec.Warnings = append(rec.Warnings, fmt.Sprintf("The current %s heap is over twice what the server needs; big heaps make each collection slower.", FormatMB(int64(in.CurrentXmx))))
+	}
+	return rec
+}
+
+func roundHeap(mb int64) int {
+	rounded := (mb + heapStepMB/2) / heapStepMB * heapStepMB
+	if rounded < MinHeapMB {
+		rounded = MinHeapMB
+	}
+	return int(rounded)
+}
+
+// GCFlags returns Aikar's G1 flags, the de facto standard for Minecraft
+// servers, in their large-heap form from 12 GB.
+func GCFlags(heapMB int) string {
+	newSize, maxNewSize, regionSize, reserve, ihop := 30, 40, "8M", 20, 15
+	if heapMB >= largeHeapMB {
+		newSize, maxNewSize, regionSize, reserve, ihop = 40, 50, "16M", 15, 20
+	}
+	return strings.Join([]string{
+		"-XX:+UseG1GC",
+		"-XX:+ParallelRefProcEnabled",
+		"-XX:MaxGCPauseMillis=200",
+		"-XX:+UnlockExperimentalVMOptions",
+		"-XX:+DisableExplicitGC",
+		"-XX:+AlwaysPreTouch",
+		fmt.Sprintf("-XX:G1NewSizePercent=%d", newSize),
+		fmt.Sprintf("-XX:G1MaxNewSizePercent=%d", maxNewSize),
+		"-XX:G1HeapRegionSize=" + regionSize,
+		fmt.Sprintf("-XX:G1ReservePercent=%d", reserve),
+		"-XX:G1HeapWastePercent=5",
+		"-XX:G1MixedGCCountTarget=4",
+		fmt.Sprintf("-XX:InitiatingHeapOccupancyPercent=%d", ihop),
+		"-XX:G1MixedGCLiveThresholdPercent=90",
+		"-XX:G1RSetUpdatingPauseTimePercent=5",
+		"-XX:SurvivorRatio=32",
+		"-XX:+PerfDisableSharedMem",
+		"-XX:MaxTenuringThreshold=1",
+	}, " ")
+}
+
+// gcSelectors pick a collector; only one may be given.
+var gcSelectors = map[string]bool{
+	"UseG1GC": true, "UseZGC": true, "UseShenandoahGC": true,
+	"UseParallelGC": true, "UseSerialGC": true, "UseConcMarkSweepGC": true,
+}
+
+// MergeFlags puts flags into existing JVM tweaks: the options flags sets
+// and other collectors are replaced, heap sizes are dropped (they have their own
+// settings) and everything else, such as -D properties, is kept.
+func MergeFlags(existing, flags string) string {
+	replaced := map[string]bool{}
+	for _, flag := range strings.Fields(flags) {
+		replaced[xxName(flag)] = true
+	}
+	var kept []string
+	for _, token := range strings.Fields(existing) {
+		name := xxName(token)
+		switch {
+		case strings.HasPrefix(token, "-Xmx"), strings.HasPrefix(token, "-Xms"):
+		case name != "" && (replaced[name] || gcSelectors[name]):
+		default:
+			kept = append(kept, token)
+		}
+	}
+	return strings.TrimSpace(strings.Join(append(kept, strings.Fields(flags)...), " "))
+}
+
+// xxName is the option name of a -XX flag: "-XX:+UseG1GC" and
+// "-XX:MaxGCPauseMillis=200" give "UseG1GC" and "MaxGCPauseMillis".
+func xxName(flag string) string {
+	rest, ok := strings.CutPrefix(flag, "-XX:")
+	if !ok {
+		return ""
+	}
+	rest = strings.TrimLeft(rest, "+-")
+	name, _, _ := strings.Cut(rest, "=")
+	return name
+}
+
+// FormatMB shows megabytes as "512 MB" or "4.5 GB".
+func FormatMB(mb int64) string {
+	if mb < 1024 {
+		return fmt.Sprintf("%d MB", mb)
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(mb)/1024), ".0") + " GB"
+}
@@ -0,0 +1,162 @@
+package java
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SupportedVersions are the Temurin feature releases that can be installed.
+var SupportedVersions = []int{8, 11, 17, 21, 25}
+
+// ManagedRuntimeDir holds runtimes installed by the CLI. It lives on the
+// server volume so runtimes survive API container recreation.
+const ManagedRuntimeDir = "/var/games/minecraft/.runtimes/java"
+
+var versionRe = regexp.MustCompile(`(\d+\.\d+(?:\.\d+)?)`)
+
+// ExtractMinecraftVersion finds a Minecraft version in a profile name or jar
+// file name, mirroring how the API auto-selects Java.
+func ExtractMinecraftVersion(values ...string) string {
+	match := versionRe.FindStringSubmatch(strings.Join(values, " "))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// RecommendedVersion returns the Java feature release for a Minecraft version:
+// 26.x+ needs 25, 1.21+ needs 21, 1.17-1.20 needs 17 and older needs 8.
+// Zero means the version could not be determined.
+func RecommendedVersion(minecraftVersion string) int {
+	parts := strings.Split(strings.TrimSpace(minecraftVersion), ".")
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0
+	}
+	if major >= 26 {
+		return 25
+	}
+	if major == 1 && len(parts) >= 2 {
+		minor, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return 0
+		}
+		switch {
+		case minor >= 21:
+			return 21
+		case minor >= 17:
+			return 17
+		default:
+			return 8
+		}
+	}
+	return 0
+}
+
+// Runtime is a Java installation inside the API container.
+type Runtime struct {
+	Home    string
+	Version string
+	Managed bool
+}
+
+// Feature returns the feature release number (8, 17, 21...) of the runtime.
+func (r Runtime) Feature() int {
+	version := strings.TrimPrefix(r.Version, "1.")
+	end := strings.IndexAny(version, "._+-")
+	if end > 0 {
+		version = version[:end]
+	}
+	feature, _ := strconv.Atoi(version)
+	return feature
+}
+
+func (r Runtime) Binary() string {
+	return strings.TrimRight(r.Home, "/") + "/bin/java"
+}
+
+var javaVersionRe = regexp.MustCompile(`version "([^"]+)"`)
+
+// ListRuntimesScript prints "home|first line of java -version" for every
+// runtime in the image and in ManagedRuntimeDir.
+const ListRuntimesScript = `for d in /usr/lib/jvm/* ` + ManagedRuntimeDir + `/*; do
+  if [ -x "$d/bin/java" ] && [ ! -L "$d" ]; then
+    printf '%s|%s\n' "$d" "$("$d/bin/java" -version 2>&1 | head -n1)"
+  fi
+done`
+
+// ParseRuntimeListing parses the output of ListRuntimesScript.
+func ParseRuntimeListing(output string) []Runtime {
+	var runtimes []Runtime
+	for _, line := range strings.Split(output, "\n") {
+		home, versionLine, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if !ok || home == "" {
+			continue
+		}
+		version := ""
+		if match := javaVersionRe.FindStringSubmatch(versionLine); match != nil {
+			version = match[1]
+		}
+		runtimes = append(runtimes, Runtime{
+			Home:    home,
+			Version: version,
+			Managed: strings.HasPrefix(home, ManagedRuntimeDir),
+		})
+	}
+	return runtimes
+}
+
+// InstallScript downloads the latest Temurin JRE for a feature release from
+// Adoptium into ManagedRuntimeDir, matching the container's architecture.
+func InstallScript(feature int) string {
+	return fmt.Sprintf(`set -e
+case "$(uname -m)" in
+  x86_64|amd64) arch=x64 ;;
+  aarch64|arm64) arch=aarch64 ;;
+  *) echo "unsupported architecture: $(uname -m)" >&2; exit 1 ;;
+esac
+dest=%[2]s/temurin-%[1]d
+tmp="$dest.partial"
+rm -rf "$tmp" && mkdir -p "$tmp"
+wget -qO- "https://api.adoptium.net/v3/binary/latest/%[1]d/ga/linux/$arch/jre/hotspot/normal/eclipse" | tar -xz -C "$tmp" --strip-components=1
+"$tmp/bin/java" -version
+rm -rf "$dest" && mv "$tmp" "$dest"
+chown -R 1000:1000 %[2]s 2>/dev/null || true`, feature, ManagedRuntimeDir)
+}
+
+// UninstallScript removes a managed runtime.
+func UninstallScript(feature int) string {
+	return fmt.Sprintf(`rm -rf %s/temurin-%d`, ManagedRuntimeDir, feature)
+}
+
+// Find returns the preferred runtime for a feature release. Runtimes bundled
+// with the image win over managed installs.
+func Find(runtimes []Runtime, feature int) (Runtime, bool) {
+	var managed *Runtime
+	for i, runtime := range runtimes {
+		if runtime.Feature() != feature {
+			continue
+		}
+		if !runtime.Managed {
+			return runtime, true
+		}
+		if managed == nil {
+			managed = &runtimes[i]
+		}
+	}
+	if managed != nil {
+		return *managed, true
+	}
+	return Runtime{}, false
+}
+
+func IsSupported(feature int) bool {
+	for _, version := range SupportedVersions {
+		if version == feature {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package level
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/nbt"
+)
+
+// File is the world metadata file inside a world directory.
+const File = "level.dat"
+
+// Position is a block position.
+type Position struct {
+	X int64 `json:"x"`
+	Y int64 `json:"y"`
+	Z int64 `json:"z"`
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d, %d, %d", p.X, p.Y, p.Z)
+}
+
+// Info is the world generation and game state stored in level.dat.
+type Info struct {
+	LevelName   string            `json:"levelName"`
+	Version     string            `json:"version,omitempty"`
+	DataVersion int64             `json:"dataVersion,omitempty"`
+	Seed        int64             `json:"seed"`
+	WorldType   string            `json:"worldType"`
+	Structures  *bool             `json:"generateStructures,omitempty"`
+	Spawn       Position          `json:"spawn"`
+	GameMode    string            `json:"gameMode"`
+	Difficulty  string            `json:"difficulty"`
+	Hardcore    bool              `json:"hardcore"`
+	Day         int64             `json:"day"`
+	LastPlayed  time.Time         `json:"lastPlayed"`
+	GameRules   map[string]string `json:"gameRules"`
+}
+
+var gameModes = []string{"survival", "creative", "adventure", "spectator"}
+var difficulties = []string{"peaceful", "easy", "normal", "hard"}
+
+// Parse reads level.dat contents.
+func Parse(data []byte) (Info, error) {
+	// Bedrock's level.dat is little-endian behind an 8-byte version/length
+	// header.
+	if len(data) >= 8 && int(binary.LittleEndian.Uint32(data[4:8])) == len(data)-8 {
+		return Info{}, errors.New("bedrock edition worlds are not supported")
+	}
+	_, root, err := nbt.Decode(data)
+	if err != nil {
+		return Info{}, err
+	}
+	level, ok := root.Compound("Data")
+	if !ok {
+		return Info{}, errors.New("level.dat has no Data compound")
+	}
+
+	info := Info{GameRules: map[string]string{}}
+	info.LevelName, _ = level.String("LevelName")
+	info.Version, _ = level.String("Version.Name")
+	info.DataVersion, _ = level.Int("DataVersion")
+
+	// 1.16 moved generation settings into WorldGenSettings.
+	if gen, ok := level.Compound("WorldGenSettings"); ok {
+		info.Seed, _ = gen.Int("seed")
+		info.WorldType = worldType(gen)
+		if structures, ok := gen.Int("generate_features"); ok {
+			value := structures != 0
+			info.Structures = &value
+		}
+	} else {
+		info.Seed, _ = level.Int("RandomSeed")
+		name, _ := level.String("generatorName")
+		info.WorldType = legacyWorldType(name)
+		if structures, ok := level.Int("MapFeatures"); ok {
+			value := structures != 0
+			info.Structures = &value
+		}
+	}
+
+	// Recent versions replaced SpawnX/Y/Z with a spawn compound.
+	if pos, ok := level.Get("spawn.pos"); ok {
+		if xyz, ok := pos.([]int32); ok && len(xyz) == 3 {
+			info.Spawn = Position{X: int64(xyz[0]), Y: int64(xyz[1]), Z: int64(xyz[2])}
+		}
+	} else {
+		info.Spawn.X, _ = level.Int("SpawnX")
+		info.Spawn.Y, _ = level.Int("SpawnY")
+		info.Spawn.Z, _ = level.Int("SpawnZ")
+	}
+
+	mode, _ := level.Int("GameType")
+	info.GameMode = lookupName(gameModes, mode)
+	difficulty, _ := level.Int("Difficulty")
+	info.Difficulty = lookupName(difficulties, difficulty)
+	hardcore, _ := level.Int("hardcore")
+	info.Hardcore = hardcore != 0
+	if dayTime, ok := level.Int("DayTime"); ok {
+		info.Day = dayTime / 24000
+	}
+	if lastPlayed, ok := level.Int("LastPlayed"); ok && lastPlayed > 0 {
+		info.LastPlayed = time.UnixMilli(lastPlayed)
+	}
+
+	if rules, ok := level.Compound("GameRules"); ok {
+		for name, value := range rules {
+			info.GameRules[name] = fmt.Sprint(value)
+		}
+	}
+
+	return info, nil
+}
+
+// RuleNames returns the game rule names in order.
+func (i Info) RuleNames() []string {
+	names := make([]string, 0, len(i.GameRules))
+	for name := range i.GameRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func worldType(gen nbt.Compound) string {
+	overworld, ok := gen.Compound("dimensions.minecraft:overworld.generator")
+	if !ok {
+		return "unknown"
+	}
+	kind, _ := overworld.String("type")
+	switch strings.TrimPrefix(kind, "minecraft:") {
+	case "flat":
+		return "flat"
+	case "debug":
+		return "debug"
+	case "noise":
+		settings, _ := overworld.String("settings")
+		preset, _ := overworld.String("biome_source.preset")
+		switch {
+		case settings == "minecraft:amplified":
+			return "amplified"
+		case settings == "minecraft:large_biomes" || preset == "minecraft:large_biomes":
+			return "large biomes"
+		case biomeSource(overworld) == "minecraft:fixed":
+			return "single biome"
+		case settings == "" || settings == "minecraft:overworld":
+			return "default"
+		}
+		return "custom (" + settings + ")"
+	}
+	return fallback(kind, "unknown")
+}
+
+func legacyWorldType(name string) string {
+	switch strings.ToLower(name) {
+	case "", "default", "default_1_1":
+		return "default"
+	case "largebiomes":
+		return "large biomes"
+	case "debug_all_block_states":
+		return "debug"
+	}
+	return strings.ToLower(name)
+}
+
+func lookupName(names []string, value int64) string {
+	if value >= 0 && int(value) < len(names) {
+		return names[value]
+	}
+	return fmt.Sprintf("unknown (%d)", value)
+}
+
+func biomeSource(generator nbt.Compound) string {
+	kind, _ := generator.String("biome_source.type")
+	return kind
+}
+
+func fallback(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
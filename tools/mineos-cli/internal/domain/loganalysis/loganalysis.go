@@ -0,0 +1,369 @@
+package loganalysis
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Line is one parsed log record. Continuation lines such as stack frames
+// carry no timestamp and are attached to the preceding record.
+type Line struct {
+	Time    time.Time
+	Thread  string
+	Level   string
+	Source  string
+	Message string
+	// Dated is set when the record carried its own date.
+	Dated bool
+}
+
+var (
+	// [12:00:00] [Server thread/INFO]: message (vanilla, Paper)
+	vanillaLine = regexp.MustCompile(`^\[(\d{2}:\d{2}:\d{2})\] \[([^\]]*)/([A-Z]+)\]: ?(.*)$`)
+	// [12:00:00 INFO]: message (Spigot, Paper console format)
+	shortLine = regexp.MustCompile(`^\[(\d{2}:\d{2}:\d{2}) ([A-Z]+)\]: ?(.*)$`)
+	// [01Jan2024 12:00:00.000] [Server thread/INFO] [modid/]: message (Forge, NeoForge)
+	forgeLine = regexp.MustCompile(`^\[(\d{2}[A-Za-z]{3}\d{4} \d{2}:\d{2}:\d{2})\.\d+\] \[([^\]]*)/([A-Z]+)\] \[([^\]]*)\]: ?(.*)$`)
+
+	exceptionPattern = regexp.MustCompile(`(?:^|\s|Caused by: )((?:[a-zA-Z_$][\w$]*\.)+[A-Z][\w$]*(?:Exception|Error|Throwable))\b`)
+	pluginPrefix     = regexp.MustCompile(`^\[([A-Za-z0-9_.\- ]{2,40})\] `)
+	pluginMentions   = []*regexp.Regexp{
+		regexp.MustCompile(`^Could not load '(?:plugins/)?([^']+?)(?:\.jar)?'`),
+		regexp.MustCompile(`^Error occurred while (?:enabling|disabling|loading) (\S+)`),
+		regexp.MustCompile(`^Could not pass event \S+ to (\S+)`),
+		regexp.MustCompile(`^Plugin (\S+) .*(?:generated an exception|has failed to register)`),
+	}
+	doneLine     = regexp.MustCompile(`Done \((\d+(?:[.,]\d+)?)s\)! For help`)
+	cantKeepUp   = regexp.MustCompile(`Can't keep up! Is the server overloaded\? Running (\d+)ms or (\d+) ticks behind`)
+	watchdogLine = regexp.MustCompile(`The server has (?:stopped responding|not responded for)`)
+	digits       = regexp.MustCompile(`\d+`)
+	uuidPattern  = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+)
+
+// ParseLine parses a record in any of the common server log formats. day
+// supplies the date for formats that only log the time of day.
+func ParseLine(raw string, day time.Time) (Line, bool) {
+	raw = strings.TrimRight(raw, "\r")
+	if m := vanillaLine.FindStringSubmatch(raw); m != nil {
+		return Line{Time: atTime(day, m[1]), Thread: m[2], Level: m[3], Message: m[4]}, true
+	}
+	if m := shortLine.FindStringSubmatch(raw); m != nil {
+		return Line{Time: atTime(day, m[1]), Level: m[2], Message: m[3]}, true
+	}
+	if m := forgeLine.FindStringSubmatch(raw); m != nil {
+		t, err := time.ParseInLocation("02Jan2006 15:04:05", m[1], day.Location())
+		if err != nil {
+			return Line{}, false
+		}
+		return Line{Time: t, Thread: m[2], Level: m[3], Source: strings.TrimSuffix(m[4], "/"), Message: m[5], Dated: true}, true
+	}
+	return Line{}, false
+}
+
+// StartupDone reports whether a log message is the "Done (x.xs)! For help"
+// line a server prints once it accepts players, with the startup time.
+func StartupDone(message string) (float64, bool) {
+	m := doneLine.FindStringSubmatch(message)
+	if m == nil {
+		return 0, false
+	}
+	seconds, _ := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", "."), 64)
+	return seconds, true
+}
+
+func atTime(day time.Time, clock string) time.Time {
+	t, err := time.Parse("15:04:05", clock)
+	if err != nil {
+		return day
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), 0, day.Location())
+}
+
+// Count is one grouped message with how often it occurred.
+type Count struct {
+	Text     string    `json:"text"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// Startup is one "Done (x.xs)!" line.
+type Startup struct {
+	Time    time.Time `json:"time"`
+	Seconds float64   `json:"seconds"`
+	File    string    `json:"file"`
+}
+
+// LagDay totals "Can't keep up" warnings for one day.
+type LagDay struct {
+	Day       string `json:"day"`
+	Events    int    `json:"events"`
+	MsBehind  int64  `json:"msBehind"`
+	WorstMs   int64  `json:"worstMs"`
+	Watchdogs int    `json:"watchdogs"`
+}
+
+type Lag struct {
+	Events      int      `json:"events"`
+	MsBehind    int64    `json:"msBehind"`
+	TicksBehind int64    `json:"ticksBehind"`
+	WorstMs     int64    `json:"worstMs"`
+	Watchdogs   int      `json:"watchdogs"`
+	Days        []LagDay `json:"days"`
+}
+
+type Report struct {
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Files      int            `json:"files"`
+	Lines      int            `json:"lines"`
+	Levels     map[string]int `json:"levels"`
+	Errors     []Count        `json:"errors"`
+	Warnings   []Count        `json:"warnings"`
+	Exceptions []Count        `json:"exceptions"`
+	Sources    []Count        `json:"sources"`
+	Startups   []Startup      `json:"startups"`
+	Lag        Lag            `json:"lag"`
+}
+
+// Analyzer accumulates statistics over log files. Records outside
+// [Since, Until) are ignored; zero bounds are open.
+type Analyzer struct {
+	Since time.Time
+	Until time.Time
+
+	files      int
+	lines      int
+	first      time.Time
+	last       time.Time
+	levels     map[string]int
+	errors     map[string]*Count
+	warnings   map[string]*Count
+	exceptions map[string]*Count
+	sources    map[string]*Count
+	startups   []Startup
+	lag        Lag
+	lagDays    map[string]*LagDay
+}
+
+func NewAnalyzer(since, until time.Time) *Analyzer {
+	return &Analyzer{
+		Since:      since,
+		Until:      until,
+		levels:     map[string]int{},
+		errors:     map[string]*Count{},
+		warnings:   map[string]*Count{},
+		exceptions: map[string]*Count{},
+		sources:    map[string]*Count{},
+		lagDays:    map[string]*LagDay{},
+	}
+}
+
+// AddFile reads one log. day is the date the log starts on; records that
+// wrap past midnight move to the following day.
+func (a *Analyzer) AddFile(name string, day time.Time, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+
+	counted := false
+	var previous time.Time
+	var current Line
+	inRange := false
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line, ok := ParseLine(raw, day)
+		if !ok {
+			// Stack traces follow the record that logged them.
+			if inRange {
+				a.exception(strings.TrimSpace(raw), current.Time)
+			}
+			continue
+		}
+		if !previous.IsZero() && !line.Dated && line.Time.Before(previous.Add(-time.Hour)) {
+			day = day.AddDate(0, 0, 1)
+			line.Time = line.Time.AddDate(0, 0, 1)
+		}
+		previous = line.Time
+		current = line
+		inRange = a.contains(line.Time)
+		if !inRange {
+			continue
+		}
+		if !counted {
+			a.files++
+			counted = true
+		}
+		a.add(name, line)
+	}
+	return scanner.Err()
+}
+
+func (a *Analyzer) contains(t time.Time) bool {
+	if !a.Since.IsZero() && t.Before(a.Since) {
+		return false
+	}
+	if !a.Until.IsZero() && !t.Before(a.Until) {
+		return false
+	}
+	return true
+}
+
+func (a *Analyzer) add(file string, line Line) {
+	a.lines++
+	if a.first.IsZero() || line.Time.Before(a.first) {
+		a.first = line.Time
+	}
+	if line.Time.After(a.last) {
+		a.last = line.Time
+	}
+	level := normalizeLevel(line.Level)
+	a.levels[level]++
+
+	if seconds, ok := StartupDone(line.Message); ok {
+		a.startups = append(a.startups, Startup{Time: line.Time, Seconds: seconds, File: file})
+	}
+	if m := cantKeepUp.FindStringSubmatch(line.Message); m != nil {
+		ms, _ := strconv.ParseInt(m[1], 10, 64)
+		ticks, _ := strconv.ParseInt(m[2], 10, 64)
+		a.lag.Events++
+		a.lag.MsBehind += ms
+		a.lag.TicksBehind += ticks
+		a.lag.WorstMs = max(a.lag.WorstMs, ms)
+		d := a.lagDay(line.Time)
+		d.Events++
+		d.MsBehind += ms
+		d.WorstMs = max(d.WorstMs, ms)
+	}
+	if watchdogLine.MatchString(line.Message) {
+		a.lag.Watchdogs++
+		a.lagDay(line.Time).Watchdogs++
+	}
+
+	a.exception(line.Message, line.Time)
+	if level != "ERROR" && level != "WARN" {
+		return
+	}
+	if level == "ERROR" {
+		tally(a.errors, normalizeMessage(line.Message), line.Time)
+	} else if cantKeepUp.FindStringIndex(line.Message) == nil {
+		tally(a.warnings, normalizeMessage(line.Message), line.Time)
+	}
+	if source := messageSource(line); source != "" {
+		tally(a.sources, source, line.Time)
+	}
+}
+
+func (a *Analyzer) exception(text string, t time.Time) {
+	if strings.HasPrefix(text, "at ") || strings.HasPrefix(text, "...") {
+		return
+	}
+	if m := exceptionPattern.FindStringSubmatch(text); m != nil {
+		tally(a.exceptions, m[1], t)
+	}
+}
+
+func (a *Analyzer) lagDay(t time.Time) *LagDay {
+	key := t.Format("2006-01-02")
+	d, ok := a.lagDays[key]
+	if !ok {
+		d = &LagDay{Day: key}
+		a.lagDays[key] = d
+	}
+	return d
+}
+
+// Report returns the totals with each ranking trimmed to the top entries.
+func (a *Analyzer) Report(top int) Report {
+	report := Report{
+		From:       a.first,
+		To:         a.last,
+		Files:      a.files,
+		Lines:      a.lines,
+		Levels:     a.levels,
+		Errors:     ranked(a.errors, top),
+		Warnings:   ranked(a.warnings, top),
+		Exceptions: ranked(a.exceptions, top),
+		Sources:    ranked(a.sources, top),
+		Startups:   append([]Startup{}, a.startups...),
+		Lag:        a.lag,
+	}
+	sort.Slice(report.Startups, func(i, j int) bool { return report.Startups[i].Time.Before(report.Startups[j].Time) })
+	report.Lag.Days = make([]LagDay, 0, len(a.lagDays))
+	for _, d := range a.lagDays {
+		report.Lag.Days = append(report.Lag.Days, *d)
+	}
+	sort.Slice(report.Lag.Days, func(i, j int) bool { return report.Lag.Days[i].Day < report.Lag.Days[j].Day })
+	return report
+}
+
+func tally(counts map[string]*Count, text string, t time.Time) {
+	c, ok := counts[text]
+	if !ok {
+		c = &Count{Text: text}
+		counts[text] = c
+	}
+	c.Count++
+	if t.After(c.LastSeen) {
+		c.LastSeen = t
+	}
+}
+
+func ranked(counts map[string]*Count, top int) []Count {
+	out := make([]Count, 0, len(counts))
+	for _, c := range counts {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Text < out[j].Text
+	})
+	if top > 0 && len(out) > top {
+		out = out[:top]
+	}
+	return out
+}
+
+func normalizeLevel(level string) string {
+	switch strings.ToUpper(level) {
+	case "WARNING":
+		return "WARN"
+	case "SEVERE", "FATAL":
+		return "ERROR"
+	}
+	return strings.ToUpper(level)
+}
+
+// normalizeMessage groups messages that differ only in numbers, UUIDs or
+// coordinates.
+func normalizeMessage(message string) string {
+	message = uuidPattern.ReplaceAllString(message, "<uuid>")
+	message = digits.ReplaceAllString(message, "#")
+	if len(message) > 160 {
+		message = message[:157] + "..."
+	}
+	return strings.TrimSpace(message)
+}
+
+// messageSource names the plugin or mod responsible for a warning, if the
+// line identifies one.
+func messageSource(line Line) string {
+	if line.Source != "" && !strings.Contains(line.Source, ".") {
+		return line.Source
+	}
+	for _, pattern := range pluginMentions {
+		if m := pattern.FindStringSubmatch(line.Message); m != nil {
+			return m[1]
+		}
+	}
+	if m := pluginPrefix.FindStringSubmatch(line.Message); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package logrecord
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Filter keeps the lines at or above Min that match Pattern. Continuation
+// lines, such as the frames of a stack trace, go with the record that
+// started them.
+type Filter struct {
+	Min     Level
+	Pattern *regexp.Regexp
+
+	// kept is the decision on the last record of each stream.
+	kept map[string]bool
+}
+
+// Keep decides on a line of stream (a service or server, as lines of
+// several can interleave). text is what Pattern is matched against.
+func (f *Filter) Keep(stream string, level Level, text string) bool {
+	if f.kept == nil {
+		f.kept = map[string]bool{}
+	}
+	if level == LevelUnknown && continuation(text) {
+		if kept, seen := f.kept[stream]; seen {
+			return kept
+		}
+	}
+	if level == LevelUnknown {
+		level = LevelInfo
+	}
+	keep := level >= f.Min && (f.Pattern == nil || f.Pattern.MatchString(text))
+	f.kept[stream] = keep
+	return keep
+}
+
+// exceptionHeader matches the first line of a Java or .NET stack trace,
+// such as "java.lang.NullPointerException: ...".
+var exceptionHeader = regexp.MustCompile(`^(?:[a-zA-Z_$][\w$]*\.)+[A-Z][\w$]*(?:Exception|Error|Throwable)\b`)
+
+// continuation reports whether a line without a level carries on the
+// previous record: stack traces with their indented frames and "Caused by"
+// lines.
+func continuation(text string) bool {
+	return text == "" || strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") ||
+		strings.HasPrefix(text, "Caused by: ") || strings.HasPrefix(text, "--- End of") ||
+		exceptionHeader.MatchString(text)
+}
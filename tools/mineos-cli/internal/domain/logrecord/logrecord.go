@@ -0,0 +1,212 @@
+// Package logrecord reads the level and message out of service and server
+// log lines, so the log commands can filter them the same way.
+package logrecord
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/loganalysis"
+)
+
+type Level int
+
+const (
+	// LevelUnknown is the level of lines that do not state one.
+	LevelUnknown Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return ""
+}
+
+// ParseLevel reads a level as Serilog, Microsoft.Extensions.Logging, log4j
+// and Node loggers write it, in full or abbreviated.
+func ParseLevel(raw string) (Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "verbose", "vrb", "trace", "trce", "trc", "debug", "dbug", "dbg", "fine", "finer", "finest":
+		return LevelDebug, true
+	case "information", "info", "inf", "config":
+		return LevelInfo, true
+	case "warning", "warn", "wrn":
+		return LevelWarn, true
+	case "error", "err", "eror", "fail", "fatal", "ftl", "critical", "crit", "severe":
+		return LevelError, true
+	}
+	return LevelUnknown, false
+}
+
+// Record is a log line with its parts taken apart.
+type Record struct {
+	// Time is the time the line states, zero when it states none.
+	Time    time.Time
+	Level   Level
+	Message string
+	// Exception is the stack trace of a structured record, if any.
+	Exception string
+}
+
+var (
+	// [12:34:56 INF] message (Serilog's default console template)
+	serilogText = regexp.MustCompile(`^\[(\d{2}:\d{2}:\d{2}) ([A-Z]{3})\] ?(.*)$`)
+	// info: Category[0] (Microsoft's simple console)
+	simpleConsole = regexp.MustCompile(`^(trce|dbug|info|warn|fail|crit): (.*)$`)
+	// {Name}, {@Name}, {Name:000} and {Name,10} in a message template.
+	templateHole = regexp.MustCompile(`\{\{|\}\}|\{[@$]?([A-Za-z0-9_]+)(?:[,:][^}]*)?\}`)
+)
+
+// ParseService parses a line a service container logged: a JSON record
+// (Serilog's compact or plain JSON, Microsoft's JSON console, or the
+// level/msg of Node loggers) or Serilog and Microsoft console text. Lines
+// in none of these are returned as the message.
+func ParseService(text string) Record {
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}") {
+		if record, ok := parseJSON(trimmed); ok {
+			return record
+		}
+	}
+	if m := serilogText.FindStringSubmatch(text); m != nil {
+		if level, ok := ParseLevel(m[2]); ok {
+			return Record{Level: level, Message: m[3]}
+		}
+	}
+	if m := simpleConsole.FindStringSubmatch(text); m != nil {
+		level, _ := ParseLevel(m[1])
+		return Record{Level: level, Message: m[2]}
+	}
+	return Record{Message: text}
+}
+
+// MinecraftLevel reads the level of a server console line, such as
+// "[12:34:56] [Server thread/WARN]: ...".
+func MinecraftLevel(message string) Level {
+	line, ok := loganalysis.ParseLine(message, time.Now())
+	if !ok {
+		return LevelUnknown
+	}
+	level, _ := ParseLevel(line.Level)
+	return level
+}
+
+func parseJSON(text string) (Record, bool) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil {
+		return Record{}, false
+	}
+	lookup := func(keys ...string) (string, bool) {
+		for _, key := range keys {
+			if value, ok := fields[key]; ok && value != nil {
+				return formatValue(value), true
+			}
+		}
+		return "", false
+	}
+
+	var record Record
+	message, ok := lookup("@m", "RenderedMessage", "Message", "message", "msg")
+	if !ok {
+		template, hasTemplate := lookup("@mt", "MessageTemplate")
+		if !hasTemplate {
+			return Record{}, false
+		}
+		properties := fields
+		if nested, ok := fields["Properties"].(map[string]any); ok {
+			properties = nested
+		}
+		message = render(template, properties)
+	}
+	record.Message = message
+
+	if raw, ok := lookup("@l", "Level", "LogLevel", "level", "severity"); ok {
+		record.Level = jsonLevel(raw)
+	} else if _, compact := fields["@t"]; compact {
+		// Compact JSON leaves out the level of Information records.
+		record.Level = LevelInfo
+	}
+	if raw, ok := lookup("@t", "Timestamp", "time", "timestamp"); ok {
+		if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+			record.Time = t
+		}
+	}
+	record.Exception, _ = lookup("@x", "Exception", "exception", "stack")
+	return record, true
+}
+
+// jsonLevel reads a level name, or one of the numbers pino and bunyan use
+// (10 trace to 60 fatal).
+func jsonLevel(raw string) Level {
+	if level, ok := ParseLevel(raw); ok {
+		return level
+	}
+	var n int
+	if _, err := fmt.Sscan(raw, &n); err != nil {
+		return LevelUnknown
+	}
+	switch {
+	case n >= 50:
+		return LevelError
+	case n >= 40:
+		return LevelWarn
+	case n >= 30:
+		return LevelInfo
+	case n > 0:
+		return LevelDebug
+	}
+	return LevelUnknown
+}
+
+// render fills a Serilog message template from the record's properties.
+func render(template string, properties map[string]any) string {
+	return templateHole.ReplaceAllStringFunc(template, func(hole string) string {
+		switch hole {
+		case "{{":
+			return "{"
+		case "}}":
+			return "}"
+		}
+		name := templateHole.FindStringSubmatch(hole)[1]
+		if value, ok := properties[name]; ok {
+			return formatValue(value)
+		}
+		return hole
+	})
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(buf.String())
+}
@@ -0,0 +1,162 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest declares the servers an installation should have.
+type Manifest struct {
+	Servers []Server `yaml:"servers"`
+}
+
+// Server is the desired state of one server. Empty fields are not managed:
+// leaving out mods, for example, leaves the mods directory alone, while
+// "mods: []" asks for no mods at all.
+type Server struct {
+	Name       string            `yaml:"name"`
+	Type       string            `yaml:"type"`
+	Software   string            `yaml:"software"`
+	Version    string            `yaml:"version"`
+	Memory     string            `yaml:"memory"`
+	Eula       bool              `yaml:"eula"`
+	Mods       []Project         `yaml:"mods"`
+	Plugins    []Project         `yaml:"plugins"`
+	Properties map[string]string `yaml:"properties"`
+	Tags       []string          `yaml:"tags"`
+	// Worlds are the world directories the server should have. They are
+	// only checked, since worlds are generated when the server starts.
+	Worlds []string `yaml:"worlds"`
+}
+
+// Project is a Modrinth project, written as "slug" or "slug@version".
+type Project struct {
+	Slug    string
+	Version string
+}
+
+func (p Project) String() string {
+	if p.Version == "" {
+		return p.Slug
+	}
+	return p.Slug + "@" + p.Version
+}
+
+func (p *Project) UnmarshalYAML(node *yaml.Node) error {
+	var value string
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+	slug, version, _ := strings.Cut(strings.TrimSpace(value), "@")
+	if slug == "" {
+		return fmt.Errorf("line %d: empty project", node.Line)
+	}
+	*p = Project{Slug: slug, Version: version}
+	return nil
+}
+
+func (p Project) MarshalYAML() (any, error) {
+	return p.String(), nil
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Parse reads and validates a manifest. Unknown fields are rejected so typos
+// do not silently drop settings.
+func Parse(data []byte) (Manifest, error) {
+	var m Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&m); err != nil {
+		return Manifest{}, fmt.Errorf("parse manifest: %w", err)
+	}
+	if len(m.Servers) == 0 {
+		return Manifest{}, errors.New("manifest declares no servers")
+	}
+
+	seen := map[string]bool{}
+	for i := range m.Servers {
+		server := &m.Servers[i]
+		if !validName.MatchString(server.Name) {
+			return Manifest{}, fmt.Errorf("server %d: invalid name %q", i+1, server.Name)
+		}
+		if seen[server.Name] {
+			return Manifest{}, fmt.Errorf("server %s is declared twice", server.Name)
+		}
+		seen[server.Name] = true
+
+		server.Type = strings.ToLower(strings.TrimSpace(server.Type))
+		if server.Type == "" {
+			server.Type = "java"
+		}
+		if server.Type != "java" && server.Type != "bedrock" {
+			return Manifest{}, fmt.Errorf("%s: type must be java or bedrock", server.Name)
+		}
+		server.Software = strings.ToLower(strings.TrimSpace(server.Software))
+		if server.Software == "" {
+			server.Software = "vanilla"
+		}
+		if server.Type == "bedrock" && (server.Version != "" || server.Memory != "" || server.Mods != nil || server.Plugins != nil) {
+			return Manifest{}, fmt.Errorf("%s: version, memory, mods and plugins only apply to java servers", server.Name)
+		}
+		if server.Software != "vanilla" && server.Software != "paper" && server.Version != "" {
+			return Manifest{}, fmt.Errorf("%s: software must be vanilla or paper to pin a version", server.Name)
+		}
+		if server.Memory != "" {
+			if _, err := ParseMemory(server.Memory); err != nil {
+				return Manifest{}, fmt.Errorf("%s: %w", server.Name, err)
+			}
+		}
+	}
+	return m, nil
+}
+
+// ProfileID is the MineOS profile providing the server jar, or "" when the
+// version is not pinned.
+func (s Server) ProfileID() string {
+	if s.Version == "" {
+		return ""
+	}
+	return s.Software + "-" + s.Version
+}
+
+// MemoryMB returns the heap size in megabytes, or 0 when unmanaged.
+func (s Server) MemoryMB() int {
+	mb, _ := ParseMemory(s.Memory)
+	return mb
+}
+
+// ParseMemory reads sizes like "4G", "4096M" or "4096" (megabytes).
+func ParseMemory(raw string) (int, error) {
+	value := strings.ToUpper(strings.TrimSpace(raw))
+	if value == "" {
+		return 0, nil
+	}
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1024
+		value = strings.TrimSuffix(value, "G")
+	case strings.HasSuffix(value, "M"):
+		value = strings.TrimSuffix(value, "M")
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid memory %q (use e.g. 4G or 4096M)", raw)
+	}
+	return n * multiplier, nil
+}
+
+// FormatMemory is the inverse of ParseMemory.
+func FormatMemory(mb int) string {
+	if mb > 0 && mb%1024 == 0 {
+		return fmt.Sprintf("%dG", mb/1024)
+	}
+	return fmt.Sprintf("%dM", mb)
+}
@@ -0,0 +1,298 @@
+// Package migrate maps servers set up by other tools (Pterodactyl, AMP, or a
+// start script run in screen or tmux) to MineOS server settings.
+package migrate
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Panels a server can be migrated from.
+const (
+	PanelPterodactyl = "pterodactyl"
+	PanelAMP         = "amp"
+	PanelScript      = "script"
+)
+
+// Source is a server found on disk, with what could be read of how it was
+// started.
+type Source struct {
+	Panel string `json:"panel"`
+	// Dir holds the server files (server.properties, the world).
+	Dir  string `json:"dir"`
+	Name string `json:"name"`
+	Java
+	Port int `json:"port,omitempty"`
+	// Notes list what could not be mapped and needs a look after import.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// Java is how a server is launched.
+type Java struct {
+	JarFile string   `json:"jarFile,omitempty"`
+	XmxMB   int      `json:"xmxMB,omitempty"`
+	XmsMB   int      `json:"xmsMB,omitempty"`
+	JvmArgs []string `json:"jvmArgs,omitempty"`
+	JarArgs []string `json:"jarArgs,omitempty"`
+	// ArgFiles are @file arguments, as in Forge's run scripts, which MineOS
+	// does not launch with.
+	ArgFiles []string `json:"argFiles,omitempty"`
+}
+
+// ParseCommand reads a java command line, e.g. from a start script or
+// `screen -dmS mc java -Xmx4G -jar server.jar nogui`. ok is false when the
+// line does not run java.
+func ParseCommand(line string) (Java, bool) {
+	words := splitWords(line)
+	start := -1
+	for i, word := range words {
+		base := strings.TrimSuffix(path.Base(strings.ReplaceAll(word, `\`, "/")), ".exe")
+		if base == "java" {
+			start = i
+			break
+		}
+		// tmux and su take the command as one quoted word.
+		if strings.ContainsAny(word, " \t") {
+			if java, ok := ParseCommand(word); ok {
+				return java, true
+			}
+		}
+	}
+	if start < 0 {
+		return Java{}, false
+	}
+
+	var java Java
+	args := words[start+1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-jar" && i+1 < len(args):
+			java.JarFile = args[i+1]
+			java.JarArgs = trimShellTail(args[i+2:])
+			return java, true
+		case strings.HasPrefix(arg, "-Xmx"):
+			java.XmxMB = ParseMemory(strings.TrimPrefix(arg, "-Xmx"))
+		case strings.HasPrefix(arg, "-Xms"):
+			java.XmsMB = ParseMemory(strings.TrimPrefix(arg, "-Xms"))
+		case strings.HasPrefix(arg, "@"):
+			java.ArgFiles = append(java.ArgFiles, strings.TrimPrefix(arg, "@"))
+		case strings.HasPrefix(arg, "-"):
+			java.JvmArgs = append(java.JvmArgs, arg)
+		default:
+			// A main class, as Forge's arg files leave the jar out.
+			java.JarArgs = trimShellTail(args[i+1:])
+			return java, true
+		}
+	}
+	return java, true
+}
+
+// trimShellTail drops what follows the java arguments in a script line:
+// redirections, pipes, "$@" and the like.
+func trimShellTail(args []string) []string {
+	var kept []string
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg[:1], "|&;><$%") || strings.HasPrefix(arg, "2>") {
+			break
+		}
+		kept = append(kept, arg)
+	}
+	return kept
+}
+
+// ParseScript finds the java command in a start script. Comment lines are
+// skipped; a script that loops to restart the server still has one.
+func ParseScript(script string) (Java, bool) {
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(strings.ToUpper(line), "REM ") {
+			continue
+		}
+		if java, ok := ParseCommand(line); ok {
+			return java, true
+		}
+	}
+	return Java{}, false
+}
+
+// ParseArgsFile reads a JVM arguments file such as Forge's
+// user_jvm_args.txt, one or more arguments per line.
+func ParseArgsFile(text string) Java {
+	var java Java
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, arg := range splitWords(line) {
+			switch {
+			case strings.HasPrefix(arg, "-Xmx"):
+				java.XmxMB = ParseMemory(strings.TrimPrefix(arg, "-Xmx"))
+			case strings.HasPrefix(arg, "-Xms"):
+				java.XmsMB = ParseMemory(strings.TrimPrefix(arg, "-Xms"))
+			default:
+				java.JvmArgs = append(java.JvmArgs, arg)
+			}
+		}
+	}
+	return java
+}
+
+// ParseMemory converts a JVM memory size (4G, 4096M, 512m, bytes) to MB.
+func ParseMemory(value string) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	unit := strings.ToLower(value[len(value)-1:])
+	number := value
+	if unit >= "a" && unit <= "z" {
+		number = value[:len(value)-1]
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	switch unit {
+	case "t":
+		return int(n * 1024 * 1024)
+	case "g":
+		return int(n * 1024)
+	case "m":
+		return int(n)
+	case "k":
+		return int(n / 1024)
+	}
+	return int(n / 1024 / 1024)
+}
+
+var templateVar = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// FromPterodactylEnv maps the environment Wings gives a server's container:
+// memory in SERVER_MEMORY, the jar in SERVER_JARFILE and the egg's startup
+// command in STARTUP, with {{VARIABLE}} placeholders.
+func FromPterodactylEnv(env []string) (Java, []string) {
+	values := map[string]string{}
+	for _, entry := range env {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			values[key] = value
+		}
+	}
+	var notes []string
+	java := Java{JarFile: values["SERVER_JARFILE"]}
+	if startup := values["STARTUP"]; startup != "" {
+		expanded := templateVar.ReplaceAllStringFunc(startup, func(match string) string {
+			return values[templateVar.FindStringSubmatch(match)[1]]
+		})
+		if parsed, ok := ParseCommand(expanded); ok {
+			java = parsed
+		} else {
+			notes = append(notes, "startup command is not a java command line: "+startup)
+		}
+	}
+	if java.XmxMB == 0 {
+		if memory, err := strconv.Atoi(values["SERVER_MEMORY"]); err == nil && memory > 0 {
+			java.XmxMB = memory
+		}
+	}
+	return java, notes
+}
+
+// ParseKVP reads an AMP .kvp settings file: key=value lines.
+func ParseKVP(text string) map[string]string {
+	values := map[string]string{}
+	for _, line := range strings.Split(text, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && !strings.HasPrefix(key, "#") {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+// FromAMPSettings maps an AMP instance's Minecraft module settings. Key
+// names differ between AMP versions, so they are matched by suffix.
+func FromAMPSettings(values map[string]string) Java {
+	var java Java
+	for key, value := range values {
+		lower := strings.ToLower(key)
+		switch {
+		case strings.HasSuffix(lower, "maxheapsizemb") || strings.HasSuffix(lower, "memorymb"):
+			if mb, err := strconv.Atoi(value); err == nil && mb > 0 {
+				java.XmxMB = mb
+			}
+		case strings.HasSuffix(lower, "minheapsizemb"):
+			if mb, err := strconv.Atoi(value); err == nil && mb > 0 {
+				java.XmsMB = mb
+			}
+		case strings.HasSuffix(lower, "javaargs") || strings.HasSuffix(lower, "jvmargs"):
+			java.JvmArgs = append(java.JvmArgs, splitWords(value)...)
+		case strings.HasSuffix(lower, "serverjar") || strings.HasSuffix(lower, "jarfile"):
+			java.JarFile = value
+		}
+	}
+	return java
+}
+
+// ServerName makes a MineOS server name from a directory or instance name:
+// letters, digits, dashes and underscores.
+func ServerName(value string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.TrimSpace(value) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// splitWords splits a command line into words, honouring single and double
+// quotes. A backslash escapes a space, quote or backslash outside single
+// quotes; others are kept, so Windows paths survive.
+func splitWords(line string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && quote != '\'' && i+1 < len(runes) && strings.ContainsRune(" \t'\"\\", runes[i+1]):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
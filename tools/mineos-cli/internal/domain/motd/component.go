@@ -0,0 +1,127 @@
+package motd
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// component is a Minecraft JSON text component as sent in status responses.
+type component struct {
+	Text          string      `json:"text"`
+	Translate     string      `json:"translate"`
+	Color         string      `json:"color"`
+	Bold          *bool       `json:"bold"`
+	Italic        *bool       `json:"italic"`
+	Underlined    *bool       `json:"underlined"`
+	Strikethrough *bool       `json:"strikethrough"`
+	Obfuscated    *bool       `json:"obfuscated"`
+	Extra         []component `json:"extra"`
+}
+
+// UnmarshalJSON accepts the plain-string shorthand for a text component.
+func (c *component) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = component{Text: text}
+		return nil
+	}
+	type plain component
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// ParseComponent converts a JSON text component (or a plain JSON string,
+// which may itself contain '§' codes) into segments.
+func ParseComponent(data []byte) ([]Segment, error) {
+	var root component
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	var b builder
+	appendComponent(&b, root, Style{})
+	return b.segments, nil
+}
+
+func appendComponent(b *builder, c component, inherited Style) {
+	style := inherited
+	if hex, ok := resolveColor(strings.ToLower(c.Color)); ok {
+		style.Color = hex
+	}
+	applyFlag(&style.Bold, c.Bold)
+	applyFlag(&style.Italic, c.Italic)
+	applyFlag(&style.Underlined, c.Underlined)
+	applyFlag(&style.Strikethrough, c.Strikethrough)
+	applyFlag(&style.Obfuscated, c.Obfuscated)
+
+	text := c.Text
+	if text == "" {
+		text = c.Translate
+	}
+	if strings.ContainsRune(text, '§') {
+		for _, segment := range ParseLegacy(text) {
+			b.write(segment.Text, mergeStyle(style, segment.Style))
+		}
+	} else {
+		b.write(text, style)
+	}
+	for _, child := range c.Extra {
+		appendComponent(b, child, style)
+	}
+}
+
+// mergeStyle layers legacy codes inside a component over the component's
+// own style.
+func mergeStyle(base, legacy Style) Style {
+	if legacy == (Style{}) {
+		return base
+	}
+	return legacy
+}
+
+func applyFlag(target *bool, value *bool) {
+	if value != nil {
+		*target = *value
+	}
+}
+
+// MarshalComponent serializes segments as a JSON text component, the form
+// tellraw takes. The segments sit in an array behind an empty parent, so
+// each carries only its own style. The 16 legacy colors are written by name,
+// which servers before 1.16 need.
+func MarshalComponent(segments []Segment) []byte {
+	type styled struct {
+		Text          string `json:"text"`
+		Color         string `json:"color,omitempty"`
+		Bold          bool   `json:"bold,omitempty"`
+		Italic        bool   `json:"italic,omitempty"`
+		Underlined    bool   `json:"underlined,omitempty"`
+		Strikethrough bool   `json:"strikethrough,omitempty"`
+		Obfuscated    bool   `json:"obfuscated,omitempty"`
+	}
+	parts := []any{""}
+	for _, segment := range segments {
+		if segment.Text == "" {
+			continue
+		}
+		style := segment.Style
+		parts = append(parts, styled{
+			Text:          segment.Text,
+			Color:         componentColor(style.Color),
+			Bold:          style.Bold,
+			Italic:        style.Italic,
+			Underlined:    style.Underlined,
+			Strikethrough: style.Strikethrough,
+			Obfuscated:    style.Obfuscated,
+		})
+	}
+	data, _ := json.Marshal(parts)
+	return data
+}
+
+func componentColor(hex string) string {
+	for _, named := range NamedColors {
+		if named.Hex == hex {
+			return named.Name
+		}
+	}
+	return hex
+}
@@ -0,0 +1,577 @@
+package motd
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLines is the number of MOTD lines the multiplayer screen shows.
+const MaxLines = 2
+
+// Style is the formatting applied to a run of text.
+type Style struct {
+	// Color is a #rrggbb hex value; empty means the client default (gray).
+	Color         string
+	Bold          bool
+	Italic        bool
+	Underlined    bool
+	Strikethrough bool
+	Obfuscated    bool
+}
+
+// Segment is a run of text sharing one style. Text may contain '\n'.
+type Segment struct {
+	Text  string
+	Style Style
+}
+
+// NamedColor is one of the 16 legacy chat colors.
+type NamedColor struct {
+	Name string
+	Code byte
+	Hex  string
+}
+
+var NamedColors = []NamedColor{
+	{"black", '0', "#000000"},
+	{"dark_blue", '1', "#0000aa"},
+	{"dark_green", '2', "#00aa00"},
+	{"dark_aqua", '3', "#00aaaa"},
+	{"dark_red", '4', "#aa0000"},
+	{"dark_purple", '5', "#aa00aa"},
+	{"gold", '6', "#ffaa00"},
+	{"gray", '7', "#aaaaaa"},
+	{"dark_gray", '8', "#555555"},
+	{"blue", '9', "#5555ff"},
+	{"green", 'a', "#55ff55"},
+	{"aqua", 'b', "#55ffff"},
+	{"red", 'c', "#ff5555"},
+	{"light_purple", 'd', "#ff55ff"},
+	{"yellow", 'e', "#ffff55"},
+	{"white", 'f', "#ffffff"},
+}
+
+// IsMiniMessage reports whether text looks like MiniMessage markup rather
+// than legacy color codes.
+func IsMiniMessage(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] != '<' {
+			continue
+		}
+		end := strings.IndexByte(text[i:], '>')
+		if end > 1 {
+			if _, ok := parseTagName(text[i+1 : i+end]); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Parse reads MiniMessage markup or legacy '&'/'§' codes, whichever the text
+// uses.
+func Parse(text string) []Segment {
+	if IsMiniMessage(text) {
+		return ParseMiniMessage(text)
+	}
+	return ParseLegacy(text)
+}
+
+// ParseLegacy reads '§' or '&' formatting codes, including the §x§r§r§g§g§b§b
+// and &#rrggbb hex forms. Unknown codes are kept as text.
+func ParseLegacy(text string) []Segment {
+	var b builder
+	style := Style{}
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r != '§' && r != '&' {
+			b.write(string(r), style)
+			i += size
+			continue
+		}
+		rest := text[i+size:]
+		if hex, n := legacyHex(rest, r); n > 0 {
+			style = Style{Color: hex}
+			i += size + n
+			continue
+		}
+		if rest == "" {
+			b.write(string(r), style)
+			i += size
+			continue
+		}
+		code := lower(rest[0])
+		if next, ok := applyCode(style, code); ok {
+			style = next
+			i += size + 1
+			continue
+		}
+		b.write(string(r), style)
+		i += size
+	}
+	return b.segments
+}
+
+func legacyHex(rest string, marker rune) (string, int) {
+	if strings.HasPrefix(rest, "#") && len(rest) >= 7 && isHex(rest[1:7]) {
+		return "#" + strings.ToLower(rest[1:7]), 7
+	}
+	if len(rest) == 0 || lower(rest[0]) != 'x' {
+		return "", 0
+	}
+	prefix := string(marker)
+	digits := make([]byte, 0, 6)
+	pos := 1
+	for len(digits) < 6 {
+		if !strings.HasPrefix(rest[pos:], prefix) || len(rest) < pos+len(prefix)+1 {
+			return "", 0
+		}
+		pos += len(prefix)
+		digits = append(digits, lower(rest[pos]))
+		pos++
+	}
+	if !isHex(string(digits)) {
+		return "", 0
+	}
+	return "#" + string(digits), pos
+}
+
+func applyCode(style Style, code byte) (Style, bool) {
+	for _, color := range NamedColors {
+		if color.Code == code {
+			// A color code resets formatting, as in the vanilla client.
+			return Style{Color: color.Hex}, true
+		}
+	}
+	switch code {
+	case 'k':
+		style.Obfuscated = true
+	case 'l':
+		style.Bold = true
+	case 'm':
+		style.Strikethrough = true
+	case 'n':
+		style.Underlined = true
+	case 'o':
+		style.Italic = true
+	case 'r':
+		style = Style{}
+	default:
+		return style, false
+	}
+	return style, true
+}
+
+// ParseMiniMessage reads the subset of MiniMessage that maps onto legacy
+// formatting: colors (<red>, <#ff0000>, <color:...>), decorations, <reset>,
+// <newline>/<br> and <gradient:#a:#b>. Unknown tags are kept as text, as
+// MiniMessage does.
+func ParseMiniMessage(text string) []Segment {
+	var b builder
+	type frame struct {
+		name     string
+		style    Style
+		gradient []string
+		start    int
+	}
+	stack := []frame{{style: Style{}}}
+	current := func() Style { return stack[len(stack)-1].style }
+
+	for i := 0; i < len(text); {
+		if text[i] == '\\' && i+1 < len(text) && (text[i+1] == '<' || text[i+1] == '\\') {
+			b.write(text[i+1:i+2], current())
+			i += 2
+			continue
+		}
+		if text[i] != '<' {
+			end := strings.IndexAny(text[i:], "<\\")
+			if end < 0 {
+				end = len(text) - i
+			} else if end == 0 {
+				end = 1
+			}
+			b.write(text[i:i+end], current())
+			i += end
+			continue
+		}
+		end := strings.IndexByte(text[i:], '>')
+		if end < 0 {
+			b.write(text[i:], current())
+			break
+		}
+		raw := text[i+1 : i+end]
+		i += end + 1
+
+		closing := strings.HasPrefix(raw, "/")
+		tag, ok := parseTagName(strings.TrimPrefix(raw, "/"))
+		if !ok {
+			b.write("<"+raw+">", current())
+			continue
+		}
+		if closing {
+			for j := len(stack) - 1; j > 0; j-- {
+				if stack[j].name == tag.name || (tag.name == "color" && strings.HasPrefix(stack[j].name, "color:")) {
+					if stack[j].gradient != nil {
+						b.applyGradient(stack[j].start, stack[j].gradient)
+					}
+					stack = stack[:j]
+					break
+				}
+			}
+			continue
+		}
+
+		style := current()
+		if tag.color != "" {
+			style.Color = tag.color
+		}
+		switch tag.name {
+		case "reset":
+			stack = stack[:1]
+			continue
+		case "newline":
+			b.write("\n", style)
+			continue
+		case "gradient":
+			stack = append(stack, frame{name: tag.name, style: style, gradient: tag.gradient, start: b.runes()})
+			continue
+		case "bold":
+			style.Bold = true
+		case "italic":
+			style.Italic = true
+		case "underlined":
+			style.Underlined = true
+		case "strikethrough":
+			style.Strikethrough = true
+		case "obfuscated":
+			style.Obfuscated = true
+		}
+		stack = append(stack, frame{name: tag.name, style: style})
+	}
+	for j := len(stack) - 1; j > 0; j-- {
+		if stack[j].gradient != nil {
+			b.applyGradient(stack[j].start, stack[j].gradient)
+		}
+	}
+	return b.segments
+}
+
+type tag struct {
+	// name identifies the tag for matching closing tags; colors are
+	// "color:<value>" so both </red> and </color> close <red>.
+	name     string
+	color    string
+	gradient []string
+}
+
+var decorationAliases = map[string]string{
+	"b": "bold", "bold": "bold",
+	"i": "italic", "em": "italic", "italic": "italic",
+	"u": "underlined", "underlined": "underlined",
+	"st": "strikethrough", "strikethrough": "strikethrough",
+	"obf": "obfuscated", "obfuscated": "obfuscated",
+	"reset": "reset",
+	"br":    "newline", "newline": "newline",
+}
+
+func parseTagName(raw string) (tag, bool) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if name, ok := decorationAliases[raw]; ok {
+		return tag{name: name}, true
+	}
+	parts := strings.Split(raw, ":")
+	switch parts[0] {
+	case "color", "colour", "c":
+		if len(parts) == 2 {
+			if hex, ok := resolveColor(parts[1]); ok {
+				return tag{name: "color:" + parts[1], color: hex}, true
+			}
+		}
+		if len(parts) == 1 {
+			// </color> closes the innermost color tag.
+			return tag{name: "color"}, true
+		}
+		return tag{}, false
+	case "gradient":
+		var stops []string
+		for _, part := range parts[1:] {
+			hex, ok := resolveColor(part)
+			if !ok {
+				return tag{}, false
+			}
+			stops = append(stops, hex)
+		}
+		if len(parts) > 1 && len(stops) < 2 {
+			return tag{}, false
+		}
+		if len(stops) == 0 {
+			stops = []string{"#ffffff", "#000000"}
+		}
+		return tag{name: "gradient", gradient: stops}, true
+	}
+	if len(parts) == 1 {
+		if hex, ok := resolveColor(raw); ok {
+			return tag{name: "color:" + raw, color: hex}, true
+		}
+	}
+	return tag{}, false
+}
+
+func resolveColor(value string) (string, bool) {
+	if strings.HasPrefix(value, "#") && len(value) == 7 && isHex(value[1:]) {
+		return value, true
+	}
+	if value == "grey" {
+		value = "gray"
+	} else if value == "dark_grey" {
+		value = "dark_gray"
+	}
+	for _, color := range NamedColors {
+		if color.Name == value {
+			return color.Hex, true
+		}
+	}
+	return "", false
+}
+
+// Legacy serializes segments with '§' codes. Hex colors are written in the
+// §x form when keepHex is set (Paper, Spigot and proxies) and otherwise
+// mapped to the nearest of the 16 legacy colors, which every server accepts.
+func Legacy(segments []Segment, keepHex bool) string {
+	var out strings.Builder
+	prev := styleCodes(Style{}, keepHex)
+	for _, segment := range segments {
+		if segment.Text == "" {
+			continue
+		}
+		// Hex colors that map to the same legacy code need no new code.
+		if codes := styleCodes(segment.Style, keepHex); codes != prev {
+			out.WriteString(codes)
+			prev = codes
+		}
+		out.WriteString(segment.Text)
+	}
+	return out.String()
+}
+
+func styleCodes(style Style, keepHex bool) string {
+	var out strings.Builder
+	switch {
+	case style.Color == "":
+		out.WriteString("§r")
+	case keepHex && nearestCode(style.Color).Hex != style.Color:
+		out.WriteString("§x")
+		for _, digit := range strings.TrimPrefix(style.Color, "#") {
+			out.WriteString("§" + string(digit))
+		}
+	default:
+		out.WriteString("§" + string(nearestCode(style.Color).Code))
+	}
+	if style.Obfuscated {
+		out.WriteString("§k")
+	}
+	if style.Bold {
+		out.WriteString("§l")
+	}
+	if style.Strikethrough {
+		out.WriteString("§m")
+	}
+	if style.Underlined {
+		out.WriteString("§n")
+	}
+	if style.Italic {
+		out.WriteString("§o")
+	}
+	return out.String()
+}
+
+// ToAmpersand replaces '§' with '&' so codes can be typed on a keyboard.
+func ToAmpersand(legacy string) string {
+	return strings.ReplaceAll(legacy, "§", "&")
+}
+
+// PlainText strips all formatting.
+func PlainText(segments []Segment) string {
+	var out strings.Builder
+	for _, segment := range segments {
+		out.WriteString(segment.Text)
+	}
+	return out.String()
+}
+
+// Lines reports the visible lines and the width of the longest.
+func Lines(segments []Segment) (int, int) {
+	lines := strings.Split(PlainText(segments), "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, utf8.RuneCountInString(line))
+	}
+	return len(lines), width
+}
+
+func nearestCode(hex string) NamedColor {
+	r, g, b := rgb(hex)
+	best := NamedColors[0]
+	bestDist := math.MaxFloat64
+	for _, color := range NamedColors {
+		cr, cg, cb := rgb(color.Hex)
+		dist := sq(r-cr) + sq(g-cg) + sq(b-cb)
+		if dist < bestDist {
+			best, bestDist = color, dist
+		}
+	}
+	return best
+}
+
+// DecodeProperty undoes the Java properties escaping Minecraft applies when
+// it rewrites server.properties (§, \n, \\).
+func DecodeProperty(raw string) string {
+	var out strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' || i+1 >= len(raw) {
+			out.WriteByte(raw[i])
+			continue
+		}
+		switch raw[i+1] {
+		case 'n':
+			out.WriteByte('\n')
+			i++
+		case 't':
+			out.WriteByte('\t')
+			i++
+		case 'u':
+			if i+6 <= len(raw) {
+				if code, err := strconv.ParseUint(raw[i+2:i+6], 16, 32); err == nil {
+					out.WriteRune(rune(code))
+					i += 5
+					continue
+				}
+			}
+			out.WriteByte(raw[i])
+		default:
+			out.WriteByte(raw[i+1])
+			i++
+		}
+	}
+	return out.String()
+}
+
+// EncodeProperty escapes a value for server.properties. Non-ASCII runes are
+// written as \uXXXX so the file reads the same whichever charset the server
+// loads it with.
+func EncodeProperty(value string) string {
+	var out strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '\\':
+			out.WriteString(`\\`)
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r > 0x7e || r < 0x20:
+			fmt.Fprintf(&out, `\u%04X`, r)
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+type builder struct {
+	segments []Segment
+}
+
+func (b *builder) write(text string, style Style) {
+	if text == "" {
+		return
+	}
+	if n := len(b.segments); n > 0 && b.segments[n-1].Style == style {
+		b.segments[n-1].Text += text
+		return
+	}
+	b.segments = append(b.segments, Segment{Text: text, Style: style})
+}
+
+func (b *builder) runes() int {
+	count := 0
+	for _, segment := range b.segments {
+		count += utf8.RuneCountInString(segment.Text)
+	}
+	return count
+}
+
+// applyGradient recolors every rune after start, interpolating between the
+// stops.
+func (b *builder) applyGradient(start int, stops []string) {
+	var head, tail []Segment
+	pos := 0
+	for _, segment := range b.segments {
+		runes := []rune(segment.Text)
+		switch {
+		case pos+len(runes) <= start:
+			head = append(head, segment)
+		case pos >= start:
+			tail = append(tail, segment)
+		default:
+			cut := start - pos
+			head = append(head, Segment{Text: string(runes[:cut]), Style: segment.Style})
+			tail = append(tail, Segment{Text: string(runes[cut:]), Style: segment.Style})
+		}
+		pos += len(runes)
+	}
+
+	total := 0
+	for _, segment := range tail {
+		total += utf8.RuneCountInString(strings.ReplaceAll(segment.Text, "\n", ""))
+	}
+	b.segments = head
+	index := 0
+	for _, segment := range tail {
+		for _, r := range segment.Text {
+			style := segment.Style
+			style.Color = interpolate(stops, index, total)
+			if r != '\n' {
+				index++
+			}
+			b.write(string(r), style)
+		}
+	}
+}
+
+func interpolate(stops []string, index, total int) string {
+	if total <= 1 {
+		return stops[0]
+	}
+	t := float64(index) / float64(total-1) * float64(len(stops)-1)
+	seg := min(int(t), len(stops)-2)
+	frac := t - float64(seg)
+	r1, g1, b1 := rgb(stops[seg])
+	r2, g2, b2 := rgb(stops[seg+1])
+	mix := func(a, b float64) int { return int(math.Round(a + (b-a)*frac)) }
+	return fmt.Sprintf("#%02x%02x%02x", mix(r1, r2), mix(g1, g2), mix(b1, b2))
+}
+
+func rgb(hex string) (float64, float64, float64) {
+	value, _ := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	return float64(value >> 16 & 0xff), float64(value >> 8 & 0xff), float64(value & 0xff)
+}
+
+func sq(v float64) float64 { return v * v }
+
+func isHex(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", rune(value[i])) {
+			return false
+		}
+	}
+	return value != ""
+}
+
+func lower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
@@ -0,0 +1,233 @@
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// splitPath turns "Data.Player.Pos[0]" into Data, Player, Pos, 0.
+func splitPath(path string) []string {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	var segments []string
+	for _, segment := range strings.Split(path, ".") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// Set replaces the tag at path. An existing tag keeps its type; a new tag
+// can only be added to an existing compound.
+func (c Compound) Set(path string, value any) error {
+	segments := splitPath(path)
+	if len(segments) == 0 {
+		return errors.New("path is empty")
+	}
+	if TypeOf(value) == TagEnd {
+		return fmt.Errorf("unsupported value %T", value)
+	}
+	parentPath := strings.Join(segments[:len(segments)-1], ".")
+	var parent any = c
+	if parentPath != "" {
+		var ok bool
+		if parent, ok = c.Get(parentPath); !ok {
+			return fmt.Errorf("%s does not exist", parentPath)
+		}
+	}
+	last := segments[len(segments)-1]
+
+	switch container := parent.(type) {
+	case Compound:
+		if existing, ok := container[last]; ok && TypeOf(existing) != TypeOf(value) {
+			return fmt.Errorf("%s is a %s, not a %s", path, TypeName(TypeOf(existing)), TypeName(TypeOf(value)))
+		}
+		container[last] = value
+		return nil
+	case List:
+		i, err := listIndex(last, len(container.Items))
+		if err != nil {
+			return err
+		}
+		if TypeOf(value) != container.Type {
+			return fmt.Errorf("%s holds %s values, not %s", parentPath, TypeName(container.Type), TypeName(TypeOf(value)))
+		}
+		container.Items[i] = value
+		return nil
+	case []byte:
+		i, err := listIndex(last, len(container))
+		if err != nil {
+			return err
+		}
+		b, ok := value.(int8)
+		if !ok {
+			return fmt.Errorf("%s holds byte values", parentPath)
+		}
+		container[i] = byte(b)
+		return nil
+	case []int32:
+		i, err := listIndex(last, len(container))
+		if err != nil {
+			return err
+		}
+		v, ok := value.(int32)
+		if !ok {
+			return fmt.Errorf("%s holds int values", parentPath)
+		}
+		container[i] = v
+		return nil
+	case []int64:
+		i, err := listIndex(last, len(container))
+		if err != nil {
+			return err
+		}
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("%s holds long values", parentPath)
+		}
+		container[i] = v
+		return nil
+	}
+	return fmt.Errorf("%s is a %s and has no children", parentPath, TypeName(TypeOf(parent)))
+}
+
+func listIndex(segment string, length int) (int, error) {
+	i, err := strconv.Atoi(segment)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a list index", segment)
+	}
+	if i < 0 || i >= length {
+		return 0, fmt.Errorf("index %d is out of range (length %d)", i, length)
+	}
+	return i, nil
+}
+
+// ParseLike parses a command-line value into the type of an existing tag.
+// Lists take "[a, b, c]" with elements of the list's type.
+func ParseLike(raw string, like any) (any, error) {
+	if list, ok := like.(List); ok {
+		items := splitItems(raw)
+		elemType := list.Type
+		if elemType == TagEnd && len(list.Items) == 0 && len(items) > 0 {
+			return nil, errors.New("cannot infer the element type of an empty list")
+		}
+		out := List{Type: elemType, Items: make([]any, 0, len(items))}
+		for _, item := range items {
+			value, err := ParseValue(item, elemType)
+			if err != nil {
+				return nil, err
+			}
+			out.Items = append(out.Items, value)
+		}
+		return out, nil
+	}
+	return ParseValue(raw, TypeOf(like))
+}
+
+// ParseValue parses a command-line value as a tag type. Numbers may carry
+// SNBT suffixes (1b, 2s, 3L, 1.5f, 2.0d) and bytes accept true/false.
+// Compounds cannot be parsed.
+func ParseValue(raw string, tagType byte) (any, error) {
+	raw = strings.TrimSpace(raw)
+	bad := func() error {
+		return fmt.Errorf("%q is not a valid %s", raw, TypeName(tagType))
+	}
+	switch tagType {
+	case TagByte:
+		switch strings.ToLower(raw) {
+		case "true":
+			return int8(1), nil
+		case "false":
+			return int8(0), nil
+		}
+		v, err := strconv.ParseInt(strings.TrimRight(raw, "bB"), 10, 8)
+		if err != nil {
+			return nil, bad()
+		}
+		return int8(v), nil
+	case TagShort:
+		v, err := strconv.ParseInt(strings.TrimRight(raw, "sS"), 10, 16)
+		if err != nil {
+			return nil, bad()
+		}
+		return int16(v), nil
+	case TagInt:
+		v, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return nil, bad()
+		}
+		return int32(v), nil
+	case TagLong:
+		v, err := strconv.ParseInt(strings.TrimRight(raw, "lL"), 10, 64)
+		if err != nil {
+			return nil, bad()
+		}
+		return v, nil
+	case TagFloat:
+		v, err := strconv.ParseFloat(strings.TrimRight(raw, "fF"), 32)
+		if err != nil {
+			return nil, bad()
+		}
+		return float32(v), nil
+	case TagDouble:
+		v, err := strconv.ParseFloat(strings.TrimRight(raw, "dD"), 64)
+		if err != nil {
+			return nil, bad()
+		}
+		return v, nil
+	case TagString:
+		if unquoted, err := strconv.Unquote(raw); err == nil {
+			return unquoted, nil
+		}
+		return raw, nil
+	case TagByteArray, TagIntArray, TagLongArray:
+		// Accept SNBT's "[I; 1, 2]" as well as a plain "[1, 2]".
+		inner := strings.TrimSpace(strings.TrimPrefix(raw, "["))
+		if len(inner) >= 2 && inner[1] == ';' {
+			inner = inner[2:]
+		}
+		items := splitItems(inner)
+		elemType := map[byte]byte{TagByteArray: TagByte, TagIntArray: TagInt, TagLongArray: TagLong}[tagType]
+		var bytes []byte
+		var ints []int32
+		var longs []int64
+		for _, item := range items {
+			value, err := ParseValue(item, elemType)
+			if err != nil {
+				return nil, err
+			}
+			switch v := value.(type) {
+			case int8:
+				bytes = append(bytes, byte(v))
+			case int32:
+				ints = append(ints, v)
+			case int64:
+				longs = append(longs, v)
+			}
+		}
+		switch tagType {
+		case TagByteArray:
+			return append([]byte{}, bytes...), nil
+		case TagIntArray:
+			return append([]int32{}, ints...), nil
+		}
+		return append([]int64{}, longs...), nil
+	}
+	return nil, fmt.Errorf("values of type %s cannot be set from the command line", TypeName(tagType))
+}
+
+// splitItems splits "[a, b, c]" (brackets optional) into its elements.
+func splitItems(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	raw = strings.TrimSuffix(strings.TrimPrefix(raw, "["), "]")
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	items := strings.Split(raw, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
@@ -0,0 +1,208 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Compression of a stored NBT file.
+const (
+	Uncompressed = iota
+	Gzip
+	Zlib
+)
+
+// Document is a whole NBT file: the root compound plus what is needed to
+// write it back the way it was stored.
+type Document struct {
+	Name        string
+	Root        Compound
+	Compression int
+}
+
+// Read decodes a file and remembers its compression.
+func Read(data []byte) (Document, error) {
+	doc := Document{Compression: compressionOf(data)}
+	name, root, err := Decode(data)
+	if err != nil {
+		return Document{}, err
+	}
+	doc.Name, doc.Root = name, root
+	return doc, nil
+}
+
+func compressionOf(data []byte) int {
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		return Gzip
+	case len(data) >= 2 && data[0] == 0x78:
+		return Zlib
+	}
+	return Uncompressed
+}
+
+// Bytes encodes the document with its original compression.
+func (d Document) Bytes() ([]byte, error) {
+	raw, err := Encode(d.Name, d.Root)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	switch d.Compression {
+	case Gzip:
+		w := gzip.NewWriter(&buf)
+		w.Write(raw)
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	case Zlib:
+		w := zlib.NewWriter(&buf)
+		w.Write(raw)
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return raw, nil
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode writes an uncompressed root compound. Compound keys are written in
+// sorted order; the game does not depend on key order.
+func Encode(name string, root Compound) ([]byte, error) {
+	e := &encoder{}
+	e.buf.WriteByte(TagCompound)
+	e.string(name)
+	if err := e.payload(root); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *encoder) string(s string) {
+	binary.Write(&e.buf, binary.BigEndian, uint16(len(s)))
+	e.buf.WriteString(s)
+}
+
+func (e *encoder) payload(value any) error {
+	switch v := value.(type) {
+	case int8:
+		e.buf.WriteByte(byte(v))
+	case int16:
+		binary.Write(&e.buf, binary.BigEndian, v)
+	case int32:
+		binary.Write(&e.buf, binary.BigEndian, v)
+	case int64:
+		binary.Write(&e.buf, binary.BigEndian, v)
+	case float32:
+		binary.Write(&e.buf, binary.BigEndian, math.Float32bits(v))
+	case float64:
+		binary.Write(&e.buf, binary.BigEndian, math.Float64bits(v))
+	case []byte:
+		binary.Write(&e.buf, binary.BigEndian, int32(len(v)))
+		e.buf.Write(v)
+	case string:
+		if len(v) > math.MaxUint16 {
+			return fmt.Errorf("string of %d bytes is too long", len(v))
+		}
+		e.string(v)
+	case List:
+		e.buf.WriteByte(v.Type)
+		binary.Write(&e.buf, binary.BigEndian, int32(len(v.Items)))
+		for _, item := range v.Items {
+			if TypeOf(item) != v.Type {
+				return fmt.Errorf("list of %s holds a %s", TypeName(v.Type), TypeName(TypeOf(item)))
+			}
+			if err := e.payload(item); err != nil {
+				return err
+			}
+		}
+	case Compound:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			tagType := TypeOf(v[name])
+			if tagType == TagEnd {
+				return fmt.Errorf("%s: unsupported value %T", name, v[name])
+			}
+			e.buf.WriteByte(tagType)
+			e.string(name)
+			if err := e.payload(v[name]); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		e.buf.WriteByte(TagEnd)
+	case []int32:
+		binary.Write(&e.buf, binary.BigEndian, int32(len(v)))
+		binary.Write(&e.buf, binary.BigEndian, v)
+	case []int64:
+		binary.Write(&e.buf, binary.BigEndian, int32(len(v)))
+		binary.Write(&e.buf, binary.BigEndian, v)
+	default:
+		return fmt.Errorf("unsupported value %T", value)
+	}
+	return nil
+}
+
+// TypeOf returns the tag type of a decoded value, or TagEnd if it is not one.
+func TypeOf(value any) byte {
+	switch value.(type) {
+	case int8:
+		return TagByte
+	case int16:
+		return TagShort
+	case int32:
+		return TagInt
+	case int64:
+		return TagLong
+	case float32:
+		return TagFloat
+	case float64:
+		return TagDouble
+	case []byte:
+		return TagByteArray
+	case string:
+		return TagString
+	case List:
+		return TagList
+	case Compound:
+		return TagCompound
+	case []int32:
+		return TagIntArray
+	case []int64:
+		return TagLongArray
+	}
+	return TagEnd
+}
+
+var typeNames = []string{"end", "byte", "short", "int", "long", "float", "double", "byte[]", "string", "list", "compound", "int[]", "long[]"}
+
+// TypeName returns the name used for a tag type in output and --type flags.
+func TypeName(tagType byte) string {
+	if int(tagType) < len(typeNames) {
+		return typeNames[tagType]
+	}
+	return fmt.Sprintf("type %d", tagType)
+}
+
+// ParseType is the inverse of TypeName.
+func ParseType(name string) (byte, bool) {
+	for i, typeName := range typeNames {
+		if i > 0 && typeName == name {
+			return byte(i), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,126 @@
+package nbt
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxArrayItems caps how many array elements Format prints; chunk data
+// holds arrays of thousands of longs.
+const maxArrayItems = 16
+
+var plainKey = regexp.MustCompile(`^[A-Za-z0-9_.+-]+$`)
+
+// Format renders a value as indented SNBT, the syntax of /data get.
+func Format(value any) string {
+	var b strings.Builder
+	format(&b, value, "")
+	return b.String()
+}
+
+func format(b *strings.Builder, value any, indent string) {
+	switch v := value.(type) {
+	case int8:
+		fmt.Fprintf(b, "%db", v)
+	case int16:
+		fmt.Fprintf(b, "%ds", v)
+	case int32:
+		fmt.Fprintf(b, "%d", v)
+	case int64:
+		fmt.Fprintf(b, "%dL", v)
+	case float32:
+		b.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32) + "f")
+	case float64:
+		b.WriteString(strconv.FormatFloat(v, 'g', -1, 64) + "d")
+	case string:
+		b.WriteString(strconv.Quote(v))
+	case []byte:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprintf("%db", int8(item))
+		}
+		formatArray(b, "B", items)
+	case []int32:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = strconv.Itoa(int(item))
+		}
+		formatArray(b, "I", items)
+	case []int64:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprintf("%dL", item)
+		}
+		formatArray(b, "L", items)
+	case List:
+		if len(v.Items) == 0 {
+			b.WriteString("[]")
+			return
+		}
+		if v.Type != TagCompound && v.Type != TagList {
+			b.WriteString("[")
+			for i, item := range v.Items {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				format(b, item, indent)
+			}
+			b.WriteString("]")
+			return
+		}
+		b.WriteString("[\n")
+		for i, item := range v.Items {
+			b.WriteString(indent + "  ")
+			format(b, item, indent+"  ")
+			if i < len(v.Items)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "]")
+	case Compound:
+		if len(v) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		b.WriteString("{\n")
+		for i, name := range names {
+			key := name
+			if !plainKey.MatchString(key) {
+				key = strconv.Quote(key)
+			}
+			b.WriteString(indent + "  " + key + ": ")
+			format(b, v[name], indent+"  ")
+			if i < len(names)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "}")
+	default:
+		fmt.Fprintf(b, "%v", v)
+	}
+}
+
+func formatArray(b *strings.Builder, prefix string, items []string) {
+	b.WriteString("[" + prefix + ";")
+	for i, item := range items {
+		if i == maxArrayItems {
+			fmt.Fprintf(b, " ... (%d total)", len(items))
+			break
+		}
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(" " + item)
+	}
+	b.WriteString("]")
+}
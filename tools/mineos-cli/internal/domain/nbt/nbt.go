@@ -0,0 +1,356 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Tag types of the Java Edition (big-endian) NBT format.
+const (
+	TagEnd byte = iota
+	TagByte
+	TagShort
+	TagInt
+	TagLong
+	TagFloat
+	TagDouble
+	TagByteArray
+	TagString
+	TagList
+	TagCompound
+	TagIntArray
+	TagLongArray
+)
+
+// maxDepth bounds nesting so corrupt data cannot exhaust the stack.
+const maxDepth = 512
+
+// Values decode to int8, int16, int32, int64, float32, float64, []byte,
+// string, List, Compound, []int32 and []int64.
+
+// Compound is a named set of tags.
+type Compound map[string]any
+
+// List is a sequence of tags that share one type.
+type List struct {
+	Type  byte
+	Items []any
+}
+
+// ErrTruncated is returned when the data ends inside a tag.
+var ErrTruncated = errors.New("nbt data is truncated")
+
+// Decode reads a root compound, decompressing gzip (level.dat, player data)
+// or zlib data first. It returns the root tag's name, usually empty.
+func Decode(data []byte) (string, Compound, error) {
+	data, err := decompress(data)
+	if err != nil {
+		return "", nil, err
+	}
+	d := &decoder{data: data}
+	tagType, err := d.byte()
+	if err != nil {
+		return "", nil, err
+	}
+	if tagType != TagCompound {
+		return "", nil, fmt.Errorf("root tag is type %d, not a compound", tagType)
+	}
+	name, err := d.string()
+	if err != nil {
+		return "", nil, err
+	}
+	value, err := d.payload(TagCompound, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, value.(Compound), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		reader, err = gzip.NewReader(bytes.NewReader(data))
+	case len(data) >= 2 && data[0] == 0x78:
+		reader, err = zlib.NewReader(bytes.NewReader(data))
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decompress nbt: %w", err)
+	}
+	defer reader.Close()
+	out, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("decompress nbt: %w", err)
+	}
+	return out, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) take(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, ErrTruncated
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	b, err := d.take(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *decoder) uint16() (uint16, error) {
+	b, err := d.take(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b), nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	b, err := d.take(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+func (d *decoder) uint64() (uint64, error) {
+	b, err := d.take(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// string reads a length-prefixed string. Java's modified UTF-8 only differs
+// from UTF-8 for NUL and supplementary characters, which names and values in
+// world files do not use in practice.
+func (d *decoder) string() (string, error) {
+	n, err := d.uint16()
+	if err != nil {
+		return "", err
+	}
+	b, err := d.take(int(n))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// length reads an array or list length and checks it fits in the remaining
+// data, so a corrupt length cannot trigger a huge allocation.
+func (d *decoder) length(elemSize int) (int, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return 0, err
+	}
+	length := int(int32(n))
+	if length < 0 {
+		return 0, fmt.Errorf("negative length %d", length)
+	}
+	if length*elemSize > len(d.data)-d.pos {
+		return 0, ErrTruncated
+	}
+	return length, nil
+}
+
+func (d *decoder) payload(tagType byte, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.New("nbt data is nested too deeply")
+	}
+	switch tagType {
+	case TagByte:
+		b, err := d.byte()
+		return int8(b), err
+	case TagShort:
+		v, err := d.uint16()
+		return int16(v), err
+	case TagInt:
+		v, err := d.uint32()
+		return int32(v), err
+	case TagLong:
+		v, err := d.uint64()
+		return int64(v), err
+	case TagFloat:
+		v, err := d.uint32()
+		return math.Float32frombits(v), err
+	case TagDouble:
+		v, err := d.uint64()
+		return math.Float64frombits(v), err
+	case TagByteArray:
+		n, err := d.length(1)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.take(n)
+		return append([]byte(nil), b...), err
+	case TagString:
+		return d.string()
+	case TagList:
+		elemType, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		n, err := d.length(1)
+		if err != nil {
+			return nil, err
+		}
+		if elemType == TagEnd && n > 0 {
+			return nil, errors.New("list of end tags is not empty")
+		}
+		list := List{Type: elemType, Items: make([]any, 0, n)}
+		for i := 0; i < n; i++ {
+			item, err := d.payload(elemType, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list.Items = append(list.Items, item)
+		}
+		return list, nil
+	case TagCompound:
+		compound := Compound{}
+		for {
+			childType, err := d.byte()
+			if err != nil {
+				return nil, err
+			}
+			if childType == TagEnd {
+				return compound, nil
+			}
+			name, err := d.string()
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.payload(childType, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			compound[name] = value
+		}
+	case TagIntArray:
+		n, err := d.length(4)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]int32, n)
+		for i := range values {
+			v, _ := d.uint32()
+			values[i] = int32(v)
+		}
+		return values, nil
+	case TagLongArray:
+		n, err := d.length(8)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]int64, n)
+		for i := range values {
+			v, _ := d.uint64()
+			values[i] = int64(v)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unknown tag type %d", tagType)
+	}
+}
+
+// Get looks up a dot-separated path such as "Data.Version.Name". Numeric
+// segments, or indexes like "Pos[0]", select list and array elements.
+func (c Compound) Get(path string) (any, bool) {
+	var current any = c
+	for _, segment := range splitPath(path) {
+		switch value := current.(type) {
+		case Compound:
+			next, ok := value[segment]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case List:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(value.Items) {
+				return nil, false
+			}
+			current = value.Items[i]
+		case []byte:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(value) {
+				return nil, false
+			}
+			current = int8(value[i])
+		case []int32:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(value) {
+				return nil, false
+			}
+			current = value[i]
+		case []int64:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(value) {
+				return nil, false
+			}
+			current = value[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// Int returns an integer tag of any width.
+func (c Compound) Int(path string) (int64, bool) {
+	value, ok := c.Get(path)
+	if !ok {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	}
+	return 0, false
+}
+
+// String returns a string tag.
+func (c Compound) String(path string) (string, bool) {
+	value, ok := c.Get(path)
+	if !ok {
+		return "", false
+	}
+	s, ok := value.(string)
+	return s, ok
+}
+
+// Compound returns a nested compound tag.
+func (c Compound) Compound(path string) (Compound, bool) {
+	value, ok := c.Get(path)
+	if !ok {
+		return nil, false
+	}
+	nested, ok := value.(Compound)
+	return nested, ok
+}
@@ -0,0 +1,180 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func sampleRoot() Compound {
+	return Compound{
+		"Data": Compound{
+			"LevelName":   "survival",
+			"SpawnX":      int32(-120),
+			"Time":        int64(1 << 40),
+			"hardcore":    int8(1),
+			"Version":     Compound{"Id": int32(3953), "Name": "1.21"},
+			"Pos":         List{Type: TagDouble, Items: []any{0.5, 64.0, -3.25}},
+			"Seeds":       []int64{7, -7},
+			"Tags":        List{Type: TagString, Items: []any{"a", "b"}},
+			"Bytes":       []byte{1, 2, 3},
+			"Ints":        []int32{4, 5},
+			"Temperature": float32(0.8),
+			"Short":       int16(-2),
+		},
+	}
+}
+
+func compressed(t *testing.T, raw []byte, compression int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	switch compression {
+	case Gzip:
+		w := gzip.NewWriter(&buf)
+		w.Write(raw)
+		w.Close()
+	case Zlib:
+		w := zlib.NewWriter(&buf)
+		w.Write(raw)
+		w.Close()
+	default:
+		return raw
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	raw, err := Encode("root", sampleRoot())
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	tests := []struct {
+		name        string
+		compression int
+	}{
+		{"uncompressed", Uncompressed},
+		{"gzip", Gzip},
+		{"zlib", Zlib},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Read(compressed(t, raw, tt.compression))
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if doc.Name != "root" || doc.Compression != tt.compression {
+				t.Fatalf("name %q compression %d, want root %d", doc.Name, doc.Compression, tt.compression)
+			}
+			if !reflect.DeepEqual(doc.Root, sampleRoot()) {
+				t.Fatalf("root = %#v", doc.Root)
+			}
+			again, err := doc.Bytes()
+			if err != nil {
+				t.Fatalf("bytes: %v", err)
+			}
+			if _, root, err := Decode(again); err != nil || !reflect.DeepEqual(root, sampleRoot()) {
+				t.Fatalf("re-decode: %v %#v", err, root)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	raw, err := Encode("", sampleRoot())
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	tests := []struct {
+		name      string
+		data      []byte
+		truncated bool
+	}{
+		{name: "empty", data: nil, truncated: true},
+		{name: "cut short", data: raw[:len(raw)/2], truncated: true},
+		{name: "missing end tag", data: raw[:len(raw)-1], truncated: true},
+		{name: "root is not a compound", data: []byte{TagString, 0, 0, 0, 1, 'x'}},
+		{name: "unknown tag type", data: []byte{TagCompound, 0, 0, 99, 0, 1, 'x', TagEnd}},
+		{name: "negative length", data: []byte{TagCompound, 0, 0, TagByteArray, 0, 1, 'x', 0xff, 0xff, 0xff, 0xff, TagEnd}},
+		{name: "length past the data", data: []byte{TagCompound, 0, 0, TagIntArray, 0, 1, 'x', 0, 0, 1, 0, TagEnd}, truncated: true},
+		{name: "non-empty list of end tags", data: []byte{TagCompound, 0, 0, TagList, 0, 1, 'x', TagEnd, 0, 0, 0, 1, TagEnd}},
+		{name: "corrupt gzip", data: []byte{0x1f, 0x8b, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Decode(tt.data)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if errors.Is(err, ErrTruncated) != tt.truncated {
+				t.Fatalf("err = %v, truncated want %v", err, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestDecodeNestingLimit(t *testing.T) {
+	var data []byte
+	data = append(data, TagCompound, 0, 0)
+	for i := 0; i <= maxDepth+1; i++ {
+		data = append(data, TagCompound, 0, 1, 'c')
+	}
+	if _, _, err := Decode(data); err == nil || errors.Is(err, ErrTruncated) {
+		t.Fatalf("err = %v, want the nesting limit", err)
+	}
+}
+
+func TestCompoundGet(t *testing.T) {
+	root := sampleRoot()
+	tests := []struct {
+		path string
+		want any
+		ok   bool
+	}{
+		{"Data.LevelName", "survival", true},
+		{"Data.Version.Id", int32(3953), true},
+		{"Data.Pos[1]", 64.0, true},
+		{"Data.Pos.2", -3.25, true},
+		{"Data.Seeds[1]", int64(-7), true},
+		{"Data.Bytes[0]", int8(1), true},
+		{"Data.Ints[1]", int32(5), true},
+		{"Data.Pos[3]", nil, false},
+		{"Data.Pos[-1]", nil, false},
+		{"Data.Missing", nil, false},
+		{"Data.LevelName.x", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := root.Get(tt.path)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Get(%q) = %#v, %v; want %#v, %v", tt.path, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCompoundInt(t *testing.T) {
+	root := sampleRoot()
+	tests := []struct {
+		path string
+		want int64
+		ok   bool
+	}{
+		{"Data.hardcore", 1, true},
+		{"Data.Short", -2, true},
+		{"Data.SpawnX", -120, true},
+		{"Data.Time", 1 << 40, true},
+		{"Data.LevelName", 0, false},
+		{"Data.Temperature", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := root.Int(tt.path)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("Int(%q) = %d, %v; want %d, %v", tt.path, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	RoleProxy   = "proxy"
+	RoleBackend = "backend"
+)
+
+// Definition describes how the servers of a proxied network relate to each
+// other. Servers that are not listed are treated as backends without
+// dependencies.
+type Definition struct {
+	// StaggerSeconds is the pause between start/stop tiers.
+	StaggerSeconds int               `yaml:"stagger_seconds"`
+	Servers        map[string]Member `yaml:"servers"`
+}
+
+type Member struct {
+	Role      string   `yaml:"role"`
+	DependsOn []string `yaml:"depends_on"`
+}
+
+func (d Definition) IsProxy(name string) bool {
+	member, ok := d.Servers[name]
+	return ok && strings.EqualFold(member.Role, RoleProxy)
+}
+
+// StartTiers groups the given servers into tiers that can be started in order.
+// Every server starts after the servers it depends on, and proxies start after
+// all backends so players are never routed to a backend that is still down.
+func (d Definition) StartTiers(names []string) ([][]string, error) {
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	deps := map[string][]string{}
+	for _, name := range names {
+		member := d.Servers[name]
+		for _, dep := range member.DependsOn {
+			if wanted[dep] && dep != name {
+				deps[name] = append(deps[name], dep)
+			}
+		}
+		if d.IsProxy(name) {
+			for _, other := range names {
+				if !d.IsProxy(other) {
+					deps[name] = append(deps[name], other)
+				}
+			}
+		}
+	}
+
+	placed := map[string]bool{}
+	var tiers [][]string
+	for len(placed) < len(wanted) {
+		var tier []string
+		for name := range wanted {
+			if placed[name] {
+				continue
+			}
+			ready := true
+			for _, dep := range deps[name] {
+				if !placed[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				tier = append(tier, name)
+			}
+		}
+		if len(tier) == 0 {
+			var remaining []string
+			for name := range wanted {
+				if !placed[name] {
+					remaining = append(remaining, name)
+				}
+			}
+			sort.Strings(remaining)
+			return nil, fmt.Errorf("dependency cycle between servers: %s", strings.Join(remaining, ", "))
+		}
+		sort.Strings(tier)
+		for _, name := range tier {
+			placed[name] = true
+		}
+		tiers = append(tiers, tier)
+	}
+	return tiers, nil
+}
+
+// StopTiers is the reverse of StartTiers: proxies stop first, then the
+// servers they depend on.
+func (d Definition) StopTiers(names []string) ([][]string, error) {
+	tiers, err := d.StartTiers(names)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(tiers)-1; i < j; i, j = i+1, j-1 {
+		tiers[i], tiers[j] = tiers[j], tiers[i]
+	}
+	return tiers, nil
+}
+
+// Validate reports dependencies on servers that are not part of the known set.
+func (d Definition) Validate(known []string) []string {
+	exists := map[string]bool{}
+	for _, name := range known {
+		exists[name] = true
+	}
+	var problems []string
+	for name, member := range d.Servers {
+		if !exists[name] {
+			problems = append(problems, fmt.Sprintf("%s is declared but does not exist", name))
+		}
+		if member.Role != "" && !strings.EqualFold(member.Role, RoleProxy) && !strings.EqualFold(member.Role, RoleBackend) {
+			problems = append(problems, fmt.Sprintf("%s has unknown role %q", name, member.Role))
+		}
+		for _, dep := range member.DependsOn {
+			if !exists[dep] {
+				problems = append(problems, fmt.Sprintf("%s depends on unknown server %s", name, dep))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
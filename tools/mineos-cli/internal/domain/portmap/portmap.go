@@ -0,0 +1,150 @@
+package portmap
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	KindServer   = "server"
+	KindServerV6 = "server-v6"
+	KindRcon     = "rcon"
+	KindQuery    = "query"
+
+	DefaultJavaPort    = 25565
+	DefaultBedrockPort = 19132
+)
+
+// Allocation is a port claimed by a server through server.properties.
+type Allocation struct {
+	Server   string
+	Kind     string
+	Port     int
+	Protocol string
+	Property string
+}
+
+func (a Allocation) Key() string {
+	return fmt.Sprintf("%d/%s", a.Port, a.Protocol)
+}
+
+// FromProperties derives the ports a server will bind from its properties.
+// RCON and query ports only count when they are enabled.
+func FromProperties(server, serverType string, props map[string]string) []Allocation {
+	var allocs []Allocation
+	add := func(kind, property, protocol string, fallbackPort int) {
+		port := fallbackPort
+		if value := strings.TrimSpace(props[property]); value != "" {
+			if parsed, err := strconv.Atoi(value); err == nil {
+				port = parsed
+			}
+		}
+		if port > 0 {
+			allocs = append(allocs, Allocation{Server: server, Kind: kind, Port: port, Protocol: protocol, Property: property})
+		}
+	}
+
+	if serverType == "bedrock" {
+		add(KindServer, "server-port", "udp", DefaultBedrockPort)
+		add(KindServerV6, "server-portv6", "udp", DefaultBedrockPort+1)
+		return allocs
+	}
+
+	add(KindServer, "server-port", "tcp", DefaultJavaPort)
+	if isTrue(props["enable-rcon"]) {
+		add(KindRcon, "rcon.port", "tcp", 25575)
+	}
+	if isTrue(props["enable-query"]) {
+		serverPort := DefaultJavaPort
+		if len(allocs) > 0 {
+			serverPort = allocs[0].Port
+		}
+		add(KindQuery, "query.port", "udp", serverPort)
+	}
+	return allocs
+}
+
+// Conflicts groups allocations that share a port and protocol across
+// different servers.
+func Conflicts(allocs []Allocation) map[string][]Allocation {
+	byKey := map[string][]Allocation{}
+	for _, alloc := range allocs {
+		byKey[alloc.Key()] = append(byKey[alloc.Key()], alloc)
+	}
+	conflicts := map[string][]Allocation{}
+	for key, group := range byKey {
+		servers := map[string]bool{}
+		for _, alloc := range group {
+			servers[alloc.Server] = true
+		}
+		if len(servers) > 1 {
+			conflicts[key] = group
+		}
+	}
+	return conflicts
+}
+
+// NextFree returns the first port at or above start that is not used and,
+// when isFree is provided, that isFree accepts.
+func NextFree(start int, used map[int]bool, isFree func(int) bool) int {
+	for port := start; port <= 65535; port++ {
+		if used[port] {
+			continue
+		}
+		if isFree != nil && !isFree(port) {
+			continue
+		}
+		return port
+	}
+	return 0
+}
+
+// Sort orders allocations by port, then server name.
+func Sort(allocs []Allocation) {
+	sort.Slice(allocs, func(i, j int) bool {
+		if allocs[i].Port != allocs[j].Port {
+			return allocs[i].Port < allocs[j].Port
+		}
+		return allocs[i].Server < allocs[j].Server
+	})
+}
+
+// Range is an inclusive port range such as the MC_PORT_RANGE published by
+// docker compose.
+type Range struct {
+	From int
+	To   int
+}
+
+func ParseRange(value string, fallback Range) Range {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	fromText, toText, found := strings.Cut(value, "-")
+	from, err := strconv.Atoi(strings.TrimSpace(fromText))
+	if err != nil {
+		return fallback
+	}
+	to := from
+	if found {
+		if to, err = strconv.Atoi(strings.TrimSpace(toText)); err != nil {
+			return fallback
+		}
+	}
+	return Range{From: from, To: to}
+}
+
+func (r Range) Contains(port int) bool {
+	return port >= r.From && port <= r.To
+}
+
+func (r Range) String() string {
+	return fmt.Sprintf("%d-%d", r.From, r.To)
+}
+
+func isTrue(value string) bool {
+	return strings.EqualFold(strings.TrimSpace(value), "true")
+}
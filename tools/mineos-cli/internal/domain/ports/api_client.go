@@ -1,24 +1,30 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
-type Server struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
-}
+// ErrNotFound is returned when the API reports a missing resource.
+var ErrNotFound = errors.New("not found")
 
-type StopAllResult struct {
-	Total   int            `json:"total"`
-	Running int            `json:"running"`
-	Stopped int            `json:"stopped"`
-	Skipped int            `json:"skipped"`
-	Results []StopAllItem  `json:"results"`
-}
+// ErrConflict is returned when a resource is in a state that rules the
+// request out, e.g. a server that must be stopped first.
+var ErrConflict = errors.New("conflict")
+
+// ErrStopTimeout is returned when a server did not stop within the shutdown
+// timeout.
+var ErrStopTimeout = errors.New("timed out waiting for the server to stop")
 
-type StopAllItem struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
-	Error  string `json:"error"`
+type BulkActionResult struct {
+	Name     string
+	Action   string
+	Duration time.Duration
+	Err      error
+	// Unchanged is set when an ensured action found the server already in
+	// the requested state.
+	Unchanged bool
 }
 
 type ApiClient interface {
@@ -26,4 +32,38 @@ type ApiClient interface {
 	ListServers(ctx context.Context) ([]Server, error)
 	StopAll(ctx context.Context, timeoutSeconds int) (StopAllResult, error)
 	ServerAction(ctx context.Context, name, action string) error
+	ServerActionWithTimeout(ctx context.Context, name, action string, timeoutSeconds int) error
+	GetPerformance(ctx context.Context, name string) (PerformanceSample, error)
+	GetPerformanceHistory(ctx context.Context, name string, minutes int) ([]PerformanceSample, error)
+	GetMemory(ctx context.Context, name string) (MemoryInfo, error)
+	ListWorlds(ctx context.Context, name string) ([]World, error)
+	Ping(ctx context.Context, name string) (PingInfo, error)
+	ReadServerFile(ctx context.Context, name, path string) (string, error)
+	WriteServerFile(ctx context.Context, name, path, content string) error
+	GetServerProperties(ctx context.Context, name string) (map[string]string, error)
+	UpdateServerProperties(ctx context.Context, name string, properties map[string]string) error
+	GetServerConfig(ctx context.Context, name string) (ServerConfig, error)
+	UpdateServerConfig(ctx context.Context, name string, cfg ServerConfig) error
+	GetServerEnvironment(ctx context.Context, name string) (ServerEnvironment, error)
+	UpdateServerEnvironment(ctx context.Context, name string, env ServerEnvironment) error
+	GetServerFileHashes(ctx context.Context, name string) (ServerIntegrity, error)
+	SendConsoleCommand(ctx context.Context, name, command string) error
+	ListPlayers(ctx context.Context, name string) ([]PlayerSummary, error)
+	ListPlayerSessions(ctx context.Context, name, uuid string, limit int) ([]PlayerSession, error)
+	ProcessPlayerActivity(ctx context.Context, name string) error
+	GetServer(ctx context.Context, name string) (ServerDetail, error)
+	CreateServer(ctx context.Context, name, serverType string) error
+	AcceptEula(ctx context.Context, name string) error
+	ImportServer(ctx context.Context, filename, serverName string, allowFlagged bool) (string, error)
+	GetJob(ctx context.Context, id string) (JobStatus, error)
+	ListProfiles(ctx context.Context) ([]Profile, error)
+	DownloadProfile(ctx context.Context, id string) error
+	CopyProfileToServer(ctx context.Context, id, serverName string) error
+	ListCustomProfiles(ctx context.Context) ([]CustomProfile, error)
+	ListPlugins(ctx context.Context, name string) ([]InstalledPlugin, error)
+	ListMods(ctx context.Context, name string) ([]InstalledMod, error)
+	ModrinthVersions(ctx context.Context, name, kind, projectID string) ([]ModrinthVersion, error)
+	InstallModrinth(ctx context.Context, name, kind, versionID string) error
+	DeleteJar(ctx context.Context, name, kind, fileName string) error
+	UpdateSetting(ctx context.Context, key, value string) error
 }
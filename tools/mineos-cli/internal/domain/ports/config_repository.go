@@ -9,5 +9,6 @@ import (
 type ConfigRepository interface {
 	Load(ctx context.Context) (config.Config, error)
 	SetPath(path string)
+	SetOverlays(paths []string)
 	Path() string
 }
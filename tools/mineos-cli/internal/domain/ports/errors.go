@@ -0,0 +1,19 @@
+package ports
+
+// WithKind makes err match kind, e.g. ErrNotFound, with errors.Is while
+// keeping its message, for errors that read better without ": not found".
+func WithKind(err, kind error) error {
+	if err == nil {
+		return nil
+	}
+	return kindError{err: err, kind: kind}
+}
+
+type kindError struct {
+	err  error
+	kind error
+}
+
+func (e kindError) Error() string { return e.err.Error() }
+
+func (e kindError) Unwrap() []error { return []error{e.err, e.kind} }
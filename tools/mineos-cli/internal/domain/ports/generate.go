@@ -0,0 +1,5 @@
+package ports
+
+// The types of the API's requests and responses are generated from the
+// vendored OpenAPI document; see "API Types" in the README.
+//go:generate go run ../../openapigen -spec ../../../api/openapi.json -out types_gen.go
@@ -0,0 +1,42 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// ModrinthFile is a file Modrinth publishes, found by its hash.
+type ModrinthFile struct {
+	ProjectID string
+	Project   string
+	Version   string
+	FileName  string
+}
+
+// UpstreamHashes looks up the hashes vendors publish for their files.
+type UpstreamHashes interface {
+	// MojangServerSha1 returns the SHA-1 of the vanilla server jar of a
+	// Minecraft version.
+	MojangServerSha1(ctx context.Context, version string) (string, error)
+	// PaperBuilds maps the SHA-256 of each Paper build of a version to its
+	// build number.
+	PaperBuilds(ctx context.Context, version string) (map[string]int, error)
+	// ModrinthFiles returns the files Modrinth knows, keyed by SHA-512.
+	ModrinthFiles(ctx context.Context, sha512s []string) (map[string]ModrinthFile, error)
+}
+
+// PaperBuild is one build PaperMC published for a Minecraft version.
+type PaperBuild struct {
+	Build   int
+	Time    time.Time
+	Channel string
+	Sha256  string
+	// Changes are the commit summaries that went into the build.
+	Changes []string
+}
+
+// PaperReleases lists the builds PaperMC published.
+type PaperReleases interface {
+	// PaperBuildHistory returns the builds of a version, oldest first.
+	PaperBuildHistory(ctx context.Context, version string) ([]PaperBuild, error)
+}
@@ -0,0 +1,8 @@
+package ports
+
+type ServerStats struct {
+	Performance PerformanceSample
+	Memory      MemoryInfo
+	Worlds      []World
+	TpsHistory  []PerformanceSample
+}
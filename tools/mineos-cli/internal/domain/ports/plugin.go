@@ -0,0 +1,5 @@
+package ports
+
+// InstalledMod is a jar in a server's mods directory; the API lists mods in
+// the same shape as plugins.
+type InstalledMod = InstalledPlugin
@@ -0,0 +1,12 @@
+package ports
+
+// CustomProfileUpload is the metadata sent with a custom jar. JavaVersion
+// zero lets the API pick Java from Version.
+type CustomProfileUpload struct {
+	Name        string
+	Software    string
+	Version     string
+	JavaVersion int
+	Description string
+	Replace     bool
+}
@@ -0,0 +1,13 @@
+package ports
+
+func (s ServerDetail) IsRunning() bool {
+	return s.Status == "running"
+}
+
+func (s ServerDetail) IsBedrock() bool {
+	return s.ServerType == "bedrock"
+}
+
+func (j JobStatus) IsDone() bool {
+	return j.Status == "completed" || j.Status == "failed"
+}
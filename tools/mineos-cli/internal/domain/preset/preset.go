@@ -0,0 +1,222 @@
+// Package preset defines bundles of gamerules, difficulty and world border
+// settings that are applied to a running server through console commands.
+package preset
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Preset is one bundle. Every field is optional; only what is set is
+// applied.
+type Preset struct {
+	Description string            `yaml:"description"`
+	Difficulty  string            `yaml:"difficulty"`
+	Gamerules   map[string]string `yaml:"gamerules"`
+	WorldBorder *WorldBorder      `yaml:"world_border"`
+	// Commands run last, e.g. "defaultgamemode creative" or "time set day".
+	Commands []string `yaml:"commands"`
+}
+
+// WorldBorder centres the border and sets its diameter in blocks. Seconds
+// grows or shrinks it to Size over that long instead of at once.
+type WorldBorder struct {
+	CenterX float64 `yaml:"center_x"`
+	CenterZ float64 `yaml:"center_z"`
+	Size    float64 `yaml:"size"`
+	Seconds int     `yaml:"seconds"`
+	// Damage is the damage per block per second beyond the border's buffer.
+	Damage float64 `yaml:"damage"`
+	// Warning is the distance in blocks at which players see the border.
+	Warning int `yaml:"warning"`
+}
+
+// Definition is the presets file: user presets by name. A user preset with
+// a built-in's name replaces it.
+type Definition struct {
+	Presets map[string]Preset `yaml:"presets"`
+}
+
+var difficulties = []string{"peaceful", "easy", "normal", "hard"}
+
+var (
+	namePattern     = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	gamerulePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+)
+
+// Builtin are the presets shipped with the CLI.
+//
+// hardcore only comes close: true hardcore (one life, spectator on death) is
+// hardcore=true in server.properties when the world is created.
+var Builtin = map[string]Preset{
+	"hardcore": {
+		Description: "Hard difficulty, no kept inventory, no shortcuts",
+		Difficulty:  "hard",
+		Gamerules: map[string]string{
+			"keepInventory":       "false",
+			"naturalRegeneration": "true",
+			"doImmediateRespawn":  "false",
+			"doInsomnia":          "true",
+			"mobGriefing":         "true",
+			"showDeathMessages":   "true",
+		},
+	},
+	"peaceful-creative": {
+		Description: "Peaceful building: no mobs, weather, fire spread or night",
+		Difficulty:  "peaceful",
+		Gamerules: map[string]string{
+			"doMobSpawning":   "false",
+			"doWeatherCycle":  "false",
+			"doDaylightCycle": "false",
+			"doFireTick":      "false",
+			"mobGriefing":     "false",
+			"keepInventory":   "true",
+		},
+		Commands: []string{"defaultgamemode creative", "time set day", "weather clear"},
+	},
+	"anarchy": {
+		Description: "Hard difficulty with every destructive rule on",
+		Difficulty:  "hard",
+		Gamerules: map[string]string{
+			"keepInventory":        "false",
+			"mobGriefing":          "true",
+			"doFireTick":           "true",
+			"tntExplodes":          "true",
+			"announceAdvancements": "true",
+			"showDeathMessages":    "true",
+		},
+	},
+	"uhc": {
+		Description: "Ultra Hardcore: no natural regeneration, 2000-block border",
+		Difficulty:  "hard",
+		Gamerules: map[string]string{
+			"naturalRegeneration":  "false",
+			"keepInventory":        "false",
+			"doImmediateRespawn":   "true",
+			"announceAdvancements": "false",
+		},
+		WorldBorder: &WorldBorder{Size: 2000, Damage: 1, Warning: 20},
+		Commands:    []string{"time set day", "weather clear"},
+	},
+}
+
+// Names returns the preset names, sorted.
+func Names(presets map[string]Preset) []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Merge returns the built-in presets overlaid with the user's.
+func (d Definition) Merge() map[string]Preset {
+	merged := make(map[string]Preset, len(Builtin)+len(d.Presets))
+	for name, p := range Builtin {
+		merged[name] = p
+	}
+	for name, p := range d.Presets {
+		merged[name] = p
+	}
+	return merged
+}
+
+func (d Definition) Validate() error {
+	for _, name := range Names(d.Presets) {
+		if !namePattern.MatchString(name) {
+			return fmt.Errorf("presets.%s: names are lowercase letters, digits, '-' and '_'", name)
+		}
+		if err := d.Presets[name].Validate(); err != nil {
+			return fmt.Errorf("presets.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (p Preset) Validate() error {
+	if p.Difficulty != "" && !contains(difficulties, p.Difficulty) {
+		return fmt.Errorf("difficulty must be one of %s", strings.Join(difficulties, ", "))
+	}
+	for _, rule := range sortedKeys(p.Gamerules) {
+		value := p.Gamerules[rule]
+		if !gamerulePattern.MatchString(rule) {
+			return fmt.Errorf("gamerule %q is not a gamerule name", rule)
+		}
+		if strings.TrimSpace(value) == "" || strings.ContainsAny(value, " \t\n") {
+			return fmt.Errorf("gamerule %s needs a single value such as true, false or a number", rule)
+		}
+	}
+	if b := p.WorldBorder; b != nil {
+		switch {
+		case b.Size < 1 || b.Size > 59999968:
+			return fmt.Errorf("world_border.size must be between 1 and 59999968 blocks")
+		case b.Seconds < 0 || b.Damage < 0 || b.Warning < 0:
+			return fmt.Errorf("world_border values must not be negative")
+		}
+	}
+	for i, command := range p.Commands {
+		if strings.TrimSpace(command) == "" || strings.Contains(command, "\n") {
+			return fmt.Errorf("commands[%d] must be a single console command", i)
+		}
+	}
+	if p.Difficulty == "" && len(p.Gamerules) == 0 && p.WorldBorder == nil && len(p.Commands) == 0 {
+		return fmt.Errorf("sets nothing")
+	}
+	return nil
+}
+
+// ConsoleCommands returns the commands that apply the preset, in order:
+// difficulty, gamerules sorted by name, world border, then Commands.
+func (p Preset) ConsoleCommands() []string {
+	var commands []string
+	if p.Difficulty != "" {
+		commands = append(commands, "difficulty "+p.Difficulty)
+	}
+	for _, rule := range sortedKeys(p.Gamerules) {
+		commands = append(commands, "gamerule "+rule+" "+p.Gamerules[rule])
+	}
+	if b := p.WorldBorder; b != nil {
+		commands = append(commands, "worldborder center "+number(b.CenterX)+" "+number(b.CenterZ))
+		set := "worldborder set " + number(b.Size)
+		if b.Seconds > 0 {
+			set += " " + strconv.Itoa(b.Seconds)
+		}
+		commands = append(commands, set)
+		if b.Damage > 0 {
+			commands = append(commands, "worldborder damage amount "+number(b.Damage))
+		}
+		if b.Warning > 0 {
+			commands = append(commands, "worldborder warning distance "+strconv.Itoa(b.Warning))
+		}
+	}
+	for _, command := range p.Commands {
+		commands = append(commands, strings.TrimPrefix(strings.TrimSpace(command), "/"))
+	}
+	return commands
+}
+
+func number(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package proxy
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PaperGlobalConfigFile = "config/paper-global.yml"
+	LegacyPaperConfigFile = "paper.yml"
+	SpigotConfigFile      = "spigot.yml"
+)
+
+// BackendForwarding captures the proxy forwarding settings of a backend.
+type BackendForwarding struct {
+	VelocityEnabled bool
+	VelocitySecret  string
+	BungeeCord      bool
+}
+
+// ParsePaperGlobal reads proxies.velocity from config/paper-global.yml.
+func ParsePaperGlobal(content string, into *BackendForwarding) error {
+	var cfg struct {
+		Proxies struct {
+			Velocity struct {
+				Enabled bool   `yaml:"enabled"`
+				Secret  string `yaml:"secret"`
+			} `yaml:"velocity"`
+		} `yaml:"proxies"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		return err
+	}
+	into.VelocityEnabled = cfg.Proxies.Velocity.Enabled
+	into.VelocitySecret = strings.TrimSpace(cfg.Proxies.Velocity.Secret)
+	return nil
+}
+
+// ParseLegacyPaper reads settings.velocity-support from pre-1.19 paper.yml.
+func ParseLegacyPaper(content string, into *BackendForwarding) error {
+	var cfg struct {
+		Settings struct {
+			VelocitySupport struct {
+				Enabled bool   `yaml:"enabled"`
+				Secret  string `yaml:"secret"`
+			} `yaml:"velocity-support"`
+		} `yaml:"settings"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		return err
+	}
+	into.VelocityEnabled = cfg.Settings.VelocitySupport.Enabled
+	into.VelocitySecret = strings.TrimSpace(cfg.Settings.VelocitySupport.Secret)
+	return nil
+}
+
+// ParseSpigot reads settings.bungeecord from spigot.yml.
+func ParseSpigot(content string, into *BackendForwarding) error {
+	var cfg struct {
+		Settings struct {
+			BungeeCord bool `yaml:"bungeecord"`
+		} `yaml:"settings"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		return err
+	}
+	into.BungeeCord = cfg.Settings.BungeeCord
+	return nil
+}
+
+// CheckVelocityBackend compares a backend against the proxy forwarding mode
+// and secret. An empty result means the backend is configured correctly.
+func CheckVelocityBackend(mode, secret string, backend BackendForwarding) string {
+	switch mode {
+	case "modern":
+		if !backend.VelocityEnabled {
+			return "velocity forwarding is disabled in paper config"
+		}
+		if backend.VelocitySecret == "" {
+			return "velocity secret is empty"
+		}
+		if backend.VelocitySecret != strings.TrimSpace(secret) {
+			return "velocity secret does not match the proxy"
+		}
+	case "legacy", "bungeeguard":
+		if !backend.BungeeCord {
+			return "bungeecord is disabled in spigot.yml"
+		}
+	case "none", "":
+		return ""
+	}
+	return ""
+}
+
+// CheckBungeeBackend verifies a backend accepts BungeeCord forwarding.
+func CheckBungeeBackend(ipForward bool, backend BackendForwarding) string {
+	if ipForward && !backend.BungeeCord {
+		return "bungeecord is disabled in spigot.yml"
+	}
+	if !ipForward && backend.BungeeCord {
+		return "proxy ip_forward is disabled but backend expects bungeecord forwarding"
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+package proxy
+
+import (
+	"bytes"
+	"errors"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BungeeServers returns the backends registered under servers in config.yml.
+func BungeeServers(content string) (map[string]string, error) {
+	var cfg struct {
+		Servers map[string]struct {
+			Address string `yaml:"address"`
+		} `yaml:"servers"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		return nil, err
+	}
+	servers := map[string]string{}
+	for name, server := range cfg.Servers {
+		servers[name] = server.Address
+	}
+	return servers, nil
+}
+
+// BungeeIPForward reports whether ip_forward is enabled.
+func BungeeIPForward(content string) bool {
+	var cfg struct {
+		IPForward bool `yaml:"ip_forward"`
+	}
+	_ = yaml.Unmarshal([]byte(content), &cfg)
+	return cfg.IPForward
+}
+
+// SetBungeeServer adds or updates a backend. When addToPriorities is set the
+// backend is appended to the first listener's priorities.
+func SetBungeeServer(content, name, address string, addToPriorities bool) (string, error) {
+	root, err := parseYamlDocument(content)
+	if err != nil {
+		return "", err
+	}
+
+	servers := mappingChild(root, "servers", true)
+	server := mappingChild(servers, name, true)
+	setScalar(server, "address", address)
+	if mappingChild(server, "motd", false) == nil {
+		setScalar(server, "motd", name)
+	}
+	if mappingChild(server, "restricted", false) == nil {
+		setScalarTagged(server, "restricted", "false", "!!bool")
+	}
+
+	if addToPriorities {
+		if priorities := firstListenerPriorities(root); priorities != nil {
+			found := false
+			for _, item := range priorities.Content {
+				if item.Value == name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				priorities.Content = append(priorities.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name})
+			}
+		}
+	}
+
+	return encodeYamlDocument(root)
+}
+
+// RemoveBungeeServer removes a backend and its listener priority entries.
+func RemoveBungeeServer(content, name string) (string, bool, error) {
+	root, err := parseYamlDocument(content)
+	if err != nil {
+		return "", false, err
+	}
+	removed := false
+	if servers := mappingChild(root, "servers", false); servers != nil {
+		removed = deleteMappingKey(servers, name)
+	}
+	if priorities := firstListenerPriorities(root); priorities != nil {
+		kept := priorities.Content[:0]
+		for _, item := range priorities.Content {
+			if item.Value == name {
+				removed = true
+				continue
+			}
+			kept = append(kept, item)
+		}
+		priorities.Content = kept
+	}
+	updated, err := encodeYamlDocument(root)
+	return updated, removed, err
+}
+
+func parseYamlDocument(content string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("expected a YAML mapping at the document root")
+	}
+	return doc.Content[0], nil
+}
+
+func encodeYamlDocument(root *yaml.Node) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func mappingChild(node *yaml.Node, key string, create bool) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	if !create {
+		return nil
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+	return child
+}
+
+func setScalar(node *yaml.Node, key, value string) {
+	setScalarTagged(node, key, value, "!!str")
+}
+
+func setScalarTagged(node *yaml.Node, key, value, tag string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+			return
+		}
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value})
+}
+
+func deleteMappingKey(node *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func firstListenerPriorities(root *yaml.Node) *yaml.Node {
+	listeners := mappingChild(root, "listeners", false)
+	if listeners == nil || listeners.Kind != yaml.SequenceNode || len(listeners.Content) == 0 {
+		return nil
+	}
+	priorities := mappingChild(listeners.Content[0], "priorities", false)
+	if priorities == nil || priorities.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return priorities
+}
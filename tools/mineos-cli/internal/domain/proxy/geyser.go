@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Platforms Geyser and Floodgate are installed on.
+const (
+	PlatformSpigot   = "spigot"
+	PlatformVelocity = KindVelocity
+	PlatformBungee   = KindBungee
+)
+
+const (
+	DefaultBedrockPort  = 19132
+	FloodgateKeyFile    = "plugins/floodgate/key.pem"
+	FloodgateConfigFile = "plugins/floodgate/config.yml"
+	AuthTypeFloodgate   = "floodgate"
+)
+
+// GeyserConfigFile is where Geyser writes its config on first start.
+func GeyserConfigFile(platform string) string {
+	switch platform {
+	case PlatformVelocity:
+		return "plugins/Geyser-Velocity/config.yml"
+	case PlatformBungee:
+		return "plugins/Geyser-BungeeCord/config.yml"
+	default:
+		return "plugins/Geyser-Spigot/config.yml"
+	}
+}
+
+// FloodgateDownload names Floodgate's download for a platform. Geyser uses
+// the platform name itself; Floodgate spells BungeeCord "bungee".
+func FloodgateDownload(platform string) string {
+	if platform == PlatformBungee {
+		return "bungee"
+	}
+	return platform
+}
+
+type GeyserSettings struct {
+	BedrockPort     int
+	CloneRemotePort bool
+	AuthType        string
+}
+
+// ReadGeyserConfig extracts the settings the CLI manages from config.yml.
+func ReadGeyserConfig(content string) (GeyserSettings, error) {
+	var cfg struct {
+		Bedrock struct {
+			Port            int  `yaml:"port"`
+			CloneRemotePort bool `yaml:"clone-remote-port"`
+		} `yaml:"bedrock"`
+		Remote struct {
+			AuthType string `yaml:"auth-type"`
+		} `yaml:"remote"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		return GeyserSettings{}, err
+	}
+	settings := GeyserSettings{
+		BedrockPort:     cfg.Bedrock.Port,
+		CloneRemotePort: cfg.Bedrock.CloneRemotePort,
+		AuthType:        cfg.Remote.AuthType,
+	}
+	if settings.BedrockPort == 0 {
+		settings.BedrockPort = DefaultBedrockPort
+	}
+	return settings, nil
+}
+
+// ConfigureGeyser sets the Bedrock listen port and, when floodgate is set,
+// switches authentication to Floodgate so Bedrock players need no Java
+// account.
+func ConfigureGeyser(content string, bedrockPort int, floodgate bool) (string, error) {
+	root, err := parseYamlDocument(content)
+	if err != nil {
+		return "", err
+	}
+	bedrock := mappingChild(root, "bedrock", true)
+	setScalarTagged(bedrock, "port", strconv.Itoa(bedrockPort), "!!int")
+	setScalarTagged(bedrock, "clone-remote-port", "false", "!!bool")
+	if floodgate {
+		setScalar(mappingChild(root, "remote", true), "auth-type", AuthTypeFloodgate)
+	}
+	return encodeYamlDocument(root)
+}
+
+// SetFloodgateSendData toggles send-floodgate-data on a proxy's Floodgate,
+// which must be on when the backends run Floodgate too.
+func SetFloodgateSendData(content string, enabled bool) (string, error) {
+	root, err := parseYamlDocument(content)
+	if err != nil {
+		return "", err
+	}
+	setScalarTagged(root, "send-floodgate-data", strconv.FormatBool(enabled), "!!bool")
+	return encodeYamlDocument(root)
+}
@@ -0,0 +1,269 @@
+package proxy
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	VelocityConfigFile = "velocity.toml"
+	BungeeConfigFile   = "config.yml"
+
+	KindVelocity = "velocity"
+	KindBungee   = "bungeecord"
+)
+
+var (
+	tomlSectionRe = regexp.MustCompile(`^\s*\[([A-Za-z0-9_.-]+)\]\s*(#.*)?$`)
+	tomlEntryRe   = regexp.MustCompile(`^\s*"?([A-Za-z0-9_.-]+)"?\s*=\s*"([^"]*)"`)
+	tomlQuotedRe  = regexp.MustCompile(`"([^"]*)"`)
+)
+
+// VelocityServers returns the backends registered in the [servers] table.
+func VelocityServers(content string) map[string]string {
+	lines := strings.Split(content, "\n")
+	start, end := velocitySection(lines, "servers")
+	servers := map[string]string{}
+	if start < 0 {
+		return servers
+	}
+	for _, line := range lines[start+1 : end] {
+		match := tomlEntryRe.FindStringSubmatch(line)
+		if match == nil || match[1] == "try" {
+			continue
+		}
+		servers[match[1]] = match[2]
+	}
+	return servers
+}
+
+// VelocityTry returns the fallback order from servers.try.
+func VelocityTry(content string) []string {
+	lines := strings.Split(content, "\n")
+	start, end := velocitySection(lines, "servers")
+	if start < 0 {
+		return nil
+	}
+	from, to := velocityTryRange(lines, start, end)
+	if from < 0 {
+		return nil
+	}
+	var order []string
+	for _, match := range tomlQuotedRe.FindAllStringSubmatch(strings.Join(lines[from:to+1], "\n"), -1) {
+		order = append(order, match[1])
+	}
+	return order
+}
+
+// SetVelocityServer adds or updates a backend entry. When addToTry is set the
+// backend is appended to the try list if missing.
+func SetVelocityServer(content, name, address string, addToTry bool) string {
+	lines := strings.Split(content, "\n")
+	start, end := velocitySection(lines, "servers")
+	entry := fmt.Sprintf("%s = %q", tomlKey(name), address)
+
+	if start < 0 {
+		lines = append(trimTrailingBlank(lines), "", "[servers]", entry)
+		if addToTry {
+			lines = append(lines, renderVelocityTry([]string{name})...)
+		}
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	replaced := false
+	for i := start + 1; i < end; i++ {
+		match := tomlEntryRe.FindStringSubmatch(lines[i])
+		if match != nil && match[1] == name {
+			lines[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		insertAt := end
+		if from, _ := velocityTryRange(lines, start, end); from >= 0 {
+			insertAt = from
+		}
+		for insertAt > start+1 && strings.TrimSpace(lines[insertAt-1]) == "" {
+			insertAt--
+		}
+		lines = insertLines(lines, insertAt, entry)
+	}
+
+	if addToTry {
+		order := VelocityTry(strings.Join(lines, "\n"))
+		for _, existing := range order {
+			if existing == name {
+				return strings.Join(lines, "\n")
+			}
+		}
+		lines = replaceVelocityTry(lines, append(order, name))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RemoveVelocityServer drops a backend entry and removes it from the try list.
+func RemoveVelocityServer(content, name string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	start, end := velocitySection(lines, "servers")
+	if start < 0 {
+		return content, false
+	}
+	removed := false
+	for i := start + 1; i < end; i++ {
+		match := tomlEntryRe.FindStringSubmatch(lines[i])
+		if match != nil && match[1] == name {
+			lines = append(lines[:i], lines[i+1:]...)
+			removed = true
+			break
+		}
+	}
+
+	order := VelocityTry(strings.Join(lines, "\n"))
+	filtered := make([]string, 0, len(order))
+	for _, existing := range order {
+		if existing != name {
+			filtered = append(filtered, existing)
+		}
+	}
+	if len(filtered) != len(order) {
+		lines = replaceVelocityTry(lines, filtered)
+		removed = true
+	}
+	return strings.Join(lines, "\n"), removed
+}
+
+// VelocityForwarding returns the forwarding mode and secret file name.
+func VelocityForwarding(content string) (mode, secretFile string) {
+	secretFile = "forwarding.secret"
+	for _, line := range strings.Split(content, "\n") {
+		if tomlSectionRe.MatchString(line) {
+			break
+		}
+		match := tomlEntryRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		switch match[1] {
+		case "player-info-forwarding-mode":
+			mode = strings.ToLower(match[2])
+		case "forwarding-secret-file":
+			secretFile = match[2]
+		}
+	}
+	return mode, secretFile
+}
+
+func velocitySection(lines []string, name string) (int, int) {
+	start := -1
+	for i, line := range lines {
+		match := tomlSectionRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if start >= 0 {
+			return start, i
+		}
+		if match[1] == name {
+			start = i
+		}
+	}
+	if start < 0 {
+		return -1, -1
+	}
+	return start, len(lines)
+}
+
+func velocityTryRange(lines []string, start, end int) (int, int) {
+	for i := start + 1; i < end; i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(trimmed, "try") || !strings.Contains(trimmed, "=") {
+			continue
+		}
+		for j := i; j < end; j++ {
+			if strings.Contains(stripTomlComment(lines[j]), "]") {
+				return i, j
+			}
+		}
+		return i, i
+	}
+	return -1, -1
+}
+
+func replaceVelocityTry(lines []string, order []string) []string {
+	start, end := velocitySection(lines, "servers")
+	from, to := velocityTryRange(lines, start, end)
+	rendered := renderVelocityTry(order)
+	if from < 0 {
+		insertAt := end
+		for insertAt > start+1 && strings.TrimSpace(lines[insertAt-1]) == "" {
+			insertAt--
+		}
+		return insertLines(lines, insertAt, rendered...)
+	}
+	result := append([]string{}, lines[:from]...)
+	result = append(result, rendered...)
+	return append(result, lines[to+1:]...)
+}
+
+func renderVelocityTry(order []string) []string {
+	if len(order) == 0 {
+		return []string{"try = []"}
+	}
+	rendered := []string{"try = ["}
+	for i, name := range order {
+		line := fmt.Sprintf("  %q", name)
+		if i < len(order)-1 {
+			line += ","
+		}
+		rendered = append(rendered, line)
+	}
+	return append(rendered, "]")
+}
+
+func tomlKey(name string) string {
+	if regexp.MustCompile(`^[A-Za-z0-9_-]+$`).MatchString(name) {
+		return name
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+func stripTomlComment(line string) string {
+	inQuote := false
+	for i, r := range line {
+		switch r {
+		case '"':
+			inQuote = !inQuote
+		case '#':
+			if !inQuote {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+func insertLines(lines []string, at int, values ...string) []string {
+	result := append([]string{}, lines[:at]...)
+	result = append(result, values...)
+	return append(result, lines[at:]...)
+}
+
+func trimTrailingBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// SortedNames returns the keys of a server map in alphabetical order.
+func SortedNames(servers map[string]string) []string {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
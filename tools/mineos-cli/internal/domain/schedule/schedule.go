@@ -0,0 +1,325 @@
+package schedule
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Definition lists the policies the agent runs on a schedule.
+type Definition struct {
+	Restarts      []RestartPolicy `yaml:"restarts"`
+	Announcements []Announcement  `yaml:"announcements"`
+	Presets       []PresetPolicy  `yaml:"presets"`
+	Verify        []VerifyPolicy  `yaml:"verify"`
+}
+
+// RestartPolicy restarts a server when Cron matches, deferring while players
+// are online for up to MaxDelay.
+type RestartPolicy struct {
+	Server string `yaml:"server"`
+	// Cron is a 5-field expression (minute hour day-of-month month
+	// day-of-week) evaluated in the host's local time.
+	Cron     string          `yaml:"cron"`
+	MaxDelay time.Duration   `yaml:"max_delay"`
+	Recheck  time.Duration   `yaml:"recheck"`
+	Warnings []time.Duration `yaml:"warnings"`
+	Message  string          `yaml:"message"`
+}
+
+// Announcement broadcasts one of its messages to the matching servers every
+// Every or whenever Cron matches, taking the messages in turn.
+type Announcement struct {
+	Name string `yaml:"name"`
+	// Servers are names or glob patterns; empty means every server.
+	Servers []string `yaml:"servers"`
+	// Every is aligned to the clock: 15m announces at :00, :15, :30 and :45.
+	Every time.Duration `yaml:"every"`
+	Cron  string        `yaml:"cron"`
+	// Messages may use MiniMessage tags or '&' color codes.
+	Messages []string `yaml:"messages"`
+	// Random picks the next message at random instead of in order.
+	Random bool `yaml:"random"`
+	// Mode is "tellraw" (the default), which keeps formatting, or "say".
+	Mode string `yaml:"mode"`
+}
+
+// PresetPolicy applies a gamerule preset (see mineos-presets.yaml) to a
+// server when Cron matches, e.g. to switch an event server to UHC rules for
+// the weekend.
+type PresetPolicy struct {
+	Server string `yaml:"server"`
+	Preset string `yaml:"preset"`
+	Cron   string `yaml:"cron"`
+}
+
+// VerifyPolicy checks the latest host-side backup of each matching server
+// when Cron matches, alerting when one fails.
+type VerifyPolicy struct {
+	// Servers are names or glob patterns; empty means every server with
+	// backups.
+	Servers []string `yaml:"servers"`
+	Cron    string   `yaml:"cron"`
+	// Extract also restores each backup into a scratch directory.
+	Extract bool `yaml:"extract"`
+}
+
+// MatchesServer reports whether the policy verifies the server's backups.
+func (v VerifyPolicy) MatchesServer(name string) bool {
+	return matchesAny(v.Servers, name)
+}
+
+const (
+	AnnounceTellraw = "tellraw"
+	AnnounceSay     = "say"
+)
+
+// Label is the announcement's name, or its first message when it has none.
+func (a Announcement) Label() string {
+	if name := strings.TrimSpace(a.Name); name != "" {
+		return name
+	}
+	if len(a.Messages) > 0 {
+		return a.Messages[0]
+	}
+	return ""
+}
+
+// MatchesServer reports whether the announcement goes to the server.
+func (a Announcement) MatchesServer(name string) bool {
+	return matchesAny(a.Servers, name)
+}
+
+// matchesAny reports whether name matches one of the patterns, or there are
+// none.
+func matchesAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok || pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDue reports whether the announcement should be sent in the minute t.
+func (a Announcement) IsDue(t time.Time) bool {
+	if a.Cron != "" {
+		return Matches(a.Cron, t)
+	}
+	minutes := int64(a.Every / time.Minute)
+	if minutes <= 0 {
+		return false
+	}
+	// Count minutes in local time so hourly intervals land on the hour in
+	// zones with a non-whole-hour offset too.
+	_, offset := t.Zone()
+	return (t.Unix()+int64(offset))/60%minutes == 0
+}
+
+func (d Definition) Validate() error {
+	for i, policy := range d.Restarts {
+		if strings.TrimSpace(policy.Server) == "" {
+			return fmt.Errorf("restarts[%d]: server is required", i)
+		}
+		if err := ValidateCron(policy.Cron); err != nil {
+			return fmt.Errorf("restarts[%d] (%s): %w", i, policy.Server, err)
+		}
+		if policy.MaxDelay < 0 || policy.Recheck < 0 {
+			return fmt.Errorf("restarts[%d] (%s): durations must not be negative", i, policy.Server)
+		}
+	}
+	for i, policy := range d.Presets {
+		switch {
+		case strings.TrimSpace(policy.Server) == "":
+			return fmt.Errorf("presets[%d]: server is required", i)
+		case strings.TrimSpace(policy.Preset) == "":
+			return fmt.Errorf("presets[%d] (%s): preset is required", i, policy.Server)
+		}
+		if err := ValidateCron(policy.Cron); err != nil {
+			return fmt.Errorf("presets[%d] (%s): %w", i, policy.Server, err)
+		}
+	}
+	for i, policy := range d.Verify {
+		if err := ValidateCron(policy.Cron); err != nil {
+			return fmt.Errorf("verify[%d]: %w", i, err)
+		}
+		for _, pattern := range policy.Servers {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("verify[%d]: server pattern %q: %w", i, pattern, err)
+			}
+		}
+	}
+	return d.validateAnnouncements()
+}
+
+func (d Definition) validateAnnouncements() error {
+	for i, announcement := range d.Announcements {
+		label := announcement.Label()
+		switch {
+		case len(announcement.Messages) == 0:
+			return fmt.Errorf("announcements[%d]: messages are required", i)
+		case (announcement.Every > 0) == (announcement.Cron != ""):
+			return fmt.Errorf("announcements[%d] (%s): set either every or cron", i, label)
+		case announcement.Every < 0 || announcement.Every%time.Minute != 0:
+			return fmt.Errorf("announcements[%d] (%s): every must be a whole number of minutes", i, label)
+		}
+		if announcement.Cron != "" {
+			if err := ValidateCron(announcement.Cron); err != nil {
+				return fmt.Errorf("announcements[%d] (%s): %w", i, label, err)
+			}
+		}
+		switch announcement.Mode {
+		case "", AnnounceTellraw, AnnounceSay:
+		default:
+			return fmt.Errorf("announcements[%d] (%s): mode must be tellraw or say", i, label)
+		}
+		for j, message := range announcement.Messages {
+			if strings.TrimSpace(message) == "" {
+				return fmt.Errorf("announcements[%d] (%s): messages[%d] is empty", i, label, j)
+			}
+		}
+	}
+	return nil
+}
+
+// Due returns the restart policies whose cron expression matches t.
+func (d Definition) Due(t time.Time) []RestartPolicy {
+	var due []RestartPolicy
+	for _, policy := range d.Restarts {
+		if Matches(policy.Cron, t) {
+			due = append(due, policy)
+		}
+	}
+	return due
+}
+
+// DuePresets returns the preset policies whose cron expression matches t.
+func (d Definition) DuePresets(t time.Time) []PresetPolicy {
+	var due []PresetPolicy
+	for _, policy := range d.Presets {
+		if Matches(policy.Cron, t) {
+			due = append(due, policy)
+		}
+	}
+	return due
+}
+
+// DueVerify returns the verify policies whose cron expression matches t.
+func (d Definition) DueVerify(t time.Time) []VerifyPolicy {
+	var due []VerifyPolicy
+	for _, policy := range d.Verify {
+		if Matches(policy.Cron, t) {
+			due = append(due, policy)
+		}
+	}
+	return due
+}
+
+// DueAnnouncements returns the announcements to send in the minute t.
+func (d Definition) DueAnnouncements(t time.Time) []Announcement {
+	var due []Announcement
+	for _, announcement := range d.Announcements {
+		if announcement.IsDue(t) {
+			due = append(due, announcement)
+		}
+	}
+	return due
+}
+
+type field struct {
+	min, max int
+}
+
+var fields = []field{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+
+// Matches reports whether a 5-field cron expression matches t. It supports
+// the same syntax as the API's cron scheduler: *, values, ranges (1-5),
+// lists (1,3,5) and steps (*/5, 1-10/2).
+func Matches(expression string, t time.Time) bool {
+	parts := strings.Fields(expression)
+	if len(parts) != len(fields) {
+		return false
+	}
+	values := []int{t.Minute(), t.Hour(), t.Day(), int(t.Month()), int(t.Weekday())}
+	for i, part := range parts {
+		ok, err := fieldMatches(part, values[i], fields[i])
+		if err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func ValidateCron(expression string) error {
+	parts := strings.Fields(expression)
+	if len(parts) != len(fields) {
+		return fmt.Errorf("cron %q must have 5 fields (minute hour day month weekday)", expression)
+	}
+	for i, part := range parts {
+		if _, err := fieldMatches(part, fields[i].min, fields[i]); err != nil {
+			return fmt.Errorf("cron %q: %w", expression, err)
+		}
+	}
+	return nil
+}
+
+func fieldMatches(expr string, value int, f field) (bool, error) {
+	matched := false
+	for _, part := range strings.Split(expr, ",") {
+		ok, err := partMatches(strings.TrimSpace(part), value, f)
+		if err != nil {
+			return false, err
+		}
+		matched = matched || ok
+	}
+	return matched, nil
+}
+
+func partMatches(part string, value int, f field) (bool, error) {
+	rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+	step := 1
+	if hasStep {
+		parsed, err := strconv.Atoi(stepExpr)
+		if err != nil || parsed < 1 {
+			return false, fmt.Errorf("invalid step %q", part)
+		}
+		step = parsed
+	}
+
+	from, to := f.min, f.max
+	if rangeExpr != "*" {
+		low, high, isRange := strings.Cut(rangeExpr, "-")
+		var err error
+		if from, err = parseValue(low, f); err != nil {
+			return false, err
+		}
+		to = from
+		if isRange {
+			if to, err = parseValue(high, f); err != nil {
+				return false, err
+			}
+			if to < from {
+				return false, fmt.Errorf("invalid range %q", rangeExpr)
+			}
+		} else if hasStep {
+			to = f.max
+		}
+	}
+	if value < from || value > to {
+		return false, nil
+	}
+	return (value-from)%step == 0, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < f.min || parsed > f.max {
+		return 0, fmt.Errorf("value %q out of range %d-%d", value, f.min, f.max)
+	}
+	return parsed, nil
+}
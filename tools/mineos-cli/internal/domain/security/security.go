@@ -0,0 +1,242 @@
+// Package security checks Minecraft server versions against known CVEs and
+// the mitigations Mojang and PaperMC published for them.
+package security
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Status string
+
+const (
+	// Vulnerable servers run an affected version without its mitigation.
+	Vulnerable Status = "vulnerable"
+	// Mitigated servers run an affected version with the mitigation in place.
+	Mitigated Status = "mitigated"
+	// Outdated servers are not known to be vulnerable but miss newer builds
+	// that mention security fixes.
+	Outdated Status = "outdated"
+	OK       Status = "ok"
+	// Unknown servers could not be checked, e.g. the version is not known.
+	Unknown Status = "unknown"
+)
+
+// Finding is the outcome of one check on one server.
+type Finding struct {
+	Check  string `json:"check"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+	// Fix says how to resolve a vulnerable or outdated finding.
+	Fix string `json:"fix,omitempty"`
+}
+
+type Report struct {
+	Server   string    `json:"server"`
+	Software string    `json:"software,omitempty"`
+	Version  string    `json:"version,omitempty"`
+	Log4j    string    `json:"log4j,omitempty"`
+	Findings []Finding `json:"findings"`
+}
+
+// Worst returns the most severe status of the report.
+func (r Report) Worst() Status {
+	worst := OK
+	for _, finding := range r.Findings {
+		if rank(finding.Status) > rank(worst) {
+			worst = finding.Status
+		}
+	}
+	return worst
+}
+
+func rank(status Status) int {
+	switch status {
+	case Vulnerable:
+		return 4
+	case Outdated:
+		return 3
+	case Unknown:
+		return 2
+	case Mitigated:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Log4ShellCheck is the check name for CVE-2021-44228 and CVE-2021-45046.
+const Log4ShellCheck = "CVE-2021-44228 (Log4Shell)"
+
+// PaperBuildCheck is the check name for outdated Paper builds.
+const PaperBuildCheck = "paper-build"
+
+// log4ShellPatchDate is when PaperMC started shipping builds with a patched
+// log4j.
+var log4ShellPatchDate = time.Date(2021, time.December, 10, 0, 0, 0, 0, time.UTC)
+
+// Log4ShellMitigation is what Mojang told server owners to add for an
+// affected Minecraft version.
+type Log4ShellMitigation struct {
+	// Log4j is the log4j version bundled with the Minecraft version.
+	Log4j string
+	// Property is the system property to set, as name=value.
+	Property string
+	// ConfigFile is the log4j configuration the property points at, which
+	// must exist in the server directory. Empty for the formatMsgNoLookups
+	// property.
+	ConfigFile string
+	// ConfigURL is where Mojang publishes ConfigFile.
+	ConfigURL string
+}
+
+// Log4ShellFor returns the mitigation an affected Minecraft version needs and
+// false for versions that are not affected: before 1.7 (no log4j 2) and from
+// 1.18.1 (patched).
+func Log4ShellFor(version string) (Log4ShellMitigation, bool) {
+	if !strings.HasPrefix(version, "1.") {
+		return Log4ShellMitigation{}, false
+	}
+	switch {
+	case CompareVersions(version, "1.7") < 0, CompareVersions(version, "1.18.1") >= 0:
+		return Log4ShellMitigation{}, false
+	case CompareVersions(version, "1.17") >= 0:
+		return Log4ShellMitigation{Log4j: "2.14.1", Property: "log4j2.formatMsgNoLookups=true"}, true
+	case CompareVersions(version, "1.12") >= 0:
+		return Log4ShellMitigation{
+			Log4j:      "2.8.1",
+			Property:   "log4j.configurationFile=log4j2_112-116.xml",
+			ConfigFile: "log4j2_112-116.xml",
+			ConfigURL:  "https://launcher.mojang.com/v1/objects/02937d122c86ce73319ef9975b58896fc1b491d1/log4j2_112-116.xml",
+		}, true
+	default:
+		return Log4ShellMitigation{
+			Log4j:      "2.0-beta9",
+			Property:   "log4j.configurationFile=log4j2_17-111.xml",
+			ConfigFile: "log4j2_17-111.xml",
+			ConfigURL:  "https://launcher.mojang.com/v1/objects/4bb89a97a66f350bc9f73b3ca8509632682aea2e/log4j2_17-111.xml",
+		}, true
+	}
+}
+
+// Log4ShellInput is what is known about how a server starts.
+type Log4ShellInput struct {
+	Version string
+	// Properties are the -D system properties passed to Java.
+	Properties map[string]string
+	// Environment holds the server's environment variables.
+	Environment map[string]string
+	// ConfigFileExists reports whether the mitigation's config file is in
+	// the server directory; nil when it could not be checked.
+	ConfigFileExists *bool
+	// BuildTime is when the Paper build was published, zero for other jars.
+	// Builds from after the fix bundle a patched log4j.
+	BuildTime time.Time
+}
+
+// CheckLog4Shell decides whether a server is exposed to Log4Shell.
+func CheckLog4Shell(in Log4ShellInput) Finding {
+	finding := Finding{Check: Log4ShellCheck, Status: OK}
+	if in.Version == "" {
+		finding.Status = Unknown
+		finding.Detail = "Minecraft version unknown"
+		return finding
+	}
+	mitigation, affected := Log4ShellFor(in.Version)
+	if !affected {
+		finding.Detail = "Minecraft " + in.Version + " is not affected"
+		return finding
+	}
+	if !in.BuildTime.IsZero() && !in.BuildTime.Before(log4ShellPatchDate) {
+		finding.Status = Mitigated
+		finding.Detail = "build from " + in.BuildTime.Format("2006-01-02") + " bundles a patched log4j"
+		return finding
+	}
+
+	name, value, _ := strings.Cut(mitigation.Property, "=")
+	finding.Fix = "mineos servers env <server> set -D" + mitigation.Property
+	if mitigation.ConfigFile != "" {
+		finding.Fix += " and put " + mitigation.ConfigURL + " in the server directory"
+	}
+
+	// formatMsgNoLookups also works as an environment variable on log4j 2.10+.
+	noLookups := mitigation.ConfigFile == "" &&
+		strings.EqualFold(in.Environment["LOG4J_FORMAT_MSG_NO_LOOKUPS"], "true")
+	if !noLookups && !strings.EqualFold(in.Properties[name], value) {
+		finding.Status = Vulnerable
+		finding.Detail = "Minecraft " + in.Version + " bundles log4j " + mitigation.Log4j + " and -D" + mitigation.Property + " is not set"
+		return finding
+	}
+	if mitigation.ConfigFile != "" {
+		switch {
+		case in.ConfigFileExists == nil:
+			finding.Status = Unknown
+			finding.Detail = "-D" + mitigation.Property + " is set but " + mitigation.ConfigFile + " could not be checked"
+			return finding
+		case !*in.ConfigFileExists:
+			finding.Status = Vulnerable
+			finding.Detail = "-D" + mitigation.Property + " is set but " + mitigation.ConfigFile + " is missing from the server directory"
+			return finding
+		}
+	}
+	finding.Status = Mitigated
+	finding.Detail = "log4j " + mitigation.Log4j + " with lookups disabled"
+	finding.Fix = ""
+	return finding
+}
+
+// ParseJavaProperties collects the -Dname=value flags from a JVM argument
+// string such as the server's Java tweaks.
+func ParseJavaProperties(args string) map[string]string {
+	properties := map[string]string{}
+	for _, arg := range strings.Fields(args) {
+		if !strings.HasPrefix(arg, "-D") {
+			continue
+		}
+		name, value, _ := strings.Cut(strings.TrimPrefix(arg, "-D"), "=")
+		properties[name] = strings.Trim(value, `"'`)
+	}
+	return properties
+}
+
+var securityChangeRe = regexp.MustCompile(`(?i)\b(security|exploit\w*|vulnerab\w*|cve-\d{4}-\d+)\b`)
+
+// MentionsSecurityFix reports whether a Paper build change summary describes
+// a security fix.
+func MentionsSecurityFix(summary string) bool {
+	return securityChangeRe.MatchString(summary)
+}
+
+// CompareVersions compares dotted numeric versions such as 1.16.5 and 1.8.
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var av, bv int
+		if i < len(as) {
+			av = leadingNumber(as[i])
+		}
+		if i < len(bs) {
+			bv = leadingNumber(bs[i])
+		}
+		if av != bv {
+			if av < bv {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// leadingNumber parses the digits a version part starts with, so "18-pre1"
+// counts as 18.
+func leadingNumber(part string) int {
+	end := 0
+	for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(part[:end])
+	return n
+}
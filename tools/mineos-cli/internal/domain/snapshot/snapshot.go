@@ -0,0 +1,78 @@
+// Package snapshot names and describes filesystem snapshots of server
+// directories, taken with btrfs, ZFS or LVM instead of copying files.
+package snapshot
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindBtrfs Kind = "btrfs"
+	KindZfs   Kind = "zfs"
+	KindLvm   Kind = "lvm"
+)
+
+// Prefix marks the snapshots MineOS took, so others on the same volume are
+// left alone.
+const Prefix = "mineos-"
+
+const timeLayout = "20060102-150405"
+
+// Volume is what a snapshot captures: a btrfs subvolume, a ZFS dataset or
+// an LVM logical volume, mounted at Mount.
+type Volume struct {
+	Kind Kind `json:"kind"`
+	// Name is the subvolume path, the dataset or vg/lv.
+	Name  string `json:"name"`
+	Mount string `json:"mount"`
+}
+
+// Exclusive reports whether the volume holds dir and nothing else, so
+// rolling it back cannot touch other servers.
+func (v Volume) Exclusive(dir string) bool {
+	return filepath.Clean(v.Mount) == filepath.Clean(dir)
+}
+
+func (v Volume) String() string {
+	return fmt.Sprintf("%s %s", v.Kind, v.Name)
+}
+
+// Snapshot is one snapshot of a server's volume.
+type Snapshot struct {
+	Name    string    `json:"name"`
+	Server  string    `json:"server"`
+	Created time.Time `json:"created"`
+	Volume  Volume    `json:"volume"`
+	// Ref is what the tools call the snapshot: its subvolume path,
+	// dataset@name or vg/lv.
+	Ref string `json:"ref"`
+	// Size is the space only the snapshot holds, when the tool reports it.
+	Size int64 `json:"size,omitempty"`
+}
+
+// NewName names a snapshot of server taken at t.
+func NewName(server string, at time.Time) string {
+	return Prefix + server + "-" + at.UTC().Format(timeLayout)
+}
+
+// ParseName reads the server and time back from a name NewName made.
+func ParseName(name string) (string, time.Time, bool) {
+	rest, ok := strings.CutPrefix(name, Prefix)
+	if !ok || len(rest) < len(timeLayout)+2 {
+		return "", time.Time{}, false
+	}
+	cut := len(rest) - len(timeLayout)
+	if rest[cut-1] != '-' {
+		return "", time.Time{}, false
+	}
+	created, err := time.Parse(timeLayout, rest[cut:])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return rest[:cut-1], created, true
+}
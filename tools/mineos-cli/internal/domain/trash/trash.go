@@ -0,0 +1,150 @@
+// Package trash keeps deleted servers on the server volume for a while, so a
+// deletion can be undone. The scripts run in the API container, where the
+// volume is mounted and the files are owned by the right user.
+package trash
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dir holds one directory per deleted server, named <unix time>-<server>.
+// Inside it, each of the server's directories (files, backups, archives) is
+// kept under the name of the segment it came from.
+const Dir = "/var/games/minecraft/.trash"
+
+// DefaultRetentionDays is how long deleted servers are kept.
+const DefaultRetentionDays = 7
+
+// Layout is where the API keeps a server's directories: one per segment
+// (servers, backups, archives) under the base directory.
+type Layout struct {
+	Base     string
+	Segments []string
+}
+
+// Entry is a deleted server in the trash.
+type Entry struct {
+	Name      string // directory name under Dir
+	Server    string
+	DeletedAt time.Time
+	SizeKB    int64
+}
+
+// ExpiresAt is when the entry is purged. Zero retention keeps it until the
+// trash is emptied.
+func (e Entry) ExpiresAt(retentionDays int) time.Time {
+	if retentionDays <= 0 {
+		return time.Time{}
+	}
+	return e.DeletedAt.AddDate(0, 0, retentionDays)
+}
+
+// EntryName is the trash directory of a server deleted at a time.
+func EntryName(server string, at time.Time) string {
+	return strconv.FormatInt(at.Unix(), 10) + "-" + server
+}
+
+// MoveScript moves a server's directories into the trash. It fails, leaving
+// nothing moved, when the server has no files directory.
+func MoveScript(layout Layout, server string, at time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "set -e\n[ -d %s ] || { echo 'server files not found' >&2; exit 1; }\n", quote(layout.path(layout.Segments[0], server)))
+	entry := Dir + "/" + EntryName(server, at)
+	fmt.Fprintf(&b, "mkdir -p %s\n", quote(entry))
+	for _, segment := range layout.Segments {
+		source := layout.path(segment, server)
+		fmt.Fprintf(&b, "if [ -e %[1]s ]; then mv %[1]s %[2]s; fi\n", quote(source), quote(entry+"/"+segment))
+	}
+	return b.String()
+}
+
+// RestoreScript moves a trash entry back. It refuses, moving nothing, when
+// a server of that name exists again.
+func RestoreScript(layout Layout, entry Entry) string {
+	var b strings.Builder
+	dir := Dir + "/" + entry.Name
+	b.WriteString("set -e\n")
+	for _, segment := range layout.Segments {
+		fmt.Fprintf(&b, "if [ -e %s ] && [ -e %s ]; then echo %s >&2; exit 3; fi\n",
+			quote(dir+"/"+segment), quote(layout.path(segment, entry.Server)), quote(segment+"/"+entry.Server+" exists again; rename or delete it first"))
+	}
+	for _, segment := range layout.Segments {
+		fmt.Fprintf(&b, "if [ -e %[1]s ]; then mkdir -p %[2]s && mv %[1]s %[3]s; fi\n",
+			quote(dir+"/"+segment), quote(layout.Base+"/"+segment), quote(layout.path(segment, entry.Server)))
+	}
+	fmt.Fprintf(&b, "rmdir %s\n", quote(dir))
+	return b.String()
+}
+
+// PurgeScript removes trash entries for good.
+func PurgeScript(entries []Entry) string {
+	if len(entries) == 0 {
+		return "true"
+	}
+	parts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		parts = append(parts, quote(Dir+"/"+entry.Name))
+	}
+	return "rm -rf " + strings.Join(parts, " ")
+}
+
+// ListScript prints "name|size in KiB" for every trash entry.
+const ListScript = `for d in ` + Dir + `/*/; do
+  [ -d "$d" ] || continue
+  printf '%s|%s\n' "$(basename "$d")" "$(du -sk "$d" | cut -f1)"
+done`
+
+// ParseListing parses the output of ListScript, newest first. Directories
+// not named by EntryName are skipped.
+func ParseListing(output string) []Entry {
+	var entries []Entry
+	for _, line := range strings.Split(output, "\n") {
+		name, size, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if !ok {
+			continue
+		}
+		stamp, server, ok := strings.Cut(name, "-")
+		seconds, err := strconv.ParseInt(stamp, 10, 64)
+		if !ok || err != nil || server == "" {
+			continue
+		}
+		kb, _ := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+		entries = append(entries, Entry{Name: name, Server: server, DeletedAt: time.Unix(seconds, 0), SizeKB: kb})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].DeletedAt.After(entries[j].DeletedAt) })
+	return entries
+}
+
+// Expired returns the entries past the retention window at now.
+func Expired(entries []Entry, retentionDays int, now time.Time) []Entry {
+	var expired []Entry
+	for _, entry := range entries {
+		if at := entry.ExpiresAt(retentionDays); !at.IsZero() && !now.Before(at) {
+			expired = append(expired, entry)
+		}
+	}
+	return expired
+}
+
+// Latest returns the most recently deleted entry of a server.
+func Latest(entries []Entry, server string) (Entry, bool) {
+	for _, entry := range entries {
+		if entry.Server == server {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+func (l Layout) path(segment, server string) string {
+	return l.Base + "/" + segment + "/" + server
+}
+
+// quote makes a value one shell word.
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
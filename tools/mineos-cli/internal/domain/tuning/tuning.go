@@ -0,0 +1,284 @@
+// Package tuning checks host kernel settings that affect Java game servers
+// and recommends better values.
+package tuning
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	RecommendedSwappiness = 10
+	RecommendedNoFile     = 1048576
+	// MinNoFile is the open file limit below which busy servers (many
+	// players, chunk files, plugin databases) start failing.
+	MinNoFile = 65536
+	// zramBelowKB is the memory below which zram swap is worth having.
+	zramBelowKB = 8 << 20
+)
+
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusInfo Status = "info"
+)
+
+type ChangeKind string
+
+const (
+	// KindSysctl is set through /proc/sys and persisted in sysctl.d.
+	KindSysctl ChangeKind = "sysctl"
+	// KindSysfs is written to a sysfs file and again at boot via tmpfiles.d.
+	KindSysfs ChangeKind = "sysfs"
+	// KindDockerNoFile raises the Docker daemon's open file limit with a
+	// systemd drop-in; containers inherit it once Docker restarts.
+	KindDockerNoFile ChangeKind = "docker-nofile"
+)
+
+// Change is one setting to apply.
+type Change struct {
+	Kind  ChangeKind `json:"kind"`
+	Key   string     `json:"key"`
+	Value string     `json:"value"`
+}
+
+// Finding is the verdict on one setting.
+type Finding struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Current     string  `json:"current"`
+	Recommended string  `json:"recommended,omitempty"`
+	Status      Status  `json:"status"`
+	Reason      string  `json:"reason,omitempty"`
+	Change      *Change `json:"change,omitempty"`
+	// Hint says how to fix what cannot be applied automatically.
+	Hint string `json:"hint,omitempty"`
+}
+
+// Swap is one line of /proc/swaps.
+type Swap struct {
+	Name   string
+	Type   string
+	SizeKB int64
+}
+
+func (s Swap) IsZram() bool {
+	return strings.HasPrefix(s.Name, "/dev/zram")
+}
+
+// Host is what was read from the kernel. A nil or empty field could not be
+// read and its check is skipped.
+type Host struct {
+	MemTotalKB int64
+	Swappiness *int
+	// THPEnabled and THPDefrag list the options of the sysfs file with the
+	// selected one in brackets, e.g. "always [madvise] never".
+	THPEnabled string
+	THPDefrag  string
+	Swaps      []Swap
+	FileMax    *uint64
+	// NoFile is the soft open file limit containers get; NoFileSource says
+	// whose limit it is ("dockerd", or "this shell" when the daemon's could
+	// not be read).
+	NoFile       *uint64
+	NoFileSource string
+}
+
+// Analyze checks every setting it could read.
+func Analyze(h Host) []Finding {
+	var findings []Finding
+	if h.Swappiness != nil {
+		findings = append(findings, swappiness(h))
+	}
+	if h.THPEnabled != "" {
+		findings = append(findings, thpEnabled(h.THPEnabled))
+	}
+	if h.THPDefrag != "" {
+		findings = append(findings, thpDefrag(h.THPDefrag))
+	}
+	if h.MemTotalKB > 0 {
+		findings = append(findings, zram(h))
+	}
+	if h.FileMax != nil {
+		findings = append(findings, fileMax(*h.FileMax))
+	}
+	if h.NoFile != nil {
+		findings = append(findings, noFile(*h.NoFile, h.NoFileSource))
+	}
+	return findings
+}
+
+// Changes returns the changes of the findings that need one.
+func Changes(findings []Finding) []Change {
+	var changes []Change
+	for _, finding := range findings {
+		if finding.Change != nil && finding.Status != StatusOK {
+			changes = append(changes, *finding.Change)
+		}
+	}
+	return changes
+}
+
+func swappiness(h Host) Finding {
+	value := *h.Swappiness
+	f := Finding{
+		ID:          "vm.swappiness",
+		Title:       "Swappiness",
+		Current:     strconv.Itoa(value),
+		Recommended: strconv.Itoa(RecommendedSwappiness),
+		Status:      StatusOK,
+	}
+	switch {
+	case len(h.Swaps) == 0:
+		f.Recommended = ""
+		f.Reason = "No swap is enabled, so swappiness has no effect."
+	case value > RecommendedSwappiness:
+		f.Status = StatusWarn
+		f.Reason = "The kernel swaps out idle JVM heap early; the next garbage collection then stalls the server while it is read back."
+		f.Change = &Change{Kind: KindSysctl, Key: "vm.swappiness", Value: strconv.Itoa(RecommendedSwappiness)}
+	}
+	return f
+}
+
+func thpEnabled(raw string) Finding {
+	selected, _ := selectedOption(raw)
+	f := Finding{
+		ID:          "thp.enabled",
+		Title:       "Transparent hugepages",
+		Current:     selected,
+		Recommended: "madvise",
+		Status:      StatusOK,
+	}
+	switch selected {
+	case "always":
+		f.Status = StatusWarn
+		f.Reason = "With always, khugepaged compacts memory behind every process, causing latency spikes; madvise lets the JVM opt in with -XX:+UseTransparentHugePages."
+	case "never":
+		f.Status = StatusInfo
+		f.Reason = "With never, the JVM cannot use huge pages even when asked to with -XX:+UseTransparentHugePages."
+	}
+	if f.Status != StatusOK {
+		f.Change = &Change{Kind: KindSysfs, Key: thpEnabledPath, Value: "madvise"}
+	}
+	return f
+}
+
+func thpDefrag(raw string) Finding {
+	selected, options := selectedOption(raw)
+	recommended := "madvise"
+	for _, option := range options {
+		if option == "defer+madvise" {
+			recommended = option
+		}
+	}
+	f := Finding{
+		ID:          "thp.defrag",
+		Title:       "Hugepage defrag",
+		Current:     selected,
+		Recommended: recommended,
+		Status:      StatusOK,
+	}
+	if selected == "always" {
+		f.Status = StatusWarn
+		f.Reason = "With always, allocations stall to compact memory, which shows up as lag spikes."
+		f.Change = &Change{Kind: KindSysfs, Key: thpDefragPath, Value: recommended}
+	}
+	return f
+}
+
+const (
+	thpEnabledPath = "/sys/kernel/mm/transparent_hugepage/enabled"
+	thpDefragPath  = "/sys/kernel/mm/transparent_hugepage/defrag"
+)
+
+// ThpPaths are the sysfs files of the hugepage settings.
+var ThpPaths = []string{thpEnabledPath, thpDefragPath}
+
+func zram(h Host) Finding {
+	f := Finding{ID: "zram", Title: "zram swap", Status: StatusOK}
+	var sizeKB int64
+	for _, swap := range h.Swaps {
+		if swap.IsZram() {
+			sizeKB += swap.SizeKB
+		}
+	}
+	switch {
+	case sizeKB > 0:
+		f.Current = formatKB(sizeKB)
+	case h.MemTotalKB < zramBelowKB:
+		f.Current = "none"
+		f.Recommended = "half of RAM"
+		f.Status = StatusInfo
+		f.Reason = fmt.Sprintf("With %s of memory, compressed swap in RAM absorbs spikes (world generation, backups) that would otherwise get a server killed.", formatKB(h.MemTotalKB))
+		f.Hint = "Install zram-tools (Debian/Ubuntu) or systemd-zram-generator, e.g. 'apt install zram-tools'."
+	default:
+		f.Current = "none"
+		f.Reason = "Not needed with this much memory."
+	}
+	return f
+}
+
+func fileMax(value uint64) Finding {
+	f := Finding{
+		ID:          "fs.file-max",
+		Title:       "System open file limit",
+		Current:     strconv.FormatUint(value, 10),
+		Recommended: strconv.Itoa(RecommendedNoFile),
+		Status:      StatusOK,
+	}
+	if value < RecommendedNoFile {
+		f.Status = StatusWarn
+		f.Reason = "Every server, player connection and region file holds file handles; the whole host shares this limit."
+		f.Change = &Change{Kind: KindSysctl, Key: "fs.file-max", Value: strconv.Itoa(RecommendedNoFile)}
+	} else {
+		f.Recommended = ""
+	}
+	return f
+}
+
+func noFile(value uint64, source string) Finding {
+	f := Finding{
+		ID:          "nofile",
+		Title:       "Open files per process (" + source + ")",
+		Current:     strconv.FormatUint(value, 10),
+		Recommended: strconv.Itoa(RecommendedNoFile),
+		Status:      StatusOK,
+	}
+	switch {
+	case value >= MinNoFile:
+		f.Recommended = ""
+	case source == "dockerd":
+		f.Status = StatusWarn
+		f.Reason = "Containers inherit the Docker daemon's limit; servers with many players or plugins fail with \"Too many open files\"."
+		f.Change = &Change{Kind: KindDockerNoFile, Key: "LimitNOFILE", Value: strconv.Itoa(RecommendedNoFile)}
+	default:
+		f.Status = StatusInfo
+		f.Reason = "The Docker daemon's own limit could not be read (run as root to check it); containers inherit it, not this shell's."
+	}
+	return f
+}
+
+// selectedOption parses "always [madvise] never" into the selected option
+// and all of them.
+func selectedOption(raw string) (string, []string) {
+	var selected string
+	var options []string
+	for _, field := range strings.Fields(raw) {
+		option := strings.Trim(field, "[]")
+		if strings.HasPrefix(field, "[") {
+			selected = option
+		}
+		options = append(options, option)
+	}
+	if selected == "" {
+		selected = strings.TrimSpace(raw)
+	}
+	return selected, options
+}
+
+func formatKB(kb int64) string {
+	return fmt.Sprintf("%.1f GB", float64(kb)/(1<<20))
+}
@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/alerts"
+)
+
+// AlertMonitor evaluates the alert rules every Interval and hands the
+// resulting events to Notify.
+type AlertMonitor struct {
+	// Load returns the current rules. It is called on every check so edits
+	// apply without restarting the agent.
+	Load     func() (alerts.Definition, error)
+	Observe  func(ctx context.Context) (alerts.Snapshot, error)
+	Notify   func(ctx context.Context, def alerts.Definition, event alerts.Event) error
+	Interval time.Duration
+	Audit    *AuditLog
+	OnEvent  func(message string)
+
+	engine    *alerts.Engine
+	lastError string
+}
+
+// Run checks until ctx is cancelled. If the file becomes invalid the last
+// valid rules keep running.
+func (m *AlertMonitor) Run(ctx context.Context) {
+	interval := m.Interval
+	if interval < time.Second {
+		interval = 30 * time.Second
+	}
+	m.engine = alerts.NewEngine()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var current alerts.Definition
+	for {
+		def, err := m.Load()
+		if err != nil {
+			m.report("failed to load alert rules: " + err.Error())
+			def = current
+		}
+		current = def
+		m.check(ctx, def)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *AlertMonitor) check(ctx context.Context, def alerts.Definition) {
+	if len(def.Rules) == 0 {
+		return
+	}
+	snapshot, err := m.Observe(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		m.report("failed to collect metrics: " + err.Error())
+		return
+	}
+	m.lastError = ""
+
+	for _, event := range m.engine.Evaluate(def, snapshot) {
+		m.event(event.Title())
+		audit := AuditEvent{
+			Event:  "alert-" + string(event.Kind),
+			Params: map[string]string{"rule": event.Rule.Label(), "subject": event.Subject, "value": event.Value},
+			Status: "sent",
+		}
+		if err := m.Notify(ctx, def, event); err != nil {
+			audit.Status = "failed"
+			audit.Error = err.Error()
+			m.event("failed to send notification: " + err.Error())
+		}
+		m.Audit.Record(audit)
+	}
+}
+
+// report shows a failure once until it changes, so a stopped API does not
+// print a line every interval.
+func (m *AlertMonitor) report(message string) {
+	if message == m.lastError {
+		return
+	}
+	m.lastError = message
+	m.event(message)
+}
+
+func (m *AlertMonitor) event(message string) {
+	if m.OnEvent != nil {
+		m.OnEvent(message)
+	}
+}
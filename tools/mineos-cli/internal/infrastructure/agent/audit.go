@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditEvent is one line of the agent audit log.
+type AuditEvent struct {
+	Time       time.Time         `json:"time"`
+	Event      string            `json:"event"`
+	Remote     string            `json:"remote,omitempty"`
+	Operation  string            `json:"operation,omitempty"`
+	Params     map[string]string `json:"params,omitempty"`
+	RunID      string            `json:"runId,omitempty"`
+	Status     string            `json:"status,omitempty"`
+	ExitCode   *int              `json:"exitCode,omitempty"`
+	DurationMs int64             `json:"durationMs,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// AuditLog appends JSON lines to a file.
+type AuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func OpenAuditLog(path string) (*AuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{file: file}, nil
+}
+
+func (a *AuditLog) Record(event AuditEvent) {
+	if a == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, _ = a.file.Write(append(line, '\n'))
+}
+
+func (a *AuditLog) Close() error {
+	if a == nil {
+		return nil
+	}
+	return a.file.Close()
+}
@@ -0,0 +1,243 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/guard"
+	guardstore "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/guard"
+)
+
+// AbuseGuard follows the console of every watched server, scores failed
+// logins, join floods and exploit attempts per address, and blocks an
+// address on Firewall once its score reaches the threshold. Bans are kept in
+// Bans and lifted when they end, also across agent restarts.
+type AbuseGuard struct {
+	// Load returns the current settings. It is re-read every minute so
+	// edits apply without restarting the agent.
+	Load func() (guard.Definition, error)
+	// Servers lists the server names; it is re-read every minute so new
+	// servers are picked up.
+	Servers func(ctx context.Context) ([]string, error)
+	// Follow passes each console line of a server to onLine until the
+	// stream ends or ctx is cancelled.
+	Follow   func(ctx context.Context, server string, onLine func(at time.Time, line string)) error
+	Firewall guardstore.Firewall
+	Bans     *guardstore.BanStore
+	Audit    *AuditLog
+	OnEvent  func(message string)
+
+	mu        sync.Mutex
+	def       guard.Definition
+	engine    *guard.Engine
+	lastError string
+}
+
+// Run guards until ctx is cancelled. If the file becomes invalid the last
+// valid settings stay in force.
+func (g *AbuseGuard) Run(ctx context.Context) {
+	g.engine = guard.NewEngine()
+	if def, err := g.Load(); err == nil {
+		g.def = def
+	}
+	g.restore(ctx)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	following := map[string]bool{}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		if def, err := g.Load(); err != nil {
+			g.report("failed to load guard settings: " + err.Error())
+		} else {
+			g.mu.Lock()
+			g.def = def
+			g.mu.Unlock()
+		}
+		g.expire(ctx)
+
+		servers, err := g.Servers(ctx)
+		switch {
+		case ctx.Err() != nil:
+		case err != nil:
+			g.report("failed to list servers: " + err.Error())
+		default:
+			g.mu.Lock()
+			def := g.def
+			g.mu.Unlock()
+			for _, server := range servers {
+				if following[server] || !def.Watches(server) {
+					continue
+				}
+				following[server] = true
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					g.follow(ctx, server)
+				}()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// restore re-applies the bans that outlived the last run, since nftables
+// sets do not survive a reboot, and lifts the ones that ended meanwhile.
+func (g *AbuseGuard) restore(ctx context.Context) {
+	g.expire(ctx)
+	bans, err := g.Bans.Load()
+	if err != nil {
+		g.event("failed to read bans: " + err.Error())
+		return
+	}
+	now := time.Now()
+	for _, ban := range bans {
+		g.engine.MarkBanned(ban.IP, ban.Until)
+		if ban.Firewall != g.Firewall.Name() {
+			continue
+		}
+		if err := g.Firewall.Block(ctx, ban.IP, ban.Until.Sub(now)); err != nil {
+			g.event(fmt.Sprintf("failed to restore the ban on %s: %v", ban.IP, err))
+		}
+	}
+}
+
+func (g *AbuseGuard) follow(ctx context.Context, server string) {
+	parser := guard.NewParser(server)
+	// Streams start with recent history; only lines after the guard
+	// started, and after the last line seen on a reconnect, count.
+	last := time.Now()
+	lastError := ""
+	for {
+		err := g.Follow(ctx, server, func(at time.Time, line string) {
+			if !at.IsZero() {
+				if !at.After(last) {
+					return
+				}
+				last = at
+			} else {
+				at = time.Now()
+			}
+			if offence, ok := parser.Parse(line, at); ok {
+				g.record(ctx, offence)
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err != nil && err.Error() != lastError:
+			lastError = err.Error()
+			g.event(fmt.Sprintf("failed to follow %s: %v", server, err))
+		case err == nil && lastError != "":
+			lastError = ""
+			g.event("following " + server + " again")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (g *AbuseGuard) record(ctx context.Context, offence guard.Offence) {
+	g.mu.Lock()
+	def := g.def
+	ban, banned := g.engine.Record(def, offence)
+	g.mu.Unlock()
+	if !banned {
+		return
+	}
+
+	ban.Firewall = g.Firewall.Name()
+	audit := AuditEvent{
+		Event: "guard-ban",
+		Params: map[string]string{
+			"ip":     ban.IP,
+			"server": ban.Server,
+			"rules":  strings.Join(ban.Rules, ","),
+			"score":  fmt.Sprint(ban.Score),
+			"until":  ban.Until.UTC().Format(time.RFC3339),
+		},
+		Status: "blocked",
+	}
+	if offence.Player != "" {
+		audit.Params["player"] = offence.Player
+	}
+
+	message := fmt.Sprintf("banned %s for %s on %s (%s, score %d)", ban.IP, def.WithDefaults().Ban, ban.Server, strings.Join(ban.Rules, ", "), ban.Score)
+	if ban.Firewall == guard.FirewallNone {
+		audit.Status = "reported"
+		message = fmt.Sprintf("%s would be banned on %s (%s, score %d); firewall is none", ban.IP, ban.Server, strings.Join(ban.Rules, ", "), ban.Score)
+	} else if err := g.Firewall.Block(ctx, ban.IP, def.WithDefaults().Ban); err != nil {
+		audit.Status = "failed"
+		audit.Error = err.Error()
+		message = fmt.Sprintf("failed to ban %s: %v", ban.IP, err)
+	}
+	if audit.Status != "failed" {
+		if err := g.Bans.Add(ban); err != nil {
+			g.event("failed to save bans: " + err.Error())
+		}
+	}
+	g.event(message)
+	g.Audit.Record(audit)
+}
+
+// expire lifts the bans that ended. nftables drops them by itself; lifting
+// them again is harmless.
+func (g *AbuseGuard) expire(ctx context.Context) {
+	now := time.Now()
+	g.mu.Lock()
+	g.engine.Prune(g.def, now)
+	g.mu.Unlock()
+
+	expired, err := g.Bans.Expired(now)
+	if err != nil {
+		g.report("failed to read bans: " + err.Error())
+		return
+	}
+	for _, ban := range expired {
+		audit := AuditEvent{Event: "guard-unban", Params: map[string]string{"ip": ban.IP}, Status: "expired"}
+		if ban.Firewall == g.Firewall.Name() {
+			if err := g.Firewall.Unblock(ctx, ban.IP); err != nil {
+				audit.Status = "failed"
+				audit.Error = err.Error()
+				g.event(fmt.Sprintf("failed to lift the ban on %s: %v", ban.IP, err))
+			}
+		}
+		if audit.Status == "expired" && ban.Firewall != guard.FirewallNone {
+			g.event("ban on " + ban.IP + " ended")
+		}
+		g.Audit.Record(audit)
+	}
+}
+
+// report shows a failure once until it changes.
+func (g *AbuseGuard) report(message string) {
+	g.mu.Lock()
+	repeated := message == g.lastError
+	g.lastError = message
+	g.mu.Unlock()
+	if !repeated {
+		g.event(message)
+	}
+}
+
+func (g *AbuseGuard) event(message string) {
+	if g.OnEvent == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.OnEvent(message)
+}
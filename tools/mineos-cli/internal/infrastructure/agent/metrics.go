@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+// MetricsRecorder samples every server at Interval into the local metrics
+// store and drops samples older than Retention.
+type MetricsRecorder struct {
+	Store     *state.Metrics
+	Interval  time.Duration
+	Retention time.Duration
+	// Collect returns one sample per server.
+	Collect func(ctx context.Context) ([]ports.PerformanceSample, error)
+	OnEvent func(message string)
+
+	lastError string
+}
+
+// Run records until ctx is cancelled. A failure is reported once and again
+// when it changes or recovers, not on every tick.
+func (r *MetricsRecorder) Run(ctx context.Context) {
+	interval := r.Interval
+	if interval < time.Second {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastPrune time.Time
+	for {
+		r.record(ctx)
+		if r.Retention > 0 && time.Since(lastPrune) >= time.Hour {
+			lastPrune = time.Now()
+			if _, err := r.Store.Prune(ctx, lastPrune.Add(-r.Retention)); err != nil && ctx.Err() == nil {
+				r.event("failed to prune metrics: " + err.Error())
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *MetricsRecorder) record(ctx context.Context) {
+	samples, err := r.Collect(ctx)
+	if err == nil {
+		err = r.Store.Record(ctx, samples)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	switch {
+	case err != nil && err.Error() != r.lastError:
+		r.lastError = err.Error()
+		r.event("failed to record metrics: " + err.Error())
+	case err == nil && r.lastError != "":
+		r.lastError = ""
+		r.event("recording metrics again")
+	}
+}
+
+func (r *MetricsRecorder) event(message string) {
+	if r.OnEvent != nil {
+		r.OnEvent(message)
+	}
+}
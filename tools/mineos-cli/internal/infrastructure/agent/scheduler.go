@@ -0,0 +1,278 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	domainbackup "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/backup"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/schedule"
+)
+
+// RestartFunc runs one scheduled restart, reporting whether the server was
+// actually restarted.
+type RestartFunc func(ctx context.Context, policy schedule.RestartPolicy, progress func(string)) (bool, error)
+
+// AnnounceFunc broadcasts one message of an announcement, returning the
+// servers it reached.
+type AnnounceFunc func(ctx context.Context, announcement schedule.Announcement, message string) ([]string, error)
+
+// PresetFunc applies a scheduled preset, returning the console commands
+// sent.
+type PresetFunc func(ctx context.Context, policy schedule.PresetPolicy) ([]string, error)
+
+// VerifyFunc verifies the latest backup of each server a verify policy
+// covers, returning a report per backup.
+type VerifyFunc func(ctx context.Context, policy schedule.VerifyPolicy) ([]domainbackup.Report, error)
+
+// AlertFunc reports a problem to the configured notification targets.
+type AlertFunc func(ctx context.Context, title, message string) error
+
+// Scheduler triggers restart policies when their cron expression matches
+// and sends announcements, applies presets and verifies backups when they
+// are due. Each
+// deferred restart runs on its own so a busy server does not hold up the
+// others; a server never has more than one restart pending.
+type Scheduler struct {
+	// Load returns the current schedule. It is called every minute so edits
+	// apply without restarting the agent.
+	Load     func() (schedule.Definition, error)
+	Restart  RestartFunc
+	Announce AnnounceFunc
+	Preset   PresetFunc
+	Verify   VerifyFunc
+	// Alert is told about backups that fail verification.
+	Alert   AlertFunc
+	Audit   *AuditLog
+	OnEvent func(message string)
+
+	mu      sync.Mutex
+	pending map[string]bool
+	// rotation is the index of the last message sent per announcement.
+	rotation map[string]int
+}
+
+// Run checks the schedule at the start of every minute until ctx is
+// cancelled, then waits for pending restarts to return.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	var current schedule.Definition
+	loaded := false
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(next.Sub(now)):
+		}
+
+		def, err := s.Load()
+		if err != nil {
+			s.event("failed to load schedule: " + err.Error())
+			if !loaded {
+				continue
+			}
+			def = current
+		}
+		current, loaded = def, true
+
+		for _, policy := range def.Due(next) {
+			if !s.claim(policy.Server) {
+				s.event(policy.Server + ": restart already pending; skipping")
+				continue
+			}
+			wg.Add(1)
+			go func(policy schedule.RestartPolicy) {
+				defer wg.Done()
+				defer s.release(policy.Server)
+				s.run(ctx, policy)
+			}(policy)
+		}
+		if s.Preset != nil {
+			for _, policy := range def.DuePresets(next) {
+				wg.Add(1)
+				go func(policy schedule.PresetPolicy) {
+					defer wg.Done()
+					s.applyPreset(ctx, policy)
+				}(policy)
+			}
+		}
+		if s.Verify != nil {
+			for _, policy := range def.DueVerify(next) {
+				wg.Add(1)
+				go func(policy schedule.VerifyPolicy) {
+					defer wg.Done()
+					s.verify(ctx, policy)
+				}(policy)
+			}
+		}
+		if s.Announce == nil {
+			continue
+		}
+		for _, announcement := range def.DueAnnouncements(next) {
+			message := s.nextMessage(announcement)
+			wg.Add(1)
+			go func(announcement schedule.Announcement) {
+				defer wg.Done()
+				s.announce(ctx, announcement, message)
+			}(announcement)
+		}
+	}
+}
+
+func (s *Scheduler) announce(ctx context.Context, announcement schedule.Announcement, message string) {
+	sent, err := s.Announce(ctx, announcement, message)
+	if len(sent) > 0 {
+		s.event("announced " + announcement.Label() + " on " + strings.Join(sent, ", "))
+	}
+	if err != nil {
+		s.event("announcement " + announcement.Label() + " failed: " + err.Error())
+	}
+}
+
+func (s *Scheduler) applyPreset(ctx context.Context, policy schedule.PresetPolicy) {
+	started := time.Now()
+	params := map[string]string{"server": policy.Server, "preset": policy.Preset, "cron": policy.Cron}
+	sent, err := s.Preset(ctx, policy)
+	event := AuditEvent{
+		Event:      "preset-applied",
+		Operation:  "servers.preset.apply",
+		Params:     params,
+		Status:     "completed",
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		event.Status = "failed"
+		event.Error = err.Error()
+		s.event(policy.Server + ": preset " + policy.Preset + " failed: " + err.Error())
+	} else {
+		s.event(fmt.Sprintf("%s: applied preset %s (%d commands)", policy.Server, policy.Preset, len(sent)))
+	}
+	s.Audit.Record(event)
+}
+
+func (s *Scheduler) verify(ctx context.Context, policy schedule.VerifyPolicy) {
+	reports, err := s.Verify(ctx, policy)
+	if err != nil {
+		s.event("backup verification failed: " + err.Error())
+		s.alert(ctx, "Backup verification failed", err.Error())
+	}
+	for _, report := range reports {
+		event := AuditEvent{
+			Event:      "backup-verified",
+			Operation:  "backup.verify",
+			Params:     map[string]string{"server": report.Server, "backup": report.ID, "cron": policy.Cron},
+			Status:     "completed",
+			DurationMs: report.Duration.Milliseconds(),
+		}
+		if report.OK() {
+			s.event(fmt.Sprintf("%s: backup %s verified (%d files, %d regions)", report.Server, report.ID, report.Files, report.Regions))
+		} else {
+			event.Status = "failed"
+			event.Error = strings.Join(report.Problems, "; ")
+			s.event(fmt.Sprintf("%s: backup %s failed verification: %s", report.Server, report.ID, event.Error))
+			s.alert(ctx, "Backup "+report.ID+" of "+report.Server+" failed verification", strings.Join(report.Problems, "\n"))
+		}
+		s.Audit.Record(event)
+	}
+}
+
+func (s *Scheduler) alert(ctx context.Context, title, message string) {
+	if s.Alert == nil {
+		return
+	}
+	if err := s.Alert(ctx, title, message); err != nil {
+		s.event("alert failed: " + err.Error())
+	}
+}
+
+// nextMessage takes the announcement's messages in turn, or at random
+// without repeating the previous one. The position survives schedule
+// reloads as long as the announcement keeps its label.
+func (s *Scheduler) nextMessage(announcement schedule.Announcement) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rotation == nil {
+		s.rotation = map[string]int{}
+	}
+	count := len(announcement.Messages)
+	last, seen := s.rotation[announcement.Label()]
+	next := 0
+	switch {
+	case announcement.Random && seen && count > 1:
+		next = rand.IntN(count - 1)
+		if next >= last {
+			next++
+		}
+	case announcement.Random:
+		next = rand.IntN(count)
+	case seen:
+		next = (last + 1) % count
+	}
+	s.rotation[announcement.Label()] = next
+	return announcement.Messages[next]
+}
+
+func (s *Scheduler) run(ctx context.Context, policy schedule.RestartPolicy) {
+	started := time.Now()
+	params := map[string]string{"server": policy.Server, "cron": policy.Cron}
+	s.Audit.Record(AuditEvent{Event: "restart-scheduled", Operation: "servers.restart", Params: params})
+	s.event(policy.Server + ": scheduled restart triggered")
+
+	restarted, err := s.Restart(ctx, policy, func(message string) {
+		s.event(policy.Server + ": " + message)
+	})
+
+	status := "completed"
+	switch {
+	case err != nil:
+		status = "failed"
+		s.event(policy.Server + ": scheduled restart failed: " + err.Error())
+	case !restarted:
+		status = "skipped"
+	default:
+		s.event(policy.Server + ": restarted")
+	}
+	event := AuditEvent{
+		Event:      "restart-finished",
+		Operation:  "servers.restart",
+		Params:     params,
+		Status:     status,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.Audit.Record(event)
+}
+
+func (s *Scheduler) claim(server string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending == nil {
+		s.pending = map[string]bool{}
+	}
+	if s.pending[server] {
+		return false
+	}
+	s.pending[server] = true
+	return true
+}
+
+func (s *Scheduler) release(server string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, server)
+}
+
+func (s *Scheduler) event(message string) {
+	if s.OnEvent != nil {
+		s.OnEvent(message)
+	}
+}
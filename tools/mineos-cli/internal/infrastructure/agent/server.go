@@ -0,0 +1,433 @@
+// Package agent implements the HTTP endpoint of 'mineos agent', which runs
+// whitelisted CLI operations for authenticated remote callers.
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/agent"
+)
+
+const (
+	maxBodyBytes  = 64 * 1024
+	maxOutputSize = 64 * 1024
+	maxRuns       = 100
+)
+
+// SignatureWindow is how far X-Mineos-Timestamp may be from the agent's
+// clock; older signatures are rejected, so a captured one cannot be replayed.
+const SignatureWindow = 5 * time.Minute
+
+// Run statuses.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+type Options struct {
+	Token string
+	// Allowed limits the operations callers may trigger. Empty allows all.
+	Allowed []string
+	// Executable and BaseArgs form the CLI invocation, e.g. mineos --env x.
+	Executable string
+	BaseArgs   []string
+	RunTimeout time.Duration
+	Audit      *AuditLog
+}
+
+type Run struct {
+	ID         string            `json:"id"`
+	Operation  string            `json:"operation"`
+	Params     map[string]string `json:"params,omitempty"`
+	Status     string            `json:"status"`
+	ExitCode   *int              `json:"exitCode,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Output     string            `json:"output,omitempty"`
+	QueuedAt   time.Time         `json:"queuedAt"`
+	StartedAt  *time.Time        `json:"startedAt,omitempty"`
+	FinishedAt *time.Time        `json:"finishedAt,omitempty"`
+
+	args   []string
+	remote string
+	done   chan struct{}
+}
+
+func (r *Run) finished() bool {
+	return r.Status == StatusSucceeded || r.Status == StatusFailed
+}
+
+type Server struct {
+	opts    Options
+	allowed map[string]bool
+
+	// execMu ensures only one operation or update touches the stack at once.
+	execMu sync.Mutex
+
+	mu    sync.Mutex
+	runs  map[string]*Run
+	order []string
+	queue chan *Run
+
+	now func() time.Time
+}
+
+func NewServer(opts Options) (*Server, error) {
+	if strings.TrimSpace(opts.Token) == "" {
+		return nil, errors.New("agent token is required")
+	}
+	if opts.RunTimeout <= 0 {
+		opts.RunTimeout = 30 * time.Minute
+	}
+	allowed := map[string]bool{}
+	for _, name := range opts.Allowed {
+		if _, ok := domain.Find(name); !ok {
+			return nil, fmt.Errorf("unknown operation %q (available: %s)", name, strings.Join(domain.Names(), ", "))
+		}
+		allowed[name] = true
+	}
+	if len(allowed) == 0 {
+		for _, op := range domain.Operations() {
+			allowed[op.Name] = !op.OptIn
+		}
+	}
+	return &Server{
+		opts:    opts,
+		allowed: allowed,
+		runs:    map[string]*Run{},
+		queue:   make(chan *Run, maxRuns),
+		now:     time.Now,
+	}, nil
+}
+
+// Serve listens on addr until ctx is cancelled. Runs execute one at a time
+// so that, for example, a stack update never overlaps a backup.
+func (s *Server) Serve(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	go s.worker(ctx)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/health", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /v1/operations", s.authenticated(s.listOperations))
+	mux.HandleFunc("POST /v1/operations/{name}", s.authenticated(s.trigger))
+	mux.HandleFunc("GET /v1/runs", s.authenticated(s.listRuns))
+	mux.HandleFunc("GET /v1/runs/{id}", s.authenticated(s.getRun))
+	return mux
+}
+
+// authenticated accepts either "Authorization: Bearer <token>" or an HMAC
+// SHA-256 signature keyed with the token, sent as X-Mineos-Signature
+// ("sha256=<hex>") with the Unix time it was made in X-Mineos-Timestamp; see
+// Sign for what it covers.
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+		if err != nil || len(body) > maxBodyBytes {
+			writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !s.authorized(r, body) {
+			s.opts.Audit.Record(AuditEvent{Event: "rejected", Remote: r.RemoteAddr, Operation: r.PathValue("name"), Error: "unauthorized"})
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) authorized(r *http.Request, body []byte) bool {
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(bearer)), []byte(s.opts.Token)) == 1
+	}
+	hexSig, ok := strings.CutPrefix(r.Header.Get("X-Mineos-Signature"), "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(hexSig)
+	if err != nil {
+		return false
+	}
+	unix, err := strconv.ParseInt(r.Header.Get("X-Mineos-Timestamp"), 10, 64)
+	if err != nil {
+		return false
+	}
+	timestamp := time.Unix(unix, 0)
+	if skew := s.now().Sub(timestamp); skew > SignatureWindow || skew < -SignatureWindow {
+		return false
+	}
+	want, _ := hex.DecodeString(Sign(s.opts.Token, r.Method, r.URL.Path, timestamp, body))
+	return hmac.Equal(got, want)
+}
+
+// Sign returns the hex HMAC-SHA256, keyed with token, of
+// "<method>\n<path>\n<unix timestamp>\n<body>", so a signature only
+// authorizes the request it was made for, and only within SignatureWindow.
+func Sign(token, method, path string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	fmt.Fprintf(mac, "%s\n%s\n%d\n", strings.ToUpper(method), path, timestamp.Unix())
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Server) listOperations(w http.ResponseWriter, _ *http.Request) {
+	var ops []domain.Operation
+	for _, op := range domain.Operations() {
+		if s.allowed[op.Name] {
+			ops = append(ops, op)
+		}
+	}
+	writeJSON(w, http.StatusOK, ops)
+}
+
+func (s *Server) trigger(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	op, ok := domain.Find(name)
+	if !ok || !s.allowed[name] {
+		s.opts.Audit.Record(AuditEvent{Event: "rejected", Remote: r.RemoteAddr, Operation: name, Error: "operation not allowed"})
+		writeError(w, http.StatusForbidden, fmt.Sprintf("operation %q is not allowed", name))
+		return
+	}
+
+	params := map[string]string{}
+	if body, _ := io.ReadAll(r.Body); len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &params); err != nil {
+			writeError(w, http.StatusBadRequest, "body must be a JSON object of string parameters")
+			return
+		}
+	}
+	args, err := op.Args(params)
+	if err != nil {
+		s.opts.Audit.Record(AuditEvent{Event: "rejected", Remote: r.RemoteAddr, Operation: name, Params: params, Error: err.Error()})
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	run := &Run{
+		ID:        newRunID(),
+		Operation: name,
+		Params:    params,
+		Status:    StatusQueued,
+		QueuedAt:  time.Now().UTC(),
+		args:      args,
+		remote:    r.RemoteAddr,
+		done:      make(chan struct{}),
+	}
+	if !s.enqueue(run) {
+		writeError(w, http.StatusServiceUnavailable, "too many queued runs")
+		return
+	}
+	s.opts.Audit.Record(AuditEvent{Event: "queued", Remote: r.RemoteAddr, Operation: name, Params: params, RunID: run.ID})
+
+	if r.URL.Query().Get("wait") == "true" {
+		select {
+		case <-run.done:
+		case <-r.Context().Done():
+			return
+		}
+		writeJSON(w, http.StatusOK, s.snapshot(run))
+		return
+	}
+	w.Header().Set("Location", "/v1/runs/"+run.ID)
+	writeJSON(w, http.StatusAccepted, s.snapshot(run))
+}
+
+func (s *Server) enqueue(run *Run) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case s.queue <- run:
+	default:
+		return false
+	}
+	s.runs[run.ID] = run
+	s.order = append(s.order, run.ID)
+	// Forget the oldest finished runs so memory stays bounded.
+	for len(s.order) > maxRuns {
+		oldest := s.runs[s.order[0]]
+		if oldest != nil && !oldest.finished() {
+			break
+		}
+		delete(s.runs, s.order[0])
+		s.order = s.order[1:]
+	}
+	return true
+}
+
+func (s *Server) listRuns(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	runs := make([]Run, 0, len(s.order))
+	for _, id := range s.order {
+		run := *s.runs[id]
+		run.Output = ""
+		runs = append(runs, run)
+	}
+	s.mu.Unlock()
+	sort.Slice(runs, func(i, j int) bool { return runs[i].QueuedAt.After(runs[j].QueuedAt) })
+	writeJSON(w, http.StatusOK, runs)
+}
+
+func (s *Server) getRun(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	run, ok := s.runs[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.snapshot(run))
+}
+
+func (s *Server) snapshot(run *Run) Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *run
+}
+
+func (s *Server) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case run := <-s.queue:
+			s.RunExclusive(func() { s.execute(ctx, run) })
+		}
+	}
+}
+
+// RunExclusive runs fn while no queued operation is executing.
+func (s *Server) RunExclusive(fn func()) {
+	s.execMu.Lock()
+	defer s.execMu.Unlock()
+	fn()
+}
+
+func (s *Server) execute(ctx context.Context, run *Run) {
+	started := time.Now().UTC()
+	s.mu.Lock()
+	run.Status = StatusRunning
+	run.StartedAt = &started
+	s.mu.Unlock()
+	s.opts.Audit.Record(AuditEvent{Event: "started", Remote: run.remote, Operation: run.Operation, Params: run.Params, RunID: run.ID})
+
+	runCtx, cancel := context.WithTimeout(ctx, s.opts.RunTimeout)
+	defer cancel()
+
+	var output tailBuffer
+	cmd := exec.CommandContext(runCtx, s.opts.Executable, append(append([]string(nil), s.opts.BaseArgs...), run.args...)...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+
+	finished := time.Now().UTC()
+	exitCode := 0
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+
+	s.mu.Lock()
+	run.FinishedAt = &finished
+	run.Output = output.String()
+	run.ExitCode = &exitCode
+	run.Status = StatusSucceeded
+	if err != nil {
+		run.Status = StatusFailed
+		run.Error = err.Error()
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			run.Error = "timed out after " + s.opts.RunTimeout.String()
+		}
+	}
+	event := AuditEvent{
+		Event:      "finished",
+		Remote:     run.remote,
+		Operation:  run.Operation,
+		Params:     run.Params,
+		RunID:      run.ID,
+		Status:     run.Status,
+		ExitCode:   run.ExitCode,
+		DurationMs: finished.Sub(started).Milliseconds(),
+		Error:      run.Error,
+	}
+	s.mu.Unlock()
+
+	s.opts.Audit.Record(event)
+	close(run.done)
+}
+
+// tailBuffer keeps the last maxOutputSize bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > maxOutputSize {
+		t.buf = t.buf[len(t.buf)-maxOutputSize:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
+
+func newRunID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
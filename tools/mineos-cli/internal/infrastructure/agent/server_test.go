@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuthorized(t *testing.T) {
+	const token = "s3cret"
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"params":{"server":"survival"}}`)
+	signed := func(token, method, path string, at time.Time, body []byte) map[string]string {
+		return map[string]string{
+			"X-Mineos-Signature": "sha256=" + Sign(token, method, path, at, body),
+			"X-Mineos-Timestamp": strconv.FormatInt(at.Unix(), 10),
+		}
+	}
+	const path = "/v1/operations/backup"
+
+	tests := []struct {
+		name    string
+		body    []byte
+		headers map[string]string
+		want    bool
+	}{
+		{name: "bearer token", headers: map[string]string{"Authorization": "Bearer " + token}, want: true},
+		{name: "wrong bearer token", headers: map[string]string{"Authorization": "Bearer nope"}},
+		{name: "no credentials"},
+		{name: "signature", headers: signed(token, http.MethodPost, path, now, body), want: true},
+		{name: "lowercase method is signed the same", headers: signed(token, "post", path, now, body), want: true},
+		{name: "signature within the window", headers: signed(token, http.MethodPost, path, now.Add(-SignatureWindow+time.Second), body), want: true},
+		{name: "stale signature", headers: signed(token, http.MethodPost, path, now.Add(-SignatureWindow-time.Second), body)},
+		{name: "signature from the future", headers: signed(token, http.MethodPost, path, now.Add(SignatureWindow+time.Second), body)},
+		{name: "wrong key", headers: signed("other", http.MethodPost, path, now, body)},
+		{name: "signed for another path", headers: signed(token, http.MethodPost, "/v1/operations/restart", now, body)},
+		{name: "signed for another method", headers: signed(token, http.MethodGet, path, now, body)},
+		{name: "tampered body", body: []byte(`{"params":{"server":"lobby"}}`), headers: signed(token, http.MethodPost, path, now, body)},
+		{
+			name: "missing sha256 prefix",
+			headers: map[string]string{
+				"X-Mineos-Signature": Sign(token, http.MethodPost, path, now, body),
+				"X-Mineos-Timestamp": strconv.FormatInt(now.Unix(), 10),
+			},
+		},
+		{
+			name: "missing timestamp",
+			headers: map[string]string{
+				"X-Mineos-Signature": "sha256=" + Sign(token, http.MethodPost, path, now, body),
+			},
+		},
+		{
+			name: "signature is not hex",
+			headers: map[string]string{
+				"X-Mineos-Signature": "sha256=zz",
+				"X-Mineos-Timestamp": strconv.FormatInt(now.Unix(), 10),
+			},
+		},
+	}
+
+	s, err := NewServer(Options{Token: token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return now }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody := tt.body
+			if reqBody == nil {
+				reqBody = body
+			}
+			r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(string(reqBody)))
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+			if got := s.authorized(r, reqBody); got != tt.want {
+				t.Fatalf("authorized = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
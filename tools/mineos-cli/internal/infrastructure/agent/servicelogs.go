@@ -0,0 +1,206 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServiceLogCapture follows the compose logs of every service into
+// <Dir>/<service>.log, so they outlive containers that are recreated. A file
+// is rotated to .log.1 once it reaches MaxBytes, keeping Keep old files.
+type ServiceLogCapture struct {
+	Dir      string
+	MaxBytes int64
+	Keep     int
+	// Services lists the compose services; it is re-read every minute so
+	// services added by an update are picked up.
+	Services func(ctx context.Context) ([]string, error)
+	// Follow passes each timestamped log line ("<RFC 3339> <message>") of a
+	// service's container after since, or all of them for a zero since, to
+	// onLine until the container stops or ctx is cancelled.
+	Follow  func(ctx context.Context, service string, since time.Time, onLine func(string)) error
+	OnEvent func(message string)
+
+	mu sync.Mutex
+}
+
+// Run captures until ctx is cancelled.
+func (c *ServiceLogCapture) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	following := map[string]bool{}
+	lastError := ""
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		services, err := c.Services(ctx)
+		switch {
+		case ctx.Err() != nil:
+		case err != nil && err.Error() != lastError:
+			lastError = err.Error()
+			c.event("failed to list services: " + err.Error())
+		case err == nil:
+			lastError = ""
+			for _, service := range services {
+				if following[service] {
+					continue
+				}
+				following[service] = true
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					c.follow(ctx, service)
+				}()
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// follow re-attaches after the container stops or is recreated, resuming
+// after the last line in the file so nothing is written twice.
+func (c *ServiceLogCapture) follow(ctx context.Context, service string) {
+	file := &rotatingLog{path: filepath.Join(c.Dir, service+".log"), maxBytes: c.MaxBytes, keep: c.Keep}
+	defer file.Close()
+	last := lastLogStamp(file.path)
+	lastError := ""
+	for {
+		err := c.Follow(ctx, service, last, func(line string) {
+			stamp, ok := logStamp(line)
+			if ok && !stamp.After(last) {
+				return
+			}
+			if werr := file.WriteLine(line); werr != nil {
+				c.event(fmt.Sprintf("%s: %v", service, werr))
+				return
+			}
+			if ok {
+				last = stamp
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err != nil && err.Error() != lastError:
+			lastError = err.Error()
+			c.event(fmt.Sprintf("failed to follow %s: %v", service, err))
+		case err == nil && lastError != "":
+			lastError = ""
+			c.event("following " + service + " again")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (c *ServiceLogCapture) event(message string) {
+	if c.OnEvent == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.OnEvent(message)
+}
+
+func logStamp(line string) (time.Time, bool) {
+	field, _, _ := strings.Cut(line, " ")
+	stamp, err := time.Parse(time.RFC3339Nano, field)
+	return stamp, err == nil
+}
+
+// lastLogStamp is the time of the last line in a capture file, or zero.
+func lastLogStamp(path string) time.Time {
+	file, err := os.Open(path)
+	if err != nil {
+		return time.Time{}
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return time.Time{}
+	}
+	offset := info.Size() - 64*1024
+	if offset < 0 {
+		offset = 0
+	}
+	tail, err := io.ReadAll(io.NewSectionReader(file, offset, info.Size()-offset))
+	if err != nil {
+		return time.Time{}
+	}
+	lines := strings.Split(strings.TrimRight(string(tail), "\n"), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if stamp, ok := logStamp(lines[i]); ok {
+			return stamp
+		}
+	}
+	return time.Time{}
+}
+
+// rotatingLog appends lines to path, moving it to path.1 (and path.1 to
+// path.2, up to keep) when the next line would take it past maxBytes.
+type rotatingLog struct {
+	path     string
+	maxBytes int64
+	keep     int
+
+	file *os.File
+	size int64
+}
+
+func (r *rotatingLog) WriteLine(line string) error {
+	if r.file == nil {
+		if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return err
+		}
+		r.file, r.size = file, info.Size()
+	}
+	if r.maxBytes > 0 && r.size > 0 && r.size+int64(len(line))+1 > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+		return r.WriteLine(line)
+	}
+	n, err := io.WriteString(r.file, line+"\n")
+	r.size += int64(n)
+	return err
+}
+
+func (r *rotatingLog) rotate() error {
+	r.Close()
+	keep := max(r.keep, 1)
+	_ = os.Remove(fmt.Sprintf("%s.%d", r.path, keep))
+	for i := keep - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	return os.Rename(r.path, r.path+".1")
+}
+
+func (r *rotatingLog) Close() {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
@@ -0,0 +1,181 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Files exchanged with the API in the data directory. The API writes the
+// request marker; the agent claims it and reports progress in the status
+// file, which the web UI polls.
+const (
+	UpdateRequestFile = "update-request.json"
+	UpdateStatusFile  = "update-status.json"
+	claimedSuffix     = ".claimed"
+)
+
+// Update states written to the status file.
+const (
+	UpdateStateAccepted  = "accepted"
+	UpdateStateRunning   = "running"
+	UpdateStateCompleted = "completed"
+	UpdateStateFailed    = "failed"
+)
+
+type UpdateRequest struct {
+	ID          string    `json:"id"`
+	RequestedAt time.Time `json:"requestedAt"`
+	RequestedBy string    `json:"requestedBy,omitempty"`
+}
+
+type UpdateStatus struct {
+	RequestID  string     `json:"requestId"`
+	State      string     `json:"state"`
+	Step       string     `json:"step,omitempty"`
+	Message    string     `json:"message,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// UpdateFunc performs the update, calling step as each phase begins.
+type UpdateFunc func(ctx context.Context, step func(name, message string)) error
+
+type UpdateWatcher struct {
+	Dir      string
+	Interval time.Duration
+	Update   UpdateFunc
+	Audit    *AuditLog
+	// Exclusive serialises updates with other agent runs when set.
+	Exclusive func(func())
+	// OnEvent receives human-readable progress for the agent's console.
+	OnEvent func(message string)
+}
+
+// Watch polls for the request marker until ctx is cancelled.
+func (w *UpdateWatcher) Watch(ctx context.Context) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// A marker claimed by a previous agent that died mid-update is retried.
+	w.check(ctx, filepath.Join(w.Dir, UpdateRequestFile+claimedSuffix))
+	for {
+		w.check(ctx, filepath.Join(w.Dir, UpdateRequestFile))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *UpdateWatcher) check(ctx context.Context, marker string) {
+	if _, err := os.Stat(marker); err != nil {
+		return
+	}
+	claimed := filepath.Join(w.Dir, UpdateRequestFile+claimedSuffix)
+	if marker != claimed {
+		if err := os.Rename(marker, claimed); err != nil {
+			w.event("failed to claim update request: " + err.Error())
+			return
+		}
+	}
+
+	var req UpdateRequest
+	if data, err := os.ReadFile(claimed); err == nil {
+		_ = json.Unmarshal(data, &req)
+	}
+	if req.ID == "" {
+		req.ID = time.Now().UTC().Format("20060102T150405Z")
+	}
+
+	run := func() { w.process(ctx, req) }
+	if w.Exclusive != nil {
+		w.Exclusive(run)
+	} else {
+		run()
+	}
+	_ = os.Remove(claimed)
+}
+
+func (w *UpdateWatcher) process(ctx context.Context, req UpdateRequest) {
+	status := UpdateStatus{RequestID: req.ID, State: UpdateStateAccepted, StartedAt: time.Now().UTC()}
+	w.write(&status)
+	w.Audit.Record(AuditEvent{Event: "update-accepted", Remote: req.RequestedBy, RunID: req.ID})
+	w.event("update requested" + requestedBy(req))
+
+	status.State = UpdateStateRunning
+	err := w.Update(ctx, func(name, message string) {
+		status.Step = name
+		status.Message = message
+		w.write(&status)
+		w.event(message)
+	})
+
+	finished := time.Now().UTC()
+	status.FinishedAt = &finished
+	status.State = UpdateStateCompleted
+	status.Message = "Update complete"
+	result := "completed"
+	if err != nil {
+		status.State = UpdateStateFailed
+		status.Error = err.Error()
+		status.Message = "Update failed"
+		result = "failed"
+	}
+	w.write(&status)
+	if err != nil {
+		w.event(status.Message + ": " + status.Error)
+	} else {
+		w.event(status.Message)
+	}
+	w.Audit.Record(AuditEvent{
+		Event:      "update-finished",
+		Remote:     req.RequestedBy,
+		RunID:      req.ID,
+		Status:     result,
+		DurationMs: finished.Sub(status.StartedAt).Milliseconds(),
+		Error:      status.Error,
+	})
+}
+
+// write replaces the status file atomically so readers never see a
+// partial document.
+func (w *UpdateWatcher) write(status *UpdateStatus) {
+	status.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return
+	}
+	path := filepath.Join(w.Dir, UpdateStatusFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		w.event("failed to write update status: " + err.Error())
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		w.event("failed to write update status: " + err.Error())
+	}
+}
+
+func (w *UpdateWatcher) event(message string) {
+	if w.OnEvent != nil {
+		w.OnEvent(message)
+	}
+}
+
+func requestedBy(req UpdateRequest) string {
+	if req.RequestedBy == "" {
+		return ""
+	}
+	return " by " + req.RequestedBy
+}
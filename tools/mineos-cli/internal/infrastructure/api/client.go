@@ -15,6 +15,7 @@ import (
 
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/ratelimit"
 )
 
 type Client struct {
@@ -27,13 +28,10 @@ type Client struct {
 var (
 	ErrApiKeyMissing = errors.New("api key missing; set MINEOS_API_KEY in .env or provide ApiKey__StaticKey")
 	ErrApiKeyInvalid = errors.New("invalid API key")
+	ErrNotFound      = ports.ErrNotFound
+	ErrConflict      = ports.ErrConflict
 )
 
-type LogEntry struct {
-	Timestamp time.Time `json:"timestamp"`
-	Message   string    `json:"message"`
-}
-
 func NewClient(baseURL, apiKey string) *Client {
 	base := strings.TrimRight(baseURL, "/")
 	return &Client{
@@ -44,13 +42,18 @@ func NewClient(baseURL, apiKey string) *Client {
 	}
 }
 
-func NewClientFromConfig(cfg config.Config) *Client {
-	apiPort := cfg.ApiPort
-	if apiPort == "" {
-		apiPort = "5078"
+// SetRateLimit caps uploads and downloads through this client with l,
+// shared with whatever else l limits; nil removes the limit. Limited
+// transfers get a longer timeout.
+func (c *Client) SetRateLimit(l *ratelimit.Limiter) {
+	c.httpClient.Transport = ratelimit.Transport(nil, l)
+	if l != nil {
+		c.httpClient.Timeout = 10 * time.Minute
 	}
-	base := "http://localhost:" + apiPort
-	return NewClient(base, cfg.EffectiveApiKey())
+}
+
+func NewClientFromConfig(cfg config.Config) *Client {
+	return NewClient(cfg.ApiURL(), cfg.EffectiveApiKey())
 }
 
 func (c *Client) Health(ctx context.Context) error {
@@ -91,7 +94,7 @@ func (c *Client) ListServers(ctx context.Context) ([]ports.Server, error) {
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("list servers failed: %s", readBody(resp.Body))
+		return nil, responseError("list servers", resp)
 	}
 
 	var servers []ports.Server
@@ -126,7 +129,7 @@ func (c *Client) StopAll(ctx context.Context, timeoutSeconds int) (ports.StopAll
 		return ports.StopAllResult{}, ErrApiKeyInvalid
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return ports.StopAllResult{}, fmt.Errorf("stop-all failed: %s", readBody(resp.Body))
+		return ports.StopAllResult{}, responseError("stop-all", resp)
 	}
 
 	var result ports.StopAllResult
@@ -152,6 +155,9 @@ func (c *Client) ServerActionWithTimeout(ctx context.Context, name, action strin
 	}
 
 	url := fmt.Sprintf("%s/servers/%s/actions/%s", c.apiBaseURL, url.PathEscape(strings.TrimSpace(name)), url.PathEscape(strings.TrimSpace(action)))
+	if timeoutSeconds > 0 {
+		url += fmt.Sprintf("?timeoutSeconds=%d", timeoutSeconds)
+	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
 	if err != nil {
 		return err
@@ -176,8 +182,11 @@ func (c *Client) ServerActionWithTimeout(ctx context.Context, name, action strin
 	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
 		return ErrApiKeyInvalid
 	}
+	if resp.StatusCode == http.StatusRequestTimeout {
+		return fmt.Errorf("%s %s: %w", action, name, ports.ErrStopTimeout)
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("server action failed: %s", readBody(resp.Body))
+		return responseError("server action", resp)
 	}
 
 	return nil
@@ -217,14 +226,14 @@ func (c *Client) SendConsoleCommand(ctx context.Context, name, command string) e
 		return ErrApiKeyInvalid
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("console command failed: %s", readBody(resp.Body))
+		return responseError("console command", resp)
 	}
 
 	return nil
 }
 
-func (c *Client) StreamConsoleLogs(ctx context.Context, name, source string) (<-chan LogEntry, <-chan error) {
-	logs := make(chan LogEntry)
+func (c *Client) StreamConsoleLogs(ctx context.Context, name, source string) (<-chan ports.LogEntry, <-chan error) {
+	logs := make(chan ports.LogEntry)
 	errs := make(chan error, 1)
 
 	go func() {
@@ -269,7 +278,7 @@ func (c *Client) StreamConsoleLogs(ctx context.Context, name, source string) (<-
 			return
 		}
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			errs <- fmt.Errorf("stream logs failed: %s", readBody(resp.Body))
+			errs <- responseError("stream logs", resp)
 			return
 		}
 
@@ -284,7 +293,7 @@ func (c *Client) StreamConsoleLogs(ctx context.Context, name, source string) (<-
 			if payload == "" {
 				continue
 			}
-			var entry LogEntry
+			var entry ports.LogEntry
 			if err := json.Unmarshal([]byte(payload), &entry); err != nil {
 				continue
 			}
@@ -303,6 +312,88 @@ func (c *Client) StreamConsoleLogs(ctx context.Context, name, source string) (<-
 	return logs, errs
 }
 
+// getJSON performs an authenticated GET against the v1 API and decodes the
+// JSON response into out. The label is used to prefix error messages.
+func (c *Client) getJSON(ctx context.Context, path, label string, out any) error {
+	return c.sendJSON(ctx, http.MethodGet, path, label, nil, out)
+}
+
+// sendJSON performs an authenticated request against the v1 API. A non-nil
+// body is encoded as JSON; a non-nil out receives the decoded response.
+func (c *Client) sendJSON(ctx context.Context, method, path, label string, body, out any) error {
+	return c.sendJSONWithClient(ctx, c.httpClient, method, path, label, body, out)
+}
+
+func (c *Client) sendJSONWithClient(ctx context.Context, httpClient *http.Client, method, path, label string, body, out any) error {
+	if strings.TrimSpace(c.apiKey) == "" {
+		return ErrApiKeyMissing
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.apiBaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.do(httpClient, req, label, out)
+}
+
+// sendBytes posts a raw body, e.g. an image, with the given content type.
+func (c *Client) sendBytes(ctx context.Context, method, path, label, contentType string, body []byte, out any) error {
+	if strings.TrimSpace(c.apiKey) == "" {
+		return ErrApiKeyMissing
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.apiBaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.do(c.httpClient, req, label, out)
+}
+
+func (c *Client) do(httpClient *http.Client, req *http.Request, label string, out any) error {
+	req.Header.Set("X-Api-Key", c.apiKey)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+		return ErrApiKeyInvalid
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return responseError(label, resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// responseError reports a failed request; missing resources and conflicts
+// wrap ErrNotFound and ErrConflict so callers can tell them apart.
+func responseError(label string, resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%s failed: %w: %s", label, ErrNotFound, readBody(resp.Body))
+	case http.StatusConflict:
+		return fmt.Errorf("%s failed: %w: %s", label, ErrConflict, readBody(resp.Body))
+	}
+	return fmt.Errorf("%s failed: %s", label, readBody(resp.Body))
+}
+
 func readBody(reader io.Reader) string {
 	if reader == nil {
 		return ""
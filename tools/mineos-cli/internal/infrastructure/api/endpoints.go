@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Endpoint is an API route, as the OpenAPI document names it.
+type Endpoint struct {
+	Method string
+	Path   string
+}
+
+// Key identifies the route whatever its parameters are called, e.g.
+// "GET /api/v1/servers/{}".
+func (e Endpoint) Key() string {
+	return strings.ToUpper(e.Method) + " " + pathParameter.ReplaceAllString(strings.TrimRight(e.Path, "/"), "{}")
+}
+
+var pathParameter = regexp.MustCompile(`\{[^}]*\}`)
+
+// Endpoints are the routes this client calls. A method that calls a new
+// route adds it here, so that 'mineos api coverage' can report what the CLI
+// wraps and which of its routes the API no longer serves.
+var Endpoints = []Endpoint{
+	{http.MethodGet, "/api/v1/health"},
+	{http.MethodGet, "/api/v1/jobs/{id}"},
+	{http.MethodPut, "/api/v1/settings/{key}"},
+
+	{http.MethodGet, "/api/v1/servers/list"},
+	{http.MethodPost, "/api/v1/servers"},
+	{http.MethodPost, "/api/v1/servers/actions/stop-all"},
+	{http.MethodGet, "/api/v1/servers/{name}"},
+	{http.MethodDelete, "/api/v1/servers/{name}"},
+	{http.MethodPost, "/api/v1/servers/{name}/actions/{action}"},
+	{http.MethodPost, "/api/v1/servers/{name}/eula"},
+	{http.MethodPost, "/api/v1/servers/{name}/icon"},
+	{http.MethodPost, "/api/v1/servers/{name}/console"},
+	{http.MethodGet, "/api/v1/servers/{name}/console/stream"},
+
+	{http.MethodGet, "/api/v1/servers/{name}/backups"},
+	{http.MethodPost, "/api/v1/servers/{name}/backups"},
+	{http.MethodPost, "/api/v1/servers/{name}/backups/restore"},
+
+	{http.MethodGet, "/api/v1/servers/{name}/files/{path}"},
+	{http.MethodPost, "/api/v1/servers/{name}/files/{path}"},
+	{http.MethodPut, "/api/v1/servers/{name}/files/{path}"},
+
+	{http.MethodGet, "/api/v1/servers/{name}/server-properties"},
+	{http.MethodPut, "/api/v1/servers/{name}/server-properties"},
+	{http.MethodGet, "/api/v1/servers/{name}/server-config"},
+	{http.MethodPut, "/api/v1/servers/{name}/server-config"},
+	{http.MethodGet, "/api/v1/servers/{name}/environment"},
+	{http.MethodPut, "/api/v1/servers/{name}/environment"},
+	{http.MethodGet, "/api/v1/servers/{name}/integrity"},
+
+	{http.MethodGet, "/api/v1/servers/{name}/performance/realtime"},
+	{http.MethodGet, "/api/v1/servers/{name}/performance/history"},
+	{http.MethodGet, "/api/v1/servers/{name}/memory"},
+	{http.MethodGet, "/api/v1/servers/{name}/worlds"},
+	{http.MethodGet, "/api/v1/servers/{name}/ping"},
+
+	{http.MethodGet, "/api/v1/servers/{name}/players"},
+	{http.MethodGet, "/api/v1/servers/{name}/players/sessions"},
+	{http.MethodGet, "/api/v1/servers/{name}/players/{uuid}/sessions"},
+	{http.MethodPost, "/api/v1/servers/{name}/players/activity/process"},
+
+	{http.MethodGet, "/api/v1/servers/{name}/plugins"},
+	{http.MethodPost, "/api/v1/servers/{name}/plugins/upload"},
+	{http.MethodDelete, "/api/v1/servers/{name}/plugins/{fileName}"},
+	{http.MethodGet, "/api/v1/servers/{name}/plugins/modrinth/search"},
+	{http.MethodGet, "/api/v1/servers/{name}/plugins/modrinth/project/{projectId}/versions"},
+	{http.MethodPost, "/api/v1/servers/{name}/plugins/modrinth/install"},
+	{http.MethodGet, "/api/v1/servers/{name}/mods"},
+	{http.MethodDelete, "/api/v1/servers/{name}/mods/{fileName}"},
+	{http.MethodGet, "/api/v1/servers/{name}/mods/modrinth/search"},
+	{http.MethodGet, "/api/v1/servers/{name}/mods/modrinth/project/{projectId}/versions"},
+	{http.MethodPost, "/api/v1/servers/{name}/mods/modrinth/install"},
+
+	{http.MethodGet, "/api/v1/host/profiles"},
+	{http.MethodPost, "/api/v1/host/profiles/{id}/download"},
+	{http.MethodPost, "/api/v1/host/profiles/{id}/copy-to-server"},
+	{http.MethodGet, "/api/v1/host/profiles/custom"},
+	{http.MethodPost, "/api/v1/host/profiles/custom"},
+	{http.MethodDelete, "/api/v1/host/profiles/custom/{id}"},
+
+	{http.MethodPost, "/api/v1/host/imports/upload"},
+	{http.MethodPost, "/api/v1/host/imports/{filename}/create-server"},
+	{http.MethodDelete, "/api/v1/host/imports/{filename}"},
+
+	{http.MethodGet, "/api/v1/host/quarantine"},
+	{http.MethodPost, "/api/v1/host/quarantine/{id}/release"},
+	{http.MethodDelete, "/api/v1/host/quarantine/{id}"},
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// ReadServerFile returns the text content of a file inside a server directory.
+// Missing files yield an error wrapping ErrNotFound.
+func (c *Client) ReadServerFile(ctx context.Context, name, filePath string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", errors.New("server name is required")
+	}
+	var result ports.FileBrowseResult
+	if err := c.getJSON(ctx, serverFilePath(name, filePath), "read file", &result); err != nil {
+		return "", err
+	}
+	if result.Kind != "file" || result.File == nil {
+		return "", fmt.Errorf("read file failed: %s is not a file", filePath)
+	}
+	return result.File.Content, nil
+}
+
+// ListServerFiles returns the entries of a directory inside a server
+// directory. Missing directories yield an error wrapping ErrNotFound.
+func (c *Client) ListServerFiles(ctx context.Context, name, dir string) ([]ports.FileEntry, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("server name is required")
+	}
+	var result ports.FileBrowseResult
+	if err := c.getJSON(ctx, serverFilePath(name, dir), "list files", &result); err != nil {
+		return nil, err
+	}
+	if result.Kind != "directory" {
+		return nil, fmt.Errorf("list files failed: %s is not a directory", dir)
+	}
+	return result.Entries, nil
+}
+
+// WriteServerFile creates or replaces a text file inside a server directory.
+func (c *Client) WriteServerFile(ctx context.Context, name, filePath, content string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	return c.sendJSON(ctx, http.MethodPut, serverFilePath(name, filePath), "write file", map[string]string{"content": content}, nil)
+}
+
+// WriteServerFileBytes creates or replaces a binary file inside a server
+// directory.
+func (c *Client) WriteServerFileBytes(ctx context.Context, name, filePath string, content []byte) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	body := map[string]string{"contentBase64": base64.StdEncoding.EncodeToString(content)}
+	return c.sendJSON(ctx, http.MethodPost, serverFilePath(name, filePath), "write file", body, nil)
+}
+
+func serverFilePath(name, filePath string) string {
+	segments := strings.Split(strings.Trim(filePath, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf("/servers/%s/files/%s", url.PathEscape(strings.TrimSpace(name)), strings.Join(segments, "/"))
+}
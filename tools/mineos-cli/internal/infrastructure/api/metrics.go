@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+func (c *Client) GetPerformance(ctx context.Context, name string) (ports.PerformanceSample, error) {
+	if strings.TrimSpace(name) == "" {
+		return ports.PerformanceSample{}, errors.New("server name is required")
+	}
+	var sample ports.PerformanceSample
+	path := fmt.Sprintf("/servers/%s/performance/realtime", url.PathEscape(strings.TrimSpace(name)))
+	if err := c.getJSON(ctx, path, "performance", &sample); err != nil {
+		return ports.PerformanceSample{}, err
+	}
+	return sample, nil
+}
+
+func (c *Client) GetPerformanceHistory(ctx context.Context, name string, minutes int) ([]ports.PerformanceSample, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("server name is required")
+	}
+	path := fmt.Sprintf("/servers/%s/performance/history", url.PathEscape(strings.TrimSpace(name)))
+	if minutes > 0 {
+		path += fmt.Sprintf("?minutes=%d", minutes)
+	}
+	var history []ports.PerformanceSample
+	if err := c.getJSON(ctx, path, "performance history", &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (c *Client) GetMemory(ctx context.Context, name string) (ports.MemoryInfo, error) {
+	if strings.TrimSpace(name) == "" {
+		return ports.MemoryInfo{}, errors.New("server name is required")
+	}
+	var memory ports.MemoryInfo
+	path := fmt.Sprintf("/servers/%s/memory", url.PathEscape(strings.TrimSpace(name)))
+	if err := c.getJSON(ctx, path, "memory", &memory); err != nil {
+		return ports.MemoryInfo{}, err
+	}
+	return memory, nil
+}
+
+func (c *Client) ListWorlds(ctx context.Context, name string) ([]ports.World, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("server name is required")
+	}
+	var worlds []ports.World
+	path := fmt.Sprintf("/servers/%s/worlds", url.PathEscape(strings.TrimSpace(name)))
+	if err := c.getJSON(ctx, path, "list worlds", &worlds); err != nil {
+		return nil, err
+	}
+	return worlds, nil
+}
+
+// Ping queries the server over the Minecraft status protocol. ErrNotFound
+// means the server is offline or unreachable.
+func (c *Client) Ping(ctx context.Context, name string) (ports.PingInfo, error) {
+	if strings.TrimSpace(name) == "" {
+		return ports.PingInfo{}, errors.New("server name is required")
+	}
+	var info ports.PingInfo
+	path := fmt.Sprintf("/servers/%s/ping", url.PathEscape(strings.TrimSpace(name)))
+	if err := c.getJSON(ctx, path, "ping", &info); err != nil {
+		return ports.PingInfo{}, err
+	}
+	return info, nil
+}
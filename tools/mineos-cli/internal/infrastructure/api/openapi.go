@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// SpecPath is where the API serves its OpenAPI document.
+const SpecPath = "/swagger/v1/swagger.json"
+
+// Operation is one method on one path of the OpenAPI document.
+type Operation struct {
+	Endpoint
+	Summary    string
+	Tags       []string
+	Deprecated bool
+}
+
+// FetchSpec downloads the API's OpenAPI document. It is served without
+// authentication.
+func (c *Client) FetchSpec(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+SpecPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, responseError("fetch OpenAPI document", resp)
+	}
+	var data json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("fetch OpenAPI document: %w", err)
+	}
+	return data, nil
+}
+
+var specMethods = []string{"get", "put", "post", "delete", "patch", "head", "options"}
+
+// ParseSpec lists the operations of an OpenAPI document, sorted by path and
+// method.
+func ParseSpec(data []byte) ([]Operation, error) {
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Swagger string                                `json:"swagger"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("read OpenAPI document: %w", err)
+	}
+	if doc.OpenAPI == "" && doc.Swagger == "" {
+		return nil, fmt.Errorf("read OpenAPI document: no openapi or swagger version")
+	}
+
+	var operations []Operation
+	for path, item := range doc.Paths {
+		for _, method := range specMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op struct {
+				Summary    string   `json:"summary"`
+				Tags       []string `json:"tags"`
+				Deprecated bool     `json:"deprecated"`
+			}
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("read OpenAPI document: %s %s: %w", strings.ToUpper(method), path, err)
+			}
+			operations = append(operations, Operation{
+				Endpoint:   Endpoint{Method: strings.ToUpper(method), Path: path},
+				Summary:    op.Summary,
+				Tags:       op.Tags,
+				Deprecated: op.Deprecated,
+			})
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Path != operations[j].Path {
+			return operations[i].Path < operations[j].Path
+		}
+		return operations[i].Method < operations[j].Method
+	})
+	return operations, nil
+}
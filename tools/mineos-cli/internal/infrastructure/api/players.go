@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// ListPlayers returns the players a server knows about.
+func (c *Client) ListPlayers(ctx context.Context, name string) ([]ports.PlayerSummary, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("server name is required")
+	}
+	var result struct {
+		Data []ports.PlayerSummary `json:"data"`
+	}
+	if err := c.getJSON(ctx, playersPath(name), "list players", &result); err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// ListPlayerSessions returns the most recent sessions on a server, newest
+// first. A non-empty uuid limits the result to one player.
+func (c *Client) ListPlayerSessions(ctx context.Context, name, uuid string, limit int) ([]ports.PlayerSession, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("server name is required")
+	}
+	path := playersPath(name) + "/sessions"
+	if uuid != "" {
+		path = playersPath(name) + "/" + url.PathEscape(uuid) + "/sessions"
+	}
+	if limit > 0 {
+		path += fmt.Sprintf("?limit=%d", limit)
+	}
+	var result struct {
+		Data []ports.PlayerSession `json:"data"`
+	}
+	if err := c.getJSON(ctx, path, "player sessions", &result); err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// ProcessPlayerActivity asks the API to scan the server's latest.log for
+// joins and leaves it has not recorded yet.
+func (c *Client) ProcessPlayerActivity(ctx context.Context, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	return c.sendJSON(ctx, http.MethodPost, playersPath(name)+"/activity/process", "process player activity", nil, nil)
+}
+
+func playersPath(name string) string {
+	return fmt.Sprintf("/servers/%s/players", url.PathEscape(strings.TrimSpace(name)))
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// ListPlugins returns the jars in a server's plugins directory.
+func (c *Client) ListPlugins(ctx context.Context, name string) ([]ports.InstalledPlugin, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("server name is required")
+	}
+	var plugins []ports.InstalledPlugin
+	if err := c.getJSON(ctx, pluginsPath(name), "list plugins", &plugins); err != nil {
+		return nil, err
+	}
+	return plugins, nil
+}
+
+// UploadPlugin stores a plugin jar in a server's plugins directory,
+// replacing a jar with the same file name.
+func (c *Client) UploadPlugin(ctx context.Context, name, fileName string, jar []byte) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(jar); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+	return c.sendBytes(ctx, http.MethodPost, pluginsPath(name)+"/upload", "upload plugin", form.FormDataContentType(), body.Bytes(), nil)
+}
+
+func pluginsPath(name string) string {
+	return fmt.Sprintf("/servers/%s/plugins", url.PathEscape(strings.TrimSpace(name)))
+}
+
+// ListMods returns the jars in a server's mods directory.
+func (c *Client) ListMods(ctx context.Context, name string) ([]ports.InstalledMod, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("server name is required")
+	}
+	var mods []ports.InstalledMod
+	path := fmt.Sprintf("/servers/%s/mods", url.PathEscape(strings.TrimSpace(name)))
+	if err := c.getJSON(ctx, path, "list mods", &mods); err != nil {
+		return nil, err
+	}
+	return mods, nil
+}
+
+// SearchModrinth searches Modrinth through the API, which filters by the
+// server's loader and Minecraft version. kind is "mods" or "plugins".
+func (c *Client) SearchModrinth(ctx context.Context, name, kind, query string) ([]ports.ModrinthProject, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("server name is required")
+	}
+	var result struct {
+		Results []ports.ModrinthProject `json:"results"`
+	}
+	path := fmt.Sprintf("/servers/%s/%s/modrinth/search?query=%s", url.PathEscape(strings.TrimSpace(name)), kind, url.QueryEscape(query))
+	if err := c.getJSON(ctx, path, "modrinth search", &result); err != nil {
+		return nil, err
+	}
+	return result.Results, nil
+}
+
+// ModrinthVersions lists a project's versions compatible with the server,
+// newest first.
+func (c *Client) ModrinthVersions(ctx context.Context, name, kind, projectID string) ([]ports.ModrinthVersion, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("server name is required")
+	}
+	var versions []ports.ModrinthVersion
+	path := fmt.Sprintf("/servers/%s/%s/modrinth/project/%s/versions", url.PathEscape(strings.TrimSpace(name)), kind, url.PathEscape(projectID))
+	if err := c.getJSON(ctx, path, "modrinth versions", &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// InstallModrinth has the API download the primary file of a Modrinth
+// version into the server. kind is "mods" or "plugins".
+func (c *Client) InstallModrinth(ctx context.Context, name, kind, versionID string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	path := fmt.Sprintf("/servers/%s/%s/modrinth/install", url.PathEscape(strings.TrimSpace(name)), kind)
+	return c.sendJSON(ctx, http.MethodPost, path, "modrinth install", map[string]string{"versionId": versionID}, nil)
+}
+
+// DeleteJar removes a jar from a server's mods or plugins directory.
+func (c *Client) DeleteJar(ctx context.Context, name, kind, fileName string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	path := fmt.Sprintf("/servers/%s/%s/%s", url.PathEscape(strings.TrimSpace(name)), kind, url.PathEscape(fileName))
+	return c.sendJSON(ctx, http.MethodDelete, path, "delete "+strings.TrimSuffix(kind, "s"), nil, nil)
+}
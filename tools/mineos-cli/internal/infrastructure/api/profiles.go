@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+func (c *Client) ListProfiles(ctx context.Context) ([]ports.Profile, error) {
+	var profiles []ports.Profile
+	if err := c.getJSON(ctx, "/host/profiles", "list profiles", &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// DownloadProfile fetches a profile's server jar into the host profile cache.
+func (c *Client) DownloadProfile(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return errors.New("profile id is required")
+	}
+	path := fmt.Sprintf("/host/profiles/%s/download", url.PathEscape(strings.TrimSpace(id)))
+	// The API downloads the jar before responding, which can take a while.
+	downloadClient := &http.Client{Timeout: 10 * time.Minute}
+	return c.sendJSONWithClient(ctx, downloadClient, http.MethodPost, path, "download profile", nil, nil)
+}
+
+func (c *Client) CopyProfileToServer(ctx context.Context, id, serverName string) error {
+	if strings.TrimSpace(id) == "" {
+		return errors.New("profile id is required")
+	}
+	if strings.TrimSpace(serverName) == "" {
+		return errors.New("server name is required")
+	}
+	path := fmt.Sprintf("/host/profiles/%s/copy-to-server", url.PathEscape(strings.TrimSpace(id)))
+	return c.sendJSON(ctx, http.MethodPost, path, "copy profile", map[string]string{"serverName": strings.TrimSpace(serverName)}, nil)
+}
+
+func (c *Client) ListCustomProfiles(ctx context.Context) ([]ports.CustomProfile, error) {
+	var profiles []ports.CustomProfile
+	if err := c.getJSON(ctx, "/host/profiles/custom", "list custom profiles", &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// UploadCustomProfile streams a server jar to the host profile cache as
+// profile "custom-<name>". The upload has no timeout of its own; ctx bounds
+// it.
+func (c *Client) UploadCustomProfile(ctx context.Context, upload ports.CustomProfileUpload, jar io.Reader) (ports.CustomProfile, error) {
+	if strings.TrimSpace(c.apiKey) == "" {
+		return ports.CustomProfile{}, ErrApiKeyMissing
+	}
+	query := url.Values{}
+	query.Set("name", strings.TrimSpace(upload.Name))
+	query.Set("software", strings.TrimSpace(upload.Software))
+	query.Set("version", strings.TrimSpace(upload.Version))
+	if upload.JavaVersion > 0 {
+		query.Set("java", strconv.Itoa(upload.JavaVersion))
+	}
+	if description := strings.TrimSpace(upload.Description); description != "" {
+		query.Set("description", description)
+	}
+	if upload.Replace {
+		query.Set("replace", "true")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiBaseURL+"/host/profiles/custom?"+query.Encode(), jar)
+	if err != nil {
+		return ports.CustomProfile{}, err
+	}
+	req.Header.Set("Content-Type", "application/java-archive")
+	var profile ports.CustomProfile
+	if err := c.do(&http.Client{Transport: c.httpClient.Transport}, req, "upload custom profile", &profile); err != nil {
+		return ports.CustomProfile{}, err
+	}
+	return profile, nil
+}
+
+func (c *Client) DeleteCustomProfile(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return errors.New("profile id is required")
+	}
+	path := "/host/profiles/custom/" + url.PathEscape(strings.TrimSpace(id))
+	return c.sendJSON(ctx, http.MethodDelete, path, "delete custom profile", nil, nil)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+func (c *Client) GetServerProperties(ctx context.Context, name string) (map[string]string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("server name is required")
+	}
+	properties := map[string]string{}
+	path := fmt.Sprintf("/servers/%s/server-properties", url.PathEscape(strings.TrimSpace(name)))
+	if err := c.getJSON(ctx, path, "server properties", &properties); err != nil {
+		return nil, err
+	}
+	return properties, nil
+}
+
+// UpdateServerProperties replaces server.properties with the given map, so
+// callers should start from GetServerProperties and change only what they need.
+func (c *Client) UpdateServerProperties(ctx context.Context, name string, properties map[string]string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	path := fmt.Sprintf("/servers/%s/server-properties", url.PathEscape(strings.TrimSpace(name)))
+	return c.sendJSON(ctx, http.MethodPut, path, "update server properties", properties, nil)
+}
+
+func (c *Client) GetServerConfig(ctx context.Context, name string) (ports.ServerConfig, error) {
+	if strings.TrimSpace(name) == "" {
+		return ports.ServerConfig{}, errors.New("server name is required")
+	}
+	var cfg ports.ServerConfig
+	path := fmt.Sprintf("/servers/%s/server-config", url.PathEscape(strings.TrimSpace(name)))
+	if err := c.getJSON(ctx, path, "server config", &cfg); err != nil {
+		return ports.ServerConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (c *Client) UpdateServerConfig(ctx context.Context, name string, cfg ports.ServerConfig) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	path := fmt.Sprintf("/servers/%s/server-config", url.PathEscape(strings.TrimSpace(name)))
+	return c.sendJSON(ctx, http.MethodPut, path, "update server config", cfg, nil)
+}
+
+func (c *Client) GetServerEnvironment(ctx context.Context, name string) (ports.ServerEnvironment, error) {
+	if strings.TrimSpace(name) == "" {
+		return ports.ServerEnvironment{}, errors.New("server name is required")
+	}
+	var env ports.ServerEnvironment
+	path := fmt.Sprintf("/servers/%s/environment", url.PathEscape(strings.TrimSpace(name)))
+	if err := c.getJSON(ctx, path, "server environment", &env); err != nil {
+		return ports.ServerEnvironment{}, err
+	}
+	return env, nil
+}
+
+// UpdateServerEnvironment replaces the server's variables and system
+// properties, so callers should start from GetServerEnvironment.
+func (c *Client) UpdateServerEnvironment(ctx context.Context, name string, env ports.ServerEnvironment) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	path := fmt.Sprintf("/servers/%s/environment", url.PathEscape(strings.TrimSpace(name)))
+	return c.sendJSON(ctx, http.MethodPut, path, "update server environment", env, nil)
+}
+
+// GetServerFileHashes has the API hash the server jar, mods and plugins,
+// which takes a while for large mod folders.
+func (c *Client) GetServerFileHashes(ctx context.Context, name string) (ports.ServerIntegrity, error) {
+	if strings.TrimSpace(name) == "" {
+		return ports.ServerIntegrity{}, errors.New("server name is required")
+	}
+	var integrity ports.ServerIntegrity
+	path := fmt.Sprintf("/servers/%s/integrity", url.PathEscape(strings.TrimSpace(name)))
+	hashClient := &http.Client{Timeout: 5 * time.Minute, Transport: c.httpClient.Transport}
+	if err := c.sendJSONWithClient(ctx, hashClient, http.MethodGet, path, "hash server files", nil, &integrity); err != nil {
+		return ports.ServerIntegrity{}, err
+	}
+	return integrity, nil
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+func (c *Client) ListQuarantine(ctx context.Context) ([]ports.QuarantinedJar, error) {
+	var entries []ports.QuarantinedJar
+	if err := c.getJSON(ctx, "/host/quarantine", "list quarantine", &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ReleaseQuarantine moves a quarantined jar back to where it was installed.
+func (c *Client) ReleaseQuarantine(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return errors.New("quarantine id is required")
+	}
+	path := fmt.Sprintf("/host/quarantine/%s/release", url.PathEscape(strings.TrimSpace(id)))
+	return c.sendJSON(ctx, http.MethodPost, path, "release quarantined jar", nil, nil)
+}
+
+func (c *Client) DeleteQuarantine(ctx context.Context, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return errors.New("quarantine id is required")
+	}
+	path := fmt.Sprintf("/host/quarantine/%s", url.PathEscape(strings.TrimSpace(id)))
+	return c.sendJSON(ctx, http.MethodDelete, path, "delete quarantined jar", nil, nil)
+}
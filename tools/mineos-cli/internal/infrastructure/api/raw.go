@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RawRequest is a request to any API endpoint, for 'mineos api'.
+type RawRequest struct {
+	Method string
+	// Path is relative to /api/v1 unless it starts with /api/; a full URL
+	// must point at this client's API.
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// RawResponse is a response as the API sent it.
+type RawResponse struct {
+	Status string
+	Code   int
+	Header http.Header
+	Body   []byte
+}
+
+// ResolveURL turns a RawRequest path into the URL it is sent to.
+func (c *Client) ResolveURL(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if strings.Contains(path, "://") {
+		if path != c.baseURL && !strings.HasPrefix(path, c.baseURL+"/") {
+			return "", errors.New("only URLs on the MineOS API (" + c.baseURL + ") are accepted, so the API key is never sent elsewhere")
+		}
+		return path, nil
+	}
+	path = "/" + strings.TrimLeft(path, "/")
+	if path == "/api" || strings.HasPrefix(path, "/api/") {
+		return c.baseURL + path, nil
+	}
+	return c.apiBaseURL + path, nil
+}
+
+// Raw sends req with the API key and returns the response whatever its
+// status, except for a refused key, which is ErrApiKeyInvalid.
+func (c *Client) Raw(ctx context.Context, req RawRequest) (RawResponse, error) {
+	if strings.TrimSpace(c.apiKey) == "" {
+		return RawResponse{}, ErrApiKeyMissing
+	}
+	target, err := c.ResolveURL(req.Path)
+	if err != nil {
+		return RawResponse{}, err
+	}
+	if len(req.Query) > 0 {
+		parsed, err := url.Parse(target)
+		if err != nil {
+			return RawResponse{}, err
+		}
+		query := parsed.Query()
+		for key, values := range req.Query {
+			query[key] = values
+		}
+		parsed.RawQuery = query.Encode()
+		target = parsed.String()
+	}
+
+	var body io.Reader
+	if req.Body != nil {
+		body = bytes.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, target, body)
+	if err != nil {
+		return RawResponse{}, err
+	}
+	for key, values := range req.Header {
+		httpReq.Header[http.CanonicalHeaderKey(key)] = values
+	}
+	if req.Body != nil && httpReq.Header.Get("Content-Type") == "" {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("X-Api-Key", c.apiKey)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return RawResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+		return RawResponse{}, ErrApiKeyInvalid
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return RawResponse{}, err
+	}
+	return RawResponse{Status: resp.Status, Code: resp.StatusCode, Header: resp.Header, Body: data}, nil
+}
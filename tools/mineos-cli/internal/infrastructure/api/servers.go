@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// Servers are owned by the minecraft user (uid/gid 1000) in the API image.
+const defaultServerOwner = 1000
+
+func (c *Client) GetServer(ctx context.Context, name string) (ports.ServerDetail, error) {
+	if strings.TrimSpace(name) == "" {
+		return ports.ServerDetail{}, errors.New("server name is required")
+	}
+	var detail ports.ServerDetail
+	path := fmt.Sprintf("/servers/%s", url.PathEscape(strings.TrimSpace(name)))
+	if err := c.getJSON(ctx, path, "get server", &detail); err != nil {
+		return ports.ServerDetail{}, err
+	}
+	return detail, nil
+}
+
+func (c *Client) CreateServer(ctx context.Context, name, serverType string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	if strings.TrimSpace(serverType) == "" {
+		serverType = "java"
+	}
+	body := map[string]any{
+		"name":       strings.TrimSpace(name),
+		"ownerUid":   defaultServerOwner,
+		"ownerGid":   defaultServerOwner,
+		"serverType": serverType,
+	}
+	return c.sendJSON(ctx, http.MethodPost, "/servers", "create server", body, nil)
+}
+
+func (c *Client) AcceptEula(ctx context.Context, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	path := fmt.Sprintf("/servers/%s/eula", url.PathEscape(strings.TrimSpace(name)))
+	return c.sendJSON(ctx, http.MethodPost, path, "accept eula", nil, nil)
+}
+
+// ImportServer queues creation of a server from an archive in the host import
+// directory and returns the background job id. Mod and plugin jars the API's
+// scanner flags are quarantined unless allowFlagged is set.
+func (c *Client) ImportServer(ctx context.Context, filename, serverName string, allowFlagged bool) (string, error) {
+	if strings.TrimSpace(filename) == "" {
+		return "", errors.New("import filename is required")
+	}
+	if strings.TrimSpace(serverName) == "" {
+		return "", errors.New("server name is required")
+	}
+	var result struct {
+		JobId string `json:"jobId"`
+	}
+	path := fmt.Sprintf("/host/imports/%s/create-server", url.PathEscape(strings.TrimSpace(filename)))
+	if err := c.sendJSON(ctx, http.MethodPost, path, "import server", map[string]any{"serverName": strings.TrimSpace(serverName), "allowFlagged": allowFlagged}, &result); err != nil {
+		return "", err
+	}
+	return result.JobId, nil
+}
+
+// UploadImport streams an archive into the host import directory under the
+// given filename (.zip, .tar.gz or .tgz). The upload has no timeout of its
+// own; ctx bounds it.
+func (c *Client) UploadImport(ctx context.Context, filename string, body io.Reader) error {
+	if strings.TrimSpace(c.apiKey) == "" {
+		return ErrApiKeyMissing
+	}
+	if strings.TrimSpace(filename) == "" {
+		return errors.New("import filename is required")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiBaseURL+"/host/imports/upload", body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-File-Name", strings.TrimSpace(filename))
+	return c.do(&http.Client{Transport: c.httpClient.Transport}, req, "upload import", nil)
+}
+
+func (c *Client) DeleteImport(ctx context.Context, filename string) error {
+	if strings.TrimSpace(filename) == "" {
+		return errors.New("import filename is required")
+	}
+	path := "/host/imports/" + url.PathEscape(strings.TrimSpace(filename))
+	return c.sendJSON(ctx, http.MethodDelete, path, "delete import", nil, nil)
+}
+
+func (c *Client) GetJob(ctx context.Context, id string) (ports.JobStatus, error) {
+	var job ports.JobStatus
+	if err := c.getJSON(ctx, "/jobs/"+url.PathEscape(id), "get job", &job); err != nil {
+		return ports.JobStatus{}, err
+	}
+	return job, nil
+}
+
+// CreateBackup queues an incremental backup of a server and returns the job id.
+func (c *Client) CreateBackup(ctx context.Context, name string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", errors.New("server name is required")
+	}
+	var result struct {
+		JobId string `json:"jobId"`
+	}
+	path := fmt.Sprintf("/servers/%s/backups", url.PathEscape(strings.TrimSpace(name)))
+	if err := c.sendJSON(ctx, http.MethodPost, path, "create backup", nil, &result); err != nil {
+		return "", err
+	}
+	return result.JobId, nil
+}
+
+// ListBackups returns a server's backup increments, newest first.
+func (c *Client) ListBackups(ctx context.Context, name string) ([]ports.BackupEntry, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("server name is required")
+	}
+	var backups []ports.BackupEntry
+	path := fmt.Sprintf("/servers/%s/backups", url.PathEscape(strings.TrimSpace(name)))
+	if err := c.getJSON(ctx, path, "list backups", &backups); err != nil {
+		return nil, err
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Time.After(backups[j].Time) })
+	return backups, nil
+}
+
+// RestoreBackup restores a server's files to the backup taken at timestamp,
+// in any form rdiff-backup accepts for --at.
+func (c *Client) RestoreBackup(ctx context.Context, name, timestamp string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	path := fmt.Sprintf("/servers/%s/backups/restore", url.PathEscape(strings.TrimSpace(name)))
+	return c.sendJSON(ctx, http.MethodPost, path, "restore backup", map[string]string{"timestamp": timestamp}, nil)
+}
+
+// DeleteServer removes a stopped server with its backups and archives.
+func (c *Client) DeleteServer(ctx context.Context, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	path := fmt.Sprintf("/servers/%s", url.PathEscape(strings.TrimSpace(name)))
+	return c.sendJSON(ctx, http.MethodDelete, path, "delete server", nil, nil)
+}
+
+// UploadServerIcon replaces a server's server-icon.png. The API resizes
+// images that are not 64x64.
+func (c *Client) UploadServerIcon(ctx context.Context, name string, png []byte) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("server name is required")
+	}
+	path := fmt.Sprintf("/servers/%s/icon", url.PathEscape(strings.TrimSpace(name)))
+	return c.sendBytes(ctx, http.MethodPost, path, "upload icon", "image/png", png, nil)
+}
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// UpdateSetting stores a runtime setting (e.g. "MineOS:ShutdownTimeoutSeconds")
+// in the API's database, where it takes precedence over the container
+// environment without a restart. The settings endpoints are admin-only.
+func (c *Client) UpdateSetting(ctx context.Context, key, value string) error {
+	if strings.TrimSpace(key) == "" {
+		return errors.New("setting key is required")
+	}
+	body := map[string]string{"value": value}
+	return c.sendJSON(ctx, http.MethodPut, "/settings/"+url.PathEscape(key), "update setting "+key, body, nil)
+}
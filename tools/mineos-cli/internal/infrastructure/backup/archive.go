@@ -0,0 +1,561 @@
+// Package backup writes and reads host-side server backups: a tar stream
+// compressed with zstd (or gzip, or a zip file) plus a JSON manifest beside
+// it, one folder per server.
+package backup
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/backup"
+)
+
+// ManifestEntry is the archive member holding the manifest, so an archive
+// streamed elsewhere still describes itself.
+const ManifestEntry = ".mineos-backup.json"
+
+// Options control how a backup is written.
+type Options struct {
+	Format domain.Format
+	// Level is the compression level; 0 uses the format default.
+	Level int
+	// Workers compress zstd blocks and hash files in parallel; 0 uses one
+	// per CPU.
+	Workers int
+	// Parent makes the backup incremental to it.
+	Parent *domain.Manifest
+	Detect domain.ChangeDetection
+	// Exclude lists top-level names that are skipped.
+	Exclude []string
+	// Progress receives the content bytes as they are read.
+	Progress io.Writer
+	// OnPlanned is told how many files, and bytes, the backup stores once
+	// the tree has been scanned.
+	OnPlanned func(files int, bytes int64)
+}
+
+func (o Options) workers() int {
+	if o.Workers > 0 {
+		return o.Workers
+	}
+	return runtime.NumCPU()
+}
+
+func (o Options) level() int {
+	if o.Level > 0 {
+		return o.Level
+	}
+	return o.Format.DefaultLevel()
+}
+
+// Scan lists the tree under dir. With ByHash every file is hashed by the
+// worker pool.
+func Scan(ctx context.Context, dir string, opts Options) ([]domain.Entry, error) {
+	excluded := map[string]bool{}
+	for _, name := range opts.Exclude {
+		excluded[name] = true
+	}
+	var entries []domain.Entry
+	err := filepath.WalkDir(dir, func(full string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, full)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.Contains(rel, "/") && excluded[rel] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entry := domain.Entry{Path: rel, Mode: info.Mode().Perm(), ModTime: info.ModTime().UTC().Truncate(time.Second)}
+		switch {
+		case d.IsDir():
+			entry.Type = domain.TypeDir
+		case info.Mode()&fs.ModeSymlink != 0:
+			entry.Type = domain.TypeSymlink
+			if entry.Link, err = os.Readlink(full); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			entry.Type = domain.TypeFile
+			entry.Size = info.Size()
+		default:
+			// Sockets and devices have no place in a server directory.
+			return nil
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.Detect == domain.ByHash {
+		if err := hashAll(ctx, dir, entries, opts.workers()); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func hashAll(ctx context.Context, dir string, entries []domain.Entry, workers int) error {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				sum, err := hashFile(filepath.Join(dir, filepath.FromSlash(entries[i].Path)))
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				entries[i].Sha256 = sum
+				mu.Unlock()
+			}
+		}()
+	}
+	for i, entry := range entries {
+		if entry.Type != domain.TypeFile {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func hashFile(name string) (string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Write archives the planned entries of manifest from dir into w: every
+// directory and symlink, and the files manifest.ID stores. It fills in the
+// hashes of the stored files and the counts.
+func Write(ctx context.Context, dir string, w io.Writer, manifest *domain.Manifest, opts Options) error {
+	var archive archiveWriter
+	switch manifest.Format {
+	case domain.FormatZstd:
+		encoder, err := zstd.NewWriter(w,
+			zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(opts.level())),
+			zstd.WithEncoderConcurrency(opts.workers()))
+		if err != nil {
+			return err
+		}
+		archive = &tarWriter{tw: tar.NewWriter(encoder), compressor: encoder}
+	case domain.FormatGzip:
+		compressor, err := gzip.NewWriterLevel(w, opts.level())
+		if err != nil {
+			return err
+		}
+		archive = &tarWriter{tw: tar.NewWriter(compressor), compressor: compressor}
+	case domain.FormatZip:
+		zw := zip.NewWriter(w)
+		level := opts.level()
+		zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(out, level)
+		})
+		archive = &zipWriter{zw: zw}
+	default:
+		return fmt.Errorf("unknown backup format %q", manifest.Format)
+	}
+
+	manifest.Stored, manifest.Bytes = 0, 0
+	for i := range manifest.Entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := &manifest.Entries[i]
+		if entry.Type == domain.TypeFile && entry.Archive != manifest.ID {
+			continue
+		}
+		if err := writeEntry(archive, dir, entry, opts.Progress); err != nil {
+			return fmt.Errorf("%s: %w", entry.Path, err)
+		}
+		if entry.Type == domain.TypeFile {
+			manifest.Stored++
+			manifest.Bytes += entry.Size
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := archive.add(domain.Entry{Path: ManifestEntry, Type: domain.TypeFile, Mode: 0o644, Size: int64(len(data)), ModTime: manifest.Created}, strings.NewReader(string(data))); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func writeEntry(archive archiveWriter, dir string, entry *domain.Entry, progress io.Writer) error {
+	if entry.Type != domain.TypeFile {
+		return archive.add(*entry, nil)
+	}
+	file, err := os.Open(filepath.Join(dir, filepath.FromSlash(entry.Path)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	var reader io.Reader = io.TeeReader(file, hash)
+	if progress != nil {
+		reader = io.TeeReader(reader, progress)
+	}
+	// A file that grew since the scan is cut at the scanned size; one that
+	// shrank cannot be stored consistently.
+	counted := &countingReader{r: io.LimitReader(reader, entry.Size)}
+	if err := archive.add(*entry, counted); err != nil {
+		return err
+	}
+	if counted.n != entry.Size {
+		return fmt.Errorf("shrank from %d to %d bytes while it was read; stop the server or retry", entry.Size, counted.n)
+	}
+	entry.Sha256 = hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type archiveWriter interface {
+	add(entry domain.Entry, content io.Reader) error
+	Close() error
+}
+
+type tarWriter struct {
+	tw         *tar.Writer
+	compressor io.WriteCloser
+}
+
+func (t *tarWriter) add(entry domain.Entry, content io.Reader) error {
+	header := &tar.Header{
+		Name:    entry.Path,
+		Mode:    int64(entry.Mode),
+		ModTime: entry.ModTime,
+		Format:  tar.FormatPAX,
+	}
+	switch entry.Type {
+	case domain.TypeDir:
+		header.Typeflag = tar.TypeDir
+		header.Name += "/"
+	case domain.TypeSymlink:
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = entry.Link
+	default:
+		header.Typeflag = tar.TypeReg
+		header.Size = entry.Size
+	}
+	if err := t.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if content == nil {
+		return nil
+	}
+	_, err := io.Copy(t.tw, content)
+	return err
+}
+
+func (t *tarWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	return t.compressor.Close()
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipWriter) add(entry domain.Entry, content io.Reader) error {
+	header := &zip.FileHeader{Name: entry.Path, Method: zip.Deflate, Modified: entry.ModTime}
+	switch entry.Type {
+	case domain.TypeDir:
+		header.Name += "/"
+		header.Method = zip.Store
+		header.SetMode(fs.ModeDir | entry.Mode)
+	case domain.TypeSymlink:
+		header.Method = zip.Store
+		header.SetMode(fs.ModeSymlink | entry.Mode)
+		content = strings.NewReader(entry.Link)
+	default:
+		header.SetMode(entry.Mode)
+	}
+	w, err := z.zw.CreateHeader(header)
+	if err != nil || content == nil {
+		return err
+	}
+	_, err = io.Copy(w, content)
+	return err
+}
+
+func (z *zipWriter) Close() error {
+	return z.zw.Close()
+}
+
+// Extract restores the entries of a backup chain into dest, which should be
+// empty. chain[0] is the backup being restored; the others are the archives
+// its entries point at, found by archivePath.
+func Extract(ctx context.Context, chain []domain.Manifest, archivePath func(domain.Manifest) string, dest string, workers int, progress io.Writer) error {
+	top := chain[0]
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return err
+	}
+	for _, entry := range top.Entries {
+		if entry.Type != domain.TypeDir {
+			continue
+		}
+		target, err := localPath(dest, entry.Path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range chain {
+		wanted := map[string]domain.Entry{}
+		for _, entry := range top.Entries {
+			if entry.Type == domain.TypeFile && entry.Archive == m.ID {
+				wanted[entry.Path] = entry
+			}
+		}
+		if len(wanted) == 0 {
+			continue
+		}
+		if err := extractArchive(ctx, archivePath(m), m.Format, dest, wanted, workers, progress); err != nil {
+			return fmt.Errorf("%s: %w", m.ID, err)
+		}
+		if len(wanted) > 0 {
+			missing := make([]string, 0, len(wanted))
+			for name := range wanted {
+				missing = append(missing, name)
+			}
+			sort.Strings(missing)
+			return fmt.Errorf("%s is missing %d file(s), e.g. %s", m.ID, len(missing), missing[0])
+		}
+	}
+
+	for _, entry := range top.Entries {
+		if entry.Type != domain.TypeSymlink {
+			continue
+		}
+		target, err := localPath(dest, entry.Path)
+		if err != nil {
+			return err
+		}
+		if err := os.Symlink(entry.Link, target); err != nil {
+			return err
+		}
+	}
+	// Directory times last, as creating their contents changed them.
+	for i := len(top.Entries) - 1; i >= 0; i-- {
+		entry := top.Entries[i]
+		if entry.Type != domain.TypeDir {
+			continue
+		}
+		target, _ := localPath(dest, entry.Path)
+		_ = os.Chmod(target, entry.Mode)
+		_ = os.Chtimes(target, entry.ModTime, entry.ModTime)
+	}
+	return nil
+}
+
+func extractArchive(ctx context.Context, archive string, format domain.Format, dest string, wanted map[string]domain.Entry, workers int, progress io.Writer) error {
+	return walkArchive(ctx, archive, format, workers, func(m member, content io.Reader) (bool, error) {
+		entry, ok := wanted[m.Name]
+		if !ok || !m.Regular {
+			return true, nil
+		}
+		if err := writeFile(dest, entry, content, progress); err != nil {
+			return false, err
+		}
+		if m.Uid >= 0 && os.Geteuid() == 0 {
+			target, _ := localPath(dest, entry.Path)
+			_ = os.Lchown(target, m.Uid, m.Gid)
+		}
+		delete(wanted, m.Name)
+		return len(wanted) > 0, nil
+	})
+}
+
+// member is a file read from an archive. Uid is -1 for zip members, which
+// do not record an owner.
+type member struct {
+	Name     string
+	Regular  bool
+	Uid, Gid int
+}
+
+// walkArchive passes the members of an archive to visit in order until it
+// returns false. A walk that is not stopped reads the archive to its end,
+// so the checksums of the compression are checked too, provided visit
+// reads every member to its end.
+func walkArchive(ctx context.Context, archive string, format domain.Format, workers int, visit func(member, io.Reader) (bool, error)) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if format == domain.FormatZip {
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		zr, err := zip.NewReader(file, info.Size())
+		if err != nil {
+			return err
+		}
+		for _, zm := range zr.File {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			content, err := zm.Open()
+			if err != nil {
+				return err
+			}
+			more, err := visit(member{Name: zm.Name, Regular: zm.Mode().IsRegular(), Uid: -1, Gid: -1}, content)
+			content.Close()
+			if err != nil || !more {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var stream io.Reader
+	switch format {
+	case domain.FormatZstd:
+		decoder, err := zstd.NewReader(file, zstd.WithDecoderConcurrency(max(workers, 1)))
+		if err != nil {
+			return err
+		}
+		defer decoder.Close()
+		stream = decoder
+	case domain.FormatGzip:
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		stream = gz
+	default:
+		return fmt.Errorf("unknown backup format %q", format)
+	}
+
+	tr := tar.NewReader(stream)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := tr.Next()
+		if err == io.EOF {
+			// Read past the end-of-archive blocks to the end of the
+			// compressed stream, where its checksum is.
+			_, err = io.Copy(io.Discard, stream)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		more, err := visit(member{Name: header.Name, Regular: header.Typeflag == tar.TypeReg, Uid: header.Uid, Gid: header.Gid}, tr)
+		if err != nil || !more {
+			return err
+		}
+	}
+}
+
+func writeFile(dest string, entry domain.Entry, content io.Reader, progress io.Writer) error {
+	target, err := localPath(dest, entry.Path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, entry.Mode|0o200)
+	if err != nil {
+		return err
+	}
+	writer := io.Writer(out)
+	if progress != nil {
+		writer = io.MultiWriter(out, progress)
+	}
+	if _, err := io.Copy(writer, content); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	_ = os.Chmod(target, entry.Mode)
+	return os.Chtimes(target, entry.ModTime, entry.ModTime)
+}
+
+// localPath joins an archived path onto dest, refusing paths that would
+// escape it.
+func localPath(dest, name string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(name)) || path.Clean(name) != name {
+		return "", fmt.Errorf("refusing unsafe path %q", name)
+	}
+	return filepath.Join(dest, filepath.FromSlash(name)), nil
+}
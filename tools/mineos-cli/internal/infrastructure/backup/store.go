@@ -0,0 +1,201 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/backup"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// Store keeps backups under root, as <server>/<id>.<format> with the
+// manifest in <server>/<id>.json.
+type Store struct {
+	root string
+}
+
+func NewStore(root string) *Store {
+	return &Store{root: root}
+}
+
+func (s *Store) Root() string {
+	return s.root
+}
+
+// ArchivePath returns where the archive of a backup is.
+func (s *Store) ArchivePath(m domain.Manifest) string {
+	return filepath.Join(s.root, m.Server, m.Archive)
+}
+
+// Create backs up dir as server. With opts.Parent set only the files that
+// changed since it are stored.
+func (s *Store) Create(ctx context.Context, server, dir string, opts Options) (domain.Manifest, error) {
+	started := time.Now()
+	if opts.Detect == "" {
+		opts.Detect = domain.ByMtime
+	}
+	entries, err := Scan(ctx, dir, opts)
+	if err != nil {
+		return domain.Manifest{}, err
+	}
+
+	manifest := domain.Manifest{
+		ID:      domain.NewID(server, started),
+		Server:  server,
+		Created: started.UTC(),
+		Format:  opts.Format,
+		Level:   opts.level(),
+		Detect:  opts.Detect,
+	}
+	if opts.Parent != nil {
+		manifest.Parent = opts.Parent.ID
+	}
+	manifest.Entries = domain.Plan(manifest.ID, entries, opts.Parent, opts.Detect)
+	manifest.Archive = manifest.ID + "." + string(opts.Format)
+	if opts.OnPlanned != nil {
+		files, bytes := 0, int64(0)
+		for _, entry := range manifest.Entries {
+			if entry.Type == domain.TypeFile && entry.Archive == manifest.ID {
+				files++
+				bytes += entry.Size
+			}
+		}
+		opts.OnPlanned(files, bytes)
+	}
+
+	serverDir := filepath.Join(s.root, server)
+	if err := os.MkdirAll(serverDir, 0o755); err != nil {
+		return domain.Manifest{}, err
+	}
+	if _, err := os.Stat(filepath.Join(serverDir, manifest.ID+".json")); err == nil {
+		return domain.Manifest{}, fmt.Errorf("backup %s already exists; wait a second and retry", manifest.ID)
+	}
+
+	final := filepath.Join(serverDir, manifest.Archive)
+	partial := final + ".partial"
+	file, err := os.Create(partial)
+	if err != nil {
+		return domain.Manifest{}, err
+	}
+	if err := Write(ctx, dir, file, &manifest, opts); err != nil {
+		file.Close()
+		os.Remove(partial)
+		return domain.Manifest{}, err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(partial)
+		return domain.Manifest{}, err
+	}
+	if info, err := os.Stat(partial); err == nil {
+		manifest.Size = info.Size()
+	}
+	if err := os.Rename(partial, final); err != nil {
+		return domain.Manifest{}, err
+	}
+	manifest.Duration = time.Since(started).Round(time.Millisecond)
+	if err := s.save(manifest); err != nil {
+		os.Remove(final)
+		return domain.Manifest{}, err
+	}
+	return manifest, nil
+}
+
+func (s *Store) save(m domain.Manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.root, m.Server, m.ID+".json")
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// List returns the backups of server, or of every server when it is empty,
+// oldest first.
+func (s *Store) List(server string) ([]domain.Manifest, error) {
+	pattern := filepath.Join(s.root, "*", "*.json")
+	if server != "" {
+		pattern = filepath.Join(s.root, server, "*.json")
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	manifests := []domain.Manifest{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var m domain.Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		manifests = append(manifests, m)
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Created.Before(manifests[j].Created) })
+	return manifests, nil
+}
+
+// Latest returns the newest backup of server.
+func (s *Store) Latest(server string) (domain.Manifest, bool, error) {
+	manifests, err := s.List(server)
+	if err != nil || len(manifests) == 0 {
+		return domain.Manifest{}, false, err
+	}
+	return manifests[len(manifests)-1], true, nil
+}
+
+// Find returns a backup by ID.
+func (s *Store) Find(id string) (domain.Manifest, error) {
+	manifests, err := s.List("")
+	if err != nil {
+		return domain.Manifest{}, err
+	}
+	for _, m := range manifests {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return domain.Manifest{}, ports.WithKind(fmt.Errorf("backup %s not found in %s", id, s.root), ports.ErrNotFound)
+}
+
+// Chain returns the backups a restore of m reads.
+func (s *Store) Chain(m domain.Manifest) ([]domain.Manifest, error) {
+	manifests, err := s.List(m.Server)
+	if err != nil {
+		return nil, err
+	}
+	known := map[string]domain.Manifest{}
+	for _, other := range manifests {
+		known[other.ID] = other
+	}
+	return domain.Chain(m, known)
+}
+
+// Delete removes a backup no other backup takes files from.
+func (s *Store) Delete(id string) error {
+	m, err := s.Find(id)
+	if err != nil {
+		return err
+	}
+	manifests, err := s.List(m.Server)
+	if err != nil {
+		return err
+	}
+	if dependents := domain.Dependents(id, manifests); len(dependents) > 0 {
+		return fmt.Errorf("%s holds files of later backups (%s); delete those first", id, strings.Join(dependents, ", "))
+	}
+	if err := os.Remove(s.ArchivePath(m)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Remove(filepath.Join(s.root, m.Server, m.ID+".json"))
+}
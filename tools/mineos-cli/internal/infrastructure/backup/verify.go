@@ -0,0 +1,189 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/anvil"
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/backup"
+)
+
+// maxListed caps how many paths one kind of problem lists.
+const maxListed = 5
+
+type VerifyOptions struct {
+	Workers  int
+	Progress io.Writer
+	// Extract also restores the backup into a scratch directory under
+	// ScratchDir, or the system temp directory, which is removed after.
+	Extract    bool
+	ScratchDir string
+}
+
+// Verify reads every archive of a backup chain to its end, so the zstd,
+// gzip or zip checksums are checked, and compares each file the backup
+// restores with the size and hash in the manifest. Worlds need a level.dat
+// and every region file a valid header. The error is only set when ctx is
+// cancelled; everything else is a problem in the report.
+func Verify(ctx context.Context, chain []domain.Manifest, archivePath func(domain.Manifest) string, opts VerifyOptions) (domain.Report, error) {
+	started := time.Now()
+	top := chain[0]
+	report := domain.Report{ID: top.ID, Server: top.Server}
+
+	worlds, missing := domain.Worlds(top.Entries)
+	report.Worlds = worlds
+	for _, world := range missing {
+		report.Problem("%s has a region folder but no %s", world, domain.LevelFile)
+	}
+	if len(worlds) == 0 {
+		report.Notes = append(report.Notes, "no world found, which is expected only for proxies and servers that never started")
+	}
+
+	files := map[string]bool{}
+	for _, entry := range top.Entries {
+		if entry.Type == domain.TypeFile {
+			files[entry.Path] = true
+		}
+	}
+	externalExists := func(dir string) func(anvil.Chunk) bool {
+		return func(chunk anvil.Chunk) bool {
+			return files[path.Join(dir, anvil.ExternalFile(chunk))]
+		}
+	}
+
+	var mismatched []string
+	for _, m := range chain {
+		wanted := map[string]domain.Entry{}
+		for _, entry := range top.Entries {
+			if entry.Type == domain.TypeFile && entry.Archive == m.ID {
+				wanted[entry.Path] = entry
+			}
+		}
+		report.Archives = append(report.Archives, m.ID)
+		err := walkArchive(ctx, archivePath(m), m.Format, opts.Workers, func(mem member, content io.Reader) (bool, error) {
+			entry, ok := wanted[mem.Name]
+			if !ok || !mem.Regular {
+				// Read it anyway: the checksums cover every member.
+				_, err := io.Copy(io.Discard, content)
+				return true, err
+			}
+			delete(wanted, mem.Name)
+
+			hash := sha256.New()
+			reader := io.TeeReader(content, hash)
+			if opts.Progress != nil {
+				reader = io.TeeReader(reader, opts.Progress)
+			}
+			var region *bytes.Buffer
+			rx, rz, isRegion := anvil.RegionCoords(path.Base(entry.Path))
+			if isRegion {
+				region = &bytes.Buffer{}
+				reader = io.TeeReader(reader, region)
+			}
+			size, err := io.Copy(io.Discard, reader)
+			if err != nil {
+				return false, err
+			}
+			report.Files++
+			report.Bytes += size
+			if size != entry.Size || (entry.Sha256 != "" && hex.EncodeToString(hash.Sum(nil)) != entry.Sha256) {
+				mismatched = append(mismatched, entry.Path)
+			}
+			if isRegion {
+				report.Regions++
+				problems := anvil.CheckHeader(anvil.Region{X: rx, Z: rz, Data: region.Bytes()}, externalExists(path.Dir(entry.Path)))
+				addRegionProblems(&report, entry.Path, problems)
+			}
+			return true, nil
+		})
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		if err != nil {
+			report.Problem("%s: archive %s is damaged: %v", m.ID, archivePath(m), err)
+			continue
+		}
+		if len(wanted) > 0 {
+			names := make([]string, 0, len(wanted))
+			for name := range wanted {
+				names = append(names, name)
+			}
+			report.Problem("%s: %d file(s) missing from the archive: %s", m.ID, len(names), listed(names))
+		}
+	}
+	if len(mismatched) > 0 {
+		report.Problem("%d file(s) differ from the manifest: %s", len(mismatched), listed(mismatched))
+	}
+
+	if opts.Extract && report.OK() {
+		if err := scratchRestore(ctx, chain, archivePath, opts); err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			report.Problem("scratch restore failed: %v", err)
+		} else {
+			report.Extracted = true
+		}
+	}
+	report.Duration = time.Since(started).Round(time.Millisecond)
+	return report, nil
+}
+
+func addRegionProblems(report *domain.Report, file string, problems []anvil.Problem) {
+	if len(problems) == 0 {
+		return
+	}
+	text := make([]string, 0, maxListed)
+	for i, problem := range problems {
+		if i == maxListed {
+			break
+		}
+		text = append(text, problem.String())
+	}
+	if len(problems) > maxListed {
+		text = append(text, fmt.Sprintf("%d more", len(problems)-maxListed))
+	}
+	report.Problem("%s: %s", file, strings.Join(text, "; "))
+}
+
+func listed(names []string) string {
+	sort.Strings(names)
+	if len(names) > maxListed {
+		return strings.Join(names[:maxListed], ", ") + ", ..."
+	}
+	return strings.Join(names, ", ")
+}
+
+// scratchRestore extracts the chain into a temporary directory and checks
+// every entry came out, then removes it.
+func scratchRestore(ctx context.Context, chain []domain.Manifest, archivePath func(domain.Manifest) string, opts VerifyOptions) error {
+	scratch, err := os.MkdirTemp(opts.ScratchDir, ".mineos-verify-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(scratch)
+
+	if err := Extract(ctx, chain, archivePath, scratch, opts.Workers, nil); err != nil {
+		return err
+	}
+	for _, entry := range chain[0].Entries {
+		info, err := os.Lstat(filepath.Join(scratch, filepath.FromSlash(entry.Path)))
+		switch {
+		case err != nil:
+			return fmt.Errorf("%s was not restored: %w", entry.Path, err)
+		case entry.Type == domain.TypeFile && info.Size() != entry.Size:
+			return fmt.Errorf("%s was restored with %d of %d bytes", entry.Path, info.Size(), entry.Size)
+		}
+	}
+	return nil
+}
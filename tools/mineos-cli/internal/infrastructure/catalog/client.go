@@ -0,0 +1,226 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	mojangManifestURL = "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json"
+	paperProjectURL   = "https://api.papermc.io/v2/projects/paper"
+	fabricGameURL     = "https://meta.fabricmc.net/v2/versions/game"
+	forgePromosURL    = "https://files.minecraftforge.net/net/minecraftforge/forge/promotions_slim.json"
+)
+
+// Software names accepted by the catalog.
+const (
+	Vanilla = "vanilla"
+	Paper   = "paper"
+	Fabric  = "fabric"
+	Forge   = "forge"
+)
+
+var SupportedSoftware = []string{Vanilla, Paper, Fabric, Forge}
+
+// Version is a Minecraft version available for a server type.
+type Version struct {
+	ID          string
+	Stable      bool
+	ReleaseTime time.Time
+	// Detail carries software-specific data such as the Forge build.
+	Detail string
+}
+
+type Catalog struct {
+	LatestRelease  string
+	LatestSnapshot string
+	Versions       []Version
+}
+
+type Client struct {
+	httpClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Versions returns the catalog for a server type, newest first.
+func (c *Client) Versions(ctx context.Context, software string) (Catalog, error) {
+	switch strings.ToLower(strings.TrimSpace(software)) {
+	case "", Vanilla:
+		return c.mojang(ctx)
+	case Paper:
+		return c.paper(ctx)
+	case Fabric:
+		return c.fabric(ctx)
+	case Forge:
+		return c.forge(ctx)
+	default:
+		return Catalog{}, fmt.Errorf("unknown server type %q (supported: %s)", software, strings.Join(SupportedSoftware, ", "))
+	}
+}
+
+func (c *Client) mojang(ctx context.Context) (Catalog, error) {
+	var manifest struct {
+		Latest struct {
+			Release  string `json:"release"`
+			Snapshot string `json:"snapshot"`
+		} `json:"latest"`
+		Versions []struct {
+			ID          string    `json:"id"`
+			Type        string    `json:"type"`
+			ReleaseTime time.Time `json:"releaseTime"`
+		} `json:"versions"`
+	}
+	if err := c.getJSON(ctx, mojangManifestURL, &manifest); err != nil {
+		return Catalog{}, err
+	}
+	cat := Catalog{LatestRelease: manifest.Latest.Release, LatestSnapshot: manifest.Latest.Snapshot}
+	for _, v := range manifest.Versions {
+		if v.Type != "release" && v.Type != "snapshot" {
+			continue
+		}
+		cat.Versions = append(cat.Versions, Version{ID: v.ID, Stable: v.Type == "release", ReleaseTime: v.ReleaseTime})
+	}
+	return cat, nil
+}
+
+func (c *Client) paper(ctx context.Context) (Catalog, error) {
+	var project struct {
+		Versions []string `json:"versions"`
+	}
+	if err := c.getJSON(ctx, paperProjectURL, &project); err != nil {
+		return Catalog{}, err
+	}
+	cat := Catalog{}
+	for i := len(project.Versions) - 1; i >= 0; i-- {
+		id := project.Versions[i]
+		stable := !strings.Contains(id, "-")
+		cat.Versions = append(cat.Versions, Version{ID: id, Stable: stable})
+		if stable && cat.LatestRelease == "" {
+			cat.LatestRelease = id
+		}
+		if !stable && cat.LatestSnapshot == "" {
+			cat.LatestSnapshot = id
+		}
+	}
+	return cat, nil
+}
+
+func (c *Client) fabric(ctx context.Context) (Catalog, error) {
+	var versions []struct {
+		Version string `json:"version"`
+		Stable  bool   `json:"stable"`
+	}
+	if err := c.getJSON(ctx, fabricGameURL, &versions); err != nil {
+		return Catalog{}, err
+	}
+	cat := Catalog{}
+	for _, v := range versions {
+		cat.Versions = append(cat.Versions, Version{ID: v.Version, Stable: v.Stable})
+		if v.Stable && cat.LatestRelease == "" {
+			cat.LatestRelease = v.Version
+		}
+		if !v.Stable && cat.LatestSnapshot == "" {
+			cat.LatestSnapshot = v.Version
+		}
+	}
+	return cat, nil
+}
+
+func (c *Client) forge(ctx context.Context) (Catalog, error) {
+	var promos struct {
+		Promos map[string]string `json:"promos"`
+	}
+	if err := c.getJSON(ctx, forgePromosURL, &promos); err != nil {
+		return Catalog{}, err
+	}
+
+	type builds struct{ latest, recommended string }
+	byVersion := map[string]*builds{}
+	for key, build := range promos.Promos {
+		idx := strings.LastIndex(key, "-")
+		if idx <= 0 {
+			continue
+		}
+		mc, channel := key[:idx], key[idx+1:]
+		entry := byVersion[mc]
+		if entry == nil {
+			entry = &builds{}
+			byVersion[mc] = entry
+		}
+		if channel == "recommended" {
+			entry.recommended = build
+		} else {
+			entry.latest = build
+		}
+	}
+
+	cat := Catalog{}
+	for mc, entry := range byVersion {
+		detail := "forge " + entry.latest
+		if entry.recommended != "" {
+			detail = "forge " + entry.recommended + " (recommended)"
+		}
+		cat.Versions = append(cat.Versions, Version{ID: mc, Stable: entry.recommended != "", Detail: detail})
+	}
+	sort.Slice(cat.Versions, func(i, j int) bool {
+		return CompareVersions(cat.Versions[i].ID, cat.Versions[j].ID) > 0
+	})
+	for _, v := range cat.Versions {
+		if v.Stable {
+			cat.LatestRelease = v.ID
+			break
+		}
+	}
+	if len(cat.Versions) > 0 {
+		cat.LatestSnapshot = cat.Versions[0].ID
+	}
+	return cat, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "mineos-cli")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// CompareVersions compares dotted numeric versions such as 1.20.4 and 1.9.
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var av, bv int
+		if i < len(as) {
+			fmt.Sscanf(as[i], "%d", &av)
+		}
+		if i < len(bs) {
+			fmt.Sscanf(bs[i], "%d", &bv)
+		}
+		if av != bv {
+			if av < bv {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+// Package confirm stores one-time tokens that let a second admin approve a
+// destructive operation. Tokens live in the installation's state directory,
+// hashed, so everyone managing the install shares them.
+package confirm
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+)
+
+// File is the token store, in the installation's state directory.
+const File = ".mineos-confirm"
+
+var (
+	ErrInvalidToken = errors.New("confirmation token is not valid")
+	ErrExpiredToken = errors.New("confirmation token has expired")
+	ErrSameUser     = errors.New("confirmation token must be issued by another admin")
+)
+
+type Token struct {
+	Hash      string    `json:"hash"`
+	Operation string    `json:"operation"`
+	IssuedBy  string    `json:"issued_by"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type Store struct {
+	path string
+}
+
+// NewStore keeps tokens in the file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Issue creates a token for operation, valid for ttl. Only its hash is
+// stored; the token itself is returned once.
+func (s *Store) Issue(operation, issuer string, ttl time.Duration) (string, Token, error) {
+	secret := make([]byte, 10)
+	if _, err := rand.Read(secret); err != nil {
+		return "", Token{}, err
+	}
+	raw := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+	code := raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16]
+
+	now := time.Now().UTC()
+	token := Token{
+		Hash:      hash(code),
+		Operation: operation,
+		IssuedBy:  issuer,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+	tokens, err := s.List()
+	if err != nil {
+		return "", Token{}, err
+	}
+	if err := s.save(append(tokens, token)); err != nil {
+		return "", Token{}, err
+	}
+	return code, token, nil
+}
+
+// Consume checks code for operation and removes it so it can't be reused.
+// A token is never accepted from the admin who issued it.
+func (s *Store) Consume(operation, code, user string) (Token, error) {
+	tokens, err := s.load()
+	if err != nil {
+		return Token{}, err
+	}
+	want := hash(code)
+	for i, token := range tokens {
+		if token.Hash != want {
+			continue
+		}
+		if time.Now().After(token.ExpiresAt) {
+			_ = s.save(append(tokens[:i:i], tokens[i+1:]...))
+			return Token{}, ErrExpiredToken
+		}
+		if token.Operation != operation {
+			return Token{}, fmt.Errorf("%w: it was issued for %s", ErrInvalidToken, token.Operation)
+		}
+		if token.IssuedBy == user {
+			return Token{}, ErrSameUser
+		}
+		if err := s.save(append(tokens[:i:i], tokens[i+1:]...)); err != nil {
+			return Token{}, err
+		}
+		return token, nil
+	}
+	return Token{}, ErrInvalidToken
+}
+
+// List returns the unexpired tokens.
+func (s *Store) List() ([]Token, error) {
+	tokens, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	live := tokens[:0]
+	for _, token := range tokens {
+		if now.Before(token.ExpiresAt) {
+			live = append(live, token)
+		}
+	}
+	return live, nil
+}
+
+func (s *Store) load() ([]Token, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tokens []Token
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("read %s: %w", s.path, err)
+	}
+	return tokens, nil
+}
+
+func (s *Store) save(tokens []Token) error {
+	if len(tokens) == 0 {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o660); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func hash(code string) string {
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// CurrentUser names the admin running the CLI, looking through sudo so two
+// people using sudo on the same host stay distinct.
+func CurrentUser() string {
+	if name := strings.TrimSpace(os.Getenv("SUDO_USER")); name != "" {
+		return name
+	}
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	return fallback(os.Getenv("USER"), os.Getenv("USERNAME"))
+}
+
+func fallback(value, fallbackValue string) string {
+	if value != "" {
+		return value
+	}
+	return fallbackValue
+}
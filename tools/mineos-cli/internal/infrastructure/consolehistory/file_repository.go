@@ -0,0 +1,89 @@
+// Package consolehistory keeps the console commands sent to each server, so
+// they can be recalled in later sessions of the TUI and `servers console`.
+package consolehistory
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+// DefaultFileName is kept in the installation's state directory.
+const DefaultFileName = "mineos-console-history.json"
+
+// MaxEntries is how many commands are kept per server; older ones drop off.
+const MaxEntries = 200
+
+type FileRepository struct {
+	path string
+}
+
+func NewFileRepository(path string) *FileRepository {
+	return &FileRepository{path: path}
+}
+
+// NewFileRepositoryForEnv returns a repository for the history file of the
+// installation whose .env is envPath.
+func NewFileRepositoryForEnv(envPath string) *FileRepository {
+	return NewFileRepository(state.FileForEnv(envPath, DefaultFileName))
+}
+
+func (r *FileRepository) Path() string {
+	return r.path
+}
+
+// Load returns server's commands, oldest first.
+func (r *FileRepository) Load(server string) ([]string, error) {
+	all, err := r.loadAll()
+	if err != nil {
+		return nil, err
+	}
+	return all[server], nil
+}
+
+// Add records command for server. Repeating the last command is not
+// recorded again.
+func (r *FileRepository) Add(server, command string) error {
+	all, err := r.loadAll()
+	if err != nil {
+		return err
+	}
+	entries := all[server]
+	if len(entries) > 0 && entries[len(entries)-1] == command {
+		return nil
+	}
+	entries = append(entries, command)
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+	all[server] = entries
+	return r.save(all)
+}
+
+func (r *FileRepository) loadAll() (map[string][]string, error) {
+	all := map[string][]string{}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return all, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+func (r *FileRepository) save(all map[string][]string) error {
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
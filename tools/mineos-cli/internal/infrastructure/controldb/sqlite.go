@@ -0,0 +1,197 @@
+// Package controldb maintains the API's own SQLite database (mineos.db in
+// the data directory): backups, VACUUM, integrity checks and the state of
+// its Entity Framework migrations.
+package controldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// Migrations are the Entity Framework migrations of the API this CLI was
+// released with, oldest first: the files in
+// apps/MineOS.Infrastructure/Migrations without their .cs extension.
+var Migrations = []string{
+	"20260111072819_AddAdvancedFeatures",
+	"20260111155122_ConvertPerformanceMetricTimestampToUnix",
+	"20260113021151_AddModpackTracking",
+	"20260116230505_AddSystemNotifications",
+	"20260117113606_AddSystemSettings",
+	"20260119154429_AddServerAccessAndMinecraftLink",
+	"20260120011817_AddModpackSourceFields",
+	"20260124091500_AddNotificationRecipients",
+	"20260201161021_AddCrashEventsAndPlayerActivity",
+	"20260206012302_AddLinkedAccounts",
+	"20260208222445_AddCronJobs",
+	"20260322144617_AddImportRecords",
+}
+
+// DB is the API's database, opened next to the running API. Every method
+// holds its locks only briefly, except Vacuum.
+type DB struct {
+	db   *sql.DB
+	path string
+}
+
+// Open opens an existing database; it never creates one.
+func Open(path string) (*DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(10000)")
+	if err != nil {
+		return nil, err
+	}
+	return &DB{db: db, path: path}, nil
+}
+
+func (d *DB) Path() string {
+	return d.path
+}
+
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Size is the size of the database file, without its WAL.
+func (d *DB) Size() (int64, error) {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Backup writes a consistent, compacted copy of the database to dest with
+// VACUUM INTO, which is safe while the API writes. dest must not exist.
+func (d *DB) Backup(ctx context.Context, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("%s already exists", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	_, err := d.db.ExecContext(ctx, `VACUUM INTO ?`, dest)
+	return err
+}
+
+// Vacuum rebuilds the database to return free pages to the file system.
+// Writers wait until it is done.
+func (d *DB) Vacuum(ctx context.Context) error {
+	if _, err := d.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return err
+	}
+	// Fold the WAL back in so the file size reflects the result.
+	_, err := d.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`)
+	return err
+}
+
+// IntegrityCheck runs SQLite's integrity and foreign key checks and returns
+// what they found; none means the database is sound.
+func (d *DB) IntegrityCheck(ctx context.Context) ([]string, error) {
+	var problems []string
+	rows, err := d.db.QueryContext(ctx, `PRAGMA integrity_check`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	fks, err := d.db.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return nil, err
+	}
+	defer fks.Close()
+	for fks.Next() {
+		var table, parent string
+		var rowid sql.NullInt64
+		var fkid int64
+		if err := fks.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return nil, err
+		}
+		problems = append(problems, fmt.Sprintf("%s row %d references a missing %s row", table, rowid.Int64, parent))
+	}
+	return problems, fks.Err()
+}
+
+// AppliedMigration is a row of __EFMigrationsHistory.
+type AppliedMigration struct {
+	ID             string `json:"id"`
+	ProductVersion string `json:"product_version"`
+}
+
+// AppliedMigrations lists the migrations the API has applied, oldest first.
+func (d *DB) AppliedMigrations(ctx context.Context) ([]AppliedMigration, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT MigrationId, ProductVersion FROM __EFMigrationsHistory ORDER BY MigrationId`)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table") {
+			return nil, errors.New("the database has no migration history; the API has not started on it yet")
+		}
+		return nil, err
+	}
+	defer rows.Close()
+	var applied []AppliedMigration
+	for rows.Next() {
+		var migration AppliedMigration
+		if err := rows.Scan(&migration.ID, &migration.ProductVersion); err != nil {
+			return nil, err
+		}
+		applied = append(applied, migration)
+	}
+	return applied, rows.Err()
+}
+
+// MigrationStatus compares the applied migrations with Migrations.
+type MigrationStatus struct {
+	Applied []AppliedMigration `json:"applied"`
+	// Pending are known to this CLI but not applied; the API applies them
+	// when it next starts.
+	Pending []string `json:"pending"`
+	// Unknown are applied but newer than this CLI, after an API upgrade
+	// without a CLI upgrade.
+	Unknown []string `json:"unknown"`
+}
+
+func (d *DB) MigrationStatus(ctx context.Context) (MigrationStatus, error) {
+	applied, err := d.AppliedMigrations(ctx)
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+	status := MigrationStatus{Applied: applied, Pending: []string{}, Unknown: []string{}}
+	seen := map[string]bool{}
+	for _, migration := range applied {
+		seen[migration.ID] = true
+	}
+	known := map[string]bool{}
+	for _, id := range Migrations {
+		known[id] = true
+		if !seen[id] {
+			status.Pending = append(status.Pending, id)
+		}
+	}
+	for _, migration := range applied {
+		if !known[migration.ID] {
+			status.Unknown = append(status.Unknown, migration.ID)
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,359 @@
+// Package discord serves Discord slash commands over the HTTP interactions
+// endpoint, so no gateway connection or bot library is needed: Discord posts
+// each command, signed with the application's Ed25519 key, and the reply is
+// the HTTP response.
+package discord
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	apiBase      = "https://discord.com/api/v10"
+	maxBodyBytes = 64 * 1024
+	// maxContent is Discord's message length limit.
+	maxContent = 2000
+)
+
+// SignatureWindow is how far X-Signature-Timestamp may be from the bot's
+// clock; older interactions are rejected, so a captured one cannot be
+// replayed.
+const SignatureWindow = 5 * time.Minute
+
+// Interaction types.
+const (
+	InteractionPing         = 1
+	InteractionCommand      = 2
+	InteractionAutocomplete = 4
+)
+
+// Response types.
+const (
+	responsePong                = 1
+	responseMessage             = 4
+	responseDeferredMessage     = 5
+	responseAutocompleteResults = 8
+)
+
+// Option types used by the MineOS commands.
+const (
+	OptionString = 3
+)
+
+const flagEphemeral = 64
+
+type User struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type Member struct {
+	User  User     `json:"user"`
+	Roles []string `json:"roles"`
+}
+
+type Option struct {
+	Name    string `json:"name"`
+	Type    int    `json:"type"`
+	Value   any    `json:"value"`
+	Focused bool   `json:"focused"`
+}
+
+type InteractionData struct {
+	Name    string   `json:"name"`
+	Options []Option `json:"options"`
+}
+
+// Interaction is the part of Discord's interaction object MineOS reads.
+type Interaction struct {
+	ID            string          `json:"id"`
+	ApplicationID string          `json:"application_id"`
+	Type          int             `json:"type"`
+	Token         string          `json:"token"`
+	GuildID       string          `json:"guild_id"`
+	Member        *Member         `json:"member"`
+	User          *User           `json:"user"`
+	Data          InteractionData `json:"data"`
+}
+
+// Option returns a string option's value.
+func (i Interaction) Option(name string) string {
+	for _, option := range i.Data.Options {
+		if option.Name == name {
+			if value, ok := option.Value.(string); ok {
+				return strings.TrimSpace(value)
+			}
+			return strings.TrimSpace(fmt.Sprint(option.Value))
+		}
+	}
+	return ""
+}
+
+// Focused returns the option being typed in an autocomplete interaction.
+func (i Interaction) Focused() (Option, bool) {
+	for _, option := range i.Data.Options {
+		if option.Focused {
+			return option, true
+		}
+	}
+	return Option{}, false
+}
+
+// Caller is the user who ran the command, for logs.
+func (i Interaction) Caller() User {
+	if i.Member != nil {
+		return i.Member.User
+	}
+	if i.User != nil {
+		return *i.User
+	}
+	return User{}
+}
+
+// Roles are the caller's role IDs; empty in direct messages.
+func (i Interaction) Roles() []string {
+	if i.Member == nil {
+		return nil
+	}
+	return i.Member.Roles
+}
+
+// Reply is what a command answers with.
+type Reply struct {
+	Content string
+	// Private replies are only shown to the caller.
+	Private bool
+	// Later runs after Discord has been told to wait, for commands that take
+	// longer than the three seconds Discord allows; its result replaces the
+	// "thinking" message.
+	Later func(ctx context.Context) string
+}
+
+type Choice struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Handler interface {
+	Command(ctx context.Context, interaction Interaction) Reply
+	Autocomplete(ctx context.Context, interaction Interaction) []Choice
+}
+
+// Server answers interactions posted to its HTTP endpoint.
+type Server struct {
+	PublicKey ed25519.PublicKey
+	Handler   Handler
+	// LaterTimeout bounds Reply.Later; Discord's interaction token lasts 15
+	// minutes.
+	LaterTimeout time.Duration
+	Client       *http.Client
+	OnError      func(err error)
+
+	now func() time.Time
+}
+
+// ParsePublicKey reads the hex public key shown on the application's
+// General Information page.
+func ParsePublicKey(value string) (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid Discord public key; copy it from the application's General Information page")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+func NewServer(publicKey ed25519.PublicKey, handler Handler) *Server {
+	return &Server{
+		PublicKey:    publicKey,
+		Handler:      handler,
+		LaterTimeout: 14 * time.Minute,
+		Client:       &http.Client{Timeout: 15 * time.Second},
+		now:          time.Now,
+	}
+}
+
+// Serve listens on addr until ctx is cancelled.
+func (s *Server) Serve(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if !Verify(s.PublicKey, r.Header.Get("X-Signature-Ed25519"), r.Header.Get("X-Signature-Timestamp"), body, s.now()) {
+		// Discord sends deliberately bad signatures to check endpoints.
+		http.Error(w, "invalid request signature", http.StatusUnauthorized)
+		return
+	}
+	var interaction Interaction
+	if err := json.Unmarshal(body, &interaction); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	switch interaction.Type {
+	case InteractionPing:
+		writeJSON(w, map[string]any{"type": responsePong})
+	case InteractionAutocomplete:
+		choices := s.Handler.Autocomplete(r.Context(), interaction)
+		if len(choices) > 25 {
+			choices = choices[:25]
+		}
+		writeJSON(w, map[string]any{"type": responseAutocompleteResults, "data": map[string]any{"choices": choices}})
+	case InteractionCommand:
+		reply := s.Handler.Command(r.Context(), interaction)
+		flags := 0
+		if reply.Private {
+			flags = flagEphemeral
+		}
+		if reply.Later == nil {
+			writeJSON(w, map[string]any{"type": responseMessage, "data": map[string]any{"content": truncate(reply.Content), "flags": flags}})
+			return
+		}
+		writeJSON(w, map[string]any{"type": responseDeferredMessage, "data": map[string]any{"flags": flags}})
+		go s.followUp(interaction, reply.Later)
+	default:
+		http.Error(w, "unsupported interaction", http.StatusBadRequest)
+	}
+}
+
+// Verify checks Discord's Ed25519 signature over timestamp+body, and that
+// the timestamp is within SignatureWindow of now.
+func Verify(publicKey ed25519.PublicKey, signature, timestamp string, body []byte, now time.Time) bool {
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(unix, 0)); skew > SignatureWindow || skew < -SignatureWindow {
+		return false
+	}
+	return ed25519.Verify(publicKey, append([]byte(timestamp), body...), sig)
+}
+
+func (s *Server) followUp(interaction Interaction, later func(ctx context.Context) string) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.LaterTimeout)
+	defer cancel()
+	content := later(ctx)
+	url := fmt.Sprintf("%s/webhooks/%s/%s/messages/@original", apiBase, interaction.ApplicationID, interaction.Token)
+	if err := s.send(context.Background(), http.MethodPatch, url, "", map[string]any{"content": truncate(content)}); err != nil && s.OnError != nil {
+		s.OnError(fmt.Errorf("failed to send reply: %w", err))
+	}
+}
+
+// CommandOption describes a slash command option for registration.
+type CommandOption struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Type         int    `json:"type"`
+	Required     bool   `json:"required,omitempty"`
+	Autocomplete bool   `json:"autocomplete,omitempty"`
+}
+
+// Command describes a slash command for registration.
+type Command struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Options     []CommandOption `json:"options,omitempty"`
+}
+
+// RegisterCommands replaces the application's slash commands, in one guild
+// when guildID is set (instant) or globally (may take up to an hour).
+func RegisterCommands(ctx context.Context, client *http.Client, botToken, applicationID, guildID string, commands []Command) error {
+	url := fmt.Sprintf("%s/applications/%s/commands", apiBase, applicationID)
+	if guildID != "" {
+		url = fmt.Sprintf("%s/applications/%s/guilds/%s/commands", apiBase, applicationID, guildID)
+	}
+	s := &Server{Client: client}
+	return s.send(ctx, http.MethodPut, url, botToken, commands)
+}
+
+func (s *Server) send(ctx context.Context, method, url, botToken string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if botToken != "" {
+		req.Header.Set("Authorization", "Bot "+botToken)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func truncate(content string) string {
+	if content == "" {
+		return "Done."
+	}
+	if utf8.RuneCountInString(content) <= maxContent {
+		return content
+	}
+	// Discord counts characters, not bytes; cut on a rune boundary so the
+	// message stays valid UTF-8.
+	end, runes := 0, 0
+	for end = range content {
+		if runes == maxContent-1 {
+			break
+		}
+		runes++
+	}
+	return content[:end] + "…"
+}
@@ -0,0 +1,87 @@
+package discord
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestVerify(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPublic, _, _ := ed25519.GenerateKey(nil)
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"type":1}`)
+	stamp := func(at time.Time) string { return strconv.FormatInt(at.Unix(), 10) }
+	sign := func(timestamp string, body []byte) string {
+		return hex.EncodeToString(ed25519.Sign(private, append([]byte(timestamp), body...)))
+	}
+
+	tests := []struct {
+		name      string
+		key       ed25519.PublicKey
+		signature string
+		timestamp string
+		body      []byte
+		want      bool
+	}{
+		{name: "valid", signature: sign(stamp(now), body), timestamp: stamp(now), want: true},
+		{name: "within the window", signature: sign(stamp(now.Add(-4*time.Minute)), body), timestamp: stamp(now.Add(-4 * time.Minute)), want: true},
+		{name: "replayed after the window", signature: sign(stamp(now.Add(-SignatureWindow-time.Second)), body), timestamp: stamp(now.Add(-SignatureWindow - time.Second))},
+		{name: "from the future", signature: sign(stamp(now.Add(SignatureWindow+time.Second)), body), timestamp: stamp(now.Add(SignatureWindow + time.Second))},
+		{name: "other key", key: otherPublic, signature: sign(stamp(now), body), timestamp: stamp(now)},
+		{name: "tampered body", signature: sign(stamp(now), body), timestamp: stamp(now), body: []byte(`{"type":2}`)},
+		{name: "timestamp not signed", signature: sign(stamp(now), body), timestamp: stamp(now.Add(time.Second))},
+		{name: "timestamp not a number", signature: sign("soon", body), timestamp: "soon"},
+		{name: "missing timestamp", signature: sign("", body)},
+		{name: "signature not hex", signature: "zz", timestamp: stamp(now)},
+		{name: "short signature", signature: "abcd", timestamp: stamp(now)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := tt.key
+			if key == nil {
+				key = public
+			}
+			payload := tt.body
+			if payload == nil {
+				payload = body
+			}
+			if got := Verify(key, tt.signature, tt.timestamp, payload, now); got != tt.want {
+				t.Fatalf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		runes   int
+	}{
+		{name: "empty becomes Done.", content: "", runes: len("Done.")},
+		{name: "short", content: "ok", runes: 2},
+		{name: "at the limit", content: strings.Repeat("a", maxContent), runes: maxContent},
+		{name: "over the limit", content: strings.Repeat("a", maxContent+10), runes: maxContent},
+		{name: "multi-byte over the limit", content: strings.Repeat("é", maxContent+1), runes: maxContent},
+		{name: "multi-byte at the limit", content: strings.Repeat("é", maxContent), runes: maxContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.content)
+			if !utf8.ValidString(got) {
+				t.Fatalf("truncate returned invalid UTF-8")
+			}
+			if n := utf8.RuneCountInString(got); n != tt.runes {
+				t.Fatalf("truncate kept %d runes, want %d", n, tt.runes)
+			}
+		})
+	}
+}
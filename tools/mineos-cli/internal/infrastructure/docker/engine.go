@@ -0,0 +1,132 @@
+// Package docker talks to the Docker Engine API directly for the few cases
+// where the docker CLI hides information the CLI wants to show, such as
+// per-layer pull progress.
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const defaultSocket = "/var/run/docker.sock"
+
+type Engine struct {
+	httpClient *http.Client
+}
+
+// NewEngine connects to the local Engine socket. It reports false when
+// DOCKER_HOST points somewhere other than a unix socket or the socket is
+// missing, so callers can fall back to the docker CLI.
+func NewEngine() (*Engine, bool) {
+	socket := defaultSocket
+	if host := strings.TrimSpace(os.Getenv("DOCKER_HOST")); host != "" {
+		path, ok := strings.CutPrefix(host, "unix://")
+		if !ok {
+			return nil, false
+		}
+		socket = path
+	}
+	if _, err := os.Stat(socket); err != nil {
+		return nil, false
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &Engine{httpClient: &http.Client{Transport: transport}}, true
+}
+
+// Pull pulls an image, calling progress with the downloaded and total bytes
+// summed over the layers seen so far. Layers already present count as done.
+// platform, such as linux/arm64, selects a build other than the host's.
+func (e *Engine) Pull(ctx context.Context, image, platform string, progress func(current, total int64)) error {
+	query := url.Values{"fromImage": {image}}
+	if platform != "" {
+		query.Set("platform", platform)
+	}
+	if !hasTagOrDigest(image) {
+		// Without a tag the Engine pulls every tag of the repository.
+		query.Set("tag", "latest")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://docker/images/create?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("pull %s: %s", image, strings.TrimSpace(string(body)))
+	}
+
+	type layer struct{ current, total int64 }
+	layers := map[string]*layer{}
+	var order []string
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var message struct {
+			ID             string `json:"id"`
+			Status         string `json:"status"`
+			Error          string `json:"error"`
+			ProgressDetail struct {
+				Current int64 `json:"current"`
+				Total   int64 `json:"total"`
+			} `json:"progressDetail"`
+		}
+		if err := decoder.Decode(&message); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("pull %s: %w", image, err)
+		}
+		if message.Error != "" {
+			return fmt.Errorf("pull %s: %s", image, message.Error)
+		}
+		if message.ID == "" {
+			continue
+		}
+		l, ok := layers[message.ID]
+		if !ok {
+			l = &layer{}
+			layers[message.ID] = l
+			order = append(order, message.ID)
+		}
+		switch message.Status {
+		case "Downloading":
+			l.current, l.total = message.ProgressDetail.Current, message.ProgressDetail.Total
+		case "Download complete", "Pull complete", "Already exists":
+			l.current = l.total
+		default:
+			continue
+		}
+		if progress != nil {
+			var current, total int64
+			for _, id := range order {
+				current += layers[id].current
+				total += layers[id].total
+			}
+			progress(current, total)
+		}
+	}
+}
+
+func hasTagOrDigest(image string) bool {
+	if strings.Contains(image, "@") {
+		return true
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	return strings.Contains(name, ":")
+}
@@ -0,0 +1,233 @@
+// Package download fetches large files with HTTP range requests so an
+// interrupted transfer continues where it stopped instead of starting over.
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// checkpointEvery is how often the partial file's state is recorded.
+const checkpointEvery = 4 << 20
+
+// retries is how many times a dropped connection is resumed within one
+// Fetch before giving up.
+const retries = 3
+
+type Options struct {
+	// SHA256 is the expected hex digest of the whole file, if known.
+	SHA256 string
+	// Progress is called with the bytes on disk and the total size (0 when
+	// the server does not say).
+	Progress func(current, total int64)
+}
+
+// checkpoint describes dest.part: the first Bytes bytes hash to SHA256 and
+// came from URL with the given validators.
+type checkpoint struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Size         int64  `json:"size"`
+	Bytes        int64  `json:"bytes"`
+	SHA256       string `json:"sha256"`
+}
+
+// Fetch downloads url to dest. A previous partial download of the same URL
+// (dest.part with its dest.part.json checkpoint) is resumed when the server
+// supports ranges and the file has not changed. The part is only renamed to
+// dest once it is complete and matches opts.SHA256.
+func Fetch(ctx context.Context, client *http.Client, url, dest string, opts Options) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	partPath, checkpointPath := dest+".part", dest+".part.json"
+
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * time.Second):
+			}
+		}
+		var sum string
+		sum, err = fetchOnce(ctx, client, url, partPath, checkpointPath, opts.Progress)
+		if err == nil {
+			if opts.SHA256 != "" && !strings.EqualFold(sum, opts.SHA256) {
+				discard(partPath, checkpointPath)
+				return fmt.Errorf("checksum mismatch for %s: got %s, want %s", url, sum, opts.SHA256)
+			}
+			if err := os.Rename(partPath, dest); err != nil {
+				return err
+			}
+			_ = os.Remove(checkpointPath)
+			return nil
+		}
+		var status statusError
+		if errors.As(err, &status) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+type statusError struct {
+	status string
+}
+
+func (e statusError) Error() string { return "download failed with status: " + e.status }
+
+// fetchOnce resumes or starts the transfer and returns the hex SHA-256 of the
+// complete part file.
+func fetchOnce(ctx context.Context, client *http.Client, url, partPath, checkpointPath string, progress func(int64, int64)) (string, error) {
+	state, digest := resumeState(url, partPath, checkpointPath)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	if state.Bytes > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", state.Bytes))
+		if validator := fallback(state.ETag, state.LastModified); validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && state.Bytes > 0:
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && state.Bytes > 0 && state.Bytes == state.Size:
+		return hex.EncodeToString(digest.Sum(nil)), nil
+	case resp.StatusCode == http.StatusOK:
+		// Fresh download, or the server ignored the range / the file changed.
+		state = checkpoint{URL: url, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+		if resp.ContentLength > 0 {
+			state.Size = resp.ContentLength
+		}
+		digest = sha256.New()
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The checkpoint no longer fits the remote file; start over next time.
+		discard(partPath, checkpointPath)
+		return "", statusError{status: resp.Status}
+	default:
+		return "", statusError{status: resp.Status}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if state.Bytes == 0 {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(partPath, flags, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if err := file.Truncate(state.Bytes); err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(state.Bytes, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	report := func() {
+		if progress != nil {
+			progress(state.Bytes, state.Size)
+		}
+	}
+	report()
+	buf := make([]byte, 64<<10)
+	sinceCheckpoint := int64(0)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, err := file.Write(buf[:n]); err != nil {
+				return "", err
+			}
+			digest.Write(buf[:n])
+			state.Bytes += int64(n)
+			sinceCheckpoint += int64(n)
+			report()
+			if sinceCheckpoint >= checkpointEvery {
+				sinceCheckpoint = 0
+				saveCheckpoint(checkpointPath, state, digest)
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			saveCheckpoint(checkpointPath, state, digest)
+			return "", readErr
+		}
+	}
+	if state.Size > 0 && state.Bytes != state.Size {
+		saveCheckpoint(checkpointPath, state, digest)
+		return "", fmt.Errorf("download ended early: got %d of %d bytes", state.Bytes, state.Size)
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// resumeState loads the checkpoint for url and re-hashes the recorded
+// prefix of the part file. Anything that doesn't line up starts over.
+func resumeState(url, partPath, checkpointPath string) (checkpoint, hash.Hash) {
+	fresh := checkpoint{URL: url}
+	data, err := os.ReadFile(checkpointPath)
+	if err != nil {
+		return fresh, sha256.New()
+	}
+	var state checkpoint
+	if json.Unmarshal(data, &state) != nil || state.URL != url || state.Bytes <= 0 {
+		return fresh, sha256.New()
+	}
+	file, err := os.Open(partPath)
+	if err != nil {
+		return fresh, sha256.New()
+	}
+	defer file.Close()
+	digest := sha256.New()
+	if n, err := io.CopyN(digest, file, state.Bytes); err != nil || n != state.Bytes {
+		return fresh, sha256.New()
+	}
+	if hex.EncodeToString(digest.Sum(nil)) != state.SHA256 {
+		return fresh, sha256.New()
+	}
+	return state, digest
+}
+
+func saveCheckpoint(path string, state checkpoint, digest hash.Hash) {
+	state.SHA256 = hex.EncodeToString(digest.Sum(nil))
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0o644)
+}
+
+func discard(paths ...string) {
+	for _, path := range paths {
+		_ = os.Remove(path)
+	}
+}
+
+func fallback(value, fallbackValue string) string {
+	if value != "" {
+		return value
+	}
+	return fallbackValue
+}
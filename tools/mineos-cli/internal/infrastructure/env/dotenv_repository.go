@@ -2,6 +2,7 @@ package env
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"github.com/joho/godotenv"
@@ -9,8 +10,17 @@ import (
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
 )
 
+// DotenvRepository reads the configuration from layered env files. Later
+// layers override earlier ones:
+//
+//  1. the main file (.env, or the --env path)
+//  2. <main file>.local next to it, when present
+//  3. each overlay (--env-overlay), in the order given
+//
+// The main file must exist; overlays given explicitly must exist too.
 type DotenvRepository struct {
-	path string
+	path     string
+	overlays []string
 }
 
 func NewDotenvRepository(path string) *DotenvRepository {
@@ -27,6 +37,20 @@ func (r *DotenvRepository) Load(_ context.Context) (config.Config, error) {
 		}
 		return cfg, err
 	}
+	layers, err := Layers(r.path, r.overlays)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.EnvOverlays = layers[1:]
+	for _, layer := range cfg.EnvOverlays {
+		overlay, err := godotenv.Read(layer)
+		if err != nil {
+			return cfg, fmt.Errorf("read env overlay %s: %w", layer, err)
+		}
+		for key, value := range overlay {
+			values[key] = value
+		}
+	}
 
 	cfg.ApiPort = values["API_PORT"]
 	cfg.WebOrigin = values["WEB_ORIGIN_PROD"]
@@ -45,11 +69,21 @@ func (r *DotenvRepository) Load(_ context.Context) (config.Config, error) {
 	cfg.DatabaseConnection = values["ConnectionStrings__DefaultConnection"]
 	cfg.DataDirectory = values["Data__Directory"]
 	cfg.ShutdownTimeout = values["MINEOS_SHUTDOWN_TIMEOUT"]
+	cfg.DockerWait = values["MINEOS_DOCKER_WAIT"]
+	cfg.PersistServiceLogs = values["MINEOS_PERSIST_SERVICE_LOGS"]
 	cfg.PreReleaseUpdates = values["MINEOS_CLI_PRERELEASE_UPDATES"]
 	cfg.TelemetryEnabled = values["MINEOS_TELEMETRY_ENABLED"]
 	cfg.TelemetryEndpoint = values["MINEOS_TELEMETRY_ENDPOINT"]
 	cfg.InstallationID = values["MINEOS_INSTALLATION_ID"]
 	cfg.TelemetryKey = values["MINEOS_TELEMETRY_KEY"]
+	cfg.TuiReadOnly = values["MINEOS_TUI_READ_ONLY"]
+	cfg.ConfirmLevel = values["MINEOS_CONFIRM_LEVEL"]
+	cfg.BindAddress = values["MINEOS_BIND_ADDRESS"]
+	cfg.ApiMemoryLimit = values["MINEOS_API_MEMORY_LIMIT"]
+	cfg.ApiCpuLimit = values["MINEOS_API_CPU_LIMIT"]
+	cfg.WebMemoryLimit = values["MINEOS_WEB_MEMORY_LIMIT"]
+	cfg.WebCpuLimit = values["MINEOS_WEB_CPU_LIMIT"]
+	cfg.ComposeOverrides = values["MINEOS_COMPOSE_OVERRIDES"]
 
 	return cfg, nil
 }
@@ -61,3 +95,25 @@ func (r *DotenvRepository) SetPath(path string) {
 func (r *DotenvRepository) Path() string {
 	return r.path
 }
+
+// SetOverlays sets the env files layered over the main file.
+func (r *DotenvRepository) SetOverlays(paths []string) {
+	r.overlays = append([]string(nil), paths...)
+}
+
+// Layers lists the env files for path in precedence order, lowest first:
+// path itself, path.local when it exists, then the overlays. A missing
+// overlay is an error since it was asked for by name.
+func Layers(path string, overlays []string) ([]string, error) {
+	layers := []string{path}
+	if info, err := os.Stat(path + ".local"); err == nil && !info.IsDir() {
+		layers = append(layers, path+".local")
+	}
+	for _, overlay := range overlays {
+		if _, err := os.Stat(overlay); err != nil {
+			return nil, fmt.Errorf("env overlay %s: %w", overlay, err)
+		}
+		layers = append(layers, overlay)
+	}
+	return layers, nil
+}
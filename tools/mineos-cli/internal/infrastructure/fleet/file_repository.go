@@ -0,0 +1,63 @@
+package fleet
+
+import (
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/fleet"
+)
+
+// DefaultFileName is where contexts were kept in the user's config directory
+// before they moved into the state store.
+const DefaultFileName = "contexts.yaml"
+
+// FileEnv names a contexts file to read instead of the state store.
+const FileEnv = "MINEOS_CONTEXTS_FILE"
+
+// FileRepository reads contexts from a file of the user's own.
+type FileRepository struct {
+	path string
+}
+
+func NewFileRepository(path string) *FileRepository {
+	return &FileRepository{path: path}
+}
+
+// LegacyPath is contexts.yaml in the user's config directory
+// (~/.config/mineos on Linux).
+func LegacyPath() string {
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return DefaultFileName
+	}
+	return filepath.Join(config, "mineos", DefaultFileName)
+}
+
+func (r *FileRepository) Path() string {
+	return r.path
+}
+
+// Load reads the contexts file. The boolean is false when the file does not
+// exist, in which case an empty definition is returned.
+func (r *FileRepository) Load() (domain.Definition, bool, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return domain.Definition{}, false, nil
+		}
+		return domain.Definition{}, false, err
+	}
+	def, err := Parse(data)
+	return def, true, err
+}
+
+// Parse reads and validates a contexts document.
+func Parse(data []byte) (domain.Definition, error) {
+	var def domain.Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return domain.Definition{}, err
+	}
+	return def, def.Validate()
+}
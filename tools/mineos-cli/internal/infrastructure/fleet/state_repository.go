@@ -0,0 +1,71 @@
+package fleet
+
+import (
+	"context"
+	"path/filepath"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/fleet"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+// StateRepository keeps the contexts in the state store, since they span
+// installations rather than belonging to one. The first read imports the
+// contexts file at LegacyPath.
+type StateRepository struct {
+	statePath string
+	legacy    string
+}
+
+func NewStateRepository(statePath string) *StateRepository {
+	return &StateRepository{statePath: statePath, legacy: LegacyPath()}
+}
+
+// Path is where the contexts are, for messages.
+func (r *StateRepository) Path() string {
+	return "the state store (" + r.statePath + ")"
+}
+
+// Dir is where relative env paths of contexts start: the config directory
+// the contexts file was in, so imported contexts keep working.
+func (r *StateRepository) Dir() string {
+	return filepath.Dir(r.legacy)
+}
+
+// Read returns the contexts document. The boolean is false when there is
+// none yet.
+func (r *StateRepository) Read() ([]byte, bool, error) {
+	ctx := context.Background()
+	store, err := state.Open(r.statePath)
+	if err != nil {
+		return nil, false, err
+	}
+	defer store.Close()
+	if err := store.ImportFile(ctx, state.NamespaceContexts, state.GlobalKey, r.legacy); err != nil {
+		return nil, false, err
+	}
+	return store.Document(ctx, state.NamespaceContexts, state.GlobalKey)
+}
+
+// Load reads and validates the contexts. The boolean is false when there
+// are none yet, in which case an empty definition is returned.
+func (r *StateRepository) Load() (domain.Definition, bool, error) {
+	data, found, err := r.Read()
+	if err != nil || !found {
+		return domain.Definition{}, found, err
+	}
+	def, err := Parse(data)
+	return def, true, err
+}
+
+// Save validates and stores a contexts document.
+func (r *StateRepository) Save(data []byte) error {
+	if _, err := Parse(data); err != nil {
+		return err
+	}
+	store, err := state.Open(r.statePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	return store.PutDocument(context.Background(), state.NamespaceContexts, state.GlobalKey, data)
+}
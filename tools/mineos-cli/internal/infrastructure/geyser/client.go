@@ -0,0 +1,126 @@
+package geyser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/ratelimit"
+)
+
+const downloadAPI = "https://download.geysermc.org/v2/projects"
+
+// Projects published by GeyserMC.
+const (
+	ProjectGeyser    = "geyser"
+	ProjectFloodgate = "floodgate"
+)
+
+// Build is one downloadable jar.
+type Build struct {
+	Project  string
+	Version  string
+	Build    int
+	FileName string
+	SHA256   string
+	URL      string
+}
+
+type Client struct {
+	httpClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: 2 * time.Minute}}
+}
+
+// SetRateLimit caps downloads with l; nil removes the limit. The timeout is
+// lifted while limited since a throttled jar can take a while.
+func (c *Client) SetRateLimit(l *ratelimit.Limiter) {
+	c.httpClient.Transport = ratelimit.Transport(nil, l)
+	if l != nil {
+		c.httpClient.Timeout = 0
+	}
+}
+
+// Latest returns the newest build of a project for a download key such as
+// "spigot", "velocity" or "bungeecord".
+func (c *Client) Latest(ctx context.Context, project, download string) (Build, error) {
+	var result struct {
+		Version   string `json:"version"`
+		Build     int    `json:"build"`
+		Downloads map[string]struct {
+			Name   string `json:"name"`
+			SHA256 string `json:"sha256"`
+		} `json:"downloads"`
+	}
+	endpoint := fmt.Sprintf("%s/%s/versions/latest/builds/latest", downloadAPI, url.PathEscape(project))
+	if err := c.getJSON(ctx, endpoint, &result); err != nil {
+		return Build{}, err
+	}
+	file, ok := result.Downloads[download]
+	if !ok {
+		return Build{}, fmt.Errorf("%s has no %s download", project, download)
+	}
+	return Build{
+		Project:  project,
+		Version:  result.Version,
+		Build:    result.Build,
+		FileName: file.Name,
+		SHA256:   file.SHA256,
+		URL:      fmt.Sprintf("%s/%s/versions/%s/builds/%d/downloads/%s", downloadAPI, url.PathEscape(project), url.PathEscape(result.Version), result.Build, url.PathEscape(download)),
+	}, nil
+}
+
+// Download fetches a build and verifies its checksum.
+func (c *Client) Download(ctx context.Context, build Build) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, build.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "mineos-cli")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s returned %d", build.URL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if build.SHA256 != "" {
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), build.SHA256) {
+			return nil, fmt.Errorf("checksum mismatch for %s", build.FileName)
+		}
+	}
+	return data, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "mineos-cli")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,122 @@
+package guard
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/guard"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+// DefaultBansFileName is kept in the installation's state directory.
+const DefaultBansFileName = "mineos-guard-bans.json"
+
+// BanStore keeps the active bans so the agent can lift them after a restart
+// and 'mineos agent bans' can list them.
+type BanStore struct {
+	path string
+}
+
+func NewBanStore(path string) *BanStore {
+	return &BanStore{path: path}
+}
+
+// NewBanStoreForEnv returns the store of the installation whose .env is
+// envPath.
+func NewBanStoreForEnv(envPath string) *BanStore {
+	return NewBanStore(state.FileForEnv(envPath, DefaultBansFileName))
+}
+
+func (s *BanStore) Path() string {
+	return s.path
+}
+
+// Load returns the bans, soonest to end first; a missing file is no bans.
+func (s *BanStore) Load() ([]domain.Ban, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []domain.Ban{}, nil
+		}
+		return nil, err
+	}
+	var bans []domain.Ban
+	if err := json.Unmarshal(data, &bans); err != nil {
+		return nil, err
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Until.Before(bans[j].Until) })
+	return bans, nil
+}
+
+func (s *BanStore) Save(bans []domain.Ban) error {
+	data, err := json.MarshalIndent(bans, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Add records a ban, replacing an earlier one for the same address.
+func (s *BanStore) Add(ban domain.Ban) error {
+	bans, err := s.Load()
+	if err != nil {
+		return err
+	}
+	bans, _ = without(bans, ban.IP)
+	return s.Save(append(bans, ban))
+}
+
+// Remove drops the ban on ip and returns it.
+func (s *BanStore) Remove(ip string) (domain.Ban, bool, error) {
+	bans, err := s.Load()
+	if err != nil {
+		return domain.Ban{}, false, err
+	}
+	bans, removed := without(bans, ip)
+	if removed == nil {
+		return domain.Ban{}, false, nil
+	}
+	return *removed, true, s.Save(bans)
+}
+
+// Expired drops and returns the bans that ended by now.
+func (s *BanStore) Expired(now time.Time) ([]domain.Ban, error) {
+	bans, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	var active, expired []domain.Ban
+	for _, ban := range bans {
+		if now.Before(ban.Until) {
+			active = append(active, ban)
+		} else {
+			expired = append(expired, ban)
+		}
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+	if active == nil {
+		active = []domain.Ban{}
+	}
+	return expired, s.Save(active)
+}
+
+func without(bans []domain.Ban, ip string) ([]domain.Ban, *domain.Ban) {
+	kept := make([]domain.Ban, 0, len(bans))
+	var removed *domain.Ban
+	for i := range bans {
+		if bans[i].IP == ip {
+			removed = &bans[i]
+			continue
+		}
+		kept = append(kept, bans[i])
+	}
+	return kept, removed
+}
@@ -0,0 +1,165 @@
+package guard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/guard"
+)
+
+// Firewall blocks and unblocks single addresses.
+type Firewall interface {
+	Name() string
+	// Setup prepares the firewall, e.g. creates the table bans are added to.
+	Setup(ctx context.Context) error
+	Block(ctx context.Context, ip string, duration time.Duration) error
+	// Unblock lifts a block; lifting one that is already gone is not an error.
+	Unblock(ctx context.Context, ip string) error
+}
+
+// NewFirewall returns the backend for kind. Auto picks nftables when nft is
+// installed, then ufw.
+func NewFirewall(kind string) (Firewall, error) {
+	switch kind {
+	case domain.FirewallNone:
+		return noFirewall{}, nil
+	case domain.FirewallNftables:
+		return nftables{}, nil
+	case domain.FirewallUfw:
+		return ufw{}, nil
+	case "", domain.FirewallAuto:
+		if _, err := exec.LookPath("nft"); err == nil {
+			return nftables{}, nil
+		}
+		if _, err := exec.LookPath("ufw"); err == nil {
+			return ufw{}, nil
+		}
+		return nil, fmt.Errorf("neither nft nor ufw is installed; set firewall: none to only report offenders")
+	default:
+		return nil, fmt.Errorf("unknown firewall %q", kind)
+	}
+}
+
+type noFirewall struct{}
+
+func (noFirewall) Name() string                                       { return domain.FirewallNone }
+func (noFirewall) Setup(context.Context) error                        { return nil }
+func (noFirewall) Block(context.Context, string, time.Duration) error { return nil }
+func (noFirewall) Unblock(context.Context, string) error              { return nil }
+
+const nftTable = "mineos_guard"
+
+// nftScript creates a table whose prerouting chain runs before Docker's NAT,
+// so ports published by containers are covered too. The sets expire bans by
+// themselves.
+const nftScript = `table inet ` + nftTable + ` {
+	set blocked4 { type ipv4_addr; flags timeout; }
+	set blocked6 { type ipv6_addr; flags timeout; }
+	chain prerouting {
+		type filter hook prerouting priority -300; policy accept;
+		ip saddr @blocked4 drop
+		ip6 saddr @blocked6 drop
+	}
+}
+`
+
+type nftables struct{}
+
+func (nftables) Name() string { return domain.FirewallNftables }
+
+func (nftables) Setup(ctx context.Context) error {
+	if _, err := run(ctx, nil, "nft", "list", "table", "inet", nftTable); err == nil {
+		return nil
+	}
+	_, err := run(ctx, strings.NewReader(nftScript), "nft", "-f", "-")
+	return err
+}
+
+func (nftables) Block(ctx context.Context, ip string, duration time.Duration) error {
+	set, err := nftSet(ip)
+	if err != nil {
+		return err
+	}
+	seconds := max(int(duration.Seconds()), 1)
+	_, err = run(ctx, nil, "nft", "add", "element", "inet", nftTable, set, fmt.Sprintf("{ %s timeout %ds }", ip, seconds))
+	return err
+}
+
+func (nftables) Unblock(ctx context.Context, ip string) error {
+	set, err := nftSet(ip)
+	if err != nil {
+		return err
+	}
+	output, err := run(ctx, nil, "nft", "delete", "element", "inet", nftTable, set, "{ "+ip+" }")
+	if err != nil && strings.Contains(output, "No such file or directory") {
+		return nil
+	}
+	return err
+}
+
+func nftSet(ip string) (string, error) {
+	parsed := net.ParseIP(ip)
+	switch {
+	case parsed == nil:
+		return "", fmt.Errorf("invalid address %q", ip)
+	case parsed.To4() != nil:
+		return "blocked4", nil
+	default:
+		return "blocked6", nil
+	}
+}
+
+// ufw rules do not expire, so the agent lifts them when a ban ends. ufw only
+// filters ports Docker publishes when it has been set up to, e.g. with
+// ufw-docker.
+type ufw struct{}
+
+func (ufw) Name() string { return domain.FirewallUfw }
+
+func (ufw) Setup(ctx context.Context) error {
+	_, err := run(ctx, nil, "ufw", "status")
+	return err
+}
+
+func (ufw) Block(ctx context.Context, ip string, _ time.Duration) error {
+	output, err := run(ctx, nil, "ufw", "prepend", "deny", "from", ip, "comment", "mineos-guard")
+	if err != nil && strings.Contains(output, "Invalid position") {
+		// prepend (like insert 1) fails while there are no rules of the IP's
+		// family yet; with nothing to come before, a plain rule is first.
+		_, err = run(ctx, nil, "ufw", "deny", "from", ip, "comment", "mineos-guard")
+	}
+	return err
+}
+
+func (ufw) Unblock(ctx context.Context, ip string) error {
+	output, err := run(ctx, nil, "ufw", "delete", "deny", "from", ip)
+	if err != nil && strings.Contains(output, "Could not delete non-existent rule") {
+		return nil
+	}
+	return err
+}
+
+// run executes a firewall command and returns its combined output, which is
+// also the error message when it fails.
+func run(ctx context.Context, stdin *strings.Reader, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		text := strings.TrimSpace(output.String())
+		if text == "" {
+			text = err.Error()
+		}
+		return text, fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), text)
+	}
+	return output.String(), nil
+}
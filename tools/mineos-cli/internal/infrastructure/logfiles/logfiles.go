@@ -0,0 +1,88 @@
+package logfiles
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// File is one log in a server's logs directory.
+type File struct {
+	Path string
+	// Day is the date the log starts on: from the rotated file name, or the
+	// modification time for latest.log.
+	Day time.Time
+	// LastWrite is the file's modification time.
+	LastWrite time.Time
+}
+
+func (f File) Name() string {
+	return filepath.Base(f.Path)
+}
+
+var rotatedName = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-\d+\.log(?:\.gz)?$`)
+
+// List returns the plain and gzipped logs in dir, oldest first.
+func List(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz")) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		file := File{Path: filepath.Join(dir, name), Day: info.ModTime(), LastWrite: info.ModTime()}
+		if m := rotatedName.FindStringSubmatch(name); m != nil {
+			if day, err := time.ParseInLocation("2006-01-02", m[1], time.Local); err == nil {
+				file.Day = day
+			}
+		}
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].Day.Equal(files[j].Day) {
+			return files[i].Day.Before(files[j].Day)
+		}
+		return files[i].LastWrite.Before(files[j].LastWrite)
+	})
+	return files, nil
+}
+
+// Open returns a reader over the file's text, decompressing .gz logs.
+func Open(file File) (io.ReadCloser, error) {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(file.Path, ".gz") {
+		return f, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &gzipFile{Reader: gz, file: f}, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
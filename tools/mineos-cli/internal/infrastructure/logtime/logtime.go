@@ -0,0 +1,170 @@
+// Package logtime puts the timestamps of Docker and Minecraft log lines into
+// one format and time zone, and filters lines by time.
+package logtime
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Layout is the timestamp format of every normalized log line.
+const Layout = "2006-01-02 15:04:05"
+
+// dockerTimestamp matches the RFC3339Nano stamp 'docker compose logs
+// --timestamps' puts after the "service-1  | " prefix.
+var dockerTimestamp = regexp.MustCompile(`^(.*?\| )?(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:\d{2})) ?`)
+
+// bracketTime matches the "[12:34:56]" prefix of vanilla/Paper console lines
+// and the "[01Jan2024 12:34:56.789]" prefix of Forge.
+var bracketTime = regexp.MustCompile(`^\[(\d{2}[A-Za-z]{3}\d{4} )?(\d{2}:\d{2}:\d{2})(?:\.\d+)?\] ?`)
+
+// Normalizer rewrites log timestamps into Location.
+type Normalizer struct {
+	Location *time.Location
+}
+
+// New returns a Normalizer for the local time zone, or UTC.
+func New(utc bool) Normalizer {
+	if utc {
+		return Normalizer{Location: time.UTC}
+	}
+	return Normalizer{Location: time.Local}
+}
+
+// Format renders t in the normalizer's zone.
+func (n Normalizer) Format(t time.Time) string {
+	return t.In(n.location()).Format(Layout)
+}
+
+// DockerLine parses a compose log line and returns its time and the line
+// with the timestamp rewritten. Lines without a timestamp are returned as-is
+// with a zero time.
+func (n Normalizer) DockerLine(line string) (time.Time, string) {
+	prefix, t, text := SplitDocker(line)
+	if t.IsZero() {
+		return t, line
+	}
+	return t, prefix + n.Format(t) + " " + text
+}
+
+// SplitDocker takes a compose log line apart into its "service-1  | "
+// prefix, if any, its timestamp and the text the service logged. Without a
+// timestamp, the time is zero and text is the whole line.
+func SplitDocker(line string) (prefix string, t time.Time, text string) {
+	match := dockerTimestamp.FindStringSubmatchIndex(line)
+	if match == nil {
+		return "", time.Time{}, line
+	}
+	t, err := time.Parse(time.RFC3339Nano, line[match[4]:match[5]])
+	if err != nil {
+		return "", time.Time{}, line
+	}
+	if match[2] >= 0 {
+		prefix = line[match[2]:match[3]]
+	}
+	return prefix, t, line[match[1]:]
+}
+
+// MinecraftEntry normalizes a console line from the API. stamp is the time
+// the API recorded, preferred when set; otherwise the server's own bracket
+// time is used, read as UTC (the container default) on the date of now.
+// The bracket time is dropped from the message either way so each line
+// carries a single timestamp.
+func (n Normalizer) MinecraftEntry(stamp time.Time, message string, now time.Time) (time.Time, string) {
+	match := bracketTime.FindStringSubmatch(message)
+	rest := message
+	if match != nil {
+		rest = message[len(match[0]):]
+	}
+	if stamp.IsZero() && match != nil {
+		stamp = parseBracket(match[1], match[2], now)
+	}
+	if stamp.IsZero() {
+		return stamp, message
+	}
+	return stamp, fmt.Sprintf("[%s] %s", n.Format(stamp), rest)
+}
+
+func parseBracket(date, clock string, now time.Time) time.Time {
+	date = strings.TrimSpace(date)
+	if date != "" {
+		t, err := time.ParseInLocation("02Jan2006 15:04:05", date+" "+clock, time.UTC)
+		if err != nil {
+			return time.Time{}
+		}
+		return t
+	}
+	t, err := time.ParseInLocation(time.DateOnly+" 15:04:05", now.UTC().Format(time.DateOnly)+" "+clock, time.UTC)
+	if err != nil {
+		return time.Time{}
+	}
+	// Just after midnight, lines from the end of yesterday.
+	if t.After(now.Add(time.Minute)) {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t
+}
+
+// Window keeps lines between Since and Until; a zero bound is open.
+type Window struct {
+	Since time.Time
+	Until time.Time
+}
+
+// Contains reports whether t is inside the window. Lines without a time
+// (continuations, stack traces) are kept.
+func (w Window) Contains(t time.Time) bool {
+	if t.IsZero() {
+		return true
+	}
+	if !w.Since.IsZero() && t.Before(w.Since) {
+		return false
+	}
+	if !w.Until.IsZero() && t.After(w.Until) {
+		return false
+	}
+	return true
+}
+
+// ParseBound reads a --since/--until value: a duration before now ("15m",
+// "2h", "3d"), an RFC3339 time, or a date/time in the normalizer's zone
+// ("2024-05-01", "2024-05-01 18:30", "18:30" for today).
+func (n Normalizer) ParseBound(raw string, now time.Time) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		if d, err := time.ParseDuration(days + "h"); err == nil {
+			return now.Add(-24 * d), nil
+		}
+	}
+	if d, err := time.ParseDuration(raw); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return t, nil
+	}
+	loc := n.location()
+	for _, layout := range []string{Layout, "2006-01-02 15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if clock, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			local := now.In(loc)
+			return time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, loc), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use a duration like 15m, or 2006-01-02 15:04)", raw)
+}
+
+func (n Normalizer) location() *time.Location {
+	if n.Location == nil {
+		return time.Local
+	}
+	return n.Location
+}
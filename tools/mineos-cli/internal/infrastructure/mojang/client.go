@@ -0,0 +1,66 @@
+package mojang
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const profileAPI = "https://api.mojang.com/users/profiles/minecraft/"
+
+// ErrUnknownPlayer is returned when no Java account has the requested name.
+var ErrUnknownPlayer = errors.New("unknown player")
+
+// Profile is a Java Edition account.
+type Profile struct {
+	UUID string
+	Name string
+}
+
+type Client struct {
+	httpClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Lookup resolves a player name to its account, returning the UUID in the
+// dashed form used by server files.
+func (c *Client) Lookup(ctx context.Context, name string) (Profile, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, profileAPI+url.PathEscape(name), nil)
+	if err != nil {
+		return Profile{}, err
+	}
+	req.Header.Set("User-Agent", "mineos-cli")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Profile{}, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNoContent:
+		return Profile{}, fmt.Errorf("%w: %s", ErrUnknownPlayer, name)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return Profile{}, fmt.Errorf("player lookup failed: %s", resp.Status)
+	}
+	var result struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Profile{}, err
+	}
+	if len(result.ID) != 32 {
+		return Profile{}, fmt.Errorf("player lookup returned malformed id %q", result.ID)
+	}
+	id := result.ID
+	return Profile{
+		UUID: id[0:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:32],
+		Name: result.Name,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/network"
+)
+
+// DefaultFileName is looked up next to the .env file.
+const DefaultFileName = "mineos-network.yaml"
+
+type FileRepository struct {
+	path string
+}
+
+func NewFileRepository(path string) *FileRepository {
+	return &FileRepository{path: path}
+}
+
+// NewFileRepositoryForEnv returns a repository for the orchestration file that
+// sits beside the given .env file.
+func NewFileRepositoryForEnv(envPath string) *FileRepository {
+	if envPath == "" {
+		envPath = ".env"
+	}
+	return NewFileRepository(filepath.Join(filepath.Dir(envPath), DefaultFileName))
+}
+
+func (r *FileRepository) Path() string {
+	return r.path
+}
+
+// Load reads the orchestration file. The boolean is false when the file does
+// not exist, in which case an empty definition is returned.
+func (r *FileRepository) Load() (domain.Definition, bool, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return domain.Definition{}, false, nil
+		}
+		return domain.Definition{}, false, err
+	}
+	var def domain.Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return domain.Definition{}, true, err
+	}
+	return def, true, nil
+}
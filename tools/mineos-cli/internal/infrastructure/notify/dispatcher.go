@@ -0,0 +1,128 @@
+// Package notify sends notifications to a Discord webhook and to generic
+// JSON webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+	SeverityResolved Severity = "resolved"
+)
+
+// Notification is posted as-is, in JSON, to generic webhooks.
+type Notification struct {
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Severity Severity          `json:"severity"`
+	Time     time.Time         `json:"time"`
+	Fields   map[string]string `json:"fields,omitempty"`
+}
+
+// Dispatcher delivers each notification to every configured target.
+type Dispatcher struct {
+	Discord  string
+	Webhooks []string
+	Client   *http.Client
+}
+
+func NewDispatcher(discord string, webhooks []string) *Dispatcher {
+	return &Dispatcher{
+		Discord:  strings.TrimSpace(discord),
+		Webhooks: webhooks,
+		Client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Configured reports whether there is anywhere to send to.
+func (d *Dispatcher) Configured() bool {
+	return d.Discord != "" || len(d.Webhooks) > 0
+}
+
+// Send posts n to every target and joins the errors of the targets that
+// failed; the others still receive it.
+func (d *Dispatcher) Send(ctx context.Context, n Notification) error {
+	if n.Time.IsZero() {
+		n.Time = time.Now().UTC()
+	}
+	var errs []error
+	if d.Discord != "" {
+		if err := d.post(ctx, d.Discord, discordPayload(n)); err != nil {
+			errs = append(errs, fmt.Errorf("discord: %w", err))
+		}
+	}
+	for _, url := range d.Webhooks {
+		if strings.TrimSpace(url) == "" {
+			continue
+		}
+		if err := d.post(ctx, strings.TrimSpace(url), n); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) post(ctx context.Context, url string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+var discordColors = map[Severity]int{
+	SeverityInfo:     0x3498db,
+	SeverityWarning:  0xf1c40f,
+	SeverityCritical: 0xe74c3c,
+	SeverityResolved: 0x2ecc71,
+}
+
+func discordPayload(n Notification) map[string]any {
+	embed := map[string]any{
+		"title":       n.Title,
+		"description": n.Message,
+		"color":       discordColors[n.Severity],
+		"timestamp":   n.Time.UTC().Format(time.RFC3339),
+	}
+	if len(n.Fields) > 0 {
+		names := make([]string, 0, len(n.Fields))
+		for name := range n.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var fields []map[string]any
+		for _, name := range names {
+			fields = append(fields, map[string]any{"name": name, "value": n.Fields[name], "inline": true})
+		}
+		embed["fields"] = fields
+	}
+	return map[string]any{"username": "MineOS", "embeds": []any{embed}}
+}
@@ -0,0 +1,86 @@
+package panels
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Size is the total size of the regular files under dir, for progress.
+func Size(dir string) int64 {
+	var total int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// Pack writes dir as a .tar.gz, the form the MineOS import takes. Modes are
+// kept, so start scripts stay executable; file contents are also written to
+// progress, when set.
+func Pack(w io.Writer, dir string, progress io.Writer) error {
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if d.Type()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		} else if !d.IsDir() && !d.Type().IsRegular() {
+			// Sockets and pipes, e.g. left by a console wrapper.
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if d.IsDir() {
+			header.Name += "/"
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		var dst io.Writer = archive
+		if progress != nil {
+			dst = io.MultiWriter(archive, progress)
+		}
+		_, err = io.Copy(dst, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,216 @@
+// Package panels finds servers left by other panels and hand-run setups on
+// disk, for 'mineos migrate'.
+package panels
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/migrate"
+)
+
+// DefaultRoots are where Pterodactyl, AMP and hand-run setups usually keep
+// their servers.
+func DefaultRoots() []string {
+	roots := []string{"/var/lib/pterodactyl/volumes", "/home/amp/.ampdata/instances", "/opt/minecraft", "/srv/minecraft"}
+	if home, err := os.UserHomeDir(); err == nil {
+		roots = append(roots, filepath.Join(home, ".ampdata", "instances"), filepath.Join(home, "minecraft"))
+	}
+	return roots
+}
+
+// Scan finds servers at root and up to three levels below it, which covers
+// volumes/<uuid> and instances/<name>/Minecraft. A server's own
+// subdirectories are not searched.
+func Scan(ctx context.Context, root string) []migrate.Source {
+	var sources []migrate.Source
+	var walk func(dir string, depth int)
+	walk = func(dir string, depth int) {
+		if source, ok := Detect(ctx, dir); ok {
+			sources = append(sources, source)
+			return
+		}
+		if depth == 0 {
+			return
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+				walk(filepath.Join(dir, entry.Name()), depth-1)
+			}
+		}
+	}
+	walk(filepath.Clean(root), 3)
+	return sources
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// Detect reads dir as a server directory: one with server.properties, or an
+// eula.txt next to a jar.
+func Detect(ctx context.Context, dir string) (migrate.Source, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil || !isServerDir(dir) {
+		return migrate.Source{}, false
+	}
+	source := migrate.Source{Dir: dir}
+	base := filepath.Base(dir)
+	parent := filepath.Dir(dir)
+
+	switch {
+	case uuidPattern.MatchString(base) && filepath.Base(parent) == "volumes":
+		source.Panel = migrate.PanelPterodactyl
+		source.Name = "pterodactyl-" + base[:8]
+		source.Java, source.Notes = pterodactylJava(ctx, base)
+	case hasKVP(parent):
+		source.Panel = migrate.PanelAMP
+		source.Name = migrate.ServerName(filepath.Base(parent))
+		source.Java = ampJava(parent)
+	default:
+		source.Panel = migrate.PanelScript
+		source.Name = migrate.ServerName(base)
+		source.Java, source.Notes = scriptJava(dir)
+	}
+
+	if source.JarFile == "" && len(source.ArgFiles) == 0 {
+		source.JarFile = guessJar(dir)
+	}
+	switch {
+	case len(source.ArgFiles) > 0:
+		source.Notes = append(source.Notes, "started from @argument files (a Forge or NeoForge run script); choose the server jar in the web UI after importing")
+	case source.JarFile == "":
+		source.Notes = append(source.Notes, "no server jar found; choose one in the web UI after importing")
+	}
+	if source.XmxMB == 0 {
+		source.Notes = append(source.Notes, "memory limit not found; the MineOS default is used")
+	}
+	source.Port = serverPort(dir)
+	return source, true
+}
+
+func isServerDir(dir string) bool {
+	if info, err := os.Stat(filepath.Join(dir, "server.properties")); err == nil && !info.IsDir() {
+		return true
+	}
+	if _, err := os.Stat(filepath.Join(dir, "eula.txt")); err == nil {
+		return guessJar(dir) != ""
+	}
+	return false
+}
+
+// pterodactylJava reads the environment Wings gave the server's container,
+// which is named after the volume.
+func pterodactylJava(ctx context.Context, uuid string) (migrate.Java, []string) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "docker", "inspect", "--format", "{{json .Config.Env}}", uuid).Output()
+	var env []string
+	if err != nil || json.Unmarshal(out, &env) != nil {
+		return migrate.Java{}, []string{"container " + uuid[:8] + " not found; memory and JVM flags were not read"}
+	}
+	return migrate.FromPterodactylEnv(env)
+}
+
+func hasKVP(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.kvp"))
+	return len(matches) > 0
+}
+
+// ampJava merges the instance's .kvp settings files.
+func ampJava(instanceDir string) migrate.Java {
+	values := map[string]string{}
+	matches, _ := filepath.Glob(filepath.Join(instanceDir, "*.kvp"))
+	sort.Strings(matches)
+	for _, path := range matches {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for key, value := range migrate.ParseKVP(string(data)) {
+			values[key] = value
+		}
+	}
+	return migrate.FromAMPSettings(values)
+}
+
+// startScripts are tried in order before any other shell script.
+var startScripts = []string{"start.sh", "run.sh", "start.command", "ServerStart.sh", "LaunchServer.sh", "start.bat", "run.bat"}
+
+func scriptJava(dir string) (migrate.Java, []string) {
+	candidates := append([]string{}, startScripts...)
+	if others, err := filepath.Glob(filepath.Join(dir, "*.sh")); err == nil {
+		sort.Strings(others)
+		for _, other := range others {
+			candidates = append(candidates, filepath.Base(other))
+		}
+	}
+	for _, name := range candidates {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		java, ok := migrate.ParseScript(string(data))
+		if !ok {
+			continue
+		}
+		// Forge and NeoForge keep the memory settings in an arguments file.
+		for _, file := range java.ArgFiles {
+			if filepath.Base(file) != "user_jvm_args.txt" {
+				continue
+			}
+			if data, err := os.ReadFile(filepath.Join(dir, file)); err == nil {
+				user := migrate.ParseArgsFile(string(data))
+				java.XmxMB = max(java.XmxMB, user.XmxMB)
+				java.XmsMB = max(java.XmsMB, user.XmsMB)
+				java.JvmArgs = append(java.JvmArgs, user.JvmArgs...)
+			}
+		}
+		return java, nil
+	}
+	return migrate.Java{}, []string{"no start script with a java command found"}
+}
+
+// jarHints name the jars of common server distributions, over libraries and
+// plugins that may sit next to them.
+var jarHints = []string{"server", "paper", "purpur", "spigot", "bukkit", "fabric-server", "minecraft_server", "forge", "neoforge", "quilt-server", "velocity", "bungeecord", "waterfall"}
+
+func guessJar(dir string) string {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.jar"))
+	if len(matches) == 1 {
+		return filepath.Base(matches[0])
+	}
+	sort.Strings(matches)
+	for _, hint := range jarHints {
+		for _, match := range matches {
+			if strings.HasPrefix(strings.ToLower(filepath.Base(match)), hint) {
+				return filepath.Base(match)
+			}
+		}
+	}
+	return ""
+}
+
+func serverPort(dir string) int {
+	data, err := os.ReadFile(filepath.Join(dir, "server.properties"))
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok && strings.TrimSpace(key) == "server-port" {
+			port, _ := strconv.Atoi(strings.TrimSpace(value))
+			return port
+		}
+	}
+	return 0
+}
@@ -0,0 +1,195 @@
+// Package plugins discovers external mineos-<name> executables that extend
+// the CLI with extra subcommands, in the style of kubectl plugins.
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+)
+
+const (
+	// Prefix is the executable name prefix that marks a plugin.
+	Prefix = "mineos-"
+	// DirEnv lists extra plugin directories, separated like PATH.
+	DirEnv = "MINEOS_PLUGIN_DIR"
+)
+
+// Manifest is the optional mineos-<name>.yaml file next to a plugin.
+type Manifest struct {
+	Short string     `yaml:"short"`
+	Long  string     `yaml:"long"`
+	Tui   []TuiEntry `yaml:"tui"`
+}
+
+// TuiEntry adds an action to the TUI sidebar that runs the plugin with Args.
+type TuiEntry struct {
+	Label       string   `yaml:"label"`
+	Args        []string `yaml:"args"`
+	Interactive bool     `yaml:"interactive"`
+	Streaming   bool     `yaml:"streaming"`
+	Destructive bool     `yaml:"destructive"`
+}
+
+type Plugin struct {
+	Name     string
+	Path     string
+	Manifest Manifest
+	// ManifestErr is set when a manifest exists but could not be parsed.
+	ManifestErr error
+	// Shadows lists later executables with the same name that are ignored.
+	Shadows []string
+}
+
+// SearchDirs returns the plugin directories in lookup order: MINEOS_PLUGIN_DIR,
+// ./plugins next to the .env file, ~/.mineos/plugins, then PATH.
+func SearchDirs() []string {
+	var dirs []string
+	dirs = append(dirs, filepath.SplitList(os.Getenv(DirEnv))...)
+	dirs = append(dirs, "plugins")
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".mineos", "plugins"))
+	}
+	dirs = append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+	return dirs
+}
+
+// Discover finds plugins in dirs. The first executable for a name wins.
+func Discover(dirs []string) []Plugin {
+	byName := map[string]*Plugin{}
+	var order []string
+	seenDirs := map[string]bool{}
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil || seenDirs[abs] {
+			continue
+		}
+		seenDirs[abs] = true
+
+		entries, err := os.ReadDir(abs)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok {
+				continue
+			}
+			path := filepath.Join(abs, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			if existing, ok := byName[name]; ok {
+				existing.Shadows = append(existing.Shadows, path)
+				continue
+			}
+			plugin := &Plugin{Name: name, Path: path}
+			plugin.Manifest, plugin.ManifestErr = loadManifest(path)
+			byName[name] = plugin
+			order = append(order, name)
+		}
+	}
+
+	sort.Strings(order)
+	result := make([]Plugin, 0, len(order))
+	for _, name := range order {
+		result = append(result, *byName[name])
+	}
+	return result
+}
+
+func pluginName(filename string) (string, bool) {
+	if !strings.HasPrefix(filename, Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(filename, Prefix)
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if name == "" || strings.ContainsAny(name, " .") {
+		return "", false
+	}
+	return name, true
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode()&0o111 != 0
+}
+
+func loadManifest(executable string) (Manifest, error) {
+	base := executable
+	if runtime.GOOS == "windows" {
+		base = strings.TrimSuffix(executable, filepath.Ext(executable))
+	}
+	for _, ext := range []string{".yaml", ".yml"} {
+		data, err := os.ReadFile(base + ext)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Manifest{}, err
+		}
+		var manifest Manifest
+		if err := yaml.Unmarshal(data, &manifest); err != nil {
+			return Manifest{}, fmt.Errorf("parse %s: %w", base+ext, err)
+		}
+		return manifest, nil
+	}
+	return Manifest{}, nil
+}
+
+// Environ returns the variables passed to a plugin so it can reach the API
+// and compose stack without parsing .env itself.
+func Environ(cfg config.Config, cliVersion string) []string {
+	apiPort := fallback(cfg.ApiPort, "5078")
+	env := []string{
+		"MINEOS_CLI_VERSION=" + cliVersion,
+		"MINEOS_ENV_PATH=" + cfg.EnvPath,
+		"MINEOS_API_PORT=" + apiPort,
+		"MINEOS_API_URL=" + cfg.ApiURL() + "/api/v1",
+		"MINEOS_API_KEY=" + cfg.EffectiveApiKey(),
+		"MINEOS_WEB_ORIGIN=" + cfg.WebOrigin,
+		"MINEOS_NETWORK_MODE=" + cfg.NetworkMode,
+		"MINEOS_MINECRAFT_HOST=" + cfg.MinecraftHost,
+		"MINEOS_DATA_DIRECTORY=" + cfg.DataDirectory,
+	}
+	if cfg.EnvPath != "" {
+		if abs, err := filepath.Abs(cfg.EnvPath); err == nil {
+			env = append(env, "MINEOS_INSTALL_DIR="+filepath.Dir(abs))
+		}
+	}
+	if exe, err := os.Executable(); err == nil {
+		env = append(env, "MINEOS_CLI="+exe)
+	}
+	return env
+}
+
+func fallback(value, fallbackValue string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallbackValue
+	}
+	return value
+}
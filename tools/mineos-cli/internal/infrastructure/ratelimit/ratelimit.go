@@ -0,0 +1,153 @@
+// Package ratelimit caps the bandwidth of downloads and uploads so large
+// transfers leave room for players on the same connection.
+package ratelimit
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Parse reads rates like curl's --limit-rate: a number of bytes per second
+// with an optional K, M or G suffix (powers of 1024), e.g. "500K" or "5M".
+// An empty string or "0" means unlimited.
+func Parse(raw string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(raw))
+	value = strings.TrimSuffix(value, "/S")
+	value = strings.TrimSuffix(value, "B")
+	if value == "" {
+		return 0, nil
+	}
+	multiplier := 1.0
+	switch value[len(value)-1] {
+	case 'K':
+		multiplier = 1 << 10
+	case 'M':
+		multiplier = 1 << 20
+	case 'G':
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		value = value[:len(value)-1]
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid rate %q (use e.g. 500K or 5M)", raw)
+	}
+	return int64(n * multiplier), nil
+}
+
+// Limiter is a token bucket of bytesPerSecond. Every reader and transport
+// made with the same Limiter draws from it, so transfers running side by
+// side share the rate instead of each getting all of it. A nil Limiter does
+// not limit.
+type Limiter struct {
+	rate int64
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// New returns a Limiter of bytesPerSecond, or nil for a rate of 0 or less.
+func New(bytesPerSecond int64) *Limiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &Limiter{rate: bytesPerSecond}
+}
+
+// Rate is the limit in bytes per second, 0 for a nil Limiter.
+func (l *Limiter) Rate() int64 {
+	if l == nil {
+		return 0
+	}
+	return l.rate
+}
+
+// chunk is the most a single read takes at once. Small chunks keep the rate
+// smooth and let parallel transfers interleave instead of bursting a full
+// buffer each.
+func (l *Limiter) chunk() int64 {
+	return max(l.rate/10, 1024)
+}
+
+// take reserves n bytes and sleeps until the bucket has paid for them.
+// Unused time is not saved up, so an idle limiter does not allow a burst.
+func (l *Limiter) take(n int) {
+	if n <= 0 {
+		return
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(float64(n) / float64(l.rate) * float64(time.Second)))
+	wait := l.next.Sub(now)
+	l.mu.Unlock()
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// Reader limits reads from r through l. A nil Limiter returns r unchanged.
+func Reader(r io.Reader, l *Limiter) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &reader{r: r, limiter: l}
+}
+
+type reader struct {
+	r       io.Reader
+	limiter *Limiter
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if chunk := r.limiter.chunk(); int64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+	n, err := r.r.Read(p)
+	r.limiter.take(n)
+	return n, err
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Transport limits request and response bodies passing through base (or
+// http.DefaultTransport when nil) through l, together with everything else
+// l limits.
+func Transport(base http.RoundTripper, l *Limiter) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if l == nil {
+		return base
+	}
+	return transport{base: base, limiter: l}
+}
+
+type transport struct {
+	base    http.RoundTripper
+	limiter *Limiter
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req = req.Clone(req.Context())
+		req.Body = readCloser{Reader(req.Body, t.limiter), req.Body}
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = readCloser{Reader(resp.Body, t.limiter), resp.Body}
+	return resp, nil
+}
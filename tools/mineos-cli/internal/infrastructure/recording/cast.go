@@ -0,0 +1,187 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Event codes of the asciicast v2 format.
+const (
+	EventOutput = "o"
+	EventInput  = "i"
+	EventResize = "r"
+	EventMarker = "m"
+)
+
+// Header is the first line of an asciicast v2 recording.
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Command   string            `json:"command,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Started is when the recording began.
+func (h Header) Started() time.Time {
+	return time.Unix(h.Timestamp, 0)
+}
+
+// Event is one line after the header: seconds since the start, a code and
+// its data.
+type Event struct {
+	Time float64
+	Code string
+	Data string
+}
+
+// At is the offset of the event from the start of the recording.
+func (e Event) At() time.Duration {
+	return time.Duration(e.Time * float64(time.Second))
+}
+
+// CastWriter writes an asciicast v2 file, which asciinema and its web player
+// can play as well as 'mineos replay'. Events go straight to the file, so a
+// session that is killed keeps what was recorded up to then.
+type CastWriter struct {
+	mu      sync.Mutex
+	file    *os.File
+	started time.Time
+	// partial holds the start of a UTF-8 character split across writes.
+	partial []byte
+}
+
+func CreateCast(path string, header Header) (*CastWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	header.Version = 2
+	started := time.Now()
+	if header.Timestamp == 0 {
+		header.Timestamp = started.Unix()
+	}
+	line, err := json.Marshal(header)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	w := &CastWriter{file: file, started: started}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write records p as terminal output, so the writer can be teed into.
+func (w *CastWriter) Write(p []byte) (int, error) {
+	data := append(w.partial, p...)
+	cut := len(data)
+	// Hold back an incomplete character at the end; JSON would turn its
+	// bytes into replacement characters.
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	w.partial = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return len(p), nil
+	}
+	if err := w.Event(EventOutput, string(data[:cut])); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *CastWriter) Resize(width, height int) error {
+	return w.Event(EventResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+func (w *CastWriter) Event(code, data string) error {
+	line, err := json.Marshal([]any{time.Since(w.started).Seconds(), code, data})
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.file.Write(append(line, '\n'))
+	return err
+}
+
+func (w *CastWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// ReadCast loads a whole asciicast v2 recording. A half-written last line,
+// left by a session that was killed, is dropped.
+func ReadCast(r io.Reader) (Header, []Event, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var header Header
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return header, nil, err
+		}
+		return header, nil, errors.New("empty recording")
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return header, nil, fmt.Errorf("not an asciicast recording: %w", err)
+	}
+	if header.Version != 2 {
+		return header, nil, fmt.Errorf("unsupported asciicast version %d (want 2)", header.Version)
+	}
+
+	var events []Event
+	var bad error
+	for line := 2; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if bad != nil {
+			return header, events, bad
+		}
+		event, err := parseEvent(text)
+		if err != nil {
+			bad = fmt.Errorf("line %d: %w", line, err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return header, events, scanner.Err()
+}
+
+func parseEvent(text string) (Event, error) {
+	var event Event
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(text), &raw); err != nil || len(raw) != 3 {
+		return event, errors.New("not an asciicast event")
+	}
+	if err := json.Unmarshal(raw[0], &event.Time); err != nil {
+		return event, err
+	}
+	if err := json.Unmarshal(raw[1], &event.Code); err != nil {
+		return event, err
+	}
+	if err := json.Unmarshal(raw[2], &event.Data); err != nil {
+		return event, err
+	}
+	return event, nil
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package recording
+
+import (
+	"os"
+	"os/exec"
+)
+
+const ptySupported = false
+
+func startPty(*exec.Cmd, int, int) (*os.File, error) {
+	return nil, errNoPty
+}
+
+func forwardResize(_, _ *os.File, _ *CastWriter) func() {
+	return func() {}
+}
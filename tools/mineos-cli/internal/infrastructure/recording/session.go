@@ -0,0 +1,101 @@
+package recording
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// errNoPty is returned on platforms the recorder cannot open a terminal on.
+var errNoPty = errors.New("no pseudo-terminal support")
+
+// RecordsTerminal reports whether sessions run in a pseudo-terminal here.
+func RecordsTerminal() bool {
+	return ptySupported
+}
+
+// Session is a recorded run of a shell or command.
+type Session struct {
+	// Command is the program to record and its arguments.
+	Command []string
+	// CastPath is the recording to create; the transcript goes next to it.
+	CastPath string
+	Title    string
+}
+
+// Run records the session until its command exits and returns the command's
+// exit code. On Linux the command runs in a pseudo-terminal so full-screen
+// programs such as the TUI work and are recorded as they looked; elsewhere
+// its output is teed through pipes, which suits plain commands only.
+func (s Session) Run(stdin, stdout *os.File) (int, error) {
+	width, height := 80, 24
+	if w, h, err := term.GetSize(int(stdout.Fd())); err == nil {
+		width, height = w, h
+	}
+	cast, err := CreateCast(s.CastPath, Header{
+		Width:   width,
+		Height:  height,
+		Command: strings.Join(s.Command, " "),
+		Title:   s.Title,
+		Env:     map[string]string{"SHELL": os.Getenv("SHELL"), "TERM": os.Getenv("TERM")},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cast.Close()
+
+	transcriptPath, err := filepath.Abs(TranscriptPath(s.CastPath))
+	if err != nil {
+		return 0, err
+	}
+	transcript, err := OpenTranscript(transcriptPath)
+	if err != nil {
+		return 0, err
+	}
+	transcript.Close()
+
+	cmd := exec.Command(s.Command[0], s.Command[1:]...)
+	cmd.Env = append(os.Environ(), TranscriptEnv+"="+transcriptPath)
+
+	master, err := startPty(cmd, width, height)
+	if errors.Is(err, errNoPty) {
+		cmd.Stdin = stdin
+		cmd.Stdout = io.MultiWriter(stdout, cast)
+		cmd.Stderr = cmd.Stdout
+		return exitCode(cmd.Run())
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer master.Close()
+
+	if term.IsTerminal(int(stdin.Fd())) {
+		state, err := term.MakeRaw(int(stdin.Fd()))
+		if err == nil {
+			defer term.Restore(int(stdin.Fd()), state)
+		}
+	}
+	stopResize := forwardResize(stdout, master, cast)
+	defer stopResize()
+
+	// The copy from stdin is left blocked on a read when the command exits;
+	// the CLI exits right after.
+	go io.Copy(master, stdin)
+	// Reading the master fails with EIO once the command and its children
+	// have closed the terminal, which is the normal end of the session.
+	_, _ = io.Copy(io.MultiWriter(stdout, cast), master)
+	return exitCode(cmd.Wait())
+}
+
+func exitCode(err error) (int, error) {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	return 0, err
+}
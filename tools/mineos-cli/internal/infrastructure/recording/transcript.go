@@ -0,0 +1,139 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TranscriptEnv names the transcript a recorded session appends to. The
+// recorder sets it for the shell it runs, so every mineos command started
+// inside the session logs what it did.
+const TranscriptEnv = "MINEOS_RECORD_TRANSCRIPT"
+
+// Kinds of transcript entries.
+const (
+	KindCommand = "command"
+	KindRequest = "request"
+	KindExit    = "exit"
+)
+
+// Operation is one line of a session transcript.
+type Operation struct {
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	// PID tells apart the commands of a session, which may overlap.
+	PID        int      `json:"pid"`
+	Command    string   `json:"command,omitempty"`
+	Args       []string `json:"args,omitempty"`
+	Method     string   `json:"method,omitempty"`
+	URL        string   `json:"url,omitempty"`
+	Status     int      `json:"status,omitempty"`
+	ExitCode   *int     `json:"exitCode,omitempty"`
+	DurationMs int64    `json:"durationMs,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// TranscriptPath is the transcript kept next to a recording:
+// session.cast has session.jsonl.
+func TranscriptPath(castPath string) string {
+	return strings.TrimSuffix(castPath, ".cast") + ".jsonl"
+}
+
+// Transcript appends operations to a file shared by the session's commands.
+type Transcript struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func OpenTranscript(path string) (*Transcript, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &Transcript{file: file}, nil
+}
+
+func (t *Transcript) Record(op Operation) {
+	if t == nil {
+		return
+	}
+	if op.Time.IsZero() {
+		op.Time = time.Now().UTC()
+	}
+	op.PID = os.Getpid()
+	line, err := json.Marshal(op)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// One write per line keeps lines whole when several commands append.
+	_, _ = t.file.Write(append(line, '\n'))
+}
+
+func (t *Transcript) Close() error {
+	if t == nil {
+		return nil
+	}
+	return t.file.Close()
+}
+
+// ReadTranscript loads a transcript, skipping lines it cannot parse.
+func ReadTranscript(path string) ([]Operation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var ops []Operation
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var op Operation
+		if err := json.Unmarshal(scanner.Bytes(), &op); err == nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops, scanner.Err()
+}
+
+// Transport records the requests passing through base that change
+// something. Reads are left out: the TUI polls, and they change nothing.
+// Bodies are never recorded since they can carry secrets, nor query strings.
+func Transport(base http.RoundTripper, transcript *Transcript) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return transport{base: base, transcript: transcript}
+}
+
+type transport struct {
+	base       http.RoundTripper
+	transcript *Transcript
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+		return t.base.RoundTrip(req)
+	}
+	started := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	op := Operation{
+		Kind:       KindRequest,
+		Method:     req.Method,
+		URL:        req.URL.Scheme + "://" + req.URL.Host + req.URL.Path,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		op.Error = err.Error()
+	} else {
+		op.Status = resp.StatusCode
+	}
+	t.transcript.Record(op)
+	return resp, err
+}
@@ -0,0 +1,119 @@
+// Package releases reads MineOS releases from GitHub.
+package releases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	Repo             = "freeman412/mineos-sveltekit"
+	apiBase          = "https://api.github.com"
+	latestReleaseURL = apiBase + "/repos/" + Repo + "/releases/latest"
+	allReleasesURL   = apiBase + "/repos/" + Repo + "/releases"
+)
+
+var ErrNoReleases = errors.New("no releases found")
+
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	HTMLURL     string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []Asset   `json:"assets"`
+	Prerelease  bool      `json:"prerelease"`
+}
+
+type Asset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	// Digest is "sha256:<hex>" for assets uploaded since GitHub started
+	// recording it; older releases leave it empty.
+	Digest string `json:"digest"`
+}
+
+// Version is the tag without its "v" prefix.
+func (r Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Latest fetches the newest stable release.
+func Latest(ctx context.Context) (*Release, error) {
+	var release Release
+	if err := get(ctx, latestReleaseURL, &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// Best fetches the newest release, counting pre-releases when asked to.
+func Best(ctx context.Context, includePrerelease bool) (*Release, error) {
+	if !includePrerelease {
+		return Latest(ctx)
+	}
+	var list []Release
+	if err := get(ctx, allReleasesURL, &list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrNoReleases
+	}
+	// GitHub returns releases sorted by created date descending, so first one is newest
+	return &list[0], nil
+}
+
+// Channels holds the newest release of each update channel.
+type Channels struct {
+	Stable  *Release
+	Preview *Release
+}
+
+// FetchChannels fetches the newest stable release and the newest pre-release
+// in one request. Preview is the stable release when it is newer than every
+// pre-release.
+func FetchChannels(ctx context.Context) (Channels, error) {
+	var list []Release
+	if err := get(ctx, allReleasesURL, &list); err != nil {
+		return Channels{}, err
+	}
+	if len(list) == 0 {
+		return Channels{}, ErrNoReleases
+	}
+	channels := Channels{Preview: &list[0]}
+	for i := range list {
+		if !list[i].Prerelease {
+			channels.Stable = &list[i]
+			break
+		}
+	}
+	return channels, nil
+}
+
+func get(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNoReleases
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API returned status: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse release info: %w", err)
+	}
+	return nil
+}
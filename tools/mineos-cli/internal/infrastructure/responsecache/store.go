@@ -0,0 +1,131 @@
+// Package responsecache keeps slow list responses on disk for a short while,
+// so that shell completion and commands run in quick succession, e.g. by the
+// TUI, do not each ask the API or the upstream catalogs again.
+package responsecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Store is a directory of cached responses, one file per key.
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir, now: time.Now}
+}
+
+// DefaultDir is responses under the user's MineOS cache directory.
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "mineos", "responses")
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+type entry struct {
+	Key      string          `json:"key"`
+	StoredAt time.Time       `json:"storedAt"`
+	Value    json.RawMessage `json:"value"`
+}
+
+func (s *Store) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:12])+".json")
+}
+
+// Get decodes the value stored under key into out. It reports false when
+// there is none younger than ttl, or it cannot be read.
+func (s *Store) Get(key string, ttl time.Duration, out any) bool {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return false
+	}
+	var cached entry
+	if json.Unmarshal(data, &cached) != nil || cached.Key != key {
+		return false
+	}
+	if age := s.now().Sub(cached.StoredAt); age < 0 || age >= ttl {
+		return false
+	}
+	return json.Unmarshal(cached.Value, out) == nil
+}
+
+// Put stores value under key. Errors are ignored: a cache that cannot be
+// written only means the next call asks again.
+func (s *Store) Put(key string, value any) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(entry{Key: key, StoredAt: s.now(), Value: raw})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(s.dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete forgets the value stored under key.
+func (s *Store) Delete(key string) {
+	os.Remove(s.path(key))
+}
+
+// DeletePrefix forgets every value whose key starts with prefix; an empty
+// prefix clears the store.
+func (s *Store) DeletePrefix(prefix string) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(s.dir, e.Name())
+		if prefix != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			var cached entry
+			if json.Unmarshal(data, &cached) == nil && !strings.HasPrefix(cached.Key, prefix) {
+				continue
+			}
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Package runstate remembers which servers were running when the stack was
+// last stopped, so starting it again can bring the same servers back.
+package runstate
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+// DefaultFileName is kept in the installation's state directory.
+const DefaultFileName = "mineos-running.json"
+
+// State is the set of servers running at a stack stop.
+type State struct {
+	StoppedAt time.Time `json:"stoppedAt"`
+	Servers   []string  `json:"servers"`
+}
+
+type FileRepository struct {
+	path string
+}
+
+func NewFileRepository(path string) *FileRepository {
+	return &FileRepository{path: path}
+}
+
+// NewFileRepositoryForEnv returns a repository for the state file of the
+// installation whose .env is envPath.
+func NewFileRepositoryForEnv(envPath string) *FileRepository {
+	return NewFileRepository(state.FileForEnv(envPath, DefaultFileName))
+}
+
+func (r *FileRepository) Path() string {
+	return r.path
+}
+
+// Load reads the state. The boolean is false when there is none.
+func (r *FileRepository) Load() (State, bool, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return State{}, false, nil
+		}
+		return State{}, false, err
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, true, err
+	}
+	return state, true, nil
+}
+
+func (r *FileRepository) Save(state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// Remove deletes the state once it has been restored.
+func (r *FileRepository) Remove() error {
+	if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
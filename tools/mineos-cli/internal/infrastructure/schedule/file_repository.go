@@ -0,0 +1,49 @@
+package schedule
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/schedule"
+)
+
+// DefaultFileName is where schedules were kept next to the .env file before
+// they moved into the state store.
+const DefaultFileName = "mineos-schedule.yaml"
+
+// FileRepository reads a schedule from a file of the user's own.
+type FileRepository struct {
+	path string
+}
+
+func NewFileRepository(path string) *FileRepository {
+	return &FileRepository{path: path}
+}
+
+func (r *FileRepository) Path() string {
+	return r.path
+}
+
+// Load reads and validates the schedule file. The boolean is false when the
+// file does not exist, in which case an empty definition is returned.
+func (r *FileRepository) Load() (domain.Definition, bool, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return domain.Definition{}, false, nil
+		}
+		return domain.Definition{}, false, err
+	}
+	def, err := Parse(data)
+	return def, true, err
+}
+
+// Parse reads and validates a schedule document.
+func Parse(data []byte) (domain.Definition, error) {
+	var def domain.Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return domain.Definition{}, err
+	}
+	return def, def.Validate()
+}
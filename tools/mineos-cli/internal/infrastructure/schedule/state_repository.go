@@ -0,0 +1,67 @@
+package schedule
+
+import (
+	"context"
+	"path/filepath"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/schedule"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+// StateRepository keeps an installation's schedule in the state store. The
+// first read imports the DefaultFileName beside its .env, where schedules
+// were kept before.
+type StateRepository struct {
+	statePath string
+	key       string
+	legacy    string
+}
+
+func NewStateRepository(statePath, envPath string) *StateRepository {
+	key := state.InstallationKey(envPath)
+	return &StateRepository{statePath: statePath, key: key, legacy: filepath.Join(filepath.Dir(key), DefaultFileName)}
+}
+
+// Path is where the schedule is, for messages.
+func (r *StateRepository) Path() string {
+	return "the state store (" + r.statePath + ")"
+}
+
+// Read returns the schedule document. The boolean is false when there is
+// none yet.
+func (r *StateRepository) Read() ([]byte, bool, error) {
+	ctx := context.Background()
+	store, err := state.Open(r.statePath)
+	if err != nil {
+		return nil, false, err
+	}
+	defer store.Close()
+	if err := store.ImportFile(ctx, state.NamespaceSchedules, r.key, r.legacy); err != nil {
+		return nil, false, err
+	}
+	return store.Document(ctx, state.NamespaceSchedules, r.key)
+}
+
+// Load reads and validates the schedule. The boolean is false when there is
+// none yet, in which case an empty definition is returned.
+func (r *StateRepository) Load() (domain.Definition, bool, error) {
+	data, found, err := r.Read()
+	if err != nil || !found {
+		return domain.Definition{}, found, err
+	}
+	def, err := Parse(data)
+	return def, true, err
+}
+
+// Save validates and stores a schedule document.
+func (r *StateRepository) Save(data []byte) error {
+	if _, err := Parse(data); err != nil {
+		return err
+	}
+	store, err := state.Open(r.statePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	return store.PutDocument(context.Background(), state.NamespaceSchedules, r.key, data)
+}
@@ -0,0 +1,410 @@
+// Package slp implements the Minecraft Server List Ping used by the
+// multiplayer screen: the Java status protocol over TCP and the Bedrock
+// RakNet unconnected ping over UDP.
+package slp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultJavaPort    = 25565
+	DefaultBedrockPort = 19132
+
+	// handshakeProtocol is sent as "any version"; servers answer with their
+	// own protocol number.
+	handshakeProtocol = -1
+	maxPacketSize     = 2 << 20
+)
+
+type Status struct {
+	// Address is the host:port that answered, after any SRV lookup.
+	Address       string
+	Version       string
+	Protocol      int
+	PlayersOnline int
+	PlayersMax    int
+	Sample        []string
+	// Description is the raw MOTD: a JSON text component for Java, a
+	// legacy string for Bedrock.
+	Description json.RawMessage
+	Legacy      string
+	HasFavicon  bool
+	Latency     time.Duration
+	Bedrock     bool
+	// ResponseBytes is the size of the status response, favicon included.
+	ResponseBytes int
+}
+
+type javaStatus struct {
+	Version struct {
+		Name     string `json:"name"`
+		Protocol int    `json:"protocol"`
+	} `json:"version"`
+	Players struct {
+		Max    int `json:"max"`
+		Online int `json:"online"`
+		Sample []struct {
+			Name string `json:"name"`
+		} `json:"sample"`
+	} `json:"players"`
+	Description json.RawMessage `json:"description"`
+	Favicon     string          `json:"favicon"`
+}
+
+// Resolve splits host[:port]. Without a port, Java addresses honour the
+// _minecraft._tcp SRV record as the client does.
+func Resolve(ctx context.Context, address string, bedrock bool) (string, bool, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err == nil {
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return "", false, fmt.Errorf("invalid port %q", port)
+		}
+		return net.JoinHostPort(host, port), false, nil
+	}
+	host = strings.Trim(address, "[]")
+	if host == "" {
+		return "", false, errors.New("host is required")
+	}
+	if bedrock {
+		return net.JoinHostPort(host, strconv.Itoa(DefaultBedrockPort)), false, nil
+	}
+	if net.ParseIP(host) == nil {
+		var resolver net.Resolver
+		if _, records, err := resolver.LookupSRV(ctx, "minecraft", "tcp", host); err == nil && len(records) > 0 {
+			target := strings.TrimSuffix(records[0].Target, ".")
+			return net.JoinHostPort(target, strconv.Itoa(int(records[0].Port))), true, nil
+		}
+	}
+	return net.JoinHostPort(host, strconv.Itoa(DefaultJavaPort)), false, nil
+}
+
+// PingJava performs a status request followed by a ping/pong to measure
+// latency.
+func PingJava(ctx context.Context, address string) (Status, error) {
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil {
+		return Status{}, err
+	}
+	port, _ := strconv.Atoi(portText)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return Status{}, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	var handshake bytes.Buffer
+	writeVarInt(&handshake, 0x00)
+	writeVarInt(&handshake, handshakeProtocol)
+	writeString(&handshake, host)
+	_ = binary.Write(&handshake, binary.BigEndian, uint16(port))
+	writeVarInt(&handshake, 1)
+	if err := writePacket(conn, handshake.Bytes()); err != nil {
+		return Status{}, err
+	}
+	if err := writePacket(conn, []byte{0x00}); err != nil {
+		return Status{}, err
+	}
+
+	reader := bufio.NewReader(conn)
+	payload, err := readPacket(reader, 0x00)
+	if err != nil {
+		return Status{}, fmt.Errorf("status response: %w", err)
+	}
+	responseBytes := len(payload)
+	body, err := readString(bytes.NewReader(payload))
+	if err != nil {
+		return Status{}, fmt.Errorf("status response: %w", err)
+	}
+	var decoded javaStatus
+	if err := json.Unmarshal([]byte(body), &decoded); err != nil {
+		return Status{}, fmt.Errorf("status response is not valid JSON: %w", err)
+	}
+
+	status := Status{
+		Address:       address,
+		Version:       decoded.Version.Name,
+		Protocol:      decoded.Version.Protocol,
+		PlayersOnline: decoded.Players.Online,
+		PlayersMax:    decoded.Players.Max,
+		Description:   decoded.Description,
+		HasFavicon:    decoded.Favicon != "",
+		ResponseBytes: responseBytes,
+	}
+	for _, player := range decoded.Players.Sample {
+		status.Sample = append(status.Sample, player.Name)
+	}
+
+	var ping bytes.Buffer
+	writeVarInt(&ping, 0x01)
+	_ = binary.Write(&ping, binary.BigEndian, time.Now().UnixMilli())
+	sent := time.Now()
+	if err := writePacket(conn, ping.Bytes()); err != nil {
+		return status, nil
+	}
+	if _, err := readPacket(reader, 0x01); err == nil {
+		status.Latency = time.Since(sent)
+	}
+	return status, nil
+}
+
+// Probe is one timed round trip to a Java server.
+type Probe struct {
+	// Connect is the TCP handshake, answered by the host's kernel; it is
+	// close to the network round trip.
+	Connect time.Duration
+	// Pong is the status ping answered by the server process itself.
+	Pong time.Duration
+}
+
+// ProbeJava opens a connection and sends only the status ping, which the
+// server answers without building its status response. Vanilla servers
+// close the connection after the pong, so each probe is a new connection.
+func ProbeJava(ctx context.Context, address string) (Probe, error) {
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil {
+		return Probe{}, err
+	}
+	port, _ := strconv.Atoi(portText)
+
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return Probe{}, err
+	}
+	defer conn.Close()
+	probe := Probe{Connect: time.Since(start)}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	var handshake bytes.Buffer
+	writeVarInt(&handshake, 0x00)
+	writeVarInt(&handshake, handshakeProtocol)
+	writeString(&handshake, host)
+	_ = binary.Write(&handshake, binary.BigEndian, uint16(port))
+	writeVarInt(&handshake, 1)
+	if err := writePacket(conn, handshake.Bytes()); err != nil {
+		return probe, err
+	}
+	var ping bytes.Buffer
+	writeVarInt(&ping, 0x01)
+	_ = binary.Write(&ping, binary.BigEndian, time.Now().UnixMilli())
+	sent := time.Now()
+	if err := writePacket(conn, ping.Bytes()); err != nil {
+		return probe, err
+	}
+	if _, err := readPacket(bufio.NewReader(conn), 0x01); err != nil {
+		return probe, fmt.Errorf("pong: %w", err)
+	}
+	probe.Pong = time.Since(sent)
+	return probe, nil
+}
+
+// raknetMagic identifies RakNet offline messages.
+var raknetMagic = []byte{0x00, 0xff, 0xff, 0x00, 0xfe, 0xfe, 0xfe, 0xfe, 0xfd, 0xfd, 0xfd, 0xfd, 0x12, 0x34, 0x56, 0x78}
+
+// PingBedrock sends a RakNet unconnected ping and parses the MCPE pong.
+func PingBedrock(ctx context.Context, address string) (Status, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return Status{}, err
+	}
+	defer conn.Close()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(5 * time.Second)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	guid := make([]byte, 8)
+	_, _ = rand.Read(guid)
+	var ping bytes.Buffer
+	ping.WriteByte(0x01)
+	_ = binary.Write(&ping, binary.BigEndian, time.Now().UnixMilli())
+	ping.Write(raknetMagic)
+	ping.Write(guid)
+
+	sent := time.Now()
+	if _, err := conn.Write(ping.Bytes()); err != nil {
+		return Status{}, err
+	}
+	buf := make([]byte, 2048)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return Status{}, err
+	}
+	latency := time.Since(sent)
+
+	// 0x1c, time (8), server guid (8), magic (16), string length (2), string.
+	const header = 1 + 8 + 8 + 16 + 2
+	if n < header || buf[0] != 0x1c || !bytes.Equal(buf[17:33], raknetMagic) {
+		return Status{}, errors.New("unexpected response; not a Bedrock server")
+	}
+	length := int(binary.BigEndian.Uint16(buf[33:35]))
+	if header+length > n {
+		return Status{}, errors.New("truncated Bedrock pong")
+	}
+	// MCPE;motd;protocol;version;online;max;guid;world;gamemode;...
+	fields := strings.Split(string(buf[header:header+length]), ";")
+	if len(fields) < 6 {
+		return Status{}, errors.New("malformed Bedrock pong")
+	}
+	status := Status{Address: address, Bedrock: true, Latency: latency, Legacy: fields[1], Version: fields[3]}
+	status.Protocol, _ = strconv.Atoi(fields[2])
+	status.PlayersOnline, _ = strconv.Atoi(fields[4])
+	status.PlayersMax, _ = strconv.Atoi(fields[5])
+	if len(fields) > 7 && fields[7] != "" {
+		status.Legacy += "\n" + fields[7]
+	}
+	return status, nil
+}
+
+// raknetProtocol is the RakNet version Bedrock speaks. A server on another
+// version still answers, with "incompatible protocol", which is all an MTU
+// probe needs.
+const raknetProtocol = 11
+
+// udpOverhead is the IPv4 and UDP header size RakNet counts in its MTU.
+const udpOverhead = 28
+
+// ProbeBedrockMTU sends a RakNet "open connection request 1" padded to mtu
+// bytes on the wire, the way clients discover the path MTU before joining.
+// It reports whether the server answered; a missing answer at a size that
+// smaller probes get through suggests fragments are being dropped.
+func ProbeBedrockMTU(ctx context.Context, address string, mtu int) (bool, error) {
+	if mtu <= udpOverhead+len(raknetMagic)+2 {
+		return false, fmt.Errorf("mtu %d is too small", mtu)
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(2 * time.Second)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	request := make([]byte, mtu-udpOverhead)
+	request[0] = 0x05
+	copy(request[1:], raknetMagic)
+	request[1+len(raknetMagic)] = raknetProtocol
+	if _, err := conn.Write(request); err != nil {
+		return false, err
+	}
+	buf := make([]byte, 2048)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return false, nil
+			}
+			return false, err
+		}
+		// 0x06 open connection reply 1, 0x19 incompatible protocol.
+		if n > 0 && (buf[0] == 0x06 || buf[0] == 0x19) {
+			return true, nil
+		}
+	}
+}
+
+func writePacket(w io.Writer, payload []byte) error {
+	var packet bytes.Buffer
+	writeVarInt(&packet, int32(len(payload)))
+	packet.Write(payload)
+	_, err := w.Write(packet.Bytes())
+	return err
+}
+
+// readPacket reads one length-prefixed packet and checks its id, returning
+// the remaining payload.
+func readPacket(r *bufio.Reader, wantID int32) ([]byte, error) {
+	length, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if length <= 0 || length > maxPacketSize {
+		return nil, fmt.Errorf("invalid packet length %d", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	payload := bytes.NewReader(data)
+	id, err := readVarInt(payload)
+	if err != nil {
+		return nil, err
+	}
+	if id != wantID {
+		return nil, fmt.Errorf("unexpected packet 0x%02x", id)
+	}
+	return data[len(data)-payload.Len():], nil
+}
+
+func writeVarInt(buf *bytes.Buffer, value int32) {
+	v := uint32(value)
+	for {
+		if v&^0x7f == 0 {
+			buf.WriteByte(byte(v))
+			return
+		}
+		buf.WriteByte(byte(v&0x7f | 0x80))
+		v >>= 7
+	}
+}
+
+func readVarInt(r io.ByteReader) (int32, error) {
+	var value uint32
+	for i := 0; i < 5; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint32(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(value), nil
+		}
+	}
+	return 0, errors.New("varint too long")
+}
+
+func writeString(buf *bytes.Buffer, value string) {
+	writeVarInt(buf, int32(len(value)))
+	buf.WriteString(value)
+}
+
+func readString(r *bytes.Reader) (string, error) {
+	length, err := readVarInt(r)
+	if err != nil {
+		return "", err
+	}
+	if length < 0 || int(length) > r.Len() {
+		return "", errors.New("invalid string length")
+	}
+	data := make([]byte, length)
+	_, err = io.ReadFull(r, data)
+	return string(data), err
+}
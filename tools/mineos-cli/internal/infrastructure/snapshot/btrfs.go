@@ -0,0 +1,125 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/snapshot"
+)
+
+type btrfs struct {
+	server string
+	volume domain.Volume
+	// mount is where the filesystem holding the subvolume is mounted.
+	mount string
+	dir   string
+}
+
+// detectBtrfs finds the subvolume dir belongs to, walking up to the mount
+// point, which is always one.
+func detectBtrfs(ctx context.Context, server, dir, snapshotRoot string, m mount, mounts []mount) (Provider, error) {
+	if err := requireTool("btrfs"); err != nil {
+		return nil, err
+	}
+	subvolume := m.Point
+	for path := dir; within(path, m.Point) && path != m.Point; path = filepath.Dir(path) {
+		if _, err := run(ctx, "btrfs", "subvolume", "show", path); err == nil {
+			subvolume = path
+			break
+		}
+	}
+
+	root := existingParent(snapshotRoot)
+	if rootMount, ok := mountOf(mounts, root); !ok || rootMount.Point != m.Point {
+		return nil, fmt.Errorf("btrfs snapshots go in %s, which has to be on the same mount as %s (%s)", snapshotRoot, dir, m.Point)
+	}
+	return &btrfs{
+		server: server,
+		volume: domain.Volume{Kind: domain.KindBtrfs, Name: subvolume, Mount: subvolume},
+		mount:  m.Point,
+		dir:    filepath.Join(snapshotRoot, server),
+	}, nil
+}
+
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil || filepath.Dir(path) == path {
+			if resolved, err := filepath.EvalSymlinks(path); err == nil {
+				return resolved
+			}
+			return path
+		}
+		path = filepath.Dir(path)
+	}
+}
+
+func (b *btrfs) Volume() domain.Volume { return b.volume }
+
+func (b *btrfs) Create(ctx context.Context, name string) (domain.Snapshot, error) {
+	if err := os.MkdirAll(b.dir, 0o755); err != nil {
+		return domain.Snapshot{}, err
+	}
+	ref := filepath.Join(b.dir, name)
+	if _, err := run(ctx, "btrfs", "subvolume", "snapshot", "-r", b.volume.Name, ref); err != nil {
+		return domain.Snapshot{}, err
+	}
+	return b.snapshot(name, ref), nil
+}
+
+func (b *btrfs) snapshot(name, ref string) domain.Snapshot {
+	server, created, _ := domain.ParseName(name)
+	return domain.Snapshot{Name: name, Server: server, Created: created, Volume: b.volume, Ref: ref}
+}
+
+func (b *btrfs) List(context.Context) ([]domain.Snapshot, error) {
+	paths, err := filepath.Glob(filepath.Join(b.dir, domain.Prefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []domain.Snapshot
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if server, _, ok := domain.ParseName(name); ok && server == b.server {
+			snapshots = append(snapshots, b.snapshot(name, path))
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Created.Before(snapshots[j].Created) })
+	return snapshots, nil
+}
+
+// Rollback puts a writable snapshot of snap in place of the subvolume and
+// deletes the old one. A subvolume that is itself a mount point cannot be
+// swapped; mount the snapshot there instead.
+func (b *btrfs) Rollback(ctx context.Context, snap domain.Snapshot) error {
+	current := b.volume.Name
+	if current == b.mount {
+		return fmt.Errorf("%s is a mount point; mount %s there instead (e.g. with subvol=)", current, snap.Ref)
+	}
+	stamp := time.Now().UTC().Format("20060102-150405")
+	restored := filepath.Join(filepath.Dir(current), "."+filepath.Base(current)+".rollback-"+stamp)
+	replaced := filepath.Join(filepath.Dir(current), "."+filepath.Base(current)+".replaced-"+stamp)
+	if _, err := run(ctx, "btrfs", "subvolume", "snapshot", snap.Ref, restored); err != nil {
+		return err
+	}
+	if err := os.Rename(current, replaced); err != nil {
+		_, _ = run(ctx, "btrfs", "subvolume", "delete", restored)
+		return err
+	}
+	if err := os.Rename(restored, current); err != nil {
+		return errors.Join(err, os.Rename(replaced, current))
+	}
+	if _, err := run(ctx, "btrfs", "subvolume", "delete", replaced); err != nil {
+		return fmt.Errorf("rolled back, but the previous subvolume is left in %s: %w", replaced, err)
+	}
+	return nil
+}
+
+func (b *btrfs) Delete(ctx context.Context, snap domain.Snapshot) error {
+	_, err := run(ctx, "btrfs", "subvolume", "delete", snap.Ref)
+	return err
+}
@@ -0,0 +1,109 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/snapshot"
+)
+
+// lvmSnapshotSize is the copy-on-write space a snapshot of a regular (not
+// thin) volume gets. It fills as the origin changes; a full one is dropped.
+const lvmSnapshotSize = "10%ORIGIN"
+
+// lvm snapshots the logical volume mounted where the server directory is.
+// The kernel freezes the filesystem while the snapshot is taken.
+type lvm struct {
+	server string
+	volume domain.Volume
+	group  string
+	lv     string
+	thin   bool
+}
+
+func detectLvm(ctx context.Context, server string, m mount) (Provider, error) {
+	if err := requireTool("lvs"); err != nil {
+		return nil, err
+	}
+	output, err := run(ctx, "lvs", "--noheadings", "--separator", "|", "-o", "vg_name,lv_name,lv_attr", m.Source)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Split(strings.TrimSpace(output), "|")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unexpected lvs output %q", output)
+	}
+	group, lv, attr := strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1]), strings.TrimSpace(fields[2])
+	return &lvm{
+		server: server,
+		volume: domain.Volume{Kind: domain.KindLvm, Name: group + "/" + lv, Mount: m.Point},
+		group:  group,
+		lv:     lv,
+		thin:   strings.HasPrefix(attr, "V"),
+	}, nil
+}
+
+func (l *lvm) Volume() domain.Volume { return l.volume }
+
+func (l *lvm) Create(ctx context.Context, name string) (domain.Snapshot, error) {
+	args := []string{"--snapshot", "--name", name}
+	if !l.thin {
+		args = append(args, "--extents", lvmSnapshotSize)
+	}
+	args = append(args, l.volume.Name)
+	if _, err := run(ctx, "lvcreate", args...); err != nil {
+		return domain.Snapshot{}, err
+	}
+	return l.snapshot(name, 0), nil
+}
+
+func (l *lvm) snapshot(name string, size int64) domain.Snapshot {
+	server, created, _ := domain.ParseName(name)
+	return domain.Snapshot{Name: name, Server: server, Created: created, Volume: l.volume, Ref: l.group + "/" + name, Size: size}
+}
+
+func (l *lvm) List(ctx context.Context) ([]domain.Snapshot, error) {
+	output, err := run(ctx, "lvs", "--noheadings", "--separator", "|", "--units", "b", "--nosuffix", "-o", "lv_name,origin,lv_size", l.group)
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []domain.Snapshot
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) != 3 || strings.TrimSpace(fields[1]) != l.lv {
+			continue
+		}
+		name := strings.TrimSpace(fields[0])
+		if server, _, ok := domain.ParseName(name); ok && server == l.server {
+			size, _ := strconv.ParseInt(strings.TrimSpace(fields[2]), 10, 64)
+			snapshots = append(snapshots, l.snapshot(name, size))
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Created.Before(snapshots[j].Created) })
+	return snapshots, nil
+}
+
+// ErrMergeDelayed is returned by an LVM rollback of a volume that is still
+// mounted: the merge runs the next time the volume is activated.
+var ErrMergeDelayed = errors.New("the merge finishes the next time the volume is activated")
+
+// Rollback merges the snapshot back into its origin, which uses it up.
+func (l *lvm) Rollback(ctx context.Context, snap domain.Snapshot) error {
+	if _, err := run(ctx, "lvconvert", "--merge", snap.Ref); err != nil {
+		return err
+	}
+	// A finished merge removes the snapshot; one still listed is pending.
+	if _, err := run(ctx, "lvs", snap.Ref); err == nil {
+		return ErrMergeDelayed
+	}
+	return nil
+}
+
+func (l *lvm) Delete(ctx context.Context, snap domain.Snapshot) error {
+	_, err := run(ctx, "lvremove", "--yes", snap.Ref)
+	return err
+}
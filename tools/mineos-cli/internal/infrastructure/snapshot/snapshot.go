@@ -0,0 +1,160 @@
+// Package snapshot takes, lists and rolls back filesystem snapshots of
+// server directories with the btrfs, zfs and LVM command line tools.
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/snapshot"
+)
+
+// ErrUnsupported is returned by Detect for directories on a filesystem
+// that cannot be snapshotted.
+var ErrUnsupported = errors.New("not on a btrfs subvolume, ZFS dataset or LVM logical volume")
+
+// Provider snapshots the volume one server directory lives on.
+type Provider interface {
+	Volume() domain.Volume
+	Create(ctx context.Context, name string) (domain.Snapshot, error)
+	// List returns the server's snapshots, oldest first.
+	List(ctx context.Context) ([]domain.Snapshot, error)
+	// Rollback returns the volume to a snapshot. The server must be
+	// stopped and the volume must be Exclusive to its directory.
+	Rollback(ctx context.Context, snap domain.Snapshot) error
+	Delete(ctx context.Context, snap domain.Snapshot) error
+}
+
+// Detect finds how the directory of server can be snapshotted. btrfs
+// snapshots are kept under snapshotRoot/<server>, which has to be on the
+// same btrfs mount; ZFS and LVM keep theirs in the pool or volume group.
+func Detect(ctx context.Context, server, dir, snapshotRoot string) (Provider, error) {
+	if runtime.GOOS != "linux" {
+		return nil, errors.New("filesystem snapshots are only supported on Linux")
+	}
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+	mounts, err := readMounts()
+	if err != nil {
+		return nil, err
+	}
+	m, ok := mountOf(mounts, dir)
+	if !ok {
+		return nil, fmt.Errorf("no mount found for %s", dir)
+	}
+
+	switch m.FSType {
+	case "btrfs":
+		return detectBtrfs(ctx, server, dir, snapshotRoot, m, mounts)
+	case "zfs":
+		return &zfs{server: server, volume: domain.Volume{Kind: domain.KindZfs, Name: m.Source, Mount: m.Point}}, nil
+	}
+	if strings.HasPrefix(m.Source, "/dev/") {
+		if provider, err := detectLvm(ctx, server, m); err == nil {
+			return provider, nil
+		}
+	}
+	return nil, fmt.Errorf("%s (%s on %s): %w", dir, m.FSType, m.Point, ErrUnsupported)
+}
+
+type mount struct {
+	Point  string
+	FSType string
+	Source string
+}
+
+func readMounts() ([]mount, error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var mounts []mount
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if m, ok := parseMountInfo(scanner.Text()); ok {
+			mounts = append(mounts, m)
+		}
+	}
+	return mounts, scanner.Err()
+}
+
+// parseMountInfo reads a line of /proc/self/mountinfo: the mount point is
+// the fifth field, the filesystem type and source follow the "-" separator.
+func parseMountInfo(line string) (mount, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 10 {
+		return mount{}, false
+	}
+	for i := 6; i < len(fields)-2; i++ {
+		if fields[i] == "-" {
+			return mount{Point: unescapeMount(fields[4]), FSType: fields[i+1], Source: unescapeMount(fields[i+2])}, true
+		}
+	}
+	return mount{}, false
+}
+
+// unescapeMount undoes the octal escapes mountinfo uses for spaces, tabs
+// and backslashes.
+func unescapeMount(value string) string {
+	replacer := strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+	return replacer.Replace(value)
+}
+
+// mountOf returns the mount dir is on: the deepest mount point above it,
+// the last one mounted when several share a point.
+func mountOf(mounts []mount, dir string) (mount, bool) {
+	var found mount
+	ok := false
+	for _, m := range mounts {
+		if !within(dir, m.Point) {
+			continue
+		}
+		if !ok || len(m.Point) >= len(found.Point) {
+			found, ok = m, true
+		}
+	}
+	return found, ok
+}
+
+func within(path, root string) bool {
+	if root == "/" {
+		return strings.HasPrefix(path, "/")
+	}
+	return path == root || strings.HasPrefix(path, root+"/")
+}
+
+func requireTool(name string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s is not installed", name)
+	}
+	return nil
+}
+
+// run executes a snapshot tool and returns its standard output; the error
+// carries what it printed on standard error.
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		text := strings.TrimSpace(stderr.String())
+		if text == "" {
+			text = err.Error()
+		}
+		return stdout.String(), fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), text)
+	}
+	return stdout.String(), nil
+}
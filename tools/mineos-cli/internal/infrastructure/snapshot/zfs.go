@@ -0,0 +1,67 @@
+package snapshot
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/snapshot"
+)
+
+// zfs snapshots the dataset mounted where the server directory is.
+type zfs struct {
+	server string
+	volume domain.Volume
+}
+
+func (z *zfs) Volume() domain.Volume { return z.volume }
+
+func (z *zfs) Create(ctx context.Context, name string) (domain.Snapshot, error) {
+	ref := z.volume.Name + "@" + name
+	if _, err := run(ctx, "zfs", "snapshot", ref); err != nil {
+		return domain.Snapshot{}, err
+	}
+	return z.snapshot(name, ref, 0), nil
+}
+
+func (z *zfs) snapshot(name, ref string, size int64) domain.Snapshot {
+	server, created, _ := domain.ParseName(name)
+	return domain.Snapshot{Name: name, Server: server, Created: created, Volume: z.volume, Ref: ref, Size: size}
+}
+
+func (z *zfs) List(ctx context.Context) ([]domain.Snapshot, error) {
+	output, err := run(ctx, "zfs", "list", "-H", "-p", "-t", "snapshot", "-d", "1", "-o", "name,used", z.volume.Name)
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []domain.Snapshot
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 2 {
+			continue
+		}
+		_, name, ok := strings.Cut(fields[0], "@")
+		if !ok {
+			continue
+		}
+		if server, _, ok := domain.ParseName(name); ok && server == z.server {
+			size, _ := strconv.ParseInt(fields[1], 10, 64)
+			snapshots = append(snapshots, z.snapshot(name, fields[0], size))
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Created.Before(snapshots[j].Created) })
+	return snapshots, nil
+}
+
+// Rollback uses zfs rollback -r, which also destroys the dataset's newer
+// snapshots: ZFS can only roll back to its latest one.
+func (z *zfs) Rollback(ctx context.Context, snap domain.Snapshot) error {
+	_, err := run(ctx, "zfs", "rollback", "-r", snap.Ref)
+	return err
+}
+
+func (z *zfs) Delete(ctx context.Context, snap domain.Snapshot) error {
+	_, err := run(ctx, "zfs", "destroy", snap.Ref)
+	return err
+}
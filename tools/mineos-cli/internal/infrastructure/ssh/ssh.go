@@ -0,0 +1,218 @@
+// Package ssh reaches a MineOS install on another host through the system
+// ssh client, so keys, agents and ~/.ssh/config apply as they do in a shell.
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDir is the remote install directory, relative to the login's home.
+const DefaultDir = "mineos"
+
+// Remote is an ssh destination and the MineOS directory on it.
+type Remote struct {
+	// Target is the ssh destination: host, user@host or a ~/.ssh/config alias.
+	Target string
+	Port   string
+	// Dir is the install directory; relative paths start at the home directory.
+	Dir string
+}
+
+// Parse reads user@host, user@host:port or ssh://user@host:port.
+func Parse(raw, dir string) (Remote, error) {
+	value := strings.TrimPrefix(strings.TrimSpace(raw), "ssh://")
+	value = strings.TrimSuffix(value, "/")
+	if value == "" {
+		return Remote{}, errors.New("empty ssh destination")
+	}
+	remote := Remote{Target: value, Dir: strings.TrimSpace(dir)}
+	if remote.Dir == "" {
+		remote.Dir = DefaultDir
+	}
+	at := strings.LastIndex(value, "@")
+	if host, port, err := net.SplitHostPort(value[at+1:]); err == nil {
+		if _, err := strconv.Atoi(port); err != nil {
+			return Remote{}, fmt.Errorf("invalid ssh port %q", port)
+		}
+		remote.Target = value[:at+1] + host
+		remote.Port = port
+	}
+	if strings.HasPrefix(remote.Target, "-") {
+		return Remote{}, fmt.Errorf("invalid ssh destination %q", raw)
+	}
+	return remote, nil
+}
+
+// String is the destination in the form Parse reads.
+func (r Remote) String() string {
+	if r.Port == "" {
+		return r.Target
+	}
+	at := strings.LastIndex(r.Target, "@")
+	return r.Target[:at+1] + net.JoinHostPort(r.Target[at+1:], r.Port)
+}
+
+func (r Remote) args(extra ...string) []string {
+	args := []string{"-o", "ServerAliveInterval=15"}
+	if r.Port != "" {
+		args = append(args, "-p", r.Port)
+	}
+	args = append(args, extra...)
+	return append(args, "--", r.Target)
+}
+
+// Command runs argv in the remote install directory. ssh hands the remote
+// shell a single string, so every argument is quoted; env entries
+// (KEY=value) are set for that command only. tty requests a terminal, for
+// interactive commands - it merges the remote stderr into stdout.
+func (r Remote) Command(ctx context.Context, tty bool, env []string, argv ...string) *exec.Cmd {
+	var script strings.Builder
+	script.WriteString("cd " + Quote(r.Dir) + " && ")
+	if len(env) > 0 {
+		script.WriteString("env")
+		for _, entry := range env {
+			script.WriteString(" " + Quote(entry))
+		}
+		script.WriteString(" ")
+	}
+	for i, arg := range argv {
+		if i > 0 {
+			script.WriteString(" ")
+		}
+		script.WriteString(Quote(arg))
+	}
+
+	flag := "-T"
+	if tty {
+		flag = "-t"
+	}
+	return exec.CommandContext(ctx, "ssh", append(r.args(flag), script.String())...)
+}
+
+// ReadFile returns a file from the install directory. ok is false when the
+// file does not exist.
+func (r Remote) ReadFile(ctx context.Context, name string) (data []byte, ok bool, err error) {
+	cmd := r.Command(ctx, false, nil, "sh", "-c", `test ! -e "$1" && exit 3; cat -- "$1"`, "sh", name)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	data, err = cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 3 {
+		return nil, false, nil
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, false, fmt.Errorf("%s: %s", r, msg)
+		}
+		return nil, false, fmt.Errorf("%s: %w", r, err)
+	}
+	return data, true, nil
+}
+
+// Quote makes value a single word for a POSIX shell.
+func Quote(value string) string {
+	if value == "" {
+		return "''"
+	}
+	safe := true
+	for _, c := range value {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_./:=@,+%", c)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// Tunnel is a local port forwarded to a port on the remote host.
+type Tunnel struct {
+	// LocalPort is the loopback port that reaches the remote port.
+	LocalPort int
+	cmd       *exec.Cmd
+	exited    chan error
+}
+
+// Forward forwards a free loopback port to remotePort on remoteHost, a
+// loopback name on the remote machine (127.0.0.1, ::1 or localhost), so
+// the remote port never needs to be exposed. It returns once the port
+// accepts connections.
+func (r Remote) Forward(ctx context.Context, remoteHost, remotePort string, timeout time.Duration) (*Tunnel, error) {
+	if _, err := strconv.Atoi(remotePort); err != nil {
+		return nil, fmt.Errorf("invalid remote port %q", remotePort)
+	}
+	localPort, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(remoteHost, ":") {
+		remoteHost = "[" + strings.Trim(remoteHost, "[]") + "]"
+	}
+	spec := fmt.Sprintf("127.0.0.1:%d:%s:%s", localPort, remoteHost, remotePort)
+	cmd := exec.Command("ssh", r.args("-N", "-o", "ExitOnForwardFailure=yes", "-L", spec)...)
+	// ssh prompts for passwords and host keys on the terminal itself.
+	cmd.Stdin = os.Stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start ssh: %w", err)
+	}
+
+	tunnel := &Tunnel{LocalPort: localPort, cmd: cmd, exited: make(chan error, 1)}
+	go func() { tunnel.exited <- cmd.Wait() }()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(localPort))
+	for {
+		if conn, err := net.DialTimeout("tcp", address, time.Second); err == nil {
+			conn.Close()
+			return tunnel, nil
+		}
+		select {
+		case err := <-tunnel.exited:
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" && err != nil {
+				msg = err.Error()
+			}
+			return nil, fmt.Errorf("ssh tunnel to %s failed: %s", r, msg)
+		case <-deadline.C:
+			tunnel.Close()
+			return nil, fmt.Errorf("ssh tunnel to %s: no connection after %s", r, timeout)
+		case <-ctx.Done():
+			tunnel.Close()
+			return nil, ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// Close stops the ssh process.
+func (t *Tunnel) Close() {
+	if t == nil || t.cmd.Process == nil {
+		return
+	}
+	_ = t.cmd.Process.Kill()
+	<-t.exited
+	t.exited <- nil
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
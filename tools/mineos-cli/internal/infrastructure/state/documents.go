@@ -0,0 +1,67 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Namespaces of the YAML documents users write with 'mineos state edit'.
+// They are stored as text, so comments and layout survive. Contexts span
+// installations and use GlobalKey; schedules and alert rules belong to one
+// installation and use its InstallationKey.
+const (
+	NamespaceContexts   = "contexts"
+	NamespaceSchedules  = "schedules"
+	NamespaceAlertRules = "alert-rules"
+)
+
+// GlobalKey is the key of documents that are not per installation.
+const GlobalKey = "default"
+
+// InstallationKey names an installation by the absolute path of its .env.
+func InstallationKey(envPath string) string {
+	if envPath == "" {
+		envPath = ".env"
+	}
+	if abs, err := filepath.Abs(envPath); err == nil {
+		return abs
+	}
+	return envPath
+}
+
+// Document returns the document under namespace and key; found is false
+// when there is none.
+func (s *Store) Document(ctx context.Context, namespace, key string) ([]byte, bool, error) {
+	var text string
+	_, found, err := s.Get(ctx, namespace, key, &text)
+	return []byte(text), found, err
+}
+
+// PutDocument stores a document under namespace and key.
+func (s *Store) PutDocument(ctx context.Context, namespace, key string, data []byte) error {
+	return s.Put(ctx, namespace, key, string(data))
+}
+
+// ImportFile moves the file at legacy, where the document was kept before
+// it moved into the store, under namespace and key, unless the store already
+// has one there. The file is renamed to .imported, so this happens once.
+func (s *Store) ImportFile(ctx context.Context, namespace, key, legacy string) error {
+	data, err := os.ReadFile(legacy)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var existing string
+	if _, found, err := s.Get(ctx, namespace, key, &existing); err != nil {
+		return err
+	} else if !found {
+		if err := s.PutDocument(ctx, namespace, key, data); err != nil {
+			return err
+		}
+	}
+	return os.Rename(legacy, legacy+".imported")
+}
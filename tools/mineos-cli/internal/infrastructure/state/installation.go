@@ -0,0 +1,156 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// InstallationDirName is the directory beside .env that holds the state of
+// that installation: its store and the files its commands keep. Every user
+// and the stack's systemd unit, which runs as root, find it from the same
+// .env, so they share it.
+const InstallationDirName = "mineos-state"
+
+// DirForEnv is the state directory of the installation whose .env is
+// envPath.
+func DirForEnv(envPath string) string {
+	return filepath.Join(filepath.Dir(InstallationKey(envPath)), InstallationDirName)
+}
+
+// PathForEnv is the installation's store, for its schedule, alert rules and
+// metrics history, in a directory made as FileForEnv makes it. The first
+// time, it moves them out of the user's store at DefaultPath, where they
+// were kept before.
+func PathForEnv(envPath string) string {
+	dir := DirForEnv(envPath)
+	path := filepath.Join(dir, DefaultFileName)
+	_ = makeDirLike(dir, filepath.Dir(dir))
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		_ = moveFromUserStore(path, InstallationKey(envPath))
+	}
+	return path
+}
+
+// FileForEnv is the file name in the installation's state directory. The
+// directory is created on first use, with the mode and owner of the one
+// holding .env, and a file of that name beside .env, where it was kept
+// before, is moved into it.
+func FileForEnv(envPath, name string) string {
+	dir := DirForEnv(envPath)
+	path := filepath.Join(dir, name)
+	if err := makeDirLike(dir, filepath.Dir(dir)); err != nil {
+		return path
+	}
+	legacy := filepath.Join(filepath.Dir(dir), name)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		_ = os.Rename(legacy, path)
+	}
+	return path
+}
+
+// makeDirLike creates dir with the permissions of parent and, when running
+// as root, gives it parent's owner, so a store first written by a root unit
+// stays usable by the operator.
+func makeDirLike(dir, parent string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	perm := os.FileMode(0o700)
+	if info, err := os.Stat(parent); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(dir, perm); err != nil {
+		return err
+	}
+	chownLike(dir, parent)
+	return nil
+}
+
+// moveFromUserStore moves the schedule, alert rules and metrics samples of
+// installation from the user's store into a new store at path. Nothing is
+// created when the user's store does not exist.
+func moveFromUserStore(path, installation string) error {
+	user := DefaultPath()
+	if _, err := os.Stat(user); err != nil || sameFile(user, path) {
+		return err
+	}
+	store, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	conn, err := store.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS legacy`, user); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, `DETACH DATABASE legacy`)
+
+	const scoped = `namespace IN ('` + NamespaceSchedules + `', '` + NamespaceAlertRules + `') AND key = ?`
+	for _, statement := range []string{
+		`INSERT OR IGNORE INTO main.entries SELECT * FROM legacy.entries WHERE ` + scoped,
+		`INSERT OR IGNORE INTO main.samples SELECT * FROM legacy.samples WHERE installation = ?`,
+		`DELETE FROM legacy.entries WHERE ` + scoped,
+		`DELETE FROM legacy.samples WHERE installation = ?`,
+	} {
+		if _, err := conn.ExecContext(ctx, statement, installation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// IsInstallationScoped reports whether entries in namespace belong to one
+// installation and live in its store rather than the user's.
+func IsInstallationScoped(namespace string) bool {
+	return namespace == NamespaceSchedules || namespace == NamespaceAlertRules
+}
+
+// Split divides an export into what belongs in the user's store and what
+// belongs in an installation's.
+func (e Export) Split() (user, installation Export) {
+	user = Export{Version: e.Version, ExportedAt: e.ExportedAt, Operations: e.Operations}
+	installation = Export{Version: e.Version, ExportedAt: e.ExportedAt, Samples: e.Samples}
+	for _, entry := range e.Entries {
+		if IsInstallationScoped(entry.Namespace) {
+			installation.Entries = append(installation.Entries, entry)
+		} else {
+			user.Entries = append(user.Entries, entry)
+		}
+	}
+	return user, installation
+}
+
+// Retarget moves the installation-scoped entries and samples of an export
+// to the installation whose .env is envPath, so state exported from one
+// installation can be imported into another at a different path.
+func (e Export) Retarget(envPath string) Export {
+	key := InstallationKey(envPath)
+	entries := make([]Entry, 0, len(e.Entries))
+	for _, entry := range e.Entries {
+		if IsInstallationScoped(entry.Namespace) {
+			entry.Key = key
+		}
+		entries = append(entries, entry)
+	}
+	samples := make([]Sample, 0, len(e.Samples))
+	for _, sample := range e.Samples {
+		sample.Installation = key
+		samples = append(samples, sample)
+	}
+	e.Entries, e.Samples = entries, samples
+	return e
+}
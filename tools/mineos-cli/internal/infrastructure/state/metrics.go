@@ -0,0 +1,175 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// LegacyMetricsFileName is where 'agent --record-metrics' kept the history
+// before it moved into the store, next to .env. Metrics imports it once.
+const LegacyMetricsFileName = "mineos-metrics.db"
+
+// Sample is a recorded performance sample with the installation it was
+// taken on, as exported.
+type Sample struct {
+	Installation string `json:"installation"`
+	ports.PerformanceSample
+}
+
+// Metrics is the local history of server performance samples of one
+// installation, so graphs can cover longer windows than the API retains.
+type Metrics struct {
+	db           *sql.DB
+	installation string
+}
+
+// Metrics returns the history of the installation whose .env is envPath.
+// The first time, it imports the samples of the LegacyMetricsFileName beside
+// it and renames that file to .imported.
+func (s *Store) Metrics(ctx context.Context, envPath string) (*Metrics, error) {
+	installation := InstallationKey(envPath)
+	legacy := filepath.Join(filepath.Dir(installation), LegacyMetricsFileName)
+	if _, err := os.Stat(legacy); err == nil {
+		if err := s.importMetrics(ctx, installation, legacy); err != nil {
+			return nil, fmt.Errorf("import %s: %w", legacy, err)
+		}
+	}
+	return &Metrics{db: s.db, installation: installation}, nil
+}
+
+func (s *Store) importMetrics(ctx context.Context, installation, path string) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS legacy`, path); err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, `INSERT OR IGNORE INTO samples
+		(installation, server, ts, running, cpu, ram_used, ram_total, tps, players)
+		SELECT ?, server, ts, running, cpu, ram_used, ram_total, tps, players FROM legacy.samples`, installation)
+	if _, detachErr := conn.ExecContext(ctx, `DETACH DATABASE legacy`); err == nil {
+		err = detachErr
+	}
+	if err != nil {
+		return err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		_ = os.Remove(path + suffix)
+	}
+	return os.Rename(path, path+".imported")
+}
+
+// Record stores samples; a second sample for the same server and second
+// replaces the first.
+func (m *Metrics) Record(ctx context.Context, samples []ports.PerformanceSample) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO samples
+		(installation, server, ts, running, cpu, ram_used, ram_total, tps, players) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, sample := range samples {
+		if err := insertSample(ctx, stmt, m.installation, sample); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertSample(ctx context.Context, stmt *sql.Stmt, installation string, sample ports.PerformanceSample) error {
+	ts := sample.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	var tps sql.NullFloat64
+	if sample.Tps != nil {
+		tps = sql.NullFloat64{Float64: *sample.Tps, Valid: true}
+	}
+	_, err := stmt.ExecContext(ctx, installation, sample.ServerName, ts.Unix(), sample.IsRunning,
+		sample.CpuPercent, sample.RamUsedMb, sample.RamTotalMb, tps, sample.PlayerCount)
+	return err
+}
+
+// Query returns the samples of one server taken at or after since, oldest
+// first.
+func (m *Metrics) Query(ctx context.Context, server string, since time.Time) ([]ports.PerformanceSample, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT ts, running, cpu, ram_used, ram_total, tps, players
+		FROM samples WHERE installation = ? AND server = ? AND ts >= ? ORDER BY ts`, m.installation, server, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []ports.PerformanceSample
+	for rows.Next() {
+		sample := ports.PerformanceSample{ServerName: server}
+		if err := scanSample(rows, &sample); err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
+func scanSample(rows *sql.Rows, sample *ports.PerformanceSample, extra ...any) error {
+	var ts int64
+	var tps sql.NullFloat64
+	dest := append(extra, &ts, &sample.IsRunning, &sample.CpuPercent, &sample.RamUsedMb,
+		&sample.RamTotalMb, &tps, &sample.PlayerCount)
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	sample.Timestamp = time.Unix(ts, 0).UTC()
+	if tps.Valid {
+		value := tps.Float64
+		sample.Tps = &value
+	}
+	return nil
+}
+
+// Oldest returns when the earliest sample of server was taken; ok is false
+// when there is none.
+func (m *Metrics) Oldest(ctx context.Context, server string) (time.Time, bool, error) {
+	var ts sql.NullInt64
+	if err := m.db.QueryRowContext(ctx, `SELECT MIN(ts) FROM samples WHERE installation = ? AND server = ?`,
+		m.installation, server).Scan(&ts); err != nil {
+		return time.Time{}, false, err
+	}
+	if !ts.Valid {
+		return time.Time{}, false, nil
+	}
+	return time.Unix(ts.Int64, 0).UTC(), true, nil
+}
+
+// Uptime returns the share of samples of server taken at or after since in
+// which it was running, from 0 to 1; ok is false when there are none.
+func (m *Metrics) Uptime(ctx context.Context, server string, since time.Time) (float64, bool, error) {
+	var share sql.NullFloat64
+	if err := m.db.QueryRowContext(ctx, `SELECT AVG(running) FROM samples WHERE installation = ? AND server = ? AND ts >= ?`,
+		m.installation, server, since.Unix()).Scan(&share); err != nil {
+		return 0, false, err
+	}
+	return share.Float64, share.Valid, nil
+}
+
+// Prune deletes samples older than before and returns how many went.
+func (m *Metrics) Prune(ctx context.Context, before time.Time) (int64, error) {
+	result, err := m.db.ExecContext(ctx, `DELETE FROM samples WHERE installation = ? AND ts < ?`, m.installation, before.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
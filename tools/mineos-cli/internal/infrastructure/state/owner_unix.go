@@ -0,0 +1,23 @@
+//go:build !windows
+
+package state
+
+import (
+	"os"
+	"syscall"
+)
+
+// chownLike gives path the owner and group of like when running as root;
+// otherwise the files are the caller's already.
+func chownLike(path, like string) {
+	if os.Geteuid() != 0 {
+		return
+	}
+	info, err := os.Stat(like)
+	if err != nil {
+		return
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && stat.Uid != 0 {
+		_ = os.Chown(path, int(stat.Uid), int(stat.Gid))
+	}
+}
@@ -0,0 +1,316 @@
+// Package state is the CLI's own durable state, in two SQLite stores of the
+// same schema. The user's, in their state directory, has the update check,
+// the record of operations run and the contexts they write. Each
+// installation's, in the directory beside its .env, has its schedule and
+// alert rules and the metrics history the agent records.
+package state
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// DefaultFileName is the database in the state directory.
+const DefaultFileName = "state.db"
+
+// DirEnv overrides the user's state directory.
+const DirEnv = "MINEOS_STATE_DIR"
+
+// FormatVersion is the version of the export format. Version 1 exports,
+// from before the metrics history moved here, are still read.
+const FormatVersion = 2
+
+const schema = `
+CREATE TABLE IF NOT EXISTS entries (
+	namespace  TEXT    NOT NULL,
+	key        TEXT    NOT NULL,
+	value      TEXT    NOT NULL,
+	updated_at INTEGER NOT NULL,
+	PRIMARY KEY (namespace, key)
+) WITHOUT ROWID;
+CREATE TABLE IF NOT EXISTS operations (
+	started_at  INTEGER NOT NULL,
+	command     TEXT    NOT NULL,
+	target      TEXT    NOT NULL,
+	exit_code   INTEGER NOT NULL,
+	duration_ms INTEGER NOT NULL,
+	PRIMARY KEY (started_at, command)
+) WITHOUT ROWID;
+CREATE TABLE IF NOT EXISTS samples (
+	installation TEXT    NOT NULL,
+	server       TEXT    NOT NULL,
+	ts           INTEGER NOT NULL,
+	running      INTEGER NOT NULL,
+	cpu          REAL    NOT NULL,
+	ram_used     INTEGER NOT NULL,
+	ram_total    INTEGER NOT NULL,
+	tps          REAL,
+	players      INTEGER NOT NULL,
+	PRIMARY KEY (installation, server, ts)
+) WITHOUT ROWID;
+`
+
+// Namespaces of entries.
+const (
+	NamespaceUpdateCheck = "update-check"
+)
+
+// Entry is a value stored under a namespace and key.
+type Entry struct {
+	Namespace string          `json:"namespace"`
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Operation is a CLI command that ran.
+type Operation struct {
+	StartedAt time.Time `json:"started_at"`
+	Command   string    `json:"command"`
+	// Target is the --ssh host or the .env the command ran against.
+	Target   string `json:"target"`
+	ExitCode int    `json:"exit_code"`
+	Duration int64  `json:"duration_ms"`
+}
+
+// Export is the whole store as 'mineos state export' writes it.
+type Export struct {
+	Version    int         `json:"version"`
+	ExportedAt time.Time   `json:"exported_at"`
+	Entries    []Entry     `json:"entries"`
+	Operations []Operation `json:"operations"`
+	Samples    []Sample    `json:"samples"`
+}
+
+// maxOperations bounds the operation record; older ones are dropped.
+const maxOperations = 10000
+
+type Store struct {
+	db   *sql.DB
+	path string
+}
+
+// DefaultDir is the user's state directory: MINEOS_STATE_DIR, or mineos in
+// the platform's state directory: $XDG_STATE_HOME or ~/.local/state on
+// Linux, Application Support on macOS and the local AppData on Windows.
+func DefaultDir() string {
+	if dir := strings.TrimSpace(os.Getenv(DirEnv)); dir != "" {
+		return dir
+	}
+	switch runtime.GOOS {
+	case "windows":
+		if dir, err := os.UserCacheDir(); err == nil {
+			return filepath.Join(dir, "mineos", "state")
+		}
+	case "darwin":
+		if dir, err := os.UserConfigDir(); err == nil {
+			return filepath.Join(dir, "mineos", "state")
+		}
+	default:
+		if dir := strings.TrimSpace(os.Getenv("XDG_STATE_HOME")); filepath.IsAbs(dir) {
+			return filepath.Join(dir, "mineos")
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, ".local", "state", "mineos")
+		}
+	}
+	return filepath.Join(os.TempDir(), "mineos-state")
+}
+
+// DefaultPath is the user's store, in DefaultDir.
+func DefaultPath() string {
+	return filepath.Join(DefaultDir(), DefaultFileName)
+}
+
+// Open opens the store, creating the file and schema when needed.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		chownLike(path+suffix, filepath.Dir(path))
+	}
+	return &Store{db: db, path: path}, nil
+}
+
+func (s *Store) Path() string {
+	return s.path
+}
+
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Get decodes the value under namespace and key into out. found is false
+// when there is none.
+func (s *Store) Get(ctx context.Context, namespace, key string, out any) (time.Time, bool, error) {
+	var value string
+	var updated int64
+	err := s.db.QueryRowContext(ctx, `SELECT value, updated_at FROM entries WHERE namespace = ? AND key = ?`, namespace, key).Scan(&value, &updated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if err := json.Unmarshal([]byte(value), out); err != nil {
+		return time.Time{}, true, err
+	}
+	return time.UnixMilli(updated), true, nil
+}
+
+// Put stores value, encoded as JSON, under namespace and key.
+func (s *Store) Put(ctx context.Context, namespace, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT OR REPLACE INTO entries (namespace, key, value, updated_at) VALUES (?, ?, ?, ?)`,
+		namespace, key, string(data), time.Now().UnixMilli())
+	return err
+}
+
+// Delete removes the value under namespace and key.
+func (s *Store) Delete(ctx context.Context, namespace, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM entries WHERE namespace = ? AND key = ?`, namespace, key)
+	return err
+}
+
+// RecordOperation adds a command to the operation record, dropping the
+// oldest beyond maxOperations.
+func (s *Store) RecordOperation(ctx context.Context, op Operation) error {
+	if _, err := s.db.ExecContext(ctx, `INSERT OR REPLACE INTO operations (started_at, command, target, exit_code, duration_ms) VALUES (?, ?, ?, ?, ?)`,
+		op.StartedAt.UnixMilli(), op.Command, op.Target, op.ExitCode, op.Duration); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, `DELETE FROM operations WHERE started_at < (
+		SELECT started_at FROM operations ORDER BY started_at DESC LIMIT 1 OFFSET ?)`, maxOperations-1)
+	return err
+}
+
+// Export reads the whole store.
+func (s *Store) Export(ctx context.Context) (Export, error) {
+	export := Export{Version: FormatVersion, ExportedAt: time.Now().UTC(), Entries: []Entry{}, Operations: []Operation{}, Samples: []Sample{}}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT namespace, key, value, updated_at FROM entries ORDER BY namespace, key`)
+	if err != nil {
+		return export, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry Entry
+		var value string
+		var updated int64
+		if err := rows.Scan(&entry.Namespace, &entry.Key, &value, &updated); err != nil {
+			return export, err
+		}
+		entry.Value = json.RawMessage(value)
+		entry.UpdatedAt = time.UnixMilli(updated).UTC()
+		export.Entries = append(export.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return export, err
+	}
+
+	ops, err := s.db.QueryContext(ctx, `SELECT started_at, command, target, exit_code, duration_ms FROM operations ORDER BY started_at`)
+	if err != nil {
+		return export, err
+	}
+	defer ops.Close()
+	for ops.Next() {
+		var op Operation
+		var started int64
+		if err := ops.Scan(&started, &op.Command, &op.Target, &op.ExitCode, &op.Duration); err != nil {
+			return export, err
+		}
+		op.StartedAt = time.UnixMilli(started).UTC()
+		export.Operations = append(export.Operations, op)
+	}
+	if err := ops.Err(); err != nil {
+		return export, err
+	}
+
+	samples, err := s.db.QueryContext(ctx, `SELECT installation, server, ts, running, cpu, ram_used, ram_total, tps, players
+		FROM samples ORDER BY installation, server, ts`)
+	if err != nil {
+		return export, err
+	}
+	defer samples.Close()
+	for samples.Next() {
+		var sample Sample
+		if err := scanSample(samples, &sample.PerformanceSample, &sample.Installation, &sample.ServerName); err != nil {
+			return export, err
+		}
+		export.Samples = append(export.Samples, sample)
+	}
+	return export, samples.Err()
+}
+
+// Import writes an export into the store. Entries replace those with the
+// same namespace and key, or with replace, the whole store is emptied
+// first.
+func (s *Store) Import(ctx context.Context, export Export, replace bool) error {
+	if export.Version != 1 && export.Version != FormatVersion {
+		return errors.New("unsupported state export version; export it again with this CLI")
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if replace {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM entries; DELETE FROM operations; DELETE FROM samples;`); err != nil {
+			return err
+		}
+	}
+	for _, entry := range export.Entries {
+		if entry.Namespace == "" || entry.Key == "" || !json.Valid(entry.Value) {
+			return errors.New("invalid entry " + entry.Namespace + "/" + entry.Key)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO entries (namespace, key, value, updated_at) VALUES (?, ?, ?, ?)`,
+			entry.Namespace, entry.Key, string(entry.Value), entry.UpdatedAt.UnixMilli()); err != nil {
+			return err
+		}
+	}
+	for _, op := range export.Operations {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO operations (started_at, command, target, exit_code, duration_ms) VALUES (?, ?, ?, ?, ?)`,
+			op.StartedAt.UnixMilli(), op.Command, op.Target, op.ExitCode, op.Duration); err != nil {
+			return err
+		}
+	}
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO samples
+		(installation, server, ts, running, cpu, ram_used, ram_total, tps, players) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, sample := range export.Samples {
+		if sample.Installation == "" || sample.ServerName == "" {
+			return errors.New("invalid metrics sample without installation or server")
+		}
+		if err := insertSample(ctx, stmt, sample.Installation, sample.PerformanceSample); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,164 @@
+// Package statuspage renders a public, static status page: an index.html
+// for people and a status.json for bots and widgets, written side by side
+// into a directory any web server can serve.
+package statuspage
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/motd"
+)
+
+const (
+	IndexFile = "index.html"
+	JSONFile  = "status.json"
+)
+
+//go:embed index.html.tmpl
+var indexTemplate string
+
+var page = template.Must(template.New(IndexFile).Funcs(template.FuncMap{
+	"motd":    motdHTML,
+	"percent": func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+	"time":    func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 UTC") },
+	"join":    strings.Join,
+}).Parse(indexTemplate))
+
+// Page is everything the status page shows.
+type Page struct {
+	Title     string    `json:"title"`
+	Generated time.Time `json:"generated"`
+	Servers   []Server  `json:"servers"`
+	// Refresh is how often the page is regenerated; the HTML reloads itself
+	// at the same rate. Zero disables the reload.
+	Refresh time.Duration `json:"-"`
+}
+
+// Server is one entry on the page. Nothing here is secret: it is what the
+// Minecraft server list already tells anyone who pings the server.
+type Server struct {
+	Name    string `json:"name"`
+	Edition string `json:"edition"`
+	Online  bool   `json:"online"`
+	Address string `json:"address,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Motd is the plain text; MotdSegments keeps the colors for the HTML.
+	Motd         string         `json:"motd,omitempty"`
+	MotdSegments []motd.Segment `json:"-"`
+	Players      int            `json:"players"`
+	MaxPlayers   int            `json:"maxPlayers,omitempty"`
+	// PlayerNames may be a sample, as servers only list up to 12 names.
+	PlayerNames []string `json:"playerNames,omitempty"`
+	// Uptime is the share of recorded samples in which the server was
+	// running, per window such as "24h"; empty without a metrics store.
+	Uptime map[string]float64 `json:"uptime,omitempty"`
+}
+
+// Write renders the page into dir, creating it if needed. Each file is
+// replaced atomically so a web server never serves half a page.
+func Write(dir string, p Page) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	var html strings.Builder
+	if err := page.Execute(&html, view{Page: p, RefreshSeconds: int(p.Refresh.Seconds())}); err != nil {
+		return fmt.Errorf("render %s: %w", IndexFile, err)
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeAtomic(filepath.Join(dir, JSONFile), append(data, '\n')); err != nil {
+		return err
+	}
+	return writeAtomic(filepath.Join(dir, IndexFile), []byte(html.String()))
+}
+
+type view struct {
+	Page
+	RefreshSeconds int
+}
+
+// Online counts the servers that are up.
+func (p Page) Online() int {
+	count := 0
+	for _, server := range p.Servers {
+		if server.Online {
+			count++
+		}
+	}
+	return count
+}
+
+// UptimeWindows lists the uptime windows in display order.
+func (s Server) UptimeWindows() []string {
+	var windows []string
+	for _, window := range []string{"24h", "7d", "30d"} {
+		if _, ok := s.Uptime[window]; ok {
+			windows = append(windows, window)
+		}
+	}
+	return windows
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// motdHTML renders MOTD segments as styled spans, escaping the text.
+func motdHTML(segments []motd.Segment) template.HTML {
+	var b strings.Builder
+	for _, segment := range segments {
+		var css []string
+		if segment.Style.Color != "" {
+			css = append(css, "color:"+segment.Style.Color)
+		}
+		if segment.Style.Bold {
+			css = append(css, "font-weight:bold")
+		}
+		if segment.Style.Italic {
+			css = append(css, "font-style:italic")
+		}
+		var decorations []string
+		if segment.Style.Underlined {
+			decorations = append(decorations, "underline")
+		}
+		if segment.Style.Strikethrough {
+			decorations = append(decorations, "line-through")
+		}
+		if len(decorations) > 0 {
+			css = append(css, "text-decoration:"+strings.Join(decorations, " "))
+		}
+		text := strings.ReplaceAll(template.HTMLEscapeString(segment.Text), "\n", "<br>")
+		if len(css) == 0 {
+			b.WriteString(text)
+			continue
+		}
+		// Colors come from the motd parser as #rrggbb, so they are safe in
+		// the attribute.
+		fmt.Fprintf(&b, `<span style="%s">%s</span>`, strings.Join(css, ";"), text)
+	}
+	return template.HTML(b.String())
+}
@@ -0,0 +1,395 @@
+// Package tuning reads and changes the kernel settings the tuning domain
+// checks. Every path is taken relative to a root directory, "/" normally.
+package tuning
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/tuning"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+// DefaultRevertFileName is kept in the installation's state directory.
+const DefaultRevertFileName = "mineos-tune-revert.json"
+
+const (
+	sysctlFile  = "/etc/sysctl.d/90-mineos.conf"
+	tmpfilesMod = "/etc/tmpfiles.d/mineos-thp.conf"
+	// DockerDropIn is the systemd drop-in holding the daemon's open file limit.
+	DockerDropIn = "/etc/systemd/system/docker.service.d/mineos-limits.conf"
+)
+
+// Read collects the host settings; anything unreadable is left empty.
+func Read(root string) domain.Host {
+	var host domain.Host
+	host.MemTotalKB = memTotalKB(root)
+	if value, err := readUint(root, sysctlPath("vm.swappiness")); err == nil {
+		v := int(value)
+		host.Swappiness = &v
+	}
+	host.THPEnabled = readTrimmed(root, domain.ThpPaths[0])
+	host.THPDefrag = readTrimmed(root, domain.ThpPaths[1])
+	host.Swaps = swaps(root)
+	if value, err := readUint(root, sysctlPath("fs.file-max")); err == nil && value > 0 {
+		host.FileMax = &value
+	}
+	if limit, ok := dockerdNoFile(root); ok {
+		host.NoFile, host.NoFileSource = &limit, "dockerd"
+	} else if limit, ok := processNoFile(filepath.Join(root, "proc/self/limits")); ok {
+		host.NoFile, host.NoFileSource = &limit, "this shell"
+	}
+	return host
+}
+
+func sysctlPath(key string) string {
+	return "/proc/sys/" + strings.ReplaceAll(key, ".", "/")
+}
+
+func readTrimmed(root, path string) string {
+	data, err := os.ReadFile(filepath.Join(root, path))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readUint(root, path string) (uint64, error) {
+	return strconv.ParseUint(readTrimmed(root, path), 10, 64)
+}
+
+func memTotalKB(root string) int64 {
+	file, err := os.Open(filepath.Join(root, "proc/meminfo"))
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, _ := strconv.ParseInt(fields[1], 10, 64)
+			return kb
+		}
+	}
+	return 0
+}
+
+func swaps(root string) []domain.Swap {
+	file, err := os.Open(filepath.Join(root, "proc/swaps"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	var result []domain.Swap
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[2], 10, 64)
+		result = append(result, domain.Swap{Name: fields[0], Type: fields[1], SizeKB: size})
+	}
+	return result
+}
+
+// dockerdNoFile reads the soft open file limit of the running Docker
+// daemon, which needs root.
+func dockerdNoFile(root string) (uint64, bool) {
+	entries, err := os.ReadDir(filepath.Join(root, "proc"))
+	if err != nil {
+		return 0, false
+	}
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		dir := filepath.Join(root, "proc", entry.Name())
+		comm, err := os.ReadFile(filepath.Join(dir, "comm"))
+		if err != nil || strings.TrimSpace(string(comm)) != "dockerd" {
+			continue
+		}
+		return processNoFile(filepath.Join(dir, "limits"))
+	}
+	return 0, false
+}
+
+func processNoFile(path string) (uint64, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		rest, ok := strings.CutPrefix(line, "Max open files")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return 0, false
+		}
+		if fields[0] == "unlimited" {
+			return 1 << 62, true
+		}
+		limit, err := strconv.ParseUint(fields[0], 10, 64)
+		return limit, err == nil
+	}
+	return 0, false
+}
+
+// RevertFile records what Apply changed so Revert can put it back.
+type RevertFile struct {
+	Created time.Time         `json:"created"`
+	Values  []RevertValue     `json:"values"`
+	Files   []RevertFileEntry `json:"files"`
+}
+
+// RevertValue is a runtime setting and the value it had before.
+type RevertValue struct {
+	Kind     domain.ChangeKind `json:"kind"`
+	Key      string            `json:"key"`
+	Previous string            `json:"previous"`
+}
+
+// RevertFileEntry is a file Apply wrote and what it held before.
+type RevertFileEntry struct {
+	Path     string `json:"path"`
+	Existed  bool   `json:"existed"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// Applied describes what Apply did.
+type Applied struct {
+	Files []string
+	// RestartDocker is set when a change only takes effect once the Docker
+	// daemon restarts.
+	RestartDocker bool
+}
+
+// Apply makes the changes at runtime and persists them for the next boot.
+// The previous values go to revertPath before anything is touched; applying
+// again keeps the values from before the first apply.
+func Apply(root string, changes []domain.Change, revertPath string) (Applied, error) {
+	revert, _, err := LoadRevert(revertPath)
+	if err != nil {
+		return Applied{}, err
+	}
+	if revert.Created.IsZero() {
+		revert.Created = time.Now().UTC()
+	}
+
+	var sysctls, sysfs []domain.Change
+	var noFile *domain.Change
+	for i, change := range changes {
+		switch change.Kind {
+		case domain.KindSysctl:
+			sysctls = append(sysctls, change)
+		case domain.KindSysfs:
+			sysfs = append(sysfs, change)
+		case domain.KindDockerNoFile:
+			noFile = &changes[i]
+		default:
+			return Applied{}, fmt.Errorf("unknown change kind %q", change.Kind)
+		}
+	}
+
+	for _, change := range changes {
+		if change.Kind == domain.KindDockerNoFile {
+			continue
+		}
+		revert.recordValue(change.Kind, change.Key, readTrimmed(root, runtimePath(change)))
+	}
+	var applied Applied
+	writes := map[string]string{}
+	if len(sysctls) > 0 {
+		writes[sysctlFile] = mergeSysctlFile(readTrimmed(root, sysctlFile), sysctls)
+	}
+	if len(sysfs) > 0 {
+		writes[tmpfilesMod] = mergeTmpfiles(readTrimmed(root, tmpfilesMod), sysfs)
+	}
+	if noFile != nil {
+		writes[DockerDropIn] = "# Written by 'mineos tune'; containers inherit the daemon's limit.\n[Service]\nLimitNOFILE=" + noFile.Value + "\n"
+		applied.RestartDocker = true
+	}
+	paths := make([]string, 0, len(writes))
+	for path := range writes {
+		paths = append(paths, path)
+		revert.recordFile(root, path)
+	}
+	sort.Strings(paths)
+	if err := SaveRevert(revertPath, revert); err != nil {
+		return Applied{}, err
+	}
+
+	for _, change := range append(sysctls, sysfs...) {
+		if err := os.WriteFile(filepath.Join(root, runtimePath(change)), []byte(change.Value+"\n"), 0o644); err != nil {
+			return applied, fmt.Errorf("set %s: %w", change.Key, err)
+		}
+	}
+	for _, path := range paths {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			return applied, err
+		}
+		if err := os.WriteFile(full, []byte(writes[path]), 0o644); err != nil {
+			return applied, err
+		}
+		applied.Files = append(applied.Files, path)
+	}
+	return applied, nil
+}
+
+func runtimePath(change domain.Change) string {
+	if change.Kind == domain.KindSysctl {
+		return sysctlPath(change.Key)
+	}
+	return change.Key
+}
+
+// mergeSysctlFile replaces the keys being changed and keeps other lines.
+func mergeSysctlFile(existing string, changes []domain.Change) string {
+	keys := map[string]bool{}
+	for _, change := range changes {
+		keys[change.Key] = true
+	}
+	lines := []string{"# Written by 'mineos tune'; 'mineos tune --revert' removes it."}
+	for _, line := range strings.Split(existing, "\n") {
+		key, _, _ := strings.Cut(line, "=")
+		if keys[strings.TrimSpace(key)] || strings.HasPrefix(line, "# Written by 'mineos tune'") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	for _, change := range changes {
+		lines = append(lines, change.Key+" = "+change.Value)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// mergeTmpfiles writes sysfs values at boot with tmpfiles.d "w" lines.
+func mergeTmpfiles(existing string, changes []domain.Change) string {
+	paths := map[string]bool{}
+	for _, change := range changes {
+		paths[change.Key] = true
+	}
+	lines := []string{"# Written by 'mineos tune'; 'mineos tune --revert' removes it."}
+	for _, line := range strings.Split(existing, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && paths[fields[1]] || strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	for _, change := range changes {
+		lines = append(lines, "w "+change.Key+" - - - - "+change.Value)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (r *RevertFile) recordValue(kind domain.ChangeKind, key, previous string) {
+	for _, value := range r.Values {
+		if value.Kind == kind && value.Key == key {
+			return
+		}
+	}
+	if kind == domain.KindSysfs {
+		// Restore the selected option, not the whole "a [b] c" list.
+		if start := strings.Index(previous, "["); start >= 0 {
+			if end := strings.Index(previous[start:], "]"); end > 0 {
+				previous = previous[start+1 : start+end]
+			}
+		}
+	}
+	r.Values = append(r.Values, RevertValue{Kind: kind, Key: key, Previous: previous})
+}
+
+func (r *RevertFile) recordFile(root, path string) {
+	for _, file := range r.Files {
+		if file.Path == path {
+			return
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(root, path))
+	r.Files = append(r.Files, RevertFileEntry{Path: path, Existed: err == nil, Previous: string(data)})
+}
+
+// LoadRevert reads a revert file; the boolean is false when there is none.
+func LoadRevert(path string) (RevertFile, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return RevertFile{}, false, nil
+		}
+		return RevertFile{}, false, err
+	}
+	var revert RevertFile
+	if err := json.Unmarshal(data, &revert); err != nil {
+		return RevertFile{}, true, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return revert, true, nil
+}
+
+func SaveRevert(path string, revert RevertFile) error {
+	data, err := json.MarshalIndent(revert, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// Revert restores the recorded values and files, then removes the revert
+// file. It carries on past failures and returns them joined.
+func Revert(root, revertPath string) (RevertFile, error) {
+	revert, found, err := LoadRevert(revertPath)
+	if err != nil {
+		return revert, err
+	}
+	if !found {
+		return revert, fmt.Errorf("nothing to revert: %s does not exist", revertPath)
+	}
+	var errs []error
+	for _, value := range revert.Values {
+		change := domain.Change{Kind: value.Kind, Key: value.Key}
+		if value.Previous == "" {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(root, runtimePath(change)), []byte(value.Previous+"\n"), 0o644); err != nil {
+			errs = append(errs, fmt.Errorf("restore %s: %w", value.Key, err))
+		}
+	}
+	for _, file := range revert.Files {
+		full := filepath.Join(root, file.Path)
+		var err error
+		if file.Existed {
+			err = os.WriteFile(full, []byte(file.Previous), 0o644)
+		} else if err = os.Remove(full); os.IsNotExist(err) {
+			err = nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("restore %s: %w", file.Path, err))
+		}
+	}
+	if len(errs) > 0 {
+		return revert, errors.Join(errs...)
+	}
+	return revert, os.Remove(revertPath)
+}
+
+// RevertPathForEnv returns the revert file of the installation whose .env
+// is envPath.
+func RevertPathForEnv(envPath string) string {
+	return state.FileForEnv(envPath, DefaultRevertFileName)
+}
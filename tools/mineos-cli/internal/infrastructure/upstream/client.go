@@ -0,0 +1,202 @@
+// Package upstream looks up the hashes and builds Mojang, PaperMC and Modrinth
+// publish for server jars and mods.
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+const (
+	mojangManifestURL = "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json"
+	paperProjectURL   = "https://api.papermc.io/v2/projects/paper"
+	modrinthURL       = "https://api.modrinth.com/v2"
+)
+
+// userAgent identifies the CLI, which Modrinth's API requires.
+const userAgent = "freemancraft/mineos-cli"
+
+type Client struct {
+	httpClient *http.Client
+}
+
+var (
+	_ ports.UpstreamHashes = (*Client)(nil)
+	_ ports.PaperReleases  = (*Client)(nil)
+)
+
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (c *Client) MojangServerSha1(ctx context.Context, version string) (string, error) {
+	var manifest struct {
+		Versions []struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"versions"`
+	}
+	if err := c.do(ctx, http.MethodGet, mojangManifestURL, nil, &manifest); err != nil {
+		return "", err
+	}
+	for _, v := range manifest.Versions {
+		if v.ID != version {
+			continue
+		}
+		var detail struct {
+			Downloads struct {
+				Server struct {
+					Sha1 string `json:"sha1"`
+				} `json:"server"`
+			} `json:"downloads"`
+		}
+		if err := c.do(ctx, http.MethodGet, v.URL, nil, &detail); err != nil {
+			return "", err
+		}
+		if detail.Downloads.Server.Sha1 == "" {
+			return "", fmt.Errorf("Mojang publishes no server jar for %s", version)
+		}
+		return detail.Downloads.Server.Sha1, nil
+	}
+	return "", fmt.Errorf("Mojang does not know version %s", version)
+}
+
+func (c *Client) PaperBuilds(ctx context.Context, version string) (map[string]int, error) {
+	history, err := c.PaperBuildHistory(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	builds := make(map[string]int, len(history))
+	for _, build := range history {
+		if build.Sha256 != "" {
+			builds[build.Sha256] = build.Build
+		}
+	}
+	return builds, nil
+}
+
+func (c *Client) PaperBuildHistory(ctx context.Context, version string) ([]ports.PaperBuild, error) {
+	var result struct {
+		Builds []struct {
+			Build   int       `json:"build"`
+			Time    time.Time `json:"time"`
+			Channel string    `json:"channel"`
+			Changes []struct {
+				Summary string `json:"summary"`
+			} `json:"changes"`
+			Downloads struct {
+				Application struct {
+					Sha256 string `json:"sha256"`
+				} `json:"application"`
+			} `json:"downloads"`
+		} `json:"builds"`
+	}
+	if err := c.do(ctx, http.MethodGet, paperProjectURL+"/versions/"+url.PathEscape(version)+"/builds", nil, &result); err != nil {
+		return nil, err
+	}
+	builds := make([]ports.PaperBuild, 0, len(result.Builds))
+	for _, build := range result.Builds {
+		changes := make([]string, 0, len(build.Changes))
+		for _, change := range build.Changes {
+			changes = append(changes, change.Summary)
+		}
+		builds = append(builds, ports.PaperBuild{
+			Build:   build.Build,
+			Time:    build.Time,
+			Channel: build.Channel,
+			Sha256:  strings.ToLower(build.Downloads.Application.Sha256),
+			Changes: changes,
+		})
+	}
+	return builds, nil
+}
+
+func (c *Client) ModrinthFiles(ctx context.Context, sha512s []string) (map[string]ports.ModrinthFile, error) {
+	files := map[string]ports.ModrinthFile{}
+	if len(sha512s) == 0 {
+		return files, nil
+	}
+	var versions map[string]struct {
+		ProjectID     string `json:"project_id"`
+		VersionNumber string `json:"version_number"`
+		Files         []struct {
+			Hashes   map[string]string `json:"hashes"`
+			Filename string            `json:"filename"`
+		} `json:"files"`
+	}
+	body := map[string]any{"hashes": sha512s, "algorithm": "sha512"}
+	if err := c.do(ctx, http.MethodPost, modrinthURL+"/version_files", body, &versions); err != nil {
+		return nil, err
+	}
+
+	var projectIDs []string
+	for hash, version := range versions {
+		file := ports.ModrinthFile{ProjectID: version.ProjectID, Version: version.VersionNumber}
+		for _, f := range version.Files {
+			if strings.EqualFold(f.Hashes["sha512"], hash) {
+				file.FileName = f.Filename
+			}
+		}
+		files[strings.ToLower(hash)] = file
+		projectIDs = append(projectIDs, version.ProjectID)
+	}
+	if len(projectIDs) == 0 {
+		return files, nil
+	}
+
+	// Titles are only for display; the hashes matched either way.
+	ids, _ := json.Marshal(projectIDs)
+	var projects []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	}
+	if err := c.do(ctx, http.MethodGet, modrinthURL+"/projects?ids="+url.QueryEscape(string(ids)), nil, &projects); err == nil {
+		titles := make(map[string]string, len(projects))
+		for _, project := range projects {
+			titles[project.ID] = project.Title
+		}
+		for hash, file := range files {
+			file.Project = titles[file.ProjectID]
+			files[hash] = file
+		}
+	}
+	return files, nil
+}
+
+func (c *Client) do(ctx context.Context, method, target string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d: %s", target, resp.StatusCode, strings.TrimSpace(string(text)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,295 @@
+// Command openapigen writes Go types for the component schemas of an OpenAPI
+// document. It covers the subset the API's documents use: objects with
+// scalar, date-time, array, string map and referenced properties.
+//
+// Go names are the schema and property names in PascalCase, with a "Dto"
+// suffix dropped; x-go-name overrides them. Nullable properties become
+// pointers, except strings, where null reads as "", unless x-go-pointer is
+// set; x-go-type replaces the type outright. Properties that are not
+// required are omitted from JSON when empty.
+//
+//	//go:generate go run ../../openapigen -spec ../../../api/openapi.json -out types_gen.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type schema struct {
+	Type                 string            `json:"type"`
+	Format               string            `json:"format"`
+	Description          string            `json:"description"`
+	Ref                  string            `json:"$ref"`
+	AllOf                []*schema         `json:"allOf"`
+	Nullable             bool              `json:"nullable"`
+	Items                *schema           `json:"items"`
+	AdditionalProperties json.RawMessage   `json:"additionalProperties"`
+	Required             []string          `json:"required"`
+	Properties           orderedProperties `json:"properties"`
+	GoName               string            `json:"x-go-name"`
+	GoType               string            `json:"x-go-type"`
+	GoPointer            bool              `json:"x-go-pointer"`
+}
+
+type property struct {
+	name   string
+	schema *schema
+}
+
+// orderedProperties keeps the properties in document order, which becomes
+// the field order.
+type orderedProperties []property
+
+func (p *orderedProperties) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value schema
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		*p = append(*p, property{name: token.(string), schema: &value})
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func main() {
+	specPath := flag.String("spec", "", "OpenAPI document to read")
+	out := flag.String("out", "", "Go file to write")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package of the Go file")
+	flag.Parse()
+	if *specPath == "" || *out == "" || *pkg == "" {
+		fmt.Fprintln(os.Stderr, "usage: openapigen -spec openapi.json -out types_gen.go [-package name]")
+		os.Exit(2)
+	}
+	if err := run(*specPath, *out, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "openapigen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath, out, pkg string) error {
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		return err
+	}
+	var doc struct {
+		Components struct {
+			Schemas map[string]*schema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("read %s: %w", specPath, err)
+	}
+	g := &generator{schemas: doc.Components.Schemas, imports: map[string]bool{}}
+	source, err := g.file(pkg, filepath.ToSlash(specPath))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, source, 0o644)
+}
+
+type generator struct {
+	schemas map[string]*schema
+	imports map[string]bool
+}
+
+func (g *generator) file(pkg, specPath string) ([]byte, error) {
+	names := make(map[string]string, len(g.schemas))
+	var goNames []string
+	for name, s := range g.schemas {
+		goName := typeName(name, s)
+		if other, ok := names[goName]; ok {
+			return nil, fmt.Errorf("schemas %s and %s are both named %s", other, name, goName)
+		}
+		names[goName] = name
+		goNames = append(goNames, goName)
+	}
+	sort.Strings(goNames)
+
+	var body bytes.Buffer
+	for _, goName := range goNames {
+		name := names[goName]
+		if err := g.writeType(&body, goName, g.schemas[name]); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by openapigen from %s; DO NOT EDIT.\n\npackage %s\n\n", specPath, pkg)
+	if len(g.imports) > 0 {
+		var imports []string
+		for path := range g.imports {
+			imports = append(imports, path)
+		}
+		sort.Strings(imports)
+		src.WriteString("import (\n")
+		for _, path := range imports {
+			fmt.Fprintf(&src, "\t%q\n", path)
+		}
+		src.WriteString(")\n\n")
+	}
+	src.Write(body.Bytes())
+	return format.Source(src.Bytes())
+}
+
+func (g *generator) writeType(w *bytes.Buffer, goName string, s *schema) error {
+	if s.Type != "object" {
+		return fmt.Errorf("type %q is not an object", s.Type)
+	}
+	if s.Description != "" {
+		writeComment(w, goName, s.Description)
+	}
+	fmt.Fprintf(w, "type %s struct {\n", goName)
+	for _, prop := range s.Properties {
+		fieldType, err := g.goType(prop.schema)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", prop.name, err)
+		}
+		tag := prop.name
+		if !slices.Contains(s.Required, prop.name) {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(w, "\t%s %s `json:%q`\n", fieldName(prop.name, prop.schema), fieldType, tag)
+	}
+	w.WriteString("}\n\n")
+	return nil
+}
+
+func (g *generator) goType(s *schema) (string, error) {
+	if s.GoType != "" {
+		if pkg, _, ok := strings.Cut(s.GoType, "."); ok {
+			g.imports[importPath(pkg)] = true
+		}
+		return s.GoType, nil
+	}
+	base, err := g.baseType(s)
+	if err != nil {
+		return "", err
+	}
+	if !s.Nullable || strings.HasPrefix(base, "[]") || strings.HasPrefix(base, "map[") {
+		return base, nil
+	}
+	if base == "string" && !s.GoPointer {
+		return base, nil
+	}
+	return "*" + base, nil
+}
+
+func (g *generator) baseType(s *schema) (string, error) {
+	if len(s.AllOf) == 1 {
+		return g.baseType(s.AllOf[0])
+	}
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		if !ok {
+			return "", fmt.Errorf("unsupported reference %s", s.Ref)
+		}
+		target, ok := g.schemas[name]
+		if !ok {
+			return "", fmt.Errorf("unknown schema %s", name)
+		}
+		return typeName(name, target), nil
+	}
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			g.imports["time"] = true
+			return "time.Time", nil
+		}
+		return "string", nil
+	case "integer":
+		if s.Format == "int64" {
+			return "int64", nil
+		}
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("array without items")
+		}
+		item, err := g.goType(s.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object":
+		var values schema
+		if len(s.AdditionalProperties) == 0 || json.Unmarshal(s.AdditionalProperties, &values) != nil {
+			return "", fmt.Errorf("inline objects need additionalProperties")
+		}
+		value, err := g.goType(&values)
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + value, nil
+	}
+	return "", fmt.Errorf("unsupported type %q", s.Type)
+}
+
+func importPath(pkg string) string {
+	if pkg == "json" {
+		return "encoding/json"
+	}
+	return pkg
+}
+
+func typeName(name string, s *schema) string {
+	if s.GoName != "" {
+		return s.GoName
+	}
+	return pascal(strings.TrimSuffix(name, "Dto"))
+}
+
+func fieldName(name string, s *schema) string {
+	if s.GoName != "" {
+		return s.GoName
+	}
+	return pascal(name)
+}
+
+func pascal(name string) string {
+	runes := []rune(name)
+	if len(runes) == 0 {
+		return name
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// writeComment turns a description such as "A world directory of a
+// server." into "// World is a world directory of a server.".
+func writeComment(w *bytes.Buffer, goName, description string) {
+	runes := []rune(description)
+	runes[0] = unicode.ToLower(runes[0])
+	text := goName + " is " + string(runes)
+	line := "//"
+	for _, word := range strings.Fields(text) {
+		if len(line)+1+len(word) > 78 && line != "//" {
+			w.WriteString(line + "\n")
+			line = "//"
+		}
+		line += " " + word
+	}
+	w.WriteString(line + "\n")
+}
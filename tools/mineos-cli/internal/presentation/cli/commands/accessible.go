@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// AccessibleEnv turns on accessible output like --accessible, e.g. in the
+// shell profile of a screen reader user.
+const AccessibleEnv = "MINEOS_ACCESSIBLE"
+
+// accessibleMode is set for the whole run by the root command: output is
+// linear text that reads well aloud. Progress and tables print new lines
+// instead of redrawing, and symbols, box drawing and color-only states are
+// replaced by words.
+var accessibleMode bool
+
+// accessibleRequested reports whether the flag or MINEOS_ACCESSIBLE asks
+// for accessible output.
+func accessibleRequested(flag bool) bool {
+	if flag {
+		return true
+	}
+	enabled, err := strconv.ParseBool(os.Getenv(AccessibleEnv))
+	return err == nil && enabled
+}
+
+// redraws reports whether output to out may be redrawn in place: only on a
+// terminal, and not in machine or accessible mode.
+func redraws(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	if !ok || machineMode || accessibleMode {
+		return false
+	}
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// markOK and markFailed start a line reporting that something worked or
+// did not.
+func markOK() string {
+	if accessibleMode {
+		return styleSuccess.Render("OK:")
+	}
+	return styleSuccess.Render("✓")
+}
+
+func markFailed() string {
+	if accessibleMode {
+		return styleError.Render("FAILED:")
+	}
+	return styleError.Render("✗")
+}
+
+// bullet starts an item of a list.
+func bullet() string {
+	if accessibleMode {
+		return "-"
+	}
+	return "•"
+}
+
+// arrow joins an old value to its new one.
+func arrow() string {
+	if accessibleMode {
+		return "to"
+	}
+	return "→"
+}
+
+// describeSeries is the text a sparkline stands for: the range and the
+// latest value.
+func describeSeries(values []float64) string {
+	low, high, last := math.Inf(1), math.Inf(-1), math.NaN()
+	for _, value := range values {
+		if math.IsNaN(value) {
+			continue
+		}
+		low, high, last = min(low, value), max(high, value), value
+	}
+	if math.IsNaN(last) {
+		return "no data"
+	}
+	format := func(value float64) string {
+		return strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64)
+	}
+	return strings.Join([]string{"low " + format(low), "high " + format(high), "latest " + format(last)}, ", ")
+}
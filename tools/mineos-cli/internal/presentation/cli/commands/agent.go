@@ -0,0 +1,704 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	domainagent "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/agent"
+	domainalerts "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/alerts"
+	domainbackup "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/backup"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	domainguard "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/guard"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	domainschedule "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/schedule"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/agent"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/alerts"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/guard"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/notify"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/schedule"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+const (
+	agentTokenEnv       = "MINEOS_AGENT_TOKEN"
+	defaultAgentListen  = "127.0.0.1:5079"
+	defaultAuditLogName = "agent-audit.log"
+
+	persistServiceLogsEnv = "MINEOS_PERSIST_SERVICE_LOGS"
+	defaultServiceLogDir  = "logs"
+)
+
+func NewAgentCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var listen string
+	var token string
+	var allow []string
+	var auditPath string
+	var runTimeout time.Duration
+	var watchUpdates bool
+	var updateInterval time.Duration
+	var scheduled bool
+	var schedulePath string
+	var recordMetrics bool
+	var metricsInterval time.Duration
+	var metricsRetention time.Duration
+	var alerting bool
+	var alertsPath string
+	var alertsInterval time.Duration
+	var persistLogs bool
+	var serviceLogDir string
+	var serviceLogMaxMB int
+	var serviceLogKeep int
+	var guarding bool
+	var guardPath string
+
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Run a local HTTP endpoint that triggers whitelisted CLI operations",
+		Long: `Run the MineOS agent: a small authenticated HTTP endpoint that lets CI
+jobs, Discord bots or panels trigger whitelisted operations on this host.
+
+Requests must carry "Authorization: Bearer <token>" or an HMAC-SHA256
+signature keyed with the token of "<method>\n<path>\n<timestamp>\n<body>",
+sent as X-Mineos-Signature: sha256=<hex> with the Unix timestamp in
+X-Mineos-Timestamp; signatures more than 5 minutes off the agent's clock
+are refused. The token comes from --token, ` + agentTokenEnv + ` in the
+environment, or ` + agentTokenEnv + ` in .env.
+
+  GET  /v1/operations              list allowed operations
+  POST /v1/operations/<name>       run one, e.g. {"server": "lobby"} (?wait=true to block)
+  GET  /v1/runs, /v1/runs/<id>     run status and output
+
+Every accepted and rejected request is appended to the audit log.
+
+With --watch-updates the agent also watches the data directory for the
+update request the web UI writes (` + agent.UpdateRequestFile + `), runs the graceful
+stop, pull and up flow, and reports progress in ` + agent.UpdateStatusFile + `. Without a
+token only the update watcher runs.
+
+With --schedule the agent runs the restart policies of the installation's
+schedule, written with 'mineos state edit schedule'. A due restart waits while players are online, broadcasting
+warnings, and is forced once max_delay runs out:
+
+  restarts:
+    - server: survival
+      cron: "0 4 * * *"
+      max_delay: 2h
+      recheck: 5m
+      warnings: [10m, 5m, 1m]
+      message: nightly restart
+
+The same schedule can list announcements, broadcast to the matching servers
+that have players online every interval (aligned to the clock) or on a
+cron, taking the messages in turn (or at random with random: true).
+Messages use MiniMessage or '&' codes and are sent with tellraw; mode: say
+sends plain text instead:
+
+  announcements:
+    - name: tips
+      servers: ["survival*"]
+      every: 15m
+      messages:
+        - "<gold>Vote for us</gold> at <aqua>example.com/vote</aqua>"
+        - "&aJoin our Discord: &fexample.com/discord"
+
+With --record-metrics the agent samples CPU, memory, players and TPS of every
+server each --metrics-interval into the installation's state store, which
+'mineos servers stats --history 24h' graphs and exports.
+
+With --alerts the agent evaluates the installation's alert rules, written
+with 'mineos state edit alerts' and re-read on every check, and notifies
+Discord (Discord__WebhookUrl unless the rules name another) and any webhooks listed, with a resolution notice when
+the condition clears:
+
+  notify:
+    webhooks: [https://example.com/hook]
+  rules:
+    - name: low-tps
+      when: tps < 15
+      for: 5m
+    - when: memory > 90%
+      for: 10m
+      cooldown: 1h
+    - when: disk < 5GB
+    - when: down
+      servers: [survival, "lobby-*"]
+      for: 2m
+
+With --persist-logs (or ` + persistServiceLogsEnv + `=true in .env) the agent follows the
+compose logs of every service into logs/<service>.log next to .env, rotated
+at --service-logs-max-mb, so they can still be read after a container is
+recreated.
+
+With --guard the agent follows the console of every server for failed
+logins, join floods, malformed packets and log4j lookups in chat, and scores
+them per address. An address that reaches the threshold within the window is
+blocked on the host firewall (nftables, or ufw) for the ban duration; bans are
+kept in ` + guard.DefaultBansFileName + ` (in ` + state.InstallationDirName + `/) and lifted when they end. Settings come from
+` + guard.DefaultFileName + ` (next to .env, re-read every minute); all are optional:
+
+  threshold: 10      # score that earns a ban
+  window: 5m
+  ban: 1h
+  firewall: auto     # nftables, ufw, or none to only report
+  whitelist: [203.0.113.7, 10.0.0.0/8]
+  servers: ["survival*"]
+  weights:           # failed-login 2, not-whitelisted 2, banned 2,
+    join: 0          # join 1, bad-packet 5, jndi bans at once; 0 disables
+
+Blocking needs root (or CAP_NET_ADMIN). List bans with 'mineos agent bans'
+and lift one early with 'mineos agent unban <ip>'.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig.Execute(context.Background())
+			if err != nil {
+				return err
+			}
+			token = resolveAgentToken(cfg, token)
+			persistLogs = persistLogs || parseEnvBool(cfg.PersistServiceLogs)
+			if token == "" && !watchUpdates && !scheduled && !recordMetrics && !alerting && !persistLogs && !guarding {
+				return fmt.Errorf("no agent token configured; set %s in .env (e.g. from 'openssl rand -hex 32') or pass --token", agentTokenEnv)
+			}
+
+			exe, err := os.Executable()
+			if err != nil {
+				return err
+			}
+			envPath, err := filepath.Abs(cfg.EnvPath)
+			if err != nil {
+				return err
+			}
+			if auditPath == "" {
+				auditPath = filepath.Join(filepath.Dir(envPath), defaultAuditLogName)
+			}
+			audit, err := agent.OpenAuditLog(auditPath)
+			if err != nil {
+				return fmt.Errorf("failed to open audit log: %w", err)
+			}
+			defer audit.Close()
+
+			var server *agent.Server
+			if token != "" {
+				server, err = agent.NewServer(agent.Options{
+					Token:      token,
+					Allowed:    allow,
+					Executable: exe,
+					BaseArgs:   []string{"--env", envPath},
+					RunTimeout: runTimeout,
+					Audit:      audit,
+				})
+				if err != nil {
+					return err
+				}
+				if host, _, err := net.SplitHostPort(listen); err == nil {
+					if ip := net.ParseIP(host); host == "" || (ip != nil && !ip.IsLoopback()) {
+						cmd.Printf("%s listening on a non-loopback address; put the agent behind TLS before exposing it.\n", styleWarning.Render("Warning:"))
+					}
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			// Started at boot, the agent can come up before Docker. Its
+			// loops retry on their own, so a daemon that is still down is
+			// only a warning.
+			if compose, err := detectCompose(); err == nil {
+				if err := waitForDocker(ctx, composeWithConfig(compose, cfg), cmd.ErrOrStderr(), dockerWaitTimeout(cfg)); err != nil && ctx.Err() == nil {
+					cmd.PrintErrln(styleWarning.Render("Warning: ") + err.Error())
+				}
+			}
+
+			started := agent.AuditEvent{Event: "agent-started"}
+			if server != nil {
+				started.Remote = listen
+			}
+			audit.Record(started)
+			cmd.Printf("Audit log: %s\n", auditPath)
+
+			var watcherDone chan struct{}
+			if watchUpdates {
+				watcher := &agent.UpdateWatcher{
+					Dir:      agentDataDir(cfg, envPath),
+					Interval: updateInterval,
+					Audit:    audit,
+					Update: func(ctx context.Context, step func(name, message string)) error {
+						return runStackUpdate(ctx, loadConfig, 0, "", cmd.OutOrStdout(), step)
+					},
+					OnEvent: func(message string) {
+						cmd.Printf("%s %s\n", styleInfo.Render("[update]"), message)
+					},
+				}
+				if server != nil {
+					watcher.Exclusive = server.RunExclusive
+				}
+				cmd.Printf("Watching %s for update requests\n", filepath.Join(watcher.Dir, agent.UpdateRequestFile))
+				watcherDone = make(chan struct{})
+				go func() {
+					defer close(watcherDone)
+					watcher.Watch(ctx)
+				}()
+			}
+
+			var schedulerDone chan struct{}
+			if scheduled {
+				var repo scheduleRepository = schedule.NewStateRepository(state.PathForEnv(envPath), envPath)
+				if schedulePath != "" {
+					repo = schedule.NewFileRepository(schedulePath)
+				}
+				if _, found, err := repo.Load(); err != nil {
+					return fmt.Errorf("invalid schedule in %s: %w", repo.Path(), err)
+				} else if !found {
+					cmd.Printf("%s no schedule in %s yet; it is re-read every minute.\n", styleWarning.Render("Note:"), repo.Path())
+				}
+				scheduler := &agent.Scheduler{
+					Load: func() (domainschedule.Definition, error) {
+						def, _, err := repo.Load()
+						return def, err
+					},
+					Restart: func(ctx context.Context, policy domainschedule.RestartPolicy, progress func(string)) (bool, error) {
+						return runScheduledRestart(ctx, loadConfig, cmd, policy, progress)
+					},
+					Announce: func(ctx context.Context, announcement domainschedule.Announcement, message string) ([]string, error) {
+						var sent []string
+						_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+							var err error
+							sent, err = usecases.NewAnnounceUseCase(client).Execute(ctx, announcement, message)
+							return err
+						})
+						return sent, err
+					},
+					Preset: func(ctx context.Context, policy domainschedule.PresetPolicy) ([]string, error) {
+						p, err := findPreset(ctx, loadConfig, policy.Preset)
+						if err != nil {
+							return nil, err
+						}
+						var sent []string
+						err = runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+							sent, err = usecases.NewApplyPresetUseCase(client).Execute(ctx, policy.Server, p, nil)
+							return err
+						})
+						return sent, err
+					},
+					Verify: func(ctx context.Context, policy domainschedule.VerifyPolicy) ([]domainbackup.Report, error) {
+						return verifyLatestBackups(ctx, loadConfig, policy)
+					},
+					Alert: func(ctx context.Context, title, message string) error {
+						def, _, err := alertRules(envPath, alertsPath).Load()
+						if err != nil {
+							return err
+						}
+						dispatcher, err := alertDispatcher(cfg, def)
+						if err != nil {
+							return err
+						}
+						return dispatcher.Send(ctx, notify.Notification{
+							Title:    title,
+							Message:  message,
+							Severity: notify.SeverityCritical,
+							Time:     time.Now().UTC(),
+						})
+					},
+					Audit: audit,
+					OnEvent: func(message string) {
+						cmd.Printf("%s %s\n", styleInfo.Render("[schedule]"), message)
+					},
+				}
+				cmd.Printf("Running schedule from %s\n", repo.Path())
+				schedulerDone = make(chan struct{})
+				go func() {
+					defer close(schedulerDone)
+					scheduler.Run(ctx)
+				}()
+			}
+
+			var recorderDone chan struct{}
+			if recordMetrics {
+				store, err := state.Open(state.PathForEnv(envPath))
+				if err != nil {
+					return fmt.Errorf("failed to open the state store: %w", err)
+				}
+				defer store.Close()
+				history, err := store.Metrics(ctx, envPath)
+				if err != nil {
+					return err
+				}
+				recorder := &agent.MetricsRecorder{
+					Store:     history,
+					Interval:  metricsInterval,
+					Retention: metricsRetention,
+					Collect: func(ctx context.Context) ([]ports.PerformanceSample, error) {
+						var samples []ports.PerformanceSample
+						_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+							var err error
+							samples, err = usecases.NewServerStatsUseCase(client).SampleAll(ctx)
+							return err
+						})
+						return samples, err
+					},
+					OnEvent: func(message string) {
+						cmd.Printf("%s %s\n", styleInfo.Render("[metrics]"), message)
+					},
+				}
+				cmd.Printf("Recording metrics every %s to %s\n", metricsInterval, store.Path())
+				recorderDone = make(chan struct{})
+				go func() {
+					defer close(recorderDone)
+					recorder.Run(ctx)
+				}()
+			}
+
+			var alertsDone chan struct{}
+			if alerting {
+				repo := alertRules(envPath, alertsPath)
+				if _, found, err := repo.Load(); err != nil {
+					return fmt.Errorf("invalid alert rules in %s: %w", repo.Path(), err)
+				} else if !found {
+					cmd.Printf("%s no alert rules in %s yet; they are re-read on every check.\n", styleWarning.Render("Note:"), repo.Path())
+				}
+				monitor := &agent.AlertMonitor{
+					Load: func() (domainalerts.Definition, error) {
+						def, _, err := repo.Load()
+						return def, err
+					},
+					Observe: func(ctx context.Context) (domainalerts.Snapshot, error) {
+						return observeAlerts(ctx, loadConfig, cmd)
+					},
+					Notify: func(ctx context.Context, def domainalerts.Definition, event domainalerts.Event) error {
+						return sendAlert(ctx, cfg, def, event)
+					},
+					Interval: alertsInterval,
+					Audit:    audit,
+					OnEvent: func(message string) {
+						cmd.Printf("%s %s\n", styleInfo.Render("[alerts]"), message)
+					},
+				}
+				cmd.Printf("Evaluating alert rules from %s every %s\n", repo.Path(), alertsInterval)
+				alertsDone = make(chan struct{})
+				go func() {
+					defer close(alertsDone)
+					monitor.Run(ctx)
+				}()
+			}
+
+			var captureDone chan struct{}
+			if persistLogs {
+				compose, err := detectCompose()
+				if err != nil {
+					return err
+				}
+				compose = composeWithConfig(compose, cfg)
+				if serviceLogDir == "" {
+					serviceLogDir = filepath.Join(filepath.Dir(envPath), defaultServiceLogDir)
+				}
+				capture := &agent.ServiceLogCapture{
+					Dir:      serviceLogDir,
+					MaxBytes: int64(serviceLogMaxMB) << 20,
+					Keep:     serviceLogKeep,
+					Services: func(ctx context.Context) ([]string, error) {
+						output, err := compose.output([]string{"config", "--services"})
+						if err != nil {
+							return nil, err
+						}
+						return strings.Fields(string(output)), nil
+					},
+					Follow: func(ctx context.Context, service string, since time.Time, onLine func(string)) error {
+						args := []string{"logs", "--follow", "--timestamps", "--no-log-prefix"}
+						if !since.IsZero() {
+							args = append(args, "--since", since.Format(time.RFC3339Nano))
+						}
+						return compose.lines(ctx, append(args, service), onLine)
+					},
+					OnEvent: func(message string) {
+						cmd.Printf("%s %s\n", styleInfo.Render("[logs]"), message)
+					},
+				}
+				cmd.Printf("Capturing service logs to %s\n", serviceLogDir)
+				captureDone = make(chan struct{})
+				go func() {
+					defer close(captureDone)
+					capture.Run(ctx)
+				}()
+			}
+
+			var guardDone chan struct{}
+			if guarding {
+				repo := guard.NewFileRepositoryForEnv(envPath)
+				if guardPath != "" {
+					repo = guard.NewFileRepository(guardPath)
+				}
+				def, found, err := repo.Load()
+				if err != nil {
+					return fmt.Errorf("invalid guard settings %s: %w", repo.Path(), err)
+				} else if !found {
+					cmd.Printf("%s %s does not exist yet; using the defaults.\n", styleWarning.Render("Note:"), repo.Path())
+				}
+				firewall, err := guard.NewFirewall(def.WithDefaults().Firewall)
+				if err != nil {
+					return err
+				}
+				if err := firewall.Setup(ctx); err != nil {
+					return fmt.Errorf("failed to set up %s: %w", firewall.Name(), err)
+				}
+				abuse := &agent.AbuseGuard{
+					Load: func() (domainguard.Definition, error) {
+						def, _, err := repo.Load()
+						return def, err
+					},
+					Servers: func(ctx context.Context) ([]string, error) {
+						var names []string
+						_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+							servers, err := client.ListServers(ctx)
+							for _, server := range servers {
+								names = append(names, server.Name)
+							}
+							return err
+						})
+						return names, err
+					},
+					Follow: func(ctx context.Context, server string, onLine func(time.Time, string)) error {
+						return followConsole(ctx, loadConfig, server, onLine)
+					},
+					Firewall: firewall,
+					Bans:     guard.NewBanStoreForEnv(envPath),
+					Audit:    audit,
+					OnEvent: func(message string) {
+						cmd.Printf("%s %s\n", styleInfo.Render("[guard]"), message)
+					},
+				}
+				cmd.Printf("Guarding server consoles with %s (settings: %s)\n", firewall.Name(), repo.Path())
+				guardDone = make(chan struct{})
+				go func() {
+					defer close(guardDone)
+					abuse.Run(ctx)
+				}()
+			}
+
+			if server != nil {
+				cmd.Printf("MineOS agent listening on http://%s\n", listen)
+			} else {
+				cmd.Println(styleDim.Render("No agent token configured; the HTTP endpoint is disabled."))
+			}
+			cmd.Println(styleDim.Render("Press Ctrl+C to stop."))
+
+			if server != nil {
+				if err := server.Serve(ctx, listen); err != nil {
+					return err
+				}
+			} else {
+				<-ctx.Done()
+			}
+			if watcherDone != nil {
+				<-watcherDone
+			}
+			if schedulerDone != nil {
+				<-schedulerDone
+			}
+			if recorderDone != nil {
+				<-recorderDone
+			}
+			if alertsDone != nil {
+				<-alertsDone
+			}
+			if captureDone != nil {
+				<-captureDone
+			}
+			if guardDone != nil {
+				<-guardDone
+			}
+			audit.Record(agent.AuditEvent{Event: "agent-stopped"})
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", defaultAgentListen, "Address to listen on")
+	cmd.Flags().StringVar(&token, "token", "", "Shared secret for bearer auth and webhook signatures (default: "+agentTokenEnv+")")
+	cmd.Flags().StringSliceVar(&allow, "allow", nil, "Operations callers may trigger (default: all but opt-in ones; see 'mineos agent operations')")
+	cmd.Flags().StringVar(&auditPath, "audit-log", "", "Audit log path (default: "+defaultAuditLogName+" next to .env)")
+	cmd.Flags().DurationVar(&runTimeout, "run-timeout", 30*time.Minute, "Maximum duration of a single operation")
+	cmd.Flags().BoolVar(&watchUpdates, "watch-updates", false, "Run updates requested from the web UI")
+	cmd.Flags().DurationVar(&updateInterval, "update-interval", 5*time.Second, "How often to check for update requests")
+	cmd.Flags().BoolVar(&scheduled, "schedule", false, "Run the restarts and announcements of the schedule ('mineos state edit schedule')")
+	cmd.Flags().StringVar(&schedulePath, "schedule-file", "", "Schedule file to use instead of the state store")
+
+	cmd.Flags().BoolVar(&recordMetrics, "record-metrics", false, "Record server metrics for 'servers stats --history'")
+	cmd.Flags().DurationVar(&metricsInterval, "metrics-interval", time.Minute, "How often to sample server metrics")
+	cmd.Flags().DurationVar(&metricsRetention, "metrics-retention", 30*24*time.Hour, "How long to keep recorded metrics (0 keeps everything)")
+
+	cmd.Flags().BoolVar(&alerting, "alerts", false, "Evaluate the alert rules ('mineos state edit alerts')")
+	cmd.Flags().StringVar(&alertsPath, "alerts-file", "", "Alert rules file to use instead of the state store")
+	cmd.Flags().DurationVar(&alertsInterval, "alerts-interval", 30*time.Second, "How often to evaluate the alert rules")
+	cmd.Flags().BoolVar(&persistLogs, "persist-logs", false, "Capture the compose logs of every service into rotated files (or set "+persistServiceLogsEnv+"=true)")
+	cmd.Flags().StringVar(&serviceLogDir, "service-logs-dir", "", "Directory for captured service logs (default: "+defaultServiceLogDir+" next to .env)")
+	cmd.Flags().IntVar(&serviceLogMaxMB, "service-logs-max-mb", 10, "Size in MB at which a service log is rotated")
+	cmd.Flags().IntVar(&serviceLogKeep, "service-logs-keep", 5, "Rotated files kept per service")
+	cmd.Flags().BoolVar(&guarding, "guard", false, "Ban addresses that abuse the server consoles' login and chat (settings in "+guard.DefaultFileName+")")
+	cmd.Flags().StringVar(&guardPath, "guard-file", "", "Guard settings path (default: "+guard.DefaultFileName+" next to .env)")
+	registerDockerWaitFlag(cmd)
+
+	cmd.AddCommand(newAgentOperationsCommand())
+	cmd.AddCommand(newAgentBansCommand(loadConfig))
+	cmd.AddCommand(newAgentUnbanCommand(loadConfig))
+
+	return cmd
+}
+
+func newAgentOperationsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "operations",
+		Short: "List operations the agent can run",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "OPERATION\tPARAMS\tDESCRIPTION")
+			for _, op := range domainagent.Operations() {
+				description := op.Description
+				if op.OptIn {
+					description += " (opt-in: name it with --allow)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", op.Name, fallback(strings.Join(op.Params, ", "), "-"), description)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// runScheduledRestart applies a restart policy, filling unset durations
+// with the 'servers restart --when-empty' defaults.
+func runScheduledRestart(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, cmd *cobra.Command, policy domainschedule.RestartPolicy, progress func(string)) (bool, error) {
+	opts := usecases.DefaultDeferredRestartOptions()
+	if policy.MaxDelay > 0 {
+		opts.MaxDelay = policy.MaxDelay
+	}
+	if policy.Recheck > 0 {
+		opts.Recheck = policy.Recheck
+	}
+	if policy.Warnings != nil {
+		opts.Warnings = policy.Warnings
+	}
+	opts.Message = policy.Message
+
+	var restarted bool
+	_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+		var err error
+		restarted, err = usecases.NewDeferredRestartUseCase(client).Execute(ctx, policy.Server, opts, progress)
+		return err
+	})
+	return restarted, err
+}
+
+// observeAlerts samples every server and the free space of the server data
+// directory for the alert rules.
+// scheduleRepository and alertRulesRepository read from the state store, or
+// from the --schedule-file or --alerts-file given instead.
+type scheduleRepository interface {
+	Load() (domainschedule.Definition, bool, error)
+	Path() string
+}
+
+type alertRulesRepository interface {
+	Load() (domainalerts.Definition, bool, error)
+	Path() string
+}
+
+func alertRules(envPath, file string) alertRulesRepository {
+	if file != "" {
+		return alerts.NewFileRepository(file)
+	}
+	return alerts.NewStateRepository(state.PathForEnv(envPath), envPath)
+}
+
+func observeAlerts(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, cmd *cobra.Command) (domainalerts.Snapshot, error) {
+	snapshot := domainalerts.Snapshot{Time: time.Now()}
+	_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+		var err error
+		snapshot.Samples, err = usecases.NewServerStatsUseCase(client).SampleAll(ctx)
+		return err
+	})
+	if err != nil {
+		return snapshot, err
+	}
+	if storage, err := loadHostStorage(ctx, loadConfig); err == nil {
+		if free, err := diskFree(storage.Base); err == nil {
+			snapshot.DiskFree = &free
+			snapshot.DiskPath = storage.Base
+		}
+	}
+	return snapshot, nil
+}
+
+// alertDispatcher sends to the targets of the alerts file, or the Discord
+// webhook in .env when it names none.
+func alertDispatcher(cfg config.Config, def domainalerts.Definition) (*notify.Dispatcher, error) {
+	discord := def.Notify.Discord
+	if discord == "" {
+		if values, err := loadLayeredEnvValues(cfg); err == nil {
+			discord = values["Discord__WebhookUrl"]
+		}
+	}
+	dispatcher := notify.NewDispatcher(discord, def.Notify.Webhooks)
+	if !dispatcher.Configured() {
+		return nil, errors.New("no notification target; set Discord__WebhookUrl in .env or notify in the alerts file")
+	}
+	return dispatcher, nil
+}
+
+func sendAlert(ctx context.Context, cfg config.Config, def domainalerts.Definition, event domainalerts.Event) error {
+	dispatcher, err := alertDispatcher(cfg, def)
+	if err != nil {
+		return err
+	}
+
+	severity := notify.SeverityWarning
+	switch {
+	case event.Kind == domainalerts.Resolved:
+		severity = notify.SeverityResolved
+	case event.Condition.Metric == domainalerts.MetricDown:
+		severity = notify.SeverityCritical
+	}
+	fields := map[string]string{"Rule": event.Rule.Label(), "Subject": event.Subject}
+	if event.Value != "" {
+		fields["Value"] = event.Value
+	}
+	return dispatcher.Send(ctx, notify.Notification{
+		Title:    event.Title(),
+		Message:  event.Text(),
+		Severity: severity,
+		Time:     event.Time.UTC(),
+		Fields:   fields,
+	})
+}
+
+// agentDataDir returns the host path of the API data volume, where the web
+// UI leaves update requests.
+func agentDataDir(cfg config.Config, envPath string) string {
+	dir := fallback(strings.TrimSpace(cfg.DataDirectory), "./data")
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(envPath), dir)
+	}
+	return filepath.Clean(dir)
+}
+
+func resolveAgentToken(cfg config.Config, flagValue string) string {
+	if token := strings.TrimSpace(flagValue); token != "" {
+		return token
+	}
+	if token := strings.TrimSpace(os.Getenv(agentTokenEnv)); token != "" {
+		return token
+	}
+	values, err := loadLayeredEnvValues(cfg)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(values[agentTokenEnv])
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/agent"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/guard"
+)
+
+// followConsole passes the server log lines of a server to onLine until the
+// stream ends.
+func followConsole(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, server string, onLine func(time.Time, string)) error {
+	cfg, err := loadConfig.Execute(ctx)
+	if err != nil {
+		return err
+	}
+	return consoleFollower(api.NewClientFromConfig(cfg))(ctx, server, onLine)
+}
+
+func newAgentBansCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "bans",
+		Short: "List the addresses 'mineos agent --guard' has banned",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig.Execute(context.Background())
+			if err != nil {
+				return err
+			}
+			store := guard.NewBanStoreForEnv(resolveEnvPath(cfg.EnvPath))
+			bans, err := store.Load()
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", store.Path(), err)
+			}
+
+			if jsonOut {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(bans)
+			}
+			if len(bans) == 0 {
+				cmd.Println("No active bans.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ADDRESS\tSERVER\tRULES\tSCORE\tUNTIL\tFIREWALL")
+			for _, ban := range bans {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", ban.IP, ban.Server, strings.Join(ban.Rules, ", "), ban.Score, ban.Until.Local().Format("2006-01-02 15:04"), ban.Firewall)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+func newAgentUnbanCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unban <ip>",
+		Short: "Lift a guard ban before it ends",
+		Long: `Remove the firewall block on an address the guard banned. The running
+agent does not ban it again before the original ban would have ended; add it
+to the whitelist to exempt it for good.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			cfg, err := loadConfig.Execute(ctx)
+			if err != nil {
+				return err
+			}
+			envPath := resolveEnvPath(cfg.EnvPath)
+			store := guard.NewBanStoreForEnv(envPath)
+			ban, found, err := store.Remove(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to update %s: %w", store.Path(), err)
+			}
+			if !found {
+				return fmt.Errorf("%s is not banned", args[0])
+			}
+
+			firewall, err := guard.NewFirewall(ban.Firewall)
+			if err == nil {
+				err = firewall.Unblock(ctx, ban.IP)
+			}
+			event := agent.AuditEvent{Event: "guard-unban", Params: map[string]string{"ip": ban.IP}, Status: "lifted"}
+			if err != nil {
+				event.Status = "failed"
+				event.Error = err.Error()
+			}
+			if audit, aerr := agent.OpenAuditLog(filepath.Join(filepath.Dir(envPath), defaultAuditLogName)); aerr == nil {
+				audit.Record(event)
+				audit.Close()
+			}
+			if err != nil {
+				_ = store.Add(ban)
+				return err
+			}
+			cmd.Printf("%s Lifted the ban on %s\n", markOK(), ban.IP)
+			return nil
+		},
+	}
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		cfg, err := loadConfig.Execute(cmd.Context())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		bans, err := guard.NewBanStoreForEnv(resolveEnvPath(cfg.EnvPath)).Load()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		ips := make([]string, 0, len(bans))
+		for _, ban := range bans {
+			ips = append(ips, ban.IP+"\t"+ban.Server)
+		}
+		return ips, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
@@ -0,0 +1,509 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+// apiMaxPages bounds --paginate, in case an endpoint never runs out.
+const apiMaxPages = 100
+
+var apiMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodHead:   true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+func NewApiCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var fields []string
+	var typedFields []string
+	var headers []string
+	var input string
+	var include bool
+	var raw bool
+	var paginate bool
+
+	cmd := &cobra.Command{
+		Use:   "api [method] <path>",
+		Short: "Send a request to any MineOS API endpoint",
+		Long: `Send a request to an endpoint of the MineOS API, for what the CLI does not
+wrap yet. The API URL and key come from .env like every other command, so
+no key ends up in shell history.
+
+Paths are relative to /api/v1 (/servers/list is /api/v1/servers/list);
+paths starting with /api/ are used as they are. The method defaults to GET,
+or POST when fields or --input are given.
+
+-f sets a string field and -F a typed one: true, false, null and numbers
+are sent as such, and @file sends the file's contents. Fields go in the
+query string of GET, HEAD and DELETE requests and in a JSON body otherwise.
+
+JSON responses are pretty-printed unless --raw is given. With --paginate,
+endpoints that page with index and pageSize are followed to the last page
+and their results printed as one.
+
+An error status prints the response body and exits with the code for it:
+4 for a refused key, 5 for not found, 6 for a conflict and 1 otherwise.
+
+'mineos api coverage' lists the endpoints no command wraps yet.`,
+		Example: `  mineos api /servers/list
+  mineos api GET /servers/survival
+  mineos api POST /servers/survival/actions/restart
+  mineos api GET /servers/survival/mods/modrinth/search -f query=sodium --paginate
+  mineos api PATCH /servers/survival -F memory=4096
+  mineos api PUT /settings --input settings.json`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+
+			req, err := buildApiRequest(args, fields, typedFields, headers, input, cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			if paginate && req.Method != http.MethodGet {
+				return fmt.Errorf("--paginate only applies to GET requests")
+			}
+
+			var resp api.RawResponse
+			_, err = withApiKeyRetry(ctx, loadConfig, cmd.ErrOrStderr(), func(_ config.Config, client *api.Client) error {
+				if paginate {
+					resp, err = fetchAllPages(ctx, client, req)
+				} else {
+					resp, err = client.Raw(ctx, req)
+				}
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			if include {
+				fmt.Fprintln(out, resp.Status)
+				keys := make([]string, 0, len(resp.Header))
+				for key := range resp.Header {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					fmt.Fprintf(out, "%s: %s\n", key, strings.Join(resp.Header[key], ", "))
+				}
+				fmt.Fprintln(out)
+			}
+			writeApiBody(out, resp.Body, raw)
+
+			if resp.Code < 200 || resp.Code >= 300 {
+				failure := fmt.Errorf("%s %s: %s", req.Method, req.Path, resp.Status)
+				switch resp.Code {
+				case http.StatusNotFound:
+					return ports.WithKind(failure, ports.ErrNotFound)
+				case http.StatusConflict:
+					return ports.WithKind(failure, ports.ErrConflict)
+				}
+				return failure
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&fields, "field", "f", nil, "Add a string field key=value (repeatable)")
+	cmd.Flags().StringArrayVarP(&typedFields, "typed-field", "F", nil, "Add a typed field key=value: true, false, null, numbers and @file are converted (repeatable)")
+	cmd.Flags().StringArrayVarP(&headers, "header", "H", nil, "Add a request header 'Name: value' (repeatable)")
+	cmd.Flags().StringVar(&input, "input", "", "Send this file as the request body ('-' for stdin)")
+	cmd.Flags().BoolVarP(&include, "include", "i", false, "Print the response status and headers before the body")
+	cmd.Flags().BoolVar(&raw, "raw", false, "Print the response body as received, without pretty-printing")
+	cmd.Flags().BoolVar(&paginate, "paginate", false, "Follow index/pageSize pages and print all results together")
+
+	cmd.AddCommand(newApiCoverageCommand(loadConfig))
+
+	return cmd
+}
+
+// buildApiRequest reads the arguments and flags of 'mineos api'.
+func buildApiRequest(args, fields, typedFields, headers []string, input string, stdin io.Reader) (api.RawRequest, error) {
+	req := api.RawRequest{Path: args[len(args)-1], Header: http.Header{}}
+	if len(args) == 2 {
+		req.Method = strings.ToUpper(args[0])
+		if !apiMethods[req.Method] {
+			return req, fmt.Errorf("unknown method %q; use GET, HEAD, POST, PUT, PATCH or DELETE", args[0])
+		}
+	}
+
+	values := map[string]any{}
+	var order []string
+	add := func(flag, entry string, typed bool) error {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("%s: expected key=value, got %q", flag, entry)
+		}
+		if _, seen := values[key]; !seen {
+			order = append(order, key)
+		}
+		if !typed {
+			values[key] = value
+			return nil
+		}
+		parsed, err := typedApiValue(value)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", flag, key, err)
+		}
+		values[key] = parsed
+		return nil
+	}
+	for _, entry := range fields {
+		if err := add("--field", entry, false); err != nil {
+			return req, err
+		}
+	}
+	for _, entry := range typedFields {
+		if err := add("--typed-field", entry, true); err != nil {
+			return req, err
+		}
+	}
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return req, fmt.Errorf("--header: expected 'Name: value', got %q", header)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "X-Api-Key") {
+			return req, fmt.Errorf("--header: the API key comes from .env and cannot be set")
+		}
+		req.Header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	if input != "" {
+		var data []byte
+		var err error
+		if input == "-" {
+			data, err = io.ReadAll(stdin)
+		} else {
+			data, err = os.ReadFile(input)
+		}
+		if err != nil {
+			return req, fmt.Errorf("--input: %w", err)
+		}
+		req.Body = data
+	}
+	if req.Method == "" {
+		req.Method = http.MethodGet
+		if len(values) > 0 || req.Body != nil {
+			req.Method = http.MethodPost
+		}
+	}
+
+	if len(values) == 0 {
+		return req, nil
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		req.Query = url.Values{}
+		for _, key := range order {
+			if values[key] == nil {
+				req.Query.Set(key, "")
+			} else {
+				req.Query.Set(key, fmt.Sprint(values[key]))
+			}
+		}
+	default:
+		if req.Body != nil {
+			return req, fmt.Errorf("--input cannot be combined with fields on a %s request", req.Method)
+		}
+		body, err := json.Marshal(values)
+		if err != nil {
+			return req, err
+		}
+		req.Body = body
+	}
+	return req, nil
+}
+
+// typedApiValue converts a -F value the way the API expects it.
+func typedApiValue(value string) (any, error) {
+	switch value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return number, nil
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number, nil
+	}
+	return value, nil
+}
+
+// fetchAllPages follows an endpoint that pages with index and pageSize, as
+// the mod searches do, and returns the first page with every page's
+// results in it.
+func fetchAllPages(ctx context.Context, client *api.Client, req api.RawRequest) (api.RawResponse, error) {
+	first, err := client.Raw(ctx, req)
+	if err != nil || first.Code < 200 || first.Code >= 300 {
+		return first, err
+	}
+	var page map[string]json.RawMessage
+	if json.Unmarshal(first.Body, &page) != nil {
+		return first, nil
+	}
+	var results []json.RawMessage
+	if json.Unmarshal(page["results"], &results) != nil {
+		return first, nil
+	}
+	var index, pageSize int
+	if json.Unmarshal(page["index"], &index) != nil || json.Unmarshal(page["pageSize"], &pageSize) != nil {
+		return first, nil
+	}
+	total := -1
+	for _, key := range []string{"totalHits", "totalCount"} {
+		if value, ok := page[key]; ok {
+			json.Unmarshal(value, &total)
+		}
+	}
+
+	for pages := 1; pages < apiMaxPages; pages++ {
+		received := len(results)
+		next := index + received
+		if received == 0 || (total >= 0 && next >= total) || (total < 0 && received%max(pageSize, 1) != 0) {
+			break
+		}
+		paged := req
+		paged.Query = url.Values{}
+		for key, values := range req.Query {
+			paged.Query[key] = values
+		}
+		paged.Query.Set("index", strconv.Itoa(next))
+		if pageSize > 0 {
+			paged.Query.Set("pageSize", strconv.Itoa(pageSize))
+		}
+		resp, err := client.Raw(ctx, paged)
+		if err != nil {
+			return resp, err
+		}
+		if resp.Code < 200 || resp.Code >= 300 {
+			return resp, nil
+		}
+		var more struct {
+			Results []json.RawMessage `json:"results"`
+		}
+		if err := json.Unmarshal(resp.Body, &more); err != nil || len(more.Results) == 0 {
+			break
+		}
+		results = append(results, more.Results...)
+	}
+
+	merged, err := json.Marshal(results)
+	if err != nil {
+		return first, err
+	}
+	page["results"] = merged
+	if _, ok := page["resultCount"]; ok {
+		page["resultCount"] = json.RawMessage(strconv.Itoa(len(results)))
+	}
+	body, err := json.Marshal(page)
+	if err != nil {
+		return first, err
+	}
+	first.Body = body
+	return first, nil
+}
+
+// writeApiBody prints a response body, pretty-printing JSON unless raw.
+func writeApiBody(out io.Writer, body []byte, raw bool) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return
+	}
+	if !raw && json.Valid(body) {
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, bytes.TrimSpace(body), "", "  ") == nil {
+			body = pretty.Bytes()
+		}
+	}
+	out.Write(body)
+	if !bytes.HasSuffix(body, []byte("\n")) {
+		fmt.Fprintln(out)
+	}
+}
+
+func newApiCoverageCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var specFile string
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "List the API endpoints the CLI does not wrap yet",
+		Long: `Compare the API's OpenAPI document with the routes the CLI's client calls:
+list the operations no command wraps yet, grouped by tag, and the routes the
+client calls that the document does not have.
+
+The document is fetched from the API (` + api.SpecPath + `) unless --spec
+names a saved copy. Exits 1 when the client calls routes the API does not
+document, so CI can catch a renamed endpoint.`,
+		Example: `  mineos api coverage
+  mineos api coverage --spec swagger.json --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+
+			var spec []byte
+			source := specFile
+			if specFile != "" {
+				data, err := os.ReadFile(specFile)
+				if err != nil {
+					return fmt.Errorf("--spec: %w", err)
+				}
+				spec = data
+			} else {
+				cfg, err := loadConfig.Execute(ctx)
+				if err != nil {
+					return err
+				}
+				client := api.NewClientFromConfig(cfg)
+				if spec, err = client.FetchSpec(ctx); err != nil {
+					return err
+				}
+				source = cfg.ApiURL() + api.SpecPath
+			}
+			operations, err := api.ParseSpec(spec)
+			if err != nil {
+				return err
+			}
+
+			wrapped := map[string]bool{}
+			for _, endpoint := range api.Endpoints {
+				wrapped[endpoint.Key()] = true
+			}
+			documented := map[string]bool{}
+			var missing []api.Operation
+			for _, op := range operations {
+				documented[op.Key()] = true
+				if !wrapped[op.Key()] {
+					missing = append(missing, op)
+				}
+			}
+			var stale []api.Endpoint
+			for _, endpoint := range api.Endpoints {
+				if !documented[endpoint.Key()] {
+					stale = append(stale, endpoint)
+				}
+			}
+			covered := len(operations) - len(missing)
+
+			if jsonOut {
+				type entry struct {
+					Method  string   `json:"method"`
+					Path    string   `json:"path"`
+					Summary string   `json:"summary,omitempty"`
+					Tags    []string `json:"tags,omitempty"`
+				}
+				report := struct {
+					Spec       string  `json:"spec"`
+					Operations int     `json:"operations"`
+					Wrapped    int     `json:"wrapped"`
+					Unwrapped  []entry `json:"unwrapped"`
+					Stale      []entry `json:"stale"`
+				}{Spec: source, Operations: len(operations), Wrapped: covered, Unwrapped: []entry{}, Stale: []entry{}}
+				for _, op := range missing {
+					report.Unwrapped = append(report.Unwrapped, entry{Method: op.Method, Path: op.Path, Summary: op.Summary, Tags: op.Tags})
+				}
+				for _, endpoint := range stale {
+					report.Stale = append(report.Stale, entry{Method: endpoint.Method, Path: endpoint.Path})
+				}
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				printApiCoverage(out, source, len(operations), covered, missing, stale)
+			}
+
+			if len(stale) > 0 {
+				return fmt.Errorf("the CLI calls %d route(s) the API does not document", len(stale))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&specFile, "spec", "", "Read the OpenAPI document from this file instead of the API")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+func printApiCoverage(out io.Writer, source string, total, covered int, missing []api.Operation, stale []api.Endpoint) {
+	fmt.Fprintf(out, "%s %s\n", styleLabel.Render("OpenAPI document:"), source)
+	percent := 0
+	if total > 0 {
+		percent = covered * 100 / total
+	}
+	fmt.Fprintf(out, "The CLI wraps %d of %d operation(s) (%d%%).\n", covered, total, percent)
+
+	if len(missing) > 0 {
+		fmt.Fprintln(out, styleTitle.Render(fmt.Sprintf("\nNot wrapped (%d)", len(missing))))
+		byTag := map[string][]api.Operation{}
+		var tags []string
+		for _, op := range missing {
+			tag := "Other"
+			if len(op.Tags) > 0 {
+				tag = op.Tags[0]
+			}
+			if _, ok := byTag[tag]; !ok {
+				tags = append(tags, tag)
+			}
+			byTag[tag] = append(byTag[tag], op)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			fmt.Fprintf(out, "  %s\n", styleLabel.Render(tag))
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			for _, op := range byTag[tag] {
+				summary := op.Summary
+				if op.Deprecated {
+					summary = strings.TrimSpace("(deprecated) " + summary)
+				}
+				fmt.Fprintf(w, "    %s\t%s\t%s\n", op.Method, op.Path, styleDim.Render(summary))
+			}
+			w.Flush()
+		}
+	}
+
+	if len(stale) > 0 {
+		fmt.Fprintln(out, styleTitle.Render(fmt.Sprintf("\nCalled by the CLI but not in the document (%d)", len(stale))))
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, endpoint := range stale {
+			fmt.Fprintf(w, "    %s\t%s\n", endpoint.Method, endpoint.Path)
+		}
+		w.Flush()
+	}
+}
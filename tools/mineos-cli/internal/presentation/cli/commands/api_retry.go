@@ -17,6 +17,7 @@ func withApiKeyRetry(ctx context.Context, loadConfig *usecases.LoadConfigUseCase
 		return false, err
 	}
 	client := api.NewClientFromConfig(cfg)
+	client.SetRateLimit(transferLimiter)
 
 	actionErr := action(cfg, client)
 	if actionErr == nil {
@@ -31,7 +32,7 @@ func withApiKeyRetry(ctx context.Context, loadConfig *usecases.LoadConfigUseCase
 	}
 
 	if out != nil {
-		fmt.Fprintln(out, "Refreshed API key from local database.")
+		fmt.Fprintln(decor(out), "Refreshed API key from local database.")
 	}
 
 	cfg, err = loadConfig.Execute(ctx)
@@ -39,6 +40,7 @@ func withApiKeyRetry(ctx context.Context, loadConfig *usecases.LoadConfigUseCase
 		return true, err
 	}
 	client = api.NewClientFromConfig(cfg)
+	client.SetRateLimit(transferLimiter)
 	if err := action(cfg, client); err != nil {
 		if key != "" {
 			return true, fmt.Errorf("%w (refreshed key did not resolve the issue)", err)
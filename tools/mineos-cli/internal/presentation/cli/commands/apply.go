@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/manifest"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+func NewApplyCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var file string
+	var dryRun bool
+	var yes bool
+	var opts usecases.ManifestOptions
+
+	cmd := &cobra.Command{
+		Use:   "apply -f <manifest.yaml>",
+		Short: "Create and update servers to match a declarative manifest",
+		Long: `Reconcile the installation with a YAML manifest of servers.
+
+Missing servers are created; memory, server.properties values, Modrinth
+mods/plugins, tags and the EULA are updated to match. Differences apply
+leaves alone are reported as drift: a different version on an existing
+server (unless --allow-version-change), a different server type, and jars
+that are not in the manifest. Servers missing from the manifest are listed
+but never removed.
+
+  servers:
+    - name: survival
+      software: paper
+      version: 1.21.1
+      memory: 4G
+      eula: true
+      plugins: [luckperms, "worldedit@7.3.6"]
+      properties:
+        difficulty: hard
+        max-players: 40
+      tags: [public]`,
+		Example: `  mineos apply -f servers.yaml --dry-run
+  mineos apply -f servers.yaml --yes`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			m, err := readManifest(file)
+			if err != nil {
+				return err
+			}
+
+			_, err = withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(cfg config.Config, client *api.Client) error {
+				uc := usecases.NewManifestUseCase(client)
+				plan, err := uc.Plan(ctx, m, opts)
+				if err != nil {
+					return err
+				}
+				printManifestPlan(cmd.OutOrStdout(), "Plan for "+file, plan)
+				pending := plan.Pending()
+				if len(pending) == 0 || dryRun {
+					return nil
+				}
+				if !yes {
+					ok, err := promptYesNo(nil, cmd.OutOrStdout(), fmt.Sprintf("Apply %d change(s)?", len(pending)), false)
+					if err != nil {
+						return err
+					}
+					if !ok {
+						return fmt.Errorf("apply cancelled")
+					}
+				}
+
+				cmd.Println()
+				started := time.Now()
+				err = uc.Apply(ctx, plan, usecases.ManifestHooks{
+					AfterCreate: func(ctx context.Context, name string) error {
+						return assignFreePorts(ctx, client, cfg, cmd, name, 0)
+					},
+					Progress: func(change usecases.ManifestChange, err error) {
+						if err != nil {
+							cmd.Printf("  %s %s: %s %s\n", markFailed(), change.Server, describeManifestChange(change), styleError.Render(err.Error()))
+							return
+						}
+						cmd.Printf("  %s %s: %s\n", markOK(), change.Server, describeManifestChange(change))
+					},
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(decor(cmd.OutOrStdout()), "\n%s Applied %d change(s) %s\n", markOK(), len(pending), styleDim.Render("("+formatElapsed(time.Since(started))+")"))
+				fmt.Fprintln(decor(cmd.OutOrStdout()), styleDim.Render("Running servers pick up version, memory and property changes on their next restart."))
+				return nil
+			})
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Manifest file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only show the plan")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Apply without asking for confirmation")
+	cmd.Flags().BoolVar(&opts.AllowVersionChange, "allow-version-change", false, "Switch existing servers to the manifest's version")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func readManifest(path string) (manifest.Manifest, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return manifest.Manifest{}, err
+	}
+	return manifest.Parse(data)
+}
+
+func printManifestPlan(out io.Writer, title string, plan usecases.ManifestPlan) {
+	fmt.Fprintf(out, "%s\n", styleTitle.Render(title))
+	server := ""
+	for _, change := range plan.Changes {
+		if change.Server != server {
+			server = change.Server
+			fmt.Fprintf(out, "\n%s\n", server)
+		}
+		symbol := styleInfo.Render("~")
+		switch {
+		case change.Action == usecases.ManifestDrift:
+			symbol = styleWarning.Render("!")
+		case change.Action == usecases.ManifestCreate || change.From == "":
+			symbol = styleSuccess.Render("+")
+		}
+		fmt.Fprintf(out, "  %s %s\n", symbol, describeManifestChange(change))
+	}
+	if len(plan.Unmanaged) > 0 {
+		fmt.Fprintf(out, "\n%s %s\n", styleDim.Render("Not in manifest:"), strings.Join(plan.Unmanaged, ", "))
+	}
+
+	pending, drift := len(plan.Pending()), len(plan.Drift())
+	fmt.Fprintln(out)
+	if pending == 0 && drift == 0 {
+		fmt.Fprintln(out, styleSuccess.Render("Everything matches the manifest."))
+		return
+	}
+	fmt.Fprintf(out, "%d change(s) to apply, %d drift\n", pending, drift)
+}
+
+func describeManifestChange(change usecases.ManifestChange) string {
+	if change.Field == "server" {
+		return "create " + change.To + " server"
+	}
+	subject := change.Field
+	if change.Key != "" {
+		subject += " " + change.Key
+	}
+	text := subject
+	switch {
+	case change.From != "" && change.To != "":
+		text += ": " + change.From + " -> " + change.To
+	case change.To != "":
+		text += ": " + change.To
+	}
+	if change.Note != "" {
+		text += styleDim.Render(" (" + change.Note + ")")
+	}
+	return text
+}
@@ -0,0 +1,489 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	domainbackup "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/backup"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/backup"
+)
+
+// backupExcludes are server folders that are not backed up: the API keeps
+// its own archives there.
+var backupExcludes = []string{"archives"}
+
+func NewBackupCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Archive server directories on the host with zstd",
+		Long: `Back up a server directory straight from the host into a tar archive
+compressed with zstd on every CPU, stored under archives/<server> in the
+host base directory. --incremental stores only the files that changed since
+the server's previous backup, compared by size and modification time or,
+with --detect hash, by content. Every backup lists the full tree, so
+restoring one reads the archives its unchanged files live in.
+
+On btrfs, ZFS or LVM, --snapshot takes an atomic filesystem snapshot
+instead; see 'mineos backup snapshots'.
+
+A running server is told to stop saving and flush its world first, and the
+backup starts once its log confirms the flush; saving is turned back on
+when the backup is done. --no-save-hold skips this.
+
+'mineos backup verify' checks that a backup can be restored.
+
+These backups sit beside the API's rdiff-backup history ('mineos servers
+backup'), which they do not replace.`,
+	}
+
+	cmd.AddCommand(newBackupCreateCommand(loadConfig))
+	cmd.AddCommand(newBackupListCommand(loadConfig))
+	cmd.AddCommand(newBackupRestoreCommand(loadConfig))
+	cmd.AddCommand(newBackupVerifyCommand(loadConfig))
+	cmd.AddCommand(newBackupDeleteCommand(loadConfig))
+	cmd.AddCommand(newBackupSnapshotsCommand(loadConfig))
+
+	return cmd
+}
+
+func newBackupCreateCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var format string
+	var level int
+	var workers int
+	var incremental bool
+	var detect string
+	var output string
+	var snapshotMode bool
+	var noSaveHold bool
+
+	cmd := &cobra.Command{
+		Use:   "create <server>",
+		Short: "Back up a server directory",
+		Example: `  mineos backup create survival
+  mineos backup create survival --incremental
+  mineos backup create survival --level 19 --workers 4
+  mineos backup create survival --output - | ssh backup@nas 'cat > survival.tar.zst'
+  mineos backup create survival --snapshot`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			ctx := context.Background()
+			opts, err := backupOptions(format, level, workers, detect)
+			if err != nil {
+				return err
+			}
+			storage, err := loadHostStorage(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			dir := storage.ServerDir(name)
+			if _, err := os.Stat(dir); err != nil {
+				return fmt.Errorf("server directory %s: %w", dir, err)
+			}
+
+			out := cmd.OutOrStdout()
+			if snapshotMode {
+				for _, flag := range []string{"format", "level", "workers", "incremental", "detect", "output"} {
+					if cmd.Flags().Changed(flag) {
+						return fmt.Errorf("--%s does not apply to --snapshot", flag)
+					}
+				}
+				return snapshotBackup(ctx, loadConfig, out, storage, name, noSaveHold)
+			}
+			if output == "-" {
+				out = cmd.ErrOrStderr()
+			}
+			if output != "" && incremental {
+				return errors.New("--incremental needs the backup store; drop --output")
+			}
+			store := backup.NewStore(storage.Archives)
+			if incremental {
+				parent, found, err := store.Latest(name)
+				if err != nil {
+					return err
+				}
+				if found {
+					opts.Parent = &parent
+				} else {
+					fmt.Fprintln(out, styleDim.Render("No earlier backup of "+name+"; taking a full one."))
+				}
+			}
+			release, err := holdSaves(ctx, loadConfig, out, name, noSaveHold)
+			if err != nil {
+				return err
+			}
+
+			bar := newTransferProgress(out, name, 0)
+			opts.Progress = bar
+			opts.OnPlanned = func(_ int, bytes int64) { bar.SetTotal(bytes) }
+
+			if output != "" {
+				manifest, err := streamBackup(ctx, name, dir, output, opts)
+				bar.Finish()
+				warnUnreleased(out, name, release())
+				if err != nil {
+					return err
+				}
+				target := output
+				if output == "-" {
+					target = "stdout"
+				}
+				fmt.Fprintf(out, "%s Wrote %s: %d files, %s\n", markOK(), target, manifest.Stored, formatBytes(manifest.Bytes))
+				return nil
+			}
+
+			manifest, err := store.Create(ctx, name, dir, opts)
+			bar.Finish()
+			warnUnreleased(out, name, release())
+			if err != nil {
+				return err
+			}
+
+			kind := "full"
+			if !manifest.Full() {
+				kind = "incremental to " + manifest.Parent
+			}
+			fmt.Fprintf(out, "%s Backed up %s as %s (%s)\n", markOK(), name, manifest.ID, kind)
+			fmt.Fprintf(out, "  stored %d of %d files, %s of %s, in %s as %s\n",
+				manifest.Stored, countFiles(manifest), formatBytes(manifest.Bytes), formatBytes(manifest.TotalBytes()),
+				manifest.Duration.Round(time.Second), formatBytes(manifest.Size))
+			fmt.Fprintln(out, styleDim.Render("  "+store.ArchivePath(manifest)))
+			return nil
+		},
+	}
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names, _ := listServerNames(cmd, loadConfig)
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cmd.Flags().StringVar(&format, "format", "zstd", "Archive format: zstd, gzip or zip")
+	cmd.Flags().IntVar(&level, "level", 0, "Compression level (zstd 1-22, default 3; gzip and zip 1-9, default 6)")
+	cmd.Flags().IntVar(&workers, "workers", 0, "Parallel compression and hashing workers (default: one per CPU)")
+	cmd.Flags().BoolVar(&incremental, "incremental", false, "Store only the files changed since the previous backup")
+	cmd.Flags().StringVar(&detect, "detect", string(domainbackup.ByMtime), "How --incremental spots changes: mtime or hash")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the archive to this file, or - for stdout, instead of the backup store")
+	cmd.Flags().BoolVar(&snapshotMode, "snapshot", false, "Take a btrfs, ZFS or LVM snapshot instead of an archive")
+	cmd.Flags().BoolVar(&noSaveHold, "no-save-hold", false, "Back up a running server without pausing its saves")
+
+	return cmd
+}
+
+func backupOptions(format string, level, workers int, detect string) (backup.Options, error) {
+	f, err := domainbackup.ParseFormat(format)
+	if err != nil {
+		return backup.Options{}, err
+	}
+	if level != 0 {
+		if err := f.ValidLevel(level); err != nil {
+			return backup.Options{}, err
+		}
+	}
+	if workers < 0 {
+		return backup.Options{}, errors.New("--workers must not be negative")
+	}
+	detection := domainbackup.ChangeDetection(detect)
+	if detection != domainbackup.ByMtime && detection != domainbackup.ByHash {
+		return backup.Options{}, fmt.Errorf("--detect must be mtime or hash, not %q", detect)
+	}
+	return backup.Options{Format: f, Level: level, Workers: workers, Detect: detection, Exclude: backupExcludes}, nil
+}
+
+// streamBackup writes a full backup of dir to output, or stdout for "-",
+// without recording it in the store.
+func streamBackup(ctx context.Context, name, dir, output string, opts backup.Options) (domainbackup.Manifest, error) {
+	entries, err := backup.Scan(ctx, dir, opts)
+	if err != nil {
+		return domainbackup.Manifest{}, err
+	}
+	now := time.Now()
+	manifest := domainbackup.Manifest{
+		ID:      domainbackup.NewID(name, now),
+		Server:  name,
+		Created: now.UTC(),
+		Format:  opts.Format,
+		Level:   opts.Level,
+		Detect:  opts.Detect,
+	}
+	if manifest.Level == 0 {
+		manifest.Level = opts.Format.DefaultLevel()
+	}
+	manifest.Entries = domainbackup.Plan(manifest.ID, entries, nil, opts.Detect)
+	manifest.Archive = filepath.Base(output)
+	if opts.OnPlanned != nil {
+		opts.OnPlanned(countFiles(manifest), manifest.TotalBytes())
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "-" {
+		file, err := os.Create(output)
+		if err != nil {
+			return manifest, err
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := backup.Write(ctx, dir, w, &manifest, opts); err != nil {
+		return manifest, err
+	}
+	return manifest, nil
+}
+
+func countFiles(m domainbackup.Manifest) int {
+	files := 0
+	for _, entry := range m.Entries {
+		if entry.Type == domainbackup.TypeFile {
+			files++
+		}
+	}
+	return files
+}
+
+func newBackupListCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "list [server]",
+		Short: "List host-side backups",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			storage, err := loadHostStorage(context.Background(), loadConfig)
+			if err != nil {
+				return err
+			}
+			server := ""
+			if len(args) == 1 {
+				server = args[0]
+			}
+			manifests, err := backup.NewStore(storage.Archives).List(server)
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				summaries := make([]domainbackup.Manifest, len(manifests))
+				for i, m := range manifests {
+					m.Entries = nil
+					summaries[i] = m
+				}
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(summaries)
+			}
+			if len(manifests) == 0 {
+				cmd.Println("No backups yet; create one with 'mineos backup create <server>'.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTYPE\tFILES\tDATA\tARCHIVE\tCREATED")
+			for _, m := range manifests {
+				kind := "full"
+				if !m.Full() {
+					kind = "incremental"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d/%d\t%s\t%s %s\t%s\n", m.ID, kind, m.Stored, countFiles(m), formatBytes(m.Bytes), formatBytes(m.Size), m.Format, m.Created.Local().Format("2006-01-02 15:04"))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names, _ := listServerNames(cmd, loadConfig)
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON (without the file lists)")
+
+	return cmd
+}
+
+func newBackupRestoreCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var to string
+	var workers int
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "restore <id>",
+		Short: "Restore a server directory from a host-side backup",
+		Long: `Restore a backup, reading every archive of its chain. The files are
+extracted next to the server first and swapped in once complete, so a failed
+restore leaves the server as it was; the server must be stopped. --to
+extracts into another (empty) directory instead and leaves the server alone.`,
+		Example: `  mineos backup restore survival-20261014-040000
+  mineos backup restore survival-20261014-040000 --to /tmp/survival-check`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+			cfg, err := loadConfig.Execute(ctx)
+			if err != nil {
+				return err
+			}
+			storage, err := loadHostStorage(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			store := backup.NewStore(storage.Archives)
+			manifest, err := store.Find(args[0])
+			if err != nil {
+				return err
+			}
+			chain, err := store.Chain(manifest)
+			if err != nil {
+				return err
+			}
+
+			target := to
+			if target == "" {
+				target = storage.ServerDir(manifest.Server)
+				running, err := serverIsRunning(ctx, loadConfig, out, manifest.Server)
+				if err != nil {
+					return fmt.Errorf("could not check whether %s is running: %w", manifest.Server, err)
+				}
+				if running {
+					return ports.WithKind(fmt.Errorf("%s is running; stop it first with 'mineos servers stop %s'", manifest.Server, manifest.Server), ports.ErrConflict)
+				}
+				if !yes {
+					warning := fmt.Sprintf("This replaces the files of %s with backup %s from %s.", manifest.Server, manifest.ID, manifest.Created.Local().Format("2006-01-02 15:04"))
+					if err := confirmServerOperation(cfg, out, manifest.Server, "restore", warning); err != nil {
+						return err
+					}
+				}
+			} else if entries, err := os.ReadDir(target); err == nil && len(entries) > 0 {
+				return fmt.Errorf("%s is not empty", target)
+			}
+
+			bar := newTransferProgress(out, manifest.ID, manifest.TotalBytes())
+			if to != "" {
+				err = backup.Extract(ctx, chain, store.ArchivePath, target, workers, bar)
+				bar.Finish()
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "%s Extracted %s into %s\n", markOK(), manifest.ID, target)
+				return nil
+			}
+
+			staging := filepath.Join(filepath.Dir(target), "."+filepath.Base(target)+".restore-"+manifest.ID)
+			if err := os.RemoveAll(staging); err != nil {
+				return err
+			}
+			err = backup.Extract(ctx, chain, store.ArchivePath, staging, workers, bar)
+			bar.Finish()
+			if err != nil {
+				os.RemoveAll(staging)
+				return err
+			}
+			if err := swapServerDir(target, staging); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s Restored %s from %s\n", markOK(), manifest.Server, manifest.ID)
+			return nil
+		},
+	}
+	cmd.ValidArgsFunction = completeBackupIDs(loadConfig)
+
+	cmd.Flags().StringVar(&to, "to", "", "Extract into this directory instead of replacing the server")
+	cmd.Flags().IntVar(&workers, "workers", 0, "Parallel decompression workers (default: one per CPU)")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Restore without asking for confirmation")
+
+	return cmd
+}
+
+// swapServerDir puts a restored copy in place of target, carrying over the
+// folders backups leave out.
+func swapServerDir(target, restored string) error {
+	if _, err := os.Stat(target); os.IsNotExist(err) {
+		return os.Rename(restored, target)
+	}
+	replaced := filepath.Join(filepath.Dir(target), "."+filepath.Base(target)+".replaced-"+time.Now().UTC().Format("20060102-150405"))
+	if err := os.Rename(target, replaced); err != nil {
+		return err
+	}
+	if err := os.Rename(restored, target); err != nil {
+		_ = os.Rename(replaced, target)
+		return err
+	}
+	for _, name := range backupExcludes {
+		if _, err := os.Stat(filepath.Join(replaced, name)); err == nil {
+			if err := os.Rename(filepath.Join(replaced, name), filepath.Join(target, name)); err != nil {
+				return fmt.Errorf("keep %s: %w (the previous files are in %s)", name, err, replaced)
+			}
+		}
+	}
+	return os.RemoveAll(replaced)
+}
+
+func newBackupDeleteCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Delete a host-side backup",
+		Long:  "Delete a backup. A backup whose archive later incremental backups take files from cannot be deleted until they are.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			storage, err := loadHostStorage(context.Background(), loadConfig)
+			if err != nil {
+				return err
+			}
+			if err := backup.NewStore(storage.Archives).Delete(args[0]); err != nil {
+				return err
+			}
+			cmd.Printf("Deleted %s\n", args[0])
+			return nil
+		},
+	}
+	cmd.ValidArgsFunction = completeBackupIDs(loadConfig)
+
+	return cmd
+}
+
+func completeBackupIDs(loadConfig *usecases.LoadConfigUseCase) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		storage, err := loadHostStorage(cmd.Context(), loadConfig)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		manifests, err := backup.NewStore(storage.Archives).List("")
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		ids := make([]string, 0, len(manifests))
+		for _, m := range manifests {
+			ids = append(ids, m.ID+"\t"+m.Created.Local().Format("2006-01-02 15:04"))
+		}
+		return ids, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func serverIsRunning(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, out io.Writer, name string) (bool, error) {
+	var running bool
+	_, err := withApiKeyRetry(ctx, loadConfig, out, func(_ config.Config, client *api.Client) error {
+		detail, err := client.GetServer(ctx, name)
+		if err != nil {
+			return err
+		}
+		running = detail.IsRunning()
+		return nil
+	})
+	return running, err
+}
@@ -0,0 +1,308 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	domainsnapshot "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/snapshot"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/snapshot"
+)
+
+// snapshotBackup takes a filesystem snapshot of a server, with its saves
+// held for the moment it takes, so the snapshot holds a consistent world
+// without stopping the server.
+func snapshotBackup(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, out io.Writer, storage hostStorage, name string, noHold bool) error {
+	provider, _, err := detectSnapshots(ctx, storage, name)
+	if err != nil {
+		return err
+	}
+	release, err := holdSaves(ctx, loadConfig, out, name, noHold)
+	if err != nil {
+		return err
+	}
+	started := time.Now()
+	snap, err := provider.Create(ctx, domainsnapshot.NewName(name, started))
+	warnUnreleased(out, name, release())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s Snapshot %s of %s taken in %s\n", markOK(), snap.Name, provider.Volume(), time.Since(started).Round(time.Millisecond))
+	fmt.Fprintln(out, styleDim.Render("  "+snap.Ref))
+	return nil
+}
+
+// detectSnapshots returns the snapshot provider of a server and its
+// resolved directory.
+func detectSnapshots(ctx context.Context, storage hostStorage, name string) (snapshot.Provider, string, error) {
+	dir, err := filepath.EvalSymlinks(storage.ServerDir(name))
+	if err != nil {
+		return nil, "", fmt.Errorf("server directory %s: %w", storage.ServerDir(name), err)
+	}
+	provider, err := snapshot.Detect(ctx, name, dir, storage.Snapshots)
+	if err != nil {
+		return nil, "", err
+	}
+	return provider, dir, nil
+}
+
+// findSnapshot looks a snapshot up by name; its server is part of the name.
+func findSnapshot(ctx context.Context, storage hostStorage, name string) (snapshot.Provider, string, domainsnapshot.Snapshot, error) {
+	server, _, ok := domainsnapshot.ParseName(name)
+	if !ok {
+		return nil, "", domainsnapshot.Snapshot{}, fmt.Errorf("%q is not a MineOS snapshot name (see 'mineos backup snapshots list')", name)
+	}
+	provider, dir, err := detectSnapshots(ctx, storage, server)
+	if err != nil {
+		return nil, "", domainsnapshot.Snapshot{}, err
+	}
+	snapshots, err := provider.List(ctx)
+	if err != nil {
+		return nil, "", domainsnapshot.Snapshot{}, err
+	}
+	for _, snap := range snapshots {
+		if snap.Name == name {
+			return provider, dir, snap, nil
+		}
+	}
+	return nil, "", domainsnapshot.Snapshot{}, ports.WithKind(fmt.Errorf("snapshot %s not found on %s", name, provider.Volume()), ports.ErrNotFound)
+}
+
+func newBackupSnapshotsCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshots",
+		Short: "List, roll back and delete filesystem snapshots",
+		Long: `Manage the snapshots 'mineos backup create --snapshot' takes of servers
+on btrfs subvolumes, ZFS datasets and LVM logical volumes.`,
+	}
+
+	cmd.AddCommand(newBackupSnapshotsListCommand(loadConfig))
+	cmd.AddCommand(newBackupSnapshotsRollbackCommand(loadConfig))
+	cmd.AddCommand(newBackupSnapshotsDeleteCommand(loadConfig))
+
+	return cmd
+}
+
+func newBackupSnapshotsListCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "list [server]",
+		Short: "List snapshots and which servers can be snapshotted",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			storage, err := loadHostStorage(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			servers := args
+			if len(servers) == 0 {
+				entries, err := os.ReadDir(storage.Servers)
+				if err != nil {
+					return err
+				}
+				for _, entry := range entries {
+					if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+						servers = append(servers, entry.Name())
+					}
+				}
+			}
+
+			snapshots := []domainsnapshot.Snapshot{}
+			var notes []string
+			for _, server := range servers {
+				provider, dir, err := detectSnapshots(ctx, storage, server)
+				if err != nil {
+					notes = append(notes, server+": "+err.Error())
+					continue
+				}
+				if !provider.Volume().Exclusive(dir) {
+					notes = append(notes, fmt.Sprintf("%s: %s also holds other files, so its snapshots cannot be rolled back", server, provider.Volume()))
+				}
+				found, err := provider.List(ctx)
+				if err != nil {
+					return err
+				}
+				snapshots = append(snapshots, found...)
+			}
+
+			if jsonOut {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(snapshots)
+			}
+			out := cmd.OutOrStdout()
+			if len(snapshots) == 0 {
+				fmt.Fprintln(out, "No snapshots yet; take one with 'mineos backup create <server> --snapshot'.")
+			} else {
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "NAME\tSERVER\tVOLUME\tSIZE\tCREATED")
+				for _, snap := range snapshots {
+					size := "-"
+					if snap.Size > 0 {
+						size = formatBytes(snap.Size)
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", snap.Name, snap.Server, snap.Volume, size, snap.Created.Local().Format("2006-01-02 15:04"))
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+			}
+			for _, note := range notes {
+				fmt.Fprintln(out, styleDim.Render(note))
+			}
+			return nil
+		},
+	}
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names, _ := listServerNames(cmd, loadConfig)
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+func newBackupSnapshotsRollbackCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "rollback <snapshot>",
+		Short: "Return a stopped server to a snapshot",
+		Long: `Return a server's volume to a snapshot. The server must be stopped and
+have its own subvolume, dataset or logical volume, since everything on it is
+rolled back.
+
+btrfs swaps in a writable copy of the snapshot and deletes the current
+subvolume. ZFS can only roll back to a dataset's latest snapshot, so newer
+ones are destroyed. LVM merges the snapshot into the volume, using it up; a
+mounted volume finishes the merge the next time it is activated.`,
+		Example: `  mineos backup snapshots rollback mineos-survival-20261014-040000`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+			cfg, err := loadConfig.Execute(ctx)
+			if err != nil {
+				return err
+			}
+			storage, err := loadHostStorage(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			provider, dir, snap, err := findSnapshot(ctx, storage, args[0])
+			if err != nil {
+				return err
+			}
+			volume := provider.Volume()
+			if !volume.Exclusive(dir) {
+				return fmt.Errorf("%s is mounted at %s and holds more than %s; copy files out of %s instead", volume, volume.Mount, snap.Server, snap.Ref)
+			}
+			running, err := serverIsRunning(ctx, loadConfig, out, snap.Server)
+			if err != nil {
+				return fmt.Errorf("could not check whether %s is running: %w", snap.Server, err)
+			}
+			if running {
+				return ports.WithKind(fmt.Errorf("%s is running; stop it first with 'mineos servers stop %s'", snap.Server, snap.Server), ports.ErrConflict)
+			}
+
+			if !yes {
+				warning := fmt.Sprintf("This returns %s to snapshot %s from %s; later changes are lost.", snap.Server, snap.Name, snap.Created.Local().Format("2006-01-02 15:04"))
+				if volume.Kind == domainsnapshot.KindZfs {
+					warning += " Newer snapshots of " + volume.Name + " are destroyed."
+				}
+				if err := confirmServerOperation(cfg, out, snap.Server, "rollback", warning); err != nil {
+					return err
+				}
+			}
+
+			err = provider.Rollback(ctx, snap)
+			if errors.Is(err, snapshot.ErrMergeDelayed) {
+				fmt.Fprintf(out, "%s %s is mounted, so %s.\n", styleWarning.Render("!"), volume.Name, err)
+				fmt.Fprintf(out, "  Unmount %s and run 'lvchange -an %s && lvchange -ay %s', or reboot, before starting %s.\n", volume.Mount, volume.Name, volume.Name, snap.Server)
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s Rolled %s back to %s\n", markOK(), snap.Server, snap.Name)
+			return nil
+		},
+	}
+	cmd.ValidArgsFunction = completeSnapshotNames(loadConfig)
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Roll back without asking for confirmation")
+
+	return cmd
+}
+
+func newBackupSnapshotsDeleteCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <snapshot>",
+		Short: "Delete a snapshot",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			storage, err := loadHostStorage(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			provider, _, snap, err := findSnapshot(ctx, storage, args[0])
+			if err != nil {
+				return err
+			}
+			if err := provider.Delete(ctx, snap); err != nil {
+				return err
+			}
+			cmd.Printf("Deleted %s\n", snap.Name)
+			return nil
+		},
+	}
+	cmd.ValidArgsFunction = completeSnapshotNames(loadConfig)
+
+	return cmd
+}
+
+func completeSnapshotNames(loadConfig *usecases.LoadConfigUseCase) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		storage, err := loadHostStorage(cmd.Context(), loadConfig)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		servers, _ := listServerNames(cmd, loadConfig)
+		var names []string
+		for _, server := range servers {
+			provider, _, err := detectSnapshots(cmd.Context(), storage, server)
+			if err != nil {
+				continue
+			}
+			snapshots, err := provider.List(cmd.Context())
+			if err != nil {
+				continue
+			}
+			for _, snap := range snapshots {
+				names = append(names, snap.Name+"\t"+snap.Created.Local().Format("2006-01-02 15:04"))
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	domainbackup "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/backup"
+	domainschedule "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/schedule"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/backup"
+)
+
+func newBackupVerifyCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var extract bool
+	var scratch string
+	var workers int
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "verify <id>",
+		Short: "Check that a host-side backup can be restored",
+		Long: `Read every archive of a backup to its end, so a truncated or corrupted
+archive fails its zstd, gzip or zip checksum, and compare each file with the
+size and SHA-256 in the manifest. Every world needs its level.dat, and every
+region file a header whose chunks point inside the file without overlapping.
+
+--extract also restores the backup into a scratch directory, removed
+afterwards, to prove the restore itself works. It needs as much free space
+as the server takes.
+
+The agent can verify the latest backups on a schedule and alert when one
+fails; see verify in 'mineos state edit schedule'.`,
+		Example: `  mineos backup verify survival-20261014-040000
+  mineos backup verify survival-20261014-040000 --extract`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			storage, err := loadHostStorage(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			store := backup.NewStore(storage.Archives)
+			manifest, err := store.Find(args[0])
+			if err != nil {
+				return err
+			}
+			if scratch == "" {
+				scratch = storage.Base
+			}
+
+			progressOut := cmd.OutOrStdout()
+			if jsonOut {
+				progressOut = cmd.ErrOrStderr()
+			}
+			bar := newTransferProgress(progressOut, manifest.ID, manifest.TotalBytes())
+			report, err := verifyBackup(ctx, store, manifest, backup.VerifyOptions{
+				Workers:    workers,
+				Progress:   bar,
+				Extract:    extract,
+				ScratchDir: scratch,
+			})
+			bar.Finish()
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				printBackupReport(cmd.OutOrStdout(), report)
+			}
+			if !report.OK() {
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
+				return exitCodeError{code: 1}
+			}
+			return nil
+		},
+	}
+	cmd.ValidArgsFunction = completeBackupIDs(loadConfig)
+
+	cmd.Flags().BoolVar(&extract, "extract", false, "Also restore the backup into a scratch directory")
+	cmd.Flags().StringVar(&scratch, "scratch-dir", "", "Where --extract restores to (default: the host base directory)")
+	cmd.Flags().IntVar(&workers, "workers", 0, "Parallel decompression workers (default: one per CPU)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output the report as JSON")
+
+	return cmd
+}
+
+// verifyBackup verifies a backup with the chain it restores from. A chain
+// with a missing parent is a failed report rather than an error.
+func verifyBackup(ctx context.Context, store *backup.Store, manifest domainbackup.Manifest, opts backup.VerifyOptions) (domainbackup.Report, error) {
+	chain, err := store.Chain(manifest)
+	if err != nil {
+		report := domainbackup.Report{ID: manifest.ID, Server: manifest.Server}
+		report.Problem("%v", err)
+		return report, nil
+	}
+	return backup.Verify(ctx, chain, store.ArchivePath, opts)
+}
+
+// verifyLatestBackups verifies the newest backup of every server a verify
+// policy covers, one server at a time so the disk is not read twice over.
+func verifyLatestBackups(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, policy domainschedule.VerifyPolicy) ([]domainbackup.Report, error) {
+	storage, err := loadHostStorage(ctx, loadConfig)
+	if err != nil {
+		return nil, err
+	}
+	store := backup.NewStore(storage.Archives)
+	manifests, err := store.List("")
+	if err != nil {
+		return nil, err
+	}
+	latest := map[string]domainbackup.Manifest{}
+	var servers []string
+	for _, m := range manifests {
+		if !policy.MatchesServer(m.Server) {
+			continue
+		}
+		if _, seen := latest[m.Server]; !seen {
+			servers = append(servers, m.Server)
+		}
+		// List is oldest first.
+		latest[m.Server] = m
+	}
+
+	var reports []domainbackup.Report
+	for _, server := range servers {
+		report, err := verifyBackup(ctx, store, latest[server], backup.VerifyOptions{Extract: policy.Extract, ScratchDir: storage.Base})
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func printBackupReport(out io.Writer, report domainbackup.Report) {
+	worlds := "none"
+	if len(report.Worlds) > 0 {
+		worlds = strings.Join(report.Worlds, ", ")
+	}
+	fmt.Fprintf(out, "%s %s\n", styleTitle.Render("Backup"), report.ID)
+	fmt.Fprintf(out, "  %s %s\n", styleLabel.Render("Archives:"), strings.Join(report.Archives, ", "))
+	fmt.Fprintf(out, "  %s %d (%s)\n", styleLabel.Render("Files:"), report.Files, formatBytes(report.Bytes))
+	fmt.Fprintf(out, "  %s %s\n", styleLabel.Render("Worlds:"), worlds)
+	fmt.Fprintf(out, "  %s %d\n", styleLabel.Render("Regions:"), report.Regions)
+	if report.Extracted {
+		fmt.Fprintf(out, "  %s restored into a scratch directory\n", styleLabel.Render("Extract:"))
+	}
+	for _, note := range report.Notes {
+		fmt.Fprintln(out, styleDim.Render("  "+note))
+	}
+	fmt.Fprintln(out)
+	if report.OK() {
+		fmt.Fprintf(out, "%s %s verified in %s\n", markOK(), report.ID, report.Duration)
+		return
+	}
+	for _, problem := range report.Problems {
+		fmt.Fprintf(out, "%s %s\n", markFailed(), problem)
+	}
+	fmt.Fprintf(out, "\n%s %s failed verification with %d problem(s)\n", markFailed(), report.ID, len(report.Problems))
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// changeResult is the --json output of commands that modify state, shaped
+// like an Ansible module result so tasks can use changed_when/failed_when
+// without parsing text. Results is set when several servers were targeted.
+type changeResult struct {
+	Changed bool         `json:"changed"`
+	Failed  bool         `json:"failed"`
+	Msg     string       `json:"msg"`
+	Results []changeItem `json:"results,omitempty"`
+}
+
+type changeItem struct {
+	Name    string `json:"name"`
+	Changed bool   `json:"changed"`
+	Failed  bool   `json:"failed"`
+	Msg     string `json:"msg,omitempty"`
+}
+
+// printChangeResult writes result as JSON. A failed result still exits
+// non-zero, with the details in the JSON rather than on stderr, and with
+// exitPartial when only some of its servers failed.
+func printChangeResult(cmd *cobra.Command, result changeResult) error {
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return err
+	}
+	if result.Failed {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return exitCodeError{code: result.exitCode()}
+	}
+	return nil
+}
+
+func (r changeResult) exitCode() int {
+	failed := 0
+	for _, item := range r.Results {
+		if item.Failed {
+			failed++
+		}
+	}
+	if failed > 0 && failed < len(r.Results) {
+		return exitPartial
+	}
+	return exitFailure
+}
+
+func bulkChangeResult(action string, results []ports.BulkActionResult) changeResult {
+	result := changeResult{Msg: action + " finished"}
+	for _, item := range results {
+		entry := changeItem{Name: item.Name, Changed: item.Err == nil && !item.Unchanged}
+		switch {
+		case item.Err != nil:
+			entry.Failed = true
+			entry.Msg = item.Err.Error()
+			result.Failed = true
+		case item.Unchanged:
+			entry.Msg = "already in the requested state"
+		}
+		result.Changed = result.Changed || entry.Changed
+		result.Results = append(result.Results, entry)
+	}
+	if result.Failed {
+		result.Msg = action + " failed on some servers"
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+//go:build !windows
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// installCLIToPath does nothing outside Windows, where the CLI is run from
+// the install directory.
+func installCLIToPath(io.Writer, string) error {
+	return nil
+}
+
+// removeCLIFromPath removes a copy of the CLI from the usual PATH
+// directories.
+func removeCLIFromPath(out io.Writer) error {
+	// Check both possible install locations
+	systemBin := "/usr/local/bin/mineos"
+	homeDir, _ := os.UserHomeDir()
+	userBin := ""
+	if homeDir != "" {
+		userBin = filepath.Join(homeDir, ".local", "bin", "mineos")
+	}
+
+	removed := false
+
+	if _, err := os.Stat(systemBin); err == nil {
+		if err := os.Remove(systemBin); err != nil {
+			return fmt.Errorf("failed to remove CLI from %s: %w", systemBin, err)
+		}
+		fmt.Fprintf(out, "%s Removed CLI from: %s\n", markOK(), systemBin)
+		removed = true
+	}
+
+	if userBin != "" {
+		if _, err := os.Stat(userBin); err == nil {
+			if err := os.Remove(userBin); err != nil {
+				return fmt.Errorf("failed to remove CLI from %s: %w", userBin, err)
+			}
+			fmt.Fprintf(out, "%s Removed CLI from: %s\n", markOK(), userBin)
+			removed = true
+		}
+	}
+
+	if !removed {
+		fmt.Fprintln(out, "CLI not found in system PATH locations.")
+	}
+
+	return nil
+}
@@ -0,0 +1,294 @@
+//go:build windows
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// windowsUninstallKey is the Apps & Features entry, per user like the
+// install itself so no elevation is needed.
+const windowsUninstallKey = `Software\Microsoft\Windows\CurrentVersion\Uninstall\MineOS`
+
+var procSendMessageTimeout = windows.NewLazySystemDLL("user32.dll").NewProc("SendMessageTimeoutW")
+
+func windowsCLIDir() (string, error) {
+	localAppData := os.Getenv("LOCALAPPDATA")
+	if localAppData == "" {
+		return "", errors.New("LOCALAPPDATA environment variable not set")
+	}
+	return filepath.Join(localAppData, "Programs", "MineOS"), nil
+}
+
+func windowsStartMenuDir() (string, error) {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		return "", errors.New("APPDATA environment variable not set")
+	}
+	return filepath.Join(appData, "Microsoft", "Windows", "Start Menu", "Programs", "MineOS"), nil
+}
+
+// installCLIToPath copies this executable to %LOCALAPPDATA%\Programs\MineOS,
+// adds that directory to the user PATH, creates Start Menu shortcuts for the
+// web UI and the terminal interface of the install in the current directory
+// and registers an Apps & Features entry that runs 'uninstall --remove-cli'.
+func installCLIToPath(out io.Writer, webOrigin string) error {
+	installDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	cliDir, err := windowsCLIDir()
+	if err != nil {
+		return err
+	}
+	exePath := filepath.Join(cliDir, "mineos.exe")
+	if err := copyRunningExecutable(exePath); err != nil {
+		return fmt.Errorf("failed to copy the CLI to %s: %w", cliDir, err)
+	}
+	fmt.Fprintf(out, "%s Installed CLI to: %s\n", markOK(), exePath)
+
+	added, err := updateUserPath(cliDir, true)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to PATH: %w", cliDir, err)
+	}
+	if added {
+		broadcastEnvironmentChange()
+		fmt.Fprintln(out, markOK()+" Added to your PATH (open a new terminal to use 'mineos')")
+	}
+
+	if err := createStartMenuShortcuts(exePath, installDir, webOrigin); err != nil {
+		fmt.Fprintf(out, "Warning: failed to create Start Menu shortcuts: %v\n", err)
+	} else {
+		fmt.Fprintln(out, markOK()+" Created Start Menu shortcuts")
+	}
+
+	if err := registerUninstallEntry(exePath, installDir); err != nil {
+		fmt.Fprintf(out, "Warning: failed to register in Apps & Features: %v\n", err)
+	}
+	return nil
+}
+
+// copyRunningExecutable replaces dest with this executable. A running copy
+// at dest cannot be overwritten but can be renamed, so it is moved aside.
+func copyRunningExecutable(dest string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if same, err := filepath.Abs(self); err == nil && strings.EqualFold(same, dest) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	old := dest + ".old"
+	_ = os.Remove(old)
+	if _, err := os.Stat(dest); err == nil {
+		if err := os.Rename(dest, old); err != nil {
+			return err
+		}
+	}
+	if err := copyFile(self, dest); err != nil {
+		_ = os.Rename(old, dest)
+		return err
+	}
+	_ = os.Remove(old)
+	return nil
+}
+
+// updateUserPath adds dir to, or removes it from, the PATH in
+// HKCU\Environment and reports whether the value changed.
+func updateUserPath(dir string, add bool) (bool, error) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, "Environment", registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return false, err
+	}
+	defer key.Close()
+
+	current, valueType, err := key.GetStringValue("Path")
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return false, err
+	}
+	var entries []string
+	found := false
+	for _, entry := range strings.Split(current, ";") {
+		if entry == "" {
+			continue
+		}
+		if strings.EqualFold(strings.TrimRight(entry, `\`), dir) {
+			found = true
+			if !add {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if add == found {
+		return false, nil
+	}
+	if add {
+		entries = append(entries, dir)
+	}
+	value := strings.Join(entries, ";")
+	if valueType == registry.SZ {
+		return true, key.SetStringValue("Path", value)
+	}
+	return true, key.SetExpandStringValue("Path", value)
+}
+
+// broadcastEnvironmentChange tells Explorer and other running programs to
+// re-read the environment, so new terminals see the PATH without a logoff.
+func broadcastEnvironmentChange() {
+	const (
+		hwndBroadcast   = 0xffff
+		wmSettingChange = 0x001a
+		smtoAbortIfHung = 0x0002
+	)
+	environment, err := windows.UTF16PtrFromString("Environment")
+	if err != nil {
+		return
+	}
+	var result uintptr
+	_, _, _ = procSendMessageTimeout.Call(hwndBroadcast, wmSettingChange, 0,
+		uintptr(unsafe.Pointer(environment)), smtoAbortIfHung, 5000, uintptr(unsafe.Pointer(&result)))
+}
+
+// createStartMenuShortcuts writes an Internet shortcut to the web UI and,
+// through the WScript.Shell COM object, a shortcut that opens the TUI in the
+// install directory.
+func createStartMenuShortcuts(exePath, installDir, webOrigin string) error {
+	dir, err := windowsStartMenuDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if webOrigin != "" {
+		content := "[InternetShortcut]\r\nURL=" + webOrigin + "\r\nIconFile=" + exePath + "\r\nIconIndex=0\r\n"
+		if err := os.WriteFile(filepath.Join(dir, "MineOS Web UI.url"), []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	script := fmt.Sprintf(`$s = (New-Object -ComObject WScript.Shell).CreateShortcut(%s)
+$s.TargetPath = %s
+$s.Arguments = %s
+$s.WorkingDirectory = %s
+$s.IconLocation = %s
+$s.Description = 'Manage MineOS from the terminal'
+$s.Save()`,
+		psLiteral(filepath.Join(dir, "MineOS Terminal.lnk")), psLiteral(exePath),
+		psLiteral(fmt.Sprintf(`--env "%s" tui`, filepath.Join(installDir, ".env"))),
+		psLiteral(installDir), psLiteral(exePath))
+	output, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).CombinedOutput()
+	if err != nil {
+		return errors.New(fallback(strings.TrimSpace(string(output)), err.Error()))
+	}
+	return nil
+}
+
+func registerUninstallEntry(exePath, installDir string) error {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, windowsUninstallKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	// uninstall works on the install in the current directory.
+	uninstall := fmt.Sprintf(`cmd.exe /c cd /d "%s" && "%s" uninstall --remove-cli`, installDir, exePath)
+	for name, value := range map[string]string{
+		"DisplayName":     "MineOS",
+		"Publisher":       "MineOS",
+		"DisplayIcon":     exePath,
+		"InstallLocation": installDir,
+		"UninstallString": uninstall,
+		"URLInfoAbout":    "https://mineos.net",
+	} {
+		if err := key.SetStringValue(name, value); err != nil {
+			return err
+		}
+	}
+	if err := key.SetDWordValue("NoModify", 1); err != nil {
+		return err
+	}
+	if err := key.SetDWordValue("NoRepair", 1); err != nil {
+		return err
+	}
+	if info, err := os.Stat(exePath); err == nil {
+		_ = key.SetDWordValue("EstimatedSize", uint32(info.Size()/1024))
+	}
+	return nil
+}
+
+// removeCLIFromPath undoes installCLIToPath. The executable is deleted
+// after exit when it is the one running, e.g. from Apps & Features.
+func removeCLIFromPath(out io.Writer) error {
+	cliDir, err := windowsCLIDir()
+	if err != nil {
+		return err
+	}
+	exePath := filepath.Join(cliDir, "mineos.exe")
+
+	if _, err := os.Stat(exePath); err == nil {
+		if err := os.Remove(exePath); err != nil {
+			if err := scheduleWindowsDelete(exePath, cliDir); err != nil {
+				return fmt.Errorf("failed to remove CLI: %w", err)
+			}
+			fmt.Fprintf(out, "%s CLI scheduled for removal: %s\n", markOK(), exePath)
+		} else {
+			fmt.Fprintf(out, "%s Removed CLI from: %s\n", markOK(), exePath)
+			os.Remove(cliDir)
+		}
+	} else {
+		fmt.Fprintln(out, "CLI not found in system PATH location.")
+	}
+
+	if removed, err := updateUserPath(cliDir, false); err != nil {
+		fmt.Fprintf(out, "Warning: failed to remove %s from PATH: %v\n", cliDir, err)
+	} else if removed {
+		broadcastEnvironmentChange()
+		fmt.Fprintln(out, markOK()+" Removed from your PATH")
+	}
+
+	if dir, err := windowsStartMenuDir(); err == nil {
+		if _, err := os.Stat(dir); err == nil {
+			if err := os.RemoveAll(dir); err != nil {
+				fmt.Fprintf(out, "Warning: failed to remove Start Menu shortcuts: %v\n", err)
+			} else {
+				fmt.Fprintln(out, markOK()+" Removed Start Menu shortcuts")
+			}
+		}
+	}
+
+	if err := registry.DeleteKey(registry.CURRENT_USER, windowsUninstallKey); err == nil {
+		fmt.Fprintln(out, markOK()+" Removed from Apps & Features")
+	} else if !errors.Is(err, registry.ErrNotExist) {
+		fmt.Fprintf(out, "Warning: failed to remove the Apps & Features entry: %v\n", err)
+	}
+	return nil
+}
+
+// scheduleWindowsDelete removes a running executable and its directory
+// once this process has exited.
+func scheduleWindowsDelete(exePath, dir string) error {
+	batchFile := filepath.Join(os.TempDir(), "mineos-remove-cli.bat")
+	script := fmt.Sprintf("@echo off\ntimeout /t 2 /nobreak >nul\ndel /f /q \"%s\"\nrd \"%s\" 2>nul\ndel \"%%~f0\"\n", exePath, dir)
+	if err := os.WriteFile(batchFile, []byte(script), 0o755); err != nil {
+		return err
+	}
+	return exec.Command("cmd", "/C", "start", "/min", batchFile).Start()
+}
+
+func psLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Values of --color.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// colorMode is --color after NO_COLOR is taken into account, set for the
+// whole run by the root command.
+var colorMode = colorAuto
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// colorRequested resolves --color: NO_COLOR (https://no-color.org) turns
+// color off unless the flag asks for it explicitly.
+func colorRequested(flag string) (string, error) {
+	switch flag {
+	case colorAuto:
+		if os.Getenv("NO_COLOR") != "" {
+			return colorNever, nil
+		}
+		return colorAuto, nil
+	case colorAlways, colorNever:
+		return flag, nil
+	}
+	return "", fmt.Errorf("must be auto, always or never, not %q", flag)
+}
+
+// applyColor sets the profile every lipgloss style renders with, the TUI's
+// included. Auto leaves lipgloss to look at stdout, which gives no color
+// when it is not a terminal.
+func applyColor(mode string) {
+	colorMode = mode
+	switch mode {
+	case colorNever:
+		lipgloss.SetColorProfile(termenv.Ascii)
+		// Commands the TUI runs and the tools they start stay plain too.
+		os.Setenv("NO_COLOR", "1")
+	case colorAlways:
+		if profile := termenv.EnvColorProfile(); profile != termenv.Ascii {
+			lipgloss.SetColorProfile(profile)
+		} else {
+			lipgloss.SetColorProfile(termenv.ANSI256)
+		}
+	}
+}
+
+// colorEnabled reports whether styled output comes out in color.
+func colorEnabled() bool {
+	return !machineMode && lipgloss.ColorProfile() != termenv.Ascii
+}
+
+// plainLog strips the escape sequences a server or container wrote into a
+// log line when output is not in color, so a log saved to a file is text.
+func plainLog(line string) string {
+	if colorEnabled() {
+		return line
+	}
+	return ansiEscape.ReplaceAllString(line, "")
+}
+
+// composeANSI is the COMPOSE_ANSI setting that makes Compose's own output
+// follow --color, or "" to let it decide.
+func composeANSI() string {
+	switch colorMode {
+	case colorNever:
+		return "never"
+	case colorAlways:
+		return "always"
+	}
+	return ""
+}
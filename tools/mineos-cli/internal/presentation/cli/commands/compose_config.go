@@ -12,6 +12,12 @@ import (
 func composeWithConfig(base composeRunner, cfg config.Config) composeRunner {
 	result := base
 	result.baseArgs = append([]string{}, base.baseArgs...)
+	if result.platform == "" {
+		result.platform = configuredPlatform(cfg)
+	}
+	if base.remote != nil {
+		return remoteComposeWithConfig(result, cfg)
+	}
 
 	envPath := strings.TrimSpace(cfg.EnvPath)
 	if envPath == "" {
@@ -27,6 +33,14 @@ func composeWithConfig(base composeRunner, cfg config.Config) composeRunner {
 
 	if fileExists(envAbs) {
 		result.baseArgs = append(result.baseArgs, "--env-file", envAbs)
+		// Compose applies repeated --env-file flags in order, so the
+		// overlays keep the precedence the CLI resolved them with.
+		for _, overlay := range cfg.EnvOverlays {
+			if abs, err := filepath.Abs(overlay); err == nil {
+				overlay = abs
+			}
+			result.baseArgs = append(result.baseArgs, "--env-file", overlay)
+		}
 	}
 
 	composeDir := filepath.Dir(envAbs)
@@ -34,15 +48,7 @@ func composeWithConfig(base composeRunner, cfg config.Config) composeRunner {
 		composeDir = ""
 	}
 
-	files := []string{"docker-compose.yml"}
-	if strings.EqualFold(strings.TrimSpace(cfg.NetworkMode), "host") {
-		files = append(files, "docker-compose.host.yml")
-	}
-	if parseBool(cfg.BuildFromSource) {
-		files = append(files, "docker-compose.build.yml")
-	}
-
-	for _, file := range files {
+	for _, file := range composeFiles(cfg) {
 		path := file
 		if composeDir != "" {
 			path = filepath.Join(composeDir, file)
@@ -53,6 +59,55 @@ func composeWithConfig(base composeRunner, cfg config.Config) composeRunner {
 	return result
 }
 
+// remoteComposeWithConfig selects the same files for a stack on the --ssh
+// host. Compose runs in the remote install directory, so paths stay
+// relative, and only the remote env files apply: local overlays (and the
+// tunnel's API_PORT) describe this machine.
+func remoteComposeWithConfig(result composeRunner, cfg config.Config) composeRunner {
+	result.baseArgs = append(result.baseArgs, "--env-file", ".env")
+	for _, overlay := range cfg.EnvOverlays {
+		if overlay == cfg.EnvPath+".local" {
+			result.baseArgs = append(result.baseArgs, "--env-file", ".env.local")
+		}
+	}
+	for _, file := range composeFiles(cfg) {
+		result.baseArgs = append(result.baseArgs, "-f", file)
+	}
+	return result
+}
+
+// configuredPlatform is the image platform set in the environment or .env,
+// which Compose would also pick up itself.
+func configuredPlatform(cfg config.Config) string {
+	if sshRemote == nil {
+		if platform := strings.TrimSpace(os.Getenv(platformEnvKey)); platform != "" {
+			return platform
+		}
+	}
+	values, err := loadLayeredEnvValues(cfg)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(values[platformEnvKey])
+}
+
+func composeFiles(cfg config.Config) []string {
+	files := []string{"docker-compose.yml"}
+	if strings.EqualFold(strings.TrimSpace(cfg.NetworkMode), "host") {
+		files = append(files, "docker-compose.host.yml")
+	}
+	if parseBool(cfg.BuildFromSource) {
+		files = append(files, "docker-compose.build.yml")
+	}
+	if !resourceLimitsFromConfig(cfg).empty() {
+		files = append(files, resourceLimitsFile)
+	}
+	for _, name := range composeOverrides(cfg) {
+		files = append(files, composeOverridePath(name))
+	}
+	return files
+}
+
 func parseBool(value string) bool {
 	parsed, err := strconv.ParseBool(strings.TrimSpace(value))
 	return err == nil && parsed
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/docker"
+)
+
+// composeLogTail is how much of a failed service's output is shown.
+const composeLogTail = 20
+
+// pull pulls the stack's images concurrently with a status line per
+// service. args are extra compose arguments such as -f files. Pulls go
+// through the Engine socket for byte progress; when it is not reachable,
+// or a pull fails there (e.g. a private registry needing the CLI's
+// credentials), the service is pulled with 'docker compose pull <service>'.
+// Images without a build for the target platform stop the pull before it
+// starts.
+func (c composeRunner) pull(ctx context.Context, out io.Writer, args ...string) error {
+	services, err := c.services(args)
+	if err != nil {
+		return c.run(append(append([]string{}, args...), "pull"))
+	}
+	var names []string
+	for _, name := range sortedServiceNames(services) {
+		if services[name].Image != "" {
+			names = append(names, name)
+		}
+	}
+	images := map[string]string{}
+	for _, name := range names {
+		images[name] = services[name].Image
+	}
+	if err := c.checkImagePlatforms(ctx, out, images); err != nil {
+		return err
+	}
+	engine, hasEngine := docker.NewEngine()
+	// The local Engine socket belongs to another host than a remote stack.
+	hasEngine = hasEngine && c.remote == nil
+
+	return c.eachService(out, "pulling", names, func(name string, table *serviceTable) (string, error) {
+		if hasEngine {
+			err := engine.Pull(ctx, services[name].Image, c.platform, func(current, total int64) {
+				table.Progress(name, current, total)
+			})
+			if err == nil {
+				return "", nil
+			}
+			table.Detail(name, "retrying with docker compose")
+		}
+		return c.captured(ctx, append(append([]string{}, args...), "pull", name), nil, func(line string) {
+			table.Detail(name, line)
+		})
+	})
+}
+
+// build builds the services that have a build section concurrently, with
+// the same status table as pull and the tail of the output of any failed
+// build.
+func (c composeRunner) build(ctx context.Context, out io.Writer, env []string, args ...string) error {
+	services, err := c.services(args)
+	if err != nil {
+		return c.runWithEnv(append(append([]string{}, args...), "build"), env)
+	}
+	var names []string
+	for _, name := range sortedServiceNames(services) {
+		if len(services[name].Build) > 0 {
+			names = append(names, name)
+		}
+	}
+	buildArgs := append(append([]string{}, args...), "build")
+	if c.exe == "docker" {
+		buildArgs = append(buildArgs, "--progress", "plain")
+	}
+
+	return c.eachService(out, "building", names, func(name string, table *serviceTable) (string, error) {
+		return c.captured(ctx, append(append([]string{}, buildArgs...), name), env, func(line string) {
+			table.Detail(name, line)
+		})
+	})
+}
+
+// eachService runs work for every service at once and then prints the
+// captured output of the ones that failed.
+func (c composeRunner) eachService(out io.Writer, verb string, names []string, work func(string, *serviceTable) (string, error)) error {
+	if len(names) == 0 {
+		return nil
+	}
+	table := newServiceTable(out, verb, names)
+	logs := make([]string, len(names))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			table.Start(name)
+			logs[i], errs[i] = work(name, table)
+			table.Finish(name, errs[i])
+		}()
+	}
+	wg.Wait()
+
+	var failed []error
+	for i, name := range names {
+		if errs[i] == nil {
+			continue
+		}
+		failed = append(failed, fmt.Errorf("%s: %w", name, errs[i]))
+		if logs[i] != "" {
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, styleError.Render("Output of "+name+":"))
+			fmt.Fprintln(out, logs[i])
+		}
+	}
+	return errors.Join(failed...)
+}
+
+// services reads the resolved compose project.
+func (c composeRunner) services(args []string) (map[string]composeService, error) {
+	data, err := c.output(append(append([]string{}, args...), "config", "--format", "json"))
+	if err != nil {
+		return nil, err
+	}
+	var project struct {
+		Services map[string]composeService `json:"services"`
+	}
+	if err := json.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("parse compose config: %w", err)
+	}
+	return project.Services, nil
+}
+
+func sortedServiceNames(services map[string]composeService) []string {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// captured runs a compose command, passing each output line to onLine and
+// returning the last composeLogTail lines for error reports.
+func (c composeRunner) captured(ctx context.Context, args []string, env []string, onLine func(string)) (string, error) {
+	cmd := c.command(ctx, args, env)
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	var tail []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			onLine(line)
+			tail = append(tail, line)
+			if len(tail) > composeLogTail {
+				tail = tail[1:]
+			}
+		}
+		// Keep draining so the process never blocks on a full pipe.
+		_, _ = io.Copy(io.Discard, reader)
+	}()
+	err := cmd.Wait()
+	writer.Close()
+	<-done
+	return strings.Join(tail, "\n"), err
+}
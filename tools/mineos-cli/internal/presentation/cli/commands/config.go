@@ -23,6 +23,8 @@ func NewConfigCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 
 	cmd.AddCommand(showCmd)
 	cmd.AddCommand(newConfigSetUpdateChannelCommand(loadConfig))
+	cmd.AddCommand(newConfigDiffCommand(loadConfig))
+	cmd.AddCommand(newConfigApplyCommand(loadConfig))
 
 	return cmd
 }
@@ -39,6 +41,9 @@ func newConfigShowCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command
 			}
 
 			fmt.Printf("Env file: %s\n", cfg.EnvPath)
+			for _, overlay := range cfg.EnvOverlays {
+				fmt.Printf("Env overlay: %s\n", overlay)
+			}
 			fmt.Printf("API port: %s\n", fallback(cfg.ApiPort, "5078"))
 			fmt.Printf("Web origin: %s\n", fallback(cfg.WebOrigin, "http://localhost:3000"))
 			fmt.Printf("Network mode: %s\n", fallback(cfg.NetworkMode, "bridge"))
@@ -65,7 +70,9 @@ func newConfigShowCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command
 }
 
 func newConfigSetUpdateChannelCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
-	return &cobra.Command{
+	var jsonOut bool
+
+	cmd := &cobra.Command{
 		Use:   "set-update-channel {stable|prerelease}",
 		Short: "Set the CLI update channel (stable or pre-release)",
 		Long: `Set the update channel for CLI updates.
@@ -106,23 +113,36 @@ Examples:
 				envMap = make(map[string]string)
 			}
 
-			envMap["MINEOS_CLI_PRERELEASE_UPDATES"] = value
-
-			if err := godotenv.Write(envMap, cfg.EnvPath); err != nil {
-				return fmt.Errorf("failed to write .env: %w", err)
-			}
-
 			channelName := "stable"
 			if value == "true" {
 				channelName = "pre-release"
 			}
 
-			fmt.Printf("✓ Update channel set to: %s\n", channelName)
+			changed := envMap["MINEOS_CLI_PRERELEASE_UPDATES"] != value
+			if changed {
+				envMap["MINEOS_CLI_PRERELEASE_UPDATES"] = value
+				if err := godotenv.Write(envMap, cfg.EnvPath); err != nil {
+					return fmt.Errorf("failed to write .env: %w", err)
+				}
+			}
+
+			if jsonOut {
+				return printChangeResult(cmd, changeResult{Changed: changed, Msg: "update channel: " + channelName})
+			}
+			if !changed {
+				fmt.Printf("Update channel is already %s\n", channelName)
+				return nil
+			}
+			fmt.Printf("%s Update channel set to: %s\n", markOK(), channelName)
 			fmt.Println("\nRun 'mineos upgrade' to check for updates on this channel.")
 
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print a changed/failed result as JSON")
+
+	return cmd
 }
 
 func fallback(value, fallbackValue string) string {
@@ -0,0 +1,294 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+// runtimeSettings maps API container variables to the settings the API
+// reads at runtime (SettingsService.Keys), so they apply without a restart.
+var runtimeSettings = map[string]string{
+	"MINEOS_TELEMETRY_ENABLED":   "MineOS:TelemetryEnabled",
+	"MINEOS_TELEMETRY_KEY":       "MineOS:TelemetryKey",
+	"MINEOS_SHUTDOWN_TIMEOUT":    "MineOS:ShutdownTimeoutSeconds",
+	"CurseForge__ApiKey":         "CurseForge:ApiKey",
+	"Discord__WebhookUrl":        "Discord:WebhookUrl",
+	"Logging__LogLevel__Default": "MineOS:LogLevel",
+}
+
+// configApplyItem is one difference between .env and the running stack.
+// Key is a container variable, or "image", "ports" or "volumes".
+type configApplyItem struct {
+	Service string `json:"service"`
+	Key     string `json:"key"`
+	Setting string `json:"setting,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type configApplyReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Applied []configApplyItem `json:"applied"`
+	Restart []configApplyItem `json:"restart_required"`
+}
+
+func newConfigApplyCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var dryRun bool
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Push runtime settings from .env to the API without a restart",
+		Long: `Compares .env with the running containers. Settings the API reads at
+runtime (telemetry, shutdown timeout, CurseForge key, Discord webhook, log
+level) are pushed through its settings endpoints; everything else that
+changed is listed as requiring a restart ('mineos stack up').`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			report, err := applyConfigChanges(cmd.Context(), loadConfig, dryRun)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			}
+			printConfigApplyReport(cmd.OutOrStdout(), report)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be applied without calling the API")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+// applyConfigChanges pushes the runtime-tunable changes and reports the rest.
+// When the settings API refuses the CLI's API key (it is admin-only), the
+// tunable keys are reported as requiring a restart instead.
+func applyConfigChanges(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, dryRun bool) (configApplyReport, error) {
+	report := configApplyReport{DryRun: dryRun, Applied: []configApplyItem{}, Restart: []configApplyItem{}}
+	compose, cfg, err := loadComposeAndConfig(ctx, loadConfig)
+	if err != nil {
+		return report, err
+	}
+	drift, err := stackConfigDrift(compose)
+	if err != nil {
+		return report, err
+	}
+
+	values, err := loadLayeredEnvValues(cfg)
+	if err != nil {
+		return report, err
+	}
+	var tunable []configApplyItem
+	for _, item := range drift {
+		if setting, ok := runtimeSettings[item.Key]; ok && item.Service == "api" {
+			item.Setting = setting
+			tunable = append(tunable, item)
+			continue
+		}
+		report.Restart = append(report.Restart, item)
+	}
+	if dryRun {
+		report.Applied = append(report.Applied, tunable...)
+		return report, nil
+	}
+
+	client := api.NewClientFromConfig(cfg)
+	for i, item := range tunable {
+		err := client.UpdateSetting(ctx, item.Setting, settingValue(item.Key, values))
+		if errors.Is(err, api.ErrApiKeyMissing) || errors.Is(err, api.ErrApiKeyInvalid) {
+			for _, rest := range tunable[i:] {
+				rest.Reason = "settings API requires an admin login"
+				report.Restart = append(report.Restart, rest)
+			}
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Applied = append(report.Applied, item)
+	}
+	return report, nil
+}
+
+// settingValue is what compose would pass for key, applying the defaults
+// from docker-compose.yml.
+func settingValue(key string, values map[string]string) string {
+	value := strings.TrimSpace(values[key])
+	if value != "" {
+		return value
+	}
+	switch key {
+	case "MINEOS_TELEMETRY_ENABLED":
+		return "true"
+	case "MINEOS_SHUTDOWN_TIMEOUT":
+		return strconv.Itoa(defaultShutdownTimeout)
+	case "Logging__LogLevel__Default":
+		return "Information"
+	}
+	return ""
+}
+
+type composeService struct {
+	ContainerName string             `json:"container_name"`
+	Image         string             `json:"image"`
+	Build         json.RawMessage    `json:"build"`
+	Environment   map[string]*string `json:"environment"`
+	Ports         []struct {
+		Target    int    `json:"target"`
+		Published string `json:"published"`
+		Protocol  string `json:"protocol"`
+	} `json:"ports"`
+	Volumes []struct {
+		Source string `json:"source"`
+		Target string `json:"target"`
+	} `json:"volumes"`
+}
+
+type inspectedContainer struct {
+	Name   string `json:"Name"`
+	Config struct {
+		Env   []string `json:"Env"`
+		Image string   `json:"Image"`
+	} `json:"Config"`
+	HostConfig struct {
+		PortBindings map[string][]struct {
+			HostPort string `json:"HostPort"`
+		} `json:"PortBindings"`
+	} `json:"HostConfig"`
+	Mounts []struct {
+		Source      string `json:"Source"`
+		Destination string `json:"Destination"`
+	} `json:"Mounts"`
+}
+
+// stackConfigDrift compares the stack compose would create from .env with
+// the running containers. Values are never reported, only keys.
+func stackConfigDrift(compose composeRunner) ([]configApplyItem, error) {
+	services, err := compose.services(nil)
+	if err != nil {
+		return nil, fmt.Errorf("render compose config: %w", err)
+	}
+
+	var drift []configApplyItem
+	for _, name := range sortedServiceNames(services) {
+		service := services[name]
+		container, err := inspectContainer(fallback(service.ContainerName, name))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w (start the stack with 'mineos stack up')", name, err)
+		}
+		drift = append(drift, serviceDrift(name, service, container)...)
+	}
+	return drift, nil
+}
+
+func inspectContainer(name string) (inspectedContainer, error) {
+	out, err := exec.Command("docker", "inspect", "--type", "container", name).Output()
+	if err != nil {
+		return inspectedContainer{}, fmt.Errorf("container %s not found", name)
+	}
+	var containers []inspectedContainer
+	if err := json.Unmarshal(out, &containers); err != nil || len(containers) == 0 {
+		return inspectedContainer{}, fmt.Errorf("inspect container %s: unexpected output", name)
+	}
+	return containers[0], nil
+}
+
+func serviceDrift(name string, service composeService, container inspectedContainer) []configApplyItem {
+	var drift []configApplyItem
+	if service.Image != "" && service.Image != container.Config.Image {
+		drift = append(drift, configApplyItem{Service: name, Key: "image"})
+	}
+
+	running := map[string]string{}
+	for _, entry := range container.Config.Env {
+		key, value, _ := strings.Cut(entry, "=")
+		running[key] = value
+	}
+	var keys []string
+	for key, value := range service.Environment {
+		// A null value passes the variable through from the compose process.
+		if value != nil && *value != running[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		drift = append(drift, configApplyItem{Service: name, Key: key})
+	}
+
+	var desiredPorts, runningPorts []string
+	for _, port := range service.Ports {
+		desiredPorts = append(desiredPorts, fmt.Sprintf("%d/%s:%s", port.Target, fallback(port.Protocol, "tcp"), port.Published))
+	}
+	for port, bindings := range container.HostConfig.PortBindings {
+		for _, binding := range bindings {
+			runningPorts = append(runningPorts, port+":"+binding.HostPort)
+		}
+	}
+	if !sameStrings(desiredPorts, runningPorts) {
+		drift = append(drift, configApplyItem{Service: name, Key: "ports"})
+	}
+
+	var desiredMounts, runningMounts []string
+	for _, volume := range service.Volumes {
+		desiredMounts = append(desiredMounts, volume.Target+"="+volume.Source)
+	}
+	for _, mount := range container.Mounts {
+		runningMounts = append(runningMounts, mount.Destination+"="+mount.Source)
+	}
+	if !sameStrings(desiredMounts, runningMounts) {
+		drift = append(drift, configApplyItem{Service: name, Key: "volumes"})
+	}
+	return drift
+}
+
+func sameStrings(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	sort.Strings(a)
+	sort.Strings(b)
+	return slices.Equal(a, b)
+}
+
+func printConfigApplyReport(out io.Writer, report configApplyReport) {
+	if len(report.Applied) == 0 && len(report.Restart) == 0 {
+		fmt.Fprintln(out, styleSuccess.Render("The running stack matches .env."))
+		return
+	}
+	if len(report.Applied) > 0 {
+		title := "Applied without restart:"
+		if report.DryRun {
+			title = "Would apply without restart:"
+		}
+		fmt.Fprintln(out, styleTitle.Render(title))
+		for _, item := range report.Applied {
+			fmt.Fprintf(out, "  %s -> %s\n", item.Key, item.Setting)
+		}
+	}
+	if len(report.Restart) > 0 {
+		fmt.Fprintln(out, styleWarning.Render("Restart required:"))
+		for _, item := range report.Restart {
+			line := fmt.Sprintf("  %s: %s", item.Service, item.Key)
+			if item.Reason != "" {
+				line += styleDim.Render(" (" + item.Reason + ")")
+			}
+			fmt.Fprintln(out, line)
+		}
+		fmt.Fprintln(out, styleDim.Render("Run 'mineos stack up' to recreate the changed containers."))
+	}
+}
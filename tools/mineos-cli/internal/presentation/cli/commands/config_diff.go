@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/fuzzy"
+)
+
+// envKeyDocs explains the keys the installer writes. Keys marked perInstall
+// are generated or chosen during install, so their values are not compared;
+// secret values are masked in the output.
+var envKeyDocs = map[string]struct {
+	doc        string
+	perInstall bool
+	secret     bool
+}{
+	envVersionKey:                             {doc: "Layout version; 'mineos env migrate' upgrades older files"},
+	"DB_TYPE":                                 {doc: "Database engine used by the API"},
+	"ConnectionStrings__DefaultConnection":    {doc: "Database location; SQLite files must live under /app/data (the Data__Directory mount)", secret: true},
+	"Auth__SeedUsername":                      {doc: "Admin account created on first start", perInstall: true},
+	"Auth__SeedPassword":                      {doc: "Password for the seeded admin account", perInstall: true, secret: true},
+	"Auth__JwtSecret":                         {doc: "Signing key for web sessions; changing it logs everyone out", perInstall: true, secret: true},
+	"Auth__JwtIssuer":                         {doc: "Issuer claim of web session tokens"},
+	"Auth__JwtAudience":                       {doc: "Audience claim of web session tokens"},
+	"Auth__JwtExpiryMinutes":                  {doc: "How long a web login stays valid"},
+	"ApiKey__SeedKey":                         {doc: "API key created on first start", perInstall: true, secret: true},
+	"MINEOS_API_KEY":                          {doc: "API key the CLI sends; normally the same as ApiKey__SeedKey", perInstall: true, secret: true},
+	"HOST_BASE_DIRECTORY":                     {doc: "Host directory holding servers, profiles and backups"},
+	"Host__BaseDirectory":                     {doc: "Path inside the API container; must stay " + containerBaseDir + ", the host path goes in HOST_BASE_DIRECTORY"},
+	"Data__Directory":                         {doc: "Host directory mounted at /app/data for the database"},
+	"Host__ServersPathSegment":                {doc: "Subdirectory of HOST_BASE_DIRECTORY for servers"},
+	"Host__ProfilesPathSegment":               {doc: "Subdirectory for downloaded server jars"},
+	"Host__BackupsPathSegment":                {doc: "Subdirectory for backups"},
+	"Host__ArchivesPathSegment":               {doc: "Subdirectory for archives"},
+	"Host__ImportsPathSegment":                {doc: "Subdirectory for imports"},
+	"Host__OwnerUid":                          {doc: "Owner uid of files the API writes on the host"},
+	"Host__OwnerGid":                          {doc: "Owner gid of files the API writes on the host"},
+	"MINEOS_NETWORK_MODE":                     {doc: "bridge (isolated) or host (needed for LAN discovery)"},
+	"MINEOS_BUILD_FROM_SOURCE":                {doc: "Build images locally instead of pulling them"},
+	"MINEOS_IMAGE_TAG":                        {doc: "Image tag to pull: latest, preview or a pinned version"},
+	"API_PORT":                                {doc: "Host port of the API"},
+	"WEB_PORT":                                {doc: "Host port of the web UI"},
+	"MINEOS_BIND_ADDRESS":                     {doc: "Prefix for published ports: empty = IPv4 and IPv6, 0.0.0.0: = IPv4 only, [::]: = IPv6 only"},
+	apiMemoryLimitKey:                         {doc: "Memory limit of the api container, which the Minecraft servers run in (docker-compose.limits.yml)"},
+	apiCpuLimitKey:                            {doc: "CPU limit of the api container and its Minecraft servers"},
+	webMemoryLimitKey:                         {doc: "Memory limit of the web container"},
+	webCpuLimitKey:                            {doc: "CPU limit of the web container"},
+	composeOverridesKey:                       {doc: "Compose files in " + composeOverridesDir + "/ included in every stack command ('mineos stack override')"},
+	"WEB_ORIGIN_PROD":                         {doc: "URL the web UI is served from; used for CORS"},
+	"PUBLIC_API_BASE_URL":                     {doc: "URL browsers use to reach the API; normally WEB_ORIGIN_PROD"},
+	"ORIGIN":                                  {doc: "Origin the web server accepts form posts from; normally WEB_ORIGIN_PROD"},
+	"CADDY_SITE":                              {doc: "Site address for the bundled reverse proxy, derived from WEB_ORIGIN_PROD"},
+	"PUBLIC_MINECRAFT_HOST":                   {doc: "Address players connect to, shown in the web UI"},
+	"BODY_SIZE_LIMIT":                         {doc: "Largest upload the web UI accepts"},
+	"Logging__LogLevel__Default":              {doc: "API log level"},
+	"Logging__LogLevel__Microsoft.AspNetCore": {doc: "Log level of the ASP.NET framework"},
+	"MINEOS_TELEMETRY_ENABLED":                {doc: "Anonymous usage statistics"},
+	"MINEOS_TELEMETRY_ENDPOINT":               {doc: "Where telemetry is sent"},
+	"MINEOS_INSTALLATION_ID":                  {doc: "Random id identifying this installation in telemetry", perInstall: true},
+	"MINEOS_SHUTDOWN_TIMEOUT":                 {doc: "Seconds servers get to stop before containers are removed"},
+	"MINEOS_CLI_PRERELEASE_UPDATES":           {doc: "Offer pre-release CLI versions in 'mineos upgrade'"},
+}
+
+// envOptionalKeys are read by MineOS but not written by the installer.
+var envOptionalKeys = map[string]string{
+	"CurseForge__ApiKey":     "CurseForge integration (also configurable in the web UI)",
+	"Discord__WebhookUrl":    "Discord notifications",
+	"DISCORD_BOT_TOKEN":      "Bot token for 'mineos discord-bot --register'",
+	"DISCORD_PUBLIC_KEY":     "Public key 'mineos discord-bot' verifies interactions with",
+	"DISCORD_APPLICATION_ID": "Application 'mineos discord-bot' registers its commands for",
+	"WEB_ORIGIN_DEV":         "Extra CORS origin for the development web server",
+	"MC_PORT_RANGE":          "Java server ports published by the API container",
+	"BEDROCK_PORT_RANGE":     "Bedrock server ports published by the API container",
+	"MINEOS_TELEMETRY_KEY":   "Telemetry authentication key",
+	"ApiKey__StaticKey":      "Fixed API key accepted in addition to the database keys",
+	"PUBLIC_BUILD_ID":        "Build id shown in the web UI for source builds",
+	"MINEOS_CONFIRM_LEVEL":   "name (type the server name) or yes (y/n) before deleting or restoring a server",
+	TrashRetentionEnv:        "Days deleted servers stay in the trash (0 keeps them until emptied)",
+	DockerWaitEnv:            "Seconds compose commands wait for the Docker daemon to start (0 fails at once)",
+	persistServiceLogsEnv:    "true for 'mineos agent' to keep container logs in logs/ (also --persist-logs)",
+	platformEnvKey:           "Image platform to pull and build, e.g. linux/amd64 (set by --platform)",
+	RateLimitEnv:             "Cap on the API's profile, mod and modpack downloads together, e.g. 5M",
+}
+
+// envDeprecatedKeys are replaced by 'mineos env migrate'.
+var envDeprecatedKeys = map[string]string{
+	"Auth__JwtExpiryHours": "replaced by Auth__JwtExpiryMinutes",
+	"Host__Owner":          "split into Host__OwnerUid and Host__OwnerGid",
+}
+
+type envDiffEntry struct {
+	Key     string `json:"key"`
+	Value   string `json:"value,omitempty"`
+	Default string `json:"default,omitempty"`
+	Comment string `json:"comment"`
+}
+
+type envDiff struct {
+	Missing    []envDiffEntry `json:"missing"`
+	NonDefault []envDiffEntry `json:"non_default"`
+	Extra      []envDiffEntry `json:"extra"`
+}
+
+// envTemplate renders the .env the installer writes when every question is
+// answered with its default, plus the defaults upgrades add.
+func envTemplate() *envFile {
+	webOrigin := fmt.Sprintf("http://localhost:%d", defaultWebPort)
+	template := parseEnvFile([]byte(renderEnv(envConfig{
+		hostBaseDir:      defaultHostBaseDir,
+		dataDir:          defaultDataDir,
+		networkMode:      defaultNetworkMode,
+		imageTag:         "latest",
+		apiPort:          defaultApiPort,
+		webPort:          defaultWebPort,
+		webOrigin:        webOrigin,
+		caddySite:        deriveCaddySite(webOrigin),
+		minecraftHost:    "localhost",
+		bodySizeLimit:    defaultBodySizeLimit,
+		telemetryEnabled: true,
+	})))
+	for _, d := range requiredEnvDefaults {
+		if _, ok := template.get(d.key); !ok {
+			template.set(d.key, d.value)
+		}
+	}
+	return template
+}
+
+func diffEnvFile(current *envFile) envDiff {
+	template := envTemplate()
+	diff := envDiff{Missing: []envDiffEntry{}, NonDefault: []envDiffEntry{}, Extra: []envDiffEntry{}}
+	for _, key := range template.keys() {
+		info := envKeyDocs[key]
+		want, _ := template.get(key)
+		value, ok := current.get(key)
+		switch {
+		case !ok:
+			entry := envDiffEntry{Key: key, Comment: info.doc}
+			if !info.perInstall {
+				entry.Default = want
+			}
+			diff.Missing = append(diff.Missing, entry)
+		case !info.perInstall && value != want:
+			if info.secret {
+				value = mask(value)
+			}
+			diff.NonDefault = append(diff.NonDefault, envDiffEntry{Key: key, Value: value, Default: want, Comment: info.doc})
+		}
+	}
+
+	known := map[string]bool{}
+	for _, key := range template.keys() {
+		known[key] = true
+	}
+	for _, key := range current.keys() {
+		if known[key] {
+			continue
+		}
+		// Values of unknown keys may be secrets, so only the key is reported.
+		entry := envDiffEntry{Key: key}
+		if comment, ok := envOptionalKeys[key]; ok {
+			entry.Comment = "optional: " + comment
+		} else if comment, ok := envDeprecatedKeys[key]; ok {
+			entry.Comment = "deprecated, " + comment + "; run 'mineos env migrate'"
+		} else if suggestion := closestEnvKey(key, known); suggestion != "" {
+			entry.Comment = "not used by MineOS; did you mean " + suggestion + "?"
+		} else {
+			entry.Comment = "not used by MineOS"
+		}
+		diff.Extra = append(diff.Extra, entry)
+	}
+	return diff
+}
+
+// closestEnvKey suggests a known key for a likely typo, or "".
+func closestEnvKey(key string, known map[string]bool) string {
+	best, bestDistance := "", 3
+	for candidate := range known {
+		if d := fuzzy.Distance(strings.ToLower(key), strings.ToLower(candidate)); d < bestDistance || (d == bestDistance && best != "" && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+func newConfigDiffCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare .env with the installer's template",
+		Long: `Compare .env with the file 'mineos install' writes for this version,
+listing keys that are missing, set to a non-default value, or not used by
+MineOS at all (often typos or leftovers from manual edits).
+
+Values generated during install, such as passwords and API keys, are only
+checked for presence.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig.Execute(cmd.Context())
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(cfg.EnvPath)
+			if err != nil {
+				return err
+			}
+			diff := diffEnvFile(parseEnvFile(data))
+			if jsonOut {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(diff)
+			}
+			printEnvDiff(cmd.OutOrStdout(), cfg.EnvPath, diff)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the differences as JSON")
+
+	return cmd
+}
+
+func printEnvDiff(out io.Writer, path string, diff envDiff) {
+	fmt.Fprintf(out, "%s\n", styleTitle.Render(path+" compared with the install template (layout version "+strconv.Itoa(latestEnvVersion())+")"))
+	if len(diff.Missing)+len(diff.NonDefault)+len(diff.Extra) == 0 {
+		fmt.Fprintf(out, "\n%s\n", styleSuccess.Render("Every key matches the template."))
+		return
+	}
+
+	section := func(title, symbol string, entries []envDiffEntry, value func(envDiffEntry) string) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(out, "\n%s\n", styleLabel.Render(fmt.Sprintf("%s (%d)", title, len(entries))))
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, entry := range entries {
+			fmt.Fprintf(w, "  %s %s\t%s\t%s\n", symbol, entry.Key, value(entry), styleDim.Render(entry.Comment))
+		}
+		w.Flush()
+	}
+
+	section("Missing", styleWarning.Render("-"), diff.Missing, func(entry envDiffEntry) string {
+		if entry.Default == "" {
+			return "(generated at install)"
+		}
+		return "default " + entry.Default
+	})
+	section("Non-default", styleInfo.Render("~"), diff.NonDefault, func(entry envDiffEntry) string {
+		return fmt.Sprintf("%s (default %s)", fallback(entry.Value, `""`), fallback(entry.Default, `""`))
+	})
+	section("Extra", styleWarning.Render("+"), diff.Extra, func(envDiffEntry) string { return "" })
+}
@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/confirm"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/presentation/cli/i18n"
+)
+
+// ConfirmTokenEnv turns on two-person confirmation for destructive commands.
+const ConfirmTokenEnv = "MINEOS_REQUIRE_CONFIRM_TOKEN"
+
+// Operations that need a confirmation token when the policy is on.
+const (
+	confirmUninstall   = "uninstall"
+	confirmDownVolumes = "down-volumes"
+	confirmWorldRepair = "world-repair"
+	confirmResetAdmin  = "reset-admin"
+)
+
+var confirmOperations = []string{confirmUninstall, confirmDownVolumes, confirmWorldRepair, confirmResetAdmin}
+
+func NewConfirmCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "confirm",
+		Short: "Issue tokens that approve another admin's destructive command",
+		Long: `With ` + ConfirmTokenEnv + `=true in .env, destructive commands need a
+one-time token issued by a different admin:
+
+  uninstall      mineos uninstall (modes that delete data)
+  down-volumes   mineos down --volumes, mineos stack down --volumes
+  world-repair   mineos world check --repair
+  reset-admin    mineos users reset-admin
+
+The second admin runs 'mineos confirm issue <operation>' on the same
+installation and hands over the token, which is passed with --confirm-token.
+Tokens work once, expire, and are never accepted from the admin who issued
+them. They guard against accidents and unilateral changes between people
+sharing an install; anyone who can edit .env can still turn the policy off.`,
+	}
+
+	cmd.AddCommand(newConfirmIssueCommand(loadConfig))
+	cmd.AddCommand(newConfirmListCommand(loadConfig))
+
+	return cmd
+}
+
+func newConfirmIssueCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var ttl time.Duration
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:       "issue <operation>",
+		Short:     "Issue a one-time confirmation token",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: confirmOperations,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			operation := args[0]
+			if !slices.Contains(confirmOperations, operation) {
+				return fmt.Errorf("unknown operation %q (use %s)", operation, strings.Join(confirmOperations, ", "))
+			}
+			if ttl <= 0 {
+				return fmt.Errorf("--ttl must be positive")
+			}
+			cfg, err := loadConfig.Execute(cmd.Context())
+			if err != nil {
+				return err
+			}
+			issuer := confirm.CurrentUser()
+			code, token, err := confirmStore(cfg).Issue(operation, issuer, ttl)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if jsonOut {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(map[string]any{
+					"token":      code,
+					"operation":  token.Operation,
+					"issued_by":  token.IssuedBy,
+					"expires_at": token.ExpiresAt,
+				})
+			}
+			fmt.Fprintf(out, "Token for %s: %s\n", styleTitle.Render(operation), styleSuccess.Render(code))
+			fmt.Fprintln(out, styleDim.Render(fmt.Sprintf("Issued by %s, valid once until %s.", issuer, token.ExpiresAt.Local().Format("2006-01-02 15:04"))))
+			if !confirmTokenRequired(cfg) {
+				fmt.Fprintf(out, "%s %s is not enabled, so commands won't ask for it.\n", styleWarning.Render("Note:"), ConfirmTokenEnv)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&ttl, "ttl", 15*time.Minute, "How long the token stays valid")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+func newConfirmListCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List unused confirmation tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig.Execute(cmd.Context())
+			if err != nil {
+				return err
+			}
+			tokens, err := confirmStore(cfg).List()
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if len(tokens) == 0 {
+				fmt.Fprintln(out, "No unused confirmation tokens.")
+				return nil
+			}
+			for _, token := range tokens {
+				fmt.Fprintf(out, "  %-14s issued by %s, expires %s\n", token.Operation, token.IssuedBy, token.ExpiresAt.Local().Format("2006-01-02 15:04"))
+			}
+			return nil
+		},
+	}
+}
+
+func confirmStore(cfg config.Config) *confirm.Store {
+	return confirm.NewStore(state.FileForEnv(resolveEnvPath(cfg.EnvPath), confirm.File))
+}
+
+// confirmTokenRequired reports whether the two-person policy is on, in the
+// environment or in the layered .env files.
+func confirmTokenRequired(cfg config.Config) bool {
+	if strings.EqualFold(strings.TrimSpace(os.Getenv(ConfirmTokenEnv)), "true") {
+		return true
+	}
+	values, err := loadLayeredEnvValues(cfg)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(values[ConfirmTokenEnv]), "true")
+}
+
+// requireConfirmToken enforces the two-person policy for operation. It is a
+// no-op when the policy is off.
+func requireConfirmToken(cfg config.Config, operation, code string, out io.Writer) error {
+	if !confirmTokenRequired(cfg) {
+		return nil
+	}
+	if strings.TrimSpace(code) == "" {
+		return fmt.Errorf("%s requires a confirmation token from another admin (%s is set): ask them to run 'mineos confirm issue %s' and pass it with --confirm-token", operation, ConfirmTokenEnv, operation)
+	}
+	token, err := confirmStore(cfg).Consume(operation, code, confirm.CurrentUser())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, styleDim.Render("Confirmed by "+token.IssuedBy+"."))
+	return nil
+}
+
+// confirmConfig is the config for the policy check of commands that also
+// run without a readable .env (uninstall, world check --dir).
+func confirmConfig(ctx context.Context, loadConfig *usecases.LoadConfigUseCase) config.Config {
+	if loadConfig != nil {
+		if cfg, err := loadConfig.Execute(ctx); err == nil {
+			return cfg
+		}
+	}
+	return config.Config{EnvPath: ".env"}
+}
+
+func addConfirmTokenFlag(cmd *cobra.Command, code *string) {
+	cmd.Flags().StringVar(code, "confirm-token", "", "One-time token from 'mineos confirm issue' (when "+ConfirmTokenEnv+" is on)")
+}
+
+// ConfirmLevelEnv chooses how deleting or restoring a server is confirmed:
+// name (the default) has the server's name typed back, yes asks y/n.
+const ConfirmLevelEnv = "MINEOS_CONFIRM_LEVEL"
+
+// confirmServerOperation asks before an operation on server that cannot be
+// undone. Typing the name catches what a reflexive "y" does not: the
+// command run against the wrong server.
+func confirmServerOperation(cfg config.Config, out io.Writer, server, operation, warning string) error {
+	fmt.Fprintln(out, styleWarning.Render(warning))
+	if !cfg.ConfirmsByName() {
+		ok, err := promptYesNo(nil, out, i18n.T("confirm.continue"), false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New(i18n.T("confirm.cancelled", operation))
+		}
+		return nil
+	}
+	if machineMode {
+		return errMachinePrompt
+	}
+	fmt.Fprintf(out, "%s ", styleLabel.Render(i18n.T("confirm.typeName", server)))
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return errors.New(i18n.T("confirm.cancelled", operation))
+	}
+	if typed := strings.TrimSpace(scanner.Text()); typed != server {
+		return errors.New(i18n.T("confirm.wrongName", operation, typed))
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/controldb"
+)
+
+func NewDbCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Maintain the API's database",
+		Long: `Maintain the database the API keeps its users, servers' settings, keys and
+history in: mineos.db in the data directory (Data__Directory), read and
+written from the host so nothing has to be run inside the container.
+
+The commands are safe while the API runs; vacuum holds off its writes until
+it is done. Run them on the MineOS host, not over --ssh.`,
+	}
+
+	cmd.AddCommand(newDbBackupCommand(loadConfig))
+	cmd.AddCommand(newDbVacuumCommand(loadConfig))
+	cmd.AddCommand(newDbIntegrityCheckCommand(loadConfig))
+	cmd.AddCommand(newDbMigrateStatusCommand(loadConfig))
+	return cmd
+}
+
+// openControlDb opens the database the .env configures.
+func openControlDb(ctx context.Context, loadConfig *usecases.LoadConfigUseCase) (*controldb.DB, string, error) {
+	if sshRemote != nil {
+		return nil, "", errors.New("mineos db works on the database file; run it on the MineOS host instead of over --ssh")
+	}
+	cfg, err := loadConfig.Execute(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if !isSqliteConfig(cfg) {
+		return nil, "", fmt.Errorf("DB_TYPE=%s is not supported: the API only runs on sqlite", cfg.DatabaseType)
+	}
+	dataDir := resolveDataDir(cfg, resolveEnvPath(cfg.EnvPath))
+	path, err := resolveSqliteDbPath(cfg, dataDir)
+	if err != nil {
+		return nil, "", err
+	}
+	db, err := controldb.Open(path)
+	return db, dataDir, err
+}
+
+func newDbBackupCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Write a consistent copy of the database",
+		Long: `Write a consistent, compacted copy of the database while the API keeps
+running, to db-backups/mineos-<time>.db in the data directory unless
+--output names the file. Restore it by stopping the stack and copying it
+over mineos.db.`,
+		Example: `  mineos db backup
+  mineos db backup --output /mnt/nas/mineos.db`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			out := cmd.OutOrStdout()
+			db, dataDir, err := openControlDb(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			dest := output
+			if dest == "" {
+				dest = filepath.Join(dataDir, "db-backups", "mineos-"+time.Now().Format("20060102-150405")+".db")
+			}
+
+			t := startTask(out, "Back up "+db.Path())
+			if err := db.Backup(ctx, dest); err != nil {
+				t.Fail()
+				return err
+			}
+			t.Done(dest)
+			if quietMode {
+				fmt.Fprintln(out, dest)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write (default: db-backups/ in the data directory)")
+	return cmd
+}
+
+func newDbVacuumCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "vacuum",
+		Short: "Compact the database file",
+		Long: `Rebuild the database to give the space of deleted rows (old metrics,
+notifications and logs) back to the file system. The API's writes wait
+until it is done, which takes seconds for most installations.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			db, _, err := openControlDb(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			before, err := db.Size()
+			if err != nil {
+				return err
+			}
+
+			t := startTask(cmd.OutOrStdout(), "Vacuum "+db.Path())
+			if err := db.Vacuum(ctx); err != nil {
+				t.Fail()
+				return err
+			}
+			after, err := db.Size()
+			if err != nil {
+				t.Fail()
+				return err
+			}
+			t.Done(fmt.Sprintf("%s -> %s", formatBytes(before), formatBytes(after)))
+			return nil
+		},
+	}
+}
+
+func newDbIntegrityCheckCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "integrity-check",
+		Short: "Check the database for corruption and broken references",
+		Long: `Run SQLite's integrity check and foreign key check on the database. It
+exits non-zero when either finds a problem; restore a backup ('mineos db
+backup') rather than keep running on a damaged file.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			out := cmd.OutOrStdout()
+			db, _, err := openControlDb(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			problems, err := db.IntegrityCheck(ctx)
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(map[string]any{"path": db.Path(), "ok": len(problems) == 0, "problems": append([]string{}, problems...)}); err != nil {
+					return err
+				}
+			} else {
+				for _, problem := range problems {
+					fmt.Fprintf(out, "%s %s\n", markFailed(), problem)
+				}
+			}
+			if len(problems) > 0 {
+				return exitCodeError{code: exitFailure, message: fmt.Sprintf("%s: %d problems found", db.Path(), len(problems))}
+			}
+			if !jsonOut {
+				fmt.Fprintf(out, "%s %s is intact\n", markOK(), db.Path())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the result as JSON")
+	return cmd
+}
+
+func newDbMigrateStatusCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate-status",
+		Short: "Show which schema migrations the database has",
+		Long: `List the API's schema migrations applied to the database, and compare
+them with the ones this CLI knows: pending ones are applied by the API when
+it next starts, unknown ones come from a newer API than this CLI.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			out := cmd.OutOrStdout()
+			db, _, err := openControlDb(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			status, err := db.MigrationStatus(ctx)
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(status)
+			}
+			for _, migration := range status.Applied {
+				fmt.Fprintf(out, "%s %s %s\n", markOK(), migration.ID, styleDim.Render("(EF "+migration.ProductVersion+")"))
+			}
+			for _, id := range status.Pending {
+				fmt.Fprintf(out, "%s %s %s\n", styleWarning.Render("•"), id, styleDim.Render("(pending: applied when the API starts)"))
+			}
+			fmt.Fprintf(out, "\n%d applied, %d pending\n", len(status.Applied), len(status.Pending))
+			if len(status.Unknown) > 0 {
+				fmt.Fprintf(out, "%s %s newer than this CLI; run 'mineos upgrade'.\n", styleWarning.Render("Note:"), strings.Join(status.Unknown, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the status as JSON")
+	return cmd
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Compose-only mode: when the API does not answer, the commands that can do
+// their job with Docker and the host's files alone carry on without it,
+// and label what they print so it is not mistaken for the full picture.
+
+// degradedNotice labels output produced without the API.
+func degradedNotice(out io.Writer, message string) {
+	fmt.Fprintln(out, styleWarning.Render("API unreachable, compose-only mode:")+" "+message)
+}
+
+// composeServiceState is a service as 'docker compose ps' reports it.
+type composeServiceState struct {
+	Service string `json:"Service"`
+	State   string `json:"State"`
+	Status  string `json:"Status"`
+	Health  string `json:"Health"`
+}
+
+// serviceStates lists every service of the stack with its container state,
+// stopped ones included.
+func (c composeRunner) serviceStates() ([]composeServiceState, error) {
+	data, err := c.output([]string{"ps", "-a", "--format", "json"})
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	var states []composeServiceState
+	// Compose before 2.21 prints one array, later versions one object per
+	// line.
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &states); err != nil {
+			return nil, fmt.Errorf("parse compose ps: %w", err)
+		}
+	} else {
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var state composeServiceState
+			if err := json.Unmarshal(line, &state); err != nil {
+				return nil, fmt.Errorf("parse compose ps: %w", err)
+			}
+			states = append(states, state)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Service < states[j].Service })
+	return states, nil
+}
+
+// followLogFile prints path's lines to handle and then those appended to
+// it until ctx ends, starting over when the file is rotated or truncated.
+// handle returns false to stop.
+func followLogFile(ctx context.Context, path string, handle func(string) bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { file.Close() }()
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	var partial string
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		for {
+			chunk, err := reader.ReadString('\n')
+			offset += int64(len(chunk))
+			if err != nil {
+				partial += chunk
+				if !errors.Is(err, io.EOF) {
+					return err
+				}
+				break
+			}
+			if !handle(strings.TrimRight(partial+chunk, "\r\n")) {
+				return nil
+			}
+			partial = ""
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if info, err := os.Stat(path); err == nil && info.Size() < offset {
+			reopened, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			file.Close()
+			file = reopened
+			reader = bufio.NewReader(file)
+			offset = 0
+			partial = ""
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+func NewDiffCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var file string
+	var jsonOut bool
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "diff -f <manifest.yaml>",
+		Short: "Show how the installation differs from a declarative manifest",
+		Long: `Compare live servers with a manifest without changing anything.
+
+Reports everything apply would change as well as drift apply leaves alone
+(versions, server types, unlisted jars and, when the manifest lists
+worlds, missing or extra world directories).
+
+Exit status is 0 when everything matches and 8 when there are differences,
+so the command can gate a CI pipeline; errors exit 1, or with their own
+codes such as an unreachable API (3) or a refused key (4). Servers missing from the manifest
+only count as a difference with --strict.`,
+		Example: `  mineos diff -f servers.yaml
+  mineos diff -f servers.yaml --json --strict`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			m, err := readManifest(file)
+			if err != nil {
+				return err
+			}
+
+			var plan usecases.ManifestPlan
+			_, err = withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+				// Version differences are always drift here: diff reports,
+				// it never plans a switch.
+				plan, err = usecases.NewManifestUseCase(client).Plan(ctx, m, usecases.ManifestOptions{})
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				if plan.Changes == nil {
+					plan.Changes = []usecases.ManifestChange{}
+				}
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(plan); err != nil {
+					return err
+				}
+			} else {
+				printManifestPlan(cmd.OutOrStdout(), "Diff for "+file, plan)
+			}
+
+			if len(plan.Changes) > 0 || (strict && len(plan.Unmanaged) > 0) {
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
+				return exitCodeError{code: exitDrift}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Manifest file")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the differences as JSON")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Count servers that are not in the manifest as differences")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
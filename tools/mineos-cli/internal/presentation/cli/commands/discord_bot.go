@@ -0,0 +1,396 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/fuzzy"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/discord"
+)
+
+const (
+	discordTokenEnv       = "DISCORD_BOT_TOKEN"
+	discordPublicKeyEnv   = "DISCORD_PUBLIC_KEY"
+	discordApplicationEnv = "DISCORD_APPLICATION_ID"
+	defaultDiscordListen  = "127.0.0.1:5081"
+)
+
+// discordPlayerName limits whitelist names to what Java and Floodgate
+// (leading '.') accept, which also keeps console commands to one line.
+var discordPlayerName = regexp.MustCompile(`^\.?[A-Za-z0-9_]{2,16}$`)
+
+var discordCommands = []discord.Command{
+	{Name: "status", Description: "Show MineOS servers, or one server in detail", Options: []discord.CommandOption{
+		{Name: "server", Description: "Server name", Type: discord.OptionString, Autocomplete: true},
+	}},
+	{Name: "players", Description: "List the players online on a server", Options: []discord.CommandOption{
+		{Name: "server", Description: "Server name", Type: discord.OptionString, Required: true, Autocomplete: true},
+	}},
+	{Name: "restart", Description: "Restart a server", Options: []discord.CommandOption{
+		{Name: "server", Description: "Server name", Type: discord.OptionString, Required: true, Autocomplete: true},
+	}},
+	{Name: "whitelist", Description: "Add a player to a server's whitelist", Options: []discord.CommandOption{
+		{Name: "server", Description: "Server name", Type: discord.OptionString, Required: true, Autocomplete: true},
+		{Name: "player", Description: "Minecraft player name", Type: discord.OptionString, Required: true},
+	}},
+}
+
+// discordAdminCommands change servers and need an --admin-role.
+var discordAdminCommands = map[string]bool{"restart": true, "whitelist": true}
+
+func NewDiscordBotCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var listen string
+	var token string
+	var publicKey string
+	var applicationID string
+	var guildID string
+	var register bool
+	var viewRoles []string
+	var adminRoles []string
+
+	cmd := &cobra.Command{
+		Use:   "discord-bot",
+		Short: "Serve Discord slash commands for server status, players, restarts and whitelisting",
+		Long: `Run a Discord bot that answers /status, /players, /restart and /whitelist
+through the MineOS API.
+
+The bot uses Discord's HTTP interactions endpoint instead of a gateway
+connection: create an application at https://discord.com/developers, make
+this endpoint reachable over HTTPS (for example with 'mineos network expose')
+and enter its URL as the application's Interactions Endpoint URL. Discord
+signs every request; the public key comes from --public-key or
+` + discordPublicKeyEnv + `.
+
+--register installs the slash commands, in --guild right away or globally
+within the hour. It needs the bot token, from --token, ` + discordTokenEnv + `,
+a file named by ` + discordTokenEnv + `_FILE (Docker secrets) or .env, and the
+application id from --application-id or ` + discordApplicationEnv + `.
+
+/status and /players are open to everyone unless --view-role is set.
+/restart and /whitelist need one of the --admin-role roles; without any they
+are refused. Roles are role IDs (Developer Mode > Copy Role ID).`,
+		Example: `  mineos discord-bot --register --guild 123456789012345678 --admin-role 234567890123456789
+  mineos discord-bot --listen 0.0.0.0:5081 --view-role 345678901234567890`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig.Execute(context.Background())
+			if err != nil {
+				return err
+			}
+			key, err := discord.ParsePublicKey(resolveSecret(cfg, publicKey, discordPublicKeyEnv))
+			if err != nil {
+				return fmt.Errorf("%w (--public-key or %s)", err, discordPublicKeyEnv)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if register {
+				botToken := resolveSecret(cfg, token, discordTokenEnv)
+				appID := resolveSecret(cfg, applicationID, discordApplicationEnv)
+				if botToken == "" || appID == "" {
+					return fmt.Errorf("--register needs the bot token (%s) and application id (%s)", discordTokenEnv, discordApplicationEnv)
+				}
+				client := &http.Client{Timeout: 15 * time.Second}
+				if err := discord.RegisterCommands(ctx, client, botToken, appID, guildID, discordCommands); err != nil {
+					return fmt.Errorf("failed to register slash commands: %w", err)
+				}
+				scope := "globally (may take up to an hour to appear)"
+				if guildID != "" {
+					scope = "in guild " + guildID
+				}
+				cmd.Printf("%s Registered %d slash commands %s\n", markOK(), len(discordCommands), scope)
+			}
+			if len(adminRoles) == 0 {
+				cmd.Println(styleDim.Render("No --admin-role set; /restart and /whitelist are disabled."))
+			}
+
+			bot := &discordBot{loadConfig: loadConfig, viewRoles: viewRoles, adminRoles: adminRoles, cmd: cmd}
+			server := discord.NewServer(key, bot)
+			server.OnError = func(err error) {
+				cmd.Printf("%s %v\n", styleInfo.Render("[discord]"), err)
+			}
+			cmd.Printf("Discord interactions endpoint listening on http://%s\n", listen)
+			cmd.Println(styleDim.Render("Press Ctrl+C to stop."))
+			return server.Serve(ctx, listen)
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", defaultDiscordListen, "Address to listen on")
+	cmd.Flags().StringVar(&token, "token", "", "Bot token for --register (default: "+discordTokenEnv+")")
+	cmd.Flags().StringVar(&publicKey, "public-key", "", "Application public key (default: "+discordPublicKeyEnv+")")
+	cmd.Flags().StringVar(&applicationID, "application-id", "", "Application id for --register (default: "+discordApplicationEnv+")")
+	cmd.Flags().StringVar(&guildID, "guild", "", "Register the commands in this guild instead of globally")
+	cmd.Flags().BoolVar(&register, "register", false, "Install the slash commands before serving")
+	cmd.Flags().StringSliceVar(&viewRoles, "view-role", nil, "Role IDs allowed to use /status and /players (default: everyone)")
+	cmd.Flags().StringSliceVar(&adminRoles, "admin-role", nil, "Role IDs allowed to use /restart and /whitelist")
+
+	return cmd
+}
+
+// resolveSecret reads a value from the flag, the environment, a file named
+// by <key>_FILE, or .env, in that order.
+func resolveSecret(cfg config.Config, flagValue, key string) string {
+	if value := strings.TrimSpace(flagValue); value != "" {
+		return value
+	}
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	if path := strings.TrimSpace(os.Getenv(key + "_FILE")); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+	values, err := loadLayeredEnvValues(cfg)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(values[key])
+}
+
+type discordBot struct {
+	loadConfig *usecases.LoadConfigUseCase
+	viewRoles  []string
+	adminRoles []string
+	cmd        *cobra.Command
+}
+
+func (b *discordBot) Command(ctx context.Context, interaction discord.Interaction) discord.Reply {
+	name := interaction.Data.Name
+	caller := interaction.Caller()
+	b.cmd.Printf("%s %s (%s) ran /%s %s\n", styleInfo.Render("[discord]"), caller.Username, caller.ID, name, discordOptionsLine(interaction))
+
+	if discordAdminCommands[name] {
+		if len(b.adminRoles) == 0 {
+			return discord.Reply{Content: "This command is disabled; the bot has no admin role configured.", Private: true}
+		}
+		if !hasAnyRole(interaction.Roles(), b.adminRoles) {
+			return discord.Reply{Content: "You don't have a role that may use /" + name + ".", Private: true}
+		}
+	} else if len(b.viewRoles) > 0 && !hasAnyRole(interaction.Roles(), b.viewRoles) && !hasAnyRole(interaction.Roles(), b.adminRoles) {
+		return discord.Reply{Content: "You don't have a role that may use /" + name + ".", Private: true}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2500*time.Millisecond)
+	defer cancel()
+	switch name {
+	case "status":
+		return b.status(ctx, interaction.Option("server"))
+	case "players":
+		return b.players(ctx, interaction.Option("server"))
+	case "restart":
+		return b.restart(ctx, interaction.Option("server"))
+	case "whitelist":
+		return b.whitelist(ctx, interaction.Option("server"), interaction.Option("player"))
+	}
+	return discord.Reply{Content: "Unknown command.", Private: true}
+}
+
+func (b *discordBot) Autocomplete(ctx context.Context, interaction discord.Interaction) []discord.Choice {
+	option, ok := interaction.Focused()
+	if !ok || option.Name != "server" {
+		return nil
+	}
+	typed := strings.ToLower(strings.TrimSpace(fmt.Sprint(option.Value)))
+	var result []discord.Choice
+	names, _ := b.serverNames(ctx)
+	for _, name := range names {
+		if typed == "" || strings.Contains(strings.ToLower(name), typed) {
+			result = append(result, discord.Choice{Name: name, Value: name})
+		}
+	}
+	return result
+}
+
+func (b *discordBot) withClient(ctx context.Context, fn func(client *api.Client) error) error {
+	_, err := withApiKeyRetry(ctx, b.loadConfig, b.cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+		return fn(client)
+	})
+	return err
+}
+
+func (b *discordBot) serverNames(ctx context.Context) ([]string, error) {
+	var names []string
+	err := b.withClient(ctx, func(client *api.Client) error {
+		servers, err := client.ListServers(ctx)
+		names = serverNames(servers)
+		return err
+	})
+	sort.Strings(names)
+	return names, err
+}
+
+// resolveServer requires the exact server name: there is no one to confirm
+// a guess before a /restart acts on it, so a close name is only suggested.
+func (b *discordBot) resolveServer(ctx context.Context, input string) (string, string) {
+	names, err := b.serverNames(ctx)
+	if err != nil {
+		return "", "MineOS is not reachable: " + err.Error()
+	}
+	if slices.Contains(names, input) {
+		return input, ""
+	}
+	match, candidates := fuzzy.Resolve(input, names)
+	switch {
+	case match != "":
+		return "", fmt.Sprintf("No server named %q. Did you mean %s?", input, match)
+	case len(candidates) > 0:
+		return "", fmt.Sprintf("No server named %q. Did you mean one of %s?", input, strings.Join(candidates, ", "))
+	}
+	return "", fmt.Sprintf("No server named %q.", input)
+}
+
+func (b *discordBot) status(ctx context.Context, input string) discord.Reply {
+	if input == "" {
+		var lines []string
+		err := b.withClient(ctx, func(client *api.Client) error {
+			samples, err := usecases.NewServerStatsUseCase(client).SampleAll(ctx)
+			for _, sample := range samples {
+				if !sample.IsRunning {
+					lines = append(lines, fmt.Sprintf("⚫ **%s** stopped", sample.ServerName))
+					continue
+				}
+				line := fmt.Sprintf("🟢 **%s** %d online", sample.ServerName, sample.PlayerCount)
+				if sample.Tps != nil {
+					line += fmt.Sprintf(", %.1f TPS", *sample.Tps)
+				}
+				lines = append(lines, line)
+			}
+			return err
+		})
+		if err != nil {
+			return discord.Reply{Content: "MineOS is not reachable: " + err.Error(), Private: true}
+		}
+		if len(lines) == 0 {
+			return discord.Reply{Content: "No servers yet."}
+		}
+		return discord.Reply{Content: strings.Join(lines, "\n")}
+	}
+
+	name, problem := b.resolveServer(ctx, input)
+	if problem != "" {
+		return discord.Reply{Content: problem, Private: true}
+	}
+	var content string
+	err := b.withClient(ctx, func(client *api.Client) error {
+		sample, err := client.GetPerformance(ctx, name)
+		if err != nil {
+			return err
+		}
+		if !sample.IsRunning {
+			content = fmt.Sprintf("⚫ **%s** is stopped.", name)
+			return nil
+		}
+		content = fmt.Sprintf("🟢 **%s** is running\nPlayers: %d\nCPU: %.0f%%\nMemory: %d MB", name, sample.PlayerCount, sample.CpuPercent, sample.RamUsedMb)
+		if sample.RamTotalMb > 0 {
+			content += fmt.Sprintf(" / %d MB", sample.RamTotalMb)
+		}
+		if sample.Tps != nil {
+			content += fmt.Sprintf("\nTPS: %.1f", *sample.Tps)
+		}
+		return nil
+	})
+	if err != nil {
+		return discord.Reply{Content: "Failed to read " + name + ": " + err.Error(), Private: true}
+	}
+	return discord.Reply{Content: content}
+}
+
+func (b *discordBot) players(ctx context.Context, input string) discord.Reply {
+	name, problem := b.resolveServer(ctx, input)
+	if problem != "" {
+		return discord.Reply{Content: problem, Private: true}
+	}
+	var online []string
+	err := b.withClient(ctx, func(client *api.Client) error {
+		history, err := usecases.NewPlayerHistoryUseCase(client).Execute(ctx, name, usecases.PlayerHistoryQuery{Limit: 500, Refresh: true})
+		for _, player := range history.Players {
+			if player.Online {
+				online = append(online, player.Name)
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return discord.Reply{Content: "Failed to read players of " + name + ": " + err.Error(), Private: true}
+	}
+	if len(online) == 0 {
+		return discord.Reply{Content: fmt.Sprintf("Nobody is online on **%s**.", name)}
+	}
+	sort.Strings(online)
+	return discord.Reply{Content: fmt.Sprintf("**%s** — %d online: %s", name, len(online), strings.Join(online, ", "))}
+}
+
+func (b *discordBot) restart(ctx context.Context, input string) discord.Reply {
+	name, problem := b.resolveServer(ctx, input)
+	if problem != "" {
+		return discord.Reply{Content: problem, Private: true}
+	}
+	return discord.Reply{Later: func(ctx context.Context) string {
+		err := b.withClient(ctx, func(client *api.Client) error {
+			return usecases.NewServerActionUseCase(client).Execute(ctx, name, "restart")
+		})
+		if err != nil {
+			return fmt.Sprintf("❌ Failed to restart **%s**: %v", name, err)
+		}
+		return fmt.Sprintf("🔄 **%s** is restarting.", name)
+	}}
+}
+
+func (b *discordBot) whitelist(ctx context.Context, input, player string) discord.Reply {
+	if !discordPlayerName.MatchString(player) {
+		return discord.Reply{Content: fmt.Sprintf("%q is not a valid player name.", player), Private: true}
+	}
+	name, problem := b.resolveServer(ctx, input)
+	if problem != "" {
+		return discord.Reply{Content: problem, Private: true}
+	}
+	err := b.withClient(ctx, func(client *api.Client) error {
+		detail, err := client.GetServer(ctx, name)
+		if err != nil {
+			return err
+		}
+		if !detail.IsRunning() {
+			return fmt.Errorf("%s is stopped; start it first", name)
+		}
+		return client.SendConsoleCommand(ctx, name, "whitelist add "+player)
+	})
+	if err != nil {
+		return discord.Reply{Content: "Failed to whitelist " + player + ": " + err.Error(), Private: true}
+	}
+	return discord.Reply{Content: fmt.Sprintf("✅ Added **%s** to the whitelist of **%s**.", player, name)}
+}
+
+func hasAnyRole(roles, allowed []string) bool {
+	for _, role := range roles {
+		for _, want := range allowed {
+			if role == strings.TrimSpace(want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func discordOptionsLine(interaction discord.Interaction) string {
+	var parts []string
+	for _, option := range interaction.Data.Options {
+		parts = append(parts, fmt.Sprintf("%s=%v", option.Name, option.Value))
+	}
+	return strings.Join(parts, " ")
+}
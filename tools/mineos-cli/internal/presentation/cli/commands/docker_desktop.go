@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// dockerDesktopSharedRoots are the folders Docker Desktop for Mac shares
+// with its VM by default. Bind mounts from anywhere else are refused with
+// "Mounts denied".
+var dockerDesktopSharedRoots = []string{"/Users", "/Volumes", "/private", "/tmp", "/var/folders"}
+
+func dockerDesktopMemoryHint() string {
+	if runtime.GOOS == "windows" {
+		return "With the WSL 2 backend, raise memory= under [wsl2] in %UserProfile%\\.wslconfig and run 'wsl --shutdown'; otherwise use Docker Desktop's Settings > Resources > Advanced."
+	}
+	return "Raise it in Docker Desktop under Settings > Resources > Advanced > Memory, then Apply & restart."
+}
+
+// warnDockerDesktopSharing warns on macOS when dir, which holds the data and
+// server directories the stack bind mounts, is not shared with the VM.
+func warnDockerDesktopSharing(out io.Writer, dir string) {
+	if runtime.GOOS != "darwin" {
+		return
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	for _, root := range dockerDesktopSharedRoots {
+		if abs == root || strings.HasPrefix(abs, root+"/") {
+			return
+		}
+	}
+	fmt.Fprintf(out, "%s %s is outside the folders Docker Desktop shares by default (%s).\n", styleWarning.Render("Warning:"), abs, strings.Join(dockerDesktopSharedRoots, ", "))
+	fmt.Fprintln(out, "  Add it under Settings > Resources > File sharing, or install under your home folder.")
+}
+
+// hostOwnershipHint says who must own the host directories the API writes
+// to. On Linux that is the container's uid 1000; Docker Desktop maps files in
+// shared folders to the user running it, so there it is only the sharing
+// that matters.
+func hostOwnershipHint() string {
+	if runtime.GOOS == "linux" {
+		return "owned by 1000:1000"
+	}
+	return "in a folder shared with Docker Desktop"
+}
+
+// clearQuarantine removes the flag macOS puts on downloaded files. Gatekeeper
+// refuses to run an unsigned binary that carries it.
+func clearQuarantine(path string) {
+	if runtime.GOOS != "darwin" {
+		return
+	}
+	_ = exec.Command("xattr", "-d", "com.apple.quarantine", path).Run()
+}
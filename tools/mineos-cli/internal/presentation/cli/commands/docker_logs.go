@@ -1,43 +1,166 @@
 package commands
 
 import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/logrecord"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/logtime"
 )
 
 func NewDockerLogsCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := newComposeLogsCommand(loadConfig)
+	cmd.AddCommand(newLogsAnalyzeCommand(loadConfig))
+	return cmd
+}
+
+// newComposeLogsCommand is shared by 'mineos logs' and 'mineos stack logs'.
+func newComposeLogsCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 	var tail int
 	var follow bool
+	var raw bool
+	var times logWindowFlags
+	var filters logFilterFlags
 
 	cmd := &cobra.Command{
-		Use:   "logs [service]",
+		Use:   "logs [service...]",
 		Short: "Stream Docker compose logs",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Stream Docker compose logs with timestamps in the local time zone (or UTC
+with --utc). --since and --until take a duration ago (15m, 2h, 3d) or a
+date/time such as "2024-05-01 18:30".
+
+The services are told apart by color. Structured JSON records (Serilog,
+Microsoft and Node loggers) and Serilog's console lines are shown as one
+line with a single timestamp, the level and the message; --raw shows lines
+as compose prints them. --level and --grep filter like they do for
+'mineos servers logs'; a stack trace goes with the line that started it.`,
+		Example: `  mineos stack logs api --level warn
+  mineos stack logs --since 1h --grep "(?i)sqlite"`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			window, err := times.window()
+			if err != nil {
+				return err
+			}
+			filter, err := filters.filter()
+			if err != nil {
+				return err
+			}
 			compose, _, err := loadComposeAndConfig(cmd.Context(), loadConfig)
 			if err != nil {
 				return err
 			}
 
-			composeArgs := []string{"logs"}
+			composeArgs := []string{"logs", "--timestamps"}
 			if follow {
 				composeArgs = append(composeArgs, "-f")
 			}
 			if tail > 0 {
 				composeArgs = append(composeArgs, "--tail", strconv.Itoa(tail))
 			}
-			if len(args) == 1 {
-				composeArgs = append(composeArgs, args[0])
+			if !window.Since.IsZero() {
+				composeArgs = append(composeArgs, "--since", window.Since.Format(time.RFC3339))
 			}
-			return compose.run(composeArgs)
+			if !window.Until.IsZero() {
+				composeArgs = append(composeArgs, "--until", window.Until.Format(time.RFC3339))
+			}
+			composeArgs = append(composeArgs, args...)
+
+			out := cmd.OutOrStdout()
+			normalizer := logTimes()
+			return compose.lines(cmd.Context(), composeArgs, func(line string) {
+				line = plainLog(line)
+				prefix, stamp, text := logtime.SplitDocker(line)
+				if !window.Contains(stamp) {
+					return
+				}
+				service := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(prefix), "|"))
+				record := logrecord.ParseService(text)
+				if !filter.Keep(service, record.Level, record.Message) {
+					return
+				}
+				if raw {
+					_, line = normalizer.DockerLine(line)
+					fmt.Fprintln(out, line)
+					return
+				}
+				printServiceRecord(out, normalizer, prefix, service, stamp, record)
+			})
 		},
 	}
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		compose, _, err := loadComposeAndConfig(cmd.Context(), loadConfig)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		output, err := compose.output([]string{"config", "--services"})
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return strings.Fields(string(output)), cobra.ShellCompDirectiveNoFileComp
+	}
 
 	cmd.Flags().IntVar(&tail, "tail", 200, "Number of log lines to show")
 	cmd.Flags().BoolVar(&follow, "follow", true, "Follow log output")
+	cmd.Flags().BoolVar(&raw, "raw", false, "Print lines as compose does, without parsing JSON records")
+	times.register(cmd)
+	filters.register(cmd)
 
 	return cmd
 }
+
+// printServiceRecord prints a parsed compose line under its colored service
+// prefix, with compose's timestamp in place of the one the record carries.
+func printServiceRecord(out io.Writer, normalizer logtime.Normalizer, prefix, service string, stamp time.Time, record logrecord.Record) {
+	if prefix != "" {
+		prefix = serviceStyle(service).Render(prefix)
+	}
+	if stamp.IsZero() {
+		stamp = record.Time
+	}
+	when := ""
+	if !stamp.IsZero() {
+		when = normalizer.Format(stamp) + " "
+	}
+	if record.Level == logrecord.LevelUnknown {
+		fmt.Fprintf(out, "%s%s%s\n", prefix, when, record.Message)
+	} else {
+		fmt.Fprintf(out, "%s%s%s %s\n", prefix, when, renderLevel(record.Level), record.Message)
+	}
+	if record.Exception == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(record.Exception, "\n"), "\n") {
+		fmt.Fprintf(out, "%s%s\n", prefix, strings.TrimRight(line, "\r"))
+	}
+}
+
+// lines runs a compose command and passes each stdout line to onLine;
+// stderr is passed through.
+func (c composeRunner) lines(ctx context.Context, args []string, onLine func(string)) error {
+	cmd := c.command(ctx, args, nil)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		onLine(scanner.Text())
+	}
+	// Keep draining so the process never blocks on a full pipe.
+	_, _ = io.Copy(io.Discard, stdout)
+	return cmd.Wait()
+}
@@ -11,6 +11,7 @@ import (
 func NewStartCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 	var wait bool
 	var waitTimeout int
+	var skipPortCheck bool
 
 	cmd := &cobra.Command{
 		Use:     "start",
@@ -24,6 +25,11 @@ func NewStartCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if !skipPortCheck {
+				if err := checkStackPorts(ctx, compose, cfg); err != nil {
+					return err
+				}
+			}
 			if err := compose.run([]string{"up", "-d"}); err != nil {
 				return err
 			}
@@ -39,6 +45,7 @@ func NewStartCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 
 	cmd.Flags().BoolVar(&wait, "wait", true, "Wait for API health after startup")
 	cmd.Flags().IntVar(&waitTimeout, "wait-timeout", 60, "Seconds to wait for API health")
+	cmd.Flags().BoolVar(&skipPortCheck, "skip-port-check", false, "Start even when another program holds a port MineOS publishes")
 
 	return cmd
 }
@@ -138,6 +145,7 @@ func NewPullCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 
 func NewDownCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 	var volumes bool
+	var confirmToken string
 	var timeout int
 
 	cmd := &cobra.Command{
@@ -151,6 +159,11 @@ func NewDownCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if volumes {
+				if err := requireConfirmToken(cfg, confirmDownVolumes, confirmToken, out); err != nil {
+					return err
+				}
+			}
 			timeoutSeconds := effectiveShutdownTimeout(cfg, timeout)
 			if err := gracefulStop(ctx, loadConfig, compose, cfg, timeoutSeconds, false, out); err != nil {
 				return err
@@ -161,6 +174,7 @@ func NewDownCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 
 	cmd.Flags().BoolVar(&volumes, "volumes", false, "Also remove Docker volumes")
 	cmd.Flags().IntVar(&timeout, "timeout", 0, "Shutdown timeout in seconds (default from .env)")
+	addConfirmTokenFlag(cmd, &confirmToken)
 
 	return cmd
 }
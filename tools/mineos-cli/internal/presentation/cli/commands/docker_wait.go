@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+)
+
+// DockerWaitEnv sets how many seconds compose commands wait for the Docker
+// daemon to come up, e.g. right after boot; 0 fails at once.
+const DockerWaitEnv = "MINEOS_DOCKER_WAIT"
+
+const defaultDockerWait = 60
+
+// dockerWaitFlag is --docker-wait, which overrides DockerWaitEnv; negative
+// when it was not given.
+var dockerWaitFlag = -1
+
+func registerDockerWaitFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().IntVar(&dockerWaitFlag, "docker-wait", -1, "Seconds to wait for the Docker daemon to start (default: "+DockerWaitEnv+" or "+strconv.Itoa(defaultDockerWait)+")")
+}
+
+func dockerWaitTimeout(cfg config.Config) time.Duration {
+	seconds := defaultDockerWait
+	if dockerWaitFlag >= 0 {
+		seconds = dockerWaitFlag
+	} else if value, err := strconv.Atoi(strings.TrimSpace(cfg.DockerWait)); err == nil && value >= 0 {
+		seconds = value
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// waitForDocker returns once the daemon answers, counting down on out while
+// it does not. A daemon that is not up within timeout is an error with a
+// hint on starting it.
+func waitForDocker(ctx context.Context, compose composeRunner, out io.Writer, timeout time.Duration) error {
+	ping := func() error {
+		output, err := compose.docker(ctx, "version", "--format", "{{.Server.Version}}").CombinedOutput()
+		if err != nil {
+			return errors.New(fallback(strings.TrimSpace(string(output)), err.Error()))
+		}
+		return nil
+	}
+	err := ping()
+	if err == nil {
+		return nil
+	}
+
+	out = decor(out)
+	live := redraws(out)
+	start := time.Now()
+	deadline := start.Add(timeout)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	lastLine := time.Time{}
+	nextPing := start.Add(2 * time.Second)
+	for left := time.Until(deadline); left > 0; left = time.Until(deadline) {
+		message := fmt.Sprintf("Waiting for the Docker daemon to start... %ds left", int(left.Seconds()+0.5))
+		if live {
+			fmt.Fprintf(out, "\r\033[K%s", styleDim.Render(message))
+		} else if time.Since(lastLine) >= 10*time.Second {
+			fmt.Fprintln(out, message)
+			lastLine = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			if live {
+				fmt.Fprintln(out)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		// The countdown ticks every second; a starting daemon is asked every
+		// other one.
+		if time.Now().Before(nextPing) {
+			continue
+		}
+		nextPing = time.Now().Add(2 * time.Second)
+		if err = ping(); err == nil {
+			if live {
+				fmt.Fprint(out, "\r\033[K")
+			}
+			fmt.Fprintln(out, styleSuccess.Render(fmt.Sprintf("Docker is up after %ds.", int(time.Since(start).Seconds()))))
+			return nil
+		}
+	}
+	if live && timeout > 0 {
+		fmt.Fprintln(out)
+	}
+
+	msg := "the Docker daemon is not running"
+	if timeout > 0 {
+		msg = fmt.Sprintf("the Docker daemon did not start within %s", timeout)
+	}
+	msg += " (" + err.Error() + ")\n"
+	switch {
+	case compose.remote != nil:
+		msg += "Start Docker on " + compose.remote.Target + ".\n"
+	case runtime.GOOS == "windows" || runtime.GOOS == "darwin":
+		msg += "Start Docker Desktop and wait for it to finish loading.\n"
+	default:
+		msg += "Start it with: sudo systemctl start docker\n"
+	}
+	msg += fmt.Sprintf("Wait longer with --docker-wait <seconds> or %s in .env.", DockerWaitEnv)
+	return errors.New(msg)
+}
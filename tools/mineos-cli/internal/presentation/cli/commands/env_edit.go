@@ -9,6 +9,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
 )
 
 // envDefault defines a required env var and its default value.
@@ -96,6 +98,14 @@ func loadEnvValues(path string) (map[string]string, error) {
 	return godotenv.Read(envPath)
 }
 
+// loadLayeredEnvValues reads .env with its .env.local and --env-overlay
+// layers applied, as compose sees it. Edits still go to the main file, so
+// use loadEnvValues when checking what a write would change.
+func loadLayeredEnvValues(cfg config.Config) (map[string]string, error) {
+	envPath := fallback(strings.TrimSpace(cfg.EnvPath), ".env")
+	return godotenv.Read(append([]string{envPath}, cfg.EnvOverlays...)...)
+}
+
 func setEnvFileValue(path, key, value string) error {
 	envPath := strings.TrimSpace(path)
 	if envPath == "" {
@@ -0,0 +1,355 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+)
+
+// envVersionKey records the last migration applied to a .env file.
+const envVersionKey = "MINEOS_ENV_VERSION"
+
+// envMigration upgrades a .env file from the previous version. apply
+// returns one line per change it made.
+type envMigration struct {
+	version     int
+	description string
+	apply       func(f *envFile) []string
+}
+
+// envMigrations must stay in version order. Add new layouts at the end;
+// never renumber or edit a released migration.
+var envMigrations = []envMigration{
+	{1, "Use WEB_ORIGIN_PROD for the web origin", migrateWebOrigin},
+	{2, "Rename Auth__JwtExpiryHours to Auth__JwtExpiryMinutes", migrateJwtExpiry},
+	{3, "Split Host__Owner into Host__OwnerUid and Host__OwnerGid", migrateHostOwner},
+	{4, "Keep container paths out of host settings", migrateContainerPaths},
+}
+
+func latestEnvVersion() int {
+	return envMigrations[len(envMigrations)-1].version
+}
+
+// Older installs only had ORIGIN; the compose file builds CORS origins from
+// WEB_ORIGIN_PROD.
+func migrateWebOrigin(f *envFile) []string {
+	origin, ok := f.get("ORIGIN")
+	if !ok || origin == "" {
+		return nil
+	}
+	var changes []string
+	for _, key := range []string{"WEB_ORIGIN_PROD", "PUBLIC_API_BASE_URL"} {
+		if value, ok := f.get(key); !ok || value == "" {
+			f.set(key, origin)
+			changes = append(changes, fmt.Sprintf("set %s=%s from ORIGIN", key, origin))
+		}
+	}
+	return changes
+}
+
+// The compose file reads the JWT lifetime in minutes; the hours key was
+// never used.
+func migrateJwtExpiry(f *envFile) []string {
+	hours, ok := f.get("Auth__JwtExpiryHours")
+	if !ok {
+		return nil
+	}
+	if _, exists := f.get("Auth__JwtExpiryMinutes"); exists {
+		f.remove("Auth__JwtExpiryHours")
+		return []string{"removed Auth__JwtExpiryHours (Auth__JwtExpiryMinutes is set)"}
+	}
+	n, err := strconv.Atoi(hours)
+	if err != nil || n <= 0 {
+		f.remove("Auth__JwtExpiryHours")
+		return []string{fmt.Sprintf("removed Auth__JwtExpiryHours (invalid value %q)", hours)}
+	}
+	minutes := strconv.Itoa(n * 60)
+	f.rename("Auth__JwtExpiryHours", "Auth__JwtExpiryMinutes", minutes)
+	return []string{fmt.Sprintf("renamed Auth__JwtExpiryHours=%s to Auth__JwtExpiryMinutes=%s", hours, minutes)}
+}
+
+func migrateHostOwner(f *envFile) []string {
+	owner, ok := f.get("Host__Owner")
+	if !ok {
+		return nil
+	}
+	uid, gid, found := strings.Cut(owner, ":")
+	if !found {
+		gid = uid
+	}
+	if _, err := strconv.Atoi(uid); err != nil {
+		return []string{fmt.Sprintf("left Host__Owner=%s alone (expected uid:gid)", owner)}
+	}
+	if _, err := strconv.Atoi(gid); err != nil {
+		return []string{fmt.Sprintf("left Host__Owner=%s alone (expected uid:gid)", owner)}
+	}
+	f.rename("Host__Owner", "Host__OwnerUid", uid)
+	f.set("Host__OwnerGid", gid)
+	return []string{fmt.Sprintf("split Host__Owner=%s into Host__OwnerUid=%s and Host__OwnerGid=%s", owner, uid, gid)}
+}
+
+// Host__BaseDirectory is the path inside the API container and SQLite lives
+// in the /app/data mount. Older files put host paths in both.
+func migrateContainerPaths(f *envFile) []string {
+	var changes []string
+	if base, ok := f.get("Host__BaseDirectory"); ok && base != containerBaseDir {
+		if hostDir, ok := f.get("HOST_BASE_DIRECTORY"); !ok || hostDir == "" {
+			f.set("HOST_BASE_DIRECTORY", base)
+			changes = append(changes, fmt.Sprintf("moved host path %s to HOST_BASE_DIRECTORY", base))
+		}
+		f.set("Host__BaseDirectory", containerBaseDir)
+		changes = append(changes, "set Host__BaseDirectory="+containerBaseDir)
+	}
+
+	dbType, _ := f.get("DB_TYPE")
+	conn, ok := f.get("ConnectionStrings__DefaultConnection")
+	if ok && (dbType == "" || strings.EqualFold(dbType, "sqlite")) {
+		if file, found := strings.CutPrefix(conn, "Data Source="); found && !strings.HasPrefix(file, "/app/data/") {
+			rewritten := "Data Source=/app/data/" + path.Base(strings.ReplaceAll(file, `\`, "/"))
+			f.set("ConnectionStrings__DefaultConnection", rewritten)
+			changes = append(changes, fmt.Sprintf("rewrote the SQLite path %s to %s", file, strings.TrimPrefix(rewritten, "Data Source=")))
+		}
+	}
+	return changes
+}
+
+// envMigrationResult is one migration that changed the file.
+type envMigrationResult struct {
+	version     int
+	description string
+	changes     []string
+}
+
+type envMigrationReport struct {
+	from, to int
+	applied  []envMigrationResult
+	defaults []string
+	backup   string
+}
+
+func (r envMigrationReport) changed() bool {
+	return r.from != r.to || len(r.applied) > 0 || len(r.defaults) > 0
+}
+
+// migrateEnvFile brings the .env at envPath up to the latest layout. Unless
+// dryRun is set, the previous file is kept as <env>.bak-<timestamp> and
+// missing defaults are added afterwards.
+func migrateEnvFile(envPath string, dryRun bool) (envMigrationReport, error) {
+	if envPath == "" {
+		envPath = ".env"
+	}
+	data, err := os.ReadFile(envPath)
+	if err != nil {
+		return envMigrationReport{}, err
+	}
+	f := parseEnvFile(data)
+
+	report := envMigrationReport{to: latestEnvVersion()}
+	if raw, ok := f.get(envVersionKey); ok {
+		if report.from, err = strconv.Atoi(raw); err != nil {
+			return envMigrationReport{}, fmt.Errorf("%s: invalid %s %q", envPath, envVersionKey, raw)
+		}
+	}
+	if report.from > report.to {
+		return envMigrationReport{}, fmt.Errorf("%s was migrated by a newer mineos (version %d, this CLI knows %d); upgrade the CLI", envPath, report.from, report.to)
+	}
+	for _, migration := range envMigrations {
+		if migration.version <= report.from {
+			continue
+		}
+		if changes := migration.apply(f); len(changes) > 0 {
+			report.applied = append(report.applied, envMigrationResult{version: migration.version, description: migration.description, changes: changes})
+		}
+	}
+	for _, d := range requiredEnvDefaults {
+		if _, ok := f.get(d.key); !ok {
+			report.defaults = append(report.defaults, d.key)
+		}
+	}
+	if dryRun || !report.changed() {
+		return report, nil
+	}
+
+	if report.from != report.to || len(report.applied) > 0 {
+		f.set(envVersionKey, strconv.Itoa(report.to))
+		info, err := os.Stat(envPath)
+		if err != nil {
+			return report, err
+		}
+		report.backup = envPath + ".bak-" + time.Now().Format("20060102150405")
+		if err := os.WriteFile(report.backup, data, info.Mode().Perm()); err != nil {
+			return report, fmt.Errorf("back up %s: %w", envPath, err)
+		}
+		if err := os.WriteFile(envPath, f.bytes(), info.Mode().Perm()); err != nil {
+			return report, err
+		}
+	}
+	if _, err := ensureEnvDefaults(envPath, nil); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func printEnvMigrationReport(out io.Writer, report envMigrationReport, dryRun bool) {
+	if !report.changed() {
+		fmt.Fprintf(out, ".env is up to date (version %d)\n", report.to)
+		return
+	}
+	verb := "Applied"
+	if dryRun {
+		verb = "Would apply"
+	}
+	if report.from != report.to {
+		fmt.Fprintf(out, "%s .env migrations %d -> %d\n", verb, report.from, report.to)
+		if len(report.applied) == 0 {
+			fmt.Fprintf(out, "  %s\n", styleDim.Render("No settings need changes; only "+envVersionKey+" is recorded."))
+		}
+	}
+	for _, result := range report.applied {
+		fmt.Fprintf(out, "  %s %s\n", styleInfo.Render(fmt.Sprintf("%d.", result.version)), result.description)
+		for _, change := range result.changes {
+			fmt.Fprintf(out, "       %s\n", styleDim.Render(change))
+		}
+	}
+	if len(report.defaults) > 0 {
+		fmt.Fprintf(out, "  %s add defaults: %s\n", styleInfo.Render("+"), strings.Join(report.defaults, ", "))
+	}
+	if report.backup != "" {
+		fmt.Fprintf(out, "Previous file saved as %s\n", report.backup)
+	}
+}
+
+func NewEnvCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Maintain the MineOS .env file",
+	}
+
+	cmd.AddCommand(newEnvMigrateCommand(loadConfig))
+
+	return cmd
+}
+
+func newEnvMigrateCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade an old .env layout to the current one",
+		Long: `Apply the versioned .env migrations newer than ` + envVersionKey + `:
+renamed keys, split values and container paths that moved. Missing
+defaults are added as well. The previous file is kept as .env.bak-<time>.
+
+'mineos stack update' runs this automatically.`,
+		Example: `  mineos env migrate --dry-run
+  mineos env migrate`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig.Execute(cmd.Context())
+			if err != nil {
+				return err
+			}
+			report, err := migrateEnvFile(cfg.EnvPath, dryRun)
+			if err != nil {
+				return err
+			}
+			printEnvMigrationReport(cmd.OutOrStdout(), report, dryRun)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the migrations without changing the file")
+
+	return cmd
+}
+
+// envFile keeps a .env file line by line so migrations preserve comments
+// and ordering.
+type envFile struct {
+	lines []string
+}
+
+func parseEnvFile(data []byte) *envFile {
+	content := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	return &envFile{lines: strings.Split(content, "\n")}
+}
+
+// find returns the index of the line assigning key, or -1.
+func (f *envFile) find(key string) int {
+	for i, line := range f.lines {
+		name, _, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && strings.TrimSpace(strings.TrimPrefix(name, "export ")) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// keys lists the assigned keys in file order, skipping comments.
+func (f *envFile) keys() []string {
+	var keys []string
+	for _, line := range f.lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if name, _, ok := strings.Cut(trimmed, "="); ok {
+			keys = append(keys, strings.TrimSpace(strings.TrimPrefix(name, "export ")))
+		}
+	}
+	return keys
+}
+
+func (f *envFile) get(key string) (string, bool) {
+	i := f.find(key)
+	if i < 0 {
+		return "", false
+	}
+	_, value, _ := strings.Cut(f.lines[i], "=")
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return value, true
+}
+
+func (f *envFile) set(key, value string) {
+	if i := f.find(key); i >= 0 {
+		f.lines[i] = key + "=" + value
+		return
+	}
+	f.lines = append(f.lines, key+"="+value)
+}
+
+// rename replaces the line for oldKey in place, keeping its position. If
+// newKey already exists it is updated and oldKey dropped.
+func (f *envFile) rename(oldKey, newKey, value string) {
+	if f.find(newKey) >= 0 {
+		f.set(newKey, value)
+		f.remove(oldKey)
+		return
+	}
+	if i := f.find(oldKey); i >= 0 {
+		f.lines[i] = newKey + "=" + value
+		return
+	}
+	f.set(newKey, value)
+}
+
+func (f *envFile) remove(key string) {
+	if i := f.find(key); i >= 0 {
+		f.lines = append(f.lines[:i], f.lines[i+1:]...)
+	}
+}
+
+func (f *envFile) bytes() []byte {
+	return []byte(strings.Join(f.lines, "\n") + "\n")
+}
@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+// Exit codes, documented in the README under "Exit Codes". Scripts and the
+// agent rely on them, so a code is never reused for something else.
+const (
+	exitOK = 0
+	// exitFailure is any other error, and checks that found a problem
+	// (verify, health, security).
+	exitFailure = 1
+	// exitUsage is a bad flag or argument, or a prompt machine mode refused.
+	exitUsage = 2
+	// exitUnreachable is the API, or another service the command needs,
+	// not answering.
+	exitUnreachable = 3
+	// exitAuth is an API key that is missing or refused.
+	exitAuth = 4
+	// exitNotFound is a server, backup or other resource that does not
+	// exist.
+	exitNotFound = 5
+	// exitConflict is a resource in a state that rules the command out,
+	// e.g. a server that must be stopped first.
+	exitConflict = 6
+	// exitPartial is an action on several servers that failed on some.
+	exitPartial = 7
+	// exitDrift is an installation that differs from its manifest, so CI
+	// can tell drift from diff failing to run.
+	exitDrift = 8
+)
+
+// exitCodeError ends the process with a specific status instead of 1. The
+// message, if any, is printed like any other error.
+type exitCodeError struct {
+	code    int
+	message string
+}
+
+func (e exitCodeError) Error() string { return e.message }
+
+func (e exitCodeError) ExitCode() int { return e.code }
+
+// cobraUsageErrors are the prefixes of cobra's argument and flag errors.
+var cobraUsageErrors = []string{
+	"unknown command",
+	"unknown flag",
+	"unknown shorthand flag",
+	"required flag(s)",
+	"invalid argument",
+	"flag needs an argument",
+	"accepts ",
+	"requires at least",
+	"requires at most",
+}
+
+// exitCode picks the exit status for the error a command returned.
+func exitCode(err error) int {
+	var coded interface{ ExitCode() int }
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &coded):
+		return coded.ExitCode()
+	case errors.Is(err, errMachinePrompt), usageError(err):
+		return exitUsage
+	case errors.Is(err, api.ErrApiKeyMissing), errors.Is(err, api.ErrApiKeyInvalid):
+		return exitAuth
+	case errors.Is(err, ports.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, ports.ErrConflict):
+		return exitConflict
+	case unreachable(err):
+		return exitUnreachable
+	}
+	return exitFailure
+}
+
+// withExitCode gives err the status exitCode picks for it, so the process
+// exits with it however err is printed.
+func withExitCode(err error) error {
+	if err == nil {
+		return nil
+	}
+	code := exitCode(err)
+	var coded interface{ ExitCode() int }
+	if code == exitFailure || errors.As(err, &coded) {
+		return err
+	}
+	return exitCodeError{code: code, message: err.Error()}
+}
+
+// usageError recognizes cobra's errors and the CLI's own flag errors, which
+// start with the flag ("--level must be ...").
+func usageError(err error) bool {
+	message := err.Error()
+	if strings.HasPrefix(message, "--") {
+		return true
+	}
+	for _, prefix := range cobraUsageErrors {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// unreachable reports a request that got no answer: refused, timed out or
+// for a host that does not resolve. Ctrl+C is not one.
+func unreachable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var urlErr *url.Error
+	var opErr *net.OpError
+	return errors.As(err, &urlErr) || errors.As(err, &opErr) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// exitKinds name the exit codes in machine mode's error.kind.
+var exitKinds = map[int]string{
+	exitUsage:       "usage",
+	exitUnreachable: "unreachable",
+	exitAuth:        "auth",
+	exitNotFound:    "not_found",
+	exitConflict:    "conflict",
+	exitPartial:     "partial",
+	exitDrift:       "drift",
+}
@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/bootstrap"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+)
+
+// bootstrapFlagKeys maps the .env keys the quiet installer takes as flags.
+var bootstrapFlagKeys = []struct{ key, flag string }{
+	{"Auth__SeedUsername", "--admin"},
+	{"HOST_BASE_DIRECTORY", "--host-dir"},
+	{"Data__Directory", "--data-dir"},
+	{"API_PORT", "--api-port"},
+	{"WEB_PORT", "--web-port"},
+	{"WEB_ORIGIN_PROD", "--web-origin"},
+	{"PUBLIC_MINECRAFT_HOST", "--minecraft-host"},
+	{"BODY_SIZE_LIMIT", "--body-size-limit"},
+	{"MINEOS_NETWORK_MODE", "--network-mode"},
+	{"MINEOS_IMAGE_TAG", "--image-tag"},
+	{platformEnvKey, "--platform"},
+	{apiMemoryLimitKey, "--api-memory"},
+	{apiCpuLimitKey, "--api-cpus"},
+	{webMemoryLimitKey, "--web-memory"},
+	{webCpuLimitKey, "--web-cpus"},
+}
+
+// bootstrapGeneratedKeys are made fresh by every install, or carried by the
+// installer flags, so the script does not copy them.
+var bootstrapGeneratedKeys = map[string]bool{
+	"Auth__SeedPassword":       true,
+	"Auth__JwtSecret":          true,
+	"ApiKey__SeedKey":          true,
+	"MINEOS_API_KEY":           true,
+	"MINEOS_INSTALLATION_ID":   true,
+	"MINEOS_BIND_ADDRESS":      true,
+	"MINEOS_BUILD_FROM_SOURCE": true,
+	envVersionKey:              true,
+}
+
+func NewExportBootstrapCommand(loadConfig *usecases.LoadConfigUseCase, version string) *cobra.Command {
+	var format string
+	var output string
+	var includeSecrets bool
+
+	cmd := &cobra.Command{
+		Use:   "export-bootstrap",
+		Short: "Write a script that re-creates this install on a new machine",
+		Long: `Write a self-contained script that installs MineOS on a new machine with
+this install's options (ports, origin, storage directories, network and
+image settings) and then writes the other .env settings that differ from a
+fresh install.
+
+  --format sh          bash for Linux and macOS (installs Docker on Linux)
+  --format ps1         PowerShell for Windows with Docker Desktop
+  --format cloud-init  user-data that runs the bash script on first boot
+
+Passwords, API keys and tokens are left out: the script reads them from the
+environment (` + bootstrap.AdminPasswordVar + ` for the admin password) or from
+the lines at its top. --include-secrets writes the current values in; keep
+such a script private. Servers and worlds are not part of the script.`,
+		Example: `  mineos export-bootstrap -o mineos-bootstrap.sh
+  ` + bootstrap.AdminPasswordVar + `=... bash mineos-bootstrap.sh
+  mineos export-bootstrap --format cloud-init --include-secrets -o user-data.yml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig.Execute(cmd.Context())
+			if err != nil {
+				return err
+			}
+			values, err := loadLayeredEnvValues(cfg)
+			if err != nil {
+				return err
+			}
+			plan, err := bootstrapPlan(cfg, values, version, includeSecrets)
+			if err != nil {
+				return err
+			}
+			script, err := bootstrap.Render(plan, format)
+			if err != nil {
+				return err
+			}
+
+			if output == "" || output == "-" {
+				fmt.Fprint(cmd.OutOrStdout(), script)
+				return nil
+			}
+			mode := os.FileMode(0o755)
+			if includeSecrets || format == bootstrap.FormatCloudInit {
+				mode = 0o600
+			}
+			if err := os.WriteFile(output, []byte(script), mode); err != nil {
+				return err
+			}
+			errOut := cmd.ErrOrStderr()
+			fmt.Fprintln(errOut, styleSuccess.Render("Wrote "+output+"."))
+			if includeSecrets {
+				fmt.Fprintln(errOut, styleWarning.Render("It contains the admin password and API keys; keep it private."))
+			} else if format == bootstrap.FormatCloudInit {
+				fmt.Fprintln(errOut, styleDim.Render("Fill in the secrets at the top of the script, or export with --include-secrets."))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", bootstrap.FormatShell, "Script format: sh, ps1 or cloud-init")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the script to a file instead of stdout")
+	cmd.Flags().BoolVar(&includeSecrets, "include-secrets", false, "Write passwords, API keys and tokens into the script")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{bootstrap.FormatShell, bootstrap.FormatPowerShell, bootstrap.FormatCloudInit}, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+// bootstrapPlan maps the .env onto installer flags and the settings that
+// differ from what the installer writes for those flags.
+func bootstrapPlan(cfg config.Config, values map[string]string, version string, includeSecrets bool) (bootstrap.Plan, error) {
+	host, _ := os.Hostname()
+	plan := bootstrap.Plan{
+		Header: []string{fmt.Sprintf("MineOS bootstrap exported from %s on %s by mineos %s.",
+			fallback(host, "this machine"), time.Now().Format("2006-01-02 15:04"), version)},
+		Dir:   "mineos",
+		Build: parseEnvBool(values["MINEOS_BUILD_FROM_SOURCE"]),
+	}
+	if norm := strings.TrimPrefix(version, "v"); norm != "" && norm != "dev" {
+		plan.Version = "v" + norm
+	}
+	// The new install gets the same directory name as this one.
+	if abs, err := filepath.Abs(filepath.Dir(fallback(cfg.EnvPath, ".env"))); err == nil {
+		if dir := filepath.Base(abs); dir != string(filepath.Separator) && dir != "." {
+			plan.Dir = dir
+		}
+	}
+
+	secret := func(name, key, variable string) bootstrap.Value {
+		value := bootstrap.Value{Name: name, Secret: true, Var: variable}
+		if includeSecrets {
+			value.Value = values[key]
+		}
+		return value
+	}
+
+	// What the installer writes for the flags, to compare the rest against.
+	install := envConfig{
+		adminUser:        fallback(values["Auth__SeedUsername"], "admin"),
+		hostBaseDir:      defaultHostBaseDir,
+		dataDir:          defaultDataDir,
+		networkMode:      defaultNetworkMode,
+		bindAddress:      values["MINEOS_BIND_ADDRESS"],
+		buildFromSource:  plan.Build,
+		apiPort:          defaultApiPort,
+		webPort:          defaultWebPort,
+		minecraftHost:    "localhost",
+		bodySizeLimit:    defaultBodySizeLimit,
+		telemetryEnabled: true,
+	}
+	if !plan.Build {
+		install.imageTag = "latest"
+	}
+	for _, entry := range bootstrapFlagKeys {
+		value := strings.TrimSpace(values[entry.key])
+		if value == "" {
+			continue
+		}
+		switch entry.key {
+		case "HOST_BASE_DIRECTORY", "Data__Directory":
+			// The installer only takes paths inside the install directory;
+			// others are written as settings below.
+			if !isValidRelativePath(value) {
+				plan.Header = append(plan.Header, fmt.Sprintf("%s is %s, outside the install directory: create it on the new machine first (owned by 1000:1000).", entry.key, value))
+				continue
+			}
+		case "MINEOS_IMAGE_TAG":
+			if plan.Build {
+				continue
+			}
+		}
+		plan.Flags = append(plan.Flags, bootstrap.Value{Name: entry.flag, Value: value})
+		switch entry.key {
+		case "HOST_BASE_DIRECTORY":
+			install.hostBaseDir = value
+		case "Data__Directory":
+			install.dataDir = value
+		case "API_PORT":
+			install.apiPort, _ = strconv.Atoi(value)
+		case "WEB_PORT":
+			install.webPort, _ = strconv.Atoi(value)
+		case "WEB_ORIGIN_PROD":
+			install.webOrigin = value
+		case "PUBLIC_MINECRAFT_HOST":
+			install.minecraftHost = value
+		case "BODY_SIZE_LIMIT":
+			install.bodySizeLimit = value
+		case "MINEOS_NETWORK_MODE":
+			install.networkMode = value
+		case "MINEOS_IMAGE_TAG":
+			install.imageTag = value
+		case platformEnvKey:
+			install.platform = value
+		}
+	}
+	if install.webOrigin == "" {
+		install.webOrigin = fmt.Sprintf("http://localhost:%d", install.webPort)
+	}
+	install.caddySite = deriveCaddySite(install.webOrigin)
+	if family := cfg.BindFamily(); family != config.BindDual {
+		plan.Flags = append(plan.Flags, bootstrap.Value{Name: "--bind-family", Value: family})
+	}
+	plan.Flags = append(plan.Flags, secret("--password", "Auth__SeedPassword", bootstrap.AdminPasswordVar))
+	if includeSecrets && strings.TrimSpace(values["MINEOS_API_KEY"]) != "" {
+		// Keeps scripts and agents that hold the key working.
+		plan.Flags = append(plan.Flags, secret("--api-key", "MINEOS_API_KEY", "MINEOS_API_KEY"))
+	}
+
+	written, err := godotenv.Unmarshal(renderEnv(install))
+	if err != nil {
+		return bootstrap.Plan{}, err
+	}
+	for _, d := range requiredEnvDefaults {
+		if _, ok := written[d.key]; !ok {
+			written[d.key] = d.value
+		}
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, ok := written[key]
+		if bootstrapGeneratedKeys[key] || (ok && value == values[key]) {
+			continue
+		}
+		if isSecretFlag(key) {
+			plan.Settings = append(plan.Settings, secret(key, key, bootstrap.SecretVar(key)))
+			continue
+		}
+		plan.Settings = append(plan.Settings, bootstrap.Value{Name: key, Value: values[key]})
+	}
+	return plan, nil
+}
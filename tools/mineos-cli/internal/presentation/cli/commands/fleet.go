@@ -0,0 +1,498 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	domainfleet "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/fleet"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/fleet"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+// fleetSelection is how fleet commands pick their contexts.
+type fleetSelection struct {
+	file     string
+	group    string
+	contexts []string
+}
+
+func NewFleetCommand() *cobra.Command {
+	var sel fleetSelection
+
+	cmd := &cobra.Command{
+		Use:   "fleet",
+		Short: "Run commands on several MineOS installations at once",
+		Long: `Run CLI commands on several MineOS installations, each reached over --ssh
+or through its own .env, and report the results together.
+
+Installations are named contexts, kept in the CLI's state store (write them
+with 'mineos state edit contexts') or in a file of your own (--file or
+` + fleet.FileEnv + `); groups list them in the order rolling commands take them:
+
+  contexts:
+    eu-1:
+      ssh: admin@eu-1.example.net
+    eu-2:
+      ssh: admin@eu-2.example.net:2222
+      dir: /opt/mineos
+    lab:
+      env: ~/mineos-lab/.env
+  groups:
+    prod: [eu-1, eu-2]
+
+Relative env paths start at the contexts file's directory, or for contexts
+in the state store at the user's config directory (e.g. ~/.config/mineos).`,
+	}
+
+	cmd.PersistentFlags().StringVar(&sel.file, "file", "", "Contexts file to use instead of the state store")
+	cmd.PersistentFlags().StringVarP(&sel.group, "context-group", "g", "", "Act on the contexts of this group, in its order")
+	cmd.PersistentFlags().StringSliceVar(&sel.contexts, "context", nil, "Act on these contexts (repeatable or comma separated), after the group's")
+
+	cmd.AddCommand(newFleetListCommand(&sel))
+	cmd.AddCommand(newFleetExecCommand(&sel))
+	cmd.AddCommand(newFleetUpdateCommand(&sel))
+
+	return cmd
+}
+
+func newFleetListCommand(sel *fleetSelection) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List contexts and their groups",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := cmd.OutOrStdout()
+			def, source, _, err := loadFleetDefinition(sel.file)
+			if err != nil {
+				return err
+			}
+			names := def.Names()
+			if sel.group != "" || len(sel.contexts) > 0 {
+				if names, err = def.Select(sel.group, sel.contexts); err != nil {
+					return ports.WithKind(err, ports.ErrNotFound)
+				}
+			}
+
+			if jsonOut {
+				type entry struct {
+					Name   string   `json:"name"`
+					SSH    string   `json:"ssh,omitempty"`
+					Dir    string   `json:"dir,omitempty"`
+					Env    string   `json:"env,omitempty"`
+					Groups []string `json:"groups"`
+				}
+				entries := []entry{}
+				for _, name := range names {
+					context := def.Contexts[name]
+					entries = append(entries, entry{Name: name, SSH: context.SSH, Dir: context.Dir, Env: context.Env, Groups: append([]string{}, def.GroupsOf(name)...)})
+				}
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(entries)
+			}
+
+			if len(names) == 0 {
+				fmt.Fprintf(out, "No contexts defined in %s; add them with 'mineos state edit contexts'.\n", source)
+				return nil
+			}
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CONTEXT\tTARGET\tGROUPS")
+			for _, name := range names {
+				groups := strings.Join(def.GroupsOf(name), ", ")
+				if groups == "" {
+					groups = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", name, def.Contexts[name].Target(), groups)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+func newFleetExecCommand(sel *fleetSelection) *cobra.Command {
+	var parallel int
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "exec -- <command> [args...]",
+		Short: "Run a mineos command on every selected context",
+		Long: `Run a mineos command on every selected context, at most --parallel at a
+time. Output is prefixed with the context's name as it arrives, and a table
+of every context's result closes the run.
+
+Commands run without a terminal, so anything that would prompt fails
+instead; pass --yes where a command asks for confirmation.`,
+		Example: `  mineos fleet exec --context-group prod -- servers list
+  mineos fleet exec --context eu-1,eu-2 -- servers backup survival
+  mineos fleet exec -g prod --json -- status`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rounds := [][]string{args}
+			return runFleetCommand(cmd, sel, jsonOut, parallel, -1, rounds)
+		},
+	}
+	// Flags after the command belong to it, not to fleet exec.
+	cmd.Flags().SetInterspersed(false)
+
+	cmd.Flags().IntVar(&parallel, "parallel", 4, "Maximum number of contexts the command runs on concurrently")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output every context's machine mode document as JSON")
+
+	return cmd
+}
+
+func newFleetUpdateCommand(sel *fleetSelection) *cobra.Command {
+	var parallel int
+	var maxFailures int
+	var timeout int
+	var noVerify bool
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the stack on every selected context, a few at a time",
+		Long: `Run 'mineos stack update' on the selected contexts in the group's order,
+--parallel at a time (one by default), and check each with 'mineos health'
+before counting it as updated.
+
+Once more than --max-failures contexts have failed, no further context is
+started, so a bad image stops at the first host instead of taking down the
+whole fleet; the rest are reported as skipped.`,
+		Example: `  mineos fleet update --context-group prod
+  mineos fleet update -g prod --parallel 2 --max-failures 1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if maxFailures < 0 {
+				return fmt.Errorf("--max-failures must be 0 or more")
+			}
+			update := []string{"stack", "update"}
+			if timeout > 0 {
+				update = append(update, "--timeout", fmt.Sprint(timeout))
+			}
+			rounds := [][]string{update}
+			if !noVerify {
+				rounds = append(rounds, []string{"health"})
+			}
+			return runFleetCommand(cmd, sel, jsonOut, parallel, maxFailures, rounds)
+		},
+	}
+
+	cmd.Flags().IntVar(&parallel, "parallel", 1, "Maximum number of contexts updated concurrently")
+	cmd.Flags().IntVar(&maxFailures, "max-failures", 0, "Failed contexts tolerated before the rollout stops")
+	cmd.Flags().IntVar(&timeout, "timeout", 0, "Shutdown timeout in seconds (default from each context's .env)")
+	cmd.Flags().BoolVar(&noVerify, "no-verify", false, "Skip the health check after each update")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output every context's machine mode documents as JSON")
+
+	return cmd
+}
+
+// fleetResult is how a command went on one context.
+type fleetResult struct {
+	Context  string  `json:"context"`
+	ExitCode int     `json:"exit_code"`
+	Skipped  bool    `json:"skipped,omitempty"`
+	Error    string  `json:"error,omitempty"`
+	Seconds  float64 `json:"duration_seconds"`
+	// Runs are the machine mode documents of the commands, with --json.
+	Runs []json.RawMessage `json:"runs,omitempty"`
+
+	duration time.Duration
+}
+
+func (r fleetResult) failed() bool {
+	return !r.Skipped && (r.ExitCode != 0 || r.Error != "")
+}
+
+// runFleetCommand runs the commands of rounds one after another on each
+// selected context. maxFailures below 0 never stops the run.
+func runFleetCommand(cmd *cobra.Command, sel *fleetSelection, jsonOut bool, parallel, maxFailures int, rounds [][]string) error {
+	if parallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	if sel.group == "" && len(sel.contexts) == 0 {
+		return fmt.Errorf("--context-group or --context is required")
+	}
+	def, _, dir, err := loadFleetDefinition(sel.file)
+	if err != nil {
+		return err
+	}
+	names, err := def.Select(sel.group, sel.contexts)
+	if err != nil {
+		return ports.WithKind(err, ports.ErrNotFound)
+	}
+	if len(names) == 0 {
+		return ports.WithKind(fmt.Errorf("context group %s is empty", sel.group), ports.ErrNotFound)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	started := time.Now()
+	if !jsonOut {
+		commands := make([]string, len(rounds))
+		for i, args := range rounds {
+			commands[i] = "mineos " + strings.Join(args, " ")
+		}
+		fmt.Fprintf(decor(out), "Running %s on %d context(s) (parallel: %d)...\n", strings.Join(commands, ", then "), len(names), parallel)
+	}
+	lines := &fleetOutput{out: out}
+	results := runFleet(cmd.Context(), names, parallel, maxFailures, func(ctx context.Context, name string) fleetResult {
+		args := fleetContextArgs(def.Contexts[name], dir)
+		result := fleetResult{Context: name}
+		for _, round := range rounds {
+			code, doc, err := runFleetStep(ctx, exe, append(append([]string{}, args...), round...), jsonOut, lines.prefixed(name))
+			if doc != nil {
+				result.Runs = append(result.Runs, doc)
+			}
+			result.ExitCode = code
+			if err != nil {
+				result.Error = err.Error()
+			} else if code != 0 {
+				result.Error = fmt.Sprintf("'mineos %s' exited with status %d", strings.Join(round, " "), code)
+			}
+			if result.failed() {
+				break
+			}
+		}
+		return result
+	})
+
+	failed, skipped := 0, 0
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			skipped++
+		case result.failed():
+			failed++
+		}
+	}
+	if jsonOut {
+		encoder := json.NewEncoder(out)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		printFleetResults(out, results, failed, skipped, time.Since(started))
+	}
+
+	if failed == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("%d of %d context(s) failed", failed, len(results))
+	if skipped > 0 {
+		message += fmt.Sprintf(", %d skipped", skipped)
+	}
+	if failed+skipped < len(results) {
+		return exitCodeError{code: exitPartial, message: message}
+	}
+	return errors.New(message)
+}
+
+// runFleet calls run for each context in order, at most parallel at once.
+// Once more than maxFailures have failed, the contexts not yet started are
+// skipped.
+func runFleet(ctx context.Context, names []string, parallel, maxFailures int, run func(ctx context.Context, name string) fleetResult) []fleetResult {
+	results := make([]fleetResult, len(names))
+	slots := make(chan struct{}, parallel)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	failures := 0
+	for i, name := range names {
+		slots <- struct{}{}
+		mu.Lock()
+		halted := maxFailures >= 0 && failures > maxFailures
+		mu.Unlock()
+		if halted || ctx.Err() != nil {
+			<-slots
+			for j := i; j < len(names); j++ {
+				results[j] = fleetResult{Context: names[j], Skipped: true}
+			}
+			break
+		}
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			start := time.Now()
+			result := run(ctx, name)
+			result.duration = time.Since(start)
+			result.Seconds = result.duration.Round(100 * time.Millisecond).Seconds()
+			mu.Lock()
+			results[i] = result
+			if result.failed() {
+				failures++
+			}
+			mu.Unlock()
+			<-slots
+		}(i, name)
+	}
+	wg.Wait()
+	return results
+}
+
+// fleetContextArgs are the global flags that point the CLI at a context.
+func fleetContextArgs(context domainfleet.Context, dir string) []string {
+	if context.SSH != "" {
+		args := []string{"--ssh", context.SSH}
+		if context.Dir != "" {
+			args = append(args, "--ssh-dir", context.Dir)
+		}
+		return args
+	}
+	env := context.Env
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(env, "~/") {
+		env = filepath.Join(home, env[2:])
+	}
+	if !filepath.IsAbs(env) {
+		env = filepath.Join(dir, env)
+	}
+	return []string{"--env", env}
+}
+
+// runFleetStep runs the CLI with args and no terminal. Its output goes to
+// out, or with jsonOut is its machine mode document.
+func runFleetStep(ctx context.Context, exe string, args []string, jsonOut bool, out io.Writer) (int, json.RawMessage, error) {
+	var doc bytes.Buffer
+	if jsonOut {
+		args = append([]string{"--machine"}, args...)
+	}
+	child := exec.CommandContext(ctx, exe, args...)
+	child.Env = append(os.Environ(), MachineEnv+"=0")
+	if jsonOut {
+		child.Stdout = &doc
+		child.Stderr = io.Discard
+	} else {
+		child.Stdout = out
+		child.Stderr = out
+	}
+	err := child.Run()
+	if closer, ok := out.(io.Closer); ok {
+		closer.Close()
+	}
+	var raw json.RawMessage
+	if jsonOut && json.Valid(bytes.TrimSpace(doc.Bytes())) {
+		raw = json.RawMessage(bytes.TrimSpace(doc.Bytes()))
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), raw, nil
+	}
+	if err != nil {
+		return exitFailure, raw, err
+	}
+	return 0, raw, nil
+}
+
+// fleetOutput interleaves the output of several contexts a line at a time,
+// each line led by its context's name.
+type fleetOutput struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (o *fleetOutput) prefixed(name string) io.Writer {
+	separator := " │"
+	if accessibleMode {
+		separator = ":"
+	}
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			o.mu.Lock()
+			fmt.Fprintf(o.out, "%s %s\n", styleDim.Render(name+separator), ansiEscape.ReplaceAllString(scanner.Text(), ""))
+			o.mu.Unlock()
+		}
+		io.Copy(io.Discard, reader)
+	}()
+	return &fleetLineWriter{writer: writer, done: done}
+}
+
+// fleetLineWriter waits on Close until every line has been printed.
+type fleetLineWriter struct {
+	writer *io.PipeWriter
+	done   chan struct{}
+}
+
+func (w *fleetLineWriter) Write(data []byte) (int, error) { return w.writer.Write(data) }
+
+func (w *fleetLineWriter) Close() error {
+	err := w.writer.Close()
+	<-w.done
+	return err
+}
+
+func printFleetResults(out io.Writer, results []fleetResult, failed, skipped int, took time.Duration) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(decor(out))
+	fmt.Fprintln(w, "CONTEXT\tRESULT\tDURATION")
+	for _, result := range results {
+		status := "ok"
+		duration := result.duration.Round(100 * time.Millisecond).String()
+		switch {
+		case result.Skipped:
+			status = "skipped"
+			duration = "-"
+		case result.failed():
+			status = "failed: " + result.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Context, status, duration)
+	}
+	w.Flush()
+	if skipped > 0 {
+		fmt.Fprintf(decor(out), "\n%s after %d failure(s); %d context(s) were not started.\n", styleWarning.Render("Rollout stopped"), failed, skipped)
+	}
+	printSummary(out, len(results)-failed-skipped, failed, took)
+}
+
+// loadFleetDefinition reads the contexts from file, or MINEOS_CONTEXTS_FILE,
+// or else the state store. It also returns where they came from and the
+// directory relative env paths start at.
+func loadFleetDefinition(file string) (domainfleet.Definition, string, string, error) {
+	if strings.TrimSpace(file) == "" {
+		file = strings.TrimSpace(os.Getenv(fleet.FileEnv))
+	}
+	if file == "" {
+		repo := fleet.NewStateRepository(state.DefaultPath())
+		def, _, err := repo.Load()
+		if err != nil {
+			return def, repo.Path(), repo.Dir(), fmt.Errorf("invalid contexts in %s: %w", repo.Path(), err)
+		}
+		return def, repo.Path(), repo.Dir(), nil
+	}
+	repo := fleet.NewFileRepository(file)
+	def, exists, err := repo.Load()
+	if err != nil {
+		return def, repo.Path(), filepath.Dir(repo.Path()), fmt.Errorf("failed to read %s: %w", repo.Path(), err)
+	}
+	if !exists {
+		return def, repo.Path(), filepath.Dir(repo.Path()), ports.WithKind(fmt.Errorf("contexts file %s does not exist", repo.Path()), ports.ErrNotFound)
+	}
+	return def, repo.Path(), filepath.Dir(repo.Path()), nil
+}
@@ -0,0 +1,496 @@
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/portmap"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/proxy"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/geyser"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/slp"
+)
+
+// floodgateKeyFileName is the shared Floodgate key kept next to .env so new
+// backends can be given the same key later.
+const floodgateKeyFileName = "floodgate-key.pem"
+
+func NewGeyserCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "geyser",
+		Short: "Set up Bedrock crossplay with Geyser and Floodgate",
+		Long: `Install and configure Geyser (lets Bedrock clients join Java servers) and
+Floodgate (lets them join without a Java account).
+
+Geyser goes on the server players connect to: a Paper/Spigot server, or
+the Velocity/BungeeCord proxy in front of a network. Behind a proxy, pass
+the backends with --backends so they get Floodgate and the shared key.
+
+Bedrock clients connect over UDP (default port 19132), which must be
+published by Docker (BEDROCK_PORT_RANGE) and forwarded on your router.`,
+	}
+
+	cmd.AddCommand(newGeyserInstallCommand(loadConfig))
+	cmd.AddCommand(newGeyserConfigureCommand(loadConfig))
+	cmd.AddCommand(newGeyserSyncKeyCommand(loadConfig))
+	cmd.AddCommand(newGeyserStatusCommand(loadConfig))
+
+	return cmd
+}
+
+type geyserOptions struct {
+	platform    string
+	bedrockPort int
+	backends    []string
+	noFloodgate bool
+}
+
+func (o *geyserOptions) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.platform, "platform", "", "Server platform: spigot, velocity or bungeecord (default: detect)")
+	cmd.Flags().IntVar(&o.bedrockPort, "bedrock-port", 0, "UDP port for Bedrock clients (default: 19132 or the next free port)")
+	cmd.Flags().StringSliceVar(&o.backends, "backends", nil, "Backend servers behind the proxy that also get Floodgate")
+	cmd.Flags().BoolVar(&o.noFloodgate, "no-floodgate", false, "Skip Floodgate; Bedrock players then need a Java account")
+	_ = cmd.RegisterFlagCompletionFunc("platform", cobra.FixedCompletions([]string{proxy.PlatformSpigot, proxy.PlatformVelocity, proxy.PlatformBungee}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func newGeyserInstallCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var opts geyserOptions
+
+	cmd := &cobra.Command{
+		Use:   "install <server>",
+		Short: "Download Geyser and Floodgate onto a server or proxy",
+		Example: `  mineos geyser install survival
+  mineos geyser install proxy --backends lobby,survival`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			ctx := context.Background()
+			downloads := geyser.NewClient()
+			downloads.SetRateLimit(transferLimiter)
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(cfg config.Config, client *api.Client) error {
+				platform, err := resolveGeyserPlatform(ctx, client, name, opts.platform)
+				if err != nil {
+					return err
+				}
+				if err := checkGeyserBackends(ctx, client, platform, opts.backends); err != nil {
+					return err
+				}
+				port, err := chooseBedrockPort(ctx, client, cfg, name, platform, opts.bedrockPort)
+				if err != nil {
+					return err
+				}
+
+				if err := installGeyserPlugin(ctx, client, downloads, cmd, name, geyser.ProjectGeyser, platform); err != nil {
+					return err
+				}
+				if !opts.noFloodgate {
+					if err := installGeyserPlugin(ctx, client, downloads, cmd, name, geyser.ProjectFloodgate, proxy.FloodgateDownload(platform)); err != nil {
+						return err
+					}
+					for _, backend := range opts.backends {
+						if err := installGeyserPlugin(ctx, client, downloads, cmd, backend, geyser.ProjectFloodgate, proxy.PlatformSpigot); err != nil {
+							return err
+						}
+					}
+					if err := syncFloodgateKey(ctx, client, cfg, cmd, append([]string{name}, opts.backends...)); err != nil {
+						return err
+					}
+				}
+
+				configured, err := configureGeyser(ctx, client, cmd, name, platform, port, opts)
+				if err != nil {
+					return err
+				}
+				printBedrockPortCheck(cmd, cfg, port)
+				if configured {
+					cmd.Printf("Restart %s to load Geyser: mineos servers restart %s\n", name, name)
+				} else {
+					cmd.Printf("Start %s once so Geyser writes its config, then run: mineos geyser configure %s --bedrock-port %d\n", name, name, port)
+				}
+				return nil
+			})
+			return err
+		},
+	}
+
+	opts.register(cmd)
+
+	return cmd
+}
+
+func newGeyserConfigureCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var opts geyserOptions
+
+	cmd := &cobra.Command{
+		Use:   "configure <server>",
+		Short: "Set the Bedrock port and Floodgate auth in Geyser's config",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			ctx := context.Background()
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(cfg config.Config, client *api.Client) error {
+				platform, err := resolveGeyserPlatform(ctx, client, name, opts.platform)
+				if err != nil {
+					return err
+				}
+				port, err := chooseBedrockPort(ctx, client, cfg, name, platform, opts.bedrockPort)
+				if err != nil {
+					return err
+				}
+				configured, err := configureGeyser(ctx, client, cmd, name, platform, port, opts)
+				if err != nil {
+					return err
+				}
+				if !configured {
+					return fmt.Errorf("%s not found; start %s once so Geyser generates it", proxy.GeyserConfigFile(platform), name)
+				}
+				printBedrockPortCheck(cmd, cfg, port)
+				cmd.Printf("Restart %s to apply: mineos servers restart %s\n", name, name)
+				return nil
+			})
+			return err
+		},
+	}
+
+	opts.register(cmd)
+
+	return cmd
+}
+
+func newGeyserSyncKeyCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync-key <server>...",
+		Short: "Copy the shared Floodgate key to servers",
+		Long: `Write the shared Floodgate key (` + floodgateKeyFileName + ` next to .env, created
+on first use) to plugins/floodgate/key.pem on every given server. The proxy
+and all of its backends must use the same key.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(cfg config.Config, client *api.Client) error {
+				if err := syncFloodgateKey(ctx, client, cfg, cmd, args); err != nil {
+					return err
+				}
+				cmd.Println(styleDim.Render("Restart the servers to load the new key."))
+				return nil
+			})
+			return err
+		},
+	}
+}
+
+func newGeyserStatusCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var platformFlag string
+
+	cmd := &cobra.Command{
+		Use:   "status <server>",
+		Short: "Show Geyser/Floodgate installation, config, port and reachability",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			ctx := context.Background()
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(cfg config.Config, client *api.Client) error {
+				platform, err := resolveGeyserPlatform(ctx, client, name, platformFlag)
+				if err != nil {
+					return err
+				}
+				plugins, err := client.ListPlugins(ctx, name)
+				if err != nil {
+					return err
+				}
+				out := cmd.OutOrStdout()
+				cmd.Println(styleTitle.Render(name))
+				printStat(out, "Platform", platform)
+				printStat(out, "Geyser", installedPlugin(plugins, "geyser"))
+				printStat(out, "Floodgate", installedPlugin(plugins, "floodgate"))
+
+				if _, err := client.ReadServerFile(ctx, name, proxy.FloodgateKeyFile); err == nil {
+					printStat(out, "Key", "present")
+				} else if errors.Is(err, api.ErrNotFound) {
+					printStat(out, "Key", styleWarning.Render("missing (mineos geyser sync-key "+name+")"))
+				}
+
+				content, err := client.ReadServerFile(ctx, name, proxy.GeyserConfigFile(platform))
+				if err != nil {
+					if !errors.Is(err, api.ErrNotFound) {
+						return err
+					}
+					printStat(out, "Config", styleWarning.Render("not generated yet (start the server once)"))
+					return nil
+				}
+				settings, err := proxy.ReadGeyserConfig(content)
+				if err != nil {
+					return fmt.Errorf("failed to parse %s: %w", proxy.GeyserConfigFile(platform), err)
+				}
+				port := settings.BedrockPort
+				if settings.CloneRemotePort {
+					printStat(out, "Bedrock port", styleWarning.Render("clone-remote-port is on (Java port reused over UDP)"))
+				} else {
+					printStat(out, "Bedrock port", strconv.Itoa(port)+"/udp")
+				}
+				printStat(out, "Auth", fallback(settings.AuthType, "online"))
+				if problem := bedrockPortProblem(cfg, port); problem != "" {
+					printStat(out, "Published", styleWarning.Render(problem))
+				} else {
+					printStat(out, "Published", styleSuccess.Render("yes"))
+				}
+
+				detail, err := client.GetServer(ctx, name)
+				if err != nil || !detail.IsRunning() {
+					printStat(out, "Reachable", styleDim.Render("server not running"))
+					return nil
+				}
+				address := cfg.MinecraftAddress(strconv.Itoa(port))
+				pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+				defer cancel()
+				status, err := slp.PingBedrock(pingCtx, address)
+				if err != nil {
+					printStat(out, "Reachable", styleError.Render("no answer from "+address))
+					return nil
+				}
+				printStat(out, "Reachable", styleSuccess.Render(fmt.Sprintf("%s (%d ms, %d/%d players)", address, status.Latency.Milliseconds(), status.PlayersOnline, status.PlayersMax)))
+				return nil
+			})
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&platformFlag, "platform", "", "Server platform: spigot, velocity or bungeecord (default: detect)")
+
+	return cmd
+}
+
+// resolveGeyserPlatform detects whether a server is a Velocity/BungeeCord
+// proxy or a Bukkit-based backend.
+func resolveGeyserPlatform(ctx context.Context, client *api.Client, name, override string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(override)) {
+	case "":
+	case proxy.PlatformSpigot, "paper", "bukkit":
+		return proxy.PlatformSpigot, nil
+	case proxy.PlatformVelocity:
+		return proxy.PlatformVelocity, nil
+	case proxy.PlatformBungee, "bungee", "waterfall":
+		return proxy.PlatformBungee, nil
+	default:
+		return "", fmt.Errorf("unknown platform %q (spigot, velocity or bungeecord)", override)
+	}
+
+	detail, err := client.GetServer(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if detail.IsBedrock() {
+		return "", fmt.Errorf("%s is a Bedrock server; Geyser is installed on Java servers so Bedrock clients can join them", name)
+	}
+	if pc, err := loadProxyConfig(ctx, client, name); err == nil {
+		return pc.kind, nil
+	}
+
+	serverCfg, err := client.GetServerConfig(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	jar := ""
+	if serverCfg.Minecraft.Profile != nil {
+		jar += *serverCfg.Minecraft.Profile + " "
+	}
+	if serverCfg.Java.JarFile != nil {
+		jar += *serverCfg.Java.JarFile
+	}
+	jar = strings.ToLower(jar)
+	switch {
+	case strings.Contains(jar, "velocity"):
+		return proxy.PlatformVelocity, nil
+	case strings.Contains(jar, "bungee") || strings.Contains(jar, "waterfall"):
+		return proxy.PlatformBungee, nil
+	case containsAny(jar, "paper", "purpur", "spigot", "folia", "pufferfish", "bukkit"):
+		return proxy.PlatformSpigot, nil
+	}
+	return "", fmt.Errorf("could not tell which platform %s runs (vanilla servers cannot load plugins); pass --platform spigot, velocity or bungeecord", name)
+}
+
+func containsAny(value string, needles ...string) bool {
+	for _, needle := range needles {
+		if strings.Contains(value, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkGeyserBackends(ctx context.Context, client *api.Client, platform string, backends []string) error {
+	if len(backends) == 0 {
+		return nil
+	}
+	if platform == proxy.PlatformSpigot {
+		return errors.New("--backends only applies when Geyser is installed on a proxy")
+	}
+	for _, backend := range backends {
+		backendPlatform, err := resolveGeyserPlatform(ctx, client, backend, "")
+		if err != nil {
+			return err
+		}
+		if backendPlatform != proxy.PlatformSpigot {
+			return fmt.Errorf("backend %s is a %s proxy, not a Paper/Spigot server", backend, backendPlatform)
+		}
+	}
+	return nil
+}
+
+func installGeyserPlugin(ctx context.Context, client *api.Client, downloads *geyser.Client, cmd *cobra.Command, name, project, download string) error {
+	build, err := downloads.Latest(ctx, project, download)
+	if err != nil {
+		return err
+	}
+	cmd.Printf("Downloading %s %s (build %d)...\n", build.FileName, build.Version, build.Build)
+	jar, err := downloads.Download(ctx, build)
+	if err != nil {
+		return err
+	}
+	if err := client.UploadPlugin(ctx, name, build.FileName, jar); err != nil {
+		return err
+	}
+	cmd.Printf("Installed %s on %s\n", build.FileName, name)
+	return nil
+}
+
+// configureGeyser applies the port and auth settings. It reports false when
+// Geyser has not generated its config yet.
+func configureGeyser(ctx context.Context, client *api.Client, cmd *cobra.Command, name, platform string, port int, opts geyserOptions) (bool, error) {
+	file := proxy.GeyserConfigFile(platform)
+	content, err := client.ReadServerFile(ctx, name, file)
+	if errors.Is(err, api.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	updated, err := proxy.ConfigureGeyser(content, port, !opts.noFloodgate)
+	if err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", file, err)
+	}
+	if err := client.WriteServerFile(ctx, name, file, updated); err != nil {
+		return false, err
+	}
+	cmd.Printf("Set bedrock.port=%d in %s\n", port, file)
+
+	if !opts.noFloodgate && len(opts.backends) > 0 {
+		floodgate, err := client.ReadServerFile(ctx, name, proxy.FloodgateConfigFile)
+		if err == nil {
+			if floodgate, err = proxy.SetFloodgateSendData(floodgate, true); err != nil {
+				return false, err
+			}
+			if err := client.WriteServerFile(ctx, name, proxy.FloodgateConfigFile, floodgate); err != nil {
+				return false, err
+			}
+			cmd.Printf("Enabled send-floodgate-data on %s\n", name)
+		} else if !errors.Is(err, api.ErrNotFound) {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// chooseBedrockPort validates a requested UDP port. Otherwise it keeps the
+// port Geyser is already configured with, or picks 19132 or the next port
+// not taken by another server.
+func chooseBedrockPort(ctx context.Context, client *api.Client, cfg config.Config, name, platform string, requested int) (int, error) {
+	allocs, _, err := usecases.NewServerPortsUseCase(client).Execute(ctx)
+	if err != nil {
+		return 0, err
+	}
+	used := map[int]bool{}
+	for _, alloc := range allocs {
+		if alloc.Protocol == "udp" && alloc.Server != name {
+			used[alloc.Port] = true
+		}
+	}
+	if requested > 0 {
+		if used[requested] {
+			return 0, fmt.Errorf("UDP port %d is already used by another server", requested)
+		}
+		return requested, nil
+	}
+	if content, err := client.ReadServerFile(ctx, name, proxy.GeyserConfigFile(platform)); err == nil {
+		if settings, err := proxy.ReadGeyserConfig(content); err == nil && !settings.CloneRemotePort && !used[settings.BedrockPort] {
+			return settings.BedrockPort, nil
+		}
+	}
+	checker := newPortChecker(cfg)
+	start := max(proxy.DefaultBedrockPort, checker.bedrockRange.From)
+	if !checker.hostMode && !checker.bedrockRange.Contains(start) {
+		start = checker.bedrockRange.From
+	}
+	port := portmap.NextFree(start, used, func(int) bool { return true })
+	if port == 0 {
+		return 0, errors.New("no free UDP port found for Geyser; pass --bedrock-port")
+	}
+	return port, nil
+}
+
+func bedrockPortProblem(cfg config.Config, port int) string {
+	checker := newPortChecker(cfg)
+	if checker.hostMode || checker.bedrockRange.Contains(port) {
+		return ""
+	}
+	return "not published (outside BEDROCK_PORT_RANGE " + checker.bedrockRange.String() + ")"
+}
+
+func printBedrockPortCheck(cmd *cobra.Command, cfg config.Config, port int) {
+	if problem := bedrockPortProblem(cfg, port); problem != "" {
+		cmd.Printf("%s UDP port %d is %s; widen BEDROCK_PORT_RANGE with 'mineos reconfigure'.\n", styleWarning.Render("Warning:"), port, problem)
+		return
+	}
+	cmd.Printf("Bedrock clients connect on UDP port %d; forward it on your router for players outside your network.\n", port)
+}
+
+// syncFloodgateKey writes the shared key to each server, creating it on
+// first use.
+func syncFloodgateKey(ctx context.Context, client *api.Client, cfg config.Config, cmd *cobra.Command, servers []string) error {
+	path := filepath.Join(filepath.Dir(fallback(cfg.EnvPath, ".env")), floodgateKeyFileName)
+	key, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Floodgate keys are raw 128-bit AES keys.
+		key = make([]byte, 16)
+		if _, err := rand.Read(key); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, key, 0o600); err != nil {
+			return fmt.Errorf("failed to save Floodgate key: %w", err)
+		}
+		cmd.Printf("Created shared Floodgate key %s\n", path)
+	} else if err != nil {
+		return err
+	}
+	for _, server := range servers {
+		if err := client.WriteServerFileBytes(ctx, server, proxy.FloodgateKeyFile, key); err != nil {
+			return fmt.Errorf("%s: %w", server, err)
+		}
+		cmd.Printf("Wrote Floodgate key to %s\n", server)
+	}
+	return nil
+}
+
+func installedPlugin(plugins []ports.InstalledPlugin, prefix string) string {
+	for _, plugin := range plugins {
+		if strings.HasPrefix(strings.ToLower(plugin.FileName), prefix) {
+			if plugin.IsDisabled {
+				return styleWarning.Render(plugin.FileName + " (disabled)")
+			}
+			return styleSuccess.Render(plugin.FileName)
+		}
+	}
+	return styleDim.Render("not installed")
+}
@@ -2,17 +2,33 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
 )
 
 func NewHealthCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
-	return &cobra.Command{
+	var deep bool
+	var jsonOut bool
+
+	cmd := &cobra.Command{
 		Use:   "health",
 		Short: "Check MineOS API health",
+		Long: `Check that the MineOS API answers its /health endpoint.
+
+With --deep, also check from inside the containers that the API can reach
+its database, write to the data and server base directories and talk to the
+Docker socket, and that the web UI can reach the API. Each check is
+reported separately with a hint on fixing it.`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := context.Background()
 			cfg, err := loadConfig.Execute(ctx)
@@ -20,12 +36,135 @@ func NewHealthCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 				return err
 			}
 			client := api.NewClientFromConfig(cfg)
-			uc := usecases.NewHealthCheckUseCase(client)
-			if err := uc.Execute(ctx); err != nil {
-				return err
+			if !deep {
+				uc := usecases.NewHealthCheckUseCase(client)
+				if err := uc.Execute(ctx); err != nil {
+					return err
+				}
+				cmd.Println("OK")
+				return nil
+			}
+
+			checks := deepHealthChecks(ctx, cfg, client)
+			failed := 0
+			for _, check := range checks {
+				if !check.OK {
+					failed++
+				}
+			}
+			out := cmd.OutOrStdout()
+			if jsonOut {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(checks); err != nil {
+					return err
+				}
+			} else {
+				printHealthChecks(out, checks)
+			}
+			if failed > 0 {
+				return exitCodeError{code: 1, message: fmt.Sprintf("%d of %d health checks failed", failed, len(checks))}
+			}
+			if !jsonOut {
+				fmt.Fprintln(out, styleSuccess.Render("All checks passed."))
 			}
-			cmd.Println("OK")
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&deep, "deep", false, "Also check the database, storage, Docker socket and web to API connection")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the --deep checks as JSON")
+
+	return cmd
+}
+
+type healthCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// deepHealthChecks runs every check, even after one fails, so the report
+// shows all that is wrong at once. The storage and socket checks run inside
+// the api container with its user and mounts.
+func deepHealthChecks(ctx context.Context, cfg config.Config, client *api.Client) []healthCheck {
+	checks := []healthCheck{}
+	add := func(name string, err error, detail, hint string) {
+		check := healthCheck{Name: name, OK: err == nil, Detail: detail}
+		if err != nil {
+			check.Detail = err.Error()
+			check.Hint = hint
+		}
+		checks = append(checks, check)
+	}
+
+	apiErr := client.Health(ctx)
+	add("API", apiErr, cfg.ApiURL(), "Start the stack with 'mineos stack up' and check 'mineos stack logs api'.")
+
+	// The API key is looked up in the database, so an authenticated request
+	// fails when the database cannot be opened.
+	dbErr := errors.New("skipped: the API is not reachable")
+	dbHint := ""
+	if apiErr == nil {
+		_, dbErr = client.ListServers(ctx)
+		dbHint = "Check 'mineos stack logs api' for SQLite errors and that Data__Directory is writable."
+	}
+	switch {
+	case errors.Is(dbErr, api.ErrApiKeyMissing):
+		dbHint = "Set MINEOS_API_KEY in .env or run 'mineos api-key refresh'."
+	case errors.Is(dbErr, api.ErrApiKeyInvalid):
+		dbHint = "The API key was not found in the database; run 'mineos api-key refresh'."
+	}
+	add("Database", dbErr, "API key lookup succeeded", dbHint)
+
+	compose, err := detectCompose()
+	if err != nil {
+		for _, name := range []string{"Data directory", "Base directory", "Docker socket", "Web to API"} {
+			add(name, err, "", "Install Docker Compose.")
+		}
+		return checks
+	}
+	compose = composeWithConfig(compose, cfg)
+	exec := func(service, script string) error {
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+		defer cancel()
+		output, err := compose.command(ctx, []string{"exec", "-T", service, "sh", "-c", script}, nil).CombinedOutput()
+		if err != nil {
+			return errors.New(fallback(strings.TrimSpace(string(output)), err.Error()))
+		}
+		return nil
+	}
+	// A write, not just a permission test, catches read-only mounts and
+	// full disks.
+	writable := func(dir string) string {
+		return fmt.Sprintf(`f=%q/.mineos-health-$$; echo ok > "$f" && rm -f "$f"`, dir)
+	}
+
+	add("Data directory", exec("api", writable("/app/data")), "/app/data is writable",
+		fmt.Sprintf("Make %s on the host writable by the API container (%s) with free space.", fallback(cfg.DataDirectory, "./data"), hostOwnershipHint()))
+	add("Base directory", exec("api", writable(containerBaseDir+"/servers")), containerBaseDir+" is writable",
+		"Make HOST_BASE_DIRECTORY on the host writable with free space; on SELinux hosts relabel it (chcon -Rt container_file_t).")
+	add("Docker socket", exec("api", `curl -fsS --max-time 5 --unix-socket /var/run/docker.sock http://localhost/_ping >/dev/null`), "the API reaches the Docker daemon",
+		"Mount /var/run/docker.sock into the api service (see docker-compose.yml); with rootless Docker mount $XDG_RUNTIME_DIR/docker.sock there instead.")
+	add("Web to API", exec("web", `node -e 'fetch(process.env.PRIVATE_API_BASE_URL + "/api/v1/health").then(r => process.exit(r.ok ? 0 : 1), e => { console.error(e.cause?.message || e.message); process.exit(1) })'`), "the web UI reaches the API",
+		"Check that both containers are on the mineos-network and PRIVATE_API_BASE_URL points at the api service, then run 'mineos stack restart'.")
+	return checks
+}
+
+func printHealthChecks(out io.Writer, checks []healthCheck) {
+	width := 0
+	for _, check := range checks {
+		width = max(width, len(check.Name))
+	}
+	for _, check := range checks {
+		mark := markOK()
+		if !check.OK {
+			mark = markFailed()
+		}
+		fmt.Fprintf(out, "%s %-*s  %s\n", mark, width, check.Name, check.Detail)
+		if check.Hint != "" {
+			fmt.Fprintln(out, "  "+styleDim.Render(check.Hint))
+		}
+	}
 }
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+)
+
+// hostStorage locates server files on the host, for commands that work on
+// the files directly rather than through the API.
+type hostStorage struct {
+	Base    string
+	Servers string
+	Backups string
+	// Archives holds what 'mineos backup' writes, one folder per server.
+	Archives string
+	// Snapshots holds btrfs snapshots taken by 'mineos backup create
+	// --snapshot'; ZFS and LVM keep theirs in the pool or volume group.
+	Snapshots string
+}
+
+func (s hostStorage) ServerDir(name string) string {
+	return filepath.Join(s.Servers, name)
+}
+
+// BackupDir is the rdiff-backup repository of a server; its top level is a
+// plain copy of the server as of the latest backup.
+func (s hostStorage) BackupDir(name string) string {
+	return filepath.Join(s.Backups, name)
+}
+
+// loadHostStorage resolves HOST_BASE_DIRECTORY and the path segments from
+// .env, relative to the .env file like docker compose does.
+func loadHostStorage(ctx context.Context, loadConfig *usecases.LoadConfigUseCase) (hostStorage, error) {
+	cfg, err := loadConfig.Execute(ctx)
+	if err != nil {
+		return hostStorage{}, err
+	}
+	envPath := fallback(cfg.EnvPath, ".env")
+	values, err := loadLayeredEnvValues(cfg)
+	if err != nil {
+		return hostStorage{}, fmt.Errorf("read %s: %w", envPath, err)
+	}
+	base := fallback(strings.TrimSpace(values["HOST_BASE_DIRECTORY"]), defaultHostBaseDir)
+	if !filepath.IsAbs(base) {
+		base = filepath.Join(filepath.Dir(envPath), base)
+	}
+	base = filepath.Clean(base)
+	return hostStorage{
+		Base:      base,
+		Servers:   filepath.Join(base, fallback(strings.TrimSpace(values["Host__ServersPathSegment"]), "servers")),
+		Backups:   filepath.Join(base, fallback(strings.TrimSpace(values["Host__BackupsPathSegment"]), "backups")),
+		Archives:  filepath.Join(base, "archives"),
+		Snapshots: filepath.Join(base, "snapshots"),
+	}, nil
+}
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/presentation/cli/i18n"
+)
+
+func NewI18nCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "i18n",
+		Short: "List translations and help write new ones",
+		Long: `The prompts of the CLI and the TUI come from message catalogs, one JSON
+file per locale. The locale is taken from --lang, ` + i18n.LangEnv + `, LC_ALL,
+LC_MESSAGES or LANG; messages a catalog does not translate are shown in
+English.
+
+To translate, write the template of a locale to the locales directory,
+translate the values, and run any command with --lang to see them. The
+directory is ` + i18n.DirEnv + `, or mineos/locales in your config directory.`,
+	}
+	cmd.AddCommand(newI18nListCommand(), newI18nTemplateCommand(), newI18nCheckCommand())
+	return cmd
+}
+
+func newI18nListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the available locales and how much of each is translated",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := cmd.OutOrStdout()
+			current := i18n.Current()
+			for _, locale := range i18n.Available() {
+				translated, total := i18n.Coverage(locale)
+				marker := "  "
+				if locale == current {
+					marker = styleSuccess.Render("* ")
+				}
+				percent := 0
+				if total > 0 {
+					percent = translated * 100 / total
+				}
+				// Pad by display width: CJK names take two columns a rune.
+				name := i18n.Names[locale]
+				name += strings.Repeat(" ", max(0, 20-lipgloss.Width(name)))
+				fmt.Fprintf(out, "%s%-7s %s %s\n", marker, locale, name,
+					styleDim.Render(fmt.Sprintf("%d%% (%d/%d)", percent, translated, total)))
+			}
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, styleDim.Render("Extra catalogs are read from "+i18n.Dir()))
+			return nil
+		},
+	}
+}
+
+func newI18nTemplateCommand() *cobra.Command {
+	var missing bool
+
+	cmd := &cobra.Command{
+		Use:   "template <locale>",
+		Short: "Print a catalog to translate, with English for what is missing",
+		Long: `Print every message key with the locale's translation, or the English text
+where it has none, as JSON ready to edit. Messages are fmt formats: keep
+their %s and %d, and write %[2]s to put the second argument first.`,
+		Example: `  mineos i18n template fr > ~/.config/mineos/locales/fr.json
+  mineos i18n template de --missing`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			locale := i18n.Normalize(args[0])
+			source := i18n.Catalog(i18n.Source)
+			catalog := i18n.Catalog(locale)
+
+			keys := make([]string, 0, len(source))
+			for key := range source {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			template := map[string]string{}
+			for _, key := range keys {
+				text, ok := catalog[key]
+				if ok && text != "" {
+					if missing {
+						continue
+					}
+				} else {
+					text = source[key]
+				}
+				template[key] = text
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+			return encoder.Encode(template)
+		},
+	}
+	cmd.Flags().BoolVar(&missing, "missing", false, "Only the messages the locale does not translate yet")
+	return cmd
+}
+
+func newI18nCheckCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check [locale...]",
+		Short: "Check catalogs for unknown keys and mismatched arguments",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			locales := i18n.Available()
+			if len(args) > 0 {
+				locales = locales[:0]
+				for _, arg := range args {
+					locales = append(locales, i18n.Normalize(arg))
+				}
+			}
+			failed := false
+			for _, locale := range locales {
+				problems := i18n.Check(locale)
+				if len(problems) == 0 {
+					fmt.Fprintf(out, "%s %s\n", markOK(), locale)
+					continue
+				}
+				failed = true
+				fmt.Fprintf(out, "%s %s\n", markFailed(), locale)
+				for _, problem := range problems {
+					fmt.Fprintf(out, "    %s\n", problem)
+				}
+			}
+			if failed {
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
+				return exitCodeError{code: 1}
+			}
+			return nil
+		},
+	}
+}
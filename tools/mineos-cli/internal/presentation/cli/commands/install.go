@@ -12,6 +12,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -20,7 +21,9 @@ import (
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/telemetry"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/presentation/cli/i18n"
 )
 
 // Installer color palette — consistent with TUI styles
@@ -60,9 +63,13 @@ type installOptions struct {
 	minecraftHost    string
 	bodySizeLimit    string
 	networkMode      string
+	bindFamily       string
 	buildFromSource  bool
 	imageTag         string
+	platform         string
+	limits           resourceLimits
 	quiet            bool
+	skipPathInstall  bool
 
 	telemetryEnabled bool
 }
@@ -81,8 +88,6 @@ const (
  | |\/| | | '_ \ / _ \ | | \___ \
  | |  | | | | | |  __/ |_| |___) |
  |_|  |_|_|_| |_|\___|\___/|____/ `
-
-	installBannerTagline = "Minecraft Server Management"
 )
 
 func NewInstallCommand() *cobra.Command {
@@ -107,9 +112,16 @@ func NewInstallCommand() *cobra.Command {
 	cmd.Flags().StringVar(&opts.minecraftHost, "minecraft-host", "", "Public Minecraft host")
 	cmd.Flags().StringVar(&opts.bodySizeLimit, "body-size-limit", "", "Web UI upload body size limit")
 	cmd.Flags().StringVar(&opts.networkMode, "network-mode", "", "Docker network mode (bridge|host)")
+	cmd.Flags().StringVar(&opts.bindFamily, "bind-family", "", "Address family for published ports (dual|ipv4|ipv6)")
 	cmd.Flags().BoolVar(&opts.buildFromSource, "build", false, "Build images from source instead of pulling")
 	cmd.Flags().StringVar(&opts.imageTag, "image-tag", "", "Image tag to pull when not building from source")
+	cmd.Flags().StringVar(&opts.platform, "platform", "", "Image platform to pull or build, e.g. linux/amd64 (default: the Docker host's)")
+	cmd.Flags().StringVar(&opts.limits.apiMemory, "api-memory", "", "Memory limit of the api container, which also runs the Minecraft servers (e.g. 8g)")
+	cmd.Flags().StringVar(&opts.limits.apiCpus, "api-cpus", "", "CPU limit of the api container and its Minecraft servers (e.g. 3.5)")
+	cmd.Flags().StringVar(&opts.limits.webMemory, "web-memory", "", "Memory limit of the web container (e.g. 512m)")
+	cmd.Flags().StringVar(&opts.limits.webCpus, "web-cpus", "", "CPU limit of the web container (e.g. 0.5)")
 	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", false, "Non-interactive mode (requires --admin, --password)")
+	cmd.Flags().BoolVar(&opts.skipPathInstall, "skip-path-install", false, "Windows: do not add the CLI to PATH, the Start Menu and Apps & Features")
 
 	return cmd
 }
@@ -131,6 +143,20 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 	if err != nil {
 		return err
 	}
+	if err := validatePlatform(opts.platform); err != nil {
+		return err
+	}
+	if err := opts.limits.validate(); err != nil {
+		return err
+	}
+	compose.platform = fallback(opts.platform, strings.TrimSpace(os.Getenv(platformEnvKey)))
+	if host, err := compose.dockerHost(cmd.Context()); err == nil {
+		warnHostResources(out, host)
+	}
+	warnDockerDesktopSharing(out, ".")
+	if self, err := os.Executable(); err == nil {
+		clearQuarantine(self)
+	}
 
 	// In quiet mode, validate required fields
 	if opts.quiet {
@@ -165,6 +191,9 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 		if opts.networkMode == "" {
 			opts.networkMode = defaultNetworkMode
 		}
+		if opts.bindFamily == "" {
+			opts.bindFamily = config.BindDual
+		}
 		if !opts.buildFromSource && opts.imageTag == "" {
 			opts.imageTag = "latest"
 		}
@@ -173,30 +202,33 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 	if _, err := os.Stat(".env"); err == nil {
 		if opts.quiet {
 			// In quiet mode, overwrite without prompting
-			fmt.Fprintln(out, styleWarning.Render("Overwriting existing .env file..."))
+			fmt.Fprintln(out, styleWarning.Render(i18n.T("install.overwriting")))
 		} else {
-			overwrite, err := promptYesNo(reader, out, ".env already exists. Overwrite", false)
+			overwrite, err := promptYesNo(reader, out, i18n.T("install.overwritePrompt"), false)
 			if err != nil {
 				return err
 			}
 			if !overwrite {
-				return errors.New("install cancelled")
+				return errors.New(i18n.T("install.cancelled"))
 			}
 		}
 	}
 
 	if !opts.quiet {
-		fmt.Fprintln(out, styleBanner.Render(installBanner))
-		fmt.Fprintln(out, styleAccent.Render(installBannerTagline))
+		// The ASCII art is noise to a screen reader.
+		if !accessibleMode {
+			fmt.Fprintln(out, styleBanner.Render(installBanner))
+		}
+		fmt.Fprintln(out, styleAccent.Render(i18n.T("install.tagline")))
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleTitle.Render("Welcome to the MineOS installer!"))
-		fmt.Fprintln(out, styleDim.Render("This will set up everything you need to manage Minecraft servers."))
-		fmt.Fprintln(out, styleDim.Render("Press Enter to accept the default values shown in parentheses."))
+		fmt.Fprintln(out, styleTitle.Render(i18n.T("install.welcome")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.welcomeDetail")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.defaultsHint")))
 		fmt.Fprintln(out, "")
 	}
 
 	if opts.adminUser == "" && !opts.quiet {
-		value, err := promptString(reader, out, "Admin username", "admin")
+		value, err := promptString(reader, out, i18n.T("install.adminUser"), "admin")
 		if err != nil {
 			return err
 		}
@@ -205,12 +237,12 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 
 	if opts.adminPass == "" && !opts.quiet {
 		for {
-			value, err := promptPassword(out, "Admin password")
+			value, err := promptPassword(out, i18n.T("install.adminPassword"))
 			if err != nil {
 				return err
 			}
 			if strings.TrimSpace(value) == "" {
-				fmt.Fprintln(out, "Password cannot be empty.")
+				fmt.Fprintln(out, i18n.T("install.passwordEmpty"))
 				continue
 			}
 			opts.adminPass = value
@@ -219,7 +251,7 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 	}
 
 	if opts.hostBaseDir == "" && !opts.quiet {
-		value, err := promptRelativePath(reader, out, "Local storage directory for Minecraft servers (relative)", defaultHostBaseDir)
+		value, err := promptRelativePath(reader, out, i18n.T("install.hostDir"), defaultHostBaseDir)
 		if err != nil {
 			return err
 		}
@@ -229,7 +261,7 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 	}
 
 	if opts.dataDir == "" && !opts.quiet {
-		value, err := promptRelativePath(reader, out, "Database directory (relative)", defaultDataDir)
+		value, err := promptRelativePath(reader, out, i18n.T("install.dataDir"), defaultDataDir)
 		if err != nil {
 			return err
 		}
@@ -240,8 +272,8 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 
 	if opts.apiPort == 0 && !opts.quiet {
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleStep.Render("Backend API port")+" "+styleDim.Render("- Used internally by the server (usually keep default)"))
-		value, err := promptInt(reader, out, "API port", defaultApiPort)
+		fmt.Fprintln(out, styleStep.Render(i18n.T("install.apiPort.title"))+" "+styleDim.Render(i18n.T("install.apiPort.hint")))
+		value, err := promptInt(reader, out, i18n.T("install.apiPort.prompt"), defaultApiPort)
 		if err != nil {
 			return err
 		}
@@ -250,9 +282,9 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 
 	if opts.webPort == 0 && !opts.quiet {
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleStep.Render("Web interface port")+" "+styleDim.Render("- This is the port you'll type in your browser"))
-		fmt.Fprintln(out, styleDim.Render("Example: http://localhost:3000 - You can change this if 3000 is already in use"))
-		value, err := promptInt(reader, out, "Web UI port", defaultWebPort)
+		fmt.Fprintln(out, styleStep.Render(i18n.T("install.webPort.title"))+" "+styleDim.Render(i18n.T("install.webPort.hint")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.webPort.example")))
+		value, err := promptInt(reader, out, i18n.T("install.webPort.prompt"), defaultWebPort)
 		if err != nil {
 			return err
 		}
@@ -262,10 +294,10 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 	if opts.webOrigin == "" && !opts.quiet {
 		defaultOrigin := fmt.Sprintf("http://localhost:%d", opts.webPort)
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleStep.Render("Web interface URL")+" "+styleDim.Render("- The full address you'll use in your browser"))
-		fmt.Fprintln(out, styleDim.Render("If running on this computer, use 'localhost'. If accessing from other devices,"))
-		fmt.Fprintln(out, styleDim.Render("replace 'localhost' with this computer's IP address (e.g., http://192.168.1.100:3000)"))
-		value, err := promptString(reader, out, "Web UI origin", defaultOrigin)
+		fmt.Fprintln(out, styleStep.Render(i18n.T("install.webOrigin.title"))+" "+styleDim.Render(i18n.T("install.webOrigin.hint")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.webOrigin.local")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.webOrigin.remote")))
+		value, err := promptString(reader, out, i18n.T("install.webOrigin.prompt"), defaultOrigin)
 		if err != nil {
 			return err
 		}
@@ -274,11 +306,11 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 
 	if opts.minecraftHost == "" && !opts.quiet {
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleStep.Render("Minecraft server address")+" "+styleDim.Render("- What players will connect to"))
-		fmt.Fprintln(out, styleDim.Render("  Local play: use 'localhost'"))
-		fmt.Fprintln(out, styleDim.Render("  LAN/friends: use this computer's local IP (e.g., 192.168.1.100)"))
-		fmt.Fprintln(out, styleDim.Render("  Internet: use your public IP or domain name (e.g., mc.example.com)"))
-		value, err := promptString(reader, out, "Public Minecraft host", "localhost")
+		fmt.Fprintln(out, styleStep.Render(i18n.T("install.minecraftHost.title"))+" "+styleDim.Render(i18n.T("install.minecraftHost.hint")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.minecraftHost.local")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.minecraftHost.lan")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.minecraftHost.internet")))
+		value, err := promptString(reader, out, i18n.T("install.minecraftHost.prompt"), "localhost")
 		if err != nil {
 			return err
 		}
@@ -287,10 +319,10 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 
 	if opts.bodySizeLimit == "" && !opts.quiet {
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleStep.Render("Upload file size limit")+" "+styleDim.Render("- Maximum upload size through the web interface"))
-		fmt.Fprintln(out, styleDim.Render("  'Infinity' = no limit, or specify a size like '500MB' or '1GB'"))
-		fmt.Fprintln(out, styleDim.Render("  (Modpacks and world backups can be large, so 'Infinity' is recommended)"))
-		value, err := promptString(reader, out, "Web UI upload size limit", defaultBodySizeLimit)
+		fmt.Fprintln(out, styleStep.Render(i18n.T("install.bodySize.title"))+" "+styleDim.Render(i18n.T("install.bodySize.hint")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.bodySize.values")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.bodySize.advice")))
+		value, err := promptString(reader, out, i18n.T("install.bodySize.prompt"), defaultBodySizeLimit)
 		if err != nil {
 			return err
 		}
@@ -303,8 +335,8 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 			opts.networkMode = defaultNetworkMode
 		} else {
 			fmt.Fprintln(out, "")
-			fmt.Fprintln(out, styleStep.Render("Network Mode")+" "+styleDim.Render("- LAN discovery requires host networking on Linux"))
-			value, err := promptYesNo(reader, out, "Enable host networking for LAN discovery", false)
+			fmt.Fprintln(out, styleStep.Render(i18n.T("install.network.title"))+" "+styleDim.Render(i18n.T("install.network.hint")))
+			value, err := promptYesNo(reader, out, i18n.T("install.network.prompt"), false)
 			if err != nil {
 				return err
 			}
@@ -320,19 +352,35 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 			return fmt.Errorf("invalid network-mode: %s", opts.networkMode)
 		}
 		if mode == "host" && runtime.GOOS != "linux" {
-			fmt.Fprintln(out, styleWarning.Render("Host networking is only supported on Linux.")+" Using bridge mode.")
+			fmt.Fprintln(out, styleWarning.Render(i18n.T("install.network.linuxOnly"))+" "+i18n.T("install.network.usingBridge"))
 			mode = defaultNetworkMode
 		}
 		opts.networkMode = mode
 	}
 
+	// Host networking publishes nothing; the servers bind every family.
+	if opts.bindFamily == "" && !opts.quiet && opts.networkMode != "host" {
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, styleStep.Render(i18n.T("install.bind.title"))+" "+styleDim.Render(i18n.T("install.bind.hint")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.bind.values")))
+		value, err := promptString(reader, out, i18n.T("install.bind.prompt"), config.BindDual)
+		if err != nil {
+			return err
+		}
+		opts.bindFamily = value
+	}
+	bindAddress, err := bindAddressFor(opts.bindFamily)
+	if err != nil {
+		return err
+	}
+
 	buildChanged := cmd.Flags().Changed("build")
 	if !buildChanged && !opts.quiet {
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleStep.Render("Installation method:"))
-		fmt.Fprintln(out, styleDim.Render("  - Pull images (recommended): Download pre-built software - faster and easier"))
-		fmt.Fprintln(out, styleDim.Render("  - Build from source: Compile the software yourself - for developers only"))
-		value, err := promptYesNo(reader, out, "Build from source instead of pulling pre-built images", false)
+		fmt.Fprintln(out, styleStep.Render(i18n.T("install.method.title")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.method.pull")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.method.build")))
+		value, err := promptYesNo(reader, out, i18n.T("install.method.prompt"), false)
 		if err != nil {
 			return err
 		}
@@ -342,11 +390,11 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 	if !opts.buildFromSource {
 		if opts.imageTag == "" && !opts.quiet {
 			fmt.Fprintln(out, "")
-			fmt.Fprintln(out, styleStep.Render("Version to install:"))
-			fmt.Fprintln(out, styleDim.Render("  - 'latest': Most recent stable version (recommended)"))
-			fmt.Fprintln(out, styleDim.Render("  - 'preview': Latest preview/pre-release version"))
-			fmt.Fprintln(out, styleDim.Render("  - Or specify a version tag like 'v1.0.0' for a specific release"))
-			value, err := promptString(reader, out, "Version tag", "latest")
+			fmt.Fprintln(out, styleStep.Render(i18n.T("install.version.title")))
+			fmt.Fprintln(out, styleDim.Render(i18n.T("install.version.latest")))
+			fmt.Fprintln(out, styleDim.Render(i18n.T("install.version.preview")))
+			fmt.Fprintln(out, styleDim.Render(i18n.T("install.version.specific")))
+			value, err := promptString(reader, out, i18n.T("install.version.prompt"), "latest")
 			if err != nil {
 				return err
 			}
@@ -354,37 +402,35 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 		}
 		if isPreviewTag(opts.imageTag) && !opts.quiet {
 			fmt.Fprintln(out, "")
-			fmt.Fprintln(out, styleWarning.Render("WARNING:")+" Preview versions may be unstable, contain bugs, or cause data loss.")
-			fmt.Fprintln(out, styleWarning.Render("Do not use preview releases in production. Back up your data before upgrading."))
+			fmt.Fprintln(out, styleWarning.Render(i18n.T("install.preview.label"))+" "+i18n.T("install.preview.unstable"))
+			fmt.Fprintln(out, styleWarning.Render(i18n.T("install.preview.production")))
 			fmt.Fprintln(out, "")
 		}
 	} else if !dirExists("apps") {
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleStep.Render("Cloning MineOS source code..."))
+		fmt.Fprintln(out, styleStep.Render(i18n.T("install.clone.start")))
 		cloneCmd := exec.Command("git", "clone", "https://github.com/freeman412/mineos-sveltekit.git", ".")
 		cloneCmd.Stdout = out
 		cloneCmd.Stderr = out
 		if err := cloneCmd.Run(); err != nil {
-			return fmt.Errorf("failed to clone source repository: %w\nPlease clone manually: git clone https://github.com/freeman412/mineos-sveltekit.git .")
+			return fmt.Errorf("failed to clone source repository: %w\nPlease clone manually: git clone https://github.com/freeman412/mineos-sveltekit.git .", err)
 		}
 		if !dirExists("apps") {
 			return errors.New("source files not found after cloning; the repository may have changed structure")
 		}
-		fmt.Fprintln(out, styleSuccess.Render("Source code cloned successfully"))
+		fmt.Fprintln(out, styleSuccess.Render(i18n.T("install.clone.done")))
 	}
 
 	// Telemetry prompt (default opt-in)
 	telemetryEnabled := true // default opt-in
 	if !opts.quiet {
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleStep.Render("Support MineOS Development"))
-		fmt.Fprintln(out, styleDim.Render("  Telemetry helps us understand how MineOS is used so we can prioritize"))
-		fmt.Fprintln(out, styleDim.Render("  features, fix bugs faster, and keep the project alive. We only collect"))
-		fmt.Fprintln(out, styleDim.Render("  anonymous data: OS, version, server count, and lifecycle events."))
-		fmt.Fprintln(out, styleDim.Render("  No personal info, player data, or server names — ever."))
+		fmt.Fprintln(out, styleStep.Render(i18n.T("install.telemetry.title")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.telemetry.why")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.telemetry.private")))
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleDim.Render("  You can change this anytime in the web UI settings or in .env"))
-		value, err := promptYesNo(reader, out, "Enable anonymous telemetry", true)
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.telemetry.change")))
+		value, err := promptYesNo(reader, out, i18n.T("install.telemetry.prompt"), true)
 		if err != nil {
 			return err
 		}
@@ -394,8 +440,8 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 
 	if !opts.quiet {
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleInfo.Render("Tip:")+" "+styleDim.Render("Integrations like CurseForge API keys can be configured later"))
-		fmt.Fprintln(out, styleDim.Render("in the web UI under Settings > Integrations."))
+		fmt.Fprintln(out, styleInfo.Render(i18n.T("install.tip.label"))+" "+styleDim.Render(i18n.T("install.tip.integrations")))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.tip.where")))
 	}
 
 	// Generate installation ID for telemetry tracking
@@ -427,8 +473,11 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 		hostBaseDir:      opts.hostBaseDir,
 		dataDir:          opts.dataDir,
 		networkMode:      opts.networkMode,
+		bindAddress:      bindAddress,
 		buildFromSource:  opts.buildFromSource,
 		imageTag:         opts.imageTag,
+		platform:         opts.platform,
+		limits:           opts.limits,
 		apiPort:          opts.apiPort,
 		webPort:          opts.webPort,
 		webOrigin:        opts.webOrigin,
@@ -443,6 +492,10 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 		return err
 	}
 
+	if err := writeResourceLimitsFile(".", opts.limits); err != nil {
+		return err
+	}
+
 	if err := createDirectories(out, opts.hostBaseDir, opts.dataDir); err != nil {
 		return err
 	}
@@ -454,18 +507,21 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 	if opts.buildFromSource {
 		composeFiles = append(composeFiles, "-f", "docker-compose.build.yml")
 	}
+	if !opts.limits.empty() {
+		composeFiles = append(composeFiles, "-f", resourceLimitsFile)
+	}
 
 	if opts.buildFromSource {
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleInfo.Render("Building Docker images..."))
+		fmt.Fprintln(out, styleInfo.Render(i18n.T("install.building")))
 		buildID := time.Now().Format("20060102150405")
-		if err := compose.runWithEnv(append(composeFiles, "build"), []string{"PUBLIC_BUILD_ID=" + buildID}); err != nil {
+		if err := compose.build(cmd.Context(), out, []string{"PUBLIC_BUILD_ID=" + buildID}, composeFiles...); err != nil {
 			return err
 		}
 	} else {
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleInfo.Render("Pulling Docker images..."))
-		if err := compose.run(append(composeFiles, "pull")); err != nil {
+		fmt.Fprintln(out, styleInfo.Render(i18n.T("install.pulling")))
+		if err := compose.pull(cmd.Context(), out, composeFiles...); err != nil {
 			return err
 		}
 	}
@@ -490,12 +546,12 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 
 			if err == nil && resp != nil && resp.TelemetryKey != "" {
 				appendToEnv(".env", "MINEOS_TELEMETRY_KEY", resp.TelemetryKey)
-				fmt.Fprintln(out, styleDim.Render("Telemetry registered."))
+				fmt.Fprintln(out, styleDim.Render(i18n.T("install.telemetry.registered")))
 			}
 		}()
 	}
 
-	fmt.Fprintln(out, styleInfo.Render("Starting services..."))
+	fmt.Fprintln(out, styleInfo.Render(i18n.T("install.starting")))
 	installErr := compose.run(append(composeFiles, "up", "-d"))
 
 	if installErr != nil {
@@ -503,40 +559,57 @@ func runInstall(cmd *cobra.Command, opts installOptions) error {
 	}
 
 	fmt.Fprintln(out, "")
-	fmt.Fprintln(out, styleBox.Render(styleSuccess.Render("  Installation Complete! 🎉  ")))
+	if accessibleMode {
+		fmt.Fprintln(out, styleSuccess.Render(i18n.T("install.done.banner")))
+	} else {
+		fmt.Fprintln(out, styleBox.Render(styleSuccess.Render("  "+i18n.T("install.done.banner")+"  ")))
+	}
 	fmt.Fprintln(out, "")
-	fmt.Fprintln(out, styleSuccess.Render("  Your MineOS server is now running!"))
+	fmt.Fprintln(out, styleSuccess.Render("  "+i18n.T("install.done.running")))
 	fmt.Fprintln(out, "")
-	fmt.Fprintln(out, styleTitle.Render("  Web Interface"))
-	fmt.Fprintf(out, "  %s %s\n", styleLabel.Render("Open your browser:"), styleValue.Render(opts.webOrigin))
+	fmt.Fprintln(out, styleTitle.Render("  "+i18n.T("install.done.web")))
+	fmt.Fprintf(out, "  %s %s\n", styleLabel.Render(i18n.T("install.done.openBrowser")), styleValue.Render(opts.webOrigin))
 	fmt.Fprintln(out, "")
-	fmt.Fprintln(out, styleTitle.Render("  Login Credentials"))
-	fmt.Fprintf(out, "  %s  %s\n", styleLabel.Render("Username:"), styleValue.Render(opts.adminUser))
-	fmt.Fprintf(out, "  %s  %s\n", styleLabel.Render("Password:"), styleValue.Render(opts.adminPass))
+	// Pad the labels of each group to the longest, whatever the language.
+	credentials := padLabels(i18n.T("install.done.username"), i18n.T("install.done.password"))
+	fmt.Fprintln(out, styleTitle.Render("  "+i18n.T("install.done.credentials")))
+	fmt.Fprintf(out, "  %s  %s\n", styleLabel.Render(credentials[0]), styleValue.Render(opts.adminUser))
+	fmt.Fprintf(out, "  %s  %s\n", styleLabel.Render(credentials[1]), styleValue.Render(opts.adminPass))
 	fmt.Fprintln(out, "")
-	fmt.Fprintln(out, styleTitle.Render("  API Information")+" "+styleDim.Render("(for advanced users)"))
-	fmt.Fprintf(out, "  %s  %s\n", styleDim.Render("Endpoint:"), styleInfo.Render(fmt.Sprintf("http://localhost:%d", opts.apiPort)))
-	fmt.Fprintf(out, "  %s  %s\n", styleDim.Render("Docs:    "), styleInfo.Render(fmt.Sprintf("http://localhost:%d/swagger", opts.apiPort)))
-	fmt.Fprintf(out, "  %s  %s\n", styleDim.Render("API key: "), styleInfo.Render(apiKey))
+	api := padLabels(i18n.T("install.done.endpoint"), i18n.T("install.done.docs"), i18n.T("install.done.apiKey"))
+	fmt.Fprintln(out, styleTitle.Render("  "+i18n.T("install.done.api"))+" "+styleDim.Render(i18n.T("install.done.apiAdvanced")))
+	fmt.Fprintf(out, "  %s  %s\n", styleDim.Render(api[0]), styleInfo.Render(fmt.Sprintf("http://localhost:%d", opts.apiPort)))
+	fmt.Fprintf(out, "  %s  %s\n", styleDim.Render(api[1]), styleInfo.Render(fmt.Sprintf("http://localhost:%d/swagger", opts.apiPort)))
+	fmt.Fprintf(out, "  %s  %s\n", styleDim.Render(api[2]), styleInfo.Render(apiKey))
 	fmt.Fprintln(out, "")
-	fmt.Fprintln(out, styleTitle.Render("  Next Steps"))
-	fmt.Fprintln(out, styleSuccess.Render("  1.")+" Open the web interface in your browser")
-	fmt.Fprintln(out, styleSuccess.Render("  2.")+" Log in with your admin credentials")
-	fmt.Fprintln(out, styleSuccess.Render("  3.")+" Create your first Minecraft server!")
+	fmt.Fprintln(out, styleTitle.Render("  "+i18n.T("install.done.next")))
+	fmt.Fprintln(out, styleSuccess.Render("  1.")+" "+i18n.T("install.done.step1"))
+	fmt.Fprintln(out, styleSuccess.Render("  2.")+" "+i18n.T("install.done.step2"))
+	fmt.Fprintln(out, styleSuccess.Render("  3.")+" "+i18n.T("install.done.step3"))
 	fmt.Fprintln(out, "")
-	fmt.Fprintln(out, styleDim.Render("  Use the terminal interface for advanced management"))
-	fmt.Fprintln(out, styleDim.Render("  Use 'mineos --help' to see all available commands"))
+	fmt.Fprintln(out, styleDim.Render("  "+i18n.T("install.done.tui")))
+	fmt.Fprintln(out, styleDim.Render("  "+i18n.T("install.done.help")))
 	fmt.Fprintln(out, "")
 
+	onPath := false
+	if runtime.GOOS == "windows" && !opts.skipPathInstall {
+		if err := installCLIToPath(out, opts.webOrigin); err != nil {
+			fmt.Fprintln(out, i18n.T("common.warning"), err)
+		} else {
+			onPath = true
+		}
+		fmt.Fprintln(out, "")
+	}
+
 	if !opts.quiet {
-		printLocalCLIInstructions(out)
+		printLocalCLIInstructions(out, onPath)
 	}
 
 	if !opts.quiet {
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, styleAccent.Render("  Happy Minecrafting! ⛏"))
+		fmt.Fprintln(out, styleAccent.Render("  "+i18n.T("install.done.happy")))
 	} else {
-		fmt.Fprintln(out, styleSuccess.Render("Installation complete."))
+		fmt.Fprintln(out, styleSuccess.Render(i18n.T("install.done.quiet")))
 	}
 
 	return nil
@@ -550,8 +623,11 @@ type envConfig struct {
 	hostBaseDir      string
 	dataDir          string
 	networkMode      string
+	bindAddress      string
 	buildFromSource  bool
 	imageTag         string
+	platform         string
+	limits           resourceLimits
 	apiPort          int
 	webPort          int
 	webOrigin        string
@@ -567,6 +643,8 @@ func renderEnv(cfg envConfig) string {
 	curseforgeLine := "# CurseForge__ApiKey="
 
 	builder := &strings.Builder{}
+	builder.WriteString("# .env layout version (upgraded by 'mineos env migrate')\n")
+	builder.WriteString(fmt.Sprintf("%s=%d\n\n", envVersionKey, latestEnvVersion()))
 	builder.WriteString("# Database Configuration\n")
 	builder.WriteString("DB_TYPE=sqlite\n")
 	builder.WriteString("ConnectionStrings__DefaultConnection=Data Source=/app/data/mineos.db\n\n")
@@ -576,7 +654,7 @@ func renderEnv(cfg envConfig) string {
 	builder.WriteString(fmt.Sprintf("Auth__JwtSecret=%s\n", cfg.jwtSecret))
 	builder.WriteString("Auth__JwtIssuer=mineos\n")
 	builder.WriteString("Auth__JwtAudience=mineos\n")
-	builder.WriteString("Auth__JwtExpiryHours=24\n\n")
+	builder.WriteString("Auth__JwtExpiryMinutes=1440\n\n")
 	builder.WriteString("# API Configuration\n")
 	builder.WriteString(fmt.Sprintf("ApiKey__SeedKey=%s\n", cfg.apiKey))
 	builder.WriteString(fmt.Sprintf("MINEOS_API_KEY=%s\n\n", cfg.apiKey))
@@ -597,11 +675,24 @@ func renderEnv(cfg envConfig) string {
 	if cfg.imageTag != "" {
 		builder.WriteString(fmt.Sprintf("MINEOS_IMAGE_TAG=%s\n", cfg.imageTag))
 	}
+	if cfg.platform != "" {
+		builder.WriteString(fmt.Sprintf("%s=%s\n", platformEnvKey, cfg.platform))
+	}
+	if !cfg.limits.empty() {
+		builder.WriteString("\n# Container limits (docker-compose.limits.yml); the api limit covers the Minecraft servers\n")
+		for _, entry := range cfg.limits.envValues() {
+			if entry[1] != "" {
+				builder.WriteString(fmt.Sprintf("%s=%s\n", entry[0], entry[1]))
+			}
+		}
+	}
 	builder.WriteString("\n# Optional: CurseForge Integration (configure in web UI Settings > Integrations)\n")
 	builder.WriteString(curseforgeLine + "\n\n")
 	builder.WriteString("# Ports\n")
 	builder.WriteString(fmt.Sprintf("API_PORT=%d\n", cfg.apiPort))
-	builder.WriteString(fmt.Sprintf("WEB_PORT=%d\n\n", cfg.webPort))
+	builder.WriteString(fmt.Sprintf("WEB_PORT=%d\n", cfg.webPort))
+	builder.WriteString("# Published port address family: empty = IPv4 and IPv6, 0.0.0.0: = IPv4 only, [::]: = IPv6 only\n")
+	builder.WriteString(fmt.Sprintf("MINEOS_BIND_ADDRESS=%s\n\n", cfg.bindAddress))
 	builder.WriteString("# Web Origins\n")
 	builder.WriteString(fmt.Sprintf("WEB_ORIGIN_PROD=%s\n", cfg.webOrigin))
 	builder.WriteString(fmt.Sprintf("PUBLIC_API_BASE_URL=%s\n", cfg.webOrigin))
@@ -627,7 +718,7 @@ func renderEnv(cfg envConfig) string {
 }
 
 func createDirectories(out io.Writer, hostBaseDir, dataDir string) error {
-	fmt.Fprintln(out, styleInfo.Render("Creating directories..."))
+	fmt.Fprintln(out, styleInfo.Render(i18n.T("install.creatingDirs")))
 	paths := []string{
 		filepath.Join(hostBaseDir, "servers"),
 		filepath.Join(hostBaseDir, "profiles"),
@@ -644,18 +735,34 @@ func createDirectories(out io.Writer, hostBaseDir, dataDir string) error {
 
 	if runtime.GOOS == "linux" && isRoot() {
 		if err := chownRecursive(hostBaseDir, 1000, 1000); err != nil {
-			fmt.Fprintf(out, "%s unable to chown %s: %v\n", styleWarning.Render("Warning:"), hostBaseDir, err)
+			fmt.Fprintln(out, styleWarning.Render(i18n.T("common.warning")), i18n.T("install.chownFailed", hostBaseDir, err))
 		}
 		if err := chownRecursive(dataDir, 1000, 1000); err != nil {
-			fmt.Fprintf(out, "%s unable to chown %s: %v\n", styleWarning.Render("Warning:"), dataDir, err)
+			fmt.Fprintln(out, styleWarning.Render(i18n.T("common.warning")), i18n.T("install.chownFailed", dataDir, err))
 		}
 	} else if runtime.GOOS == "linux" {
-		fmt.Fprintln(out, styleDim.Render("Not running as root; skipping ownership changes (1000:1000)."))
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.notRoot")))
+	} else if runtime.GOOS == "darwin" {
+		fmt.Fprintln(out, styleDim.Render(i18n.T("install.dockerDesktopOwnership")))
 	}
 
 	return nil
 }
 
+// padLabels pads labels to the width of the longest, so the values after
+// them line up.
+func padLabels(labels ...string) []string {
+	width := 0
+	for _, label := range labels {
+		width = max(width, lipgloss.Width(label))
+	}
+	padded := make([]string, len(labels))
+	for i, label := range labels {
+		padded[i] = label + strings.Repeat(" ", width-lipgloss.Width(label))
+	}
+	return padded
+}
+
 func chownRecursive(path string, uid, gid int) error {
 	return filepath.WalkDir(path, func(target string, d os.DirEntry, err error) error {
 		if err != nil {
@@ -666,8 +773,11 @@ func chownRecursive(path string, uid, gid int) error {
 }
 
 func promptString(_ *bufio.Reader, _ io.Writer, label, defaultValue string) (string, error) {
+	if machineMode {
+		return "", errMachinePrompt
+	}
 	if defaultValue != "" {
-		fmt.Printf("%s %s: ", styleLabel.Render(label), styleDim.Render("(default: "+defaultValue+")"))
+		fmt.Printf("%s %s: ", styleLabel.Render(label), styleDim.Render(i18n.T("prompt.default", defaultValue)))
 	} else {
 		fmt.Printf("%s: ", styleLabel.Render(label))
 	}
@@ -688,6 +798,9 @@ func promptString(_ *bufio.Reader, _ io.Writer, label, defaultValue string) (str
 }
 
 func promptPassword(out io.Writer, prompt string) (string, error) {
+	if machineMode {
+		return "", errMachinePrompt
+	}
 	if term.IsTerminal(int(os.Stdin.Fd())) {
 		fmt.Fprint(out, styleLabel.Render(strings.TrimSuffix(prompt, " "))+": ")
 		bytes, err := term.ReadPassword(int(os.Stdin.Fd()))
@@ -708,15 +821,18 @@ func promptInt(reader *bufio.Reader, out io.Writer, label string, defaultValue i
 	}
 	parsed, err := strconv.Atoi(strings.TrimSpace(value))
 	if err != nil {
-		return 0, fmt.Errorf("invalid number for %s", label)
+		return 0, errors.New(i18n.T("prompt.invalidNumber", label))
 	}
 	return parsed, nil
 }
 
 func promptYesNo(_ *bufio.Reader, _ io.Writer, label string, defaultValue bool) (bool, error) {
-	defaultLabel := "y/N"
+	if machineMode {
+		return false, errMachinePrompt
+	}
+	defaultLabel := i18n.T("prompt.yesNo.defaultNo")
 	if defaultValue {
-		defaultLabel = "Y/n"
+		defaultLabel = i18n.T("prompt.yesNo.defaultYes")
 	}
 	fmt.Printf("%s %s: ", styleLabel.Render(label), styleDim.Render("("+defaultLabel+")"))
 
@@ -731,10 +847,12 @@ func promptYesNo(_ *bufio.Reader, _ io.Writer, label string, defaultValue bool)
 	if line == "" {
 		return defaultValue, nil
 	}
-	switch strings.ToLower(line) {
-	case "y", "yes":
+	yes, no := i18n.Answers()
+	answer := strings.ToLower(line)
+	switch {
+	case slices.Contains(yes, answer):
 		return true, nil
-	case "n", "no":
+	case slices.Contains(no, answer):
 		return false, nil
 	default:
 		return defaultValue, nil
@@ -753,7 +871,7 @@ func promptRelativePath(reader *bufio.Reader, out io.Writer, label, defaultValue
 			}
 			return value, nil
 		}
-		fmt.Println("Path must be relative to the current directory (no leading /, ~, or ..).")
+		fmt.Println(i18n.T("prompt.relativePath"))
 	}
 }
 
@@ -804,22 +922,38 @@ func deriveCaddySite(origin string) string {
 	if slash := strings.Index(host, "/"); slash >= 0 {
 		host = host[:slash]
 	}
-	if colon := strings.Index(host, ":"); colon >= 0 {
-		host = host[:colon]
-	}
+	host = config.NormalizeHost(host, "")
 	if host == "" {
 		return "http://localhost"
 	}
+	if strings.Contains(host, ":") {
+		// Caddy takes IPv6 literals in brackets.
+		host = "[" + host + "]"
+	}
 	if scheme == "https" {
 		return host
 	}
 	return "http://" + host
 }
 
+// bindAddressFor turns an address family into the MINEOS_BIND_ADDRESS
+// prefix docker compose puts before each published port.
+func bindAddressFor(family string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(family)) {
+	case "", config.BindDual:
+		return "", nil
+	case config.BindIPv4:
+		return "0.0.0.0:", nil
+	case config.BindIPv6:
+		return "[::]:", nil
+	}
+	return "", fmt.Errorf("invalid address family %q (use dual, ipv4 or ipv6)", family)
+}
+
 func ensureDockerAvailable() error {
 	if _, err := exec.LookPath("docker"); err != nil {
-		msg := "Docker is not installed.\n\n"
-		msg += "MineOS requires Docker to run. Please install Docker Desktop:\n"
+		msg := i18n.T("docker.missing.title") + "\n\n"
+		msg += i18n.T("docker.missing.install") + "\n"
 		if runtime.GOOS == "windows" {
 			msg += "  https://docs.docker.com/desktop/install/windows-install/\n"
 		} else if runtime.GOOS == "darwin" {
@@ -827,7 +961,7 @@ func ensureDockerAvailable() error {
 		} else {
 			msg += "  https://docs.docker.com/engine/install/\n"
 		}
-		msg += "\nThen re-run this installer."
+		msg += "\n" + i18n.T("docker.missing.rerun")
 		return errors.New(msg)
 	}
 	return nil
@@ -836,17 +970,15 @@ func ensureDockerAvailable() error {
 func ensureDockerRunning() error {
 	cmd := exec.Command("docker", "info")
 	if err := cmd.Run(); err != nil {
-		msg := "Docker is installed but not running.\n\n"
+		msg := i18n.T("docker.stopped.title") + "\n\n"
 		if runtime.GOOS == "windows" {
-			msg += "Please start Docker Desktop from the Start menu or system tray,\n"
-			msg += "wait for it to finish loading, then re-run this installer.\n"
+			msg += i18n.T("docker.stopped.windows") + "\n"
 		} else if runtime.GOOS == "darwin" {
-			msg += "Please start Docker Desktop from Applications,\n"
-			msg += "wait for it to finish loading, then re-run this installer.\n"
+			msg += i18n.T("docker.stopped.mac") + "\n"
 		} else {
-			msg += "Please start the Docker daemon:\n"
+			msg += i18n.T("docker.stopped.linux") + "\n"
 			msg += "  sudo systemctl start docker\n"
-			msg += "\nThen re-run this installer.\n"
+			msg += "\n" + i18n.T("docker.missing.rerun") + "\n"
 		}
 		return errors.New(msg)
 	}
@@ -865,30 +997,26 @@ func dirExists(path string) bool {
 
 // reuse uninstall compose helper
 func (c composeRunner) run(args []string) error {
-	cmd := exec.Command(c.exe, append(c.baseArgs, args...)...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	return cmd.Run()
+	return c.attached(args, nil).Run()
 }
 
 func (c composeRunner) runWithEnv(args []string, env []string) error {
-	cmd := exec.Command(c.exe, append(c.baseArgs, args...)...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	cmd.Env = append(os.Environ(), env...)
-	return cmd.Run()
+	return c.attached(args, env).Run()
 }
 
-func printLocalCLIInstructions(out io.Writer) {
+func printLocalCLIInstructions(out io.Writer, onPath bool) {
 	fmt.Fprintln(out, "")
-	fmt.Fprintln(out, styleTitle.Render("  To manage your servers from the terminal:"))
+	fmt.Fprintln(out, styleTitle.Render("  "+i18n.T("install.done.terminal")))
 
 	if runtime.GOOS == "windows" {
 		pwd, _ := os.Getwd()
 		fmt.Fprintf(out, "    %s\n", styleInfo.Render(fmt.Sprintf("cd \"%s\"", pwd)))
-		fmt.Fprintf(out, "    %s\n", styleInfo.Render(".\\mineos.exe tui"))
+		if onPath {
+			fmt.Fprintf(out, "    %s\n", styleInfo.Render("mineos tui"))
+			fmt.Fprintln(out, styleDim.Render("    "+i18n.T("install.done.startMenu")))
+		} else {
+			fmt.Fprintf(out, "    %s\n", styleInfo.Render(".\\mineos.exe tui"))
+		}
 	} else {
 		pwd, _ := os.Getwd()
 		fmt.Fprintf(out, "    %s\n", styleInfo.Render(fmt.Sprintf("cd \"%s\"", pwd)))
@@ -18,6 +18,7 @@ import (
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/consolehistory"
 )
 
 const (
@@ -239,12 +240,15 @@ func (s *interactiveSession) handleConsoleCommand(ctx context.Context, line, pre
 		return fmt.Errorf("select a server with 'use <name>' before sending console commands")
 	}
 
-	_, err := withApiKeyRetry(ctx, s.loadConfig, s.out, func(_ config.Config, client *api.Client) error {
+	var envPath string
+	_, err := withApiKeyRetry(ctx, s.loadConfig, s.out, func(cfg config.Config, client *api.Client) error {
+		envPath = cfg.EnvPath
 		return client.SendConsoleCommand(ctx, server, command)
 	})
 	if err != nil {
 		return err
 	}
+	_ = consolehistory.NewFileRepositoryForEnv(resolveEnvPath(envPath)).Add(server, command)
 	fmt.Fprintf(s.out, "sent to %s: %s\n", server, command)
 	return nil
 }
@@ -350,7 +354,7 @@ func (s *interactiveSession) resolveServer(fields []string) (string, error) {
 	return s.currentServer, nil
 }
 
-func (s *interactiveSession) printLogEntry(entry api.LogEntry) {
+func (s *interactiveSession) printLogEntry(entry ports.LogEntry) {
 	timestamp := entry.Timestamp
 	if timestamp.IsZero() {
 		fmt.Fprintln(s.out, entry.Message)
@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/java"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+func NewJavaCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "java",
+		Short: "Manage Java runtimes used by Minecraft servers",
+		Long: `List, install and assign Java runtimes inside the MineOS API container.
+
+Runtimes bundled with the image live in /usr/lib/jvm. Additional versions
+are installed from Adoptium (Eclipse Temurin) into ` + java.ManagedRuntimeDir + `,
+which is on the server volume and survives container updates.`,
+	}
+
+	cmd.AddCommand(newJavaListCommand(loadConfig))
+	cmd.AddCommand(newJavaInstallCommand(loadConfig))
+	cmd.AddCommand(newJavaUninstallCommand(loadConfig))
+	cmd.AddCommand(newJavaShowCommand(loadConfig))
+	cmd.AddCommand(newJavaSetCommand(loadConfig))
+
+	return cmd
+}
+
+func listJavaRuntimes(ctx context.Context, loadConfig *usecases.LoadConfigUseCase) ([]java.Runtime, error) {
+	compose, _, err := loadComposeAndConfig(ctx, loadConfig)
+	if err != nil {
+		return nil, err
+	}
+	out, err := compose.output([]string{"exec", "-T", "api", "sh", "-c", java.ListRuntimesScript})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runtimes in the api container (is the stack running?): %w", err)
+	}
+	runtimes := java.ParseRuntimeListing(string(out))
+	sort.Slice(runtimes, func(i, j int) bool {
+		if runtimes[i].Feature() != runtimes[j].Feature() {
+			return runtimes[i].Feature() < runtimes[j].Feature()
+		}
+		return runtimes[i].Home < runtimes[j].Home
+	})
+	return runtimes, nil
+}
+
+func parseJavaFeature(value string) (int, error) {
+	feature, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(value), "java"))
+	if err != nil || !java.IsSupported(feature) {
+		return 0, fmt.Errorf("unsupported Java version %q (supported: %s)", value, joinInts(java.SupportedVersions))
+	}
+	return feature, nil
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		parts = append(parts, strconv.Itoa(value))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func newJavaListCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List Java runtimes available in the API container",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			runtimes, err := listJavaRuntimes(context.Background(), loadConfig)
+			if err != nil {
+				return err
+			}
+			if len(runtimes) == 0 {
+				cmd.Println("No Java runtimes found.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "JAVA\tVERSION\tSOURCE\tHOME")
+			for _, runtime := range runtimes {
+				source := "image"
+				if runtime.Managed {
+					source = "managed"
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", runtime.Feature(), fallback(runtime.Version, "unknown"), source, runtime.Home)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newJavaInstallCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "install <version>",
+		Short: "Download a Temurin runtime (8, 11, 17, 21, 25) into the API container",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			feature, err := parseJavaFeature(args[0])
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			compose, _, err := loadComposeAndConfig(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			cmd.Printf("Installing Temurin %d into %s/temurin-%d...\n", feature, java.ManagedRuntimeDir, feature)
+			if err := compose.run([]string{"exec", "-T", "api", "sh", "-c", java.InstallScript(feature)}); err != nil {
+				return fmt.Errorf("java install failed: %w", err)
+			}
+			cmd.Println(styleSuccess.Render(fmt.Sprintf("Java %d installed.", feature)))
+			cmd.Printf("Assign it with: mineos java set <server> %d\n", feature)
+			return nil
+		},
+	}
+}
+
+func newJavaUninstallCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall <version>",
+		Short: "Remove a runtime installed with 'mineos java install'",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			feature, err := parseJavaFeature(args[0])
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+			compose, _, err := loadComposeAndConfig(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			if err := compose.run([]string{"exec", "-T", "api", "sh", "-c", java.UninstallScript(feature)}); err != nil {
+				return fmt.Errorf("java uninstall failed: %w", err)
+			}
+			cmd.Printf("Removed managed Java %d (runtimes bundled with the image are untouched).\n", feature)
+			return nil
+		},
+	}
+}
+
+func newJavaShowCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <server>",
+		Short: "Show the runtime assigned to a server and the suggested version",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			var serverCfg ports.ServerConfig
+			err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				var err error
+				serverCfg, err = client.GetServerConfig(ctx, args[0])
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			mcVersion := serverMinecraftVersion(serverCfg)
+			suggested := java.RecommendedVersion(mcVersion)
+			binary := strings.TrimSpace(serverCfg.Java.JavaBinary)
+			if binary == "" || binary == "java" {
+				binary = "auto (selected by MineOS at start)"
+			}
+
+			printStat(cmd.OutOrStdout(), "Server", args[0])
+			printStat(cmd.OutOrStdout(), "Minecraft", fallback(mcVersion, "unknown"))
+			printStat(cmd.OutOrStdout(), "Java binary", binary)
+			if suggested > 0 {
+				printStat(cmd.OutOrStdout(), "Suggested", fmt.Sprintf("Java %d", suggested))
+			} else {
+				printStat(cmd.OutOrStdout(), "Suggested", styleDim.Render("unknown (no version in profile or jar name)"))
+			}
+			return nil
+		},
+	}
+}
+
+func newJavaSetCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var install bool
+
+	cmd := &cobra.Command{
+		Use:   "set <server> <version|auto|suggested|/path/to/java>",
+		Short: "Assign a Java runtime to a server",
+		Long: `Assign a Java runtime to a server.
+
+  auto       let MineOS pick a runtime at start (default)
+  suggested  pin the runtime recommended for the server's Minecraft version
+  8|17|21... pin a specific feature release
+  /path      pin an explicit java binary inside the container`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serverName, choice := args[0], strings.TrimSpace(args[1])
+			ctx := context.Background()
+
+			return runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				serverCfg, err := client.GetServerConfig(ctx, serverName)
+				if err != nil {
+					return err
+				}
+
+				binary := ""
+				switch {
+				case choice == "auto":
+				case strings.HasPrefix(choice, "/"):
+					binary = choice
+				default:
+					feature := 0
+					if choice == "suggested" {
+						feature = java.RecommendedVersion(serverMinecraftVersion(serverCfg))
+						if feature == 0 {
+							return fmt.Errorf("cannot determine the Minecraft version of %s; pass a Java version explicitly", serverName)
+						}
+					} else if feature, err = parseJavaFeature(choice); err != nil {
+						return err
+					}
+
+					runtimes, err := listJavaRuntimes(ctx, loadConfig)
+					if err != nil {
+						return err
+					}
+					runtime, ok := java.Find(runtimes, feature)
+					if !ok {
+						if !install {
+							return fmt.Errorf("java %d is not installed; run 'mineos java install %d' or pass --install", feature, feature)
+						}
+						compose, _, err := loadComposeAndConfig(ctx, loadConfig)
+						if err != nil {
+							return err
+						}
+						if err := compose.run([]string{"exec", "-T", "api", "sh", "-c", java.InstallScript(feature)}); err != nil {
+							return fmt.Errorf("java install failed: %w", err)
+						}
+						runtime = java.Runtime{Home: fmt.Sprintf("%s/temurin-%d", java.ManagedRuntimeDir, feature), Managed: true}
+					}
+					binary = runtime.Binary()
+				}
+
+				serverCfg.Java.JavaBinary = binary
+				if err := client.UpdateServerConfig(ctx, serverName, serverCfg); err != nil {
+					return err
+				}
+				cmd.Printf("%s java: %s\n", serverName, fallback(binary, "auto"))
+				cmd.Println(styleDim.Render("Restart the server to apply."))
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&install, "install", false, "Install the runtime first if it is missing")
+
+	return cmd
+}
+
+func serverMinecraftVersion(cfg ports.ServerConfig) string {
+	var profile, jar string
+	if cfg.Minecraft.Profile != nil {
+		profile = *cfg.Minecraft.Profile
+	}
+	if cfg.Java.JarFile != nil {
+		jar = *cfg.Java.JarFile
+	}
+	return java.ExtractMinecraftVersion(profile, jar)
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/logrecord"
+)
+
+// logFilterFlags are the --grep/--level flags of the log commands.
+type logFilterFlags struct {
+	grep  string
+	level string
+}
+
+func (f *logFilterFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.grep, "grep", "", "Only show lines matching this regular expression ((?i) to ignore case)")
+	cmd.Flags().StringVar(&f.level, "level", "", "Only show lines at or above this level (debug, info, warn, error)")
+}
+
+func (f logFilterFlags) filter() (*logrecord.Filter, error) {
+	filter := &logrecord.Filter{}
+	if f.level != "" {
+		level, ok := logrecord.ParseLevel(f.level)
+		if !ok {
+			return nil, fmt.Errorf("--level must be debug, info, warn or error, not %q", f.level)
+		}
+		filter.Min = level
+	}
+	if f.grep != "" {
+		pattern, err := regexp.Compile(f.grep)
+		if err != nil {
+			return nil, fmt.Errorf("--grep: %w", err)
+		}
+		filter.Pattern = pattern
+	}
+	return filter, nil
+}
+
+// serviceColors tell the services of a multiplexed log apart.
+var serviceColors = []lipgloss.Color{"39", "70", "205", "214", "81", "135", "75", "178"}
+
+// serviceStyle gives a service the same color on every run, whatever its
+// replica number.
+func serviceStyle(service string) lipgloss.Style {
+	name := service
+	if i := strings.LastIndex(name, "-"); i > 0 {
+		name = name[:i]
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	return lipgloss.NewStyle().Foreground(serviceColors[hash.Sum32()%uint32(len(serviceColors))])
+}
+
+// renderLevel is a fixed-width level tag; lines without a level get
+// padding so messages line up.
+func renderLevel(level logrecord.Level) string {
+	tag := fmt.Sprintf("%-5s", level)
+	switch level {
+	case logrecord.LevelDebug:
+		return styleDim.Render(tag)
+	case logrecord.LevelInfo:
+		return styleInfo.Render(tag)
+	case logrecord.LevelWarn:
+		return styleWarning.Render(tag)
+	case logrecord.LevelError:
+		return styleError.Render(tag)
+	}
+	return tag
+}
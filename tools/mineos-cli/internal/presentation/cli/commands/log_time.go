@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/logtime"
+)
+
+// utcTimes is the root --utc flag: log timestamps in UTC instead of the
+// local time zone.
+var utcTimes bool
+
+func logTimes() logtime.Normalizer {
+	return logtime.New(utcTimes)
+}
+
+// logWindowFlags are the --since/--until flags of the log commands.
+type logWindowFlags struct {
+	since string
+	until string
+}
+
+func (f *logWindowFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.since, "since", "", "Only show lines after this time (e.g. 15m, 2h, 3d or 2024-05-01 18:30)")
+	cmd.Flags().StringVar(&f.until, "until", "", "Only show lines before this time (same formats as --since)")
+}
+
+func (f logWindowFlags) window() (logtime.Window, error) {
+	now := time.Now()
+	since, err := logTimes().ParseBound(f.since, now)
+	if err != nil {
+		return logtime.Window{}, fmt.Errorf("--since: %w", err)
+	}
+	until, err := logTimes().ParseBound(f.until, now)
+	if err != nil {
+		return logtime.Window{}, fmt.Errorf("--until: %w", err)
+	}
+	if !since.IsZero() && !until.IsZero() && until.Before(since) {
+		return logtime.Window{}, fmt.Errorf("--until is before --since")
+	}
+	return logtime.Window{Since: since, Until: until}, nil
+}
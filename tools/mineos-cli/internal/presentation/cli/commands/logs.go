@@ -5,17 +5,22 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/logrecord"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/logtime"
 )
 
 func NewServerLogsCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 	var source string
+	var times logWindowFlags
+	var filters logFilterFlags
 
 	cmd := &cobra.Command{
 		Use:   "logs <server>",
@@ -26,18 +31,37 @@ Available log sources:
   combined  - All logs combined (default)
   server    - Server console output
   java      - Java/JVM output
-  crash     - Crash reports`,
+  crash     - Crash reports
+
+Timestamps are shown in the local time zone (or UTC with --utc). --since
+and --until take a duration ago (15m, 2h, 3d) or a date/time such as
+"2024-05-01 18:30"; the stream ends once a line is past --until.
+
+--level keeps lines at or above a level (debug, info, warn, error) and
+--grep those matching a regular expression; the stack trace of a kept line
+is kept with it.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			serverName := args[0]
 			out := cmd.OutOrStdout()
+			window, err := times.window()
+			if err != nil {
+				return err
+			}
+			filter, err := filters.filter()
+			if err != nil {
+				return err
+			}
 
 			ctx := context.Background()
-			_, err := withApiKeyRetry(ctx, loadConfig, out, func(_ config.Config, client *api.Client) error {
+			_, err = withApiKeyRetry(ctx, loadConfig, out, func(_ config.Config, client *api.Client) error {
 				// Verify server exists
 				_, err := client.ListServers(ctx)
 				return err
 			})
+			if unreachable(err) && sshRemote == nil && (source == "combined" || source == "server") {
+				return followServerLogFile(ctx, loadConfig, cmd, serverName, window, filter)
+			}
 			if err != nil {
 				return err
 			}
@@ -53,6 +77,7 @@ Available log sources:
 			streamCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 			defer stop()
 
+			normalizer := logTimes()
 			logs, errs := client.StreamConsoleLogs(streamCtx, serverName, source)
 			for {
 				select {
@@ -60,10 +85,12 @@ Available log sources:
 					if !ok {
 						return nil
 					}
-					if entry.Timestamp.IsZero() {
-						fmt.Fprintln(out, entry.Message)
-					} else {
-						fmt.Fprintf(out, "[%s] %s\n", entry.Timestamp.Format(time.RFC3339), entry.Message)
+					stamp, line := normalizer.MinecraftEntry(entry.Timestamp, entry.Message, time.Now())
+					if !window.Until.IsZero() && stamp.After(window.Until) {
+						return nil
+					}
+					if window.Contains(stamp) && filter.Keep(serverName, logrecord.MinecraftLevel(entry.Message), entry.Message) {
+						fmt.Fprintln(out, plainLog(line))
 					}
 				case err, ok := <-errs:
 					if ok && err != nil {
@@ -78,6 +105,37 @@ Available log sources:
 	}
 
 	cmd.Flags().StringVarP(&source, "source", "s", "combined", "Log source (combined, server, java, crash)")
+	times.register(cmd)
+	filters.register(cmd)
 
 	return cmd
 }
+
+// followServerLogFile is 'servers logs' without the API: the server's
+// logs/latest.log on the host, read and then followed.
+func followServerLogFile(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, cmd *cobra.Command, serverName string, window logtime.Window, filter *logrecord.Filter) error {
+	storage, err := loadHostStorage(ctx, loadConfig)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(storage.ServerDir(serverName), "logs", "latest.log")
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("the API is not reachable and %s cannot be read: %w", path, err)
+	}
+	out := cmd.OutOrStdout()
+	degradedNotice(cmd.ErrOrStderr(), "reading "+path+" on the host. Press Ctrl+C to stop.")
+
+	streamCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	normalizer := logTimes()
+	return followLogFile(streamCtx, path, func(message string) bool {
+		stamp, line := normalizer.MinecraftEntry(time.Time{}, message, time.Now())
+		if !window.Until.IsZero() && stamp.After(window.Until) {
+			return false
+		}
+		if window.Contains(stamp) && filter.Keep(serverName, logrecord.MinecraftLevel(message), message) {
+			fmt.Fprintln(out, plainLog(line))
+		}
+		return true
+	})
+}
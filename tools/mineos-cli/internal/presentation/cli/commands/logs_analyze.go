@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/loganalysis"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/logfiles"
+)
+
+func newLogsAnalyzeCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var dir string
+	var since string
+	var until string
+	var top int
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "analyze [server]",
+		Short: "Summarize a server's log archive",
+		Long: `Read a server's logs/ directory (latest.log and rotated .log.gz files)
+straight from disk and summarize errors, common exceptions, plugin and mod
+warnings, startup times and "Can't keep up" lag warnings.
+
+The directory is found under HOST_BASE_DIRECTORY from .env; use --dir to
+analyze any logs directory, e.g. one copied off another machine.`,
+		Example: `  mineos logs analyze survival
+  mineos logs analyze survival --since 7d --top 5
+  mineos logs analyze --dir ./old-logs --format json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf("--format must be text or json")
+			}
+			if dir == "" {
+				if len(args) == 0 {
+					return fmt.Errorf("specify a server or --dir")
+				}
+				storage, err := loadHostStorage(context.Background(), loadConfig)
+				if err != nil {
+					return fmt.Errorf("%w (use --dir to point at a logs directory)", err)
+				}
+				dir = filepath.Join(storage.ServerDir(args[0]), "logs")
+			}
+
+			now := time.Now()
+			from, err := parseLogTime(since, now)
+			if err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			to, err := parseLogTime(until, now)
+			if err != nil {
+				return fmt.Errorf("--until: %w", err)
+			}
+
+			files, err := logfiles.List(dir)
+			if err != nil {
+				return fmt.Errorf("read logs: %w", err)
+			}
+			analyzer := loganalysis.NewAnalyzer(from, to)
+			for _, file := range files {
+				// Rotated logs cover the day they are named after.
+				if !from.IsZero() && file.LastWrite.Before(from) {
+					continue
+				}
+				if !to.IsZero() && !file.Day.Before(to) {
+					continue
+				}
+				reader, err := logfiles.Open(file)
+				if err != nil {
+					return err
+				}
+				err = analyzer.AddFile(file.Name(), file.Day, reader)
+				reader.Close()
+				if err != nil {
+					return fmt.Errorf("%s: %w", file.Name(), err)
+				}
+			}
+
+			report := analyzer.Report(top)
+			if format == "json" {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			}
+			printLogReport(cmd.OutOrStdout(), dir, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "", "Logs directory to analyze instead of a server's")
+	cmd.Flags().StringVar(&since, "since", "", "Only include records after this time (e.g. 7d, 12h, 2024-05-01)")
+	cmd.Flags().StringVar(&until, "until", "", "Only include records before this time")
+	cmd.Flags().IntVar(&top, "top", 10, "Entries shown per ranking (0 for all)")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text or json")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+// parseLogTime accepts a relative age such as "7d" or "12h", a date, or an
+// RFC 3339 timestamp. Empty means unbounded.
+func parseLogTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", value)
+}
+
+func printLogReport(out io.Writer, dir string, report loganalysis.Report) {
+	fmt.Fprintf(out, "%s\n\n", styleTitle.Render("Log analysis: "+dir))
+	if report.Lines == 0 {
+		fmt.Fprintln(out, "No log records in range.")
+		return
+	}
+	printStat(out, "Range", fmt.Sprintf("%s - %s", report.From.Format("2006-01-02 15:04"), report.To.Format("2006-01-02 15:04")))
+	printStat(out, "Files", strconv.Itoa(report.Files))
+	printStat(out, "Records", strconv.Itoa(report.Lines))
+	printStat(out, "Errors", styleError.Render(strconv.Itoa(report.Levels["ERROR"])))
+	printStat(out, "Warnings", styleWarning.Render(strconv.Itoa(report.Levels["WARN"])))
+
+	printLogCounts(out, "Most common errors", report.Errors)
+	printLogCounts(out, "Most common exceptions", report.Exceptions)
+	printLogCounts(out, "Plugin/mod warnings", report.Sources)
+	printLogCounts(out, "Most common warnings", report.Warnings)
+
+	if len(report.Startups) > 0 {
+		fmt.Fprintf(out, "\n%s\n", styleTitle.Render("Startup time"))
+		seconds := make([]float64, 0, len(report.Startups))
+		low, high := report.Startups[0].Seconds, report.Startups[0].Seconds
+		for _, startup := range report.Startups {
+			seconds = append(seconds, startup.Seconds)
+			low, high = min(low, startup.Seconds), max(high, startup.Seconds)
+		}
+		first, last := report.Startups[0], report.Startups[len(report.Startups)-1]
+		printStat(out, "Starts", strconv.Itoa(len(report.Startups)))
+		printStat(out, "Fastest", fmt.Sprintf("%.1fs", low))
+		printStat(out, "Slowest", fmt.Sprintf("%.1fs", high))
+		printStat(out, "Trend", fmt.Sprintf("%.1fs (%s) -> %.1fs (%s)  %s", first.Seconds, first.Time.Format("01-02"), last.Seconds, last.Time.Format("01-02"), sparkline(seconds, 0, high, 40)))
+	}
+
+	fmt.Fprintf(out, "\n%s\n", styleTitle.Render("Lag"))
+	if report.Lag.Events == 0 && report.Lag.Watchdogs == 0 {
+		fmt.Fprintf(out, "  %s\n", styleSuccess.Render("No \"Can't keep up\" or watchdog warnings."))
+		return
+	}
+	printStat(out, "Overloaded", fmt.Sprintf("%d warning(s), %s behind in total, worst %dms", report.Lag.Events, (time.Duration(report.Lag.MsBehind)*time.Millisecond).Round(time.Second), report.Lag.WorstMs))
+	if report.Lag.Watchdogs > 0 {
+		printStat(out, "Watchdog", styleError.Render(fmt.Sprintf("%d unresponsive server report(s)", report.Lag.Watchdogs)))
+	}
+	events := make([]float64, 0, len(report.Lag.Days))
+	var busiest float64
+	for _, day := range report.Lag.Days {
+		events = append(events, float64(day.Events))
+		busiest = max(busiest, float64(day.Events))
+	}
+	if len(report.Lag.Days) > 1 {
+		printStat(out, "Per day", fmt.Sprintf("%s  %s", sparkline(events, 0, busiest, 40), styleDim.Render(report.Lag.Days[0].Day+" .. "+report.Lag.Days[len(report.Lag.Days)-1].Day)))
+	}
+}
+
+func printLogCounts(out io.Writer, title string, counts []loganalysis.Count) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\n%s\n", styleTitle.Render(title))
+	for _, count := range counts {
+		fmt.Fprintf(out, "  %6d  %s %s\n", count.Count, count.Text, styleDim.Render("(last "+count.LastSeen.Format("01-02 15:04")+")"))
+	}
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+)
+
+// MachineEnv turns on machine mode like --machine, for tools that find an
+// environment variable easier to set than a flag.
+const MachineEnv = "MINEOS_MACHINE"
+
+// machineMode is set for the whole run by Execute: styling is off, prompts
+// fail instead of waiting for input and stdout becomes one JSON document.
+var machineMode bool
+
+var errMachinePrompt = errors.New("input required but prompts are disabled in machine mode; pass the value as a flag (e.g. --yes)")
+
+// machineResult is the document printed on stdout in machine mode. Result
+// holds the command's JSON output; anything else it printed is in Output.
+type machineResult struct {
+	Command  string          `json:"command"`
+	ExitCode int             `json:"exit_code"`
+	Result   json.RawMessage `json:"result"`
+	Output   []string        `json:"output"`
+	Error    *machineError   `json:"error,omitempty"`
+}
+
+type machineError struct {
+	// Kind is usage, prompt, auth or error.
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// Execute runs the root command, in machine mode when --machine or
+// MINEOS_MACHINE=1 is given.
+func Execute(root *cobra.Command) error {
+	defer closeRemote()
+	var err error
+	if !machineRequested(os.Args[1:]) {
+		err = root.Execute()
+	} else {
+		err = executeMachine(root)
+	}
+	forgetChanged()
+	err = withExitCode(err)
+	recordOperation(err)
+	finishTranscript(err)
+	return err
+}
+
+// machineRequested looks for --machine before cobra parses flags, so that
+// flag and usage errors are reported as JSON too.
+func machineRequested(args []string) bool {
+	if enabled, err := strconv.ParseBool(os.Getenv(MachineEnv)); err == nil && enabled {
+		return true
+	}
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--machine" {
+			return true
+		}
+		if value, ok := strings.CutPrefix(arg, "--machine="); ok {
+			enabled, _ := strconv.ParseBool(value)
+			return enabled
+		}
+	}
+	return false
+}
+
+func executeMachine(root *cobra.Command) error {
+	machineMode = true
+	defer func() { machineMode = false }()
+	lipgloss.SetColorProfile(termenv.Ascii)
+	root.SilenceErrors = true
+	root.SilenceUsage = true
+
+	// Commands write through cmd.OutOrStdout() as well as fmt.Print, so the
+	// process's stdout is captured rather than the command's writer.
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	os.Stdout = writer
+	captured := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		captured <- data
+	}()
+
+	executed, runErr := root.ExecuteC()
+	writer.Close()
+	os.Stdout = stdout
+	output := strings.TrimSpace(ansiEscape.ReplaceAllString(string(<-captured), ""))
+
+	result := machineResult{Result: json.RawMessage("null"), Output: []string{}}
+	if executed != nil {
+		result.Command = strings.TrimSpace(strings.TrimPrefix(executed.CommandPath(), root.Name()))
+	}
+	switch {
+	case output == "":
+	case json.Valid([]byte(output)):
+		result.Result = json.RawMessage(output)
+	default:
+		result.Output = strings.Split(output, "\n")
+	}
+
+	if runErr != nil {
+		result.ExitCode = exitCode(runErr)
+		if message := runErr.Error(); message != "" {
+			result.Error = &machineError{Kind: machineErrorKind(runErr), Message: strings.TrimSpace(message)}
+		}
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return exitCodeError{code: result.ExitCode}
+	}
+	return nil
+}
+
+func machineErrorKind(err error) string {
+	if errors.Is(err, errMachinePrompt) {
+		return "prompt"
+	}
+	if kind, ok := exitKinds[exitCode(err)]; ok {
+		return kind
+	}
+	return "error"
+}
+
+// prepareMachineCommand switches commands with a --json flag to JSON and
+// refuses the interactive ones.
+func prepareMachineCommand(cmd *cobra.Command) error {
+	switch cmd.Name() {
+	case "tui", "interactive":
+		return errors.New(cmd.Name() + " is interactive and not available in machine mode")
+	}
+	if flag := cmd.Flags().Lookup("json"); flag != nil {
+		return flag.Value.Set("true")
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/migrate"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/panels"
+)
+
+func NewMigrateCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Import servers from Pterodactyl, AMP or a screen/tmux setup",
+		Long: `Find Minecraft servers run by another panel or by hand on this machine and
+import them into MineOS, with their memory, JVM flags, jar and port.
+
+Pterodactyl servers are read from their Wings volume and container, AMP
+instances from their .kvp settings, and hand-run servers from their start
+script (start.sh, run.sh, a screen or tmux line, Forge's user_jvm_args.txt).
+The files are copied; the original server is left as it is.`,
+		Example: `  mineos migrate scan
+  mineos migrate import ~/minecraft --name survival
+  mineos migrate import /var/lib/pterodactyl/volumes/*`,
+	}
+	cmd.AddCommand(newMigrateScanCommand())
+	cmd.AddCommand(newMigrateImportCommand(loadConfig))
+	return cmd
+}
+
+func newMigrateScanCommand() *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "scan [dir...]",
+		Short: "List servers that can be imported",
+		Long: `List the servers found in the given directories, or in the usual places:
+` + strings.Join(panels.DefaultRoots(), ", ") + `.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+			roots := args
+			if len(roots) == 0 {
+				roots = panels.DefaultRoots()
+			}
+			sources := []migrate.Source{}
+			for _, root := range roots {
+				sources = append(sources, panels.Scan(ctx, root)...)
+			}
+			if jsonOut {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(sources)
+			}
+			if len(sources) == 0 {
+				fmt.Fprintln(out, "No servers found. Pass the directory that holds server.properties.")
+				return nil
+			}
+			for i, source := range sources {
+				if i > 0 {
+					fmt.Fprintln(out)
+				}
+				printMigrateSource(out, source)
+			}
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, styleDim.Render("Import one with 'mineos migrate import <dir>'."))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the servers as JSON")
+
+	return cmd
+}
+
+func newMigrateImportCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var name string
+	var dryRun bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "import <dir>...",
+		Short: "Copy servers into MineOS",
+		Long: `Upload each server directory to MineOS, create a server from it and apply
+the memory, JVM flags, jar and jar arguments it was started with. The port
+is kept unless another server uses it.
+
+Stop the server in the old panel first, so the world is not copied while it
+is being written.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if name != "" && len(args) > 1 {
+				return fmt.Errorf("--name can only be used when importing one server")
+			}
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+			sources := make([]migrate.Source, 0, len(args))
+			for _, dir := range args {
+				source, ok := panels.Detect(ctx, dir)
+				if !ok {
+					return fmt.Errorf("%s is not a server directory (no server.properties, or eula.txt and a jar)", dir)
+				}
+				if name != "" {
+					source.Name = name
+				}
+				if source.Name == "" {
+					return fmt.Errorf("cannot name the server in %s; pass --name", dir)
+				}
+				sources = append(sources, source)
+			}
+			for i, source := range sources {
+				if i > 0 {
+					fmt.Fprintln(out)
+				}
+				printMigrateSource(out, source)
+			}
+			if dryRun {
+				return nil
+			}
+
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, styleWarning.Render("Stop these servers in the old panel first; a running server's world may be copied half-written."))
+			if !yes {
+				ok, err := promptYesNo(nil, out, fmt.Sprintf("Import %d server(s) into MineOS?", len(sources)), true)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("import cancelled")
+				}
+			}
+
+			_, err := withApiKeyRetry(ctx, loadConfig, out, func(cfg config.Config, client *api.Client) error {
+				for _, source := range sources {
+					exists, err := serverExists(ctx, client, source.Name)
+					if err != nil {
+						return err
+					}
+					if exists {
+						return ports.WithKind(fmt.Errorf("a server named %s already exists; pass --name", source.Name), ports.ErrConflict)
+					}
+				}
+				for _, source := range sources {
+					fmt.Fprintln(out)
+					if err := importMigrateSource(ctx, client, cfg, cmd, source); err != nil {
+						return fmt.Errorf("import %s: %w", source.Dir, err)
+					}
+				}
+				return nil
+			})
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Name of the server in MineOS (default: from the panel or directory)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be imported")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Import without asking for confirmation")
+
+	return cmd
+}
+
+// importMigrateSource uploads one server as a .tar.gz, packed while it is
+// sent, creates it from the import and writes its Java settings.
+func importMigrateSource(ctx context.Context, client *api.Client, cfg config.Config, cmd *cobra.Command, source migrate.Source) error {
+	out := cmd.OutOrStdout()
+	filename := fmt.Sprintf("%s-%d.tar.gz", source.Name, time.Now().Unix())
+
+	bar := newTransferProgress(out, "Uploading "+source.Name, panels.Size(source.Dir))
+	reader, writer := io.Pipe()
+	packed := make(chan struct{})
+	go func() {
+		defer close(packed)
+		writer.CloseWithError(panels.Pack(writer, source.Dir, bar))
+	}()
+	err := client.UploadImport(ctx, filename, reader)
+	reader.Close()
+	<-packed
+	bar.Finish()
+	if err != nil {
+		// A partial upload may have been kept.
+		_ = client.DeleteImport(ctx, filename)
+		return err
+	}
+	defer func() {
+		if err := client.DeleteImport(ctx, filename); err != nil {
+			fmt.Fprintln(out, styleWarning.Render("remove the uploaded archive: "+err.Error()))
+		}
+	}()
+
+	jobID, err := client.ImportServer(ctx, filename, source.Name, false)
+	if err != nil {
+		return err
+	}
+	cmd.Printf("Creating %s...\n", source.Name)
+	if err := waitForJob(ctx, client, out, jobID); err != nil {
+		return err
+	}
+	reportQuarantined(ctx, client, out, source.Name)
+
+	serverCfg, err := client.GetServerConfig(ctx, source.Name)
+	if err != nil {
+		return err
+	}
+	if source.XmxMB > 0 {
+		serverCfg.Java.JavaXmx = source.XmxMB
+	}
+	if source.XmsMB > 0 && source.XmsMB <= serverCfg.Java.JavaXmx {
+		serverCfg.Java.JavaXms = source.XmsMB
+	}
+	if len(source.JvmArgs) > 0 {
+		tweaks := strings.Join(source.JvmArgs, " ")
+		serverCfg.Java.JavaTweaks = &tweaks
+	}
+	if source.JarFile != "" {
+		jar := source.JarFile
+		serverCfg.Java.JarFile = &jar
+	}
+	if len(source.JarArgs) > 0 {
+		jarArgs := strings.Join(source.JarArgs, " ")
+		serverCfg.Java.JarArgs = &jarArgs
+	}
+	if err := client.UpdateServerConfig(ctx, source.Name, serverCfg); err != nil {
+		return err
+	}
+	cmd.Printf("Imported %s\n", source.Name)
+	if err := assignFreePorts(ctx, client, cfg, cmd, source.Name, 0); err != nil {
+		return err
+	}
+
+	for _, note := range source.Notes {
+		fmt.Fprintln(out, styleWarning.Render("Check: ")+note)
+	}
+	cmd.Println(styleDim.Render("Start it with 'mineos servers start " + source.Name + "'."))
+	return nil
+}
+
+func printMigrateSource(out io.Writer, source migrate.Source) {
+	fmt.Fprintln(out, styleTitle.Render(source.Name)+" "+styleDim.Render("("+source.Panel+")"))
+	printStat(out, "Directory", source.Dir)
+	printStat(out, "Size", formatBytes(panels.Size(source.Dir)))
+	printStat(out, "Jar", fallback(source.JarFile, "-"))
+	memory := "-"
+	if source.XmxMB > 0 {
+		memory = strconv.Itoa(source.XmxMB) + " MB"
+		if source.XmsMB > 0 {
+			memory = strconv.Itoa(source.XmsMB) + "-" + memory
+		}
+	}
+	printStat(out, "Memory", memory)
+	if len(source.JvmArgs) > 0 {
+		printStat(out, "JVM flags", strings.Join(source.JvmArgs, " "))
+	}
+	if len(source.JarArgs) > 0 {
+		printStat(out, "Arguments", strings.Join(source.JarArgs, " "))
+	}
+	if source.Port > 0 {
+		printStat(out, "Port", strconv.Itoa(source.Port))
+	}
+	for _, note := range source.Notes {
+		fmt.Fprintln(out, "  "+styleWarning.Render("! ")+note)
+	}
+}
@@ -0,0 +1,292 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/anvil"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/nbt"
+)
+
+func NewNbtCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "nbt",
+		Short: "Inspect and edit NBT files (level.dat, playerdata, region chunks)",
+		Long: `Read and edit NBT files such as level.dat and <world>/playerdata/<uuid>.dat.
+
+Files are local paths, or paths inside a server directory with --server.
+Tag paths are dot-separated with list indexes in brackets, e.g.
+Data.GameRules.keepInventory or Pos[1].`,
+	}
+
+	cmd.AddCommand(newNbtShowCommand(loadConfig))
+	cmd.AddCommand(newNbtGetCommand(loadConfig))
+	cmd.AddCommand(newNbtSetCommand(loadConfig))
+
+	return cmd
+}
+
+// nbtTarget is the file an nbt subcommand works on.
+type nbtTarget struct {
+	server string
+	chunk  string
+}
+
+func (t *nbtTarget) register(cmd *cobra.Command, chunks bool) {
+	cmd.Flags().StringVar(&t.server, "server", "", "Resolve the file inside this server's directory")
+	if chunks {
+		cmd.Flags().StringVar(&t.chunk, "chunk", "", "Chunk coordinates x,z to read from a .mca region file")
+	}
+}
+
+func (t nbtTarget) path(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, file string) (string, error) {
+	if t.server == "" {
+		return file, nil
+	}
+	storage, err := loadHostStorage(ctx, loadConfig)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(storage.ServerDir(t.server), file), nil
+}
+
+// load reads an NBT file, or one chunk of a region file with --chunk.
+func (t nbtTarget) load(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, file string) (string, nbt.Document, error) {
+	path, err := t.path(ctx, loadConfig, file)
+	if err != nil {
+		return "", nbt.Document{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nbt.Document{}, err
+	}
+
+	if strings.HasSuffix(path, ".mca") {
+		if t.chunk == "" {
+			return "", nbt.Document{}, fmt.Errorf("%s is a region file; pick a chunk with --chunk x,z", file)
+		}
+		data, err = regionChunk(filepath.Base(path), data, t.chunk)
+		if err != nil {
+			return "", nbt.Document{}, err
+		}
+	}
+	doc, err := nbt.Read(data)
+	if err != nil {
+		return "", nbt.Document{}, fmt.Errorf("read %s: %w", file, err)
+	}
+	return path, doc, nil
+}
+
+func regionChunk(name string, data []byte, coords string) ([]byte, error) {
+	rx, rz, ok := anvil.RegionCoords(name)
+	if !ok {
+		return nil, fmt.Errorf("%s is not named like a region file (r.<x>.<z>.mca)", name)
+	}
+	xs, zs, ok := strings.Cut(coords, ",")
+	x, errX := strconv.Atoi(strings.TrimSpace(xs))
+	z, errZ := strconv.Atoi(strings.TrimSpace(zs))
+	if !ok || errX != nil || errZ != nil {
+		return nil, fmt.Errorf("--chunk must be x,z chunk coordinates")
+	}
+	if x>>5 != rx || z>>5 != rz {
+		return nil, fmt.Errorf("chunk %d,%d is in r.%d.%d.mca, not %s", x, z, x>>5, z>>5, name)
+	}
+	chunk, err := anvil.Region{X: rx, Z: rz, Data: data}.ChunkData((x & 31) + (z&31)*32)
+	if err != nil {
+		return nil, fmt.Errorf("chunk %d,%d: %w", x, z, err)
+	}
+	return chunk, nil
+}
+
+func newNbtShowCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var target nbtTarget
+
+	cmd := &cobra.Command{
+		Use:   "show <file> [path]",
+		Short: "Pretty-print an NBT file or part of it",
+		Example: `  mineos nbt show --server survival world/level.dat Data.GameRules
+  mineos nbt show ./world/playerdata/0f2b0c4e-8b1a-4c4e-9d56-5d3b0a7f2c11.dat
+  mineos nbt show --server survival world/region/r.0.0.mca --chunk 3,7`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, doc, err := target.load(context.Background(), loadConfig, args[0])
+			if err != nil {
+				return err
+			}
+			var value any = doc.Root
+			if len(args) == 2 {
+				if value, err = lookupTag(doc.Root, args[1]); err != nil {
+					return err
+				}
+			}
+			cmd.Println(nbt.Format(value))
+			return nil
+		},
+	}
+
+	target.register(cmd, true)
+
+	return cmd
+}
+
+func newNbtGetCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var target nbtTarget
+
+	cmd := &cobra.Command{
+		Use:   "get <file> <path>",
+		Short: "Print one tag; numbers and strings are printed bare for scripts",
+		Example: `  mineos nbt get --server survival world/level.dat Data.WorldGenSettings.seed
+  mineos nbt get --server survival world/playerdata/<uuid>.dat Pos`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, doc, err := target.load(context.Background(), loadConfig, args[0])
+			if err != nil {
+				return err
+			}
+			value, err := lookupTag(doc.Root, args[1])
+			if err != nil {
+				return err
+			}
+			cmd.Println(bareTag(value))
+			return nil
+		},
+	}
+
+	target.register(cmd, true)
+
+	return cmd
+}
+
+func newNbtSetCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var target nbtTarget
+	var tagType string
+
+	cmd := &cobra.Command{
+		Use:   "set <file> <path> <value>",
+		Short: "Change one tag in an NBT file",
+		Long: `Change one tag in an NBT file. The value is parsed as the existing tag's
+type; lists take "[a, b, c]". New tags need --type. With --server the
+server must be stopped, since it rewrites these files while running.
+The original file is kept as <file>.mineos-bak.`,
+		Example: `  # Move a player stuck in a wall back to spawn height
+  mineos nbt set --server survival world/playerdata/<uuid>.dat Pos "[0.5, 80, 0.5]"
+
+  # Change a game rule without starting the server
+  mineos nbt set --server survival world/level.dat Data.GameRules.keepInventory true
+
+  # Add a tag
+  mineos nbt set ./level.dat Data.allowCommands 1 --type byte`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			file, tagPath, raw := args[0], args[1], args[2]
+			if strings.HasSuffix(file, ".mca") {
+				return fmt.Errorf("editing region chunks is not supported")
+			}
+			if target.server != "" {
+				if err := requireServerStopped(ctx, loadConfig, cmd, target.server); err != nil {
+					return err
+				}
+			}
+			path, doc, err := target.load(ctx, loadConfig, file)
+			if err != nil {
+				return err
+			}
+
+			old, exists := doc.Root.Get(tagPath)
+			var value any
+			switch {
+			case tagType != "":
+				parsed, ok := nbt.ParseType(tagType)
+				if !ok {
+					return fmt.Errorf("unknown --type %q", tagType)
+				}
+				value, err = nbt.ParseValue(raw, parsed)
+			case exists:
+				value, err = nbt.ParseLike(raw, old)
+			default:
+				return fmt.Errorf("%s does not exist; pass --type to create it", tagPath)
+			}
+			if err != nil {
+				return err
+			}
+			if err := doc.Root.Set(tagPath, value); err != nil {
+				return err
+			}
+
+			data, err := doc.Bytes()
+			if err != nil {
+				return err
+			}
+			if err := replaceWithBackup(path, data); err != nil {
+				return err
+			}
+			if exists {
+				cmd.Printf("%s: %s -> %s\n", tagPath, nbt.Format(old), nbt.Format(value))
+			} else {
+				cmd.Printf("%s: added %s\n", tagPath, nbt.Format(value))
+			}
+			return nil
+		},
+	}
+
+	target.register(cmd, false)
+	cmd.Flags().StringVar(&tagType, "type", "", "Tag type for new tags: byte, short, int, long, float, double, string, byte[], int[], long[]")
+
+	return cmd
+}
+
+func lookupTag(root nbt.Compound, path string) (any, error) {
+	value, ok := root.Get(path)
+	if !ok {
+		return nil, fmt.Errorf("%s not found", path)
+	}
+	return value, nil
+}
+
+// bareTag prints scalars without SNBT suffixes or quotes.
+func bareTag(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int8, int16, int32, int64:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return nbt.Format(value)
+}
+
+// replaceWithBackup keeps the current file as <path>.mineos-bak and swaps
+// in the new content.
+func replaceWithBackup(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".mineos-bak", current, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("save copy before writing: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	domainnetwork "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/network"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/network"
+)
+
+func NewNetworkCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "Start and stop a proxied server network in dependency order",
+		Long: `Start and stop servers in the order declared in the orchestration file
+(mineos-network.yaml next to .env by default):
+
+  stagger_seconds: 5
+  servers:
+    velocity:
+      role: proxy
+    lobby: {}
+    survival:
+      depends_on: [lobby]
+
+Proxies start after every backend and stop before them. Servers not listed
+are treated as backends with no dependencies.
+
+'network expose' publishes the web UI through Tailscale or a Cloudflare
+Tunnel instead, and 'network test' measures the path players take to a
+Minecraft port.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&file, "file", "", "Orchestration file (default: mineos-network.yaml next to .env)")
+
+	cmd.AddCommand(newNetworkOrderCommand(loadConfig, &file))
+	cmd.AddCommand(newNetworkActionCommand(loadConfig, &file, "start"))
+	cmd.AddCommand(newNetworkActionCommand(loadConfig, &file, "stop"))
+	cmd.AddCommand(newNetworkActionCommand(loadConfig, &file, "restart"))
+	cmd.AddCommand(newNetworkExposeCommand(loadConfig))
+	cmd.AddCommand(newNetworkTestCommand(loadConfig))
+
+	return cmd
+}
+
+func newNetworkOrderCommand(loadConfig *usecases.LoadConfigUseCase, file *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "order",
+		Short: "Show the start and stop order",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+			cfg, err := loadConfig.Execute(ctx)
+			if err != nil {
+				return err
+			}
+			def, path, err := loadNetworkDefinition(cfg, *file)
+			if err != nil {
+				return err
+			}
+
+			var names []string
+			_, err = withApiKeyRetry(ctx, loadConfig, out, func(_ config.Config, client *api.Client) error {
+				servers, err := client.ListServers(ctx)
+				if err != nil {
+					return err
+				}
+				names = serverNames(servers)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "%s %s\n", styleLabel.Render("Orchestration file:"), path)
+			for _, problem := range def.Validate(names) {
+				fmt.Fprintf(out, "%s %s\n", styleWarning.Render("Warning:"), problem)
+			}
+
+			startTiers, err := def.StartTiers(names)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(out, styleTitle.Render("\nStart order"))
+			printTiers(out, def, startTiers)
+			stopTiers, _ := def.StopTiers(names)
+			fmt.Fprintln(out, styleTitle.Render("\nStop order"))
+			printTiers(out, def, stopTiers)
+			return nil
+		},
+	}
+}
+
+func newNetworkActionCommand(loadConfig *usecases.LoadConfigUseCase, file *string, action string) *cobra.Command {
+	var parallel int
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   action + " [name|pattern]...",
+		Short: fmt.Sprintf("%s servers in dependency order", action),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+			cfg, err := loadConfig.Execute(ctx)
+			if err != nil {
+				return err
+			}
+			def, _, err := loadNetworkDefinition(cfg, *file)
+			if err != nil {
+				return err
+			}
+
+			var results []ports.BulkActionResult
+			started := time.Now()
+			_, err = withApiKeyRetry(ctx, loadConfig, out, func(_ config.Config, client *api.Client) error {
+				selector := usecases.ServerSelector{All: len(args) == 0, Patterns: args}
+				names, err := usecases.NewSelectServersUseCase(client).Execute(ctx, selector)
+				if err != nil {
+					return err
+				}
+				if len(names) == 0 {
+					return fmt.Errorf("no servers found")
+				}
+
+				if action == "restart" {
+					stopped, err := runNetworkTiers(ctx, client, out, def, names, "stop", parallel, dryRun)
+					results = append(results, stopped...)
+					if err != nil || dryRun {
+						return err
+					}
+					started, err := runNetworkTiers(ctx, client, out, def, names, "start", parallel, dryRun)
+					results = append(results, started...)
+					return err
+				}
+				results, err = runNetworkTiers(ctx, client, out, def, names, action, parallel, dryRun)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				return nil
+			}
+			return printBulkSummary(out, results, time.Since(started))
+		},
+	}
+
+	cmd.Flags().IntVar(&parallel, "parallel", 4, "Maximum number of servers acted on concurrently within a tier")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the plan without acting on servers")
+
+	return cmd
+}
+
+func runNetworkTiers(ctx context.Context, client *api.Client, out io.Writer, def domainnetwork.Definition, names []string, action string, parallel int, dryRun bool) ([]ports.BulkActionResult, error) {
+	var tiers [][]string
+	var err error
+	if action == "start" {
+		tiers, err = def.StartTiers(names)
+	} else {
+		tiers, err = def.StopTiers(names)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		fmt.Fprintf(out, "%s\n", styleTitle.Render(strings.ToUpper(action[:1])+action[1:]+" plan"))
+		printTiers(out, def, tiers)
+		return nil, nil
+	}
+
+	uc := usecases.NewOrderedServerActionUseCase(client)
+	return uc.Execute(ctx, tiers, action, usecases.OrderedActionOptions{
+		Parallel:       parallel,
+		Stagger:        time.Duration(def.StaggerSeconds) * time.Second,
+		AbortOnFailure: action == "start",
+		OnTier: func(i int, tier []string) {
+			fmt.Fprintf(out, "%s %s\n", styleStep.Render(fmt.Sprintf("[%s tier %d]", action, i+1)), strings.Join(tier, ", "))
+		},
+		OnDone: func(result ports.BulkActionResult) {
+			if result.Err != nil {
+				fmt.Fprintf(out, "  %s %s: %v\n", styleError.Render("x"), result.Name, result.Err)
+			} else {
+				fmt.Fprintf(out, "  %s %s\n", styleSuccess.Render("ok"), result.Name)
+			}
+		},
+	}), nil
+}
+
+func loadNetworkDefinition(cfg config.Config, file string) (domainnetwork.Definition, string, error) {
+	repo := network.NewFileRepositoryForEnv(cfg.EnvPath)
+	if strings.TrimSpace(file) != "" {
+		repo = network.NewFileRepository(file)
+	}
+	def, _, err := repo.Load()
+	if err != nil {
+		return def, repo.Path(), fmt.Errorf("failed to read %s: %w", repo.Path(), err)
+	}
+	return def, repo.Path(), nil
+}
+
+// stopServersInOrder stops running servers tier by tier when an orchestration
+// file exists. It returns false when there is no file so callers can fall
+// back to the API's stop-all.
+func stopServersInOrder(ctx context.Context, client *api.Client, cfg config.Config, servers []ports.Server, out io.Writer) (bool, error) {
+	def, exists, err := network.NewFileRepositoryForEnv(cfg.EnvPath).Load()
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, nil
+	}
+
+	var running []string
+	for _, server := range servers {
+		if strings.EqualFold(server.Status, "running") {
+			running = append(running, server.Name)
+		}
+	}
+	if len(running) == 0 {
+		fmt.Fprintln(out, "No running servers.")
+		return true, nil
+	}
+
+	fmt.Fprintln(out, "Stopping servers in network order...")
+	results, err := runNetworkTiers(ctx, client, out, def, running, "stop", 4, false)
+	if err != nil {
+		return true, err
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			return true, fmt.Errorf("failed to stop %s: %w", result.Name, result.Err)
+		}
+	}
+	return true, nil
+}
+
+func printTiers(out io.Writer, def domainnetwork.Definition, tiers [][]string) {
+	for i, tier := range tiers {
+		labels := make([]string, 0, len(tier))
+		for _, name := range tier {
+			if def.IsProxy(name) {
+				name += styleDim.Render(" (proxy)")
+			}
+			labels = append(labels, name)
+		}
+		fmt.Fprintf(out, "  %d. %s\n", i+1, strings.Join(labels, ", "))
+	}
+}
+
+func serverNames(servers []ports.Server) []string {
+	names := make([]string, 0, len(servers))
+	for _, server := range servers {
+		names = append(names, server.Name)
+	}
+	return names
+}
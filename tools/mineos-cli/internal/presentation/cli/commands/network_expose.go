@@ -0,0 +1,350 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/portmap"
+)
+
+// cloudflaredConfigFile is written next to .env by 'network expose'.
+const cloudflaredConfigFile = "cloudflared.yml"
+
+type exposeOptions struct {
+	provider      string
+	hostname      string
+	tunnelName    string
+	minecraft     bool
+	minecraftHost string
+	funnel        bool
+	dryRun        bool
+	jsonOut       bool
+}
+
+type exposeResult struct {
+	Provider      string   `json:"provider"`
+	Origin        string   `json:"origin"`
+	CaddySite     string   `json:"caddy_site"`
+	MinecraftHost string   `json:"minecraft_host,omitempty"`
+	TunnelConfig  string   `json:"tunnel_config,omitempty"`
+	RunCommand    string   `json:"run_command,omitempty"`
+	Notes         []string `json:"notes,omitempty"`
+	DryRun        bool     `json:"dry_run"`
+}
+
+func newNetworkExposeCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	opts := exposeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "expose",
+		Short: "Publish the web UI through Tailscale or a Cloudflare Tunnel",
+		Long: `Publishes the web UI without port forwarding and writes the resulting
+address into WEB_ORIGIN_PROD, PUBLIC_API_BASE_URL, ORIGIN and CADDY_SITE.
+
+  tailscale   uses the machine's tailnet name; 'tailscale serve' adds HTTPS
+              for tailnet devices, --funnel publishes it to the internet
+  cloudflare  creates (or reuses) a named tunnel, routes --hostname to it
+              and writes cloudflared.yml next to .env
+
+The provider defaults to Tailscale when it is connected, else Cloudflare.
+With --minecraft, PUBLIC_MINECRAFT_HOST is set too: tailnet devices reach
+the server ports directly, and Cloudflare forwards TCP to --minecraft-hostname
+for players running 'cloudflared access tcp'.`,
+		Example: `  mineos network expose
+  mineos network expose --provider tailscale --funnel
+  mineos network expose --provider cloudflare --hostname mineos.example.com`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runNetworkExpose(cmd.Context(), cmd.OutOrStdout(), loadConfig, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.provider, "provider", "auto", "auto, tailscale or cloudflare")
+	cmd.Flags().StringVar(&opts.hostname, "hostname", "", "Public hostname for the web UI (cloudflare)")
+	cmd.Flags().StringVar(&opts.tunnelName, "tunnel-name", "mineos", "Cloudflare tunnel to create or reuse")
+	cmd.Flags().BoolVar(&opts.minecraft, "minecraft", false, "Also publish the first Minecraft port and set PUBLIC_MINECRAFT_HOST")
+	cmd.Flags().StringVar(&opts.minecraftHost, "minecraft-hostname", "", "Hostname for Minecraft TCP (cloudflare, implies --minecraft)")
+	cmd.Flags().BoolVar(&opts.funnel, "funnel", false, "Publish to the internet with Tailscale Funnel instead of the tailnet only")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show the address and changes without running the provider or writing .env")
+	cmd.Flags().BoolVar(&opts.jsonOut, "json", false, "Print the result as JSON")
+
+	return cmd
+}
+
+func runNetworkExpose(ctx context.Context, out io.Writer, loadConfig *usecases.LoadConfigUseCase, opts exposeOptions) error {
+	if sshRemote != nil {
+		return errors.New("'mineos network expose' runs the tunnel on this machine; run it on the MineOS host instead of over --ssh")
+	}
+	cfg, err := loadConfig.Execute(ctx)
+	if err != nil {
+		return err
+	}
+	values, err := loadLayeredEnvValues(cfg)
+	if err != nil {
+		return err
+	}
+	webPort := fallback(values["WEB_PORT"], fmt.Sprint(defaultWebPort))
+	minecraftPort := portmap.ParseRange(values["MC_PORT_RANGE"], defaultJavaPortRange).From
+	if opts.minecraftHost != "" {
+		opts.minecraft = true
+	}
+
+	provider := strings.ToLower(strings.TrimSpace(opts.provider))
+	if provider == "auto" {
+		provider = "cloudflare"
+		if _, err := tailscaleSelf(ctx); err == nil {
+			provider = "tailscale"
+		}
+	}
+
+	var result exposeResult
+	switch provider {
+	case "tailscale":
+		result, err = exposeTailscale(ctx, opts, webPort)
+	case "cloudflare":
+		result, err = exposeCloudflare(ctx, cfg.EnvPath, opts, webPort, minecraftPort)
+	default:
+		return fmt.Errorf("unknown provider %q (use tailscale or cloudflare)", opts.provider)
+	}
+	if err != nil {
+		return err
+	}
+	result.DryRun = opts.dryRun
+	result.CaddySite = deriveCaddySite(result.Origin)
+
+	if !opts.dryRun {
+		envPath := fallback(cfg.EnvPath, ".env")
+		updates := [][2]string{
+			{"WEB_ORIGIN_PROD", result.Origin},
+			{"PUBLIC_API_BASE_URL", result.Origin},
+			{"ORIGIN", result.Origin},
+			{"CADDY_SITE", result.CaddySite},
+		}
+		if result.MinecraftHost != "" {
+			updates = append(updates, [2]string{"PUBLIC_MINECRAFT_HOST", result.MinecraftHost})
+		}
+		for _, update := range updates {
+			if err := setEnvFileValue(envPath, update[0], update[1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.jsonOut {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	title := "Exposed with " + result.Provider
+	if opts.dryRun {
+		title = "Would expose with " + result.Provider
+	}
+	fmt.Fprintln(out, styleTitle.Render(title))
+	fmt.Fprintf(out, "  %s %s\n", styleLabel.Render("Web UI:"), result.Origin)
+	fmt.Fprintf(out, "  %s %s\n", styleLabel.Render("CADDY_SITE:"), result.CaddySite)
+	if result.MinecraftHost != "" {
+		fmt.Fprintf(out, "  %s %s\n", styleLabel.Render("Minecraft:"), result.MinecraftHost)
+	}
+	if result.TunnelConfig != "" {
+		fmt.Fprintf(out, "  %s %s\n", styleLabel.Render("Tunnel config:"), result.TunnelConfig)
+	}
+	if result.RunCommand != "" {
+		fmt.Fprintf(out, "  %s %s\n", styleLabel.Render("Keep it running with:"), result.RunCommand)
+	}
+	for _, note := range result.Notes {
+		fmt.Fprintln(out, styleDim.Render("  "+note))
+	}
+	if opts.dryRun {
+		return nil
+	}
+
+	fmt.Fprintln(out, "")
+	if report, err := applyConfigChanges(ctx, loadConfig, false); err == nil {
+		printConfigApplyReport(out, report)
+	} else {
+		fmt.Fprintln(out, styleDim.Render("Run 'mineos stack up' to apply the new origin."))
+	}
+	return nil
+}
+
+type tailscaleStatus struct {
+	BackendState string `json:"BackendState"`
+	Self         struct {
+		DNSName      string   `json:"DNSName"`
+		TailscaleIPs []string `json:"TailscaleIPs"`
+	} `json:"Self"`
+}
+
+// tailscaleSelf returns this machine's tailnet name (or address) when
+// Tailscale is installed and connected.
+func tailscaleSelf(ctx context.Context) (string, error) {
+	if _, err := exec.LookPath("tailscale"); err != nil {
+		return "", errors.New("tailscale is not installed")
+	}
+	data, err := toolOutput(ctx, "tailscale", "status", "--json")
+	if err != nil {
+		return "", err
+	}
+	var status tailscaleStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return "", fmt.Errorf("parse tailscale status: %w", err)
+	}
+	if status.BackendState != "Running" {
+		return "", fmt.Errorf("tailscale is not connected (state %s); run 'tailscale up'", fallback(status.BackendState, "unknown"))
+	}
+	if name := strings.TrimSuffix(status.Self.DNSName, "."); name != "" {
+		return name, nil
+	}
+	if len(status.Self.TailscaleIPs) > 0 {
+		return status.Self.TailscaleIPs[0], nil
+	}
+	return "", errors.New("tailscale reported no name or address for this machine")
+}
+
+func exposeTailscale(ctx context.Context, opts exposeOptions, webPort string) (exposeResult, error) {
+	host, err := tailscaleSelf(ctx)
+	if err != nil {
+		return exposeResult{}, err
+	}
+	result := exposeResult{Provider: "tailscale", Origin: "https://" + host}
+	if opts.minecraft {
+		result.MinecraftHost = host
+		result.Notes = append(result.Notes, "Minecraft is reachable from tailnet devices only; Funnel does not carry game traffic.")
+	}
+
+	verb := "serve"
+	if opts.funnel {
+		verb = "funnel"
+	} else {
+		result.Notes = append(result.Notes, "Only devices on your tailnet can open the web UI; add --funnel to publish it.")
+	}
+	if opts.dryRun {
+		return result, nil
+	}
+	if _, err := toolOutput(ctx, "tailscale", verb, "--bg", webPort); err != nil {
+		return exposeResult{}, fmt.Errorf("tailscale %s: %w", verb, err)
+	}
+	return result, nil
+}
+
+type cloudflareTunnel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func exposeCloudflare(ctx context.Context, envPath string, opts exposeOptions, webPort string, minecraftPort int) (exposeResult, error) {
+	hostname := strings.TrimSpace(opts.hostname)
+	if hostname == "" {
+		return exposeResult{}, errors.New("--hostname is required for a Cloudflare Tunnel (e.g. mineos.example.com)")
+	}
+	if opts.minecraft && opts.minecraftHost == "" {
+		return exposeResult{}, errors.New("--minecraft-hostname is required to forward Minecraft through Cloudflare")
+	}
+
+	configPath := filepath.Join(filepath.Dir(fallback(envPath, ".env")), cloudflaredConfigFile)
+	if abs, err := filepath.Abs(configPath); err == nil {
+		configPath = abs
+	}
+	result := exposeResult{
+		Provider:     "cloudflare",
+		Origin:       "https://" + hostname,
+		TunnelConfig: configPath,
+		RunCommand:   "cloudflared tunnel --config " + configPath + " run",
+	}
+	if opts.minecraft {
+		result.MinecraftHost = opts.minecraftHost
+		result.Notes = append(result.Notes, fmt.Sprintf("Players connect through 'cloudflared access tcp --hostname %s --url localhost:%d' and join localhost.", opts.minecraftHost, minecraftPort))
+	}
+	if opts.dryRun {
+		return result, nil
+	}
+	if _, err := exec.LookPath("cloudflared"); err != nil {
+		return exposeResult{}, errors.New("cloudflared is not installed; see https://developers.cloudflare.com/cloudflare-one/connections/connect-networks/downloads/")
+	}
+
+	tunnel, err := cloudflareTunnelByName(ctx, opts.tunnelName)
+	if err != nil {
+		return exposeResult{}, err
+	}
+	if tunnel.ID == "" {
+		if _, err := toolOutput(ctx, "cloudflared", "tunnel", "create", opts.tunnelName); err != nil {
+			return exposeResult{}, fmt.Errorf("create tunnel %s (run 'cloudflared tunnel login' first): %w", opts.tunnelName, err)
+		}
+		if tunnel, err = cloudflareTunnelByName(ctx, opts.tunnelName); err != nil {
+			return exposeResult{}, err
+		}
+		if tunnel.ID == "" {
+			return exposeResult{}, fmt.Errorf("tunnel %s was created but is not listed", opts.tunnelName)
+		}
+	}
+
+	routes := []string{hostname}
+	if opts.minecraft {
+		routes = append(routes, opts.minecraftHost)
+	}
+	for _, route := range routes {
+		if _, err := toolOutput(ctx, "cloudflared", "tunnel", "route", "dns", "--overwrite-dns", tunnel.ID, route); err != nil {
+			return exposeResult{}, fmt.Errorf("route %s to tunnel %s: %w", route, opts.tunnelName, err)
+		}
+	}
+
+	home, _ := os.UserHomeDir()
+	var config strings.Builder
+	config.WriteString("# Written by 'mineos network expose'.\n")
+	fmt.Fprintf(&config, "tunnel: %s\n", tunnel.ID)
+	fmt.Fprintf(&config, "credentials-file: %s\n", filepath.Join(home, ".cloudflared", tunnel.ID+".json"))
+	config.WriteString("ingress:\n")
+	fmt.Fprintf(&config, "  - hostname: %s\n    service: http://localhost:%s\n", hostname, webPort)
+	if opts.minecraft {
+		fmt.Fprintf(&config, "  - hostname: %s\n    service: tcp://localhost:%d\n", opts.minecraftHost, minecraftPort)
+	}
+	config.WriteString("  - service: http_status:404\n")
+	if err := os.WriteFile(configPath, []byte(config.String()), 0o644); err != nil {
+		return exposeResult{}, err
+	}
+	return result, nil
+}
+
+func cloudflareTunnelByName(ctx context.Context, name string) (cloudflareTunnel, error) {
+	data, err := toolOutput(ctx, "cloudflared", "tunnel", "list", "--output", "json", "--name", name)
+	if err != nil {
+		return cloudflareTunnel{}, fmt.Errorf("list tunnels (run 'cloudflared tunnel login' first): %w", err)
+	}
+	var tunnels []cloudflareTunnel
+	if err := json.Unmarshal(data, &tunnels); err != nil {
+		return cloudflareTunnel{}, fmt.Errorf("parse tunnel list: %w", err)
+	}
+	for _, tunnel := range tunnels {
+		if tunnel.Name == name {
+			return tunnel, nil
+		}
+	}
+	return cloudflareTunnel{}, nil
+}
+
+// toolOutput runs an external tool and returns its stdout, with stderr
+// folded into the error.
+func toolOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, fmt.Errorf("%w: %s", err, msg)
+		}
+		return out, err
+	}
+	return out, nil
+}
@@ -0,0 +1,532 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/slp"
+)
+
+const relayTokenEnv = "MINEOS_RELAY_TOKEN"
+
+// bedrockMTUSizes are probed smallest first: 576 always fits, 1492 is PPPoE
+// and 1500 plain Ethernet.
+var bedrockMTUSizes = []int{576, 1200, 1400, 1492, 1500}
+
+// Thresholds for the verdict. Pong minus connect is time spent in the
+// server process rather than on the wire.
+const (
+	slowNetworkLatency = 150 * time.Millisecond
+	highJitter         = 30 * time.Millisecond
+	slowServerResponse = 50 * time.Millisecond
+	lowTps             = 18.0
+)
+
+const (
+	verdictHealthy     = "healthy"
+	verdictNetwork     = "network"
+	verdictServer      = "server"
+	verdictUnreachable = "unreachable"
+)
+
+type networkTestOptions struct {
+	count      int
+	interval   time.Duration
+	timeout    time.Duration
+	bedrock    bool
+	relay      string
+	relayToken string
+	jsonOut    bool
+}
+
+type latencyStats struct {
+	MinMs    float64 `json:"min_ms"`
+	AvgMs    float64 `json:"avg_ms"`
+	MaxMs    float64 `json:"max_ms"`
+	JitterMs float64 `json:"jitter_ms"`
+}
+
+type mtuProbe struct {
+	Size     int  `json:"size"`
+	Answered bool `json:"answered"`
+}
+
+type networkTestResult struct {
+	Address     string        `json:"address"`
+	Edition     string        `json:"edition"`
+	Vantage     string        `json:"vantage"`
+	Samples     int           `json:"samples"`
+	Lost        int           `json:"lost"`
+	Connect     *latencyStats `json:"connect,omitempty"`
+	Response    *latencyStats `json:"response,omitempty"`
+	Loopback    *latencyStats `json:"loopback,omitempty"`
+	StatusBytes int           `json:"status_bytes,omitempty"`
+	StatusMs    float64       `json:"status_ms,omitempty"`
+	StatusError string        `json:"status_error,omitempty"`
+	MTU         []mtuProbe    `json:"mtu,omitempty"`
+	Tps         *float64      `json:"tps,omitempty"`
+	Verdict     string        `json:"verdict"`
+	Findings    []string      `json:"findings"`
+}
+
+func newNetworkTestCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	opts := networkTestOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "test <host[:port]|server>",
+		Short: "Measure latency, loss and MTU problems on the path to a Minecraft port",
+		Long: `Sends a series of Server List Pings to the Minecraft port and reports
+latency, jitter and loss, to tell "server lag" from "network lag".
+
+Java probes time the TCP handshake, which the host's kernel answers, and the
+status pong, which the server process answers; a large gap between the two
+points at the server. One full status request checks that large responses
+get through. Bedrock probes time RakNet pings over UDP and send padded
+connection requests of increasing size to find where fragments are dropped.
+
+A MineOS server name is probed at PUBLIC_MINECRAFT_HOST and also over
+loopback, and its TPS is read from the API, so the server's own delay can
+be separated from the network.
+
+Probing your own public address from the same network often goes through
+the router's hairpin NAT and says little about players outside. --relay
+runs the test from another host running 'mineos agent --allow network.test'
+(and network.test-bedrock), authenticated with --relay-token or
+` + relayTokenEnv + `.`,
+		Example: `  mineos network test survival
+  mineos network test play.example.net --count 20
+  mineos network test --bedrock pe.example.net
+  mineos network test play.example.net --relay https://friend.example.org:5079`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runNetworkTest(cmd.Context(), cmd, loadConfig, args[0], opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.count, "count", 10, "Number of probes")
+	cmd.Flags().DurationVar(&opts.interval, "interval", 500*time.Millisecond, "Pause between probes")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 3*time.Second, "Maximum time to wait for each answer")
+	cmd.Flags().BoolVar(&opts.bedrock, "bedrock", false, "Probe a Bedrock server over UDP")
+	cmd.Flags().StringVar(&opts.relay, "relay", "", "Run the test from a mineos agent on another host (its base URL)")
+	cmd.Flags().StringVar(&opts.relayToken, "relay-token", "", "Token of the relay agent (default: "+relayTokenEnv+")")
+	cmd.Flags().BoolVar(&opts.jsonOut, "json", false, "Print the result as JSON")
+
+	return cmd
+}
+
+func runNetworkTest(ctx context.Context, cmd *cobra.Command, loadConfig *usecases.LoadConfigUseCase, target string, opts networkTestOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opts.count < 1 {
+		return errors.New("--count must be at least 1")
+	}
+	out := cmd.OutOrStdout()
+
+	serverName := ""
+	loopback := ""
+	resolveCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if address, isBedrock, ok := resolveServerAddress(resolveCtx, loadConfig, cmd, target); ok {
+		serverName = target
+		target = address
+		opts.bedrock = opts.bedrock || isBedrock
+		if cfg, err := loadConfig.Execute(ctx); err == nil {
+			if _, port, err := net.SplitHostPort(address); err == nil {
+				loopback = net.JoinHostPort(cfg.LoopbackHost(), port)
+			}
+		}
+	}
+	address, _, err := slp.Resolve(resolveCtx, target, opts.bedrock)
+	if err != nil {
+		return err
+	}
+	if loopback == address {
+		loopback = ""
+	}
+
+	var result networkTestResult
+	if opts.relay != "" {
+		if !opts.jsonOut {
+			fmt.Fprintf(out, "Testing %s from %s (%d probes)...\n", address, opts.relay, opts.count)
+		}
+		result, err = relayNetworkTest(ctx, opts, address)
+		if err != nil {
+			return err
+		}
+	} else {
+		if !opts.jsonOut {
+			fmt.Fprintf(out, "Testing %s from this host (%d probes)...\n", address, opts.count)
+		}
+		result = probeNetwork(ctx, address, opts)
+		if loopback != "" {
+			baseline := opts
+			baseline.count = max(3, opts.count/2)
+			local := probeNetwork(ctx, loopback, baseline)
+			if opts.bedrock {
+				result.Loopback = local.Response
+			} else {
+				result.Loopback = local.Connect
+				if local.Response != nil {
+					result.Loopback = local.Response
+				}
+			}
+		}
+	}
+	if serverName != "" {
+		result.Tps = serverTps(ctx, loadConfig, out, serverName)
+	}
+	judgeNetworkTest(&result)
+
+	if opts.jsonOut {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+	} else {
+		printNetworkTest(out, result)
+	}
+	if result.Verdict == verdictUnreachable && opts.relay == "" {
+		return fmt.Errorf("no answer from %s", address)
+	}
+	return nil
+}
+
+// probeNetwork runs the probes from this host.
+func probeNetwork(ctx context.Context, address string, opts networkTestOptions) networkTestResult {
+	result := networkTestResult{Address: address, Edition: "java", Vantage: "local", Samples: opts.count}
+	if opts.bedrock {
+		result.Edition = "bedrock"
+	}
+
+	var connects, responses []time.Duration
+	for i := 0; i < opts.count; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return result
+			case <-time.After(opts.interval):
+			}
+		}
+		probeCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+		if opts.bedrock {
+			status, err := slp.PingBedrock(probeCtx, address)
+			if err == nil {
+				responses = append(responses, status.Latency)
+			} else {
+				result.Lost++
+			}
+		} else {
+			probe, err := slp.ProbeJava(probeCtx, address)
+			if probe.Connect > 0 {
+				connects = append(connects, probe.Connect)
+			}
+			if err == nil {
+				responses = append(responses, probe.Pong)
+			} else {
+				result.Lost++
+			}
+		}
+		cancel()
+	}
+	result.Connect = summarizeLatency(connects)
+	result.Response = summarizeLatency(responses)
+	if len(responses) == 0 {
+		return result
+	}
+
+	if opts.bedrock {
+		for _, size := range bedrockMTUSizes {
+			probeCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+			answered, err := slp.ProbeBedrockMTU(probeCtx, address, size)
+			cancel()
+			if err != nil {
+				break
+			}
+			result.MTU = append(result.MTU, mtuProbe{Size: size, Answered: answered})
+		}
+		return result
+	}
+
+	statusCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+	started := time.Now()
+	status, err := slp.PingJava(statusCtx, address)
+	if err != nil {
+		result.StatusError = err.Error()
+		return result
+	}
+	result.StatusBytes = status.ResponseBytes
+	result.StatusMs = milliseconds(time.Since(started))
+	return result
+}
+
+// judgeNetworkTest sets the verdict and the findings behind it. Server
+// findings win over network ones only when the network itself looks fine.
+func judgeNetworkTest(result *networkTestResult) {
+	result.Findings = []string{}
+	if result.Response == nil {
+		result.Verdict = verdictUnreachable
+		if result.Connect != nil {
+			result.Findings = append(result.Findings, "the port accepts connections but nothing answers the status ping; the server may be starting or frozen")
+		} else {
+			result.Findings = append(result.Findings, "no probe got an answer; check the server is running and the port is forwarded")
+		}
+		return
+	}
+
+	network := false
+	if result.Lost > 0 {
+		network = true
+		result.Findings = append(result.Findings, fmt.Sprintf("%d of %d probes were lost", result.Lost, result.Samples))
+	}
+	wire := result.Response
+	if result.Connect != nil {
+		wire = result.Connect
+	}
+	if wire.AvgMs > milliseconds(slowNetworkLatency) {
+		network = true
+		result.Findings = append(result.Findings, fmt.Sprintf("round trips average %.0f ms", wire.AvgMs))
+	}
+	if wire.JitterMs > milliseconds(highJitter) {
+		network = true
+		result.Findings = append(result.Findings, fmt.Sprintf("latency varies by %.0f ms between probes", wire.JitterMs))
+	}
+	if result.StatusError != "" {
+		network = true
+		result.Findings = append(result.Findings, "pings get through but the full status response does not; large packets may be dropped (MTU)")
+	}
+	answered := 0
+	for _, probe := range result.MTU {
+		if probe.Answered {
+			answered = probe.Size
+		}
+	}
+	if len(result.MTU) > 0 && answered < result.MTU[len(result.MTU)-1].Size {
+		network = true
+		if answered == 0 {
+			result.Findings = append(result.Findings, "no padded connection request was answered; fragmented UDP is likely dropped")
+		} else {
+			result.Findings = append(result.Findings, fmt.Sprintf("connection requests above %d bytes go unanswered; lower the MTU on the path or the router", answered))
+		}
+	}
+
+	server := false
+	if result.Connect != nil && result.Response.AvgMs-result.Connect.AvgMs > milliseconds(slowServerResponse) {
+		server = true
+		result.Findings = append(result.Findings, fmt.Sprintf("the server takes %.0f ms longer to answer than the host", result.Response.AvgMs-result.Connect.AvgMs))
+	}
+	if result.Loopback != nil && result.Loopback.AvgMs > milliseconds(slowServerResponse) {
+		server = true
+		result.Findings = append(result.Findings, fmt.Sprintf("the server answers slowly even over loopback (%.0f ms)", result.Loopback.AvgMs))
+	}
+	if result.Tps != nil && *result.Tps < lowTps {
+		server = true
+		result.Findings = append(result.Findings, fmt.Sprintf("the server runs at %.1f TPS", *result.Tps))
+	}
+
+	switch {
+	case network:
+		result.Verdict = verdictNetwork
+	case server:
+		result.Verdict = verdictServer
+	default:
+		result.Verdict = verdictHealthy
+	}
+}
+
+func printNetworkTest(out io.Writer, result networkTestResult) {
+	fmt.Fprintln(out)
+	if result.Vantage != "local" {
+		printStat(out, "Vantage", result.Vantage)
+	}
+	if result.Connect != nil {
+		printStat(out, "Connect", formatLatencyStats(*result.Connect))
+	}
+	if result.Response != nil {
+		label := "Pong"
+		if result.Edition == "bedrock" {
+			label = "Ping"
+		}
+		printStat(out, label, formatLatencyStats(*result.Response))
+	}
+	if result.Loopback != nil {
+		printStat(out, "Loopback", formatLatencyStats(*result.Loopback))
+	}
+	loss := fmt.Sprintf("%d/%d", result.Lost, result.Samples)
+	if result.Lost > 0 {
+		loss = styleWarning.Render(loss)
+	}
+	printStat(out, "Lost", loss)
+	if result.StatusBytes > 0 {
+		printStat(out, "Status", fmt.Sprintf("%s in %.0f ms", formatBytes(int64(result.StatusBytes)), result.StatusMs))
+	} else if result.StatusError != "" {
+		printStat(out, "Status", styleWarning.Render(result.StatusError))
+	}
+	if len(result.MTU) > 0 {
+		parts := make([]string, 0, len(result.MTU))
+		for _, probe := range result.MTU {
+			if probe.Answered {
+				parts = append(parts, fmt.Sprintf("%d ok", probe.Size))
+			} else {
+				parts = append(parts, styleWarning.Render(fmt.Sprintf("%d dropped", probe.Size)))
+			}
+		}
+		printStat(out, "MTU", strings.Join(parts, ", "))
+	}
+	if result.Tps != nil {
+		printStat(out, "TPS", fmt.Sprintf("%.1f", *result.Tps))
+	}
+
+	verdict := styleSuccess.Render("connection looks healthy")
+	switch result.Verdict {
+	case verdictNetwork:
+		verdict = styleWarning.Render("network lag")
+	case verdictServer:
+		verdict = styleWarning.Render("server lag")
+	case verdictUnreachable:
+		verdict = styleError.Render("unreachable")
+	}
+	fmt.Fprintln(out)
+	printStat(out, "Verdict", verdict)
+	for _, finding := range result.Findings {
+		fmt.Fprintf(out, "  %s\n", styleDim.Render("- "+finding))
+	}
+}
+
+// relayNetworkTest asks a mineos agent on another host to run the test and
+// returns the result it printed.
+func relayNetworkTest(ctx context.Context, opts networkTestOptions, address string) (networkTestResult, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return networkTestResult{}, err
+	}
+	if ip := net.ParseIP(host); strings.EqualFold(host, "localhost") || (ip != nil && (ip.IsLoopback() || ip.IsPrivate())) {
+		return networkTestResult{}, fmt.Errorf("%s is not reachable from another network; set PUBLIC_MINECRAFT_HOST or pass the public address", address)
+	}
+	token := strings.TrimSpace(opts.relayToken)
+	if token == "" {
+		token = strings.TrimSpace(os.Getenv(relayTokenEnv))
+	}
+	if token == "" {
+		return networkTestResult{}, fmt.Errorf("no relay token; pass --relay-token or set %s", relayTokenEnv)
+	}
+
+	operation := "network.test"
+	if opts.bedrock {
+		operation = "network.test-bedrock"
+	}
+	body, _ := json.Marshal(map[string]string{"host": host, "port": port})
+	url := strings.TrimSuffix(opts.relay, "/") + "/v1/operations/" + operation + "?wait=true"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return networkTestResult{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	// The agent answers once the remote test has finished.
+	budget := time.Duration(opts.count)*(opts.interval+opts.timeout) + time.Duration(len(bedrockMTUSizes)+1)*opts.timeout + 30*time.Second
+	client := &http.Client{Timeout: budget}
+	resp, err := client.Do(req)
+	if err != nil {
+		return networkTestResult{}, fmt.Errorf("relay %s: %w", opts.relay, err)
+	}
+	defer resp.Body.Close()
+
+	var run struct {
+		Status string `json:"status"`
+		Output string `json:"output"`
+		Error  string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&run); err != nil {
+		return networkTestResult{}, fmt.Errorf("relay %s: unexpected response (%s)", opts.relay, resp.Status)
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		return networkTestResult{}, fmt.Errorf("relay %s does not allow %s; start it with --allow %s", opts.relay, operation, operation)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return networkTestResult{}, fmt.Errorf("relay %s: %s", opts.relay, fallback(run.Error, resp.Status))
+	}
+
+	// The output is the JSON result, possibly after notes on stderr.
+	var result networkTestResult
+	start := strings.Index(run.Output, "{")
+	if start < 0 || json.Unmarshal([]byte(run.Output[start:]), &result) != nil {
+		return networkTestResult{}, fmt.Errorf("relay %s: test %s: %s", opts.relay, run.Status, fallback(strings.TrimSpace(run.Output), run.Error))
+	}
+	result.Vantage = opts.relay
+	result.Loopback = nil
+	return result, nil
+}
+
+func serverTps(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, out io.Writer, name string) *float64 {
+	var tps *float64
+	_, _ = withApiKeyRetry(ctx, loadConfig, out, func(_ config.Config, client *api.Client) error {
+		stats, err := usecases.NewServerStatsUseCase(client).Execute(ctx, name, 0)
+		if err != nil {
+			return err
+		}
+		tps = stats.Performance.Tps
+		return nil
+	})
+	return tps
+}
+
+func summarizeLatency(samples []time.Duration) *latencyStats {
+	if len(samples) == 0 {
+		return nil
+	}
+	stats := &latencyStats{MinMs: milliseconds(samples[0]), MaxMs: milliseconds(samples[0])}
+	var sum, jitter float64
+	for i, sample := range samples {
+		value := milliseconds(sample)
+		stats.MinMs = min(stats.MinMs, value)
+		stats.MaxMs = max(stats.MaxMs, value)
+		sum += value
+		if i > 0 {
+			diff := value - milliseconds(samples[i-1])
+			if diff < 0 {
+				diff = -diff
+			}
+			jitter += diff
+		}
+	}
+	stats.AvgMs = sum / float64(len(samples))
+	if len(samples) > 1 {
+		stats.JitterMs = jitter / float64(len(samples)-1)
+	}
+	return stats
+}
+
+func formatLatencyStats(stats latencyStats) string {
+	return fmt.Sprintf("min %s  avg %s  max %s ms  %s", formatMs(stats.MinMs), formatMs(stats.AvgMs), formatMs(stats.MaxMs),
+		styleDim.Render("jitter "+formatMs(stats.JitterMs)+" ms"))
+}
+
+// formatMs keeps a decimal for the sub-10 ms times of LAN and loopback.
+func formatMs(value float64) string {
+	if value < 10 {
+		return fmt.Sprintf("%.1f", value)
+	}
+	return fmt.Sprintf("%.0f", value)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/motd"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/slp"
+)
+
+func NewPingCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var bedrock bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "ping <host[:port]|server>",
+		Short: "Query a Minecraft server the way the multiplayer screen does",
+		Long: `Send a Server List Ping and report the MOTD, version, protocol, players
+and latency. The probe talks to the Minecraft port directly rather than the
+MineOS API, so it shows whether players can actually reach the server, e.g.
+after setting up port forwarding.
+
+A MineOS server name is resolved to MINECRAFT_HOST (or localhost) and the
+server's configured port. Hostnames without a port honour the
+_minecraft._tcp SRV record. Use --bedrock for Bedrock servers; it is implied
+when pinging a Bedrock server by name.`,
+		Example: `  mineos ping survival
+  mineos ping play.example.net
+  mineos ping 203.0.113.10:25570
+  mineos ping --bedrock pe.example.net`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			target := args[0]
+			if address, isBedrock, ok := resolveServerAddress(ctx, loadConfig, cmd, target); ok {
+				target = address
+				bedrock = bedrock || isBedrock
+			}
+			address, srv, err := slp.Resolve(ctx, target, bedrock)
+			if err != nil {
+				return err
+			}
+
+			via := ""
+			if srv {
+				via = " (via SRV record)"
+			}
+			cmd.Printf("Pinging %s%s...\n", address, via)
+
+			var status slp.Status
+			if bedrock {
+				status, err = slp.PingBedrock(ctx, address)
+			} else {
+				status, err = slp.PingJava(ctx, address)
+			}
+			if err != nil {
+				return pingError(address, bedrock, err)
+			}
+			printPingStatus(cmd, status)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&bedrock, "bedrock", false, "Use the Bedrock (RakNet) ping on UDP")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Second, "Maximum time to wait for an answer")
+
+	return cmd
+}
+
+// resolveServerAddress maps a MineOS server name to the address players use.
+// Anything that looks like a host, or that the API does not know, is pinged
+// as given.
+func resolveServerAddress(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, cmd *cobra.Command, target string) (string, bool, bool) {
+	if strings.ContainsAny(target, ".:[") || strings.EqualFold(target, "localhost") {
+		return "", false, false
+	}
+	cfg, err := loadConfig.Execute(ctx)
+	if err != nil || cfg.EffectiveApiKey() == "" {
+		return "", false, false
+	}
+
+	var address string
+	var isBedrock bool
+	_, err = withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(cfg config.Config, client *api.Client) error {
+		detail, err := client.GetServer(ctx, target)
+		if err != nil {
+			return err
+		}
+		props, err := client.GetServerProperties(ctx, target)
+		if err != nil {
+			return err
+		}
+		isBedrock = detail.IsBedrock()
+		port := props["server-port"]
+		if port == "" {
+			port = strconv.Itoa(slp.DefaultJavaPort)
+			if isBedrock {
+				port = strconv.Itoa(slp.DefaultBedrockPort)
+			}
+		}
+		address = cfg.MinecraftAddress(port)
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ports.ErrNotFound) {
+			cmd.Printf("%s could not look up %s through the API (%v); pinging it as a hostname\n", styleWarning.Render("Note:"), target, err)
+		}
+		return "", false, false
+	}
+	return address, isBedrock, true
+}
+
+func printPingStatus(cmd *cobra.Command, status slp.Status) {
+	out := cmd.OutOrStdout()
+	edition := "Java"
+	if status.Bedrock {
+		edition = "Bedrock"
+	}
+	printStat(out, "Edition", edition)
+	printStat(out, "Version", fmt.Sprintf("%s (protocol %d)", fallback(motd.PlainText(motd.ParseLegacy(status.Version)), "unknown"), status.Protocol))
+	players := fmt.Sprintf("%d/%d", status.PlayersOnline, status.PlayersMax)
+	if len(status.Sample) > 0 {
+		players += " " + styleDim.Render("("+strings.Join(status.Sample, ", ")+")")
+	}
+	printStat(out, "Players", players)
+	if status.Latency > 0 {
+		printStat(out, "Latency", fmt.Sprintf("%d ms", status.Latency.Milliseconds()))
+	}
+	if !status.Bedrock {
+		icon := "none"
+		if status.HasFavicon {
+			icon = "yes"
+		}
+		printStat(out, "Icon", icon)
+	}
+
+	segments := motd.ParseLegacy(status.Legacy)
+	if len(status.Description) > 0 {
+		if parsed, err := motd.ParseComponent(status.Description); err == nil {
+			segments = parsed
+		}
+	}
+	cmd.Println(renderMotdPreview(segments))
+}
+
+func pingError(address string, bedrock bool, err error) error {
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		if bedrock {
+			return fmt.Errorf("no answer from %s over UDP; check the server is running and the port is forwarded", address)
+		}
+		return fmt.Errorf("timed out waiting for %s", address)
+	case strings.Contains(err.Error(), "connection refused"):
+		return fmt.Errorf("connection to %s refused; nothing is listening on that port or it is not forwarded", address)
+	}
+	return fmt.Errorf("ping %s failed: %w", address, err)
+}
@@ -0,0 +1,295 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// platformEnvKey is the variable Compose and the docker CLI read for the
+// platform to pull, build and run images for.
+const platformEnvKey = "DOCKER_DEFAULT_PLATFORM"
+
+var platformPattern = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
+
+func validatePlatform(platform string) error {
+	if platform != "" && !platformPattern.MatchString(platform) {
+		return fmt.Errorf("invalid --platform %q (use e.g. linux/amd64, linux/arm64 or linux/arm/v7)", platform)
+	}
+	return nil
+}
+
+// dockerHost describes the machine the Docker daemon runs on.
+type dockerHost struct {
+	OS       string
+	Arch     string
+	Variant  string
+	MemTotal int64
+	// Model is the board name from the device tree, such as "Raspberry Pi 4
+	// Model B Rev 1.4"; empty on PCs and when it cannot be read.
+	Model string
+	// OperatingSystem is the daemon's OS name, "Docker Desktop" when it runs
+	// in Docker Desktop's VM.
+	OperatingSystem string
+}
+
+// Platform is the host's platform in image manifest form, e.g. linux/arm64.
+func (h dockerHost) Platform() string {
+	if h.Variant != "" {
+		return h.OS + "/" + h.Arch + "/" + h.Variant
+	}
+	return h.OS + "/" + h.Arch
+}
+
+func (h dockerHost) IsArm() bool {
+	return h.Arch == "arm" || h.Arch == "arm64"
+}
+
+func (h dockerHost) IsRaspberryPi() bool {
+	return strings.Contains(h.Model, "Raspberry Pi")
+}
+
+// IsDockerDesktop reports a daemon in Docker Desktop's VM, whose memory is
+// the VM's allowance rather than the machine's.
+func (h dockerHost) IsDockerDesktop() bool {
+	return strings.Contains(h.OperatingSystem, "Docker Desktop")
+}
+
+// dockerHost asks the daemon, not the CLI's own binary, so it is right for
+// --ssh hosts and for an amd64 CLI under emulation on Apple silicon.
+func (c composeRunner) dockerHost(ctx context.Context) (dockerHost, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	cmd := c.docker(ctx, "info", "--format", "{{.OSType}}|{{.Architecture}}|{{.MemTotal}}|{{.OperatingSystem}}")
+	out, err := cmd.Output()
+	if err != nil {
+		return dockerHost{}, err
+	}
+	fields := strings.SplitN(strings.TrimSpace(string(out)), "|", 4)
+	if len(fields) != 4 {
+		return dockerHost{}, fmt.Errorf("unexpected docker info output %q", strings.TrimSpace(string(out)))
+	}
+	host := dockerHost{OS: fields[0], OperatingSystem: fields[3]}
+	host.Arch, host.Variant = normalizeArch(fields[1])
+	host.MemTotal, _ = strconv.ParseInt(fields[2], 10, 64)
+	host.Model = c.boardModel(ctx)
+	return host, nil
+}
+
+// normalizeArch maps uname machine names, which docker info reports, to
+// the architecture and variant used in image manifests.
+func normalizeArch(machine string) (string, string) {
+	switch strings.ToLower(strings.TrimSpace(machine)) {
+	case "x86_64", "amd64":
+		return "amd64", ""
+	case "aarch64", "arm64", "armv8l":
+		return "arm64", ""
+	case "armv7l", "armv7", "armhf":
+		return "arm", "v7"
+	case "armv6l", "armv6", "armel":
+		return "arm", "v6"
+	case "i386", "i686", "x86":
+		return "386", ""
+	}
+	return strings.ToLower(strings.TrimSpace(machine)), ""
+}
+
+func (c composeRunner) boardModel(ctx context.Context) string {
+	const path = "/proc/device-tree/model"
+	var data []byte
+	if c.remote != nil {
+		data, _ = c.remote.Command(ctx, false, nil, "cat", path).Output()
+	} else {
+		data, _ = os.ReadFile(path)
+	}
+	return strings.TrimSpace(strings.TrimRight(string(data), "\x00"))
+}
+
+// imagePlatforms lists the platforms an image is published for. It returns
+// nothing when the manifest does not say, e.g. for single-platform images
+// from older registries.
+func (c composeRunner) imagePlatforms(ctx context.Context, image string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	cmd := c.docker(ctx, "manifest", "inspect", "-v", image)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	type entry struct {
+		Descriptor struct {
+			Platform *struct {
+				OS           string `json:"os"`
+				Architecture string `json:"architecture"`
+				Variant      string `json:"variant"`
+			} `json:"platform"`
+		} `json:"Descriptor"`
+	}
+	// A multi-platform image is an array of entries, a single one an object.
+	var entries []entry
+	if err := json.Unmarshal(out, &entries); err != nil {
+		var single entry
+		if err := json.Unmarshal(out, &single); err != nil {
+			return nil, fmt.Errorf("parse manifest of %s: %w", image, err)
+		}
+		entries = []entry{single}
+	}
+	seen := map[string]bool{}
+	var platforms []string
+	for _, e := range entries {
+		p := e.Descriptor.Platform
+		// Build attestations are listed as unknown/unknown.
+		if p == nil || p.OS == "" || p.OS == "unknown" {
+			continue
+		}
+		platform := p.OS + "/" + p.Architecture
+		if p.Variant != "" {
+			platform += "/" + p.Variant
+		}
+		if !seen[platform] {
+			seen[platform] = true
+			platforms = append(platforms, platform)
+		}
+	}
+	sort.Strings(platforms)
+	return platforms, nil
+}
+
+// platformOffered reports whether an image built for one of offered runs
+// as want. arm64 images may carry the redundant v8 variant, and an image
+// without a variant is taken to run on every variant of its architecture.
+func platformOffered(want string, offered []string) bool {
+	wantOS, wantArch, wantVariant := splitPlatform(want)
+	for _, platform := range offered {
+		goos, arch, variant := splitPlatform(platform)
+		if goos != wantOS || arch != wantArch {
+			continue
+		}
+		if variant == "" || wantVariant == "" || variant == wantVariant {
+			return true
+		}
+	}
+	return false
+}
+
+func splitPlatform(platform string) (string, string, string) {
+	parts := strings.SplitN(platform, "/", 3)
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	if parts[1] == "arm64" && parts[2] == "v8" {
+		parts[2] = ""
+	}
+	return parts[0], parts[1], parts[2]
+}
+
+// checkImagePlatforms makes sure every image exists for the platform the
+// stack will run on, which is --platform or the Docker host's own. Without
+// it a missing ARM build pulls fine and then fails with "exec format error"
+// when the container starts. Images whose manifest cannot be read are not
+// held up; the pull reports real registry problems.
+func (c composeRunner) checkImagePlatforms(ctx context.Context, out io.Writer, images map[string]string) error {
+	want := c.platform
+	var host dockerHost
+	if want == "" {
+		var err error
+		if host, err = c.dockerHost(ctx); err != nil || host.OS == "" {
+			return nil
+		}
+		want = host.Platform()
+	}
+
+	names := make([]string, 0, len(images))
+	for name := range images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checked := map[string]bool{}
+	for _, name := range names {
+		image := images[name]
+		if checked[image] {
+			continue
+		}
+		checked[image] = true
+		offered, err := c.imagePlatforms(ctx, image)
+		if err != nil {
+			fmt.Fprintf(out, "%s could not read the manifest of %s; skipping the platform check (%v)\n", styleDim.Render("Note:"), image, firstLine(err.Error()))
+			continue
+		}
+		if len(offered) == 0 || platformOffered(want, offered) {
+			continue
+		}
+		return platformMismatchError(name, image, want, offered, host)
+	}
+	return nil
+}
+
+const buildFromSourceHint = "'mineos install --build', or MINEOS_BUILD_FROM_SOURCE=true in .env"
+
+func platformMismatchError(service, image, want string, offered []string, host dockerHost) error {
+	var hint string
+	switch {
+	case want == "linux/arm/v7" || want == "linux/arm/v6":
+		if platformOffered("linux/arm64", offered) {
+			hint = "This is a 32-bit ARM system; install a 64-bit OS (e.g. Raspberry Pi OS 64-bit) to use the arm64 images."
+		} else {
+			hint = "Build the images on this machine instead: " + buildFromSourceHint + "."
+		}
+	case host.OS != "" && platformOffered("linux/amd64", offered):
+		hint = "Build the images on this machine (" + buildFromSourceHint + "), or run the amd64 images under emulation with --platform linux/amd64 (needs QEMU/binfmt, and is slow)."
+	default:
+		hint = "Build the images on this machine (" + buildFromSourceHint + "), or pick a --platform the image offers."
+	}
+	return fmt.Errorf("%s image %s has no %s build (available: %s).\n%s", service, image, want, strings.Join(offered, ", "), hint)
+}
+
+// dockerDesktopMinMemory is what Docker Desktop's VM needs for MineOS and
+// one small server; its default allowance on 8 GB Macs is 2 GB.
+const dockerDesktopMinMemory = 4 << 30
+
+// warnHostResources points out hosts that will struggle: a Docker Desktop VM
+// with too little memory, Raspberry Pi and other ARM boards with little
+// memory, and 32-bit ARM.
+func warnHostResources(out io.Writer, host dockerHost) {
+	const gib = 1 << 30
+	if host.IsDockerDesktop() {
+		if host.MemTotal > 0 && host.MemTotal < dockerDesktopMinMemory {
+			fmt.Fprintf(out, "%s Docker Desktop's VM has %.1f GB of memory; MineOS and one Minecraft server need 4 GB.\n", styleWarning.Render("Warning:"), float64(host.MemTotal)/gib)
+			fmt.Fprintln(out, "  "+dockerDesktopMemoryHint())
+		}
+		return
+	}
+	if !host.IsArm() {
+		return
+	}
+	name := "This ARM host"
+	if host.IsRaspberryPi() {
+		name = "This " + strings.TrimSpace(strings.Split(host.Model, " Rev ")[0])
+	}
+	if host.Arch == "arm" {
+		fmt.Fprintf(out, "%s %s runs a 32-bit OS; MineOS and modern Minecraft versions need a 64-bit OS (arm64).\n", styleWarning.Render("Warning:"), name)
+	}
+	if host.MemTotal > 0 && host.MemTotal < 4*gib {
+		fmt.Fprintf(out, "%s %s has %.1f GB of memory. MineOS itself uses about 0.5 GB and each Minecraft\n", styleWarning.Render("Warning:"), name, float64(host.MemTotal)/gib)
+		fmt.Fprintln(out, "  server needs 1-2 GB more; plan on one small server, or use a board with 4 GB or more.")
+	}
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
@@ -0,0 +1,241 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+func NewPlayersCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "players",
+		Short: "Player activity commands",
+	}
+
+	cmd.AddCommand(newPlayersHistoryCommand(loadConfig))
+
+	return cmd
+}
+
+func newPlayersHistoryCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var query usecases.PlayerHistoryQuery
+	var sessions bool
+	var format string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "history <server>",
+		Short: "Show player sessions, playtime and last seen",
+		Long: `Report join/leave sessions recorded from a server's log.
+
+MineOS records sessions as it reads latest.log; --refresh scans the log
+for anything not yet recorded. Totals cover the sessions fetched, so raise
+--limit for all-time leaderboards.`,
+		Example: `  mineos players history survival
+  mineos players history survival --player Steve
+  mineos players history survival --format csv --output playtime.csv
+  mineos players history survival --sessions --format json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			if format != "table" && format != "csv" && format != "json" {
+				return fmt.Errorf("--format must be table, csv or json")
+			}
+
+			var history usecases.PlayerHistory
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+				var err error
+				history, err = usecases.NewPlayerHistoryUseCase(client).Execute(ctx, args[0], query)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if output != "" {
+				file, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				out = file
+			}
+
+			now := time.Now().Truncate(time.Second)
+			showSessions := sessions || query.Player != ""
+			switch format {
+			case "csv":
+				err = writePlayerHistoryCSV(out, history, showSessions, now)
+			case "json":
+				err = writePlayerHistoryJSON(out, args[0], history, now)
+			default:
+				err = printPlayerHistory(out, args[0], history, showSessions, now)
+			}
+			if err != nil {
+				return err
+			}
+			if output != "" {
+				cmd.Printf("Wrote %s\n", output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&query.Player, "player", "", "Only show this player")
+	cmd.Flags().IntVar(&query.Limit, "limit", 1000, "Maximum number of sessions to fetch")
+	cmd.Flags().BoolVar(&query.Refresh, "refresh", false, "Scan latest.log for new sessions first")
+	cmd.Flags().BoolVar(&sessions, "sessions", false, "List individual sessions (CSV exports sessions instead of totals)")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format: table, csv or json")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the report to a file instead of stdout")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"table", "csv", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+func printPlayerHistory(out io.Writer, server string, history usecases.PlayerHistory, showSessions bool, now time.Time) error {
+	if len(history.Sessions) == 0 {
+		fmt.Fprintf(out, "No sessions recorded for %s yet. Try --refresh to scan latest.log.\n", server)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PLAYER\tSESSIONS\tPLAYTIME\tLAST SEEN")
+	for _, player := range history.Players {
+		lastSeen := player.LastSeen.Local().Format("2006-01-02 15:04")
+		if player.Online {
+			lastSeen = styleSuccess.Render("online now")
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", player.Name, player.Sessions, formatPlaytime(player.Playtime), lastSeen)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !showSessions {
+		return nil
+	}
+
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PLAYER\tJOINED\tLEFT\tDURATION\tREASON")
+	for _, session := range history.Sessions {
+		left := "-"
+		if session.LeftAt != nil {
+			left = session.LeftAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", session.PlayerName, session.JoinedAt.Local().Format("2006-01-02 15:04"), left,
+			formatPlaytime(usecases.SessionDuration(session, now)), fallback(session.LeaveReason, "-"))
+	}
+	return w.Flush()
+}
+
+func writePlayerHistoryCSV(out io.Writer, history usecases.PlayerHistory, showSessions bool, now time.Time) error {
+	w := csv.NewWriter(out)
+	if showSessions {
+		_ = w.Write([]string{"player", "uuid", "joined_at", "left_at", "duration_seconds", "leave_reason"})
+		for _, session := range history.Sessions {
+			left := ""
+			if session.LeftAt != nil {
+				left = session.LeftAt.UTC().Format(time.RFC3339)
+			}
+			_ = w.Write([]string{
+				session.PlayerName,
+				session.PlayerUUID,
+				session.JoinedAt.UTC().Format(time.RFC3339),
+				left,
+				strconv.FormatInt(int64(usecases.SessionDuration(session, now).Seconds()), 10),
+				session.LeaveReason,
+			})
+		}
+	} else {
+		_ = w.Write([]string{"player", "uuid", "sessions", "playtime_seconds", "first_seen", "last_seen", "online"})
+		for _, player := range history.Players {
+			_ = w.Write([]string{
+				player.Name,
+				player.UUID,
+				strconv.Itoa(player.Sessions),
+				strconv.FormatInt(int64(player.Playtime.Seconds()), 10),
+				player.FirstSeen.UTC().Format(time.RFC3339),
+				player.LastSeen.UTC().Format(time.RFC3339),
+				strconv.FormatBool(player.Online),
+			})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writePlayerHistoryJSON(out io.Writer, server string, history usecases.PlayerHistory, now time.Time) error {
+	type playerRecord struct {
+		Name            string    `json:"name"`
+		UUID            string    `json:"uuid"`
+		Sessions        int       `json:"sessions"`
+		PlaytimeSeconds int64     `json:"playtimeSeconds"`
+		FirstSeen       time.Time `json:"firstSeen"`
+		LastSeen        time.Time `json:"lastSeen"`
+		Online          bool      `json:"online"`
+	}
+	type sessionRecord struct {
+		Name            string     `json:"name"`
+		UUID            string     `json:"uuid"`
+		JoinedAt        time.Time  `json:"joinedAt"`
+		LeftAt          *time.Time `json:"leftAt"`
+		DurationSeconds int64      `json:"durationSeconds"`
+		LeaveReason     string     `json:"leaveReason,omitempty"`
+	}
+	report := struct {
+		Server      string          `json:"server"`
+		GeneratedAt time.Time       `json:"generatedAt"`
+		Players     []playerRecord  `json:"players"`
+		Sessions    []sessionRecord `json:"sessions"`
+	}{Server: server, GeneratedAt: now.UTC(), Players: []playerRecord{}, Sessions: []sessionRecord{}}
+	for _, player := range history.Players {
+		report.Players = append(report.Players, playerRecord{
+			Name:            player.Name,
+			UUID:            player.UUID,
+			Sessions:        player.Sessions,
+			PlaytimeSeconds: int64(player.Playtime.Seconds()),
+			FirstSeen:       player.FirstSeen.UTC(),
+			LastSeen:        player.LastSeen.UTC(),
+			Online:          player.Online,
+		})
+	}
+	for _, session := range history.Sessions {
+		report.Sessions = append(report.Sessions, sessionRecord{
+			Name:            session.PlayerName,
+			UUID:            session.PlayerUUID,
+			JoinedAt:        session.JoinedAt.UTC(),
+			LeftAt:          session.LeftAt,
+			DurationSeconds: int64(usecases.SessionDuration(session, now).Seconds()),
+			LeaveReason:     session.LeaveReason,
+		})
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// formatPlaytime renders a duration as hours and minutes, e.g. "12h 05m".
+func formatPlaytime(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+	hours := int(d / time.Hour)
+	minutes := int(d%time.Hour) / int(time.Minute)
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", hours, minutes)
+}
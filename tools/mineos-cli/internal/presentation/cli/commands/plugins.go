@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/plugins"
+)
+
+// pluginAnnotation marks cobra commands that dispatch to a plugin executable.
+const pluginAnnotation = "mineos-plugin"
+
+// addPluginCommands registers every discovered plugin whose name does not
+// collide with a built-in command.
+func addPluginCommands(root *cobra.Command, deps RootDeps) {
+	for _, plugin := range plugins.Discover(plugins.SearchDirs()) {
+		if isBuiltinCommand(root, plugin.Name) {
+			continue
+		}
+		root.AddCommand(newPluginCommand(plugin, deps))
+	}
+}
+
+func isBuiltinCommand(root *cobra.Command, name string) bool {
+	for _, cmd := range root.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return name == "help" || name == "completion"
+}
+
+func newPluginCommand(plugin plugins.Plugin, deps RootDeps) *cobra.Command {
+	short := plugin.Manifest.Short
+	if short == "" {
+		short = "Plugin provided by " + plugin.Path
+	}
+	return &cobra.Command{
+		Use:                plugin.Name,
+		Short:              short,
+		Long:               plugin.Manifest.Long,
+		DisableFlagParsing: true,
+		Annotations:        map[string]string{pluginAnnotation: plugin.Path},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			// Flag parsing is disabled so the plugin sees its own flags; the
+			// global --env flag still belongs to mineos.
+			args, envPath := stripEnvFlag(args)
+			if envPath != "" {
+				deps.ConfigRepo.SetPath(envPath)
+			}
+			cfg, err := deps.LoadConfig.Execute(context.Background())
+			if err != nil {
+				// Plugins may run before MineOS is installed; pass what we know.
+				cfg = config.Config{EnvPath: deps.ConfigRepo.Path()}
+			}
+
+			proc := exec.Command(plugin.Path, args...)
+			proc.Stdin = os.Stdin
+			proc.Stdout = cmd.OutOrStdout()
+			proc.Stderr = cmd.ErrOrStderr()
+			proc.Env = append(os.Environ(), plugins.Environ(cfg, deps.Version)...)
+			if err := proc.Run(); err != nil {
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) {
+					return fmt.Errorf("plugin %s exited with status %d", plugin.Name, exitErr.ExitCode())
+				}
+				return fmt.Errorf("failed to run plugin %s: %w", plugin.Name, err)
+			}
+			return nil
+		},
+	}
+}
+
+// stripEnvFlag removes a leading --env flag from args.
+func stripEnvFlag(args []string) ([]string, string) {
+	if len(args) == 0 {
+		return args, ""
+	}
+	if value, ok := strings.CutPrefix(args[0], "--env="); ok {
+		return args[1:], value
+	}
+	if args[0] == "--env" && len(args) > 1 {
+		return args[2:], args[1]
+	}
+	return args, ""
+}
+
+func NewPluginsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugins",
+		Short: "Manage CLI plugins (mineos-<name> executables)",
+		Long: `Plugins are executables named mineos-<name>. They are looked up in
+` + plugins.DirEnv + `, ./plugins, ~/.mineos/plugins and then PATH, and run as
+'mineos <name>'. Plugins receive the resolved configuration in MINEOS_*
+environment variables (MINEOS_API_URL, MINEOS_API_KEY, MINEOS_ENV_PATH, ...).
+
+An optional mineos-<name>.yaml manifest next to the executable sets the help
+text and adds entries to the TUI sidebar:
+
+  short: Back up worlds to S3
+  tui:
+    - label: S3 Backup
+      args: [run]
+      streaming: true`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List discovered plugins",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			found := plugins.Discover(plugins.SearchDirs())
+			if len(found) == 0 {
+				cmd.Println("No plugins found.")
+				return nil
+			}
+			root := cmd.Root()
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tTUI\tPATH\tDESCRIPTION")
+			var warnings []string
+			for _, plugin := range found {
+				if command, _, err := root.Find([]string{plugin.Name}); err == nil && command.Annotations[pluginAnnotation] == "" {
+					warnings = append(warnings, fmt.Sprintf("%s is ignored: it conflicts with the built-in '%s' command", plugin.Path, plugin.Name))
+					continue
+				}
+				if plugin.ManifestErr != nil {
+					warnings = append(warnings, plugin.ManifestErr.Error())
+				}
+				for _, shadow := range plugin.Shadows {
+					warnings = append(warnings, fmt.Sprintf("%s is shadowed by %s", shadow, plugin.Path))
+				}
+				fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", plugin.Name, len(plugin.Manifest.Tui), plugin.Path, fallback(plugin.Manifest.Short, "-"))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if len(warnings) > 0 {
+				cmd.Printf("\n%s\n  %s\n", styleWarning.Render("Warnings:"), strings.Join(warnings, "\n  "))
+			}
+			return nil
+		},
+	})
+
+	return cmd
+}
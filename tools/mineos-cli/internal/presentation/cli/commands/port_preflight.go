@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/portmap"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// publishedPort is a host port the stack binds, named after the .env key
+// that sets it.
+type publishedPort struct {
+	port     int
+	protocol string
+	key      string
+	service  string
+}
+
+// stackPublishedPorts lists the host ports compose up binds. In host
+// network mode the Minecraft ranges are not published; the servers bind
+// their own ports when they start.
+func stackPublishedPorts(cfg config.Config, values map[string]string) []publishedPort {
+	list := []publishedPort{
+		{port: atoiDefault(cfg.ApiPort, defaultApiPort), protocol: "tcp", key: "API_PORT", service: "api"},
+		{port: atoiDefault(values["WEB_PORT"], defaultWebPort), protocol: "tcp", key: "WEB_PORT", service: "web"},
+	}
+	if strings.EqualFold(cfg.NetworkMode, "host") {
+		return list
+	}
+	javaRange := portmap.ParseRange(values["MC_PORT_RANGE"], defaultJavaPortRange)
+	for port := javaRange.From; port <= javaRange.To; port++ {
+		list = append(list,
+			publishedPort{port: port, protocol: "tcp", key: "MC_PORT_RANGE", service: "api"},
+			publishedPort{port: port, protocol: "udp", key: "MC_PORT_RANGE", service: "api"})
+	}
+	bedrockRange := portmap.ParseRange(values["BEDROCK_PORT_RANGE"], defaultBedrockPortRange)
+	for port := bedrockRange.From; port <= bedrockRange.To; port++ {
+		list = append(list, publishedPort{port: port, protocol: "udp", key: "BEDROCK_PORT_RANGE", service: "api"})
+	}
+	return list
+}
+
+func atoiDefault(value string, def int) int {
+	if parsed, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && parsed > 0 {
+		return parsed
+	}
+	return def
+}
+
+// checkStackPorts fails, naming the process holding each port, when another
+// program has bound a port the stack publishes; compose up would otherwise
+// leave the container restarting. Ports of services that are already
+// running are their own and skipped, as is the check for --ssh hosts.
+func checkStackPorts(ctx context.Context, compose composeRunner, cfg config.Config) error {
+	if compose.remote != nil {
+		return nil
+	}
+	values, err := loadLayeredEnvValues(cfg)
+	if err != nil {
+		return err
+	}
+	running := map[string]bool{}
+	if output, err := compose.output([]string{"ps", "--services", "--filter", "status=running"}); err == nil {
+		for _, service := range strings.Fields(string(output)) {
+			running[service] = true
+		}
+	}
+
+	var lines []string
+	for _, published := range stackPublishedPorts(cfg, values) {
+		if running[published.service] || portBindable(published.port, published.protocol, cfg.BindFamily()) {
+			continue
+		}
+		line := fmt.Sprintf("  %d/%s (%s)", published.port, published.protocol, published.key)
+		if owner := portOwner(ctx, published.port, published.protocol); owner != "" {
+			line += " is used by " + owner
+		} else {
+			line += " is in use"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return ports.WithKind(fmt.Errorf("ports needed by MineOS are already in use:\n%s\nStop those programs or pick other ports with 'mineos reconfigure' (or --skip-port-check to start anyway)", strings.Join(lines, "\n")), ports.ErrConflict)
+}
+
+// portBindable is hostPortFree limited to the families the stack publishes
+// on (MINEOS_BIND_ADDRESS).
+func portBindable(port int, protocol string, family string) bool {
+	if family == config.BindDual {
+		return hostPortFree(port, protocol)
+	}
+	network, host := "4", "0.0.0.0"
+	if family == config.BindIPv6 {
+		network, host = "6", "::"
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	if protocol == "udp" {
+		conn, err := net.ListenPacket("udp"+network, address)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+	listener, err := net.Listen("tcp"+network, address)
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+var (
+	ssUsers     = regexp.MustCompile(`users:\(\("([^"]+)",pid=(\d+)`)
+	netstatLine = regexp.MustCompile(`^\s*(TCP|UDP)\s+\S+:(\d+)\s+\S+\s+(?:LISTENING\s+)?(\d+)\s*$`)
+)
+
+// portOwner names the process bound to a port, e.g. "nginx (pid 812)", with
+// lsof or ss, or netstat on Windows. Ports published by another container
+// name the container. Empty when no tool could tell, which is common for
+// other users' processes without root.
+func portOwner(ctx context.Context, port int, protocol string) string {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	owner := ""
+	if runtime.GOOS == "windows" {
+		owner = netstatPortOwner(ctx, port, protocol)
+	} else {
+		owner = lsofPortOwner(ctx, port, protocol)
+		if owner == "" {
+			owner = ssPortOwner(ctx, port, protocol)
+		}
+	}
+	if owner == "" || strings.HasPrefix(owner, "docker-proxy") || strings.HasPrefix(owner, "com.docker") {
+		if output, err := exec.CommandContext(ctx, "docker", "ps", "--filter", "publish="+strconv.Itoa(port)+"/"+protocol, "--format", "{{.Names}}").Output(); err == nil {
+			if names := strings.Fields(string(output)); len(names) > 0 {
+				return "container " + strings.Join(names, ", ")
+			}
+		}
+	}
+	return owner
+}
+
+func lsofPortOwner(ctx context.Context, port int, protocol string) string {
+	args := []string{"-nP", "-i" + strings.ToUpper(protocol) + ":" + strconv.Itoa(port), "-Fpc"}
+	if protocol == "tcp" {
+		args = append(args, "-sTCP:LISTEN")
+	}
+	output, err := exec.CommandContext(ctx, "lsof", args...).Output()
+	if err != nil && len(output) == 0 {
+		return ""
+	}
+	pid, command := "", ""
+	for _, line := range strings.Split(string(output), "\n") {
+		switch {
+		case strings.HasPrefix(line, "p") && pid == "":
+			pid = line[1:]
+		case strings.HasPrefix(line, "c") && command == "":
+			command = line[1:]
+		}
+	}
+	return processLabel(command, pid)
+}
+
+func ssPortOwner(ctx context.Context, port int, protocol string) string {
+	flag := "-t"
+	if protocol == "udp" {
+		flag = "-u"
+	}
+	output, err := exec.CommandContext(ctx, "ss", "-Hlnp", flag, "sport = :"+strconv.Itoa(port)).Output()
+	if err != nil {
+		return ""
+	}
+	if m := ssUsers.FindStringSubmatch(string(output)); m != nil {
+		return processLabel(m[1], m[2])
+	}
+	return ""
+}
+
+func netstatPortOwner(ctx context.Context, port int, protocol string) string {
+	output, err := exec.CommandContext(ctx, "netstat", "-ano", "-p", strings.ToUpper(protocol)).Output()
+	if err != nil {
+		return ""
+	}
+	pid := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		m := netstatLine.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m != nil && m[2] == strconv.Itoa(port) && (protocol == "udp" || strings.Contains(line, "LISTENING")) {
+			pid = m[3]
+			break
+		}
+	}
+	if pid == "" {
+		return ""
+	}
+	command := ""
+	if output, err := exec.CommandContext(ctx, "tasklist", "/FI", "PID eq "+pid, "/FO", "CSV", "/NH").Output(); err == nil {
+		if fields := strings.Split(strings.TrimSpace(string(output)), ","); len(fields) > 1 {
+			command = strings.Trim(fields[0], `"`)
+		}
+	}
+	return processLabel(command, pid)
+}
+
+func processLabel(command, pid string) string {
+	switch {
+	case command != "" && pid != "":
+		return fmt.Sprintf("%s (pid %s)", command, pid)
+	case pid != "":
+		return "pid " + pid
+	}
+	return command
+}
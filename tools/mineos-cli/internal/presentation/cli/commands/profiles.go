@@ -0,0 +1,297 @@
+package commands
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/java"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+const customProfileType = "custom"
+
+func NewProfilesCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profiles",
+		Short: "Manage the server jars cached on the host",
+		Long: `Profiles are the server jars MineOS keeps in its profile cache and
+copies into servers. Vanilla, Paper and Bedrock profiles are downloaded on
+demand; 'build-custom' adds your own jars, such as a private fork or an older
+build, as profiles named custom-<name>.`,
+	}
+
+	cmd.AddCommand(newProfilesListCommand(loadConfig))
+	cmd.AddCommand(newProfilesBuildCustomCommand(loadConfig))
+	cmd.AddCommand(newProfilesDeleteCommand(loadConfig))
+
+	return cmd
+}
+
+func newProfilesListCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var all bool
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List downloaded and custom profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			var profiles []ports.Profile
+			custom := map[string]ports.CustomProfile{}
+			if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				var err error
+				if profiles, err = client.ListProfiles(ctx); err != nil {
+					return err
+				}
+				customProfiles, err := client.ListCustomProfiles(ctx)
+				if err != nil {
+					return err
+				}
+				for _, profile := range customProfiles {
+					custom[profile.Id] = profile
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			shown := profiles[:0]
+			for _, profile := range profiles {
+				if all || profile.Downloaded {
+					shown = append(shown, profile)
+				}
+			}
+			if jsonOut {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(shown)
+			}
+			if len(shown) == 0 {
+				cmd.Println("No profiles downloaded yet. Install one with 'mineos servers create --version' or add a jar with 'mineos profiles build-custom'.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tSOFTWARE\tVERSION\tTYPE\tDOWNLOADED\tJAVA")
+			for _, profile := range shown {
+				downloaded := "no"
+				if profile.Downloaded {
+					downloaded = styleSuccess.Render("yes")
+				}
+				javaVersion := "auto"
+				if c, ok := custom[profile.Id]; ok && c.JavaVersion != nil {
+					javaVersion = strconv.Itoa(*c.JavaVersion)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", profile.Id, fallback(profile.Group, "-"), fallback(profile.Version, "-"), fallback(profile.Type, "-"), downloaded, javaVersion)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Include profiles that are not downloaded")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+func newProfilesBuildCustomCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var upload ports.CustomProfileUpload
+
+	cmd := &cobra.Command{
+		Use:   "build-custom <jar>",
+		Short: "Register a server jar of your own as a profile",
+		Long: `Upload a server jar to the host profile cache as profile custom-<name>,
+so 'mineos servers create --profile custom-<name>' can install it like any
+other profile.
+
+The Minecraft version and Java requirement are read from the jar's
+version.json when it has one (vanilla, Spigot and Paper jars do); otherwise
+give --version, and --java if the version alone does not decide it. Servers
+installed from the profile are pinned to that Java runtime.`,
+		Example: `  mineos profiles build-custom ./paper-fork.jar --name myfork --software paper
+  mineos profiles build-custom ./craftbukkit-1.8.8.jar --name legacy --software craftbukkit --version 1.8.8 --java 8`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[0]
+			if upload.Name == "" {
+				upload.Name = customProfileName(path)
+			}
+			detected, detectedJava, err := readJarVersion(path)
+			if err != nil {
+				return err
+			}
+			if upload.Version == "" {
+				if detected == "" {
+					return fmt.Errorf("%s has no version.json; give the Minecraft version with --version", filepath.Base(path))
+				}
+				upload.Version = detected
+				cmd.Printf("Detected Minecraft %s\n", detected)
+			}
+			if upload.JavaVersion == 0 && detectedJava > 0 {
+				upload.JavaVersion = detectedJava
+			}
+			if upload.JavaVersion == 0 && java.RecommendedVersion(upload.Version) == 0 {
+				cmd.Printf("%s cannot tell the Java version from %q; servers will use the default java unless --java is given.\n", styleWarning.Render("Note:"), upload.Version)
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			ctx := context.Background()
+			var profile ports.CustomProfile
+			if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				// A retry after an API key prompt must send the jar again.
+				if _, err := file.Seek(0, io.SeekStart); err != nil {
+					return err
+				}
+				profile, err = client.UploadCustomProfile(ctx, upload, file)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			javaVersion := "auto"
+			if profile.JavaVersion != nil {
+				javaVersion = strconv.Itoa(*profile.JavaVersion)
+			}
+			cmd.Printf("%s Added profile %s (%s %s, Java %s, %s)\n", markOK(), profile.Id, profile.Software, profile.Version, javaVersion, formatBytes(profile.SizeBytes))
+			cmd.Println(styleDim.Render("sha256 " + profile.Sha256))
+			cmd.Println(styleDim.Render(fmt.Sprintf("Create a server with it: mineos servers create <name> --profile %s", profile.Id)))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&upload.Name, "name", "", "Profile name, stored as custom-<name> (default: the jar's file name)")
+	cmd.Flags().StringVar(&upload.Software, "software", "", "Server software the jar is built from, e.g. paper, spigot, fabric or forge")
+	cmd.Flags().StringVar(&upload.Version, "version", "", "Minecraft version (default: read from the jar)")
+	cmd.Flags().IntVar(&upload.JavaVersion, "java", 0, "Java feature release the jar needs, e.g. 8, 17 or 21 (default: from the jar or version)")
+	cmd.Flags().StringVar(&upload.Description, "description", "", "Free-form note kept with the profile")
+	cmd.Flags().BoolVar(&upload.Replace, "replace", false, "Replace an existing custom profile of the same name")
+	_ = cmd.MarkFlagRequired("software")
+	_ = cmd.RegisterFlagCompletionFunc("java", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		versions := make([]string, len(java.SupportedVersions))
+		for i, version := range java.SupportedVersions {
+			versions[i] = strconv.Itoa(version)
+		}
+		return versions, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+func newProfilesDeleteCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <profile>",
+		Short: "Delete a custom profile",
+		Long:  "Remove a custom profile from the host profile cache. Servers already installed from it keep their copy of the jar.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			id := args[0]
+			if !strings.HasPrefix(id, customProfileType+"-") {
+				id = customProfileType + "-" + id
+			}
+			if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				return client.DeleteCustomProfile(ctx, id)
+			}); err != nil {
+				return err
+			}
+			cmd.Printf("Deleted profile %s\n", id)
+			return nil
+		},
+	}
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeProfileIDs(cmd, loadConfig, true), cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// completeProfileIDs returns the downloaded profile ids, or only the custom
+// ones, for shell completion.
+func completeProfileIDs(cmd *cobra.Command, loadConfig *usecases.LoadConfigUseCase, customOnly bool) []string {
+	cfg, err := loadConfig.Execute(cmd.Context())
+	if err != nil {
+		return nil
+	}
+	profiles, err := cachedProfiles(cmd.Context(), cfg, api.NewClientFromConfig(cfg))
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, profile := range profiles {
+		if profile.Downloaded && (!customOnly || profile.Type == customProfileType) {
+			ids = append(ids, profile.Id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// customProfileName turns a jar file name into a profile name:
+// "My-Fork-1.20.4.jar" becomes "my-fork-1.20.4".
+func customProfileName(path string) string {
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return strings.Trim(b.String(), "-._")
+}
+
+// readJarVersion checks that path is a jar and reads the Minecraft version
+// and Java requirement from the version.json Mojang ships in server jars.
+// Both are empty when the jar has none.
+func readJarVersion(path string) (string, int, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("%s is not a jar file: %w", filepath.Base(path), err)
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.Name != "version.json" {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return "", 0, err
+		}
+		defer reader.Close()
+		var info struct {
+			ID          string `json:"id"`
+			Name        string `json:"name"`
+			JavaVersion int    `json:"java_version"`
+		}
+		if err := json.NewDecoder(reader).Decode(&info); err != nil {
+			return "", 0, nil
+		}
+		return fallback(info.ID, info.Name), info.JavaVersion, nil
+	}
+	return "", 0, nil
+}
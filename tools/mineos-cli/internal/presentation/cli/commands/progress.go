@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const progressBarWidth = 30
+
+// progressBar reports a long operation as a bar with percentage, ETA and,
+// for transfers, size and rate. On a terminal it redraws one line in place;
+// elsewhere (pipes, CI logs, machine and accessible mode) it prints a line
+// every 10% and whenever the message changes, without the bar in accessible
+// mode. Quiet mode prints nothing.
+type progressBar struct {
+	out     io.Writer
+	label   string
+	bytes   bool
+	total   int64
+	current int64
+	message string
+
+	start   time.Time
+	drawn   time.Time
+	live    bool
+	step    int64
+	last    string
+	printed int64
+}
+
+// newTransferProgress tracks bytes; total may be 0 when the size is unknown.
+// The bar is an io.Writer so it can sit in an io.MultiWriter next to the
+// destination.
+func newTransferProgress(out io.Writer, label string, total int64) *progressBar {
+	return newProgressBar(out, label, total, true)
+}
+
+// newPercentProgress tracks a 0-100 percentage, e.g. from an API job.
+func newPercentProgress(out io.Writer, label string) *progressBar {
+	return newProgressBar(out, label, 100, false)
+}
+
+func newProgressBar(out io.Writer, label string, total int64, bytes bool) *progressBar {
+	out = decor(out)
+	return &progressBar{out: out, label: label, bytes: bytes, total: total, start: time.Now(), step: -1, printed: -1, live: redraws(out)}
+}
+
+func (p *progressBar) Write(data []byte) (int, error) {
+	p.Set(p.current + int64(len(data)))
+	return len(data), nil
+}
+
+func (p *progressBar) Set(current int64) {
+	p.current = current
+	p.draw(false)
+}
+
+// SetTotal updates the expected size, e.g. as image layers are discovered.
+func (p *progressBar) SetTotal(total int64) {
+	p.total = total
+}
+
+func (p *progressBar) SetMessage(message string) {
+	p.message = message
+	p.draw(false)
+}
+
+// Finish draws the final state and ends the line.
+func (p *progressBar) Finish() {
+	p.draw(true)
+	if p.live {
+		fmt.Fprintln(p.out)
+	}
+}
+
+func (p *progressBar) draw(final bool) {
+	now := time.Now()
+	if p.live {
+		if !final && now.Sub(p.drawn) < 100*time.Millisecond {
+			return
+		}
+		p.drawn = now
+		fmt.Fprintf(p.out, "\r\x1b[2K%s", p.render(now))
+		return
+	}
+
+	step := int64(-1)
+	if p.total > 0 {
+		step = min(p.current, p.total) * 10 / p.total
+	}
+	if !final && step == p.step && p.message == p.last {
+		return
+	}
+	if !final && step == -1 && p.message == p.last {
+		// Unknown size: nothing meaningful to print until the end.
+		return
+	}
+	if final && p.current == p.printed && p.message == p.last {
+		return
+	}
+	p.step, p.last, p.printed = step, p.message, p.current
+	fmt.Fprintln(p.out, p.render(now))
+}
+
+func (p *progressBar) render(now time.Time) string {
+	parts := []string{}
+	if p.label != "" {
+		parts = append(parts, p.label)
+	}
+
+	elapsed := now.Sub(p.start).Seconds()
+	if p.total > 0 {
+		ratio := min(float64(p.current)/float64(p.total), 1)
+		filled := int(ratio * progressBarWidth)
+		if !accessibleMode {
+			parts = append(parts, styleInfo.Render(strings.Repeat("█", filled))+styleDim.Render(strings.Repeat("░", progressBarWidth-filled)))
+		}
+		parts = append(parts, fmt.Sprintf("%3d%%", int(ratio*100)))
+	}
+	if p.bytes {
+		size := formatBytes(p.current)
+		if p.total > 0 {
+			size += " / " + formatBytes(p.total)
+		}
+		parts = append(parts, size)
+		if elapsed > 0 && p.current > 0 {
+			parts = append(parts, formatBytes(int64(float64(p.current)/elapsed))+"/s")
+		}
+	}
+	if p.total > 0 && p.current > 0 && p.current < p.total && elapsed >= 1 {
+		remaining := elapsed * float64(p.total-p.current) / float64(p.current)
+		parts = append(parts, styleDim.Render("ETA "+(time.Duration(remaining)*time.Second).String()))
+	}
+	if p.message != "" {
+		parts = append(parts, p.message)
+	}
+	return "  " + strings.Join(parts, "  ")
+}
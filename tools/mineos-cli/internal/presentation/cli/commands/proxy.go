@@ -0,0 +1,367 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/proxy"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+func NewProxyCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proxy",
+		Short: "Manage Velocity/BungeeCord proxy servers",
+		Long: `Manage backend registration for a Velocity or BungeeCord proxy hosted by MineOS.
+
+The proxy type is detected from velocity.toml or config.yml in the proxy's
+server directory. Backends default to 127.0.0.1:<server-port>.`,
+	}
+
+	cmd.AddCommand(newProxyListCommand(loadConfig))
+	cmd.AddCommand(newProxyRegisterCommand(loadConfig))
+	cmd.AddCommand(newProxyUnregisterCommand(loadConfig))
+	cmd.AddCommand(newProxyReloadCommand(loadConfig))
+	cmd.AddCommand(newProxyVerifyCommand(loadConfig))
+
+	return cmd
+}
+
+type proxyConfig struct {
+	kind    string
+	file    string
+	content string
+}
+
+func loadProxyConfig(ctx context.Context, client *api.Client, name string) (proxyConfig, error) {
+	content, err := client.ReadServerFile(ctx, name, proxy.VelocityConfigFile)
+	if err == nil {
+		return proxyConfig{kind: proxy.KindVelocity, file: proxy.VelocityConfigFile, content: content}, nil
+	}
+	if !errors.Is(err, api.ErrNotFound) {
+		return proxyConfig{}, err
+	}
+
+	content, err = client.ReadServerFile(ctx, name, proxy.BungeeConfigFile)
+	if err == nil && strings.Contains(content, "listeners:") {
+		return proxyConfig{kind: proxy.KindBungee, file: proxy.BungeeConfigFile, content: content}, nil
+	}
+	if err != nil && !errors.Is(err, api.ErrNotFound) {
+		return proxyConfig{}, err
+	}
+	return proxyConfig{}, fmt.Errorf("%s does not look like a proxy (no velocity.toml or BungeeCord config.yml); start it once to generate its config", name)
+}
+
+func (p proxyConfig) servers() (map[string]string, error) {
+	if p.kind == proxy.KindVelocity {
+		return proxy.VelocityServers(p.content), nil
+	}
+	return proxy.BungeeServers(p.content)
+}
+
+func newProxyListCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list <proxy>",
+		Short: "List backends registered with a proxy",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			return runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				cfg, err := loadProxyConfig(ctx, client, args[0])
+				if err != nil {
+					return err
+				}
+				servers, err := cfg.servers()
+				if err != nil {
+					return err
+				}
+				cmd.Printf("%s (%s)\n", args[0], cfg.kind)
+				if len(servers) == 0 {
+					cmd.Println("No backends registered.")
+					return nil
+				}
+				for _, name := range proxy.SortedNames(servers) {
+					cmd.Printf("  %s\t%s\n", name, servers[name])
+				}
+				if cfg.kind == proxy.KindVelocity {
+					if order := proxy.VelocityTry(cfg.content); len(order) > 0 {
+						cmd.Printf("Try order: %s\n", strings.Join(order, ", "))
+					}
+				}
+				return nil
+			})
+		},
+	}
+}
+
+func newProxyRegisterCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var address string
+	var fallback bool
+	var reload bool
+
+	cmd := &cobra.Command{
+		Use:   "register <proxy> <backend>",
+		Short: "Register a backend server with a proxy",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			proxyName, backend := args[0], args[1]
+			ctx := context.Background()
+			return runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				cfg, err := loadProxyConfig(ctx, client, proxyName)
+				if err != nil {
+					return err
+				}
+
+				target := strings.TrimSpace(address)
+				if target == "" {
+					props, err := client.GetServerProperties(ctx, backend)
+					if err != nil {
+						return fmt.Errorf("failed to read %s server.properties: %w", backend, err)
+					}
+					port := strings.TrimSpace(props["server-port"])
+					if port == "" {
+						port = "25565"
+					}
+					target = "127.0.0.1:" + port
+				}
+
+				var updated string
+				if cfg.kind == proxy.KindVelocity {
+					updated = proxy.SetVelocityServer(cfg.content, backend, target, fallback)
+				} else {
+					updated, err = proxy.SetBungeeServer(cfg.content, backend, target, fallback)
+					if err != nil {
+						return fmt.Errorf("failed to update %s: %w", cfg.file, err)
+					}
+				}
+				if err := client.WriteServerFile(ctx, proxyName, cfg.file, updated); err != nil {
+					return err
+				}
+				cmd.Printf("Registered %s -> %s in %s/%s\n", backend, target, proxyName, cfg.file)
+
+				if reload {
+					return reloadProxy(ctx, client, cmd, proxyName, cfg.kind)
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&address, "address", "", "Backend address host:port (default: 127.0.0.1:<server-port>)")
+	cmd.Flags().BoolVar(&fallback, "try", false, "Add the backend to the proxy's connection order (try/priorities)")
+	cmd.Flags().BoolVar(&reload, "reload", false, "Reload the proxy configuration afterwards")
+
+	return cmd
+}
+
+func newProxyUnregisterCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var reload bool
+
+	cmd := &cobra.Command{
+		Use:   "unregister <proxy> <backend>",
+		Short: "Remove a backend server from a proxy",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			proxyName, backend := args[0], args[1]
+			ctx := context.Background()
+			return runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				cfg, err := loadProxyConfig(ctx, client, proxyName)
+				if err != nil {
+					return err
+				}
+
+				var updated string
+				var removed bool
+				if cfg.kind == proxy.KindVelocity {
+					updated, removed = proxy.RemoveVelocityServer(cfg.content, backend)
+				} else {
+					updated, removed, err = proxy.RemoveBungeeServer(cfg.content, backend)
+					if err != nil {
+						return fmt.Errorf("failed to update %s: %w", cfg.file, err)
+					}
+				}
+				if !removed {
+					cmd.Printf("%s is not registered with %s\n", backend, proxyName)
+					return nil
+				}
+				if err := client.WriteServerFile(ctx, proxyName, cfg.file, updated); err != nil {
+					return err
+				}
+				cmd.Printf("Unregistered %s from %s\n", backend, proxyName)
+
+				if reload {
+					return reloadProxy(ctx, client, cmd, proxyName, cfg.kind)
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&reload, "reload", false, "Reload the proxy configuration afterwards")
+
+	return cmd
+}
+
+func newProxyReloadCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reload <proxy>",
+		Short: "Reload the proxy configuration",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			return runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				cfg, err := loadProxyConfig(ctx, client, args[0])
+				if err != nil {
+					return err
+				}
+				return reloadProxy(ctx, client, cmd, args[0], cfg.kind)
+			})
+		},
+	}
+}
+
+func reloadProxy(ctx context.Context, client *api.Client, cmd *cobra.Command, name, kind string) error {
+	command := "velocity reload"
+	if kind == proxy.KindBungee {
+		command = "greload"
+	}
+	if err := client.SendConsoleCommand(ctx, name, command); err != nil {
+		return fmt.Errorf("failed to reload %s: %w", name, err)
+	}
+	cmd.Printf("Sent '%s' to %s\n", command, name)
+	return nil
+}
+
+func newProxyVerifyCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify <proxy> [backend]...",
+		Short: "Check that backends accept the proxy's player forwarding",
+		Long: `Compare the proxy's forwarding mode and secret with each backend.
+
+Velocity modern forwarding is checked against proxies.velocity in
+config/paper-global.yml (or paper.yml on older servers). Legacy and
+BungeeCord forwarding require settings.bungeecord in spigot.yml.
+Backends default to every server registered with the proxy.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			proxyName := args[0]
+			ctx := context.Background()
+			problems := 0
+			err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				cfg, err := loadProxyConfig(ctx, client, proxyName)
+				if err != nil {
+					return err
+				}
+
+				backends := args[1:]
+				if len(backends) == 0 {
+					servers, err := cfg.servers()
+					if err != nil {
+						return err
+					}
+					backends = proxy.SortedNames(servers)
+				}
+				if len(backends) == 0 {
+					cmd.Println("No backends registered.")
+					return nil
+				}
+
+				var mode, secret string
+				var ipForward bool
+				if cfg.kind == proxy.KindVelocity {
+					var secretFile string
+					mode, secretFile = proxy.VelocityForwarding(cfg.content)
+					if mode == "modern" || mode == "bungeeguard" {
+						secret, err = client.ReadServerFile(ctx, proxyName, secretFile)
+						if err != nil {
+							return fmt.Errorf("failed to read forwarding secret %s: %w", secretFile, err)
+						}
+						secret = strings.TrimSpace(secret)
+					}
+					cmd.Printf("Proxy %s: velocity, forwarding mode %s\n", proxyName, fallback(mode, "none"))
+				} else {
+					ipForward = proxy.BungeeIPForward(cfg.content)
+					cmd.Printf("Proxy %s: bungeecord, ip_forward %t\n", proxyName, ipForward)
+				}
+
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "BACKEND\tRESULT")
+				for _, backend := range backends {
+					forwarding, err := readBackendForwarding(ctx, client, backend)
+					var problem string
+					switch {
+					case err != nil:
+						problem = err.Error()
+					case cfg.kind == proxy.KindVelocity:
+						problem = proxy.CheckVelocityBackend(mode, secret, forwarding)
+					default:
+						problem = proxy.CheckBungeeBackend(ipForward, forwarding)
+					}
+					if problem == "" {
+						fmt.Fprintf(w, "%s\t%s\n", backend, styleSuccess.Render("ok"))
+					} else {
+						problems++
+						fmt.Fprintf(w, "%s\t%s\n", backend, styleError.Render(problem))
+					}
+				}
+				return w.Flush()
+			})
+			if err != nil {
+				return err
+			}
+			if problems > 0 {
+				return fmt.Errorf("%d backend(s) have forwarding problems", problems)
+			}
+			return nil
+		},
+	}
+}
+
+func readBackendForwarding(ctx context.Context, client *api.Client, name string) (proxy.BackendForwarding, error) {
+	var forwarding proxy.BackendForwarding
+
+	content, err := client.ReadServerFile(ctx, name, proxy.PaperGlobalConfigFile)
+	switch {
+	case err == nil:
+		if err := proxy.ParsePaperGlobal(content, &forwarding); err != nil {
+			return forwarding, fmt.Errorf("invalid %s: %w", proxy.PaperGlobalConfigFile, err)
+		}
+	case errors.Is(err, api.ErrNotFound):
+		legacy, legacyErr := client.ReadServerFile(ctx, name, proxy.LegacyPaperConfigFile)
+		if legacyErr == nil {
+			if err := proxy.ParseLegacyPaper(legacy, &forwarding); err != nil {
+				return forwarding, fmt.Errorf("invalid %s: %w", proxy.LegacyPaperConfigFile, err)
+			}
+		} else if !errors.Is(legacyErr, api.ErrNotFound) {
+			return forwarding, legacyErr
+		}
+	default:
+		return forwarding, err
+	}
+
+	content, err = client.ReadServerFile(ctx, name, proxy.SpigotConfigFile)
+	if err == nil {
+		if err := proxy.ParseSpigot(content, &forwarding); err != nil {
+			return forwarding, fmt.Errorf("invalid %s: %w", proxy.SpigotConfigFile, err)
+		}
+	} else if !errors.Is(err, api.ErrNotFound) {
+		return forwarding, err
+	}
+
+	return forwarding, nil
+}
+
+// runWithClient wraps withApiKeyRetry for commands that only need the client.
+func runWithClient(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, cmd *cobra.Command, action func(*api.Client) error) error {
+	_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+		return action(client)
+	})
+	return err
+}
@@ -0,0 +1,198 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+func NewQuarantineCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quarantine",
+		Short: "Review mod and plugin jars the scanner flagged",
+		Long: `Mod and plugin jars are scanned as the API installs them, whether
+uploaded, installed from Modrinth or CurseForge, or unpacked by 'mineos
+servers import'. The scanner looks for known malware (such as the fractureiser family), contacts to
+known malware hosts, Discord token grabbing and autostart persistence, plus
+heuristics like spawning processes, loading classes from URLs and heavy name
+obfuscation. A jar with any high severity finding, or two medium ones, is moved
+to the host quarantine folder instead of the server.
+
+Release a jar you trust to put it back where it was installed.`,
+	}
+
+	cmd.AddCommand(newQuarantineListCommand(loadConfig))
+	cmd.AddCommand(newQuarantineReleaseCommand(loadConfig))
+	cmd.AddCommand(newQuarantineDeleteCommand(loadConfig))
+
+	return cmd
+}
+
+func newQuarantineListCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var server string
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List quarantined jars and why they were flagged",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			var entries []ports.QuarantinedJar
+			if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				var err error
+				entries, err = client.ListQuarantine(ctx)
+				return err
+			}); err != nil {
+				return err
+			}
+			entries = filterQuarantine(entries, server)
+
+			if jsonOut {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(entries)
+			}
+			if len(entries) == 0 {
+				cmd.Println("Nothing in quarantine.")
+				return nil
+			}
+			printQuarantine(cmd.OutOrStdout(), entries)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&server, "server", "", "Only show jars from this server")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+	_ = cmd.RegisterFlagCompletionFunc("server", func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		names, _ := listServerNames(cmd, loadConfig)
+		return names, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+func newQuarantineReleaseCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release <id>",
+		Short: "Put a quarantined jar back on its server",
+		Long:  "Move a quarantined jar back to the folder it was installed to. Only release jars you have reviewed; the server picks it up on its next restart.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				return client.ReleaseQuarantine(ctx, args[0])
+			}); err != nil {
+				return err
+			}
+			cmd.Printf("%s Released %s; restart the server to load it.\n", styleWarning.Render("!"), args[0])
+			return nil
+		},
+	}
+	cmd.ValidArgsFunction = completeQuarantineIDs(loadConfig)
+
+	return cmd
+}
+
+func newQuarantineDeleteCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Delete a quarantined jar",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				return client.DeleteQuarantine(ctx, args[0])
+			}); err != nil {
+				return err
+			}
+			cmd.Printf("Deleted %s\n", args[0])
+			return nil
+		},
+	}
+	cmd.ValidArgsFunction = completeQuarantineIDs(loadConfig)
+
+	return cmd
+}
+
+func completeQuarantineIDs(loadConfig *usecases.LoadConfigUseCase) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		cfg, err := loadConfig.Execute(cmd.Context())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		entries, err := api.NewClientFromConfig(cfg).ListQuarantine(cmd.Context())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		ids := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			ids = append(ids, entry.Id+"\t"+entry.ServerName+"/"+entry.FileName)
+		}
+		return ids, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// reportQuarantined warns about jars quarantined from server, used after an
+// import so flagged mods do not go missing silently.
+func reportQuarantined(ctx context.Context, client *api.Client, out io.Writer, server string) {
+	entries, err := client.ListQuarantine(ctx)
+	if err != nil {
+		fmt.Fprintln(out, styleWarning.Render("check quarantine: "+err.Error()))
+		return
+	}
+	entries = filterQuarantine(entries, server)
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "%s %d jar(s) from %s were quarantined:\n", styleWarning.Render("!"), len(entries), server)
+	printQuarantine(out, entries)
+	fmt.Fprintln(out, styleDim.Render("Release a jar you trust with 'mineos quarantine release <id>'."))
+}
+
+func filterQuarantine(entries []ports.QuarantinedJar, server string) []ports.QuarantinedJar {
+	if server == "" {
+		return entries
+	}
+	filtered := entries[:0]
+	for _, entry := range entries {
+		if entry.ServerName == server {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+func printQuarantine(out io.Writer, entries []ports.QuarantinedJar) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSERVER\tJAR\tQUARANTINED\tFINDINGS")
+	for _, entry := range entries {
+		rules := make([]string, 0, len(entry.Report.Findings))
+		for _, finding := range entry.Report.Findings {
+			rule := finding.Rule
+			if finding.Severity == "high" {
+				rule = styleError.Render(rule)
+			}
+			rules = append(rules, rule)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.Id, entry.ServerName, entry.OriginalPath, entry.QuarantinedAt.Local().Format("2006-01-02 15:04"), strings.Join(rules, ", "))
+	}
+	_ = w.Flush()
+	for _, entry := range entries {
+		for _, finding := range entry.Report.Findings {
+			fmt.Fprintln(out, styleDim.Render(fmt.Sprintf("  %s: %s (%s)", entry.Id, finding.Detail, fallback(finding.Entry, entry.FileName))))
+		}
+	}
+}
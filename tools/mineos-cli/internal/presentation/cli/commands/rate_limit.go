@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/ratelimit"
+)
+
+// RateLimitEnv sets the default for --limit-rate.
+const RateLimitEnv = "MINEOS_RATE_LIMIT"
+
+// transferLimiter is the --limit-rate bucket, nil when unlimited. Every
+// transfer the CLI makes draws from it, so parallel ones share the rate.
+// Downloads the API performs have their own, from MINEOS_RATE_LIMIT in .env.
+var transferLimiter *ratelimit.Limiter
+
+func setTransferRate(raw string) error {
+	rate, err := ratelimit.Parse(raw)
+	if err != nil {
+		return err
+	}
+	transferLimiter = ratelimit.New(rate)
+	return nil
+}
+
+// transferClient is an HTTP client for large downloads, without a timeout
+// since a limited transfer can take a while.
+func transferClient() *http.Client {
+	return &http.Client{Transport: ratelimit.Transport(nil, transferLimiter)}
+}
+
+func defaultTransferRate() string {
+	return os.Getenv(RateLimitEnv)
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -69,6 +70,7 @@ func runReconfigure(cmd *cobra.Command, loadConfig *usecases.LoadConfigUseCase)
 	currentCurseforge := values["CurseForge__ApiKey"]
 	currentDiscord := values["Discord__WebhookUrl"]
 	currentNetworkMode := fallback(values["MINEOS_NETWORK_MODE"], defaultNetworkMode)
+	currentBindAddress := values["MINEOS_BIND_ADDRESS"]
 	currentBuildFromSource := parseEnvBool(values["MINEOS_BUILD_FROM_SOURCE"])
 	currentImageTag := fallback(values["MINEOS_IMAGE_TAG"], "latest")
 	currentManagementKey := values["MINEOS_API_KEY"]
@@ -148,6 +150,17 @@ func runReconfigure(cmd *cobra.Command, loadConfig *usecases.LoadConfigUseCase)
 			networkMode = "host"
 		}
 	}
+	bindAddress := currentBindAddress
+	if networkMode != "host" {
+		fmt.Fprintln(out, "Published ports can listen on IPv4 and IPv6 (dual), ipv4 only or ipv6 only.")
+		family, err := promptString(reader, out, "Address family", cfg.BindFamily())
+		if err != nil {
+			return err
+		}
+		if bindAddress, err = bindAddressFor(family); err != nil {
+			return err
+		}
+	}
 
 	buildFromSource, err := promptYesNo(reader, out, "Build images from source instead of pulling", currentBuildFromSource)
 	if err != nil {
@@ -164,6 +177,11 @@ func runReconfigure(cmd *cobra.Command, loadConfig *usecases.LoadConfigUseCase)
 		return err
 	}
 
+	limits, err := promptResourceLimits(reader, out, resourceLimitsFromConfig(cfg))
+	if err != nil {
+		return err
+	}
+
 	curseforgeKey, err := promptOptionalValue(reader, out, "CurseForge API key (optional)", currentCurseforge)
 	if err != nil {
 		return err
@@ -241,12 +259,26 @@ func runReconfigure(cmd *cobra.Command, loadConfig *usecases.LoadConfigUseCase)
 	if err := setEnvFileValue(envPath, "MINEOS_NETWORK_MODE", networkMode); err != nil {
 		return err
 	}
+	if err := setEnvFileValue(envPath, "MINEOS_BIND_ADDRESS", bindAddress); err != nil {
+		return err
+	}
 	if err := setEnvFileValue(envPath, "MINEOS_BUILD_FROM_SOURCE", strconv.FormatBool(buildFromSource)); err != nil {
 		return err
 	}
 	if err := setEnvFileValue(envPath, "MINEOS_IMAGE_TAG", imageTag); err != nil {
 		return err
 	}
+	for _, entry := range limits.envValues() {
+		if _, exists := values[entry[0]]; !exists && entry[1] == "" {
+			continue
+		}
+		if err := setEnvFileValue(envPath, entry[0], entry[1]); err != nil {
+			return err
+		}
+	}
+	if err := writeResourceLimitsFile(filepath.Dir(envPath), limits); err != nil {
+		return err
+	}
 	if strings.TrimSpace(curseforgeKey) != "" {
 		if err := setEnvFileValue(envPath, "CurseForge__ApiKey", curseforgeKey); err != nil {
 			return err
@@ -271,6 +303,18 @@ func runReconfigure(cmd *cobra.Command, loadConfig *usecases.LoadConfigUseCase)
 
 	fmt.Println("Configuration updated.")
 
+	// Push what the API can take at runtime and only offer a restart for the
+	// rest. If the stack isn't running (or can't be inspected), fall back to
+	// offering the restart.
+	if report, err := applyConfigChanges(ctx, loadConfig, false); err == nil {
+		printConfigApplyReport(out, report)
+		// Limits are not container variables the report compares; a change
+		// needs the containers recreated all the same.
+		if len(report.Restart) == 0 && limits == resourceLimitsFromConfig(cfg) {
+			return nil
+		}
+	}
+
 	// Ask if user wants to restart services
 	restartServices, err := promptYesNo(nil, nil, "Restart services now to apply changes", true)
 	if err != nil {
@@ -287,7 +331,11 @@ func runReconfigure(cmd *cobra.Command, loadConfig *usecases.LoadConfigUseCase)
 		}
 
 		// Run docker compose up -d to recreate containers with new env vars
-		// (restart doesn't reload environment variables)
+		// (restart doesn't reload environment variables), with the compose
+		// files the new settings select.
+		if updated, err := loadConfig.Execute(ctx); err == nil {
+			compose = composeWithConfig(compose, updated)
+		}
 		args := append([]string{}, compose.baseArgs...)
 		args = append(args, "up", "-d")
 		restartCmd := exec.Command(compose.exe, args...)
@@ -339,6 +387,9 @@ func promptOptionalPassword(out io.Writer, current string) (string, error) {
 }
 
 func promptOptionalValue(_ *bufio.Reader, _ io.Writer, label, current string) (string, error) {
+	if machineMode {
+		return "", errMachinePrompt
+	}
 	if current != "" {
 		fmt.Printf("%s (leave blank to keep current): ", label)
 	} else {
@@ -359,6 +410,40 @@ func promptOptionalValue(_ *bufio.Reader, _ io.Writer, label, current string) (s
 	return line, nil
 }
 
+// promptResourceLimits asks for the container limits; "none" removes one.
+func promptResourceLimits(reader *bufio.Reader, out io.Writer, current resourceLimits) (resourceLimits, error) {
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Memory and CPU limits keep the MineOS containers within a share of the host,")
+	fmt.Fprintln(out, "e.g. 8g and 3.5. The Minecraft servers run inside the api container, so its")
+	fmt.Fprintln(out, "limit must fit all of them; the web UI is fine with 512m. Enter 'none' to remove a limit.")
+	for {
+		limits := current
+		for _, field := range []struct {
+			label string
+			value *string
+		}{
+			{"API container memory limit", &limits.apiMemory},
+			{"API container CPU limit", &limits.apiCpus},
+			{"Web container memory limit", &limits.webMemory},
+			{"Web container CPU limit", &limits.webCpus},
+		} {
+			value, err := promptOptionalValue(reader, out, field.label, *field.value)
+			if err != nil {
+				return limits, err
+			}
+			if strings.EqualFold(value, "none") {
+				value = ""
+			}
+			*field.value = value
+		}
+		err := limits.validate()
+		if err == nil {
+			return limits, nil
+		}
+		fmt.Fprintln(out, err)
+	}
+}
+
 func promptRelativePathWithCurrent(reader *bufio.Reader, out io.Writer, label, current string) (string, error) {
 	for {
 		value, err := promptString(reader, out, label, current)
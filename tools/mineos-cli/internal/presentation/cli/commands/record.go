@@ -0,0 +1,264 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/recording"
+)
+
+func NewRecordCommand() *cobra.Command {
+	var output string
+	var title string
+
+	cmd := &cobra.Command{
+		Use:   "record [-- <command> [args...]]",
+		Short: "Record a terminal session and the operations run in it",
+		Long: `Record a shell, or the given command, for review with 'mineos replay'.
+
+Two files are written:
+
+  <name>.cast    the terminal output, in asciicast v2 format (asciinema and
+                 its web player can play it too)
+  <name>.jsonl   a transcript of every mineos command run in the session,
+                 the API requests it made that changed something, and how
+                 it exited
+
+Request bodies and credential flags (--api-key, --token, ...) are never
+recorded, but the terminal output is: anything shown on screen ends up in
+the .cast file. On Linux the session runs in its own terminal, so the TUI
+can be recorded; elsewhere only plain commands are.`,
+		Example: `  mineos record
+  mineos record -o incident-1432.cast --title "Lag spike on survival"
+  mineos record -- mineos tui`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if os.Getenv(recording.TranscriptEnv) != "" {
+				return ports.WithKind(errors.New("this shell is already being recorded"), ports.ErrConflict)
+			}
+			command := args
+			if len(command) == 0 {
+				command = []string{defaultShell()}
+			}
+			if output == "" {
+				output = "mineos-session-" + time.Now().Format("20060102-150405") + ".cast"
+			}
+			if !strings.HasSuffix(output, ".cast") {
+				output += ".cast"
+			}
+
+			out := cmd.OutOrStdout()
+			recordingMark := styleInfo.Render("●")
+			if accessibleMode {
+				recordingMark = styleInfo.Render("Recording:")
+			}
+			fmt.Fprintf(out, "%s Recording to %s\n", recordingMark, output)
+			if !recording.RecordsTerminal() {
+				fmt.Fprintln(out, styleWarning.Render("Full-screen programs such as the TUI cannot be recorded on "+runtime.GOOS+"."))
+			}
+			fmt.Fprintln(out, styleDim.Render("Exit the shell (Ctrl-D or 'exit') to stop."))
+
+			// Ctrl-C belongs to the recorded session, not to the recorder.
+			signal.Ignore(os.Interrupt)
+			defer signal.Reset(os.Interrupt)
+			session := recording.Session{Command: command, CastPath: output, Title: title}
+			code, err := session.Run(os.Stdin, os.Stdout)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "\n%s Recorded %s\n", markOK(), output)
+			fmt.Fprintln(out, styleDim.Render("Replay it with 'mineos replay "+output+"'."))
+			if code != 0 && len(args) > 0 {
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
+				return exitCodeError{code: code}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Recording to write (default mineos-session-<time>.cast)")
+	cmd.Flags().StringVar(&title, "title", "", "Title stored in the recording")
+
+	return cmd
+}
+
+func defaultShell() string {
+	if runtime.GOOS == "windows" {
+		return fallback(os.Getenv("COMSPEC"), "cmd.exe")
+	}
+	return fallback(os.Getenv("SHELL"), "/bin/sh")
+}
+
+func NewReplayCommand() *cobra.Command {
+	var speed float64
+	var idleLimit time.Duration
+	var from time.Duration
+	var operations bool
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "replay <recording.cast>",
+		Short: "Play back a recorded session or list the operations run in it",
+		Long: `Play back a session recorded with 'mineos record' in this terminal, with
+its original timing. Long pauses are shortened to --idle-limit.
+
+--operations lists the transcript instead: each mineos command run during
+the session, the changes it made through the API and how it ended, with
+their time into the recording so the playback can be started there with
+--from.`,
+		Example: `  mineos replay incident-1432.cast
+  mineos replay incident-1432.cast --operations
+  mineos replay incident-1432.cast --from 4m30s --speed 2`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			castPath := args[0]
+			if strings.HasSuffix(castPath, ".jsonl") {
+				castPath = strings.TrimSuffix(castPath, ".jsonl") + ".cast"
+				operations = true
+			}
+			if speed <= 0 {
+				return errors.New("--speed must be above 0")
+			}
+
+			file, err := os.Open(castPath)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			header, events, err := recording.ReadCast(file)
+			if err != nil {
+				return fmt.Errorf("%s: %w", castPath, err)
+			}
+
+			out := cmd.OutOrStdout()
+			if operations || jsonOut {
+				ops, err := recording.ReadTranscript(recording.TranscriptPath(castPath))
+				if errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("no transcript next to %s (expected %s)", castPath, recording.TranscriptPath(castPath))
+				}
+				if err != nil {
+					return err
+				}
+				if jsonOut {
+					encoder := json.NewEncoder(out)
+					encoder.SetIndent("", "  ")
+					return encoder.Encode(ops)
+				}
+				printOperations(out, header, ops)
+				return nil
+			}
+			return playCast(out, header, events, speed, idleLimit, from)
+		},
+	}
+
+	cmd.Flags().Float64Var(&speed, "speed", 1, "Playback speed factor")
+	cmd.Flags().DurationVar(&idleLimit, "idle-limit", 2*time.Second, "Longest pause kept in the playback (0 keeps them all)")
+	cmd.Flags().DurationVar(&from, "from", 0, "Start the playback this far into the recording")
+	cmd.Flags().BoolVar(&operations, "operations", false, "List the operations run in the session instead of playing it")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the operations as JSON")
+
+	return cmd
+}
+
+// playCast writes the recorded output with its timing. Output before from is
+// written at once, so the screen is as it was at that point.
+func playCast(out io.Writer, header recording.Header, events []recording.Event, speed float64, idleLimit, from time.Duration) error {
+	if file, ok := out.(*os.File); ok {
+		if width, height, err := term.GetSize(int(file.Fd())); err == nil && (width < header.Width || height < header.Height) {
+			fmt.Fprintln(out, styleWarning.Render(fmt.Sprintf("Recorded at %dx%d; enlarge this %dx%d terminal or the playback may wrap.", header.Width, header.Height, width, height)))
+			time.Sleep(2 * time.Second)
+		}
+	}
+
+	var last time.Duration
+	for _, event := range events {
+		if event.Code != recording.EventOutput {
+			continue
+		}
+		at := event.At()
+		if at > from {
+			delay := at - max(last, from)
+			if idleLimit > 0 && delay > idleLimit {
+				delay = idleLimit
+			}
+			time.Sleep(time.Duration(float64(delay) / speed))
+		}
+		last = at
+		if _, err := io.WriteString(out, event.Data); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(out, "\r\n%s\n", styleDim.Render(fmt.Sprintf("End of recording (%s, recorded %s).", formatOffset(last), header.Started().Local().Format("2006-01-02 15:04"))))
+	return nil
+}
+
+func printOperations(out io.Writer, header recording.Header, ops []recording.Operation) {
+	if title := header.Title; title != "" {
+		fmt.Fprintln(out, styleLabel.Render(title))
+	}
+	fmt.Fprintln(out, styleDim.Render("Recorded "+header.Started().Local().Format("2006-01-02 15:04:05")))
+	if len(ops) == 0 {
+		fmt.Fprintln(out, "No mineos commands were run in this session.")
+		return
+	}
+	fmt.Fprintln(out)
+	for _, op := range ops {
+		offset := styleDim.Render(fmt.Sprintf("%8s", formatOffset(op.Time.Sub(header.Started()))))
+		switch op.Kind {
+		case recording.KindCommand:
+			fmt.Fprintf(out, "%s  %s\n", offset, styleInfo.Render("mineos "+strings.Join(op.Args, " ")))
+		case recording.KindRequest:
+			status := styleSuccess.Render(fmt.Sprint(op.Status))
+			switch {
+			case op.Error != "":
+				status = styleError.Render(op.Error)
+			case op.Status >= 400:
+				status = styleError.Render(fmt.Sprint(op.Status))
+			}
+			fmt.Fprintf(out, "%s    %s %s  %s %s\n", offset, op.Method, requestPath(op.URL), status, styleDim.Render(fmt.Sprintf("%dms", op.DurationMs)))
+		case recording.KindExit:
+			code := 0
+			if op.ExitCode != nil {
+				code = *op.ExitCode
+			}
+			took := styleDim.Render("after " + (time.Duration(op.DurationMs) * time.Millisecond).Round(time.Millisecond*100).String())
+			if code == 0 {
+				fmt.Fprintf(out, "%s    %s %s\n", offset, styleSuccess.Render("done"), took)
+			} else {
+				fmt.Fprintf(out, "%s    %s %s %s\n", offset, styleError.Render(fmt.Sprintf("exit %d", code)), took, styleDim.Render(firstLine(strings.TrimSpace(op.Error))))
+			}
+		}
+	}
+}
+
+// requestPath shortens API URLs to their path past /api/v1.
+func requestPath(url string) string {
+	if _, path, ok := strings.Cut(url, "/api/v1"); ok {
+		return path
+	}
+	return url
+}
+
+// formatOffset shows a time into a recording as m:ss or h:mm:ss.
+func formatOffset(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	seconds := int(d.Seconds())
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
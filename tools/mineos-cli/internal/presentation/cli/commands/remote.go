@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/ssh"
+)
+
+// sshTunnelTimeout bounds connecting and authenticating, including any
+// password prompt.
+const sshTunnelTimeout = 60 * time.Second
+
+// sshRemote is the host given with --ssh; nil when managing this machine.
+var sshRemote *ssh.Remote
+
+var sshSession struct {
+	tunnel *ssh.Tunnel
+	dir    string
+}
+
+// sshLocalOnly lists the commands that work on files next to .env or under
+// the data directory, which a remote session only has copies of.
+var sshLocalOnly = map[string]bool{
+	"agent":       true,
+	"confirm":     true,
+	"env":         true,
+	"geyser":      true,
+	"install":     true,
+	"nbt":         true,
+	"reconfigure": true,
+	"tune":        true,
+	"uninstall":   true,
+	"world":       true,
+}
+
+// sshSkipConnect lists the commands that never touch the installation.
+func sshSkipConnect(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case "mineos", "version", "completion", "help", "upgrade", "plugins", "record", "replay", "fleet",
+		cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	return cmd.Annotations[pluginAnnotation] != ""
+}
+
+// connectRemote prepares a --ssh session: the remote .env (and .env.local)
+// is copied to a private temporary directory and read from there, with
+// API_PORT pointed at a tunnel to the remote API so it never has to listen
+// publicly. Compose commands run on the remote host over ssh.
+func connectRemote(ctx context.Context, cmd *cobra.Command, repo ports.ConfigRepository, target, dir string, overlays []string) error {
+	remote, err := ssh.Parse(target, dir)
+	if err != nil {
+		return fmt.Errorf("--ssh: %w", err)
+	}
+	name := cmd.Name()
+	for parent := cmd; parent.HasParent() && parent.Parent().HasParent(); parent = parent.Parent() {
+		name = parent.Parent().Name()
+	}
+	if sshLocalOnly[name] {
+		return fmt.Errorf("'mineos %s' works on local files and cannot run over --ssh; run it on %s", name, remote.Target)
+	}
+
+	tmp, err := os.MkdirTemp("", "mineos-ssh-")
+	if err != nil {
+		return err
+	}
+	sshSession.dir = tmp
+
+	envPath := filepath.Join(tmp, ".env")
+	for _, file := range []string{".env", ".env.local"} {
+		data, ok, err := remote.ReadFile(ctx, file)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if file == ".env" {
+				return fmt.Errorf(".env not found in %s on %s; pass --ssh-dir with the MineOS install directory", remote.Dir, remote)
+			}
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(tmp, file), data, 0o600); err != nil {
+			return err
+		}
+	}
+
+	apiPort := "5078"
+	bindAddress := ""
+	for _, file := range []string{envPath, envPath + ".local"} {
+		if !fileExists(file) {
+			continue
+		}
+		values, err := loadEnvValues(file)
+		if err != nil {
+			return err
+		}
+		if port := values["API_PORT"]; port != "" {
+			apiPort = port
+		}
+		if value, ok := values["MINEOS_BIND_ADDRESS"]; ok {
+			bindAddress = value
+		}
+	}
+
+	// An IPv6-only stack is only reachable on the remote ::1.
+	remoteHost := config.Config{BindAddress: bindAddress}.LoopbackHost()
+	tunnel, err := remote.Forward(ctx, remoteHost, apiPort, sshTunnelTimeout)
+	if err != nil {
+		return err
+	}
+	sshSession.tunnel = tunnel
+
+	// The local end of the tunnel is always 127.0.0.1.
+	portPath := filepath.Join(tmp, "ssh.env")
+	if err := os.WriteFile(portPath, []byte("API_PORT="+strconv.Itoa(tunnel.LocalPort)+"\nMINEOS_BIND_ADDRESS=0.0.0.0:\n"), 0o600); err != nil {
+		return err
+	}
+	repo.SetPath(envPath)
+	repo.SetOverlays(append(append([]string{}, overlays...), portPath))
+	sshRemote = &remote
+	return nil
+}
+
+// closeRemote stops the tunnel and removes the copied env files.
+func closeRemote() {
+	sshSession.tunnel.Close()
+	if sshSession.dir != "" {
+		_ = os.RemoveAll(sshSession.dir)
+	}
+	sshRemote = nil
+	sshSession.tunnel = nil
+	sshSession.dir = ""
+}
+
+// command builds a compose command, on the --ssh host when there is one.
+// env entries are added to the command's environment.
+func (c composeRunner) command(ctx context.Context, args []string, env []string) *exec.Cmd {
+	return c.prepare(ctx, false, args, env)
+}
+
+// attached is command for output shown straight on the terminal; over ssh
+// it gets a remote terminal so prompts and 'compose exec' stay interactive.
+func (c composeRunner) attached(args []string, env []string) *exec.Cmd {
+	tty := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+	cmd := c.prepare(context.Background(), tty, args, env)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	return cmd
+}
+
+func (c composeRunner) prepare(ctx context.Context, tty bool, args []string, env []string) *exec.Cmd {
+	argv := append(append([]string{}, c.baseArgs...), args...)
+	if c.platform != "" {
+		env = append(append([]string{}, env...), platformEnvKey+"="+c.platform)
+	}
+	if ansi := composeANSI(); ansi != "" {
+		env = append(append([]string{}, env...), "COMPOSE_ANSI="+ansi)
+	}
+	if c.remote != nil {
+		return c.remote.Command(ctx, tty, env, append([]string{c.exe}, argv...)...)
+	}
+	cmd := exec.CommandContext(ctx, c.exe, argv...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return cmd
+}
+
+// docker builds a plain docker command (not compose), on the --ssh host
+// when there is one.
+func (c composeRunner) docker(ctx context.Context, args ...string) *exec.Cmd {
+	if c.remote != nil {
+		return c.remote.Command(ctx, false, nil, append([]string{"docker"}, args...)...)
+	}
+	return exec.CommandContext(ctx, "docker", args...)
+}
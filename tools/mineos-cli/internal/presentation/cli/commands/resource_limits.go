@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+)
+
+// resourceLimitsFile is the compose override with the memory and CPU limits
+// of the api and web containers. install and reconfigure write it from the
+// .env keys below, and composeFiles adds it while any of them is set.
+const resourceLimitsFile = "docker-compose.limits.yml"
+
+const (
+	apiMemoryLimitKey = "MINEOS_API_MEMORY_LIMIT"
+	apiCpuLimitKey    = "MINEOS_API_CPU_LIMIT"
+	webMemoryLimitKey = "MINEOS_WEB_MEMORY_LIMIT"
+	webCpuLimitKey    = "MINEOS_WEB_CPU_LIMIT"
+)
+
+// resourceLimits are compose values: memory like 512m or 2g, CPUs like 1.5.
+// Empty means unlimited.
+type resourceLimits struct {
+	apiMemory string
+	apiCpus   string
+	webMemory string
+	webCpus   string
+}
+
+func resourceLimitsFromConfig(cfg config.Config) resourceLimits {
+	return resourceLimits{
+		apiMemory: strings.TrimSpace(cfg.ApiMemoryLimit),
+		apiCpus:   strings.TrimSpace(cfg.ApiCpuLimit),
+		webMemory: strings.TrimSpace(cfg.WebMemoryLimit),
+		webCpus:   strings.TrimSpace(cfg.WebCpuLimit),
+	}
+}
+
+func (l resourceLimits) empty() bool {
+	return l == resourceLimits{}
+}
+
+// envValues are the .env keys for l, empty ones included so a removed
+// limit is cleared.
+func (l resourceLimits) envValues() [][2]string {
+	return [][2]string{
+		{apiMemoryLimitKey, l.apiMemory},
+		{apiCpuLimitKey, l.apiCpus},
+		{webMemoryLimitKey, l.webMemory},
+		{webCpuLimitKey, l.webCpus},
+	}
+}
+
+var memoryLimitPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?[kmg]?b?$`)
+
+func (l resourceLimits) validate() error {
+	for _, memory := range []struct{ flag, value string }{{"--api-memory", l.apiMemory}, {"--web-memory", l.webMemory}} {
+		if memory.value != "" && !memoryLimitPattern.MatchString(strings.ToLower(memory.value)) {
+			return fmt.Errorf("%s: %q is not a memory size like 512m or 2g", memory.flag, memory.value)
+		}
+	}
+	for _, cpus := range []struct{ flag, value string }{{"--api-cpus", l.apiCpus}, {"--web-cpus", l.webCpus}} {
+		if cpus.value == "" {
+			continue
+		}
+		if parsed, err := strconv.ParseFloat(cpus.value, 64); err != nil || parsed <= 0 {
+			return fmt.Errorf("%s: %q is not a CPU count like 0.5 or 2", cpus.flag, cpus.value)
+		}
+	}
+	return nil
+}
+
+// renderResourceLimits is the override for l, with only the limits set.
+func renderResourceLimits(l resourceLimits) string {
+	builder := &strings.Builder{}
+	builder.WriteString("# Written by mineos install and reconfigure from the MINEOS_*_LIMIT keys in\n")
+	builder.WriteString("# .env; change the limits there, this file is overwritten.\n")
+	builder.WriteString("services:\n")
+	for _, service := range []struct{ name, memory, cpus string }{{"api", l.apiMemory, l.apiCpus}, {"web", l.webMemory, l.webCpus}} {
+		if service.memory == "" && service.cpus == "" {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("  %s:\n    deploy:\n      resources:\n        limits:\n", service.name))
+		if service.memory != "" {
+			builder.WriteString(fmt.Sprintf("          memory: %s\n", strings.ToLower(service.memory)))
+		}
+		if service.cpus != "" {
+			builder.WriteString(fmt.Sprintf("          cpus: %q\n", service.cpus))
+		}
+	}
+	return builder.String()
+}
+
+// writeResourceLimitsFile writes the override into dir, or removes it when
+// no limit is set.
+func writeResourceLimitsFile(dir string, l resourceLimits) error {
+	path := filepath.Join(dir, resourceLimitsFile)
+	if l.empty() {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(path, []byte(renderResourceLimits(l)), 0o644)
+}
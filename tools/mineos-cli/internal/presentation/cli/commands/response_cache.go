@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/catalog"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/responsecache"
+)
+
+// NoCacheEnv turns off cached responses like --no-cache.
+const NoCacheEnv = "MINEOS_NO_CACHE"
+
+// noCache is set for the whole run by the root command: cached responses
+// are not used, though the fresh ones still replace them.
+var noCache bool
+
+// noCacheRequested reports whether the flag or MINEOS_NO_CACHE asks for
+// fresh responses.
+func noCacheRequested(flag bool) bool {
+	if flag {
+		return true
+	}
+	enabled, err := strconv.ParseBool(os.Getenv(NoCacheEnv))
+	return err == nil && enabled
+}
+
+// How long cached responses are used. Server names change rarely and are
+// forgotten when a command creates or deletes a server; the catalogs only
+// change with a Minecraft release.
+const (
+	serverNamesTTL = 30 * time.Second
+	profilesTTL    = 5 * time.Minute
+	catalogTTL     = time.Hour
+)
+
+// Cache key prefixes, one per kind of response.
+const (
+	cacheServers  = "servers:"
+	cacheProfiles = "profiles:"
+	cacheCatalog  = "catalog:"
+)
+
+var responses = responsecache.NewStore(responsecache.DefaultDir())
+
+// cacheChanges lists the commands that change cached responses, by command
+// path. What they change is forgotten once they have run, whether or not
+// they succeeded.
+var cacheChanges = map[string][]string{
+	"apply":                 {cacheServers, cacheProfiles},
+	"migrate import":        {cacheServers},
+	"profiles build-custom": {cacheProfiles},
+	"profiles delete":       {cacheProfiles},
+	"servers create":        {cacheServers, cacheProfiles},
+	"servers delete":        {cacheServers},
+	"servers import":        {cacheServers},
+	"servers undelete":      {cacheServers},
+	"smoke-test":            {cacheServers, cacheProfiles},
+}
+
+// ranCommand is the command the root command ran, for forgetChanged.
+var ranCommand *cobra.Command
+
+// cached returns the value stored under key when it is younger than ttl,
+// and otherwise fetches and stores it.
+func cached[T any](key string, ttl time.Duration, fetch func() (T, error)) (T, error) {
+	var value T
+	if !noCache && responses.Get(key, ttl, &value) {
+		return value, nil
+	}
+	value, err := fetch()
+	if err == nil {
+		responses.Put(key, value)
+	}
+	return value, err
+}
+
+// forgetCached drops the cached responses of one kind, e.g. after a server
+// is created.
+func forgetCached(prefix string) {
+	_ = responses.DeletePrefix(prefix)
+}
+
+// forgetChanged forgets what the command that ran may have changed.
+func forgetChanged() {
+	if ranCommand == nil {
+		return
+	}
+	path := strings.TrimSpace(strings.TrimPrefix(ranCommand.CommandPath(), ranCommand.Root().Name()))
+	for _, prefix := range cacheChanges[path] {
+		forgetCached(prefix)
+	}
+}
+
+// cacheScope tells installations apart: the --ssh host, whose tunnel port
+// changes every session, or the API URL.
+func cacheScope(cfg config.Config) string {
+	if sshRemote != nil {
+		return "ssh://" + sshRemote.String() + "/" + sshRemote.Dir
+	}
+	return cfg.ApiURL()
+}
+
+func cachedServerNames(ctx context.Context, cfg config.Config, client *api.Client) ([]string, error) {
+	return cached(cacheServers+cacheScope(cfg), serverNamesTTL, func() ([]string, error) {
+		servers, err := client.ListServers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return serverNames(servers), nil
+	})
+}
+
+func cachedProfiles(ctx context.Context, cfg config.Config, client *api.Client) ([]ports.Profile, error) {
+	return cached(cacheProfiles+cacheScope(cfg), profilesTTL, func() ([]ports.Profile, error) {
+		return client.ListProfiles(ctx)
+	})
+}
+
+func cachedCatalog(ctx context.Context, software string) (catalog.Catalog, error) {
+	software = strings.ToLower(strings.TrimSpace(software))
+	if software == "" {
+		software = catalog.Vanilla
+	}
+	return cached(cacheCatalog+software, catalogTTL, func() (catalog.Catalog, error) {
+		return catalog.NewClient().Versions(ctx, software)
+	})
+}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/ssh"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/presentation/cli/i18n"
 )
 
 type RootDeps struct {
@@ -21,6 +23,15 @@ type RootDeps struct {
 
 func NewRootCommand(deps RootDeps) *cobra.Command {
 	var envPath string
+	var envOverlays []string
+	var limitRate string
+	var sshTarget string
+	var sshDir string
+	var lang string
+	var accessible bool
+	var color string
+	var quiet bool
+	var noCacheFlag bool
 
 	cmd := &cobra.Command{
 		Use:   "mineos",
@@ -29,10 +40,55 @@ func NewRootCommand(deps RootDeps) *cobra.Command {
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			return cmd.Help()
 		},
-		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			if envPath != "" {
 				deps.ConfigRepo.SetPath(envPath)
 			}
+			deps.ConfigRepo.SetOverlays(envOverlays)
+			mode, err := colorRequested(color)
+			if err != nil {
+				return fmt.Errorf("--color: %w", err)
+			}
+			applyColor(mode)
+			ranCommand = cmd
+			startOperation(envPath)
+			noCache = noCacheRequested(noCacheFlag)
+			if noCache {
+				os.Setenv(NoCacheEnv, "1")
+			}
+			quietMode = quietRequested(quiet)
+			if quietMode {
+				os.Setenv(QuietEnv, "1")
+			}
+			accessibleMode = accessibleRequested(accessible)
+			if accessibleMode {
+				// Commands the TUI runs print accessible output as well.
+				os.Setenv(AccessibleEnv, "1")
+			}
+			if lang != "" {
+				chosen, err := i18n.Set(lang)
+				if err != nil {
+					return fmt.Errorf("--lang: %w", err)
+				}
+				// Commands the TUI runs speak the same language.
+				os.Setenv(i18n.LangEnv, chosen)
+			}
+			startTranscript(cmd)
+			if err := setTransferRate(limitRate); err != nil {
+				return fmt.Errorf("--limit-rate: %w", err)
+			}
+			if machineMode {
+				if err := prepareMachineCommand(cmd); err != nil {
+					return err
+				}
+			}
+			if sshTarget != "" && !sshSkipConnect(cmd) {
+				// The remote .env was checked while connecting.
+				if err := connectRemote(cmd.Context(), cmd, deps.ConfigRepo, sshTarget, sshDir, envOverlays); err != nil {
+					return err
+				}
+				return resolveServerArgs(cmd, args, deps.LoadConfig)
+			}
 
 			// Skip .env check for commands that don't need it (or can help bootstrap an install).
 			skipEnvCheck := cmd.Name() == "mineos" ||
@@ -41,6 +97,26 @@ func NewRootCommand(deps RootDeps) *cobra.Command {
 				cmd.Name() == "update" ||
 				cmd.Name() == "upgrade" ||
 				cmd.Name() == "version" ||
+				cmd.Name() == "versions" ||
+				cmd.Name() == "completion" ||
+				cmd.Name() == cobra.ShellCompRequestCmd ||
+				cmd.Name() == cobra.ShellCompNoDescRequestCmd ||
+				cmd.Name() == "ping" ||
+				cmd.Name() == "fleet" ||
+				(cmd.Parent() != nil && cmd.Parent().Name() == "state") ||
+				(cmd.Parent() != nil && cmd.Parent().Name() == "fleet") ||
+				cmd.Name() == "tune" ||
+				cmd.Name() == "record" ||
+				cmd.Name() == "replay" ||
+				(cmd.Parent() != nil && cmd.Parent().Name() == "i18n") ||
+				(cmd.Name() == "test" && cmd.Parent() != nil && cmd.Parent().Name() == "network") ||
+				(cmd.Name() == "scan" && cmd.Parent() != nil && cmd.Parent().Name() == "migrate") ||
+				(cmd.Name() == "analyze" && cmd.Flags().Changed("dir")) ||
+				(cmd.Parent() != nil && cmd.Parent().Name() == "world" && cmd.Flags().Changed("dir")) ||
+				(cmd.Parent() != nil && cmd.Parent().Name() == "nbt" && !cmd.Flags().Changed("server")) ||
+				cmd.Name() == "plugins" ||
+				(cmd.Parent() != nil && cmd.Parent().Name() == "plugins") ||
+				cmd.Annotations[pluginAnnotation] != "" ||
 				cmd.Name() == "help"
 			if skipEnvCheck {
 				return nil
@@ -53,31 +129,73 @@ func NewRootCommand(deps RootDeps) *cobra.Command {
 			}
 			if _, err := os.Stat(effectivePath); os.IsNotExist(err) {
 				pwd, _ := os.Getwd()
-				msg := fmt.Sprintf("\n.env file not found at: %s\n\n", effectivePath)
-				msg += "MineOS is not installed in this directory.\n\n"
-				msg += "To install MineOS, run:\n"
+				msg := "\n" + i18n.T("env.missing.title", effectivePath) + "\n\n"
+				msg += i18n.T("env.missing.notInstalled") + "\n\n"
+				msg += i18n.T("env.missing.install") + "\n"
 				msg += "  mineos install\n\n"
-				msg += "If MineOS is installed elsewhere:\n"
-				msg += "  1. Navigate to the installation directory, OR\n"
-				msg += fmt.Sprintf("  2. Use --env flag: mineos --env /path/to/.env %s\n\n", cmd.Name())
-				msg += fmt.Sprintf("Current directory: %s\n", pwd)
+				msg += i18n.T("env.missing.elsewhere") + "\n"
+				msg += "  " + i18n.T("env.missing.navigate") + "\n"
+				msg += "  " + i18n.T("env.missing.flag", "mineos --env /path/to/.env "+cmd.Name()) + "\n\n"
+				msg += i18n.T("env.missing.cwd", pwd) + "\n"
 				return errors.New(msg)
 			}
 
-			return nil
+			return resolveServerArgs(cmd, args, deps.LoadConfig)
 		},
 	}
 
 	cmd.PersistentFlags().StringVar(&envPath, "env", ".env", "Path to the MineOS .env file")
+	cmd.PersistentFlags().StringVar(&limitRate, "limit-rate", defaultTransferRate(), "Cap downloads and uploads, e.g. 500K or 5M per second (or set "+RateLimitEnv+")")
+	cmd.PersistentFlags().BoolVar(&utcTimes, "utc", false, "Show log timestamps in UTC instead of the local time zone")
+	cmd.PersistentFlags().StringVar(&sshTarget, "ssh", "", "Manage MineOS on another host over ssh: user@host[:port]; the API is reached through a tunnel")
+	cmd.PersistentFlags().StringVar(&sshDir, "ssh-dir", ssh.DefaultDir, "MineOS install directory on the --ssh host, relative to the home directory")
+	cmd.PersistentFlags().StringVar(&lang, "lang", "", "Language of prompts and messages, e.g. de or pt-BR (default: from "+i18n.LangEnv+" or LANG)")
+	cmd.PersistentFlags().StringVar(&color, "color", colorAuto, "When to color output: auto (on a terminal), always or never (NO_COLOR also turns it off)")
+	cmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print results and errors only: no spinners, progress or summary lines (or set "+QuietEnv+"=1)")
+	cmd.PersistentFlags().BoolVar(&noCacheFlag, "no-cache", false, "Ask the API and catalogs again instead of using responses cached for completion (or set "+NoCacheEnv+"=1)")
+	cmd.PersistentFlags().BoolVar(&accessible, "accessible", false, "Screen reader friendly output: plain text instead of symbols, boxes and redrawn lines (or set "+AccessibleEnv+"=1)")
+	cmd.PersistentFlags().StringArrayVar(&envOverlays, "env-overlay", nil, "Env file layered over .env and .env.local (repeatable, later files win)")
+	// Read by Execute before flags are parsed; registered so cobra accepts it.
+	cmd.PersistentFlags().Bool("machine", false, "Print a single JSON document, never prompt and disable styling (or set "+MachineEnv+"=1)")
 
+	cmd.AddCommand(NewAgentCommand(deps.LoadConfig))
+	cmd.AddCommand(NewApiCommand(deps.LoadConfig))
 	cmd.AddCommand(NewApiKeyCommand(deps.LoadConfig))
+	cmd.AddCommand(NewUsersCommand(deps.LoadConfig))
+	cmd.AddCommand(NewDbCommand(deps.LoadConfig))
+	cmd.AddCommand(NewApplyCommand(deps.LoadConfig))
+	cmd.AddCommand(NewBackupCommand(deps.LoadConfig))
 	cmd.AddCommand(NewConfigCommand(deps.LoadConfig))
+	cmd.AddCommand(NewConfirmCommand(deps.LoadConfig))
+	cmd.AddCommand(NewDiffCommand(deps.LoadConfig))
+	cmd.AddCommand(NewDiscordBotCommand(deps.LoadConfig))
+	cmd.AddCommand(NewStatusPageCommand(deps.LoadConfig))
+	cmd.AddCommand(NewTuneCommand(deps.LoadConfig))
+	cmd.AddCommand(NewEnvCommand(deps.LoadConfig))
+	cmd.AddCommand(NewExportBootstrapCommand(deps.LoadConfig, deps.Version))
+	cmd.AddCommand(NewFleetCommand())
+	cmd.AddCommand(NewStateCommand(deps.LoadConfig))
+	cmd.AddCommand(NewGeyserCommand(deps.LoadConfig))
 	cmd.AddCommand(NewHealthCommand(deps.LoadConfig))
+	cmd.AddCommand(NewI18nCommand())
 	cmd.AddCommand(NewInteractiveCommand(deps.LoadConfig))
 	cmd.AddCommand(NewInstallCommand())
 	// Default logs for installation management: docker compose logs.
 	cmd.AddCommand(NewDockerLogsCommand(deps.LoadConfig))
+	cmd.AddCommand(NewJavaCommand(deps.LoadConfig))
+	cmd.AddCommand(NewMigrateCommand(deps.LoadConfig))
+	cmd.AddCommand(NewNbtCommand(deps.LoadConfig))
+	cmd.AddCommand(NewNetworkCommand(deps.LoadConfig))
+	cmd.AddCommand(NewPingCommand(deps.LoadConfig))
+	cmd.AddCommand(NewPlayersCommand(deps.LoadConfig))
+	cmd.AddCommand(NewPluginsCommand())
+	cmd.AddCommand(NewProfilesCommand(deps.LoadConfig))
+	cmd.AddCommand(NewQuarantineCommand(deps.LoadConfig))
+	cmd.AddCommand(NewSecurityCommand(deps.LoadConfig))
+	cmd.AddCommand(NewProxyCommand(deps.LoadConfig))
 	cmd.AddCommand(NewReconfigureCommand(deps.LoadConfig))
+	cmd.AddCommand(NewRecordCommand())
+	cmd.AddCommand(NewReplayCommand())
 	cmd.AddCommand(NewStartCommand(deps.LoadConfig))
 	cmd.AddCommand(NewStopCommand(deps.LoadConfig))
 	cmd.AddCommand(NewRestartCommand(deps.LoadConfig))
@@ -87,11 +205,17 @@ func NewRootCommand(deps RootDeps) *cobra.Command {
 	cmd.AddCommand(NewStackCommand(deps.LoadConfig))
 	cmd.AddCommand(NewTuiCommand(deps.LoadConfig, deps.Version))
 	cmd.AddCommand(NewServersCommand(deps.LoadConfig))
+	cmd.AddCommand(NewSmokeTestCommand(deps.LoadConfig))
 	cmd.AddCommand(NewStatusCommand(deps.LoadConfig))
+	cmd.AddCommand(NewTopCommand(deps.LoadConfig))
 	cmd.AddCommand(NewUninstallCommand())
 	cmd.AddCommand(NewUpdateCommand(deps.LoadConfig, deps.Version))
 	cmd.AddCommand(NewUpgradeCommand(deps.Version))
 	cmd.AddCommand(NewVersionCommand(deps.Version))
+	cmd.AddCommand(NewVersionsCommand(deps.LoadConfig))
+	cmd.AddCommand(NewWorldCommand(deps.LoadConfig))
+	addPluginCommands(cmd, deps)
+	addServerCompletions(cmd, deps.LoadConfig)
 
 	return cmd
 }
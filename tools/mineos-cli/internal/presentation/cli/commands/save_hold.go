@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+// holdSaves pauses saving on a running server for a backup of it and
+// returns the func that resumes it; see usecases.SaveHoldUseCase. With skip
+// set the server keeps saving.
+func holdSaves(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, out io.Writer, name string, skip bool) (func() error, error) {
+	noop := func() error { return nil }
+	if skip {
+		fmt.Fprintln(out, styleDim.Render("Not pausing saves on "+name+"; files it writes during the backup may be captured mid-write."))
+		return noop, nil
+	}
+	opts := saveHoldOptions(out)
+	var hold *usecases.SaveHold
+	_, err := withApiKeyRetry(ctx, loadConfig, out, func(_ config.Config, client *api.Client) error {
+		var err error
+		hold, err = usecases.NewSaveHoldUseCase(client, consoleFollower(client)).Hold(ctx, name, opts)
+		return err
+	})
+	if err != nil {
+		return noop, fmt.Errorf("%w (--no-save-hold backs up without pausing saves)", err)
+	}
+	return func() error { return hold.Release(context.WithoutCancel(ctx)) }, nil
+}
+
+// saveHoldOptions reports the phases of a save hold on out.
+func saveHoldOptions(out io.Writer) usecases.SaveHoldOptions {
+	return usecases.SaveHoldOptions{
+		OnPhase: func(server, phase string) {
+			switch phase {
+			case usecases.SaveHolding:
+				fmt.Fprintln(out, styleDim.Render("Pausing saves on "+server+" and flushing the world..."))
+			case usecases.SaveFlushed:
+				fmt.Fprintf(out, "%s %s flushed to disk\n", markOK(), server)
+			case usecases.SaveReleased:
+				fmt.Fprintln(out, styleDim.Render("Saving resumed on "+server+"."))
+			}
+		},
+	}
+}
+
+// consoleFollower streams a server's log through client, stamping lines
+// with the time in the log line when it has one.
+func consoleFollower(client *api.Client) usecases.ConsoleFollower {
+	return func(ctx context.Context, server string, onLine func(time.Time, string)) error {
+		normalizer := logTimes()
+		logs, errs := client.StreamConsoleLogs(ctx, server, "server")
+		for {
+			select {
+			case entry, ok := <-logs:
+				if !ok {
+					return nil
+				}
+				stamp, _ := normalizer.MinecraftEntry(entry.Timestamp, entry.Message, time.Now())
+				onLine(stamp, entry.Message)
+			case err, ok := <-errs:
+				if ok && err != nil {
+					return err
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// warnUnreleased reports a failed release, which leaves the server not
+// saving until it is told to.
+func warnUnreleased(out io.Writer, name string, err error) {
+	if err != nil {
+		fmt.Fprintln(out, styleWarning.Render("Could not resume saving; run 'mineos servers console "+name+" save-on': "+err.Error()))
+	}
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/security"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/upstream"
+)
+
+func NewSecurityCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "security",
+		Short: "Check servers for known vulnerabilities",
+	}
+
+	cmd.AddCommand(newSecurityScanCommand(loadConfig))
+
+	return cmd
+}
+
+func newSecurityScanCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var jsonOut bool
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "scan [server...]",
+		Short: "Check Minecraft and log4j versions against known CVEs",
+		Long: `Check each server's Minecraft version and the log4j it bundles against
+Log4Shell (CVE-2021-44228 and CVE-2021-45046). Minecraft 1.7 to 1.18 is
+affected unless the server starts with the mitigation Mojang published:
+-Dlog4j2.formatMsgNoLookups=true for 1.17 and 1.18, or a patched log4j
+configuration file for older versions. Paper builds from after the fix bundle
+a patched log4j.
+
+For Paper servers the scan also lists newer builds of the same version whose
+changes mention security fixes.
+
+The Minecraft version comes from the server jar name or profile. Exits with
+status 1 when a server is vulnerable, or with --strict when one is outdated or
+could not be checked too.`,
+		Example: `  mineos security scan
+  mineos security scan survival creative --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			var reports []security.Report
+			if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				var err error
+				reports, err = usecases.NewSecurityScanUseCase(client, upstream.NewClient()).Execute(ctx, args)
+				return err
+			}); err != nil {
+				return err
+			}
+
+			if jsonOut {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(reports); err != nil {
+					return err
+				}
+			} else {
+				printSecurityReports(cmd.OutOrStdout(), reports)
+			}
+
+			for _, report := range reports {
+				worst := report.Worst()
+				if worst == security.Vulnerable || (strict && (worst == security.Outdated || worst == security.Unknown)) {
+					cmd.SilenceErrors = true
+					cmd.SilenceUsage = true
+					return exitCodeError{code: exitFailure}
+				}
+			}
+			return nil
+		},
+	}
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		names, _ := listServerNames(cmd, loadConfig)
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Also fail on outdated Paper builds and servers that could not be checked")
+
+	return cmd
+}
+
+func printSecurityReports(out io.Writer, reports []security.Report) {
+	if len(reports) == 0 {
+		fmt.Fprintln(out, "No servers to scan.")
+		return
+	}
+
+	vulnerable := 0
+	for i, report := range reports {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		about := []string{fallback(report.Software, "unknown software"), fallback(report.Version, "unknown version")}
+		if report.Log4j != "" {
+			about = append(about, "log4j "+report.Log4j)
+		}
+		fmt.Fprintf(out, "%s %s\n", styleTitle.Render(report.Server), styleDim.Render("("+strings.Join(about, ", ")+")"))
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, finding := range report.Findings {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", securityStatusLabel(finding.Status), finding.Check, finding.Detail)
+		}
+		w.Flush()
+		for _, finding := range report.Findings {
+			if finding.Fix != "" {
+				fmt.Fprintln(out, styleDim.Render("  Fix: "+strings.ReplaceAll(finding.Fix, "<server>", report.Server)))
+			}
+		}
+		if report.Worst() == security.Vulnerable {
+			vulnerable++
+		}
+	}
+
+	if vulnerable > 0 {
+		fmt.Fprintf(out, "\n%s %d of %d server(s) vulnerable. Apply the fix and restart them.\n", markFailed(), vulnerable, len(reports))
+		return
+	}
+	fmt.Fprintf(out, "\n%s No vulnerable servers among %d scanned.\n", markOK(), len(reports))
+}
+
+func securityStatusLabel(status security.Status) string {
+	switch status {
+	case security.Vulnerable:
+		return styleError.Render("VULNERABLE")
+	case security.Outdated:
+		return styleWarning.Render("outdated")
+	case security.Mitigated:
+		return styleSuccess.Render("mitigated")
+	case security.OK:
+		return styleSuccess.Render("ok")
+	default:
+		return styleDim.Render("unknown")
+	}
+}
@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/runstate"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+func NewServerAutostartCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "autostart [<server> [on|off]]",
+		Short: "Show or set which servers start with the stack",
+		Long: `Show or set each server's start-on-boot policy. The API starts servers
+whose policy is on whenever it starts, after 'stack up' and after a host
+reboot alike.
+
+Servers that were running when 'mineos stack stop' (or down/restart) ran are
+remembered in ` + runstate.DefaultFileName + ` in the installation's state directory
+(` + state.InstallationDirName + `/ next to .env) and started again by the next
+'mineos stack up', whatever their policy. 'mineos stack service install'
+does the same across host reboots.`,
+		Example: `  mineos servers autostart
+  mineos servers autostart survival on`,
+		Args: cobra.RangeArgs(0, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+			return runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				switch len(args) {
+				case 0:
+					return printAutostart(ctx, client, out)
+				case 1:
+					serverCfg, err := client.GetServerConfig(ctx, args[0])
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(out, "%s: %s\n", args[0], onOff(serverCfg.OnReboot.Start))
+					return nil
+				}
+				var enabled bool
+				switch strings.ToLower(args[1]) {
+				case "on", "true", "yes":
+					enabled = true
+				case "off", "false", "no":
+				default:
+					return fmt.Errorf("invalid policy %q (use on or off)", args[1])
+				}
+				serverCfg, err := client.GetServerConfig(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				serverCfg.OnReboot.Start = enabled
+				if err := client.UpdateServerConfig(ctx, args[0], serverCfg); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "%s Autostart for %s is %s.\n", markOK(), args[0], onOff(enabled))
+				return nil
+			})
+		},
+	}
+	return cmd
+}
+
+func printAutostart(ctx context.Context, client *api.Client, out io.Writer) error {
+	servers, err := client.ListServers(ctx)
+	if err != nil {
+		return err
+	}
+	if len(servers) == 0 {
+		fmt.Fprintln(out, "No servers found.")
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tSTATUS\tAUTOSTART")
+	for _, server := range servers {
+		policy := "?"
+		if serverCfg, err := client.GetServerConfig(ctx, server.Name); err == nil {
+			policy = onOff(serverCfg.OnReboot.Start)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", server.Name, server.Status, policy)
+	}
+	return w.Flush()
+}
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
+
+// recordRunningServers saves which servers are running before a stack stop.
+// Under --ssh the .env is a temporary copy, so there is nowhere to keep it.
+func recordRunningServers(cfg config.Config, servers []ports.Server, out io.Writer) {
+	if sshRemote != nil {
+		return
+	}
+	state := runstate.State{StoppedAt: time.Now().UTC(), Servers: []string{}}
+	for _, server := range servers {
+		if strings.EqualFold(server.Status, "running") {
+			state.Servers = append(state.Servers, server.Name)
+		}
+	}
+	if err := runstate.NewFileRepositoryForEnv(resolveEnvPath(cfg.EnvPath)).Save(state); err != nil {
+		fmt.Fprintf(out, "Warning: could not record the running servers: %v\n", err)
+	}
+}
+
+// restoreRunningServers starts the servers recorded at the last stack stop.
+// Servers with autostart on are left to the API, which starts them itself.
+func restoreRunningServers(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, out io.Writer) error {
+	if sshRemote != nil {
+		return nil
+	}
+	cfg, err := loadConfig.Execute(ctx)
+	if err != nil {
+		return err
+	}
+	repo := runstate.NewFileRepositoryForEnv(resolveEnvPath(cfg.EnvPath))
+	state, found, err := repo.Load()
+	if err != nil || !found {
+		return err
+	}
+	if len(state.Servers) == 0 {
+		return repo.Remove()
+	}
+
+	_, err = withApiKeyRetry(ctx, loadConfig, out, func(_ config.Config, client *api.Client) error {
+		var names []string
+		for _, name := range state.Servers {
+			serverCfg, err := client.GetServerConfig(ctx, name)
+			if err != nil || serverCfg.OnReboot.Start {
+				// Deleted since, or the API's to start.
+				continue
+			}
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			return nil
+		}
+		fmt.Fprintf(out, "Starting the servers that were running before the stack stopped: %s\n", strings.Join(names, ", "))
+		var failed []string
+		usecases.NewBulkServerActionUseCase(client).Ensure(ctx, names, "start", 2, func(result ports.BulkActionResult) {
+			switch {
+			case result.Err != nil:
+				failed = append(failed, result.Name)
+				fmt.Fprintf(out, "  %s %s: %v\n", markFailed(), result.Name, result.Err)
+			case result.Unchanged:
+				fmt.Fprintf(out, "  %s %s already running\n", styleDim.Render("·"), result.Name)
+			default:
+				fmt.Fprintf(out, "  %s %s\n", markOK(), result.Name)
+			}
+		})
+		if len(failed) > 0 {
+			return fmt.Errorf("failed to start %s", strings.Join(failed, ", "))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return repo.Remove()
+}
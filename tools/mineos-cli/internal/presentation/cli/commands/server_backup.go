@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+func NewServerBackupCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var noWait bool
+	var noSaveHold bool
+
+	cmd := &cobra.Command{
+		Use:   "backup <server>",
+		Short: "Create an incremental backup of a server",
+		Long: `Create an incremental backup of a server with the API. A running server
+is told to stop saving and flush its world first, and saving is turned back
+on once the backup is done, so it never holds a half-written region file.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			ctx := context.Background()
+			return runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				if noWait {
+					jobID, err := client.CreateBackup(ctx, name)
+					if err != nil {
+						return err
+					}
+					cmd.Printf("Backup queued for %s (job %s)\n", name, jobID)
+					return nil
+				}
+				t := trackTask(cmd.OutOrStdout(), "Back up "+name)
+				backup := func(ctx context.Context) error {
+					jobID, err := client.CreateBackup(ctx, name)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(decor(cmd.OutOrStdout()), "Backing up %s...\n", name)
+					return waitForJob(ctx, client, cmd.OutOrStdout(), jobID)
+				}
+				var err error
+				if noSaveHold {
+					err = backup(ctx)
+				} else {
+					err = usecases.NewSaveHoldUseCase(client, consoleFollower(client)).Around(ctx, name, saveHoldOptions(cmd.OutOrStdout()), backup)
+				}
+				if err != nil {
+					t.Fail()
+					return err
+				}
+				t.Done("")
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&noWait, "no-wait", false, "Return once the backup is queued, without pausing saves")
+	cmd.Flags().BoolVar(&noSaveHold, "no-save-hold", false, "Back up a running server without pausing its saves")
+
+	return cmd
+}
+
+func NewServerRestoreCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var at string
+	var list bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:         "restore <server>",
+		Annotations: map[string]string{destructiveAnnotation: "true"},
+		Short:       "Restore a server's files from a backup",
+		Long: `Restore a server's files, worlds included, from its latest backup or the
+one taken at --at. Anything changed since that backup is lost; take a new
+backup first to keep it.
+
+The server's name has to be typed to confirm; with ` + ConfirmLevelEnv + `=yes
+in .env a y/n is asked instead.`,
+		Example: `  mineos servers restore survival --list
+  mineos servers restore survival
+  mineos servers restore survival --at 2026-10-12T04:00:00Z`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+			_, err := withApiKeyRetry(ctx, loadConfig, out, func(cfg config.Config, client *api.Client) error {
+				backups, err := client.ListBackups(ctx, name)
+				if err != nil {
+					return err
+				}
+				if len(backups) == 0 {
+					return ports.WithKind(fmt.Errorf("%s has no backups; create one with 'mineos servers backup %s'", name, name), ports.ErrNotFound)
+				}
+				if list {
+					for _, backup := range backups {
+						size := ""
+						if backup.Size != nil {
+							size = formatBytes(*backup.Size)
+						}
+						fmt.Fprintf(out, "  %s  %s\n", backup.Time.UTC().Format(time.RFC3339), styleDim.Render(size))
+					}
+					return nil
+				}
+
+				timestamp := at
+				if timestamp == "" {
+					timestamp = backups[0].Time.UTC().Format(time.RFC3339)
+				}
+				if !yes {
+					detail, err := client.GetServer(ctx, name)
+					if err != nil {
+						return err
+					}
+					warning := fmt.Sprintf("This replaces the files of %s with the backup from %s.", name, timestamp)
+					if detail.IsRunning() {
+						warning = fmt.Sprintf("%s is running: its live world is overwritten with the backup from %s.", name, timestamp)
+					}
+					if err := confirmServerOperation(cfg, out, name, "restore", warning); err != nil {
+						return err
+					}
+				}
+				return runTask(out, "Restore "+name+" to "+timestamp, func(io.Writer) error {
+					return client.RestoreBackup(ctx, name, timestamp)
+				})
+			})
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&at, "at", "", "Restore the backup taken at this time (default the latest)")
+	cmd.Flags().BoolVar(&list, "list", false, "List the backups instead of restoring")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Restore without asking for confirmation")
+
+	return cmd
+}
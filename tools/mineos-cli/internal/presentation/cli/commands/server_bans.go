@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/bans"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/mojang"
+)
+
+func NewServerBanCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var all bool
+	var tag string
+	var reason string
+
+	cmd := &cobra.Command{
+		Use:   "ban <name|pattern>... <player>",
+		Short: "Ban a player on one or more servers",
+		Long: `Ban a player on the selected servers.
+
+Running servers receive a console "ban" command; stopped servers have
+banned-players.json edited so the ban applies on their next start.
+Bedrock servers are skipped.`,
+		Example: `  mineos servers ban survival Griefer123 --reason "x-ray"
+  mineos servers ban --all Griefer123
+  mineos servers ban --tag network Griefer123
+  mineos servers ban list survival
+  mineos servers ban sync --tag network`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			player := args[len(args)-1]
+			selector := usecases.ServerSelector{All: all, Tag: tag, Patterns: args[:len(args)-1]}
+			return runBanChange(cmd, loadConfig, selector, func(ctx context.Context, uc *usecases.BansUseCase, name string) usecases.BanResult {
+				return uc.Ban(ctx, name, player, reason)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Ban on every server")
+	cmd.Flags().StringVar(&tag, "tag", "", "Ban on servers carrying this tag")
+	cmd.Flags().StringVar(&reason, "reason", "", "Reason shown to the player")
+
+	cmd.AddCommand(newServerBanListCommand(loadConfig))
+	cmd.AddCommand(newServerBanSyncCommand(loadConfig))
+
+	return cmd
+}
+
+func NewServerPardonCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var all bool
+	var tag string
+
+	cmd := &cobra.Command{
+		Use:   "pardon <name|pattern>... <player>",
+		Short: "Lift a player's ban on one or more servers",
+		Example: `  mineos servers pardon survival Griefer123
+  mineos servers pardon --all Griefer123`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			player := args[len(args)-1]
+			selector := usecases.ServerSelector{All: all, Tag: tag, Patterns: args[:len(args)-1]}
+			return runBanChange(cmd, loadConfig, selector, func(ctx context.Context, uc *usecases.BansUseCase, name string) usecases.BanResult {
+				return uc.Pardon(ctx, name, player)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Pardon on every server")
+	cmd.Flags().StringVar(&tag, "tag", "", "Pardon on servers carrying this tag")
+
+	return cmd
+}
+
+func newServerBanListCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list <server>",
+		Short: "Show a server's ban list",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			var list bans.List
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+				var err error
+				list, err = usecases.NewBansUseCase(client, nil).List(ctx, args[0])
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				cmd.Printf("No players are banned on %s.\n", args[0])
+				return nil
+			}
+			list = bans.Merge(list)
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PLAYER\tREASON\tSOURCE\tCREATED\tEXPIRES")
+			for _, entry := range list {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.Name, fallback(entry.Reason, "-"), fallback(entry.Source, "-"), fallback(entry.Created, "-"), fallback(entry.Expires, bans.Forever))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newServerBanSyncCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var all bool
+	var tag string
+	var opts usecases.BanSyncOptions
+
+	cmd := &cobra.Command{
+		Use:   "sync [name|pattern]...",
+		Short: "Share one ban list across the selected servers",
+		Long: `Make the ban lists of the selected servers consistent.
+
+By default every ban found on any selected server is applied to all of
+them. With --from, that server's list is authoritative and only its bans
+are propagated; add --prune to also pardon players it does not ban.`,
+		Example: `  mineos servers ban sync --all
+  mineos servers ban sync --tag network --from lobby --prune
+  mineos servers ban sync "survival-*" --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			selector := usecases.ServerSelector{All: all, Tag: tag, Patterns: args}
+			if selector.IsEmpty() {
+				return fmt.Errorf("specify server names, a glob pattern, --all or --tag")
+			}
+			if opts.Prune && opts.From == "" {
+				return fmt.Errorf("--prune requires --from; a merged list never pardons anyone")
+			}
+
+			var want bans.List
+			var results []usecases.BanSyncResult
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+				names, err := usecases.NewSelectServersUseCase(client).Execute(ctx, selector)
+				if err != nil {
+					return err
+				}
+				if len(names) == 0 {
+					return ports.WithKind(errors.New("no servers matched the selection"), ports.ErrNotFound)
+				}
+				want, results, err = usecases.NewBansUseCase(client, lookupPlayer).Sync(ctx, names, opts)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			source := "merged from the selection"
+			if opts.From != "" {
+				source = "from " + opts.From
+			}
+			cmd.Printf("Ban list: %d player(s), %s\n", len(want), source)
+			failed := 0
+			for _, result := range results {
+				switch {
+				case result.Err != nil:
+					failed++
+					cmd.Printf("  %s %s: %v\n", styleError.Render("x"), result.Server, result.Err)
+				case result.Via == usecases.BanSkipped:
+					cmd.Printf("  %s %s %s\n", styleDim.Render("-"), result.Server, styleDim.Render(result.Note))
+				case len(result.Banned) == 0 && len(result.Pardons) == 0:
+					cmd.Printf("  %s %s %s\n", styleSuccess.Render("ok"), result.Server, styleDim.Render("already in sync"))
+				default:
+					var changes []string
+					if len(result.Banned) > 0 {
+						changes = append(changes, "ban "+strings.Join(result.Banned, ", "))
+					}
+					if len(result.Pardons) > 0 {
+						changes = append(changes, "pardon "+strings.Join(result.Pardons, ", "))
+					}
+					marker := styleSuccess.Render("ok")
+					if opts.DryRun {
+						marker = styleInfo.Render("~")
+					}
+					cmd.Printf("  %s %s %s %s\n", marker, result.Server, strings.Join(changes, "; "), styleDim.Render("("+result.Via+")"))
+				}
+			}
+			if opts.DryRun {
+				cmd.Println(styleDim.Render("Dry run: no changes were made."))
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d server(s) failed to sync", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Sync every server")
+	cmd.Flags().StringVar(&tag, "tag", "", "Sync servers carrying this tag")
+	cmd.Flags().StringVar(&opts.From, "from", "", "Use this server's ban list as the source of truth")
+	cmd.Flags().BoolVar(&opts.Prune, "prune", false, "Pardon players not banned on --from")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show what would change without applying it")
+
+	return cmd
+}
+
+// runBanChange applies a ban or pardon to every selected server and prints a
+// line per server.
+func runBanChange(cmd *cobra.Command, loadConfig *usecases.LoadConfigUseCase, selector usecases.ServerSelector, apply func(context.Context, *usecases.BansUseCase, string) usecases.BanResult) error {
+	ctx := context.Background()
+	if selector.IsEmpty() {
+		return fmt.Errorf("specify a server name, a glob pattern, --all or --tag before the player name")
+	}
+
+	var results []usecases.BanResult
+	_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+		names, err := usecases.NewSelectServersUseCase(client).Execute(ctx, selector)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return ports.WithKind(errors.New("no servers matched the selection"), ports.ErrNotFound)
+		}
+		uc := usecases.NewBansUseCase(client, lookupPlayer)
+		results = results[:0]
+		for _, name := range names {
+			results = append(results, apply(ctx, uc, name))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+			cmd.Printf("  %s %s: %v\n", styleError.Render("x"), result.Server, result.Err)
+		case result.Via == usecases.BanSkipped:
+			cmd.Printf("  %s %s %s\n", styleDim.Render("-"), result.Server, styleDim.Render(result.Note))
+		default:
+			detail := result.Via
+			if result.Note != "" {
+				detail = result.Note
+			}
+			cmd.Printf("  %s %s %s\n", styleSuccess.Render("ok"), result.Server, styleDim.Render("("+detail+")"))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d server(s) failed", failed, len(results))
+	}
+	return nil
+}
+
+func lookupPlayer(ctx context.Context, name string) (string, string, error) {
+	profile, err := mojang.NewClient().Lookup(ctx, name)
+	if err != nil {
+		return "", "", err
+	}
+	return profile.UUID, profile.Name, nil
+}
@@ -0,0 +1,215 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/console"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/consolehistory"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+func NewServerConsoleCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "console <server> [command...]",
+		Short: "Send console commands to a server",
+		Long: `Send a console command to a server, or open a console prompt when no
+command is given.
+
+At the prompt, Up and Down recall earlier commands; the history is kept per
+server in ` + consolehistory.DefaultFileName + ` in ` + state.InstallationDirName + `/ next to the .env file and is
+shared with the TUI. Tab completes common commands (gamemode, tp, give,
+whitelist, ...) and the names of players online. Replies show in
+'mineos servers logs <server>'.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			server := args[0]
+			out := cmd.OutOrStdout()
+
+			cfg, err := loadConfig.Execute(ctx)
+			if err != nil {
+				return err
+			}
+			history := consolehistory.NewFileRepositoryForEnv(resolveEnvPath(cfg.EnvPath))
+			send := func(command string) error {
+				if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+					return client.SendConsoleCommand(ctx, server, command)
+				}); err != nil {
+					return err
+				}
+				if err := history.Add(server, command); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s could not save console history: %v\n", styleWarning.Render("Warning:"), err)
+				}
+				return nil
+			}
+
+			if len(args) > 1 {
+				command := strings.Join(args[1:], " ")
+				if err := send(command); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "sent to %s: %s\n", server, command)
+				return nil
+			}
+
+			if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+				return sendConsoleLines(os.Stdin, out, server, send)
+			}
+			return runConsolePrompt(ctx, loadConfig, out, server, history, send)
+		},
+	}
+}
+
+// sendConsoleLines sends each line of a piped stdin.
+func sendConsoleLines(in io.Reader, out io.Writer, server string, send func(string) error) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		command := strings.TrimSpace(scanner.Text())
+		if command == "" {
+			continue
+		}
+		if err := send(command); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "sent to %s: %s\n", server, command)
+	}
+	return scanner.Err()
+}
+
+func runConsolePrompt(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, out io.Writer, server string, history *consolehistory.FileRepository, send func(string) error) error {
+	entries, err := history.Load(server)
+	if err != nil {
+		fmt.Fprintf(out, "%s could not read console history: %v\n", styleWarning.Render("Warning:"), err)
+	}
+	players := onlinePlayerNames(ctx, loadConfig, out, server)
+
+	fmt.Fprintf(out, "Console for %s. Tab completes, Up/Down recall, Ctrl+D or 'exit' leaves.\n", server)
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), state)
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, out}, server+"> ")
+	// ReadLine adds every line itself; the file is written by send, so only
+	// commands that went through are kept.
+	t.History = &consoleHistory{entries: entries}
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' || pos != len(line) {
+			return "", 0, false
+		}
+		completed, options := completeConsoleLine(line, players)
+		if len(options) > 1 {
+			fmt.Fprintln(t, strings.Join(options, "  "))
+		} else if usage := console.Usage(completed); len(options) == 0 && usage != "" {
+			fmt.Fprintln(t, styleDim.Render("usage: "+usage))
+		}
+		return completed, len(completed), true
+	}
+
+	for {
+		line, err := t.ReadLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		command := strings.TrimSpace(line)
+		switch command {
+		case "":
+			continue
+		case "exit", "quit":
+			return nil
+		}
+		if err := send(command); err != nil {
+			fmt.Fprintf(t, "%s %v\n", styleError.Render("error:"), err)
+		}
+	}
+}
+
+// completeConsoleLine extends line as far as its completions agree. With
+// several completions it also returns the words they would end in, for
+// listing.
+func completeConsoleLine(line string, players []string) (string, []string) {
+	candidates := console.Complete(line, players)
+	switch len(candidates) {
+	case 0:
+		return line, nil
+	case 1:
+		return candidates[0] + " ", nil
+	}
+	common := candidates[0]
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	if len(common) < len(line) {
+		// The typed word differs in case from the completions.
+		common = line
+	}
+	start := strings.LastIndexAny(line, " \t") + 1
+	options := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		options[i] = candidate[start:]
+	}
+	return common, options
+}
+
+// onlinePlayerNames returns who is online from the recorded sessions, for
+// completion. Nothing is completed when they cannot be read.
+func onlinePlayerNames(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, out io.Writer, server string) []string {
+	var names []string
+	_, _ = withApiKeyRetry(ctx, loadConfig, out, func(_ config.Config, client *api.Client) error {
+		history, err := usecases.NewPlayerHistoryUseCase(client).Execute(ctx, server, usecases.PlayerHistoryQuery{Limit: 200, Refresh: true})
+		if err != nil {
+			return err
+		}
+		for _, player := range history.Players {
+			if player.Online {
+				names = append(names, player.Name)
+			}
+		}
+		return nil
+	})
+	return names
+}
+
+// consoleHistory is the prompt's in-memory history, oldest entry first.
+type consoleHistory struct {
+	entries []string
+}
+
+func (h *consoleHistory) Add(entry string) {
+	if entry = strings.TrimSpace(entry); entry == "" {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == entry {
+		return
+	}
+	h.entries = append(h.entries, entry)
+}
+
+func (h *consoleHistory) Len() int {
+	return len(h.entries)
+}
+
+func (h *consoleHistory) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
@@ -0,0 +1,318 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/crash"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+// crashTriage is everything gathered about one crash report.
+type crashTriage struct {
+	Server  string
+	File    string
+	Report  crash.Report
+	Suspect crash.Suspect
+	Found   bool
+	Update  crashUpdate
+}
+
+// crashUpdate is the Modrinth lookup for the suspect.
+type crashUpdate struct {
+	Checked   bool
+	Project   string
+	Installed string
+	Latest    string
+	Newer     bool
+	Err       error
+}
+
+func NewServerCrashesCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var reportName string
+	var limit int
+	var noUpdateCheck bool
+	var share bool
+
+	cmd := &cobra.Command{
+		Use:   "crashes <server>",
+		Short: "List crash reports and triage the newest one",
+		Long: `List a server's crash reports and explain the newest (or --report).
+
+The summary shows the crash description, the root exception, the top of
+the stack trace and the mod or plugin it most likely points at. The
+suspect is checked against Modrinth for a newer compatible version.`,
+		Example: `  mineos servers crashes survival
+  mineos servers crashes survival --report crash-2024-05-01_12.00.00-server.txt
+  mineos servers crashes survival --share`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			name := args[0]
+			var reports []ports.FileEntry
+			var triage crashTriage
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+				var err error
+				reports, err = listCrashReports(ctx, client, name)
+				if err != nil || len(reports) == 0 {
+					return err
+				}
+				file := reports[0].Name
+				if reportName != "" {
+					file = path.Base(reportName)
+				}
+				triage, err = triageCrash(ctx, client, name, file, !noUpdateCheck)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if len(reports) == 0 {
+				cmd.Printf("No crash reports for %s.\n", name)
+				return nil
+			}
+
+			out := cmd.OutOrStdout()
+			if share {
+				printCrashShare(out, triage)
+				return nil
+			}
+			fmt.Fprintf(out, "%s\n\n", styleTitle.Render("Crash reports: "+name))
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			for i, entry := range reports {
+				if limit > 0 && i >= limit {
+					fmt.Fprintf(w, "%s\t\t\n", styleDim.Render(fmt.Sprintf("... %d older", len(reports)-limit)))
+					break
+				}
+				fmt.Fprintf(w, "  %s\t%s\t%s\n", entry.Name, entry.Modified.Local().Format("2006-01-02 15:04"), formatBytes(entry.Size))
+			}
+			w.Flush()
+			fmt.Fprintln(out)
+			printCrashTriage(out, triage)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&reportName, "report", "", "Crash report to triage instead of the newest")
+	cmd.Flags().IntVar(&limit, "limit", 10, "Crash reports listed (0 for all)")
+	cmd.Flags().BoolVar(&noUpdateCheck, "no-update-check", false, "Skip the Modrinth lookup for the suspected mod or plugin")
+	cmd.Flags().BoolVar(&share, "share", false, "Print only a Markdown summary for Discord or an issue tracker")
+
+	return cmd
+}
+
+// listCrashReports returns crash-reports/*.txt, newest first.
+func listCrashReports(ctx context.Context, client *api.Client, name string) ([]ports.FileEntry, error) {
+	entries, err := client.ListServerFiles(ctx, name, crash.Dir)
+	if err != nil {
+		if errors.Is(err, api.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var reports []ports.FileEntry
+	for _, entry := range entries {
+		if !entry.IsDirectory && strings.HasSuffix(entry.Name, ".txt") {
+			reports = append(reports, entry)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Modified.After(reports[j].Modified) })
+	return reports, nil
+}
+
+func triageCrash(ctx context.Context, client *api.Client, name, file string, checkUpdates bool) (crashTriage, error) {
+	content, err := client.ReadServerFile(ctx, name, crash.Dir+"/"+file)
+	if err != nil {
+		return crashTriage{}, err
+	}
+	triage := crashTriage{Server: name, File: file, Report: crash.Parse(content)}
+
+	// A missing mods or plugins directory just means none are installed.
+	mods, _ := client.ListMods(ctx, name)
+	plugins, _ := client.ListPlugins(ctx, name)
+	jars := make([]string, 0, len(mods)+len(plugins))
+	for _, mod := range mods {
+		jars = append(jars, mod.FileName)
+	}
+	for _, plugin := range plugins {
+		jars = append(jars, plugin.FileName)
+	}
+	triage.Suspect, triage.Found = triage.Report.FindSuspect(jars)
+	if !triage.Found || !checkUpdates {
+		return triage, nil
+	}
+
+	kind := "plugins"
+	if len(mods) > 0 && (triage.Suspect.Jar == "" || containsJar(mods, triage.Suspect.Jar)) {
+		kind = "mods"
+	}
+	triage.Update = checkModrinthUpdate(ctx, client, name, kind, triage.Suspect)
+	return triage, nil
+}
+
+func containsJar(jars []ports.InstalledPlugin, fileName string) bool {
+	for _, jar := range jars {
+		if jar.FileName == fileName {
+			return true
+		}
+	}
+	return false
+}
+
+func checkModrinthUpdate(ctx context.Context, client *api.Client, name, kind string, suspect crash.Suspect) crashUpdate {
+	update := crashUpdate{Checked: true}
+	if suspect.Jar != "" {
+		update.Installed = crash.JarVersion(suspect.Jar)
+	}
+	hits, err := client.SearchModrinth(ctx, name, kind, suspect.Name)
+	if err != nil {
+		update.Err = err
+		return update
+	}
+	if len(hits) == 0 {
+		update.Err = fmt.Errorf("no Modrinth project matches %q", suspect.Name)
+		return update
+	}
+	hit := hits[0]
+	for _, candidate := range hits {
+		if strings.EqualFold(candidate.Slug, suspect.Name) || strings.EqualFold(candidate.Title, suspect.Name) {
+			hit = candidate
+			break
+		}
+	}
+	update.Project = hit.Title
+	versions, err := client.ModrinthVersions(ctx, name, kind, hit.ProjectID)
+	if err != nil {
+		update.Err = err
+		return update
+	}
+	if len(versions) == 0 {
+		update.Err = fmt.Errorf("%s has no version for this server's loader and Minecraft version", hit.Title)
+		return update
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].DatePublished.After(versions[j].DatePublished) })
+	latest := versions[0]
+	update.Latest = latest.VersionNumber
+	installed := false
+	for _, file := range latest.Files {
+		if file.FileName == suspect.Jar {
+			installed = true
+		}
+	}
+	update.Newer = suspect.Jar != "" && !installed && update.Installed != latest.VersionNumber &&
+		!strings.Contains(suspect.Jar, latest.VersionNumber)
+	return update
+}
+
+func printCrashTriage(out io.Writer, triage crashTriage) {
+	report := triage.Report
+	fmt.Fprintf(out, "%s\n\n", styleTitle.Render("Newest crash: "+triage.File))
+	if !report.Time.IsZero() {
+		printStat(out, "Time", report.Time.Format("2006-01-02 15:04:05"))
+	}
+	printStat(out, "Description", fallback(report.Description, styleDim.Render("unknown")))
+	printStat(out, "Exception", styleError.Render(fallback(report.Exception, "unknown")))
+	if root := report.Root(); root != report.Exception {
+		printStat(out, "Root cause", styleError.Render(root))
+	}
+	if report.MinecraftVersion != "" {
+		printStat(out, "Minecraft", report.MinecraftVersion)
+	}
+	if report.JavaVersion != "" {
+		printStat(out, "Java", report.JavaVersion)
+	}
+
+	if len(report.Frames) > 0 {
+		fmt.Fprintf(out, "\n  %s\n", styleLabel.Render("Stack trace"))
+		for i, frame := range report.Frames {
+			if i == 8 {
+				fmt.Fprintf(out, "    %s\n", styleDim.Render(fmt.Sprintf("... %d more", len(report.Frames)-i)))
+				break
+			}
+			fmt.Fprintf(out, "    at %s\n", frame)
+		}
+	}
+
+	fmt.Fprintln(out)
+	if !triage.Found {
+		printStat(out, "Suspect", styleDim.Render("none found; the trace only shows server code"))
+		return
+	}
+	suspect := triage.Suspect.Name
+	if triage.Suspect.Jar != "" {
+		suspect += styleDim.Render(" (" + triage.Suspect.Jar + ")")
+	}
+	printStat(out, "Suspect", styleWarning.Render(suspect))
+	printStat(out, "Why", styleDim.Render(triage.Suspect.Reason))
+
+	update := triage.Update
+	switch {
+	case !update.Checked:
+	case update.Err != nil:
+		printStat(out, "Update", styleDim.Render("not checked: "+update.Err.Error()))
+	case update.Newer:
+		printStat(out, "Update", styleWarning.Render(fmt.Sprintf("%s %s is available (installed: %s)", update.Project, update.Latest, fallback(update.Installed, "unknown"))))
+	case triage.Suspect.Jar == "":
+		printStat(out, "Update", fmt.Sprintf("latest %s is %s", update.Project, update.Latest))
+	default:
+		printStat(out, "Update", styleSuccess.Render("installed version is the latest ("+update.Latest+")"))
+	}
+}
+
+// printCrashShare writes a plain Markdown summary to paste into chat or an
+// issue.
+func printCrashShare(out io.Writer, triage crashTriage) {
+	report := triage.Report
+	title := fmt.Sprintf("**Crash on %s**", triage.Server)
+	if !report.Time.IsZero() {
+		title += " (" + report.Time.Format("2006-01-02 15:04") + ")"
+	}
+	fmt.Fprintln(out, title)
+	if report.Description != "" {
+		fmt.Fprintf(out, "Description: %s\n", report.Description)
+	}
+	var versions []string
+	if report.MinecraftVersion != "" {
+		versions = append(versions, "Minecraft "+report.MinecraftVersion)
+	}
+	if report.JavaVersion != "" {
+		versions = append(versions, "Java "+report.JavaVersion)
+	}
+	if len(versions) > 0 {
+		fmt.Fprintln(out, strings.Join(versions, ", "))
+	}
+	if triage.Found {
+		line := "Suspected: " + triage.Suspect.Name
+		if triage.Suspect.Jar != "" {
+			line += " (`" + triage.Suspect.Jar + "`)"
+		}
+		if triage.Update.Newer {
+			line += fmt.Sprintf(", %s is available", triage.Update.Latest)
+		}
+		fmt.Fprintln(out, line)
+	}
+	fmt.Fprintln(out, "```")
+	fmt.Fprintln(out, fallback(report.Exception, "unknown exception"))
+	for i, frame := range report.Frames {
+		if i == 6 {
+			fmt.Fprintf(out, "\t... %d more\n", len(report.Frames)-i)
+			break
+		}
+		fmt.Fprintf(out, "\tat %s\n", frame)
+	}
+	for _, cause := range report.CausedBy {
+		fmt.Fprintf(out, "Caused by: %s\n", cause)
+	}
+	fmt.Fprintln(out, "```")
+}
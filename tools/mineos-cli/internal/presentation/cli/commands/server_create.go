@@ -0,0 +1,354 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/catalog"
+)
+
+const minecraftEulaURL = "https://aka.ms/MinecraftEULA"
+
+func NewServerAcceptEulaCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "accept-eula <server>",
+		Short: "Accept the Minecraft EULA for a server",
+		Long:  "Write eula=true to the server's eula.txt. By running this you agree to the Minecraft EULA (" + minecraftEulaURL + ").",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			changed := false
+			err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				detail, err := client.GetServer(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				if detail.EulaAccepted {
+					return nil
+				}
+				changed = true
+				return client.AcceptEula(ctx, args[0])
+			})
+			if jsonOut {
+				if err != nil {
+					return printChangeResult(cmd, changeResult{Failed: true, Msg: err.Error()})
+				}
+				return printChangeResult(cmd, changeResult{Changed: changed, Msg: "EULA accepted for " + args[0]})
+			}
+			if err != nil {
+				return err
+			}
+			if !changed {
+				cmd.Printf("EULA already accepted for %s\n", args[0])
+				return nil
+			}
+			cmd.Printf("EULA accepted for %s\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print a changed/failed result as JSON")
+
+	return cmd
+}
+
+type serverSetupOptions struct {
+	acceptEula bool
+	bootstrap  bool
+	timeout    time.Duration
+	// noPrompt skips the interactive questions, e.g. for --json output.
+	noPrompt bool
+}
+
+func (o *serverSetupOptions) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.acceptEula, "accept-eula", false, "Accept the Minecraft EULA ("+minecraftEulaURL+")")
+	cmd.Flags().BoolVar(&o.bootstrap, "bootstrap", false, "Run a first start/stop cycle to generate config files")
+	cmd.Flags().DurationVar(&o.timeout, "bootstrap-timeout", 3*time.Minute, "Maximum time to wait for the first start")
+}
+
+func NewServerCreateCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var serverType string
+	var software string
+	var version string
+	var profileID string
+	var port int
+	var ensure bool
+	var jsonOut bool
+	var opts serverSetupOptions
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a new server",
+		Example: `  mineos servers create survival --version 1.21.1 --accept-eula
+  mineos servers create legacy --profile custom-myfork --accept-eula
+  mineos servers create survival --ensure --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			ctx := context.Background()
+			software = strings.ToLower(strings.TrimSpace(software))
+			if version != "" && software != catalog.Vanilla && software != catalog.Paper {
+				return fmt.Errorf("--software must be vanilla or paper (MineOS profiles are not available for %q)", software)
+			}
+			if version != "" && profileID != "" {
+				return fmt.Errorf("give either --version or --profile, not both")
+			}
+			if version != "" {
+				profileID = software + "-" + version
+			}
+			changed := false
+			opts.noPrompt = jsonOut
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(cfg config.Config, client *api.Client) error {
+				if ensure {
+					exists, err := serverExists(ctx, client, name)
+					if err != nil || exists {
+						return err
+					}
+				}
+				if err := client.CreateServer(ctx, name, serverType); err != nil {
+					return err
+				}
+				changed = true
+				if jsonOut {
+					// Progress lines would break the JSON document.
+					cmd.SetOut(io.Discard)
+				}
+				cmd.Printf("Created %s server %s\n", serverType, name)
+				if err := assignFreePorts(ctx, client, cfg, cmd, name, port); err != nil {
+					return err
+				}
+				if profileID != "" && serverType != "bedrock" {
+					if err := installProfile(ctx, client, cmd, name, profileID); err != nil {
+						return err
+					}
+				}
+				if serverType == "bedrock" {
+					return nil
+				}
+				return finishServerSetup(ctx, client, cmd, name, opts)
+			})
+			if jsonOut {
+				cmd.SetOut(nil)
+				if err != nil {
+					return printChangeResult(cmd, changeResult{Changed: changed, Failed: true, Msg: err.Error()})
+				}
+				if !changed {
+					return printChangeResult(cmd, changeResult{Msg: name + " already exists"})
+				}
+				return printChangeResult(cmd, changeResult{Changed: true, Msg: "created " + serverType + " server " + name})
+			}
+			if err == nil && !changed {
+				cmd.Printf("%s already exists\n", name)
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&serverType, "type", "java", "Server type (java or bedrock)")
+	cmd.Flags().StringVar(&software, "software", catalog.Vanilla, "Server software for --version (vanilla or paper)")
+	cmd.Flags().StringVar(&version, "version", "", "Minecraft version to install (see 'mineos versions')")
+	cmd.Flags().StringVar(&profileID, "profile", "", "Profile to install, e.g. a custom jar (see 'mineos profiles list')")
+	cmd.Flags().IntVar(&port, "port", 0, "Server port (default: next free port)")
+	cmd.Flags().BoolVar(&ensure, "ensure", false, "Succeed without changes if the server already exists")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print a changed/failed result as JSON")
+	opts.register(cmd)
+	_ = cmd.RegisterFlagCompletionFunc("version", completeMinecraftVersions)
+	_ = cmd.RegisterFlagCompletionFunc("profile", func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return completeProfileIDs(cmd, loadConfig, false), cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("software", cobra.FixedCompletions([]string{catalog.Vanilla, catalog.Paper}, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+func NewServerImportCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var port int
+	var allowFlagged bool
+	var opts serverSetupOptions
+
+	cmd := &cobra.Command{
+		Use:   "import <archive> <name>",
+		Short: "Create a server from an archive in the host import directory",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			archive, name := args[0], args[1]
+			ctx := context.Background()
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(cfg config.Config, client *api.Client) error {
+				jobID, err := client.ImportServer(ctx, archive, name, allowFlagged)
+				if err != nil {
+					return err
+				}
+				t := trackTask(cmd.OutOrStdout(), "Import "+archive+" as "+name)
+				if err := waitForJob(ctx, client, cmd.OutOrStdout(), jobID); err != nil {
+					t.Fail()
+					return err
+				}
+				t.Done("")
+				if !allowFlagged {
+					reportQuarantined(ctx, client, cmd.OutOrStdout(), name)
+				}
+				if err := assignFreePorts(ctx, client, cfg, cmd, name, port); err != nil {
+					return err
+				}
+
+				detail, err := client.GetServer(ctx, name)
+				if err == nil && detail.IsBedrock() {
+					return nil
+				}
+				return finishServerSetup(ctx, client, cmd, name, opts)
+			})
+			return err
+		},
+	}
+
+	cmd.Flags().IntVar(&port, "port", 0, "Server port (default: keep the archive's port unless it conflicts)")
+	cmd.Flags().BoolVar(&allowFlagged, "allow-flagged", false, "Keep mod and plugin jars the malware scanner flags instead of quarantining them")
+	opts.register(cmd)
+
+	return cmd
+}
+
+// installProfile downloads a profile if needed and copies its jar into the
+// server, which also points the server config at it.
+func installProfile(ctx context.Context, client *api.Client, cmd *cobra.Command, name, profileID string) error {
+	profiles, err := client.ListProfiles(ctx)
+	if err != nil {
+		return err
+	}
+	var profile *ports.Profile
+	for i := range profiles {
+		if profiles[i].Id == profileID {
+			profile = &profiles[i]
+			break
+		}
+	}
+	if profile == nil {
+		return ports.WithKind(fmt.Errorf("profile %s not found; list versions with 'mineos versions' or profiles with 'mineos profiles list'", profileID), ports.ErrNotFound)
+	}
+	return runTask(cmd.OutOrStdout(), "Install "+profileID+" on "+name, func(io.Writer) error {
+		if !profile.Downloaded {
+			if err := client.DownloadProfile(ctx, profileID); err != nil {
+				return err
+			}
+		}
+		return client.CopyProfileToServer(ctx, profileID, name)
+	})
+}
+
+func serverExists(ctx context.Context, client *api.Client, name string) (bool, error) {
+	servers, err := client.ListServers(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, server := range servers {
+		if server.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// finishServerSetup offers to accept the EULA and bootstrap a freshly created
+// server. Without flags the user is prompted when stdin is a terminal.
+func finishServerSetup(ctx context.Context, client *api.Client, cmd *cobra.Command, name string, opts serverSetupOptions) error {
+	interactive := term.IsTerminal(int(os.Stdin.Fd())) && !machineMode && !opts.noPrompt
+
+	detail, err := client.GetServer(ctx, name)
+	if err != nil {
+		return err
+	}
+	accepted := detail.EulaAccepted
+
+	if !accepted {
+		accept := opts.acceptEula
+		if !accept && interactive && !cmd.Flags().Changed("accept-eula") {
+			cmd.Printf("The Minecraft EULA must be accepted before %s can start: %s\n", name, minecraftEulaURL)
+			accept, err = promptYesNo(nil, cmd.OutOrStdout(), "Accept the EULA now?", false)
+			if err != nil {
+				return err
+			}
+		}
+		if accept {
+			if err := client.AcceptEula(ctx, name); err != nil {
+				return err
+			}
+			accepted = true
+			cmd.Println(styleSuccess.Render("EULA accepted."))
+		}
+	}
+
+	if !accepted {
+		cmd.Printf("%s The server will not start until the EULA is accepted: mineos servers accept-eula %s\n", styleWarning.Render("Note:"), name)
+		return nil
+	}
+
+	bootstrap := opts.bootstrap
+	if !bootstrap && interactive && !cmd.Flags().Changed("bootstrap") {
+		bootstrap, err = promptYesNo(nil, cmd.OutOrStdout(), "Run a first start to generate config files?", true)
+		if err != nil {
+			return err
+		}
+	}
+	if !bootstrap {
+		return nil
+	}
+
+	uc := usecases.NewBootstrapServerUseCase(client)
+	err = uc.Execute(ctx, name, opts.timeout, func(msg string) {
+		cmd.Printf("  %s\n", msg)
+	})
+	if err != nil {
+		return withEulaHint(err, name)
+	}
+	cmd.Println(styleSuccess.Render(name + " is ready."))
+	return nil
+}
+
+func waitForJob(ctx context.Context, client *api.Client, out io.Writer, jobID string) error {
+	if jobID == "" {
+		return nil
+	}
+	bar := newPercentProgress(out, "")
+	defer bar.Finish()
+	for {
+		job, err := client.GetJob(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		bar.message = job.Message
+		bar.Set(int64(job.Percentage))
+		if job.IsDone() {
+			if job.Status == "failed" {
+				return fmt.Errorf("job %s failed: %s", jobID, fallback(job.Error, "unknown error"))
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// withEulaHint adds the accept-eula command to EULA errors.
+func withEulaHint(err error, name string) error {
+	if errors.Is(err, usecases.ErrEulaNotAccepted) {
+		return fmt.Errorf("%w; accept it with 'mineos servers accept-eula %s' (%s)", err, name, minecraftEulaURL)
+	}
+	return err
+}
@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/trash"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+// TrashRetentionEnv sets how many days deleted servers stay in the trash.
+const TrashRetentionEnv = "MINEOS_TRASH_RETENTION_DAYS"
+
+func NewServerDeleteCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var permanent bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:         "delete <server>",
+		Annotations: map[string]string{destructiveAnnotation: "true"},
+		Short:       "Move a stopped server to the trash",
+		Long: `Move a server, with its backups and archives, to the trash on the server
+volume. It stays there for ` + strconv.Itoa(trash.DefaultRetentionDays) + ` days (` + TrashRetentionEnv + ` in .env, 0 to keep
+it until 'mineos servers trash --empty') and comes back with
+'mineos servers undelete'. --permanent deletes it right away instead.
+
+The server has to be stopped first. Its name has to be typed to confirm;
+with ` + ConfirmLevelEnv + `=yes in .env a y/n is asked instead.`,
+		Example: `  mineos servers delete old-survival
+  mineos servers undelete old-survival`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+			_, err := withApiKeyRetry(ctx, loadConfig, out, func(cfg config.Config, client *api.Client) error {
+				detail, err := client.GetServer(ctx, name)
+				if err != nil {
+					return err
+				}
+				if detail.IsRunning() {
+					return ports.WithKind(fmt.Errorf("%s is running; stop it first with 'mineos servers stop %s'", name, name), ports.ErrConflict)
+				}
+				layout, retention, err := trashSettings(cfg)
+				if err != nil {
+					return err
+				}
+				if !yes {
+					warning := fmt.Sprintf("This moves %s, its worlds, backups and archives to the trash%s.", name, retentionSuffix(retention))
+					if permanent {
+						warning = fmt.Sprintf("This permanently deletes %s, its worlds, backups and archives.", name)
+					}
+					if err := confirmServerOperation(cfg, out, name, "delete", warning); err != nil {
+						return err
+					}
+				}
+				if permanent {
+					if err := client.DeleteServer(ctx, name); err != nil {
+						return err
+					}
+					cmd.Println(styleSuccess.Render("Deleted " + name + "."))
+					return nil
+				}
+
+				compose, err := detectCompose()
+				if err != nil {
+					return err
+				}
+				if _, err := runTrashScript(compose, trash.MoveScript(layout, name, time.Now())); err != nil {
+					return fmt.Errorf("move %s to the trash: %w", name, err)
+				}
+				cmd.Println(styleSuccess.Render("Moved " + name + " to the trash."))
+				cmd.Println(styleDim.Render("Bring it back with 'mineos servers undelete " + name + "'."))
+				if entries, err := listTrash(compose); err == nil {
+					purgeExpiredTrash(compose, entries, retention, out)
+				}
+				return nil
+			})
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&permanent, "permanent", false, "Delete right away instead of moving to the trash")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+
+	return cmd
+}
+
+func NewServerUndeleteCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "undelete <name>",
+		Short: "Bring back a server from the trash",
+		Long: `Move the most recently deleted server of that name back from the trash,
+with its backups and archives. It comes back stopped.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			ctx := context.Background()
+			compose, cfg, err := loadComposeAndConfig(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			layout, _, err := trashSettings(cfg)
+			if err != nil {
+				return err
+			}
+			entries, err := listTrash(compose)
+			if err != nil {
+				return err
+			}
+			entry, ok := trash.Latest(entries, name)
+			if !ok {
+				return fmt.Errorf("%s is not in the trash; 'mineos servers trash' lists what is", name)
+			}
+			if _, err := runTrashScript(compose, trash.RestoreScript(layout, entry)); err != nil {
+				return fmt.Errorf("undelete %s: %w", name, err)
+			}
+			cmd.Println(styleSuccess.Render(fmt.Sprintf("Restored %s, deleted %s.", name, entry.DeletedAt.Local().Format("2006-01-02 15:04"))))
+			return nil
+		},
+	}
+}
+
+func NewServerTrashCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var empty bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "List deleted servers, or empty the trash",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			out := cmd.OutOrStdout()
+			compose, cfg, err := loadComposeAndConfig(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			_, retention, err := trashSettings(cfg)
+			if err != nil {
+				return err
+			}
+			entries, err := listTrash(compose)
+			if err != nil {
+				return err
+			}
+			entries = purgeExpiredTrash(compose, entries, retention, out)
+			if len(entries) == 0 {
+				fmt.Fprintln(out, "The trash is empty.")
+				return nil
+			}
+
+			if empty {
+				if !yes {
+					ok, err := promptYesNo(nil, out, fmt.Sprintf("Permanently delete %d server(s) in the trash?", len(entries)), false)
+					if err != nil {
+						return err
+					}
+					if !ok {
+						return fmt.Errorf("empty cancelled")
+					}
+				}
+				if _, err := runTrashScript(compose, trash.PurgeScript(entries)); err != nil {
+					return err
+				}
+				fmt.Fprintln(out, styleSuccess.Render(fmt.Sprintf("Deleted %d server(s) for good.", len(entries))))
+				return nil
+			}
+
+			for _, entry := range entries {
+				expires := "kept until emptied"
+				if at := entry.ExpiresAt(retention); !at.IsZero() {
+					expires = "purged " + at.Local().Format("2006-01-02 15:04")
+				}
+				fmt.Fprintf(out, "  %-24s deleted %s  %9s  %s\n", entry.Server, entry.DeletedAt.Local().Format("2006-01-02 15:04"),
+					formatBytes(entry.SizeKB*1024), styleDim.Render(expires))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&empty, "empty", false, "Permanently delete everything in the trash")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Empty without asking for confirmation")
+
+	return cmd
+}
+
+// trashSettings reads the container layout of server directories and the
+// retention window from .env.
+func trashSettings(cfg config.Config) (trash.Layout, int, error) {
+	values, err := loadLayeredEnvValues(cfg)
+	if err != nil {
+		return trash.Layout{}, 0, err
+	}
+	layout := trash.Layout{Base: containerBaseDir}
+	for _, key := range []string{"Host__ServersPathSegment", "Host__BackupsPathSegment", "Host__ArchivesPathSegment"} {
+		segment := strings.TrimSuffix(strings.TrimPrefix(key, "Host__"), "PathSegment")
+		layout.Segments = append(layout.Segments, fallback(strings.TrimSpace(values[key]), strings.ToLower(segment)))
+	}
+	retention := trash.DefaultRetentionDays
+	if value := strings.TrimSpace(values[TrashRetentionEnv]); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return trash.Layout{}, 0, fmt.Errorf("%s must be a number of days, got %q", TrashRetentionEnv, value)
+		}
+		retention = days
+	}
+	return layout, retention, nil
+}
+
+func retentionSuffix(days int) string {
+	if days <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" for %d days", days)
+}
+
+func runTrashScript(compose composeRunner, script string) ([]byte, error) {
+	out, err := compose.output([]string{"exec", "-T", "api", "sh", "-c", script})
+	if err != nil {
+		return out, fmt.Errorf("%w (is the stack running?)", err)
+	}
+	return out, nil
+}
+
+func listTrash(compose composeRunner) ([]trash.Entry, error) {
+	out, err := runTrashScript(compose, trash.ListScript)
+	if err != nil {
+		return nil, fmt.Errorf("list the trash: %w", err)
+	}
+	return trash.ParseListing(string(out)), nil
+}
+
+// purgeExpiredTrash deletes the entries past the retention window and
+// returns the rest. Failures only warn: the command that triggered the
+// purge has done its job.
+func purgeExpiredTrash(compose composeRunner, entries []trash.Entry, retention int, out io.Writer) []trash.Entry {
+	expired := trash.Expired(entries, retention, time.Now())
+	if len(expired) == 0 {
+		return entries
+	}
+	if _, err := runTrashScript(compose, trash.PurgeScript(expired)); err != nil {
+		fmt.Fprintln(out, styleWarning.Render("purge the trash: "+err.Error()))
+		return entries
+	}
+	names := make([]string, 0, len(expired))
+	for _, entry := range expired {
+		names = append(names, entry.Server)
+	}
+	fmt.Fprintln(out, styleDim.Render("Purged from the trash: "+strings.Join(names, ", ")))
+	// Entries are newest first, so the expired ones are the tail.
+	return entries[:len(entries)-len(expired)]
+}
@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+func NewServerEnvCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var properties []string
+	var reveal bool
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "env <server> [list|set|unset] [KEY=VALUE...|KEY...]",
+		Short: "Manage a server's environment variables and JVM system properties",
+		Long: `Environment variables and Java system properties (-D) passed to a server
+when it starts, for things like log4j mitigations, the time zone or a Velocity
+forwarding secret. Changes apply at the next start; a running server is marked
+as needing a restart.
+
+Values of names containing SECRET, TOKEN, PASSWORD or KEY are masked in the
+list unless --reveal is given.`,
+		Example: `  mineos servers env survival
+  mineos servers env survival set TZ=Europe/Berlin -Dlog4j2.formatMsgNoLookups=true
+  mineos servers env proxy set VELOCITY_FORWARDING_SECRET=s3cret
+  mineos servers env survival unset TZ -Dlog4j2.formatMsgNoLookups`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			server := args[0]
+			action := "list"
+			if len(args) > 1 {
+				action = args[1]
+			}
+			rest := args[min(len(args), 2):]
+
+			switch action {
+			case "list":
+				if len(rest) > 0 || len(properties) > 0 {
+					return fmt.Errorf("list takes no arguments")
+				}
+				var env ports.ServerEnvironment
+				if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+					var err error
+					env, err = usecases.NewServerEnvironmentUseCase(client).Get(ctx, server)
+					return err
+				}); err != nil {
+					return err
+				}
+				if jsonOut {
+					encoder := json.NewEncoder(cmd.OutOrStdout())
+					encoder.SetIndent("", "  ")
+					return encoder.Encode(env)
+				}
+				printServerEnvironment(cmd.OutOrStdout(), server, env, reveal)
+				return nil
+			case "set", "unset":
+				change, err := parseEnvironmentChange(rest, properties, action == "unset")
+				if err != nil {
+					return err
+				}
+				return applyEnvironmentChange(ctx, loadConfig, cmd, server, change)
+			default:
+				return fmt.Errorf("unknown action %q (use list, set or unset)", action)
+			}
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&properties, "property", "D", nil, "Java system property, as name=value for set or name for unset (repeatable)")
+	cmd.Flags().BoolVar(&reveal, "reveal", false, "Show values that look like secrets")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			names, _ := listServerNames(cmd, loadConfig)
+			return names, cobra.ShellCompDirectiveNoFileComp
+		case 1:
+			return []string{"list", "set", "unset"}, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// parseEnvironmentChange reads KEY=VALUE (or KEY for unset) arguments as
+// environment variables and the -D values as system properties.
+func parseEnvironmentChange(args, properties []string, unset bool) (usecases.EnvironmentChange, error) {
+	change := usecases.EnvironmentChange{
+		Environment: map[string]string{},
+		Properties:  map[string]string{},
+		Unset:       unset,
+	}
+	parse := func(arg string, into map[string]string, kind string) error {
+		key, value, hasValue := strings.Cut(arg, "=")
+		switch {
+		case key == "":
+			return fmt.Errorf("%q has no %s name", arg, kind)
+		case unset && hasValue:
+			return fmt.Errorf("unset takes names only, not %q", arg)
+		case !unset && !hasValue:
+			return fmt.Errorf("%q needs a value: %s=VALUE", arg, key)
+		}
+		into[key] = value
+		return nil
+	}
+	for _, arg := range args {
+		if err := parse(arg, change.Environment, "variable"); err != nil {
+			return change, err
+		}
+	}
+	for _, arg := range properties {
+		if err := parse(arg, change.Properties, "property"); err != nil {
+			return change, err
+		}
+	}
+	if len(change.Environment) == 0 && len(change.Properties) == 0 {
+		if unset {
+			return change, fmt.Errorf("give the variables to unset, and -D<name> for system properties")
+		}
+		return change, fmt.Errorf("give KEY=VALUE variables to set, and -D<name>=<value> for system properties")
+	}
+	return change, nil
+}
+
+func applyEnvironmentChange(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, cmd *cobra.Command, server string, change usecases.EnvironmentChange) error {
+	changed, running := false, false
+	_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+		var err error
+		_, changed, err = usecases.NewServerEnvironmentUseCase(client).Apply(ctx, server, change)
+		if err != nil {
+			return err
+		}
+		if detail, err := client.GetServer(ctx, server); err == nil {
+			running = detail.IsRunning()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !changed {
+		cmd.Printf("Environment of %s is unchanged.\n", server)
+		return nil
+	}
+	cmd.Printf("%s Environment updated for %s\n", markOK(), server)
+	if running {
+		cmd.Println(styleDim.Render(fmt.Sprintf("Restart %s to apply it: mineos servers restart %s", server, server)))
+	}
+	return nil
+}
+
+func printServerEnvironment(out io.Writer, server string, env ports.ServerEnvironment, reveal bool) {
+	if len(env.Environment) == 0 && len(env.SystemProperties) == 0 {
+		fmt.Fprintf(out, "No environment overrides for %s.\n", server)
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tNAME\tVALUE")
+	print := func(kind string, values map[string]string) {
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := values[key]
+			if !reveal && value != "" && usecases.LooksSecret(key) {
+				value = styleDim.Render("********")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", kind, key, value)
+		}
+	}
+	print("env", env.Environment)
+	print("-D", env.SystemProperties)
+	w.Flush()
+}
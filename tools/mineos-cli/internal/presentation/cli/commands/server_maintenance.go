@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/motd"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+const (
+	defaultMaintenanceMessage = "The server is down for maintenance. Please check back soon."
+	defaultMaintenanceMotd    = "&cDown for maintenance\n&7Back soon"
+)
+
+func NewServerMaintenanceCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var message string
+	var motdText string
+	var keepMotd bool
+
+	cmd := &cobra.Command{
+		Use:   "maintenance <server> [on|off]",
+		Short: "Close a server to everyone but whitelisted players and restore it afterwards",
+		Long: `Turn maintenance mode on or off; without on|off the current mode is shown.
+
+On enables the whitelist, sets enforce-whitelist, kicks online players who
+are neither whitelisted nor operators with --message, and sets a maintenance
+MOTD. The previous white-list, enforce-whitelist and motd values are kept in
+` + usecases.MaintenanceFile + ` in the server directory, and off puts them back.
+
+A running server shows the new MOTD after its next restart. Bedrock servers
+are not supported.`,
+		Example: `  mineos servers maintenance survival on
+  mineos servers maintenance survival on --message "Updating to 1.21, back at 18:00"
+  mineos servers maintenance survival off`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			name := args[0]
+			mode := ""
+			if len(args) == 2 {
+				mode = strings.ToLower(args[1])
+			}
+
+			switch mode {
+			case "":
+				var state usecases.MaintenanceState
+				if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+					var err error
+					state, err = usecases.NewMaintenanceUseCase(client).State(ctx, name)
+					return err
+				}); err != nil {
+					return err
+				}
+				if !state.Active {
+					cmd.Printf("%s is not in maintenance mode.\n", name)
+					return nil
+				}
+				cmd.Printf("%s is in maintenance mode since %s.\n", name, state.Since.Local().Format("2006-01-02 15:04"))
+				return nil
+
+			case "on":
+				opts := usecases.MaintenanceOptions{Message: message}
+				if !keepMotd {
+					opts.Motd = motd.EncodeProperty(motd.Legacy(motd.Parse(strings.ReplaceAll(motdText, `\n`, "\n")), false))
+				}
+				var result usecases.MaintenanceResult
+				if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+					var err error
+					result, err = usecases.NewMaintenanceUseCase(client).On(ctx, name, opts)
+					return err
+				}); err != nil {
+					return err
+				}
+				if result.Unchanged {
+					cmd.Printf("%s is already in maintenance mode.\n", name)
+					return nil
+				}
+				cmd.Printf("%s %s is in maintenance mode\n", markOK(), name)
+				if len(result.Kicked) > 0 {
+					cmd.Printf("  Kicked: %s\n", strings.Join(result.Kicked, ", "))
+				}
+				printMaintenanceNotes(cmd, result)
+				if result.Running && !keepMotd {
+					cmd.Println(styleDim.Render(fmt.Sprintf("The MOTD applies after a restart: mineos servers restart %s", name)))
+				}
+				return nil
+
+			case "off":
+				var result usecases.MaintenanceResult
+				if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+					var err error
+					result, err = usecases.NewMaintenanceUseCase(client).Off(ctx, name)
+					return err
+				}); err != nil {
+					return err
+				}
+				if result.Unchanged {
+					cmd.Printf("%s is not in maintenance mode.\n", name)
+					return nil
+				}
+				cmd.Printf("%s %s is open again; whitelist and MOTD restored\n", markOK(), name)
+				printMaintenanceNotes(cmd, result)
+				if result.Running {
+					cmd.Println(styleDim.Render(fmt.Sprintf("The MOTD applies after a restart: mineos servers restart %s", name)))
+				}
+				return nil
+			}
+			return fmt.Errorf("unknown mode %q (use on or off)", args[1])
+		},
+	}
+
+	cmd.Flags().StringVar(&message, "message", defaultMaintenanceMessage, "Kick message shown to players")
+	cmd.Flags().StringVar(&motdText, "motd", defaultMaintenanceMotd, "Maintenance MOTD, in '&' codes or MiniMessage")
+	cmd.Flags().BoolVar(&keepMotd, "keep-motd", false, "Leave the MOTD unchanged")
+
+	return cmd
+}
+
+func printMaintenanceNotes(cmd *cobra.Command, result usecases.MaintenanceResult) {
+	for _, note := range result.Notes {
+		cmd.Printf("%s %s\n", styleWarning.Render("Note:"), note)
+	}
+}
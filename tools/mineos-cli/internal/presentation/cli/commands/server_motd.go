@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/icon"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/motd"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+const motdProperty = "motd"
+
+func NewServerMotdCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "motd",
+		Short: "Show or change the message shown in the multiplayer server list",
+	}
+
+	cmd.AddCommand(newServerMotdGetCommand(loadConfig))
+	cmd.AddCommand(newServerMotdSetCommand(loadConfig))
+
+	return cmd
+}
+
+func newServerMotdGetCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var raw bool
+
+	cmd := &cobra.Command{
+		Use:   "get <server>",
+		Short: "Print a server's MOTD with a colored preview",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			var value string
+			if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				props, err := client.GetServerProperties(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				value = motd.DecodeProperty(props[motdProperty])
+				return nil
+			}); err != nil {
+				return err
+			}
+			if raw {
+				cmd.Println(motd.ToAmpersand(value))
+				return nil
+			}
+			cmd.Printf("%s %s\n", styleDim.Render("Codes:"), motd.ToAmpersand(value))
+			cmd.Println(renderMotdPreview(motd.ParseLegacy(value)))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&raw, "raw", false, "Print only the MOTD with '&' color codes")
+
+	return cmd
+}
+
+func newServerMotdSetCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var keepHex bool
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "set <server> <motd>",
+		Short: "Set a server's MOTD from MiniMessage or '&' color codes",
+		Long: `Set the MOTD written to server.properties.
+
+The text may use legacy codes (&a green, &l bold, &r reset, &#rrggbb) or
+MiniMessage tags (<green>, <bold>, <#ff8800>, <gradient:#ff0000:#0000ff>,
+<newline>). MiniMessage is converted to legacy codes, which every server
+reads. Hex colors become the nearest of the 16 classic colors unless
+--keep-hex is given (Paper and Spigot render §x hex codes).
+
+Use "\n" or <newline> for the second line.`,
+		Example: `  mineos servers motd set survival "&6Survival &7- &aSeason 3"
+  mineos servers motd set lobby "<gradient:#ff5555:#ffaa00>My Network</gradient><newline><gray>play.example.net"`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			text := strings.ReplaceAll(args[1], `\n`, "\n")
+			segments := motd.Parse(text)
+			value := motd.Legacy(segments, keepHex)
+
+			cmd.Println(renderMotdPreview(motd.ParseLegacy(value)))
+			if lines, _ := motd.Lines(segments); lines > motd.MaxLines {
+				cmd.Printf("%s the server list shows only %d lines; %d given.\n", styleWarning.Render("Note:"), motd.MaxLines, lines)
+			}
+			if dryRun {
+				cmd.Printf("%s %s\n", styleDim.Render("Codes:"), motd.ToAmpersand(value))
+				return nil
+			}
+
+			ctx := context.Background()
+			running := false
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+				props, err := client.GetServerProperties(ctx, name)
+				if err != nil {
+					return err
+				}
+				props[motdProperty] = motd.EncodeProperty(value)
+				if err := client.UpdateServerProperties(ctx, name, props); err != nil {
+					return err
+				}
+				if detail, err := client.GetServer(ctx, name); err == nil {
+					running = detail.IsRunning()
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			cmd.Printf("MOTD updated for %s\n", name)
+			if running {
+				cmd.Println(styleDim.Render(fmt.Sprintf("Restart %s to apply it: mineos servers restart %s", name, name)))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&keepHex, "keep-hex", false, "Keep hex colors as §x codes (Paper/Spigot) instead of the nearest classic color")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview the MOTD without saving it")
+
+	return cmd
+}
+
+// renderMotdPreview draws the MOTD roughly as the multiplayer screen shows it.
+func renderMotdPreview(segments []motd.Segment) string {
+	var lines []string
+	var line strings.Builder
+	for _, segment := range segments {
+		style := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(fallback(segment.Style.Color, "#aaaaaa"))).
+			Bold(segment.Style.Bold).
+			Italic(segment.Style.Italic).
+			Underline(segment.Style.Underlined).
+			Strikethrough(segment.Style.Strikethrough).
+			Blink(segment.Style.Obfuscated)
+		parts := strings.Split(segment.Text, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, line.String())
+				line.Reset()
+			}
+			if part != "" {
+				line.WriteString(style.Render(part))
+			}
+		}
+	}
+	lines = append(lines, line.String())
+	if accessibleMode {
+		return strings.Join(lines, "\n")
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
+
+func NewServerIconCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "icon",
+		Short: "Manage the server list icon",
+	}
+
+	cmd.AddCommand(newServerIconSetCommand(loadConfig))
+
+	return cmd
+}
+
+func newServerIconSetCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <server> <image>",
+		Short: fmt.Sprintf("Upload a PNG/JPEG/GIF as server-icon.png (scaled to %dx%d)", icon.Size, icon.Size),
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, path := args[0], args[1]
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			data, info, err := icon.Prepare(file)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if info.Cropped {
+				cmd.Printf("%s %dx%d image cropped to a centered square\n", styleWarning.Render("Note:"), info.Width, info.Height)
+			}
+			if info.Resized {
+				cmd.Printf("Scaled %s image to %dx%d\n", strings.ToUpper(info.Format), icon.Size, icon.Size)
+			}
+
+			ctx := context.Background()
+			if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				return client.UploadServerIcon(ctx, name, data)
+			}); err != nil {
+				return err
+			}
+			cmd.Printf("Icon updated for %s (shown after the next restart)\n", name)
+			return nil
+		},
+	}
+}
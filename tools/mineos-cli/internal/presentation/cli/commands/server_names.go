@@ -0,0 +1,221 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/fuzzy"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+// serverPlaceholders are the Use placeholders that name an existing server;
+// their arguments may be typed partially. "<name>" is left out since create
+// and import take a new name.
+var serverPlaceholders = map[string]bool{
+	"server":       true,
+	"name|pattern": true,
+	"proxy":        true,
+	"backend":      true,
+}
+
+// serverArgPositions returns which of n positional arguments are server
+// names according to the command's Use line. A variadic placeholder
+// ("<name|pattern>...") takes every argument the placeholders after it
+// leave over.
+func serverArgPositions(use string, n int) []int {
+	fields := strings.Fields(use)
+	if len(fields) < 2 {
+		return nil
+	}
+	fields = fields[1:]
+	var positions []int
+	arg := 0
+	for i, field := range fields {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		variadic := strings.HasSuffix(field, "...")
+		name := strings.Trim(strings.TrimSuffix(field, "..."), "<>[]")
+		count := 1
+		if variadic {
+			count = max(0, n-arg-len(fields[i+1:]))
+		}
+		for j := 0; j < count && arg < n; j++ {
+			if serverPlaceholders[name] {
+				positions = append(positions, arg)
+			}
+			arg++
+		}
+	}
+	return positions
+}
+
+func takesServerArgs(use string) bool {
+	for _, field := range strings.Fields(use) {
+		if serverPlaceholders[strings.Trim(strings.TrimSuffix(field, "..."), "<>[]")] {
+			return true
+		}
+	}
+	return false
+}
+
+// destructiveAnnotation marks commands that must be given exact server
+// names: "true" always, or the name of a flag that makes them destructive
+// (world check --repair).
+const destructiveAnnotation = "mineos:destructive"
+
+func isDestructive(cmd *cobra.Command) bool {
+	value := cmd.Annotations[destructiveAnnotation]
+	switch value {
+	case "":
+		return false
+	case "true":
+		return true
+	}
+	return cmd.Flags().Changed(value)
+}
+
+// promptable reports whether the user can be asked to pick a server.
+func promptable() bool {
+	return !machineMode && term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// resolveServerArgs lets partially typed server names through on a
+// terminal: a name that matches another server is offered as "did you
+// mean", and an ambiguous one as a choice. Nothing is rewritten for
+// destructive commands or without a terminal (machine mode, the agent, CI),
+// where a name must be exact; names that do not match a server are left
+// for the command to report.
+func resolveServerArgs(cmd *cobra.Command, args []string, loadConfig *usecases.LoadConfigUseCase) error {
+	var pending []int
+	for _, i := range serverArgPositions(cmd.Use, len(args)) {
+		if arg := args[i]; arg != "" && !isGlobPattern(arg) && !strings.ContainsAny(arg, "/\\:") {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	names, ok := listServerNames(cmd, loadConfig)
+	if !ok {
+		return nil
+	}
+
+	exactOnly := isDestructive(cmd) || !promptable()
+	for _, i := range pending {
+		if slices.Contains(names, args[i]) {
+			continue
+		}
+		match, candidates := fuzzy.Resolve(args[i], names)
+		if match == "" && len(candidates) == 0 {
+			continue
+		}
+		if exactOnly {
+			if !machineMode {
+				suggestion := match
+				if suggestion == "" {
+					suggestion = strings.Join(candidates, ", ")
+				}
+				fmt.Fprintln(decor(cmd.ErrOrStderr()), styleDim.Render(fmt.Sprintf("No server is named %q; did you mean %s?", args[i], suggestion)))
+			}
+			continue
+		}
+		if match == "" {
+			chosen, err := chooseServer(cmd, args[i], candidates)
+			if err != nil {
+				return err
+			}
+			args[i] = chosen
+			continue
+		}
+		if confirmServer(cmd, args[i], match) {
+			args[i] = match
+		}
+	}
+	return nil
+}
+
+// confirmServer asks whether input meant match; Enter accepts.
+func confirmServer(cmd *cobra.Command, input, match string) bool {
+	fmt.Fprintf(cmd.ErrOrStderr(), "No server is named %q. Did you mean %s? [Y/n] ", input, match)
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+// listServerNames fetches the server names; ok is false when the API cannot
+// be reached, in which case arguments are passed on unchanged.
+func listServerNames(cmd *cobra.Command, loadConfig *usecases.LoadConfigUseCase) ([]string, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cfg, err := loadConfig.Execute(ctx)
+	if err != nil || cfg.EffectiveApiKey() == "" {
+		return nil, false
+	}
+	var names []string
+	_, err = withApiKeyRetry(ctx, loadConfig, cmd.ErrOrStderr(), func(cfg config.Config, client *api.Client) error {
+		names, err = cachedServerNames(ctx, cfg, client)
+		return err
+	})
+	return names, err == nil
+}
+
+func chooseServer(cmd *cobra.Command, input string, candidates []string) (string, error) {
+	if !promptable() {
+		return "", fmt.Errorf("server %q is ambiguous; it matches %s", input, strings.Join(candidates, ", "))
+	}
+	out := cmd.ErrOrStderr()
+	fmt.Fprintf(out, "%q matches several servers:\n", input)
+	for i, candidate := range candidates {
+		fmt.Fprintf(out, "  %d) %s\n", i+1, candidate)
+	}
+	fmt.Fprintf(out, "Choose [1-%d]: ", len(candidates))
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return "", fmt.Errorf("server %q is ambiguous; no choice made", input)
+	}
+	choice, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+	if err != nil || choice < 1 || choice > len(candidates) {
+		return "", fmt.Errorf("invalid choice %q", strings.TrimSpace(scanner.Text()))
+	}
+	return candidates[choice-1], nil
+}
+
+// addServerCompletions completes server names for every command whose Use
+// line takes one, unless it already completes its arguments.
+func addServerCompletions(root *cobra.Command, loadConfig *usecases.LoadConfigUseCase) {
+	for _, cmd := range root.Commands() {
+		addServerCompletions(cmd, loadConfig)
+		if cmd.ValidArgsFunction != nil || !takesServerArgs(cmd.Use) {
+			continue
+		}
+		cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			positions := serverArgPositions(cmd.Use, len(args)+1)
+			if len(positions) == 0 || positions[len(positions)-1] != len(args) {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			names, _ := listServerNames(cmd, loadConfig)
+			var matches []string
+			for _, name := range names {
+				if strings.HasPrefix(strings.ToLower(name), strings.ToLower(toComplete)) {
+					matches = append(matches, name)
+				}
+			}
+			return matches, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/portmap"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+var (
+	defaultJavaPortRange    = portmap.Range{From: 25565, To: 25570}
+	defaultBedrockPortRange = portmap.Range{From: 19132, To: 19137}
+)
+
+func NewServerPortsCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ports",
+		Short: "List port allocations (server, RCON, query) and detect conflicts",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := context.Background()
+			var allocs []portmap.Allocation
+			var running map[string]bool
+			var cfg config.Config
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(loaded config.Config, client *api.Client) error {
+				cfg = loaded
+				var err error
+				allocs, running, err = usecases.NewServerPortsUseCase(client).Execute(ctx)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if len(allocs) == 0 {
+				cmd.Println("No servers found.")
+				return nil
+			}
+
+			checker := newPortChecker(cfg)
+			conflicts := portmap.Conflicts(allocs)
+			problems := 0
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PORT\tPROTO\tSERVER\tKIND\tSTATUS")
+			for _, alloc := range allocs {
+				status := styleSuccess.Render("ok")
+				if group, ok := conflicts[alloc.Key()]; ok {
+					status = styleError.Render("conflict: " + strings.Join(otherServers(group, alloc.Server), ", "))
+					problems++
+				} else if problem := checker.check(alloc, running[alloc.Server]); problem != "" {
+					status = styleWarning.Render(problem)
+					problems++
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", alloc.Port, alloc.Protocol, alloc.Server, alloc.Kind, status)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if problems > 0 {
+				cmd.Printf("\n%d port problem(s) found.\n", problems)
+			}
+			return nil
+		},
+	}
+}
+
+func otherServers(group []portmap.Allocation, self string) []string {
+	var names []string
+	seen := map[string]bool{self: true}
+	for _, alloc := range group {
+		if !seen[alloc.Server] {
+			seen[alloc.Server] = true
+			names = append(names, alloc.Server)
+		}
+	}
+	return names
+}
+
+// portChecker validates allocations against the host. In host network mode a
+// port must be bindable unless its own server is running; in bridge mode the
+// port must fall inside the range docker compose publishes.
+type portChecker struct {
+	hostMode     bool
+	javaRange    portmap.Range
+	bedrockRange portmap.Range
+}
+
+func newPortChecker(cfg config.Config) portChecker {
+	checker := portChecker{
+		hostMode:     strings.EqualFold(cfg.NetworkMode, "host"),
+		javaRange:    defaultJavaPortRange,
+		bedrockRange: defaultBedrockPortRange,
+	}
+	if values, err := loadLayeredEnvValues(cfg); err == nil {
+		checker.javaRange = portmap.ParseRange(values["MC_PORT_RANGE"], defaultJavaPortRange)
+		checker.bedrockRange = portmap.ParseRange(values["BEDROCK_PORT_RANGE"], defaultBedrockPortRange)
+	}
+	return checker
+}
+
+func (c portChecker) check(alloc portmap.Allocation, serverRunning bool) string {
+	if c.hostMode {
+		if !serverRunning && !hostPortFree(alloc.Port, alloc.Protocol) {
+			return "in use by a host process"
+		}
+		return ""
+	}
+	if alloc.Kind == portmap.KindRcon {
+		// RCON is usually reached from inside the container only.
+		return ""
+	}
+	published := c.javaRange
+	if alloc.Protocol == "udp" && (alloc.Kind == portmap.KindServer || alloc.Kind == portmap.KindServerV6) {
+		published = c.bedrockRange
+	}
+	if !published.Contains(alloc.Port) {
+		return "not published (outside " + published.String() + ")"
+	}
+	return ""
+}
+
+// free reports whether a port can be handed to a new server.
+func (c portChecker) free(port int, protocol string) bool {
+	if c.hostMode {
+		return hostPortFree(port, protocol)
+	}
+	return true
+}
+
+// hostPortFree binds the port on IPv4 and IPv6 separately, since a process
+// may hold it on one family only. Hosts without IPv6 skip that check.
+func hostPortFree(port int, protocol string) bool {
+	for _, family := range []struct{ suffix, host string }{{"4", "0.0.0.0"}, {"6", "::"}} {
+		address := net.JoinHostPort(family.host, strconv.Itoa(port))
+		var err error
+		if protocol == "udp" {
+			var conn net.PacketConn
+			if conn, err = net.ListenPacket("udp"+family.suffix, address); err == nil {
+				conn.Close()
+			}
+		} else {
+			var listener net.Listener
+			if listener, err = net.Listen("tcp"+family.suffix, address); err == nil {
+				listener.Close()
+			}
+		}
+		if err != nil && !(family.suffix == "6" && ipv6Unavailable(err)) {
+			return false
+		}
+	}
+	return true
+}
+
+func ipv6Unavailable(err error) bool {
+	return errors.Is(err, syscall.EAFNOSUPPORT) || errors.Is(err, syscall.EADDRNOTAVAIL) || errors.Is(err, syscall.EPROTONOSUPPORT)
+}
+
+// assignFreePorts moves a server's ports off any port already claimed by
+// another server or, in host network mode, by a host process. A non-zero
+// requestedPort pins the main server port.
+func assignFreePorts(ctx context.Context, client *api.Client, cfg config.Config, cmd *cobra.Command, name string, requestedPort int) error {
+	uc := usecases.NewServerPortsUseCase(client)
+	allocs, running, err := uc.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	used := map[string]map[int]bool{"tcp": {}, "udp": {}}
+	var own []portmap.Allocation
+	for _, alloc := range allocs {
+		if alloc.Server == name {
+			own = append(own, alloc)
+			continue
+		}
+		used[alloc.Protocol][alloc.Port] = true
+	}
+	if len(own) == 0 {
+		return nil
+	}
+
+	checker := newPortChecker(cfg)
+	changes := map[string]string{}
+	for _, alloc := range own {
+		port := alloc.Port
+		if requestedPort > 0 && alloc.Kind == portmap.KindServer {
+			if used[alloc.Protocol][requestedPort] {
+				return fmt.Errorf("port %d is already used by another server", requestedPort)
+			}
+			port = requestedPort
+		} else if used[alloc.Protocol][port] || (!running[name] && !checker.free(port, alloc.Protocol)) {
+			port = portmap.NextFree(port+1, used[alloc.Protocol], func(p int) bool {
+				return checker.free(p, alloc.Protocol)
+			})
+			if port == 0 {
+				return fmt.Errorf("no free %s port found for %s", alloc.Property, name)
+			}
+			cmd.Printf("%s %s port %d is taken; using %d\n", styleWarning.Render("Port conflict:"), alloc.Kind, alloc.Port, port)
+		}
+		used[alloc.Protocol][port] = true
+		if port != alloc.Port {
+			changes[alloc.Property] = strconv.Itoa(port)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	props, err := client.GetServerProperties(ctx, name)
+	if err != nil {
+		return err
+	}
+	for key, value := range changes {
+		props[key] = value
+	}
+	if err := client.UpdateServerProperties(ctx, name, props); err != nil {
+		return err
+	}
+	for key, value := range changes {
+		cmd.Printf("Set %s=%s for %s\n", key, value, name)
+	}
+	return nil
+}
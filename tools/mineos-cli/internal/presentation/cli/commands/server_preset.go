@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/preset"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	presetfile "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/preset"
+)
+
+func NewServerPresetCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preset",
+		Short: "Apply bundles of gamerules, difficulty and world border",
+		Long: `Presets set gamerules, difficulty and the world border of a running
+server through its console. Built in are hardcore, peaceful-creative, anarchy
+and uhc; more can be defined in ` + presetfile.DefaultFileName + ` next to the .env
+file, where a preset with a built-in's name replaces it:
+
+  presets:
+    event:
+      description: Weekend build event
+      difficulty: peaceful
+      gamerules:
+        doDaylightCycle: false
+        keepInventory: true
+      world_border:
+        center_x: 0
+        center_z: 0
+        size: 500
+      commands:
+        - time set noon
+
+'mineos agent --schedule' can apply them on a cron (see presets in
+'mineos state edit schedule').`,
+	}
+
+	cmd.AddCommand(newServerPresetListCommand(loadConfig))
+	cmd.AddCommand(newServerPresetShowCommand(loadConfig))
+	cmd.AddCommand(newServerPresetApplyCommand(loadConfig))
+
+	return cmd
+}
+
+func newServerPresetListCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List built-in and user presets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			presets, user, err := loadPresets(cmd.Context(), loadConfig)
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PRESET\tSOURCE\tDESCRIPTION")
+			for _, name := range preset.Names(presets) {
+				source := "built-in"
+				if _, ok := user.Presets[name]; ok {
+					source = "user"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", name, source, presets[name].Description)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newServerPresetShowCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <preset>",
+		Short: "Print the console commands a preset sends",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := findPreset(cmd.Context(), loadConfig, args[0])
+			if err != nil {
+				return err
+			}
+			printPresetCommands(cmd.OutOrStdout(), args[0], p)
+			return nil
+		},
+	}
+}
+
+func newServerPresetApplyCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "apply <server> <preset>",
+		Short: "Apply a preset to a running server",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			server, name := args[0], args[1]
+			p, err := findPreset(ctx, loadConfig, name)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				printPresetCommands(cmd.OutOrStdout(), name, p)
+				return nil
+			}
+
+			var sent []string
+			err = runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				sent, err = usecases.NewApplyPresetUseCase(client).Execute(ctx, server, p, func(command string) {
+					cmd.Printf("  %s %s\n", markOK(), command)
+				})
+				return err
+			})
+			if err != nil {
+				if len(sent) > 0 {
+					cmd.Printf("%s %d of %d commands were sent before the error.\n", styleWarning.Render("Warning:"), len(sent), len(p.ConsoleCommands()))
+				}
+				return err
+			}
+			cmd.Printf("Applied %s to %s. Check the replies with 'mineos servers logs %s'.\n", name, server, server)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the console commands without sending them")
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			names, _ := listServerNames(cmd, loadConfig)
+			return names, cobra.ShellCompDirectiveNoFileComp
+		case 1:
+			presets, _, _ := loadPresets(cmd.Context(), loadConfig)
+			return preset.Names(presets), cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// loadPresets returns the built-in presets merged with the user's, and the
+// user definition on its own.
+func loadPresets(ctx context.Context, loadConfig *usecases.LoadConfigUseCase) (map[string]preset.Preset, preset.Definition, error) {
+	cfg, err := loadConfig.Execute(ctx)
+	if err != nil {
+		return nil, preset.Definition{}, err
+	}
+	repo := presetfile.NewFileRepositoryForEnv(resolveEnvPath(cfg.EnvPath))
+	user, _, err := repo.Load()
+	if err != nil {
+		return nil, preset.Definition{}, fmt.Errorf("invalid presets %s: %w", repo.Path(), err)
+	}
+	return user.Merge(), user, nil
+}
+
+func findPreset(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, name string) (preset.Preset, error) {
+	presets, _, err := loadPresets(ctx, loadConfig)
+	if err != nil {
+		return preset.Preset{}, err
+	}
+	p, ok := presets[name]
+	if !ok {
+		return preset.Preset{}, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(preset.Names(presets), ", "))
+	}
+	return p, nil
+}
+
+func printPresetCommands(out io.Writer, name string, p preset.Preset) {
+	if p.Description != "" {
+		fmt.Fprintf(out, "%s: %s\n", name, p.Description)
+	}
+	for _, command := range p.ConsoleCommands() {
+		fmt.Fprintln(out, "  "+command)
+	}
+}
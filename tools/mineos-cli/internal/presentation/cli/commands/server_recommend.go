@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/java"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+// recommendHistoryWindow is how far back the metrics store is searched for
+// a server's peak memory use.
+const recommendHistoryWindow = 7 * 24 * time.Hour
+
+type heapRecommendResult struct {
+	Server      string `json:"server"`
+	CurrentXmx  int    `json:"currentXmxMb"`
+	CurrentXms  int    `json:"currentXmsMb"`
+	CurrentJVM  string `json:"currentFlags"`
+	MergedFlags string `json:"mergedFlags"`
+	java.HeapRecommendation
+	Applied bool `json:"applied"`
+}
+
+func NewServerRecommendCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var apply bool
+	var yes bool
+	var keepFlags bool
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "recommend <server>",
+		Short: "Suggest heap size and GC flags for a server",
+		Long: `Suggest -Xmx, -Xms and GC flags for a server from:
+
+  the workload      how many mods or plugins it runs
+  its history       the most memory it used over the last 7 days, from
+                    'mineos agent --record-metrics', or the last hour
+  the host          the memory Docker sees, less what the OS and the other
+                    running (or start-on-boot) servers are set to use
+
+The GC flags are Aikar's G1 flags. --apply writes the heap sizes and merges
+the flags into the server's JVM settings, keeping other options such as -D
+properties; --keep-flags changes only the heap. Restart the server to use
+them.`,
+		Example: `  mineos servers recommend survival
+  mineos servers recommend modpack --apply --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			name := args[0]
+			out := cmd.OutOrStdout()
+
+			var result heapRecommendResult
+			err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				detail, err := client.GetServer(ctx, name)
+				if err != nil {
+					return err
+				}
+				if detail.IsBedrock() {
+					return fmt.Errorf("%s is a Bedrock server; it does not run on Java and has no heap to size", name)
+				}
+				serverCfg, err := client.GetServerConfig(ctx, name)
+				if err != nil {
+					return err
+				}
+				input := heapInput(ctx, loadConfig, client, name, serverCfg)
+				tweaks := ""
+				if serverCfg.Java.JavaTweaks != nil {
+					tweaks = strings.TrimSpace(*serverCfg.Java.JavaTweaks)
+				}
+
+				result = heapRecommendResult{
+					Server:             name,
+					CurrentXmx:         serverCfg.Java.JavaXmx,
+					CurrentXms:         serverCfg.Java.JavaXms,
+					CurrentJVM:         tweaks,
+					HeapRecommendation: java.RecommendHeap(input),
+				}
+				result.MergedFlags = result.CurrentJVM
+				if !keepFlags {
+					result.MergedFlags = java.MergeFlags(result.CurrentJVM, result.Flags)
+				}
+				if !jsonOut {
+					printHeapRecommendation(out, result)
+				}
+				if !apply {
+					if !jsonOut {
+						fmt.Fprintln(out)
+						fmt.Fprintln(out, styleDim.Render(fmt.Sprintf("Run 'mineos servers recommend %s --apply' to use these settings.", name)))
+					}
+					return nil
+				}
+				if result.CurrentXmx == result.XmxMB && result.CurrentXms == result.XmsMB && result.MergedFlags == result.CurrentJVM {
+					if !jsonOut {
+						fmt.Fprintln(out, styleSuccess.Render("The server already uses these settings."))
+					}
+					return nil
+				}
+				if !yes {
+					ok, err := promptYesNo(nil, out, fmt.Sprintf("Write these settings to %s?", name), true)
+					if err != nil || !ok {
+						return err
+					}
+				}
+				serverCfg.Java.JavaXmx = result.XmxMB
+				serverCfg.Java.JavaXms = result.XmsMB
+				flags := result.MergedFlags
+				serverCfg.Java.JavaTweaks = &flags
+				if err := client.UpdateServerConfig(ctx, name, serverCfg); err != nil {
+					return err
+				}
+				result.Applied = true
+				if !jsonOut {
+					fmt.Fprintln(out, styleSuccess.Render(fmt.Sprintf("Updated the JVM settings of %s.", name)))
+					if detail.IsRunning() {
+						fmt.Fprintln(out, styleDim.Render("Restart the server to apply."))
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(result)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&apply, "apply", false, "Write the recommended settings to the server")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Apply without asking")
+	cmd.Flags().BoolVar(&keepFlags, "keep-flags", false, "Only change the heap sizes and leave the JVM flags alone")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the recommendation as JSON")
+
+	return cmd
+}
+
+// heapInput gathers what the recommendation is based on. Anything that
+// cannot be read is left out rather than failing the command.
+func heapInput(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, client *api.Client, name string, serverCfg ports.ServerConfig) java.HeapInput {
+	input := java.HeapInput{CurrentXmx: serverCfg.Java.JavaXmx}
+
+	if compose, _, err := loadComposeAndConfig(ctx, loadConfig); err == nil {
+		if host, err := compose.dockerHost(ctx); err == nil {
+			input.HostMemoryMB = host.MemTotal >> 20
+		}
+	}
+
+	if servers, err := client.ListServers(ctx); err == nil {
+		for _, server := range servers {
+			if server.Name == name {
+				continue
+			}
+			other, err := client.GetServerConfig(ctx, server.Name)
+			if err != nil || other.Java.JavaXmx <= 0 {
+				continue
+			}
+			// Stopped servers only count when they come back on their own.
+			if server.Status != "running" && !other.OnReboot.Start {
+				continue
+			}
+			input.OtherServers++
+			input.OtherServersMB += int64(other.Java.JavaXmx)
+		}
+	}
+
+	if mods, err := client.ListMods(ctx, name); err == nil {
+		input.Mods = len(mods)
+	}
+	if plugins, err := client.ListPlugins(ctx, name); err == nil {
+		input.Plugins = len(plugins)
+	}
+
+	samples, _ := localHistory(ctx, loadConfig, name, recommendHistoryWindow)
+	input.HistorySpan = "7 days"
+	if len(samples) == 0 {
+		samples, _ = client.GetPerformanceHistory(ctx, name, 60)
+		input.HistorySpan = "the last hour"
+	}
+	for _, sample := range samples {
+		if sample.IsRunning && sample.RamUsedMb > input.PeakUsedMB {
+			input.PeakUsedMB = sample.RamUsedMb
+		}
+	}
+	return input
+}
+
+func printHeapRecommendation(out io.Writer, result heapRecommendResult) {
+	current := func(mb int) string {
+		if mb <= 0 {
+			return styleDim.Render("not set")
+		}
+		return java.FormatMB(int64(mb))
+	}
+	change := func(from, to int) string {
+		if from == to {
+			return java.FormatMB(int64(to)) + styleDim.Render(" (unchanged)")
+		}
+		return current(from) + " " + arrow() + " " + styleInfo.Render(java.FormatMB(int64(to)))
+	}
+
+	printStat(out, "Server", result.Server)
+	printStat(out, "Max heap", change(result.CurrentXmx, result.XmxMB))
+	printStat(out, "Min heap", change(result.CurrentXms, result.XmsMB))
+	flags := styleDim.Render("unchanged")
+	if result.MergedFlags != result.CurrentJVM {
+		flags = styleInfo.Render("Aikar's G1 flags") + styleDim.Render(fmt.Sprintf(" (%d flags)", len(strings.Fields(result.Flags))))
+	}
+	printStat(out, "GC flags", flags)
+
+	fmt.Fprintln(out)
+	for _, reason := range result.Reasons {
+		fmt.Fprintln(out, "  "+styleDim.Render(bullet()+" "+reason))
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintln(out, "  "+styleWarning.Render("! "+warning))
+	}
+	if result.MergedFlags != result.CurrentJVM {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, styleDim.Render("JVM flags after applying:"))
+		fmt.Fprintln(out, "  "+result.MergedFlags)
+	}
+}
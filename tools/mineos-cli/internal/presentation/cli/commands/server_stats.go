@@ -0,0 +1,221 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+func NewServerStatsCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var watch bool
+	var interval time.Duration
+	var history string
+	var csvPath string
+
+	cmd := &cobra.Command{
+		Use:   "stats <server>",
+		Short: "Show server metrics (CPU, memory, players, TPS, world size)",
+		Long: `Show the current metrics of a server with graphs of TPS, players and memory
+over the --history window.
+
+The graphs come from the metrics history that 'mineos agent
+--record-metrics' keeps in the installation's state store when it has samples for
+the window, and from the API's recent history otherwise. --csv writes
+the history samples instead, for analysis in a spreadsheet.`,
+		Example: `  mineos servers stats survival
+  mineos servers stats survival --history 24h
+  mineos servers stats survival --history 7d --csv survival.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serverName := args[0]
+			out := cmd.OutOrStdout()
+			window, err := parseHistoryWindow(history)
+			if err != nil {
+				return err
+			}
+
+			fetch := func(ctx context.Context) (ports.ServerStats, error) {
+				var stats ports.ServerStats
+				local, err := localHistory(ctx, loadConfig, serverName, window)
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s %v\n", styleWarning.Render("Note:"), err)
+				}
+				apiMinutes := int(window.Minutes())
+				if len(local) > 0 {
+					apiMinutes = 0
+				}
+				_, err = withApiKeyRetry(ctx, loadConfig, out, func(_ config.Config, client *api.Client) error {
+					uc := usecases.NewServerStatsUseCase(client)
+					result, err := uc.Execute(ctx, serverName, apiMinutes)
+					if err != nil {
+						return err
+					}
+					stats = result
+					return nil
+				})
+				if len(local) > 0 {
+					stats.TpsHistory = local
+				}
+				return stats, err
+			}
+
+			if csvPath != "" {
+				stats, err := fetch(context.Background())
+				if err != nil {
+					return err
+				}
+				return writeHistoryCSV(csvPath, out, stats.TpsHistory)
+			}
+
+			if !watch {
+				stats, err := fetch(context.Background())
+				if err != nil {
+					return err
+				}
+				renderServerStats(out, serverName, stats)
+				return nil
+			}
+
+			if interval < time.Second {
+				interval = time.Second
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				stats, err := fetch(ctx)
+				if ctx.Err() != nil {
+					return nil
+				}
+				if !machineMode {
+					fmt.Fprint(out, "\033[H\033[2J")
+				}
+				if err != nil {
+					fmt.Fprintf(out, "%s\n", styleError.Render("Error: "+err.Error()))
+				} else {
+					renderServerStats(out, serverName, stats)
+				}
+				fmt.Fprintf(out, "\n%s\n", styleDim.Render(fmt.Sprintf("Refreshing every %s. Press Ctrl+C to stop.", interval)))
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Continuously refresh the stats")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Refresh interval when using --watch")
+	cmd.Flags().StringVar(&history, "history", "1h", "History window for the graphs, e.g. 1h, 24h or 7d; a plain number is minutes (0 to disable)")
+	cmd.Flags().StringVar(&csvPath, "csv", "", "Write the history samples as CSV to a file ('-' for stdout)")
+
+	return cmd
+}
+
+func renderServerStats(out io.Writer, name string, stats ports.ServerStats) {
+	sample := stats.Performance
+
+	status := styleError.Render("stopped")
+	if sample.IsRunning {
+		status = styleSuccess.Render("running")
+	}
+
+	fmt.Fprintf(out, "%s\n\n", styleTitle.Render("Server stats: "+name))
+	printStat(out, "Status", status)
+	if !sample.Timestamp.IsZero() {
+		printStat(out, "Sampled", sample.Timestamp.Local().Format("2006-01-02 15:04:05"))
+	}
+	printStat(out, "CPU", fmt.Sprintf("%.1f%%", sample.CpuPercent))
+	if sample.RamTotalMb > 0 {
+		printStat(out, "Memory", fmt.Sprintf("%d MB / %d MB (%.0f%%)", sample.RamUsedMb, sample.RamTotalMb,
+			float64(sample.RamUsedMb)/float64(sample.RamTotalMb)*100))
+	} else {
+		printStat(out, "Memory", fmt.Sprintf("%d MB", sample.RamUsedMb))
+	}
+	if stats.Memory.ResidentMemory > 0 {
+		printStat(out, "Resident", formatBytes(stats.Memory.ResidentMemory))
+	}
+	printStat(out, "Players", fmt.Sprintf("%d", sample.PlayerCount))
+	if sample.Tps != nil {
+		printStat(out, "TPS", fmt.Sprintf("%.1f", *sample.Tps))
+	} else {
+		printStat(out, "TPS", styleDim.Render("n/a"))
+	}
+
+	if len(stats.Worlds) > 0 {
+		var total int64
+		for _, world := range stats.Worlds {
+			total += world.SizeBytes
+		}
+		printStat(out, "World size", formatBytes(total))
+		for _, world := range stats.Worlds {
+			fmt.Fprintf(out, "  %s %s\n", styleDim.Render(fmt.Sprintf("%-20s", world.Name)), formatBytes(world.SizeBytes))
+		}
+	}
+
+	renderHistory(out, stats.TpsHistory)
+}
+
+func printStat(out io.Writer, label, value string) {
+	label = fmt.Sprintf("%-12s", label)
+	fmt.Fprintf(out, "  %s %s\n", styleLabel.Render(label), value)
+}
+
+// sparkline renders values as block characters scaled between low and high,
+// keeping only the most recent width points. NaN values are gaps. In
+// accessible mode it describes the points in words instead.
+func sparkline(values []float64, low, high float64, width int) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	if accessibleMode {
+		return describeSeries(values)
+	}
+	var b strings.Builder
+	for _, value := range values {
+		if math.IsNaN(value) {
+			b.WriteRune(' ')
+			continue
+		}
+		ratio := 0.0
+		if high > low {
+			ratio = (value - low) / (high - low)
+		}
+		idx := int(ratio * float64(len(blocks)-1))
+		idx = max(0, min(idx, len(blocks)-1))
+		b.WriteRune(blocks[idx])
+	}
+	return b.String()
+}
+
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+const historyGraphWidth = 60
+
+// parseHistoryWindow reads --history: a duration such as "24h", days as
+// "7d", or a plain number of minutes as the flag took before.
+func parseHistoryWindow(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if minutes, err := strconv.Atoi(value); err == nil && minutes >= 0 {
+		return time.Duration(minutes) * time.Minute, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid --history %q (use e.g. 60, 1h, 24h or 7d)", value)
+}
+
+// localHistory reads the window from the metrics history in the state
+// store. It returns nothing when there are no samples, which is the normal
+// case without 'agent --record-metrics', and under --ssh, where the history
+// is on the host.
+func localHistory(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, server string, window time.Duration) ([]ports.PerformanceSample, error) {
+	if window <= 0 || sshRemote != nil {
+		return nil, nil
+	}
+	cfg, err := loadConfig.Execute(ctx)
+	if err != nil {
+		return nil, nil
+	}
+	envPath := resolveEnvPath(cfg.EnvPath)
+	store, err := state.Open(state.PathForEnv(envPath))
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	history, err := store.Metrics(ctx, envPath)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := history.Query(ctx, server, time.Now().Add(-window))
+	if err != nil {
+		return nil, fmt.Errorf("metrics history in %s: %w", store.Path(), err)
+	}
+	return samples, nil
+}
+
+func renderHistory(out io.Writer, samples []ports.PerformanceSample) {
+	if len(samples) == 0 {
+		return
+	}
+	span := samples[len(samples)-1].Timestamp.Sub(samples[0].Timestamp).Round(time.Minute)
+	fmt.Fprintln(out)
+	printStat(out, "History", styleDim.Render(fmt.Sprintf("%s, %d samples", strings.TrimSuffix(span.String(), "0s"), len(samples))))
+
+	tps, tpsValues := historySeries(samples, func(s ports.PerformanceSample) (float64, bool) {
+		if s.Tps == nil {
+			return 0, false
+		}
+		return *s.Tps, true
+	})
+	players, playerValues := historySeries(samples, func(s ports.PerformanceSample) (float64, bool) {
+		return float64(s.PlayerCount), s.IsRunning
+	})
+	memory, memoryValues := historySeries(samples, func(s ports.PerformanceSample) (float64, bool) {
+		return float64(s.RamUsedMb), s.IsRunning
+	})
+
+	if len(tpsValues) > 0 {
+		printStat(out, "TPS", sparkline(tps, 0, 20, historyGraphWidth))
+		printStat(out, "", seriesSummary(tpsValues, "%.1f"))
+	}
+	if len(playerValues) > 0 {
+		_, _, peak := seriesRange(playerValues)
+		printStat(out, "Players", sparkline(players, 0, max(peak, 1), historyGraphWidth))
+		printStat(out, "", seriesSummary(playerValues, "%.0f"))
+	}
+	if len(memoryValues) > 0 {
+		_, _, peak := seriesRange(memoryValues)
+		for _, sample := range samples {
+			peak = max(peak, float64(sample.RamTotalMb))
+		}
+		printStat(out, "Memory", sparkline(memory, 0, max(peak, 1), historyGraphWidth))
+		printStat(out, "", seriesSummary(memoryValues, "%.0f MB"))
+	}
+}
+
+// historySeries spreads the picked values over historyGraphWidth columns by
+// time, averaging the samples in each column; columns without a value (the
+// server was stopped) are NaN. The raw values are returned for the summary.
+func historySeries(samples []ports.PerformanceSample, pick func(ports.PerformanceSample) (float64, bool)) ([]float64, []float64) {
+	var values []float64
+	if len(samples) <= historyGraphWidth {
+		series := make([]float64, len(samples))
+		for i, sample := range samples {
+			series[i] = math.NaN()
+			if value, ok := pick(sample); ok {
+				series[i] = value
+				values = append(values, value)
+			}
+		}
+		return series, values
+	}
+
+	first := samples[0].Timestamp
+	span := samples[len(samples)-1].Timestamp.Sub(first)
+	sums := make([]float64, historyGraphWidth)
+	counts := make([]int, historyGraphWidth)
+	for i, sample := range samples {
+		value, ok := pick(sample)
+		if !ok {
+			continue
+		}
+		values = append(values, value)
+		column := i * historyGraphWidth / len(samples)
+		if span > 0 {
+			column = int(float64(sample.Timestamp.Sub(first)) / float64(span) * float64(historyGraphWidth-1))
+		}
+		column = max(0, min(column, historyGraphWidth-1))
+		sums[column] += value
+		counts[column]++
+	}
+	series := make([]float64, historyGraphWidth)
+	for i := range series {
+		series[i] = math.NaN()
+		if counts[i] > 0 {
+			series[i] = sums[i] / float64(counts[i])
+		}
+	}
+	return series, values
+}
+
+func seriesRange(values []float64) (low, avg, high float64) {
+	low, high = values[0], values[0]
+	sum := 0.0
+	for _, value := range values {
+		low = min(low, value)
+		high = max(high, value)
+		sum += value
+	}
+	return low, sum / float64(len(values)), high
+}
+
+func seriesSummary(values []float64, format string) string {
+	low, avg, high := seriesRange(values)
+	return styleDim.Render(fmt.Sprintf("min "+format+"  avg "+format+"  max "+format, low, avg, high))
+}
+
+// writeHistoryCSV writes one row per sample to path, or to out for "-".
+func writeHistoryCSV(path string, out io.Writer, samples []ports.PerformanceSample) error {
+	target := out
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		target = file
+	}
+
+	w := csv.NewWriter(target)
+	_ = w.Write([]string{"timestamp", "running", "cpu_percent", "ram_used_mb", "ram_total_mb", "players", "tps"})
+	for _, sample := range samples {
+		tps := ""
+		if sample.Tps != nil {
+			tps = strconv.FormatFloat(*sample.Tps, 'f', 2, 64)
+		}
+		_ = w.Write([]string{
+			sample.Timestamp.UTC().Format(time.RFC3339),
+			strconv.FormatBool(sample.IsRunning),
+			strconv.FormatFloat(sample.CpuPercent, 'f', 1, 64),
+			strconv.FormatInt(sample.RamUsedMb, 10),
+			strconv.FormatInt(sample.RamTotalMb, 10),
+			strconv.Itoa(sample.PlayerCount),
+			tps,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	if path != "-" && !machineMode {
+		fmt.Fprintf(out, "%s Wrote %d samples to %s\n", markOK(), len(samples), path)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+func NewServerTagsCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tags",
+		Short: "Manage server tags used by --tag selections",
+	}
+
+	cmd.AddCommand(newServerTagsListCommand(loadConfig))
+	cmd.AddCommand(newServerTagsEditCommand(loadConfig, "add"))
+	cmd.AddCommand(newServerTagsEditCommand(loadConfig, "remove"))
+
+	return cmd
+}
+
+func newServerTagsListCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list [server]",
+		Short: "Show tags for one or all servers",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			tagged := map[string][]string{}
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+				names := args
+				if len(names) == 0 {
+					servers, err := client.ListServers(ctx)
+					if err != nil {
+						return err
+					}
+					for _, server := range servers {
+						names = append(names, server.Name)
+					}
+				}
+				uc := usecases.NewServerTagsUseCase(client)
+				for _, name := range names {
+					tags, err := uc.Get(ctx, name)
+					if err != nil {
+						return err
+					}
+					tagged[name] = tags
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			names := make([]string, 0, len(tagged))
+			for name := range tagged {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				tags := strings.Join(tagged[name], ",")
+				if tags == "" {
+					tags = "-"
+				}
+				cmd.Printf("%s\t%s\n", name, tags)
+			}
+			return nil
+		},
+	}
+}
+
+func newServerTagsEditCommand(loadConfig *usecases.LoadConfigUseCase, op string) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   op + " <server> <tag>...",
+		Short: op + " tags on a server",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			var updated []string
+			var changed bool
+			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+				uc := usecases.NewServerTagsUseCase(client)
+				var err error
+				if op == "add" {
+					updated, changed, err = uc.Add(ctx, args[0], args[1:]...)
+				} else {
+					updated, changed, err = uc.Remove(ctx, args[0], args[1:]...)
+				}
+				return err
+			})
+			tags := strings.Join(updated, ",")
+			if tags == "" {
+				tags = "(none)"
+			}
+			if jsonOut {
+				if err != nil {
+					return printChangeResult(cmd, changeResult{Failed: true, Msg: err.Error()})
+				}
+				return printChangeResult(cmd, changeResult{Changed: changed, Msg: args[0] + " tags: " + tags})
+			}
+			if err != nil {
+				return err
+			}
+			suffix := ""
+			if !changed {
+				suffix = styleDim.Render(" (unchanged)")
+			}
+			cmd.Printf("%s tags: %s%s\n", args[0], tags, suffix)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print a changed/failed result as JSON")
+
+	return cmd
+}
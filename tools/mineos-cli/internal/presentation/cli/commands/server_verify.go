@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/integrity"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/upstream"
+)
+
+func NewServerVerifyCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var jsonOut bool
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "verify <server>",
+		Short: "Check the server jar, mods and plugins against upstream hashes",
+		Long: `Hash the server jar, mods and plugins and compare them with what their
+vendors publish: Mojang for vanilla jars, PaperMC for Paper builds, Modrinth
+for mods and plugins, and the uploaded file for custom profiles.
+
+A mismatch means a file claims to be a published one but differs from it:
+it was tampered with or is corrupted. Files that cannot be checked (mods from
+CurseForge or built by hand, jars not installed from a MineOS profile) are
+reported as unknown. Exits with status 1 on a mismatch, or with --strict on
+unknown files too.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			var report integrity.Report
+			if err := runWithClient(ctx, loadConfig, cmd, func(client *api.Client) error {
+				var err error
+				report, err = usecases.NewVerifyServerUseCase(client, upstream.NewClient()).Execute(ctx, args[0])
+				return err
+			}); err != nil {
+				return err
+			}
+
+			if jsonOut {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				printVerifyReport(cmd.OutOrStdout(), report)
+			}
+
+			if report.Count(integrity.Mismatch) > 0 || (strict && report.Count(integrity.Unknown) > 0) {
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
+				return exitCodeError{code: exitFailure}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Also fail when a file cannot be checked")
+
+	return cmd
+}
+
+func printVerifyReport(out io.Writer, report integrity.Report) {
+	if len(report.Checks) == 0 {
+		fmt.Fprintf(out, "%s has no server jar, mods or plugins to check.\n", report.Server)
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tFILE\tDETAIL")
+	for _, check := range report.Checks {
+		var status string
+		switch check.Status {
+		case integrity.Verified:
+			status = styleSuccess.Render("ok")
+		case integrity.Mismatch:
+			status = styleError.Render("MISMATCH")
+		default:
+			status = styleDim.Render("unknown")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status, check.Path, check.Detail)
+	}
+	w.Flush()
+
+	summary := fmt.Sprintf("%d verified, %d unknown", report.Count(integrity.Verified), report.Count(integrity.Unknown))
+	if mismatched := report.Count(integrity.Mismatch); mismatched > 0 {
+		fmt.Fprintf(out, "\n%s %d file(s) do not match upstream; %s. Replace them from a trusted source.\n", markFailed(), mismatched, summary)
+		return
+	}
+	fmt.Fprintf(out, "\n%s No mismatches; %s.\n", markOK(), summary)
+}
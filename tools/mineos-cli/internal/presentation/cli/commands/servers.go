@@ -2,8 +2,14 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -20,8 +26,31 @@ func NewServersCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 	}
 
 	cmd.AddCommand(NewServersListCommand(loadConfig))
+	cmd.AddCommand(NewServerCreateCommand(loadConfig))
+	cmd.AddCommand(NewServerImportCommand(loadConfig))
+	cmd.AddCommand(NewServerAcceptEulaCommand(loadConfig))
+	cmd.AddCommand(NewServerPortsCommand(loadConfig))
 	cmd.AddCommand(NewServersStopAllCommand(loadConfig))
+	cmd.AddCommand(NewServerAutostartCommand(loadConfig))
 	cmd.AddCommand(NewServerLogsCommand(loadConfig))
+	cmd.AddCommand(NewServerConsoleCommand(loadConfig))
+	cmd.AddCommand(NewServerVerifyCommand(loadConfig))
+	cmd.AddCommand(NewServerCrashesCommand(loadConfig))
+	cmd.AddCommand(NewServerStatsCommand(loadConfig))
+	cmd.AddCommand(NewServerRecommendCommand(loadConfig))
+	cmd.AddCommand(NewServerTagsCommand(loadConfig))
+	cmd.AddCommand(NewServerBackupCommand(loadConfig))
+	cmd.AddCommand(NewServerRestoreCommand(loadConfig))
+	cmd.AddCommand(NewServerDeleteCommand(loadConfig))
+	cmd.AddCommand(NewServerUndeleteCommand(loadConfig))
+	cmd.AddCommand(NewServerTrashCommand(loadConfig))
+	cmd.AddCommand(NewServerMotdCommand(loadConfig))
+	cmd.AddCommand(NewServerPresetCommand(loadConfig))
+	cmd.AddCommand(NewServerEnvCommand(loadConfig))
+	cmd.AddCommand(NewServerMaintenanceCommand(loadConfig))
+	cmd.AddCommand(NewServerIconCommand(loadConfig))
+	cmd.AddCommand(NewServerBanCommand(loadConfig))
+	cmd.AddCommand(NewServerPardonCommand(loadConfig))
 	cmd.AddCommand(NewServerActionCommand(loadConfig, "start"))
 	cmd.AddCommand(NewServerActionCommand(loadConfig, "stop"))
 	cmd.AddCommand(NewServerActionCommand(loadConfig, "restart"))
@@ -63,59 +92,320 @@ func NewServersListCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Comman
 }
 
 func NewServersStopAllCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
-	var timeout int
+	var opts usecases.StopAllOptions
+	var jsonOut bool
 
 	cmd := &cobra.Command{
 		Use:   "stop-all",
 		Short: "Stop all running servers",
+		Long: `Stop every running server at once, showing each one as it is saved,
+stopped and done. Servers that take longer than --timeout to stop are
+reported and left to the API; --server-timeout gives slow servers (large
+modpacks) more time.
+
+--exclude keeps matching servers up, e.g. a lobby during maintenance. With
+a network definition (mineos-network.yaml) servers stop in network order
+instead.`,
+		Example: `  mineos servers stop-all
+  mineos servers stop-all --exclude lobby --server-timeout modpack=900
+  mineos servers stop-all --exclude 'lobby-*' --parallel 2`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := context.Background()
+			out := cmd.OutOrStdout()
 			var result ports.StopAllResult
-			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
-				uc := usecases.NewStopAllServersUseCase(client)
-				stopResult, err := uc.Execute(ctx, timeout)
+			ordered := false
+			_, err := withApiKeyRetry(ctx, loadConfig, out, func(cfg config.Config, client *api.Client) error {
+				servers, err := client.ListServers(ctx)
 				if err != nil {
 					return err
 				}
-				result = stopResult
+				known := map[string]bool{}
+				var included []ports.Server
+				for _, server := range servers {
+					known[server.Name] = true
+					if !opts.Excluded(server.Name) {
+						included = append(included, server)
+					}
+				}
+				for name := range opts.Timeouts {
+					if !known[name] {
+						return fmt.Errorf("--server-timeout: no server named %q", name)
+					}
+				}
+				if handled, err := stopServersInOrder(ctx, client, cfg, included, out); handled || err != nil {
+					ordered = handled
+					return err
+				}
+
+				running := opts.Running(servers)
+				if !jsonOut {
+					if excluded := excludedRunning(servers, opts); len(excluded) > 0 {
+						fmt.Fprintf(out, "%s %s\n", styleDim.Render("Leaving running:"), strings.Join(excluded, ", "))
+					}
+					if len(running) == 0 {
+						fmt.Fprintln(out, "No running servers to stop.")
+					}
+				}
+				var table *serviceTable
+				if !jsonOut && len(running) > 0 {
+					table = newServiceTable(out, usecases.StopStopping, running)
+					opts.OnPhase = func(name, phase string, err error) {
+						switch phase {
+						case usecases.StopSaving, usecases.StopStopping:
+							table.Phase(name, phase)
+						default:
+							table.Finish(name, err)
+						}
+					}
+				}
+				result = usecases.NewStopAllServersUseCase(client).Stream(ctx, servers, opts)
 				return nil
 			})
-			if err != nil {
+			if err != nil || ordered {
 				return err
 			}
-			cmd.Printf("Total: %d, running: %d, stopped: %d, skipped: %d\n", result.Total, result.Running, result.Stopped, result.Skipped)
-			for _, item := range result.Results {
-				if item.Error != "" {
-					cmd.Printf("%s\t%s\t%s\n", item.Name, item.Status, item.Error)
-				} else {
-					cmd.Printf("%s\t%s\n", item.Name, item.Status)
+			if jsonOut {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(result); err != nil {
+					return err
+				}
+			} else if result.Running > 0 {
+				fmt.Fprintf(out, "Stopped %d of %d running server(s).\n", result.Stopped, result.Running)
+			}
+			if failed := result.Running - result.Stopped; failed > 0 {
+				message := fmt.Sprintf("%d server(s) did not stop", failed)
+				if result.Stopped > 0 {
+					return exitCodeError{code: exitPartial, message: message}
 				}
+				return errors.New(message)
 			}
 			return nil
 		},
 	}
 
-	cmd.Flags().IntVar(&timeout, "timeout", 300, "Shutdown timeout in seconds")
+	cmd.Flags().IntVar(&opts.TimeoutSeconds, "timeout", 300, "Shutdown timeout in seconds")
+	cmd.Flags().StringToIntVar(&opts.Timeouts, "server-timeout", nil, "Shutdown timeout for individual servers, e.g. modpack=900 (repeatable)")
+	cmd.Flags().StringSliceVar(&opts.Exclude, "exclude", nil, "Leave these servers running (names or glob patterns, repeatable)")
+	cmd.Flags().IntVar(&opts.Parallel, "parallel", 0, "Stop at most this many servers at once (default: all)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the result as JSON")
 
 	return cmd
 }
 
+// excludedRunning lists the running servers opts leaves up.
+func excludedRunning(servers []ports.Server, opts usecases.StopAllOptions) []string {
+	var names []string
+	for _, server := range servers {
+		if strings.EqualFold(server.Status, "running") && opts.Excluded(server.Name) {
+			names = append(names, server.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func NewServerActionCommand(loadConfig *usecases.LoadConfigUseCase, action string) *cobra.Command {
-	return &cobra.Command{
-		Use:   fmt.Sprintf("%s <name>", action),
-		Short: fmt.Sprintf("%s a server", action),
-		Args:  cobra.ExactArgs(1),
+	var all bool
+	var tag string
+	var parallel int
+	var whenEmpty bool
+	var ensure bool
+	var jsonOut bool
+	deferred := usecases.DefaultDeferredRestartOptions()
+
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("%s <name|pattern>...", action),
+		Short: fmt.Sprintf("%s one or more servers", action),
+		Long: fmt.Sprintf(`%s servers by name.
+
+Targets may be literal names, glob patterns (quote them, e.g. "lobby-*"),
+--all for every server, or --tag to select servers carrying a tag.
+Patterns and --tag can be combined to narrow the selection.`, action),
+		Example: fmt.Sprintf(`  mineos servers %[1]s survival
+  mineos servers %[1]s "lobby-*"
+  mineos servers %[1]s --tag minigames --parallel 2`, action),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
+			selector := usecases.ServerSelector{All: all, Tag: tag, Patterns: args}
+			if selector.IsEmpty() {
+				return fmt.Errorf("specify a server name, a glob pattern, --all or --tag")
+			}
+
+			single := len(args) == 1 && !all && tag == "" && !isGlobPattern(args[0])
+			if whenEmpty {
+				if !single {
+					return fmt.Errorf("--when-empty restarts a single server; pass one literal name")
+				}
+				return runDeferredRestart(ctx, loadConfig, cmd, args[0], deferred)
+			}
+
+			if single {
+				changed := true
+				out := cmd.OutOrStdout()
+				var t *task
+				if !jsonOut {
+					t = startTask(out, strings.ToUpper(action[:1])+action[1:]+" "+args[0])
+					out = t
+				}
+				_, err := withApiKeyRetry(ctx, loadConfig, out, func(_ config.Config, client *api.Client) error {
+					uc := usecases.NewServerActionUseCase(client)
+					if ensure {
+						var err error
+						changed, err = uc.Ensure(ctx, args[0], action)
+						return err
+					}
+					return uc.Execute(ctx, args[0], action)
+				})
+				if jsonOut {
+					result := changeResult{Changed: err == nil && changed, Msg: fmt.Sprintf("%s: %s", action, args[0])}
+					switch {
+					case err != nil:
+						result.Failed = true
+						result.Msg = withEulaHint(err, args[0]).Error()
+					case !changed:
+						result.Msg = args[0] + " is already " + ensuredState(action)
+					}
+					return printChangeResult(cmd, result)
+				}
+				if err != nil {
+					t.Fail()
+					return withEulaHint(err, args[0])
+				}
+				if !changed {
+					t.finish()
+					cmd.Printf("%s is already %s\n", args[0], ensuredState(action))
+					return nil
+				}
+				t.Done("")
+				return nil
+			}
+
+			var results []ports.BulkActionResult
+			started := time.Now()
 			_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
-				uc := usecases.NewServerActionUseCase(client)
-				return uc.Execute(ctx, args[0], action)
+				names, err := usecases.NewSelectServersUseCase(client).Execute(ctx, selector)
+				if err != nil {
+					return err
+				}
+				if len(names) == 0 {
+					return ports.WithKind(errors.New("no servers matched the selection"), ports.ErrNotFound)
+				}
+				progress := decor(cmd.OutOrStdout())
+				if jsonOut {
+					progress = io.Discard
+				}
+				fmt.Fprintf(progress, "Running %s on %d server(s) (parallel: %d)...\n", action, len(names), parallel)
+				bulk := usecases.NewBulkServerActionUseCase(client)
+				run := bulk.Execute
+				if ensure {
+					run = bulk.Ensure
+				}
+				results = run(ctx, names, action, parallel, func(result ports.BulkActionResult) {
+					switch {
+					case result.Err != nil:
+						fmt.Fprintf(progress, "  %s %s\n", markFailed(), result.Name)
+					case result.Unchanged:
+						fmt.Fprintf(progress, "  %s %s %s\n", styleDim.Render("-"), result.Name, styleDim.Render("(already "+ensuredState(action)+")"))
+					default:
+						fmt.Fprintf(progress, "  %s %s\n", markOK(), result.Name)
+					}
+				})
+				return nil
 			})
+			if jsonOut {
+				if err != nil {
+					return printChangeResult(cmd, changeResult{Failed: true, Msg: err.Error()})
+				}
+				return printChangeResult(cmd, bulkChangeResult(action, results))
+			}
 			if err != nil {
 				return err
 			}
-			cmd.Printf("%s: %s\n", action, args[0])
-			return nil
+
+			return printBulkSummary(cmd.OutOrStdout(), results, time.Since(started))
 		},
 	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Target every server")
+	cmd.Flags().StringVar(&tag, "tag", "", "Target servers carrying this tag")
+	cmd.Flags().IntVar(&parallel, "parallel", 4, "Maximum number of servers acted on concurrently")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print a changed/failed result as JSON")
+	if usecases.CanEnsure(action) {
+		cmd.Flags().BoolVar(&ensure, "ensure", false, fmt.Sprintf("Succeed without acting on servers that are already %s", ensuredState(action)))
+		cmd.Example += fmt.Sprintf(`
+  mineos servers %s survival --ensure --json`, action)
+	}
+	if action == "restart" {
+		cmd.Flags().BoolVar(&whenEmpty, "when-empty", false, "Defer the restart while players are online, broadcasting warnings")
+		cmd.Flags().DurationVar(&deferred.MaxDelay, "max-delay", deferred.MaxDelay, "Longest the restart is deferred with --when-empty")
+		cmd.Flags().DurationVar(&deferred.Recheck, "recheck", deferred.Recheck, "How often the player count is re-checked with --when-empty")
+		cmd.Flags().DurationSliceVar(&deferred.Warnings, "warn", deferred.Warnings, "Countdown warnings broadcast before a forced restart")
+		cmd.Flags().StringVar(&deferred.Message, "message", "", "Reason included in restart broadcasts")
+		cmd.Example += `
+  mineos servers restart survival --when-empty --max-delay 1h --message "weekly maintenance"`
+	}
+
+	return cmd
+}
+
+// runDeferredRestart waits for a server to empty (or for --max-delay to run
+// out) before restarting it.
+func runDeferredRestart(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, cmd *cobra.Command, name string, opts usecases.DeferredRestartOptions) error {
+	var restarted bool
+	_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+		var err error
+		restarted, err = usecases.NewDeferredRestartUseCase(client).Execute(ctx, name, opts, func(msg string) {
+			cmd.Printf("  %s %s\n", styleDim.Render(time.Now().Format("15:04:05")), msg)
+		})
+		return err
+	})
+	if err != nil {
+		return withEulaHint(err, name)
+	}
+	if restarted {
+		cmd.Printf("restart: %s\n", name)
+	}
+	return nil
+}
+
+// ensuredState is the state an ensured action leaves a server in.
+func ensuredState(action string) string {
+	if action == "start" {
+		return "running"
+	}
+	return "stopped"
+}
+
+func isGlobPattern(value string) bool {
+	return strings.ContainsAny(value, "*?[")
+}
+
+func printBulkSummary(out io.Writer, results []ports.BulkActionResult, took time.Duration) error {
+	failed := 0
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(decor(out))
+	fmt.Fprintln(w, "SERVER\tACTION\tRESULT\tDURATION")
+	for _, result := range results {
+		status := "ok"
+		switch {
+		case result.Err != nil:
+			failed++
+			status = "failed: " + result.Err.Error()
+		case result.Unchanged:
+			status = "unchanged"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Name, result.Action, status, result.Duration.Round(100*time.Millisecond))
+	}
+	w.Flush()
+
+	printSummary(out, len(results)-failed, failed, took)
+	if failed > 0 {
+		message := fmt.Sprintf("%d of %d server action(s) failed", failed, len(results))
+		if failed < len(results) {
+			return exitCodeError{code: exitPartial, message: message}
+		}
+		return errors.New(message)
+	}
+	return nil
 }
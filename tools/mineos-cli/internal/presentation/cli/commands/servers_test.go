@@ -0,0 +1,20 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/apitest"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+func TestServersList(t *testing.T) {
+	fake := apitest.NewServer(t)
+	fake.AddServer(apitest.ServerState{Detail: ports.ServerDetail{Name: "lobby", Status: "running"}})
+	fake.AddServer(apitest.ServerState{Detail: ports.ServerDetail{Name: "survival"}})
+
+	out, err := apitest.RunCommand(t, NewServersCommand(fake.LoadConfig()), "list")
+	if err != nil {
+		t.Fatalf("servers list: %v\n%s", err, out)
+	}
+	apitest.AssertGolden(t, "servers_list", out)
+}
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Service states shown by serviceTable.
+const (
+	serviceWaiting = "waiting"
+	serviceRunning = "running"
+	serviceDone    = "done"
+	serviceFailed  = "failed"
+)
+
+type serviceRow struct {
+	name    string
+	verb    string
+	state   string
+	detail  string
+	current int64
+	total   int64
+	start   time.Time
+	end     time.Time
+	step    int64
+}
+
+// serviceTable shows one status line per compose service while they are
+// pulled or built concurrently. On a terminal the table redraws in place;
+// otherwise each change is printed as "  [service] ...", which the TUI's
+// output view collapses into one line per service.
+type serviceTable struct {
+	mu    sync.Mutex
+	out   io.Writer
+	live  bool
+	rows  []*serviceRow
+	index map[string]*serviceRow
+	width int
+	lines int
+	drawn time.Time
+}
+
+func newServiceTable(out io.Writer, verb string, names []string) *serviceTable {
+	t := &serviceTable{out: out, index: map[string]*serviceRow{}, live: redraws(out)}
+	for _, name := range names {
+		row := &serviceRow{name: name, verb: verb, state: serviceWaiting, step: -1}
+		t.rows = append(t.rows, row)
+		t.index[name] = row
+		t.width = max(t.width, len(name))
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.redraw(true)
+	return t
+}
+
+// Start marks a service as in progress.
+func (t *serviceTable) Start(name string) {
+	t.update(name, func(row *serviceRow) {
+		row.state = serviceRunning
+		row.start = time.Now()
+	}, true)
+}
+
+// Phase marks a service as in progress with a different verb, for work that
+// goes through several steps such as saving and then stopping a server.
+func (t *serviceTable) Phase(name, verb string) {
+	t.update(name, func(row *serviceRow) {
+		if row.state == serviceWaiting {
+			row.start = time.Now()
+		}
+		row.state = serviceRunning
+		row.verb = verb
+	}, true)
+}
+
+// Progress records transferred bytes, e.g. from an image pull.
+func (t *serviceTable) Progress(name string, current, total int64) {
+	t.update(name, func(row *serviceRow) {
+		row.current, row.total = current, total
+	}, false)
+}
+
+// Detail shows the latest output line of a service.
+func (t *serviceTable) Detail(name, detail string) {
+	t.update(name, func(row *serviceRow) {
+		row.detail = detail
+	}, false)
+}
+
+// Finish marks a service as done, or failed when err is set.
+func (t *serviceTable) Finish(name string, err error) {
+	t.update(name, func(row *serviceRow) {
+		row.state = serviceDone
+		row.detail = ""
+		if err != nil {
+			row.state = serviceFailed
+			row.detail = err.Error()
+		}
+		row.end = time.Now()
+	}, true)
+}
+
+func (t *serviceTable) update(name string, change func(*serviceRow), force bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	row, ok := t.index[name]
+	if !ok {
+		return
+	}
+	before := row.state
+	change(row)
+	if t.live {
+		t.redraw(force)
+		return
+	}
+	// Plain output: print state changes and every 10% of a transfer.
+	step := int64(-1)
+	if row.total > 0 {
+		step = min(row.current, row.total) * 10 / row.total
+	}
+	if force || row.state != before || step > row.step {
+		row.step = step
+		fmt.Fprintln(t.out, t.render(row))
+	}
+}
+
+// redraw repaints every row, moving the cursor back over the previous table.
+func (t *serviceTable) redraw(force bool) {
+	if !t.live {
+		if force && t.lines == 0 {
+			for _, row := range t.rows {
+				fmt.Fprintln(t.out, t.render(row))
+			}
+			t.lines = len(t.rows)
+		}
+		return
+	}
+	now := time.Now()
+	if !force && now.Sub(t.drawn) < 100*time.Millisecond {
+		return
+	}
+	t.drawn = now
+	if t.lines > 0 {
+		fmt.Fprintf(t.out, "\x1b[%dA", t.lines)
+	}
+	for _, row := range t.rows {
+		fmt.Fprintf(t.out, "\r\x1b[2K%s\n", t.render(row))
+	}
+	t.lines = len(t.rows)
+}
+
+func (t *serviceTable) render(row *serviceRow) string {
+	name := fmt.Sprintf("[%s]%s", row.name, strings.Repeat(" ", t.width-len(row.name)))
+	var state string
+	switch row.state {
+	case serviceWaiting:
+		state = styleDim.Render("waiting")
+	case serviceRunning:
+		state = styleInfo.Render(row.verb)
+	case serviceDone:
+		state = markOK() + " " + styleSuccess.Render(row.end.Sub(row.start).Round(time.Second).String())
+	case serviceFailed:
+		state = markFailed() + " " + styleError.Render("failed")
+	}
+	parts := []string{name, state}
+	if row.total > 0 && row.state == serviceRunning {
+		percent := min(row.current, row.total) * 100 / row.total
+		parts = append(parts, fmt.Sprintf("%3d%%", percent), formatBytes(row.current)+" / "+formatBytes(row.total))
+	}
+	if row.detail != "" {
+		parts = append(parts, styleDim.Render(truncateLine(row.detail, 60)))
+	}
+	return "  " + strings.Join(parts, "  ")
+}
+
+func truncateLine(line string, width int) string {
+	runes := []rune(strings.TrimSpace(line))
+	if len(runes) <= width {
+		return string(runes)
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/recording"
+)
+
+// sessionTranscript is open while a command runs inside 'mineos record'.
+var sessionTranscript struct {
+	log     *recording.Transcript
+	started time.Time
+}
+
+// secretFlagWords mark flags whose values are kept out of transcripts.
+var secretFlagWords = []string{"key", "token", "password", "secret"}
+
+// startTranscript logs the command to the session transcript, and from then
+// on every request it makes that changes something.
+func startTranscript(cmd *cobra.Command) {
+	path := os.Getenv(recording.TranscriptEnv)
+	if path == "" || sessionTranscript.log != nil {
+		return
+	}
+	switch cmd.Name() {
+	case "record", "replay", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return
+	}
+	log, err := recording.OpenTranscript(path)
+	if err != nil {
+		return
+	}
+	sessionTranscript.log = log
+	sessionTranscript.started = time.Now()
+	http.DefaultTransport = recording.Transport(http.DefaultTransport, log)
+	log.Record(recording.Operation{
+		Kind:    recording.KindCommand,
+		Command: cmd.CommandPath(),
+		Args:    redactArgs(os.Args[1:]),
+	})
+}
+
+func finishTranscript(err error) {
+	log := sessionTranscript.log
+	if log == nil {
+		return
+	}
+	code := 0
+	op := recording.Operation{Kind: recording.KindExit, DurationMs: time.Since(sessionTranscript.started).Milliseconds()}
+	if err != nil {
+		code = 1
+		var coded interface{ ExitCode() int }
+		if errors.As(err, &coded) {
+			code = coded.ExitCode()
+		}
+		op.Error = err.Error()
+	}
+	op.ExitCode = &code
+	log.Record(op)
+	_ = log.Close()
+	sessionTranscript.log = nil
+}
+
+// redactArgs hides the values of flags that look like credentials, in both
+// the --flag value and --flag=value forms.
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	hideNext := false
+	for i, arg := range args {
+		if hideNext {
+			redacted[i] = "***"
+			hideNext = false
+			continue
+		}
+		redacted[i] = arg
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || !isSecretFlag(name) {
+			continue
+		}
+		if hasValue {
+			redacted[i] = arg[:len(arg)-len(value)] + "***"
+		} else {
+			hideNext = true
+		}
+	}
+	return redacted
+}
+
+func isSecretFlag(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range secretFlagWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,383 @@
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/loganalysis"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/catalog"
+)
+
+const smokeServerPrefix = "mineos-smoke-"
+
+var listReply = regexp.MustCompile(`There are \d+ of a max(?: of)? \d+ players online`)
+
+type smokeStep struct {
+	Name    string  `json:"name"`
+	OK      bool    `json:"ok"`
+	Seconds float64 `json:"seconds"`
+	Detail  string  `json:"detail,omitempty"`
+}
+
+type smokeResult struct {
+	Passed bool        `json:"passed"`
+	Server string      `json:"server"`
+	Kept   bool        `json:"kept,omitempty"`
+	Steps  []smokeStep `json:"steps"`
+}
+
+func NewSmokeTestCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var version string
+	var timeout time.Duration
+	var acceptEula bool
+	var keep bool
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "smoke-test",
+		Short: "Run a disposable server through a full lifecycle",
+		Long: `Check that MineOS works end to end after an install or update: create a
+small throwaway vanilla server, start it and wait for "Done", run a console
+command, back it up, stop it and delete it again. Each step is reported
+with its time; the command exits non-zero at the first one that fails.
+
+The server is called ` + smokeServerPrefix + `<random> and is deleted with its
+backups even when a step fails or the test is interrupted, unless --keep is
+given for a look at what went wrong. Its jar is the newest downloaded
+vanilla profile, or the newest release when none is downloaded yet.
+
+Starting the server needs the Minecraft EULA (` + minecraftEulaURL + `); you are
+asked to accept it, or pass --accept-eula.`,
+		Example: `  mineos smoke-test
+  mineos smoke-test --version 1.21.1 --accept-eula --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := cmd.OutOrStdout()
+			if !acceptEula {
+				interactive := term.IsTerminal(int(os.Stdin.Fd())) && !machineMode && !jsonOut
+				if interactive {
+					fmt.Fprintf(out, "The smoke test server needs the Minecraft EULA accepted: %s\n", minecraftEulaURL)
+					var err error
+					if acceptEula, err = promptYesNo(nil, out, "Accept the EULA for it?", false); err != nil {
+						return err
+					}
+				}
+				if !acceptEula {
+					return errors.New("the smoke test needs the Minecraft EULA accepted for its server; pass --accept-eula (" + minecraftEulaURL + ")")
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			cfg, err := loadConfig.Execute(ctx)
+			if err != nil {
+				return err
+			}
+			client := api.NewClientFromConfig(cfg)
+			name, err := smokeServerName()
+			if err != nil {
+				return err
+			}
+
+			live := false
+			if file, ok := out.(*os.File); ok && !jsonOut {
+				live = term.IsTerminal(int(file.Fd()))
+			}
+			result := smokeResult{Server: name}
+			step := func(label string, fn func() (string, error)) bool {
+				if live {
+					fmt.Fprintf(out, "  %s...", label)
+				}
+				started := time.Now()
+				detail, err := fn()
+				entry := smokeStep{Name: label, OK: err == nil, Seconds: time.Since(started).Round(100 * time.Millisecond).Seconds(), Detail: detail}
+				if err != nil {
+					entry.Detail = err.Error()
+				}
+				result.Steps = append(result.Steps, entry)
+				if !jsonOut {
+					if live {
+						fmt.Fprint(out, "\r\033[K")
+					}
+					printSmokeStep(out, entry)
+				}
+				return err == nil
+			}
+
+			if !jsonOut {
+				fmt.Fprintf(out, "Smoke test with server %s\n", name)
+			}
+			created := false
+			passed := step("API", func() (string, error) {
+				return cfg.ApiURL(), client.Health(ctx)
+			}) && step("Create server", func() (string, error) {
+				if err := client.CreateServer(ctx, name, "java"); err != nil {
+					return "", err
+				}
+				created = true
+				// assignFreePorts reports moved ports, which would break the
+				// step lines.
+				cmd.SetOut(io.Discard)
+				defer cmd.SetOut(out)
+				return name, assignFreePorts(ctx, client, cfg, cmd, name, 0)
+			}) && step("Install jar", func() (string, error) {
+				return installSmokeProfile(ctx, client, name, version)
+			}) && step("Start", func() (string, error) {
+				return startSmokeServer(ctx, client, name, timeout)
+			}) && step("Console command", func() (string, error) {
+				return smokeConsoleCommand(ctx, client, name)
+			}) && step("Backup", func() (string, error) {
+				jobID, err := client.CreateBackup(ctx, name)
+				if err != nil {
+					return "", err
+				}
+				if err := waitForJob(ctx, client, io.Discard, jobID); err != nil {
+					return "", err
+				}
+				backups, err := client.ListBackups(ctx, name)
+				if err != nil {
+					return "", err
+				}
+				if len(backups) == 0 {
+					return "", errors.New("the backup job finished but no backup is listed")
+				}
+				return fmt.Sprintf("%d backup(s)", len(backups)), nil
+			}) && step("Stop", func() (string, error) {
+				if err := client.ServerActionWithTimeout(ctx, name, "stop", 60); err != nil {
+					return "", err
+				}
+				detail, err := client.GetServer(ctx, name)
+				if err != nil {
+					return "", err
+				}
+				if detail.IsRunning() {
+					return "", fmt.Errorf("%s is still running after stop", name)
+				}
+				return "stopped cleanly", nil
+			})
+
+			// Cleanup runs on its own context so an interrupted test still
+			// removes its server.
+			if created && keep {
+				result.Kept = true
+				if !jsonOut {
+					fmt.Fprintf(out, "%s Kept %s; remove it with 'mineos servers delete %s --permanent'\n", styleWarning.Render("Note:"), name, name)
+				}
+			} else if created {
+				cleanupCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+				ok := step("Clean up", func() (string, error) {
+					return removeSmokeServer(cleanupCtx, client, name)
+				})
+				cancel()
+				passed = passed && ok
+			}
+			result.Passed = passed
+
+			if jsonOut {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(result); err != nil {
+					return err
+				}
+			}
+			if !passed {
+				failed := result.Steps[len(result.Steps)-1].Name
+				for _, entry := range result.Steps {
+					if !entry.OK {
+						failed = entry.Name
+						break
+					}
+				}
+				return exitCodeError{code: 1, message: "smoke test failed at: " + failed}
+			}
+			if !jsonOut {
+				fmt.Fprintln(out, styleSuccess.Render("Smoke test passed."))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&version, "version", "", "Minecraft version for the test server (default: newest downloaded vanilla profile)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Maximum time to wait for the server to finish starting")
+	cmd.Flags().BoolVar(&acceptEula, "accept-eula", false, "Accept the Minecraft EULA for the test server ("+minecraftEulaURL+")")
+	cmd.Flags().BoolVar(&keep, "keep", false, "Keep the test server instead of deleting it")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the steps as JSON")
+	_ = cmd.RegisterFlagCompletionFunc("version", completeMinecraftVersions)
+
+	return cmd
+}
+
+func smokeServerName() (string, error) {
+	buf := make([]byte, 3)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return smokeServerPrefix + hex.EncodeToString(buf), nil
+}
+
+func printSmokeStep(out io.Writer, step smokeStep) {
+	mark := markOK()
+	if !step.OK {
+		mark = markFailed()
+	}
+	fmt.Fprintf(out, "%s %-16s %s %s\n", mark, step.Name, step.Detail, styleDim.Render(fmt.Sprintf("(%.1fs)", step.Seconds)))
+}
+
+// installSmokeProfile puts a vanilla jar on the server with a small heap and
+// accepts the EULA. Without a version, an already downloaded profile is
+// preferred so the test does not depend on Mojang's servers.
+func installSmokeProfile(ctx context.Context, client *api.Client, name, version string) (string, error) {
+	profiles, err := client.ListProfiles(ctx)
+	if err != nil {
+		return "", err
+	}
+	var profile *ports.Profile
+	for i := range profiles {
+		candidate := &profiles[i]
+		if !strings.EqualFold(candidate.Group, catalog.Vanilla) {
+			continue
+		}
+		if version != "" {
+			if candidate.Version == version {
+				profile = candidate
+				break
+			}
+			continue
+		}
+		if candidate.Type != "release" {
+			continue
+		}
+		if profile == nil || (candidate.Downloaded && !profile.Downloaded) ||
+			(candidate.Downloaded == profile.Downloaded && catalog.CompareVersions(candidate.Version, profile.Version) > 0) {
+			profile = candidate
+		}
+	}
+	if profile == nil {
+		if version != "" {
+			return "", fmt.Errorf("no vanilla profile for %s; list versions with 'mineos versions'", version)
+		}
+		return "", errors.New("the API lists no vanilla release profiles")
+	}
+	if !profile.Downloaded {
+		if err := client.DownloadProfile(ctx, profile.Id); err != nil {
+			return "", err
+		}
+	}
+	if err := client.CopyProfileToServer(ctx, profile.Id, name); err != nil {
+		return "", err
+	}
+
+	serverConfig, err := client.GetServerConfig(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	serverConfig.Java.JavaXmx, serverConfig.Java.JavaXms = 1024, 512
+	if err := client.UpdateServerConfig(ctx, name, serverConfig); err != nil {
+		return "", err
+	}
+	if err := client.AcceptEula(ctx, name); err != nil {
+		return "", err
+	}
+	return profile.Id, nil
+}
+
+// startSmokeServer starts the server and watches logs/latest.log for the
+// "Done" line, failing early when the server exits.
+func startSmokeServer(ctx context.Context, client *api.Client, name string, timeout time.Duration) (string, error) {
+	if err := client.ServerAction(ctx, name, "start"); err != nil {
+		return "", err
+	}
+	line, err := waitForServerLog(ctx, client, name, "did not finish starting", timeout, 0, func(message string) bool {
+		_, ok := loganalysis.StartupDone(message)
+		return ok
+	})
+	if err != nil {
+		return "", err
+	}
+	seconds, _ := loganalysis.StartupDone(line)
+	return fmt.Sprintf("Done in %.1fs", seconds), nil
+}
+
+// smokeConsoleCommand sends "list" and waits for its reply in the log.
+func smokeConsoleCommand(ctx context.Context, client *api.Client, name string) (string, error) {
+	content, err := client.ReadServerFile(ctx, name, "logs/latest.log")
+	if err != nil {
+		return "", err
+	}
+	if err := client.SendConsoleCommand(ctx, name, "list"); err != nil {
+		return "", err
+	}
+	line, err := waitForServerLog(ctx, client, name, "did not answer 'list'", 30*time.Second, len(content), listReply.MatchString)
+	if err != nil {
+		return "", err
+	}
+	return "list: " + listReply.FindString(line), nil
+}
+
+// waitForServerLog polls logs/latest.log for a line after offset that
+// matches, returning it. failure describes what did not happen in time.
+func waitForServerLog(ctx context.Context, client *api.Client, name, failure string, timeout time.Duration, offset int, match func(string) bool) (string, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+		content, err := client.ReadServerFile(ctx, name, "logs/latest.log")
+		if err != nil && !errors.Is(err, ports.ErrNotFound) {
+			return "", err
+		}
+		if offset > len(content) {
+			offset = 0
+		}
+		for _, line := range strings.Split(content[offset:], "\n") {
+			if match(line) {
+				return strings.TrimSpace(line), nil
+			}
+		}
+		detail, err := client.GetServer(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		if !detail.IsRunning() {
+			return "", fmt.Errorf("%s stopped; check 'mineos servers logs %s --source java'", name, name)
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("%s %s within %s", name, failure, timeout)
+		}
+	}
+}
+
+func removeSmokeServer(ctx context.Context, client *api.Client, name string) (string, error) {
+	detail, err := client.GetServer(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if detail.IsRunning() {
+		if err := client.ServerActionWithTimeout(ctx, name, "stop", 60); err != nil {
+			return "", err
+		}
+	}
+	if err := client.DeleteServer(ctx, name); err != nil {
+		return "", err
+	}
+	return "deleted " + name + " and its backups", nil
+}
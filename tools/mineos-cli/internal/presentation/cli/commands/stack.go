@@ -37,6 +37,9 @@ func NewStackCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 	cmd.AddCommand(NewStackUpdateSourceCommand(loadConfig))
 	cmd.AddCommand(NewStackPsCommand(loadConfig))
 	cmd.AddCommand(NewStackLogsCommand(loadConfig))
+	cmd.AddCommand(NewStackServiceCommand(loadConfig))
+	cmd.AddCommand(NewStackOverrideCommand(loadConfig))
+	registerDockerWaitFlag(cmd)
 
 	return cmd
 }
@@ -44,6 +47,8 @@ func NewStackCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 func NewStackUpCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 	var wait bool
 	var waitTimeout int
+	var skipPortCheck bool
+	var restore bool
 
 	cmd := &cobra.Command{
 		Use:     "up",
@@ -57,14 +62,17 @@ func NewStackUpCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if !skipPortCheck {
+				if err := checkStackPorts(ctx, compose, cfg); err != nil {
+					return err
+				}
+			}
 			if err := compose.run([]string{"up", "-d"}); err != nil {
 				return err
 			}
 
 			if wait {
-				if err := waitForApiReady(ctx, cfg, out, waitTimeout); err != nil {
-					fmt.Fprintf(out, "Warning: %v\n", err)
-				}
+				startRestoredServers(ctx, loadConfig, cfg, out, waitTimeout, restore)
 			}
 			return nil
 		},
@@ -72,6 +80,8 @@ func NewStackUpCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 
 	cmd.Flags().BoolVar(&wait, "wait", true, "Wait for API health after startup")
 	cmd.Flags().IntVar(&waitTimeout, "wait-timeout", 60, "Seconds to wait for API health")
+	cmd.Flags().BoolVar(&skipPortCheck, "skip-port-check", false, "Start even when another program holds a port MineOS publishes")
+	cmd.Flags().BoolVar(&restore, "restore", true, "Start the servers that were running at the last 'stack stop' (needs --wait)")
 
 	return cmd
 }
@@ -105,6 +115,7 @@ func NewStackStopCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command
 func NewStackRestartCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 	var wait bool
 	var waitTimeout int
+	var restore bool
 	var timeout int
 
 	cmd := &cobra.Command{
@@ -126,9 +137,7 @@ func NewStackRestartCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Comma
 				return err
 			}
 			if wait {
-				if err := waitForApiReady(ctx, cfg, out, waitTimeout); err != nil {
-					fmt.Fprintf(out, "Warning: %v\n", err)
-				}
+				startRestoredServers(ctx, loadConfig, cfg, out, waitTimeout, restore)
 			}
 			return nil
 		},
@@ -136,6 +145,7 @@ func NewStackRestartCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Comma
 
 	cmd.Flags().BoolVar(&wait, "wait", true, "Wait for API health after restart")
 	cmd.Flags().IntVar(&waitTimeout, "wait-timeout", 60, "Seconds to wait for API health")
+	cmd.Flags().BoolVar(&restore, "restore", true, "Start the servers that were running before the restart (needs --wait)")
 	cmd.Flags().IntVar(&timeout, "timeout", 0, "Shutdown timeout in seconds (default from .env)")
 
 	return cmd
@@ -144,6 +154,7 @@ func NewStackRestartCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Comma
 func NewStackDownCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 	var timeout int
 	var volumes bool
+	var confirmToken string
 
 	cmd := &cobra.Command{
 		Use:   "down",
@@ -156,6 +167,11 @@ func NewStackDownCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command
 			if err != nil {
 				return err
 			}
+			if volumes {
+				if err := requireConfirmToken(cfg, confirmDownVolumes, confirmToken, out); err != nil {
+					return err
+				}
+			}
 			timeoutSeconds := effectiveShutdownTimeout(cfg, timeout)
 			if err := gracefulStop(ctx, loadConfig, compose, cfg, timeoutSeconds, false, out); err != nil {
 				return err
@@ -166,6 +182,7 @@ func NewStackDownCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command
 
 	cmd.Flags().BoolVar(&volumes, "volumes", false, "Also remove Docker volumes")
 	cmd.Flags().IntVar(&timeout, "timeout", 0, "Shutdown timeout in seconds (default from .env)")
+	addConfirmTokenFlag(cmd, &confirmToken)
 
 	return cmd
 }
@@ -191,7 +208,7 @@ func NewStackPullCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command
 			if err != nil {
 				return err
 			}
-			return compose.run([]string{"pull"})
+			return compose.pull(ctx, cmd.OutOrStdout())
 		},
 	}
 
@@ -233,12 +250,7 @@ func NewStackBuildCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command
 			}
 
 			buildID := time.Now().Format("20060102150405")
-			env := []string{"PUBLIC_BUILD_ID=" + buildID}
-			args := []string{"build"}
-			if compose.exe == "docker" {
-				args = append(args, "--progress", "plain")
-			}
-			return compose.runWithEnv(args, env)
+			return compose.build(ctx, out, []string{"PUBLIC_BUILD_ID=" + buildID})
 		},
 	}
 
@@ -268,7 +280,7 @@ func NewStackRecreateCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Comm
 			if err := compose.down(false); err != nil {
 				return err
 			}
-			if err := compose.run([]string{"pull"}); err != nil {
+			if err := compose.pull(ctx, out); err != nil {
 				return err
 			}
 			return compose.run([]string{"up", "-d", "--force-recreate"})
@@ -303,11 +315,11 @@ func NewStackRebuildCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Comma
 			}
 
 			if parseBool(cfg.BuildFromSource) {
-				if err := buildWithCompose(compose); err != nil {
+				if err := buildWithCompose(ctx, out, compose); err != nil {
 					return err
 				}
 			} else {
-				if err := compose.run([]string{"pull"}); err != nil {
+				if err := compose.pull(ctx, out); err != nil {
 					return err
 				}
 			}
@@ -343,7 +355,7 @@ func NewStackRebuildSourceCommand(loadConfig *usecases.LoadConfigUseCase) *cobra
 				return err
 			}
 
-			if err := buildWithCompose(compose); err != nil {
+			if err := buildWithCompose(ctx, out, compose); err != nil {
 				return err
 			}
 			return compose.run([]string{"up", "-d", "--force-recreate"})
@@ -357,45 +369,81 @@ func NewStackRebuildSourceCommand(loadConfig *usecases.LoadConfigUseCase) *cobra
 
 func NewStackUpdateCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 	var timeout int
+	var platform string
 
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update ONLY Docker containers (use 'mineos update' to update everything)",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			ctx := cmd.Context()
 			out := cmd.OutOrStdout()
+			return runStackUpdate(cmd.Context(), loadConfig, timeout, platform, out, func(name, message string) {
+				// gracefulStop reports its own progress.
+				if name != "stop" {
+					fmt.Fprintln(out, message)
+				}
+			})
+		},
+	}
 
-			// Force non-build mode for update - always pull images
-			compose, cfg, err := loadComposeWithBuildOverride(ctx, loadConfig, false)
-			if err != nil {
-				return err
-			}
+	cmd.Flags().IntVar(&timeout, "timeout", 0, "Shutdown timeout in seconds (default from .env)")
+	cmd.Flags().StringVar(&platform, "platform", "", "Image platform to pull, e.g. linux/amd64, saved as "+platformEnvKey+" in .env")
 
-			tag := strings.TrimSpace(cfg.ImageTag)
-			channel := "stable (latest)"
-			if tag == "preview" {
-				channel = "preview"
-			} else if tag != "" && tag != "latest" {
-				channel = "pinned (" + tag + ")"
-			}
-			fmt.Fprintf(out, "Pulling images (%s)...\n", channel)
-			if err := compose.run([]string{"pull"}); err != nil {
-				return err
-			}
+	return cmd
+}
 
-			timeoutSeconds := effectiveShutdownTimeout(cfg, timeout)
-			if err := gracefulStop(ctx, loadConfig, compose, cfg, timeoutSeconds, false, out); err != nil {
-				return err
-			}
+// runStackUpdate pulls new images, gracefully stops servers and recreates the
+// containers, calling step before each phase. A platform overrides the one
+// in .env and replaces it once the images are pulled.
+func runStackUpdate(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, timeout int, platform string, out io.Writer, step func(name, message string)) error {
+	if err := validatePlatform(platform); err != nil {
+		return err
+	}
+	// Force non-build mode for update - always pull images
+	compose, cfg, err := loadComposeWithBuildOverride(ctx, loadConfig, false)
+	if err != nil {
+		return err
+	}
+	if platform != "" {
+		compose.platform = platform
+	}
 
-			fmt.Fprintln(out, "Recreating containers with new images...")
-			return compose.run([]string{"up", "-d", "--force-recreate"})
-		},
+	// New images may expect the current .env layout.
+	report, err := migrateEnvFile(cfg.EnvPath, false)
+	if err != nil {
+		return fmt.Errorf("migrate .env: %w", err)
+	}
+	if report.changed() {
+		step("migrate", "Migrating .env...")
+		printEnvMigrationReport(out, report, false)
 	}
 
-	cmd.Flags().IntVar(&timeout, "timeout", 0, "Shutdown timeout in seconds (default from .env)")
+	tag := strings.TrimSpace(cfg.ImageTag)
+	channel := "stable (latest)"
+	if tag == "preview" {
+		channel = "preview"
+	} else if tag != "" && tag != "latest" {
+		channel = "pinned (" + tag + ")"
+	}
+	step("pull", fmt.Sprintf("Pulling images (%s)...", channel))
+	if err := compose.pull(ctx, out); err != nil {
+		return err
+	}
+	if platform != "" && platform != configuredPlatform(cfg) {
+		if sshRemote != nil {
+			fmt.Fprintf(out, "%s --platform applies to this update only; set %s in the remote .env to keep it.\n", styleDim.Render("Note:"), platformEnvKey)
+		} else if err := setEnvFileValue(cfg.EnvPath, platformEnvKey, platform); err != nil {
+			return fmt.Errorf("save %s: %w", platformEnvKey, err)
+		}
+	}
 
-	return cmd
+	step("stop", "Stopping servers and containers...")
+	timeoutSeconds := effectiveShutdownTimeout(cfg, timeout)
+	if err := gracefulStop(ctx, loadConfig, compose, cfg, timeoutSeconds, false, out); err != nil {
+		return err
+	}
+
+	step("up", "Recreating containers with new images...")
+	return compose.run([]string{"up", "-d", "--force-recreate"})
 }
 
 func NewStackUpdateSourceCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
@@ -427,44 +475,14 @@ func NewStackPsCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 }
 
 func NewStackLogsCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
-	var tail int
-	var follow bool
-
-	cmd := &cobra.Command{
-		Use:   "logs [service]",
-		Short: "Stream Docker compose logs",
-		Args:  cobra.MaximumNArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			compose, _, err := loadComposeAndConfig(cmd.Context(), loadConfig)
-			if err != nil {
-				return err
-			}
-
-			composeArgs := []string{"logs"}
-			if follow {
-				composeArgs = append(composeArgs, "-f")
-			}
-			if tail > 0 {
-				composeArgs = append(composeArgs, "--tail", strconv.Itoa(tail))
-			}
-			if len(args) == 1 {
-				composeArgs = append(composeArgs, args[0])
-			}
-			return compose.run(composeArgs)
-		},
-	}
-
-	cmd.Flags().IntVar(&tail, "tail", 200, "Number of log lines to show")
-	cmd.Flags().BoolVar(&follow, "follow", true, "Follow log output")
-
-	return cmd
+	return newComposeLogsCommand(loadConfig)
 }
 
 func gracefulStop(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, compose composeRunner, cfg config.Config, timeoutSeconds int, force bool, out io.Writer) error {
 	if force {
 		fmt.Fprintln(out, "Force stop enabled; killing servers and stopping containers immediately.")
 		if err := stopMinecraftServers(ctx, loadConfig, out, true, timeoutSeconds); err != nil {
-			fmt.Fprintf(out, "Warning: %v\n", err)
+			warnServersNotStopped(out, err)
 		}
 		if err := compose.run([]string{"stop", "-t", "0"}); err != nil {
 			return err
@@ -473,7 +491,7 @@ func gracefulStop(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, c
 	}
 
 	if err := stopMinecraftServers(ctx, loadConfig, out, false, timeoutSeconds); err != nil {
-		fmt.Fprintf(out, "Warning: %v\n", err)
+		warnServersNotStopped(out, err)
 	}
 
 	// Minecraft servers are already stopped via the API above.
@@ -487,13 +505,41 @@ func gracefulStop(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, c
 	return nil
 }
 
+// warnServersNotStopped reports why the servers were not stopped through
+// the API before the containers are. A stack whose API is down can still
+// be stopped; that is the usual way to recover it.
+func warnServersNotStopped(out io.Writer, err error) {
+	if unreachable(err) {
+		degradedNotice(out, "servers are not saved and stopped first; stopping the containers directly.")
+		return
+	}
+	fmt.Fprintf(out, "Warning: %v\n", err)
+}
+
+// startRestoredServers waits for the API and then, with restore set, starts
+// the servers recorded at the last stack stop. Failures are only warnings:
+// the stack itself is up.
+func startRestoredServers(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, cfg config.Config, out io.Writer, waitTimeout int, restore bool) {
+	if err := waitForApiReady(ctx, cfg, out, waitTimeout); err != nil {
+		fmt.Fprintf(out, "Warning: %v\n", err)
+		return
+	}
+	if !restore {
+		return
+	}
+	if err := restoreRunningServers(ctx, loadConfig, out); err != nil {
+		fmt.Fprintf(out, "Warning: %v\n", err)
+	}
+}
+
 func stopMinecraftServers(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, out io.Writer, force bool, timeoutSeconds int) error {
-	_, err := withApiKeyRetry(ctx, loadConfig, out, func(_ config.Config, client *api.Client) error {
+	_, err := withApiKeyRetry(ctx, loadConfig, out, func(cfg config.Config, client *api.Client) error {
 		// Check if there are any servers first to avoid waiting on empty stop-all
 		servers, err := client.ListServers(ctx)
 		if err != nil {
 			return err
 		}
+		recordRunningServers(cfg, servers, out)
 		if len(servers) == 0 {
 			fmt.Fprintln(out, "No servers found.")
 			return nil
@@ -509,6 +555,10 @@ func stopMinecraftServers(ctx context.Context, loadConfig *usecases.LoadConfigUs
 			return nil
 		}
 
+		if handled, err := stopServersInOrder(ctx, client, cfg, servers, out); handled || err != nil {
+			return err
+		}
+
 		result, err := client.StopAll(ctx, timeoutSeconds)
 		if err != nil {
 			return err
@@ -526,17 +576,12 @@ func stopMinecraftServers(ctx context.Context, loadConfig *usecases.LoadConfigUs
 	return err
 }
 
-func buildWithCompose(compose composeRunner) error {
+func buildWithCompose(ctx context.Context, out io.Writer, compose composeRunner) error {
 	if !dirExists("apps") {
 		return errors.New("source files not found (./apps missing); build requires the repo source")
 	}
 	buildID := time.Now().Format("20060102150405")
-	env := []string{"PUBLIC_BUILD_ID=" + buildID}
-	args := []string{"build"}
-	if compose.exe == "docker" {
-		args = append(args, "--progress", "plain")
-	}
-	return compose.runWithEnv(args, env)
+	return compose.build(ctx, out, []string{"PUBLIC_BUILD_ID=" + buildID})
 }
 
 func runGitPull(out io.Writer) error {
@@ -1,10 +1,12 @@
 package commands
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -26,7 +28,11 @@ func loadComposeAndConfig(ctx context.Context, loadConfig *usecases.LoadConfigUs
 	if err != nil {
 		return composeRunner{}, config.Config{}, err
 	}
-	return composeWithConfig(compose, cfg), cfg, nil
+	compose = composeWithConfig(compose, cfg)
+	if err := waitForDocker(ctx, compose, os.Stderr, dockerWaitTimeout(cfg)); err != nil {
+		return composeRunner{}, config.Config{}, err
+	}
+	return compose, cfg, nil
 }
 
 func loadComposeWithBuildOverride(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, buildFromSource bool) (composeRunner, config.Config, error) {
@@ -39,7 +45,27 @@ func loadComposeWithBuildOverride(ctx context.Context, loadConfig *usecases.Load
 		return composeRunner{}, config.Config{}, err
 	}
 	cfg.BuildFromSource = strconv.FormatBool(buildFromSource)
-	return composeWithConfig(compose, cfg), cfg, nil
+	compose = composeWithConfig(compose, cfg)
+	if err := waitForDocker(ctx, compose, os.Stderr, dockerWaitTimeout(cfg)); err != nil {
+		return composeRunner{}, config.Config{}, err
+	}
+	return compose, cfg, nil
+}
+
+// output runs a compose command and returns its stdout. Stderr is included in
+// the returned error to keep failures readable.
+func (c composeRunner) output(args []string) ([]byte, error) {
+	cmd := c.command(context.Background(), args, nil)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, fmt.Errorf("%w: %s", err, msg)
+		}
+		return out, err
+	}
+	return out, nil
 }
 
 func effectiveShutdownTimeout(cfg config.Config, override int) int {
@@ -58,12 +84,7 @@ func waitForApiReady(ctx context.Context, cfg config.Config, out io.Writer, time
 	if timeoutSeconds <= 0 {
 		timeoutSeconds = 60
 	}
-	apiPort := strings.TrimSpace(cfg.ApiPort)
-	if apiPort == "" {
-		apiPort = strconv.Itoa(defaultApiPort)
-	}
-
-	url := fmt.Sprintf("http://localhost:%s/health", apiPort)
+	url := cfg.ApiURL() + "/health"
 	client := &http.Client{Timeout: 5 * time.Second}
 	deadline := time.Now().Add(time.Duration(timeoutSeconds) * time.Second)
 
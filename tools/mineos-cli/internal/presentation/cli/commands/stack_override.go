@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+// User compose files live in composeOverridesDir of the install directory
+// and are listed, in the order compose applies them, in composeOverridesKey.
+const (
+	composeOverridesDir = "compose-overrides"
+	composeOverridesKey = "MINEOS_COMPOSE_OVERRIDES"
+)
+
+func composeOverrides(cfg config.Config) []string {
+	var names []string
+	for _, name := range strings.Split(cfg.ComposeOverrides, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// composeOverridePath is relative to the install directory, with forward
+// slashes so it also works on an --ssh host.
+func composeOverridePath(name string) string {
+	return composeOverridesDir + "/" + name
+}
+
+func NewStackOverrideCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "override",
+		Short: "Manage your own compose files included in every stack command",
+		Long: `Add compose files of your own to the stack: extra services such as a
+database or a map renderer, or changes to the api and web services. Added
+files are copied to ` + composeOverridesDir + `/ in the install directory, where
+you can keep editing them, and every stack command (and the TUI's stack actions) passes
+them to docker compose after MineOS's own files, in the order added.`,
+	}
+
+	cmd.AddCommand(newStackOverrideAddCommand(loadConfig))
+	cmd.AddCommand(newStackOverrideRemoveCommand(loadConfig))
+	cmd.AddCommand(newStackOverrideListCommand(loadConfig))
+	return cmd
+}
+
+func requireLocalOverrides() error {
+	if sshRemote != nil {
+		return errors.New("'stack override' edits the install directory; run it on the --ssh host")
+	}
+	return nil
+}
+
+func newStackOverrideAddCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "add <file>",
+		Short: "Include a compose file in the stack",
+		Long: `Copy a compose file to ` + composeOverridesDir + `/ and include it in every stack
+command. docker compose has to accept the stack with it first. Start what
+it adds with 'mineos stack up'.`,
+		Example: `  mineos stack override add bluemap.yml
+  mineos stack override add ~/compose/db.yml --name postgres.yml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			out := cmd.OutOrStdout()
+			if err := requireLocalOverrides(); err != nil {
+				return err
+			}
+			source := args[0]
+			if name == "" {
+				name = filepath.Base(source)
+			}
+			ext := strings.ToLower(filepath.Ext(name))
+			if name != filepath.Base(name) || strings.HasPrefix(name, ".") || (ext != ".yml" && ext != ".yaml") {
+				return fmt.Errorf("--name: %q must be a file name ending in .yml or .yaml", name)
+			}
+			if _, err := os.Stat(source); err != nil {
+				return err
+			}
+			cfg, err := loadConfig.Execute(ctx)
+			if err != nil {
+				return err
+			}
+			names := composeOverrides(cfg)
+			if slices.Contains(names, name) {
+				return ports.WithKind(fmt.Errorf("%s is already included; edit %s instead", name, composeOverridePath(name)), ports.ErrConflict)
+			}
+
+			envPath := resolveEnvPath(cfg.EnvPath)
+			dest := filepath.Join(filepath.Dir(envPath), composeOverridesDir, name)
+			copied := false
+			if src, err := filepath.Abs(source); err != nil || src != dest {
+				if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+					return err
+				}
+				if err := copyFile(source, dest); err != nil {
+					return err
+				}
+				copied = true
+			}
+
+			cfg.ComposeOverrides = strings.Join(append(names, name), ",")
+			if compose, err := detectCompose(); err == nil {
+				if _, err := composeWithConfig(compose, cfg).output([]string{"config", "--quiet"}); err != nil {
+					if copied {
+						_ = os.Remove(dest)
+					}
+					return fmt.Errorf("docker compose rejects the stack with %s: %w", source, err)
+				}
+			} else {
+				fmt.Fprintf(out, "%s %v; %s is included unchecked.\n", styleWarning.Render("Warning:"), err, name)
+			}
+			if err := setEnvFileValue(envPath, composeOverridesKey, cfg.ComposeOverrides); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s Included %s\n", markOK(), composeOverridePath(name))
+			fmt.Fprintln(decor(out), styleDim.Render("Run 'mineos stack up' to apply it."))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "File name in "+composeOverridesDir+"/ (default: the file's own)")
+	return cmd
+}
+
+func newStackOverrideRemoveCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var keepRunning bool
+	var keepFile bool
+
+	cmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Stop including a compose file",
+		Long: `Stop including a compose file in the stack. The containers of services
+only it defines are stopped and removed first, unless --keep-running, and
+the file is deleted from ` + composeOverridesDir + `/ unless --keep-file. Changes it
+made to the api or web services are undone by the next 'mineos stack up'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			out := cmd.OutOrStdout()
+			if err := requireLocalOverrides(); err != nil {
+				return err
+			}
+			cfg, err := loadConfig.Execute(ctx)
+			if err != nil {
+				return err
+			}
+			name := args[0]
+			names := composeOverrides(cfg)
+			index := slices.Index(names, name)
+			if index < 0 {
+				return ports.WithKind(fmt.Errorf("%s is not included; see 'mineos stack override list'", name), ports.ErrNotFound)
+			}
+			without := cfg
+			without.ComposeOverrides = strings.Join(slices.Delete(slices.Clone(names), index, index+1), ",")
+
+			if !keepRunning {
+				if err := removeOverrideServices(cfg, without, name, out); err != nil {
+					return err
+				}
+			}
+			envPath := resolveEnvPath(cfg.EnvPath)
+			if err := setEnvFileValue(envPath, composeOverridesKey, without.ComposeOverrides); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s No longer including %s\n", markOK(), composeOverridePath(name))
+			if !keepFile {
+				path := filepath.Join(filepath.Dir(envPath), composeOverridesDir, name)
+				if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&keepRunning, "keep-running", false, "Leave the containers of its services running")
+	cmd.Flags().BoolVar(&keepFile, "keep-file", false, "Keep the file in "+composeOverridesDir+"/")
+	return cmd
+}
+
+// removeOverrideServices stops and removes the containers of the services
+// that only the override defines, which compose would otherwise leave
+// behind as orphans.
+func removeOverrideServices(with, without config.Config, name string, out io.Writer) error {
+	compose, err := detectCompose()
+	if err != nil {
+		return fmt.Errorf("%w; use --keep-running to remove %s anyway", err, name)
+	}
+	all, err := composeWithConfig(compose, with).output([]string{"config", "--services"})
+	if err != nil {
+		return fmt.Errorf("list the services of %s: %w", name, err)
+	}
+	rest, err := composeWithConfig(compose, without).output([]string{"config", "--services"})
+	if err != nil {
+		return fmt.Errorf("list the stack's services: %w", err)
+	}
+	kept := strings.Fields(string(rest))
+	var own []string
+	for _, service := range strings.Fields(string(all)) {
+		if !slices.Contains(kept, service) {
+			own = append(own, service)
+		}
+	}
+	if len(own) == 0 {
+		return nil
+	}
+	fmt.Fprintf(decor(out), "Removing %s...\n", strings.Join(own, ", "))
+	return composeWithConfig(compose, with).run(append([]string{"rm", "--stop", "--force"}, own...))
+}
+
+type composeOverrideEntry struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Missing bool   `json:"missing,omitempty"`
+}
+
+func newStackOverrideListCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the included compose files, in the order applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := cmd.OutOrStdout()
+			cfg, err := loadConfig.Execute(cmd.Context())
+			if err != nil {
+				return err
+			}
+			dir := filepath.Dir(resolveEnvPath(cfg.EnvPath))
+			entries := []composeOverrideEntry{}
+			for _, name := range composeOverrides(cfg) {
+				entry := composeOverrideEntry{Name: name, Path: composeOverridePath(name)}
+				// Over --ssh the files are on the other host.
+				if sshRemote == nil {
+					entry.Missing = !fileExists(filepath.Join(dir, composeOverridesDir, name))
+				}
+				entries = append(entries, entry)
+			}
+
+			if jsonOut {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(entries)
+			}
+			if len(entries) == 0 {
+				fmt.Fprintln(decor(out), "No compose overrides; add one with 'mineos stack override add <file>'.")
+				return nil
+			}
+			for _, entry := range entries {
+				if entry.Missing {
+					fmt.Fprintf(out, "%s %s %s\n", markFailed(), entry.Path, styleWarning.Render("(missing: compose commands fail until it is restored or removed)"))
+					continue
+				}
+				fmt.Fprintln(out, entry.Path)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the list as JSON")
+	return cmd
+}
@@ -0,0 +1,198 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+)
+
+const (
+	systemdUnitName = "mineos.service"
+	systemdUnitPath = "/etc/systemd/system/" + systemdUnitName
+)
+
+func NewStackServiceCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "service",
+		Short: "Run 'stack up' at boot and 'stack stop' at shutdown with systemd",
+		Long: `Install a systemd unit that runs 'mineos stack up' when the host boots and
+'mineos stack stop' before it shuts down. Servers are then saved and stopped
+cleanly on shutdown, and the ones that were running come back after the
+reboot, alongside those with 'mineos servers autostart' on.`,
+	}
+
+	cmd.AddCommand(newStackServiceInstallCommand(loadConfig))
+	cmd.AddCommand(newStackServiceRemoveCommand())
+	cmd.AddCommand(newStackServiceStatusCommand())
+
+	return cmd
+}
+
+func requireSystemd() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("systemd units are Linux only; on %s, start Docker Desktop at login instead", runtime.GOOS)
+	}
+	if sshRemote != nil {
+		return errors.New("'stack service' changes this host; run it on the --ssh host")
+	}
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return errors.New("systemctl not found; this host does not use systemd")
+	}
+	return nil
+}
+
+func newStackServiceInstallCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var printOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install and enable the " + systemdUnitName + " unit (needs root)",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig.Execute(cmd.Context())
+			if err != nil {
+				return err
+			}
+			unit, err := systemdUnit(cfg)
+			if err != nil {
+				return err
+			}
+			if printOnly {
+				cmd.Print(unit)
+				return nil
+			}
+			if err := requireSystemd(); err != nil {
+				return err
+			}
+			if !isRoot() {
+				return errors.New("installing a systemd unit needs root; run it with sudo")
+			}
+			if err := os.WriteFile(systemdUnitPath, []byte(unit), 0o644); err != nil {
+				return err
+			}
+			cmd.Printf("%s Wrote %s\n", markOK(), systemdUnitPath)
+			// Starting it now is harmless (the stack is brought up if it is
+			// not already) and makes systemd run the stop at the next shutdown.
+			for _, args := range [][]string{{"daemon-reload"}, {"enable", "--now", systemdUnitName}} {
+				if err := runSystemctl(cmd.Context(), args...); err != nil {
+					return err
+				}
+			}
+			cmd.Printf("%s Enabled %s\n", markOK(), systemdUnitName)
+			cmd.Println(styleDim.Render("Remove it with 'sudo mineos stack service remove'."))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the unit instead of installing it")
+
+	return cmd
+}
+
+func newStackServiceRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove",
+		Short: "Disable and remove the " + systemdUnitName + " unit (needs root)",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := requireSystemd(); err != nil {
+				return err
+			}
+			if !isRoot() {
+				return errors.New("removing a systemd unit needs root; run it with sudo")
+			}
+			if _, err := os.Stat(systemdUnitPath); os.IsNotExist(err) {
+				cmd.Println("The unit is not installed.")
+				return nil
+			}
+			// Not --now: stopping the unit would stop the stack.
+			if err := runSystemctl(cmd.Context(), "disable", systemdUnitName); err != nil {
+				return err
+			}
+			if err := os.Remove(systemdUnitPath); err != nil {
+				return err
+			}
+			if err := runSystemctl(cmd.Context(), "daemon-reload"); err != nil {
+				return err
+			}
+			cmd.Printf("%s Removed %s; the stack keeps running.\n", markOK(), systemdUnitName)
+			return nil
+		},
+	}
+}
+
+func newStackServiceStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the " + systemdUnitName + " unit is installed and enabled",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := requireSystemd(); err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if _, err := os.Stat(systemdUnitPath); os.IsNotExist(err) {
+				printStat(out, "Unit", styleDim.Render("not installed"))
+				return nil
+			}
+			printStat(out, "Unit", systemdUnitPath)
+			for _, query := range []string{"is-enabled", "is-active"} {
+				// Both exit non-zero for "disabled" and "inactive"; the word
+				// printed is what matters.
+				output, _ := exec.CommandContext(cmd.Context(), "systemctl", query, systemdUnitName).Output()
+				printStat(out, strings.TrimPrefix(query, "is-"), fallback(strings.TrimSpace(string(output)), "unknown"))
+			}
+			return nil
+		},
+	}
+}
+
+// systemdUnit runs this binary against the current installation. Docker is
+// wanted rather than required, since a snap or socket-activated daemon may
+// not be docker.service; stack up waits for it instead. The stop timeout
+// leaves room for servers to save and shut down. It runs as root, but what
+// it keeps for the installation goes to the state directory beside .env,
+// which the operator's commands read too.
+func systemdUnit(cfg config.Config) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	envPath, err := filepath.Abs(resolveEnvPath(cfg.EnvPath))
+	if err != nil {
+		return "", err
+	}
+	stopTimeout := effectiveShutdownTimeout(cfg, 0) + 90
+	return fmt.Sprintf(`[Unit]
+Description=MineOS (Docker stack and Minecraft servers)
+Wants=docker.service network-online.target
+After=docker.service network-online.target
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+WorkingDirectory=%[1]s
+ExecStart="%[2]s" --env "%[3]s" stack up --docker-wait 240 --wait-timeout 180
+ExecStop="%[2]s" --env "%[3]s" stack stop
+TimeoutStartSec=480
+TimeoutStopSec=%[4]d
+
+[Install]
+WantedBy=multi-user.target
+`, filepath.Dir(envPath), exe, envPath, stopTimeout), nil
+}
+
+func runSystemctl(ctx context.Context, args ...string) error {
+	output, err := exec.CommandContext(ctx, "systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %s", strings.Join(args, " "), fallback(strings.TrimSpace(string(output)), err.Error()))
+	}
+	return nil
+}
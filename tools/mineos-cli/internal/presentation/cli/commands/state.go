@@ -0,0 +1,271 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+// Commands whose runs are not recorded as operations: they only read, only
+// help other commands run, or work on the state store itself.
+var unrecordedCommands = map[string]bool{
+	"help":                          true,
+	"version":                       true,
+	"completion":                    true,
+	"state":                         true,
+	cobra.ShellCompRequestCmd:       true,
+	cobra.ShellCompNoDescRequestCmd: true,
+}
+
+// The operation the root command started, for recordOperation.
+var (
+	operationStart time.Time
+	operationEnv   string
+)
+
+// startOperation notes when the command started and which .env it uses.
+func startOperation(envPath string) {
+	operationStart = time.Now()
+	if envPath == "" {
+		envPath = ".env"
+	}
+	if abs, err := filepath.Abs(envPath); err == nil {
+		envPath = abs
+	}
+	operationEnv = envPath
+}
+
+// recordOperation adds the command that ran to the state store. Only the
+// command path, target and outcome are kept, never the arguments, which
+// may hold passwords and keys. Failing to record never fails the command.
+func recordOperation(err error) {
+	if ranCommand == nil || !ranCommand.HasParent() || operationStart.IsZero() {
+		return
+	}
+	for c := ranCommand; c.HasParent(); c = c.Parent() {
+		if unrecordedCommands[c.Name()] {
+			return
+		}
+	}
+	target := operationEnv
+	if sshRemote != nil {
+		target = "ssh://" + sshRemote.String() + "/" + sshRemote.Dir
+	}
+	store, openErr := state.Open(state.DefaultPath())
+	if openErr != nil {
+		return
+	}
+	defer store.Close()
+	_ = store.RecordOperation(context.Background(), state.Operation{
+		StartedAt: operationStart,
+		Command:   strings.TrimSpace(strings.TrimPrefix(ranCommand.CommandPath(), ranCommand.Root().Name())),
+		Target:    target,
+		ExitCode:  exitCode(err),
+		Duration:  time.Since(operationStart).Milliseconds(),
+	})
+}
+
+func NewStateCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Edit, export and import the CLI's local state",
+		Long: `The CLI keeps its own state in two SQLite databases. The user's, in their
+state directory (~/.local/state/mineos on Linux, or ` + state.DirEnv + `), has the
+cached update check, the record of the commands they ran (with their target
+and exit code but never their arguments) and the contexts they write. The
+installation's, in ` + state.InstallationDirName + `/ next to its .env (--env), has its schedule
+and alert rules and the metrics history 'mineos agent --record-metrics'
+keeps, so every admin and the systemd unit of 'mineos stack service',
+which runs as root, share them. The YAML documents you write are:
+
+  contexts   the installations 'mineos fleet' acts on
+  schedule   the restarts and announcements of 'mineos agent --schedule'
+  alerts     the rules of 'mineos agent --alerts'
+
+The other files an installation keeps, such as the servers to start again
+after 'stack stop', the agent's bans and the console history, live in
+` + state.InstallationDirName + `/ too. Files from before, contexts.yaml in the config directory,
+mineos-schedule.yaml, mineos-alerts.yaml and mineos-metrics.db next to .env
+and the schedule, alert rules and history of an installation in the user's
+database, are moved the first time they are needed.
+
+Export it to move it to another machine or keep it with a backup.`,
+	}
+
+	cmd.AddCommand(newStateShowCommand(loadConfig))
+	cmd.AddCommand(newStateEditCommand(loadConfig))
+	cmd.AddCommand(newStateSetCommand(loadConfig))
+	cmd.AddCommand(newStatePathCommand(loadConfig))
+	cmd.AddCommand(newStateExportCommand(loadConfig))
+	cmd.AddCommand(newStateImportCommand(loadConfig))
+	return cmd
+}
+
+// stateEnvPath is the .env of the installation whose store the state
+// commands use. Under --ssh the store is on the host.
+func stateEnvPath(ctx context.Context, loadConfig *usecases.LoadConfigUseCase) (string, error) {
+	if sshRemote != nil {
+		return "", fmt.Errorf("the state of an installation is kept on its host; run 'mineos state' there instead of over --ssh")
+	}
+	cfg, err := loadConfig.Execute(ctx)
+	if err != nil {
+		return "", err
+	}
+	return resolveEnvPath(cfg.EnvPath), nil
+}
+
+// exportStores reads the stores at paths into one export; their contents
+// do not overlap.
+func exportStores(ctx context.Context, paths ...string) (state.Export, error) {
+	var all state.Export
+	for i, path := range paths {
+		store, err := state.Open(path)
+		if err != nil {
+			return all, err
+		}
+		export, err := store.Export(ctx)
+		store.Close()
+		if err != nil {
+			return all, err
+		}
+		if i == 0 {
+			all = export
+			continue
+		}
+		all.Entries = append(all.Entries, export.Entries...)
+		all.Operations = append(all.Operations, export.Operations...)
+		all.Samples = append(all.Samples, export.Samples...)
+	}
+	return all, nil
+}
+
+func newStatePathCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "path",
+		Short: "Print where the state databases are",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			envPath, err := stateEnvPath(cmd.Context(), loadConfig)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "user\t%s\ninstallation\t%s\n", state.DefaultPath(), state.PathForEnv(envPath))
+			return nil
+		},
+	}
+}
+
+func newStateExportCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the local state as JSON",
+		Example: `  mineos state export > mineos-state.json
+  mineos state export -o mineos-state.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			envPath, err := stateEnvPath(cmd.Context(), loadConfig)
+			if err != nil {
+				return err
+			}
+			export, err := exportStores(cmd.Context(), state.DefaultPath(), state.PathForEnv(envPath))
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if output != "" && output != "-" {
+				file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				out = file
+			}
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(export); err != nil {
+				return err
+			}
+			if output != "" && output != "-" {
+				fmt.Fprintf(decor(cmd.ErrOrStderr()), "%s Exported %d entries, %d operations and %d metrics samples to %s\n",
+					markOK(), len(export.Entries), len(export.Operations), len(export.Samples), output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write (default: stdout)")
+	return cmd
+}
+
+func newStateImportCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var replace bool
+
+	cmd := &cobra.Command{
+		Use:   "import <file|->",
+		Short: "Read local state written by 'mineos state export'",
+		Long: `Read local state written by 'mineos state export'. Entries and metrics
+samples replace those with the same key and operations are added to the
+record; with --replace, the current state is emptied first.
+
+The schedule, alert rules and metrics history go to the installation of
+--env, whichever installation they were exported from.`,
+		Example: `  mineos state import mineos-state.json
+  ssh old-host mineos state export | mineos state import -`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var data []byte
+			var err error
+			if args[0] == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return err
+			}
+			var export state.Export
+			if err := json.Unmarshal(data, &export); err != nil {
+				return fmt.Errorf("%s is not a state export: %w", args[0], err)
+			}
+
+			envPath, err := stateEnvPath(cmd.Context(), loadConfig)
+			if err != nil {
+				return err
+			}
+			user, installation := export.Retarget(envPath).Split()
+			for _, part := range []struct {
+				path   string
+				export state.Export
+			}{{state.DefaultPath(), user}, {state.PathForEnv(envPath), installation}} {
+				store, err := state.Open(part.path)
+				if err != nil {
+					return err
+				}
+				err = store.Import(cmd.Context(), part.export, replace)
+				store.Close()
+				if err != nil {
+					return fmt.Errorf("import into %s: %w", part.path, err)
+				}
+			}
+			fmt.Fprintf(decor(cmd.OutOrStdout()), "%s Imported %d entries, %d operations and %d metrics samples into %s and %s\n",
+				markOK(), len(export.Entries), len(export.Operations), len(export.Samples), state.DefaultPath(), state.PathForEnv(envPath))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&replace, "replace", false, "Empty the current state before importing")
+	return cmd
+}
@@ -0,0 +1,210 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/alerts"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/fleet"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/schedule"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+)
+
+// stateDocumentNames are the YAML documents kept in the state store: the
+// fleet's contexts, and the schedule and alert rules of the installation
+// the .env belongs to.
+var stateDocumentNames = []string{"contexts", "schedule", "alerts"}
+
+// stateDocumentTemplates start a document that does not exist yet.
+var stateDocumentTemplates = map[string]string{
+	"contexts": "# Contexts for 'mineos fleet'; see 'mineos fleet --help'.\ncontexts: {}\n",
+	"schedule": "# Restarts, announcements, presets and backup checks for\n# 'mineos agent --schedule'; see 'mineos agent --help'.\nrestarts: []\n",
+	"alerts":   "# Alert rules for 'mineos agent --alerts'; see 'mineos agent --help'.\nrules: []\n",
+}
+
+type stateDocument interface {
+	Read() ([]byte, bool, error)
+	Save(data []byte) error
+	Path() string
+}
+
+func openStateDocument(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, name string) (stateDocument, error) {
+	switch name {
+	case "contexts":
+		return fleet.NewStateRepository(state.DefaultPath()), nil
+	case "schedule", "alerts":
+		if sshRemote != nil {
+			return nil, fmt.Errorf("the %s of an installation is kept on its host; run 'mineos state' there instead of over --ssh", name)
+		}
+		cfg, err := loadConfig.Execute(ctx)
+		if err != nil {
+			return nil, err
+		}
+		envPath := resolveEnvPath(cfg.EnvPath)
+		if name == "schedule" {
+			return schedule.NewStateRepository(state.PathForEnv(envPath), envPath), nil
+		}
+		return alerts.NewStateRepository(state.PathForEnv(envPath), envPath), nil
+	}
+	return nil, ports.WithKind(fmt.Errorf("unknown document %q; use %s", name, strings.Join(stateDocumentNames, ", ")), ports.ErrNotFound)
+}
+
+func newStateShowCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:       "show <contexts|schedule|alerts>",
+		Short:     "Print a document kept in the state store",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: stateDocumentNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := openStateDocument(cmd.Context(), loadConfig, args[0])
+			if err != nil {
+				return err
+			}
+			data, found, err := doc.Read()
+			if err != nil {
+				return err
+			}
+			if !found {
+				return ports.WithKind(fmt.Errorf("no %s in %s yet; write it with 'mineos state edit %s'", args[0], doc.Path(), args[0]), ports.ErrNotFound)
+			}
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	}
+}
+
+func newStateSetCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <contexts|schedule|alerts> <file|->",
+		Short: "Replace a document in the state store with a YAML file",
+		Long: `Replace a document in the state store with a YAML file, or standard input
+with -. It is checked first and left unchanged when it is invalid.`,
+		Example: `  mineos state set schedule mineos-schedule.yaml
+  mineos state show alerts | sed 's/tps < 15/tps < 12/' | mineos state set alerts -`,
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: stateDocumentNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var data []byte
+			var err error
+			if args[1] == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = os.ReadFile(args[1])
+			}
+			if err != nil {
+				return err
+			}
+			doc, err := openStateDocument(cmd.Context(), loadConfig, args[0])
+			if err != nil {
+				return err
+			}
+			if err := doc.Save(data); err != nil {
+				return fmt.Errorf("invalid %s: %w", args[0], err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s Saved the %s to %s\n", markOK(), args[0], doc.Path())
+			return nil
+		},
+	}
+}
+
+func newStateEditCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit <contexts|schedule|alerts>",
+		Short: "Edit a document in the state store",
+		Long: `Open a document in the state store in $VISUAL or $EDITOR (vi, or notepad
+on Windows) and save it when the editor exits. An invalid document is not
+saved; the edit is kept in a temporary file to fix and 'mineos state set'.`,
+		Example: `  mineos state edit schedule
+  mineos --env /opt/mineos/.env state edit alerts
+  EDITOR=nano mineos state edit contexts`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: stateDocumentNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			out := cmd.OutOrStdout()
+			doc, err := openStateDocument(cmd.Context(), loadConfig, name)
+			if err != nil {
+				return err
+			}
+			before, found, err := doc.Read()
+			if err != nil {
+				return err
+			}
+			if !found {
+				before = []byte(stateDocumentTemplates[name])
+			}
+
+			file, err := os.CreateTemp("", "mineos-"+name+"-*.yaml")
+			if err != nil {
+				return err
+			}
+			path := file.Name()
+			_, err = file.Write(before)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return err
+			}
+			if err := runEditor(cmd, path); err != nil {
+				os.Remove(path)
+				return err
+			}
+			after, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if found && bytes.Equal(before, after) {
+				os.Remove(path)
+				fmt.Fprintln(decor(out), "No changes.")
+				return nil
+			}
+			if err := doc.Save(after); err != nil {
+				return fmt.Errorf("invalid %s, not saved: %w; your edit is in %s", name, err, path)
+			}
+			os.Remove(path)
+			fmt.Fprintf(out, "%s Saved the %s to %s\n", markOK(), name, doc.Path())
+			return nil
+		},
+	}
+}
+
+// runEditor opens path in the user's editor on the terminal.
+func runEditor(cmd *cobra.Command, path string) error {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	// EDITOR may carry arguments, such as "code --wait".
+	fields := strings.Fields(editor)
+	child := exec.CommandContext(cmd.Context(), fields[0], append(fields[1:], path)...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+	if err := child.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("%s exited with %d; nothing was saved", fields[0], exitErr.ExitCode())
+		}
+		return fmt.Errorf("start the editor (set VISUAL or EDITOR): %w", err)
+	}
+	return nil
+}
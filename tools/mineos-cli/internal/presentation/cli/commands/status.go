@@ -3,10 +3,12 @@ package commands
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
 )
 
@@ -21,15 +23,43 @@ func NewStatusCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
 				return err
 			}
 			client := api.NewClientFromConfig(cfg)
-			health := "unhealthy"
-			if err := client.Health(ctx); err == nil {
-				health = "healthy"
+			health := "healthy"
+			healthErr := client.Health(ctx)
+			if unreachable(healthErr) {
+				health = "unreachable"
+			} else if healthErr != nil {
+				health = "unhealthy"
 			}
 			fmt.Printf("API: %s\n", health)
 			fmt.Printf("Web origin: %s\n", fallback(cfg.WebOrigin, "http://localhost:3000"))
 			fmt.Printf("Minecraft host: %s\n", fallback(cfg.MinecraftHost, "localhost"))
 			fmt.Printf("Network mode: %s\n", fallback(cfg.NetworkMode, "bridge"))
+
+			// The containers are asked of Docker, so they show even when
+			// the API is what is down.
+			printServiceStates(cfg)
+			if unreachable(healthErr) {
+				fmt.Println()
+				degradedNotice(os.Stdout, "server status needs the API; 'mineos stack logs api' shows why it is down.")
+			}
 			return nil
 		},
 	}
 }
+
+func printServiceStates(cfg config.Config) {
+	compose, err := detectCompose()
+	if err != nil {
+		fmt.Printf("Services: %s\n", err)
+		return
+	}
+	states, err := composeWithConfig(compose, cfg).serviceStates()
+	if err != nil {
+		fmt.Printf("Services: %s\n", err)
+		return
+	}
+	fmt.Println("Services:")
+	for _, state := range states {
+		fmt.Printf("  %-10s %s\n", state.Service, fallback(state.Status, state.State))
+	}
+}
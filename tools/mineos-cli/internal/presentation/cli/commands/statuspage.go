@@ -0,0 +1,314 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/motd"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/slp"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/statuspage"
+)
+
+const defaultStatusPageDir = "statuspage"
+
+// statusPageUptimeWindows are the uptime columns; all but the first are
+// only shown once the metrics store covers the whole window.
+var statusPageUptimeWindows = []struct {
+	label  string
+	window time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+type statusPageOptions struct {
+	title       string
+	servers     []string
+	hidePlayers bool
+	refresh     time.Duration
+}
+
+func NewStatusPageCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var outDir string
+	var interval time.Duration
+	var once bool
+	var serve string
+	var opts statusPageOptions
+
+	cmd := &cobra.Command{
+		Use:   "statuspage",
+		Short: "Render a public status page for the servers",
+		Long: `Write a static status page (index.html and status.json) listing each
+server with whether it is online, its MOTD, version, address, online players
+and uptime, and keep it fresh every --interval. The page holds only what the
+Minecraft server list already shows, so it can be published without
+exposing the web UI or the API.
+
+The files go to --out (default: ` + defaultStatusPageDir + `/ next to .env). Serve that
+directory with the web server in front of the host, e.g. Caddy's
+'file_server', or pass --serve to have this command serve it too.
+
+Players and MOTD come from pinging each running server, falling back to the
+API. Uptime is the share of time a server was running according to the
+metrics 'mineos agent --record-metrics' records; without them it is left
+out. --hide-players shows counts but not names.`,
+		Example: `  mineos statuspage --once
+  mineos statuspage --out /srv/www/status --interval 30s
+  mineos statuspage --serve 0.0.0.0:8090 --title "Example Network" --servers 'survival*'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if interval <= 0 {
+				once = true
+			}
+			if once && serve != "" {
+				return errors.New("--serve keeps running; drop --once")
+			}
+			if outDir == "" {
+				cfg, err := loadConfig.Execute(context.Background())
+				if err != nil {
+					return err
+				}
+				outDir = filepath.Join(filepath.Dir(resolveEnvPath(cfg.EnvPath)), defaultStatusPageDir)
+			}
+			if !once {
+				opts.refresh = interval
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			render := func() error {
+				renderCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+				defer cancel()
+				page, err := collectStatusPage(renderCtx, loadConfig, cmd.OutOrStdout(), opts)
+				if err != nil {
+					return err
+				}
+				return statuspage.Write(outDir, page)
+			}
+
+			if err := render(); err != nil {
+				return err
+			}
+			if once {
+				cmd.Printf("%s Wrote %s and %s to %s\n", markOK(), statuspage.IndexFile, statuspage.JSONFile, outDir)
+				return nil
+			}
+
+			serveErr := make(chan error, 1)
+			if serve != "" {
+				go func() { serveErr <- serveStatusPage(ctx, serve, outDir) }()
+				cmd.Printf("Serving the status page on http://%s\n", serve)
+			}
+			cmd.Printf("Writing the status page to %s every %s\n", outDir, interval)
+			cmd.Println(styleDim.Render("Press Ctrl+C to stop."))
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case err := <-serveErr:
+					return err
+				case <-ticker.C:
+					// A failed refresh leaves the previous page up.
+					if err := render(); err != nil && ctx.Err() == nil {
+						cmd.Printf("%s %v\n", styleWarning.Render("Status page not updated:"), err)
+					}
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&outDir, "out", "", "Directory to write the page to (default: "+defaultStatusPageDir+"/ next to .env)")
+	cmd.Flags().DurationVar(&interval, "interval", time.Minute, "How often to refresh the page")
+	cmd.Flags().BoolVar(&once, "once", false, "Render the page once and exit")
+	cmd.Flags().StringVar(&serve, "serve", "", "Also serve the page over HTTP on this address, e.g. 0.0.0.0:8090")
+	cmd.Flags().StringVar(&opts.title, "title", "Server Status", "Page title")
+	cmd.Flags().StringSliceVar(&opts.servers, "servers", nil, "Only list these servers (names or glob patterns)")
+	cmd.Flags().BoolVar(&opts.hidePlayers, "hide-players", false, "Show player counts without names")
+
+	return cmd
+}
+
+func collectStatusPage(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, out io.Writer, opts statusPageOptions) (statuspage.Page, error) {
+	page := statuspage.Page{Title: opts.title, Generated: time.Now().UTC(), Refresh: opts.refresh}
+	_, err := withApiKeyRetry(ctx, loadConfig, out, func(cfg config.Config, client *api.Client) error {
+		servers, err := client.ListServers(ctx)
+		if err != nil {
+			return err
+		}
+		page.Servers = page.Servers[:0]
+		for _, server := range servers {
+			if !statusPageIncludes(opts.servers, server.Name) {
+				continue
+			}
+			page.Servers = append(page.Servers, statusPageServer(ctx, cfg, client, server.Name, opts.hidePlayers))
+		}
+		return nil
+	})
+	if err != nil {
+		return statuspage.Page{}, err
+	}
+	addStatusPageUptime(ctx, loadConfig, page.Servers)
+	return page, nil
+}
+
+func statusPageIncludes(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok || pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+// statusPageServer describes one server. Failures only leave fields empty:
+// one broken server must not take the page down.
+func statusPageServer(ctx context.Context, cfg config.Config, client *api.Client, name string, hidePlayers bool) statuspage.Server {
+	entry := statuspage.Server{Name: name, Edition: "Java"}
+	detail, err := client.GetServer(ctx, name)
+	if err != nil {
+		return entry
+	}
+	bedrock := detail.IsBedrock()
+	if bedrock {
+		entry.Edition = "Bedrock"
+	}
+	entry.Online = detail.IsRunning()
+
+	props, _ := client.GetServerProperties(ctx, name)
+	port := props["server-port"]
+	if port == "" {
+		port = strconv.Itoa(slp.DefaultJavaPort)
+		if bedrock {
+			port = strconv.Itoa(slp.DefaultBedrockPort)
+		}
+	}
+	entry.Address = cfg.MinecraftAddress(port)
+	entry.MotdSegments = motd.Parse(motd.DecodeProperty(props["motd"]))
+	entry.MaxPlayers, _ = strconv.Atoi(props["max-players"])
+	if !entry.Online {
+		entry.Motd = motd.PlainText(entry.MotdSegments)
+		return entry
+	}
+
+	// Ping the server the way the multiplayer screen does; over --ssh
+	// localhost is this machine, so use the public address then.
+	probe := net.JoinHostPort("localhost", port)
+	if sshRemote != nil {
+		probe = entry.Address
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	var status slp.Status
+	if bedrock {
+		status, err = slp.PingBedrock(pingCtx, probe)
+	} else {
+		status, err = slp.PingJava(pingCtx, probe)
+	}
+	if err == nil {
+		entry.Version = motd.PlainText(motd.ParseLegacy(status.Version))
+		entry.Players, entry.MaxPlayers = status.PlayersOnline, status.PlayersMax
+		entry.PlayerNames = status.Sample
+		if len(status.Description) > 0 {
+			if segments, err := motd.ParseComponent(status.Description); err == nil {
+				entry.MotdSegments = segments
+			}
+		} else if status.Legacy != "" {
+			entry.MotdSegments = motd.ParseLegacy(status.Legacy)
+		}
+	} else if sample, err := client.GetPerformance(ctx, name); err == nil {
+		entry.Players = sample.PlayerCount
+	}
+	if hidePlayers {
+		entry.PlayerNames = nil
+	}
+	entry.Motd = motd.PlainText(entry.MotdSegments)
+	return entry
+}
+
+// addStatusPageUptime fills in uptime from the local metrics store, when
+// there is one; under --ssh the store is on the remote host.
+func addStatusPageUptime(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, servers []statuspage.Server) {
+	if sshRemote != nil || len(servers) == 0 {
+		return
+	}
+	cfg, err := loadConfig.Execute(ctx)
+	if err != nil {
+		return
+	}
+	envPath := resolveEnvPath(cfg.EnvPath)
+	store, err := state.Open(state.PathForEnv(envPath))
+	if err != nil {
+		return
+	}
+	defer store.Close()
+	history, err := store.Metrics(ctx, envPath)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	for i := range servers {
+		oldest, ok, err := history.Oldest(ctx, servers[i].Name)
+		if err != nil || !ok {
+			continue
+		}
+		for j, window := range statusPageUptimeWindows {
+			since := now.Add(-window.window)
+			if j > 0 && oldest.After(since) {
+				break
+			}
+			share, ok, err := history.Uptime(ctx, servers[i].Name, since)
+			if err != nil || !ok {
+				break
+			}
+			if servers[i].Uptime == nil {
+				servers[i].Uptime = map[string]float64{}
+			}
+			servers[i].Uptime[window.label] = share * 100
+		}
+	}
+}
+
+// serveStatusPage serves only the page's directory, read-only.
+func serveStatusPage(ctx context.Context, addr, dir string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{Handler: http.FileServer(http.Dir(dir)), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("status page server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// QuietEnv turns on quiet output like --quiet.
+const QuietEnv = "MINEOS_QUIET"
+
+// quietMode is set for the whole run by the root command: commands print
+// their results and errors only, without spinners, progress, headers or
+// summary lines.
+var quietMode bool
+
+// quietRequested reports whether the flag or MINEOS_QUIET asks for quiet
+// output.
+func quietRequested(flag bool) bool {
+	if flag {
+		return true
+	}
+	enabled, err := strconv.ParseBool(os.Getenv(QuietEnv))
+	return err == nil && enabled
+}
+
+// decor is where decorative output goes: out, or nowhere in quiet mode.
+func decor(out io.Writer) io.Writer {
+	if quietMode {
+		return io.Discard
+	}
+	return out
+}
+
+// spinnerDelay is how long an operation runs before it gets a spinner;
+// quicker ones would only flicker.
+const spinnerDelay = 300 * time.Millisecond
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// task reports an operation the same way in every command: a spinner while
+// it runs, where output is redrawn, and one line saying how it ended and
+// how long it took. Output written through the task while it runs clears
+// the spinner first.
+type task struct {
+	out   io.Writer
+	label string
+	start time.Time
+
+	mu      sync.Mutex
+	drawn   bool
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func startTask(out io.Writer, label string) *task {
+	t := &task{out: out, label: label, start: time.Now()}
+	if !quietMode && redraws(out) {
+		t.stop = make(chan struct{})
+		t.stopped = make(chan struct{})
+		go t.spin()
+	}
+	return t
+}
+
+// trackTask is a task without the spinner, for operations that show their
+// own progress.
+func trackTask(out io.Writer, label string) *task {
+	return &task{out: out, label: label, start: time.Now()}
+}
+
+// runTask runs fn as a task labelled label; fn writes through the task.
+func runTask(out io.Writer, label string, fn func(out io.Writer) error) error {
+	t := startTask(out, label)
+	if err := fn(t); err != nil {
+		t.Fail()
+		return err
+	}
+	t.Done("")
+	return nil
+}
+
+func (t *task) spin() {
+	defer close(t.stopped)
+	select {
+	case <-t.stop:
+		return
+	case <-time.After(spinnerDelay):
+	}
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		t.mu.Lock()
+		fmt.Fprintf(t.out, "\r\x1b[2K%s %s %s", styleInfo.Render(spinnerFrames[frame%len(spinnerFrames)]), t.label, styleDim.Render(formatElapsed(time.Since(t.start))))
+		t.drawn = true
+		t.mu.Unlock()
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *task) Write(data []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clear()
+	return t.out.Write(data)
+}
+
+func (t *task) clear() {
+	if t.drawn {
+		fmt.Fprint(t.out, "\r\x1b[2K")
+		t.drawn = false
+	}
+}
+
+func (t *task) finish() time.Duration {
+	if t.stop != nil {
+		close(t.stop)
+		<-t.stopped
+		t.stop = nil
+	}
+	t.mu.Lock()
+	t.clear()
+	t.mu.Unlock()
+	return time.Since(t.start)
+}
+
+// Done ends the task with its success line; detail, if any, follows the
+// label.
+func (t *task) Done(detail string) {
+	took := t.finish()
+	line := t.label
+	if detail != "" {
+		line += ": " + detail
+	}
+	fmt.Fprintf(decor(t.out), "%s %s %s\n", markOK(), line, styleDim.Render("("+formatElapsed(took)+")"))
+}
+
+// Fail ends the task with its failure line. The error is the caller's to
+// return, so that it is printed once.
+func (t *task) Fail() {
+	took := t.finish()
+	fmt.Fprintf(decor(t.out), "%s %s failed %s\n", markFailed(), t.label, styleDim.Render("("+formatElapsed(took)+")"))
+}
+
+// printSummary is the closing line of a command that acted on several
+// things: how many worked, how many failed and how long it all took.
+func printSummary(out io.Writer, succeeded, failed int, took time.Duration) {
+	mark := markOK()
+	if failed > 0 {
+		mark = markFailed()
+	}
+	fmt.Fprintf(decor(out), "\n%s %d succeeded, %d failed %s\n", mark, succeeded, failed, styleDim.Render("("+formatElapsed(took)+")"))
+}
+
+// formatElapsed is a duration as tasks and summaries report it: tenths of
+// a second under a minute, whole seconds above.
+func formatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return strconv.FormatFloat(d.Seconds(), 'f', 1, 64) + "s"
+	}
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,227 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/presentation/cli/tui"
+)
+
+func NewTopCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var interval time.Duration
+	var once bool
+	var sortKey string
+
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "Live resource view of MineOS containers and Minecraft servers",
+		Long: `Show CPU, memory, players and TPS for every Minecraft server together with
+docker stats for the MineOS containers, refreshed every --interval.
+
+Keys: s cycles the sort column, c/m/p/t/n sort by CPU, memory, players, TPS
+or name, r reverses the order and q quits. Sorting by TPS puts the slowest
+servers first.
+
+--once, or output that is not a terminal, prints a single snapshot.`,
+		Example: `  mineos top
+  mineos top --sort tps
+  mineos top --once`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			if !isTopSort(sortKey) {
+				return fmt.Errorf("unknown sort %q (use cpu, mem, players, tps or name)", sortKey)
+			}
+			compose, _, err := loadComposeAndConfig(ctx, loadConfig)
+			if err != nil {
+				return err
+			}
+			fetch := func(ctx context.Context) tui.TopSnapshot {
+				return collectTopSnapshot(ctx, loadConfig, compose)
+			}
+
+			if once || machineMode || !term.IsTerminal(int(os.Stdout.Fd())) {
+				snapshot := fetch(ctx)
+				fmt.Fprintln(cmd.OutOrStdout(), tui.RenderTop(snapshot, sortKey, false, 0))
+				return nil
+			}
+			return tui.RunTop(ctx, tui.TopOptions{Interval: interval, Sort: sortKey, Fetch: fetch}, cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", time.Second, "Time between refreshes")
+	cmd.Flags().BoolVar(&once, "once", false, "Print one snapshot and exit")
+	cmd.Flags().StringVar(&sortKey, "sort", tui.TopSortCPU, "Sort by cpu, mem, players, tps or name")
+
+	return cmd
+}
+
+func isTopSort(key string) bool {
+	switch key {
+	case tui.TopSortCPU, tui.TopSortMemory, tui.TopSortPlayers, tui.TopSortTps, tui.TopSortName:
+		return true
+	}
+	return false
+}
+
+// collectTopSnapshot reads docker stats and the server samples side by side.
+// A source that fails is reported in Errors and leaves its table empty.
+func collectTopSnapshot(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, compose composeRunner) tui.TopSnapshot {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	snapshot := tui.TopSnapshot{Taken: time.Now()}
+	var wg sync.WaitGroup
+	var containerErr, serverErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		snapshot.Containers, containerErr = containerStats(ctx, compose)
+	}()
+	go func() {
+		defer wg.Done()
+		snapshot.Servers, serverErr = serverSamples(ctx, loadConfig)
+	}()
+	wg.Wait()
+
+	if containerErr != nil {
+		snapshot.Errors = append(snapshot.Errors, "docker stats: "+containerErr.Error())
+	}
+	if serverErr != nil {
+		snapshot.Errors = append(snapshot.Errors, "servers: "+serverErr.Error())
+	}
+	return snapshot
+}
+
+// dockerStatsLine is one line of 'docker stats --format {{json .}}'.
+type dockerStatsLine struct {
+	Name     string `json:"Name"`
+	CPUPerc  string `json:"CPUPerc"`
+	MemUsage string `json:"MemUsage"`
+	NetIO    string `json:"NetIO"`
+	BlockIO  string `json:"BlockIO"`
+	PIDs     string `json:"PIDs"`
+}
+
+func containerStats(ctx context.Context, compose composeRunner) ([]tui.TopContainer, error) {
+	ps := compose.command(ctx, []string{"ps", "-q"}, nil)
+	out, err := ps.Output()
+	if err != nil {
+		return nil, err
+	}
+	ids := strings.Fields(string(out))
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	stats := compose.docker(ctx, append([]string{"stats", "--no-stream", "--format", "{{json .}}"}, ids...)...)
+	var stderr bytes.Buffer
+	stats.Stderr = &stderr
+	out, err = stats.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	var containers []tui.TopContainer
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var row dockerStatsLine
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			continue
+		}
+		used, limit, _ := strings.Cut(row.MemUsage, "/")
+		containers = append(containers, tui.TopContainer{
+			Name:       row.Name,
+			CPUPercent: parsePercent(row.CPUPerc),
+			MemUsedMb:  parseDockerSize(used) / (1 << 20),
+			MemLimitMb: parseDockerSize(limit) / (1 << 20),
+			NetIO:      row.NetIO,
+			BlockIO:    row.BlockIO,
+			PIDs:       row.PIDs,
+		})
+	}
+	return containers, nil
+}
+
+func serverSamples(ctx context.Context, loadConfig *usecases.LoadConfigUseCase) ([]tui.TopServer, error) {
+	var servers []tui.TopServer
+	_, err := withApiKeyRetry(ctx, loadConfig, io.Discard, func(_ config.Config, client *api.Client) error {
+		list, err := client.ListServers(ctx)
+		if err != nil {
+			return err
+		}
+		servers = make([]tui.TopServer, len(list))
+		var wg sync.WaitGroup
+		for i, server := range list {
+			servers[i] = tui.TopServer{Name: server.Name, Running: server.Status == "running"}
+			if !servers[i].Running {
+				continue
+			}
+			wg.Add(1)
+			go func(row *tui.TopServer) {
+				defer wg.Done()
+				sample, err := client.GetPerformance(ctx, row.Name)
+				if err != nil {
+					return
+				}
+				row.CPUPercent = sample.CpuPercent
+				row.RamUsedMb = sample.RamUsedMb
+				row.RamTotalMb = sample.RamTotalMb
+				row.Players = sample.PlayerCount
+				row.Tps = sample.Tps
+			}(&servers[i])
+		}
+		wg.Wait()
+		return nil
+	})
+	return servers, err
+}
+
+func parsePercent(value string) float64 {
+	f, _ := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+	return f
+}
+
+// parseDockerSize reads the sizes docker stats prints, such as "512MiB" or
+// "1.2GB", in bytes.
+func parseDockerSize(value string) float64 {
+	value = strings.TrimSpace(value)
+	units := []struct {
+		suffix string
+		factor float64
+	}{
+		{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+		{"kB", 1e3}, {"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+		{"B", 1},
+	}
+	for _, unit := range units {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			f, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+			if err != nil {
+				return 0
+			}
+			return f * unit.factor
+		}
+	}
+	return 0
+}
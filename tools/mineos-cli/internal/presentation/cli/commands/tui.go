@@ -8,13 +8,29 @@ import (
 )
 
 func NewTuiCommand(loadConfig *usecases.LoadConfigUseCase, version string) *cobra.Command {
-	return &cobra.Command{
+	var readOnly bool
+
+	cmd := &cobra.Command{
 		Use:     "tui",
 		Aliases: []string{"ui"},
 		Short:   "Full-screen MineOS dashboard",
+		Long: `Full-screen MineOS dashboard.
+
+--read-only (or MINEOS_TUI_READ_ONLY=true in .env) hides every action that
+changes the installation: container and server controls, console commands,
+settings toggles and the SYSTEM and PLUGINS menus. Views and logs keep
+working, which suits wall-mounted status displays.
+
+With --accessible the dashboard is laid out in one column, the current view
+first and then the selected menu item, without box drawing or symbols.`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			// This command is kept for explicit access, but the default `mineos` already launches the TUI.
-			return tui.RunTui(cmd.Context(), loadConfig, version, cmd.InOrStdin(), cmd.OutOrStdout())
+			opts := tui.Options{LogTimes: logTimes(), ReadOnly: readOnly, Accessible: accessibleMode, Remote: sshRemote}
+			return tui.RunTui(cmd.Context(), loadConfig, version, opts, cmd.InOrStdin(), cmd.OutOrStdout())
 		},
 	}
+
+	cmd.Flags().BoolVar(&readOnly, "read-only", false, "Hide all actions that change the installation")
+
+	return cmd
 }
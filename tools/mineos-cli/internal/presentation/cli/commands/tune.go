@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	domain "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/tuning"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/tuning"
+)
+
+func NewTuneCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var apply bool
+	var yes bool
+	var revert bool
+	var revertFile string
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "tune",
+		Short: "Check host kernel settings for Java servers",
+		Long: `Inspect the host kernel settings that matter for Minecraft servers and report
+the ones worth changing:
+
+  vm.swappiness          how eagerly the kernel swaps out the JVM heap
+  transparent hugepages  "always" causes latency spikes; madvise lets the JVM opt in
+  zram                   compressed swap, worth having on small hosts
+  open file limits       fs.file-max and the limit containers inherit from Docker
+
+--apply makes the recommended changes now and for the next boot
+(/etc/sysctl.d, /etc/tmpfiles.d and a Docker systemd drop-in) and needs
+root. The previous values are saved to a revert file (default: ` + tuning.DefaultRevertFileName + `
+in ` + state.InstallationDirName + `/ next to .env) so --revert can put everything back. zram is only reported:
+setting it up depends on the distribution.`,
+		Example: `  mineos tune
+  sudo mineos tune --apply
+  sudo mineos tune --revert`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if runtime.GOOS != "linux" {
+				return fmt.Errorf("'mineos tune' checks Linux kernel settings; on %s Docker runs in a VM whose kernel is managed by Docker Desktop", runtime.GOOS)
+			}
+			if apply && revert {
+				return errors.New("use either --apply or --revert")
+			}
+			if (apply || revert) && !isRoot() {
+				return errors.New("changing kernel settings needs root; run it with sudo")
+			}
+			if revertFile == "" && (apply || revert) {
+				// Tuning a host before installing is fine; the revert file
+				// then goes to the current directory.
+				envPath := ""
+				if cfg, err := loadConfig.Execute(context.Background()); err == nil {
+					envPath = cfg.EnvPath
+				}
+				revertFile = tuning.RevertPathForEnv(resolveEnvPath(envPath))
+			}
+			out := cmd.OutOrStdout()
+
+			if revert {
+				restored, err := tuning.Revert("/", revertFile)
+				if err != nil {
+					return err
+				}
+				for _, value := range restored.Values {
+					fmt.Fprintf(out, "%s %s restored to %s\n", markOK(), value.Key, value.Previous)
+				}
+				for _, file := range restored.Files {
+					action := "restored"
+					if !file.Existed {
+						action = "removed"
+					}
+					fmt.Fprintf(out, "%s %s %s\n", markOK(), file.Path, action)
+				}
+				printDockerRestartNote(out, restored.Files)
+				return nil
+			}
+
+			findings := domain.Analyze(tuning.Read("/"))
+			if jsonOut && !apply {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(findings)
+			}
+			printTuneFindings(out, findings)
+
+			changes := domain.Changes(findings)
+			if len(changes) == 0 {
+				if apply {
+					fmt.Fprintln(out, styleSuccess.Render("Nothing to apply."))
+				}
+				return nil
+			}
+			if !apply {
+				fmt.Fprintln(out)
+				fmt.Fprintln(out, styleDim.Render("Run 'sudo mineos tune --apply' to make the recommended changes."))
+				return nil
+			}
+			if !yes {
+				ok, err := promptYesNo(nil, out, fmt.Sprintf("Apply %d change(s)?", len(changes)), true)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return nil
+				}
+			}
+			applied, err := tuning.Apply("/", changes, revertFile)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(out)
+			for _, change := range changes {
+				fmt.Fprintf(out, "%s %s = %s\n", markOK(), change.Key, change.Value)
+			}
+			for _, file := range applied.Files {
+				printStat(out, "Wrote", file)
+			}
+			printStat(out, "Revert file", revertFile)
+			if applied.RestartDocker {
+				fmt.Fprintln(out, styleWarning.Render("The open file limit applies once Docker restarts:"))
+				fmt.Fprintln(out, "  sudo systemctl daemon-reload && sudo systemctl restart docker")
+			}
+			fmt.Fprintln(out, styleDim.Render("Undo with 'sudo mineos tune --revert'."))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&apply, "apply", false, "Apply the recommended changes (needs root)")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Apply without asking")
+	cmd.Flags().BoolVar(&revert, "revert", false, "Undo the changes a previous --apply made")
+	cmd.Flags().StringVar(&revertFile, "revert-file", "", "Where previous values are saved (default: "+tuning.DefaultRevertFileName+" in "+state.InstallationDirName+"/)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the findings as JSON")
+
+	return cmd
+}
+
+func printTuneFindings(out io.Writer, findings []domain.Finding) {
+	if len(findings) == 0 {
+		fmt.Fprintln(out, styleWarning.Render("No kernel settings could be read."))
+		return
+	}
+	width := 0
+	for _, finding := range findings {
+		width = max(width, len(finding.Title))
+	}
+	for _, finding := range findings {
+		mark := markOK()
+		switch finding.Status {
+		case domain.StatusWarn:
+			mark = styleWarning.Render("!")
+		case domain.StatusInfo:
+			mark = styleInfo.Render("i")
+		}
+		line := fmt.Sprintf("%s %-*s  %s", mark, width, finding.Title, finding.Current)
+		if finding.Status != domain.StatusOK && finding.Recommended != "" {
+			line += styleDim.Render(" (recommended: " + finding.Recommended + ")")
+		}
+		fmt.Fprintln(out, line)
+		if finding.Status != domain.StatusOK && finding.Reason != "" {
+			fmt.Fprintln(out, "  "+styleDim.Render(finding.Reason))
+		}
+		if finding.Hint != "" {
+			fmt.Fprintln(out, "  "+styleDim.Render(finding.Hint))
+		}
+	}
+}
+
+func printDockerRestartNote(out io.Writer, files []tuning.RevertFileEntry) {
+	for _, file := range files {
+		if file.Path == tuning.DockerDropIn {
+			fmt.Fprintln(out, styleWarning.Render("Restart Docker for the open file limit to go back:"))
+			fmt.Fprintln(out, "  sudo systemctl daemon-reload && sudo systemctl restart docker")
+			return
+		}
+	}
+}
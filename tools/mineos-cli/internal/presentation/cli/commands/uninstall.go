@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"archive/zip"
 	"bufio"
 	"context"
 	"errors"
@@ -17,6 +16,11 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 
+	domainbackup "github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/backup"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/backup"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/confirm"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/ssh"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/telemetry"
 )
 
@@ -32,6 +36,7 @@ type uninstallOptions struct {
 	removeVols  bool
 	removeCLI   bool
 	removeAll   bool
+	confirmCode string
 }
 
 var errUninstallCancelled = errors.New("uninstall cancelled")
@@ -50,8 +55,9 @@ func NewUninstallCommand() *cobra.Command {
 	cmd.Flags().StringVar(&opts.mode, "mode", "", "Uninstall mode: containers|backup|remove|complete (interactive if empty)")
 	cmd.Flags().BoolVar(&opts.skipConfirm, "yes", false, "Skip the DELETE confirmation for destructive options")
 	cmd.Flags().BoolVar(&opts.removeVols, "volumes", false, "Also remove Docker volumes when deleting data")
-	cmd.Flags().BoolVar(&opts.removeCLI, "remove-cli", false, "Remove CLI from system PATH")
+	cmd.Flags().BoolVar(&opts.removeCLI, "remove-cli", false, "Remove the CLI from PATH (on Windows also its shortcuts and Apps & Features entry)")
 	cmd.Flags().BoolVar(&opts.removeAll, "remove-all", false, "Remove everything including the MineOS installation directory")
+	addConfirmTokenFlag(cmd, &opts.confirmCode)
 
 	return cmd
 }
@@ -72,6 +78,11 @@ func runUninstall(cmd *cobra.Command, opts uninstallOptions) error {
 	if err != nil {
 		return err
 	}
+	if mode != "containers" {
+		if err := requireConfirmToken(confirmConfig(cmd.Context(), nil), confirmUninstall, opts.confirmCode, out); err != nil {
+			return err
+		}
+	}
 
 	fmt.Fprintln(out, uninstallBanner)
 	fmt.Fprintln(out, "MineOS Uninstall")
@@ -86,7 +97,7 @@ func runUninstall(cmd *cobra.Command, opts uninstallOptions) error {
 		if err := compose.down(false); err != nil {
 			return err
 		}
-		fmt.Fprintln(out, "✓ Containers removed. Data preserved.")
+		fmt.Fprintln(out, markOK()+" Containers removed. Data preserved.")
 
 	case "backup":
 		fmt.Fprintln(out, "Backing up data and removing everything...")
@@ -109,7 +120,7 @@ func runUninstall(cmd *cobra.Command, opts uninstallOptions) error {
 		if err := removeLocalData(out); err != nil {
 			return err
 		}
-		fmt.Fprintf(out, "✓ Containers and data removed. Backup created at %s\n", backupRoot)
+		fmt.Fprintf(out, "%s Containers and data removed. Backup created at %s\n", markOK(), backupRoot)
 
 	case "remove":
 		fmt.Fprintln(out, "Removing containers and data (no backup)...")
@@ -125,7 +136,7 @@ func runUninstall(cmd *cobra.Command, opts uninstallOptions) error {
 		if err := removeLocalData(out); err != nil {
 			return err
 		}
-		fmt.Fprintln(out, "✓ Containers and data removed.")
+		fmt.Fprintln(out, markOK()+" Containers and data removed.")
 
 	case "complete":
 		fmt.Fprintln(out, "Complete uninstall - removing EVERYTHING...")
@@ -146,13 +157,17 @@ func runUninstall(cmd *cobra.Command, opts uninstallOptions) error {
 			fmt.Fprintf(out, "Warning: Failed to remove data: %v\n", err)
 		}
 
+		if err := removeCLIFromPath(out); err != nil {
+			fmt.Fprintf(out, "Warning: Failed to remove the CLI: %v\n", err)
+		}
+
 		// Remove entire installation directory
 		if err := removeInstallationDirectory(out); err != nil {
 			fmt.Fprintf(out, "Warning: Failed to remove installation directory: %v\n", err)
 		}
 
 		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, "✓ Complete uninstall finished!")
+		fmt.Fprintln(out, markOK()+" Complete uninstall finished!")
 		fmt.Fprintln(out, "MineOS has been completely removed from your system.")
 		return nil
 
@@ -160,6 +175,12 @@ func runUninstall(cmd *cobra.Command, opts uninstallOptions) error {
 		return fmt.Errorf("unknown uninstall mode: %s", mode)
 	}
 
+	if opts.removeCLI {
+		if err := removeCLIFromPath(out); err != nil {
+			return err
+		}
+	}
+
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Additional cleanup:")
 	fmt.Fprintln(out, "  - To remove Docker images: docker image prune -a")
@@ -188,6 +209,9 @@ func resolveUninstallMode(cmd *cobra.Command, mode string) (string, error) {
 }
 
 func promptUninstallMode(cmd *cobra.Command) (string, error) {
+	if machineMode {
+		return "", errMachinePrompt
+	}
 	fmt.Println("")
 	fmt.Println("Choose an uninstall option:")
 	fmt.Println("  1) Remove containers only (keep all data) [default]")
@@ -214,6 +238,9 @@ func confirmDestructive(cmd *cobra.Command, skip bool) error {
 	if skip {
 		return nil
 	}
+	if machineMode {
+		return errMachinePrompt
+	}
 	fmt.Println("This will permanently delete database files and local data.")
 	fmt.Print("Type DELETE to continue: ")
 
@@ -242,8 +269,16 @@ func backupData(out io.Writer) (string, error) {
 
 	if _, err := os.Stat("data"); err == nil {
 		fmt.Fprintln(out, "Backing up local data folder...")
-		zipPath := filepath.Join(backupRoot, "sqlite-data.zip")
-		if err := zipDir("data", zipPath); err != nil {
+		archivePath := filepath.Join(backupRoot, "sqlite-data."+string(domainbackup.FormatZstd))
+		bar := newTransferProgress(out, filepath.Base(archivePath), 0)
+		opts := backup.Options{
+			Format:    domainbackup.FormatZstd,
+			Progress:  bar,
+			OnPlanned: func(_ int, bytes int64) { bar.SetTotal(bytes) },
+		}
+		_, err := streamBackup(context.Background(), "data", "data", archivePath, opts)
+		bar.Finish()
+		if err != nil {
 			return "", err
 		}
 	}
@@ -262,7 +297,8 @@ func removeLocalData(out io.Writer) error {
 	items := []string{
 		"data", "backups", "logs",
 		"docker-compose.yml", "docker-compose.override.yml",
-		".env", ".env.bak",
+		resourceLimitsFile, composeOverridesDir,
+		".env", ".env.bak", confirm.File, state.InstallationDirName,
 	}
 	for _, item := range items {
 		if _, err := os.Stat(item); err == nil {
@@ -276,55 +312,6 @@ func removeLocalData(out io.Writer) error {
 	return nil
 }
 
-func zipDir(srcDir, destZip string) error {
-	zipFile, err := os.Create(destZip)
-	if err != nil {
-		return err
-	}
-	defer zipFile.Close()
-
-	writer := zip.NewWriter(zipFile)
-	defer writer.Close()
-
-	return filepath.WalkDir(srcDir, func(path string, d os.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return walkErr
-		}
-		if d.IsDir() {
-			return nil
-		}
-		relPath, err := filepath.Rel(srcDir, path)
-		if err != nil {
-			return err
-		}
-
-		zipPath := filepath.ToSlash(relPath)
-		file, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
-
-		info, err := d.Info()
-		if err != nil {
-			return err
-		}
-		header, err := zip.FileInfoHeader(info)
-		if err != nil {
-			return err
-		}
-		header.Name = zipPath
-		header.Method = zip.Deflate
-
-		writerEntry, err := writer.CreateHeader(header)
-		if err != nil {
-			return err
-		}
-		_, err = io.Copy(writerEntry, file)
-		return err
-	})
-}
-
 func copyFile(src, dest string) error {
 	in, err := os.Open(src)
 	if err != nil {
@@ -351,9 +338,17 @@ func copyFile(src, dest string) error {
 type composeRunner struct {
 	exe      string
 	baseArgs []string
+	// remote runs compose on the --ssh host instead of locally.
+	remote *ssh.Remote
+	// platform overrides the image platform, e.g. linux/amd64; empty uses
+	// the Docker host's own.
+	platform string
 }
 
 func detectCompose() (composeRunner, error) {
+	if sshRemote != nil {
+		return composeRunner{exe: "docker", baseArgs: []string{"compose"}, remote: sshRemote}, nil
+	}
 	if _, err := exec.LookPath("docker"); err == nil {
 		cmd := exec.Command("docker", "compose", "version")
 		if err := cmd.Run(); err == nil {
@@ -391,79 +386,6 @@ func shouldRemoveVolumes(opts uninstallOptions) bool {
 	return runtime.GOOS == "windows"
 }
 
-func removeCLIFromPath(out io.Writer) error {
-	if runtime.GOOS == "windows" {
-		return removeCLIFromPathWindows(out)
-	}
-	return removeCLIFromPathUnix(out)
-}
-
-func removeCLIFromPathWindows(out io.Writer) error {
-	localAppData := os.Getenv("LOCALAPPDATA")
-	if localAppData == "" {
-		return errors.New("LOCALAPPDATA environment variable not set")
-	}
-
-	installDir := filepath.Join(localAppData, "Programs", "MineOS")
-	exePath := filepath.Join(installDir, "mineos.exe")
-
-	if _, err := os.Stat(exePath); err == nil {
-		if err := os.Remove(exePath); err != nil {
-			return fmt.Errorf("failed to remove CLI: %w", err)
-		}
-		fmt.Fprintf(out, "✓ Removed CLI from: %s\n", exePath)
-
-		// Try to remove the directory if empty
-		os.Remove(installDir)
-		os.Remove(filepath.Dir(installDir)) // Try to remove Programs dir if empty
-
-		fmt.Fprintln(out, "")
-		fmt.Fprintln(out, "Note: You may need to manually remove the PATH entry:")
-		fmt.Fprintf(out, "  %s\n", installDir)
-		fmt.Fprintln(out, "Follow the same steps as during installation to edit your PATH.")
-	} else {
-		fmt.Fprintln(out, "CLI not found in system PATH location.")
-	}
-
-	return nil
-}
-
-func removeCLIFromPathUnix(out io.Writer) error {
-	// Check both possible install locations
-	systemBin := "/usr/local/bin/mineos"
-	homeDir, _ := os.UserHomeDir()
-	userBin := ""
-	if homeDir != "" {
-		userBin = filepath.Join(homeDir, ".local", "bin", "mineos")
-	}
-
-	removed := false
-
-	if _, err := os.Stat(systemBin); err == nil {
-		if err := os.Remove(systemBin); err != nil {
-			return fmt.Errorf("failed to remove CLI from %s: %w", systemBin, err)
-		}
-		fmt.Fprintf(out, "✓ Removed CLI from: %s\n", systemBin)
-		removed = true
-	}
-
-	if userBin != "" {
-		if _, err := os.Stat(userBin); err == nil {
-			if err := os.Remove(userBin); err != nil {
-				return fmt.Errorf("failed to remove CLI from %s: %w", userBin, err)
-			}
-			fmt.Fprintf(out, "✓ Removed CLI from: %s\n", userBin)
-			removed = true
-		}
-	}
-
-	if !removed {
-		fmt.Fprintln(out, "CLI not found in system PATH locations.")
-	}
-
-	return nil
-}
-
 // reportUninstallTelemetry reads .env for telemetry config and notifies the
 // telemetry server that this installation is being removed.
 func reportUninstallTelemetry(out io.Writer) {
@@ -522,7 +444,7 @@ func removeInstallationDirectory(out io.Writer) error {
 			return fmt.Errorf("failed to start cleanup script: %w", err)
 		}
 
-		fmt.Fprintln(out, "✓ Cleanup scheduled. Directory will be removed in 2 seconds.")
+		fmt.Fprintln(out, markOK()+" Cleanup scheduled. Directory will be removed in 2 seconds.")
 		return nil
 	}
 
@@ -544,6 +466,6 @@ func removeInstallationDirectory(out io.Writer) error {
 		return fmt.Errorf("failed to start cleanup script: %w", err)
 	}
 
-	fmt.Fprintln(out, "✓ Cleanup scheduled. Directory will be removed in 2 seconds.")
+	fmt.Fprintln(out, markOK()+" Cleanup scheduled. Directory will be removed in 2 seconds.")
 	return nil
 }
@@ -14,6 +14,7 @@ func NewUpdateCommand(loadConfig *usecases.LoadConfigUseCase, currentVersion str
 	var skipStack bool
 	var force bool
 	var prerelease bool
+	var platform string
 
 	cmd := &cobra.Command{
 		Use:   "update",
@@ -58,13 +59,16 @@ Examples:
 				if timeout > 0 {
 					_ = stackCmd.Flags().Set("timeout", fmt.Sprintf("%d", timeout))
 				}
+				if platform != "" {
+					_ = stackCmd.Flags().Set("platform", platform)
+				}
 				if err := stackCmd.RunE(cmd, []string{}); err != nil {
 					return fmt.Errorf("stack update failed: %w", err)
 				}
 			}
 
 			fmt.Fprintln(out, "")
-			fmt.Fprintln(out, "✓ MineOS update complete!")
+			fmt.Fprintln(out, markOK()+" MineOS update complete!")
 			return nil
 		},
 	}
@@ -74,6 +78,7 @@ Examples:
 	cmd.Flags().BoolVar(&skipStack, "skip-stack", false, "Skip container update, only upgrade CLI binary")
 	cmd.Flags().BoolVar(&force, "force", false, "Force CLI upgrade even if already on latest")
 	cmd.Flags().BoolVar(&prerelease, "prerelease", false, "Include pre-release/beta versions")
+	cmd.Flags().StringVar(&platform, "platform", "", "Image platform to pull, e.g. linux/amd64 (see 'mineos stack update')")
 
 	return cmd
 }
@@ -4,37 +4,23 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
-)
 
-const (
-	githubRepo       = "freeman412/mineos-sveltekit"
-	githubAPIBase    = "https://api.github.com"
-	latestReleaseURL = githubAPIBase + "/repos/" + githubRepo + "/releases/latest"
-	allReleasesURL   = githubAPIBase + "/repos/" + githubRepo + "/releases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/download"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/releases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/state"
 )
 
-type githubRelease struct {
-	TagName    string        `json:"tag_name"`
-	Assets     []githubAsset `json:"assets"`
-	Prerelease bool          `json:"prerelease"`
-}
-
-type githubAsset struct {
-	Name               string `json:"name"`
-	BrowserDownloadURL string `json:"browser_download_url"`
-}
-
 func NewUpgradeCommand(currentVersion string) *cobra.Command {
 	var force bool
 	var check bool
@@ -65,8 +51,6 @@ Examples:
 	return cmd
 }
 
-var errNoReleases = errors.New("no releases found")
-
 func runUpgrade(cmd *cobra.Command, currentVersion string, force, checkOnly, includePrerelease bool) error {
 	out := cmd.OutOrStdout()
 
@@ -81,9 +65,9 @@ func runUpgrade(cmd *cobra.Command, currentVersion string, force, checkOnly, inc
 
 	fmt.Fprintln(out, "Checking for updates...")
 
-	release, err := fetchBestRelease(includePrerelease)
+	release, err := releases.Best(cmd.Context(), includePrerelease)
 	if err != nil {
-		if errors.Is(err, errNoReleases) {
+		if errors.Is(err, releases.ErrNoReleases) {
 			fmt.Fprintf(out, "Current version: %s\n", currentVersion)
 			fmt.Fprintln(out, "No releases available yet. You are running the latest code.")
 			return nil
@@ -168,35 +152,29 @@ func runUpgrade(cmd *cobra.Command, currentVersion string, force, checkOnly, inc
 		return fmt.Errorf("failed to resolve executable path: %w", err)
 	}
 
-	// Download to temp file
-	tmpFile, err := os.CreateTemp("", "mineos-upgrade-*")
+	// Download into the cache so an interrupted transfer resumes on the next run
+	archivePath, err := upgradeDownloadPath(latestVersion, assetName)
 	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
-	}
-	tmpPath := tmpFile.Name()
-	defer os.Remove(tmpPath)
-
-	resp, err := http.Get(downloadURL)
-	if err != nil {
-		tmpFile.Close()
-		return fmt.Errorf("failed to download: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		tmpFile.Close()
-		return fmt.Errorf("download failed with status: %s", resp.Status)
+		return fmt.Errorf("failed to prepare download: %w", err)
 	}
+	defer os.Remove(archivePath)
 
-	_, err = io.Copy(tmpFile, resp.Body)
-	tmpFile.Close()
+	bar := newTransferProgress(out, assetName, 0)
+	err = download.Fetch(cmd.Context(), transferClient(), downloadURL, archivePath, download.Options{
+		SHA256: assetSHA256(release.Assets, assetName),
+		Progress: func(current, total int64) {
+			bar.SetTotal(total)
+			bar.Set(current)
+		},
+	})
+	bar.Finish()
 	if err != nil {
-		return fmt.Errorf("failed to save download: %w", err)
+		return fmt.Errorf("failed to download: %w (run 'mineos upgrade' again to resume)", err)
 	}
 
 	// Extract binary from archive
 	fmt.Fprintln(out, "Extracting...")
-	binaryPath, err := extractBinary(tmpPath, assetName)
+	binaryPath, err := extractBinary(archivePath, assetName)
 	if err != nil {
 		return fmt.Errorf("failed to extract: %w", err)
 	}
@@ -219,61 +197,28 @@ func runUpgrade(cmd *cobra.Command, currentVersion string, force, checkOnly, inc
 	return nil
 }
 
-func fetchLatestRelease() (*githubRelease, error) {
-	resp, err := http.Get(latestReleaseURL)
+// upgradeDownloadPath is where a release archive is downloaded. The name
+// includes the version so a partial file is never resumed against a
+// different release.
+func upgradeDownloadPath(version, assetName string) (string, error) {
+	dir, err := os.UserCacheDir()
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, errNoReleases
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status: %s", resp.Status)
+		dir = os.TempDir()
 	}
-
-	var release githubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, fmt.Errorf("failed to parse release info: %w", err)
+	dir = filepath.Join(dir, "mineos", "downloads")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
 	}
-
-	return &release, nil
+	return filepath.Join(dir, version+"-"+assetName), nil
 }
 
-// fetchBestRelease fetches the best available release based on pre-release preference.
-// If includePrerelease is true, it will consider pre-release versions.
-func fetchBestRelease(includePrerelease bool) (*githubRelease, error) {
-	// If we only want stable releases, use the latest release endpoint
-	if !includePrerelease {
-		return fetchLatestRelease()
-	}
-
-	// Otherwise, fetch all releases and find the newest (including pre-releases)
-	resp, err := http.Get(allReleasesURL)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, errNoReleases
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status: %s", resp.Status)
-	}
-
-	var releases []githubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
-		return nil, fmt.Errorf("failed to parse releases info: %w", err)
-	}
-
-	if len(releases) == 0 {
-		return nil, errNoReleases
+func assetSHA256(assets []releases.Asset, name string) string {
+	for _, asset := range assets {
+		if asset.Name == name {
+			return strings.TrimPrefix(asset.Digest, "sha256:")
+		}
 	}
-
-	// GitHub returns releases sorted by created date descending, so first one is newest
-	return &releases[0], nil
+	return ""
 }
 
 func getAssetName() string {
@@ -425,6 +370,7 @@ func replaceBinary(oldPath, newPath string) error {
 		os.Remove(oldPath + ".new")
 		return err
 	}
+	clearQuarantine(oldPath)
 
 	return nil
 }
@@ -456,7 +402,7 @@ func CheckForUpdates(currentVersion string) string {
 	}
 
 	// Only check stable releases for background checks
-	release, err := fetchBestRelease(false)
+	release, err := latestRelease(context.Background())
 	if err != nil {
 		return ""
 	}
@@ -474,3 +420,29 @@ func CheckForUpdates(currentVersion string) string {
 
 	return ""
 }
+
+// updateCheckInterval is how long the background check reuses the latest
+// release it found, so that not every command asks GitHub.
+const updateCheckInterval = 24 * time.Hour
+
+// latestRelease is releases.Latest, remembered in the state store for
+// updateCheckInterval. The store is optional: without it every run asks.
+func latestRelease(ctx context.Context) (*releases.Release, error) {
+	store, err := state.Open(state.DefaultPath())
+	if err != nil {
+		return releases.Latest(ctx)
+	}
+	defer store.Close()
+
+	var release releases.Release
+	checked, found, err := store.Get(ctx, state.NamespaceUpdateCheck, "latest", &release)
+	if err == nil && found && time.Since(checked) < updateCheckInterval {
+		return &release, nil
+	}
+	latest, err := releases.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_ = store.Put(ctx, state.NamespaceUpdateCheck, "latest", releases.Release{TagName: latest.TagName, Prerelease: latest.Prerelease})
+	return latest, nil
+}
@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/argon2"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+)
+
+func NewUsersCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "users",
+		Short: "Recover MineOS web accounts",
+	}
+
+	cmd.AddCommand(newUsersResetAdminCommand(loadConfig))
+	return cmd
+}
+
+func newUsersResetAdminCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var username string
+	var password string
+	var generate bool
+	var noRestart bool
+	var confirmToken string
+
+	cmd := &cobra.Command{
+		Use:   "reset-admin",
+		Short: "Reset an admin password directly in the database",
+		Long: `Break-glass recovery for an installation nobody can sign in to: sets a new
+password for an admin account directly in the sqlite database, like
+'mineos api-key refresh' does for the API key, so it works without the web
+UI or a valid API key. The account is re-activated if it was disabled and
+made an admin if it was not one.
+
+Without --username the seeded admin (Auth__SeedUsername) is reset, or else
+the oldest admin. The api container is restarted afterwards.
+
+Run it on the host: the database is not reachable over --ssh.`,
+		Example: `  mineos users reset-admin
+  mineos users reset-admin --username alice --generate`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			out := cmd.OutOrStdout()
+			if sshRemote != nil {
+				return errors.New("users reset-admin edits the database file; run it on the MineOS host instead of over --ssh")
+			}
+			if generate && password != "" {
+				return errors.New("--generate: use either --password or --generate")
+			}
+			cfg, err := loadConfig.Execute(ctx)
+			if err != nil {
+				return err
+			}
+			if !isSqliteConfig(cfg) {
+				return errors.New("admin password reset is only supported for sqlite installations")
+			}
+			if err := requireConfirmToken(cfg, confirmResetAdmin, confirmToken, out); err != nil {
+				return err
+			}
+			envPath := resolveEnvPath(cfg.EnvPath)
+			dbPath, err := resolveSqliteDbPath(cfg, resolveDataDir(cfg, envPath))
+			if err != nil {
+				return err
+			}
+			db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			user, err := findAdminToReset(ctx, db, cfg, username)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case generate:
+				buf := make([]byte, 15)
+				if _, err := rand.Read(buf); err != nil {
+					return err
+				}
+				password = base64.RawURLEncoding.EncodeToString(buf)
+			case password == "":
+				password, err = promptNewPassword(out, user)
+				if err != nil {
+					return err
+				}
+			}
+			hash, err := hashPassword(password)
+			if err != nil {
+				return err
+			}
+			if _, err := db.ExecContext(ctx, `UPDATE Users SET PasswordHash = ?, Role = 'admin', IsActive = 1 WHERE Id = ?`, hash, user.id); err != nil {
+				return fmt.Errorf("update %s: %w", dbPath, err)
+			}
+			fmt.Fprintf(out, "%s Password of %s reset.\n", markOK(), user.username)
+			if user.role != "admin" || !user.active {
+				fmt.Fprintf(out, "  %s\n", styleDim.Render("The account is now an active admin."))
+			}
+			if generate {
+				fmt.Fprintf(out, "  New password: %s\n", password)
+			}
+
+			// The seeded password in .env is what reconfigure shows as the
+			// current one; keep it true.
+			if values, err := loadLayeredEnvValues(cfg); err == nil && strings.TrimSpace(values["Auth__SeedUsername"]) == user.username {
+				if err := setEnvFileValue(envPath, "Auth__SeedPassword", password); err != nil {
+					fmt.Fprintf(out, "Warning: Auth__SeedPassword in %s not updated: %v\n", envPath, err)
+				}
+			}
+
+			if noRestart {
+				return nil
+			}
+			// Locked out often means a wedged API as well; a restart is part
+			// of the recovery.
+			return restartApiContainer(ctx, cfg, out)
+		},
+	}
+
+	cmd.Flags().StringVar(&username, "username", "", "Account to reset (default: the seeded admin, or the oldest admin)")
+	cmd.Flags().StringVar(&password, "password", "", "New password (default: prompt)")
+	cmd.Flags().BoolVar(&generate, "generate", false, "Generate a random password and print it")
+	cmd.Flags().BoolVar(&noRestart, "no-restart", false, "Do not restart the api container")
+	addConfirmTokenFlag(cmd, &confirmToken)
+	return cmd
+}
+
+type resetUser struct {
+	id       int64
+	username string
+	role     string
+	active   bool
+}
+
+// findAdminToReset picks the named account, or the seeded admin, or the
+// oldest admin.
+func findAdminToReset(ctx context.Context, db *sql.DB, cfg config.Config, username string) (resetUser, error) {
+	query := func(where string, args ...any) (resetUser, error) {
+		var user resetUser
+		err := db.QueryRowContext(ctx, `SELECT Id, Username, Role, IsActive FROM Users WHERE `+where+` ORDER BY Id LIMIT 1`, args...).
+			Scan(&user.id, &user.username, &user.role, &user.active)
+		return user, err
+	}
+
+	if username = strings.TrimSpace(username); username != "" {
+		user, err := query(`Username = ?`, username)
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, ports.WithKind(fmt.Errorf("no user named %s", username), ports.ErrNotFound)
+		}
+		return user, err
+	}
+	if values, err := loadLayeredEnvValues(cfg); err == nil {
+		if seed := strings.TrimSpace(values["Auth__SeedUsername"]); seed != "" {
+			user, err := query(`Username = ? AND Role = 'admin'`, seed)
+			if err == nil || !errors.Is(err, sql.ErrNoRows) {
+				return user, err
+			}
+		}
+	}
+	user, err := query(`Role = 'admin'`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, ports.WithKind(errors.New("no admin account found; pass --username to make an existing user the admin"), ports.ErrNotFound)
+	}
+	return user, err
+}
+
+func promptNewPassword(out io.Writer, user resetUser) (string, error) {
+	password, err := promptPassword(out, "New password for "+user.username)
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", errors.New("the password cannot be empty")
+	}
+	again, err := promptPassword(out, "Repeat the password")
+	if err != nil {
+		return "", err
+	}
+	if again != password {
+		return "", errors.New("the passwords do not match")
+	}
+	return password, nil
+}
+
+// Argon2id parameters of the API's hasher, the Isopoh.Cryptography.Argon2
+// defaults. The parameters are encoded in the hash, so the API would verify
+// others too, but these keep the reset account like the rest.
+const (
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 1
+	argon2KeyLen  = 32
+	argon2SaltLen = 16
+)
+
+// hashPassword encodes password as the API stores it:
+// $argon2id$v=19$m=65536,t=3,p=1$<salt>$<hash>.
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// restartApiContainer restarts only the api service and waits for it.
+func restartApiContainer(ctx context.Context, cfg config.Config, out io.Writer) error {
+	compose, err := detectCompose()
+	if err != nil {
+		return fmt.Errorf("%w; restart the api container yourself", err)
+	}
+	fmt.Fprintln(out, "Restarting the api container...")
+	if err := composeWithConfig(compose, cfg).run([]string{"restart", "api"}); err != nil {
+		return err
+	}
+	return waitForApiReady(ctx, cfg, out, 60)
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/catalog"
+)
+
+func NewVersionsCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var snapshots bool
+	var limit int
+	var downloadedOnly bool
+
+	cmd := &cobra.Command{
+		Use:       "versions [vanilla|paper|fabric|forge]",
+		Short:     "List available Minecraft versions per server type",
+		Long:      "Query Mojang, PaperMC, Fabric and Forge for available versions and show which are already downloaded as MineOS profiles.",
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: catalog.SupportedSoftware,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			software := catalog.Vanilla
+			if len(args) == 1 {
+				software = strings.ToLower(args[0])
+			}
+			ctx := context.Background()
+
+			cat, err := cachedCatalog(ctx, software)
+			if err != nil {
+				return err
+			}
+			downloaded, profilesKnown := downloadedProfiles(ctx, loadConfig, software)
+
+			cmd.Printf("%s\n\n", styleTitle.Render("Minecraft versions: "+software))
+			printStat(cmd.OutOrStdout(), "Latest", fallback(cat.LatestRelease, "unknown"))
+			if cat.LatestSnapshot != "" && cat.LatestSnapshot != cat.LatestRelease {
+				printStat(cmd.OutOrStdout(), "Snapshot", cat.LatestSnapshot)
+			}
+			cmd.Println()
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tTYPE\tRELEASED\tDOWNLOADED\tDETAIL")
+			shown := 0
+			for _, version := range cat.Versions {
+				if !version.Stable && !snapshots {
+					continue
+				}
+				if downloadedOnly && !downloaded[version.ID] {
+					continue
+				}
+				if limit > 0 && shown >= limit {
+					break
+				}
+				shown++
+
+				kind := "release"
+				if !version.Stable {
+					kind = "snapshot"
+				}
+				released := "-"
+				if !version.ReleaseTime.IsZero() {
+					released = version.ReleaseTime.Format("2006-01-02")
+				}
+				status := "-"
+				if profilesKnown {
+					status = "no"
+					if downloaded[version.ID] {
+						status = styleSuccess.Render("yes")
+					}
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", version.ID, kind, released, status, fallback(version.Detail, "-"))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if shown == 0 {
+				cmd.Println("No matching versions.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&snapshots, "snapshots", false, "Include snapshots and pre-releases")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of versions to show (0 for all)")
+	cmd.Flags().BoolVar(&downloadedOnly, "downloaded", false, "Only show versions downloaded as profiles")
+
+	return cmd
+}
+
+// downloadedProfiles maps Minecraft versions to whether a profile for the
+// given server type is downloaded. It is best-effort: the second result is
+// false when the API is unreachable or the type has no MineOS profiles.
+func downloadedProfiles(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, software string) (map[string]bool, bool) {
+	downloaded := map[string]bool{}
+	if software != catalog.Vanilla && software != catalog.Paper {
+		return downloaded, false
+	}
+	cfg, err := loadConfig.Execute(ctx)
+	if err != nil {
+		return downloaded, false
+	}
+	profiles, err := cachedProfiles(ctx, cfg, api.NewClientFromConfig(cfg))
+	if err != nil {
+		return downloaded, false
+	}
+	for _, profile := range profiles {
+		if strings.EqualFold(profile.Group, software) && profile.Downloaded {
+			downloaded[profile.Version] = true
+		}
+	}
+	return downloaded, true
+}
+
+// completeMinecraftVersions offers release versions for the server type
+// selected by a command's --software flag.
+func completeMinecraftVersions(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	software, _ := cmd.Flags().GetString("software")
+	cat, err := cachedCatalog(cmd.Context(), software)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var versions []string
+	for _, version := range cat.Versions {
+		if version.Stable && strings.HasPrefix(version.ID, toComplete) {
+			versions = append(versions, version.ID)
+		}
+	}
+	return versions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
@@ -0,0 +1,356 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/anvil"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+)
+
+func NewWorldCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "world",
+		Short: "Inspect and repair world files on disk",
+	}
+
+	cmd.AddCommand(newWorldCheckCommand(loadConfig))
+	cmd.AddCommand(newWorldInfoCommand(loadConfig))
+
+	return cmd
+}
+
+// regionScan is the result of checking one region file.
+type regionScan struct {
+	Path     string
+	Rel      string
+	X, Z     int
+	Chunks   int
+	Problems []anvil.Problem
+}
+
+func newWorldCheckCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var world string
+	var dir string
+	var repair string
+	var yes bool
+	var confirmToken string
+
+	cmd := &cobra.Command{
+		Use:         "check [server]",
+		Annotations: map[string]string{destructiveAnnotation: "repair"},
+		Short:       "Scan region files for corrupt or truncated chunks",
+		Long: `Scan the Anvil region files (region/, entities/ and poi/ in every
+dimension) of a server for chunks with bad offsets, overlapping sectors,
+truncated data or undecodable compression, and report their coordinates.
+
+--repair delete removes the damaged chunks so the game regenerates them.
+--repair restore copies each damaged chunk from the latest backup instead;
+chunks without a healthy copy are left as they are. The server must be
+stopped, and every changed file is first copied to <file>.mineos-bak.`,
+		Example: `  mineos world check survival
+  mineos world check survival --world world_nether
+  mineos world check survival --repair restore
+  mineos world check --dir /srv/old-server --repair delete --yes`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			if repair != "" && repair != "delete" && repair != "restore" {
+				return fmt.Errorf("--repair must be delete or restore")
+			}
+			root, backupRoot, err := resolveServerDir(ctx, loadConfig, dir, args)
+			if err != nil {
+				return err
+			}
+			scanRoot := root
+			if world != "" {
+				scanRoot = filepath.Join(root, world)
+			}
+
+			scans, err := scanRegions(root, scanRoot)
+			if err != nil {
+				return err
+			}
+			damaged := printRegionScans(cmd.OutOrStdout(), scanRoot, scans)
+			if len(damaged) == 0 || repair == "" {
+				if len(damaged) > 0 {
+					cmd.Printf("\nRepair with --repair restore (from the latest backup) or --repair delete (regenerate).\n")
+				}
+				return nil
+			}
+
+			if len(args) == 1 {
+				if err := requireServerStopped(ctx, loadConfig, cmd, args[0]); err != nil {
+					return err
+				}
+			} else {
+				cmd.Println(styleWarning.Render("Make sure no server is using this directory before repairing."))
+			}
+			if repair == "restore" && backupRoot == "" {
+				return fmt.Errorf("--repair restore needs a server name to find its backup")
+			}
+			if err := requireConfirmToken(confirmConfig(ctx, loadConfig), confirmWorldRepair, confirmToken, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+			if !yes {
+				ok, err := promptYesNo(nil, cmd.OutOrStdout(), fmt.Sprintf("Repair %d region file(s) by %s?", len(damaged), map[string]string{"delete": "deleting damaged chunks", "restore": "restoring chunks from the latest backup"}[repair]), false)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("repair cancelled")
+				}
+			}
+
+			cmd.Println()
+			fixed, left := 0, 0
+			for _, scan := range damaged {
+				f, l, err := repairRegion(cmd, scan, repair, backupRoot)
+				if err != nil {
+					return fmt.Errorf("%s: %w", scan.Rel, err)
+				}
+				fixed += f
+				left += l
+			}
+			cmd.Printf("\n%s\n", styleSuccess.Render(fmt.Sprintf("Repaired %d problem(s).", fixed)))
+			if left > 0 {
+				cmd.Printf("%s %d problem(s) left as they are (marked - above).\n", styleWarning.Render("Note:"), left)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&world, "world", "", "Only scan this world directory (e.g. world_nether)")
+	cmd.Flags().StringVar(&dir, "dir", "", "Server directory to scan instead of a server by name")
+	cmd.Flags().StringVar(&repair, "repair", "", "Repair damaged chunks: delete or restore")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Repair without asking for confirmation")
+	addConfirmTokenFlag(cmd, &confirmToken)
+	_ = cmd.RegisterFlagCompletionFunc("repair", cobra.FixedCompletions([]string{"delete", "restore"}, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+// resolveServerDir returns the server directory to work on, either --dir or
+// the named server on the host, and the server's backup mirror.
+func resolveServerDir(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, dir string, args []string) (string, string, error) {
+	if dir != "" {
+		return dir, "", nil
+	}
+	if len(args) == 0 {
+		return "", "", fmt.Errorf("specify a server or --dir")
+	}
+	storage, err := loadHostStorage(ctx, loadConfig)
+	if err != nil {
+		return "", "", fmt.Errorf("%w (use --dir to point at a server directory)", err)
+	}
+	return storage.ServerDir(args[0]), storage.BackupDir(args[0]), nil
+}
+
+// scanRegions checks every region file below scanRoot. Paths are reported
+// relative to root so they can be found in the backup.
+func scanRegions(root, scanRoot string) ([]regionScan, error) {
+	var scans []regionScan
+	err := filepath.WalkDir(scanRoot, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		switch filepath.Base(filepath.Dir(path)) {
+		case "region", "entities", "poi":
+		default:
+			return nil
+		}
+		rx, rz, ok := anvil.RegionCoords(entry.Name())
+		if !ok {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		// Only the findings are kept: repairRegion reads the file again, so
+		// a large world is not held in memory.
+		region := anvil.Region{X: rx, Z: rz, Data: data}
+		scan := regionScan{Path: path, Rel: rel, X: rx, Z: rz}
+		if len(data) >= anvil.HeaderSize {
+			for i := 0; i < anvil.ChunksPerRegion; i++ {
+				if region.Present(i) {
+					scan.Chunks++
+				}
+			}
+		}
+		scan.Problems = anvil.Check(region, func(chunk anvil.Chunk) bool {
+			return fileExists(filepath.Join(filepath.Dir(path), anvil.ExternalFile(chunk)))
+		})
+		scans = append(scans, scan)
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s does not exist", scanRoot)
+	}
+	sort.Slice(scans, func(i, j int) bool { return scans[i].Rel < scans[j].Rel })
+	return scans, err
+}
+
+// printRegionScans reports the problems found and returns the damaged files.
+func printRegionScans(out io.Writer, root string, scans []regionScan) []regionScan {
+	fmt.Fprintf(out, "%s\n\n", styleTitle.Render("World check: "+root))
+	chunks, problems := 0, 0
+	var damaged []regionScan
+	for _, scan := range scans {
+		chunks += scan.Chunks
+		if len(scan.Problems) == 0 {
+			continue
+		}
+		damaged = append(damaged, scan)
+		problems += len(scan.Problems)
+		fmt.Fprintf(out, "  %s %s\n", markFailed(), scan.Rel)
+		for _, problem := range scan.Problems {
+			fmt.Fprintf(out, "      %s\n", problem)
+		}
+	}
+	if len(damaged) > 0 {
+		fmt.Fprintln(out)
+	}
+	printStat(out, "Regions", fmt.Sprintf("%d", len(scans)))
+	printStat(out, "Chunks", fmt.Sprintf("%d", chunks))
+	if problems == 0 {
+		printStat(out, "Problems", styleSuccess.Render("none"))
+	} else {
+		printStat(out, "Problems", styleError.Render(fmt.Sprintf("%d in %d file(s)", problems, len(damaged))))
+	}
+	return damaged
+}
+
+// repairRegion fixes one file and returns how many problems were repaired
+// and how many were left.
+func repairRegion(cmd *cobra.Command, scan regionScan, mode, backupRoot string) (int, int, error) {
+	data, err := os.ReadFile(scan.Path)
+	if err != nil {
+		return 0, 0, err
+	}
+	region := anvil.Region{X: scan.X, Z: scan.Z, Data: data}
+	backupPath := filepath.Join(backupRoot, scan.Rel)
+	var backup anvil.Region
+	backupBad := map[int]bool{}
+	haveBackup := false
+	if mode == "restore" {
+		data, err := os.ReadFile(backupPath)
+		if err == nil {
+			backup = anvil.Region{X: scan.X, Z: scan.Z, Data: data}
+			haveBackup = len(data) >= anvil.HeaderSize
+			backupChunkExists := func(chunk anvil.Chunk) bool {
+				return fileExists(filepath.Join(filepath.Dir(backupPath), anvil.ExternalFile(chunk)))
+			}
+			for _, problem := range anvil.Check(backup, backupChunkExists) {
+				if problem.Chunk == nil {
+					haveBackup = false
+					break
+				}
+				backupBad[problem.Chunk.Index] = true
+			}
+		}
+	}
+
+	fixed, left := 0, 0
+	for _, problem := range scan.Problems {
+		if problem.Chunk == nil {
+			// A damaged header can only be replaced as a whole.
+			if len(region.Data) < anvil.HeaderSize && haveBackup {
+				region.Data = append([]byte(nil), backup.Data...)
+				fixed++
+				cmd.Printf("  %s %s restored from backup\n", markOK(), scan.Rel)
+				continue
+			}
+			// Defects of the whole file with an intact header, such as a
+			// truncated last sector, are beyond a chunk repair.
+			left++
+			reason := "header is damaged; restore the file from a backup"
+			if len(region.Data) >= anvil.HeaderSize {
+				reason = problem.Reason + "; restore the file from a backup"
+			}
+			cmd.Printf("  %s %s %s\n", styleWarning.Render("-"), scan.Rel, styleDim.Render(reason))
+			continue
+		}
+		chunk := *problem.Chunk
+		label := fmt.Sprintf("%s chunk %d,%d", scan.Rel, chunk.X, chunk.Z)
+		if mode == "delete" {
+			region.DeleteChunk(chunk.Index)
+			fixed++
+			cmd.Printf("  %s %s deleted\n", markOK(), label)
+			continue
+		}
+		if !haveBackup || backupBad[chunk.Index] || !backup.Present(chunk.Index) {
+			left++
+			cmd.Printf("  %s %s %s\n", styleWarning.Render("-"), label, styleDim.Render("no healthy copy in the backup"))
+			continue
+		}
+		if backup.External(chunk.Index) {
+			if err := restoreExternalChunk(scan.Path, backupPath, chunk); err != nil {
+				left++
+				cmd.Printf("  %s %s %s\n", styleWarning.Render("-"), label, styleDim.Render(err.Error()))
+				continue
+			}
+		}
+		if err := region.CopyChunk(chunk.Index, backup); err != nil {
+			left++
+			cmd.Printf("  %s %s %s\n", styleWarning.Render("-"), label, styleDim.Render(err.Error()))
+			continue
+		}
+		fixed++
+		cmd.Printf("  %s %s restored from backup (saved %s)\n", markOK(), label, backup.Timestamp(chunk.Index).Local().Format("2006-01-02 15:04"))
+	}
+	if fixed == 0 {
+		return 0, left, nil
+	}
+
+	if err := replaceWithBackup(scan.Path, region.Data); err != nil {
+		return 0, 0, err
+	}
+	return fixed, left, nil
+}
+
+// restoreExternalChunk copies the .mcc file of an external chunk from the
+// backup, keeping the current one as <file>.mineos-bak.
+func restoreExternalChunk(regionPath, backupPath string, chunk anvil.Chunk) error {
+	name := anvil.ExternalFile(chunk)
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(backupPath), name))
+	if err != nil {
+		return fmt.Errorf("the backup has no %s", name)
+	}
+	dest := filepath.Join(filepath.Dir(regionPath), name)
+	if fileExists(dest) {
+		return replaceWithBackup(dest, data)
+	}
+	return os.WriteFile(dest, data, 0o644)
+}
+
+// requireServerStopped refuses to touch world files while the game has them
+// open.
+func requireServerStopped(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, cmd *cobra.Command, name string) error {
+	_, err := withApiKeyRetry(ctx, loadConfig, cmd.OutOrStdout(), func(_ config.Config, client *api.Client) error {
+		detail, err := client.GetServer(ctx, name)
+		if err != nil {
+			return err
+		}
+		if detail.IsRunning() {
+			return ports.WithKind(fmt.Errorf("%s is running; stop it first with 'mineos servers stop %s'", name, name), ports.ErrConflict)
+		}
+		return nil
+	})
+	return err
+}
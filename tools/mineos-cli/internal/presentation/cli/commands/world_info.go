@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/level"
+)
+
+// dimensionSize is the on-disk footprint of one dimension.
+type dimensionSize struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Regions int    `json:"regions"`
+	Bytes   int64  `json:"bytes"`
+}
+
+type worldInfoReport struct {
+	World string `json:"world"`
+	level.Info
+	Dimensions []dimensionSize `json:"dimensions"`
+}
+
+func newWorldInfoCommand(loadConfig *usecases.LoadConfigUseCase) *cobra.Command {
+	var world string
+	var dir string
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "info [server]",
+		Short: "Show seed, world type, spawn, dimension sizes and game rules",
+		Long: `Read level.dat of a server's world (level-name in server.properties, or
+--world) and show its seed, world type, spawn point, game settings, the
+size of each dimension on disk and the game rules.`,
+		Example: `  mineos world info survival
+  mineos world info survival --json
+  mineos world info --dir /srv/old-server --world creative`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			root, _, err := resolveServerDir(ctx, loadConfig, dir, args)
+			if err != nil {
+				return err
+			}
+			if world == "" {
+				world = levelName(root)
+			}
+
+			data, err := os.ReadFile(filepath.Join(root, world, level.File))
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return fmt.Errorf("%s has no %s; has the server been started yet?", filepath.Join(root, world), level.File)
+				}
+				return err
+			}
+			info, err := level.Parse(data)
+			if err != nil {
+				return fmt.Errorf("read %s: %w", level.File, err)
+			}
+			report := worldInfoReport{World: world, Info: info, Dimensions: worldDimensions(root, world)}
+
+			if jsonOut {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			}
+			printWorldInfo(cmd.OutOrStdout(), report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&world, "world", "", "World directory (default: level-name from server.properties)")
+	cmd.Flags().StringVar(&dir, "dir", "", "Server directory to read instead of a server by name")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the report as JSON")
+
+	return cmd
+}
+
+// levelName reads level-name from server.properties, defaulting to "world".
+func levelName(serverDir string) string {
+	file, err := os.Open(filepath.Join(serverDir, "server.properties"))
+	if err != nil {
+		return "world"
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && strings.TrimSpace(key) == "level-name" && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return "world"
+}
+
+// worldDimensions measures the vanilla dimensions, whether stored inside the
+// world (vanilla, Fabric, Forge) or in world_nether/world_the_end (Bukkit),
+// plus datapack dimensions.
+func worldDimensions(serverDir, world string) []dimensionSize {
+	base := filepath.Join(serverDir, world)
+	candidates := []dimensionSize{
+		{Name: "overworld", Path: world},
+		{Name: "nether", Path: firstExisting(serverDir, filepath.Join(world, "DIM-1"), filepath.Join(world+"_nether", "DIM-1"))},
+		{Name: "the end", Path: firstExisting(serverDir, filepath.Join(world, "DIM1"), filepath.Join(world+"_the_end", "DIM1"))},
+	}
+	namespaces, _ := os.ReadDir(filepath.Join(base, "dimensions"))
+	for _, namespace := range namespaces {
+		if !namespace.IsDir() {
+			continue
+		}
+		entries, _ := os.ReadDir(filepath.Join(base, "dimensions", namespace.Name()))
+		for _, entry := range entries {
+			if entry.IsDir() {
+				candidates = append(candidates, dimensionSize{
+					Name: namespace.Name() + ":" + entry.Name(),
+					Path: filepath.Join(world, "dimensions", namespace.Name(), entry.Name()),
+				})
+			}
+		}
+	}
+
+	var dimensions []dimensionSize
+	for _, dimension := range candidates {
+		if dimension.Path == "" {
+			continue
+		}
+		for _, sub := range []string{"region", "entities", "poi"} {
+			entries, err := os.ReadDir(filepath.Join(serverDir, dimension.Path, sub))
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				info, err := entry.Info()
+				if err != nil || entry.IsDir() {
+					continue
+				}
+				dimension.Bytes += info.Size()
+				if sub == "region" && strings.HasSuffix(entry.Name(), ".mca") {
+					dimension.Regions++
+				}
+			}
+		}
+		dimensions = append(dimensions, dimension)
+	}
+	return dimensions
+}
+
+func firstExisting(root string, paths ...string) string {
+	for _, path := range paths {
+		if info, err := os.Stat(filepath.Join(root, path)); err == nil && info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+func printWorldInfo(out io.Writer, report worldInfoReport) {
+	info := report.Info
+	fmt.Fprintf(out, "%s\n\n", styleTitle.Render("World: "+report.World))
+	printStat(out, "Level name", fallback(info.LevelName, "-"))
+	version := fallback(info.Version, "unknown")
+	if info.DataVersion > 0 {
+		version += styleDim.Render(fmt.Sprintf(" (data version %d)", info.DataVersion))
+	}
+	printStat(out, "Version", version)
+	printStat(out, "Seed", fmt.Sprintf("%d", info.Seed))
+	printStat(out, "World type", info.WorldType)
+	if info.Structures != nil {
+		printStat(out, "Structures", map[bool]string{true: "yes", false: "no"}[*info.Structures])
+	}
+	printStat(out, "Spawn", info.Spawn.String())
+	mode := info.GameMode
+	if info.Hardcore {
+		mode += styleWarning.Render(" (hardcore)")
+	}
+	printStat(out, "Game mode", mode)
+	printStat(out, "Difficulty", info.Difficulty)
+	printStat(out, "Day", fmt.Sprintf("%d", info.Day))
+	if !info.LastPlayed.IsZero() {
+		printStat(out, "Last played", info.LastPlayed.Local().Format("2006-01-02 15:04"))
+	}
+
+	fmt.Fprintf(out, "\n%s\n", styleTitle.Render("Dimensions"))
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, dimension := range report.Dimensions {
+		fmt.Fprintf(w, "  %s\t%d region(s)\t%s\t%s\n", dimension.Name, dimension.Regions, formatBytes(dimension.Bytes), styleDim.Render(dimension.Path))
+	}
+	w.Flush()
+
+	if len(info.GameRules) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\n%s\n", styleTitle.Render("Game rules"))
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, name := range info.RuleNames() {
+		fmt.Fprintf(w, "  %s\t%s\n", name, info.GameRules[name])
+	}
+	w.Flush()
+}
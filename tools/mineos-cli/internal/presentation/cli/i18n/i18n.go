@@ -0,0 +1,344 @@
+// Package i18n translates the CLI and TUI prompts. Messages are looked up by
+// key in JSON catalogs, one per locale: the English one, en.json, lists
+// every key, and the others translate as many as they cover; a missing key
+// falls back to English. Messages are fmt formats, so a translation can
+// reorder its arguments with %[2]s.
+//
+// Catalogs ship embedded under locales/, and more can be dropped into the
+// locales directory (MINEOS_LOCALES_DIR, or mineos/locales in the user
+// config directory) to try a translation without rebuilding the CLI.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Source is the locale every key is written in.
+const Source = "en"
+
+const (
+	// LangEnv overrides the locale taken from LC_ALL, LC_MESSAGES and LANG.
+	LangEnv = "MINEOS_LANG"
+	// DirEnv points at a directory of extra or overriding catalogs.
+	DirEnv = "MINEOS_LOCALES_DIR"
+)
+
+//go:embed locales/*.json
+var embedded embed.FS
+
+var (
+	mu      sync.RWMutex
+	current = Source
+	// chain is the catalogs of the current locale, most specific first;
+	// the source catalog is always last.
+	chain []map[string]string
+)
+
+// Names are the native names of the shipped locales.
+var Names = map[string]string{
+	"en":    "English",
+	"es":    "Español",
+	"de":    "Deutsch",
+	"pt-BR": "Português (Brasil)",
+	"zh-CN": "简体中文",
+}
+
+// T returns the message for key in the current locale, formatted with args.
+// An unknown key is returned as is, so a typo shows up instead of an empty
+// line.
+func T(key string, args ...any) string {
+	message := key
+	for _, catalog := range catalogs() {
+		if text, ok := catalog[key]; ok && text != "" {
+			message = text
+			break
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Current returns the locale T translates into.
+func Current() string {
+	catalogs()
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// catalogs returns the chain of the current locale. Until something sets
+// the locale, it is the environment's.
+func catalogs() []map[string]string {
+	mu.RLock()
+	loaded := chain
+	mu.RUnlock()
+	if loaded == nil {
+		_, _ = Set("")
+		mu.RLock()
+		loaded = chain
+		mu.RUnlock()
+	}
+	return loaded
+}
+
+// Set switches to the closest available locale to tag, returning it. An
+// empty tag picks the locale of the environment (see Detect). A tag with no
+// catalog at all selects English and is reported as an error.
+func Set(tag string) (string, error) {
+	requested := tag
+	if strings.TrimSpace(tag) == "" {
+		requested = Detect(os.Getenv)
+	}
+	chosen := Match(Normalize(requested), Available())
+	loaded := []map[string]string{}
+	if chosen != Source {
+		if language, _, found := strings.Cut(chosen, "-"); found && contains(Available(), language) {
+			loaded = append(loaded, load(chosen), load(language))
+		} else {
+			loaded = append(loaded, load(chosen))
+		}
+	}
+	loaded = append(loaded, load(Source))
+
+	mu.Lock()
+	current, chain = chosen, loaded
+	mu.Unlock()
+	if tag != "" && chosen == Source && Normalize(tag) != Source {
+		return chosen, fmt.Errorf("no translation for %q; available: %s", tag, strings.Join(Available(), ", "))
+	}
+	return chosen, nil
+}
+
+// Detect reads the locale of the environment: MINEOS_LANG, then the POSIX
+// LC_ALL, LC_MESSAGES and LANG.
+func Detect(getenv func(string) string) string {
+	for _, name := range []string{LangEnv, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := strings.TrimSpace(getenv(name)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Normalize turns a POSIX locale ("pt_BR.UTF-8", "de_DE@euro") or a BCP 47
+// tag in any case into the form catalogs are named by ("pt-BR", "de-DE").
+// C and POSIX are English.
+func Normalize(raw string) string {
+	tag := strings.TrimSpace(raw)
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	tag = strings.ReplaceAll(tag, "_", "-")
+	if tag == "" || strings.EqualFold(tag, "C") || strings.EqualFold(tag, "POSIX") {
+		return Source
+	}
+	parts := strings.Split(tag, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			parts[i] = strings.ToUpper(parts[i])
+		case 4:
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// Match picks the available locale for tag: the exact one, then the bare
+// language, then another region of the language. Chinese is only matched
+// exactly or by script, since zh-TW readers want Traditional characters.
+func Match(tag string, available []string) string {
+	for _, locale := range available {
+		if strings.EqualFold(locale, tag) {
+			return locale
+		}
+	}
+	language, rest, _ := strings.Cut(tag, "-")
+	if language == "zh" {
+		simplified := rest == "" || rest == "CN" || rest == "SG" || strings.HasPrefix(rest, "Hans")
+		if simplified && contains(available, "zh-CN") {
+			return "zh-CN"
+		}
+		return Source
+	}
+	if contains(available, language) {
+		return language
+	}
+	for _, locale := range available {
+		if strings.HasPrefix(locale, language+"-") {
+			return locale
+		}
+	}
+	return Source
+}
+
+// Available lists the locales with a catalog, embedded or in the locales
+// directory, sorted with English first.
+func Available() []string {
+	seen := map[string]bool{}
+	entries, _ := embedded.ReadDir("locales")
+	for _, entry := range entries {
+		seen[strings.TrimSuffix(entry.Name(), ".json")] = true
+	}
+	if dir := Dir(); dir != "" {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+		for _, file := range files {
+			seen[strings.TrimSuffix(filepath.Base(file), ".json")] = true
+		}
+	}
+	locales := make([]string, 0, len(seen))
+	for locale := range seen {
+		if locale != Source {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	return append([]string{Source}, locales...)
+}
+
+// Dir is the directory extra catalogs are read from.
+func Dir() string {
+	if dir := strings.TrimSpace(os.Getenv(DirEnv)); dir != "" {
+		return dir
+	}
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(config, "mineos", "locales")
+}
+
+// Catalog returns the messages of a locale, the embedded ones with those
+// of the locales directory over them.
+func Catalog(locale string) map[string]string {
+	return load(locale)
+}
+
+func load(locale string) map[string]string {
+	catalog, _ := read(locale)
+	return catalog
+}
+
+// read merges the embedded catalog of locale with the one in the locales
+// directory, reporting a file that does not parse.
+func read(locale string) (map[string]string, error) {
+	catalog := map[string]string{}
+	if data, err := embedded.ReadFile("locales/" + locale + ".json"); err == nil {
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return catalog, fmt.Errorf("embedded %s.json: %w", locale, err)
+		}
+	}
+	if dir := Dir(); dir != "" {
+		path := filepath.Join(dir, locale+".json")
+		if data, err := os.ReadFile(path); err == nil {
+			var overrides map[string]string
+			if err := json.Unmarshal(data, &overrides); err != nil {
+				return catalog, fmt.Errorf("%s: %w", path, err)
+			}
+			for key, text := range overrides {
+				catalog[key] = text
+			}
+		}
+	}
+	return catalog, nil
+}
+
+// verb matches the fmt verbs of a message, with any explicit argument index.
+var verb = regexp.MustCompile(`%(?:\[\d+\])?[-+# 0]*\d*(?:\.\d+)?[a-zA-Z%]`)
+
+// Check lists what is wrong with a locale's catalog: a file that does not
+// parse, keys English does not have, and messages that take a different
+// number of arguments than the English ones.
+func Check(locale string) []string {
+	var problems []string
+	catalog, err := read(locale)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	source := load(Source)
+	keys := make([]string, 0, len(catalog))
+	for key := range catalog {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		english, known := source[key]
+		if !known {
+			problems = append(problems, fmt.Sprintf("%s: not an English key", key))
+			continue
+		}
+		if want, got := arguments(english), arguments(catalog[key]); catalog[key] != "" && want != got {
+			problems = append(problems, fmt.Sprintf("%s: takes %d argument(s), English takes %d", key, got, want))
+		}
+	}
+	return problems
+}
+
+// arguments counts the arguments a message consumes.
+func arguments(message string) int {
+	count, next := 0, 0
+	for _, match := range verb.FindAllString(message, -1) {
+		if strings.HasSuffix(match, "%") {
+			continue
+		}
+		if strings.HasPrefix(match, "%[") {
+			var index int
+			fmt.Sscanf(match, "%%[%d]", &index)
+			next = index
+		} else {
+			next++
+		}
+		count = max(count, next)
+	}
+	return count
+}
+
+// Coverage is the share of the source keys a locale translates.
+func Coverage(locale string) (translated, total int) {
+	source := load(Source)
+	catalog := load(locale)
+	for key := range source {
+		if catalog[key] != "" {
+			translated++
+		}
+	}
+	return translated, len(source)
+}
+
+// Answers are the words accepted as yes and no in the current locale, on
+// top of the English ones.
+func Answers() (yes, no []string) {
+	yes = []string{"y", "yes"}
+	no = []string{"n", "no"}
+	for _, word := range strings.Split(T("prompt.answers.yes"), "|") {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			yes = append(yes, word)
+		}
+	}
+	for _, word := range strings.Split(T("prompt.answers.no"), "|") {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			no = append(no, word)
+		}
+	}
+	return yes, no
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
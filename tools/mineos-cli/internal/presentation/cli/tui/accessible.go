@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rule is the line under a section title. Accessible mode leaves it blank,
+// since a screen reader reads box drawing out character by character.
+func (m TuiModel) rule(width int) string {
+	if m.Accessible {
+		return ""
+	}
+	return StyleSubtle.Render(strings.Repeat("─", width))
+}
+
+// pointer marks the selected line of a list.
+func (m TuiModel) pointer() string {
+	if m.Accessible {
+		return "> "
+	}
+	return "▶ "
+}
+
+// glyph is symbol, or its word in accessible mode, for markers that
+// carry meaning on their own.
+func (m TuiModel) glyph(symbol, word string) string {
+	if m.Accessible {
+		return word
+	}
+	return symbol
+}
+
+// renderConfirmText is the confirm dialog as lines of text, without the
+// box around it.
+func (m TuiModel) renderConfirmText(height int) []string {
+	lines := []string{StyleHeader.Render(" CONFIRM ACTION"), ""}
+	if m.ConfirmAction != nil {
+		lines = append(lines, StyleSelected.Render("  "+m.ConfirmAction.Label))
+	}
+	if m.ConfirmMessage != "" {
+		lines = append(lines, "  "+m.ConfirmMessage)
+	}
+	lines = append(lines, "")
+	instructions := "[Enter] Confirm  [Esc] Cancel"
+	if m.ConfirmName != "" {
+		lines = append(lines, "  Type "+m.ConfirmName+" to confirm: "+m.Input.Value())
+		if strings.TrimSpace(m.Input.Value()) != m.ConfirmName {
+			instructions = "[Esc] Cancel"
+		}
+	}
+	lines = append(lines, StyleSubtle.Render("  "+instructions))
+	return PadLines(lines, height)
+}
+
+// linearView is the layout of accessible mode: the header, the current
+// view and then the selected menu item, one column the width of the
+// terminal, so a screen reader goes through it top to bottom without the
+// sidebar's lines interleaved with the view's.
+func (m TuiModel) linearView(header string, render func(width, height int) []string) string {
+	var b strings.Builder
+	b.WriteString(header)
+	b.WriteString("\n")
+
+	footer := m.RenderFooter()
+	height := max(m.Height-strings.Count(header, "\n")-strings.Count(footer, "\n")-3, MinContentHeight)
+	for _, line := range render(m.Width, height) {
+		b.WriteString(TrimToWidth(strings.TrimRight(line, " "), m.Width))
+		b.WriteString("\n")
+	}
+	b.WriteString(m.menuPosition())
+	b.WriteString("\n")
+	b.WriteString(footer)
+	return b.String()
+}
+
+// menuPosition names the selected menu item and where it is in the menu,
+// which the sidebar shows by layout alone.
+func (m TuiModel) menuPosition() string {
+	selectable, position := 0, 0
+	for i, item := range m.NavItems {
+		if !item.IsSelectable() {
+			continue
+		}
+		selectable++
+		if i == m.NavIndex {
+			position = selectable
+		}
+	}
+	if position == 0 {
+		return ""
+	}
+	item := m.NavItems[m.NavIndex]
+	label := item.Label
+	if item.Destructive {
+		label += " (destructive)"
+	}
+	if item.Action != nil && m.RunningOp != nil && m.operationBlocked(*item.Action) != "" {
+		label += " (unavailable)"
+	}
+	if section := m.navSection(m.NavIndex); section != "" {
+		label = section + ": " + label
+	}
+	return StyleSelected.Render(" Menu: "+label) + StyleSubtle.Render(fmt.Sprintf(" (%d of %d)", position, selectable))
+}
+
+// navSection is the header of the menu section item i is in.
+func (m TuiModel) navSection(i int) string {
+	for ; i >= 0; i-- {
+		if m.NavItems[i].ItemType == NavHeader {
+			return m.NavItems[i].Label
+		}
+	}
+	return ""
+}
@@ -2,18 +2,26 @@
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
 )
 
+// errReadOnly is returned by every mutating command in read-only mode.
+var errReadOnly = errors.New("read-only mode: actions are disabled")
+
 func (m TuiModel) ServerActionCmd(action string) tea.Cmd {
+	if m.ReadOnly {
+		return func() tea.Msg { return ActionResultMsg{Err: errReadOnly} }
+	}
 	server := m.SelectedServer()
 	if server == "" || !m.ConfigReady {
 		return func() tea.Msg { return ActionResultMsg{Err: errors.New("select a server first")} }
@@ -31,6 +39,9 @@ func (m TuiModel) ServerActionCmd(action string) tea.Cmd {
 }
 
 func (m TuiModel) StopAllCmd(timeout int) tea.Cmd {
+	if m.ReadOnly {
+		return func() tea.Msg { return ActionResultMsg{Err: errReadOnly} }
+	}
 	if !m.ConfigReady {
 		return func() tea.Msg { return ActionResultMsg{Err: errors.New("API client not ready")} }
 	}
@@ -47,14 +58,21 @@ func (m TuiModel) StopAllCmd(timeout int) tea.Cmd {
 }
 
 func (m TuiModel) ConsoleCommandCmd(command string) tea.Cmd {
+	if m.ReadOnly {
+		return func() tea.Msg { return ActionResultMsg{Err: errReadOnly} }
+	}
 	server := m.SelectedServer()
 	if server == "" || !m.ConfigReady {
 		return func() tea.Msg { return ActionResultMsg{Err: errors.New("select a server first")} }
 	}
 	ctx := m.Ctx
 	client := m.Client
+	history := m.consoleHistory()
 	return func() tea.Msg {
 		err := client.SendConsoleCommand(ctx, server, command)
+		if err == nil {
+			_ = history.Add(server, command)
+		}
 		return ActionResultMsg{
 			Message: fmt.Sprintf("sent to %s: %s", server, command),
 			Err:     err,
@@ -63,6 +81,9 @@ func (m TuiModel) ConsoleCommandCmd(command string) tea.Cmd {
 }
 
 func (m TuiModel) ComposeActionCmd(args ...string) tea.Cmd {
+	if m.ReadOnly {
+		return func() tea.Msg { return ActionResultMsg{Err: errReadOnly} }
+	}
 	if !m.ComposeReady {
 		return func() tea.Msg { return ActionResultMsg{Err: errors.New("docker compose not available")} }
 	}
@@ -73,13 +94,21 @@ func (m TuiModel) ComposeActionCmd(args ...string) tea.Cmd {
 	}
 }
 
-func (m TuiModel) ExecMenuItem(item MenuItem) tea.Cmd {
+// ExecMenuItem runs a menu action as a CLI subprocess. Cancelling ctx
+// interrupts a streaming or captured command; interactive commands own the
+// terminal and take Ctrl+C themselves.
+func (m TuiModel) ExecMenuItem(ctx context.Context, item MenuItem) tea.Cmd {
+	if m.ReadOnly {
+		return func() tea.Msg { return ExecFinishedMsg{Action: item.Label, Err: errReadOnly} }
+	}
 	exe, err := os.Executable()
 	if err != nil {
 		return func() tea.Msg { return ExecFinishedMsg{Action: item.Label, Err: err} }
 	}
 	args := append([]string{}, item.Args...)
-	if envPath := strings.TrimSpace(m.Cfg.EnvPath); envPath != "" && envPath != ".env" {
+	if m.Remote != nil {
+		args = append([]string{"--ssh", m.Remote.String(), "--ssh-dir", m.Remote.Dir}, args...)
+	} else if envPath := strings.TrimSpace(m.Cfg.EnvPath); envPath != "" && envPath != ".env" {
 		args = append([]string{"--env", envPath}, args...)
 	}
 
@@ -94,13 +123,14 @@ func (m TuiModel) ExecMenuItem(item MenuItem) tea.Cmd {
 
 	// Streaming commands show output in real-time (for long-running docker operations)
 	if item.Streaming {
-		return m.StartStreamingCmd(exe, args, item.Label)
+		return m.StartStreamingCmd(ctx, exe, args, item.Label)
 	}
 
 	// Non-interactive commands capture output for display in TUI
 	return func() tea.Msg {
-		cmd := exec.Command(exe, args...)
+		cmd, done := cancellableCommand(ctx, exe, args)
 		output, err := cmd.CombinedOutput()
+		close(done)
 
 		// Parse output into lines
 		outputStr := strings.TrimSpace(string(output))
@@ -113,10 +143,31 @@ func (m TuiModel) ExecMenuItem(item MenuItem) tea.Cmd {
 	}
 }
 
+// cancellableCommand builds a command that is interrupted like Ctrl+C when
+// ctx is cancelled, and killed with its children if it has not exited
+// StreamingCancelGrace later. Close done once the command has been waited for.
+func cancellableCommand(ctx context.Context, exe string, args []string) (*exec.Cmd, chan struct{}) {
+	done := make(chan struct{})
+	cmd := exec.CommandContext(ctx, exe, args...)
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		err := interruptProcessGroup(cmd.Process)
+		go func() {
+			select {
+			case <-done:
+			case <-time.After(StreamingCancelGrace):
+				_ = killProcessGroup(cmd.Process)
+			}
+		}()
+		return err
+	}
+	return cmd, done
+}
+
 // StartStreamingCmd starts a command that streams output without requiring stdin
-func (m TuiModel) StartStreamingCmd(exe string, args []string, label string) tea.Cmd {
+func (m TuiModel) StartStreamingCmd(ctx context.Context, exe string, args []string, label string) tea.Cmd {
 	return func() tea.Msg {
-		cmd := exec.Command(exe, args...)
+		cmd, done := cancellableCommand(ctx, exe, args)
 
 		// Use combined output (stdout + stderr together)
 		stdoutPipe, err := cmd.StdoutPipe()
@@ -131,6 +182,7 @@ func (m TuiModel) StartStreamingCmd(exe string, args []string, label string) tea
 		cmd.Stderr = cmd.Stdout
 
 		if err := cmd.Start(); err != nil {
+			close(done)
 			stdoutPipe.Close()
 			return StreamingStartedMsg{
 				Output: makeErrorChan("Failed to start: " + err.Error()),
@@ -144,6 +196,10 @@ func (m TuiModel) StartStreamingCmd(exe string, args []string, label string) tea
 		// Single goroutine to read and manage the stream
 		go func() {
 			defer close(outputChan)
+			started := time.Now()
+			// Last status of each service, reported when cancelled
+			var services []string
+			reached := map[string]string{}
 
 			// Send initial status
 			cmdStr := fmt.Sprintf("%s %s", filepath.Base(exe), strings.Join(args, " "))
@@ -159,6 +215,12 @@ func (m TuiModel) StartStreamingCmd(exe string, args []string, label string) tea
 				line := scanner.Text()
 				outputChan <- line
 				lineCount++
+				if match := serviceStatusPattern.FindStringSubmatch(line); match != nil {
+					if _, seen := reached[match[1]]; !seen {
+						services = append(services, match[1])
+					}
+					reached[match[1]] = strings.TrimSpace(line[len(match[0]):])
+				}
 			}
 
 			if err := scanner.Err(); err != nil {
@@ -168,11 +230,17 @@ func (m TuiModel) StartStreamingCmd(exe string, args []string, label string) tea
 
 			// Wait for process to finish
 			waitErr := cmd.Wait()
+			close(done)
 			outputChan <- ""
-			if waitErr != nil {
-				outputChan <- fmt.Sprintf("✗ Command failed (%d lines): %s", lineCount, waitErr.Error())
+			if ctx.Err() != nil {
+				outputChan <- m.glyph("✗ ", "") + fmt.Sprintf("Cancelled after %s (%d lines)", time.Since(started).Round(time.Second), lineCount)
+				for _, service := range services {
+					outputChan <- fmt.Sprintf("  %s: %s", service, reached[service])
+				}
+			} else if waitErr != nil {
+				outputChan <- m.glyph("✗ ", "FAILED: ") + fmt.Sprintf("Command failed (%d lines): %s", lineCount, waitErr.Error())
 			} else {
-				outputChan <- fmt.Sprintf("✓ Command completed (%d lines)", lineCount)
+				outputChan <- m.glyph("✓ ", "OK: ") + fmt.Sprintf("Command completed (%d lines)", lineCount)
 			}
 		}()
 
@@ -309,6 +377,9 @@ func (m TuiModel) SendInteractiveInput(input string) tea.Cmd {
 
 // ToggleEnvSettingCmd toggles a boolean env var between "true" and "false" in the .env file
 func (m TuiModel) ToggleEnvSettingCmd(envKey, currentValue string) tea.Cmd {
+	if m.ReadOnly {
+		return func() tea.Msg { return SettingsToggledMsg{Key: envKey, Err: errReadOnly} }
+	}
 	envPath := m.Cfg.EnvPath
 	newVal := "true"
 	if currentValue == "true" {
@@ -1,21 +1,30 @@
 package tui
 
 import (
+	"context"
 	"errors"
 	"os"
 	"os/exec"
 	"sync"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/ssh"
 )
 
 // ComposeRunner wraps docker compose execution with thread safety
 type ComposeRunner struct {
 	Exe      string
 	BaseArgs []string
-	mu       sync.Mutex // Protects concurrent compose command execution
+	// Remote runs compose on another host over ssh (--ssh).
+	Remote *ssh.Remote
+	mu     sync.Mutex // Protects concurrent compose command execution
 }
 
-// DetectCompose detects the available docker compose command
-func DetectCompose() (*ComposeRunner, error) {
+// DetectCompose detects the available docker compose command. With a
+// remote, compose runs in the remote install directory.
+func DetectCompose(remote *ssh.Remote) (*ComposeRunner, error) {
+	if remote != nil {
+		return &ComposeRunner{Exe: "docker", BaseArgs: []string{"compose"}, Remote: remote}, nil
+	}
 	if _, err := exec.LookPath("docker"); err == nil {
 		cmd := exec.Command("docker", "compose", "version")
 		if err := cmd.Run(); err == nil {
@@ -30,12 +39,29 @@ func DetectCompose() (*ComposeRunner, error) {
 	return nil, errors.New("docker compose is not available")
 }
 
+// Command builds a compose command without running it.
+func (c *ComposeRunner) Command(ctx context.Context, args []string) *exec.Cmd {
+	argv := append(append([]string{}, c.BaseArgs...), args...)
+	if c.Remote != nil {
+		return c.Remote.Command(ctx, false, nil, append([]string{c.Exe}, argv...)...)
+	}
+	return exec.CommandContext(ctx, c.Exe, argv...)
+}
+
+// dockerCommand builds a plain docker command on the same host as compose.
+func (c *ComposeRunner) dockerCommand(ctx context.Context, args []string) *exec.Cmd {
+	if c.Remote != nil {
+		return c.Remote.Command(ctx, false, nil, args...)
+	}
+	return exec.CommandContext(ctx, args[0], args[1:]...)
+}
+
 // Run executes a compose command with mutex protection
 func (c *ComposeRunner) Run(args []string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	cmd := exec.Command(c.Exe, append(c.BaseArgs, args...)...)
+	cmd := c.Command(context.Background(), args)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
@@ -47,11 +73,16 @@ func (c *ComposeRunner) RunWithEnv(args []string, env []string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	cmd := exec.Command(c.Exe, append(c.BaseArgs, args...)...)
+	var cmd *exec.Cmd
+	if c.Remote != nil {
+		cmd = c.Remote.Command(context.Background(), false, env, append([]string{c.Exe}, append(c.BaseArgs, args...)...)...)
+	} else {
+		cmd = c.Command(context.Background(), args)
+		cmd.Env = append(os.Environ(), env...)
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
-	cmd.Env = append(os.Environ(), env...)
 	return cmd.Run()
 }
 
@@ -60,6 +91,6 @@ func (c *ComposeRunner) RunSilent(args []string) ([]byte, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	cmd := exec.Command(c.Exe, append(c.BaseArgs, args...)...)
+	cmd := c.Command(context.Background(), args)
 	return cmd.Output()
 }
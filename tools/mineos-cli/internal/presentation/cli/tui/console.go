@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/console"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/consolehistory"
+)
+
+// ConsolePlayersMsg carries the players online on Server, for completing
+// console command arguments.
+type ConsolePlayersMsg struct {
+	Server  string
+	Players []string
+}
+
+func (m TuiModel) consoleHistory() *consolehistory.FileRepository {
+	return consolehistory.NewFileRepositoryForEnv(m.Cfg.EnvPath)
+}
+
+// EnterConsoleMode opens the console prompt for server with its saved history
+// and starts looking up who is online.
+func (m *TuiModel) EnterConsoleMode(server string) tea.Cmd {
+	m.Mode = ModeCommand
+	m.ConsoleServer = server
+	m.ConsoleHistory, _ = m.consoleHistory().Load(server)
+	m.ConsoleHistoryIndex = len(m.ConsoleHistory)
+	m.ConsoleDraft = ""
+	if m.ConsolePlayersServer != server {
+		m.ConsolePlayers = nil
+	}
+
+	m.Input.SetValue("")
+	m.Input.ShowSuggestions = true
+	// Up and Down recall history; Ctrl+N/Ctrl+P cycle the suggestions.
+	m.Input.KeyMap.NextSuggestion = key.NewBinding(key.WithKeys("ctrl+n"))
+	m.Input.KeyMap.PrevSuggestion = key.NewBinding(key.WithKeys("ctrl+p"))
+	m.refreshConsoleSuggestions()
+	m.Input.Focus()
+	return tea.Batch(textinput.Blink, m.LoadConsolePlayersCmd(server))
+}
+
+func (m *TuiModel) leaveConsoleMode() {
+	m.Mode = ModeNormal
+	m.Input.Blur()
+	m.Input.ShowSuggestions = false
+	m.Input.SetSuggestions(nil)
+}
+
+// recallConsoleHistory moves through the history, Up being -1. Moving past the
+// newest entry brings back what was being typed.
+func (m *TuiModel) recallConsoleHistory(delta int) {
+	index := m.ConsoleHistoryIndex + delta
+	if index < 0 || index > len(m.ConsoleHistory) {
+		return
+	}
+	if m.ConsoleHistoryIndex == len(m.ConsoleHistory) {
+		m.ConsoleDraft = m.Input.Value()
+	}
+	m.ConsoleHistoryIndex = index
+	if index == len(m.ConsoleHistory) {
+		m.Input.SetValue(m.ConsoleDraft)
+	} else {
+		m.Input.SetValue(m.ConsoleHistory[index])
+	}
+	m.Input.CursorEnd()
+	m.refreshConsoleSuggestions()
+}
+
+func (m *TuiModel) refreshConsoleSuggestions() {
+	m.Input.SetSuggestions(console.Complete(m.Input.Value(), m.ConsolePlayers))
+}
+
+// ConsoleUsage is the syntax of the command being typed, shown under the
+// prompt.
+func (m TuiModel) ConsoleUsage() string {
+	return console.Usage(m.Input.Value())
+}
+
+func (m TuiModel) LoadConsolePlayersCmd(server string) tea.Cmd {
+	if !m.ConfigReady || m.Client == nil {
+		return nil
+	}
+	ctx := m.Ctx
+	client := m.Client
+	return func() tea.Msg {
+		history, err := usecases.NewPlayerHistoryUseCase(client).Execute(ctx, server, usecases.PlayerHistoryQuery{Limit: 200, Refresh: true})
+		if err != nil {
+			return ConsolePlayersMsg{Server: server}
+		}
+		var names []string
+		for _, player := range history.Players {
+			if player.Online {
+				names = append(names, player.Name)
+			}
+		}
+		return ConsolePlayersMsg{Server: server, Players: names}
+	}
+}
+
+func (m TuiModel) handleConsolePlayers(msg ConsolePlayersMsg) (tea.Model, tea.Cmd) {
+	m.ConsolePlayersServer = msg.Server
+	m.ConsolePlayers = msg.Players
+	if m.Mode == ModeCommand && m.ConsoleServer == msg.Server {
+		m.refreshConsoleSuggestions()
+	}
+	return m, nil
+}
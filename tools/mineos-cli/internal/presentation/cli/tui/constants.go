@@ -47,7 +47,8 @@ const MinecraftCat = `
 
 // Log buffer and streaming constants
 const (
-	MaxLogLines          = 5000 // Increased buffer size
+	MaxLogLines          = 5000     // Increased buffer size
+	LogMemoryBudget      = 16 << 20 // Bytes of log text kept across all sources
 	DefaultDockerLogTail = 200
 	LogRetryDelay        = 2 * time.Second
 	ConnectionRetryDelay = 6 * time.Second
@@ -57,8 +58,9 @@ const (
 
 // Streaming constants
 const (
-	StreamingBufferSize = 100
-	ScannerMaxBuffer    = 1024 * 1024 // 1MB
+	StreamingBufferSize  = 100
+	ScannerMaxBuffer     = 1024 * 1024 // 1MB
+	StreamingCancelGrace = 10 * time.Second // Wait after an interrupt before killing
 )
 
 // Timeout constants
@@ -8,13 +8,15 @@ import (
 func (m TuiModel) RenderDashboardMain(width, height int) []string {
 	lines := make([]string, 0, height)
 
-	// Banner
-	for _, bannerLine := range strings.Split(Banner, "\n") {
-		lines = append(lines, StyleHeader.Render(bannerLine))
+	// Banner; accessible mode skips the ASCII art, which reads as noise
+	if !m.Accessible {
+		for _, bannerLine := range strings.Split(Banner, "\n") {
+			lines = append(lines, StyleHeader.Render(bannerLine))
+		}
 	}
 	lines = append(lines, StyleSubtle.Render(BannerTagline))
 	lines = append(lines, "")
-	lines = append(lines, StyleSubtle.Render(strings.Repeat("─", width)))
+	lines = append(lines, m.rule(width))
 	lines = append(lines, "")
 
 	// API Status - based on config ready and server list loaded
@@ -22,6 +24,8 @@ func (m TuiModel) RenderDashboardMain(width, height int) []string {
 	var health string
 	if m.ContainersStopped {
 		health = StyleSubtle.Render("stopped (containers down)")
+	} else if m.ApiDown {
+		health = StyleStopped.Render(apiDownMessage())
 	} else if m.ConfigReady && m.Client != nil && m.ErrMsg == "" {
 		health = StyleRunning.Render("connected")
 	} else if !m.ConfigReady && m.ErrMsg == "" {
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/presentation/cli/i18n"
+)
+
+// cliExitUnreachable is the CLI's exit code for a service, usually the API,
+// that does not answer.
+const cliExitUnreachable = 3
+
+// apiConnectionError reports an error from a request the API never
+// answered, as opposed to one it answered with an error.
+func apiConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	errStr := err.Error()
+	return strings.Contains(errStr, "connection refused") ||
+		strings.Contains(errStr, "no such host") ||
+		strings.Contains(errStr, "i/o timeout")
+}
+
+// actionUnreachable reports a menu action that failed because the CLI it
+// ran could not reach the API.
+func actionUnreachable(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == cliExitUnreachable
+}
+
+// apiDownMessage is shown once while the API is down, instead of the error
+// of each request that fails: Docker, the stack actions and the service
+// logs still work.
+func apiDownMessage() string {
+	return i18n.T("tui.apiDown")
+}
@@ -4,6 +4,8 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/presentation/cli/i18n"
 )
 
 func (m TuiModel) RenderFooter() string {
@@ -11,10 +13,12 @@ func (m TuiModel) RenderFooter() string {
 
 	// Command/Status line
 	if m.Mode == ModeCommand {
-		b.WriteString(StyleStatus.Render(" CONSOLE: "))
+		b.WriteString(StyleStatus.Render(i18n.T("tui.footer.console")))
 		b.WriteString(m.Input.View())
 	} else if m.ErrMsg != "" {
-		b.WriteString(TrimToWidth(StyleError.Render(" ERROR: "+m.ErrMsg), m.Width))
+		b.WriteString(TrimToWidth(StyleError.Render(i18n.T("tui.footer.error")+m.ErrMsg), m.Width))
+	} else if m.ApiDown && !m.ContainersStopped {
+		b.WriteString(TrimToWidth(StyleStopped.Render(" "+apiDownMessage()), m.Width))
 	} else if m.StatusMsg != "" {
 		b.WriteString(TrimToWidth(StyleStatus.Render(" "+m.StatusMsg), m.Width))
 	} else {
@@ -23,9 +27,21 @@ func (m TuiModel) RenderFooter() string {
 	b.WriteString("\n")
 
 	// Keyboard shortcuts line - context-sensitive
-	help := " [Up/Down] Navigate  [Enter] Select  [Esc] Back  [q] Quit"
+	help := " " + i18n.T("tui.help.default")
 	if m.CurrentView == ViewServiceLogs && len(m.ComposeServices) > 1 {
-		help = " [Up/Down] Navigate  [Left/Right] Switch Service  [Esc] Back  [q] Quit"
+		help = " " + i18n.T("tui.help.serviceLogs")
+	}
+	if m.CurrentView == ViewUpdates && !m.ReadOnly {
+		help = " " + i18n.T("tui.help.updates")
+	}
+	if m.Mode == ModeCommand {
+		help = " " + i18n.T("tui.help.console")
+		if usage := m.ConsoleUsage(); usage != "" {
+			help = " " + usage + "  |" + help
+		}
+	}
+	if m.CurrentView == ViewOutput && m.RunningOp != nil && !m.RunningOp.Interactive {
+		help = " " + i18n.T("tui.help.output", m.RunningOp.Label)
 	}
 
 	footerStyle := lipgloss.NewStyle().
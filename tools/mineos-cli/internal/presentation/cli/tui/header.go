@@ -9,11 +9,14 @@ import (
 
 func (m TuiModel) RenderHeader() string {
 	// API Health
-	health := StyleError.Render("● UNHEALTHY")
+	dot := m.glyph("● ", "")
+	health := StyleError.Render(dot + "UNHEALTHY")
 	if m.ContainersStopped {
-		health = StyleSubtle.Render("● STOPPED")
+		health = StyleSubtle.Render(dot + "STOPPED")
+	} else if m.ApiDown {
+		health = StyleStopped.Render(dot + "DOWN (limited functionality)")
 	} else if m.ConfigReady && m.ErrMsg == "" {
-		health = StyleRunning.Render("● HEALTHY")
+		health = StyleRunning.Render(dot + "HEALTHY")
 	}
 
 	// Logo/Title with ALPHA warning
@@ -22,10 +25,15 @@ func (m TuiModel) RenderHeader() string {
 		version = "dev"
 	}
 	logo := StyleHeader.Render(" MineOS ") + StyleSubtle.Render(" v"+version) + " " + StyleError.Render("[ALPHA - EXPERIMENTAL]")
+	if m.ReadOnly {
+		logo += " " + StyleStatus.Render("[READ-ONLY]")
+	}
+	if status := m.RenderOperationStatus(); status != "" {
+		logo += " " + status
+	}
 
 	// Persistent Info (Top Left Box)
-	apiPort := Fallback(m.Cfg.ApiPort, "5078")
-	apiEndpoint := StyleSubtle.Render(fmt.Sprintf("http://localhost:%s", apiPort))
+	apiEndpoint := StyleSubtle.Render(m.Cfg.ApiURL())
 	infoLines := []string{
 		fmt.Sprintf("API:       %s  %s", health, apiEndpoint),
 		fmt.Sprintf("Origin:    %s", StyleStatus.Render(Fallback(m.Cfg.WebOrigin, "http://localhost:3000"))),
@@ -40,6 +48,9 @@ func (m TuiModel) RenderHeader() string {
 	totalWidth := m.Width
 
 	joined := lipgloss.NewStyle().Width(totalWidth).Padding(0, 1).Render(logo + "\n" + headerLeft)
+	if m.Accessible {
+		return joined
+	}
 
 	return lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder(), false, false, true, false).
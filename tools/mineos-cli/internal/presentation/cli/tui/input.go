@@ -9,6 +9,14 @@ import (
 
 // HandleKey processes key input in normal mode
 func (m TuiModel) HandleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Ctrl+C, and Esc on its output, cancel the running operation before
+	// they quit or navigate.
+	if m.RunningOp != nil && !m.RunningOp.Interactive {
+		if msg.Type == tea.KeyCtrlC || (msg.Type == tea.KeyEsc && m.CurrentView == ViewOutput) {
+			return m.CancelOperation()
+		}
+	}
+
 	switch msg.Type {
 	case tea.KeyCtrlC:
 		m.Quitting = true
@@ -40,11 +48,20 @@ func (m TuiModel) HandleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.pageDown()
 	}
 
+	if m.CurrentView == ViewUpdates {
+		if next, cmd, handled := m.HandleUpdatesKey(msg.String()); handled {
+			return next, cmd
+		}
+	}
+
 	// Vim-style navigation
 	switch msg.String() {
 	case "q":
 		m.Quitting = true
 		m.StopLogs()
+		if m.OpCancel != nil {
+			m.OpCancel()
+		}
 		return m, tea.Quit
 	case "j":
 		return m.navDown()
@@ -56,7 +73,7 @@ func (m TuiModel) HandleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.navRight()
 	case "p":
 		// Toggle pre-release updates in settings view
-		if m.CurrentView == ViewSettings && m.ConfigReady {
+		if m.CurrentView == ViewSettings && m.ConfigReady && !m.ReadOnly {
 			return m, m.ToggleEnvSettingCmd("MINEOS_CLI_PRERELEASE_UPDATES", m.Cfg.PreReleaseUpdates)
 		}
 	case "/":
@@ -229,7 +246,7 @@ func (m TuiModel) navSelect() (tea.Model, tea.Cmd) {
 	}
 
 	// In servers view without server actions, Enter selects a server and shows actions
-	if m.CurrentView == ViewServers && len(m.Servers) > 0 {
+	if m.CurrentView == ViewServers && len(m.Servers) > 0 && !m.ReadOnly {
 		m.ServerActions = true
 		m.ActionIndex = 0
 		return m, nil
@@ -262,6 +279,9 @@ func (m TuiModel) navSelect() (tea.Model, tea.Cmd) {
 			m.LogType = LogTypeDocker
 			m.Logs = nil
 			cmd = m.StartLogStreamCmd()
+		} else if item.View == ViewUpdates && m.Updates == nil && !m.UpdatesLoading {
+			m.UpdatesLoading = true
+			cmd = m.LoadUpdatesCmd()
 		}
 		return m, cmd
 
@@ -276,10 +296,13 @@ func (m TuiModel) navSelect() (tea.Model, tea.Cmd) {
 				m.ErrMsg = "Select a server first (go to Servers view)"
 				return m, nil
 			}
-			m.Mode = ModeCommand
-			m.Input.SetValue("")
-			m.Input.Focus()
-			return m, textinput.Blink
+			cmd := m.EnterConsoleMode(m.SelectedServer())
+			return m, cmd
+		}
+
+		if reason := m.operationBlocked(*item.Action); reason != "" {
+			m.StatusMsg = reason
+			return m, nil
 		}
 
 		// Check if action requires confirmation
@@ -316,36 +339,34 @@ func (m TuiModel) executeServerAction() (tea.Model, tea.Cmd) {
 
 	// Handle console command
 	if action.Action == "console" {
-		m.Mode = ModeCommand
-		m.Input.SetValue("")
-		m.Input.Focus()
-		return m, textinput.Blink
+		cmd := m.EnterConsoleMode(serverName)
+		return m, cmd
+	}
+
+	menuItem := MenuItem{
+		Label: action.Label + ": " + serverName,
+		Args:  action.Args(serverName),
+	}
+	if reason := m.operationBlocked(menuItem); reason != "" {
+		m.StatusMsg = reason
+		return m, nil
 	}
 
 	// Handle destructive actions
 	if action.Destructive {
-		menuItem := &MenuItem{
-			Label:       action.Label,
-			Args:        []string{"servers", serverName, action.Action},
-			Destructive: true,
+		menuItem.Destructive = true
+		if action.ConfirmName {
+			m.RequestNameConfirmation(&menuItem, serverActionWarning(action.Action, serverName), serverName)
+			return m, nil
 		}
-		m.ConfirmAction = menuItem
+		m.ConfirmAction = &menuItem
 		m.ConfirmMessage = "This action may cause data loss. Continue?"
 		m.Mode = ModeConfirm
 		return m, nil
 	}
 
-	// Execute the server action
-	m.PreviousView = m.CurrentView
-	m.CurrentView = ViewOutput
-	m.OutputTitle = action.Label + ": " + serverName
-	m.OutputLines = []string{"Executing " + action.Label + " on " + serverName + "..."}
-
-	menuItem := MenuItem{
-		Label: action.Label,
-		Args:  []string{"servers", serverName, action.Action},
-	}
-	return m, m.ExecMenuItem(menuItem)
+	cmd := m.StartOperation(menuItem)
+	return m, cmd
 }
 
 // navBack handles Esc key - goes back to previous view or exits
@@ -392,12 +413,6 @@ func (m TuiModel) executeNavAction(item NavItem) (tea.Model, tea.Cmd) {
 		args[1] = server
 	}
 
-	// Switch to output view for all commands
-	m.PreviousView = m.CurrentView
-	m.CurrentView = ViewOutput
-	m.OutputTitle = item.Label
-	m.OutputLines = []string{"Executing " + item.Label + "..."}
-
 	// Execute via CLI subprocess
 	menuItem := MenuItem{
 		Label:       item.Label,
@@ -405,28 +420,34 @@ func (m TuiModel) executeNavAction(item NavItem) (tea.Model, tea.Cmd) {
 		Interactive: item.Action.Interactive,
 		Streaming:   item.Action.Streaming,
 	}
-	return m, m.ExecMenuItem(menuItem)
+	cmd := m.StartOperation(menuItem)
+	return m, cmd
 }
 
 // HandleCommandInput handles input when in command mode
 func (m TuiModel) HandleCommandInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
 	case tea.KeyEsc:
-		m.Mode = ModeNormal
-		m.Input.Blur()
+		m.leaveConsoleMode()
 		return m, nil
 	case tea.KeyEnter:
 		command := strings.TrimSpace(m.Input.Value())
-		m.Mode = ModeNormal
-		m.Input.Blur()
+		m.leaveConsoleMode()
 		if command == "" {
 			return m, nil
 		}
 		return m, m.ConsoleCommandCmd(command)
+	case tea.KeyUp:
+		m.recallConsoleHistory(-1)
+		return m, nil
+	case tea.KeyDown:
+		m.recallConsoleHistory(1)
+		return m, nil
 	}
 
 	var cmd tea.Cmd
 	m.Input, cmd = m.Input.Update(msg)
+	m.refreshConsoleSuggestions()
 	return m, cmd
 }
 
@@ -460,8 +481,23 @@ func (m TuiModel) HandleInteractiveInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// serverActionWarning is the confirm dialog message of an action that asks
+// for the server name.
+func serverActionWarning(action, server string) string {
+	switch action {
+	case "restore":
+		return "Restores the latest backup over " + server + "'s world."
+	case "delete":
+		return "Moves " + server + " with its backups to the trash."
+	}
+	return "This action cannot be undone."
+}
+
 // HandleConfirmInput handles input when in confirm mode
 func (m TuiModel) HandleConfirmInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ConfirmName != "" {
+		return m.handleConfirmNameInput(msg)
+	}
 	switch msg.Type {
 	case tea.KeyEsc:
 		m.Mode = ModeNormal
@@ -476,13 +512,8 @@ func (m TuiModel) HandleConfirmInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.ConfirmAction = nil
 			m.ConfirmMessage = ""
 
-			// Switch to output view for all commands
-			m.PreviousView = m.CurrentView
-			m.CurrentView = ViewOutput
-			m.OutputTitle = action.Label
-			m.OutputLines = []string{"Executing " + action.Label + "..."}
-
-			return m, m.ExecMenuItem(*action)
+			cmd := m.StartOperation(*action)
+			return m, cmd
 		}
 		m.Mode = ModeNormal
 		return m, nil
@@ -496,13 +527,8 @@ func (m TuiModel) HandleConfirmInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.ConfirmAction = nil
 			m.ConfirmMessage = ""
 
-			// Switch to output view for all commands
-			m.PreviousView = m.CurrentView
-			m.CurrentView = ViewOutput
-			m.OutputTitle = action.Label
-			m.OutputLines = []string{"Executing " + action.Label + "..."}
-
-			return m, m.ExecMenuItem(*action)
+			cmd := m.StartOperation(*action)
+			return m, cmd
 		}
 		return m, nil
 
@@ -516,6 +542,39 @@ func (m TuiModel) HandleConfirmInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleConfirmNameInput takes the typed server name; Enter only confirms
+// once it matches.
+func (m TuiModel) handleConfirmNameInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.Mode = ModeNormal
+		m.ConfirmAction = nil
+		m.ConfirmMessage = ""
+		m.ConfirmName = ""
+		m.Input.Blur()
+		return m, nil
+
+	case tea.KeyEnter:
+		if strings.TrimSpace(m.Input.Value()) != m.ConfirmName || m.ConfirmAction == nil {
+			return m, nil
+		}
+		action := m.ConfirmAction
+		m.Mode = ModeNormal
+		m.ConfirmAction = nil
+		m.ConfirmMessage = ""
+		m.ConfirmName = ""
+		m.Input.SetValue("")
+		m.Input.Blur()
+
+		cmd := m.StartOperation(*action)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.Input, cmd = m.Input.Update(msg)
+	return m, cmd
+}
+
 // NextLogSource cycles to the next log source
 func (m TuiModel) NextLogSource(current string, services []string) string {
 	if len(services) == 0 {
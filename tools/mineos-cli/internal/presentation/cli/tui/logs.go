@@ -11,7 +11,7 @@ func (m TuiModel) RenderServiceLogsMain(width, height int) []string {
 
 	// Header
 	lines = append(lines, StyleHeader.Render(" SERVICE LOGS "))
-	lines = append(lines, StyleSubtle.Render(strings.Repeat("─", width)))
+	lines = append(lines, m.rule(width))
 
 	// Service selector
 	if len(m.ComposeServices) > 1 {
@@ -27,7 +27,7 @@ func (m TuiModel) RenderServiceLogsMain(width, height int) []string {
 			}
 		}
 		lines = append(lines, TrimToWidth(svcLine, width))
-		lines = append(lines, StyleSubtle.Render("  Use ← → to switch services"))
+		lines = append(lines, StyleSubtle.Render("  Use "+m.glyph("← →", "Left and Right")+" to switch services"))
 		lines = append(lines, "")
 	}
 
@@ -101,6 +101,9 @@ func (m TuiModel) RenderServiceLogsMain(width, height int) []string {
 	} else {
 		scrollInfo = fmt.Sprintf("  Viewing latest (%d total)", totalLogs)
 	}
+	if m.LogsDropped > 0 {
+		scrollInfo += fmt.Sprintf(", %d older lines dropped", m.LogsDropped)
+	}
 	lines = append(lines, StyleSubtle.Render(scrollInfo))
 
 	// Show search hint or active search
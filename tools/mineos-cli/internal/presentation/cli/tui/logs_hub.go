@@ -0,0 +1,232 @@
+package tui
+
+import (
+	"context"
+	"sync"
+)
+
+// LogHub collects the lines of the TUI's log streams. Streams write into one
+// channel drained by a single fan-in goroutine, which keeps a ring buffer per
+// source within LogMemoryBudget. Starting a stream cancels the previous one,
+// so switching sources quickly never leaves readers behind.
+type LogHub struct {
+	in      chan logEvent
+	updates chan struct{}
+	done    chan struct{}
+	once    sync.Once
+
+	mu     sync.Mutex
+	rings  map[string]*logRing
+	bytes  int
+	active string
+	gen    uint64
+	cancel context.CancelFunc
+	status *LogStatus
+}
+
+// LogStatus reports how the active stream ended: Err is nil when the source
+// closed cleanly (e.g. a container restarted) and the stream should reconnect.
+type LogStatus struct {
+	Err error
+}
+
+type logEvent struct {
+	gen    uint64
+	source string
+	line   string
+	end    bool
+	err    error
+}
+
+// logRing holds the newest MaxLogLines lines of one source. A stale ring is
+// still shown while its source reconnects and is cleared by the first line
+// of the new stream, which replays the source's history.
+type logRing struct {
+	lines   []string
+	head    int
+	count   int
+	bytes   int
+	dropped int
+	stale   bool
+}
+
+// NewLogHub starts the fan-in goroutine; Close stops it.
+func NewLogHub() *LogHub {
+	h := &LogHub{
+		in:      make(chan logEvent, StreamingBufferSize),
+		updates: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		rings:   map[string]*logRing{},
+	}
+	go h.run()
+	return h
+}
+
+// Start makes source the active stream, cancelling the previous one. run
+// blocks until the stream ends, passing each line to emit.
+func (h *LogHub) Start(source string, run func(ctx context.Context, emit func(string)) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	h.mu.Lock()
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.gen++
+	gen := h.gen
+	h.cancel = cancel
+	h.active = source
+	h.status = nil
+	if ring, ok := h.rings[source]; ok {
+		ring.stale = true
+	}
+	h.mu.Unlock()
+	h.notify()
+
+	go func() {
+		emit := func(line string) {
+			select {
+			case h.in <- logEvent{gen: gen, source: source, line: line}:
+			case <-ctx.Done():
+			}
+		}
+		err := run(ctx, emit)
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case h.in <- logEvent{gen: gen, source: source, end: true, err: err}:
+		case <-h.done:
+		}
+	}()
+}
+
+// Stop cancels the active stream.
+func (h *LogHub) Stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cancel != nil {
+		h.cancel()
+		h.cancel = nil
+	}
+	h.gen++
+	h.active = ""
+}
+
+// Close stops the active stream and the fan-in goroutine.
+func (h *LogHub) Close() {
+	h.Stop()
+	h.once.Do(func() { close(h.done) })
+}
+
+// Updates signals (coalesced) that the active source changed.
+func (h *LogHub) Updates() <-chan struct{} {
+	return h.updates
+}
+
+// Done is closed by Close.
+func (h *LogHub) Done() <-chan struct{} {
+	return h.done
+}
+
+// Snapshot returns the active source's lines, how many were dropped to stay
+// within the buffer limits, and the stream's end status, which is reported
+// once.
+func (h *LogHub) Snapshot() ([]string, int, *LogStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status := h.status
+	h.status = nil
+	ring, ok := h.rings[h.active]
+	if !ok {
+		return nil, 0, status
+	}
+	lines := make([]string, 0, ring.count)
+	for i := 0; i < ring.count; i++ {
+		lines = append(lines, ring.lines[(ring.head+i)%len(ring.lines)])
+	}
+	return lines, ring.dropped, status
+}
+
+func (h *LogHub) run() {
+	for {
+		select {
+		case <-h.done:
+			return
+		case event := <-h.in:
+			if h.handle(event) {
+				h.notify()
+			}
+		}
+	}
+}
+
+func (h *LogHub) handle(event logEvent) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if event.gen != h.gen {
+		return false
+	}
+	if event.end {
+		h.status = &LogStatus{Err: event.err}
+		return true
+	}
+	h.push(event.source, event.line)
+	return true
+}
+
+func (h *LogHub) push(source, line string) {
+	ring, ok := h.rings[source]
+	if !ok {
+		ring = &logRing{lines: make([]string, MaxLogLines)}
+		h.rings[source] = ring
+	}
+	if ring.stale {
+		h.bytes -= ring.bytes
+		*ring = logRing{lines: ring.lines}
+		clear(ring.lines)
+	}
+	if ring.count == len(ring.lines) {
+		h.evict(ring)
+	}
+	ring.lines[(ring.head+ring.count)%len(ring.lines)] = line
+	ring.count++
+	ring.bytes += len(line)
+	h.bytes += len(line)
+
+	// Over budget: free other sources first, then the oldest active lines.
+	for h.bytes > LogMemoryBudget {
+		victim := ring
+		for name, other := range h.rings {
+			if other == ring {
+				continue
+			}
+			if other.count == 0 {
+				delete(h.rings, name)
+				continue
+			}
+			victim = other
+			break
+		}
+		if victim.count == 0 {
+			break
+		}
+		h.evict(victim)
+	}
+}
+
+func (h *LogHub) evict(ring *logRing) {
+	line := ring.lines[ring.head]
+	ring.lines[ring.head] = ""
+	ring.head = (ring.head + 1) % len(ring.lines)
+	ring.count--
+	ring.bytes -= len(line)
+	h.bytes -= len(line)
+	ring.dropped++
+}
+
+func (h *LogHub) notify() {
+	select {
+	case h.updates <- struct{}{}:
+	default:
+	}
+}
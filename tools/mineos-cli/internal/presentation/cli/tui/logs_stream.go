@@ -5,158 +5,125 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"os/exec"
 	"sync"
 	"time"
 
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/logtime"
 )
 
 func (m *TuiModel) EnsureLogStream() {
 	if !m.LogsActive {
 		return
 	}
+	if source, run, ok := m.logStream(); ok {
+		m.LogHub.Start(source, run)
+		return
+	}
+	m.LogHub.Stop()
+}
 
-	m.StopLogs()
-	ctx, cancel := context.WithCancel(context.Background())
-	m.LogCancel = cancel
-
+// logStream returns the hub key and reader for the selected log source, or
+// false when the source isn't available yet.
+func (m TuiModel) logStream() (string, func(context.Context, func(string)) error, bool) {
 	if m.LogType == LogTypeDocker {
 		if !m.ComposeReady {
-			return
+			return "", nil, false
 		}
-		m.LogsChan, m.LogErrsChan = StreamDockerLogs(ctx, m.Compose, m.LogSource)
-	} else {
-		if !m.ConfigReady || m.MinecraftSource == "" {
-			return
-		}
-		m.LogsChan, m.LogErrsChan = StreamMinecraftLogs(ctx, m.Client, m.MinecraftSource, m.MinecraftType)
+		compose, service, times := m.Compose, m.LogSource, m.LogTimes
+		return "docker:" + service, func(ctx context.Context, emit func(string)) error {
+			return StreamDockerLogs(ctx, compose, service, times, emit)
+		}, true
+	}
+	if !m.ConfigReady || m.MinecraftSource == "" {
+		return "", nil, false
 	}
+	client, server, logType, times := m.Client, m.MinecraftSource, m.MinecraftType, m.LogTimes
+	return "minecraft:" + server + "/" + logType, func(ctx context.Context, emit func(string)) error {
+		return StreamMinecraftLogs(ctx, client, server, logType, times, emit)
+	}, true
 }
 
-func StreamMinecraftLogs(ctx context.Context, client *api.Client, server, source string) (<-chan string, <-chan error) {
-	logsChan := make(chan string, 100)
-	errsChan := make(chan error, 10)
-
-	go func() {
-		defer close(logsChan)
-		defer close(errsChan)
-
-		entries, apiErrs := client.StreamConsoleLogs(ctx, server, source)
-		for {
-			select {
-			case <-ctx.Done():
-				// Normal cancellation, don't send error
-				return
-			case err, ok := <-apiErrs:
-				if !ok {
-					// Channel closed normally
-					return
-				}
-				if err != nil {
-					// Only send actual API errors, not connection closes
-					if ctx.Err() == nil {
-						errsChan <- err
-					}
-					return
-				}
-			case entry, ok := <-entries:
-				if !ok {
-					// Stream closed - will trigger reconnection via channel close
-					// Don't send explicit error, let the channel close handle it
-					return
-				}
-				msg := entry.Message
-				if !entry.Timestamp.IsZero() {
-					msg = fmt.Sprintf("[%s] %s", entry.Timestamp.Format(time.RFC3339), msg)
-				}
-				logsChan <- msg
+// StreamMinecraftLogs passes a server's console lines, with timestamps
+// normalized by times, to emit until the stream closes or ctx is cancelled.
+func StreamMinecraftLogs(ctx context.Context, client *api.Client, server, source string, times logtime.Normalizer, emit func(string)) error {
+	entries, apiErrs := client.StreamConsoleLogs(ctx, server, source)
+	for {
+		select {
+		case <-ctx.Done():
+			// Normal cancellation, don't report an error
+			return nil
+		case err, ok := <-apiErrs:
+			if !ok || err == nil {
+				// Channel closed normally
+				return nil
+			}
+			return err
+		case entry, ok := <-entries:
+			if !ok {
+				// Stream closed - the hub reports it so the TUI reconnects
+				return nil
 			}
+			_, msg := times.MinecraftEntry(entry.Timestamp, entry.Message, time.Now())
+			emit(msg)
 		}
-	}()
-
-	return logsChan, errsChan
+	}
 }
 
-func StreamDockerLogs(ctx context.Context, compose *ComposeRunner, service string) (<-chan string, <-chan error) {
-	logs := make(chan string, 100)
-	errs := make(chan error, 1)
-
-	go func() {
-		args := append([]string{}, compose.BaseArgs...)
-		args = append(args, "logs", "-f", "--tail", fmt.Sprintf("%d", DefaultDockerLogTail), "--timestamps")
-		if service != "" && service != DefaultDockerLogSource {
-			args = append(args, service)
-		}
-
-		cmd := exec.CommandContext(ctx, compose.Exe, args...)
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			errs <- err
-			close(logs)
-			close(errs)
-			return
-		}
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			errs <- err
-			close(logs)
-			close(errs)
-			return
-		}
+// StreamDockerLogs passes compose log lines, with timestamps normalized by
+// times, to emit until the command exits or ctx is cancelled.
+func StreamDockerLogs(ctx context.Context, compose *ComposeRunner, service string, times logtime.Normalizer, emit func(string)) error {
+	args := []string{"logs", "-f", "--tail", fmt.Sprintf("%d", DefaultDockerLogTail), "--timestamps"}
+	if service != "" && service != DefaultDockerLogSource {
+		args = append(args, service)
+	}
 
-		if err := cmd.Start(); err != nil {
-			errs <- err
-			close(logs)
-			close(errs)
-			return
-		}
+	cmd := compose.Command(ctx, args)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
 
-		var wg sync.WaitGroup
-		readStream := func(reader io.Reader) {
-			defer wg.Done()
-			scanner := bufio.NewScanner(reader)
-			scanner.Buffer(make([]byte, 0, 1024*1024), 1024*1024)
-			for scanner.Scan() {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-				}
-				line := scanner.Text()
-				select {
-				case logs <- formatDockerLogLine(line):
-				case <-ctx.Done():
-					return
-				}
+	var wg sync.WaitGroup
+	readStream := func(reader io.Reader) {
+		defer wg.Done()
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, ScannerMaxBuffer), ScannerMaxBuffer)
+		for scanner.Scan() {
+			if ctx.Err() != nil {
+				return
 			}
+			emit(formatDockerLogLine(times, scanner.Text()))
 		}
+	}
 
-		wg.Add(2)
-		go readStream(stdout)
-		go readStream(stderr)
-
-		// Wait for readers to finish before closing channels
-		wg.Wait()
-		_ = cmd.Wait()
-		close(logs)
-		close(errs)
-	}()
+	wg.Add(2)
+	go readStream(stdout)
+	go readStream(stderr)
 
-	return logs, errs
+	// Wait for readers to finish before reaping the process
+	wg.Wait()
+	_ = cmd.Wait()
+	return nil
 }
 
+// StopLogs stops log streaming for good (on quit).
 func (m *TuiModel) StopLogs() {
-	if m.LogCancel != nil {
-		m.LogCancel()
-		m.LogCancel = nil
+	if m.LogHub != nil {
+		m.LogHub.Close()
 	}
-	m.LogsChan = nil
-	m.LogErrsChan = nil
 }
 
-func formatDockerLogLine(line string) string {
-	// Docker logs often have a service name prefix like "service-1 | message"
-	// but it depends on the driver. For compose logs, it's usually there.
-	return line // Simplified for now, styling can be added in view
+func formatDockerLogLine(times logtime.Normalizer, line string) string {
+	// Lines look like "service-1  | 2024-05-01T18:30:00.123Z message";
+	// only the timestamp is rewritten, styling is left to the view.
+	_, line = times.DockerLine(line)
+	return line
 }
@@ -3,12 +3,15 @@ package tui
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/config"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/domain/ports"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/logtime"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/ssh"
 )
 
 // TuiView represents the different views in the TUI
@@ -19,6 +22,7 @@ const (
 	ViewServers
 	ViewServiceLogs // Docker container logs
 	ViewSettings
+	ViewUpdates
 	ViewOutput // Shows command output
 )
 
@@ -92,9 +96,9 @@ type TuiModel struct {
 	LogSource       string
 	MinecraftSource string // server name
 	MinecraftType   string // combined|server|java|crash
-	LogsChan        <-chan string
-	LogErrsChan     <-chan error
-	LogCancel       context.CancelFunc
+	LogHub          *LogHub
+	LogsDropped     int // Lines of the current source dropped by the buffer limits
+	LogTimes        logtime.Normalizer
 	LogScroll       int    // Scroll offset for logs view
 	LogSearchQuery  string // Search query for logs
 	LogSearchMode   bool   // Whether in search mode
@@ -118,9 +122,22 @@ type TuiModel struct {
 	Input    textinput.Model
 	Quitting bool
 
+	// Console prompt state (see console.go). ConsoleHistoryIndex is
+	// len(ConsoleHistory) while not recalling; ConsoleDraft keeps what was
+	// typed before.
+	ConsoleServer        string
+	ConsoleHistory       []string
+	ConsoleHistoryIndex  int
+	ConsoleDraft         string
+	ConsolePlayers       []string
+	ConsolePlayersServer string
+
 	// Confirmation dialog state
 	ConfirmAction  *MenuItem
 	ConfirmMessage string
+	// ConfirmName is the server name to type into Input to confirm; empty
+	// for a y/n confirmation.
+	ConfirmName string
 
 	// Interactive command state
 	InteractiveStdin   io.WriteCloser
@@ -132,11 +149,40 @@ type TuiModel struct {
 	StreamingRunning bool
 	StreamingLabel   string
 
+	// Operation queue (see ops.go): RunningOp is the menu action in
+	// progress, QueuedOps wait for it in order
+	RunningOp   *MenuItem
+	QueuedOps   []MenuItem
+	LastOpKey   string
+	LastOpEnded time.Time
+	// OpCancel interrupts the running operation; OpCancelling is set once
+	// it has been asked to stop.
+	OpCancel     context.CancelFunc
+	OpCancelling bool
+
 	// Retry state for error recovery
 	RetryCount int
 
+	// ReadOnly hides every action that changes the installation (wall
+	// displays, viewers who only need logs)
+	ReadOnly bool
+
+	// Accessible renders one column of plain text for screen readers
+	Accessible bool
+
+	// Remote is the host managed over --ssh; compose runs there and menu
+	// actions re-run the CLI against it
+	Remote *ssh.Remote
+
+	// Updates view state; nil until the view is first opened
+	Updates        *UpdateInfo
+	UpdatesLoading bool
+
 	// Container state tracking
 	ContainersStopped bool // True when user intentionally stopped containers
+	// ApiDown is set while the API does not answer; the views show it once
+	// instead of the error of each failed request (see degraded.go)
+	ApiDown bool
 }
 
 // MenuItem represents an item in the command menu
@@ -178,17 +224,12 @@ type ServersLoadedMsg struct {
 
 // LogStreamStartedMsg is sent when a new log stream is started
 type LogStreamStartedMsg struct {
-	LogsChan  <-chan string
-	ErrsChan  <-chan error
-	Cancel    context.CancelFunc
 	LogType   LogType
 	LogSource string
 }
 
-// LogLineMsg is sent for each log line received
-type LogLineMsg struct {
-	Line string
-}
+// LogsUpdatedMsg is sent when the log hub has new lines or the stream ended
+type LogsUpdatedMsg struct{}
 
 // LogErrorMsg is sent when a log streaming error occurs
 type LogErrorMsg struct {
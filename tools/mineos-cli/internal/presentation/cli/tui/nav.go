@@ -1,48 +1,122 @@
 package tui
 
-import "os"
+import (
+	"os"
 
-// BuildNavItems creates the full navigation menu
-func BuildNavItems() []NavItem {
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/plugins"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/presentation/cli/i18n"
+)
+
+// BuildNavItems creates the navigation menu; readOnly leaves only the views.
+// The item labels are translated, the labels of their actions are not:
+// the update loop tells actions apart by them.
+func BuildNavItems(readOnly bool) []NavItem {
 	items := []NavItem{
 		// Views section
-		{Label: "VIEWS", ItemType: NavHeader},
-		{Label: "Dashboard", ItemType: NavView, View: ViewDashboard},
-		{Label: "Minecraft Servers", ItemType: NavView, View: ViewServers},
-		{Label: "Service Logs", ItemType: NavView, View: ViewServiceLogs},
-		{Label: "Settings", ItemType: NavView, View: ViewSettings},
+		{Label: i18n.T("tui.nav.views"), ItemType: NavHeader},
+		{Label: i18n.T("tui.nav.dashboard"), ItemType: NavView, View: ViewDashboard},
+		{Label: i18n.T("tui.nav.servers"), ItemType: NavView, View: ViewServers},
+		{Label: i18n.T("tui.nav.serviceLogs"), ItemType: NavView, View: ViewServiceLogs},
+		{Label: i18n.T("tui.nav.settings"), ItemType: NavView, View: ViewSettings},
+		{Label: i18n.T("tui.nav.updates"), ItemType: NavView, View: ViewUpdates},
 
 		{Label: "", ItemType: NavSeparator},
 
 		// Docker services (containers)
-		{Label: "DOCKER", ItemType: NavHeader},
-		{Label: "Start Containers", ItemType: NavAction, Action: &MenuItem{Label: "Start Containers", Args: []string{"stack", "up"}, Streaming: true}},
-		{Label: "Stop Containers", ItemType: NavAction, Action: &MenuItem{Label: "Stop Containers", Args: []string{"stack", "stop"}, Streaming: true}},
-		{Label: "Restart Containers", ItemType: NavAction, Action: &MenuItem{Label: "Restart Containers", Args: []string{"stack", "restart"}, Streaming: true}},
-		{Label: "Remove Containers", ItemType: NavAction, Action: &MenuItem{Label: "Remove Containers", Args: []string{"stack", "down"}, Destructive: true, Streaming: true}, Destructive: true},
-		{Label: "Update Images", ItemType: NavAction, Action: &MenuItem{Label: "Update Images", Args: []string{"stack", "update"}, Streaming: true}},
+		{Label: i18n.T("tui.nav.docker"), ItemType: NavHeader},
+		{Label: i18n.T("tui.nav.startContainers"), ItemType: NavAction, Action: &MenuItem{Label: "Start Containers", Args: []string{"stack", "up"}, Streaming: true}},
+		{Label: i18n.T("tui.nav.stopContainers"), ItemType: NavAction, Action: &MenuItem{Label: "Stop Containers", Args: []string{"stack", "stop"}, Streaming: true}},
+		{Label: i18n.T("tui.nav.restartContainers"), ItemType: NavAction, Action: &MenuItem{Label: "Restart Containers", Args: []string{"stack", "restart"}, Streaming: true}},
+		{Label: i18n.T("tui.nav.removeContainers"), ItemType: NavAction, Action: &MenuItem{Label: "Remove Containers", Args: []string{"stack", "down"}, Destructive: true, Streaming: true}, Destructive: true},
+		{Label: i18n.T("tui.nav.updateImages"), ItemType: NavAction, Action: &MenuItem{Label: "Update Images", Args: []string{"stack", "update"}, Streaming: true}},
+		{Label: i18n.T("tui.nav.stopAllServers"), ItemType: NavAction, Action: &MenuItem{Label: "Stop All Servers", Args: []string{"servers", "stop-all"}, Destructive: true, Streaming: true}, Destructive: true},
 	}
 
 	// Only show Rebuild if source code is available (apps directory exists)
 	if hasSourceCode() {
-		items = append(items, NavItem{Label: "Rebuild Source", ItemType: NavAction, Action: &MenuItem{Label: "Rebuild Source", Args: []string{"stack", "rebuild"}, Streaming: true}})
+		items = append(items, NavItem{Label: i18n.T("tui.nav.rebuildSource"), ItemType: NavAction, Action: &MenuItem{Label: "Rebuild Source", Args: []string{"stack", "rebuild"}, Streaming: true}})
 	}
 
+	items = append(items, pluginNavItems(plugins.Discover(plugins.SearchDirs()))...)
+
 	items = append(items,
 		NavItem{Label: "", ItemType: NavSeparator},
 
 		// System actions
-		NavItem{Label: "SYSTEM", ItemType: NavHeader},
-		NavItem{Label: "Install", ItemType: NavAction, Action: &MenuItem{Label: "Install", Args: []string{"install"}, Interactive: true}},
-		NavItem{Label: "Upgrade CLI", ItemType: NavAction, Action: &MenuItem{Label: "Upgrade CLI", Args: []string{"upgrade"}}},
-		NavItem{Label: "Reconfigure", ItemType: NavAction, Action: &MenuItem{Label: "Reconfigure", Args: []string{"reconfigure"}, Interactive: true}},
-		NavItem{Label: "Refresh API Key", ItemType: NavAction, Action: &MenuItem{Label: "Refresh API Key", Args: []string{"api-key", "refresh"}}},
-		NavItem{Label: "Uninstall", ItemType: NavAction, Action: &MenuItem{Label: "Uninstall", Args: []string{"uninstall"}, Interactive: true, Destructive: true}, Destructive: true},
+		NavItem{Label: i18n.T("tui.nav.system"), ItemType: NavHeader},
+		NavItem{Label: i18n.T("tui.nav.install"), ItemType: NavAction, Action: &MenuItem{Label: "Install", Args: []string{"install"}, Interactive: true}},
+		NavItem{Label: i18n.T("tui.nav.upgradeCli"), ItemType: NavAction, Action: &MenuItem{Label: "Upgrade CLI", Args: []string{"upgrade"}}},
+		NavItem{Label: i18n.T("tui.nav.reconfigure"), ItemType: NavAction, Action: &MenuItem{Label: "Reconfigure", Args: []string{"reconfigure"}, Interactive: true}},
+		NavItem{Label: i18n.T("tui.nav.refreshApiKey"), ItemType: NavAction, Action: &MenuItem{Label: "Refresh API Key", Args: []string{"api-key", "refresh"}}},
+		NavItem{Label: i18n.T("tui.nav.uninstall"), ItemType: NavAction, Action: &MenuItem{Label: "Uninstall", Args: []string{"uninstall"}, Interactive: true, Destructive: true}, Destructive: true},
 	)
 
+	if readOnly {
+		return withoutActions(items)
+	}
 	return items
 }
 
+// withoutActions drops the action items and the sections left empty.
+func withoutActions(items []NavItem) []NavItem {
+	var result, section []NavItem
+	flush := func() {
+		for _, item := range section {
+			if !item.IsSelectable() {
+				continue
+			}
+			if len(result) > 0 {
+				result = append(result, NavItem{Label: "", ItemType: NavSeparator})
+			}
+			result = append(result, section...)
+			break
+		}
+		section = nil
+	}
+	for _, item := range items {
+		switch item.ItemType {
+		case NavAction, NavSeparator:
+			continue
+		case NavHeader:
+			flush()
+		}
+		section = append(section, item)
+	}
+	flush()
+	return result
+}
+
+// pluginNavItems builds a PLUGINS section from the TUI entries declared in
+// plugin manifests. Each entry runs "mineos <plugin> <args...>".
+func pluginNavItems(found []plugins.Plugin) []NavItem {
+	var items []NavItem
+	for _, plugin := range found {
+		for _, entry := range plugin.Manifest.Tui {
+			label := entry.Label
+			if label == "" {
+				label = plugin.Name
+			}
+			args := append([]string{plugin.Name}, entry.Args...)
+			action := &MenuItem{
+				Label:       label,
+				Args:        args,
+				Destructive: entry.Destructive,
+				Interactive: entry.Interactive,
+				Streaming:   entry.Streaming,
+			}
+			items = append(items, NavItem{Label: label, ItemType: NavAction, Action: action, Destructive: entry.Destructive})
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	header := []NavItem{
+		{Label: "", ItemType: NavSeparator},
+		{Label: i18n.T("tui.nav.plugins"), ItemType: NavHeader},
+	}
+	return append(header, items...)
+}
+
 // hasSourceCode checks if the source code is available (apps directory exists)
 func hasSourceCode() bool {
 	info, err := os.Stat("apps")
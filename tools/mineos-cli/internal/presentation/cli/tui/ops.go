@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Menu actions run the CLI as a subprocess, so the ComposeRunner mutex
+// cannot keep them apart. They go through an operation queue instead: one
+// runs at a time, the rest wait their turn, and repeats or conflicting
+// actions are refused while it is busy.
+
+// OperationCooldown ignores a repeat of an operation that just finished,
+// e.g. a double-pressed Enter on a quick action.
+const OperationCooldown = time.Second
+
+func (i MenuItem) operationKey() string {
+	return i.Label + "\x00" + strings.Join(i.Args, "\x00")
+}
+
+// touchesStack reports operations that run compose or change the install.
+// Two of them conflict, so such an action is disabled while another one is
+// running or queued.
+func (i MenuItem) touchesStack() bool {
+	if len(i.Args) == 0 {
+		return false
+	}
+	switch i.Args[0] {
+	case "stack", "install", "uninstall", "reconfigure", "upgrade":
+		return true
+	}
+	return false
+}
+
+// operationBlocked says why item can neither start nor queue now, or is
+// empty when it can.
+func (m TuiModel) operationBlocked(item MenuItem) string {
+	if m.RunningOp == nil {
+		if m.LastOpKey == item.operationKey() && time.Since(m.LastOpEnded) < OperationCooldown {
+			return item.Label + " just finished"
+		}
+		return ""
+	}
+	pending := append([]MenuItem{*m.RunningOp}, m.QueuedOps...)
+	for i, op := range pending {
+		if op.operationKey() != item.operationKey() {
+			continue
+		}
+		if i == 0 {
+			return item.Label + " is already running"
+		}
+		return item.Label + " is already queued"
+	}
+	// An interactive command takes over the terminal, which must not happen
+	// out of the blue when it comes up in the queue.
+	if item.Interactive {
+		return item.Label + " needs the terminal; wait for " + m.RunningOp.Label + " to finish"
+	}
+	if item.touchesStack() {
+		for _, op := range pending {
+			if op.touchesStack() {
+				return item.Label + " is disabled until " + op.Label + " finishes"
+			}
+		}
+	}
+	return ""
+}
+
+// StartOperation runs item now, queues it behind the running operation, or
+// refuses it with a status message.
+func (m *TuiModel) StartOperation(item MenuItem) tea.Cmd {
+	if reason := m.operationBlocked(item); reason != "" {
+		m.StatusMsg = reason
+		return nil
+	}
+	if m.RunningOp != nil {
+		m.QueuedOps = append(m.QueuedOps, item)
+		m.StatusMsg = fmt.Sprintf("Queued %s (%d waiting)", item.Label, len(m.QueuedOps))
+		return nil
+	}
+	m.OutputLines = nil
+	return m.beginOperation(item)
+}
+
+// beginOperation shows the operation's output and runs it. Output of an
+// operation that ran before it in the queue is kept above it.
+func (m *TuiModel) beginOperation(item MenuItem) tea.Cmd {
+	m.RunningOp = &item
+	if m.CurrentView != ViewOutput {
+		m.PreviousView = m.CurrentView
+	}
+	m.CurrentView = ViewOutput
+	m.OutputTitle = item.Label
+	if len(m.OutputLines) > 0 {
+		m.OutputLines = append(m.OutputLines, m.glyph(strings.Repeat("─", 20), ""), "")
+	}
+	m.OutputLines = append(m.OutputLines, "Executing "+item.Label+"...")
+
+	parent := m.Ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	m.OpCancel = cancel
+	m.OpCancelling = false
+	return m.ExecMenuItem(ctx, item)
+}
+
+// CancelOperation interrupts the running operation, which then finishes
+// as usual with what it got done reported. Interactive commands handle
+// Ctrl+C themselves.
+func (m TuiModel) CancelOperation() (tea.Model, tea.Cmd) {
+	if m.RunningOp == nil || m.OpCancel == nil || m.RunningOp.Interactive {
+		return m, nil
+	}
+	if m.OpCancelling {
+		m.StatusMsg = "Still cancelling " + m.RunningOp.Label + "; it is killed if it does not stop"
+		return m, nil
+	}
+	m.OpCancelling = true
+	m.OpCancel()
+	m.OutputLines = append(m.OutputLines, "", "Cancelling "+m.RunningOp.Label+"...")
+	m.StatusMsg = "Cancelling " + m.RunningOp.Label
+	return m, nil
+}
+
+// finishOperation marks the running operation done and starts the next
+// queued one, if any.
+func (m *TuiModel) finishOperation() tea.Cmd {
+	if m.RunningOp == nil {
+		return nil
+	}
+	m.LastOpKey = m.RunningOp.operationKey()
+	m.LastOpEnded = time.Now()
+	m.RunningOp = nil
+	if m.OpCancel != nil {
+		m.OpCancel()
+		m.OpCancel = nil
+	}
+	m.OpCancelling = false
+	if len(m.QueuedOps) == 0 {
+		return nil
+	}
+	next := m.QueuedOps[0]
+	m.QueuedOps = m.QueuedOps[1:]
+	return m.beginOperation(next)
+}
+
+// RenderOperationStatus is the header badge for the running operation.
+func (m TuiModel) RenderOperationStatus() string {
+	if m.RunningOp == nil {
+		return ""
+	}
+	status := m.glyph("⟳ ", "Running: ") + m.RunningOp.Label
+	if len(m.QueuedOps) > 0 {
+		status += fmt.Sprintf(" (+%d queued)", len(m.QueuedOps))
+	}
+	return StyleStopped.Render("[" + status + "]")
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in its own process group, so an
+// interrupt reaches compose and everything it started, as Ctrl+C in a shell
+// would.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func interruptProcessGroup(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGINT)
+}
+
+func killProcessGroup(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGKILL)
+}
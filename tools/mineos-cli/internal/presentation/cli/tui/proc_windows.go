@@ -0,0 +1,28 @@
+//go:build windows
+
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// setProcessGroup starts the command in its own process group, which
+// CTRL_BREAK can be sent to without interrupting the TUI.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: windows.CREATE_NEW_PROCESS_GROUP}
+}
+
+// interruptProcessGroup sends CTRL_BREAK, which Go programs such as the CLI
+// and compose receive as os.Interrupt.
+func interruptProcessGroup(process *os.Process) error {
+	return windows.GenerateConsoleCtrlEvent(windows.CTRL_BREAK_EVENT, uint32(process.Pid))
+}
+
+func killProcessGroup(process *os.Process) error {
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(process.Pid)).Run()
+}
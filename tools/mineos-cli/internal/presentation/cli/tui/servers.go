@@ -8,8 +8,11 @@ import (
 // ServerActionItem represents an action available for a server
 type ServerActionItem struct {
 	Label       string
-	Action      string // start, stop, restart, kill, console
+	Action      string // start, stop, restart, kill, restore, delete, console
 	Destructive bool
+	// ConfirmName asks for the server name to be typed, unless
+	// MINEOS_CONFIRM_LEVEL=yes, since the action cannot be undone.
+	ConfirmName bool
 }
 
 // GetServerActions returns the list of actions available for a server
@@ -20,10 +23,23 @@ func GetServerActions() []ServerActionItem {
 		{Label: "Restart Server", Action: "restart"},
 		{Label: "Kill Server", Action: "kill", Destructive: true},
 		{Label: "Send Console Command", Action: "console"},
+		{Label: "Restore Latest Backup", Action: "restore", Destructive: true, ConfirmName: true},
+		{Label: "Delete Server", Action: "delete", Destructive: true, ConfirmName: true},
 		{Label: "← Back to Server List", Action: "back"},
 	}
 }
 
+// Args is the mineos command line running the action on server. Actions
+// that ask for the server name skip the command's own prompt, since the TUI
+// dialog has asked already.
+func (a ServerActionItem) Args(server string) []string {
+	args := []string{"servers", a.Action, server}
+	if a.ConfirmName {
+		args = append(args, "--yes")
+	}
+	return args
+}
+
 func (m TuiModel) RenderServersMain(width, height int) []string {
 	// If in server actions mode, show actions for selected server
 	if m.ServerActions && len(m.Servers) > 0 {
@@ -36,7 +52,7 @@ func (m TuiModel) RenderServersMain(width, height int) []string {
 	tableLines := m.RenderServersTable(width, tableHeight)
 	logLines := m.RenderMinecraftLogs(width, logHeight)
 
-	lines := append(tableLines, StyleSubtle.Render(strings.Repeat("─", width)))
+	lines := append(tableLines, m.rule(width))
 	lines = append(lines, logLines...)
 	return lines
 }
@@ -47,10 +63,12 @@ func (m TuiModel) RenderServersTable(width, height int) []string {
 	// Table Header
 	header := fmt.Sprintf("  %-25s %-15s", "SERVER NAME", "STATUS")
 	lines = append(lines, StyleHeader.Render(header))
-	lines = append(lines, StyleSubtle.Render(strings.Repeat("─", width)))
+	lines = append(lines, m.rule(width))
 
 	if m.ErrMsg != "" {
 		lines = append(lines, TrimToWidth(StyleError.Render(" Error: "+m.ErrMsg), width))
+	} else if m.ApiDown {
+		lines = append(lines, TrimToWidth(StyleStopped.Render(" API down: the server list is from before it stopped answering."), width))
 	}
 
 	if len(m.Servers) == 0 {
@@ -65,7 +83,7 @@ func (m TuiModel) RenderServersTable(width, height int) []string {
 
 		nameStyle := StyleHeader // Default
 		if i == m.Selected {
-			prefix = StyleSelected.Render("▶ ")
+			prefix = StyleSelected.Render(m.pointer())
 			nameStyle = StyleSelected
 		}
 
@@ -139,7 +157,7 @@ func (m TuiModel) RenderServerActionsMain(width, height int) []string {
 	serverName := m.SelectedServer()
 	title := fmt.Sprintf(" SERVER: %s ", serverName)
 	lines = append(lines, StyleHeader.Render(title))
-	lines = append(lines, StyleSubtle.Render(strings.Repeat("─", width)))
+	lines = append(lines, m.rule(width))
 	lines = append(lines, "")
 
 	// Show server status
@@ -162,7 +180,7 @@ func (m TuiModel) RenderServerActionsMain(width, height int) []string {
 			label = label + " !"
 		}
 		if i == m.ActionIndex {
-			prefix = StyleSelected.Render("▶ ")
+			prefix = StyleSelected.Render(m.pointer())
 			label = StyleSelected.Render(label)
 		}
 		lines = append(lines, prefix+label)
@@ -176,7 +194,7 @@ func (m TuiModel) RenderServerActionsMain(width, height int) []string {
 	logHeight := height - usedHeight
 	if logHeight > 3 {
 		lines = append(lines, "")
-		lines = append(lines, StyleSubtle.Render(strings.Repeat("─", width)))
+		lines = append(lines, m.rule(width))
 		logLines := m.RenderMinecraftLogs(width, logHeight)
 		lines = append(lines, logLines...)
 	}
@@ -8,7 +8,7 @@ func (m TuiModel) RenderSettingsMain(width, height int) []string {
 	lines := make([]string, 0, height)
 
 	lines = append(lines, StyleHeader.Render(" SETTINGS "))
-	lines = append(lines, StyleSubtle.Render(strings.Repeat("─", width)))
+	lines = append(lines, m.rule(width))
 	lines = append(lines, "")
 
 	if !m.ConfigReady {
@@ -49,13 +49,21 @@ func (m TuiModel) RenderSettingsMain(width, height int) []string {
 	if m.Cfg.IsPreReleaseEnabled() {
 		channel = StyleError.Render("Preview (Pre-release)")
 	}
-	lines = append(lines, "  Channel: "+channel+"  "+StyleSubtle.Render("[p] toggle"))
+	toggle := "  " + StyleSubtle.Render("[p] toggle")
+	if m.ReadOnly {
+		toggle = ""
+	}
+	lines = append(lines, "  Channel: "+channel+toggle)
 	if m.Cfg.IsPreReleaseEnabled() {
 		lines = append(lines, "  "+StyleError.Render("Warning: Preview builds may be unstable or cause data loss."))
 	}
 	lines = append(lines, "")
 
-	lines = append(lines, StyleSubtle.Render("Use SYSTEM menu to reconfigure or update."))
+	if m.ReadOnly {
+		lines = append(lines, StyleSubtle.Render("Read-only mode: settings can't be changed from here."))
+	} else {
+		lines = append(lines, StyleSubtle.Render("Use SYSTEM menu to reconfigure or update."))
+	}
 
 	return PadLines(lines, height)
 }
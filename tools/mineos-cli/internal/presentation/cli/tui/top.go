@@ -0,0 +1,288 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Sort keys for the top view.
+const (
+	TopSortCPU     = "cpu"
+	TopSortMemory  = "mem"
+	TopSortPlayers = "players"
+	TopSortTps     = "tps"
+	TopSortName    = "name"
+)
+
+var topSortKeys = []string{TopSortCPU, TopSortMemory, TopSortPlayers, TopSortTps, TopSortName}
+
+// TopContainer is one MineOS container as docker stats reports it.
+type TopContainer struct {
+	Name       string
+	CPUPercent float64
+	MemUsedMb  float64
+	MemLimitMb float64
+	NetIO      string
+	BlockIO    string
+	PIDs       string
+}
+
+// TopServer is one Minecraft server's latest performance sample.
+type TopServer struct {
+	Name       string
+	Running    bool
+	CPUPercent float64
+	RamUsedMb  int64
+	RamTotalMb int64
+	Players    int
+	Tps        *float64
+}
+
+// TopSnapshot is one refresh of the top view. Errors are shown above the
+// tables; a failing source does not hide the other.
+type TopSnapshot struct {
+	Containers []TopContainer
+	Servers    []TopServer
+	Errors     []string
+	Taken      time.Time
+}
+
+type TopOptions struct {
+	Interval time.Duration
+	Sort     string
+	// Fetch collects a snapshot; it may take longer than Interval, the next
+	// refresh then starts when it returns.
+	Fetch func(ctx context.Context) TopSnapshot
+}
+
+type topModel struct {
+	ctx      context.Context
+	opts     TopOptions
+	snapshot TopSnapshot
+	sortKey  string
+	reverse  bool
+	loading  bool
+	width    int
+}
+
+type topSnapshotMsg TopSnapshot
+type topTickMsg struct{}
+
+// RunTop shows the live resource view until q or Ctrl+C.
+func RunTop(ctx context.Context, opts TopOptions, in io.Reader, out io.Writer) error {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	model := topModel{ctx: ctx, opts: opts, sortKey: opts.Sort, loading: true}
+	if model.sortKey == "" {
+		model.sortKey = TopSortCPU
+	}
+
+	programOpts := []tea.ProgramOption{tea.WithAltScreen()}
+	if in != nil {
+		programOpts = append(programOpts, tea.WithInput(in))
+	}
+	if out != nil {
+		programOpts = append(programOpts, tea.WithOutput(out))
+	}
+	program := tea.NewProgram(model, programOpts...)
+	go func() {
+		<-ctx.Done()
+		program.Quit()
+	}()
+	_, err := program.Run()
+	return err
+}
+
+func (m topModel) Init() tea.Cmd {
+	return m.fetch()
+}
+
+func (m topModel) fetch() tea.Cmd {
+	return func() tea.Msg {
+		return topSnapshotMsg(m.opts.Fetch(m.ctx))
+	}
+}
+
+func (m topModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "s", "tab":
+			m.sortKey = nextTopSort(m.sortKey)
+		case "r":
+			m.reverse = !m.reverse
+		case "c":
+			m.sortKey = TopSortCPU
+		case "m":
+			m.sortKey = TopSortMemory
+		case "p":
+			m.sortKey = TopSortPlayers
+		case "t":
+			m.sortKey = TopSortTps
+		case "n":
+			m.sortKey = TopSortName
+		}
+		return m, nil
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+	case topSnapshotMsg:
+		m.snapshot = TopSnapshot(msg)
+		m.loading = false
+		return m, tea.Tick(m.opts.Interval, func(time.Time) tea.Msg { return topTickMsg{} })
+	case topTickMsg:
+		return m, m.fetch()
+	}
+	return m, nil
+}
+
+func (m topModel) View() string {
+	if m.loading {
+		return StyleSubtle.Render("Collecting stats...")
+	}
+	return RenderTop(m.snapshot, m.sortKey, m.reverse, m.width) + "\n" +
+		StyleSubtle.Render("s/c/m/p/t/n sort  r reverse  q quit")
+}
+
+// RenderTop draws both tables; the top command also prints it once for
+// --once and non-terminal output.
+func RenderTop(snapshot TopSnapshot, sortKey string, reverse bool, width int) string {
+	var b strings.Builder
+	title := fmt.Sprintf("MineOS top - %s", snapshot.Taken.Local().Format("15:04:05"))
+	b.WriteString(StyleHeader.Render(title) + "  " + StyleSubtle.Render("sorted by "+sortKey) + "\n")
+	for _, msg := range snapshot.Errors {
+		b.WriteString(StyleError.Render("! "+msg) + "\n")
+	}
+
+	servers := append([]TopServer(nil), snapshot.Servers...)
+	sortTopServers(servers, sortKey, reverse)
+	b.WriteString("\n" + StyleHeader.Render(fmt.Sprintf("%-24s %-8s %7s %16s %8s %6s", "SERVER", "STATUS", "CPU", "MEMORY", "PLAYERS", "TPS")) + "\n")
+	if len(servers) == 0 {
+		b.WriteString(StyleSubtle.Render("No servers") + "\n")
+	}
+	for _, server := range servers {
+		status := StyleStopped.Render(fmt.Sprintf("%-8s", "stopped"))
+		if server.Running {
+			status = StyleRunning.Render(fmt.Sprintf("%-8s", "running"))
+		}
+		memory := "-"
+		cpu, players, tps := "-", "-", "-"
+		if server.Running {
+			cpu = fmt.Sprintf("%.1f%%", server.CPUPercent)
+			memory = fmt.Sprintf("%d MB", server.RamUsedMb)
+			if server.RamTotalMb > 0 {
+				memory = fmt.Sprintf("%d/%d MB", server.RamUsedMb, server.RamTotalMb)
+			}
+			players = fmt.Sprintf("%d", server.Players)
+			if server.Tps != nil {
+				tps = fmt.Sprintf("%.1f", *server.Tps)
+			}
+		}
+		tpsCell := fmt.Sprintf("%6s", tps)
+		if server.Tps != nil && *server.Tps < 18 {
+			tpsCell = StyleStopped.Render(tpsCell)
+		}
+		b.WriteString(fmt.Sprintf("%-24s %s %7s %16s %8s %s\n", truncateTop(server.Name, 24), status, cpu, memory, players, tpsCell))
+	}
+
+	containers := append([]TopContainer(nil), snapshot.Containers...)
+	sortTopContainers(containers, sortKey, reverse)
+	b.WriteString("\n" + StyleHeader.Render(fmt.Sprintf("%-24s %7s %22s %22s %5s", "CONTAINER", "CPU", "MEMORY", "NET I/O", "PIDS")) + "\n")
+	if len(containers) == 0 {
+		b.WriteString(StyleSubtle.Render("No running containers") + "\n")
+	}
+	for _, c := range containers {
+		memory := fmt.Sprintf("%.0f MB", c.MemUsedMb)
+		if c.MemLimitMb > 0 {
+			memory = fmt.Sprintf("%.0f/%.0f MB", c.MemUsedMb, c.MemLimitMb)
+		}
+		b.WriteString(fmt.Sprintf("%-24s %6.1f%% %22s %22s %5s\n", truncateTop(c.Name, 24), c.CPUPercent, memory, c.NetIO, c.PIDs))
+	}
+
+	out := b.String()
+	if width > 0 {
+		lines := strings.Split(out, "\n")
+		for i, line := range lines {
+			if lipgloss.Width(line) > width {
+				lines[i] = lipgloss.NewStyle().MaxWidth(width).Render(line)
+			}
+		}
+		out = strings.Join(lines, "\n")
+	}
+	return out
+}
+
+func sortTopServers(servers []TopServer, key string, reverse bool) {
+	sort.SliceStable(servers, func(i, j int) bool {
+		a, b := servers[i], servers[j]
+		if a.Running != b.Running {
+			return a.Running
+		}
+		less := strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		switch key {
+		case TopSortCPU:
+			less = a.CPUPercent > b.CPUPercent
+		case TopSortMemory:
+			less = a.RamUsedMb > b.RamUsedMb
+		case TopSortPlayers:
+			less = a.Players > b.Players
+		case TopSortTps:
+			// Lowest TPS first: the struggling servers are the interesting ones.
+			less = tpsValue(a.Tps) < tpsValue(b.Tps)
+		}
+		if reverse {
+			return !less
+		}
+		return less
+	})
+}
+
+func sortTopContainers(containers []TopContainer, key string, reverse bool) {
+	sort.SliceStable(containers, func(i, j int) bool {
+		a, b := containers[i], containers[j]
+		less := a.Name < b.Name
+		switch key {
+		case TopSortCPU:
+			less = a.CPUPercent > b.CPUPercent
+		case TopSortMemory:
+			less = a.MemUsedMb > b.MemUsedMb
+		}
+		if reverse {
+			return !less
+		}
+		return less
+	})
+}
+
+func tpsValue(tps *float64) float64 {
+	if tps == nil {
+		return 20
+	}
+	return *tps
+}
+
+func nextTopSort(current string) string {
+	for i, key := range topSortKeys {
+		if key == current {
+			return topSortKeys[(i+1)%len(topSortKeys)]
+		}
+	}
+	return topSortKeys[0]
+}
+
+func truncateTop(value string, width int) string {
+	if len(value) <= width {
+		return value
+	}
+	return value[:width-1] + "…"
+}
@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/apitest"
+)
+
+func TestTopView(t *testing.T) {
+	tps := 19.8
+	lagging := 12.5
+	snapshot := TopSnapshot{
+		Servers: []TopServer{
+			{Name: "survival", Running: true, CPUPercent: 42.5, RamUsedMb: 3072, RamTotalMb: 4096, Players: 7, Tps: &lagging},
+			{Name: "lobby", Running: true, CPUPercent: 8.1, RamUsedMb: 1024, RamTotalMb: 2048, Players: 2, Tps: &tps},
+			{Name: "creative"},
+		},
+		Containers: []TopContainer{
+			{Name: "mineos-api", CPUPercent: 1.2, MemUsedMb: 180, MemLimitMb: 512, NetIO: "1.2MB / 800kB", BlockIO: "0B / 0B", PIDs: "24"},
+		},
+		Taken: time.Date(2024, 5, 1, 12, 30, 0, 0, time.Local),
+	}
+	model := topModel{
+		ctx:     context.Background(),
+		opts:    TopOptions{Interval: time.Hour, Fetch: func(context.Context) TopSnapshot { return snapshot }},
+		sortKey: TopSortCPU,
+		loading: true,
+	}
+
+	view := apitest.RenderView(t, model, "mineos-api",
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	apitest.AssertGolden(t, "top_sorted_by_name", view)
+}
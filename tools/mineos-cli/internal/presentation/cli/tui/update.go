@@ -3,7 +3,6 @@ package tui
 import (
 	"context"
 	"io"
-	"os/exec"
 	"sort"
 	"strings"
 	"time"
@@ -12,22 +11,40 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/application/usecases"
 	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/api"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/logtime"
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/ssh"
 )
 
+// Options are the TUI's command-line settings.
+type Options struct {
+	LogTimes logtime.Normalizer
+	ReadOnly bool
+	// Accessible lays the views out in one column of plain text, for
+	// screen readers.
+	Accessible bool
+	// Remote is the host reached with --ssh, if any.
+	Remote *ssh.Remote
+}
+
 // NewTuiModel creates a new TUI model with the given dependencies
-func NewTuiModel(loadConfig *usecases.LoadConfigUseCase, ctx context.Context, version string) TuiModel {
+func NewTuiModel(loadConfig *usecases.LoadConfigUseCase, ctx context.Context, version string, opts Options) TuiModel {
 	input := textinput.New()
 	input.Placeholder = "console command"
 	input.CharLimit = 2048
 	input.Width = 50
 
-	navItems := BuildNavItems()
+	navItems := BuildNavItems(opts.ReadOnly)
 
 	return TuiModel{
 		LoadConfig:    loadConfig,
 		Ctx:           ctx,
 		Version:       version,
 		LogsActive:    true,
+		LogHub:        NewLogHub(),
+		LogTimes:      opts.LogTimes,
+		ReadOnly:      opts.ReadOnly,
+		Accessible:    opts.Accessible,
+		Remote:        opts.Remote,
 		LogType:       LogTypeDocker,
 		LogSource:     DefaultDockerLogSource,
 		MinecraftType: "combined",
@@ -40,8 +57,8 @@ func NewTuiModel(loadConfig *usecases.LoadConfigUseCase, ctx context.Context, ve
 }
 
 // RunTui runs the TUI application with context and I/O streams
-func RunTui(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, version string, in io.Reader, out io.Writer) error {
-	model := NewTuiModel(loadConfig, ctx, version)
+func RunTui(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, version string, tuiOpts Options, in io.Reader, out io.Writer) error {
+	model := NewTuiModel(loadConfig, ctx, version, tuiOpts)
 
 	opts := []tea.ProgramOption{tea.WithAltScreen()}
 	if in != nil {
@@ -65,7 +82,7 @@ func RunTui(ctx context.Context, loadConfig *usecases.LoadConfigUseCase, version
 
 // Init initializes the TUI model
 func (m TuiModel) Init() tea.Cmd {
-	return tea.Batch(m.LoadConfigCmd(), m.LoadComposeCmd())
+	return tea.Batch(m.LoadConfigCmd(), m.LoadComposeCmd(), m.ListenLogsCmd())
 }
 
 // Update handles all incoming messages
@@ -106,13 +123,8 @@ func (m TuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case LogStreamStartedMsg:
 		return m.handleLogStreamStarted(msg)
 
-	case LogLineMsg:
-		m.AppendLog(msg.Line)
-		// Clear log-related errors on successful log receipt
-		if strings.Contains(m.ErrMsg, "log stream") || strings.Contains(m.ErrMsg, "stream") {
-			m.ErrMsg = ""
-		}
-		return m, m.ListenLogsCmd()
+	case LogsUpdatedMsg:
+		return m.handleLogsUpdated()
 
 	case LogErrorMsg:
 		if msg.Err != nil {
@@ -131,6 +143,9 @@ func (m TuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if !isTransientError {
 				m.ErrMsg = errStr
 			}
+			if m.LogType == LogTypeMinecraft && apiConnectionError(msg.Err) {
+				m.ApiDown = true
+			}
 
 			// Don't retry if containers were intentionally stopped
 			if m.ContainersStopped {
@@ -157,6 +172,9 @@ func (m TuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ActionResultMsg:
 		return m.handleActionResult(msg)
 
+	case ConsolePlayersMsg:
+		return m.handleConsolePlayers(msg)
+
 	case ExecFinishedMsg:
 		return m.handleExecFinished(msg)
 
@@ -181,12 +199,15 @@ func (m TuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case StreamingFinishedMsg:
 		return m.handleStreamingFinished(msg)
 
+	case UpdatesLoadedMsg:
+		return m.handleUpdatesLoaded(msg)
+
 	case HealthTickMsg:
 		// Don't poll if containers are intentionally stopped or already healthy
 		if m.ContainersStopped {
 			return m, nil
 		}
-		if m.ConfigReady && m.ErrMsg == "" {
+		if m.ConfigReady && m.ErrMsg == "" && !m.ApiDown {
 			return m, nil
 		}
 		// Re-attempt config and server load
@@ -240,6 +261,14 @@ func (m TuiModel) handleConfigLoaded(msg ConfigLoadedMsg) (tea.Model, tea.Cmd) {
 	m.StatusMsg = "" // Clear reconnecting status
 	m.RetryCount = 0
 	m.Client = api.NewClientFromConfig(msg.Cfg)
+	// The .env key can turn read-only on, never off
+	if msg.Cfg.IsTuiReadOnly() && !m.ReadOnly {
+		m.ReadOnly = true
+		m.NavItems = BuildNavItems(true)
+		m.NavIndex = FirstSelectableIndex(m.NavItems)
+		m.NavScroll = 0
+		m.ServerActions = false
+	}
 	return m, m.LoadServersCmd()
 }
 
@@ -267,13 +296,12 @@ func (m TuiModel) handleComposeServices(msg ComposeServicesMsg) (tea.Model, tea.
 
 func (m TuiModel) handleServersLoaded(msg ServersLoadedMsg) (tea.Model, tea.Cmd) {
 	if msg.Err != nil {
-		errStr := msg.Err.Error()
-		// Don't show transient connection errors in status
-		isTransient := strings.Contains(errStr, "connection refused") ||
-			strings.Contains(errStr, "no such host") ||
-			strings.Contains(errStr, "i/o timeout")
-		if !isTransient {
-			m.ErrMsg = errStr
+		// A connection error means the API is down, which the views show
+		// on their own; other errors are shown as they are.
+		if apiConnectionError(msg.Err) {
+			m.ApiDown = true
+		} else {
+			m.ErrMsg = msg.Err.Error()
 		}
 		// Schedule health poll to retry when API comes back
 		if !m.ContainersStopped {
@@ -286,6 +314,7 @@ func (m TuiModel) handleServersLoaded(msg ServersLoadedMsg) (tea.Model, tea.Cmd)
 		m.Client = api.NewClientFromConfig(msg.Cfg)
 		m.ConfigReady = true
 	}
+	m.ApiDown = false
 	m.Servers = msg.Servers
 	if len(m.Servers) == 0 {
 		m.Selected = 0
@@ -301,16 +330,41 @@ func (m TuiModel) handleServersLoaded(msg ServersLoadedMsg) (tea.Model, tea.Cmd)
 }
 
 func (m TuiModel) handleLogStreamStarted(msg LogStreamStartedMsg) (tea.Model, tea.Cmd) {
-	// Store the new stream state
-	m.LogsChan = msg.LogsChan
-	m.LogErrsChan = msg.ErrsChan
-	m.LogCancel = msg.Cancel
+	// Start commands run concurrently; if an older one finished last, the hub
+	// is streaming a source the user already left.
+	source := m.LogSource
+	if m.LogType == LogTypeMinecraft {
+		source = m.MinecraftSource
+	}
+	if msg.LogType != m.LogType || msg.LogSource != source {
+		return m, m.StartLogStreamCmd()
+	}
+	return m, nil
+}
 
-	// Clear logs when starting a new stream to prevent duplicates on reconnect
-	// The API sends all logs from the beginning, so we must clear to avoid accumulation
-	m.Logs = nil
+func (m TuiModel) handleLogsUpdated() (tea.Model, tea.Cmd) {
+	lines, dropped, status := m.LogHub.Snapshot()
+	m.Logs = lines
+	m.LogsDropped = dropped
+	if m.LogScroll > len(m.Logs) {
+		m.LogScroll = len(m.Logs)
+	}
+	listen := m.ListenLogsCmd()
 
-	return m, m.ListenLogsCmd()
+	switch {
+	case status == nil:
+		// Clear log-related errors on successful log receipt
+		if len(lines) > 0 && strings.Contains(m.ErrMsg, "stream") {
+			m.ErrMsg = ""
+		}
+		return m, listen
+	case status.Err != nil:
+		next, cmd := m.Update(LogErrorMsg{Err: status.Err})
+		return next, tea.Batch(listen, cmd)
+	default:
+		// Stream closed cleanly - reconnect silently
+		return m, tea.Batch(listen, m.StartLogStreamCmd())
+	}
 }
 
 func (m TuiModel) handleActionResult(msg ActionResultMsg) (tea.Model, tea.Cmd) {
@@ -319,7 +373,9 @@ func (m TuiModel) handleActionResult(msg ActionResultMsg) (tea.Model, tea.Cmd) {
 		m.Client = api.NewClientFromConfig(*msg.Cfg)
 		m.ConfigReady = true
 	}
-	if msg.Err != nil {
+	if actionUnreachable(msg.Err) || apiConnectionError(msg.Err) {
+		m.ApiDown = true
+	} else if msg.Err != nil {
 		m.ErrMsg = msg.Err.Error()
 	} else {
 		m.StatusMsg = msg.Message
@@ -334,11 +390,19 @@ func (m TuiModel) handleExecFinished(msg ExecFinishedMsg) (tea.Model, tea.Cmd) {
 		m.OutputLines = append(m.OutputLines, msg.Output...)
 	}
 
-	if msg.Err != nil {
+	if m.OpCancelling {
+		// The error is only the interrupt; the output shows how far it got.
+		m.OutputLines = append(m.OutputLines, "", m.glyph("✗ ", "")+msg.Action+" cancelled")
+		m.StatusMsg = msg.Action + " cancelled"
+	} else if actionUnreachable(msg.Err) {
+		// The CLI's output above already says what it could not reach.
+		m.OutputLines = append(m.OutputLines, "", m.glyph("✗ ", "")+msg.Action+" needs the API, which is not answering")
+		m.ApiDown = true
+	} else if msg.Err != nil {
 		m.OutputLines = append(m.OutputLines, "", "Error: "+msg.Err.Error())
 		m.ErrMsg = msg.Err.Error()
 	} else if msg.Action != "" {
-		m.OutputLines = append(m.OutputLines, "", "✓ "+msg.Action+" complete")
+		m.OutputLines = append(m.OutputLines, "", m.glyph("✓ ", "OK: ")+msg.Action+" complete")
 		m.StatusMsg = msg.Action + " complete"
 		m.ErrMsg = "" // Clear error on success
 	}
@@ -346,20 +410,23 @@ func (m TuiModel) handleExecFinished(msg ExecFinishedMsg) (tea.Model, tea.Cmd) {
 	// Add hint to go back
 	m.OutputLines = append(m.OutputLines, "", "Press Esc to go back.")
 
-	return m, tea.Batch(m.LoadConfigCmd(), m.LoadComposeCmd(), m.LoadServersCmd())
+	next := m.finishOperation()
+	return m, tea.Batch(m.LoadConfigCmd(), m.LoadComposeCmd(), m.LoadServersCmd(), next)
 }
 
 func (m TuiModel) handleConfirmAction(msg ConfirmActionMsg) (tea.Model, tea.Cmd) {
 	m.Mode = ModeNormal
 	m.ConfirmAction = nil
 	m.ConfirmMessage = ""
+	m.ConfirmName = ""
 
 	if !msg.Confirmed || msg.Action == nil {
 		m.StatusMsg = "Action cancelled"
 		return m, nil
 	}
 
-	return m, m.ExecMenuItem(*msg.Action)
+	cmd := m.StartOperation(*msg.Action)
+	return m, cmd
 }
 
 func (m TuiModel) handleInteractiveStarted(msg InteractiveStartedMsg) (tea.Model, tea.Cmd) {
@@ -395,20 +462,13 @@ func (m TuiModel) handleInteractiveFinished(msg InteractiveFinishedMsg) (tea.Mod
 		m.OutputLines = append(m.OutputLines, "", "Error: "+msg.Err.Error())
 		m.ErrMsg = msg.Err.Error()
 	} else {
-		m.OutputLines = append(m.OutputLines, "", "✓ Command complete")
+		m.OutputLines = append(m.OutputLines, "", m.glyph("✓ ", "OK: ")+"Command complete")
 		m.ErrMsg = ""
 	}
 	m.OutputLines = append(m.OutputLines, "", "Press Esc to go back.")
 
-	return m, tea.Batch(m.LoadConfigCmd(), m.LoadComposeCmd(), m.LoadServersCmd())
-}
-
-// AppendLog adds a line to the log buffer with size limiting
-func (m *TuiModel) AppendLog(line string) {
-	m.Logs = append(m.Logs, line)
-	if len(m.Logs) > MaxLogLines {
-		m.Logs = m.Logs[len(m.Logs)-MaxLogLines:]
-	}
+	next := m.finishOperation()
+	return m, tea.Batch(m.LoadConfigCmd(), m.LoadComposeCmd(), m.LoadServersCmd(), next)
 }
 
 // LoadConfigCmd creates a command to load configuration
@@ -431,7 +491,7 @@ func (m TuiModel) LoadConfigCmdWithRetry(retryCount int) tea.Cmd {
 // LoadComposeCmd creates a command to detect docker compose
 func (m TuiModel) LoadComposeCmd() tea.Cmd {
 	return func() tea.Msg {
-		compose, err := DetectCompose()
+		compose, err := DetectCompose(m.Remote)
 		return ComposeLoadedMsg{Compose: compose, Err: err}
 	}
 }
@@ -446,10 +506,7 @@ func (m TuiModel) LoadComposeServicesCmd() tea.Cmd {
 
 // ListComposeServices lists all services defined in docker-compose
 func (m TuiModel) ListComposeServices() ([]string, error) {
-	args := append([]string{}, m.Compose.BaseArgs...)
-	args = append(args, "config", "--services")
-	cmd := exec.Command(m.Compose.Exe, args...)
-	output, err := cmd.Output()
+	output, err := m.Compose.Command(context.Background(), []string{"config", "--services"}).Output()
 	if err != nil {
 		return nil, err
 	}
@@ -485,43 +542,25 @@ func (m TuiModel) LoadServersCmd() tea.Cmd {
 }
 
 // StartLogStreamCmd creates a command to start log streaming
-// This uses message-based state update to avoid the value receiver issue
+// The hub replaces any previous stream, so rapid switching is safe
 func (m TuiModel) StartLogStreamCmd() tea.Cmd {
 	return func() tea.Msg {
-		// Stop any existing stream first
-		if m.LogCancel != nil {
-			m.LogCancel()
-		}
-
 		if !m.LogsActive {
+			m.LogHub.Stop()
 			return nil
 		}
+		source, run, ok := m.logStream()
+		if !ok {
+			m.LogHub.Stop()
+			return nil
+		}
+		m.LogHub.Start(source, run)
 
-		ctx, cancel := context.WithCancel(context.Background())
-
-		var logsChan <-chan string
-		var errsChan <-chan error
 		logSource := m.LogSource
-
-		if m.LogType == LogTypeDocker {
-			if !m.ComposeReady {
-				cancel()
-				return nil
-			}
-			logsChan, errsChan = StreamDockerLogs(ctx, m.Compose, m.LogSource)
-		} else {
-			if !m.ConfigReady || m.MinecraftSource == "" {
-				cancel()
-				return nil
-			}
-			logsChan, errsChan = StreamMinecraftLogs(ctx, m.Client, m.MinecraftSource, m.MinecraftType)
+		if m.LogType == LogTypeMinecraft {
 			logSource = m.MinecraftSource
 		}
-
 		return LogStreamStartedMsg{
-			LogsChan:  logsChan,
-			ErrsChan:  errsChan,
-			Cancel:    cancel,
 			LogType:   m.LogType,
 			LogSource: logSource,
 		}
@@ -534,29 +573,21 @@ func (m TuiModel) EnsureLogStreamCmd() tea.Cmd {
 	return m.StartLogStreamCmd()
 }
 
-// ListenLogsCmd creates a command to listen for log messages
+// ListenLogsCmd waits for the next log hub update. Exactly one listener
+// runs for the life of the TUI: Init starts it and each LogsUpdatedMsg
+// re-arms it.
 func (m TuiModel) ListenLogsCmd() tea.Cmd {
-	logsChan := m.LogsChan
-	errsChan := m.LogErrsChan
-
-	if logsChan == nil && errsChan == nil {
+	hub := m.LogHub
+	if hub == nil {
 		return nil
 	}
 
 	return func() tea.Msg {
 		select {
-		case line, ok := <-logsChan:
-			if !ok {
-				// Channel closed cleanly - trigger silent retry
-				return LogRetryMsg{}
-			}
-			return LogLineMsg{Line: line}
-		case err, ok := <-errsChan:
-			if !ok {
-				// Channel closed cleanly - trigger silent retry
-				return LogRetryMsg{}
-			}
-			return LogErrorMsg{Err: err}
+		case <-hub.Updates():
+			return LogsUpdatedMsg{}
+		case <-hub.Done():
+			return nil
 		}
 	}
 }
@@ -587,6 +618,19 @@ func (m *TuiModel) RequestConfirmation(action *MenuItem, message string) {
 	m.Mode = ModeConfirm
 	m.ConfirmAction = action
 	m.ConfirmMessage = message
+	m.ConfirmName = ""
+}
+
+// RequestNameConfirmation sets up a confirmation dialog that takes the
+// server's name typed out, or a y/n with MINEOS_CONFIRM_LEVEL=yes.
+func (m *TuiModel) RequestNameConfirmation(action *MenuItem, message, server string) {
+	m.RequestConfirmation(action, message)
+	if !m.Cfg.ConfirmsByName() {
+		return
+	}
+	m.ConfirmName = server
+	m.Input.SetValue("")
+	m.Input.Focus()
 }
 
 // handleStreamingStarted handles the start of a streaming command
@@ -595,17 +639,22 @@ func (m TuiModel) handleStreamingStarted(msg StreamingStartedMsg) (tea.Model, te
 	m.StreamingRunning = true
 	m.StreamingLabel = msg.Label
 
-	// Switch to output view
-	m.PreviousView = m.CurrentView
+	// Switch to output view; beginOperation has set up its lines
+	if m.CurrentView != ViewOutput {
+		m.PreviousView = m.CurrentView
+	}
 	m.CurrentView = ViewOutput
 	m.OutputTitle = msg.Label
-	m.OutputLines = nil // Clear and let streaming populate
 
 	return m, m.ListenStreamingCmd()
 }
 
 // handleStreamingOutput handles a line of streaming output
 func (m TuiModel) handleStreamingOutput(msg StreamingOutputMsg) (tea.Model, tea.Cmd) {
+	if i := serviceStatusLine(m.OutputLines, msg.Line); i >= 0 {
+		m.OutputLines[i] = msg.Line
+		return m, m.ListenStreamingCmd()
+	}
 	m.OutputLines = append(m.OutputLines, msg.Line)
 	// Limit output buffer
 	if len(m.OutputLines) > MaxLogLines {
@@ -620,14 +669,25 @@ func (m TuiModel) handleStreamingFinished(msg StreamingFinishedMsg) (tea.Model,
 	m.StreamingOutput = nil
 
 	// Detect if containers were intentionally stopped
-	isStopAction := strings.Contains(msg.Label, "Stop") || strings.Contains(msg.Label, "Remove")
-	isStartAction := strings.Contains(msg.Label, "Start") || strings.Contains(msg.Label, "Restart")
-
-	if msg.Err != nil {
+	// Only the container actions change whether the API is up.
+	containers := strings.HasSuffix(msg.Label, "Containers")
+	isStopAction := containers && (strings.Contains(msg.Label, "Stop") || strings.Contains(msg.Label, "Remove"))
+	isStartAction := containers && (strings.Contains(msg.Label, "Start") || strings.Contains(msg.Label, "Restart"))
+
+	cancelled := m.OpCancelling
+	if cancelled {
+		// The containers are in whatever state compose left them; the
+		// reload below finds out.
+		m.OutputLines = append(m.OutputLines, "", m.glyph("✗ ", "")+msg.Label+" cancelled")
+		m.StatusMsg = msg.Label + " cancelled"
+	} else if actionUnreachable(msg.Err) {
+		m.OutputLines = append(m.OutputLines, "", m.glyph("✗ ", "")+msg.Label+" needs the API, which is not answering")
+		m.ApiDown = true
+	} else if msg.Err != nil {
 		m.OutputLines = append(m.OutputLines, "", "Error: "+msg.Err.Error())
 		m.ErrMsg = msg.Err.Error()
 	} else {
-		m.OutputLines = append(m.OutputLines, "", "✓ "+msg.Label+" complete")
+		m.OutputLines = append(m.OutputLines, "", m.glyph("✓ ", "OK: ")+msg.Label+" complete")
 		m.StatusMsg = msg.Label + " complete"
 		m.ErrMsg = ""
 
@@ -640,14 +700,23 @@ func (m TuiModel) handleStreamingFinished(msg StreamingFinishedMsg) (tea.Model,
 			m.ContainersStopped = false
 		}
 	}
+	if msg.Err == nil && !cancelled && msg.Label == "Upgrade CLI" {
+		m.OutputLines = append(m.OutputLines, "", "Quit and start the TUI again to use the new version.")
+	}
 	m.OutputLines = append(m.OutputLines, "", "Press Esc to go back.")
 
+	next := m.finishOperation()
 	// Don't try to load servers if we just stopped containers
 	if m.ContainersStopped {
-		return m, m.LoadComposeCmd() // Only reload compose status
+		return m, tea.Batch(m.LoadComposeCmd(), next) // Only reload compose status
 	}
 
-	return m, tea.Batch(m.LoadConfigCmd(), m.LoadComposeCmd(), m.LoadServersCmd())
+	cmds := []tea.Cmd{m.LoadConfigCmd(), m.LoadComposeCmd(), m.LoadServersCmd(), next}
+	if m.Updates != nil && (msg.Label == "Upgrade CLI" || msg.Label == "Update Stack") {
+		m.UpdatesLoading = true
+		cmds = append(cmds, m.LoadUpdatesCmd())
+	}
+	return m, tea.Batch(cmds...)
 }
 
 // ListenStreamingCmd creates a command to listen for streaming output
@@ -0,0 +1,302 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/freemancraft/mineos-sveltekit/tools/mineos-cli/internal/infrastructure/releases"
+)
+
+// releaseNotesLines is how much of the release notes the Updates view shows.
+const releaseNotesLines = 12
+
+// UpdateInfo is what the Updates view shows: what runs here and the newest
+// release of each channel.
+type UpdateInfo struct {
+	// StackImage, StackVersion and StackRevision describe the API container,
+	// from the OCI labels of its image.
+	StackImage    string
+	StackVersion  string
+	StackRevision string
+	StackErr      string
+
+	Releases    releases.Channels
+	ReleasesErr string
+	CheckedAt   time.Time
+}
+
+// UpdatesLoadedMsg is sent when the Updates view has checked for releases
+type UpdatesLoadedMsg struct {
+	Info UpdateInfo
+}
+
+// LoadUpdatesCmd reads the running stack version and fetches the releases.
+func (m TuiModel) LoadUpdatesCmd() tea.Cmd {
+	compose := m.Compose
+	ctx := m.Ctx
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+		info := UpdateInfo{CheckedAt: time.Now()}
+		info.StackImage, info.StackVersion, info.StackRevision, info.StackErr = stackImageVersion(ctx, compose)
+		channels, err := releases.FetchChannels(ctx)
+		if err != nil {
+			info.ReleasesErr = err.Error()
+		}
+		info.Releases = channels
+		return UpdatesLoadedMsg{Info: info}
+	}
+}
+
+// stackImageVersion inspects the API container. The labels of a container
+// include those of its image, so this is the version actually running rather
+// than the one last pulled.
+func stackImageVersion(ctx context.Context, compose *ComposeRunner) (image, version, revision, errMsg string) {
+	if compose == nil {
+		return "", "", "", "docker compose is not available"
+	}
+	out, err := compose.Command(ctx, []string{"ps", "-q", "api"}).Output()
+	id := strings.TrimSpace(string(out))
+	if err != nil || id == "" {
+		return "", "", "", "the API container is not running"
+	}
+	format := `{{.Config.Image}}|{{index .Config.Labels "org.opencontainers.image.version"}}|{{index .Config.Labels "org.opencontainers.image.revision"}}`
+	args := []string{"docker", "inspect", "--format", format, id}
+	inspect := compose.dockerCommand(ctx, args)
+	out, err = inspect.Output()
+	if err != nil {
+		return "", "", "", "docker inspect failed: " + err.Error()
+	}
+	parts := strings.SplitN(strings.TrimSpace(string(out)), "|", 3)
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	for i := range parts {
+		if parts[i] == "<no value>" {
+			parts[i] = ""
+		}
+	}
+	return parts[0], parts[1], parts[2], ""
+}
+
+func (m TuiModel) handleUpdatesLoaded(msg UpdatesLoadedMsg) (tea.Model, tea.Cmd) {
+	m.Updates = &msg.Info
+	m.UpdatesLoading = false
+	return m, nil
+}
+
+// cliRelease is the release the CLI would upgrade to on its channel.
+func (m TuiModel) cliRelease() *releases.Release {
+	if m.Updates == nil {
+		return nil
+	}
+	if m.Cfg.IsPreReleaseEnabled() {
+		return m.Updates.Releases.Preview
+	}
+	return m.Updates.Releases.Stable
+}
+
+// stackRelease is the release the stack would update to; pinned is true
+// when MINEOS_IMAGE_TAG names a version rather than a channel.
+func (m TuiModel) stackRelease() (release *releases.Release, pinned bool) {
+	if m.Updates == nil {
+		return nil, false
+	}
+	switch strings.TrimSpace(m.Cfg.ImageTag) {
+	case "", "latest":
+		return m.Updates.Releases.Stable, false
+	case "preview":
+		return m.Updates.Releases.Preview, false
+	}
+	return nil, true
+}
+
+// HandleUpdatesKey handles the Updates view's keys; handled is false for
+// keys it leaves to the rest of the TUI.
+func (m TuiModel) HandleUpdatesKey(key string) (tea.Model, tea.Cmd, bool) {
+	switch key {
+	case "r":
+		if m.UpdatesLoading {
+			return m, nil, true
+		}
+		m.UpdatesLoading = true
+		return m, m.LoadUpdatesCmd(), true
+	case "u":
+		if m.ReadOnly {
+			return m, nil, true
+		}
+		args := []string{"upgrade"}
+		if m.Cfg.IsPreReleaseEnabled() {
+			args = append(args, "--prerelease")
+		}
+		cmd := m.StartOperation(MenuItem{Label: "Upgrade CLI", Args: args, Streaming: true})
+		return m, cmd, true
+	case "s":
+		if m.ReadOnly {
+			return m, nil, true
+		}
+		m.RequestConfirmation(&MenuItem{Label: "Update Stack", Args: []string{"stack", "update"}, Streaming: true},
+			"Updating the stack pulls new images and recreates the containers; running servers are stopped and started again. Continue?")
+		return m, nil, true
+	case "p":
+		if m.ConfigReady && !m.ReadOnly {
+			return m, m.ToggleEnvSettingCmd("MINEOS_CLI_PRERELEASE_UPDATES", m.Cfg.PreReleaseUpdates), true
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+func (m TuiModel) RenderUpdatesMain(width, height int) []string {
+	lines := make([]string, 0, height)
+
+	lines = append(lines, StyleHeader.Render(" UPDATES "))
+	lines = append(lines, m.rule(width))
+	lines = append(lines, "")
+
+	if m.Updates == nil {
+		lines = append(lines, StyleSubtle.Render("Checking for updates..."))
+		return PadLines(lines, height)
+	}
+	info := m.Updates
+
+	version := Fallback(strings.TrimSpace(m.Version), "dev")
+	lines = append(lines, StyleHeader.Render("CLI"))
+	lines = append(lines, "  Installed: "+version)
+	channel := StyleRunning.Render("Stable")
+	if m.Cfg.IsPreReleaseEnabled() {
+		channel = StyleError.Render("Preview (Pre-release)")
+	}
+	if !m.ReadOnly {
+		channel += "  " + StyleSubtle.Render("[p] toggle")
+	}
+	lines = append(lines, "  Channel:   "+channel)
+	lines = append(lines, "  Latest:    "+m.updateStatus(version, m.cliRelease(), info.ReleasesErr))
+	lines = append(lines, "")
+
+	lines = append(lines, StyleHeader.Render("Stack"))
+	tag := Fallback(strings.TrimSpace(m.Cfg.ImageTag), "latest")
+	if info.StackErr != "" {
+		lines = append(lines, "  Running:   "+StyleStopped.Render(info.StackErr))
+	} else {
+		running := Fallback(info.StackVersion, StyleSubtle.Render("unknown (image has no version label)"))
+		if revision := info.StackRevision; len(revision) >= 7 {
+			running += StyleSubtle.Render(" (" + revision[:7] + ")")
+		}
+		lines = append(lines, "  Image:     "+info.StackImage)
+		lines = append(lines, "  Running:   "+running)
+	}
+	lines = append(lines, "  Tag:       "+tag)
+	switch release, pinned := m.stackRelease(); {
+	case pinned:
+		lines = append(lines, "  Latest:    "+StyleSubtle.Render("pinned; set MINEOS_IMAGE_TAG to latest or preview to follow a channel"))
+	case info.StackErr != "" || info.StackVersion == "":
+		lines = append(lines, "  Latest:    "+m.updateStatus("", release, info.ReleasesErr))
+	default:
+		lines = append(lines, "  Latest:    "+m.updateStatus(info.StackVersion, release, info.ReleasesErr))
+	}
+	lines = append(lines, "")
+
+	if stable := info.Releases.Stable; stable != nil {
+		lines = append(lines, StyleHeader.Render("Releases"))
+		lines = append(lines, "  Stable:    "+releaseLine(stable))
+		if preview := info.Releases.Preview; preview != nil && preview != stable {
+			lines = append(lines, "  Preview:   "+releaseLine(preview))
+		}
+		lines = append(lines, "")
+	}
+
+	if release := m.cliRelease(); release != nil && strings.TrimSpace(release.Body) != "" {
+		lines = append(lines, StyleHeader.Render("Release notes: "+release.TagName))
+		for _, note := range releaseNotes(release.Body, releaseNotesLines) {
+			lines = append(lines, "  "+TrimToWidth(note, width-2))
+		}
+		if release.HTMLURL != "" {
+			lines = append(lines, "  "+StyleSubtle.Render(release.HTMLURL))
+		}
+		lines = append(lines, "")
+	}
+
+	checked := "Checked " + info.CheckedAt.Format("15:04:05")
+	if m.UpdatesLoading {
+		checked = "Checking..."
+	}
+	lines = append(lines, StyleSubtle.Render(checked+"  [r] refresh"))
+
+	return PadLines(lines, height)
+}
+
+// updateStatus compares a running version with the newest release.
+func (m TuiModel) updateStatus(current string, release *releases.Release, errMsg string) string {
+	switch {
+	case release == nil && errMsg != "":
+		return StyleError.Render("check failed: " + errMsg)
+	case release == nil:
+		return StyleSubtle.Render("no releases yet")
+	case current == "dev":
+		return release.TagName + "  " + StyleSubtle.Render("(development build)")
+	case current == "":
+		return release.TagName
+	case strings.TrimPrefix(current, "v") == release.Version():
+		return release.TagName + "  " + StyleRunning.Render(m.glyph("✓ ", "")+"up to date")
+	}
+	return release.TagName + "  " + StyleStopped.Render("update available")
+}
+
+func releaseLine(release *releases.Release) string {
+	line := release.TagName
+	if !release.PublishedAt.IsZero() {
+		line += "  " + StyleSubtle.Render(release.PublishedAt.Local().Format("2006-01-02"))
+	}
+	return line
+}
+
+// releaseNotes turns the first lines of a Markdown release body into plain
+// text: headings lose their hashes, blank runs collapse and links keep their
+// text.
+func releaseNotes(body string, limit int) []string {
+	var notes []string
+	blank := false
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if strings.TrimSpace(line) == "" {
+			blank = len(notes) > 0
+			continue
+		}
+		if blank {
+			notes = append(notes, "")
+			blank = false
+		}
+		line = strings.TrimLeft(line, "#")
+		line = strings.ReplaceAll(line, "**", "")
+		line = markdownLinks(line)
+		if len(notes) >= limit {
+			notes = append(notes, StyleSubtle.Render("..."))
+			break
+		}
+		notes = append(notes, strings.TrimSpace(line))
+	}
+	return notes
+}
+
+// markdownLinks replaces [text](url) with text.
+func markdownLinks(line string) string {
+	for {
+		open := strings.Index(line, "[")
+		if open < 0 {
+			return line
+		}
+		mid := strings.Index(line[open:], "](")
+		if mid < 0 {
+			return line
+		}
+		end := strings.Index(line[open+mid:], ")")
+		if end < 0 {
+			return line
+		}
+		line = line[:open] + line[open+1:open+mid] + line[open+mid+end+1:]
+	}
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"regexp"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -66,3 +67,28 @@ func SanitizeLogLine(line string) string {
 
 	return result.String()
 }
+
+// serviceStatusPattern matches the per-service lines the CLI prints while
+// pulling or building ("  [api]  pulling  42% ...").
+var serviceStatusPattern = regexp.MustCompile(`^  \[([A-Za-z0-9._-]+)\]\s`)
+
+// serviceStatusLine returns the index of the earlier status line for the
+// same service in the current table, so streaming output can update it in
+// place. It returns -1 for other lines or a new service.
+func serviceStatusLine(lines []string, line string) int {
+	match := serviceStatusPattern.FindStringSubmatch(line)
+	if match == nil {
+		return -1
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		other := serviceStatusPattern.FindStringSubmatch(lines[i])
+		if other == nil {
+			// Only look back through the contiguous table.
+			return -1
+		}
+		if other[1] == match[1] {
+			return i
+		}
+	}
+	return -1
+}
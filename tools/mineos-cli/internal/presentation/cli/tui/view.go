@@ -15,6 +15,9 @@ func (m TuiModel) View() string {
 	// 1. Render Header
 	header := m.RenderHeader()
 	headerHeight := lipgloss.Height(header)
+	if m.Accessible {
+		return m.linearView(header, m.renderMain)
+	}
 
 	// 2. Define Layout Dimensions
 	contentHeight := m.Height - headerHeight - 2
@@ -27,32 +30,7 @@ func (m TuiModel) View() string {
 
 	// 3. Render Navigation and Content
 	leftLines := m.RenderNavSidebar(leftWidth, contentHeight)
-	var rightLines []string
-
-	switch m.CurrentView {
-	case ViewDashboard:
-		rightLines = m.RenderDashboardMain(rightWidth, contentHeight)
-	case ViewServers:
-		rightLines = m.RenderServersMain(rightWidth, contentHeight)
-	case ViewServiceLogs:
-		rightLines = m.RenderServiceLogsMain(rightWidth, contentHeight)
-	case ViewSettings:
-		rightLines = m.RenderSettingsMain(rightWidth, contentHeight)
-	case ViewOutput:
-		rightLines = m.RenderOutputMain(rightWidth, contentHeight)
-	default:
-		rightLines = []string{"View not implemented"}
-	}
-
-	// Overlay confirm dialog if active
-	if m.Mode == ModeConfirm {
-		rightLines = m.RenderConfirmDialog(rightWidth, contentHeight)
-	}
-
-	// Overlay search input if active
-	if m.Mode == ModeSearch {
-		rightLines = m.RenderSearchInput(rightLines, rightWidth, contentHeight)
-	}
+	rightLines := m.renderMain(rightWidth, contentHeight)
 
 	// 4. Assemble View
 	var b strings.Builder
@@ -78,6 +56,38 @@ func (m TuiModel) View() string {
 	return b.String()
 }
 
+// renderMain renders the current view with any dialog over it.
+func (m TuiModel) renderMain(width, height int) []string {
+	var lines []string
+	switch m.CurrentView {
+	case ViewDashboard:
+		lines = m.RenderDashboardMain(width, height)
+	case ViewServers:
+		lines = m.RenderServersMain(width, height)
+	case ViewServiceLogs:
+		lines = m.RenderServiceLogsMain(width, height)
+	case ViewSettings:
+		lines = m.RenderSettingsMain(width, height)
+	case ViewUpdates:
+		lines = m.RenderUpdatesMain(width, height)
+	case ViewOutput:
+		lines = m.RenderOutputMain(width, height)
+	default:
+		lines = []string{"View not implemented"}
+	}
+
+	// Overlay confirm dialog if active
+	if m.Mode == ModeConfirm {
+		lines = m.RenderConfirmDialog(width, height)
+	}
+
+	// Overlay search input if active
+	if m.Mode == ModeSearch {
+		lines = m.RenderSearchInput(lines, width, height)
+	}
+	return lines
+}
+
 // RenderNavSidebar renders the unified navigation menu
 func (m TuiModel) RenderNavSidebar(width, height int) []string {
 	lines := make([]string, 0, height)
@@ -145,6 +155,10 @@ func (m TuiModel) renderNavItem(item NavItem, selected bool, width int) string {
 		if item.Destructive {
 			label = label + " !"
 		}
+		if item.Action != nil && m.RunningOp != nil && m.operationBlocked(*item.Action) != "" {
+			// Disabled while a conflicting operation runs or waits
+			label = StyleSubtle.Render(label)
+		}
 		if selected {
 			prefix = StyleSelected.Render("▶ ")
 			label = StyleSelected.Render(label)
@@ -164,7 +178,7 @@ func (m TuiModel) RenderOutputMain(width, height int) []string {
 		title = " " + m.OutputTitle + " "
 	}
 	lines = append(lines, StyleHeader.Render(title))
-	lines = append(lines, StyleSubtle.Render(strings.Repeat("─", width)))
+	lines = append(lines, m.rule(width))
 	lines = append(lines, "")
 
 	// Reserve space for input if in interactive mode
@@ -203,7 +217,7 @@ func (m TuiModel) RenderOutputMain(width, height int) []string {
 		for len(lines) < height-inputHeight {
 			lines = append(lines, "")
 		}
-		lines = append(lines, StyleSubtle.Render(strings.Repeat("─", width)))
+		lines = append(lines, m.rule(width))
 		lines = append(lines, StyleStatus.Render("  INPUT: ")+m.Input.View())
 		lines = append(lines, StyleSubtle.Render("  [Enter] Send  [Ctrl+C] Cancel"))
 	}
@@ -213,6 +227,9 @@ func (m TuiModel) RenderOutputMain(width, height int) []string {
 
 // RenderConfirmDialog renders a confirmation dialog for destructive actions
 func (m TuiModel) RenderConfirmDialog(width, height int) []string {
+	if m.Accessible {
+		return m.renderConfirmText(height)
+	}
 	lines := make([]string, 0, height)
 
 	// Center the dialog vertically
@@ -255,8 +272,20 @@ func (m TuiModel) RenderConfirmDialog(width, height int) []string {
 
 	lines = append(lines, StyleError.Render("  │"+strings.Repeat(" ", boxWidth)+"│"))
 
+	// Typed server name
+	if m.ConfirmName != "" {
+		prompt := centerText("Type "+m.ConfirmName+" to confirm:", boxWidth)
+		lines = append(lines, StyleError.Render("  │")+prompt+StyleError.Render("│"))
+		typed := "  > " + m.Input.Value() + "█"
+		lines = append(lines, StyleError.Render("  │")+PadRight(typed, boxWidth)+StyleError.Render("│"))
+		lines = append(lines, StyleError.Render("  │"+strings.Repeat(" ", boxWidth)+"│"))
+	}
+
 	// Instructions
 	instructions := "[Enter] Confirm  [Esc] Cancel"
+	if m.ConfirmName != "" && strings.TrimSpace(m.Input.Value()) != m.ConfirmName {
+		instructions = "[Esc] Cancel"
+	}
 	instructionsPadded := centerText(instructions, boxWidth)
 	lines = append(lines, StyleError.Render("  │")+StyleSubtle.Render(instructionsPadded)+StyleError.Render("│"))
 
@@ -287,7 +316,7 @@ func (m TuiModel) RenderSearchInput(existingLines []string, width, height int) [
 
 	// Overlay search input at the bottom
 	if len(lines) >= 2 {
-		lines[len(lines)-2] = StyleSubtle.Render(strings.Repeat("─", width))
+		lines[len(lines)-2] = m.rule(width)
 		searchLine := "  Search: " + m.Input.View()
 		lines[len(lines)-1] = TrimToWidth(searchLine, width)
 	}